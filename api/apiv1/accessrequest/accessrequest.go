@@ -0,0 +1,91 @@
+package accessrequest
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/accessrequest"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// Create 发起应用权限申请
+func Create(c *core.Context) error {
+	var param view.ReqCreateAccessRequest
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = accessrequest.AccessRequest.Create(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// List 我发起的或待我审批的申请
+func List(c *core.Context) error {
+	var param view.ReqListAccessRequest
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := accessrequest.AccessRequest.List(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// Review 审批申请
+func Review(c *core.Context) error {
+	var param view.ReqReviewAccessRequest
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = accessrequest.AccessRequest.Review(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// Cancel 撤回申请
+func Cancel(c *core.Context) error {
+	var param view.ReqAccessRequestID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = accessrequest.AccessRequest.Cancel(c.GetUser(), param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// Revoke 收回已授予的权限
+func Revoke(c *core.Context) error {
+	var param view.ReqAccessRequestID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = accessrequest.AccessRequest.Revoke(c.GetUser(), param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/app/middleware"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/agent"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+)
+
+func ListConfig(c *core.Context) error {
+	var param view.ReqListAgentConfig
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := agent.AgentConfig.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+func CreateConfig(c *core.Context) error {
+	var param view.AgentConfig
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = agent.AgentConfig.Create(uint(c.GetUser().Uid), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func UpdateConfig(c *core.Context) error {
+	var param view.AgentConfig
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = agent.AgentConfig.Update(uint(c.GetUser().Uid), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func DeleteConfig(c *core.Context) error {
+	var param view.ReqAgentConfigID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = agent.AgentConfig.Delete(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func PublishConfig(c *core.Context) error {
+	var param view.ReqAgentConfigID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = agent.AgentConfig.Publish(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// EffectiveConfig 某台 agent 合并之后的配置
+func EffectiveConfig(c *core.Context) error {
+	env := c.QueryParam("env")
+	zoneCode := c.QueryParam("zone_code")
+	hostName := c.QueryParam("host_name")
+	if env == "" || zoneCode == "" || hostName == "" {
+		return c.OutputJSON(output.MsgInvalidParam, "env, zone_code and host_name are required")
+	}
+
+	payload, err := agent.AgentConfig.Effective(env, zoneCode, hostName)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(payload))
+}
+
+// PullConfig agent 拉取当前生效的配置，etcd 不可用时作为兜底。
+// 发布时大量 agent 同时拉取，传输速度受服务端带宽和该 agent 的 download_limit 限制
+func PullConfig(c echo.Context) error {
+	env := c.QueryParam("env")
+	zoneCode := c.QueryParam("zone_code")
+	hostName := c.QueryParam("host_name")
+	if env == "" || zoneCode == "" || hostName == "" {
+		return output.JSON(c, output.MsgInvalidParam, "env, zone_code and host_name are required")
+	}
+
+	payload, err := agent.AgentConfig.Effective(env, zoneCode, hostName)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
+	body, err := json.Marshal(output.JSONResult{Code: output.MsgOk, Message: "success", Data: payload})
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := agent.Bandwidth.Writer(c.Request().Context(), c.Response(), middleware.ClientIP(c), payload.Content.DownloadBytesPerSec())
+	_, err = w.Write(body)
+	return err
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/agent"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func ListOffline(c *core.Context) error {
+	var param view.ReqListAgentOffline
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := agent.AgentOffline.ListOffline(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+func ListFlapping(c *core.Context) error {
+	var param view.ReqListAgentFlapping
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := agent.AgentOffline.ListFlapping(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+func ListOfflineThreshold(c *core.Context) error {
+	list, err := agent.AgentOffline.ListThreshold()
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+func SetOfflineThreshold(c *core.Context) error {
+	var param view.ReqAgentOfflineThreshold
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = agent.AgentOffline.SetThreshold(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
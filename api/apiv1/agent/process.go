@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/agent"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// ProcessStatus 应用在节点上的进程状态
+func ProcessStatus(c *core.Context) error {
+	var param view.ReqAgentProcess
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	status, err := agent.AgentProcess.Status(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(status))
+}
+
+// ProcessRestart 重启应用在节点上的进程
+func ProcessRestart(c *core.Context) error {
+	var param view.ReqAgentProcess
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	status, err := agent.AgentProcess.Restart(param, c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(status))
+}
@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/app/middleware"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/agent"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+)
+
+// UploadPackage 上传 agent 升级包
+func UploadPackage(c *core.Context) error {
+	version := c.FormValue("version")
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid file: "+err.Error())
+	}
+
+	err = agent.AgentUpgrade.UploadPackage(uint(c.GetUser().Uid), version, file)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func ListPackage(c *core.Context) error {
+	list, err := agent.AgentUpgrade.ListPackage()
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+// DownloadPackage agent 下载升级包，下载速度受 agent.bandwidth 配置和该 agent 的 download_limit 限制。
+// 限速按 TCP 连接的来源地址区分 agent，开启 ipAllowlist.trustForwardedFor 时才使用代理头中的地址
+func DownloadPackage(c echo.Context) error {
+	path, err := agent.AgentUpgrade.PackagePath(c.QueryParam("version"))
+	if err != nil {
+		return c.String(http.StatusNotFound, err.Error())
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return c.String(http.StatusNotFound, err.Error())
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, echo.MIMEOctetStream)
+	header.Set(echo.HeaderContentDisposition, "attachment; filename="+filepath.Base(path))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(info.Size(), 10))
+	c.Response().WriteHeader(http.StatusOK)
+
+	addr := middleware.ClientIP(c)
+	w := agent.Bandwidth.Writer(c.Request().Context(), c.Response(), addr, agent.AgentConfig.DownloadLimit(addr))
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func CreateUpgrade(c *core.Context) error {
+	var param view.ReqCreateAgentUpgrade
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = agent.AgentUpgrade.Create(uint(c.GetUser().Uid), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func ListUpgrade(c *core.Context) error {
+	list, err := agent.AgentUpgrade.List()
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+func UpgradeDetail(c *core.Context) error {
+	var param view.ReqAgentUpgradeID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	detail, err := agent.AgentUpgrade.Detail(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(detail))
+}
+
+func PauseUpgrade(c *core.Context) error {
+	var param view.ReqAgentUpgradeID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = agent.AgentUpgrade.Pause(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func ResumeUpgrade(c *core.Context) error {
+	var param view.ReqAgentUpgradeID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = agent.AgentUpgrade.Resume(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func CancelUpgrade(c *core.Context) error {
+	var param view.ReqAgentUpgradeID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = agent.AgentUpgrade.Cancel(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
@@ -27,6 +27,22 @@ func Index(c *core.Context) error {
 	)
 }
 
+// AppDependency 应用依赖拓扑：上下游应用与共用资源
+func AppDependency(c *core.Context) error {
+	var param view.ReqAppDependency
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := analysis.Analysis.AppDependency(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
 // TopologySelect Kanban topology
 func TopologySelect(c echo.Context) error {
 	regionSelect, zoneSelect, envSelect := resource.Resource.GetSelectData()
@@ -74,12 +90,12 @@ func TopologyList(c echo.Context) error {
 	reqModel := ReqTopologyList{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	list, page, err := analysis.Analysis.GetTopologyList(reqModel.AppTopology, reqModel.CurrentPage, reqModel.PageSize, "update_time desc,id desc")
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "success", map[string]interface{}{
 		"pagination": page,
@@ -95,13 +111,13 @@ func TopologyRelationship(c echo.Context) error {
 	reqModel := ReqTopologyList{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	list, _, err := analysis.Analysis.GetTopologyList(reqModel.AppTopology, 1, 10000, "update_time desc,id desc")
 
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	res = make([]RespRelationship, 0)
@@ -136,7 +152,7 @@ func DependenceList(c echo.Context) error {
 
 	req := ReqList{}
 	if err = c.Bind(&req); err != nil {
-		return output.JSON(c, output.MsgErr, "参数错误:"+err.Error(), result)
+		return output.JSON(c, output.MsgInvalidParam, "参数错误:"+err.Error(), result)
 	}
 
 	req.PkgQs = strings.TrimSpace(req.PkgQs)
@@ -151,7 +167,7 @@ func DependenceList(c echo.Context) error {
 		// return output.JSON(c, output.MsgErr, "参数错误:必须传app_name或者PkgQs至少一个", result)
 	}
 	if rgIsOk == false {
-		return output.JSON(c, output.MsgErr, "范围参数错误", result)
+		return output.JSON(c, output.MsgInvalidParam, "范围参数错误", result)
 	}
 
 	if result, err = resource.Resource.AllPkgList(req.AppName, req.PkgQs, req.Operate, ver); err != nil {
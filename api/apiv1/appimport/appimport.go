@@ -0,0 +1,69 @@
+package appimport
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/appimport"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// List 扫描发现的待导入应用
+func List(c *core.Context) error {
+	var param view.ReqListAppImport
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := appimport.AppImport.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// Scan 立即扫描 GitLab 分组
+func Scan(c *core.Context) error {
+	resp, err := appimport.AppImport.Scan()
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+// Import 一键导入选中的应用
+func Import(c *core.Context) error {
+	var param view.ReqAppImport
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := appimport.AppImport.Import(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+// Ignore 忽略选中的应用
+func Ignore(c *core.Context) error {
+	var param view.ReqAppImport
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = appimport.AppImport.Ignore(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
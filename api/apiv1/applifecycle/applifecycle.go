@@ -0,0 +1,60 @@
+package applifecycle
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/applifecycle"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+)
+
+// SetStatus 修改应用生命周期状态
+func SetStatus(c *core.Context) error {
+	var param view.ReqSetAppStatus
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = applifecycle.AppLifecycle.SetStatus(param, c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success()
+}
+
+// ArchiveList 应用归档、删除记录
+func ArchiveList(c *core.Context) error {
+	var param view.ReqListAppArchive
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := applifecycle.AppLifecycle.ArchiveList(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success(c.WithData(list))
+}
+
+// ArchiveExport 下载清理前导出的配置和定时任务
+func ArchiveExport(c *core.Context) error {
+	var param view.ReqExportAppArchive
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	export, err := applifecycle.AppLifecycle.Export(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	filename := fmt.Sprintf("%s_archive_%d.json", export.AppName, param.ID)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", filename))
+	return c.JSON(http.StatusOK, export)
+}
@@ -0,0 +1,75 @@
+package apptransfer
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/apptransfer"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// Create 发起应用归属转移
+func Create(c *core.Context) error {
+	var param view.ReqCreateAppTransfer
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = apptransfer.AppTransfer.Create(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// List 我发起的或待我确认的转移
+func List(c *core.Context) error {
+	var param view.ReqListAppTransfer
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := apptransfer.AppTransfer.List(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// Review 接受或拒绝转移
+func Review(c *core.Context) error {
+	var param view.ReqReviewAppTransfer
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = apptransfer.AppTransfer.Review(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// Cancel 撤回转移
+func Cancel(c *core.Context) error {
+	var param view.ReqAppTransferID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = apptransfer.AppTransfer.Cancel(c.GetUser(), param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
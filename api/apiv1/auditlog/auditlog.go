@@ -0,0 +1,56 @@
+package auditlog
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
+	"github.com/douyu/juno/internal/pkg/service/auditlog"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+func List(c *core.Context) error {
+	var param view.ReqListAuditLog
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := auditlog.AuditLog.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	listquery.SetHeaders(c, pagination)
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// Export 按查询条件导出 CSV
+func Export(c *core.Context) error {
+	var param view.ReqListAuditLog
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	filename := fmt.Sprintf("audit_log_%s.csv", time.Now().Format("20060102150405"))
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", filename))
+	c.Response().WriteHeader(http.StatusOK)
+
+	err = auditlog.AuditLog.Export(param, c.Response())
+	if err != nil {
+		// 响应头已写出，只能记录日志
+		xlog.Error("auditlog.Export failed", xlog.String("err", err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package cmdb
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/cmdb"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// SourceList CMDB 数据源及最近一次同步结果
+func SourceList(c *core.Context) error {
+	return c.Success(c.WithData(cmdb.CMDB.Sources()))
+}
+
+// Sync 立即同步
+func Sync(c *core.Context) error {
+	var param view.ReqCmdbSync
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	results, err := cmdb.CMDB.Sync(param.Source, c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(results))
+}
+
+// ConflictList 同步冲突列表
+func ConflictList(c *core.Context) error {
+	var param view.ReqListCmdbConflict
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := cmdb.CMDB.ConflictList(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// ResolveConflict 处理同步冲突
+func ResolveConflict(c *core.Context) error {
+	var param view.ReqResolveCmdbConflict
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = cmdb.CMDB.ResolveConflict(param, c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
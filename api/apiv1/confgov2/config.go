@@ -8,8 +8,10 @@ import (
 
 	"github.com/douyu/juno/internal/app/core"
 	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
 	"github.com/douyu/juno/internal/pkg/service/assist"
 	"github.com/douyu/juno/internal/pkg/service/confgov2"
+	"github.com/douyu/juno/internal/pkg/service/permission"
 	"github.com/douyu/juno/internal/pkg/service/user"
 	"github.com/douyu/juno/pkg/errorconst"
 	"github.com/douyu/juno/pkg/model/view"
@@ -21,19 +23,26 @@ func List(c echo.Context) (err error) {
 	param := view.ReqListConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
 	err = c.Validate(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
-	list, err := confgov2.List(param)
+	// 只返回用户有权访问的机房配置
+	zones, _, err := permission.ZoneScope.UserZones(user.GetUser(c))
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
+	list, page, err := confgov2.List(param, zones...)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
+	listquery.SetHeaders(c, page)
 	return output.JSON(c, output.MsgOk, "", list)
 }
 
@@ -42,17 +51,17 @@ func Detail(c echo.Context) (err error) {
 	param := view.ReqDetailConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
 	err = c.Validate(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
 	detail, err := confgov2.Detail(param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "", detail)
@@ -63,22 +72,22 @@ func Create(c echo.Context) (err error) {
 	param := view.ReqCreateConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效: "+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效: "+err.Error())
 	}
 
 	err = c.Validate(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效: "+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效: "+err.Error())
 	}
 
 	fileNameRegex := regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_-]{1,32}$")
 	if !fileNameRegex.MatchString(param.FileName) {
-		return output.JSON(c, output.MsgErr, "无效的文件名")
+		return output.JSON(c, output.MsgInvalidParam, "无效的文件名")
 	}
 
 	resp, err := confgov2.Create(c, param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success", resp)
@@ -89,20 +98,20 @@ func Update(c echo.Context) (err error) {
 	param := view.ReqUpdateConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效: "+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效: "+err.Error())
 	}
 
 	err = c.Validate(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
 	err = confgov2.Update(c, param)
 	if err != nil {
 		if err == errorconst.ParamConfigNotExists.Error() {
-			return output.JSON(c, output.MsgErr, "当前配置不存在，无法更新")
+			return output.JSON(c, output.MsgNotFound, "当前配置不存在，无法更新")
 		}
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "")
@@ -113,15 +122,15 @@ func Publish(c *core.Context) (err error) {
 	param := view.ReqPublishConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, "参数无效: "+err.Error())
+		return c.OutputJSON(output.MsgInvalidParam, "参数无效: "+err.Error())
 	}
 
 	err = confgov2.Publish(param, c)
 	if err != nil {
 		if err == errorconst.ParamConfigNotExists.Error() {
-			return c.OutputJSON(output.MsgErr, "当前配置不存在，无法发布")
+			return c.OutputJSON(output.MsgNotFound, "当前配置不存在，无法发布")
 		}
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 	return c.OutputJSON(output.MsgOk, "发布成功")
 }
@@ -131,21 +140,21 @@ func History(c echo.Context) (err error) {
 	param := view.ReqHistoryConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效: "+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效: "+err.Error())
 	}
 
 	err = c.Validate(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
 	history, err := confgov2.History(param, user.GetUser(c).Uid)
 	if err != nil {
 		if err == errorconst.ParamConfigNotExists.Error() {
-			return output.JSON(c, output.MsgErr, "当前配置不存在，无法更新")
+			return output.JSON(c, output.MsgNotFound, "当前配置不存在，无法更新")
 		}
 
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "", history)
 }
@@ -155,11 +164,11 @@ func Diff(c echo.Context) (err error) {
 	param := view.ReqDiffConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 	resp, err := confgov2.Diff(param.ID, param.HistoryID)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "", resp)
 }
@@ -169,17 +178,17 @@ func Delete(c echo.Context) (err error) {
 	param := view.ReqDeleteConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
 	err = c.Validate(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
 	err = confgov2.Delete(c, param.ID)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "")
@@ -190,11 +199,11 @@ func InstanceList(c echo.Context) (err error) {
 	param := view.ReqConfigInstanceList{}
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 	err = c.Validate(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 	resp, err := confgov2.Instances(param)
 	if err != nil {
@@ -208,11 +217,11 @@ func AppAction(c echo.Context) (err error) {
 	param := view.ReqAppAction{}
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 	err = c.Validate(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
 	xlog.Debug("AppAction", xlog.String("setp", "begin"), xlog.Any("param", param), xlog.String("url", cfg.Cfg.Assist.Action.URL))
@@ -225,7 +234,7 @@ func AppAction(c echo.Context) (err error) {
 			Typ:      param.Typ,
 		})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, resp.Code, resp.Msg, resp.Data)
@@ -236,17 +245,17 @@ func InstanceConfigContent(c *core.Context) (err error) {
 	param := view.ReqReadInstanceConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
 	}
 
 	err = c.Validate(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "参数无效:"+err.Error())
+		return output.JSON(c, output.MsgInvalidParam, "参数无效:"+err.Error())
 	}
 
 	configContents, err := confgov2.ReadInstanceConfig(param)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
 	return c.OutputJSON(output.MsgOk, "success", c.WithData(configContents))
@@ -256,13 +265,13 @@ func Lock(c *core.Context) (err error) {
 	param := view.ReqLockConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, "invalid params "+err.Error())
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params "+err.Error())
 	}
 
 	u := user.GetUser(c)
 	err = confgov2.TryLock(uint(u.Uid), param.ConfigID)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
 	return c.OutputJSON(output.MsgOk, "success")
@@ -272,13 +281,13 @@ func Unlock(c *core.Context) (err error) {
 	param := view.ReqLockConfig{}
 	err = c.Bind(&param)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, "invalid params "+err.Error())
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params "+err.Error())
 	}
 
 	u := user.GetUser(c)
 	err = confgov2.Unlock(uint(u.Uid), param.ConfigID)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
 	return c.OutputJSON(output.MsgOk, "success")
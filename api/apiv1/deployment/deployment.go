@@ -0,0 +1,65 @@
+package deployment
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/deployment"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// Report CI 部署完成后上报，关联流水线执行记录
+func Report(c *core.Context) error {
+	var param view.ReqReportDeployment
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+	if token, ok := c.Get("OpenAuthAccessToken").(db.AccessToken); ok && param.Operator == "" {
+		param.Operator = token.Name
+	}
+
+	count, err := deployment.Deployment.Report(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"count": count,
+	}))
+}
+
+// List 应用部署历史
+func List(c *core.Context) error {
+	var param view.ReqListDeployment
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := deployment.Deployment.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// Latest 应用各实例最近一次部署
+func Latest(c *core.Context) error {
+	var param view.ReqLatestDeployment
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := deployment.Deployment.Latest(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
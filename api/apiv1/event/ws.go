@@ -0,0 +1,139 @@
+package event
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	casbin2 "github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/permission"
+	"github.com/douyu/juno/internal/pkg/service/wsevent"
+	"github.com/douyu/juno/pkg/graceful"
+	"github.com/douyu/juno/pkg/model/db"
+	"golang.org/x/net/websocket"
+)
+
+const pingInterval = 30 * time.Second
+
+// Subscribe 通过 WebSocket 向浏览器推送实体变更事件，替代页面轮询。
+// topic、key 可重复传入，key 为空时推送主题下的全部事件；受机房限制的用户只会收到可访问机房的事件，
+// 任务日志等需要应用权限的事件只推送给有权限的用户
+func Subscribe(c *core.Context) error {
+	params := c.QueryParams()
+	topics := params["topic"]
+	if len(topics) == 0 {
+		return c.OutputJSON(output.MsgInvalidParam, "topic 不能为空")
+	}
+	for _, topic := range topics {
+		if !wsevent.ValidTopic(topic) {
+			return c.OutputJSON(output.MsgInvalidParam, fmt.Sprintf("不支持订阅 %s", topic))
+		}
+	}
+
+	zones, restricted, err := permission.ZoneScope.UserZones(c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+	allow := wsevent.AppPermFilter(func(e wsevent.Event) bool {
+		return permission.ZoneAllowed(zones, restricted, e.Zone)
+	}, appPermChecker(c.GetUser()))
+
+	server := websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(conn *websocket.Conn) {
+			serve(conn, params["key"], topics, allow)
+		},
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// appPermChecker 与 CasbinAppMW 相同，先检查 casbin 应用权限，没有时检查 gitlab 权限。
+// 会话期间缓存结果，权限变更在浏览器重连后生效
+func appPermChecker(u *db.User) func(appName, env, action string) bool {
+	var (
+		mtx   sync.Mutex
+		perms = make(map[string]bool)
+	)
+	return func(appName, env, action string) bool {
+		if u == nil {
+			return false
+		}
+		key := fmt.Sprintf("%s|%s|%s", appName, env, action)
+		mtx.Lock()
+		defer mtx.Unlock()
+		if allowed, ok := perms[key]; ok {
+			return allowed
+		}
+
+		obj := casbin2.CasbinAppObjKey(appName, env)
+		allowed, err := casbin2.Casbin.CheckPermission(strconv.Itoa(u.Uid), obj, action, db.CasbinPolicyTypeApp)
+		if err != nil || !allowed {
+			allowed = permission.Permission.CheckGitlabAuth(uint(u.Uid), appName, env) == nil
+		}
+		perms[key] = allowed
+		return allowed
+	}
+}
+
+// serve 开始退出时主动断开，浏览器重连到其他实例或新进程
+func serve(conn *websocket.Conn, keys, topics []string, allow func(wsevent.Event) bool) {
+	defer graceful.Track()()
+	defer conn.Close()
+
+	session := wsevent.Instance().Subscribe(topics, keys, allow)
+	defer wsevent.Instance().Unsubscribe(session)
+
+	// 浏览器不发送消息，读取只用于感知连接断开
+	closed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, conn)
+		close(closed)
+	}()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		var e wsevent.Event
+		select {
+		case <-closed:
+			return
+		case <-graceful.Closing():
+			return
+		case <-ticker.C:
+			e = wsevent.Event{Topic: wsevent.TopicPing, Time: time.Now().Unix()}
+		case e = <-session.Events():
+		}
+
+		if session.Overflowed() {
+			e = wsevent.Event{Topic: wsevent.TopicResync, Time: time.Now().Unix()}
+		}
+		if err := websocket.JSON.Send(conn, e); err != nil {
+			return
+		}
+	}
+}
+
+// checkOrigin 只允许同源页面建立连接，防止其他站点借用户的登录态订阅事件。
+// 经反向代理转发且改写了 Host 时，按 X-Forwarded-Host 判断
+func checkOrigin(config *websocket.Config, req *http.Request) (err error) {
+	config.Origin, err = websocket.Origin(config, req)
+	if err != nil {
+		return
+	}
+	if config.Origin == nil {
+		return fmt.Errorf("origin not allowed")
+	}
+	host := config.Origin.Host
+	if host != req.Host && host != req.Header.Get("X-Forwarded-Host") {
+		return fmt.Errorf("origin not allowed")
+	}
+	return
+}
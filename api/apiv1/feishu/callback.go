@@ -0,0 +1,109 @@
+package feishu
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/douyu/juno/internal/pkg/service/accessrequest"
+	"github.com/douyu/juno/internal/pkg/service/apptransfer"
+	"github.com/douyu/juno/internal/pkg/service/promotion"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+const defaultApproveExpireDays = 7
+
+// callbackReq 消息卡片回调请求，首次配置请求网址时 Type 为 url_verification
+type callbackReq struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Token     string `json:"token"`
+	OpenID    string `json:"open_id"`
+	UserID    string `json:"user_id"`
+	Action    struct {
+		Value notice.FeishuActionValue `json:"value"`
+	} `json:"action"`
+}
+
+// Callback 飞书消息卡片回调，处理卡片上的审批按钮。飞书用户的 user_id 需要与 Juno 用户名一致
+func Callback(c echo.Context) error {
+	conf := cfg.Cfg.Notice.Feishu
+	if !conf.Enable || conf.VerificationToken == "" {
+		return c.JSON(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	}
+
+	var req callbackReq
+	err := c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(conf.VerificationToken)) != 1 {
+		return c.JSON(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+	}
+
+	if req.Type == "url_verification" {
+		return c.JSON(http.StatusOK, map[string]string{"challenge": req.Challenge})
+	}
+
+	u := user.User.GetUserByName(req.UserID)
+	if u.Uid == 0 || u.State == db.UserStateDisabled {
+		return toast(c, fmt.Sprintf("飞书用户 %s 没有对应的 Juno 用户", req.UserID))
+	}
+
+	value := req.Action.Value
+	approve := value.Action == notice.FeishuActionApprove
+	switch value.Kind {
+	case notice.ApprovalAccessRequest:
+		expireDays := conf.ApproveExpireDays
+		if expireDays <= 0 {
+			expireDays = defaultApproveExpireDays
+		}
+		err = accessrequest.AccessRequest.Review(&u, view.ReqReviewAccessRequest{
+			ID:         value.ID,
+			Approve:    approve,
+			ExpireDays: expireDays,
+			Comment:    "通过飞书卡片审批",
+		})
+	case notice.ApprovalPromotion:
+		err = promotion.Promotion.Review(&u, view.ReqReviewPromotion{
+			ID:      value.ID,
+			Approve: approve,
+			Comment: "通过飞书卡片审批",
+		})
+	case notice.ApprovalAppTransfer:
+		err = apptransfer.AppTransfer.Review(&u, view.ReqReviewAppTransfer{
+			ID:      value.ID,
+			Accept:  approve,
+			Comment: "通过飞书卡片确认",
+		})
+	default:
+		err = fmt.Errorf("不支持的审批类型 %s", value.Kind)
+	}
+	if err != nil {
+		xlog.Error("feishu.Callback review failed", xlog.String("user", u.Username), xlog.String("kind", value.Kind), xlog.String("err", err.Error()))
+		return toast(c, err.Error())
+	}
+
+	result := "已拒绝"
+	if approve {
+		result = "已通过"
+	}
+	return c.JSON(http.StatusOK, notice.FeishuResultCard(
+		fmt.Sprintf("[Juno] 审批%s", result),
+		fmt.Sprintf("审批单 #%d 已被 %s %s", value.ID, u.Username, result),
+		approve,
+	))
+}
+
+// toast 审批失败时保留原卡片，只提示错误信息
+func toast(c echo.Context, msg string) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"toast": map[string]string{"type": "error", "content": msg},
+	})
+}
@@ -0,0 +1,43 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/service/graphquery"
+	"github.com/douyu/juno/pkg/graphql"
+)
+
+// Query 执行只读 GraphQL 查询，一次请求获取应用、实例、配置、流水线、告警的嵌套数据。
+// 按 GraphQL 约定返回 {data, errors}，不使用 {code, msg, data} 包装
+func Query(c *core.Context) error {
+	var req graphql.Request
+	if c.Request().Method == http.MethodGet {
+		req.Query = c.QueryParam("query")
+		req.OperationName = c.QueryParam("operationName")
+		if variables := c.QueryParam("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return c.JSON(http.StatusBadRequest, &graphql.Response{
+					Errors: []*graphql.Error{{Message: "variables 不是有效的 JSON 对象"}},
+				})
+			}
+		}
+	} else if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, &graphql.Response{
+			Errors: []*graphql.Error{{Message: "请求体不是有效的 JSON: " + err.Error()}},
+		})
+	}
+
+	if req.Query == "" {
+		return c.JSON(http.StatusBadRequest, &graphql.Response{Errors: []*graphql.Error{{Message: "query 不能为空"}}})
+	}
+
+	resp := graphquery.GraphQuery.Execute(c.Request().Context(), c.GetUser(), req)
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Schema 以 SDL 描述可查询的类型和字段
+func Schema(c *core.Context) error {
+	return c.Success(c.WithData(graphquery.GraphQuery.SDL()))
+}
@@ -0,0 +1,71 @@
+package k8scluster
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/k8scluster"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// List k8s 集群列表，不返回凭证
+func List(c *core.Context) error {
+	list, err := k8scluster.K8sCluster.List()
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success(c.WithData(list))
+}
+
+// Save 创建或更新 k8s 集群
+func Save(c *core.Context) error {
+	var param view.ReqSaveK8sCluster
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	item, err := k8scluster.K8sCluster.Save(param, c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success(c.WithData(item))
+}
+
+// Delete 删除 k8s 集群
+func Delete(c *core.Context) error {
+	var param view.ReqK8sClusterID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = k8scluster.K8sCluster.Delete(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success()
+}
+
+// Check 立即检查集群连通性
+func Check(c *core.Context) error {
+	var param view.ReqK8sClusterID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	item, err := k8scluster.K8sCluster.Check(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success(c.WithData(item))
+}
+
+// ImportSetting 从系统设置导入集群
+func ImportSetting(c *core.Context) error {
+	resp, err := k8scluster.K8sCluster.ImportSetting(c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success(c.WithData(resp))
+}
@@ -0,0 +1,68 @@
+package notifyrule
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/notifyrule"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func List(c *core.Context) error {
+	var param view.ReqListNotifyRule
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := notifyrule.NotifyRule.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+func Create(c *core.Context) error {
+	var param view.ReqCreateNotifyRule
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = notifyrule.NotifyRule.Create(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func Update(c *core.Context) error {
+	var param view.ReqUpdateNotifyRule
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = notifyrule.NotifyRule.Update(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func Delete(c *core.Context) error {
+	var param view.ReqDeleteNotifyRule
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = notifyrule.NotifyRule.Delete(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
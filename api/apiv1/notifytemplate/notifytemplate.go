@@ -0,0 +1,85 @@
+package notifytemplate
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/notifytemplate"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func List(c *core.Context) error {
+	var param view.ReqListNotifyTemplate
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := notifytemplate.NotifyTemplate.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+func Save(c *core.Context) error {
+	var param view.ReqSaveNotifyTemplate
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = notifytemplate.NotifyTemplate.Save(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func Delete(c *core.Context) error {
+	var param view.ReqDeleteNotifyTemplate
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = notifytemplate.NotifyTemplate.Delete(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// Preview 使用示例事件渲染模板
+func Preview(c *core.Context) error {
+	var param view.ReqPreviewNotifyTemplate
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := notifytemplate.NotifyTemplate.Preview(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+// Test 发送测试消息到指定渠道
+func Test(c *core.Context) error {
+	var param view.ReqPreviewNotifyTemplate
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = notifytemplate.NotifyTemplate.Test(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
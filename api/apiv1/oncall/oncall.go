@@ -0,0 +1,115 @@
+package oncall
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/oncall"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// ListRotation 全部值班轮换
+func ListRotation(c *core.Context) error {
+	list, err := oncall.OnCall.Rotations()
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+// Rotation 团队值班轮换及当前值班人员
+func Rotation(c *core.Context) error {
+	var param view.ReqOnCallRotation
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := oncall.OnCall.Rotation(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+func SetRotation(c *core.Context) error {
+	var param view.ReqSetOnCallRotation
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = oncall.OnCall.SetRotation(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func DeleteRotation(c *core.Context) error {
+	var param view.ReqDeleteOnCallRotation
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = oncall.OnCall.DeleteRotation(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// ListIncident 告警中心列表
+func ListIncident(c *core.Context) error {
+	var param view.ReqListIncident
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := oncall.OnCall.ListIncident(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// AckIncident 确认告警，停止升级
+func AckIncident(c *core.Context) error {
+	var param view.ReqAckIncident
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = oncall.OnCall.Ack(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// ResolveIncident 标记告警已恢复
+func ResolveIncident(c *core.Context) error {
+	var param view.ReqAckIncident
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = oncall.OnCall.Resolve(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
@@ -0,0 +1,169 @@
+package permission
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/permission"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// ListPolicy 策略列表
+func ListPolicy(c *core.Context) error {
+	var param view.ReqListPolicy
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := permission.Policy.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// CreatePolicy 新建策略
+func CreatePolicy(c *core.Context) error {
+	var param view.ReqCreatePolicy
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = permission.Policy.Create(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// UpdatePolicy 更新策略
+func UpdatePolicy(c *core.Context) error {
+	var param view.ReqUpdatePolicy
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = permission.Policy.Update(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// DeletePolicy 删除策略
+func DeletePolicy(c *core.Context) error {
+	var param view.ReqDeletePolicy
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = permission.Policy.Delete(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// ListRoleBinding 用户组绑定列表
+func ListRoleBinding(c *core.Context) error {
+	var param view.ReqListRoleBinding
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := permission.Policy.ListBinding(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// CreateRoleBinding 将用户加入用户组
+func CreateRoleBinding(c *core.Context) error {
+	var param view.ReqRoleBinding
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = permission.Policy.CreateBinding(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// DeleteRoleBinding 将用户移出用户组
+func DeleteRoleBinding(c *core.Context) error {
+	var param view.ReqRoleBinding
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = permission.Policy.DeleteBinding(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// WhoCan 查询拥有某个权限的用户
+func WhoCan(c *core.Context) error {
+	var param view.ReqWhoCan
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := permission.Policy.WhoCan(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+// CheckPolicy 查询用户是否拥有某个权限
+func CheckPolicy(c *core.Context) error {
+	var param view.ReqCheckPolicy
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	ok, err := permission.Policy.Check(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(ok))
+}
+
+// ReloadPolicy 重新加载资源文件和策略
+func ReloadPolicy(c *core.Context) error {
+	err := casbin.Casbin.Reload()
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
@@ -11,7 +11,7 @@ import (
 func ListUserGroup(c echo.Context) (err error) {
 	list, err := permission.UserGroup.List()
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "", list)
@@ -22,12 +22,12 @@ func UpdateUserGroup(c echo.Context) (err error) {
 
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	err = permission.UserGroup.Update(param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
@@ -38,12 +38,12 @@ func ChangeUserGroup(c echo.Context) (err error) {
 
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	err = permission.UserGroup.ChangeUserGroup(param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
@@ -53,12 +53,12 @@ func SetAPIPerm(c echo.Context) (err error) {
 	var param view.ReqSetGroupAPIPerm
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	err = permission.UserGroup.SetAPIPerm(param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
@@ -68,12 +68,12 @@ func SetMenuPerm(c echo.Context) (err error) {
 	var param view.ReqSetGroupMenuPerm
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	err = permission.UserGroup.SetMenuPerm(param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
@@ -83,12 +83,12 @@ func GetMenuPerm(c echo.Context) (err error) {
 	var param view.ReqGetGroupMenuPerm
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	resp, err := permission.UserGroup.GetMenuPerm(param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success", resp)
@@ -98,12 +98,12 @@ func GetAPIPerm(c echo.Context) (err error) {
 	var param view.ReqGetGroupAPIPerm
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	perm, err := permission.UserGroup.GetAPIPerm(param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "", perm)
@@ -114,20 +114,31 @@ func SetAppPerm(c echo.Context) (err error) {
 
 	err = c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	err = permission.UserGroup.CreateAppPermission(param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
 }
 
 func GetAppPerm(c echo.Context) (err error) {
+	var param view.ReqGetGroupAppPolicy
 
-	return output.JSON(c, output.MsgOk, "success")
+	err = c.Bind(&param)
+	if err != nil {
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := permission.UserGroup.AppPolicies(param)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
+	return output.JSON(c, output.MsgOk, "success", list)
 }
 
 func AppPermissionList(c echo.Context) (err error) {
@@ -0,0 +1,53 @@
+package permission
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/permission"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// ListZoneScope 用户、团队的机房访问限制列表
+func ListZoneScope(c *core.Context) error {
+	var param view.ReqListZoneScope
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := permission.ZoneScope.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+// SetZoneScope 设置用户或团队可访问的机房
+func SetZoneScope(c *core.Context) error {
+	var param view.ReqSetZoneScope
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = permission.ZoneScope.Set(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// MyZoneScope 当前用户可访问的机房
+func MyZoneScope(c *core.Context) error {
+	zones, restricted, err := permission.ZoneScope.UserZones(c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(view.RespUserZoneScope{
+		Restricted: restricted,
+		Zones:      zones,
+	}))
+}
@@ -0,0 +1,126 @@
+package promotion
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/promotion"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// ConfigPreview 预览配置晋升到目标环境的差异
+func ConfigPreview(c *core.Context) error {
+	return preview(c, db.PromotionKindConfig)
+}
+
+// ConfigCreate 发起配置晋升
+func ConfigCreate(c *core.Context) error {
+	return create(c, db.PromotionKindConfig)
+}
+
+// PipelinePreview 预览流水线晋升到目标环境的差异
+func PipelinePreview(c *core.Context) error {
+	return preview(c, db.PromotionKindPipeline)
+}
+
+// PipelineCreate 发起流水线晋升
+func PipelineCreate(c *core.Context) error {
+	return create(c, db.PromotionKindPipeline)
+}
+
+// List 晋升历史，或我发起的、待我审批的晋升
+func List(c *core.Context) error {
+	var param view.ReqListPromotion
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := promotion.Promotion.List(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// Detail 晋升详情及差异
+func Detail(c *core.Context) error {
+	var param view.ReqPromotionID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	detail, err := promotion.Promotion.Detail(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(detail))
+}
+
+// Review 审批晋升
+func Review(c *core.Context) error {
+	var param view.ReqReviewPromotion
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = promotion.Promotion.Review(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// Cancel 撤回晋升
+func Cancel(c *core.Context) error {
+	var param view.ReqPromotionID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = promotion.Promotion.Cancel(c.GetUser(), param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func preview(c *core.Context, kind string) error {
+	var param view.ReqPromotionPreview
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := promotion.Promotion.Preview(kind, param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+func create(c *core.Context, kind string) error {
+	var param view.ReqCreatePromotion
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	item, err := promotion.Promotion.Create(c.GetUser(), kind, param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(item))
+}
@@ -23,10 +23,10 @@ func ProxyPost(c echo.Context) (err error) {
 	reqModel := view.ReqHTTPProxy{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
-	request := invoker.Resty.R().SetBody(reqModel.Body).SetQueryParams(reqModel.Params)
+	request := invoker.Resty.R().SetContext(c.Request().Context()).SetBody(reqModel.Body).SetQueryParams(reqModel.Params)
 	switch reqModel.Type {
 	case "POST":
 		xlog.Info("post info", xlog.Any("path", c.Request().URL.String()), xlog.Any("req", reqModel))
@@ -54,12 +54,12 @@ func NodeHeartBeat(c echo.Context) error {
 	reqModel := view.ReqNodeHeartBeat{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	info, err := json.Marshal(reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	if proxy.StreamStore.IsStreamExist() {
@@ -68,10 +68,10 @@ func NodeHeartBeat(c echo.Context) error {
 			Msg:   info,
 		})
 		if err != nil {
-			return output.JSON(c, output.MsgErr, err.Error())
+			return output.JSONError(c, err)
 		}
 	} else {
-		return output.JSON(c, output.MsgErr, "stream is not exist")
+		return output.JSON(c, output.MsgNotFound, "stream is not exist")
 	}
 	return output.JSON(c, output.MsgOk, "success")
 }
@@ -85,10 +85,10 @@ func WorkerHeartbeat(c echo.Context) error {
 			Msg:   body,
 		})
 		if err != nil {
-			return output.JSON(c, output.MsgErr, err.Error())
+			return output.JSONError(c, err)
 		}
 	} else {
-		return output.JSON(c, output.MsgErr, "stream is not exist")
+		return output.JSON(c, output.MsgNotFound, "stream is not exist")
 	}
 	return output.JSON(c, output.MsgOk, "success")
 }
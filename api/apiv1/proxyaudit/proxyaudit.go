@@ -0,0 +1,26 @@
+package proxyaudit
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/proxyaudit"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func List(c *core.Context) error {
+	var param view.ReqListProxyAudit
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := proxyaudit.ProxyAudit.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
@@ -0,0 +1,62 @@
+package recyclebin
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// List 回收站列表，非管理员只能看到自己删除的对象
+func List(c *core.Context) error {
+	var param view.ReqListRecycleItem
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := recyclebin.RecycleBin.List(c.GetUser(), param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	listquery.SetHeaders(c, pagination)
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+// Restore 恢复对象
+func Restore(c *core.Context) error {
+	var param view.ReqRecycleItemID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = recyclebin.RecycleBin.Restore(c.GetUser(), param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// Purge 彻底删除对象
+func Purge(c *core.Context) error {
+	var param view.ReqRecycleItemID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = recyclebin.RecycleBin.Purge(c.GetUser(), param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
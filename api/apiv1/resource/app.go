@@ -6,6 +6,7 @@ import (
 
 	"github.com/douyu/juno/internal/app/core"
 	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
 	"github.com/douyu/juno/internal/pkg/service/resource"
 	"github.com/douyu/juno/internal/pkg/service/system"
 	"github.com/douyu/juno/pkg/model/db"
@@ -25,7 +26,7 @@ func AppInfo(c echo.Context) error {
 	reqModel := ReqAppInfo{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	if reqModel.Aid > 0 {
@@ -36,7 +37,11 @@ func AppInfo(c echo.Context) error {
 
 	info, err = resource.Resource.GetApp(identify)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
+	}
+	info.Meta, err = resource.Resource.AppMeta(info.Aid)
+	if err != nil {
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "success", info)
 }
@@ -47,12 +52,18 @@ func AppList(c echo.Context) error {
 	reqModel := ReqAppList{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
-	}
-	list, page, err := resource.Resource.GetAppList(reqModel.AppInfo, reqModel.CurrentPage, reqModel.PageSize, reqModel.KeywordsType, reqModel.Keywords, reqModel.SearchPort, "update_time desc,aid desc")
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
+	}
+	reqModel.AppInfo.Status = reqModel.AppStatus
+	list, page, err := resource.Resource.GetAppList(reqModel.AppInfo, reqModel.KeywordsType, reqModel.Keywords, reqModel.SearchPort, view.AppListFilter{
+		Meta:     reqModel.MetaFilter,
+		Tags:     reqModel.Tags,
+		ZoneCode: reqModel.ZoneCode,
+	}, reqModel.ListQuery)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
+	listquery.SetHeaders(c, page)
 	return output.JSON(c, output.MsgOk, "success", map[string]interface{}{
 		"pagination": page,
 		"list":       list,
@@ -69,7 +80,7 @@ func AppListWithEnv(c echo.Context) error {
 
 	appList, err := resource.Resource.GetAppListWithEnv(param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success", appList)
@@ -88,16 +99,16 @@ func GetFrameVersion(c echo.Context) error {
 	var param view.ReqGetFrameVersion
 	err := c.Bind(&param)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	if param.AppName == "" {
-		return output.JSON(c, output.MsgErr, "必须传appName")
+		return output.JSON(c, output.MsgInvalidParam, "必须传appName")
 	}
 
 	frameVersion, err := resource.Resource.GetFrameVersion(param.AppName)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	resp.FrameVersion = frameVersion
@@ -159,13 +170,13 @@ func AppPut(c echo.Context) error {
 	reqModel := ReqAppPut{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	// todo 根据header头，识别是谁创建的
 	for _, value := range reqModel.List {
 		err = resource.Resource.PutApp(value, &db.User{})
 		if err != nil {
-			return output.JSON(c, output.MsgErr, err.Error())
+			return output.JSONError(c, err)
 		}
 	}
 	return output.JSON(c, output.MsgOk, "success")
@@ -179,11 +190,11 @@ func AppCreate(c echo.Context) error {
 	reqModel := ReqAppCreate{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	err = resource.Resource.CreateApp(reqModel.AppInfo, &db.User{})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
@@ -197,11 +208,11 @@ func AppUpdate(c echo.Context) error {
 	reqModel := ReqAppUpdate{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	err = resource.Resource.UpdateApp(reqModel.AppInfo, &db.User{})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
@@ -215,11 +226,11 @@ func AppDelete(c echo.Context) error {
 	reqModel := ReqAppDelete{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	err = resource.Resource.DeleteApp(reqModel.AppInfo, &db.User{})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
@@ -231,12 +242,12 @@ func GrpcAddrList(c *core.Context) error {
 
 	err := c.Bind(&param)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
 	}
 
 	port, nodes, err := resource.Resource.GetAppGrpcList(param.AppName)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
 	resp := RespAppGrpcAddrList{
@@ -259,12 +270,12 @@ func HttpAddrList(c *core.Context) error {
 
 	err := c.Bind(&param)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
 	}
 
 	port, nodes, err := resource.Resource.GetAppHttpList(param.AppName)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
 	resp := RespAppHTTPAddrList{
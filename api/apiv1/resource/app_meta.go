@@ -0,0 +1,62 @@
+package resource
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// AppMetaFieldList 应用自定义字段定义
+func AppMetaFieldList(c *core.Context) error {
+	list, err := resource.Resource.AppMetaFieldList()
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success(c.WithData(list))
+}
+
+// AppMetaFieldSave 创建或更新应用自定义字段
+func AppMetaFieldSave(c *core.Context) error {
+	var param view.ReqSaveAppMetaField
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = resource.Resource.SaveAppMetaField(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success()
+}
+
+// AppMetaFieldDelete 删除应用自定义字段及其所有值
+func AppMetaFieldDelete(c *core.Context) error {
+	var param view.ReqDeleteAppMetaField
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = resource.Resource.DeleteAppMetaField(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success()
+}
+
+// AppMetaSet 设置应用的自定义字段值
+func AppMetaSet(c *core.Context) error {
+	var param view.ReqSetAppMeta
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = resource.Resource.SetAppMeta(param.Aid, param.Meta)
+	if err != nil {
+		return c.OutputError(err)
+	}
+	return c.Success()
+}
@@ -17,12 +17,12 @@ func AppNodeInfo(c echo.Context) error {
 	reqModel := ReqAppNodeInfo{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	info, err = resource.Resource.GetAppNodeInfo(reqModel.Id)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "success", info)
 }
@@ -34,7 +34,15 @@ func AppNodeList(c echo.Context) error {
 	reqModel := ReqAppNodeList{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
+	}
+
+	zones, allowed, err := scopedZones(c, reqModel.ZoneCode)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	if !allowed {
+		return output.JSON(c, output.MsgNoAuth, "当前用户没有该机房的访问权限")
 	}
 
 	list, pagination, err := resource.Resource.GetAppNodeList(db.AppNode{
@@ -44,9 +52,9 @@ func AppNodeList(c echo.Context) error {
 		IP:       reqModel.Ip,
 		Env:      reqModel.Env,
 		ZoneCode: reqModel.ZoneCode,
-	}, reqModel.CurrentPage, reqModel.PageSize, "update_time desc,id desc")
+	}, reqModel.CurrentPage, reqModel.PageSize, "update_time desc,id desc", zones...)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "success", map[string]interface{}{
 		"pagination": pagination,
@@ -61,7 +69,7 @@ func AppNodePut(c echo.Context) error {
 	)
 	reqModel := ReqAppNodePut{}
 	if err = c.Bind(&reqModel); err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	// todo 根据header头，识别是谁创建的
 	if reqModel.Id > 0 {
@@ -72,7 +80,7 @@ func AppNodePut(c echo.Context) error {
 
 	err = resource.Resource.PutAppNode(identify, reqModel.List, &db.User{})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
@@ -85,14 +93,14 @@ func AppEnvZoneList(c echo.Context) error {
 	reqModel := ReqAppEnvNodeList{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	preNodes, err := resource.Resource.GetAllAppEnvZone(db.AppNode{
 		AppName: reqModel.AppName,
 	})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	type zone struct {
@@ -137,17 +145,17 @@ func AppNodeTransferList(c echo.Context) error {
 	reqModel := ReqAppNodeTransferList{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	list1, total1, err1 := resource.Resource.AppNodeTransferSource()
 	if err1 != nil {
-		return output.JSON(c, output.MsgErr, err1.Error())
+		return output.JSONError(c, err1)
 	}
 
 	target, total2, err2 := resource.Resource.AppNodeTransferTarget(reqModel.Aid)
 	if err2 != nil {
-		return output.JSON(c, output.MsgErr, err2.Error())
+		return output.JSONError(c, err2)
 	}
 
 	targetList := make([]string, 0)
@@ -168,17 +176,17 @@ func AppNodeTransferPut(c echo.Context) error {
 	reqModel := ReqAppNodeTransferPut{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	appInfo, err := resource.Resource.GetApp(reqModel.Aid)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	oldTarget, _, err := resource.Resource.AppNodeTransferTarget(reqModel.Aid)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	oldTargetMap := make(map[string]interface{}, 0)
@@ -198,7 +206,7 @@ func AppNodeTransferPut(c echo.Context) error {
 	err = resource.Resource.AppNodeTransferPut(tx, add, del, appInfo, nil)
 	if err != nil {
 		tx.Rollback()
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	tx.Commit()
 	return output.JSON(c, output.MsgOk, "success", "")
@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// ZoneBatch 批量创建、更新可用区
+func ZoneBatch(c *core.Context) error {
+	var param view.ReqBatchZone
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := resource.Resource.BatchZones(param, c.GetUser())
+	return outputBatch(c, resp, err)
+}
+
+// ZoneImport 通过 CSV 导入可用区，preview=true 时只返回校验结果
+func ZoneImport(c *core.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid file: "+err.Error())
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return c.OutputError(err)
+	}
+	defer reader.Close()
+
+	list, err := resource.ParseZoneCSV(reader)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	resp, err := resource.Resource.BatchZones(view.ReqBatchZone{
+		List:    list,
+		Preview: c.FormValue("preview") == "true",
+	}, c.GetUser())
+	return outputBatch(c, resp, err)
+}
+
+// NodeBatch 批量创建、更新机器节点
+func NodeBatch(c *core.Context) error {
+	var param view.ReqBatchNode
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := resource.Resource.BatchNodes(param, c.GetUser())
+	return outputBatch(c, resp, err)
+}
+
+// NodeImport 通过 CSV 导入机器节点，preview=true 时只返回校验结果
+func NodeImport(c *core.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid file: "+err.Error())
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return c.OutputError(err)
+	}
+	defer reader.Close()
+
+	list, err := resource.ParseNodeCSV(reader)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	resp, err := resource.Resource.BatchNodes(view.ReqBatchNode{
+		List:    list,
+		Preview: c.FormValue("preview") == "true",
+	}, c.GetUser())
+	return outputBatch(c, resp, err)
+}
+
+// outputBatch 存在校验失败的行时返回错误，同时返回每行的校验结果
+func outputBatch(c *core.Context, resp view.RespBatchResource, err error) error {
+	if err != nil {
+		return c.OutputError(err)
+	}
+	if !resp.Preview && resp.Invalid > 0 {
+		return c.OutputJSON(output.MsgInvalidParam, fmt.Sprintf("%d 行校验失败，未导入任何数据", resp.Invalid), c.WithData(resp))
+	}
+	return c.Success(c.WithData(resp))
+}
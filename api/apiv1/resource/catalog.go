@@ -0,0 +1,41 @@
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v2"
+)
+
+// CatalogExport 导出服务目录，format 为 yaml 时返回 YAML，否则返回 JSON
+func CatalogExport(c *core.Context) error {
+	var param view.ReqCatalogExport
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	catalog, err := resource.Resource.Catalog(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	filename := fmt.Sprintf("juno_catalog_%s", time.Now().Format("20060102150405"))
+	if param.Format == view.CatalogFormatYAML {
+		content, err := yaml.Marshal(catalog)
+		if err != nil {
+			return c.OutputError(err)
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s.yaml", filename))
+		return c.Blob(http.StatusOK, "application/x-yaml; charset=utf-8", content)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s.json", filename))
+	return c.JSON(http.StatusOK, catalog)
+}
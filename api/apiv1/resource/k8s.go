@@ -0,0 +1,34 @@
+package resource
+
+import (
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+)
+
+// AppWorkloadList 应用在 k8s 集群中的工作负载及 Pod，与主机节点列表并列展示
+func AppWorkloadList(c echo.Context) error {
+	var reqModel view.ReqAppWorkloadList
+	err := c.Bind(&reqModel)
+	if err != nil {
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
+	}
+	if reqModel.AppName == "" {
+		return output.JSON(c, output.MsgInvalidParam, "应用名不能为空")
+	}
+
+	zones, allowed, err := scopedZones(c, reqModel.ZoneCode)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	if !allowed {
+		return output.JSON(c, output.MsgNoAuth, "当前用户没有该机房的访问权限")
+	}
+
+	resp, err := resource.Resource.AppWorkloadList(reqModel, zones...)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	return output.JSON(c, output.MsgOk, "success", resp)
+}
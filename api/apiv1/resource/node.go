@@ -22,7 +22,7 @@ func NodeInfo(c echo.Context) error {
 	reqModel := ReqNodeInfo{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	if reqModel.Id > 0 {
@@ -33,9 +33,13 @@ func NodeInfo(c echo.Context) error {
 	tx := invoker.JunoMysql
 	info, err = resource.Resource.GetNode(tx, identify)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
-	return output.JSON(c, output.MsgOk, "success", info)
+	metric, err := resource.Resource.LatestNodeMetric(info.HostName)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	return output.JSON(c, output.MsgOk, "success", view.NodeInfo{Node: info, Metric: metric})
 }
 
 func NodeList(c echo.Context) error {
@@ -43,12 +47,20 @@ func NodeList(c echo.Context) error {
 	reqModel := ReqNodeList{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
+	}
+
+	zones, allowed, err := scopedZones(c, reqModel.ZoneCode)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	if !allowed {
+		return output.JSON(c, output.MsgNoAuth, "当前用户没有该机房的访问权限")
 	}
 
-	list, pagination, err := resource.Resource.GetNodeList(reqModel.Node, reqModel.CurrentPage, reqModel.PageSize, reqModel.KeywordsType, reqModel.Keywords, "update_time desc,id desc")
+	list, pagination, err := resource.Resource.GetNodeList(reqModel.Node, reqModel.CurrentPage, reqModel.PageSize, reqModel.KeywordsType, reqModel.Keywords, "update_time desc,id desc", reqModel.Tags, zones...)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "success", map[string]interface{}{
 		"pagination": pagination,
@@ -62,13 +74,13 @@ func NodePut(c echo.Context) error {
 	)
 	reqModel := ReqNodePut{}
 	if err = c.Bind(&reqModel); err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	tx := invoker.JunoMysql
 	for _, value := range reqModel.List {
 		err = resource.Resource.PutNode(tx, value)
 		if err != nil {
-			return output.JSON(c, output.MsgErr, err.Error())
+			return output.JSONError(c, err)
 		}
 	}
 	return output.JSON(c, output.MsgOk, "success")
@@ -82,11 +94,11 @@ func NodeCreate(c echo.Context) error {
 	reqModel := ReqNodeCreate{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	err = resource.Resource.CreateNode(invoker.JunoMysql, reqModel.Node, &db.User{})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "success")
 }
@@ -98,11 +110,11 @@ func NodeUpdate(c echo.Context) error {
 	reqModel := ReqNodeUpdate{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	err = resource.Resource.UpdateNode(reqModel.Node, &db.User{})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "success")
 }
@@ -115,11 +127,11 @@ func NodeDelete(c echo.Context) error {
 	reqModel := ReqNodeUpdate{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	err = resource.Resource.DeleteNode(reqModel.Node, &db.User{})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "success")
 }
@@ -132,32 +144,52 @@ func NodeHeartBeat(c echo.Context) error {
 	reqModel := view.ReqNodeHeartBeat{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	err = resource.Resource.NodeHeartBeat(reqModel, &db.User{})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	return output.JSON(c, output.MsgOk, "success")
 }
 
+// NodeMetricList 节点主机资源指标历史
+func NodeMetricList(c echo.Context) error {
+	var param view.ReqNodeMetricList
+	err := c.Bind(&param)
+	if err != nil {
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
+	}
+
+	err = c.Validate(&param)
+	if err != nil {
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := resource.Resource.NodeMetricList(param)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	return output.JSON(c, output.MsgOk, "success", list)
+}
+
 // NodeTransferList list of available areas of the node
 func NodeTransferList(c echo.Context) error {
 	var err error
 	reqModel := ReqNodeTransferList{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	list1, total1, err1 := resource.Resource.NodeTransferSource()
 	if err1 != nil {
-		return output.JSON(c, output.MsgErr, err1.Error())
+		return output.JSONError(c, err1)
 	}
 
 	target, total2, err2 := resource.Resource.NodeTransferTarget(reqModel.ZoneCode, reqModel.Env)
 	if err2 != nil {
-		return output.JSON(c, output.MsgErr, err2.Error())
+		return output.JSONError(c, err2)
 	}
 
 	targetList := make([]string, 0)
@@ -179,18 +211,18 @@ func NodeTransferPut(c echo.Context) error {
 	reqModel := ReqNodeTransferPut{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	// get zone info
 	zoneInfo, err := resource.Resource.GetZoneInfo(db.Zone{Id: reqModel.ZoneId})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	oldTarget, _, err := resource.Resource.NodeTransferTarget(zoneInfo.ZoneCode, zoneInfo.Env)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	oldTargetMap := make(map[string]interface{}, 0)
@@ -208,7 +240,7 @@ func NodeTransferPut(c echo.Context) error {
 
 	err = resource.Resource.NodeTransferPut(add, del, zoneInfo)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success", "")
@@ -220,12 +252,12 @@ func NodeStatics(c echo.Context) error {
 	start := end - 86400*30
 	dayCnts, err := resource.Resource.NodeDayCnt(start, end)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	list, err := resource.Resource.GetAllNode()
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	item1 := NodeStaticsInfo{
 		Name: "未部署",
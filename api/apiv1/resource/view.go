@@ -1,6 +1,9 @@
 package resource
 
-import "github.com/douyu/juno/pkg/model/db"
+import (
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
 
 // 应用信息
 type ReqAppInfo struct {
@@ -10,11 +13,14 @@ type ReqAppInfo struct {
 
 type ReqAppList struct {
 	db.AppInfo
-	KeywordsType string `query:"keywords_type"`
-	Keywords     string `query:"keywords"`
-	CurrentPage  int    `query:"currentPage"`
-	PageSize     int    `query:"pageSize"`
-	SearchPort   string `query:"search_port"`
+	view.ListQuery
+	KeywordsType string   `query:"keywords_type"` // app_name、aid、name、owner、repo，为空时匹配应用名、中文名、仓库地址和负责人
+	Keywords     string   `query:"keywords"`
+	SearchPort   string   `query:"search_port"`
+	MetaFilter   []string `query:"meta"` // 自定义字段筛选，name=value
+	Tags         []string `query:"tag"`  // 标签筛选，需同时拥有全部标签
+	ZoneCode     string   `query:"zone_code"`
+	AppStatus    string   `query:"status"` // 生命周期状态，为空时不展示已归档、待删除的应用
 }
 
 type ReqAppPut struct {
@@ -73,10 +79,11 @@ type ReqNodeInfo struct {
 
 type ReqNodeList struct {
 	db.Node
-	CurrentPage  int    `query:"currentPage"`
-	PageSize     int    `query:"pageSize"`
-	KeywordsType string `query:"keywords_type"`
-	Keywords     string `query:"keywords"`
+	CurrentPage  int      `query:"currentPage"`
+	PageSize     int      `query:"pageSize"`
+	KeywordsType string   `query:"keywords_type"`
+	Keywords     string   `query:"keywords"`
+	Tags         []string `query:"tag"` // 标签筛选，需同时拥有全部标签
 }
 
 type ReqNodePut struct {
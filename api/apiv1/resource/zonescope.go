@@ -0,0 +1,16 @@
+package resource
+
+import (
+	"github.com/douyu/juno/internal/pkg/service/permission"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/labstack/echo/v4"
+)
+
+// scopedZones 返回列表查询需要限定的机房，不受限制时返回 nil。请求的 zoneCode 不可访问时 allowed 为 false
+func scopedZones(c echo.Context, zoneCode string) (zones []string, allowed bool, err error) {
+	zones, restricted, err := permission.ZoneScope.UserZones(user.GetUser(c))
+	if err != nil || !restricted {
+		return nil, true, err
+	}
+	return zones, permission.ZoneAllowed(zones, restricted, zoneCode), nil
+}
@@ -0,0 +1,211 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/douyu/juno/internal/pkg/service/provision"
+	"github.com/douyu/juno/pkg/scim"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+// ServiceProviderConfig 声明支持的 SCIM 特性
+func ServiceProviderConfig(c echo.Context) error {
+	return respond(c, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{scim.SchemaServiceProviderConfig},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": scim.MaxResults},
+		"changePassword": map[string]bool{"supported": false},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]interface{}{
+			{
+				"type":        "oauthbearertoken",
+				"name":        "Service Account Token",
+				"description": "Juno service account token with scim scope",
+				"primary":     true,
+			},
+		},
+	})
+}
+
+// ResourceTypes 支持的资源类型
+func ResourceTypes(c echo.Context) error {
+	types := []map[string]interface{}{
+		{
+			"schemas":  []string{scim.SchemaResourceType},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   scim.SchemaUser,
+		},
+		{
+			"schemas":  []string{scim.SchemaResourceType},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   scim.SchemaGroup,
+		},
+	}
+	return respond(c, http.StatusOK, scim.NewListResponse(types, len(types), 1, len(types)))
+}
+
+func ListUsers(c echo.Context) error {
+	startIndex, count := pagination(c)
+	resp, err := provision.Provision.ListUsers(c.QueryParam("filter"), startIndex, count)
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusOK, resp)
+}
+
+func GetUser(c echo.Context) error {
+	resp, err := provision.Provision.GetUser(c.Param("id"))
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusOK, resp)
+}
+
+func CreateUser(c echo.Context) error {
+	var param scim.User
+	if err := decode(c, &param); err != nil {
+		return fail(c, err)
+	}
+
+	resp, err := provision.Provision.CreateUser(param)
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusCreated, resp)
+}
+
+func ReplaceUser(c echo.Context) error {
+	var param scim.User
+	if err := decode(c, &param); err != nil {
+		return fail(c, err)
+	}
+
+	resp, err := provision.Provision.ReplaceUser(c.Param("id"), param)
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusOK, resp)
+}
+
+func PatchUser(c echo.Context) error {
+	var param scim.PatchRequest
+	if err := decode(c, &param); err != nil {
+		return fail(c, err)
+	}
+
+	resp, err := provision.Provision.PatchUser(c.Param("id"), param.Operations)
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusOK, resp)
+}
+
+func DeleteUser(c echo.Context) error {
+	err := provision.Provision.DeleteUser(c.Param("id"))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func ListGroups(c echo.Context) error {
+	startIndex, count := pagination(c)
+	resp, err := provision.Provision.ListGroups(c.QueryParam("filter"), startIndex, count)
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusOK, resp)
+}
+
+func GetGroup(c echo.Context) error {
+	resp, err := provision.Provision.GetGroup(c.Param("id"))
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusOK, resp)
+}
+
+func CreateGroup(c echo.Context) error {
+	var param scim.Group
+	if err := decode(c, &param); err != nil {
+		return fail(c, err)
+	}
+
+	resp, err := provision.Provision.CreateGroup(param)
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusCreated, resp)
+}
+
+func ReplaceGroup(c echo.Context) error {
+	var param scim.Group
+	if err := decode(c, &param); err != nil {
+		return fail(c, err)
+	}
+
+	resp, err := provision.Provision.ReplaceGroup(c.Param("id"), param)
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusOK, resp)
+}
+
+func PatchGroup(c echo.Context) error {
+	var param scim.PatchRequest
+	if err := decode(c, &param); err != nil {
+		return fail(c, err)
+	}
+
+	resp, err := provision.Provision.PatchGroup(c.Param("id"), param.Operations)
+	if err != nil {
+		return fail(c, err)
+	}
+	return respond(c, http.StatusOK, resp)
+}
+
+func DeleteGroup(c echo.Context) error {
+	err := provision.Provision.DeleteGroup(c.Param("id"))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// decode SCIM 请求的 Content-Type 为 application/scim+json，echo 的 Bind 不支持，直接解析
+func decode(c echo.Context, v interface{}) error {
+	err := json.NewDecoder(c.Request().Body).Decode(v)
+	if err != nil {
+		return scim.BadRequest(scim.ScimTypeInvalidValue, "invalid request body: "+err.Error())
+	}
+	return nil
+}
+
+func pagination(c echo.Context) (startIndex, count int) {
+	startIndex, _ = strconv.Atoi(c.QueryParam("startIndex"))
+	count, _ = strconv.Atoi(c.QueryParam("count"))
+	return
+}
+
+func respond(c echo.Context, status int, v interface{}) error {
+	c.Response().Header().Set(echo.HeaderContentType, scim.ContentType)
+	return c.JSON(status, v)
+}
+
+func fail(c echo.Context, err error) error {
+	e, ok := err.(*scim.Error)
+	if !ok {
+		xlog.Error("scim request failed", xlog.String("path", c.Request().URL.Path), xlog.String("err", err.Error()))
+		e = scim.NewError(http.StatusInternalServerError, "", err.Error())
+	}
+	return respond(c, e.StatusCode(), e)
+}
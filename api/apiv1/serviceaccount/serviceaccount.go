@@ -0,0 +1,100 @@
+package serviceaccount
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/serviceaccount"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func List(c *core.Context) error {
+	list, err := serviceaccount.ServiceAccount.List()
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+// Scopes 可用的 scope 列表
+func Scopes(c *core.Context) error {
+	return c.Success(c.WithData(serviceaccount.Scopes()))
+}
+
+// Create 创建服务账号，凭证明文只返回一次
+func Create(c *core.Context) error {
+	var param view.ReqCreateServiceAccount
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := serviceaccount.ServiceAccount.Create(c.GetUser().Uid, param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+func Update(c *core.Context) error {
+	var param view.ReqUpdateServiceAccount
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = serviceaccount.ServiceAccount.Update(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func Delete(c *core.Context) error {
+	var param view.ReqServiceAccountID
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = serviceaccount.ServiceAccount.Delete(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// Rotate 轮换凭证，新凭证明文只返回一次
+func Rotate(c *core.Context) error {
+	var param view.ReqRotateServiceAccount
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := serviceaccount.ServiceAccount.Rotate(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+// RevokeCredential 立即吊销凭证
+func RevokeCredential(c *core.Context) error {
+	var param view.ReqRevokeServiceAccountCredential
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = serviceaccount.ServiceAccount.RevokeCredential(param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
@@ -0,0 +1,49 @@
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/backup"
+	"github.com/douyu/juno/internal/pkg/invoker"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+)
+
+// Backup 导出数据库和 etcd 的备份文件，恢复只能通过 juno-admin --restore 执行
+func Backup(c echo.Context) error {
+	if !user.IsAdmin(c) {
+		return output.JSON(c, output.MsgNoAuth, "只有管理员可以导出备份")
+	}
+
+	var param view.ReqSystemBackup
+	if err := c.Bind(&param); err != nil {
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
+	}
+
+	// 先写入临时文件，导出失败时仍能返回错误信息
+	file, err := ioutil.TempFile("", "juno-backup-*.gz")
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	_, err = backup.Export(c.Request().Context(), backup.Option{
+		DB:   invoker.JunoMysql,
+		Key:  param.Key,
+		Etcd: backup.EtcdClients(),
+	}, file)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
+	filename := fmt.Sprintf("juno_backup_%s.gz", time.Now().Format("20060102150405"))
+	return c.Attachment(file.Name(), filename)
+}
@@ -2,7 +2,9 @@ package system
 
 import (
 	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/user"
 	"github.com/douyu/juno/pkg/auth/authconfig"
+	"github.com/douyu/juno/pkg/auth/oidc"
 	"github.com/douyu/juno/pkg/cfg"
 	"github.com/labstack/echo/v4"
 )
@@ -13,11 +15,31 @@ func Config(c echo.Context) error {
 	for key, oauth := range authconfig.OAuthService.OAuthInfos {
 		enabledOAuths[key] = map[string]string{"name": oauth.Name}
 	}
+	oidcSetting := map[string]interface{}{"enabled": false}
+	if oidc.Provider != nil {
+		oidcSetting = map[string]interface{}{"enabled": true, "name": oidc.Provider.Name()}
+	}
 	viewSetting := map[string]interface{}{
 		"appUrl":           cfg.Cfg.AppURL,
 		"authProxyEnabled": cfg.Cfg.AuthProxyEnabled,
 		"disableLoginForm": cfg.Cfg.Auth.DisableLoginForm,
+		"ldapEnabled":      cfg.Cfg.Auth.LDAP.Enable,
 		"oauth":            enabledOAuths,
+		"oidc":             oidcSetting,
 	}
 	return output.JSON(c, output.MsgOk, "success", viewSetting)
 }
+
+// ConfigReload 重新加载配置文件中运行时可修改的配置，返回有变化的配置名
+func ConfigReload(c echo.Context) error {
+	if !user.IsAdmin(c) {
+		return output.JSON(c, output.MsgNoAuth, "只有管理员可以重新加载配置")
+	}
+
+	changed, err := cfg.Reload()
+	if err != nil {
+		return output.JSON(c, output.MsgErr, "重新加载配置失败:"+err.Error())
+	}
+
+	return output.JSON(c, output.MsgOk, "success", map[string]interface{}{"changed": changed})
+}
@@ -0,0 +1,85 @@
+package tag
+
+import (
+	"strconv"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/tag"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// SetApp 设置应用标签
+func SetApp(c *core.Context) error {
+	var param view.ReqSetAppTags
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	return set(c, db.TagEntityApp, param.AppName, param.Tags)
+}
+
+// SetNode 设置节点标签
+func SetNode(c *core.Context) error {
+	var param view.ReqSetNodeTags
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	return set(c, db.TagEntityNode, param.HostName, param.Tags)
+}
+
+// SetPipeline 设置流水线标签
+func SetPipeline(c *core.Context) error {
+	var param view.ReqSetPipelineTags
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	return set(c, db.TagEntityPipeline, strconv.Itoa(int(param.ID)), param.Tags)
+}
+
+// Get 实体的标签
+func Get(c *core.Context) error {
+	var param view.ReqEntityTags
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	tags, err := tag.Tag.Get(param.EntityType, param.EntityKey)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(tags))
+}
+
+// Suggest 标签自动补全
+func Suggest(c *core.Context) error {
+	var param view.ReqSuggestTags
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := tag.Tag.Suggest(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+func set(c *core.Context, entityType, entityKey string, tags []string) error {
+	list, err := tag.Tag.Set(entityType, entityKey, tags)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
@@ -0,0 +1,142 @@
+package team
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/team"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func List(c *core.Context) error {
+	var param view.ReqListTeam
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, pagination, err := team.Team.List(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"pagination": pagination,
+		"list":       list,
+	}))
+}
+
+func Detail(c *core.Context) error {
+	var param view.ReqTeamDetail
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	resp, err := team.Team.Detail(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+// Mine 当前用户所在的团队
+func Mine(c *core.Context) error {
+	list, err := team.Team.UserTeams(c.GetUser().Uid)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+func Create(c *core.Context) error {
+	var param view.ReqCreateTeam
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = team.Team.Create(c.GetUser().Uid, param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func Update(c *core.Context) error {
+	var param view.ReqUpdateTeam
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = team.Team.Update(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func Delete(c *core.Context) error {
+	var param view.ReqDeleteTeam
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = team.Team.Delete(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func SetMember(c *core.Context) error {
+	var param view.ReqSetTeamMember
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = team.Team.SetMember(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+func RemoveMember(c *core.Context) error {
+	var param view.ReqRemoveTeamMember
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = team.Team.RemoveMember(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// SetApp 设置应用所属团队
+func SetApp(c *core.Context) error {
+	var param view.ReqSetAppTeam
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = team.Team.SetAppTeam(param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/douyu/juno/internal/app/core"
 	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
+	"github.com/douyu/juno/internal/pkg/service/permission"
 	"github.com/douyu/juno/internal/pkg/service/testplatform"
 	"github.com/douyu/juno/internal/pkg/service/user"
 	"github.com/douyu/juno/pkg/model/view"
@@ -14,14 +16,21 @@ func ListPipeline(c *core.Context) (err error) {
 	var params view.ReqListPipeline
 	err = c.Bind(&params)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, "invalid params:"+err.Error())
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params:"+err.Error())
 	}
 
-	pipelines, err := testplatform.ListPipeline(params)
+	// 只返回用户有权访问的机房流水线
+	zones, _, err := permission.ZoneScope.UserZones(c.GetUser())
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
+	pipelines, page, err := testplatform.ListPipeline(params, zones...)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	listquery.SetHeaders(c, page)
 	return c.OutputJSON(output.MsgOk, "success", c.WithData(pipelines))
 }
 
@@ -30,13 +39,13 @@ func CreatePipeline(c *core.Context) (err error) {
 
 	err = c.Bind(&params)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, "invalid params: "+err.Error())
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
 	}
 
 	u := user.GetUser(c)
 	err = testplatform.CreatePipeline(uint(u.Uid), params)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
 	return c.OutputJSON(output.MsgOk, "success")
@@ -47,12 +56,12 @@ func UpdatePipeline(c *core.Context) (err error) {
 
 	err = c.Bind(&params)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, "invalid params: "+err.Error())
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
 	}
 
 	err = testplatform.UpdatePipeline(uint(user.GetUser(c).Uid), params)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
 	return c.OutputJSON(output.MsgOk, "success")
@@ -61,26 +70,32 @@ func UpdatePipeline(c *core.Context) (err error) {
 func RunPipeline(c *core.Context) (err error) {
 	pipelineId, err := strconv.Atoi(c.QueryParam("id"))
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, "invalid pipeline")
+		return c.OutputJSON(output.MsgInvalidParam, "invalid pipeline")
 	}
 
-	err = testplatform.DispatchTask(uint(user.GetUser(c).Uid), uint(pipelineId))
+	// commit 为触发任务的提交，同一提交还在排队的任务会被本次任务取代；
+	// event 为触发任务的事件，如 CI 在合并请求时传入 merge_request，用于阶段的执行条件
+	taskID, superseded, err := testplatform.DispatchTask(c.Request().Context(), uint(user.GetUser(c).Uid), uint(pipelineId),
+		c.QueryParam("commit"), c.QueryParam("event"))
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
-	return c.OutputJSON(output.MsgOk, "success")
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(map[string]interface{}{
+		"task_id":    taskID,
+		"superseded": superseded,
+	}))
 }
 
 func DeletePipeline(c *core.Context) error {
 	pipelineId, err := strconv.Atoi(c.QueryParam("id"))
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, "invalid pipeline")
+		return c.OutputJSON(output.MsgInvalidParam, "invalid pipeline")
 	}
 
-	err = testplatform.DeletePipeline(pipelineId)
+	err = testplatform.DeletePipeline(c.GetUser().Uid, pipelineId)
 	if err != nil {
-		return c.OutputJSON(output.MsgErr, err.Error())
+		return c.OutputError(err)
 	}
 
 	return c.OutputJSON(output.MsgOk, "success")
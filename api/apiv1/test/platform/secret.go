@@ -0,0 +1,71 @@
+package platform
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/testplatform"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+)
+
+// ListSecrets 应用在环境下的流水线密钥，不返回值
+func ListSecrets(c *core.Context) error {
+	var params view.ReqListTestSecret
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	list, err := testplatform.ListSecrets(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(list))
+}
+
+func SaveSecret(c *core.Context) error {
+	var params view.ReqSaveTestSecret
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = testplatform.SaveSecret(uint(c.GetUser().Uid), params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success")
+}
+
+func DeleteSecret(c *core.Context) error {
+	var params view.ReqDeleteTestSecret
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = testplatform.DeleteSecret(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success")
+}
+
+// TaskSecrets worker 开始执行任务时获取任务引用的密钥
+func TaskSecrets(c echo.Context) error {
+	var params view.ReqTaskSecrets
+	err := c.Bind(&params)
+	if err != nil {
+		return output.JSON(c, output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	secrets, err := testplatform.TaskSecrets(params.TaskID)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
+	return output.JSON(c, output.MsgOk, "success", secrets)
+}
@@ -0,0 +1,67 @@
+package platform
+
+import (
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/testplatform"
+	"github.com/douyu/juno/pkg/graceful"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+// TaskEventStream worker 通过 WebSocket 长连接上报任务事件，阶段执行中的日志按行实时推送。
+// 每条事件处理完成后返回确认，处理方式与 TaskStepStatusUpdate 相同；连接断开时 worker 改用 HTTP 上报
+func TaskEventStream(c echo.Context) error {
+	// worker 不是浏览器，不校验 Origin，认证由服务账号中间件完成
+	server := websocket.Server{
+		Handler: serveTaskEventStream,
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// serveTaskEventStream 按接收顺序逐条处理，同一阶段的日志不会乱序。开始退出时主动断开，worker 重连到其他实例
+func serveTaskEventStream(conn *websocket.Conn) {
+	defer graceful.Track()()
+	defer conn.Close()
+
+	messages := make(chan view.TestTaskStreamMessage)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(messages)
+		for {
+			var msg view.TestTaskStreamMessage
+			if err := websocket.JSON.Receive(conn, &msg); err != nil {
+				return
+			}
+			select {
+			case messages <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		var msg view.TestTaskStreamMessage
+		var ok bool
+		select {
+		case <-graceful.Closing():
+			return
+		case msg, ok = <-messages:
+			if !ok {
+				return
+			}
+		}
+
+		ack := view.TestTaskStreamAck{Seq: msg.Seq, Code: output.MsgOk, Msg: "success"}
+		if err := testplatform.UpdateTaskStatus(msg.Event); err != nil {
+			// 任务已被取代或取消时返回冲突，worker 据此跳过或终止任务
+			ack.Code, ack.Msg = output.Resolve(err)
+		}
+		if err := websocket.JSON.Send(conn, ack); err != nil {
+			return
+		}
+	}
+}
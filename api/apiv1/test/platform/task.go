@@ -1,8 +1,13 @@
 package platform
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+
 	"github.com/douyu/juno/internal/app/core"
 	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/permission"
 	"github.com/douyu/juno/internal/pkg/service/testplatform"
 	"github.com/douyu/juno/pkg/model/view"
 	"github.com/labstack/echo/v4"
@@ -17,12 +22,29 @@ func TaskStepStatusUpdate(c echo.Context) error {
 
 	err = testplatform.UpdateTaskStatus(params)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		// 任务已被取代时返回冲突，worker 据此跳过任务
+		return output.JSONError(c, err)
 	}
 
 	return output.JSON(c, output.MsgOk, "success")
 }
 
+// PrepareTaskArtifact worker 上传制品前申请对象存储的上传地址
+func PrepareTaskArtifact(c echo.Context) error {
+	var params view.ReqPrepareTaskArtifact
+	err := c.Bind(&params)
+	if err != nil {
+		return output.JSON(c, output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	upload, err := testplatform.PrepareTaskArtifact(params)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
+	return output.JSON(c, output.MsgOk, "success", upload)
+}
+
 func TaskList(c *core.Context) error {
 	var params view.ReqQueryTestTasks
 	err := c.Bind(&params)
@@ -55,3 +77,259 @@ func TaskSteps(c *core.Context) error {
 
 	return c.OutputJSON(output.MsgOk, "success", c.WithData(steps))
 }
+
+func TaskInfo(c *core.Context) error {
+	var params view.ReqQueryTaskItem
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	task, err := testplatform.TaskInfo(params.TaskID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(task))
+}
+
+// CancelTask 取消排队中或执行中的任务
+func CancelTask(c *core.Context) error {
+	taskID, err := strconv.Atoi(c.QueryParam("task_id"))
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid task")
+	}
+
+	err = testplatform.CancelTask(uint(taskID), c.GetUser().Username)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success")
+}
+
+// TaskArtifacts 任务的制品列表
+func TaskArtifacts(c *core.Context) error {
+	var params view.ReqQueryTaskItem
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	list, err := testplatform.TaskArtifacts(params.TaskID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(list))
+}
+
+// TaskArtifact 查看制品内容，HTML 报告在沙箱中展示，禁止执行脚本和访问 Juno 的登录态。
+// 保存在对象存储中的制品跳转到存储的预签名地址
+func TaskArtifact(c *core.Context) error {
+	var params view.ReqQueryTaskArtifact
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	artifact, err := testplatform.TaskArtifact(params.TaskID, params.Name)
+	if err != nil {
+		return c.OutputError(err)
+	}
+	if artifact.StorageKey != "" {
+		url, err := testplatform.ArtifactDownloadURL(artifact)
+		if err != nil {
+			return c.OutputError(err)
+		}
+		return c.Redirect(http.StatusFound, url)
+	}
+
+	contentType := artifact.ContentType
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+	c.Response().Header().Set("Content-Security-Policy", "sandbox")
+	c.Response().Header().Set("X-Content-Type-Options", "nosniff")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", artifact.Name))
+	return c.Blob(http.StatusOK, contentType, artifact.Content)
+}
+
+// TaskVulnerabilities 任务扫描发现的依赖漏洞
+func TaskVulnerabilities(c *core.Context) error {
+	var params view.ReqQueryTaskItem
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	list, err := testplatform.TaskVulnerabilities(params.TaskID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(list))
+}
+
+// TaskBuildReports 任务的构建报告
+func TaskBuildReports(c *core.Context) error {
+	var params view.ReqQueryTaskItem
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	list, err := testplatform.TaskBuildReports(params.TaskID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(list))
+}
+
+// BuildReportTrend 流水线最近的构建大小、耗时趋势
+func BuildReportTrend(c *core.Context) error {
+	var params view.ReqBuildReportTrend
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	list, err := testplatform.BuildReportTrend(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(list))
+}
+
+// TaskCoverage 任务的覆盖率和各个包的覆盖率
+func TaskCoverage(c *core.Context) error {
+	var params view.ReqQueryTaskItem
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	coverage, err := testplatform.TaskCoverageDetail(params.TaskID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(coverage))
+}
+
+// CoverageTrend 应用最近的覆盖率趋势
+func CoverageTrend(c *core.Context) error {
+	var params view.ReqCoverageTrend
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	list, err := testplatform.CoverageTrend(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(list))
+}
+
+// TaskBenchmarks 任务的基准测试结果及与基准的对比
+func TaskBenchmarks(c *core.Context) error {
+	var params view.ReqQueryTaskItem
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	list, err := testplatform.TaskBenchmarks(params.TaskID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(list))
+}
+
+// BenchmarkHistory 流水线最近的基准测试结果
+func BenchmarkHistory(c *core.Context) error {
+	var params view.ReqBenchmarkHistory
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	list, err := testplatform.BenchmarkHistory(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(list))
+}
+
+// CompareTasks 对比同一流水线的两次任务
+func CompareTasks(c *core.Context) error {
+	var params view.ReqCompareTasks
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	result, err := testplatform.CompareTasks(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(result))
+}
+
+// SearchLogs 搜索应用的历史构建日志
+func SearchLogs(c *core.Context) error {
+	var params view.ReqSearchLogs
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	// 只返回用户有权访问的机房任务
+	zones, _, err := permission.ZoneScope.UserZones(c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	hits, err := testplatform.SearchLogs(params, zones...)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(hits))
+}
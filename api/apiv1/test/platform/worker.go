@@ -4,6 +4,8 @@ import (
 	"github.com/douyu/juno/internal/app/core"
 	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
 	"github.com/douyu/juno/internal/pkg/service/testplatform"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/view"
 )
 
 func WorkerZones(c *core.Context) (err error) {
@@ -14,3 +16,169 @@ func WorkerZones(c *core.Context) (err error) {
 
 	return c.OutputJSON(output.MsgOk, "", c.WithData(zones))
 }
+
+// WorkerNodes 在线的 worker 节点
+func WorkerNodes(c *core.Context) error {
+	var params view.ReqWorkerNodes
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	nodes, err := testplatform.WorkerNodes(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(nodes))
+}
+
+// WorkerQueue worker 节点的等待队列
+func WorkerQueue(c *core.Context) error {
+	var params view.ReqWorkerQueue
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	queue, err := testplatform.WorkerQueue(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(queue))
+}
+
+// RemoveQueuedTask 删除 worker 队列中等待的任务
+func RemoveQueuedTask(c *core.Context) error {
+	var params view.ReqWorkerQueueItem
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = testplatform.RemoveQueuedTask(params, user.GetUser(c).Username)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success")
+}
+
+// MoveQueuedTaskToFront 将 worker 队列中等待的任务移到队首
+func MoveQueuedTaskToFront(c *core.Context) error {
+	var params view.ReqWorkerQueueItem
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = testplatform.MoveQueuedTaskToFront(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success")
+}
+
+// PauseWorker 暂停 worker 节点消费队列
+func PauseWorker(c *core.Context) error {
+	var params view.ReqWorkerNode
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = testplatform.PauseWorker(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success")
+}
+
+// ResumeWorker 恢复 worker 节点消费队列
+func ResumeWorker(c *core.Context) error {
+	var params view.ReqWorkerNode
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = testplatform.ResumeWorker(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success")
+}
+
+// WorkerQueueStats 各机房的排队情况，供外部扩缩容系统查询
+func WorkerQueueStats(c *core.Context) error {
+	stats, err := testplatform.WorkerQueueStats()
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(stats))
+}
+
+// DrainWorker 不再向 worker 下发新任务，返回的 idle 为 true 时可以下线
+func DrainWorker(c *core.Context) error {
+	return drainWorker(c, testplatform.DrainWorker)
+}
+
+// UndrainWorker 恢复向 worker 下发任务
+func UndrainWorker(c *core.Context) error {
+	return drainWorker(c, testplatform.UndrainWorker)
+}
+
+// WorkerDrainStatus worker 的 drain 状态
+func WorkerDrainStatus(c *core.Context) error {
+	return drainWorker(c, testplatform.WorkerDrainStatus)
+}
+
+func drainWorker(c *core.Context, fn func(view.ReqDrainWorker) (view.WorkerDrainStatus, error)) error {
+	var params view.ReqDrainWorker
+	err := c.Bind(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = c.Validate(&params)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	status, err := fn(params)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.OutputJSON(output.MsgOk, "success", c.WithData(status))
+}
@@ -0,0 +1,48 @@
+package user
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/i18n"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// Language 当前用户的语言设置
+func Language(c *core.Context) error {
+	lang, err := user.User.Language(c.GetUser().Uid)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(view.RespLanguage{
+		Language:  lang,
+		Current:   output.Lang(c),
+		Supported: i18n.Supported(),
+	}))
+}
+
+// SetLanguage 设置当前用户的语言，为空时按浏览器语言
+func SetLanguage(c *core.Context) error {
+	var param view.ReqSetLanguage
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.User.SetLanguage(c.GetUser().Uid, param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// I18nMessages 当前语言的译文，key 为 zh-CN 原文，前端据此翻译接口返回的枚举名称等文本
+func I18nMessages(c *core.Context) error {
+	lang := output.Lang(c)
+	return c.Success(c.WithData(map[string]interface{}{
+		"language": lang,
+		"messages": i18n.Messages(lang),
+	}))
+}
@@ -40,13 +40,13 @@ func GenStateString() (string, error) {
 
 func LoginOauth(c echo.Context) error {
 	if authconfig.OAuthService == nil {
-		return output.JSON(c, 1, "oauth not enabled")
+		return output.JSON(c, output.MsgOAuthFailed, "oauth not enabled")
 	}
 
 	name := c.Param("oauth")
 	connect, ok := social.SocialMap[name]
 	if !ok {
-		return output.JSON(c, 2, fmt.Sprintf("No OAuth with name %s configured", name))
+		return output.JSON(c, output.MsgOAuthFailed, fmt.Sprintf("No OAuth with name %s configured", name))
 	}
 	state := c.QueryParam("state")
 	errorParam := c.QueryParam("error")
@@ -62,7 +62,7 @@ func LoginOauth(c echo.Context) error {
 		state, err := GenStateString()
 		if err != nil {
 			xlog.Error("Generating state string failed", zap.Error(err))
-			return output.JSON(c, 3, "An internal error occurred")
+			return output.JSON(c, output.MsgInternal, output.Message(output.MsgInternal))
 		}
 
 		hashedState := hashStatecode(state, authconfig.OAuthService.OAuthInfos[name].ClientSecret)
@@ -85,7 +85,7 @@ func LoginOauth(c echo.Context) error {
 
 	cookie, err := c.Cookie(OauthStateCookieName)
 	if err != nil {
-		return output.JSON(c, 4, "system error: "+err.Error())
+		return output.JSON(c, output.MsgOAuthFailed, "system error: "+err.Error())
 	}
 	cookieState, _ := url.QueryUnescape(cookie.Value)
 
@@ -101,13 +101,13 @@ func LoginOauth(c echo.Context) error {
 	})
 
 	if cookieState == "" {
-		return output.JSON(c, 5, "login.OAuthLogin(missing saved state)")
+		return output.JSON(c, output.MsgOAuthFailed, "login.OAuthLogin(missing saved state)")
 	}
 
 	queryState := hashStatecode(state, authconfig.OAuthService.OAuthInfos[name].ClientSecret)
 	xlog.Info("state check", zap.Any("queryState", queryState), zap.Any("cookieState", cookieState))
 	if cookieState != queryState {
-		return output.JSON(c, 6, "login.OAuthLogin(state mismatch)")
+		return output.JSON(c, output.MsgOAuthFailed, "login.OAuthLogin(state mismatch)")
 	}
 
 	// handle call back
@@ -125,7 +125,7 @@ func LoginOauth(c echo.Context) error {
 		cert, err := tls.LoadX509KeyPair(authconfig.OAuthService.OAuthInfos[name].TlsClientCert, authconfig.OAuthService.OAuthInfos[name].TlsClientKey)
 		if err != nil {
 			xlog.Error("Failed to setup TlsClientCert", zap.String("oauth", name), zap.Error(err))
-			return output.JSON(c, 7, "login.OAuthLogin(Failed to setup TlsClientCert)")
+			return output.JSON(c, output.MsgOAuthFailed, "login.OAuthLogin(Failed to setup TlsClientCert)")
 		}
 
 		tr.TLSClientConfig.Certificates = append(tr.TLSClientConfig.Certificates, cert)
@@ -135,7 +135,7 @@ func LoginOauth(c echo.Context) error {
 		caCert, err := ioutil.ReadFile(authconfig.OAuthService.OAuthInfos[name].TlsClientCa)
 		if err != nil {
 			xlog.Error("Failed to setup TlsClientCa", zap.String("oauth", name), zap.Error(err))
-			return output.JSON(c, 8, "login.OAuthLogin(Failed to setup TlsClientCa)")
+			return output.JSON(c, output.MsgOAuthFailed, "login.OAuthLogin(Failed to setup TlsClientCa)")
 		}
 		caCertPool := x509.NewCertPool()
 		caCertPool.AppendCertsFromPEM(caCert)
@@ -148,7 +148,7 @@ func LoginOauth(c echo.Context) error {
 	// get token from provider
 	token, err := connect.Exchange(oauthCtx, code)
 	if err != nil {
-		return output.JSON(c, 9, "login.OAuthLogin(NewTransportWithCode)")
+		return output.JSON(c, output.MsgOAuthFailed, "login.OAuthLogin(NewTransportWithCode)")
 	}
 	// token.TokenType was defaulting to "bearer", which is out of spec, so we explicitly set to "Bearer"
 	token.TokenType = "Bearer"
@@ -165,7 +165,7 @@ func LoginOauth(c echo.Context) error {
 			// todo
 			return c.Redirect(http.StatusFound, cfg.Cfg.AppSubURL+"/login")
 		} else {
-			return output.JSON(c, 10, fmt.Sprintf("login.OAuthLogin(get info from %s)", name), err.Error())
+			return output.JSON(c, output.MsgOAuthFailed, fmt.Sprintf("login.OAuthLogin(get info from %s)", name), err.Error())
 		}
 	}
 
@@ -194,13 +194,16 @@ func LoginOauth(c echo.Context) error {
 	// create or update oauth user
 	err = user.User.CreateOrUpdateOauthUser(mysqlUser)
 	if err != nil {
-		return output.JSON(c, 11, "create or update oauth user error", err.Error())
+		return output.JSON(c, output.MsgOAuthFailed, "create or update oauth user error", err.Error())
 	}
 	xlog.Debug("OAuthLogin got user info", zap.Any("mysqlUserInfo", mysqlUser))
 
-	err = user.Session.Save(c, mysqlUser)
+	pending, err := completeLogin(c, mysqlUser)
 	if err != nil {
-		return output.JSON(c, 12, "create or update oauth user error", err.Error())
+		return output.JSON(c, output.MsgOAuthFailed, "create or update oauth user error", err.Error())
+	}
+	if pending {
+		return c.Redirect(http.StatusFound, cfg.Cfg.AppSubURL+twoFactorLoginPath)
 	}
 
 	toURULCookie, err := c.Cookie("redirect_juno_to")
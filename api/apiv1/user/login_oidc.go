@@ -0,0 +1,123 @@
+package user
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/permission"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/auth/oidc"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	OIDCStateCookieName = "oidc_state"
+)
+
+// oidcState 授权请求时写入 cookie，回调时校验 state 并取回 nonce、code_verifier
+type oidcState struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// LoginOIDC 无 code 时跳转到 IdP 授权，有 code 时处理回调
+func LoginOIDC(c echo.Context) error {
+	if oidc.Provider == nil {
+		return output.JSON(c, output.MsgOAuthFailed, "oidc not enabled")
+	}
+
+	if errorParam := c.QueryParam("error"); errorParam != "" {
+		xlog.Error("oidc login failed", xlog.String("error", errorParam), xlog.String("errorDesc", c.QueryParam("error_description")))
+		return c.Redirect(http.StatusFound, cfg.Cfg.AppSubURL+"/user/login")
+	}
+
+	code := c.QueryParam("code")
+	if code == "" {
+		return redirectOIDC(c)
+	}
+
+	cookie, err := c.Cookie(OIDCStateCookieName)
+	if err != nil {
+		return output.JSON(c, output.MsgOAuthFailed, "login.OIDCLogin(missing saved state)")
+	}
+	setOIDCStateCookie(c, "", -1)
+
+	var saved oidcState
+	data, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err == nil {
+		err = json.Unmarshal(data, &saved)
+	}
+	if err != nil || saved.State == "" || saved.State != c.QueryParam("state") {
+		return output.JSON(c, output.MsgOAuthFailed, "login.OIDCLogin(state mismatch)")
+	}
+
+	userInfo, err := oidc.Provider.Exchange(c.Request().Context(), code, saved.Nonce, saved.CodeVerifier)
+	if err != nil {
+		xlog.Error("oidc exchange failed", xlog.String("err", err.Error()))
+		return output.JSON(c, output.MsgOAuthFailed, "login.OIDCLogin(exchange failed)")
+	}
+
+	u, userGroup, err := user.User.LoginOIDC(userInfo)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
+	err = permission.UserGroup.ChangeUserGroup(view.ReqChangeUserGroup{
+		UID:    uint(u.Uid),
+		Groups: []string{userGroup},
+	})
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
+	pending, err := completeLogin(c, &u)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	if pending {
+		return c.Redirect(http.StatusFound, cfg.Cfg.AppSubURL+twoFactorLoginPath)
+	}
+
+	return c.Redirect(http.StatusFound, cfg.Cfg.AppSubURL+"/")
+}
+
+func redirectOIDC(c echo.Context) error {
+	var (
+		state oidcState
+		err   error
+	)
+	for _, s := range []*string{&state.State, &state.Nonce, &state.CodeVerifier} {
+		*s, err = oidc.RandomString()
+		if err != nil {
+			return output.JSON(c, output.MsgInternal, "An internal error occurred")
+		}
+	}
+
+	authURL, err := oidc.Provider.AuthCodeURL(c.Request().Context(), state.State, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		xlog.Error("oidc auth url failed", xlog.String("err", err.Error()))
+		return output.JSON(c, output.MsgOAuthFailed, "login.OIDCLogin(discovery failed)")
+	}
+
+	data, _ := json.Marshal(state)
+	setOIDCStateCookie(c, base64.RawURLEncoding.EncodeToString(data), cfg.Cfg.Auth.OauthStateCookieMaxAge)
+
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+func setOIDCStateCookie(c echo.Context, value string, maxAge int) {
+	c.SetCookie(&http.Cookie{
+		Name:     OIDCStateCookieName,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,84 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+)
+
+// NotifyEmail 当前用户接收通知的邮箱
+func NotifyEmail(c *core.Context) error {
+	resp, err := user.User.NotifyEmail(c.GetUser().Uid)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+// SetNotifyEmail 设置当前用户接收通知的邮箱
+func SetNotifyEmail(c *core.Context) error {
+	var param view.ReqSetNotifyEmail
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.User.SetNotifyEmail(c.GetUser().Uid, param.Email)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// NotifyPref 当前用户的通知偏好
+func NotifyPref(c *core.Context) error {
+	resp, err := user.User.NotifyPref(c.GetUser().Uid)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+// SetNotifyPref 设置当前用户的通知偏好
+func SetNotifyPref(c *core.Context) error {
+	var param view.NotifyPref
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.User.SetNotifyPref(c.GetUser().Uid, param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// Unsubscribe 邮件退订链接，通过链接中的凭证识别用户，不需要登录
+func Unsubscribe(c echo.Context) error {
+	var param view.ReqNotifyUnsubscribe
+	err := c.Bind(&param)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	username, err := user.User.Unsubscribe(param.Token, param.Event)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	msg := fmt.Sprintf("%s 已退订全部邮件通知，可在 Juno 个人设置中重新开启", username)
+	if param.Event != "" {
+		msg = fmt.Sprintf("%s 已退订 %s 事件通知，可在 Juno 个人设置中重新开启", username, param.Event)
+	}
+	return c.String(http.StatusOK, msg)
+}
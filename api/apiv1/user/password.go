@@ -0,0 +1,83 @@
+package user
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+// ChangeExpiredPassword 密码过期的用户在登录前修改密码，修改成功后需要重新登录
+func ChangeExpiredPassword(c *core.Context) error {
+	var param view.ReqChangeExpiredPassword
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.User.CheckLoginLock(param.Username)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	u := user.User.GetUserByName(param.Username)
+	if u.Uid == 0 || u.Password == "" {
+		recordLoginFailure(c, param.Username)
+		return c.OutputJSON(output.MsgNeedLogin, "账号或密码错误")
+	}
+
+	err = user.User.ChangePassword(u.Uid, param.OldPassword, param.NewPassword, "")
+	if err != nil {
+		if err == user.ErrOldPasswordWrong {
+			recordLoginFailure(c, param.Username)
+			return c.OutputJSON(output.MsgNeedLogin, "账号或密码错误")
+		}
+		return c.OutputError(err)
+	}
+	clearLoginFailures(param.Username)
+
+	return c.Success()
+}
+
+// LoginLockList 管理员查看登录失败与锁定记录
+func LoginLockList(c *core.Context) error {
+	list, err := user.User.LoginLockList()
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+// UnlockLogin 管理员解除账号的登录锁定
+func UnlockLogin(c *core.Context) error {
+	var param view.ReqUnlockLogin
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.User.ClearLoginFailures(param.Username)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	xlog.Info("login unlocked", xlog.String("username", param.Username), xlog.String("operator", c.GetUser().Username))
+	return c.Success()
+}
+
+func recordLoginFailure(c echo.Context, username string) {
+	err := user.User.RecordLoginFailure(username, c.RealIP())
+	if err != nil {
+		xlog.Error("record login failure failed", xlog.String("username", username), xlog.String("err", err.Error()))
+	}
+}
+
+func clearLoginFailures(username string) {
+	err := user.User.ClearLoginFailures(username)
+	if err != nil {
+		xlog.Error("clear login failures failed", xlog.String("username", username), xlog.String("err", err.Error()))
+	}
+}
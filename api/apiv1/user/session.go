@@ -0,0 +1,108 @@
+package user
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// SessionList 当前用户的登录会话列表
+func SessionList(c *core.Context) error {
+	list, err := user.Session.List(c, c.GetUser().Uid)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+// SessionRevoke 吊销当前用户的某个会话
+func SessionRevoke(c *core.Context) error {
+	var param view.ReqRevokeUserSession
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.Session.Revoke(c.GetUser().Uid, param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// SessionRevokeOthers 吊销当前用户除当前会话外的所有会话
+func SessionRevokeOthers(c *core.Context) error {
+	err := user.Session.RevokeAll(c.GetUser().Uid, user.Session.CurrentID(c))
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// ChangePassword 修改密码，其他设备上的会话会被吊销
+func ChangePassword(c *core.Context) error {
+	var param view.ReqChangePassword
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.User.ChangePassword(c.GetUser().Uid, param.OldPassword, param.NewPassword, user.Session.CurrentID(c))
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// UserSessionList 管理员查看用户的登录会话
+func UserSessionList(c *core.Context) error {
+	var param view.ReqListUserSession
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	list, err := user.Session.List(c, param.Uid)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(list))
+}
+
+// UserSessionRevoke 管理员吊销用户的某个会话
+func UserSessionRevoke(c *core.Context) error {
+	var param view.ReqRevokeUserSession
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.Session.Revoke(0, param.ID)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// UserSessionRevokeAll 管理员吊销用户的全部会话
+func UserSessionRevokeAll(c *core.Context) error {
+	var param view.ReqRevokeAllUserSession
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.Session.RevokeAll(param.Uid, "")
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
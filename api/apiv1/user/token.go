@@ -0,0 +1,57 @@
+package user
+
+import (
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/personaltoken"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// TokenList 当前用户的 API Token 列表
+func TokenList(c *core.Context) error {
+	u := c.GetUser()
+
+	list, err := personaltoken.PersonalToken.List(u.Uid)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(map[string]interface{}{
+		"list":   list,
+		"scopes": personaltoken.Scopes(),
+	}))
+}
+
+// TokenCreate 创建 API Token，Token 明文只返回一次
+func TokenCreate(c *core.Context) error {
+	var param view.ReqCreatePersonalToken
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	u := c.GetUser()
+	resp, err := personaltoken.PersonalToken.Create(u.Uid, param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+// TokenRevoke 吊销 API Token
+func TokenRevoke(c *core.Context) error {
+	var param view.ReqRevokePersonalToken
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	u := c.GetUser()
+	err = personaltoken.PersonalToken.Revoke(u.Uid, param)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
@@ -0,0 +1,215 @@
+package user
+
+import (
+	"errors"
+
+	"github.com/douyu/juno/internal/app/core"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+// 第三方登录后需要两步验证时跳转的前端页面
+const twoFactorLoginPath = "/user/login?two_factor=1"
+
+// completeLogin 保存登录会话，开启了两步验证的用户先进入待验证状态，已停用的用户不能登录
+func completeLogin(c echo.Context, u *db.User) (pending bool, err error) {
+	if u.State == db.UserStateDisabled {
+		return false, user.ErrUserDisabled
+	}
+
+	enabled, err := user.User.TOTPEnabled(u.Uid)
+	if err != nil {
+		return
+	}
+
+	if enabled {
+		return true, user.Session.SavePending(c, u.Uid)
+	}
+
+	return false, user.Session.Save(c, u)
+}
+
+// markTwoFactorVerified 在当前会话中记录两步验证时间，API Token 认证的请求没有会话
+func markTwoFactorVerified(c *core.Context, u *db.User) error {
+	if _, ok := c.Get(user.ContextTokenUser).(*db.User); ok {
+		return nil
+	}
+
+	user.Session.MarkTwoFactorVerified(c)
+	return user.Session.Save(c, u)
+}
+
+// verifyTOTP 校验验证码，账号已锁定时不校验。验证码错误计入登录失败次数，与密码错误共用锁定策略
+func verifyTOTP(c echo.Context, u *db.User, code string) error {
+	err := user.User.CheckLoginLock(u.Username)
+	if err != nil {
+		return err
+	}
+
+	err = user.User.VerifyTOTP(u.Uid, code)
+	if errors.Is(err, user.ErrTOTPInvalidCode) {
+		recordLoginFailure(c, u.Username)
+	}
+	return err
+}
+
+// LoginTOTP 密码校验通过后，使用验证码或备用码完成登录
+func LoginTOTP(c *core.Context) error {
+	var param view.ReqTOTPCode
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	uid := user.Session.PendingUid(c)
+	if uid == 0 {
+		return c.OutputJSON(output.MsgNeedLogin, "登录已过期，请重新登录")
+	}
+
+	u := user.User.GetUserByUID(uid)
+	err = verifyTOTP(c, &u, param.Code)
+	if err != nil {
+		if errors.Is(err, user.ErrTOTPInvalidCode) {
+			invalidated, serr := user.Session.RecordPendingFailure(c)
+			if serr != nil {
+				xlog.Error("record pending totp failure failed", xlog.Int("uid", uid), xlog.FieldErr(serr))
+			}
+			if invalidated {
+				return c.OutputJSON(output.MsgNeedLogin, "验证码错误次数过多，请重新登录")
+			}
+		}
+		return c.OutputError(err)
+	}
+	// 密码校验通过时不清除失败次数，两步验证也完成后才清除
+	clearLoginFailures(u.Username)
+
+	user.Session.MarkTwoFactorVerified(c)
+	err = user.Session.Save(c, &u)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(u))
+}
+
+// TOTPStatus 当前用户的两步验证状态
+func TOTPStatus(c *core.Context) error {
+	status, err := user.User.TOTPStatus(c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(status))
+}
+
+// TOTPEnroll 生成两步验证密钥
+func TOTPEnroll(c *core.Context) error {
+	resp, err := user.User.EnrollTOTP(c.GetUser())
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(resp))
+}
+
+// TOTPActivate 校验验证码并开启两步验证
+func TOTPActivate(c *core.Context) error {
+	var param view.ReqTOTPCode
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	u := c.GetUser()
+	codes, err := user.User.ActivateTOTP(u.Uid, param.Code)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	_ = markTwoFactorVerified(c, u)
+
+	return c.Success(c.WithData(view.RespTOTPBackupCodes{BackupCodes: codes}))
+}
+
+// TOTPVerify 校验验证码，用于执行敏感操作前的二次确认
+func TOTPVerify(c *core.Context) error {
+	var param view.ReqTOTPCode
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	u := c.GetUser()
+	err = verifyTOTP(c, u, param.Code)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	err = markTwoFactorVerified(c, u)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// TOTPDisable 关闭两步验证
+func TOTPDisable(c *core.Context) error {
+	var param view.ReqTOTPCode
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	u := c.GetUser()
+	err = user.User.CheckLoginLock(u.Username)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	err = user.User.DisableTOTP(u, param.Code)
+	if err != nil {
+		if errors.Is(err, user.ErrTOTPInvalidCode) {
+			recordLoginFailure(c, u.Username)
+		}
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
+
+// TOTPBackupCodes 重新生成备用码，旧的备用码失效
+func TOTPBackupCodes(c *core.Context) error {
+	var param view.ReqTOTPCode
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	codes, err := user.User.RegenerateBackupCodes(c.GetUser().Uid, param.Code)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success(c.WithData(view.RespTOTPBackupCodes{BackupCodes: codes}))
+}
+
+// TOTPReset 管理员重置用户的两步验证
+func TOTPReset(c *core.Context) error {
+	var param view.ReqResetTOTP
+	err := c.Bind(&param)
+	if err != nil {
+		return c.OutputJSON(output.MsgInvalidParam, err.Error())
+	}
+
+	err = user.User.ResetTOTP(param.Uid)
+	if err != nil {
+		return c.OutputError(err)
+	}
+
+	return c.Success()
+}
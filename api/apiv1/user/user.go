@@ -6,9 +6,15 @@ import (
 
 	"github.com/douyu/juno/internal/app/core"
 	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
 	"github.com/douyu/juno/internal/pkg/service/appevent"
+	"github.com/douyu/juno/internal/pkg/service/permission"
 	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/auth/ldap"
+	"github.com/douyu/juno/pkg/cfg"
 	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -21,16 +27,18 @@ func List(c echo.Context) error {
 	reqModel := ReqUserList{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
-	list, total, err := user.User.GetList(db.User{}, reqModel.CurrentPage, reqModel.PageSize, "update_time desc")
+	list, page, err := user.User.GetList(db.User{}, reqModel.ListQuery)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
+	listquery.SetHeaders(c, page)
 	return output.JSON(c, output.MsgOk, "success", map[string]interface{}{
-		"total": total,
-		"list":  list,
+		"total":      page.Total,
+		"pagination": page,
+		"list":       list,
 	})
 }
 
@@ -43,21 +51,27 @@ func Create(c echo.Context) error {
 	err = c.Bind(&reqModel)
 
 	if reqModel.Username == "" || reqModel.Password == "" {
-		return output.JSON(c, output.MsgErr, "参数异常")
+		return output.JSON(c, output.MsgInvalidParam, "参数异常")
 	}
 
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
+
+	err = user.ValidatePassword(reqModel.Password)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
 	hash, err := bcrypt.GenerateFromPassword([]byte(reqModel.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 
 	reqModel.Password = string(hash)
 	err = user.User.Create(&reqModel.User)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	meta, _ := json.Marshal(reqModel)
 	appevent.AppEvent.UserCreateEvent(string(meta), user.Session.Read(c))
@@ -72,7 +86,7 @@ func Update(c echo.Context) error {
 	reqModel := ReqUserUpdate{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 
 	err = user.User.Update(reqModel.User.Uid, &db.User{
@@ -80,7 +94,7 @@ func Update(c echo.Context) error {
 		Access:     reqModel.Access,
 	})
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	reqModel.Username = user.User.GetNameByUID(reqModel.User.Uid)
 	meta, _ := json.Marshal(reqModel)
@@ -96,13 +110,13 @@ func Delete(c echo.Context) error {
 	reqModel := ReqUserUpdate{}
 	err = c.Bind(&reqModel)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSON(c, output.MsgInvalidParam, err.Error())
 	}
 	reqModel.Username = user.User.GetNameByUID(reqModel.User.Uid)
 
 	err = user.User.Delete(reqModel.User)
 	if err != nil {
-		return output.JSON(c, output.MsgErr, err.Error())
+		return output.JSONError(c, err)
 	}
 	meta, _ := json.Marshal(reqModel)
 	appevent.AppEvent.UserDeleteEvent(string(meta), user.Session.Read(c))
@@ -113,7 +127,7 @@ func Delete(c echo.Context) error {
 func Info(c *core.Context) error {
 	u := user.GetUser(c)
 	if !u.IsLogin() {
-		return c.OutputJSON(output.MsgErr, "err")
+		return c.OutputJSON(output.MsgNeedLogin, "err")
 	}
 	return c.OutputJSON(output.MsgOk, "", c.WithData(u))
 }
@@ -128,15 +142,71 @@ func Login(c echo.Context) error {
 	var data login
 	_ = c.Bind(&data)
 	// TODO 三种登录方式：账号密码、header头、gitlab oauth2
+	err := user.User.CheckLoginLock(data.Username)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
 	u := user.User.GetUserByName(data.Username)
-	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(data.Password))
+	err = bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(data.Password))
 	if err != nil {
-		return output.JSON(c, output.MsgErr, "账号或密码错误", "")
+		if !cfg.Cfg.Auth.LDAP.Enable {
+			recordLoginFailure(c, data.Username)
+			return output.JSON(c, output.MsgNeedLogin, "账号或密码错误", "")
+		}
+
+		// 本地账号校验失败，使用 LDAP 校验
+		return loginLDAP(c, data)
+	}
+
+	expired, err := user.User.PasswordExpired(&u)
+	if err != nil {
+		return output.JSONError(c, err)
 	}
-	user.Session.Save(c, &u)
+	if expired {
+		return output.JSON(c, output.MsgPasswordExpired, user.ErrPasswordExpired.Error(), "")
+	}
+
+	return finishLogin(c, &u)
+}
+
+// finishLogin 账号密码校验通过后保存会话。需要两步验证时保留失败次数，验证码错误继续累计
+func finishLogin(c echo.Context, u *db.User) error {
+	pending, err := completeLogin(c, u)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	if pending {
+		return output.JSON(c, output.MsgNeedTwoFactor, "请输入两步验证码", "")
+	}
+	clearLoginFailures(u.Username)
 	return output.JSON(c, output.MsgOk, "", u)
 }
 
+func loginLDAP(c echo.Context, data login) error {
+	u, userGroup, err := user.User.LoginLDAP(data.Username, data.Password)
+	if err != nil {
+		xlog.Warn("login ldap failed", xlog.String("username", data.Username), xlog.String("err", err.Error()))
+		if err == ldap.ErrInvalidCredentials {
+			recordLoginFailure(c, data.Username)
+		}
+		if err == ldap.ErrInvalidCredentials || err == ldap.ErrNoGroupMatched || err == user.ErrUsernameConflict {
+			return output.JSONError(c, err)
+		}
+		return output.JSON(c, output.MsgNeedLogin, "LDAP登录失败", "")
+	}
+
+	err = permission.UserGroup.ChangeUserGroup(view.ReqChangeUserGroup{
+		UID:    uint(u.Uid),
+		Groups: []string{userGroup},
+	})
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+
+	return finishLogin(c, &u)
+}
+
 func Logout(c echo.Context) error {
 	user.Session.Logout(c)
 	return output.JSON(c, output.MsgOk, "", "")
@@ -1,10 +1,12 @@
 package user
 
-import "github.com/douyu/juno/pkg/model/db"
+import (
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
 
 type ReqUserList struct {
-	CurrentPage int `query:"currentPage"`
-	PageSize    int `query:"pageSize"`
+	view.ListQuery
 }
 
 type ReqUserCreate struct {
@@ -7,6 +7,11 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// Check worker 启动时校验 Juno 地址和服务账号 Token，通过认证即返回成功
+func Check(c echo.Context) error {
+	return output.JSON(c, output.MsgOk, "success")
+}
+
 func Heartbeat(c echo.Context) error {
 	var params view.WorkerHeartbeat
 
@@ -15,6 +15,8 @@ export default {
     '/api/admin': {
       target: 'http://localhost:50000',
       changeOrigin: true,
+      ws: true, // 事件推送
+      xfwd: true,
       pathRewrite: { '^': '' },
     },
     '/api/v1': {
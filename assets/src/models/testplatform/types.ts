@@ -55,15 +55,17 @@ export interface Task {
   zone_code: string
   branch: string
   desc: PipelineDesc
-  status: "pending" | "running" | "failed" | "success"
+  status: "pending" | "running" | "failed" | "success" | "superseded" | "canceled"
   created_at: string
+  commit_sha?: string
+  superseded_by?: number
 }
 
 export interface TaskStepStatus {
   id: number
   task_id: number
   step_name: string
-  status: "waiting" | "running" | "failed" | "success"
+  status: "waiting" | "running" | "failed" | "success" | "skipped"
   logs: string
 }
 
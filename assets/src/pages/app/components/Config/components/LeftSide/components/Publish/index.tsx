@@ -17,6 +17,7 @@ import {CheckboxChangeEvent} from "antd/es/checkbox";
 import styles from './index.less';
 import {ConnectState} from "@/models/connect";
 import {useBoolean} from "ahooks";
+import {useEvents} from "@/utils/events";
 
 interface PublishProps {
   env: string
@@ -82,6 +83,12 @@ function Publish(props: PublishProps) {
     }
   }, [configFile]);
 
+  // 发布后实例同步、生效进度变化时刷新实例列表
+  useEvents(['config.publish'], [], (e) => {
+    if (!configFile || (e.topic !== 'resync' && e.data?.configuration_id !== configFile.id)) return
+    loadConfigInstances(configFile.aid, configFile.env, configFile.zone, configFile.id);
+  });
+
   let publishStart = () => {
     if (!configFile) {
       message.error('请选择配置文件再进行发布');
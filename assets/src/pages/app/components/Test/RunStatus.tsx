@@ -1,10 +1,12 @@
-import {CheckCircleOutlined, CloseCircleOutlined, InfoCircleOutlined, SyncOutlined} from "@ant-design/icons/lib";
+import {CheckCircleOutlined, CloseCircleOutlined, InfoCircleOutlined, StopOutlined, SyncOutlined} from "@ant-design/icons/lib";
 import React from "react";
 
 // pending
 // running
 // failed
 // success
+// superseded
+// canceled
 export default function RunStatus(props: { status: string, className: string }) {
   const render = () => {
     switch (props.status) {
@@ -31,6 +33,16 @@ export default function RunStatus(props: { status: string, className: string })
           <div style={{color: "green"}}><CheckCircleOutlined/></div>
           <div>成功</div>
         </>
+      case "canceled":
+        return <>
+          <div style={{color: "gray"}}><StopOutlined/></div>
+          <div>已取消</div>
+        </>
+      case "superseded":
+        return <>
+          <div style={{color: "gray"}}><StopOutlined/></div>
+          <div>已取代</div>
+        </>
       default:
         return <>
           <div style={{color: "gray"}}><InfoCircleOutlined/></div>
@@ -10,6 +10,7 @@ import RunStatus from "@/pages/app/components/Test/RunStatus";
 import {useBoolean} from "ahooks";
 import DrawerEditPipeline from "@/pages/app/components/Test/DrawerEditPipeline";
 import {EditOutlined, PlayCircleOutlined, ReloadOutlined} from "@ant-design/icons/lib";
+import {useEvents} from "@/utils/events";
 
 interface TasksProps {
   pipeline: Pipeline
@@ -28,11 +29,11 @@ function Tasks(props: TasksProps) {
   const [pagination, setPagination] = useState<{ current: number, total: number, pageSize: number }>()
   const [visibleDrawer, visibleDrawerAct] = useBoolean(false)
 
-  const loadTasks = (page = 1, pageSize = 10) => {
-    tasksLoadingAct.setTrue()
+  const loadTasks = (page = 1, pageSize = 10, silent = false) => {
+    if (!silent) tasksLoadingAct.setTrue()
 
     fetchTasks(pipeline.id, page, pageSize).then(r => {
-      tasksLoadingAct.setFalse()
+      if (!silent) tasksLoadingAct.setFalse()
 
       if (r.code === 14000) {
         return
@@ -71,6 +72,11 @@ function Tasks(props: TasksProps) {
     loadTasks()
   }, [pipeline])
 
+  // 任务状态变化时刷新当前页
+  useEvents(['task'], [`test/${pipeline.id}`], () => {
+    loadTasks(pagination?.current, pagination?.pageSize, true)
+  })
+
   let classNames = [styles.task]
   if (currentTask) classNames.push(styles.taskSelected)
   return <div>
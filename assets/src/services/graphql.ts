@@ -0,0 +1,28 @@
+import request from "@/utils/request";
+
+export interface GraphQLError {
+  message: string
+  locations?: { line: number, column: number }[]
+  path?: (string | number)[]
+}
+
+export interface GraphQLResponse<T = any> {
+  data?: T
+  errors?: GraphQLError[]
+}
+
+/**
+ * 执行只读 GraphQL 查询，字段解析出错时 data 中对应字段为 null，错误在 errors 中返回
+ * @example
+ * graphql(`query ($name: String!) { app(name: $name) { app_name instances { host_name } } }`, {name: 'juno'})
+ */
+export async function graphql<T = any>(query: string, variables?: { [key: string]: any }, operationName?: string): Promise<GraphQLResponse<T>> {
+  return request(`/api/admin/public/graphql`, {
+    method: 'POST',
+    data: {query, variables, operationName},
+  })
+}
+
+export async function graphqlSchema() {
+  return request(`/api/admin/public/graphql/schema`)
+}
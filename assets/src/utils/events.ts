@@ -0,0 +1,62 @@
+import {useEffect, useRef} from "react";
+import {stringify} from "qs";
+
+export type EventTopic = 'task' | 'config.publish' | 'alert' | 'agent'
+
+export interface ServerEvent {
+  topic: EventTopic | 'resync' | 'ping'
+  key?: string
+  zone?: string
+  data?: any
+  time: number
+}
+
+const reconnectDelay = 3000
+
+/**
+ * 订阅服务端推送的事件，断线后自动重连。
+ * 收到 resync 事件时说明有事件被丢弃，同样会回调 onEvent，页面应重新拉取数据
+ * @return 取消订阅
+ */
+export function subscribeEvents(topics: EventTopic[], keys: string[], onEvent: (e: ServerEvent) => void) {
+  let ws: WebSocket | null = null
+  let timer: number | undefined
+  let closed = false
+
+  const connect = () => {
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
+    const query = stringify({topic: topics, key: keys}, {arrayFormat: 'repeat'})
+    ws = new WebSocket(`${protocol}//${window.location.host}/api/admin/public/event/ws?${query}`)
+
+    ws.onmessage = (msg) => {
+      const e: ServerEvent = JSON.parse(msg.data)
+      if (e.topic === 'ping') return
+      onEvent(e)
+    }
+    ws.onclose = () => {
+      if (closed) return
+      timer = window.setTimeout(connect, reconnectDelay)
+    }
+  }
+
+  connect()
+
+  return () => {
+    closed = true
+    window.clearTimeout(timer)
+    ws?.close()
+  }
+}
+
+/**
+ * 在组件中订阅事件，topics、keys 变化时重新订阅
+ */
+export function useEvents(topics: EventTopic[], keys: string[], onEvent: (e: ServerEvent) => void) {
+  const handler = useRef(onEvent)
+  handler.current = onEvent
+
+  const deps = [topics.join(','), keys.join(',')]
+  useEffect(() => {
+    return subscribeEvents(topics, keys, (e) => handler.current(e))
+  }, deps)
+}
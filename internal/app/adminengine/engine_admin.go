@@ -25,15 +25,29 @@ import (
 	"github.com/douyu/juno/internal/app/middleware"
 	"github.com/douyu/juno/internal/pkg/install"
 	"github.com/douyu/juno/internal/pkg/invoker"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
 	"github.com/douyu/juno/internal/pkg/service"
+	"github.com/douyu/juno/internal/pkg/service/accessrequest"
+	"github.com/douyu/juno/internal/pkg/service/agent"
 	"github.com/douyu/juno/internal/pkg/service/appDep"
+	"github.com/douyu/juno/internal/pkg/service/appimport"
+	"github.com/douyu/juno/internal/pkg/service/applifecycle"
 	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/internal/pkg/service/cmdb"
 	"github.com/douyu/juno/internal/pkg/service/confgo"
+	"github.com/douyu/juno/internal/pkg/service/k8scluster"
 	"github.com/douyu/juno/internal/pkg/service/notify"
+	"github.com/douyu/juno/internal/pkg/service/oncall"
 	"github.com/douyu/juno/internal/pkg/service/openauth"
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
+	"github.com/douyu/juno/pkg/cache"
 	"github.com/douyu/juno/pkg/cfg"
 	"github.com/douyu/juno/pkg/constx"
+	"github.com/douyu/juno/pkg/graceful"
+	"github.com/douyu/juno/pkg/metrics"
+	"github.com/douyu/juno/pkg/notice"
 	"github.com/douyu/juno/pkg/pb"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/douyu/jupiter"
 	"github.com/douyu/jupiter/pkg"
 	"github.com/douyu/jupiter/pkg/client/etcdv3"
@@ -42,6 +56,7 @@ import (
 	"github.com/douyu/jupiter/pkg/server/xecho"
 	"github.com/douyu/jupiter/pkg/worker/xcron"
 	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
 )
 
 // Admin ...
@@ -50,8 +65,15 @@ type Admin struct {
 	mockFlag    bool
 	clearFlag   bool
 	installFlag bool
+	migrateFlag string
 	runFlag     bool
 	hostFlag    string
+
+	backupFlag    string
+	restoreFlag   string
+	backupKeyFlag string
+
+	server *adminServer
 }
 
 // New ...
@@ -64,6 +86,14 @@ func New() *Admin {
 		Action:  func(name string, fs *flag.FlagSet) {},
 	})
 
+	flag.Register(&flag.StringFlag{
+		Name:    "migrate",
+		Usage:   "--migrate=up|up:N|down|down:N|status",
+		EnvVar:  "Juno_Migrate",
+		Default: "",
+		Action:  func(name string, fs *flag.FlagSet) {},
+	})
+
 	flag.Register(&flag.BoolFlag{
 		Name:    "mock",
 		Usage:   "--mock",
@@ -88,6 +118,30 @@ func New() *Admin {
 		Action:  func(name string, fs *flag.FlagSet) {},
 	})
 
+	flag.Register(&flag.StringFlag{
+		Name:    "backup",
+		Usage:   "--backup=juno.backup.gz",
+		EnvVar:  "Juno_Backup",
+		Default: "",
+		Action:  func(name string, fs *flag.FlagSet) {},
+	})
+
+	flag.Register(&flag.StringFlag{
+		Name:    "restore",
+		Usage:   "--restore=juno.backup.gz",
+		EnvVar:  "Juno_Restore",
+		Default: "",
+		Action:  func(name string, fs *flag.FlagSet) {},
+	})
+
+	flag.Register(&flag.StringFlag{
+		Name:    "backup-key",
+		Usage:   "--backup-key, 加密备份中凭证的密钥",
+		EnvVar:  "Juno_Backup_Key",
+		Default: "",
+		Action:  func(name string, fs *flag.FlagSet) {},
+	})
+
 	eng := &Admin{}
 	err := eng.Startup(
 		eng.parseFlag,
@@ -95,13 +149,25 @@ func New() *Admin {
 		eng.migrateDB,
 		eng.initClientProxy,
 		eng.initInvoker,
+		eng.cmdBackup,
 		eng.cmdMock,
 		eng.initNotify,
+		eng.initReloadSignal,
 		eng.serveHTTP,
+		eng.initUpgradeSignal,
 		eng.serveGovern,
 		eng.defers,
 		eng.initParseWorker,
 		eng.initVersionWorker,
+		eng.initAgentWorker,
+		eng.initAccessRequestWorker,
+		eng.initNoticeDigestWorker,
+		eng.initOnCallWorker,
+		eng.initAppImportWorker,
+		eng.initCMDBWorker,
+		eng.initAppLifecycleWorker,
+		eng.initRecycleBinWorker,
+		eng.initK8SClusterWorker,
 	)
 
 	if err != nil {
@@ -114,8 +180,13 @@ func (eng *Admin) parseFlag() error {
 	eng.mockFlag = flag.Bool("mock")
 	eng.clearFlag = flag.Bool("clear")
 	eng.installFlag = flag.Bool("install")
+	eng.migrateFlag = flag.String("migrate")
 	eng.hostFlag = flag.String("host")
-	if !eng.installFlag && !eng.mockFlag && !eng.clearFlag {
+	eng.backupFlag = flag.String("backup")
+	eng.restoreFlag = flag.String("restore")
+	eng.backupKeyFlag = flag.String("backup-key")
+	if !eng.installFlag && !eng.mockFlag && !eng.clearFlag && eng.migrateFlag == "" &&
+		eng.backupFlag == "" && eng.restoreFlag == "" {
 		eng.runFlag = true
 	}
 	return nil
@@ -138,6 +209,7 @@ func (eng *Admin) initConfig() (err error) {
 	bizConfig.Dir = cfg.Cfg.Logger.Biz.Dir
 	bizConfig.Async = cfg.Cfg.Logger.Biz.Async
 	xlog.DefaultLogger = bizConfig.Build()
+	tracing.Init(cfg.Cfg.Trace, "juno-admin")
 	return
 }
 
@@ -173,17 +245,29 @@ func (eng *Admin) serveHTTP() (err error) {
 		serverConfig.Host = eng.hostFlag
 	}
 	serverConfig.Port = cfg.Cfg.Server.Http.Port
-	server := serverConfig.Build()
+	server, err := newAdminServer(serverConfig)
+	if err != nil {
+		return err
+	}
+	eng.server = server
 	server.Debug = true
+	server.HTTPErrorHandler = output.HTTPErrorHandler
 
+	server.Use(middleware.MetricsMW)
 	server.Use(middleware.ProxyGatewayMW)
 
 	server.Validator = NewValidator()
 
+	// Liveness and readiness probes
+	adminHealth().Register(server.Echo)
+	// Prometheus metrics
+	server.GET("/metrics", echo.WrapHandler(metrics.Handler()), middleware.IPAllowlistMW("metrics", cfg.Cfg.IPAllowlist.Metrics))
 	// Provide Admin API interface
-	apiAdmin(server)
+	apiAdmin(server.Server)
 	// Provide Open API interface
-	apiV1(server)
+	apiV1(server.Server)
+	// Provide OpenAPI specification
+	apiSpec(server.Server)
 	err = eng.Serve(server)
 	return
 }
@@ -222,6 +306,12 @@ func (eng *Admin) serveGovern() (err error) {
 
 func (eng *Admin) initInvoker() (err error) {
 	invoker.Init()
+	if invoker.JunoMysql != nil {
+		if err = metrics.RegisterDB("juno", invoker.JunoMysql.DB()); err != nil {
+			return err
+		}
+	}
+	cache.Init(cfg.Cfg.Cache)
 	err = service.Init()
 	if err != nil {
 		return err
@@ -293,3 +383,90 @@ func (eng *Admin) initVersionWorker() (err error) {
 	cron.Schedule(xcron.Every(time.Hour*12), xcron.FuncJob(appDep.AppDep.SyncAppVersion))
 	return eng.Schedule(cron)
 }
+
+func (eng *Admin) initAgentWorker() (err error) {
+	if !eng.runFlag {
+		return
+	}
+	cron := xcron.DefaultConfig().Build()
+	cron.Schedule(xcron.Every(time.Second*30), xcron.FuncJob(agent.AgentUpgrade.Tick))
+	cron.Schedule(xcron.Every(time.Second*30), xcron.FuncJob(agent.AgentOffline.Tick))
+	return eng.Schedule(cron)
+}
+
+func (eng *Admin) initAccessRequestWorker() (err error) {
+	if !eng.runFlag {
+		return
+	}
+	cron := xcron.DefaultConfig().Build()
+	cron.Schedule(xcron.Every(time.Minute), xcron.FuncJob(accessrequest.AccessRequest.ExpireTick))
+	return eng.Schedule(cron)
+}
+
+// initNoticeDigestWorker 汇总事件缓存在各实例内存中，每个实例都需要定时发送
+func (eng *Admin) initNoticeDigestWorker() (err error) {
+	if !eng.runFlag || !cfg.Cfg.Notice.Digest.Enable {
+		return
+	}
+	// 退出时发送尚未汇总的事件
+	graceful.OnShutdown("notice digest", func(ctx context.Context) error {
+		return notice.FlushDigest()
+	})
+	cron := xcron.DefaultConfig().Build()
+	cron.Schedule(xcron.Every(notice.DigestInterval()), xcron.FuncJob(notice.FlushDigest))
+	return eng.Schedule(cron)
+}
+
+func (eng *Admin) initOnCallWorker() (err error) {
+	if !eng.runFlag {
+		return
+	}
+	cron := xcron.DefaultConfig().Build()
+	cron.Schedule(xcron.Every(time.Minute), xcron.FuncJob(oncall.OnCall.EscalateTick))
+	return eng.Schedule(cron)
+}
+
+func (eng *Admin) initAppImportWorker() (err error) {
+	if !eng.runFlag || !cfg.Cfg.AppImport.Enable || cfg.Cfg.AppImport.Interval <= 0 {
+		return
+	}
+	cron := xcron.DefaultConfig().Build()
+	cron.Schedule(xcron.Every(cfg.Cfg.AppImport.Interval), xcron.FuncJob(appimport.AppImport.ScanTick))
+	return eng.Schedule(cron)
+}
+
+func (eng *Admin) initCMDBWorker() (err error) {
+	if !eng.runFlag || !cfg.Cfg.CMDB.Enable || cfg.Cfg.CMDB.Interval <= 0 {
+		return
+	}
+	cron := xcron.DefaultConfig().Build()
+	cron.Schedule(xcron.Every(cfg.Cfg.CMDB.Interval), xcron.FuncJob(cmdb.CMDB.SyncTick))
+	return eng.Schedule(cron)
+}
+
+func (eng *Admin) initAppLifecycleWorker() (err error) {
+	if !eng.runFlag || cfg.Cfg.AppLifecycle.Interval <= 0 {
+		return
+	}
+	cron := xcron.DefaultConfig().Build()
+	cron.Schedule(xcron.Every(cfg.Cfg.AppLifecycle.Interval), xcron.FuncJob(applifecycle.AppLifecycle.CleanupTick))
+	return eng.Schedule(cron)
+}
+
+func (eng *Admin) initRecycleBinWorker() (err error) {
+	if !eng.runFlag {
+		return
+	}
+	cron := xcron.DefaultConfig().Build()
+	cron.Schedule(xcron.Every(recyclebin.RecycleBin.PurgeInterval()), xcron.FuncJob(recyclebin.RecycleBin.PurgeTick))
+	return eng.Schedule(cron)
+}
+
+func (eng *Admin) initK8SClusterWorker() (err error) {
+	if !eng.runFlag || cfg.Cfg.K8SCluster.CheckInterval <= 0 {
+		return
+	}
+	cron := xcron.DefaultConfig().Build()
+	cron.Schedule(xcron.Every(cfg.Cfg.K8SCluster.CheckInterval), xcron.FuncJob(k8scluster.K8sCluster.CheckTick))
+	return eng.Schedule(cron)
+}
@@ -0,0 +1,53 @@
+package adminengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/douyu/juno/internal/pkg/backup"
+	"github.com/douyu/juno/internal/pkg/invoker"
+)
+
+// cmdBackup 执行 --backup、--restore，恢复时目标库需要先 --install 或 --migrate 到与备份相同的版本
+func (eng *Admin) cmdBackup() (err error) {
+	if eng.backupFlag == "" && eng.restoreFlag == "" {
+		return nil
+	}
+	option := backup.Option{
+		DB:   invoker.JunoMysql,
+		Key:  eng.backupKeyFlag,
+		Etcd: backup.EtcdClients(),
+	}
+
+	if eng.backupFlag != "" {
+		var file *os.File
+		file, err = os.OpenFile(eng.backupFlag, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		summary, err := backup.Export(context.Background(), option, file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(eng.backupFlag)
+			return fmt.Errorf("backup failed: %w", err)
+		}
+		fmt.Printf("backup ok: %d tables, %d rows, %d etcd keys\n", summary.Tables, summary.Rows, summary.EtcdKeys)
+		return nil
+	}
+
+	file, err := os.Open(eng.restoreFlag)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	summary, err := backup.Restore(context.Background(), option, file)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	fmt.Printf("restore ok: %d tables, %d rows, %d etcd keys, %d etcd keys skipped\n",
+		summary.Tables, summary.Rows, summary.EtcdKeys, summary.Skipped)
+	return nil
+}
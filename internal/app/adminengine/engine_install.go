@@ -19,15 +19,17 @@ import (
 	"os"
 
 	"github.com/douyu/juno/internal/pkg/install"
+	"github.com/douyu/juno/internal/pkg/migration"
 	"github.com/douyu/juno/pkg/cfg"
-	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/sqldialect"
 	"github.com/douyu/juno/pkg/util"
+	"github.com/douyu/jupiter/pkg/xlog"
 	"github.com/jinzhu/gorm"
 )
 
 func (eng *Admin) migrateDB() error {
 	gormdb, err := gorm.Open(
-		"mysql",
+		cfg.Cfg.Database.Dialect,
 		cfg.Cfg.Database.DSN,
 	)
 	if err != nil {
@@ -39,20 +41,32 @@ func (eng *Admin) migrateDB() error {
 	}()
 
 	eng.cmdClear(gormdb)
-	eng.cmdInstall(gormdb)
+	if err = eng.cmdInstall(gormdb); err != nil {
+		return err
+	}
+	if err = eng.cmdMigrate(gormdb); err != nil {
+		return err
+	}
+	if eng.runFlag {
+		eng.checkMigration(gormdb)
+	}
 	return nil
 }
 
 func (eng *Admin) cmdClear(gormdb *gorm.DB) {
 	if eng.clearFlag {
-		dsn, err := util.ParseDSN(cfg.Cfg.Database.DSN)
-		if err != nil {
-			panic("dsn parse error: " + err.Error())
-		}
 		var result []struct {
 			Sqlstr string
 		}
-		gormdb.Raw("SELECT concat('DROP TABLE IF EXISTS `', table_name, '`;') as sqlstr FROM information_schema.tables WHERE table_schema = '" + dsn.DBName + "'").Scan(&result)
+		if cfg.Cfg.Database.Dialect == sqldialect.Postgres {
+			gormdb.Raw("SELECT 'DROP TABLE IF EXISTS \"' || tablename || '\" CASCADE;' as sqlstr FROM pg_tables WHERE schemaname = current_schema()").Scan(&result)
+		} else {
+			dsn, err := util.ParseDSN(cfg.Cfg.Database.DSN)
+			if err != nil {
+				panic("dsn parse error: " + err.Error())
+			}
+			gormdb.Raw("SELECT concat('DROP TABLE IF EXISTS `', table_name, '`;') as sqlstr FROM information_schema.tables WHERE table_schema = '" + dsn.DBName + "'").Scan(&result)
+		}
 		for _, v := range result {
 			fmt.Println(`sql drop:`, v.Sqlstr)
 			gormdb.Exec(v.Sqlstr)
@@ -61,64 +75,36 @@ func (eng *Admin) cmdClear(gormdb *gorm.DB) {
 	}
 }
 
-func (eng *Admin) cmdInstall(gormdb *gorm.DB) {
-	if eng.installFlag {
-		models := []interface{}{
-			&db.AppInfo{},
-			&db.AppChangeMap{},
-			&db.AppTopology{},
-			&db.AppEvent{},
-			&db.AppLog{},
-			&db.AppNode{},
-			&db.Node{},
-			&db.AppNodeMap{},
-			&db.AppPackage{},
-			&db.AppStatics{},
-			&db.AppUserRelation{},
-			&db.AppViewHistory{},
-			&db.Board{},
-			&db.BoardAuth{},
-			&db.CmcTpl{},
-			&db.Zone{},
-			&db.OpsSupervisorConfig{},
-			&db.PProf{},
-			&db.ToolInfo{},
-			&db.User{},
-			&db.UserRelation{},
-			&db.Option{},
-			&db.SystemConfig{},
-			&db.Configuration{},
-			&db.ConfigurationPublish{},
-			&db.ConfigurationHistory{},
-			&db.ConfigurationStatus{},
-			&db.ConfigurationResourceRelation{},
-			&db.ConfigResource{},
-			&db.ConfigResourceTag{},
-			&db.ConfigResourceValue{},
-			&db.CasbinPolicyAuth{},
-			&db.CasbinPolicyGroup{},
-			&db.AccessToken{},
-			&db.GrpcTestCase{},
-			&db.GrpcTestLog{},
-			&db.GrpcProto{},
-			&db.GrpcProtoService{},
-			&db.GrpcServiceMethod{},
-			&db.HttpTestLog{},
-			&db.HttpTestCase{},
-			&db.HttpTestCollection{},
-			&db.TestPipeline{},
-			&db.TestPipelineTask{},
-			&db.TestPipelineStepStatus{},
-			&db.WorkerNode{},
-			&db.CronJob{},
-			&db.CronTask{},
-			&db.CronJobTimer{},
-		}
-		gormdb = gormdb.Debug()
-		gormdb.SingularTable(true)
-		gormdb.Set("gorm:table_options", "ENGINE=InnoDB").AutoMigrate(models...)
-		gormdb.Model(&db.AccessToken{}).AddUniqueIndex("idx_unique_name", "name")
-		fmt.Println("create table ok")
+// cmdInstall 执行全部迁移，等同于 --migrate=up
+func (eng *Admin) cmdInstall(gormdb *gorm.DB) error {
+	if !eng.installFlag {
+		return nil
+	}
+	if err := migration.Up(gormdb, 0); err != nil {
+		return err
+	}
+	fmt.Println("create table ok")
+	return nil
+}
+
+// cmdMigrate 执行 --migrate 指定的迁移操作，见 migration.Run
+func (eng *Admin) cmdMigrate(gormdb *gorm.DB) error {
+	if eng.migrateFlag == "" {
+		return nil
+	}
+	return migration.Run(gormdb, eng.migrateFlag, os.Stdout)
+}
+
+// checkMigration 有未执行的迁移时只告警，由运维确认后执行 --migrate=up
+func (eng *Admin) checkMigration(gormdb *gorm.DB) {
+	current, err := migration.Current(gormdb)
+	if err != nil {
+		xlog.Warn("check migration failed", xlog.String("err", err.Error()))
+		return
+	}
+	if current < migration.Latest() {
+		xlog.Warn("database schema is not up to date, run with --migrate=up",
+			xlog.Int("current", current), xlog.Int("latest", migration.Latest()))
 	}
 }
 
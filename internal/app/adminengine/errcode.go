@@ -0,0 +1,100 @@
+package adminengine
+
+import (
+	"github.com/douyu/juno/internal/pkg/backup"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/accessrequest"
+	"github.com/douyu/juno/internal/pkg/service/appimport"
+	"github.com/douyu/juno/internal/pkg/service/applifecycle"
+	"github.com/douyu/juno/internal/pkg/service/apptransfer"
+	"github.com/douyu/juno/internal/pkg/service/artifact"
+	"github.com/douyu/juno/internal/pkg/service/cmdb"
+	"github.com/douyu/juno/internal/pkg/service/notifyrule"
+	"github.com/douyu/juno/internal/pkg/service/notifytemplate"
+	"github.com/douyu/juno/internal/pkg/service/oncall"
+	"github.com/douyu/juno/internal/pkg/service/openauth"
+	"github.com/douyu/juno/internal/pkg/service/permission"
+	"github.com/douyu/juno/internal/pkg/service/promotion"
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
+	"github.com/douyu/juno/internal/pkg/service/serviceaccount"
+	"github.com/douyu/juno/internal/pkg/service/team"
+	"github.com/douyu/juno/internal/pkg/service/testplatform"
+	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/jinzhu/gorm"
+)
+
+// 登记 service 层返回的哨兵错误，handler 通过 c.OutputError 或直接返回错误时按错误码目录输出
+func init() {
+	output.RegisterError(output.MsgNotFound,
+		gorm.ErrRecordNotFound,
+		accessrequest.ErrRequestNotFound,
+		apptransfer.ErrTransferNotFound,
+		artifact.ErrObjectNotFound,
+		notifyrule.ErrNotifyRuleNotFound,
+		notifytemplate.ErrNotifyTemplateNotFound,
+		oncall.ErrRotationNotFound,
+		oncall.ErrIncidentNotFound,
+		openauth.ErrAppNotFound,
+		permission.ErrZoneNotFound,
+		permission.ErrZoneScopeSubjectNotFound,
+		permission.ErrUserGroupNotFund,
+		permission.ErrPolicyNotFound,
+		permission.ErrBindingNotFound,
+		permission.ErrAppEnvNotExists,
+		promotion.ErrPromotionNotFound,
+		recyclebin.ErrItemNotFound,
+		serviceaccount.ErrAccountNotFound,
+		serviceaccount.ErrCredentialMissing,
+		team.ErrTeamNotFound,
+		testplatform.ErrSecretNotFound,
+		user.ErrSessionNotFound,
+	)
+	output.RegisterError(output.MsgInvalidParam,
+		permission.ErrInvalidAppPerm,
+		permission.ErrInvalidAPIPerm,
+		backup.ErrNoBackupKey,
+		user.ErrPasswordReused,
+		user.ErrUnsubscribeToken,
+		user.ErrTOTPInvalidCode,
+		user.ErrUnsupportedLanguage,
+		testplatform.ErrArtifactTooLarge,
+		testplatform.ErrInvalidArtifactName,
+		testplatform.ErrCompareDifferentPipeline,
+		testplatform.ErrInvalidCommitSHA,
+		testplatform.ErrInvalidTaskEvent,
+		testplatform.ErrInvalidSecretName,
+		testplatform.ErrInvalidSecretValue,
+		pipeline.ErrInvalidGoVersion,
+		pipeline.ErrInvalidBuildTarget,
+		pipeline.ErrInvalidBenchmarkArgs,
+		pipeline.ErrInvalidArtifactPath,
+		pipeline.ErrInvalidArtifactStep,
+		pipeline.ErrInvalidEnvName,
+		pipeline.ErrReservedEnvName,
+		pipeline.ErrInvalidEnvValue,
+		pipeline.ErrInvalidEnvStep,
+		pipeline.ErrTooManyStepEnv,
+		pipeline.ErrInvalidWhen,
+		pipeline.ErrInvalidWhenStep,
+	)
+	output.RegisterError(output.MsgConflict,
+		appimport.ErrScanRunning,
+		applifecycle.ErrCleanupRunning,
+		cmdb.ErrSyncRunning,
+		recyclebin.ErrItemExpired,
+		testplatform.ErrTaskSuperseded,
+		testplatform.ErrTaskCanceled,
+		testplatform.ErrTaskFinished,
+		user.ErrTOTPAlreadyEnabled,
+		user.ErrUsernameConflict,
+	)
+	output.RegisterError(output.MsgNoAuth,
+		accessrequest.ErrNoReviewPerm,
+		recyclebin.ErrNoRestorePerm,
+		recyclebin.ErrNoPurgePerm,
+	)
+	output.RegisterError(output.MsgNeedLogin, user.ErrUserDisabled)
+	output.RegisterError(output.MsgPasswordExpired, user.ErrPasswordExpired)
+	output.RegisterError(output.MsgNeedTwoFactorEnroll, user.ErrTOTPRequired)
+}
@@ -1,6 +1,9 @@
 package adminengine
 
-import "github.com/go-playground/validator/v10"
+import (
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/go-playground/validator/v10"
+)
 
 type (
 	FormValidator struct {
@@ -14,6 +17,10 @@ func NewValidator() *FormValidator {
 	}
 }
 
+// Validate 校验失败返回 MsgInvalidParam
 func (f *FormValidator) Validate(i interface{}) error {
-	return f.validator.Struct(i)
+	if err := f.validator.Struct(i); err != nil {
+		return output.InvalidParam(err)
+	}
+	return nil
 }
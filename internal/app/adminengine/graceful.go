@@ -0,0 +1,94 @@
+package adminengine
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/graceful"
+	"github.com/douyu/jupiter/pkg/server/xecho"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// adminServer 退出时先等待负载均衡摘除实例，再停止接收请求，等待进行中的请求和 WebSocket 连接结束，最后发送缓存的通知。
+// jupiter 收到 SIGTERM 时调用 Stop，收到 SIGQUIT 时调用 GracefulStop，两者都平滑退出
+type adminServer struct {
+	*xecho.Server
+	// inherited 父进程交接的监听 socket，为空时使用 xecho 监听的端口
+	inherited net.Listener
+}
+
+// newAdminServer 由 Upgrade 启动时使用父进程交接的监听 socket。
+// 端口仍被父进程占用，xecho 改为监听随机端口，该端口不对外提供服务
+func newAdminServer(config *xecho.Config) (*adminServer, error) {
+	ln, err := graceful.Inherited()
+	if err != nil {
+		return nil, err
+	}
+	if ln != nil {
+		config.Port = 0
+	}
+	return &adminServer{Server: config.Build(), inherited: ln}, nil
+}
+
+// Serve 使用交接的监听 socket 时，开始服务后通知父进程退出
+func (s *adminServer) Serve() error {
+	if s.inherited == nil {
+		return s.Server.Serve()
+	}
+
+	s.Echo.HideBanner = true
+	s.Echo.Listener = s.inherited
+	if err := graceful.Ready(); err != nil {
+		xlog.Error("notify parent process ready failed", xlog.FieldErr(err))
+	}
+	xlog.Info("serve on inherited listener", xlog.String("addr", s.inherited.Addr().String()))
+	err := s.Echo.Start("")
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Listener 开始服务后正在使用的监听 socket
+func (s *adminServer) Listener() net.Listener {
+	return s.Echo.Listener
+}
+
+// Stop 与 GracefulStop 相同，等待时间为 server.graceful.timeout
+func (s *adminServer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Cfg.Server.Graceful.Timeout)
+	defer cancel()
+	return s.GracefulStop(ctx)
+}
+
+// GracefulStop ctx 结束时强制关闭剩余连接
+func (s *adminServer) GracefulStop(ctx context.Context) error {
+	graceful.Shutdown()
+
+	if delay := cfg.Cfg.Server.Graceful.ReadyDelay; delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
+	err := s.Echo.Shutdown(ctx)
+	if err == nil {
+		// WebSocket 收到 Closing 后主动断开，这里等待其结束
+		err = graceful.WaitFor(ctx, func() bool { return graceful.Streams() == 0 })
+	}
+	if err != nil {
+		xlog.Warn("drain http server timeout, close remaining connections",
+			xlog.Int("streams", graceful.Streams()), xlog.FieldErr(err))
+		_ = s.Echo.Close()
+	}
+
+	// 请求已全部结束，不会再产生新的通知和审计记录
+	if err := graceful.Flush(ctx); err != nil {
+		xlog.Error("flush on shutdown failed", xlog.FieldErr(err))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package adminengine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/douyu/juno/internal/pkg/invoker"
+	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/pkg/cache"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/constx"
+	"github.com/douyu/juno/pkg/graceful"
+	"github.com/douyu/juno/pkg/health"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// adminHealth 开始退出或元数据库不可用时未就绪；配置中心 etcd 按机房检查，单个机房不可用只影响该机房的配置发布
+func adminHealth() *health.Checker {
+	h := health.New(0)
+	h.Critical("shutdown", func(ctx context.Context) error {
+		if graceful.Draining() {
+			return fmt.Errorf("shutting down")
+		}
+		return nil
+	})
+	// 检查项名称为 mysql 或 postgres，与切换 PostgreSQL 之前的 mysql 保持兼容
+	h.Critical(cfg.Cfg.Database.Dialect, func(ctx context.Context) error {
+		if invoker.JunoMysql == nil {
+			return fmt.Errorf("database not enabled")
+		}
+		return health.SQL(invoker.JunoMysql.DB())(ctx)
+	})
+	// Redis 不可用时读取直接访问数据库，不影响就绪
+	if cfg.Cfg.Cache.Enable && cfg.Cfg.Cache.Type == cache.TypeRedis {
+		h.Optional("cache", cache.Ping)
+	}
+
+	if cfg.Cfg.App.Mode != constx.ModeMultiple {
+		h.Optional("etcd", etcdCheck(view.UniqZone{}))
+		return h
+	}
+	for _, cp := range cfg.Cfg.ClientProxy.MultiProxy {
+		if !cp.DefaultEtcd.Enable {
+			continue
+		}
+		zone := view.UniqZone{Env: cp.Env, Zone: cp.ZoneCode}
+		h.Optional("etcd:"+zone.String(), etcdCheck(zone))
+	}
+	return h
+}
+
+// etcdCheck 每次检查时获取客户端，机房的 etcd 可能在启动后才连接成功
+func etcdCheck(zone view.UniqZone) health.CheckFunc {
+	return func(ctx context.Context) error {
+		return health.Etcd(clientproxy.ClientProxy.DefaultEtcd(zone))(ctx)
+	}
+}
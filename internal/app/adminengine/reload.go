@@ -0,0 +1,30 @@
+package adminengine
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// initReloadSignal 收到 SIGHUP 时重新加载配置，不重启服务，已建立的 WebSocket 连接不受影响
+func (eng *Admin) initReloadSignal() (err error) {
+	if !eng.runFlag {
+		return
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		for range ch {
+			changed, err := cfg.Reload()
+			if err != nil {
+				xlog.Error("reload cfg on SIGHUP failed", xlog.FieldErr(err))
+				continue
+			}
+			xlog.Info("reload cfg on SIGHUP", xlog.Any("changed", changed))
+		}
+	}()
+	return
+}
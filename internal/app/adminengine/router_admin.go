@@ -18,21 +18,41 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/douyu/juno/api/apiv1/accessrequest"
+	"github.com/douyu/juno/api/apiv1/agent"
 	"github.com/douyu/juno/api/apiv1/analysis"
+	"github.com/douyu/juno/api/apiv1/appimport"
+	"github.com/douyu/juno/api/apiv1/applifecycle"
+	"github.com/douyu/juno/api/apiv1/apptransfer"
+	"github.com/douyu/juno/api/apiv1/auditlog"
+	cmdbHandle "github.com/douyu/juno/api/apiv1/cmdb"
 	"github.com/douyu/juno/api/apiv1/confgo"
 	"github.com/douyu/juno/api/apiv1/confgov2"
 	"github.com/douyu/juno/api/apiv1/confgov2/configresource"
 	configstatics "github.com/douyu/juno/api/apiv1/confgov2/configstatistics"
 	"github.com/douyu/juno/api/apiv1/cronjob"
+	"github.com/douyu/juno/api/apiv1/deployment"
 	etcdHandle "github.com/douyu/juno/api/apiv1/etcd"
 	"github.com/douyu/juno/api/apiv1/event"
+	"github.com/douyu/juno/api/apiv1/feishu"
+	"github.com/douyu/juno/api/apiv1/graphql"
+	"github.com/douyu/juno/api/apiv1/k8scluster"
 	"github.com/douyu/juno/api/apiv1/loggerplatform"
+	"github.com/douyu/juno/api/apiv1/notifyrule"
+	"github.com/douyu/juno/api/apiv1/notifytemplate"
+	"github.com/douyu/juno/api/apiv1/oncall"
 	"github.com/douyu/juno/api/apiv1/openauth"
 	"github.com/douyu/juno/api/apiv1/permission"
 	pprofHandle "github.com/douyu/juno/api/apiv1/pprof"
+	"github.com/douyu/juno/api/apiv1/promotion"
+	"github.com/douyu/juno/api/apiv1/proxyaudit"
+	"github.com/douyu/juno/api/apiv1/recyclebin"
 	"github.com/douyu/juno/api/apiv1/resource"
+	"github.com/douyu/juno/api/apiv1/serviceaccount"
 	"github.com/douyu/juno/api/apiv1/static"
 	"github.com/douyu/juno/api/apiv1/system"
+	"github.com/douyu/juno/api/apiv1/tag"
+	"github.com/douyu/juno/api/apiv1/team"
 	"github.com/douyu/juno/api/apiv1/test/grpc"
 	http2 "github.com/douyu/juno/api/apiv1/test/http"
 	"github.com/douyu/juno/api/apiv1/test/platform"
@@ -61,6 +81,9 @@ func apiAdmin(server *xecho.Server) {
 	// session init
 	sessionMW := session.Middleware(userSrv.NewSessionStore())
 
+	// 代理到业务实例治理端口的请求审计
+	governanceAuditMW := middleware.ProxyAuditMW(db.ProxyAuditKindGovernance)
+
 	var casbinMW echo.MiddlewareFunc
 	// casbin init
 	if cfg.Cfg.Casbin.Enable {
@@ -87,13 +110,14 @@ func apiAdmin(server *xecho.Server) {
 
 	echo.NotFoundHandler = func(c echo.Context) error {
 		if strings.HasPrefix(c.Request().URL.Path, "/api/") {
-			return c.JSON(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+			return echo.ErrNotFound
 		}
 		return c.File("assets/dist")
 	}
 
 	// grafana proxy
-	groupGrafana := server.Group("/grafana", sessionMW, loginAuthRedirect, middleware.GrafanaAuthMW)
+	groupGrafana := server.Group("/grafana", sessionMW, loginAuthRedirect, middleware.GrafanaAuthMW,
+		middleware.RateLimitMW("grafana"), middleware.ProxyAuditMW(db.ProxyAuditKindGrafana))
 	{
 		AllMethods := []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete,
 			http.MethodHead, http.MethodTrace, http.MethodPut, http.MethodConnect, http.MethodOptions}
@@ -102,8 +126,15 @@ func apiAdmin(server *xecho.Server) {
 		groupGrafana.Match(AllMethods, "/*", grafana.Proxy)
 	}
 
+	adminAllowlistMW := middleware.IPAllowlistMW("admin", cfg.Cfg.IPAllowlist.Admin)
+
 	g := server.Group("/api/admin")
-	g.Use(sessionMW) // use session
+	g.Use(adminAllowlistMW)           // restrict source ip
+	g.Use(sessionMW)                  // use session
+	g.Use(middleware.PersonalTokenMW) // use api token
+	g.Use(middleware.LanguageMW)      // resolve response language
+	g.Use(middleware.AuditMW)         // audit mutating operations
+	g.Use(middleware.TwoFactorMW)     // enforce two-factor policy
 	if cfg.Cfg.Casbin.Enable {
 		g.Use(casbinMW) // use casbin
 	}
@@ -114,17 +145,83 @@ func apiAdmin(server *xecho.Server) {
 		publicGroup.GET("/system/config", system.Config)
 		publicGroup.GET("/user/logout", user.Logout, loginAuthWithJSON)
 		publicGroup.GET("/user/info", core.Handle(user.Info), loginAuthWithJSON)
+		publicGroup.GET("/user/token/list", core.Handle(user.TokenList), loginAuthWithJSON)
+		publicGroup.POST("/user/token/create", core.Handle(user.TokenCreate), loginAuthWithJSON)
+		publicGroup.POST("/user/token/revoke", core.Handle(user.TokenRevoke), loginAuthWithJSON)
+		publicGroup.GET("/user/session/list", core.Handle(user.SessionList), loginAuthWithJSON)
+		publicGroup.POST("/user/session/revoke", core.Handle(user.SessionRevoke), loginAuthWithJSON)
+		publicGroup.POST("/user/session/revokeOthers", core.Handle(user.SessionRevokeOthers), loginAuthWithJSON)
+		publicGroup.POST("/user/password/change", core.Handle(user.ChangePassword), loginAuthWithJSON)
+		publicGroup.GET("/user/totp/status", core.Handle(user.TOTPStatus), loginAuthWithJSON)
+		publicGroup.POST("/user/totp/enroll", core.Handle(user.TOTPEnroll), loginAuthWithJSON)
+		publicGroup.POST("/user/totp/activate", core.Handle(user.TOTPActivate), loginAuthWithJSON)
+		publicGroup.POST("/user/totp/verify", core.Handle(user.TOTPVerify), loginAuthWithJSON)
+		publicGroup.POST("/user/totp/disable", core.Handle(user.TOTPDisable), loginAuthWithJSON)
+		publicGroup.POST("/user/totp/backupCodes", core.Handle(user.TOTPBackupCodes), loginAuthWithJSON)
+		publicGroup.GET("/user/notify/email", core.Handle(user.NotifyEmail), loginAuthWithJSON)
+		publicGroup.POST("/user/notify/email/set", core.Handle(user.SetNotifyEmail), loginAuthWithJSON)
+		publicGroup.GET("/user/notify/pref", core.Handle(user.NotifyPref), loginAuthWithJSON)
+		publicGroup.POST("/user/notify/pref/set", core.Handle(user.SetNotifyPref), loginAuthWithJSON)
+		publicGroup.GET("/user/language", core.Handle(user.Language), loginAuthWithJSON)
+		publicGroup.POST("/user/language/set", core.Handle(user.SetLanguage), loginAuthWithJSON)
+		// 当前语言的译文和枚举名称，未登录时按 Accept-Language
+		publicGroup.GET("/i18n/messages", core.Handle(user.I18nMessages))
+		// 邮件退订链接，通过链接中的凭证识别用户
+		publicGroup.GET("/user/notify/unsubscribe", user.Unsubscribe)
+
+		// 应用权限申请，审批权限由服务内校验：应用所属团队 owner 或管理员
+		publicGroup.GET("/permission/request/list", core.Handle(accessrequest.List), loginAuthWithJSON)
+		publicGroup.POST("/permission/request/create", core.Handle(accessrequest.Create), loginAuthWithJSON)
+		publicGroup.POST("/permission/request/cancel", core.Handle(accessrequest.Cancel), loginAuthWithJSON)
+		publicGroup.POST("/permission/request/review", core.Handle(accessrequest.Review), loginAuthWithJSON)
+		publicGroup.POST("/permission/request/revoke", core.Handle(accessrequest.Revoke), loginAuthWithJSON)
+
+		// 环境晋升审批，审批权限由服务内校验：应用所属团队 owner 或管理员
+		publicGroup.GET("/promotion/list", core.Handle(promotion.List), loginAuthWithJSON)
+		publicGroup.GET("/promotion/detail", core.Handle(promotion.Detail), loginAuthWithJSON)
+		publicGroup.POST("/promotion/review", core.Handle(promotion.Review), loginAuthWithJSON)
+		publicGroup.POST("/promotion/cancel", core.Handle(promotion.Cancel), loginAuthWithJSON)
+
+		// 应用归属转移，由当前团队 owner 或管理员发起，目标团队 owner 或管理员确认
+		publicGroup.GET("/app/transfer/list", core.Handle(apptransfer.List), loginAuthWithJSON)
+		publicGroup.POST("/app/transfer/create", core.Handle(apptransfer.Create), loginAuthWithJSON)
+		publicGroup.POST("/app/transfer/review", core.Handle(apptransfer.Review), loginAuthWithJSON)
+		publicGroup.POST("/app/transfer/cancel", core.Handle(apptransfer.Cancel), loginAuthWithJSON)
+
+		// 当前用户可访问的机房，前端据此过滤机房选项
+		publicGroup.GET("/permission/zoneScope/mine", core.Handle(permission.MyZoneScope), loginAuthWithJSON)
+
+		// 任务状态、配置发布进度、告警、agent 离线等事件推送
+		publicGroup.GET("/event/ws", core.Handle(event.Subscribe), loginAuthWithJSON)
+
+		// 只读 GraphQL 查询，配置、流水线的应用权限和机房限制由服务内校验
+		graphqlRateLimitMW := middleware.RateLimitMW("graphql")
+		publicGroup.GET("/graphql", core.Handle(graphql.Query), loginAuthWithJSON, graphqlRateLimitMW)
+		publicGroup.POST("/graphql", core.Handle(graphql.Query), loginAuthWithJSON, graphqlRateLimitMW)
+		publicGroup.GET("/graphql/schema", core.Handle(graphql.Schema), loginAuthWithJSON)
+
+		// 飞书消息卡片回调，通过 verificationToken 校验请求来源
+		publicGroup.POST("/notice/feishu/callback", feishu.Callback)
 	}
 
 	userGroup := g.Group("/user")
 	{
 		// user
 		userGroup.POST("/login", user.Login)
+		userGroup.POST("/login/totp", core.Handle(user.LoginTOTP))
+		userGroup.POST("/login/password", core.Handle(user.ChangeExpiredPassword))
+		userGroup.GET("/login/oidc", user.LoginOIDC)
 		userGroup.GET("/login/:oauth", user.LoginOauth)
 		userGroup.POST("/create", user.Create, loginAuthWithJSON)
 		userGroup.POST("/update", user.Update, loginAuthWithJSON)
 		userGroup.GET("/list", user.List, loginAuthWithJSON)
 		userGroup.POST("/delete", user.Delete, loginAuthWithJSON)
+		userGroup.GET("/session/list", core.Handle(user.UserSessionList), loginAuthWithJSON)
+		userGroup.POST("/session/revoke", core.Handle(user.UserSessionRevoke), loginAuthWithJSON)
+		userGroup.POST("/session/revokeAll", core.Handle(user.UserSessionRevokeAll), loginAuthWithJSON)
+		userGroup.POST("/totp/reset", core.Handle(user.TOTPReset), loginAuthWithJSON)
+		userGroup.GET("/lock/list", core.Handle(user.LoginLockList), loginAuthWithJSON)
+		userGroup.POST("/unlock", core.Handle(user.UnlockLogin), loginAuthWithJSON)
 	}
 
 	confgoGroup := g.Group("/confgo", loginAuthWithJSON)
@@ -144,22 +241,28 @@ func apiAdmin(server *xecho.Server) {
 		configReadByIDMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromConfigID, db.AppPermConfigRead)
 		configWriteByIDMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromConfigID, db.AppPermConfigWrite)
 		configReadInstanceMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromConfigID, db.AppPermConfigReadInstance)
-
-		configV2G.POST("/config/lock", core.Handle(confgov2.Lock), configWriteByIDMW)                                      // 获取配置编辑锁
-		configV2G.POST("/config/unlock", core.Handle(confgov2.Unlock), configWriteByIDMW)                                  // 解锁配置
-		configV2G.GET("/config/list", confgov2.List, configReadQueryMW)                                                    // 配置文件列表
-		configV2G.GET("/config/detail", confgov2.Detail, configReadByIDMW)                                                 // 配置文件内容
-		configV2G.POST("/config/create", confgov2.Create, configWriteBodyMW)                                               // 配置新建
-		configV2G.POST("/config/update", confgov2.Update, configWriteByIDMW)                                               // 配置更新
-		configV2G.POST("/config/publish", core.Handle(confgov2.Publish), configWriteByIDMW)                                // 配置发布
-		configV2G.GET("/config/history", confgov2.History, configReadByIDMW)                                               // 配置文件历史
-		configV2G.POST("/config/delete", confgov2.Delete, configWriteByIDMW)                                               // 配置删除
-		configV2G.GET("/config/diff", confgov2.Diff, configReadByIDMW)                                                     // 配置文件Diif，返回两个版本的配置内容
-		configV2G.GET("/config/instance/list", confgov2.InstanceList, configReadByIDMW)                                    // 配置文件Diif，返回两个版本的配置内容
-		configV2G.GET("/config/instance/configContent", core.Handle(confgov2.InstanceConfigContent), configReadInstanceMW) // 读取机器上的配置文件
-		configV2G.GET("/config/statics", configstatics.Statics)                                                            // 全局的统计信息，不走应用权限
-
-		configV2G.POST("/app/action", confgov2.AppAction, configWriteBodyMW)
+		configPublishByIDMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromConfigID, db.AppPermConfigPublish)
+		configPublishTwoFactorMW := middleware.TwoFactorSensitiveMW(middleware.ParseAppEnvFromConfigID)
+		configZoneBodyMW := middleware.ZoneScopeMW(middleware.ParseZoneFromContext)
+		configZoneByIDMW := middleware.ZoneScopeMW(middleware.ParseZoneFromConfigID)
+
+		configV2G.POST("/config/lock", core.Handle(confgov2.Lock), configWriteByIDMW, configZoneByIDMW)                                                         // 获取配置编辑锁
+		configV2G.POST("/config/unlock", core.Handle(confgov2.Unlock), configWriteByIDMW, configZoneByIDMW)                                                     // 解锁配置
+		configV2G.GET("/config/list", confgov2.List, configReadQueryMW)                                                                                         // 配置文件列表
+		configV2G.GET("/config/detail", confgov2.Detail, configReadByIDMW, configZoneByIDMW)                                                                    // 配置文件内容
+		configV2G.POST("/config/create", confgov2.Create, configWriteBodyMW, configZoneBodyMW)                                                                  // 配置新建
+		configV2G.POST("/config/update", confgov2.Update, configWriteByIDMW, configZoneByIDMW)                                                                  // 配置更新
+		configV2G.POST("/config/publish", core.Handle(confgov2.Publish), configPublishByIDMW, configZoneByIDMW, configPublishTwoFactorMW)                       // 配置发布
+		configV2G.GET("/config/history", confgov2.History, configReadByIDMW, configZoneByIDMW)                                                                  // 配置文件历史
+		configV2G.POST("/config/delete", confgov2.Delete, configWriteByIDMW, configZoneByIDMW)                                                                  // 配置删除
+		configV2G.GET("/config/diff", confgov2.Diff, configReadByIDMW, configZoneByIDMW)                                                                        // 配置文件Diif，返回两个版本的配置内容
+		configV2G.GET("/config/promotion/preview", core.Handle(promotion.ConfigPreview), configReadByIDMW, configZoneByIDMW)                                    // 预览晋升到下一个环境的差异
+		configV2G.POST("/config/promotion/create", core.Handle(promotion.ConfigCreate), configReadByIDMW, configZoneByIDMW)                                     // 发起配置晋升，审批通过后写入目标环境
+		configV2G.GET("/config/instance/list", confgov2.InstanceList, configReadByIDMW, configZoneByIDMW)                                                       // 配置文件Diif，返回两个版本的配置内容
+		configV2G.GET("/config/instance/configContent", core.Handle(confgov2.InstanceConfigContent), configReadInstanceMW, configZoneByIDMW, governanceAuditMW) // 读取机器上的配置文件
+		configV2G.GET("/config/statics", configstatics.Statics)                                                                                                 // 全局的统计信息，不走应用权限
+
+		configV2G.POST("/app/action", confgov2.AppAction, configWriteBodyMW, configZoneBodyMW, governanceAuditMW)
 
 		resourceG := configV2G.Group("/resource")
 		resourceG.GET("/list", configresource.List)
@@ -182,12 +285,33 @@ func apiAdmin(server *xecho.Server) {
 		resourceGroup.POST("/app/delete", resource.AppDelete)
 		resourceGroup.GET("/app/grpcAddrList", core.Handle(resource.GrpcAddrList))
 		resourceGroup.GET("/app/httpAddrList", core.Handle(resource.HttpAddrList))
+		resourceGroup.GET("/app/k8s/workloads", resource.AppWorkloadList)
+		resourceGroup.GET("/k8s/cluster/list", core.Handle(k8scluster.List))
+		resourceGroup.POST("/k8s/cluster/save", core.Handle(k8scluster.Save))
+		resourceGroup.POST("/k8s/cluster/delete", core.Handle(k8scluster.Delete))
+		resourceGroup.POST("/k8s/cluster/check", core.Handle(k8scluster.Check))
+		resourceGroup.POST("/k8s/cluster/import", core.Handle(k8scluster.ImportSetting))
+		resourceGroup.GET("/app/import/list", core.Handle(appimport.List))
+		resourceGroup.POST("/app/import/scan", core.Handle(appimport.Scan))
+		resourceGroup.POST("/app/import/import", core.Handle(appimport.Import))
+		resourceGroup.POST("/app/import/ignore", core.Handle(appimport.Ignore))
+		resourceGroup.GET("/app/meta/field/list", core.Handle(resource.AppMetaFieldList))
+		resourceGroup.POST("/app/meta/field/save", core.Handle(resource.AppMetaFieldSave))
+		resourceGroup.POST("/app/meta/field/delete", core.Handle(resource.AppMetaFieldDelete))
+		resourceGroup.POST("/app/meta/set", core.Handle(resource.AppMetaSet))
+		resourceGroup.POST("/app/status/set", core.Handle(applifecycle.SetStatus))
+		resourceGroup.GET("/app/archive/list", core.Handle(applifecycle.ArchiveList))
+		resourceGroup.GET("/app/archive/export", core.Handle(applifecycle.ArchiveExport))
+		resourceGroup.GET("/app/catalog/export", core.Handle(resource.CatalogExport))
+		resourceGroup.POST("/app/tag/set", core.Handle(tag.SetApp))
 
 		resourceGroup.GET("/zone/info", resource.ZoneInfo)
 		resourceGroup.GET("/zone/list", resource.ZoneList)
 		resourceGroup.POST("/zone/create", resource.ZoneCreate)
 		resourceGroup.POST("/zone/update", resource.ZoneUpdate)
 		resourceGroup.POST("/zone/delete", resource.ZoneDelete)
+		resourceGroup.POST("/zone/batch", core.Handle(resource.ZoneBatch))
+		resourceGroup.POST("/zone/import", core.Handle(resource.ZoneImport))
 		resourceGroup.GET("/zone/zone_env", resource.ZoneEnv)
 
 		resourceGroup.GET("/node/info", resource.NodeInfo)
@@ -195,7 +319,11 @@ func apiAdmin(server *xecho.Server) {
 		resourceGroup.POST("/node/create", resource.NodeCreate)
 		resourceGroup.POST("/node/update", resource.NodeUpdate)
 		resourceGroup.POST("/node/delete", resource.NodeDelete)
+		resourceGroup.POST("/node/batch", core.Handle(resource.NodeBatch))
+		resourceGroup.POST("/node/import", core.Handle(resource.NodeImport))
 		resourceGroup.GET("/node/statics", resource.NodeStatics)
+		resourceGroup.GET("/node/metrics", resource.NodeMetricList)
+		resourceGroup.POST("/node/tag/set", core.Handle(tag.SetNode))
 
 		resourceGroup.GET("/node/transfer/list", resource.NodeTransferList)
 		resourceGroup.POST("/node/transfer/put", resource.NodeTransferPut)
@@ -205,10 +333,46 @@ func apiAdmin(server *xecho.Server) {
 		resourceGroup.POST("/app_node/put", resource.AppNodePut)
 		resourceGroup.GET("/app_node/transfer/list", resource.AppNodeTransferList)
 		resourceGroup.POST("/app_node/transfer/put", resource.AppNodeTransferPut)
+		resourceGroup.GET("/app_node/deployment/list", core.Handle(deployment.List))
+		resourceGroup.GET("/app_node/deployment/latest", core.Handle(deployment.Latest))
 
 		resourceGroup.GET("/app_env_zone/list", resource.AppEnvZoneList)
 	}
 
+	agentGroup := g.Group("/agent", loginAuthWithJSON)
+	{
+		// agent 配置热更新
+		agentGroup.GET("/config/list", core.Handle(agent.ListConfig))
+		agentGroup.POST("/config/create", core.Handle(agent.CreateConfig))
+		agentGroup.POST("/config/update", core.Handle(agent.UpdateConfig))
+		agentGroup.POST("/config/delete", core.Handle(agent.DeleteConfig))
+		agentGroup.POST("/config/publish", core.Handle(agent.PublishConfig))
+		agentGroup.GET("/config/effective", core.Handle(agent.EffectiveConfig))
+
+		// systemd/supervisord 进程状态
+		mwAppReadAuth := middleware.CasbinAppMW(middleware.ParseAppEnvFromContext, db.AppPermAppRead)
+		mwProcessRestartAuth := middleware.CasbinAppMW(middleware.ParseAppEnvFromContext, db.AppPermProcessRestart)
+		mwProcessZone := middleware.ZoneScopeMW(middleware.ParseZoneFromContext)
+		agentGroup.GET("/process/status", core.Handle(agent.ProcessStatus), mwAppReadAuth, mwProcessZone, governanceAuditMW)
+		agentGroup.POST("/process/restart", core.Handle(agent.ProcessRestart), mwProcessRestartAuth, mwProcessZone, governanceAuditMW)
+
+		// agent 滚动升级
+		agentGroup.POST("/package/upload", core.Handle(agent.UploadPackage))
+		agentGroup.GET("/package/list", core.Handle(agent.ListPackage))
+		agentGroup.POST("/upgrade/create", core.Handle(agent.CreateUpgrade))
+		agentGroup.GET("/upgrade/list", core.Handle(agent.ListUpgrade))
+		agentGroup.GET("/upgrade/detail", core.Handle(agent.UpgradeDetail))
+		agentGroup.POST("/upgrade/pause", core.Handle(agent.PauseUpgrade))
+		agentGroup.POST("/upgrade/resume", core.Handle(agent.ResumeUpgrade))
+		agentGroup.POST("/upgrade/cancel", core.Handle(agent.CancelUpgrade))
+
+		// agent 离线检测
+		agentGroup.GET("/offline/list", core.Handle(agent.ListOffline))
+		agentGroup.GET("/offline/flapping", core.Handle(agent.ListFlapping))
+		agentGroup.GET("/offline/threshold/list", core.Handle(agent.ListOfflineThreshold))
+		agentGroup.POST("/offline/threshold/set", core.Handle(agent.SetOfflineThreshold))
+	}
+
 	// 测试平台组
 	testGroup := g.Group("/test", loginAuthWithJSON)
 	{
@@ -248,14 +412,56 @@ func apiAdmin(server *xecho.Server) {
 		// 自动化测试平台
 		platformG := testGroup.Group("/platform")
 		{
-			platformG.POST("/pipeline/create", core.Handle(platform.CreatePipeline))
-			platformG.GET("/pipeline/list", core.Handle(platform.ListPipeline))
-			platformG.POST("/pipeline/update", core.Handle(platform.UpdatePipeline))
-			platformG.POST("/pipeline/run", core.Handle(platform.RunPipeline))
-			platformG.GET("/pipeline/tasks", core.Handle(platform.TaskList))
-			platformG.POST("/pipeline/delete", core.Handle(platform.DeletePipeline))
-			platformG.GET("/pipeline/tasks/steps", core.Handle(platform.TaskSteps))
+			pipelineReadMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromContext, db.AppPermPipelineRead)
+			pipelineWriteMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromContext, db.AppPermPipelineWrite)
+			pipelineReadByIDMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromPipelineID, db.AppPermPipelineRead)
+			pipelineWriteByIDMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromPipelineID, db.AppPermPipelineWrite)
+			pipelineRunByIDMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromPipelineID, db.AppPermPipelineRun)
+			pipelineTasksMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromPipelineQuery, db.AppPermPipelineRead)
+			pipelineTaskStepsMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromTaskID, db.AppPermPipelineRead)
+			pipelineTaskRunMW := middleware.CasbinAppMW(middleware.ParseAppEnvFromTaskID, db.AppPermPipelineRun)
+			pipelineZoneMW := middleware.ZoneScopeMW(middleware.ParseZoneFromContext)
+			pipelineZoneByIDMW := middleware.ZoneScopeMW(middleware.ParseZoneFromPipelineID)
+			pipelineTasksZoneMW := middleware.ZoneScopeMW(middleware.ParseZoneFromPipelineQuery)
+			pipelineTaskStepsZoneMW := middleware.ZoneScopeMW(middleware.ParseZoneFromTaskID)
+
+			platformG.POST("/pipeline/create", core.Handle(platform.CreatePipeline), pipelineWriteMW, pipelineZoneMW)
+			platformG.GET("/pipeline/list", core.Handle(platform.ListPipeline), pipelineReadMW)
+			platformG.POST("/pipeline/update", core.Handle(platform.UpdatePipeline), pipelineWriteByIDMW, pipelineWriteMW, pipelineZoneByIDMW, pipelineZoneMW)
+			platformG.POST("/pipeline/run", core.Handle(platform.RunPipeline), pipelineRunByIDMW, pipelineZoneByIDMW)
+			platformG.GET("/pipeline/tasks", core.Handle(platform.TaskList), pipelineTasksMW, pipelineTasksZoneMW)
+			platformG.POST("/pipeline/delete", core.Handle(platform.DeletePipeline), pipelineWriteByIDMW, pipelineZoneByIDMW)
+			platformG.GET("/pipeline/tasks/steps", core.Handle(platform.TaskSteps), pipelineTaskStepsMW, pipelineTaskStepsZoneMW)
+			platformG.GET("/pipeline/tasks/info", core.Handle(platform.TaskInfo), pipelineTaskStepsMW, pipelineTaskStepsZoneMW)
+			platformG.POST("/pipeline/tasks/cancel", core.Handle(platform.CancelTask), pipelineTaskRunMW, pipelineTaskStepsZoneMW)
+			platformG.GET("/pipeline/tasks/artifacts", core.Handle(platform.TaskArtifacts), pipelineTaskStepsMW, pipelineTaskStepsZoneMW)
+			platformG.GET("/pipeline/tasks/artifact", core.Handle(platform.TaskArtifact), pipelineTaskStepsMW, pipelineTaskStepsZoneMW)
+			platformG.GET("/pipeline/tasks/vulnerabilities", core.Handle(platform.TaskVulnerabilities), pipelineTaskStepsMW, pipelineTaskStepsZoneMW)
+			platformG.GET("/pipeline/tasks/buildReports", core.Handle(platform.TaskBuildReports), pipelineTaskStepsMW, pipelineTaskStepsZoneMW)
+			platformG.GET("/pipeline/tasks/coverage", core.Handle(platform.TaskCoverage), pipelineTaskStepsMW, pipelineTaskStepsZoneMW)
+			platformG.GET("/pipeline/tasks/benchmarks", core.Handle(platform.TaskBenchmarks), pipelineTaskStepsMW, pipelineTaskStepsZoneMW)
+			platformG.GET("/pipeline/tasks/compare", core.Handle(platform.CompareTasks), pipelineTaskStepsMW, pipelineTaskStepsZoneMW)
+			platformG.GET("/pipeline/buildReports", core.Handle(platform.BuildReportTrend), pipelineTasksMW, pipelineTasksZoneMW)
+			platformG.GET("/pipeline/benchmarks", core.Handle(platform.BenchmarkHistory), pipelineTasksMW, pipelineTasksZoneMW)
+			platformG.GET("/pipeline/logs/search", core.Handle(platform.SearchLogs), pipelineReadMW)
+			platformG.GET("/pipeline/coverage", core.Handle(platform.CoverageTrend), pipelineReadMW)
+			platformG.GET("/secret/list", core.Handle(platform.ListSecrets), pipelineReadMW)      // 流水线密钥，不返回值
+			platformG.POST("/secret/save", core.Handle(platform.SaveSecret), pipelineWriteMW)     // 创建或更新密钥
+			platformG.POST("/secret/delete", core.Handle(platform.DeleteSecret), pipelineWriteMW) // 删除密钥
+			platformG.GET("/pipeline/promotion/preview", core.Handle(promotion.PipelinePreview), pipelineReadByIDMW, pipelineZoneByIDMW)
+			platformG.POST("/pipeline/promotion/create", core.Handle(promotion.PipelineCreate), pipelineReadByIDMW, pipelineZoneByIDMW)
+			platformG.POST("/pipeline/tag/set", core.Handle(tag.SetPipeline), pipelineWriteByIDMW, pipelineZoneByIDMW)
 			platformG.GET("/worker/zones", core.Handle(platform.WorkerZones))
+			platformG.GET("/worker/nodes", core.Handle(platform.WorkerNodes))
+			platformG.GET("/worker/queue", core.Handle(platform.WorkerQueue))                        // worker 等待队列
+			platformG.POST("/worker/queue/remove", core.Handle(platform.RemoveQueuedTask))           // 删除等待中的任务
+			platformG.POST("/worker/queue/moveToFront", core.Handle(platform.MoveQueuedTaskToFront)) // 等待中的任务移到队首
+			platformG.POST("/worker/pause", core.Handle(platform.PauseWorker))                       // 暂停 worker 消费队列
+			platformG.POST("/worker/resume", core.Handle(platform.ResumeWorker))                     // 恢复 worker 消费队列
+			platformG.POST("/worker/drain", core.Handle(platform.DrainWorker))                       // 不再向 worker 下发新任务
+			platformG.POST("/worker/undrain", core.Handle(platform.UndrainWorker))                   // 恢复向 worker 下发任务
+			platformG.GET("/worker/drain/status", core.Handle(platform.WorkerDrainStatus))           // worker drain 状态
+			platformG.GET("/worker/queue/stats", core.Handle(platform.WorkerQueueStats))             // 各机房排队情况
 		}
 	}
 
@@ -274,14 +480,18 @@ func apiAdmin(server *xecho.Server) {
 		taskG.GET("/detail", core.Handle(cronjob.DetailTask)) // 任务详情（日志等）
 	}
 
+	// 注册中心数据全量读取 etcd，限流避免打满 etcd
+	registryRateLimitMW := middleware.RateLimitMW("registry")
+
 	analysisGroup := g.Group("/analysis", loginAuthWithJSON)
 	{
 		analysisGroup.GET("/index", core.Handle(analysis.Index))
 		analysisGroup.GET("/topology/select", analysis.TopologySelect)
 		analysisGroup.GET("/topology/list", analysis.TopologyList)
 		analysisGroup.GET("/topology/relationship", analysis.TopologyRelationship)
+		analysisGroup.GET("/topology/dependency", core.Handle(analysis.AppDependency))
 		analysisGroup.GET("/deppkg/list", analysis.DependenceList)
-		analysisGroup.GET("/register/list", core.Handle(etcdHandle.ProTableList))
+		analysisGroup.GET("/register/list", core.Handle(etcdHandle.ProTableList), registryRateLimitMW)
 	}
 
 	systemGroup := g.Group("/system", loginAuthWithJSON)
@@ -296,6 +506,12 @@ func apiAdmin(server *xecho.Server) {
 		// 系统设置
 		systemGroup.GET("/setting/list", system.SettingList)
 		systemGroup.POST("/setting/update", system.SettingUpdate)
+
+		// 重新加载配置文件
+		systemGroup.POST("/config/reload", system.ConfigReload)
+
+		// 导出备份
+		systemGroup.POST("/backup", system.Backup)
 	}
 
 	permissionG := g.Group("/permission", loginAuthWithJSON)
@@ -335,6 +551,25 @@ func apiAdmin(server *xecho.Server) {
 		permissionG.GET("/menu/list", permission.ListMenu)
 		// 菜单-API权限树
 		permissionG.GET("/permissionTree", permission.MenuAPITree)
+
+		// 策略管理
+		permissionG.GET("/policy/list", core.Handle(permission.ListPolicy))
+		permissionG.POST("/policy/create", core.Handle(permission.CreatePolicy))
+		permissionG.POST("/policy/update", core.Handle(permission.UpdatePolicy))
+		permissionG.POST("/policy/delete", core.Handle(permission.DeletePolicy))
+		// 用户组绑定
+		permissionG.GET("/binding/list", core.Handle(permission.ListRoleBinding))
+		permissionG.POST("/binding/create", core.Handle(permission.CreateRoleBinding))
+		permissionG.POST("/binding/delete", core.Handle(permission.DeleteRoleBinding))
+		// 查询拥有某个权限的用户
+		permissionG.GET("/whoCan", core.Handle(permission.WhoCan))
+		// 查询用户是否拥有某个权限
+		permissionG.GET("/check", core.Handle(permission.CheckPolicy))
+		// 重新加载资源文件和策略
+		permissionG.POST("/reload", core.Handle(permission.ReloadPolicy))
+		// 机房访问限制
+		permissionG.GET("/zoneScope/list", core.Handle(permission.ListZoneScope))
+		permissionG.POST("/zoneScope/set", core.Handle(permission.SetZoneScope))
 	}
 
 	eventGroup := g.Group("/event", loginAuthWithJSON)
@@ -342,12 +577,98 @@ func apiAdmin(server *xecho.Server) {
 		eventGroup.GET("/list", event.List)
 	}
 
+	proxyAuditGroup := g.Group("/proxyAudit", loginAuthWithJSON)
+	{
+		proxyAuditGroup.GET("/list", core.Handle(proxyaudit.List))
+	}
+
+	auditLogGroup := g.Group("/auditLog", loginAuthWithJSON)
+	{
+		auditLogGroup.GET("/list", core.Handle(auditlog.List))
+		auditLogGroup.GET("/export", core.Handle(auditlog.Export))
+	}
+
+	// 回收站，非管理员只能查看、恢复自己删除的对象
+	recycleBinGroup := g.Group("/recycleBin", loginAuthWithJSON)
+	{
+		recycleBinGroup.GET("/list", core.Handle(recyclebin.List))
+		recycleBinGroup.POST("/restore", core.Handle(recyclebin.Restore))
+		recycleBinGroup.POST("/purge", core.Handle(recyclebin.Purge))
+	}
+
+	serviceAccountGroup := g.Group("/serviceAccount", loginAuthWithJSON)
+	{
+		serviceAccountGroup.GET("/list", core.Handle(serviceaccount.List))
+		serviceAccountGroup.GET("/scopes", core.Handle(serviceaccount.Scopes))
+		serviceAccountGroup.POST("/create", core.Handle(serviceaccount.Create))
+		serviceAccountGroup.POST("/update", core.Handle(serviceaccount.Update))
+		serviceAccountGroup.POST("/delete", core.Handle(serviceaccount.Delete))
+		serviceAccountGroup.POST("/rotate", core.Handle(serviceaccount.Rotate))
+		serviceAccountGroup.POST("/credential/revoke", core.Handle(serviceaccount.RevokeCredential))
+	}
+
+	teamGroup := g.Group("/team", loginAuthWithJSON)
+	{
+		teamGroup.GET("/list", core.Handle(team.List))
+		teamGroup.GET("/detail", core.Handle(team.Detail))
+		teamGroup.GET("/mine", core.Handle(team.Mine))
+		teamGroup.POST("/create", core.Handle(team.Create))
+		teamGroup.POST("/update", core.Handle(team.Update))
+		teamGroup.POST("/delete", core.Handle(team.Delete))
+		teamGroup.POST("/member/set", core.Handle(team.SetMember))
+		teamGroup.POST("/member/remove", core.Handle(team.RemoveMember))
+		teamGroup.POST("/app/set", core.Handle(team.SetApp))
+	}
+
+	// 应用、节点、流水线标签
+	tagGroup := g.Group("/tag", loginAuthWithJSON)
+	{
+		tagGroup.GET("/get", core.Handle(tag.Get))
+		tagGroup.GET("/suggest", core.Handle(tag.Suggest))
+	}
+
+	notifyRuleGroup := g.Group("/notify/rule", loginAuthWithJSON)
+	{
+		notifyRuleGroup.GET("/list", core.Handle(notifyrule.List))
+		notifyRuleGroup.POST("/create", core.Handle(notifyrule.Create))
+		notifyRuleGroup.POST("/update", core.Handle(notifyrule.Update))
+		notifyRuleGroup.POST("/delete", core.Handle(notifyrule.Delete))
+	}
+
+	notifyTemplateGroup := g.Group("/notify/template", loginAuthWithJSON)
+	{
+		notifyTemplateGroup.GET("/list", core.Handle(notifytemplate.List))
+		notifyTemplateGroup.POST("/save", core.Handle(notifytemplate.Save))
+		notifyTemplateGroup.POST("/delete", core.Handle(notifytemplate.Delete))
+		notifyTemplateGroup.POST("/preview", core.Handle(notifytemplate.Preview))
+		notifyTemplateGroup.POST("/test", core.Handle(notifytemplate.Test))
+	}
+
+	onCallGroup := g.Group("/oncall", loginAuthWithJSON)
+	{
+		onCallGroup.GET("/rotation/list", core.Handle(oncall.ListRotation))
+		onCallGroup.GET("/rotation/detail", core.Handle(oncall.Rotation))
+		onCallGroup.POST("/rotation/set", core.Handle(oncall.SetRotation))
+		onCallGroup.POST("/rotation/delete", core.Handle(oncall.DeleteRotation))
+		onCallGroup.GET("/incident/list", core.Handle(oncall.ListIncident))
+		onCallGroup.POST("/incident/ack", core.Handle(oncall.AckIncident))
+		onCallGroup.POST("/incident/resolve", core.Handle(oncall.ResolveIncident))
+	}
+
+	cmdbGroup := g.Group("/cmdb", loginAuthWithJSON)
+	{
+		cmdbGroup.GET("/source/list", core.Handle(cmdbHandle.SourceList))
+		cmdbGroup.POST("/sync", core.Handle(cmdbHandle.Sync))
+		cmdbGroup.GET("/conflict/list", core.Handle(cmdbHandle.ConflictList))
+		cmdbGroup.POST("/conflict/resolve", core.Handle(cmdbHandle.ResolveConflict))
+	}
+
 	pprofGroup := g.Group("/pprof", loginAuthWithJSON)
 	{
 		mwRunPProfAuth := middleware.CasbinAppMW(middleware.ParseAppEnvFromContext, db.AppPermPProfRun)
 		mwReadPProfAuth := middleware.CasbinAppMW(middleware.ParseAppEnvFromContext, db.AppPermPProfRead)
 
-		pprofGroup.POST("/run", pprofHandle.Run, mwRunPProfAuth)
+		pprofGroup.POST("/run", pprofHandle.Run, mwRunPProfAuth, middleware.ProxyAuditMW(db.ProxyAuditKindPProf))
 		pprofGroup.GET("/list", pprofHandle.FileList, mwReadPProfAuth)
 		pprofGroup.GET("/dep/check", pprofHandle.CheckDep)
 
@@ -355,7 +676,7 @@ func apiAdmin(server *xecho.Server) {
 		pprofGroup.GET("/config/list", pprofHandle.GetSysConfig)
 	}
 
-	etcdGroup := g.Group("/etcd")
+	etcdGroup := g.Group("/etcd", registryRateLimitMW)
 	{
 		etcdGroup.GET("/list", etcdHandle.List)
 	}
@@ -367,7 +688,7 @@ func apiAdmin(server *xecho.Server) {
 		openAuthG.POST("/accessToken/delete", openauth.DeleteAccessToken)
 	}
 
-	loggerGroup := g.Group("/logger", loginAuthWithJSON)
+	loggerGroup := g.Group("/logger", loginAuthWithJSON, middleware.RateLimitMW("logger"))
 	{
 		loggerGroup.GET("/logstore", core.Handle(loggerplatform.LogStore))
 	}
@@ -15,112 +15,196 @@
 package adminengine
 
 import (
+	"github.com/douyu/juno/api/apiv1/agent"
 	"github.com/douyu/juno/api/apiv1/analysis"
 	"github.com/douyu/juno/api/apiv1/confgov2"
+	"github.com/douyu/juno/api/apiv1/deployment"
 	etcdHandle "github.com/douyu/juno/api/apiv1/etcd"
 	"github.com/douyu/juno/api/apiv1/event"
 	pprofHandle "github.com/douyu/juno/api/apiv1/pprof"
 	"github.com/douyu/juno/api/apiv1/resource"
+	"github.com/douyu/juno/api/apiv1/scim"
 	"github.com/douyu/juno/api/apiv1/system"
 	"github.com/douyu/juno/api/apiv1/test/platform"
 	"github.com/douyu/juno/api/apiv1/worker"
 	"github.com/douyu/juno/internal/app/core"
 	"github.com/douyu/juno/internal/app/middleware"
+	"github.com/douyu/juno/pkg/apispec"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
 	"github.com/douyu/jupiter/pkg/server/xecho"
+	"github.com/labstack/echo/v4"
 )
 
 func apiV1(server *xecho.Server) {
 
-	server.POST("/api/v1/resource/node/heartbeat", resource.NodeHeartBeat)
-	server.POST("/api/v1/worker/heartbeat", worker.Heartbeat)
-	server.POST("/api/v1/worker/testTask/update", platform.TaskStepStatusUpdate, middleware.ProxyAuth)
+	// worker、agent 使用服务账号认证
+	annotate(server.POST("/api/v1/resource/node/heartbeat", resource.NodeHeartBeat, middleware.ServiceAccountHeartbeatMW(db.ServiceAccountScopeAgent)),
+		apispec.Doc{Summary: "agent 心跳", Request: view.ReqNodeHeartBeat{}, Security: []string{specServiceAccount}})
+
+	workerAllowlistMW := middleware.IPAllowlistMW("worker", cfg.Cfg.IPAllowlist.Worker)
+	annotate(server.POST("/api/v1/worker/register", worker.Heartbeat, workerAllowlistMW, middleware.ServiceAccountHeartbeatMW(db.ServiceAccountScopeWorker)),
+		apispec.Doc{Summary: "worker 定时注册，上报容量、队列长度和执行中的任务", Request: view.WorkerHeartbeat{}, Security: []string{specServiceAccount}})
+	// 旧版本 worker 的心跳地址
+	annotate(server.POST("/api/v1/worker/heartbeat", worker.Heartbeat, workerAllowlistMW, middleware.ServiceAccountHeartbeatMW(db.ServiceAccountScopeWorker)),
+		apispec.Doc{Summary: "worker 心跳", Request: view.WorkerHeartbeat{}, Security: []string{specServiceAccount}})
+	annotate(server.GET("/api/v1/worker/check", worker.Check, workerAllowlistMW, middleware.ServiceAccountMW(db.ServiceAccountScopeWorker)),
+		apispec.Doc{Summary: "worker 启动时校验服务账号 Token", Security: []string{specServiceAccount}})
+	annotate(server.POST("/api/v1/worker/testTask/update", platform.TaskStepStatusUpdate, workerAllowlistMW, middleware.ServiceAccountMW(db.ServiceAccountScopeWorker)),
+		apispec.Doc{Summary: "worker 上报测试任务步骤状态", Request: view.TestTaskEvent{}, Security: []string{specServiceAccount}})
+	annotate(server.POST("/api/v1/worker/testTask/artifact/prepare", platform.PrepareTaskArtifact, workerAllowlistMW, middleware.ServiceAccountMW(db.ServiceAccountScopeWorker)),
+		apispec.Doc{Summary: "worker 申请制品的对象存储上传地址", Request: view.ReqPrepareTaskArtifact{}, Response: view.TestTaskArtifactUpload{}, Security: []string{specServiceAccount}})
+	annotate(server.GET("/api/v1/worker/testTask/secrets", platform.TaskSecrets, workerAllowlistMW, middleware.ServiceAccountMW(db.ServiceAccountScopeWorker)),
+		apispec.Doc{Summary: "worker 获取测试任务引用的密钥", Request: view.ReqTaskSecrets{}, Response: map[string]string{}, Security: []string{specServiceAccount}})
+	annotate(server.GET("/api/v1/worker/testTask/stream", platform.TaskEventStream, workerAllowlistMW, middleware.ServiceAccountMW(db.ServiceAccountScopeWorker)),
+		apispec.Doc{Summary: "worker 通过 WebSocket 上报测试任务事件，实时推送阶段日志", Request: view.TestTaskStreamMessage{}, Security: []string{specServiceAccount}})
+	annotate(server.GET("/api/v1/agent/config", agent.PullConfig, middleware.ServiceAccountMW(db.ServiceAccountScopeAgent)), apispec.Doc{
+		Summary: "agent 拉取生效的配置",
+		Request: struct {
+			Env      string `query:"env"`
+			ZoneCode string `query:"zone_code"`
+			HostName string `query:"host_name"`
+		}{},
+		Response: view.AgentConfigPayload{},
+		Security: []string{specServiceAccount},
+	})
+	annotate(server.GET("/api/v1/agent/package/download", agent.DownloadPackage, middleware.ServiceAccountMW(db.ServiceAccountScopeAgent)), apispec.Doc{
+		Summary: "下载 agent 安装包",
+		Request: struct {
+			Version string `query:"version"`
+		}{},
+		ContentType: echo.MIMEOctetStream,
+		Security:    []string{specServiceAccount},
+	})
 
 	v1 := server.Group("/api/v1", middleware.OpenAuth)
+	v1.Use(middleware.AuditMW)
 	resourceGroup := v1.Group("/resource")
 	{
 		// 创建应用
-		resourceGroup.POST("/app/put", resource.AppPut)
-		resourceGroup.GET("/app/info", resource.AppInfo) // http://127.0.0.1:9999/api/resourceGroup/app/info/1
-		resourceGroup.GET("/app/list", resource.AppList) // http://127.0.0.1:9999/api/resourceGroup/app/info/1
+		annotate(resourceGroup.POST("/app/put", resource.AppPut), apispec.Doc{Summary: "批量创建或更新应用", Request: resource.ReqAppPut{}})
+		annotate(resourceGroup.GET("/app/info", resource.AppInfo), apispec.Doc{Summary: "应用详情", Request: resource.ReqAppInfo{}, Response: db.AppInfo{}})
+		annotate(resourceGroup.GET("/app/list", resource.AppList), apispec.Doc{Summary: "应用列表", Request: resource.ReqAppList{}, Response: page([]db.AppInfo{})})
+		// 服务目录，供 Backstage 或文档生成工具拉取
+		annotate(resourceGroup.GET("/app/catalog/export", core.Handle(resource.CatalogExport)),
+			apispec.Doc{Summary: "导出服务目录", Request: view.ReqCatalogExport{}, Response: view.Catalog{}, Raw: true})
 
 		// 创建机房信息
-		resourceGroup.POST("/zone/put", resource.ZonePut)
+		annotate(resourceGroup.POST("/zone/put", resource.ZonePut), apispec.Doc{Summary: "批量创建或更新机房", Request: resource.ReqZonePut{}})
 		// 获取机房信息
-		resourceGroup.GET("/zone/info", resource.ZoneInfo)
+		annotate(resourceGroup.GET("/zone/info", resource.ZoneInfo), apispec.Doc{Summary: "机房详情", Request: resource.ReqZoneInfo{}, Response: db.Zone{}})
 		// 获取机房列表
-		resourceGroup.GET("/zone/list", resource.ZoneList)
+		annotate(resourceGroup.GET("/zone/list", resource.ZoneList), apispec.Doc{Summary: "机房列表", Request: resource.ReqZoneList{}, Response: page([]db.Zone{})})
 
 		// 创建节点
-		resourceGroup.POST("/node/put", resource.NodePut)
+		annotate(resourceGroup.POST("/node/put", resource.NodePut), apispec.Doc{Summary: "批量创建或更新节点", Request: resource.ReqNodePut{}})
 		// 获取节点信息 identify可以是id，也可以是hostname
-		resourceGroup.GET("/node/info", resource.NodeInfo)
-		resourceGroup.GET("/node/list", resource.NodeList)
-		resourceGroup.GET("/node/transfer/list", resource.NodeTransferList)
-		resourceGroup.GET("/node/transfer/put", resource.NodeTransferPut)
+		annotate(resourceGroup.GET("/node/info", resource.NodeInfo), apispec.Doc{Summary: "节点详情", Request: resource.ReqNodeInfo{}, Response: db.Node{}})
+		annotate(resourceGroup.GET("/node/list", resource.NodeList), apispec.Doc{Summary: "节点列表", Request: resource.ReqNodeList{}, Response: page([]db.Node{})})
+		annotate(resourceGroup.GET("/node/transfer/list", resource.NodeTransferList), apispec.Doc{Summary: "可迁移到机房的节点", Request: resource.ReqNodeTransferList{}})
+		annotate(resourceGroup.GET("/node/transfer/put", resource.NodeTransferPut), apispec.Doc{Summary: "迁移节点到机房", Request: resource.ReqNodeTransferPut{}})
 
 		// 创建应用和节点关系
-		resourceGroup.POST("/app_node/put", resource.AppNodePut)
+		annotate(resourceGroup.POST("/app_node/put", resource.AppNodePut), apispec.Doc{Summary: "批量创建或更新应用节点", Request: resource.ReqAppNodePut{}})
 		// 根据应用和节点的id查询，应用节点关系信息
-		resourceGroup.GET("/app_node/info", resource.AppNodeInfo)
+		annotate(resourceGroup.GET("/app_node/info", resource.AppNodeInfo), apispec.Doc{Summary: "应用节点详情", Request: resource.ReqAppNodeInfo{}, Response: db.AppNode{}})
 		// 获取全部应用节点的列表
 		// 根据应用的id或者应用名称，获取节点列表
 		// 根据节点的hostname，获取应用列表
-		resourceGroup.GET("/app_node/list", resource.AppNodeList)
+		annotate(resourceGroup.GET("/app_node/list", resource.AppNodeList), apispec.Doc{Summary: "应用节点列表", Request: resource.ReqAppNodeList{}, Response: page([]db.AppNode{})})
 
-		resourceGroup.GET("/app_env_zone/list", resource.AppEnvZoneList)
+		annotate(resourceGroup.GET("/app_env_zone/list", resource.AppEnvZoneList), apispec.Doc{Summary: "应用部署的环境和机房", Request: resource.ReqAppEnvNodeList{}})
 	}
 
 	configurationGroup := v1.Group("/confgo")
 	{
-		configurationGroup.GET("/config/list", confgov2.List)                     // 配置文件列表
-		configurationGroup.GET("/config/detail", confgov2.Detail)                 // 配置文件内容
-		configurationGroup.GET("/config/diff", confgov2.Diff)                     // 配置文件Diif，返回两个版本的配置内容
-		configurationGroup.GET("/config/instance/list", confgov2.InstanceList)    // 配置发布后各实例同步状态
-		configurationGroup.GET("/config/history", confgov2.History)               // 配置文件历史
-		configurationGroup.POST("/config/create", confgov2.Create)                // 配置新建
-		configurationGroup.POST("/config/update", confgov2.Update)                // 配置更新
-		configurationGroup.POST("/config/publish", core.Handle(confgov2.Publish)) // 配置发布
-		configurationGroup.POST("/config/delete", confgov2.Delete)                // 配置删除
+		annotate(configurationGroup.GET("/config/list", confgov2.List), apispec.Doc{Summary: "配置文件列表", Request: view.ReqListConfig{}, Response: view.RespListConfig{}})
+		annotate(configurationGroup.GET("/config/detail", confgov2.Detail), apispec.Doc{Summary: "配置文件内容", Request: view.ReqDetailConfig{}, Response: view.RespDetailConfig{}})
+		annotate(configurationGroup.GET("/config/diff", confgov2.Diff), apispec.Doc{Summary: "配置文件 Diff，返回两个版本的配置内容", Request: view.ReqDiffConfig{}, Response: view.RespDiffConfig{}})
+		annotate(configurationGroup.GET("/config/instance/list", confgov2.InstanceList), apispec.Doc{Summary: "配置发布后各实例同步状态", Request: view.ReqConfigInstanceList{}, Response: view.RespConfigInstanceList{}})
+		annotate(configurationGroup.GET("/config/history", confgov2.History), apispec.Doc{Summary: "配置文件历史", Request: view.ReqHistoryConfig{}, Response: view.RespHistoryConfig{}})
+		annotate(configurationGroup.POST("/config/create", confgov2.Create), apispec.Doc{Summary: "配置新建", Request: view.ReqCreateConfig{}, Response: view.RespDetailConfig{}})
+		annotate(configurationGroup.POST("/config/update", confgov2.Update), apispec.Doc{Summary: "配置更新", Request: view.ReqUpdateConfig{}})
+		annotate(configurationGroup.POST("/config/publish", core.Handle(confgov2.Publish)), apispec.Doc{Summary: "配置发布", Request: view.ReqPublishConfig{}})
+		annotate(configurationGroup.POST("/config/delete", confgov2.Delete), apispec.Doc{Summary: "配置删除", Request: view.ReqDeleteConfig{}})
 	}
 
+	// CI 部署完成后上报部署记录
+	annotate(v1.POST("/deployment/report", core.Handle(deployment.Report)), apispec.Doc{
+		Summary:  "上报部署记录",
+		Request:  view.ReqReportDeployment{},
+		Response: apispec.Object{"count": 0},
+	})
+
 	analysisGroup := v1.Group("/analysis")
 	{
-		analysisGroup.GET("/index", core.Handle(analysis.Index))
-		analysisGroup.GET("/topology/select", analysis.TopologySelect)
-		analysisGroup.GET("/topology/list", analysis.TopologyList)
-		analysisGroup.GET("/topology/relationship", analysis.TopologyRelationship)
-		analysisGroup.GET("/deppkg/list", analysis.DependenceList)
+		annotate(analysisGroup.GET("/index", core.Handle(analysis.Index)), apispec.Doc{Summary: "应用依赖分析"})
+		annotate(analysisGroup.GET("/topology/select", analysis.TopologySelect), apispec.Doc{Summary: "拓扑筛选项"})
+		annotate(analysisGroup.GET("/topology/list", analysis.TopologyList), apispec.Doc{Summary: "拓扑列表", Request: analysis.ReqTopologyList{}, Response: page([]db.AppTopology{})})
+		annotate(analysisGroup.GET("/topology/relationship", analysis.TopologyRelationship), apispec.Doc{Summary: "拓扑关系", Request: analysis.ReqTopologyList{}})
+		annotate(analysisGroup.GET("/deppkg/list", analysis.DependenceList), apispec.Doc{Summary: "依赖包列表", Request: analysis.ReqList{}})
 	}
 
 	systemGroup := v1.Group("/system")
 	{
-		systemGroup.GET("/option/info", system.OptionInfo)
-		systemGroup.GET("/option/list", system.OptionList)
-		systemGroup.POST("/option/create", system.OptionCreate)
-		systemGroup.POST("/option/update", system.OptionUpdate)
-		systemGroup.POST("/option/delete", system.OptionDelete)
+		annotate(systemGroup.GET("/option/info", system.OptionInfo), apispec.Doc{Summary: "系统选项详情", Request: view.ReqOptionInfo{}, Response: db.Option{}})
+		annotate(systemGroup.GET("/option/list", system.OptionList), apispec.Doc{Summary: "系统选项列表", Request: view.ReqOptionList{}, Response: page([]db.Option{})})
+		annotate(systemGroup.POST("/option/create", system.OptionCreate), apispec.Doc{Summary: "创建系统选项", Request: view.ReqOptionCreate{}})
+		annotate(systemGroup.POST("/option/update", system.OptionUpdate), apispec.Doc{Summary: "更新系统选项", Request: view.ReqOptionUpdate{}})
+		annotate(systemGroup.POST("/option/delete", system.OptionDelete), apispec.Doc{Summary: "删除系统选项", Request: view.ReqOptionDelete{}})
 
 		// 系统设置
-		systemGroup.GET("/setting/list", system.SettingList)
-		systemGroup.POST("/setting/update", system.SettingUpdate)
+		annotate(systemGroup.GET("/setting/list", system.SettingList), apispec.Doc{Summary: "系统设置", Response: map[string]string{}})
+		annotate(systemGroup.POST("/setting/update", system.SettingUpdate), apispec.Doc{Summary: "更新系统设置", Request: view.ReqUpdateSettings{}})
 	}
 
 	eventGroup := v1.Group("/event")
 	{
-		eventGroup.GET("/list", event.List)
+		annotate(eventGroup.GET("/list", event.List), apispec.Doc{Summary: "事件列表", Request: view.ReqEventList{}, Response: page([]db.AppEvent{})})
 	}
 
 	pprofGroup := v1.Group("/pprof")
 	{
-		pprofGroup.POST("/run", pprofHandle.Run)
-		pprofGroup.GET("/list", pprofHandle.FileList)
-		pprofGroup.GET("/dep/check", pprofHandle.CheckDep)
-		pprofGroup.GET("/config/list", pprofHandle.GetSysConfig)
+		annotate(pprofGroup.POST("/run", pprofHandle.Run), apispec.Doc{Summary: "采集 pprof", Request: view.ReqRunProfile{}})
+		annotate(pprofGroup.GET("/list", pprofHandle.FileList), apispec.Doc{Summary: "pprof 文件列表", Request: view.ReqListPProf{}})
+		annotate(pprofGroup.GET("/dep/check", pprofHandle.CheckDep), apispec.Doc{Summary: "检查 pprof 依赖", Request: db.ReqCheck{}})
+		annotate(pprofGroup.GET("/config/list", pprofHandle.GetSysConfig), apispec.Doc{Summary: "pprof 配置", Request: db.ReqSysConfig{}})
 		//pprofGroup.POST("/config/update", pprofHandle.SetSysConfig)
 	}
 
 	etcdGroup := v1.Group("/etcd")
 	{
-		etcdGroup.GET("/list", etcdHandle.List)
+		annotate(etcdGroup.GET("/list", etcdHandle.List), apispec.Doc{Summary: "etcd 键列表", Request: view.ReqGetEtcdList{}, Response: []view.RespEtcdInfo{}})
+	}
+
+	// 外部扩缩容系统查询各机房的排队情况，缩容前先 drain worker，等待 idle 后再下线
+	workerGroup := v1.Group("/worker")
+	{
+		annotate(workerGroup.GET("/queue/stats", core.Handle(platform.WorkerQueueStats)), apispec.Doc{Summary: "各机房测试任务排队情况", Response: []view.WorkerQueueStat{}})
+		annotate(workerGroup.POST("/drain", core.Handle(platform.DrainWorker)), apispec.Doc{Summary: "drain worker，不再下发新任务", Request: view.ReqDrainWorker{}, Response: view.WorkerDrainStatus{}})
+		annotate(workerGroup.POST("/undrain", core.Handle(platform.UndrainWorker)), apispec.Doc{Summary: "恢复向 worker 下发任务", Request: view.ReqDrainWorker{}, Response: view.WorkerDrainStatus{}})
+		annotate(workerGroup.GET("/drain/status", core.Handle(platform.WorkerDrainStatus)), apispec.Doc{Summary: "worker drain 状态", Request: view.ReqDrainWorker{}, Response: view.WorkerDrainStatus{}})
+	}
+
+	// SCIM 2.0，企业 IdP 使用拥有 scim scope 的服务账号同步用户与团队
+	scimGroup := server.Group("/scim/v2", middleware.SCIMAuthMW, middleware.AuditMW)
+	{
+		scimGroup.GET("/ServiceProviderConfig", scim.ServiceProviderConfig)
+		scimGroup.GET("/ResourceTypes", scim.ResourceTypes)
+
+		scimGroup.GET("/Users", scim.ListUsers)
+		scimGroup.POST("/Users", scim.CreateUser)
+		scimGroup.GET("/Users/:id", scim.GetUser)
+		scimGroup.PUT("/Users/:id", scim.ReplaceUser)
+		scimGroup.PATCH("/Users/:id", scim.PatchUser)
+		scimGroup.DELETE("/Users/:id", scim.DeleteUser)
+
+		scimGroup.GET("/Groups", scim.ListGroups)
+		scimGroup.POST("/Groups", scim.CreateGroup)
+		scimGroup.GET("/Groups/:id", scim.GetGroup)
+		scimGroup.PUT("/Groups/:id", scim.ReplaceGroup)
+		scimGroup.PATCH("/Groups/:id", scim.PatchGroup)
+		scimGroup.DELETE("/Groups/:id", scim.DeleteGroup)
 	}
 }
@@ -0,0 +1,160 @@
+// Copyright 2020 Douyu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminengine
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/pkg/apispec"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg"
+	"github.com/douyu/jupiter/pkg/server/xecho"
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v2"
+)
+
+// 文档中的认证方式
+const (
+	specOpenAuth       = "openAuth"
+	specBearer         = "bearer"
+	specSession        = "session"
+	specServiceAccount = "serviceAccount"
+)
+
+var specSecuritySchemes = map[string]apispec.SecurityScheme{
+	specOpenAuth: {
+		Type:        "apiKey",
+		In:          "query",
+		Name:        "app_id",
+		Description: "开放平台认证，请求需同时携带 app_id、timestamp、nonce_str（16 位）、sign，sign = md5(app_id + nonce_str + secret + timestamp)",
+	},
+	specBearer: {
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "个人访问令牌，Authorization: Bearer <token>",
+	},
+	specSession: {
+		Type: "apiKey",
+		In:   "cookie",
+		Name: "session_juno",
+	},
+	specServiceAccount: {
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "服务账号令牌，也可以通过 Token 请求头传递",
+	},
+}
+
+// annotate 为路由添加文档注解
+func annotate(route *echo.Route, doc apispec.Doc) {
+	apispec.Annotate(route.Method, route.Path, doc)
+}
+
+// page 分页列表接口的 data 结构
+func page(list interface{}) apispec.Object {
+	return apispec.Object{
+		"pagination": view.Pagination{},
+		"list":       list,
+	}
+}
+
+// apiSpec 提供 OpenAPI 文档，文档在首次请求时根据已注册的路由生成
+func apiSpec(server *xecho.Server) {
+	apispec.Secure("/api/v1", specOpenAuth)
+	apispec.Secure("/api/admin", specBearer, specSession)
+	apispec.Secure("/scim/v2", specServiceAccount)
+	apispec.ErrorCodes(specErrorCodes()...)
+
+	var (
+		once     sync.Once
+		specJSON []byte
+		specYAML []byte
+		specErr  error
+	)
+
+	annotate(server.GET("/api/spec", func(c echo.Context) error {
+		once.Do(func() {
+			specJSON, specYAML, specErr = buildSpec(server.Routes())
+		})
+		if specErr != nil {
+			return c.String(http.StatusInternalServerError, specErr.Error())
+		}
+
+		if c.QueryParam("format") == "yaml" {
+			return c.Blob(http.StatusOK, "application/x-yaml", specYAML)
+		}
+		return c.JSONBlob(http.StatusOK, specJSON)
+	}), apispec.Doc{
+		Summary: "OpenAPI 文档",
+		Request: struct {
+			Format string `query:"format"` // json、yaml，默认 json
+		}{},
+		Raw:    true,
+		Public: true,
+	})
+}
+
+// specErrorCodes 文档中的错误码目录
+func specErrorCodes() []apispec.ErrorCode {
+	codes := output.Codes()
+	list := make([]apispec.ErrorCode, 0, len(codes))
+	for _, code := range codes {
+		list = append(list, apispec.ErrorCode{
+			Code:        code.Code,
+			Category:    string(code.Category),
+			Message:     code.Message,
+			Description: code.Description,
+		})
+	}
+	return list
+}
+
+func buildSpec(routes []*echo.Route) (specJSON, specYAML []byte, err error) {
+	list := make([]apispec.Route, 0, len(routes))
+	for _, route := range routes {
+		// 跳过静态文件、代理以及 echo 为分组中间件注册的兜底路由
+		if !strings.HasPrefix(route.Path, "/api/") && !strings.HasPrefix(route.Path, "/scim/") {
+			continue
+		}
+		if strings.Contains(route.Path, "*") || strings.HasPrefix(route.Name, "github.com/labstack/echo/") {
+			continue
+		}
+		list = append(list, apispec.Route{Method: route.Method, Path: route.Path})
+	}
+
+	doc := apispec.Build(apispec.Info{
+		Title:       "Juno API",
+		Description: "/api/v1 为开放接口，/api/admin 为控制台接口，可使用个人访问令牌调用",
+		Version:     pkg.AppVersion(),
+	}, specSecuritySchemes, list)
+
+	specJSON, err = json.Marshal(doc)
+	if err != nil {
+		return
+	}
+
+	// 借助 JSON 转换保持字段顺序与 JSON 文档一致
+	var content yaml.MapSlice
+	err = yaml.Unmarshal(specJSON, &content)
+	if err != nil {
+		return
+	}
+	specYAML, err = yaml.Marshal(content)
+	return
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+// +build !windows
+
+package adminengine
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/graceful"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// initUpgradeSignal 收到 SIGUSR2 时用相同的参数启动新进程并交接监听端口，新进程开始服务后当前进程平滑退出。
+// 替换二进制文件后发送 SIGUSR2 即可升级，升级期间 agent、worker 的回调不会被拒绝
+func (eng *Admin) initUpgradeSignal() (err error) {
+	if !eng.runFlag {
+		return
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR2)
+	go func() {
+		for range ch {
+			if graceful.Draining() {
+				continue
+			}
+			ln := eng.server.Listener()
+			if ln == nil {
+				xlog.Warn("upgrade on SIGUSR2 ignored, server not started")
+				continue
+			}
+			if err := graceful.Upgrade(ln, cfg.Cfg.Server.Graceful.UpgradeTimeout); err != nil {
+				xlog.Error("upgrade on SIGUSR2 failed", xlog.FieldErr(err))
+				continue
+			}
+			xlog.Info("new process is ready, shutting down")
+			signal.Stop(ch)
+			_ = eng.Stop()
+			return
+		}
+	}()
+	return
+}
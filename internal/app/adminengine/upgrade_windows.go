@@ -0,0 +1,6 @@
+package adminengine
+
+// initUpgradeSignal windows 不支持交接监听端口
+func (eng *Admin) initUpgradeSignal() (err error) {
+	return
+}
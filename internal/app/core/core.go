@@ -6,6 +6,7 @@ import (
 
 	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
 	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/i18n"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/labstack/echo/v4"
 )
@@ -91,7 +92,7 @@ func (c *Context) GetUser() *db.User {
 func (c *Context) OutputJSON(Code int, message string, options ...JSONOption) error {
 	result := new(JSONResult)
 	result.Code = Code
-	result.Message = message
+	result.Message = i18n.T(output.Lang(c), message)
 
 	for _, opt := range options {
 		opt(result)
@@ -100,6 +101,12 @@ func (c *Context) OutputJSON(Code int, message string, options ...JSONOption) er
 	return c.JSON(result.Status, result)
 }
 
+// OutputError 按错误码目录输出错误，output.Error 的内部原因只记录日志，不返回给用户
+func (c *Context) OutputError(err error, options ...JSONOption) error {
+	code, message := output.ResolveRequest(c, err)
+	return c.OutputJSON(code, message, options...)
+}
+
 func (c *Context) Success(options ...JSONOption) error {
 	return c.OutputJSON(output.MsgOk, "success", options...)
 }
@@ -0,0 +1,65 @@
+package junoctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// response Admin API 的返回格式
+type response struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// client 使用个人 API Token 调用 Admin API
+type client struct {
+	http *resty.Client
+}
+
+func newClient(addr, token string) *client {
+	return &client{
+		http: resty.New().
+			SetHostURL(strings.TrimSuffix(addr, "/")).
+			SetAuthToken(token).
+			SetTimeout(30 * time.Second),
+	}
+}
+
+// get 调用 GET 接口，data 解析到 out
+func (c *client) get(path string, query map[string]string, out interface{}) error {
+	return c.do(c.http.R().SetQueryParams(query), http.MethodGet, path, out)
+}
+
+// post 调用 POST 接口，body 为 JSON 请求体
+func (c *client) post(path string, query map[string]string, body interface{}, out interface{}) error {
+	req := c.http.R().SetQueryParams(query)
+	if body != nil {
+		req.SetBody(body)
+	}
+	return c.do(req, http.MethodPost, path, out)
+}
+
+func (c *client) do(req *resty.Request, method, path string, out interface{}) error {
+	resp, err := req.Execute(method, path)
+	if err != nil {
+		return err
+	}
+
+	var res response
+	if err = json.Unmarshal(resp.Body(), &res); err != nil {
+		return fmt.Errorf("%s %s: unexpected response (HTTP %d)", method, path, resp.StatusCode())
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("%s %s: %s (code %d)", method, path, res.Msg, res.Code)
+	}
+	if out == nil || len(res.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(res.Data, out)
+}
@@ -0,0 +1,38 @@
+package junoctl
+
+import (
+	"fmt"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func init() {
+	register("config publish", command{Usage: "发布配置文件，默认发布到所有实例", Run: configPublish})
+}
+
+func configPublish(ctx *cmdContext, args []string) error {
+	var hosts stringList
+	id := ctx.flags.Uint("id", 0, "配置文件 ID")
+	version := ctx.flags.String("version", "", "发布的版本，默认为最新版本")
+	k8s := ctx.flags.Bool("k8s", false, "同时发布到 K8S 集群")
+	ctx.flags.Var(&hosts, "host", "发布的实例机器名，可指定多次，默认发布到所有实例")
+	if err := ctx.parse(args, "id"); err != nil {
+		return err
+	}
+
+	param := view.ReqPublishConfig{
+		ID:       *id,
+		HostName: hosts,
+		PubK8S:   *k8s,
+	}
+	if *version != "" {
+		param.Version = version
+	}
+	err := ctx.client.post("/api/admin/confgov2/config/publish", nil, param, nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ctx.out, "config %d published\n", *id)
+	return nil
+}
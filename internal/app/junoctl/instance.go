@@ -0,0 +1,53 @@
+package junoctl
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func init() {
+	register("instance list", command{Usage: "应用的实例列表", Run: instanceList})
+}
+
+func instanceList(ctx *cmdContext, args []string) error {
+	app := ctx.flags.String("app", "", "应用名")
+	env := ctx.flags.String("env", "", "环境")
+	zone := ctx.flags.String("zone", "", "机房")
+	page := ctx.flags.Int("page", 1, "页码")
+	pageSize := ctx.flags.Int("page-size", 50, "每页数量")
+	if err := ctx.parse(args, "app"); err != nil {
+		return err
+	}
+
+	var res struct {
+		List       []db.AppNode     `json:"list"`
+		Pagination *view.Pagination `json:"pagination"`
+	}
+	err := ctx.client.get("/api/admin/resource/app_node/list", map[string]string{
+		"app_name":    *app,
+		"env":         *env,
+		"zone_code":   *zone,
+		"currentPage": strconv.Itoa(*page),
+		"pageSize":    strconv.Itoa(*pageSize),
+	}, &res)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(ctx.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tIP\tENV\tZONE")
+	for _, node := range res.List {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", node.HostName, node.IP, node.Env, node.ZoneCode)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if res.Pagination != nil {
+		fmt.Fprintf(ctx.out, "page %d, total %d\n", res.Pagination.Current, res.Pagination.Total)
+	}
+	return nil
+}
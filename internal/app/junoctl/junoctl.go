@@ -0,0 +1,117 @@
+// Package junoctl Juno 命令行客户端，使用个人 API Token 调用 Admin API，
+// 用于在 CI 或终端中触发流水线、查看任务日志、发布配置和查询实例
+package junoctl
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrUsage 参数错误，已输出用法
+var ErrUsage = errors.New("invalid usage")
+
+type (
+	// command 子命令，名称为 "资源 动作"，如 "pipeline run"
+	command struct {
+		Usage string
+		Run   func(ctx *cmdContext, args []string) error
+	}
+
+	cmdContext struct {
+		client *client
+		out    io.Writer
+		flags  *flag.FlagSet
+	}
+)
+
+var commands = map[string]command{}
+
+func register(name string, cmd command) {
+	commands[name] = cmd
+}
+
+// Run 执行命令，args 不包括程序名
+func Run(args []string, out io.Writer) error {
+	global := flag.NewFlagSet("junoctl", flag.ContinueOnError)
+	global.SetOutput(out)
+	addr := global.String("addr", os.Getenv("JUNO_ADDR"), "Juno Admin 地址，默认读取环境变量 JUNO_ADDR")
+	token := global.String("token", os.Getenv("JUNO_TOKEN"), "个人 API Token，默认读取环境变量 JUNO_TOKEN")
+	global.Usage = func() { usage(out, global) }
+	if err := global.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	args = global.Args()
+	if len(args) < 2 {
+		usage(out, global)
+		return ErrUsage
+	}
+	name := args[0] + " " + args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(out, "unknown command %q\n", name)
+		usage(out, global)
+		return ErrUsage
+	}
+	if *addr == "" || *token == "" {
+		return fmt.Errorf("--addr and --token are required")
+	}
+
+	flags := flag.NewFlagSet("junoctl "+name, flag.ContinueOnError)
+	flags.SetOutput(out)
+	return cmd.Run(&cmdContext{
+		client: newClient(*addr, *token),
+		out:    out,
+		flags:  flags,
+	}, args[2:])
+}
+
+// parse 解析子命令参数，required 中的参数未指定时返回 ErrUsage
+func (ctx *cmdContext) parse(args []string, required ...string) error {
+	if err := ctx.flags.Parse(args); err != nil {
+		return ErrUsage
+	}
+	set := map[string]bool{}
+	ctx.flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, name := range required {
+		if !set[name] {
+			fmt.Fprintf(ctx.out, "flag --%s is required\n", name)
+			ctx.flags.Usage()
+			return ErrUsage
+		}
+	}
+	return nil
+}
+
+func usage(out io.Writer, global *flag.FlagSet) {
+	fmt.Fprintln(out, "Usage: junoctl [--addr ADDR] [--token TOKEN] <resource> <action> [flags]")
+	fmt.Fprintln(out, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-20s %s\n", name, commands[name].Usage)
+	}
+	fmt.Fprintln(out, "\nGlobal flags:")
+	global.PrintDefaults()
+	fmt.Fprintln(out, "\nRun 'junoctl <resource> <action> -h' for command flags.")
+}
+
+// stringList 可重复指定的参数
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
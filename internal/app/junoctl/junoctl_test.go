@@ -0,0 +1,60 @@
+package junoctl
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogTailer(t *testing.T) {
+	var buf bytes.Buffer
+	tailer := newLogTailer(&buf)
+
+	tailer.Write("unit_test", "line1\nli")
+	tailer.Write("task", "")
+	tailer.Write("unit_test", "line1\nline2\nline3")
+	tailer.Write("unit_test", "line1\nline2\nline3")
+	tailer.Flush()
+	tailer.Write("unit_test", "line1\nline2\nline3\n")
+
+	want := "[unit_test] line1\n[unit_test] line2\n[unit_test] line3\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer juno_test" {
+			_, _ = w.Write([]byte(`{"code":401,"msg":"token auth failed"}`))
+			return
+		}
+		switch r.URL.Path {
+		case pipelinePath + "/run":
+			_, _ = w.Write([]byte(`{"code":0,"msg":"success","data":{"task_id":12}}`))
+		default:
+			_, _ = w.Write([]byte(`{"code":1,"msg":"pipeline not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	var res struct {
+		TaskID uint `json:"task_id"`
+	}
+	err := newClient(server.URL, "juno_test").post(pipelinePath+"/run", map[string]string{"id": "1"}, nil, &res)
+	if err != nil || res.TaskID != 12 {
+		t.Fatalf("run = %v, %v", res, err)
+	}
+
+	err = newClient(server.URL, "juno_test").get(pipelinePath+"/tasks", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "pipeline not found") {
+		t.Errorf("expect api error, got %v", err)
+	}
+
+	err = newClient(server.URL, "invalid").get(pipelinePath+"/tasks", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "code 401") {
+		t.Errorf("expect auth error, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package junoctl
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+const pipelinePath = "/api/admin/test/platform/pipeline"
+
+func init() {
+	register("pipeline run", command{Usage: "触发流水线，--follow 时等待任务结束并输出日志", Run: pipelineRun})
+	register("pipeline tasks", command{Usage: "流水线的任务列表", Run: pipelineTasks})
+}
+
+func pipelineRun(ctx *cmdContext, args []string) error {
+	id := ctx.flags.Uint("id", 0, "流水线 ID")
+	follow := ctx.flags.Bool("follow", false, "等待任务结束并输出日志，任务失败时返回非零退出码")
+	interval := ctx.flags.Duration("interval", 2*time.Second, "--follow 时查询日志的间隔")
+	commit := ctx.flags.String("commit", "", "触发的提交 SHA，同一提交还在排队的任务会被取代")
+	if err := ctx.parse(args, "id"); err != nil {
+		return err
+	}
+
+	query := map[string]string{"id": strconv.Itoa(int(*id))}
+	if *commit != "" {
+		query["commit"] = *commit
+	}
+	var res struct {
+		TaskID     uint   `json:"task_id"`
+		Superseded []uint `json:"superseded"`
+	}
+	err := ctx.client.post(pipelinePath+"/run", query, nil, &res)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.out, "task %d created\n", res.TaskID)
+	for _, taskID := range res.Superseded {
+		fmt.Fprintf(ctx.out, "task %d superseded\n", taskID)
+	}
+
+	if !*follow {
+		return nil
+	}
+	return followTask(ctx, res.TaskID, *interval)
+}
+
+func pipelineTasks(ctx *cmdContext, args []string) error {
+	id := ctx.flags.Uint("id", 0, "流水线 ID")
+	page := ctx.flags.Int("page", 1, "页码")
+	pageSize := ctx.flags.Int("page-size", 20, "每页数量，最大 100")
+	if err := ctx.parse(args, "id"); err != nil {
+		return err
+	}
+
+	var res struct {
+		List       []view.TestTask `json:"list"`
+		Pagination view.Pagination `json:"pagination"`
+	}
+	err := ctx.client.get(pipelinePath+"/tasks", map[string]string{
+		"pipeline_id": strconv.Itoa(int(*id)),
+		"page":        strconv.Itoa(*page),
+		"page_size":   strconv.Itoa(*pageSize),
+	}, &res)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(ctx.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tSTATUS\tBRANCH\tENV\tZONE\tCREATED")
+	for _, task := range res.List {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", task.TaskID, task.Status, task.Branch, task.Env, task.ZoneCode,
+			task.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.out, "page %d, total %d\n", res.Pagination.Current, res.Pagination.Total)
+	return nil
+}
@@ -0,0 +1,91 @@
+package junoctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+const secretPath = "/api/admin/test/platform/secret"
+
+func init() {
+	register("secret list", command{Usage: "应用在环境下的流水线密钥，不输出密钥的值", Run: secretList})
+	register("secret set", command{Usage: "创建或更新流水线密钥，未指定 --value 时从标准输入读取", Run: secretSet})
+	register("secret delete", command{Usage: "删除流水线密钥", Run: secretDelete})
+}
+
+func secretList(ctx *cmdContext, args []string) error {
+	app := ctx.flags.String("app", "", "应用名")
+	env := ctx.flags.String("env", "", "环境")
+	if err := ctx.parse(args, "app", "env"); err != nil {
+		return err
+	}
+
+	var list []view.TestSecret
+	err := ctx.client.get(secretPath+"/list", map[string]string{"app_name": *app, "env": *env}, &list)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(ctx.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tUPDATED")
+	for _, item := range list {
+		fmt.Fprintf(w, "%s\t%s\n", item.Name, item.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
+
+func secretSet(ctx *cmdContext, args []string) error {
+	app := ctx.flags.String("app", "", "应用名")
+	env := ctx.flags.String("env", "", "环境")
+	name := ctx.flags.String("name", "", "密钥名，流水线中使用 ${{ secrets.NAME }} 引用")
+	value := ctx.flags.String("value", "", "密钥的值，会留在 shell 历史中，建议通过标准输入传入")
+	if err := ctx.parse(args, "app", "env", "name"); err != nil {
+		return err
+	}
+
+	if *value == "" {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		// echo 等输出的结尾换行不属于密钥
+		*value = strings.TrimRight(string(data), "\r\n")
+	}
+
+	err := ctx.client.post(secretPath+"/save", nil, view.ReqSaveTestSecret{
+		AppName: *app,
+		Env:     *env,
+		Name:    *name,
+		Value:   *value,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.out, "secret %s saved\n", *name)
+	return nil
+}
+
+func secretDelete(ctx *cmdContext, args []string) error {
+	app := ctx.flags.String("app", "", "应用名")
+	env := ctx.flags.String("env", "", "环境")
+	name := ctx.flags.String("name", "", "密钥名")
+	if err := ctx.parse(args, "app", "env", "name"); err != nil {
+		return err
+	}
+
+	err := ctx.client.post(secretPath+"/delete", nil, view.ReqDeleteTestSecret{
+		AppName: *app,
+		Env:     *env,
+		Name:    *name,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.out, "secret %s deleted\n", *name)
+	return nil
+}
@@ -0,0 +1,173 @@
+package junoctl
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// ErrTaskFailed 任务执行失败，用于 CI 中返回非零退出码
+var ErrTaskFailed = fmt.Errorf("task failed")
+
+func init() {
+	register("task logs", command{Usage: "输出任务日志，--follow 时持续输出直到任务结束", Run: taskLogs})
+	register("task cancel", command{Usage: "取消排队中或执行中的任务", Run: taskCancel})
+}
+
+func taskLogs(ctx *cmdContext, args []string) error {
+	id := ctx.flags.Uint("id", 0, "任务 ID")
+	follow := ctx.flags.Bool("follow", false, "持续输出直到任务结束，任务失败时返回非零退出码")
+	interval := ctx.flags.Duration("interval", 2*time.Second, "--follow 时查询日志的间隔")
+	if err := ctx.parse(args, "id"); err != nil {
+		return err
+	}
+
+	if *follow {
+		return followTask(ctx, *id, *interval)
+	}
+
+	tailer := newLogTailer(ctx.out)
+	task, err := pollTask(ctx, *id, tailer)
+	if err != nil {
+		return err
+	}
+	tailer.Flush()
+	fmt.Fprintf(ctx.out, "task %d %s\n", task.TaskID, task.Status)
+	return nil
+}
+
+func taskCancel(ctx *cmdContext, args []string) error {
+	id := ctx.flags.Uint("id", 0, "任务 ID")
+	if err := ctx.parse(args, "id"); err != nil {
+		return err
+	}
+
+	err := ctx.client.post(pipelinePath+"/tasks/cancel", map[string]string{"task_id": strconv.Itoa(int(*id))}, nil, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.out, "task %d canceled\n", *id)
+	return nil
+}
+
+// followTask 按 interval 查询任务日志直到任务结束
+func followTask(ctx *cmdContext, taskID uint, interval time.Duration) error {
+	tailer := newLogTailer(ctx.out)
+	for {
+		task, err := pollTask(ctx, taskID, tailer)
+		if err != nil {
+			return err
+		}
+
+		switch task.Status {
+		case db.TestTaskStatusSuccess:
+			tailer.Flush()
+			fmt.Fprintf(ctx.out, "task %d %s\n", taskID, task.Status)
+			return nil
+		case db.TestTaskStatusFailed, db.TestTaskStatusCanceled:
+			tailer.Flush()
+			fmt.Fprintf(ctx.out, "task %d %s\n", taskID, task.Status)
+			return ErrTaskFailed
+		case db.TestTaskStatusSuperseded:
+			// 被取代的任务不会执行，继续跟踪取代它的任务
+			tailer.Flush()
+			fmt.Fprintf(ctx.out, "task %d %s by task %d\n", taskID, task.Status, task.SupersededBy)
+			taskID, tailer = task.SupersededBy, newLogTailer(ctx.out)
+			continue
+		}
+		time.Sleep(interval)
+	}
+}
+
+// pollTask 查询任务和各步骤的日志，输出新增部分
+func pollTask(ctx *cmdContext, taskID uint, tailer *logTailer) (task view.TestTask, err error) {
+	query := map[string]string{"task_id": strconv.Itoa(int(taskID))}
+	err = ctx.client.get(pipelinePath+"/tasks/info", query, &task)
+	if err != nil {
+		return
+	}
+
+	var steps []view.TestTaskStepStatus
+	err = ctx.client.get(pipelinePath+"/tasks/steps", query, &steps)
+	if err != nil {
+		return
+	}
+
+	tailer.Write("task", task.Logs)
+	for _, step := range steps {
+		tailer.Write(step.StepName, step.Logs)
+	}
+	return
+}
+
+// logTailer 记录各日志已输出的位置，每次只输出新增的完整行，行首加上日志名称
+type logTailer struct {
+	out     io.Writer
+	offsets map[string]int
+	pending map[string]string
+	// partial Flush 输出了不完整的行，后续内容接着该行
+	partial map[string]bool
+	names   []string
+}
+
+func newLogTailer(out io.Writer) *logTailer {
+	return &logTailer{
+		out:     out,
+		offsets: map[string]int{},
+		pending: map[string]string{},
+		partial: map[string]bool{},
+	}
+}
+
+// Write logs 为名称为 name 的日志的全部内容
+func (t *logTailer) Write(name, logs string) {
+	offset, ok := t.offsets[name]
+	if !ok {
+		t.names = append(t.names, name)
+		t.offsets[name] = 0
+	}
+	if len(logs) <= offset {
+		return
+	}
+
+	text := logs[offset:]
+	if t.partial[name] {
+		t.partial[name] = false
+		if text[0] == '\n' {
+			text = text[1:]
+			offset++
+			t.offsets[name] = offset
+		}
+	}
+	end := strings.LastIndexByte(text, '\n')
+	if end < 0 {
+		t.pending[name] = text
+		return
+	}
+	t.print(name, text[:end])
+	t.offsets[name] = offset + end + 1
+	t.pending[name] = text[end+1:]
+}
+
+// Flush 输出未以换行结尾的剩余日志
+func (t *logTailer) Flush() {
+	for _, name := range t.names {
+		if rest := t.pending[name]; rest != "" {
+			t.print(name, rest)
+			t.offsets[name] += len(rest)
+			t.pending[name] = ""
+			t.partial[name] = true
+		}
+	}
+}
+
+func (t *logTailer) print(name, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(t.out, "[%s] %s\n", name, line)
+	}
+}
@@ -0,0 +1,278 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/auditlog"
+	casbin2 "github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// 审计记录中请求参数、响应体的最大长度
+	auditMaxRequest  = 4096
+	auditMaxResponse = 4096
+
+	auditMaskValue = "******"
+)
+
+type auditSnapshot struct {
+	resource string
+	idParam  string
+}
+
+var (
+	// 按路径前缀划分资源类型，未匹配的取 /api/admin/、/api/v1/ 之后的第一段
+	auditResources = []struct {
+		prefix   string
+		resource string
+	}{
+		{"/api/admin/confgov2/", db.AuditResourceConfig},
+		{"/api/admin/confgo/", db.AuditResourceConfig},
+		{"/api/admin/test/platform/", db.AuditResourcePipeline},
+		{"/api/admin/user/", db.AuditResourceUser},
+		{"/api/admin/public/user/", db.AuditResourceUser},
+		{"/api/admin/permission/", db.AuditResourcePermission},
+		{"/api/admin/public/permission/", db.AuditResourcePermission},
+		{"/api/admin/resource/", db.AuditResourceResource},
+		{"/api/v1/confgo/", db.AuditResourceConfig},
+		{"/api/v1/resource/", db.AuditResourceResource},
+		{"/scim/v2/Users", db.AuditResourceUser},
+		{"/scim/v2/Groups", db.AuditResourcePermission},
+	}
+
+	// 需要记录变更前后资源状态的接口
+	auditSnapshots = map[string]auditSnapshot{
+		"/api/admin/confgov2/config/update":        {db.AuditResourceConfig, "id"},
+		"/api/admin/confgov2/config/publish":       {db.AuditResourceConfig, "id"},
+		"/api/admin/confgov2/config/delete":        {db.AuditResourceConfig, "id"},
+		"/api/admin/test/platform/pipeline/update": {db.AuditResourcePipeline, "id"},
+		"/api/admin/test/platform/pipeline/delete": {db.AuditResourcePipeline, "id"},
+		"/api/admin/user/update":                   {db.AuditResourceUser, "uid"},
+		"/api/admin/user/delete":                   {db.AuditResourceUser, "uid"},
+	}
+
+	// 包含以下关键字的参数会被脱敏
+	auditSensitiveKeys = []string{"password", "secret", "token", "webhook"}
+)
+
+// AuditMW 记录所有变更类请求：操作人、接口、资源、请求参数、变更前后摘要、结果、IP
+func AuditMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return next(c)
+		}
+
+		payload := proxyAuditPayload(c)
+		item := db.AuditLog{
+			Method:   req.Method,
+			Path:     truncateString(req.URL.Path, 255),
+			Resource: auditResource(req.URL.Path),
+			AppName:  payload["app_name"],
+			Env:      payload["env"],
+			Request:  truncateString(auditRequest(c), auditMaxRequest),
+			ClientIP: c.RealIP(),
+		}
+
+		snapshot, hasSnapshot := auditSnapshots[req.URL.Path]
+		if hasSnapshot {
+			item.ResourceID = payload[snapshot.idParam]
+			item.Before = auditlog.AuditLog.Snapshot(snapshot.resource, item.ResourceID)
+		} else {
+			item.ResourceID = auditResourceID(payload)
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = writer
+
+		start := time.Now()
+		err := next(c)
+		item.Latency = time.Since(start).Milliseconds()
+
+		c.Response().Writer = writer.ResponseWriter
+		item.Status = c.Response().Status
+		item.Code, item.Message = auditResult(writer.body.Bytes())
+		if err != nil {
+			item.Message = err.Error()
+			if he, ok := err.(*echo.HTTPError); ok {
+				item.Status = he.Code
+			} else if !c.Response().Committed {
+				item.Status = http.StatusInternalServerError
+			}
+		}
+		item.Message = truncateString(item.Message, 512)
+
+		if hasSnapshot && err == nil && item.Code == 0 {
+			item.After = auditlog.AuditLog.Snapshot(snapshot.resource, item.ResourceID)
+		}
+
+		if apiItem := casbin2.Casbin.GetAPIItem(c.Path(), req.Method); apiItem != nil {
+			item.Action = apiItem.Name
+		}
+
+		if u := user.GetUser(c); u.IsLogin() {
+			item.Uid = u.Uid
+			item.UserName = u.Username
+		} else if token, ok := c.Get("OpenAuthAccessToken").(db.AccessToken); ok {
+			// Open API 记录 AccessToken 名称
+			item.UserName = truncateString("openapi:"+token.Name, 64)
+		} else if account, ok := c.Get(ContextServiceAccount).(string); ok {
+			// 服务账号记录账号名称
+			item.UserName = truncateString("sa:"+account, 64)
+		} else {
+			// 登录等接口没有 session，记录请求中的用户名
+			item.UserName = truncateString(payload["username"], 64)
+		}
+
+		auditlog.AuditLog.Record(item)
+		return err
+	}
+}
+
+type auditResponseWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if remain := auditMaxResponse - w.body.Len(); remain > 0 {
+		if len(b) > remain {
+			w.body.Write(b[:remain])
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func auditResource(path string) string {
+	for _, item := range auditResources {
+		if strings.HasPrefix(path, item.prefix) {
+			return item.resource
+		}
+	}
+
+	for _, prefix := range []string{"/api/admin/", "/api/v1/"} {
+		path = strings.TrimPrefix(path, prefix)
+	}
+	if i := strings.Index(path, "/"); i > 0 {
+		path = path[:i]
+	}
+	return truncateString(path, 32)
+}
+
+func auditResourceID(payload map[string]string) string {
+	for _, key := range []string{"id", "uid", "aid", "app_name", "host_name", "name"} {
+		if payload[key] != "" {
+			return truncateString(payload[key], 255)
+		}
+	}
+	return ""
+}
+
+// auditRequest 请求参数，query 和 JSON body 合并后脱敏
+func auditRequest(c echo.Context) string {
+	params := make(map[string]interface{})
+	for key, values := range c.QueryParams() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	req := c.Request()
+	contentType := req.Header.Get(echo.HeaderContentType)
+	if req.Body != nil && strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+		req.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+
+		var body interface{}
+		if len(bodyBytes) <= proxyAuditMaxBody && json.Unmarshal(bodyBytes, &body) == nil {
+			if m, ok := body.(map[string]interface{}); ok {
+				for key, value := range m {
+					params[key] = value
+				}
+			} else {
+				params["body"] = body
+			}
+		}
+	} else if contentType != "" {
+		params["content_type"] = contentType
+	}
+
+	b, _ := json.Marshal(auditMask(params))
+	return string(b)
+}
+
+// auditMask 递归脱敏敏感字段
+func auditMask(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if auditSensitive(key) {
+				v[key] = auditMaskValue
+				continue
+			}
+			v[key] = auditMask(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = auditMask(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func auditSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, item := range auditSensitiveKeys {
+		if strings.Contains(key, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditResult 从响应体中解析 code 和 msg，响应体可能被截断，因此按 token 逐个解析
+func auditResult(body []byte) (code int, msg string) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+
+		var dst interface{} = new(json.RawMessage)
+		switch tok {
+		case "code":
+			dst = &code
+		case "msg":
+			dst = &msg
+		}
+		if dec.Decode(dst) != nil {
+			return
+		}
+	}
+	return
+}
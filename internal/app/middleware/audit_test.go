@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+func TestAuditResource(t *testing.T) {
+	tests := map[string]string{
+		"/api/admin/confgov2/config/update":        db.AuditResourceConfig,
+		"/api/admin/test/platform/pipeline/delete": db.AuditResourcePipeline,
+		"/api/admin/user/update":                   db.AuditResourceUser,
+		"/api/admin/team/create":                   "team",
+		"/api/admin/proxyAudit":                    "proxyAudit",
+		"/api/v1/confgo/config/publish":            db.AuditResourceConfig,
+		"/api/v1/system/option/create":             "system",
+	}
+
+	for path, want := range tests {
+		if got := auditResource(path); got != want {
+			t.Errorf("auditResource(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestAuditMask(t *testing.T) {
+	var params map[string]interface{}
+	_ = json.Unmarshal([]byte(`{"username":"juno","password":"123","config":{"client_secret":"x","items":[{"token":"t","name":"n"}]}}`), &params)
+
+	b, _ := json.Marshal(auditMask(params))
+	want := `{"config":{"client_secret":"******","items":[{"name":"n","token":"******"}]},"password":"******","username":"juno"}`
+	if string(b) != want {
+		t.Errorf("auditMask() = %s, want %s", b, want)
+	}
+}
+
+func TestAuditResult(t *testing.T) {
+	tests := []struct {
+		body string
+		code int
+		msg  string
+	}{
+		{`{"code":0,"msg":"success","data":{"id":1}}`, 0, "success"},
+		{`{"code":1,"msg":"配置不存在","data":""}`, 1, "配置不存在"},
+		{`{"data":[1,2],"code":14000,"msg":"forbidden"}`, 14000, "forbidden"},
+		{`{"code":1,"msg":"failed","data":{"list":[{"na`, 1, "failed"},
+		{`not json`, 0, ""},
+	}
+
+	for _, tt := range tests {
+		code, msg := auditResult([]byte(tt.body))
+		if code != tt.code || msg != tt.msg {
+			t.Errorf("auditResult(%s) = (%d, %q), want (%d, %q)", tt.body, code, msg, tt.code, tt.msg)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/douyu/juno/internal/pkg/service/confgov2"
 	"github.com/douyu/juno/internal/pkg/service/permission"
 	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/internal/pkg/service/testplatform"
 	"github.com/douyu/juno/internal/pkg/service/user"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/view"
@@ -64,7 +65,7 @@ func CasbinMiddleware(config CasbinConfig) echo.MiddlewareFunc {
 
 			pass, err := config.CheckPermission(u, c.Path(), c.Request().Method)
 			if err != nil {
-				return output.JSON(c, output.MsgErr, err.Error())
+				return output.JSONError(c, err)
 			}
 
 			if pass {
@@ -143,6 +144,48 @@ func ParseAppEnvFromConfigID(c echo.Context) (appName, env string, err error) {
 	return
 }
 
+// ParseAppEnvFromPipelineID 从 Query 或 Body 的流水线ID获取应用环境信息
+func ParseAppEnvFromPipelineID(c echo.Context) (appName, env string, err error) {
+	payload := struct {
+		ID uint `json:"id" query:"id"`
+	}{}
+
+	if id := c.QueryParam("id"); id != "" {
+		pipelineID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return "", "", err
+		}
+		payload.ID = uint(pipelineID)
+	} else {
+		err = c.Bind(&payload)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return testplatform.PipelineAppEnv(payload.ID)
+}
+
+// ParseAppEnvFromPipelineQuery 从 Query 参数 pipeline_id 获取应用环境信息
+func ParseAppEnvFromPipelineQuery(c echo.Context) (appName, env string, err error) {
+	pipelineID, err := strconv.ParseUint(c.QueryParam("pipeline_id"), 10, 64)
+	if err != nil {
+		return "", "", err
+	}
+
+	return testplatform.PipelineAppEnv(uint(pipelineID))
+}
+
+// ParseAppEnvFromTaskID 从 Query 参数 task_id 获取应用环境信息
+func ParseAppEnvFromTaskID(c echo.Context) (appName, env string, err error) {
+	taskID, err := strconv.ParseUint(c.QueryParam("task_id"), 10, 64)
+	if err != nil {
+		return "", "", err
+	}
+
+	return testplatform.TaskAppEnv(uint(taskID))
+}
+
 func CasbinAppMW(parserFn AppEnvParser, action string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -155,7 +198,7 @@ func CasbinAppMW(parserFn AppEnvParser, action string) echo.MiddlewareFunc {
 			// 写回 Request Body
 			c.Request().Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
 			if err != nil {
-				return output.JSON(c, output.MsgErr, "can not get app,env from context: "+err.Error(), nil)
+				return output.JSON(c, output.MsgInvalidParam, "can not get app,env from context: "+err.Error(), nil)
 			}
 
 			u := user.GetUser(c)
@@ -168,7 +211,7 @@ func CasbinAppMW(parserFn AppEnvParser, action string) echo.MiddlewareFunc {
 
 			hasPerm, err := casbin2.Casbin.CheckPermission(sub, obj, action, db.CasbinPolicyTypeApp)
 			if err != nil {
-				return output.JSON(c, output.MsgErr, err.Error())
+				return output.JSONError(c, err)
 			}
 
 			if hasPerm {
@@ -238,10 +281,10 @@ func GrafanaAuthMW(next echo.HandlerFunc) echo.HandlerFunc {
 }
 
 func AuthFailedResp(c echo.Context, msg, obj, act string) error {
-	return c.JSON(http.StatusForbidden, map[string]interface{}{
-		"code": output.MsgNoAuth,
-		"msg":  msg,
-		"data": map[string]interface{}{
+	return c.JSON(http.StatusForbidden, output.JSONResult{
+		Code:    output.MsgNoAuth,
+		Message: msg,
+		Data: map[string]interface{}{
 			"obj": obj,
 			"act": act,
 		},
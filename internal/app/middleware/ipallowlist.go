@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/auditlog"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+// IPAllowlistMW 来源 IP 不在 cidrs 中的请求返回 403 并记录审计，cidrs 为空时不限制
+func IPAllowlistMW(name string, cidrs []string) echo.MiddlewareFunc {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		xlog.Panic("invalid ip allowlist", xlog.String("name", name), xlog.String("err", err.Error()))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if len(nets) == 0 {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			ip := ClientIP(c)
+			if ipAllowed(nets, ip) {
+				return next(c)
+			}
+
+			xlog.Warn("request rejected by ip allowlist",
+				xlog.String("name", name),
+				xlog.String("ip", ip),
+				xlog.String("path", c.Request().URL.Path))
+
+			auditlog.AuditLog.Record(db.AuditLog{
+				CreatedAt: time.Now(),
+				Method:    c.Request().Method,
+				Path:      c.Request().URL.Path,
+				Action:    "IP白名单拦截: " + name,
+				Resource:  db.AuditResourceSecurity,
+				Status:    http.StatusForbidden,
+				Code:      output.MsgNoAuth,
+				Message:   "ip not allowed",
+				ClientIP:  ip,
+			})
+
+			return output.JSON(c, output.MsgNoAuth, "forbidden: ip not allowed", nil)
+		}
+	}
+}
+
+// parseCIDRs 解析 CIDR 列表，单个 IP 视为 /32 或 /128
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, item := range cidrs {
+		item = strings.TrimSpace(item)
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip: %s", item)
+			}
+			if ip.To4() != nil {
+				item += "/32"
+			} else {
+				item += "/128"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func ipAllowed(nets []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, ipNet := range nets {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP 默认使用 TCP 连接的来源地址，避免伪造 X-Forwarded-For 绕过白名单和按来源计数的限制
+func ClientIP(c echo.Context) string {
+	if cfg.Cfg.IPAllowlist.TrustForwardedFor {
+		return c.RealIP()
+	}
+
+	host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		return c.Request().RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import "testing"
+
+func TestIPAllowed(t *testing.T) {
+	nets, err := parseCIDRs([]string{"10.0.0.0/8", " 192.168.1.10 ", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"10.1.2.3":     true,
+		"11.0.0.1":     false,
+		"192.168.1.10": true,
+		"192.168.1.11": false,
+		"::1":          true,
+		"::2":          false,
+		"":             false,
+		"not-an-ip":    false,
+	}
+
+	for ip, want := range tests {
+		if got := ipAllowed(nets, ip); got != want {
+			t.Errorf("ipAllowed(%q) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestParseCIDRsInvalid(t *testing.T) {
+	for _, item := range []string{"10.0.0.0/33", "abc", "10.0.0"} {
+		if _, err := parseCIDRs([]string{item}); err == nil {
+			t.Errorf("parseCIDRs(%q) expected error", item)
+		}
+	}
+}
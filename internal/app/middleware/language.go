@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/i18n"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+// LanguageMW 确定请求的语言：登录用户优先使用个人设置，未设置时按 Accept-Language 选择
+func LanguageMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		lang := ""
+		if u := user.GetUser(c); u.Uid > 0 {
+			var err error
+			lang, err = user.User.Language(u.Uid)
+			if err != nil {
+				xlog.Warn("LanguageMW: read user language failed", xlog.Int("uid", u.Uid), xlog.FieldErr(err))
+			}
+		}
+		if lang == "" {
+			lang = i18n.ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+		}
+
+		c.Set(i18n.ContextKey, lang)
+		return next(c)
+	}
+}
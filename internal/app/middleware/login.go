@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
 	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/db"
 	"github.com/labstack/echo/v4"
 )
 
@@ -41,6 +43,11 @@ func LoginAuth(loginURL string, redirectType RedirectType) *Auth {
 			if !u.IsLogin() {
 				return errors.New("no session")
 			}
+			// API Token 认证的请求不写入 session
+			if _, ok := context.Get(user.ContextTokenUser).(*db.User); ok {
+				context.Set("user", u)
+				return nil
+			}
 			err := user.Session.Save(context, u)
 			if err != nil {
 				return errors.New(fmt.Sprintf("update session err: %s", err.Error()))
@@ -56,11 +63,7 @@ func LoginAuth(loginURL string, redirectType RedirectType) *Auth {
 			if redirectType == RedirectTypeHttp {
 				return context.Redirect(http.StatusFound, "/user/login?return_url="+context.Request().URL.EscapedPath())
 			}
-			return context.JSON(http.StatusOK, map[string]interface{}{
-				"code": 302,
-				"msg":  fmt.Sprintf("%s?%s=%s", loginURL, RedirectParam, context.Request().RequestURI),
-				"data": "",
-			})
+			return output.JSON(context, output.MsgRedirect, fmt.Sprintf("%s?%s=%s", loginURL, RedirectParam, context.Request().RequestURI))
 		},
 	}
 }
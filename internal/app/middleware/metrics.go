@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/pkg/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// MetricsMW 按路由模板统计请求数和耗时，未匹配路由的请求统一记为 unmatched
+func MetricsMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		begin := time.Now()
+		err := next(c)
+
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTP(c.Request().Method, route, responseStatus(c, err), time.Since(begin))
+		return err
+	}
+}
+
+// responseStatus 返回错误时响应由 HTTPErrorHandler 在中间件之后写入，按错误推断状态码
+func responseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	return output.HTTPStatus(err)
+}
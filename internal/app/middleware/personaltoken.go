@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/personaltoken"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/labstack/echo/v4"
+)
+
+// PersonalTokenMW 支持通过 Authorization: Bearer <token> 调用 Admin API
+// Token 的权限为 scopes 与用户自身权限的交集，用户权限仍由后续的 casbin 中间件校验
+func PersonalTokenMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := bearerToken(c.Request().Header.Get(echo.HeaderAuthorization))
+		if token == "" {
+			return next(c)
+		}
+
+		u, scopes, err := personaltoken.PersonalToken.Authenticate(token, c.RealIP())
+		if err != nil {
+			return output.JSON(c, output.MsgNeedLogin, "token auth failed: "+err.Error(), nil)
+		}
+
+		if !personaltoken.Allowed(scopes, c.Request().Method, c.Request().URL.Path) {
+			return output.JSON(c, output.MsgNoAuth, "token scope does not allow this api", nil)
+		}
+
+		c.Set(user.ContextTokenUser, &u)
+		return next(c)
+	}
+}
+
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(prefix):])
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import "testing"
+
+func TestBearerToken(t *testing.T) {
+	tests := map[string]string{
+		"":                   "",
+		"Bearer":             "",
+		"Bearer ":            "",
+		"Basic abc":          "",
+		"Bearer juno_abc":    "juno_abc",
+		"bearer  juno_abc  ": "juno_abc",
+	}
+
+	for header, want := range tests {
+		if got := bearerToken(header); got != want {
+			t.Errorf("bearerToken(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/proxyaudit"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/labstack/echo/v4"
+)
+
+// 审计时最多读取的请求体大小
+const proxyAuditMaxBody = 1 << 20
+
+// ProxyAuditMW 记录代理到业务实例的请求：操作人、目标实例、路径、耗时、状态
+func ProxyAuditMW(kind string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			// grafana 静态资源不记录
+			if kind == db.ProxyAuditKindGrafana && strings.HasPrefix(req.URL.Path, "/grafana/public/") {
+				return next(c)
+			}
+			payload := proxyAuditPayload(c)
+
+			start := time.Now()
+			err := next(c)
+			latency := time.Since(start)
+
+			item := db.ProxyAuditLog{
+				Kind:     kind,
+				Method:   req.Method,
+				Path:     truncateString(req.URL.Path, 512),
+				Query:    truncateString(req.URL.RawQuery, 1024),
+				AppName:  payload["app_name"],
+				Env:      payload["env"],
+				Target:   proxyAuditTarget(kind, payload),
+				Status:   c.Response().Status,
+				Latency:  latency.Milliseconds(),
+				ClientIP: c.RealIP(),
+			}
+			if u := user.GetUser(c); u != nil {
+				item.Uid = u.Uid
+				item.UserName = u.Username
+			}
+			if err != nil {
+				item.Error = truncateString(err.Error(), 1024)
+				if he, ok := err.(*echo.HTTPError); ok {
+					item.Status = he.Code
+				} else if !c.Response().Committed {
+					item.Status = http.StatusInternalServerError
+				}
+			}
+			proxyaudit.ProxyAudit.Record(item)
+
+			return err
+		}
+	}
+}
+
+// proxyAuditPayload 从 query 和 JSON body 中提取字符串参数，读取后写回 body
+func proxyAuditPayload(c echo.Context) map[string]string {
+	payload := make(map[string]string)
+	for key, values := range c.QueryParams() {
+		if len(values) > 0 {
+			payload[key] = values[0]
+		}
+	}
+
+	req := c.Request()
+	if req.Body == nil || !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return payload
+	}
+
+	bodyBytes, _ := ioutil.ReadAll(req.Body)
+	req.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+	if len(bodyBytes) > proxyAuditMaxBody {
+		return payload
+	}
+
+	var body map[string]interface{}
+	if json.Unmarshal(bodyBytes, &body) != nil {
+		return payload
+	}
+	for key, value := range body {
+		switch v := value.(type) {
+		case string:
+			payload[key] = v
+		case float64:
+			payload[key] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return payload
+}
+
+func proxyAuditTarget(kind string, payload map[string]string) string {
+	if kind == db.ProxyAuditKindGrafana {
+		return db.ProxyAuditKindGrafana
+	}
+	for _, key := range []string{"address", "host_name", "hostname", "node_name"} {
+		if payload[key] != "" {
+			return truncateString(payload[key], 255)
+		}
+	}
+	if payload["ip"] != "" && payload["port"] != "" {
+		return payload["ip"] + ":" + payload["port"]
+	}
+	return payload["ip"]
+}
+
+func truncateString(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
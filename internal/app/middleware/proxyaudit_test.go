@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+func Test_proxyAuditTarget(t *testing.T) {
+	var tests = []struct {
+		name    string
+		kind    string
+		payload map[string]string
+		want    string
+	}{
+		{
+			name:    "grafana",
+			kind:    db.ProxyAuditKindGrafana,
+			payload: map[string]string{"host_name": "host-1"},
+			want:    db.ProxyAuditKindGrafana,
+		},
+		{
+			name:    "host name",
+			kind:    db.ProxyAuditKindPProf,
+			payload: map[string]string{"host_name": "host-1", "ip": "10.0.0.1"},
+			want:    "host-1",
+		},
+		{
+			name:    "ip and port",
+			kind:    db.ProxyAuditKindGovernance,
+			payload: map[string]string{"ip": "10.0.0.1", "port": "9999"},
+			want:    "10.0.0.1:9999",
+		},
+		{
+			name:    "empty",
+			kind:    db.ProxyAuditKindGovernance,
+			payload: map[string]string{},
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := proxyAuditTarget(tt.kind, tt.payload); got != tt.want {
+				t.Errorf("proxyAuditTarget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
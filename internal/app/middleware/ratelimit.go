@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+// limiterIdleTimeout 超过该时间未使用的令牌桶会被回收
+const limiterIdleTimeout = 10 * time.Minute
+
+// RateLimitMW 按 rateLimit.groups 中 name 对应的规则限流，未开启或未配置规则时不限制。
+// 规则在每次请求时读取，配置重新加载后立即生效。
+// 需放在登录、OpenAuth 中间件之后，才能按用户、AccessToken 计数
+func RateLimitMW(name string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		limiter := &rateLimiter{name: name, idleTimeout: limiterIdleTimeout}
+		return func(c echo.Context) error {
+			buckets := limiter.current()
+			if buckets == nil {
+				return next(c)
+			}
+
+			key := rateLimitKey(c)
+			delay, allowed := buckets.take(key, time.Now())
+			if allowed {
+				return next(c)
+			}
+
+			xlog.Warn("request rate limited",
+				xlog.String("name", name),
+				xlog.String("key", key),
+				xlog.String("path", c.Request().URL.Path))
+
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			return c.JSON(http.StatusTooManyRequests, output.JSONResult{
+				Code:    output.MsgRateLimited,
+				Message: output.Message(output.MsgRateLimited),
+			})
+		}
+	}
+}
+
+// rateLimitKey 登录用户按 uid 计数，OpenAPI 按 AccessToken 计数，其余按来源 IP 计数
+func rateLimitKey(c echo.Context) string {
+	if token, ok := c.Get("OpenAuthAccessToken").(db.AccessToken); ok {
+		return "token:" + token.AppID
+	}
+	if u := user.GetUser(c); u != nil && u.Uid > 0 {
+		return "user:" + strconv.Itoa(u.Uid)
+	}
+	return "ip:" + ClientIP(c)
+}
+
+type (
+	// rateLimiter 持有当前规则对应的令牌桶，规则变化后重建，已有的计数清零
+	rateLimiter struct {
+		name        string
+		idleTimeout time.Duration
+
+		mtx     sync.Mutex
+		rule    cfg.RateLimitRule
+		buckets *rateBuckets
+	}
+
+	// rateBuckets 按 key 维护独立的令牌桶
+	rateBuckets struct {
+		rule        cfg.RateLimitRule
+		idleTimeout time.Duration
+
+		mtx       sync.Mutex
+		buckets   map[string]*rateBucket
+		lastSweep time.Time
+	}
+
+	rateBucket struct {
+		limiter  *rate.Limiter
+		lastUsed time.Time
+	}
+)
+
+// current 返回当前规则的令牌桶，未开启限流时返回 nil
+func (l *rateLimiter) current() *rateBuckets {
+	conf := cfg.Cfg.RateLimit
+	rule, ok := conf.Groups[l.name]
+	if !conf.Enable || !ok || rule.Rate <= 0 {
+		return nil
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.buckets == nil || l.rule != rule {
+		l.rule = rule
+		l.buckets = newRateBuckets(rule, l.idleTimeout)
+	}
+	return l.buckets
+}
+
+func newRateBuckets(rule cfg.RateLimitRule, idleTimeout time.Duration) *rateBuckets {
+	if rule.Burst <= 0 {
+		rule.Burst = int(math.Ceil(rule.Rate))
+	}
+	return &rateBuckets{
+		rule:        rule,
+		idleTimeout: idleTimeout,
+		buckets:     make(map[string]*rateBucket),
+	}
+}
+
+// take 从 key 的令牌桶中取一个令牌，取不到时返回需要等待的时间
+func (r *rateBuckets) take(key string, now time.Time) (delay time.Duration, allowed bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// 回收闲置的令牌桶，最多每个 idleTimeout 扫描一次
+	if now.Sub(r.lastSweep) > r.idleTimeout {
+		for k, b := range r.buckets {
+			if now.Sub(b.lastUsed) > r.idleTimeout {
+				delete(r.buckets, k)
+			}
+		}
+		r.lastSweep = now
+	}
+
+	b, ok := r.buckets[key]
+	if !ok {
+		b = &rateBucket{limiter: rate.NewLimiter(rate.Limit(r.rule.Rate), r.rule.Burst)}
+		r.buckets[key] = b
+	}
+	b.lastUsed = now
+
+	reservation := b.limiter.ReserveN(now, 1)
+	delay = reservation.DelayFrom(now)
+	if delay == 0 {
+		return 0, true
+	}
+	reservation.CancelAt(now)
+	return delay, false
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+func TestRateBucketsTake(t *testing.T) {
+	buckets := newRateBuckets(cfg.RateLimitRule{Rate: 1, Burst: 2}, time.Minute)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if _, ok := buckets.take("user:1", now); !ok {
+			t.Fatalf("request %d should be allowed within burst", i)
+		}
+	}
+	delay, ok := buckets.take("user:1", now)
+	if ok {
+		t.Fatal("request exceeding burst should be limited")
+	}
+	if delay <= 0 || delay > time.Second {
+		t.Errorf("unexpected delay %s", delay)
+	}
+
+	if _, ok = buckets.take("user:2", now); !ok {
+		t.Error("keys should be limited independently")
+	}
+
+	// 被拒绝的请求不消耗令牌，1 秒后补充一个令牌
+	if _, ok = buckets.take("user:1", now.Add(time.Second)); !ok {
+		t.Error("token should be refilled after 1s")
+	}
+}
+
+func TestRateBucketsSweep(t *testing.T) {
+	buckets := newRateBuckets(cfg.RateLimitRule{Rate: 1}, time.Minute)
+	if buckets.rule.Burst != 1 {
+		t.Errorf("burst should default to rate, got %d", buckets.rule.Burst)
+	}
+
+	now := time.Now()
+	buckets.take("ip:10.0.0.1", now)
+	buckets.take("ip:10.0.0.2", now.Add(2*time.Minute))
+	if _, ok := buckets.buckets["ip:10.0.0.1"]; ok {
+		t.Error("idle bucket should be removed")
+	}
+	if len(buckets.buckets) != 1 {
+		t.Errorf("got %d buckets, want 1", len(buckets.buckets))
+	}
+}
+
+func TestRateLimiterReload(t *testing.T) {
+	old := cfg.Cfg.RateLimit
+	t.Cleanup(func() { cfg.Cfg.RateLimit = old })
+
+	limiter := &rateLimiter{name: "test", idleTimeout: time.Minute}
+	cfg.Cfg.RateLimit = cfg.RateLimit{Enable: true}
+	if limiter.current() != nil {
+		t.Fatal("group without rule should not be limited")
+	}
+
+	cfg.Cfg.RateLimit.Groups = map[string]cfg.RateLimitRule{"test": {Rate: 1, Burst: 2}}
+	buckets := limiter.current()
+	if buckets == nil || buckets.rule.Burst != 2 {
+		t.Fatalf("unexpected buckets %+v", buckets)
+	}
+	if limiter.current() != buckets {
+		t.Error("buckets should be reused while rule unchanged")
+	}
+
+	cfg.Cfg.RateLimit.Groups = map[string]cfg.RateLimitRule{"test": {Rate: 1, Burst: 5}}
+	if next := limiter.current(); next == buckets || next.rule.Burst != 5 {
+		t.Error("buckets should be rebuilt after rule changed")
+	}
+
+	cfg.Cfg.RateLimit.Enable = false
+	if limiter.current() != nil {
+		t.Error("disabled rate limit should not be limited")
+	}
+}
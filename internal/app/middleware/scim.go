@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/service/serviceaccount"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/scim"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+// SCIMAuthMW SCIM 接口认证，IdP 使用拥有 scim scope 的服务账号凭证，失败时按 SCIM 规范返回错误
+func SCIMAuthMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := bearerToken(c.Request().Header.Get(echo.HeaderAuthorization))
+		if !strings.HasPrefix(token, serviceaccount.TokenPrefix) {
+			return scimDenied(c, http.StatusUnauthorized, "service account bearer token required")
+		}
+
+		account, err := serviceaccount.ServiceAccount.Authenticate(token, c.RealIP())
+		if err != nil {
+			return scimDenied(c, http.StatusUnauthorized, err.Error())
+		}
+		if !serviceaccount.Allowed(account, db.ServiceAccountScopeSCIM) {
+			return scimDenied(c, http.StatusForbidden, "service account "+account.Name+" does not have scope "+db.ServiceAccountScopeSCIM)
+		}
+
+		return serviceAccountNext(c, next, account.Name, false)
+	}
+}
+
+func scimDenied(c echo.Context, status int, reason string) error {
+	xlog.Warn("scim request denied",
+		xlog.String("path", c.Request().URL.Path),
+		xlog.String("ip", c.RealIP()),
+		xlog.String("reason", reason))
+
+	c.Response().Header().Set(echo.HeaderContentType, scim.ContentType)
+	return c.JSON(status, scim.NewError(status, "", reason))
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/serviceaccount"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// ContextServiceAccount 请求所属服务账号名称在 echo.Context 中的 key
+	ContextServiceAccount = "service_account"
+
+	// 使用 proxyAuth.token 的请求记录的服务账号名称
+	sharedTokenAccount = "shared-token"
+	// 未携带 Token 的心跳请求记录的服务账号名称
+	anonymousAccount = "anonymous"
+)
+
+var sharedTokenWarnOnce sync.Once
+
+// ServiceAccountMW 服务账号认证，要求服务账号拥有 scope
+func ServiceAccountMW(scope string) echo.MiddlewareFunc {
+	return serviceAccountMW(scope, false)
+}
+
+// ServiceAccountHeartbeatMW 心跳接口的服务账号认证，开启 allowAnonymousHeartbeat 时允许不携带 Token
+func ServiceAccountHeartbeatMW(scope string) echo.MiddlewareFunc {
+	return serviceAccountMW(scope, true)
+}
+
+func serviceAccountMW(scope string, heartbeat bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := c.Request().Header.Get("Token")
+			if token == "" {
+				token = bearerToken(c.Request().Header.Get(echo.HeaderAuthorization))
+			}
+
+			switch {
+			case token == "":
+				if !heartbeat || !cfg.Cfg.ServiceAccount.AllowAnonymousHeartbeat {
+					return serviceAccountDenied(c, scope, "service account token required")
+				}
+				return serviceAccountNext(c, next, anonymousAccount, heartbeat)
+
+			case strings.HasPrefix(token, serviceaccount.TokenPrefix):
+				account, err := serviceaccount.ServiceAccount.Authenticate(token, c.RealIP())
+				if err != nil {
+					return serviceAccountDenied(c, scope, err.Error())
+				}
+				if !serviceaccount.Allowed(account, scope) {
+					return serviceAccountDenied(c, scope, "service account "+account.Name+" does not have scope "+scope)
+				}
+				return serviceAccountNext(c, next, account.Name, heartbeat)
+
+			case cfg.Cfg.ServiceAccount.AllowSharedToken && cfg.Cfg.ProxyAuth.Token != "" && token == cfg.Cfg.ProxyAuth.Token:
+				sharedTokenWarnOnce.Do(func() {
+					xlog.Warn("service account: request authenticated with shared proxyAuth.token, please migrate to service accounts",
+						xlog.String("ip", c.RealIP()), xlog.String("path", c.Request().URL.Path))
+				})
+				return serviceAccountNext(c, next, sharedTokenAccount, heartbeat)
+			}
+
+			return serviceAccountDenied(c, scope, "invalid service account token")
+		}
+	}
+}
+
+// serviceAccountNext 记录请求所属的服务账号，心跳请求频繁，只在 debug 级别输出
+func serviceAccountNext(c echo.Context, next echo.HandlerFunc, account string, heartbeat bool) error {
+	c.Set(ContextServiceAccount, account)
+
+	fields := []zap.Field{
+		xlog.String("account", account),
+		xlog.String("method", c.Request().Method),
+		xlog.String("path", c.Request().URL.Path),
+		xlog.String("ip", c.RealIP()),
+	}
+	if heartbeat {
+		xlog.Debug("service account request", fields...)
+	} else {
+		xlog.Info("service account request", fields...)
+	}
+
+	return next(c)
+}
+
+func serviceAccountDenied(c echo.Context, scope, reason string) error {
+	xlog.Warn("service account request denied",
+		xlog.String("scope", scope),
+		xlog.String("path", c.Request().URL.Path),
+		xlog.String("ip", c.RealIP()),
+		xlog.String("reason", reason))
+	return output.JSON(c, output.MsgNoAuth, "forbidden: "+reason)
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/labstack/echo/v4"
+)
+
+// 未完成两步验证时仍可访问的接口，用于登录和开启两步验证
+var twoFactorSkipPrefixes = []string{
+	"/api/admin/public/",
+	"/api/admin/user/login",
+}
+
+// TwoFactorMW 角色要求开启两步验证的用户，在当前会话完成两步验证之前只能访问 public 接口
+func TwoFactorMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if skipTwoFactor(c) {
+			return next(c)
+		}
+
+		u := user.Session.Read(c)
+		if u == nil || !user.TwoFactorRequired(u) {
+			return next(c)
+		}
+
+		if !user.Session.TwoFactorVerifiedAt(c).IsZero() {
+			return next(c)
+		}
+
+		return twoFactorResp(c, u)
+	}
+}
+
+// TwoFactorSensitiveMW 生产环境的敏感操作，开启了两步验证的用户需要在最近 SensitiveWindow 内完成过验证
+func TwoFactorSensitiveMW(parserFn AppEnvParser) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := c.Get(user.ContextTokenUser).(*db.User); ok {
+				return next(c)
+			}
+
+			u := user.Session.Read(c)
+			if u == nil {
+				return output.JSON(c, output.MsgNeedLogin, "forbidden")
+			}
+
+			bodyBytes, _ := ioutil.ReadAll(c.Request().Body)
+			c.Request().Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+
+			_, env, err := parserFn(c)
+
+			c.Request().Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+			if err != nil {
+				return output.JSON(c, output.MsgInvalidParam, "can not get app,env from context: "+err.Error(), nil)
+			}
+
+			if !isProductionEnv(env) {
+				return next(c)
+			}
+
+			enabled, err := user.User.TOTPEnabled(u.Uid)
+			if err != nil {
+				return output.JSONError(c, err)
+			}
+			if !enabled && !user.TwoFactorRequired(u) {
+				return next(c)
+			}
+
+			verifiedAt := user.Session.TwoFactorVerifiedAt(c)
+			if !verifiedAt.IsZero() && time.Since(verifiedAt) <= cfg.Cfg.Auth.TwoFactor.SensitiveWindow {
+				return next(c)
+			}
+
+			return twoFactorResp(c, u)
+		}
+	}
+}
+
+func twoFactorResp(c echo.Context, u *db.User) error {
+	enabled, err := user.User.TOTPEnabled(u.Uid)
+	if err != nil {
+		return output.JSONError(c, err)
+	}
+	if !enabled {
+		return output.JSON(c, output.MsgNeedTwoFactorEnroll, "请先开启两步验证", nil)
+	}
+	return output.JSON(c, output.MsgNeedTwoFactor, "请完成两步验证", nil)
+}
+
+func skipTwoFactor(c echo.Context) bool {
+	if _, ok := c.Get(user.ContextTokenUser).(*db.User); ok {
+		return true
+	}
+
+	path := c.Request().URL.Path
+	for _, prefix := range twoFactorSkipPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isProductionEnv(env string) bool {
+	for _, e := range cfg.Cfg.App.ProductionEnvs {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/confgov2"
+	"github.com/douyu/juno/internal/pkg/service/permission"
+	"github.com/douyu/juno/internal/pkg/service/testplatform"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/labstack/echo/v4"
+)
+
+// ZoneParser 从 echo context 中获取请求涉及的机房，返回空表示不涉及具体机房
+type ZoneParser func(c echo.Context) (zone string, err error)
+
+// ParseZoneFromContext 从 Query/Body 参数 zone_code 或 zone 中获取机房
+func ParseZoneFromContext(c echo.Context) (zone string, err error) {
+	payload := struct {
+		ZoneCode string `json:"zone_code" query:"zone_code"`
+		Zone     string `json:"zone" query:"zone"`
+	}{}
+	err = c.Bind(&payload)
+	if err != nil {
+		return "", err
+	}
+
+	if payload.ZoneCode != "" {
+		return payload.ZoneCode, nil
+	}
+	return payload.Zone, nil
+}
+
+// ParseZoneFromConfigID 从配置ID获取配置所在机房
+func ParseZoneFromConfigID(c echo.Context) (zone string, err error) {
+	payload := struct {
+		ID uint `json:"id" query:"id"`
+	}{}
+	err = c.Bind(&payload)
+	if err != nil {
+		return "", err
+	}
+
+	detail, err := confgov2.Detail(view.ReqDetailConfig{ID: payload.ID})
+	if err != nil {
+		return
+	}
+
+	return detail.Zone, nil
+}
+
+// ParseZoneFromPipelineID 从 Query 或 Body 的流水线ID获取流水线所在机房
+func ParseZoneFromPipelineID(c echo.Context) (zone string, err error) {
+	payload := struct {
+		ID uint `json:"id" query:"id"`
+	}{}
+
+	if id := c.QueryParam("id"); id != "" {
+		pipelineID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		payload.ID = uint(pipelineID)
+	} else {
+		err = c.Bind(&payload)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return testplatform.PipelineZone(payload.ID)
+}
+
+// ParseZoneFromPipelineQuery 从 Query 参数 pipeline_id 获取流水线所在机房
+func ParseZoneFromPipelineQuery(c echo.Context) (zone string, err error) {
+	pipelineID, err := strconv.ParseUint(c.QueryParam("pipeline_id"), 10, 64)
+	if err != nil {
+		return "", err
+	}
+
+	return testplatform.PipelineZone(uint(pipelineID))
+}
+
+// ParseZoneFromTaskID 从 Query 参数 task_id 获取任务所在机房
+func ParseZoneFromTaskID(c echo.Context) (zone string, err error) {
+	taskID, err := strconv.ParseUint(c.QueryParam("task_id"), 10, 64)
+	if err != nil {
+		return "", err
+	}
+
+	return testplatform.TaskZone(uint(taskID))
+}
+
+// ZoneScopeMW 校验用户是否可以访问请求涉及的机房，与 CasbinAppMW 配合使用
+func ZoneScopeMW(parserFn ZoneParser) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// 获取 Body 内容
+			bodyBytes, _ := ioutil.ReadAll(c.Request().Body)
+			c.Request().Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+
+			zone, err := parserFn(c)
+
+			// 写回 Request Body
+			c.Request().Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+			if err != nil {
+				return output.JSON(c, output.MsgInvalidParam, "can not get zone from context: "+err.Error(), nil)
+			}
+
+			u := user.GetUser(c)
+			if u == nil {
+				return output.JSON(c, output.MsgNeedLogin, "forbidden")
+			}
+
+			allowed, err := permission.ZoneScope.Allowed(u, zone)
+			if err != nil {
+				return output.JSONError(c, err)
+			}
+			if !allowed {
+				return AuthFailedResp(c, "当前用户没有该机房的访问权限，请联系管理员", zone, "zone")
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/douyu/juno/internal/pkg/service/report"
 	"github.com/douyu/juno/pkg/cfg"
 	"github.com/douyu/juno/pkg/pb"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/douyu/jupiter"
 	"github.com/douyu/jupiter/pkg"
 	"github.com/douyu/jupiter/pkg/client/etcdv3"
@@ -79,6 +80,7 @@ func (eng *Proxy) initConfig() (err error) {
 	bizConfig.Dir = cfg.Cfg.Logger.Biz.Dir
 	bizConfig.Async = cfg.Cfg.Logger.Biz.Async
 	xlog.DefaultLogger = bizConfig.Build()
+	tracing.Init(cfg.Cfg.Trace, "juno-proxy")
 	invoker.Init()
 	return
 }
@@ -134,6 +136,7 @@ func (eng *Proxy) serveHTTP() (err error) {
 	server := serverConfig.Build()
 	server.Debug = true
 	server.Validator = &FormValidator{validator: validator.New()}
+	proxyHealth().Register(server.Echo)
 	apiV1(server)
 
 	err = eng.Serve(server)
@@ -187,6 +190,7 @@ func (eng *Proxy) serveGovern() (err error) {
 
 func apiV1(server *xecho.Server) {
 	server.POST("/*", apiproxy.ProxyPost)
+	server.POST("/api/v1/worker/register", apiproxy.WorkerHeartbeat)
 	server.POST("/api/v1/worker/heartbeat", apiproxy.WorkerHeartbeat)
 	server.POST("/api/v1/resource/node/heartbeat", apiproxy.NodeHeartBeat)
 	server.POST("/api/v1/testworker/platform/dispatch", apiproxy.DispatchTask)
@@ -0,0 +1,31 @@
+package proxyengine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/douyu/juno/internal/pkg/invoker"
+	"github.com/douyu/juno/internal/pkg/service/proxy"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/health"
+)
+
+// proxyHealth 配置中心 etcd、元数据库启用时为关键依赖；与 juno-admin 的通知流断开时只标记为 degraded
+func proxyHealth() *health.Checker {
+	h := health.New(0)
+	if cfg.Cfg.ServerProxy.DefaultEtcd.Enable {
+		h.Critical("etcd", health.Etcd(invoker.ConfgoEtcd))
+	}
+	if cfg.Cfg.Database.Enable {
+		h.Critical(cfg.Cfg.Database.Dialect, func(ctx context.Context) error {
+			return health.SQL(invoker.JunoMysql.DB())(ctx)
+		})
+	}
+	h.Optional("admin_stream", func(ctx context.Context) error {
+		if proxy.StreamStore == nil || !proxy.StreamStore.IsStreamExist() {
+			return fmt.Errorf("juno-admin is not connected")
+		}
+		return nil
+	})
+	return h
+}
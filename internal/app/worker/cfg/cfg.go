@@ -1,8 +1,12 @@
 package cfg
 
 import (
+	"os"
+	"strings"
 	"time"
 
+	"github.com/douyu/juno/pkg/taskqueue"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/douyu/jupiter/pkg/conf"
 )
 
@@ -17,6 +21,24 @@ type (
 			ParallelWorker   int
 			RepoStorageDir   string
 			TestTaskQueueDir string
+			// MaxStepLogSize 单个阶段保留的日志大小（字节），为 0 时使用默认值 4MB
+			MaxStepLogSize int
+			// GoToolchainDir 流水线指定 Go 版本时，各版本的安装目录
+			GoToolchainDir string
+			// GoDownloadURL Go 发布包的下载地址，为空时使用 https://dl.google.com/go/
+			GoDownloadURL string
+			// WorkspaceDir 任务独立的 HOME、GOPATH、临时目录所在的目录，为空时使用系统临时目录
+			WorkspaceDir string
+			// StepTimeout 流水线没有设置超时时间的阶段使用的超时时间，为 0 时使用默认值 5 分钟
+			StepTimeout time.Duration
+			// ShutdownTimeout 退出时等待执行中的任务结束的时间，超时后终止任务并重新入队，为 0 时使用默认值 5 分钟
+			ShutdownTimeout time.Duration
+			// Queue 任务队列，多个 worker 共享任务时使用 redis 或 nsq
+			Queue taskqueue.Config
+			// Labels worker 的标签，如 arch = "arm64"、go = "1.21"，流水线要求的标签全部匹配时才会执行任务
+			Labels map[string]string
+			// LogStream 通过 WebSocket 长连接按行实时上报阶段日志，连接不可用时使用 HTTP
+			LogStream bool
 		}
 
 		Heartbeat struct {
@@ -30,6 +52,8 @@ type (
 			ZoneName   string
 			Env        string
 		}
+
+		Trace tracing.Config
 	}
 )
 
@@ -37,6 +61,55 @@ var (
 	Cfg cfg
 )
 
-func Init() error {
-	return conf.UnmarshalKey("", &Cfg)
+// Init 加载配置：--config 指定的 TOML，环境变量 JUNO_WORKER_CONFIG 指定的 TOML/YAML，最后是 JUNO_WORKER_ 开头的环境变量。
+// 加载后填充默认值并校验目录、Juno 地址和 Token，有问题时直接返回，不启动 worker
+func Init() (err error) {
+	if file := os.Getenv(EnvConfigFile); file != "" {
+		err = loadFile(file)
+		if err != nil {
+			return
+		}
+	}
+
+	var c cfg
+	err = conf.UnmarshalKey("", &c)
+	if err != nil {
+		return
+	}
+	err = applyEnv(&c, os.LookupEnv)
+	if err != nil {
+		return
+	}
+	c.setDefaults()
+
+	err = c.Validate()
+	if err != nil {
+		return
+	}
+	err = CheckServer(c.Juno.Address, c.Juno.Token)
+	if err != nil {
+		return
+	}
+
+	Cfg = c
+	return
+}
+
+// setDefaults 未配置的项使用的默认值
+func (c *cfg) setDefaults() {
+	if c.Worker.ParallelWorker <= 0 {
+		c.Worker.ParallelWorker = 1
+	}
+	if c.Worker.Queue.Local.Dir == "" {
+		c.Worker.Queue.Local.Dir = c.Worker.TestTaskQueueDir
+	}
+	if c.Worker.Queue.MaxInFlight <= 0 {
+		c.Worker.Queue.MaxInFlight = c.Worker.ParallelWorker
+	}
+	if c.Heartbeat.Addr == "" && c.Juno.Address != "" {
+		c.Heartbeat.Addr = strings.TrimSuffix(c.Juno.Address, "/") + "/api/v1/worker/register"
+	}
+	if c.Heartbeat.Internal <= 0 {
+		c.Heartbeat.Internal = 3 * time.Second
+	}
 }
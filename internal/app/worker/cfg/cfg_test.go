@@ -0,0 +1,165 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnvName(t *testing.T) {
+	cases := map[string]string{
+		"Token":            "TOKEN",
+		"ParallelWorker":   "PARALLEL_WORKER",
+		"GoDownloadURL":    "GO_DOWNLOAD_URL",
+		"NSQDAddr":         "NSQD_ADDR",
+		"NSQ":              "NSQ",
+		"DB":               "DB",
+		"TestTaskQueueDir": "TEST_TASK_QUEUE_DIR",
+	}
+	for field, want := range cases {
+		if got := envName(field); got != want {
+			t.Errorf("envName(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"JUNO_WORKER_JUNO_TOKEN":                         "sa-token",
+		"JUNO_WORKER_WORKER_PARALLEL_WORKER":             "4",
+		"JUNO_WORKER_WORKER_QUEUE_VISIBILITY_TIMEOUT":    "2m",
+		"JUNO_WORKER_WORKER_QUEUE_REDIS_ADDRS":           "10.0.0.1:6379, 10.0.0.2:6379,",
+		"JUNO_WORKER_WORKER_LABELS":                      "arch=arm64, go=1.21,",
+		"JUNO_WORKER_HEARTBEAT_DEBUG":                    "true",
+		"JUNO_WORKER_TRACE_SAMPLE_RATE":                  "0.5",
+		"JUNO_WORKER_WORKER_QUEUE_NSQ_LOOKUPD_ADDRS":     "",
+		"JUNO_WORKER_WORKER_QUEUE_NSQ_NSQD_ADDR_UNKNOWN": "ignored",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	var c cfg
+	c.Juno.Address = "http://juno.local:50000"
+	c.Juno.Token = "from-file"
+	if err := applyEnv(&c, lookup); err != nil {
+		t.Fatal(err)
+	}
+	if c.Juno.Address != "http://juno.local:50000" || c.Juno.Token != "sa-token" {
+		t.Errorf("juno = %+v", c.Juno)
+	}
+	if c.Worker.ParallelWorker != 4 || c.Worker.Queue.VisibilityTimeout != 2*time.Minute {
+		t.Errorf("worker = %+v", c.Worker)
+	}
+	if want := []string{"10.0.0.1:6379", "10.0.0.2:6379"}; !reflect.DeepEqual(c.Worker.Queue.Redis.Addrs, want) {
+		t.Errorf("redis addrs = %v, want %v", c.Worker.Queue.Redis.Addrs, want)
+	}
+	if want := map[string]string{"arch": "arm64", "go": "1.21"}; !reflect.DeepEqual(c.Worker.Labels, want) {
+		t.Errorf("labels = %v, want %v", c.Worker.Labels, want)
+	}
+	if !c.Heartbeat.Debug || c.Trace.SampleRate != 0.5 {
+		t.Errorf("heartbeat debug = %v, trace sample rate = %v", c.Heartbeat.Debug, c.Trace.SampleRate)
+	}
+
+	env["JUNO_WORKER_WORKER_LABELS"] = "arm64"
+	if err := applyEnv(&c, lookup); err == nil {
+		t.Error("expect error for label without value")
+	}
+	env["JUNO_WORKER_WORKER_LABELS"] = ""
+
+	env["JUNO_WORKER_WORKER_PARALLEL_WORKER"] = "four"
+	err := applyEnv(&c, lookup)
+	if err == nil || !strings.Contains(err.Error(), "JUNO_WORKER_WORKER_PARALLEL_WORKER") {
+		t.Errorf("expect error naming the variable, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "worker-cfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var c cfg
+	c.Juno.Address = "http://juno.local:50000"
+	c.Juno.Token = "sa-token"
+	c.Worker.RepoStorageDir = filepath.Join(dir, "repos")
+	c.Worker.TestTaskQueueDir = filepath.Join(dir, "queue")
+	c.setDefaults()
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.Worker.RepoStorageDir); err != nil {
+		t.Errorf("repo storage dir not created: %v", err)
+	}
+	if c.Heartbeat.Addr != "http://juno.local:50000/api/v1/worker/register" {
+		t.Errorf("default heartbeat addr = %q", c.Heartbeat.Addr)
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	c.Juno.Address = "juno.local:50000"
+	c.Juno.Token = ""
+	c.Worker.WorkspaceDir = filepath.Join(file, "workspaces")
+	c.Worker.Queue.Backend = "kafka"
+	err = c.Validate()
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	for _, want := range []string{"juno.address", "JUNO_WORKER_JUNO_TOKEN", "worker.workspaceDir", "worker.queue.backend"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %s, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestCheckServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/worker/check" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Token") != "sa-token" {
+			_, _ = w.Write([]byte(`{"code":14000,"msg":"forbidden: invalid service account token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"msg":"success"}`))
+	}))
+	defer server.Close()
+
+	if err := CheckServer(server.URL+"/", "sa-token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckServer(server.URL, "wrong"); err == nil || !strings.Contains(err.Error(), "juno.token") {
+		t.Errorf("expect token error, got %v", err)
+	}
+	if err := CheckServer(server.URL+"/prefix", "sa-token"); err == nil || !strings.Contains(err.Error(), "juno.address") {
+		t.Errorf("expect address error, got %v", err)
+	}
+}
+
+func TestUnmarshalYAML(t *testing.T) {
+	content := []byte("juno:\n  address: http://juno.local:50000\nworker:\n  queue:\n    redis:\n      addrs: [\"127.0.0.1:6379\"]\n")
+	raw := make(map[string]interface{})
+	if err := unmarshalYAML(content, &raw); err != nil {
+		t.Fatal(err)
+	}
+	juno, ok := raw["juno"].(map[string]interface{})
+	if !ok || juno["address"] != "http://juno.local:50000" {
+		t.Fatalf("juno = %#v", raw["juno"])
+	}
+	queue := raw["worker"].(map[string]interface{})["queue"].(map[string]interface{})
+	if _, ok := queue["redis"].(map[string]interface{}); !ok {
+		t.Errorf("nested map not converted: %#v", queue["redis"])
+	}
+}
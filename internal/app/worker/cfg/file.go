@@ -0,0 +1,80 @@
+package cfg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/douyu/jupiter/pkg/conf"
+	"gopkg.in/yaml.v2"
+)
+
+// EnvConfigFile 指定额外配置文件的环境变量，按扩展名使用 TOML 或 YAML 解析，覆盖 --config 中的同名配置
+const EnvConfigFile = "JUNO_WORKER_CONFIG"
+
+func loadFile(path string) error {
+	var unmarshal func([]byte, interface{}) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		unmarshal = toml.Unmarshal
+	case ".yaml", ".yml":
+		unmarshal = unmarshalYAML
+	default:
+		return fmt.Errorf("config file %s (%s): unsupported format, use .toml, .yaml or .yml", path, EnvConfigFile)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config file %s (%s): %v", path, EnvConfigFile, err)
+	}
+	defer f.Close()
+
+	err = conf.LoadFromReader(f, unmarshal)
+	if err != nil {
+		return fmt.Errorf("config file %s (%s): %v", path, EnvConfigFile, err)
+	}
+	return nil
+}
+
+// unmarshalYAML yaml.v2 解析出的嵌套 map 键为 interface{}，转换为 map[string]interface{} 后才能和 TOML 的配置合并
+func unmarshalYAML(content []byte, v interface{}) error {
+	out, ok := v.(*map[string]interface{})
+	if !ok {
+		return yaml.Unmarshal(content, v)
+	}
+
+	var raw map[string]interface{}
+	err := yaml.Unmarshal(content, &raw)
+	if err != nil {
+		return err
+	}
+	*out = stringKeys(raw).(map[string]interface{})
+	return nil
+}
+
+func stringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = stringKeys(value)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = stringKeys(value)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, value := range v {
+			s[i] = stringKeys(value)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,141 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/pkg/taskqueue"
+)
+
+// Validate 检查配置能否启动 worker，返回所有问题，每条问题说明对应的配置项和环境变量
+func (c cfg) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Juno.Address == "" {
+		add("juno.address is empty, set it to the Juno Admin address such as http://juno.local:50000 (env %s)", envKey("Juno", "Address"))
+	} else if !isHTTPURL(c.Juno.Address) {
+		add("juno.address %q is not a http(s) URL (env %s)", c.Juno.Address, envKey("Juno", "Address"))
+	}
+	if c.Juno.Token == "" {
+		add("juno.token is empty, create a service account with worker scope in Juno Admin and use its token (env %s)", envKey("Juno", "Token"))
+	}
+
+	type dirCheck struct {
+		key, env, path string
+		required       bool
+	}
+	dirs := []dirCheck{
+		{"worker.repoStorageDir", envKey("Worker", "RepoStorageDir"), c.Worker.RepoStorageDir, true},
+		{"worker.goToolchainDir", envKey("Worker", "GoToolchainDir"), c.Worker.GoToolchainDir, false},
+		{"worker.workspaceDir", envKey("Worker", "WorkspaceDir"), c.Worker.WorkspaceDir, false},
+	}
+	switch c.Worker.Queue.Backend {
+	case "", taskqueue.BackendLocal:
+		dirs = append(dirs, dirCheck{"worker.testTaskQueueDir", envKey("Worker", "TestTaskQueueDir"), c.Worker.Queue.Local.Dir, true})
+	case taskqueue.BackendRedis:
+		if len(c.Worker.Queue.Redis.Addrs) == 0 {
+			add("worker.queue.redis.addrs is empty (env %s)", envKey("Worker", "Queue", "Redis", "Addrs"))
+		}
+	case taskqueue.BackendNSQ:
+		if c.Worker.Queue.NSQ.NSQDAddr == "" {
+			add("worker.queue.nsq.nsqdAddr is empty (env %s)", envKey("Worker", "Queue", "NSQ", "NSQDAddr"))
+		}
+	default:
+		add("worker.queue.backend %q is not supported, use %s, %s or %s (env %s)", c.Worker.Queue.Backend,
+			taskqueue.BackendLocal, taskqueue.BackendRedis, taskqueue.BackendNSQ, envKey("Worker", "Queue", "Backend"))
+	}
+	for _, dir := range dirs {
+		if dir.path == "" {
+			if dir.required {
+				add("%s is empty (env %s)", dir.key, dir.env)
+			}
+			continue
+		}
+		err := writableDir(dir.path)
+		if err != nil {
+			add("%s %q is not writable: %v, create it or grant write permission to the worker user (env %s)", dir.key, dir.path, err, dir.env)
+		}
+	}
+
+	if c.Worker.GoDownloadURL != "" && !isHTTPURL(c.Worker.GoDownloadURL) {
+		add("worker.goDownloadURL %q is not a http(s) URL (env %s)", c.Worker.GoDownloadURL, envKey("Worker", "GoDownloadURL"))
+	}
+	if c.Worker.MaxStepLogSize < 0 {
+		add("worker.maxStepLogSize %d is negative, use 0 for the default 4MB (env %s)", c.Worker.MaxStepLogSize, envKey("Worker", "MaxStepLogSize"))
+	}
+	if c.Worker.StepTimeout < 0 {
+		add("worker.stepTimeout %s is negative, use 0 for the default 5m (env %s)", c.Worker.StepTimeout, envKey("Worker", "StepTimeout"))
+	}
+	if c.Worker.ShutdownTimeout < 0 {
+		add("worker.shutdownTimeout %s is negative, use 0 for the default 5m (env %s)", c.Worker.ShutdownTimeout, envKey("Worker", "ShutdownTimeout"))
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid worker config:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// CheckServer 请求 Juno Admin 的 /api/v1/worker/check，确认地址可以访问、Token 有效并且有 worker 权限
+func CheckServer(address, token string) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(address, "/")+"/api/v1/worker/check", nil)
+	if err != nil {
+		return fmt.Errorf("juno.address %q is invalid: %v (env %s)", address, err, envKey("Juno", "Address"))
+	}
+	req.Header.Set("Token", token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("juno admin %s is unreachable: %v, check juno.address (env %s) and the network between worker and admin",
+			address, err, envKey("Juno", "Address"))
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response from juno admin %s failed: %v", address, err)
+	}
+	var res struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if resp.StatusCode == http.StatusNotFound || json.Unmarshal(body, &res) != nil {
+		return fmt.Errorf("%s does not look like juno admin (HTTP %s), check juno.address (env %s)", address, resp.Status, envKey("Juno", "Address"))
+	}
+	if res.Code != output.MsgOk {
+		return fmt.Errorf("juno admin rejected juno.token: %s, use the token of a service account with worker scope (env %s)", res.Msg, envKey("Juno", "Token"))
+	}
+	return nil
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// writableDir 目录不存在时创建，并确认 worker 可以在其中创建文件
+func writableDir(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, ".juno-check-")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/douyu/juno/internal/app/worker/testworker"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/taskqueue"
+	"github.com/labstack/echo/v4"
+)
+
+// Queue 等待中的任务和暂停状态
+func Queue(c echo.Context) (err error) {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	queue, err := testworker.Instance().Queue(c.Request().Context(), limit)
+	if err != nil {
+		return output.JSON(c, output.MsgErr, "list queue failed: "+err.Error())
+	}
+
+	return output.JSON(c, output.MsgOk, "success", queue)
+}
+
+// RemoveQueued 删除等待中的任务
+func RemoveQueued(c echo.Context) (err error) {
+	var params view.ReqQueueItem
+
+	err = c.Bind(&params)
+	if err == nil {
+		err = c.Validate(&params)
+	}
+	if err != nil {
+		return output.JSON(c, output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = testworker.Instance().RemoveQueued(c.Request().Context(), params.ID)
+	return queueResult(c, err)
+}
+
+// MoveQueuedToFront 将等待中的任务移到队首
+func MoveQueuedToFront(c echo.Context) (err error) {
+	var params view.ReqQueueItem
+
+	err = c.Bind(&params)
+	if err == nil {
+		err = c.Validate(&params)
+	}
+	if err != nil {
+		return output.JSON(c, output.MsgInvalidParam, "invalid params: "+err.Error())
+	}
+
+	err = testworker.Instance().MoveQueuedToFront(c.Request().Context(), params.ID)
+	return queueResult(c, err)
+}
+
+// PauseQueue 暂停消费队列
+func PauseQueue(c echo.Context) error {
+	testworker.Instance().Pause()
+	return output.JSON(c, output.MsgOk, "success")
+}
+
+// ResumeQueue 恢复消费队列
+func ResumeQueue(c echo.Context) error {
+	testworker.Instance().Resume()
+	return output.JSON(c, output.MsgOk, "success")
+}
+
+// Drain 不再接收新任务，外部扩缩容系统下线 worker 前调用
+func Drain(c echo.Context) error {
+	testworker.Instance().Drain()
+	return DrainStatus(c)
+}
+
+// Undrain 恢复接收任务
+func Undrain(c echo.Context) error {
+	testworker.Instance().Undrain()
+	return DrainStatus(c)
+}
+
+// DrainStatus drain 状态，Idle 为 true 时可以安全下线
+func DrainStatus(c echo.Context) error {
+	status, err := testworker.Instance().DrainStatus(c.Request().Context())
+	if err != nil {
+		return output.JSON(c, output.MsgErr, "get drain status failed: "+err.Error())
+	}
+
+	return output.JSON(c, output.MsgOk, "success", status)
+}
+
+func queueResult(c echo.Context, err error) error {
+	switch err {
+	case nil:
+		return output.JSON(c, output.MsgOk, "success")
+	case taskqueue.ErrNotFound:
+		return output.JSON(c, output.MsgNotFound, "task not found in queue, it may have been taken by worker")
+	default:
+		return output.JSON(c, output.MsgErr, err.Error())
+	}
+}
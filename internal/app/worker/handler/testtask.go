@@ -15,6 +15,15 @@ func DispatchTestTask(c echo.Context) (err error) {
 		return output.JSON(c, output.MsgErr, "invalid params"+err.Error())
 	}
 
+	// Juno 不会向 drain 的 worker 下发任务，多个 Juno 实例之间状态同步前仍可能下发
+	if testworker.Instance().Draining() {
+		return output.JSON(c, output.MsgErr, testworker.ErrDraining.Error())
+	}
+
+	if !testworker.Instance().MatchLabels(params.WorkerLabels) {
+		return output.JSON(c, output.MsgErr, testworker.ErrLabelsUnmatched.Error())
+	}
+
 	err = testworker.Instance().Push(params)
 	if err != nil {
 		return output.JSON(c, output.MsgErr, "enqueue failed: "+err.Error())
@@ -22,3 +31,18 @@ func DispatchTestTask(c echo.Context) (err error) {
 
 	return output.JSON(c, output.MsgOk, "success")
 }
+
+// CancelTestTask 终止执行中的任务，任务不在本 worker 上执行时忽略
+func CancelTestTask(c echo.Context) (err error) {
+	var params view.ReqCancelTask
+
+	err = c.Bind(&params)
+	if err != nil {
+		return output.JSON(c, output.MsgErr, "invalid params"+err.Error())
+	}
+
+	found := testworker.Instance().Cancel(params.TaskID)
+	return output.JSON(c, output.MsgOk, "success", map[string]interface{}{
+		"found": found,
+	})
+}
@@ -1,33 +1,29 @@
 package heartbeat
 
 import (
+	"context"
 	"time"
 
 	"github.com/douyu/juno/internal/app/worker/cfg"
+	"github.com/douyu/juno/internal/app/worker/testworker"
 	"github.com/douyu/juno/pkg/model/view"
 	"github.com/douyu/juno/pkg/util"
+	"github.com/douyu/jupiter/pkg"
 	"github.com/douyu/jupiter/pkg/server/xecho"
 	"github.com/douyu/jupiter/pkg/xlog"
 	"github.com/go-resty/resty/v2"
 )
 
+// Start 定时向 Juno 注册，上报节点信息、容量、队列长度和执行中的任务
 func Start() error {
 	config := cfg.Cfg.Heartbeat
-	client := resty.New()
+	client := resty.New().SetHeader("Token", cfg.Cfg.Juno.Token)
+	startedAt := time.Now()
 
 	go func() {
 		for {
 			req := client.R()
-			req.SetBody(view.WorkerHeartbeat{
-				IP:         util.ExternalIPString(),
-				Port:       xecho.StdConfig("http").Port,
-				HostName:   config.HostName,
-				RegionCode: config.RegionCode,
-				RegionName: config.RegionName,
-				ZoneCode:   config.ZoneCode,
-				ZoneName:   config.ZoneName,
-				Env:        config.Env,
-			})
+			req.SetBody(register(startedAt))
 
 			_, err := req.Post(config.Addr)
 			if err != nil {
@@ -39,3 +35,27 @@ func Start() error {
 	}()
 	return nil
 }
+
+func register(startedAt time.Time) view.WorkerHeartbeat {
+	config := cfg.Cfg.Heartbeat
+	worker := testworker.Instance()
+	ctx, cancel := context.WithTimeout(context.Background(), config.Internal)
+	defer cancel()
+
+	return view.WorkerHeartbeat{
+		IP:         util.ExternalIPString(),
+		Port:       xecho.StdConfig("http").Port,
+		HostName:   config.HostName,
+		RegionCode: config.RegionCode,
+		RegionName: config.RegionName,
+		ZoneCode:   config.ZoneCode,
+		ZoneName:   config.ZoneName,
+		Env:        config.Env,
+		Version:    pkg.AppVersion(),
+		Capacity:   worker.Capacity(),
+		QueueDepth: worker.QueueDepth(ctx),
+		StartedAt:  startedAt,
+		Tasks:      worker.RunningTasks(),
+		Labels:     worker.Labels(),
+	}
+}
@@ -1,8 +1,15 @@
 package worker
 
 import (
+	"context"
+	"strings"
+
+	"github.com/douyu/juno/internal/app/worker/cfg"
 	"github.com/douyu/juno/internal/app/worker/handler"
+	"github.com/douyu/juno/internal/app/worker/testworker"
+	"github.com/douyu/juno/pkg/health"
 	"github.com/douyu/jupiter/pkg/server/xecho"
+	"github.com/douyu/jupiter/pkg/xlog"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
@@ -14,11 +21,57 @@ func (w *Worker) serveHttp() error {
 		validator: validator.New(),
 	}
 
+	workerHealth().Register(s.Echo)
 	apiV1(s.Group("/api/v1"))
 
-	return w.Serve(s)
+	return w.Serve(&workerServer{Server: s})
+}
+
+// workerServer 退出时先等待执行中的任务结束，期间仍然可以查询 drain 状态，之后再停止 http 服务。
+// jupiter 收到 SIGTERM 时调用 Stop，收到 SIGQUIT 时调用 GracefulStop，两者都平滑退出
+type workerServer struct {
+	*xecho.Server
+}
+
+// Stop 与 GracefulStop 相同，等待时间为 worker.shutdownTimeout
+func (s *workerServer) Stop() error {
+	return s.GracefulStop(context.Background())
+}
+
+// GracefulStop 最长等待 worker.shutdownTimeout，超时后终止剩余的任务并重新入队
+func (s *workerServer) GracefulStop(ctx context.Context) error {
+	timeout := cfg.Cfg.Worker.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = testworker.DefaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := testworker.Instance().Shutdown(ctx)
+	if err != nil {
+		xlog.Error("shutdown test worker failed", xlog.FieldErr(err))
+	}
+	return s.Server.Stop()
+}
+
+// workerHealth 任务队列不可用时未就绪，Juno 不可达时只标记为 degraded
+func workerHealth() *health.Checker {
+	return health.New(0).
+		Critical("queue", testworker.Instance().CheckQueue).
+		Optional("juno", health.HTTP(nil, strings.TrimSuffix(cfg.Cfg.Juno.Address, "/")+"/healthz"))
 }
 
 func apiV1(g *echo.Group) {
 	g.POST("/testTask/dispatch", handler.DispatchTestTask)
+	g.POST("/testTask/cancel", handler.CancelTestTask)
+
+	g.GET("/queue", handler.Queue)
+	g.POST("/queue/remove", handler.RemoveQueued)
+	g.POST("/queue/moveToFront", handler.MoveQueuedToFront)
+	g.POST("/queue/pause", handler.PauseQueue)
+	g.POST("/queue/resume", handler.ResumeQueue)
+
+	g.GET("/drain", handler.DrainStatus)
+	g.POST("/drain", handler.Drain)
+	g.POST("/undrain", handler.Undrain)
 }
@@ -0,0 +1,107 @@
+package testworker
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GoPackage go list -json 输出中影响分析、选择构建包用到的字段
+type GoPackage struct {
+	ImportPath   string
+	Name         string
+	Dir          string
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+}
+
+// ParseGoList 解析 go list -json 输出的包列表
+func ParseGoList(r io.Reader) (packages []GoPackage, err error) {
+	decoder := json.NewDecoder(r)
+	for {
+		var pkg GoPackage
+		err = decoder.Decode(&pkg)
+		if err == io.EOF {
+			return packages, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, pkg)
+	}
+}
+
+// AffectedPackages 变更影响的包，包括变更文件所在的包和直接、间接导入这些包的包（含测试代码的导入）。
+// changedFiles 为绝对路径，不属于任何包的文件如文档不影响测试。
+// go.mod、go.sum 变更时依赖版本可能变化，返回 full 为 true，需要执行全部测试
+func AffectedPackages(packages []GoPackage, changedFiles []string) (affected []string, full bool) {
+	changed := make(map[string]bool)
+	for _, file := range changedFiles {
+		base := filepath.Base(file)
+		if base == "go.mod" || base == "go.sum" {
+			return nil, true
+		}
+
+		// 文件属于目录最深的包，如 pkg/a/testdata/x.json 属于 pkg/a
+		owner := ""
+		ownerDir := ""
+		for _, pkg := range packages {
+			if isUnder(file, pkg.Dir) && len(pkg.Dir) > len(ownerDir) {
+				owner, ownerDir = pkg.ImportPath, pkg.Dir
+			}
+		}
+		if owner != "" {
+			changed[owner] = true
+		}
+	}
+
+	// 反向依赖：被导入的包 -> 导入它的包
+	importers := make(map[string][]string)
+	for _, pkg := range packages {
+		for _, list := range [][]string{pkg.Imports, pkg.TestImports, pkg.XTestImports} {
+			for _, imp := range list {
+				importers[imp] = append(importers[imp], pkg.ImportPath)
+			}
+		}
+	}
+
+	queue := make([]string, 0, len(changed))
+	for pkg := range changed {
+		queue = append(queue, pkg)
+	}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, importer := range importers[pkg] {
+			if !changed[importer] {
+				changed[importer] = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+
+	// 只返回当前模块中的包，外部测试包 xxx_test 随所属的包一起执行
+	local := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		local[pkg.ImportPath] = true
+	}
+	for pkg := range changed {
+		if local[pkg] {
+			affected = append(affected, pkg)
+		}
+	}
+	sort.Strings(affected)
+	return affected, false
+}
+
+// isUnder file 是否在 dir 目录下
+func isUnder(file, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(dir, filepath.Dir(file))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
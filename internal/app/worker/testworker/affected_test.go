@@ -0,0 +1,37 @@
+package testworker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAffectedPackages(t *testing.T) {
+	list := `{"ImportPath":"m/a","Dir":"/src/a"}
+{"ImportPath":"m/a/b","Dir":"/src/a/b","Imports":["fmt"]}
+{"ImportPath":"m/c","Dir":"/src/c","Imports":["m/a/b"]}
+{"ImportPath":"m/d","Dir":"/src/d","TestImports":["m/c"]}
+{"ImportPath":"m/e","Dir":"/src/e","Imports":["m/a"]}`
+	packages, err := ParseGoList(strings.NewReader(list))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 5 {
+		t.Fatalf("expect 5 packages, got %d", len(packages))
+	}
+
+	affected, full := AffectedPackages(packages, []string{"/src/a/b/testdata/x.json", "/src/README.md"})
+	if full || !reflect.DeepEqual(affected, []string{"m/a/b", "m/c", "m/d"}) {
+		t.Errorf("unexpected affected packages %v, full = %v", affected, full)
+	}
+
+	affected, _ = AffectedPackages(packages, []string{"/src/docs/x.md"})
+	if len(affected) != 0 {
+		t.Errorf("docs change should not affect packages, got %v", affected)
+	}
+
+	_, full = AffectedPackages(packages, []string{"/src/a/x.go", "/src/go.sum"})
+	if !full {
+		t.Error("go.sum change should run all tests")
+	}
+}
@@ -0,0 +1,205 @@
+package testworker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+const (
+	// maxStepArtifacts 每个阶段最多上传的制品文件数
+	maxStepArtifacts = 100
+	// inlineArtifactSize Juno 没有配置对象存储时随事件上报的制品大小上限，与 Juno 的限制相同
+	inlineArtifactSize = 16 * 1024 * 1024
+)
+
+var (
+	errTooManyArtifacts = fmt.Errorf("too many artifacts")
+
+	// artifactClient 上传到对象存储，大文件上传耗时较长，只限制总时间
+	artifactClient = &http.Client{Timeout: 30 * time.Minute}
+)
+
+// uploadStepArtifacts 上传阶段声明的制品，阶段失败时同样上传，便于排查。任务取消后不再上传
+func (t *TestWorker) uploadStepArtifacts(task view.TestTask, step db.TestPipelineStep) {
+	if len(step.Artifacts) == 0 || t.taskRun(task).Canceled() {
+		return
+	}
+
+	dir := t.codeBaseDir(task)
+	names, err := findArtifacts(dir, step.Artifacts, maxStepArtifacts)
+	if err != nil {
+		xlog.Error("TestWorker.uploadStepArtifacts find artifacts failed", xlog.String("step", step.Name), xlog.String("err", err.Error()))
+	}
+	if len(names) >= maxStepArtifacts {
+		xlog.Warn("TestWorker.uploadStepArtifacts too many artifacts, the rest are skipped",
+			xlog.String("step", step.Name), xlog.Int("limit", maxStepArtifacts))
+	}
+
+	for _, name := range names {
+		err = t.uploadArtifactFile(task, step.Name, filepath.Join(dir, filepath.FromSlash(name)), name)
+		if err != nil {
+			xlog.Error("TestWorker.uploadStepArtifacts upload failed",
+				xlog.String("step", step.Name), xlog.String("artifact", name), xlog.String("err", err.Error()))
+		}
+	}
+}
+
+// findArtifacts 代码目录下与任一路径匹配的文件，返回以 / 分隔的相对路径。
+// 跳过 .git 目录，不跟随符号链接，最多返回 limit 个
+func findArtifacts(dir string, patterns []string, limit int) (names []string, err error) {
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		for _, pattern := range patterns {
+			if pipeline.MatchArtifactPath(pattern, rel) {
+				names = append(names, rel)
+				break
+			}
+		}
+		if len(names) >= limit {
+			return errTooManyArtifacts
+		}
+		return nil
+	})
+	if err == errTooManyArtifacts {
+		err = nil
+	}
+	return
+}
+
+func (t *TestWorker) uploadArtifactFile(task view.TestTask, stepName, file, name string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return t.uploadArtifact(task, stepName, name, contentType, f, info.Size())
+}
+
+// uploadArtifact Juno 配置了对象存储时上传到 Juno 签发的地址后上报 key，否则随事件上报制品内容
+func (t *TestWorker) uploadArtifact(task view.TestTask, stepName, name, contentType string, body io.Reader, size int64) (err error) {
+	upload, err := t.prepareArtifact(task, view.ReqPrepareTaskArtifact{
+		TaskID:      task.TaskID,
+		StepName:    stepName,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+	})
+	if err != nil {
+		return
+	}
+
+	payload := view.TestTaskArtifactPayload{
+		StepName:    stepName,
+		Name:        name,
+		ContentType: contentType,
+	}
+	if upload.Storage {
+		err = putArtifact(upload.URL, contentType, body, size)
+		if err != nil {
+			return
+		}
+		payload.StorageKey = upload.Key
+	} else {
+		if size > inlineArtifactSize {
+			return fmt.Errorf("artifact is larger than %d bytes and Juno has no artifact storage", inlineArtifactSize)
+		}
+		payload.Content, err = ioutil.ReadAll(body)
+		if err != nil {
+			return
+		}
+	}
+
+	t.notifyTaskEvent(task, view.TaskArtifactEvent, payload)
+	return nil
+}
+
+// prepareArtifact 申请上传地址。通过 juno-proxy 连接或 Juno 版本较低时没有该接口，按没有配置对象存储处理
+func (t *TestWorker) prepareArtifact(task view.TestTask, req view.ReqPrepareTaskArtifact) (upload view.TestTaskArtifactUpload, err error) {
+	resp, err := t.client.R().SetHeaders(task.Trace).SetBody(req).Post("/api/v1/worker/testTask/artifact/prepare")
+	if err != nil {
+		return
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return upload, nil
+	}
+
+	respObj := struct {
+		Code int                         `json:"code"`
+		Msg  string                      `json:"msg"`
+		Data view.TestTaskArtifactUpload `json:"data"`
+	}{}
+	err = json.Unmarshal(resp.Body(), &respObj)
+	if err != nil {
+		return
+	}
+	if respObj.Code != output.MsgOk {
+		return upload, fmt.Errorf("prepare artifact upload failed: %s", respObj.Msg)
+	}
+	return respObj.Data, nil
+}
+
+// putArtifact 使用预签名地址上传
+func putArtifact(url, contentType string, body io.Reader, size int64) error {
+	req, err := http.NewRequest(http.MethodPut, url, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := artifactClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload artifact: %s %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package testworker
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFindArtifacts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"coverage.out", "bin/app", "reports/a/junit.xml", "reports/b.xml", ".git/config.xml", "main.go"} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 不跟随指向代码目录之外的符号链接
+	_ = os.Symlink("/etc/passwd", filepath.Join(dir, "bin", "passwd"))
+
+	names, err := findArtifacts(dir, []string{"coverage.out", "bin/*", "**/*.xml"}, 10)
+	sort.Strings(names)
+	want := []string{"bin/app", "coverage.out", "reports/a/junit.xml", "reports/b.xml"}
+	if err != nil || !reflect.DeepEqual(names, want) {
+		t.Fatalf("findArtifacts() = %v, %v, want %v", names, err, want)
+	}
+
+	names, err = findArtifacts(dir, []string{"**"}, 2)
+	if err != nil || len(names) != 2 {
+		t.Errorf("findArtifacts() with limit = %v, %v", names, err)
+	}
+}
+
+func TestPutArtifact(t *testing.T) {
+	var body, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.ContentLength != 5 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		body, contentType = string(data), r.Header.Get("Content-Type")
+		if r.URL.Query().Get("X-Amz-Signature") == "expired" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("<Error><Code>AccessDenied</Code></Error>"))
+		}
+	}))
+	defer server.Close()
+
+	err := putArtifact(server.URL+"/bucket/tasks/1/bin/app?X-Amz-Signature=ok", "application/octet-stream", strings.NewReader("hello"), 5)
+	if err != nil || body != "hello" || contentType != "application/octet-stream" {
+		t.Fatalf("putArtifact() = %v, body %q, content type %q", err, body, contentType)
+	}
+
+	err = putArtifact(server.URL+"/bucket/tasks/1/bin/app?X-Amz-Signature=expired", "text/plain", strings.NewReader("hello"), 5)
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("putArtifact() err = %v, want AccessDenied", err)
+	}
+}
@@ -0,0 +1,101 @@
+package testworker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// ParseBenchmarks 解析 go test -bench 的输出，同一基准测试多次执行时取平均值，按首次出现的顺序返回
+func ParseBenchmarks(r io.Reader) (results db.BenchmarkResults, err error) {
+	index := make(map[string]int)
+	pkg := ""
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "pkg: ") {
+			pkg = strings.TrimSpace(strings.TrimPrefix(line, "pkg: "))
+			continue
+		}
+		result, ok := parseBenchmarkLine(line)
+		if !ok {
+			continue
+		}
+		result.Package = pkg
+
+		key := pkg + "." + result.Name
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(results)
+			results = append(results, result)
+			continue
+		}
+		// 累计后在最后求平均
+		sum := &results[i]
+		sum.Runs++
+		sum.Iterations += result.Iterations
+		sum.NsPerOp += result.NsPerOp
+		sum.BytesPerOp += result.BytesPerOp
+		sum.AllocsPerOp += result.AllocsPerOp
+	}
+	for i := range results {
+		n := float64(results[i].Runs)
+		results[i].Iterations /= int64(results[i].Runs)
+		results[i].NsPerOp /= n
+		results[i].BytesPerOp /= n
+		results[i].AllocsPerOp /= n
+	}
+	return results, scanner.Err()
+}
+
+// parseBenchmarkLine 解析一行结果，如 BenchmarkEncode-8  1000000  1052 ns/op  128 B/op  2 allocs/op
+func parseBenchmarkLine(line string) (result db.BenchmarkResult, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return
+	}
+	iterations, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return
+	}
+
+	result = db.BenchmarkResult{Name: fields[0], Runs: 1, Iterations: iterations}
+	for i := 2; i+1 < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return result, false
+		}
+		switch fields[i+1] {
+		case "ns/op":
+			result.NsPerOp = value
+			ok = true
+		case "B/op":
+			result.BytesPerOp = value
+		case "allocs/op":
+			result.AllocsPerOp = value
+		}
+	}
+	return
+}
+
+// writeBenchmarkReport 输出各基准测试的结果和相对基准的变化，性能退化的标记为 REGRESSION
+func writeBenchmarkReport(w io.Writer, results []view.BenchmarkResult) {
+	for _, item := range results {
+		fmt.Fprintf(w, "%-60s %14.1f ns/op", item.Package+"."+item.Name, item.NsPerOp)
+		if item.Delta == nil {
+			fmt.Fprintln(w, "  (no baseline)")
+			continue
+		}
+		fmt.Fprintf(w, "  %+.1f%% vs %.1f ns/op", *item.Delta*100, item.Baseline.NsPerOp)
+		if item.Regression {
+			fmt.Fprint(w, "  REGRESSION")
+		}
+		fmt.Fprintln(w)
+	}
+}
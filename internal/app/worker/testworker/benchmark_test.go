@@ -0,0 +1,63 @@
+package testworker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func TestParseBenchmarks(t *testing.T) {
+	out := `goos: linux
+goarch: amd64
+pkg: example.com/app/codec
+cpu: Intel(R) Xeon(R) CPU
+BenchmarkEncode-8   	 1000000	      1000 ns/op	     128 B/op	       2 allocs/op
+BenchmarkEncode-8   	 3000000	      1200 ns/op	     128 B/op	       4 allocs/op
+BenchmarkDecode/small-8         	  500000	      2500 ns/op
+PASS
+ok  	example.com/app/codec	3.012s
+pkg: example.com/app/store
+BenchmarkGet-8   	 2000000	       600.5 ns/op
+--- FAIL: BenchmarkBroken-8
+BenchmarkNoUnit-8 100 fast
+PASS
+`
+	results, err := ParseBenchmarks(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := db.BenchmarkResults{
+		{Package: "example.com/app/codec", Name: "BenchmarkEncode-8", Runs: 2, Iterations: 2000000, NsPerOp: 1100, BytesPerOp: 128, AllocsPerOp: 3},
+		{Package: "example.com/app/codec", Name: "BenchmarkDecode/small-8", Runs: 1, Iterations: 500000, NsPerOp: 2500},
+		{Package: "example.com/app/store", Name: "BenchmarkGet-8", Runs: 1, Iterations: 2000000, NsPerOp: 600.5},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("ParseBenchmarks() = %+v", results)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+}
+
+func TestWriteBenchmarkReport(t *testing.T) {
+	delta := 0.25
+	var buf bytes.Buffer
+	writeBenchmarkReport(&buf, []view.BenchmarkResult{
+		{
+			BenchmarkResult: db.BenchmarkResult{Package: "a", Name: "BenchmarkX-8", NsPerOp: 1250},
+			Baseline:        &db.BenchmarkResult{Package: "a", Name: "BenchmarkX-8", NsPerOp: 1000},
+			Delta:           &delta,
+			Regression:      true,
+		},
+		{BenchmarkResult: db.BenchmarkResult{Package: "a", Name: "BenchmarkY-8", NsPerOp: 10}},
+	})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "+25.0% vs 1000.0 ns/op  REGRESSION") || !strings.HasSuffix(lines[1], "(no baseline)") {
+		t.Errorf("report:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,44 @@
+package testworker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxBuildErrors 编译失败时错误信息中列出的编译错误数
+const maxBuildErrors = 5
+
+// buildErrorRegexp go 编译错误的格式，如 ./main.go:10:2: undefined: foo
+var buildErrorRegexp = regexp.MustCompile(`^\S+\.go:\d+(:\d+)?: .+`)
+
+// parseBuildErrors 从编译输出中提取编译错误，忽略 # 开头的包名和其他输出
+func parseBuildErrors(out []byte) (errs []string) {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if buildErrorRegexp.MatchString(line) {
+			errs = append(errs, line)
+		}
+	}
+	return
+}
+
+// buildFailure 编译失败的原因，输出中有编译错误时列出前几个，否则使用命令的错误
+func buildFailure(out []byte, err error) error {
+	errs := parseBuildErrors(out)
+	if len(errs) == 0 {
+		return fmt.Errorf("build failed: %v", err)
+	}
+	shown := errs
+	if len(shown) > maxBuildErrors {
+		shown = shown[:maxBuildErrors]
+	}
+	more := ""
+	if len(errs) > len(shown) {
+		more = fmt.Sprintf("\n... and %d more", len(errs)-len(shown))
+	}
+	return fmt.Errorf("build failed with %d compile errors:\n%s%s", len(errs), strings.Join(shown, "\n"), more)
+}
@@ -0,0 +1,37 @@
+package testworker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBuildFailure(t *testing.T) {
+	out := []byte("# example.com/app/internal/foo\n" +
+		"internal/foo/foo.go:10:2: undefined: bar\n" +
+		"  internal/foo/foo.go:12: missing return\n" +
+		"note: module requires Go 1.21\n")
+	errs := parseBuildErrors(out)
+	if len(errs) != 2 || errs[0] != "internal/foo/foo.go:10:2: undefined: bar" || errs[1] != "internal/foo/foo.go:12: missing return" {
+		t.Fatalf("parseBuildErrors() = %q", errs)
+	}
+
+	err := buildFailure(out, fmt.Errorf("exit status 2"))
+	if !strings.HasPrefix(err.Error(), "build failed with 2 compile errors:\n") {
+		t.Errorf("buildFailure() = %v", err)
+	}
+
+	var many []string
+	for i := 1; i <= maxBuildErrors+2; i++ {
+		many = append(many, fmt.Sprintf("main.go:%d:1: syntax error", i))
+	}
+	err = buildFailure([]byte(strings.Join(many, "\n")), fmt.Errorf("exit status 2"))
+	if !strings.HasSuffix(err.Error(), "\n... and 2 more") || strings.Contains(err.Error(), many[maxBuildErrors]) {
+		t.Errorf("buildFailure() with many errors = %v", err)
+	}
+
+	err = buildFailure([]byte("sh: make: not found\n"), fmt.Errorf("exit status 127"))
+	if err.Error() != "build failed: exit status 127" {
+		t.Errorf("buildFailure() without compile errors = %v", err)
+	}
+}
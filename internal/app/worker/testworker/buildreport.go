@@ -0,0 +1,126 @@
+package testworker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+const (
+	// buildReportTopPackages 构建报告中保留的包数量
+	buildReportTopPackages = 20
+
+	// linkerPackage 链接器生成的符号，如 go:buildid、go:string.*
+	linkerPackage = "<linker>"
+	// otherPackage 无法识别所属包的符号，如 cgo 符号
+	otherPackage = "<other>"
+)
+
+// symbolPrefixes 类型、itab 等编译器生成的符号前缀，去掉后是所属包的符号
+var symbolPrefixes = []string{"type:.eq.", "type..eq.", "type:", "type.", "go:itab.", "go.itab."}
+
+// ParseNmSizes 解析 go tool nm -size 的输出，按包汇总符号大小
+func ParseNmSizes(r io.Reader) (sizes map[string]int64, err error) {
+	sizes = make(map[string]int64)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// 地址 大小 类型 名称，未定义的符号没有地址
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		if len(fields) == 3 {
+			fields = append([]string{""}, fields...)
+		}
+
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil || size == 0 || fields[2] == "U" {
+			continue
+		}
+		sizes[SymbolPackage(strings.Join(fields[3:], " "))] += size
+	}
+	return sizes, scanner.Err()
+}
+
+// SymbolPackage 符号所属的包，如 github.com/a/b.(*T).Method 属于 github.com/a/b
+func SymbolPackage(name string) string {
+	for _, prefix := range symbolPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			name = name[len(prefix):]
+			break
+		}
+	}
+	name = strings.TrimLeft(name, "*")
+	if strings.HasPrefix(name, "go:") || strings.HasPrefix(name, "go.") {
+		return linkerPackage
+	}
+
+	// 泛型实例化、方法接收者、itab 的接口部分中可能出现其他包名
+	if i := strings.IndexAny(name, "[(,"); i >= 0 {
+		name = name[:i]
+	}
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot <= 0 {
+		return otherPackage
+	}
+	return name[:slash+1+dot]
+}
+
+// TopPackages 占用最大的 n 个包
+func TopPackages(sizes map[string]int64, n int) db.BuildPackageSizes {
+	packages := make(db.BuildPackageSizes, 0, len(sizes))
+	for pkg, size := range sizes {
+		packages = append(packages, db.BuildPackageSize{Package: pkg, Size: size})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Size != packages[j].Size {
+			return packages[i].Size > packages[j].Size
+		}
+		return packages[i].Package < packages[j].Package
+	})
+	if len(packages) > n {
+		packages = packages[:n]
+	}
+	return packages
+}
+
+// MainPackage 选择构建的 main 包，根目录是 main 包时使用根目录，否则使用导入路径最小的 main 包
+func MainPackage(packages []GoPackage, rootDir string) (string, error) {
+	var mains []GoPackage
+	for _, pkg := range packages {
+		if pkg.Name != "main" {
+			continue
+		}
+		if pkg.Dir == rootDir {
+			return ".", nil
+		}
+		mains = append(mains, pkg)
+	}
+	if len(mains) == 0 {
+		return "", fmt.Errorf("no main package found")
+	}
+
+	sort.Slice(mains, func(i, j int) bool {
+		return mains[i].ImportPath < mains[j].ImportPath
+	})
+	return mains[0].ImportPath, nil
+}
+
+// formatSize 以合适的单位展示字节数
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.2f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.2f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,70 @@
+package testworker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSymbolPackage(t *testing.T) {
+	cases := []struct {
+		symbol string
+		want   string
+	}{
+		{"runtime.mallocgc", "runtime"},
+		{"main.main", "main"},
+		{"github.com/a/b.(*T).Method", "github.com/a/b"},
+		{"github.com/a/b.Map[go.shape.string,github.com/c/d.V]", "github.com/a/b"},
+		{"type:*github.com/a/b.T", "github.com/a/b"},
+		{"type..eq.net/http.Request", "net/http"},
+		{"go:itab.*net/http.conn,io.Reader", "net/http"},
+		{"go:buildid", linkerPackage},
+		{"_cgo_init", otherPackage},
+	}
+	for _, c := range cases {
+		if got := SymbolPackage(c.symbol); got != c.want {
+			t.Errorf("SymbolPackage(%s) = %s, want %s", c.symbol, got, c.want)
+		}
+	}
+}
+
+func TestParseNmSizes(t *testing.T) {
+	out := `  4a3b20     3000 T runtime.mallocgc
+  4a3b21     1000 T runtime.gcStart
+  5b0000     2500 R type:*github.com/a/b.T
+  5c0000      500 T github.com/a/b.(*T).Method
+                0 U _cgo_init
+  6d0000        0 D runtime.empty
+`
+	sizes, err := ParseNmSizes(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	top := TopPackages(sizes, 1)
+	if len(top) != 1 || top[0].Package != "runtime" || top[0].Size != 4000 {
+		t.Errorf("unexpected top packages %+v", top)
+	}
+	if sizes["github.com/a/b"] != 3000 || len(sizes) != 2 {
+		t.Errorf("unexpected sizes %+v", sizes)
+	}
+}
+
+func TestMainPackage(t *testing.T) {
+	packages := []GoPackage{
+		{ImportPath: "example.com/app/pkg", Name: "pkg", Dir: "/src/pkg"},
+		{ImportPath: "example.com/app/cmd/worker", Name: "main", Dir: "/src/cmd/worker"},
+		{ImportPath: "example.com/app/cmd/server", Name: "main", Dir: "/src/cmd/server"},
+	}
+	if pkg, _ := MainPackage(packages, "/src"); pkg != "example.com/app/cmd/server" {
+		t.Errorf("unexpected main package %s", pkg)
+	}
+
+	packages = append(packages, GoPackage{ImportPath: "example.com/app", Name: "main", Dir: "/src"})
+	if pkg, _ := MainPackage(packages, "/src"); pkg != "." {
+		t.Errorf("unexpected main package %s", pkg)
+	}
+
+	if _, err := MainPackage(packages[:1], "/src"); err == nil {
+		t.Error("expected error without main package")
+	}
+}
@@ -0,0 +1,202 @@
+package testworker
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+var (
+	// ErrTaskCanceled 任务已取消，执行中的命令被终止
+	ErrTaskCanceled = fmt.Errorf("task canceled")
+	// ErrStepTimeout 阶段执行超时，执行中的命令被终止
+	ErrStepTimeout = fmt.Errorf("step timeout, commands killed")
+)
+
+type (
+	// taskRun 任务在 worker 上的执行状态，记录任务启动的命令，取消时终止命令所在的进程组
+	taskRun struct {
+		mtx      sync.Mutex
+		canceled bool
+		done     chan struct{} // 取消时关闭
+		cmds     map[*exec.Cmd]struct{}
+		info     db.WorkerTask // 随 worker 注册上报
+		// interrupted worker 退出时终止的任务，重新入队而不是标记为取消
+		interrupted bool
+		// secrets 任务引用的密钥，masker 把日志中的密钥值替换为 ***
+		secrets map[string]string
+		masker  *strings.Replacer
+	}
+)
+
+func newTaskRun() *taskRun {
+	return &taskRun{done: make(chan struct{}), cmds: make(map[*exec.Cmd]struct{})}
+}
+
+func (r *taskRun) Canceled() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.canceled
+}
+
+// start 在独立的进程组中启动命令，任务已经取消时不再启动
+func (r *taskRun) start(cmd *exec.Cmd) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.canceled {
+		return ErrTaskCanceled
+	}
+	setProcessGroup(cmd)
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+	r.cmds[cmd] = struct{}{}
+	return nil
+}
+
+// run 执行命令直到结束，任务被取消时返回 ErrTaskCanceled。
+// deadline 不为零时，到期后终止命令并返回 ErrStepTimeout
+func (r *taskRun) run(cmd *exec.Cmd, deadline time.Time) error {
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return ErrStepTimeout
+	}
+	err := r.start(cmd)
+	if err != nil {
+		return err
+	}
+
+	var timedOut int32
+	if !deadline.IsZero() {
+		timer := time.AfterFunc(time.Until(deadline), func() {
+			atomic.StoreInt32(&timedOut, 1)
+			err := r.kill(cmd)
+			if err != nil {
+				xlog.Warn("TestWorker: kill timeout process group failed", xlog.Int("pid", cmd.Process.Pid), xlog.String("err", err.Error()))
+			}
+		})
+		defer timer.Stop()
+	}
+	err = cmd.Wait()
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	delete(r.cmds, cmd)
+	if r.canceled {
+		return ErrTaskCanceled
+	}
+	if atomic.LoadInt32(&timedOut) == 1 {
+		return ErrStepTimeout
+	}
+	return err
+}
+
+// wait 等待 d，任务取消时提前返回 false
+func (r *taskRun) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// kill 终止执行中的命令，不影响任务的其他命令
+func (r *taskRun) kill(cmd *exec.Cmd) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.cmds[cmd]; !ok {
+		return nil
+	}
+	return killProcessGroup(cmd.Process.Pid)
+}
+
+func (r *taskRun) Interrupted() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.interrupted
+}
+
+// interrupt worker 退出时终止任务，已经取消过时返回 false
+func (r *taskRun) interrupt() bool {
+	r.mtx.Lock()
+	r.interrupted = !r.canceled
+	r.mtx.Unlock()
+	return r.cancel()
+}
+
+// cancel 取消任务并终止全部执行中的命令，已经取消过时返回 false
+func (r *taskRun) cancel() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.canceled {
+		return false
+	}
+	r.canceled = true
+	close(r.done)
+	for cmd := range r.cmds {
+		err := killProcessGroup(cmd.Process.Pid)
+		if err != nil {
+			xlog.Warn("TestWorker: kill process group failed", xlog.Int("pid", cmd.Process.Pid), xlog.String("err", err.Error()))
+		}
+	}
+	return true
+}
+
+// Cancel 取消本 worker 上执行中的任务，拆分下发的各部分都会终止，返回任务是否在本 worker 上执行
+func (t *TestWorker) Cancel(taskID uint) (found bool) {
+	t.runs.Range(func(key, value interface{}) bool {
+		if key.(taskKey).TaskID != taskID {
+			return true
+		}
+		found = true
+		if value.(*taskRun).cancel() {
+			xlog.Info("TestWorker: task canceled", xlog.Int("taskId", int(taskID)), xlog.Int("part", key.(taskKey).Part))
+		}
+		return true
+	})
+	return
+}
+
+// taskRun 任务的执行状态，任务不在执行中时返回新的状态，命令照常执行
+func (t *TestWorker) taskRun(task view.TestTask) *taskRun {
+	if run, ok := t.runs.Load(taskKey{TaskID: task.TaskID, Part: task.Part}); ok {
+		return run.(*taskRun)
+	}
+	return newTaskRun()
+}
+
+// runCmd 执行任务的命令，任务取消或阶段超时时命令被终止
+func (t *TestWorker) runCmd(task view.TestTask, cmd *exec.Cmd) error {
+	return t.taskRun(task).run(cmd, task.StepDeadline)
+}
+
+// cmdOutput 同 cmd.Output，任务取消时命令被终止
+func (t *TestWorker) cmdOutput(task view.TestTask, cmd *exec.Cmd) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := t.runCmd(task, cmd)
+	return stdout.Bytes(), err
+}
+
+// cmdCombinedOutput 同 cmd.CombinedOutput，任务取消时命令被终止
+func (t *TestWorker) cmdCombinedOutput(task view.TestTask, cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := t.runCmd(task, cmd)
+	return output.Bytes(), err
+}
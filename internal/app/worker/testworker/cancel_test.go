@@ -0,0 +1,105 @@
+package testworker
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func TestTaskRunCancel(t *testing.T) {
+	w := &TestWorker{}
+	task := view.TestTask{TaskID: 1, Part: 2}
+	run := newTaskRun()
+	w.runs.Store(taskKey{TaskID: task.TaskID, Part: task.Part}, run)
+
+	// 管道另一端的 sleep 持有输出，只终止 sh 时 Wait 不会返回
+	cmd := exec.Command("sh", "-c", "sleep 60 | cat")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	done := make(chan error, 1)
+	go func() {
+		done <- w.runCmd(task, cmd)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		run.mtx.Lock()
+		started := len(run.cmds) > 0
+		run.mtx.Unlock()
+		if started {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("command not started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if w.Cancel(2) {
+		t.Error("Cancel() of another task should not find the task")
+	}
+	if !w.Cancel(task.TaskID) {
+		t.Fatal("Cancel() should find the running task")
+	}
+	select {
+	case err := <-done:
+		if err != ErrTaskCanceled {
+			t.Errorf("runCmd() = %v, want ErrTaskCanceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("command not killed")
+	}
+
+	if run.cancel() {
+		t.Error("cancel() twice should return false")
+	}
+	if err := w.runCmd(task, exec.Command("true")); err != ErrTaskCanceled {
+		t.Errorf("runCmd() after cancel = %v, want ErrTaskCanceled", err)
+	}
+
+	// 不在执行中的任务照常执行命令
+	got, err := w.cmdOutput(view.TestTask{TaskID: 3}, exec.Command("echo", "ok"))
+	if err != nil || string(got) != "ok\n" {
+		t.Errorf("cmdOutput() = %q, %v", got, err)
+	}
+}
+
+func TestTaskRunDeadline(t *testing.T) {
+	w := &TestWorker{}
+	task := view.TestTask{TaskID: 1, StepDeadline: time.Now().Add(200 * time.Millisecond)}
+	w.runs.Store(taskKey{TaskID: task.TaskID}, newTaskRun())
+
+	start := time.Now()
+	if err := w.runCmd(task, exec.Command("sh", "-c", "sleep 60 | cat")); err != ErrStepTimeout {
+		t.Errorf("runCmd() = %v, want ErrStepTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("command killed after %s", elapsed)
+	}
+	if err := w.runCmd(task, exec.Command("true")); err != ErrStepTimeout {
+		t.Errorf("runCmd() after deadline = %v, want ErrStepTimeout", err)
+	}
+
+	task.StepDeadline = time.Now().Add(time.Minute)
+	if err := w.runCmd(task, exec.Command("true")); err != nil {
+		t.Errorf("runCmd() before deadline = %v", err)
+	}
+}
+
+func TestStepTimeout(t *testing.T) {
+	// 阶段没有设置超时时间时使用 worker 的默认值，默认值与之前单元测试写死的 5 分钟保持一致
+	if DefaultStepTimeout != 5*time.Minute {
+		t.Errorf("DefaultStepTimeout = %s, want 5m", DefaultStepTimeout)
+	}
+
+	w := &TestWorker{option: Option{StepTimeout: DefaultStepTimeout}}
+	if got := w.stepTimeout(0); got != 5*time.Minute {
+		t.Errorf("stepTimeout(0) = %s, want 5m", got)
+	}
+	if got := w.stepTimeout(1800); got != 30*time.Minute {
+		t.Errorf("stepTimeout(1800) = %s, want 30m", got)
+	}
+}
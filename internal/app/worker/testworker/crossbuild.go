@@ -0,0 +1,16 @@
+package testworker
+
+import (
+	"os"
+
+	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
+)
+
+// crossBuildEnv 在任务环境的基础上设置目标平台。没有设置 CGO_ENABLED 时，go 交叉编译默认关闭 cgo，
+// 依赖 cgo 的代码在目标平台上会构建失败，与发布时交叉编译的结果一致
+func crossBuildEnv(env []string, target pipeline.BuildTarget) []string {
+	if env == nil {
+		env = os.Environ()
+	}
+	return mergeEnv(env, map[string]string{"GOOS": target.GOOS, "GOARCH": target.GOARCH}, "")
+}
@@ -0,0 +1,21 @@
+package testworker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
+)
+
+func TestCrossBuildEnv(t *testing.T) {
+	env := crossBuildEnv([]string{"GOOS=linux", "HOME=/ws/home", "GOARCH=amd64"}, pipeline.BuildTarget{GOOS: "windows", GOARCH: "arm64"})
+	want := []string{"HOME=/ws/home", "GOARCH=arm64", "GOOS=windows"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("crossBuildEnv() = %v, want %v", env, want)
+	}
+
+	env = crossBuildEnv(nil, pipeline.BuildTarget{GOOS: "darwin", GOARCH: "amd64"})
+	if len(env) < 2 || env[len(env)-1] != "GOOS=darwin" || env[len(env)-2] != "GOARCH=amd64" {
+		t.Errorf("crossBuildEnv(nil) should keep worker environment and set target, got %v", env)
+	}
+}
@@ -0,0 +1,74 @@
+package testworker
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/taskqueue"
+)
+
+// ErrDraining worker 已经 drain，不再接收下发的任务
+var ErrDraining = fmt.Errorf("worker is draining")
+
+// Drain 不再接收下发的任务。共享队列（redis、nsq）同时暂停消费，剩余的任务由其他 worker 执行；
+// 本地队列中已经排队的任务继续执行，全部结束后 worker 空闲，可以安全下线
+func (t *TestWorker) Drain() {
+	t.drainMtx.Lock()
+	defer t.drainMtx.Unlock()
+
+	if t.draining {
+		return
+	}
+	t.draining = true
+	if t.sharedQueue() && !t.Paused() {
+		t.drainPaused = true
+		t.Pause()
+	}
+}
+
+// Undrain 恢复接收任务，drain 时暂停的消费同时恢复，drain 之前手动暂停的保持暂停
+func (t *TestWorker) Undrain() {
+	t.drainMtx.Lock()
+	defer t.drainMtx.Unlock()
+
+	if !t.draining {
+		return
+	}
+	t.draining = false
+	if t.drainPaused {
+		t.drainPaused = false
+		t.Resume()
+	}
+}
+
+func (t *TestWorker) Draining() bool {
+	t.drainMtx.Lock()
+	defer t.drainMtx.Unlock()
+	return t.draining
+}
+
+// DrainStatus drain 状态和剩余的任务数
+func (t *TestWorker) DrainStatus(ctx context.Context) (status view.WorkerDrainStatus, err error) {
+	status.Draining = t.Draining()
+	status.Running = int(atomic.LoadInt32(&t.running))
+
+	if !t.sharedQueue() {
+		if manager, ok := t.queue.(taskqueue.Manager); ok {
+			_, status.Queued, err = manager.Pending(ctx, 1)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	status.Idle = status.Draining && status.Running == 0 && status.Queued == 0
+	return
+}
+
+// sharedQueue 队列是否由多个 worker 共享
+func (t *TestWorker) sharedQueue() bool {
+	backend := t.option.Queue.Backend
+	return backend != "" && backend != taskqueue.BackendLocal
+}
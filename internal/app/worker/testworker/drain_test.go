@@ -0,0 +1,62 @@
+package testworker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/douyu/juno/pkg/taskqueue"
+)
+
+func TestWorkerDrainLocalQueue(t *testing.T) {
+	w := newTestWorker(&fakeQueue{messages: []*taskqueue.Message{{ID: "1"}}})
+
+	w.Drain()
+	if !w.Draining() || w.Paused() {
+		t.Fatalf("local queue should keep consuming while draining, paused = %v", w.Paused())
+	}
+
+	status, err := w.DrainStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Draining || status.Queued != 1 || status.Idle {
+		t.Errorf("status = %+v, want draining with 1 queued task", status)
+	}
+
+	w.queue = &fakeQueue{}
+	if status, _ = w.DrainStatus(context.Background()); !status.Idle {
+		t.Errorf("status = %+v, want idle", status)
+	}
+
+	w.Undrain()
+	if status, _ = w.DrainStatus(context.Background()); status.Draining || status.Idle {
+		t.Errorf("status = %+v after undrain", status)
+	}
+}
+
+func TestWorkerDrainSharedQueue(t *testing.T) {
+	w := newTestWorker(&fakeQueue{messages: []*taskqueue.Message{{ID: "1"}}})
+	w.option.Queue.Backend = taskqueue.BackendRedis
+
+	w.Drain()
+	if !w.Paused() {
+		t.Fatal("shared queue should be paused while draining")
+	}
+	// 共享队列中的任务由其他 worker 执行
+	status, _ := w.DrainStatus(context.Background())
+	if status.Queued != 0 || !status.Idle {
+		t.Errorf("status = %+v, want idle", status)
+	}
+	w.Undrain()
+	if w.Paused() {
+		t.Error("undrain should resume consuming")
+	}
+
+	// drain 之前手动暂停的，undrain 后保持暂停
+	w.Pause()
+	w.Drain()
+	w.Undrain()
+	if !w.Paused() {
+		t.Error("manually paused worker should stay paused")
+	}
+}
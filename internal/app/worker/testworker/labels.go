@@ -0,0 +1,39 @@
+package testworker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/taskqueue"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// unmatchedRequeueDelay 标签不匹配的任务放回共享队列后等待的时间，避免只有本 worker 消费时反复取到同一任务
+const unmatchedRequeueDelay = 3 * time.Second
+
+// ErrLabelsUnmatched worker 没有任务要求的全部标签
+var ErrLabelsUnmatched = fmt.Errorf("worker labels do not match task")
+
+// Labels worker 声明的标签，随注册上报给 Juno
+func (t *TestWorker) Labels() map[string]string {
+	return t.option.Labels
+}
+
+// MatchLabels worker 是否具有任务要求的全部标签
+func (t *TestWorker) MatchLabels(required map[string]string) bool {
+	return db.MapStringString(t.option.Labels).Contains(required)
+}
+
+// requeueUnmatched 共享队列中标签不匹配的任务放回队列，由具有这些标签的 worker 执行
+func (t *TestWorker) requeueUnmatched(msg *taskqueue.Message, task view.TestTask) {
+	xlog.Info("TestWorker: requeue task with unmatched labels",
+		xlog.Int("taskId", int(task.TaskID)), xlog.Any("labels", task.WorkerLabels))
+
+	err := t.queue.Nack(msg)
+	if err != nil {
+		xlog.Error("nack task failed", xlog.String("err", err.Error()), xlog.Int("taskId", int(task.TaskID)))
+	}
+	time.Sleep(unmatchedRequeueDelay)
+}
@@ -0,0 +1,30 @@
+package testworker
+
+import (
+	"testing"
+)
+
+func TestWorkerMatchLabels(t *testing.T) {
+	w := &TestWorker{option: Option{Labels: map[string]string{"arch": "arm64", "go": "1.21"}}}
+
+	for _, c := range []struct {
+		required map[string]string
+		want     bool
+	}{
+		{nil, true},
+		{map[string]string{"arch": "arm64"}, true},
+		{map[string]string{"arch": "arm64", "go": "1.21"}, true},
+		{map[string]string{"arch": "amd64"}, false},
+		{map[string]string{"arch": "arm64", "zone": "bj"}, false},
+	} {
+		if got := w.MatchLabels(c.required); got != c.want {
+			t.Errorf("MatchLabels(%v) = %v, want %v", c.required, got, c.want)
+		}
+	}
+
+	// 没有标签的 worker 只执行不要求标签的任务
+	w = &TestWorker{}
+	if !w.MatchLabels(nil) || w.MatchLabels(map[string]string{"arch": "arm64"}) {
+		t.Error("worker without labels should only match tasks without required labels")
+	}
+}
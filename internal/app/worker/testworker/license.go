@@ -0,0 +1,282 @@
+package testworker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+const (
+	// LicenseUnknown 没有找到许可证文件或无法识别时的许可证
+	LicenseUnknown = "NOASSERTION"
+
+	// SBOMArtifactName 许可证检查产出的 SBOM 制品名
+	SBOMArtifactName = "sbom.json"
+
+	// licenseHeadSize 按标题识别许可证时只看开头部分，GPL 的正文中会提到其他 GPL 系列许可证
+	licenseHeadSize = 512
+)
+
+type (
+	// GoModule go list -m -json 输出的模块信息
+	GoModule struct {
+		Path     string
+		Version  string
+		Main     bool
+		Indirect bool
+		Dir      string
+		Replace  *GoModule
+	}
+
+	// ModuleLicense 模块的许可证和策略检查结果
+	ModuleLicense struct {
+		Path      string
+		Version   string
+		License   string
+		Indirect  bool
+		Violation string // 违反策略的原因，为空时符合策略
+	}
+
+	// SBOM CycloneDX 格式的软件物料清单，只包含依赖模块和许可证
+	SBOM struct {
+		BOMFormat   string          `json:"bomFormat"`
+		SpecVersion string          `json:"specVersion"`
+		Version     int             `json:"version"`
+		Metadata    SBOMMetadata    `json:"metadata"`
+		Components  []SBOMComponent `json:"components"`
+	}
+
+	SBOMMetadata struct {
+		Timestamp string        `json:"timestamp"`
+		Component SBOMComponent `json:"component"`
+	}
+
+	SBOMComponent struct {
+		Type       string         `json:"type"`
+		Name       string         `json:"name"`
+		Version    string         `json:"version,omitempty"`
+		Purl       string         `json:"purl,omitempty"`
+		Scope      string         `json:"scope,omitempty"`
+		Licenses   []SBOMLicense  `json:"licenses,omitempty"`
+		Properties []SBOMProperty `json:"properties,omitempty"`
+	}
+
+	SBOMLicense struct {
+		License SBOMLicenseID `json:"license"`
+	}
+
+	SBOMLicenseID struct {
+		ID string `json:"id"`
+	}
+
+	SBOMProperty struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	licenseRule struct {
+		id string
+		// head 只在开头部分匹配
+		head    bool
+		phrases []string
+	}
+)
+
+// licenseRules 按顺序匹配，更具体的许可证在前，如 LGPL 在 GPL 前、BSD-3-Clause 在 BSD-2-Clause 前
+var licenseRules = []licenseRule{
+	{id: "AGPL-3.0", head: true, phrases: []string{"gnu affero general public license", "version 3"}},
+	{id: "LGPL-3.0", head: true, phrases: []string{"gnu lesser general public license", "version 3"}},
+	{id: "LGPL-2.1", head: true, phrases: []string{"gnu lesser general public license", "version 2.1"}},
+	{id: "GPL-3.0", head: true, phrases: []string{"gnu general public license", "version 3"}},
+	{id: "GPL-2.0", head: true, phrases: []string{"gnu general public license", "version 2"}},
+	{id: "MPL-2.0", phrases: []string{"mozilla public license", "2.0"}},
+	{id: "Apache-2.0", phrases: []string{"apache license", "version 2.0"}},
+	{id: "BSD-3-Clause", phrases: []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{id: "BSD-2-Clause", phrases: []string{"redistribution and use in source and binary forms"}},
+	{id: "MIT", phrases: []string{"permission is hereby granted, free of charge"}},
+	{id: "ISC", phrases: []string{"permission to use, copy, modify, and", "distribute this software for any purpose with or without fee"}},
+	{id: "Unlicense", phrases: []string{"this is free and unencumbered software released into the public domain"}},
+	{id: "CC0-1.0", phrases: []string{"cc0 1.0 universal"}},
+}
+
+var (
+	licenseFilePattern = regexp.MustCompile(`(?i)^(licen[cs]e|copying)([.\-_].*)?$`)
+	spacePattern       = regexp.MustCompile(`\s+`)
+)
+
+// ParseGoListModules 解析 go list -m -json all 的输出
+func ParseGoListModules(r io.Reader) (modules []GoModule, err error) {
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var m GoModule
+		err = decoder.Decode(&m)
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, m)
+	}
+	return
+}
+
+// ClassifyLicense 根据许可证正文识别 SPDX ID，无法识别时返回 LicenseUnknown
+func ClassifyLicense(text string) string {
+	text = spacePattern.ReplaceAllString(strings.ToLower(text), " ")
+	head := text
+	if len(head) > licenseHeadSize {
+		head = head[:licenseHeadSize]
+	}
+
+	for _, rule := range licenseRules {
+		content := text
+		if rule.head {
+			content = head
+		}
+
+		matched := true
+		for _, phrase := range rule.phrases {
+			if !strings.Contains(content, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule.id
+		}
+	}
+	return LicenseUnknown
+}
+
+// DetectLicense 识别模块目录下的许可证文件，有多个文件时使用第一个能识别的
+func DetectLicense(dir string) string {
+	if dir == "" {
+		return LicenseUnknown
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return LicenseUnknown
+	}
+	for _, file := range files {
+		if file.IsDir() || !licenseFilePattern.MatchString(file.Name()) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			continue
+		}
+		if license := ClassifyLicense(string(content)); license != LicenseUnknown {
+			return license
+		}
+	}
+	return LicenseUnknown
+}
+
+// CheckLicensePolicy 检查许可证是否符合策略，返回违反的原因。
+// 禁止列表优先，允许列表不为空时不在列表中的许可证（包括无法识别的）都违反策略
+func CheckLicensePolicy(license string, policy view.SettingLicensePolicy) string {
+	for _, id := range policy.Deny {
+		if strings.EqualFold(id, license) {
+			return fmt.Sprintf("license %s is denied", license)
+		}
+	}
+
+	if len(policy.Allow) == 0 {
+		return ""
+	}
+	for _, id := range policy.Allow {
+		if strings.EqualFold(id, license) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("license %s is not allowed", license)
+}
+
+// ScanLicenses 识别依赖模块的许可证并检查策略，不包含主模块。模块被替换时使用替换后的目录和版本
+func ScanLicenses(modules []GoModule, policy view.SettingLicensePolicy) (licenses []ModuleLicense) {
+	for _, m := range modules {
+		if m.Main {
+			continue
+		}
+
+		dir, version := m.Dir, m.Version
+		if m.Replace != nil {
+			dir = m.Replace.Dir
+			if m.Replace.Version != "" {
+				version = m.Replace.Version
+			}
+		}
+
+		license := DetectLicense(dir)
+		licenses = append(licenses, ModuleLicense{
+			Path:      m.Path,
+			Version:   version,
+			License:   license,
+			Indirect:  m.Indirect,
+			Violation: CheckLicensePolicy(license, policy),
+		})
+	}
+
+	sort.Slice(licenses, func(i, j int) bool {
+		return licenses[i].Path < licenses[j].Path
+	})
+	return
+}
+
+// BuildSBOM 生成 CycloneDX 格式的 SBOM，违反策略的原因记录在组件的 juno:license:violation 属性中
+func BuildSBOM(app, branch string, licenses []ModuleLicense, now time.Time) SBOM {
+	sbom := SBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Metadata: SBOMMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Component: SBOMComponent{
+				Type:    "application",
+				Name:    app,
+				Version: branch,
+			},
+		},
+		Components: make([]SBOMComponent, 0, len(licenses)),
+	}
+
+	for _, l := range licenses {
+		component := SBOMComponent{
+			Type:    "library",
+			Name:    l.Path,
+			Version: l.Version,
+			Purl:    "pkg:golang/" + l.Path,
+			Scope:   "required",
+		}
+		if l.Version != "" {
+			// 替换为本地目录的模块没有版本
+			component.Purl += "@" + l.Version
+		}
+		if l.License != LicenseUnknown {
+			component.Licenses = []SBOMLicense{{License: SBOMLicenseID{ID: l.License}}}
+		}
+		if l.Indirect {
+			component.Properties = append(component.Properties, SBOMProperty{Name: "juno:indirect", Value: "true"})
+		}
+		if l.Violation != "" {
+			component.Properties = append(component.Properties, SBOMProperty{Name: "juno:license:violation", Value: l.Violation})
+		}
+		sbom.Components = append(sbom.Components, component)
+	}
+	return sbom
+}
+
+// formatModuleLicense 许可证检查日志中的一行
+func formatModuleLicense(l ModuleLicense) string {
+	if l.Violation != "" {
+		return fmt.Sprintf("[VIOLATION] %s@%s %s: %s\n", l.Path, l.Version, l.License, l.Violation)
+	}
+	return fmt.Sprintf("%s@%s %s\n", l.Path, l.Version, l.License)
+}
@@ -0,0 +1,129 @@
+package testworker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func TestClassifyLicense(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"MIT License\n\nCopyright (c) 2020\n\nPermission is hereby granted, free of charge, to any person", "MIT"},
+		{"Apache License\n                           Version 2.0, January 2004", "Apache-2.0"},
+		{"Redistribution and use in source and binary forms... Neither the name of Google Inc.", "BSD-3-Clause"},
+		{"Redistribution and use in source and binary forms, with or without modification", "BSD-2-Clause"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n... version 3 of the GNU General Public License", "LGPL-3.0"},
+		{"GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991", "GPL-2.0"},
+		{"GNU AFFERO GENERAL PUBLIC LICENSE\nVersion 3, 19 November 2007", "AGPL-3.0"},
+		{"Mozilla Public License Version 2.0", "MPL-2.0"},
+		{"All rights reserved.", LicenseUnknown},
+	}
+	for _, c := range cases {
+		if got := ClassifyLicense(c.text); got != c.want {
+			t.Errorf("ClassifyLicense(%q) = %s, want %s", c.text, got, c.want)
+		}
+	}
+
+	// GPL 正文中提到 AGPL 不影响识别
+	gpl3 := "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n" + strings.Repeat("terms ", 200) +
+		"13. Use with the GNU Affero General Public License. version 3"
+	if got := ClassifyLicense(gpl3); got != "GPL-3.0" {
+		t.Errorf("ClassifyLicense(gpl3) = %s, want GPL-3.0", got)
+	}
+}
+
+func TestCheckLicensePolicy(t *testing.T) {
+	policy := view.SettingLicensePolicy{
+		Allow: []string{"MIT", "apache-2.0"},
+		Deny:  []string{"GPL-3.0"},
+	}
+	cases := map[string]bool{
+		"MIT":          false,
+		"Apache-2.0":   false,
+		"GPL-3.0":      true,
+		"BSD-3-Clause": true,
+		LicenseUnknown: true,
+	}
+	for license, violated := range cases {
+		if got := CheckLicensePolicy(license, policy) != ""; got != violated {
+			t.Errorf("CheckLicensePolicy(%s) violated = %v, want %v", license, got, violated)
+		}
+	}
+
+	// 没有允许列表时只检查禁止列表
+	policy.Allow = nil
+	if reason := CheckLicensePolicy(LicenseUnknown, policy); reason != "" {
+		t.Errorf("unexpected violation %s", reason)
+	}
+}
+
+func TestScanLicenses(t *testing.T) {
+	root, err := ioutil.TempDir("", "juno-license")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	write := func(dir, file, content string) string {
+		dir = filepath.Join(root, dir)
+		_ = os.MkdirAll(dir, 0755)
+		if file != "" {
+			_ = ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644)
+		}
+		return dir
+	}
+	mitDir := write("mit", "LICENSE", "Permission is hereby granted, free of charge")
+	gplDir := write("gpl", "COPYING.txt", "GNU GENERAL PUBLIC LICENSE Version 3")
+	noneDir := write("none", "", "")
+	localDir := write("local", "LICENSE.md", "Apache License Version 2.0")
+
+	out := `{"Path": "example.com/app", "Main": true, "Dir": "` + root + `"}
+{"Path": "example.com/mit", "Version": "v1.0.0", "Dir": "` + mitDir + `"}
+{"Path": "example.com/gpl", "Version": "v2.1.0", "Indirect": true, "Dir": "` + gplDir + `"}
+{"Path": "example.com/none", "Version": "v0.1.0", "Dir": "` + noneDir + `"}
+{"Path": "example.com/fork", "Version": "v1.2.0", "Replace": {"Path": "../local", "Dir": "` + localDir + `"}}
+`
+	modules, err := ParseGoListModules(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	licenses := ScanLicenses(modules, view.SettingLicensePolicy{Deny: []string{"GPL-3.0"}})
+	got := make([]string, 0, len(licenses))
+	for _, l := range licenses {
+		got = append(got, l.Path+" "+l.License+" "+strings.TrimSpace(l.Violation))
+	}
+	want := []string{
+		"example.com/fork Apache-2.0 ",
+		"example.com/gpl GPL-3.0 license GPL-3.0 is denied",
+		"example.com/mit MIT ",
+		"example.com/none NOASSERTION ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected licenses:\n%s", strings.Join(got, "\n"))
+	}
+
+	sbom := BuildSBOM("app", "master", licenses, time.Unix(0, 0))
+	content, _ := json.Marshal(sbom)
+	for _, s := range []string{
+		`"bomFormat":"CycloneDX"`,
+		`"purl":"pkg:golang/example.com/mit@v1.0.0"`,
+		`{"name":"juno:license:violation","value":"license GPL-3.0 is denied"}`,
+	} {
+		if !strings.Contains(string(content), s) {
+			t.Errorf("sbom missing %s", s)
+		}
+	}
+	if len(sbom.Components[3].Licenses) != 0 {
+		t.Errorf("unknown license should not be listed: %+v", sbom.Components[3])
+	}
+}
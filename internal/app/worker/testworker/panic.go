@@ -0,0 +1,79 @@
+package testworker
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+type (
+	// PanicError 执行任务时发生的 panic，Stack 为 panic 时的调用栈
+	PanicError struct {
+		Value interface{}
+		Stack []byte
+	}
+)
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// newPanicError 在 recover 之后调用，记录 panic 时的调用栈
+func newPanicError(task view.TestTask, value interface{}) *PanicError {
+	perr := &PanicError{Value: value, Stack: debug.Stack()}
+	xlog.Error("TestWorker: recovered from panic",
+		xlog.Int("taskId", int(task.TaskID)),
+		xlog.Int("part", task.Part),
+		xlog.String("panic", fmt.Sprint(value)),
+		xlog.ByteString("stack", perr.Stack))
+	return perr
+}
+
+// safeHandleTask 执行任务，任何位置的 panic 都转换为 *PanicError 返回，worker goroutine 不会因此退出
+func (t *TestWorker) safeHandleTask(task view.TestTask) (perr *PanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			perr = newPanicError(task, r)
+		}
+	}()
+	return t.handleTask(task)
+}
+
+// callJob 执行 job，job 中的 panic 转换为 *PanicError，该阶段标记为失败
+func (t *TestWorker) callJob(handler JobHandler, task view.TestTask, name string, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			perr := newPanicError(task, r)
+			t.notifyStepStatus(task, name, db.TestStepStatusFailed, fmt.Sprintf("%s\n%s", perr.Error(), perr.Stack))
+			t.notifyProgress(task, name, db.TestStepStatusFailed, progressFailed, perr.Error())
+			err = perr
+		}
+	}()
+	return handler(task, name, payload)
+}
+
+// onTaskPanic 任务执行中 panic 时重新入队一次，排除偶发的问题；已经重新入队过或者入队失败时标记任务失败
+func (t *TestWorker) onTaskPanic(task view.TestTask, perr *PanicError) {
+	if !task.Requeued {
+		task.Requeued = true
+		task.QueuedAt = time.Time{}
+		err := t.Push(task)
+		if err == nil {
+			status := db.TestTaskStatusPending
+			if task.Part > 0 {
+				// 拆分下发的任务其他部分可能仍在执行
+				status = db.TestTaskStatusRunning
+			}
+			t.notifyTaskUpdate(task, status, fmt.Sprintf("worker %s, task requeued\n", perr.Error()))
+			return
+		}
+		xlog.Error("TestWorker: requeue task failed", xlog.Int("taskId", int(task.TaskID)), xlog.String("err", err.Error()))
+	}
+
+	t.notifyTaskUpdate(task, db.TestTaskStatusFailed, fmt.Sprintf("task failed. err = %s\n%s", perr.Error(), perr.Stack))
+}
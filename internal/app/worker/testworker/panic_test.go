@@ -0,0 +1,110 @@
+package testworker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/taskqueue"
+	"github.com/go-resty/resty/v2"
+)
+
+type pushQueue struct {
+	taskqueue.Queue
+	bodies [][]byte
+}
+
+func (q *pushQueue) Push(ctx context.Context, body []byte) error {
+	q.bodies = append(q.bodies, body)
+	return nil
+}
+
+// newEventServer 记录 worker 上报的任务事件
+func newEventServer() (server *httptest.Server, events func() []view.TestTaskEvent) {
+	var mtx sync.Mutex
+	var received []view.TestTaskEvent
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event view.TestTaskEvent
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		mtx.Lock()
+		received = append(received, event)
+		mtx.Unlock()
+		_, _ = w.Write([]byte(`{"code":0,"msg":"success"}`))
+	}))
+	return server, func() []view.TestTaskEvent {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return append([]view.TestTaskEvent(nil), received...)
+	}
+}
+
+func TestCallJobRecoversPanic(t *testing.T) {
+	server, events := newEventServer()
+	defer server.Close()
+	w := &TestWorker{client: resty.New().SetHostURL(server.URL)}
+
+	err := w.callJob(func(task view.TestTask, name string, p json.RawMessage) error {
+		var m map[string]int
+		m[name]++
+		return nil
+	}, view.TestTask{TaskID: 1}, "unit_test", nil)
+
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expect *PanicError, got %v", err)
+	}
+	if !strings.Contains(perr.Error(), "nil map") || !strings.Contains(string(perr.Stack), "panic_test.go") {
+		t.Errorf("unexpected panic error %q, stack:\n%s", perr.Error(), perr.Stack)
+	}
+
+	var step view.TestTaskStepUpdatePayload
+	received := events()
+	if len(received) == 0 || json.Unmarshal(received[0].Data, &step) != nil {
+		t.Fatalf("step status not reported: %v", received)
+	}
+	if step.StepName != "unit_test" || step.Status != db.TestStepStatusFailed || !strings.Contains(step.LogsAppend, "goroutine") {
+		t.Errorf("unexpected step update %+v", step)
+	}
+}
+
+func TestOnTaskPanic(t *testing.T) {
+	server, events := newEventServer()
+	defer server.Close()
+	queue := &pushQueue{}
+	w := &TestWorker{client: resty.New().SetHostURL(server.URL), queue: queue}
+	perr := &PanicError{Value: "boom"}
+
+	lastStatus := func() db.TestTaskStatus {
+		received := events()
+		var update view.TestTaskUpdateEventPayload
+		_ = json.Unmarshal(received[len(received)-1].Data, &update)
+		return update.Status
+	}
+
+	w.onTaskPanic(view.TestTask{TaskID: 1}, perr)
+	if len(queue.bodies) != 1 {
+		t.Fatalf("task should be requeued once, pushed %d", len(queue.bodies))
+	}
+	var requeued view.TestTask
+	if err := json.Unmarshal(queue.bodies[0], &requeued); err != nil || !requeued.Requeued || requeued.QueuedAt.IsZero() {
+		t.Fatalf("unexpected requeued task %+v, err %v", requeued, err)
+	}
+	if status := lastStatus(); status != db.TestTaskStatusPending {
+		t.Errorf("status after requeue = %s, want pending", status)
+	}
+
+	w.onTaskPanic(requeued, perr)
+	if len(queue.bodies) != 1 {
+		t.Errorf("task should not be requeued twice, pushed %d", len(queue.bodies))
+	}
+	if status := lastStatus(); status != db.TestTaskStatusFailed {
+		t.Errorf("status after second panic = %s, want failed", status)
+	}
+}
@@ -2,42 +2,175 @@ package testworker
 
 import (
 	"bytes"
+	"fmt"
+	"sync"
+)
+
+const (
+	// DefaultMaxLogSize 单个阶段默认保留的日志大小
+	DefaultMaxLogSize = 4 << 20
+	// maxTailSize 日志超过大小限制后保留的结尾部分，失败原因通常在最后
+	maxTailSize = 64 * 1024
+)
+
+// ANSI 转义序列解析状态
+const (
+	ansiNone = iota
+	ansiEsc  // 读到 ESC
+	ansiCSI  // ESC [ 开头的控制序列，以 0x40-0x7E 结束
+	ansiOSC  // ESC ] 开头的系统命令，以 BEL 或 ESC \ 结束
+	ansiOSCEsc
 )
 
 type (
+	// Printer 分块输出日志，去掉颜色等 ANSI 转义序列。分块在换行处切分，单行超过 bufSize 时才会被拆开。
+	// 日志超过 maxSize 后不再输出，只保留结尾部分，在 Flush 时连同截断提示一起输出
 	Printer struct {
-		C       chan string
+		C chan string
+
+		mtx     sync.Mutex
 		buf     *bytes.Buffer
-		readBuf []byte
-		bufSize uint32
+		bufSize int
+		lines   bool // 有完整的行即输出，不等待攒够 bufSize
+		maxSize int
+		written int // 已经写入 buf 的大小，不包括截断后的部分
+		dropped int // 截断丢弃的大小
+		tail    []byte
+		ansi    int
 	}
 )
 
-func NewPrinter(bufSize uint32) *Printer {
+// NewPrinter 每 bufSize 字节输出一次，maxSize 为 0 时不限制日志大小
+func NewPrinter(bufSize uint32, maxSize int) *Printer {
 	return &Printer{
 		C:       make(chan string), // sync
 		buf:     bytes.NewBuffer([]byte{}),
-		bufSize: bufSize,
-		readBuf: make([]byte, bufSize),
+		bufSize: int(bufSize),
+		maxSize: maxSize,
+	}
+}
+
+// NewLinePrinter 按行输出，每次写入的完整行立即输出，用于实时推送日志。单行超过 maxLineSize 时拆分输出
+func NewLinePrinter(maxLineSize uint32, maxSize int) *Printer {
+	p := NewPrinter(maxLineSize, maxSize)
+	p.lines = true
+	return p
+}
+
+// Write 缓冲满时阻塞到 C 被读取
+func (p *Printer) Write(data []byte) (n int, err error) {
+	p.mtx.Lock()
+	p.write(p.stripANSI(data))
+	chunks := p.chunks()
+	p.mtx.Unlock()
+
+	// 不持有锁发送，读取方阻塞时不影响 Flush
+	for _, chunk := range chunks {
+		p.C <- chunk // sync
+	}
+
+	return len(data), nil
+}
+
+// Flush 返回剩余的日志，日志被截断时追加截断提示和结尾部分
+func (p *Printer) Flush() (data []byte) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	data = append(data, p.buf.Bytes()...)
+	p.buf.Reset()
+
+	if p.dropped > 0 {
+		// 结尾部分从完整的 UTF-8 字符开始
+		for len(p.tail) > 0 && p.tail[0]&0xc0 == 0x80 {
+			p.tail = p.tail[1:]
+		}
+		skipped := p.dropped - len(p.tail)
+		data = append(data, fmt.Sprintf("\n... output truncated, %d bytes skipped, showing the last %d bytes ...\n", skipped, len(p.tail))...)
+		data = append(data, p.tail...)
+		p.dropped = 0
+		p.tail = nil
 	}
+	return
 }
 
-func (p Printer) Write(data []byte) (n int, err error) {
-	n, err = p.buf.Write(data)
-	if err != nil {
+func (p *Printer) write(data []byte) {
+	if p.maxSize <= 0 || p.written+len(data) <= p.maxSize {
+		p.buf.Write(data)
+		p.written += len(data)
 		return
 	}
 
-	for p.buf.Len() >= int(p.bufSize) {
-		n, _ = p.buf.Read(p.readBuf)
-		p.C <- string(p.readBuf[:n]) // sync
+	remain := p.maxSize - p.written
+	if remain > 0 {
+		p.buf.Write(data[:remain])
+		p.written += remain
+		data = data[remain:]
 	}
 
-	return len(data), nil
+	p.dropped += len(data)
+	p.tail = append(p.tail, data...)
+	if len(p.tail) > maxTailSize {
+		p.tail = append(p.tail[:0], p.tail[len(p.tail)-maxTailSize:]...)
+	}
+}
+
+// chunks 输出到缓冲中最后一个换行为止，没有换行且超过 bufSize 时输出 bufSize 大小
+func (p *Printer) chunks() (chunks []string) {
+	for {
+		data := p.buf.Bytes()
+		if len(data) == 0 || (!p.lines && len(data) < p.bufSize) {
+			return
+		}
+		n := bytes.LastIndexByte(data, '\n') + 1
+		if n == 0 {
+			if len(data) < p.bufSize {
+				return
+			}
+			n = p.bufSize
+		}
+		chunks = append(chunks, string(p.buf.Next(n)))
+	}
 }
 
-func (p Printer) Flush() (data []byte) {
-	buf := make([]byte, p.bufSize)
-	n, _ := p.buf.Read(buf)
-	return buf[:n]
+// stripANSI 去掉 ANSI 转义序列，序列被拆分到多次写入时同样可以去掉
+func (p *Printer) stripANSI(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for _, b := range data {
+		switch p.ansi {
+		case ansiNone:
+			if b == 0x1b {
+				p.ansi = ansiEsc
+				continue
+			}
+			out = append(out, b)
+		case ansiEsc:
+			switch b {
+			case '[':
+				p.ansi = ansiCSI
+			case ']':
+				p.ansi = ansiOSC
+			default:
+				// 两个字符的转义序列，如 ESC c
+				p.ansi = ansiNone
+			}
+		case ansiCSI:
+			if b >= 0x40 && b <= 0x7e {
+				p.ansi = ansiNone
+			}
+		case ansiOSC:
+			if b == 0x07 {
+				p.ansi = ansiNone
+			} else if b == 0x1b {
+				p.ansi = ansiOSCEsc
+			}
+		case ansiOSCEsc:
+			if b == '\\' {
+				p.ansi = ansiNone
+			} else {
+				p.ansi = ansiOSC
+			}
+		}
+	}
+	return out
 }
@@ -0,0 +1,119 @@
+package testworker
+
+import (
+	"strings"
+	"testing"
+)
+
+// drain 读取 printer 输出的分块，写入完成后返回全部日志
+func drain(p *Printer, write func()) string {
+	var out strings.Builder
+	done := make(chan struct{})
+	go func() {
+		write()
+		close(done)
+	}()
+	for {
+		select {
+		case chunk := <-p.C:
+			out.WriteString(chunk)
+		case <-done:
+			out.Write(p.Flush())
+			return out.String()
+		}
+	}
+}
+
+func TestPrinterStripANSI(t *testing.T) {
+	p := NewPrinter(4, 0)
+	out := drain(p, func() {
+		_, _ = p.Write([]byte("\x1b[31mFAIL\x1b[0m ok\x1b"))
+		// 转义序列拆分到两次写入
+		_, _ = p.Write([]byte("[1;32mpass\x1b]0;title\x07 done\n"))
+	})
+	if out != "FAIL okpass done\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestPrinterTruncate(t *testing.T) {
+	p := NewPrinter(8, 16)
+	out := drain(p, func() {
+		_, _ = p.Write([]byte("0123456789"))
+		_, _ = p.Write([]byte("abcdefghij"))
+		_, _ = p.Write([]byte(strings.Repeat("x", maxTailSize) + "the end"))
+	})
+
+	if !strings.HasPrefix(out, "0123456789abcdef\n... output truncated, 11 bytes skipped") {
+		t.Errorf("unexpected head %q", out[:64])
+	}
+	if !strings.HasSuffix(out, "the end") {
+		t.Errorf("tail lost %q", out[len(out)-64:])
+	}
+	if len(out) > 16+maxTailSize+128 {
+		t.Errorf("output too large: %d", len(out))
+	}
+}
+
+func TestPrinterFlush(t *testing.T) {
+	p := NewPrinter(128, 0)
+	_, _ = p.Write([]byte("short"))
+	if out := string(p.Flush()); out != "short" {
+		t.Errorf("unexpected output %q", out)
+	}
+	if out := p.Flush(); len(out) != 0 {
+		t.Errorf("unexpected output after flush %q", out)
+	}
+}
+
+func TestLinePrinter(t *testing.T) {
+	p := NewLinePrinter(8, 0)
+	var chunks []string
+	done := make(chan struct{})
+	go func() {
+		for chunk := range p.C {
+			chunks = append(chunks, chunk)
+		}
+		close(done)
+	}()
+
+	_, _ = p.Write([]byte("ok\npar"))
+	_, _ = p.Write([]byte("tial\n"))
+	// 超过 maxLineSize 的行拆分输出
+	_, _ = p.Write([]byte("0123456789abcdef!"))
+	close(p.C)
+	<-done
+
+	want := []string{"ok\n", "partial\n", "01234567", "89abcdef"}
+	if strings.Join(chunks, "|") != strings.Join(want, "|") {
+		t.Errorf("chunks = %q, want %q", chunks, want)
+	}
+	if out := string(p.Flush()); out != "!" {
+		t.Errorf("flush = %q", out)
+	}
+}
+
+func TestPrinterSplitOnNewline(t *testing.T) {
+	p := NewPrinter(8, 0)
+	var chunks []string
+	done := make(chan struct{})
+	go func() {
+		for chunk := range p.C {
+			chunks = append(chunks, chunk)
+		}
+		close(done)
+	}()
+
+	_, _ = p.Write([]byte("ok\n"))
+	_, _ = p.Write([]byte("line\nmore"))
+	close(p.C)
+	<-done
+
+	// 攒够 8 字节后输出到最后一个换行，行不会被拆开
+	if len(chunks) != 1 || chunks[0] != "ok\nline\n" {
+		t.Errorf("chunks = %q", chunks)
+	}
+	if out := string(p.Flush()); out != "more" {
+		t.Errorf("flush = %q", out)
+	}
+}
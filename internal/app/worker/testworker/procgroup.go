@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package testworker
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 命令在独立的进程组中执行，终止时包括 go test 启动的测试进程
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killProcessGroup 终止 pid 所在的进程组
+func killProcessGroup(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
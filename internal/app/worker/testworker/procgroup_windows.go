@@ -0,0 +1,18 @@
+package testworker
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup windows 不支持进程组
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup windows 只终止 pid 对应的进程
+func killProcessGroup(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
@@ -0,0 +1,125 @@
+package testworker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/taskqueue"
+)
+
+const defaultQueueLimit = 100
+
+var ErrQueueNotManageable = fmt.Errorf("task queue does not support inspection")
+
+// Queue 等待中的任务，按出队顺序排列
+func (t *TestWorker) Queue(ctx context.Context, limit int) (queue view.WorkerQueue, err error) {
+	queue.Paused = t.Paused()
+	queue.Tasks = make([]view.QueuedTask, 0)
+
+	manager, ok := t.queue.(taskqueue.Manager)
+	if !ok {
+		return
+	}
+	queue.Manageable = true
+
+	if limit <= 0 {
+		limit = defaultQueueLimit
+	}
+	messages, total, err := manager.Pending(ctx, limit)
+	if err != nil {
+		return
+	}
+	queue.Total = total
+
+	now := time.Now()
+	for _, msg := range messages {
+		item := view.QueuedTask{ID: msg.ID, Attempts: msg.Attempts}
+
+		var task view.TestTask
+		if json.Unmarshal(msg.Body, &task) == nil {
+			item.TaskID = task.TaskID
+			item.Name = task.Name
+			item.AppName = task.AppName
+			item.Env = task.Env
+			item.Branch = task.Branch
+			item.Part = task.Part
+			item.Priority = task.Priority
+			item.QueuedAt = task.QueuedAt
+			if !task.QueuedAt.IsZero() {
+				item.Wait = now.Sub(task.QueuedAt).Seconds()
+			}
+		}
+		queue.Tasks = append(queue.Tasks, item)
+	}
+	return
+}
+
+// RemoveQueued 删除等待中的任务，任务在 Juno 中保持排队状态，需要重新执行
+func (t *TestWorker) RemoveQueued(ctx context.Context, id string) error {
+	manager, ok := t.queue.(taskqueue.Manager)
+	if !ok {
+		return ErrQueueNotManageable
+	}
+	return manager.Remove(ctx, id)
+}
+
+// MoveQueuedToFront 将等待中的任务移到队首
+func (t *TestWorker) MoveQueuedToFront(ctx context.Context, id string) error {
+	manager, ok := t.queue.(taskqueue.Manager)
+	if !ok {
+		return ErrQueueNotManageable
+	}
+	return manager.MoveToFront(ctx, id)
+}
+
+// Pause 暂停从队列取任务，正在执行的任务不受影响。
+// 阻塞中的 Pop 被取消，redis 队列在暂停前已经读到的消息仍会执行
+func (t *TestWorker) Pause() {
+	t.pauseMtx.Lock()
+	defer t.pauseMtx.Unlock()
+
+	if t.paused {
+		return
+	}
+	t.paused = true
+	t.resumed = make(chan struct{})
+	t.popCancel()
+}
+
+// Resume 恢复从队列取任务
+func (t *TestWorker) Resume() {
+	t.pauseMtx.Lock()
+	defer t.pauseMtx.Unlock()
+
+	if !t.paused {
+		return
+	}
+	t.paused = false
+	t.popCtx, t.popCancel = context.WithCancel(context.Background())
+	close(t.resumed)
+}
+
+func (t *TestWorker) Paused() bool {
+	t.pauseMtx.Lock()
+	defer t.pauseMtx.Unlock()
+	return t.paused
+}
+
+// waitResumed 暂停时阻塞到恢复，返回 Pop 使用的 ctx，暂停时被取消
+func (t *TestWorker) waitResumed() context.Context {
+	for {
+		t.pauseMtx.Lock()
+		if !t.paused {
+			ctx := t.popCtx
+			t.pauseMtx.Unlock()
+			return ctx
+		}
+		resumed := t.resumed
+		t.pauseMtx.Unlock()
+
+		<-resumed
+	}
+}
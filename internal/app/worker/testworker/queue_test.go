@@ -0,0 +1,90 @@
+package testworker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/taskqueue"
+)
+
+type fakeQueue struct {
+	taskqueue.Queue
+	messages []*taskqueue.Message
+}
+
+func (q *fakeQueue) Pending(ctx context.Context, limit int) ([]*taskqueue.Message, int, error) {
+	if limit > len(q.messages) {
+		limit = len(q.messages)
+	}
+	return q.messages[:limit], len(q.messages), nil
+}
+
+func (q *fakeQueue) Remove(ctx context.Context, id string) error {
+	return taskqueue.ErrNotFound
+}
+
+func (q *fakeQueue) MoveToFront(ctx context.Context, id string) error {
+	return nil
+}
+
+func newTestWorker(queue taskqueue.Queue) *TestWorker {
+	t := &TestWorker{queue: queue}
+	t.popCtx, t.popCancel = context.WithCancel(context.Background())
+	return t
+}
+
+func TestWorkerPause(t *testing.T) {
+	w := newTestWorker(&fakeQueue{})
+
+	ctx := w.waitResumed()
+	w.Pause()
+	w.Pause()
+	if ctx.Err() == nil || !w.Paused() {
+		t.Fatal("pause should cancel pop context")
+	}
+
+	resumed := make(chan context.Context)
+	go func() { resumed <- w.waitResumed() }()
+	select {
+	case <-resumed:
+		t.Fatal("waitResumed returned while paused")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	w.Resume()
+	select {
+	case ctx := <-resumed:
+		if ctx.Err() != nil {
+			t.Fatal("context after resume should not be canceled")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitResumed not returned after resume")
+	}
+}
+
+func TestWorkerQueue(t *testing.T) {
+	body, _ := json.Marshal(view.TestTask{TaskID: 1, AppName: "app", QueuedAt: time.Now().Add(-time.Minute)})
+	queue := &fakeQueue{messages: []*taskqueue.Message{
+		{ID: "1", Body: body},
+		{ID: "2", Body: []byte("invalid")},
+	}}
+	w := newTestWorker(queue)
+
+	result, err := w.Queue(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Manageable || result.Total != 2 || len(result.Tasks) != 1 {
+		t.Fatalf("unexpected queue %+v", result)
+	}
+	if task := result.Tasks[0]; task.TaskID != 1 || task.AppName != "app" || task.Wait < 60 {
+		t.Errorf("unexpected task %+v", task)
+	}
+
+	if err := w.RemoveQueued(context.Background(), "1"); err != taskqueue.ErrNotFound {
+		t.Errorf("remove err = %v, want %v", err, taskqueue.ErrNotFound)
+	}
+}
@@ -0,0 +1,463 @@
+package testworker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+const (
+	// maxTestOutput 单个测试保留的输出，超过时只保留开头部分
+	maxTestOutput = 64 * 1024
+	// maxOtherLines 编译错误等非 test2json 输出保留的行数
+	maxOtherLines = 200
+	// maxRaces 保留的数据竞争报告数，同一测试中的竞争通常重复出现
+	maxRaces = 20
+
+	raceBegin = "WARNING: DATA RACE"
+	raceEnd   = "=================="
+
+	// ReportArtifactName 测试报告制品名
+	ReportArtifactName = "report.html"
+	// CoverProfileArtifactName 覆盖率文件制品名
+	CoverProfileArtifactName = "coverage.out"
+	// CoverReportArtifactName HTML 覆盖率报告制品名
+	CoverReportArtifactName = "coverage.html"
+)
+
+type (
+	// TestCollector 解析 go test -json 的输出，汇总测试结果，用于生成测试报告
+	TestCollector struct {
+		mtx     sync.Mutex
+		partial []byte
+
+		packages map[string]*ReportPackage
+		outputs  map[string]*bytes.Buffer
+		failures []ReportFailure
+		other    []string
+		races    []ReportRace
+		raceBufs map[string]*strings.Builder // 读取中的数据竞争报告
+	}
+
+	// testEvent go test -json 输出的事件，见 go doc test2json
+	testEvent struct {
+		Action  string
+		Package string
+		Test    string
+		Elapsed float64
+		Output  string
+	}
+
+	// TestReport 测试报告
+	TestReport struct {
+		Title       string
+		GeneratedAt time.Time
+		Passed      int
+		Failed      int
+		Skipped     int
+		Packages    []ReportPackage
+		Failures    []ReportFailure
+		Races       []ReportRace
+		Other       []string
+		Coverage    *CoverageProfile
+	}
+
+	ReportPackage struct {
+		Name    string
+		Status  string // pass, fail, skip
+		Passed  int
+		Failed  int
+		Skipped int
+		Elapsed float64
+	}
+
+	ReportFailure struct {
+		Package string
+		Test    string // 为空时为整个包失败，如编译失败
+		Output  string
+	}
+
+	// ReportRace -race 检测到的数据竞争
+	ReportRace struct {
+		Package string
+		Test    string // 为空时竞争发生在测试之外，如 TestMain 或 init
+		Report  string // WARNING: DATA RACE 之后的访问位置和调用栈
+	}
+
+	// CoverageProfile go test -coverprofile 的覆盖率统计
+	CoverageProfile struct {
+		Statements int
+		Covered    int
+		Files      []FileCoverage
+	}
+
+	FileCoverage struct {
+		File       string
+		Package    string
+		Statements int
+		Covered    int
+	}
+)
+
+// NewTestCollector ..
+func NewTestCollector() *TestCollector {
+	return &TestCollector{
+		packages: make(map[string]*ReportPackage),
+		outputs:  make(map[string]*bytes.Buffer),
+		raceBufs: make(map[string]*strings.Builder),
+	}
+}
+
+// Write 按行解析输出，不完整的行留到下次写入
+func (c *TestCollector) Write(data []byte) (n int, err error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.partial = append(c.partial, data...)
+	for {
+		i := bytes.IndexByte(c.partial, '\n')
+		if i < 0 {
+			break
+		}
+		c.handleLine(c.partial[:i])
+		c.partial = c.partial[i+1:]
+	}
+	return len(data), nil
+}
+
+func (c *TestCollector) handleLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	var e testEvent
+	if line[0] != '{' || json.Unmarshal(line, &e) != nil || e.Action == "" {
+		if len(c.other) < maxOtherLines {
+			c.other = append(c.other, string(line))
+		}
+		return
+	}
+
+	pkg, ok := c.packages[e.Package]
+	if !ok {
+		pkg = &ReportPackage{Name: e.Package}
+		c.packages[e.Package] = pkg
+	}
+
+	key := e.Package + " " + e.Test
+	switch e.Action {
+	case "output":
+		buf, ok := c.outputs[key]
+		if !ok {
+			buf = &bytes.Buffer{}
+			c.outputs[key] = buf
+		}
+		if buf.Len() < maxTestOutput {
+			buf.WriteString(e.Output)
+		}
+		c.handleRaceOutput(key, e)
+	case "pass", "fail", "skip":
+		if e.Test == "" {
+			pkg.Status = e.Action
+			pkg.Elapsed = e.Elapsed
+		} else {
+			switch e.Action {
+			case "pass":
+				pkg.Passed++
+			case "fail":
+				pkg.Failed++
+			case "skip":
+				pkg.Skipped++
+			}
+		}
+
+		// 子测试失败时父测试也会失败，只记录包失败中不包含测试失败的情况，如编译失败
+		if e.Action == "fail" && (e.Test != "" || pkg.Failed == 0) {
+			failure := ReportFailure{Package: e.Package, Test: e.Test}
+			if buf, ok := c.outputs[key]; ok {
+				failure.Output = buf.String()
+			}
+			c.failures = append(c.failures, failure)
+		}
+		delete(c.outputs, key)
+	}
+}
+
+// handleRaceOutput 收集 ================== 包围的数据竞争报告
+func (c *TestCollector) handleRaceOutput(key string, e testEvent) {
+	line := strings.TrimRight(e.Output, "\r\n")
+	race, ok := c.raceBufs[key]
+	switch {
+	case line == raceBegin:
+		c.raceBufs[key] = &strings.Builder{}
+	case !ok:
+	case line == raceEnd:
+		delete(c.raceBufs, key)
+		if len(c.races) < maxRaces {
+			c.races = append(c.races, ReportRace{Package: e.Package, Test: e.Test, Report: race.String()})
+		}
+	case race.Len() < maxTestOutput:
+		race.WriteString(line + "\n")
+	}
+}
+
+// Races 检测到的数据竞争，最多保留 maxRaces 个
+func (c *TestCollector) Races() []ReportRace {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return append([]ReportRace(nil), c.races...)
+}
+
+// raceFailure 检测到数据竞争时阶段失败的原因，列出发生竞争的测试
+func raceFailure(races []ReportRace) error {
+	tests := make([]string, 0, len(races))
+	seen := make(map[string]bool)
+	for _, race := range races {
+		name := race.Package
+		if race.Test != "" {
+			name += "." + race.Test
+		}
+		if !seen[name] {
+			seen[name] = true
+			tests = append(tests, name)
+		}
+	}
+	return fmt.Errorf("%d data races detected in %s", len(races), strings.Join(tests, ", "))
+}
+
+// Report 汇总的测试报告，coverage 为空时不展示覆盖率
+func (c *TestCollector) Report(title string, coverage *CoverageProfile) TestReport {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if len(c.partial) > 0 {
+		c.handleLine(c.partial)
+		c.partial = nil
+	}
+
+	report := TestReport{
+		Title:       title,
+		GeneratedAt: time.Now(),
+		Failures:    append([]ReportFailure(nil), c.failures...),
+		Races:       append([]ReportRace(nil), c.races...),
+		Other:       append([]string(nil), c.other...),
+		Coverage:    coverage,
+	}
+	for _, pkg := range c.packages {
+		if pkg.Status == "" {
+			// 没有结束事件的包，如进程被终止
+			pkg.Status = "fail"
+		}
+		report.Passed += pkg.Passed
+		report.Failed += pkg.Failed
+		report.Skipped += pkg.Skipped
+		report.Packages = append(report.Packages, *pkg)
+	}
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].Name < report.Packages[j].Name
+	})
+	return report
+}
+
+// ParseCoverProfile 解析 go test -coverprofile 输出的文件，同一代码块出现多次时任意一次执行即视为覆盖
+func ParseCoverProfile(r io.Reader) (*CoverageProfile, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+
+	files := make(map[string]map[string]*block)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		// file:startLine.startCol,endLine.endCol numStmts count
+		i := strings.LastIndex(line, ":")
+		fields := strings.Fields(line[i+1:])
+		if i < 0 || len(fields) != 3 {
+			return nil, fmt.Errorf("invalid cover profile line: %s", line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cover profile line: %s", line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cover profile line: %s", line)
+		}
+
+		file := line[:i]
+		if files[file] == nil {
+			files[file] = make(map[string]*block)
+		}
+		b, ok := files[file][fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			files[file][fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	profile := &CoverageProfile{}
+	for file, blocks := range files {
+		fc := FileCoverage{File: file, Package: path.Dir(file)}
+		for _, b := range blocks {
+			fc.Statements += b.statements
+			if b.covered {
+				fc.Covered += b.statements
+			}
+		}
+		profile.Statements += fc.Statements
+		profile.Covered += fc.Covered
+		profile.Files = append(profile.Files, fc)
+	}
+	sort.Slice(profile.Files, func(i, j int) bool {
+		return profile.Files[i].File < profile.Files[j].File
+	})
+	return profile, nil
+}
+
+// Percent 覆盖率百分比
+func (p CoverageProfile) Percent() float64 {
+	return percent(p.Covered, p.Statements)
+}
+
+// Packages 按包汇总的覆盖率，按包名排序
+func (p CoverageProfile) Packages() db.CoveragePackages {
+	index := make(map[string]int)
+	packages := make(db.CoveragePackages, 0)
+	for _, f := range p.Files {
+		i, ok := index[f.Package]
+		if !ok {
+			i = len(packages)
+			index[f.Package] = i
+			packages = append(packages, db.CoveragePackage{Package: f.Package})
+		}
+		packages[i].Statements += f.Statements
+		packages[i].Covered += f.Covered
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Package < packages[j].Package
+	})
+	return packages
+}
+
+// Percent 覆盖率百分比
+func (f FileCoverage) Percent() float64 {
+	return percent(f.Covered, f.Statements)
+}
+
+func percent(covered, statements int) float64 {
+	if statements == 0 {
+		return 0
+	}
+	return float64(covered) * 100 / float64(statements)
+}
+
+// RenderReport 渲染为不依赖外部资源的 HTML
+func RenderReport(report TestReport) ([]byte, error) {
+	var buf bytes.Buffer
+	err := reportTemplate.Execute(&buf, report)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	// heatColor 覆盖率 0% 为红色，100% 为绿色
+	"heatColor": func(p float64) template.CSS {
+		return template.CSS(fmt.Sprintf("hsl(%d, 70%%, 45%%)", int(p*1.2)))
+	},
+	"percent": func(p float64) string {
+		return fmt.Sprintf("%.1f%%", p)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 20px; color: #333; }
+table { border-collapse: collapse; margin-bottom: 20px; }
+th, td { border: 1px solid #ddd; padding: 4px 10px; text-align: left; font-size: 13px; }
+.summary span { display: inline-block; margin-right: 20px; font-size: 16px; }
+.pass { color: #389e0d; } .fail { color: #cf1322; } .skip { color: #8c8c8c; }
+pre { background: #f6f6f6; padding: 10px; white-space: pre-wrap; font-size: 12px; max-height: 400px; overflow: auto; }
+.heatmap { display: flex; flex-wrap: wrap; gap: 3px; margin-bottom: 20px; }
+.cell { width: 18px; height: 18px; border-radius: 2px; }
+</style>
+</head>
+<body>
+<h2>{{.Title}}</h2>
+<p>生成时间：{{.GeneratedAt.Format "2006-01-02 15:04:05"}}</p>
+<div class="summary">
+  <span class="pass">通过 {{.Passed}}</span>
+  <span class="fail">失败 {{.Failed}}</span>
+  <span class="skip">跳过 {{.Skipped}}</span>
+  {{with .Coverage}}<span>覆盖率 {{percent .Percent}}</span>{{end}}
+</div>
+
+<h3>测试包</h3>
+<table>
+  <tr><th>包</th><th>结果</th><th>通过</th><th>失败</th><th>跳过</th><th>耗时(s)</th></tr>
+  {{range .Packages}}
+  <tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.Passed}}</td><td>{{.Failed}}</td><td>{{.Skipped}}</td><td>{{printf "%.2f" .Elapsed}}</td></tr>
+  {{end}}
+</table>
+
+{{if .Failures}}
+<h3>失败的测试</h3>
+{{range .Failures}}
+<h4 class="fail">{{.Package}}{{if .Test}} / {{.Test}}{{end}}</h4>
+<pre>{{.Output}}</pre>
+{{end}}
+{{end}}
+
+{{if .Races}}
+<h3>数据竞争</h3>
+{{range .Races}}
+<h4 class="fail">{{.Package}}{{if .Test}} / {{.Test}}{{end}}</h4>
+<pre>{{.Report}}</pre>
+{{end}}
+{{end}}
+
+{{if .Other}}
+<h3>其他输出</h3>
+<pre>{{range .Other}}{{.}}
+{{end}}</pre>
+{{end}}
+
+{{with .Coverage}}
+<h3>覆盖率热力图</h3>
+<div class="heatmap">
+  {{range .Files}}<div class="cell" style="background: {{heatColor .Percent}}" title="{{.File}} {{percent .Percent}}"></div>{{end}}
+</div>
+<table>
+  <tr><th>文件</th><th>语句</th><th>覆盖</th><th>覆盖率</th></tr>
+  {{range .Files}}
+  <tr><td>{{.File}}</td><td>{{.Statements}}</td><td>{{.Covered}}</td><td style="color: {{heatColor .Percent}}">{{percent .Percent}}</td></tr>
+  {{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
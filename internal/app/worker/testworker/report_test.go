@@ -0,0 +1,130 @@
+package testworker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTestCollector(t *testing.T) {
+	collector := NewTestCollector()
+	output := `{"Action":"run","Package":"a/b","Test":"TestOK"}
+{"Action":"output","Package":"a/b","Test":"TestOK","Output":"=== RUN   TestOK\n"}
+{"Action":"pass","Package":"a/b","Test":"TestOK","Elapsed":0.01}
+{"Action":"run","Package":"a/b","Test":"TestBad"}
+{"Action":"output","Package":"a/b","Test":"TestBad","Output":"    bad_test.go:10: want 1, got <2>\n"}
+{"Action":"fail","Package":"a/b","Test":"TestBad","Elapsed":0.02}
+{"Action":"fail","Package":"a/b","Elapsed":0.5}
+# a/c
+c.go:3:2: undefined: foo
+{"Action":"output","Package":"a/c","Output":"FAIL\ta/c [build failed]\n"}
+{"Action":"fail","Package":"a/c","Elapsed":0}
+{"Action":"skip","Package":"a/d","Test":"TestSkip"}
+{"Action":"pass","Pack`
+	// 分两次写入，第二次补全被截断的行
+	_, _ = collector.Write([]byte(output))
+	_, _ = collector.Write([]byte(`age":"a/d","Elapsed":0.1}` + "\n"))
+
+	report := collector.Report("report", nil)
+	if report.Passed != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Fatalf("unexpected summary %d/%d/%d", report.Passed, report.Failed, report.Skipped)
+	}
+	if len(report.Packages) != 3 || report.Packages[0].Status != "fail" || report.Packages[2].Status != "pass" {
+		t.Fatalf("unexpected packages %+v", report.Packages)
+	}
+	if len(report.Failures) != 2 {
+		t.Fatalf("expect 2 failures, got %+v", report.Failures)
+	}
+	if report.Failures[0].Test != "TestBad" || !strings.Contains(report.Failures[0].Output, "want 1") {
+		t.Errorf("unexpected failure %+v", report.Failures[0])
+	}
+	if report.Failures[1].Package != "a/c" || report.Failures[1].Test != "" {
+		t.Errorf("unexpected failure %+v", report.Failures[1])
+	}
+	if len(report.Other) != 2 {
+		t.Errorf("unexpected other output %v", report.Other)
+	}
+}
+
+func TestTestCollectorRaces(t *testing.T) {
+	collector := NewTestCollector()
+	_, _ = collector.Write([]byte(`{"Action":"run","Package":"a/b","Test":"TestRace"}
+{"Action":"output","Package":"a/b","Test":"TestRace","Output":"==================\n"}
+{"Action":"output","Package":"a/b","Test":"TestRace","Output":"WARNING: DATA RACE\n"}
+{"Action":"output","Package":"a/b","Test":"TestRace","Output":"Write at 0x00c000012345 by goroutine 8:\n"}
+{"Action":"output","Package":"a/b","Test":"TestRace","Output":"  a/b.TestRace.func1()\n"}
+{"Action":"output","Package":"a/b","Test":"TestRace","Output":"==================\n"}
+{"Action":"output","Package":"a/b","Test":"TestRace","Output":"    testing.go:1152: race detected during execution of test\n"}
+{"Action":"fail","Package":"a/b","Test":"TestRace","Elapsed":0.02}
+{"Action":"output","Package":"a/b","Output":"==================\n"}
+{"Action":"output","Package":"a/b","Output":"WARNING: DATA RACE\n"}
+{"Action":"output","Package":"a/b","Output":"Read at 0x00c000012345 by main goroutine:\n"}
+{"Action":"output","Package":"a/b","Output":"==================\n"}
+{"Action":"fail","Package":"a/b","Elapsed":0.5}
+`))
+
+	races := collector.Races()
+	if len(races) != 2 {
+		t.Fatalf("expect 2 races, got %+v", races)
+	}
+	if races[0].Test != "TestRace" || races[0].Report != "Write at 0x00c000012345 by goroutine 8:\n  a/b.TestRace.func1()\n" {
+		t.Errorf("unexpected race %+v", races[0])
+	}
+	if races[1].Test != "" || !strings.HasPrefix(races[1].Report, "Read at") {
+		t.Errorf("unexpected race %+v", races[1])
+	}
+	if err := raceFailure(races); err.Error() != "2 data races detected in a/b.TestRace, a/b" {
+		t.Errorf("raceFailure() = %v", err)
+	}
+	if report := collector.Report("report", nil); len(report.Races) != 2 {
+		t.Errorf("report races = %+v", report.Races)
+	}
+}
+
+func TestParseCoverProfile(t *testing.T) {
+	profile := `mode: set
+a/b/x.go:1.1,3.2 2 1
+a/b/x.go:5.1,7.2 3 0
+a/b/y.go:1.1,2.2 5 0
+a/b/y.go:1.1,2.2 5 1
+`
+	coverage, err := ParseCoverProfile(strings.NewReader(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coverage.Statements != 10 || coverage.Covered != 7 {
+		t.Fatalf("unexpected coverage %d/%d", coverage.Covered, coverage.Statements)
+	}
+	if len(coverage.Files) != 2 || coverage.Files[0].File != "a/b/x.go" || coverage.Files[0].Package != "a/b" {
+		t.Fatalf("unexpected files %+v", coverage.Files)
+	}
+	if packages := coverage.Packages(); len(packages) != 1 || packages[0].Package != "a/b" || packages[0].Covered != 7 || packages[0].Statements != 10 {
+		t.Errorf("unexpected packages %+v", packages)
+	}
+
+	_, err = ParseCoverProfile(strings.NewReader("a/b/x.go:1.1,3.2 two 1"))
+	if err == nil {
+		t.Error("expect error for invalid line")
+	}
+}
+
+func TestRenderReport(t *testing.T) {
+	collector := NewTestCollector()
+	_, _ = collector.Write([]byte(`{"Action":"output","Package":"a","Test":"TestX","Output":"<script>alert(1)</script>\n"}
+{"Action":"fail","Package":"a","Test":"TestX"}
+`))
+	coverage := &CoverageProfile{Statements: 4, Covered: 1, Files: []FileCoverage{{File: "a/x.go", Package: "a", Statements: 4, Covered: 1}}}
+
+	content, err := RenderReport(collector.Report("app master", coverage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+	if strings.Contains(html, "<script>") {
+		t.Error("test output should be escaped")
+	}
+	for _, want := range []string{"app master", "TestX", "a/x.go", "25.0%", "hsl(30, 70%, 45%)"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report should contain %q", want)
+		}
+	}
+}
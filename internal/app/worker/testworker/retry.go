@@ -0,0 +1,49 @@
+package testworker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// maxRetryDelay 重试前等待时间的上限
+const maxRetryDelay = 5 * time.Minute
+
+// retryStep 执行阶段，失败后按 step.Retries 重试，每次重试前等待的时间翻倍。
+// 除最后一次外失败都上报为执行中，每次失败和重试都记录在阶段日志中。任务取消或 panic 时不再重试
+func (t *TestWorker) retryStep(task view.TestTask, step db.TestPipelineStep, run func(task view.TestTask) error) (err error) {
+	attempts := step.Retries + 1
+	for attempt := 1; ; attempt++ {
+		task.StepRetryPending = attempt < attempts
+		err = run(task)
+		if err == nil || err == ErrTaskCanceled || !task.StepRetryPending {
+			return
+		}
+		if _, ok := err.(*PanicError); ok {
+			return
+		}
+
+		delay := retryDelay(step.RetryDelaySeconds, attempt)
+		t.notifyStepStatus(task, step.Name, db.TestStepStatusRunning,
+			fmt.Sprintf("\nattempt %d/%d failed: %s, retry in %s\n", attempt, attempts, err.Error(), delay))
+		if !t.taskRun(task).wait(delay) {
+			t.notifyStepStatus(task, step.Name, db.TestStepStatusSkipped, "task canceled\n")
+			return ErrTaskCanceled
+		}
+		t.notifyStepStatus(task, step.Name, db.TestStepStatusRunning, fmt.Sprintf("attempt %d/%d\n", attempt+1, attempts))
+	}
+}
+
+// retryDelay 第 attempt 次失败后等待的时间，第一次为 delaySeconds，之后每次翻倍，不超过 maxRetryDelay
+func retryDelay(delaySeconds, attempt int) time.Duration {
+	delay := time.Duration(delaySeconds) * time.Second
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
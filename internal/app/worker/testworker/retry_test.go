@@ -0,0 +1,69 @@
+package testworker
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func TestRetryDelay(t *testing.T) {
+	for _, c := range []struct {
+		seconds, attempt int
+		want             time.Duration
+	}{
+		{0, 1, 0},
+		{5, 1, 5 * time.Second},
+		{5, 3, 20 * time.Second},
+		{60, 10, maxRetryDelay},
+	} {
+		if got := retryDelay(c.seconds, c.attempt); got != c.want {
+			t.Errorf("retryDelay(%d, %d) = %s, want %s", c.seconds, c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestRetryStep(t *testing.T) {
+	w := &TestWorker{}
+	task := view.TestTask{TaskID: 1}
+	w.runs.Store(taskKey{TaskID: task.TaskID}, newTaskRun())
+
+	var pending []bool
+	err := w.retryStep(task, db.TestPipelineStep{Name: "git_pull", Retries: 2}, func(task view.TestTask) error {
+		pending = append(pending, task.StepRetryPending)
+		if len(pending) < 3 {
+			return errors.New("connection reset by peer")
+		}
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(pending, []bool{true, true, false}) {
+		t.Fatalf("retryStep() = %v, attempts = %v", err, pending)
+	}
+
+	attempts := 0
+	err = w.retryStep(task, db.TestPipelineStep{Name: "git_pull", Retries: 1}, func(task view.TestTask) error {
+		attempts++
+		return errors.New("connection reset by peer")
+	})
+	if err == nil || attempts != 2 {
+		t.Errorf("retryStep() = %v after %d attempts, want error after 2", err, attempts)
+	}
+
+	// 等待重试时取消任务
+	notified = nil
+	time.AfterFunc(50*time.Millisecond, func() { w.Cancel(task.TaskID) })
+	start := time.Now()
+	err = w.retryStep(task, db.TestPipelineStep{Name: "git_pull", Retries: 1, RetryDelaySeconds: 60}, func(task view.TestTask) error {
+		return errors.New("connection reset by peer")
+	})
+	if err != ErrTaskCanceled || time.Since(start) > 5*time.Second {
+		t.Errorf("retryStep() = %v after %s, want ErrTaskCanceled", err, time.Since(start))
+	}
+	if len(notified) == 0 || !strings.Contains(notified[0], "attempt 1/2 failed: connection reset by peer, retry in 1m0s") {
+		t.Errorf("notified = %q", notified)
+	}
+}
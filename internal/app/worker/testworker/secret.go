@@ -0,0 +1,120 @@
+package testworker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+const (
+	// secretMask 日志中替换密钥值的内容
+	secretMask = "***"
+	// minMaskSize 短于该长度的密钥值不替换，否则日志中的大量普通内容会被替换
+	minMaskSize = 4
+)
+
+// loadSecrets 获取任务各阶段环境变量引用的密钥，没有引用时不请求 Juno
+func (t *TestWorker) loadSecrets(task view.TestTask, run *taskRun) error {
+	if len(pipeline.SecretRefs(task.Desc)) == 0 {
+		return nil
+	}
+
+	resp, err := t.client.R().SetHeaders(task.Trace).
+		SetQueryParam("task_id", strconv.Itoa(int(task.TaskID))).
+		Get("/api/v1/worker/testTask/secrets")
+	if err != nil {
+		return err
+	}
+	// 通过 juno-proxy 连接或 Juno 版本较低时没有该接口
+	if resp.StatusCode() == http.StatusNotFound {
+		return fmt.Errorf("juno does not provide secrets to this worker")
+	}
+
+	respObj := struct {
+		Code int               `json:"code"`
+		Msg  string            `json:"msg"`
+		Data map[string]string `json:"data"`
+	}{}
+	err = json.Unmarshal(resp.Body(), &respObj)
+	if err != nil {
+		return err
+	}
+	if respObj.Code != output.MsgOk {
+		return fmt.Errorf("get secrets failed: %s", respObj.Msg)
+	}
+
+	run.setSecrets(respObj.Data)
+	return nil
+}
+
+// stepEnv 阶段的环境变量，替换其中的密钥引用
+func (t *TestWorker) stepEnv(task view.TestTask, step db.TestPipelineStep) (env map[string]string, err error) {
+	if len(step.Env) == 0 {
+		return nil, nil
+	}
+
+	secrets := t.taskRun(task).taskSecrets()
+	env = make(map[string]string, len(step.Env))
+	for name, value := range step.Env {
+		env[name], err = pipeline.ExpandSecrets(value, secrets)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
+func (r *taskRun) setSecrets(secrets map[string]string) {
+	var values []string
+	for _, value := range secrets {
+		values = append(values, value)
+		// 多行的密钥按行输出日志时被拆开，每一行分别替换
+		if strings.Contains(value, "\n") {
+			for _, line := range strings.Split(value, "\n") {
+				values = append(values, strings.TrimSuffix(line, "\r"))
+			}
+		}
+	}
+	// 长的值优先替换，避免一个密钥是另一个的一部分时只替换了一部分
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	var pairs []string
+	for _, value := range values {
+		if len(value) >= minMaskSize {
+			pairs = append(pairs, value, secretMask)
+		}
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.secrets = secrets
+	if len(pairs) > 0 {
+		r.masker = strings.NewReplacer(pairs...)
+	}
+}
+
+func (r *taskRun) taskSecrets() map[string]string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.secrets
+}
+
+// mask 把日志中的密钥值替换为 ***
+func (r *taskRun) mask(logs string) string {
+	r.mtx.Lock()
+	masker := r.masker
+	r.mtx.Unlock()
+
+	if masker == nil || logs == "" {
+		return logs
+	}
+	return masker.Replace(logs)
+}
@@ -0,0 +1,52 @@
+package testworker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func TestMaskSecrets(t *testing.T) {
+	run := newTaskRun()
+	if got := run.mask("token s3cr3t"); got != "token s3cr3t" {
+		t.Errorf("mask without secrets = %q", got)
+	}
+
+	run.setSecrets(map[string]string{
+		"TOKEN":      "s3cr3t",
+		"LONG_TOKEN": "s3cr3t-long",
+		"SHORT":      "ab",
+		"KEY":        "line-one\r\nline-two",
+	})
+	got := run.mask("a s3cr3t b s3cr3t-long c ab\nline-one\r\nline-two\nline-two\n")
+	if want := "a *** b *** c ab\n***\n***\n"; got != want {
+		t.Errorf("mask() = %q, want %q", got, want)
+	}
+}
+
+func TestStepEnvSecrets(t *testing.T) {
+	w := &TestWorker{}
+	task := view.TestTask{TaskID: 1}
+	run := newTaskRun()
+	run.setSecrets(map[string]string{"TOKEN": "s3cr3t"})
+	w.runs.Store(taskKey{TaskID: 1}, run)
+
+	env, err := w.stepEnv(task, db.TestPipelineStep{Env: map[string]string{
+		"AUTH":    "Bearer ${{ secrets.TOKEN }}",
+		"GOFLAGS": "-mod=mod",
+	}})
+	if err != nil || env["AUTH"] != "Bearer s3cr3t" || env["GOFLAGS"] != "-mod=mod" {
+		t.Fatalf("stepEnv() = %v, %v", env, err)
+	}
+
+	_, err = w.stepEnv(task, db.TestPipelineStep{Env: map[string]string{"A": "${{ secrets.OTHER }}"}})
+	if !errors.Is(err, pipeline.ErrSecretNotResolved) {
+		t.Errorf("stepEnv(missing secret) err = %v", err)
+	}
+	if env, err = w.stepEnv(task, db.TestPipelineStep{}); env != nil || err != nil {
+		t.Errorf("stepEnv(no env) = %v, %v", env, err)
+	}
+}
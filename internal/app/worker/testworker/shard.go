@@ -0,0 +1,66 @@
+package testworker
+
+import (
+	"sort"
+)
+
+// defaultPackageElapsed 没有任何耗时记录时每个包的估计耗时
+const defaultPackageElapsed = 1.0
+
+// ShardPackages 按耗时把包分配到 total 个分片，返回第 shard 个分片（从 1 开始）的包。
+// 耗时长的包优先分配到当前总耗时最少的分片，没有耗时记录的包按已知包的平均耗时估计。
+// 各分片使用相同的输入时分配结果一致，因此分片之间不重复、不遗漏
+func ShardPackages(packages []string, timings map[string]float64, shard, total int) []string {
+	if total <= 1 {
+		return packages
+	}
+
+	var sum float64
+	var known int
+	for _, pkg := range packages {
+		if elapsed, ok := timings[pkg]; ok {
+			sum += elapsed
+			known++
+		}
+	}
+	estimate := defaultPackageElapsed
+	if known > 0 {
+		estimate = sum / float64(known)
+	}
+
+	type item struct {
+		pkg     string
+		elapsed float64
+	}
+	items := make([]item, 0, len(packages))
+	for _, pkg := range packages {
+		elapsed, ok := timings[pkg]
+		if !ok {
+			elapsed = estimate
+		}
+		items = append(items, item{pkg: pkg, elapsed: elapsed})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].elapsed != items[j].elapsed {
+			return items[i].elapsed > items[j].elapsed
+		}
+		return items[i].pkg < items[j].pkg
+	})
+
+	loads := make([]float64, total)
+	result := make([]string, 0)
+	for _, it := range items {
+		target := 0
+		for i := 1; i < total; i++ {
+			if loads[i] < loads[target] {
+				target = i
+			}
+		}
+		loads[target] += it.elapsed
+		if target == shard-1 {
+			result = append(result, it.pkg)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,35 @@
+package testworker
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestShardPackages(t *testing.T) {
+	packages := []string{"a", "b", "c", "d", "e"}
+	timings := map[string]float64{"a": 10, "b": 6, "c": 4, "d": 1}
+
+	shards := [][]string{
+		ShardPackages(packages, timings, 1, 2),
+		ShardPackages(packages, timings, 2, 2),
+	}
+	// a(10) -> 1, b(6) -> 2, e(估计 5.25) -> 2, c(4) -> 1, d(1) -> 2
+	if !reflect.DeepEqual(shards[0], []string{"a", "c"}) || !reflect.DeepEqual(shards[1], []string{"b", "d", "e"}) {
+		t.Fatalf("unexpected shards %v", shards)
+	}
+
+	// 分片之间不重复、不遗漏
+	var all []string
+	for shard := 1; shard <= 3; shard++ {
+		all = append(all, ShardPackages(packages, nil, shard, 3)...)
+	}
+	sort.Strings(all)
+	if !reflect.DeepEqual(all, packages) {
+		t.Errorf("packages lost or duplicated: %v", all)
+	}
+
+	if got := ShardPackages(packages, timings, 1, 1); !reflect.DeepEqual(got, packages) {
+		t.Errorf("single shard should run all packages, got %v", got)
+	}
+}
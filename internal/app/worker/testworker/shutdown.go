@@ -0,0 +1,136 @@
+package testworker
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// DefaultShutdownTimeout 退出时等待执行中的任务结束的时间
+const DefaultShutdownTimeout = 5 * time.Minute
+
+// shutdownPollInterval 退出时检查执行中任务数的间隔
+const shutdownPollInterval = 100 * time.Millisecond
+
+// interruptGrace 终止超时未结束的任务后，等待任务重新入队的时间
+const interruptGrace = 30 * time.Second
+
+// Shutdown 平滑退出：不再接收下发的任务并停止从队列取任务，等待执行中的任务结束，最长等待 ctx 的截止时间。
+// 超时仍未结束的任务被终止后重新入队，Juno 中标记为排队，重启后重新执行；最后关闭队列，本地队列的数据落盘
+func (t *TestWorker) Shutdown(ctx context.Context) error {
+	t.drainMtx.Lock()
+	t.shutdown = true
+	t.drainMtx.Unlock()
+	t.Drain()
+	t.Pause()
+
+	xlog.Info("TestWorker: shutting down, wait for running tasks", xlog.Int("running", t.Running()))
+	if !t.waitIdle(ctx) {
+		interrupted := t.interruptAll()
+		xlog.Warn("TestWorker: shutdown timeout, interrupt running tasks", xlog.Int("tasks", interrupted))
+
+		graceCtx, cancel := context.WithTimeout(context.Background(), interruptGrace)
+		defer cancel()
+		if !t.waitIdle(graceCtx) {
+			xlog.Error("TestWorker: interrupted tasks not finished", xlog.Int("running", t.Running()))
+		}
+	}
+
+	if t.queue == nil {
+		return nil
+	}
+	return t.queue.Close()
+}
+
+func (t *TestWorker) ShuttingDown() bool {
+	t.drainMtx.Lock()
+	defer t.drainMtx.Unlock()
+	return t.shutdown
+}
+
+// Running 执行中的任务数
+func (t *TestWorker) Running() int {
+	return int(atomic.LoadInt32(&t.running))
+}
+
+// startTask 开始执行从队列取到的任务，正在退出时返回 false，任务需要放回队列。
+// 与 Shutdown 使用同一把锁，Shutdown 之后不会再有任务开始执行
+func (t *TestWorker) startTask() bool {
+	t.drainMtx.Lock()
+	defer t.drainMtx.Unlock()
+	if t.shutdown {
+		return false
+	}
+	atomic.AddInt32(&t.running, 1)
+	return true
+}
+
+func (t *TestWorker) finishTask() {
+	atomic.AddInt32(&t.running, -1)
+}
+
+// beginPop 开始从队列取任务，正在退出时返回 false，不再取任务。
+// 与 Shutdown 使用同一把锁，Shutdown 之后开始的 Pop 不会发生，之前开始的由 waitIdle 等待
+func (t *TestWorker) beginPop() bool {
+	t.drainMtx.Lock()
+	defer t.drainMtx.Unlock()
+	if t.shutdown {
+		return false
+	}
+	atomic.AddInt32(&t.popping, 1)
+	return true
+}
+
+// endPop Pop 失败，或取到的任务已经确认、放回队列
+func (t *TestWorker) endPop() {
+	atomic.AddInt32(&t.popping, -1)
+}
+
+// waitIdle 等待执行中的任务全部结束，取到的任务全部确认或放回队列，ctx 结束时返回 false。
+// Shutdown 已经暂停消费，阻塞中的 Pop 会立即返回
+func (t *TestWorker) waitIdle(ctx context.Context) bool {
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for t.Running() > 0 || atomic.LoadInt32(&t.popping) > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// interruptAll 终止全部执行中的任务，任务结束后重新入队
+func (t *TestWorker) interruptAll() (count int) {
+	t.runs.Range(func(key, value interface{}) bool {
+		if value.(*taskRun).interrupt() {
+			count++
+		}
+		return true
+	})
+	return
+}
+
+// requeueInterrupted 退出时被终止的任务重新入队，worker 重启后或由其他 worker 重新执行
+func (t *TestWorker) requeueInterrupted(task view.TestTask) {
+	task.QueuedAt = time.Time{}
+	err := t.Push(task)
+	if err != nil {
+		xlog.Error("TestWorker: requeue interrupted task failed", xlog.Int("taskId", int(task.TaskID)), xlog.String("err", err.Error()))
+		t.notifyTaskUpdate(task, db.TestTaskStatusFailed, fmt.Sprintf("worker shutting down, requeue task failed. err = %s\n", err.Error()))
+		return
+	}
+
+	status := db.TestTaskStatusPending
+	if task.Part > 0 {
+		// 拆分下发的任务其他部分可能仍在执行
+		status = db.TestTaskStatusRunning
+	}
+	t.notifyTaskUpdate(task, status, "worker shutting down, running commands killed, task requeued\n")
+}
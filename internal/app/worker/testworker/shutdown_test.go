@@ -0,0 +1,165 @@
+package testworker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/taskqueue"
+)
+
+type closeQueue struct {
+	fakeQueue
+	closed bool
+}
+
+func (q *closeQueue) Close() error {
+	q.closed = true
+	return nil
+}
+
+// popQueue Pop 进入后阻塞到 release，模拟 Shutdown 时已经取出、还没有开始执行的消息
+type popQueue struct {
+	fakeQueue
+	entered chan struct{}
+	release chan struct{}
+
+	mtx     sync.Mutex
+	popped  bool
+	closed  bool
+	nacked  bool
+	nackErr error
+}
+
+func (q *popQueue) Pop(ctx context.Context) (*taskqueue.Message, error) {
+	q.mtx.Lock()
+	if q.closed {
+		q.mtx.Unlock()
+		return nil, taskqueue.ErrClosed
+	}
+	if q.popped {
+		q.mtx.Unlock()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	q.popped = true
+	q.mtx.Unlock()
+
+	close(q.entered)
+	<-q.release
+	return &taskqueue.Message{ID: "1", Body: []byte(`{"task_id":1}`)}, nil
+}
+
+func (q *popQueue) Nack(msg *taskqueue.Message) error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if q.closed {
+		q.nackErr = errors.New("queue closed")
+		return q.nackErr
+	}
+	q.nacked = true
+	return nil
+}
+
+func (q *popQueue) Close() error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.closed = true
+	return nil
+}
+
+func TestWorkerShutdownDuringPop(t *testing.T) {
+	queue := &popQueue{entered: make(chan struct{}), release: make(chan struct{})}
+	w := newTestWorker(queue)
+	go w.work()
+	<-queue.entered
+
+	done := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done <- w.Shutdown(ctx)
+	}()
+
+	// 取出的消息放回队列之前不能关闭队列
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown() returned before popped task was nacked, err = %v", err)
+	case <-time.After(3 * shutdownPollInterval):
+	}
+	close(queue.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	queue.mtx.Lock()
+	defer queue.mtx.Unlock()
+	if !queue.nacked || queue.nackErr != nil || !queue.closed {
+		t.Errorf("nacked = %v, nackErr = %v, closed = %v", queue.nacked, queue.nackErr, queue.closed)
+	}
+	if w.Running() != 0 {
+		t.Errorf("running = %d, popped task should not start after shutdown", w.Running())
+	}
+}
+
+func TestWorkerShutdown(t *testing.T) {
+	queue := &closeQueue{}
+	w := newTestWorker(queue)
+
+	// 执行中的任务在截止时间前结束
+	if !w.startTask() {
+		t.Fatal("startTask() = false before shutdown")
+	}
+	time.AfterFunc(50*time.Millisecond, w.finishTask)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !queue.closed || w.Running() != 0 {
+		t.Errorf("queue closed = %v, running = %d", queue.closed, w.Running())
+	}
+	if !w.ShuttingDown() || !w.Draining() || !w.Paused() {
+		t.Error("worker should stop consuming after shutdown")
+	}
+	if w.startTask() {
+		t.Error("startTask() = true after shutdown")
+	}
+}
+
+func TestWorkerShutdownTimeout(t *testing.T) {
+	w := newTestWorker(&closeQueue{})
+
+	run := newTaskRun()
+	w.runs.Store(taskKey{TaskID: 1}, run)
+	w.startTask()
+	go func() {
+		// 任务被终止后结束
+		<-run.done
+		w.runs.Delete(taskKey{TaskID: 1})
+		w.finishTask()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := w.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !run.Interrupted() || !run.Canceled() {
+		t.Errorf("interrupted = %v, canceled = %v", run.Interrupted(), run.Canceled())
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("shutdown took %s", time.Since(start))
+	}
+
+	// 已经取消的任务不算作被终止
+	canceled := newTaskRun()
+	canceled.cancel()
+	if canceled.interrupt() || canceled.Interrupted() {
+		t.Error("canceled task should not be interrupted")
+	}
+}
@@ -0,0 +1,42 @@
+package testworker
+
+import (
+	"context"
+	"sort"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/taskqueue"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// Capacity 同时执行的任务数
+func (t *TestWorker) Capacity() int {
+	return t.option.ParallelWorker
+}
+
+// RunningTasks 执行中的任务，按开始执行的时间排序
+func (t *TestWorker) RunningTasks() db.WorkerTasks {
+	tasks := make(db.WorkerTasks, 0)
+	t.runs.Range(func(key, value interface{}) bool {
+		tasks = append(tasks, value.(*taskRun).info)
+		return true
+	})
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartedAt.Before(tasks[j].StartedAt)
+	})
+	return tasks
+}
+
+// QueueDepth 队列中等待的任务数，共享队列为全部 worker 共同等待的任务数。队列不支持查看或查询失败时返回 -1
+func (t *TestWorker) QueueDepth(ctx context.Context) int {
+	manager, ok := t.queue.(taskqueue.Manager)
+	if !ok {
+		return -1
+	}
+	_, total, err := manager.Pending(ctx, 1)
+	if err != nil {
+		xlog.Error("TestWorker.QueueDepth", xlog.String("err", err.Error()))
+		return -1
+	}
+	return total
+}
@@ -0,0 +1,44 @@
+package testworker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/taskqueue"
+)
+
+func TestWorkerStatus(t *testing.T) {
+	w := newTestWorker(&fakeQueue{messages: []*taskqueue.Message{{ID: "1"}, {ID: "2"}}})
+	w.option.ParallelWorker = 4
+
+	now := time.Now()
+	for _, part := range []int{2, 1} {
+		run := newTaskRun()
+		run.info.TaskID = 1
+		run.info.Part = part
+		run.info.StartedAt = now.Add(-time.Duration(part) * time.Minute)
+		w.runs.Store(taskKey{TaskID: 1, Part: part}, run)
+	}
+
+	var parts []int
+	for _, task := range w.RunningTasks() {
+		parts = append(parts, task.Part)
+	}
+	if !reflect.DeepEqual(parts, []int{2, 1}) {
+		t.Errorf("running task parts = %v, want [2 1]", parts)
+	}
+	if w.Capacity() != 4 {
+		t.Errorf("capacity = %d", w.Capacity())
+	}
+	if depth := w.QueueDepth(context.Background()); depth != 2 {
+		t.Errorf("queue depth = %d, want 2", depth)
+	}
+
+	// 不支持查看的队列
+	w.queue = struct{ taskqueue.Queue }{}
+	if depth := w.QueueDepth(context.Background()); depth != -1 {
+		t.Errorf("queue depth = %d, want -1", depth)
+	}
+}
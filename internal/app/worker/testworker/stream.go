@@ -0,0 +1,183 @@
+package testworker
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// streamAckTimeout 等待 Juno 确认事件的时间，超时后断开连接，事件改用 HTTP 上报
+	streamAckTimeout = 10 * time.Second
+	// streamRedialInterval 连接失败后重新连接的间隔，期间事件直接使用 HTTP 上报
+	streamRedialInterval = 10 * time.Second
+	// streamPath Juno 接收 worker 事件的 WebSocket 地址
+	streamPath = "/api/v1/worker/testTask/stream"
+	// streamMaxLineSize 按行推送日志时单行的最大长度，超过时拆分推送
+	streamMaxLineSize = 4096
+	// httpLogChunkSize 使用 HTTP 上报时日志攒够该大小才上报一次
+	httpLogChunkSize = 128
+)
+
+// errStreamClosed 连接在收到确认之前断开
+var errStreamClosed = fmt.Errorf("event stream closed")
+
+type (
+	// eventStream worker 到 Juno 的 WebSocket 长连接，多个任务共用。
+	// 每条事件等待 Juno 处理完成后的确认再返回，同一阶段的日志按顺序到达，阶段的最终状态不会早于日志。
+	// 连接不可用时 send 返回错误，由调用方改用 HTTP 上报
+	eventStream struct {
+		url    string
+		origin string
+		token  string
+
+		mtx      sync.Mutex
+		conn     *websocket.Conn
+		seq      uint64
+		pending  map[uint64]chan view.TestTaskStreamAck
+		redialAt time.Time // 连接失败后在该时间之前不再重连
+		dial     func() (*websocket.Conn, error)
+
+		writeMtx sync.Mutex
+	}
+)
+
+// newPrinter 阶段日志的 Printer，使用长连接时按行实时输出
+func (t *TestWorker) newPrinter() *Printer {
+	if t.stream != nil {
+		return NewLinePrinter(streamMaxLineSize, t.option.MaxStepLogSize)
+	}
+	return NewPrinter(httpLogChunkSize, t.option.MaxStepLogSize)
+}
+
+// newEventStream junoAddress 为 Juno 的 http 地址，使用对应的 ws、wss 地址
+func newEventStream(junoAddress, token string) *eventStream {
+	address := strings.TrimSuffix(junoAddress, "/")
+	url := address + streamPath
+	if strings.HasPrefix(url, "https://") {
+		url = "wss://" + strings.TrimPrefix(url, "https://")
+	} else {
+		url = "ws://" + strings.TrimPrefix(url, "http://")
+	}
+
+	s := &eventStream{
+		url:     url,
+		origin:  address,
+		token:   token,
+		pending: make(map[uint64]chan view.TestTaskStreamAck),
+	}
+	s.dial = s.dialJuno
+	return s
+}
+
+func (s *eventStream) dialJuno() (*websocket.Conn, error) {
+	config, err := websocket.NewConfig(s.url, s.origin)
+	if err != nil {
+		return nil, err
+	}
+	config.Header = http.Header{}
+	config.Header.Set("Token", s.token)
+	return websocket.DialConfig(config)
+}
+
+// send 上报事件并等待 Juno 的确认，返回 Juno 响应的错误码
+func (s *eventStream) send(event view.TestTaskEvent) (code int, msg string, err error) {
+	conn, seq, ack, err := s.prepare()
+	if err != nil {
+		return
+	}
+	defer s.forget(seq)
+
+	s.writeMtx.Lock()
+	_ = conn.SetWriteDeadline(time.Now().Add(streamAckTimeout))
+	err = websocket.JSON.Send(conn, view.TestTaskStreamMessage{Seq: seq, Event: event})
+	s.writeMtx.Unlock()
+	if err != nil {
+		s.close(conn)
+		return
+	}
+
+	timer := time.NewTimer(streamAckTimeout)
+	defer timer.Stop()
+	select {
+	case result, ok := <-ack:
+		if !ok {
+			return 0, "", errStreamClosed
+		}
+		return result.Code, result.Msg, nil
+	case <-timer.C:
+		s.close(conn)
+		return 0, "", fmt.Errorf("wait for event ack timeout")
+	}
+}
+
+// prepare 连接不存在时建立连接，分配事件序号
+func (s *eventStream) prepare() (conn *websocket.Conn, seq uint64, ack chan view.TestTaskStreamAck, err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.conn == nil {
+		if time.Now().Before(s.redialAt) {
+			return nil, 0, nil, errStreamClosed
+		}
+		s.conn, err = s.dial()
+		if err != nil {
+			s.conn = nil
+			s.redialAt = time.Now().Add(streamRedialInterval)
+			xlog.Warn("TestWorker: dial event stream failed, use http", xlog.String("url", s.url), xlog.String("err", err.Error()))
+			return
+		}
+		go s.receive(s.conn)
+	}
+
+	s.seq++
+	ack = make(chan view.TestTaskStreamAck, 1)
+	s.pending[s.seq] = ack
+	return s.conn, s.seq, ack, nil
+}
+
+// receive 读取确认，交给等待中的 send。连接断开时等待中的 send 全部返回错误
+func (s *eventStream) receive(conn *websocket.Conn) {
+	for {
+		var ack view.TestTaskStreamAck
+		if err := websocket.JSON.Receive(conn, &ack); err != nil {
+			s.close(conn)
+			return
+		}
+
+		s.mtx.Lock()
+		if ch, ok := s.pending[ack.Seq]; ok {
+			ch <- ack
+			delete(s.pending, ack.Seq)
+		}
+		s.mtx.Unlock()
+	}
+}
+
+func (s *eventStream) forget(seq uint64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.pending, seq)
+}
+
+// close 关闭连接，conn 已经不是当前连接时忽略
+func (s *eventStream) close(conn *websocket.Conn) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.conn != conn {
+		return
+	}
+	_ = conn.Close()
+	s.conn = nil
+	for seq, ch := range s.pending {
+		close(ch)
+		delete(s.pending, seq)
+	}
+}
@@ -0,0 +1,94 @@
+package testworker
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
+	"github.com/douyu/juno/pkg/model/view"
+	"golang.org/x/net/websocket"
+)
+
+func TestEventStream(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		received []view.TestTaskEvent
+	)
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		if conn.Request().Header.Get("Token") != "sa-token" {
+			return
+		}
+		for {
+			var msg view.TestTaskStreamMessage
+			if err := websocket.JSON.Receive(conn, &msg); err != nil {
+				return
+			}
+			mtx.Lock()
+			received = append(received, msg.Event)
+			mtx.Unlock()
+
+			ack := view.TestTaskStreamAck{Seq: msg.Seq, Code: output.MsgOk, Msg: "success"}
+			if msg.Event.TaskID == 2 {
+				ack.Code, ack.Msg = output.MsgConflict, "task canceled"
+			}
+			if msg.Event.TaskID == 3 {
+				// 不确认直接断开
+				return
+			}
+			_ = websocket.JSON.Send(conn, ack)
+		}
+	}))
+	defer server.Close()
+
+	s := newEventStream(server.URL, "sa-token")
+	data, _ := json.Marshal(view.TestTaskStepUpdatePayload{StepName: "unit_test", LogsAppend: "ok\n"})
+
+	code, _, err := s.send(view.TestTaskEvent{Type: view.TaskStepUpdateEvent, TaskID: 1, Data: data})
+	if err != nil || code != output.MsgOk {
+		t.Fatalf("send() = %d, %v", code, err)
+	}
+	code, msg, err := s.send(view.TestTaskEvent{Type: view.TaskStepUpdateEvent, TaskID: 2, Data: data})
+	if err != nil || code != output.MsgConflict || msg != "task canceled" {
+		t.Fatalf("send() = %d %q, %v, want conflict", code, msg, err)
+	}
+	mtx.Lock()
+	if len(received) != 2 || string(received[0].Data) != string(data) {
+		t.Errorf("received = %+v", received)
+	}
+	mtx.Unlock()
+
+	// 连接断开时返回错误，由调用方改用 HTTP
+	if _, _, err = s.send(view.TestTaskEvent{Type: view.TaskStepUpdateEvent, TaskID: 3}); err == nil {
+		t.Error("expect error when connection closed before ack")
+	}
+	// 之后重新连接
+	if code, _, err = s.send(view.TestTaskEvent{Type: view.TaskStepUpdateEvent, TaskID: 1}); err != nil || code != output.MsgOk {
+		t.Errorf("send() after reconnect = %d, %v", code, err)
+	}
+}
+
+func TestEventStreamDialFailed(t *testing.T) {
+	s := newEventStream("http://127.0.0.1:1", "sa-token")
+	dials := 0
+	dial := s.dial
+	s.dial = func() (*websocket.Conn, error) {
+		dials++
+		return dial()
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := s.send(view.TestTaskEvent{TaskID: 1}); err == nil {
+			t.Fatal("expect dial error")
+		}
+	}
+	// 连接失败后一段时间内不再重连
+	if dials != 1 {
+		t.Errorf("dials = %d, want 1", dials)
+	}
+
+	if s := newEventStream("https://juno.local/", ""); s.url != "wss://juno.local"+streamPath {
+		t.Errorf("url = %s", s.url)
+	}
+}
@@ -0,0 +1,269 @@
+package testworker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/pkg/errors"
+)
+
+// DefaultGoDownloadURL 官方发布包的下载地址，文件名为 go1.16.15.linux-amd64.tar.gz
+const DefaultGoDownloadURL = "https://dl.google.com/go/"
+
+type (
+	// Toolchains 管理多个版本的 Go，每个版本解压在 dir/<version>，首次使用时下载
+	Toolchains struct {
+		dir         string
+		downloadURL string
+		client      *http.Client
+
+		mtx   sync.Mutex
+		locks map[string]*sync.Mutex // 同一版本同时只有一个下载
+	}
+)
+
+func NewToolchains(dir, downloadURL string) *Toolchains {
+	if downloadURL == "" {
+		downloadURL = DefaultGoDownloadURL
+	}
+	if !strings.HasSuffix(downloadURL, "/") {
+		downloadURL += "/"
+	}
+	return &Toolchains{
+		dir:         dir,
+		downloadURL: downloadURL,
+		client:      &http.Client{Timeout: 10 * time.Minute},
+		locks:       make(map[string]*sync.Mutex),
+	}
+}
+
+// GOROOT 返回 version 的 GOROOT，未安装时下载安装。version 为空时返回空字符串，使用 worker 默认的 go
+func (c *Toolchains) GOROOT(version string) (goroot string, err error) {
+	version, err = pipeline.NormalizeGoVersion(version)
+	if err != nil || version == "" {
+		return
+	}
+
+	lock := c.lock(version)
+	lock.Lock()
+	defer lock.Unlock()
+
+	goroot = c.path(version)
+	if _, err = os.Stat(filepath.Join(goroot, "bin", "go")); err == nil {
+		return
+	}
+
+	xlog.Info("Toolchains: install go", xlog.String("version", version))
+	err = c.install(version)
+	if err != nil {
+		return "", errors.Wrapf(err, "install %s failed", version)
+	}
+	return
+}
+
+func (c *Toolchains) lock(version string) *sync.Mutex {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.locks[version] == nil {
+		c.locks[version] = &sync.Mutex{}
+	}
+	return c.locks[version]
+}
+
+func (c *Toolchains) path(version string) string {
+	return filepath.Join(c.dir, version)
+}
+
+// install 下载并校验发布包，解压到临时目录后重命名，中途失败不会留下不完整的版本
+func (c *Toolchains) install(version string) (err error) {
+	err = os.MkdirAll(c.dir, 0755)
+	if err != nil {
+		return
+	}
+	tmpDir, err := ioutil.TempDir(c.dir, ".install-"+version)
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	url := fmt.Sprintf("%s%s.%s-%s.tar.gz", c.downloadURL, version, runtime.GOOS, runtime.GOARCH)
+	archive := filepath.Join(tmpDir, "go.tar.gz")
+	sum, err := c.download(url, archive)
+	if err != nil {
+		return
+	}
+
+	expected, err := c.checksum(url + ".sha256")
+	if err != nil {
+		return
+	}
+	if expected != "" && expected != sum {
+		return fmt.Errorf("checksum mismatch for %s: expect %s, got %s", url, expected, sum)
+	}
+
+	err = extractTarGz(archive, tmpDir)
+	if err != nil {
+		return
+	}
+	return os.Rename(filepath.Join(tmpDir, "go"), c.path(version))
+}
+
+// download 下载到 dst，返回文件的 sha256
+func (c *Toolchains) download(url, dst string) (sum string, err error) {
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), resp.Body)
+	if err != nil {
+		return
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checksum 发布包的 sha256，镜像没有提供校验文件时返回空字符串
+func (c *Toolchains) checksum(url string) (sum string, err error) {
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		xlog.Warn("Toolchains: checksum not found, skip verification", xlog.String("url", url))
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file %s", url)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// extractTarGz 解压到 dir，只处理目录和普通文件，拒绝解压到 dir 之外的路径
+func extractTarGz(archive, dir string) (err error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		var header *tar.Header
+		header, err = tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = writeFile(target, tr, os.FileMode(header.Mode).Perm())
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) (err error) {
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return
+}
+
+// goEnv 使用 goroot 执行命令的环境变量，goroot 在 PATH 最前。goroot 为空时返回 nil，即使用 worker 自身的环境
+func goEnv(goroot string) []string {
+	if goroot == "" {
+		return nil
+	}
+	set, bin := goOverrides(goroot)
+	return mergeEnv(os.Environ(), set, bin)
+}
+
+// goOverrides goroot 需要设置的环境变量和加入 PATH 的目录
+func goOverrides(goroot string) (set map[string]string, bin string) {
+	// GOTOOLCHAIN=local 避免 go.mod 中的 toolchain 指令切换到其他版本
+	return map[string]string{"GOROOT": goroot, "GOTOOLCHAIN": "local"}, filepath.Join(goroot, "bin")
+}
+
+// goBinary goroot 下的 go，goroot 为空时为 PATH 中的 go
+func goBinary(goroot string) string {
+	if goroot == "" {
+		return "go"
+	}
+	return filepath.Join(goroot, "bin", "go")
+}
+
+// goVersion go version 输出中的版本和平台，如 go1.16.15 linux/amd64
+func goVersion(goroot string) (string, error) {
+	cmd := exec.Command(goBinary(goroot), "version")
+	cmd.Env = goEnv(goroot)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrap(err, "go version failed")
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(out)), "go version "), nil
+}
@@ -0,0 +1,148 @@
+package testworker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func makeTarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestToolchainsGOROOT(t *testing.T) {
+	archive := makeTarGz(t, map[string]string{
+		"go/bin/go": "#!/bin/sh\necho go version go1.99.1 " + runtime.GOOS + "/" + runtime.GOARCH + "\n",
+	})
+	sum := sha256.Sum256(archive)
+	name := fmt.Sprintf("/go1.99.1.%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+
+	var downloads int32
+	checksum := hex.EncodeToString(sum[:])
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case name:
+			atomic.AddInt32(&downloads, 1)
+			_, _ = w.Write(archive)
+		case name + ".sha256":
+			_, _ = w.Write([]byte(checksum))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "toolchains")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := NewToolchains(dir, server.URL)
+
+	if goroot, err := c.GOROOT(""); err != nil || goroot != "" {
+		t.Fatalf("default GOROOT = %q, %v", goroot, err)
+	}
+	if _, err := c.GOROOT("1.99/../.."); err == nil {
+		t.Fatal("expect error for invalid version")
+	}
+
+	for i := 0; i < 2; i++ {
+		goroot, err := c.GOROOT("1.99.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if goroot != filepath.Join(dir, "go1.99.1") {
+			t.Fatalf("unexpected GOROOT %s", goroot)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("downloaded %d times, want 1", downloads)
+	}
+
+	version, err := goVersion(filepath.Join(dir, "go1.99.1"))
+	if err != nil || !strings.HasPrefix(version, "go1.99.1 ") {
+		t.Errorf("goVersion = %q, %v", version, err)
+	}
+
+	checksum = strings.Repeat("0", 64)
+	if _, err := c.GOROOT("1.99.2"); err == nil {
+		t.Error("expect error for missing release")
+	}
+	_ = os.RemoveAll(filepath.Join(dir, "go1.99.1"))
+	if _, err := c.GOROOT("1.99.1"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expect checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go1.99.1")); !os.IsNotExist(err) {
+		t.Error("failed install should not leave GOROOT")
+	}
+}
+
+func TestExtractTarGzRejectsTraversal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "a.tar.gz")
+	err = ioutil.WriteFile(archive, makeTarGz(t, map[string]string{"../evil": "x"}), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := extractTarGz(archive, filepath.Join(dir, "out")); err == nil {
+		t.Fatal("expect error for path outside dir")
+	}
+}
+
+func TestGoEnv(t *testing.T) {
+	if env := goEnv(""); env != nil {
+		t.Fatalf("default env = %v, want nil", env)
+	}
+
+	env := goEnv("/opt/go1.16")
+	var path, goroot string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "PATH=") {
+			path = kv
+		}
+		if strings.HasPrefix(kv, "GOROOT=") {
+			if goroot != "" {
+				t.Fatal("duplicated GOROOT")
+			}
+			goroot = kv
+		}
+	}
+	if goroot != "GOROOT=/opt/go1.16" || !strings.HasPrefix(path, "PATH=/opt/go1.16/bin") {
+		t.Errorf("unexpected env GOROOT %q PATH %q", goroot, path)
+	}
+}
@@ -0,0 +1,164 @@
+package testworker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+type (
+	// govulncheckMessage govulncheck -json 输出的消息，只解析用到的字段
+	govulncheckMessage struct {
+		OSV     *osvEntry           `json:"osv"`
+		Finding *govulncheckFinding `json:"finding"`
+	}
+
+	osvEntry struct {
+		ID       string   `json:"id"`
+		Summary  string   `json:"summary"`
+		Aliases  []string `json:"aliases"`
+		Severity []struct {
+			Type  string `json:"type"`
+			Score string `json:"score"`
+		} `json:"severity"`
+		DatabaseSpecific struct {
+			Severity string `json:"severity"`
+		} `json:"database_specific"`
+	}
+
+	govulncheckFinding struct {
+		OSV          string `json:"osv"`
+		FixedVersion string `json:"fixed_version"`
+		Trace        []struct {
+			Module   string `json:"module"`
+			Version  string `json:"version"`
+			Package  string `json:"package"`
+			Function string `json:"function"`
+		} `json:"trace"`
+	}
+)
+
+var vulnSeverityRank = map[string]int{
+	db.VulnSeverityUnknown:  0,
+	db.VulnSeverityLow:      1,
+	db.VulnSeverityModerate: 2,
+	db.VulnSeverityHigh:     3,
+	db.VulnSeverityCritical: 4,
+}
+
+// ParseGovulncheck 解析 govulncheck -json 的输出，同一漏洞、同一模块的多条发现合并为一条，
+// 任意一条发现的调用链到达存在漏洞的函数时视为实际调用
+func ParseGovulncheck(r io.Reader) (vulns []view.Vulnerability, err error) {
+	entries := make(map[string]*osvEntry)
+	found := make(map[string]*view.Vulnerability)
+	var keys []string
+
+	decoder := json.NewDecoder(r)
+	for {
+		var msg govulncheckMessage
+		err = decoder.Decode(&msg)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if msg.OSV != nil {
+			entries[msg.OSV.ID] = msg.OSV
+		}
+		if msg.Finding == nil || len(msg.Finding.Trace) == 0 {
+			continue
+		}
+
+		frame := msg.Finding.Trace[0]
+		key := msg.Finding.OSV + " " + frame.Module
+		v, ok := found[key]
+		if !ok {
+			v = &view.Vulnerability{
+				VulnID:       msg.Finding.OSV,
+				Module:       frame.Module,
+				Version:      frame.Version,
+				FixedVersion: msg.Finding.FixedVersion,
+			}
+			found[key] = v
+			keys = append(keys, key)
+		}
+		v.Called = v.Called || frame.Function != ""
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		v := found[key]
+		v.Severity = db.VulnSeverityUnknown
+		if entry, ok := entries[v.VulnID]; ok {
+			v.Summary = entry.Summary
+			v.Aliases = entry.Aliases
+			v.Severity = osvSeverity(*entry)
+		}
+		vulns = append(vulns, *v)
+	}
+	return vulns, nil
+}
+
+// osvSeverity 漏洞级别，优先使用 GHSA 等数据源标注的级别，其次使用数值形式的 CVSS 分数
+func osvSeverity(entry osvEntry) string {
+	switch strings.ToLower(entry.DatabaseSpecific.Severity) {
+	case "critical":
+		return db.VulnSeverityCritical
+	case "high":
+		return db.VulnSeverityHigh
+	case "moderate", "medium":
+		return db.VulnSeverityModerate
+	case "low":
+		return db.VulnSeverityLow
+	}
+
+	for _, s := range entry.Severity {
+		score, err := strconv.ParseFloat(s.Score, 64)
+		if err != nil {
+			continue
+		}
+		switch {
+		case score >= 9:
+			return db.VulnSeverityCritical
+		case score >= 7:
+			return db.VulnSeverityHigh
+		case score >= 4:
+			return db.VulnSeverityModerate
+		case score > 0:
+			return db.VulnSeverityLow
+		}
+	}
+	return db.VulnSeverityUnknown
+}
+
+// VulnBlocked 漏洞是否触发拦截，只拦截代码实际调用的漏洞，gate 为 any 时没有级别的漏洞也拦截
+func VulnBlocked(v view.Vulnerability, gate string) bool {
+	if gate == "" || !v.Called {
+		return false
+	}
+	if gate == db.VulnGateAny {
+		return true
+	}
+	return v.Severity != db.VulnSeverityUnknown && vulnSeverityRank[v.Severity] >= vulnSeverityRank[gate]
+}
+
+// formatVulnerability 阶段日志中的一行
+func formatVulnerability(v view.Vulnerability) string {
+	fixed := v.FixedVersion
+	if fixed == "" {
+		fixed = "none"
+	}
+	called := ""
+	if v.Called {
+		called = ", called"
+	}
+	return fmt.Sprintf("%s %s@%s fixed: %s (%s%s) %s\n", v.VulnID, v.Module, v.Version, fixed, v.Severity, called, v.Summary)
+}
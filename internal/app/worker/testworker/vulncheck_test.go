@@ -0,0 +1,58 @@
+package testworker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+func TestParseGovulncheck(t *testing.T) {
+	output := `{"config":{"scanner_name":"govulncheck"}}
+{
+  "osv": {
+    "id": "GO-2023-0001",
+    "summary": "Panic in foo",
+    "aliases": ["CVE-2023-0001", "GHSA-xxxx"],
+    "database_specific": {"severity": "HIGH"}
+  }
+}
+{"osv":{"id":"GO-2023-0002","summary":"Leak in bar"}}
+{"finding":{"osv":"GO-2023-0001","fixed_version":"v1.2.3","trace":[{"module":"example.com/foo","version":"v1.2.0","package":"example.com/foo"}]}}
+{"finding":{"osv":"GO-2023-0001","fixed_version":"v1.2.3","trace":[{"module":"example.com/foo","version":"v1.2.0","package":"example.com/foo","function":"Parse"},{"module":"m","function":"main"}]}}
+{"finding":{"osv":"GO-2023-0002","trace":[{"module":"example.com/bar","version":"v0.1.0"}]}}
+`
+	vulns, err := ParseGovulncheck(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vulns) != 2 {
+		t.Fatalf("expect 2 vulnerabilities, got %+v", vulns)
+	}
+
+	foo, bar := vulns[0], vulns[1]
+	if foo.VulnID != "GO-2023-0001" || !foo.Called || foo.Severity != db.VulnSeverityHigh ||
+		foo.FixedVersion != "v1.2.3" || len(foo.Aliases) != 2 {
+		t.Errorf("unexpected vulnerability %+v", foo)
+	}
+	if bar.Called || bar.Severity != db.VulnSeverityUnknown || bar.Summary != "Leak in bar" {
+		t.Errorf("unexpected vulnerability %+v", bar)
+	}
+
+	cases := []struct {
+		gate string
+		foo  bool
+		bar  bool
+	}{
+		{"", false, false},
+		{db.VulnSeverityCritical, false, false},
+		{db.VulnSeverityHigh, true, false},
+		{db.VulnSeverityLow, true, false},
+		{db.VulnGateAny, true, false},
+	}
+	for _, c := range cases {
+		if VulnBlocked(foo, c.gate) != c.foo || VulnBlocked(bar, c.gate) != c.bar {
+			t.Errorf("unexpected gate result for %q", c.gate)
+		}
+	}
+}
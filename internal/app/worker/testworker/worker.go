@@ -1,11 +1,15 @@
 package testworker
 
 import (
+	"bytes"
 	"context"
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/url"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -14,15 +18,18 @@ import (
 
 	"github.com/jhump/protoreflect/desc"
 
-	"github.com/beeker1121/goque"
+	"github.com/douyu/juno/internal/pkg/packages/contrib/output"
 	"github.com/douyu/juno/internal/pkg/packages/xtest"
 	"github.com/douyu/juno/internal/pkg/service/codeplatform"
 	"github.com/douyu/juno/internal/pkg/service/httptest"
 	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/taskqueue"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/douyu/jupiter/pkg/xlog"
 	"github.com/go-resty/resty/v2"
+	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
@@ -32,9 +39,26 @@ type (
 	TestWorker struct {
 		option      Option
 		client      *resty.Client
-		taskChan    chan view.TestTask
-		queue       *goque.Queue
+		queue       taskqueue.Queue
+		toolchains  *Toolchains
+		workspaces  sync.Map     // taskKey => *workspace
+		runs        sync.Map     // taskKey => *taskRun
+		stream      *eventStream // 开启 LogStream 时上报阶段状态和日志的长连接
 		jobHandlers map[db.TestJobType]JobHandler
+
+		// 暂停消费时取消 popCtx，结束阻塞中的 Pop，恢复时关闭 resumed
+		pauseMtx  sync.Mutex
+		paused    bool
+		resumed   chan struct{}
+		popCtx    context.Context
+		popCancel context.CancelFunc
+
+		drainMtx    sync.Mutex
+		draining    bool
+		drainPaused bool  // drain 时暂停了消费，undrain 时恢复
+		shutdown    bool  // 正在退出，不再开始执行新的任务
+		running     int32 // 执行中的任务数，原子操作
+		popping     int32 // 正在 Pop 或取到后还没有确认、放回队列的消息数，原子操作
 	}
 
 	Option struct {
@@ -42,7 +66,14 @@ type (
 		Token          string
 		ParallelWorker int
 		RepoStorageDir string
-		QueueDir       string
+		MaxStepLogSize int           // 单个阶段保留的日志大小（字节），超过时只保留开头和结尾
+		GoToolchainDir string        // 流水线指定 Go 版本时，各版本的安装目录
+		GoDownloadURL  string        // Go 发布包的下载地址，默认 https://dl.google.com/go/
+		WorkspaceDir   string        // 任务独立的 HOME、GOPATH、临时目录所在的目录，默认为系统临时目录
+		StepTimeout    time.Duration // 流水线没有设置超时时间的阶段使用的超时时间，默认 DefaultStepTimeout
+		Queue          taskqueue.Config
+		Labels         map[string]string // worker 的标签，如 arch=arm64，只执行要求的标签全部匹配的任务
+		LogStream      bool              // 通过 WebSocket 长连接按行实时上报阶段日志，连接不可用时使用 HTTP
 	}
 
 	RespConsumeJob struct {
@@ -61,6 +92,9 @@ type (
 	JobHandler   func(task view.TestTask, name string, p json.RawMessage) error
 )
 
+// DefaultStepTimeout 阶段默认的超时时间，与之前单元测试写死的 5 分钟相同，耗时较长的仓库在流水线或 worker 配置中调大
+const DefaultStepTimeout = 5 * time.Minute
+
 var (
 	instance *TestWorker
 	initOnce sync.Once
@@ -76,15 +110,20 @@ func init() {
 
 func Instance() *TestWorker {
 	initOnce.Do(func() {
-		instance = &TestWorker{
-			taskChan: make(chan view.TestTask),
-		}
+		instance = &TestWorker{}
+		instance.popCtx, instance.popCancel = context.WithCancel(context.Background())
 
 		instance.jobHandlers = map[db.TestJobType]JobHandler{
-			db.JobGitPull:   instance.gitPull,
-			db.JobHttpTest:  instance.httpTest,
-			db.JobUnitTest:  instance.unitTest,
-			db.JobCodeCheck: instance.codeCheck,
+			db.JobGitPull:      instance.gitPull,
+			db.JobHttpTest:     instance.httpTest,
+			db.JobUnitTest:     instance.unitTest,
+			db.JobCodeCheck:    instance.codeCheck,
+			db.JobVulnCheck:    instance.vulnCheck,
+			db.JobLicenseCheck: instance.licenseCheck,
+			db.JobBuildReport:  instance.buildReport,
+			db.JobCrossBuild:   instance.crossBuild,
+			db.JobBuildCheck:   instance.buildCheck,
+			db.JobBenchmark:    instance.benchmark,
 			//db.JobGrpcTest:  instance.grpcTest,
 		}
 	})
@@ -93,12 +132,25 @@ func Instance() *TestWorker {
 }
 
 func (t *TestWorker) Init(option Option) (err error) {
+	if option.MaxStepLogSize <= 0 {
+		option.MaxStepLogSize = DefaultMaxLogSize
+	}
+	if option.StepTimeout <= 0 {
+		option.StepTimeout = DefaultStepTimeout
+	}
+	if option.GoToolchainDir == "" {
+		option.GoToolchainDir = filepath.Join(os.TempDir(), "juno-toolchains")
+	}
 	t.option = option
+	t.toolchains = NewToolchains(option.GoToolchainDir, option.GoDownloadURL)
 	t.client = resty.New().
 		SetHostURL(option.JunoAddress).
 		SetTimeout(20*time.Second).
 		SetHeader("Token", option.Token)
-	t.queue, err = goque.OpenQueue(option.QueueDir)
+	if option.LogStream {
+		t.stream = newEventStream(option.JunoAddress, option.Token)
+	}
+	t.queue, err = taskqueue.Open(option.Queue)
 	if err != nil {
 		return
 	}
@@ -108,13 +160,30 @@ func (t *TestWorker) Init(option Option) (err error) {
 	return
 }
 
+// CheckQueue 任务队列是否可用
+func (t *TestWorker) CheckQueue(ctx context.Context) error {
+	if t.queue == nil {
+		return fmt.Errorf("task queue not opened")
+	}
+	return t.queue.Ping(ctx)
+}
+
 func (t *TestWorker) Start() {
-	go t.startPull()
-	go t.startWork()
+	for i := 0; i < t.option.ParallelWorker; i++ {
+		go t.work()
+	}
 }
 
 func (t *TestWorker) Push(task view.TestTask) error {
-	_, err := t.queue.EnqueueObjectAsJSON(task)
+	if task.QueuedAt.IsZero() {
+		task.QueuedAt = time.Now()
+	}
+	body, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	err = taskqueue.PushPriority(context.Background(), t.queue, body, task.Priority)
 	if err != nil {
 		xlog.Error("enqueue failed", xlog.String("err", err.Error()))
 		return err
@@ -123,55 +192,210 @@ func (t *TestWorker) Push(task view.TestTask) error {
 	return nil
 }
 
-func (t *TestWorker) startPull() {
+// work 每个 goroutine 直接从队列取任务，取到的任务在处理期间定期 Touch，避免被其他 worker 重复领取
+func (t *TestWorker) work() {
 	for {
-		item, err := t.queue.Dequeue()
+		ctx := t.waitResumed()
+		// 从 Pop 开始计数，Shutdown 等取到的任务确认或放回队列之后再关闭队列
+		if !t.beginPop() {
+			return
+		}
+		msg, err := t.queue.Pop(ctx)
 		if err != nil {
-			if err == goque.ErrEmpty {
-				time.Sleep(1 * time.Second)
-			} else {
-				xlog.Error("pull item failed. wait for 10 second and retry", xlog.String("err", err.Error()))
-				time.Sleep(10 * time.Second)
+			t.endPop()
+			if err == taskqueue.ErrClosed {
+				return
+			}
+			if ctx.Err() != nil {
+				// 暂停消费
+				continue
 			}
 
+			xlog.Error("pull item failed. wait for 10 second and retry", xlog.String("err", err.Error()))
+			time.Sleep(10 * time.Second)
 			continue
 		}
 
-		if item == nil {
-			continue
-		}
+		t.consume(msg)
+		t.endPop()
+	}
+}
 
-		var task view.TestTask
-		err = item.ToObjectFromJSON(&task)
-		if err != nil {
-			xlog.Error("unmarshall task failed", xlog.String("err", err.Error()))
+// consume 执行取到的任务，返回前任务已经确认或放回队列
+func (t *TestWorker) consume(msg *taskqueue.Message) {
+	var task view.TestTask
+	err := json.Unmarshal(msg.Body, &task)
+	if err != nil {
+		xlog.Error("unmarshall task failed", xlog.String("err", err.Error()))
+		_ = t.queue.Ack(msg)
+		return
+	}
+	if !t.MatchLabels(task.WorkerLabels) {
+		t.requeueUnmatched(msg, task)
+		return
+	}
 
-			continue
+	// 退出前已经取到的任务放回队列
+	if !t.startTask() {
+		err = t.queue.Nack(msg)
+		if err != nil {
+			xlog.Error("nack task failed", xlog.String("err", err.Error()), xlog.Int("taskId", int(task.TaskID)))
 		}
+		return
+	}
+	stop := taskqueue.KeepAlive(t.queue, msg, t.option.Queue.VisibilityTimeout)
+	perr := t.safeHandleTask(task)
+	stop()
+	if perr != nil {
+		t.onTaskPanic(task, perr)
+	}
 
-		t.taskChan <- task
+	// 任务结果已经上报给 Juno，无论成功与否都不再重试
+	err = t.queue.Ack(msg)
+	if err != nil {
+		xlog.Error("ack task failed", xlog.String("err", err.Error()), xlog.Int("taskId", int(task.TaskID)))
 	}
+	// 确认之后才计为结束，退出时关闭队列前确认已经完成
+	t.finishTask()
 }
 
-func (t *TestWorker) startWork() {
-	for i := 0; i < t.option.ParallelWorker; i++ {
-		go t.work()
+// handleTask 执行任务并上报结果，job 中发生 panic 时返回 *PanicError，由调用方决定重新入队还是标记失败
+func (t *TestWorker) handleTask(task view.TestTask) (perr *PanicError) {
+	// 以 Juno 下发任务时的 span 为父 span，后续的状态回调、job 都关联到该任务的 span
+	span, ctx := tracing.StartSpanFromCarrier(context.Background(), task.Trace, "testworker.runTask",
+		opentracing.Tag{Key: "task.id", Value: task.TaskID},
+		opentracing.Tag{Key: "app.name", Value: task.AppName})
+	task.Trace = tracing.Inject(ctx)
+
+	// 排队期间被同一提交的新任务取代的任务不再执行
+	code, msg := t.postTaskEvent(task, view.TaskUpdateEvent, view.TestTaskUpdateEventPayload{Status: db.TestTaskStatusRunning})
+	if code == output.MsgConflict {
+		xlog.Info("TestWorker: skip task", xlog.Int("taskId", int(task.TaskID)), xlog.String("reason", msg))
+		tracing.Finish(span, nil)
+		return
+	}
+
+	key := taskKey{TaskID: task.TaskID, Part: task.Part}
+	run := newTaskRun()
+	run.info = db.WorkerTask{
+		TaskID:    task.TaskID,
+		Part:      task.Part,
+		Name:      task.Name,
+		AppName:   task.AppName,
+		Branch:    task.Branch,
+		StartedAt: time.Now(),
+	}
+	t.runs.Store(key, run)
+	defer t.runs.Delete(key)
+
+	ws, err := newWorkspace(t.option.WorkspaceDir, task)
+	if err != nil {
+		t.notifyTaskUpdate(task, db.TestTaskStatusFailed, fmt.Sprintf("create workspace failed. err = %s", err.Error()))
+		tracing.Finish(span, err)
+		return
+	}
+	t.workspaces.Store(key, ws)
+	defer func() {
+		t.workspaces.Delete(key)
+		if err := ws.remove(); err != nil {
+			xlog.Error("remove workspace failed", xlog.String("dir", ws.dir), xlog.String("err", err.Error()))
+		}
+	}()
+
+	err = t.prepareToolchain(task)
+	if err != nil {
+		t.notifyTaskUpdate(task, db.TestTaskStatusFailed, fmt.Sprintf("prepare go toolchain failed. err = %s", err.Error()))
+		tracing.Finish(span, err)
+		return
+	}
+
+	err = t.loadSecrets(task, run)
+	if err != nil {
+		t.notifyTaskUpdate(task, db.TestTaskStatusFailed, fmt.Sprintf("load secrets failed. err = %s", err.Error()))
+		tracing.Finish(span, err)
+		return
 	}
+
+	err = t.runTask(task, task.Desc)
+	if errors.As(err, &perr) {
+		tracing.Finish(span, err)
+		return
+	}
+	if run.Interrupted() {
+		t.requeueInterrupted(task)
+	} else if run.Canceled() {
+		t.notifyTaskUpdate(task, db.TestTaskStatusCanceled, "task canceled, running commands killed\n")
+	} else if err != nil {
+		t.notifyTaskUpdate(task, db.TestTaskStatusFailed, fmt.Sprintf("task failed. err = %s", err.Error()))
+	} else if task.Part == 0 {
+		// 拆分下发的任务由 Juno 汇总全部阶段的状态得出结果
+		t.notifyTaskUpdate(task, db.TestTaskStatusSuccess, "")
+	}
+	tracing.Finish(span, err)
+	return
 }
 
-func (t *TestWorker) work() {
-	for {
-		task := <-t.taskChan
+// prepareToolchain 安装任务指定的 Go 版本，上报实际使用的版本
+func (t *TestWorker) prepareToolchain(task view.TestTask) error {
+	goroot, err := t.toolchains.GOROOT(task.Desc.GoVersion)
+	if err != nil {
+		return err
+	}
+	version, err := goVersion(goroot)
+	if err != nil {
+		return err
+	}
 
-		t.notifyTaskUpdate(task.TaskID, db.TestTaskStatusRunning, "")
+	t.notifyTaskEvent(task, view.TaskUpdateEvent, view.TestTaskUpdateEventPayload{
+		Status:     db.TestTaskStatusRunning,
+		LogsAppend: fmt.Sprintf("go version: %s\n", version),
+		GoVersion:  version,
+	})
+	return nil
+}
 
-		err := t.runTask(task, task.Desc)
-		if err != nil {
-			t.notifyTaskUpdate(task.TaskID, db.TestTaskStatusFailed, fmt.Sprintf("task failed. err = %s", err.Error()))
-		} else {
-			t.notifyTaskUpdate(task.TaskID, db.TestTaskStatusSuccess, "")
+// taskGOROOT 任务使用的 GOROOT，prepareToolchain 时已经安装。为空时使用 worker 默认的 go
+func (t *TestWorker) taskGOROOT(task view.TestTask) string {
+	version, _ := pipeline.NormalizeGoVersion(task.Desc.GoVersion)
+	if version == "" {
+		return ""
+	}
+	return t.toolchains.path(version)
+}
+
+// taskEnv 任务命令的环境变量：使用任务独立的工作目录，指定了 Go 版本时使用对应的 GOROOT，加上阶段设置的环境变量
+func (t *TestWorker) taskEnv(task view.TestTask) []string {
+	set := make(map[string]string)
+	pathPrefix := ""
+	if goroot := t.taskGOROOT(task); goroot != "" {
+		set, pathPrefix = goOverrides(goroot)
+	}
+	for key, value := range task.StepEnv {
+		set[key] = value
+	}
+	if ws, ok := t.workspaces.Load(taskKey{TaskID: task.TaskID, Part: task.Part}); ok {
+		for key, value := range ws.(*workspace).env() {
+			set[key] = value
 		}
 	}
+	if len(set) == 0 {
+		return nil
+	}
+	return mergeEnv(os.Environ(), set, pathPrefix)
+}
+
+// shellCommand 在任务的环境中执行 shell 命令
+func (t *TestWorker) shellCommand(task view.TestTask, commands ...string) *exec.Cmd {
+	cmd := exec.Command("sh", "-c", strings.Join(commands, " && "))
+	cmd.Env = t.taskEnv(task)
+	return cmd
+}
+
+// goCommand 在任务的环境中使用任务的 Go 版本执行 go 命令，不经过 shell
+func (t *TestWorker) goCommand(task view.TestTask, args ...string) *exec.Cmd {
+	cmd := exec.Command(goBinary(t.taskGOROOT(task)), args...)
+	cmd.Env = t.taskEnv(task)
+	return cmd
 }
 
 func (t *TestWorker) runTask(task view.TestTask, desc db.TestPipelineDesc) (err error) {
@@ -179,12 +403,19 @@ func (t *TestWorker) runTask(task view.TestTask, desc db.TestPipelineDesc) (err
 	for _, step := range desc.Steps {
 		if desc.Parallel {
 			_step := step
-			eg.Go(func() error {
+			eg.Go(func() (err error) {
+				// errgroup 的 goroutine 中 panic 无法在外层 recover
+				defer func() {
+					if r := recover(); r != nil {
+						err = newPanicError(task, r)
+					}
+				}()
 				return t.runStep(task, _step)
 			})
 		} else {
 			err = t.runStep(task, step)
-			if err != nil {
+			// 任务取消后继续遍历，剩余的阶段标记为跳过
+			if err != nil && !t.taskRun(task).Canceled() {
 				xlog.Error("TestWorker.runTask failed, stop running", xlog.String("err", err.Error()))
 				break
 			}
@@ -203,13 +434,32 @@ func (t *TestWorker) runTask(task view.TestTask, desc db.TestPipelineDesc) (err
 }
 
 func (t *TestWorker) runStep(task view.TestTask, step db.TestPipelineStep) (err error) {
+	run, err := pipeline.EvalWhen(step.When, pipeline.WhenVars{Branch: task.Branch, Event: task.Event, Env: task.Env})
+	if err != nil {
+		t.notifyStepStatus(task, step.Name, db.TestStepStatusFailed, fmt.Sprintf("invalid step condition. err = %s\n", err.Error()))
+		return
+	}
+	if !run {
+		t.notifyStepStatus(task, step.Name, db.TestStepStatusSkipped, fmt.Sprintf("condition not met: %s\n", step.When))
+		return nil
+	}
+
 	switch step.Type {
 	case db.StepTypeJob:
 		if step.JobPayload == nil {
 			return fmt.Errorf("platform.JobPayload = nil when step.Type = StepTypeJob. step = %v", step)
 		}
 
-		err = t.runJob(task, step.Name, step.JobPayload)
+		task.StepEnv, err = t.stepEnv(task, step)
+		if err != nil {
+			t.notifyStepStatus(task, step.Name, db.TestStepStatusFailed, fmt.Sprintf("resolve step env failed. err = %s\n", err.Error()))
+			return
+		}
+
+		err = t.retryStep(task, step, func(task view.TestTask) error {
+			return t.runJob(task, step.Name, step.TimeoutSeconds, step.JobPayload)
+		})
+		t.uploadStepArtifacts(task, step)
 		if err != nil {
 			return
 		}
@@ -228,11 +478,23 @@ func (t *TestWorker) runStep(task view.TestTask, step db.TestPipelineStep) (err
 	return
 }
 
-func (t *TestWorker) runJob(task view.TestTask, name string, payload *db.TestJobPayload) (err error) {
+func (t *TestWorker) runJob(task view.TestTask, name string, timeoutSeconds int, payload *db.TestJobPayload) (err error) {
+	if t.taskRun(task).Canceled() {
+		t.notifyStepStatus(task, name, db.TestStepStatusSkipped, "task canceled\n")
+		return ErrTaskCanceled
+	}
+
 	handler, ok := t.jobHandlers[payload.Type]
 	if ok {
-		t.notifyProgress(task.TaskID, name, db.TestTaskStatusRunning, progressStart, "")
-		err = handler(task, name, payload.Payload)
+		span, ctx := tracing.StartSpanFromCarrier(context.Background(), task.Trace, "testworker.runJob",
+			opentracing.Tag{Key: "job.name", Value: name},
+			opentracing.Tag{Key: "job.type", Value: string(payload.Type)})
+		task.Trace = tracing.Inject(ctx)
+		task.StepDeadline = time.Now().Add(t.stepTimeout(timeoutSeconds))
+
+		t.notifyProgress(task, name, db.TestTaskStatusRunning, progressStart, "")
+		err = t.callJob(handler, task, name, payload.Payload)
+		tracing.Finish(span, err)
 
 		if err != nil {
 			xlog.Error("runJob failed", xlog.String("err", err.Error()))
@@ -244,22 +506,47 @@ func (t *TestWorker) runJob(task view.TestTask, name string, payload *db.TestJob
 	return
 }
 
-func (t *TestWorker) notifyTaskEvent(taskId uint, event view.TestTaskEventType, data interface{}) {
-	req := t.client.R()
+// stepTimeout 阶段的超时时间，阶段没有设置时使用 worker 的默认值
+func (t *TestWorker) stepTimeout(timeoutSeconds int) time.Duration {
+	if timeoutSeconds > 0 {
+		return time.Duration(timeoutSeconds) * time.Second
+	}
+	return t.option.StepTimeout
+}
 
+func (t *TestWorker) notifyTaskEvent(task view.TestTask, event view.TestTaskEventType, data interface{}) {
+	code, _ := t.postTaskEvent(task, event, data)
+	// 任务已经在 Juno 上取消，没有收到取消请求时在这里终止
+	if code == output.MsgConflict {
+		t.Cancel(task.TaskID)
+	}
+}
+
+// postTaskEvent 上报任务事件，返回 Juno 响应的错误码，请求失败时错误码为 MsgErr。
+// 开启 LogStream 时阶段状态和日志通过 WebSocket 长连接上报，连接不可用时使用 HTTP
+func (t *TestWorker) postTaskEvent(task view.TestTask, event view.TestTaskEventType, data interface{}) (code int, msg string) {
 	eventData, _ := json.Marshal(data)
 	body := view.TestTaskEvent{
 		Type:   event,
-		TaskID: taskId,
+		TaskID: task.TaskID,
 		Data:   eventData,
 	}
 
+	if t.stream != nil && event == view.TaskStepUpdateEvent {
+		code, msg, err := t.stream.send(body)
+		if err == nil {
+			return code, msg
+		}
+		xlog.Warn("TestWorker: send event by stream failed, use http", xlog.String("err", err.Error()))
+	}
+
+	req := t.client.R().SetHeaders(task.Trace)
 	req.SetBody(body)
 
 	resp, err := req.Post("/api/v1/worker/testTask/update")
 	if err != nil {
 		log.Error("TestWorker.notifyStepStatus", xlog.String("err", err.Error()))
-		return
+		return output.MsgErr, err.Error()
 	}
 
 	respObj := struct {
@@ -269,29 +556,38 @@ func (t *TestWorker) notifyTaskEvent(taskId uint, event view.TestTaskEventType,
 	err = json.Unmarshal(resp.Body(), &respObj)
 	if err != nil {
 		log.Error("TestWorker: json unmarshall failed", xlog.String("err", err.Error()))
-		return
+		return output.MsgErr, err.Error()
 	}
 
+	return respObj.Code, respObj.Msg
 }
 
-func (t *TestWorker) notifyTaskUpdate(taskId uint, status db.TestTaskStatus, logsAppend string) {
-	t.notifyTaskEvent(taskId, view.TaskUpdateEvent, view.TestTaskUpdateEventPayload{
+func (t *TestWorker) notifyTaskUpdate(task view.TestTask, status db.TestTaskStatus, logsAppend string) {
+	t.notifyTaskEvent(task, view.TaskUpdateEvent, view.TestTaskUpdateEventPayload{
 		Status:     status,
-		LogsAppend: logsAppend,
+		LogsAppend: t.taskRun(task).mask(logsAppend),
 	})
 }
 
-func (t *TestWorker) notifyStepStatus(taskId uint, stepName string, status db.TestStepStatus, logsAppend string) {
+func (t *TestWorker) notifyStepStatus(task view.TestTask, stepName string, status db.TestStepStatus, logsAppend string) {
+	// 还会重试时不上报失败，避免 Juno 在重试之前结束任务
+	if status == db.TestStepStatusFailed && task.StepRetryPending {
+		status = db.TestStepStatusRunning
+	}
 	data := view.TestTaskStepUpdatePayload{
 		StepName:   stepName,
 		Status:     status,
-		LogsAppend: logsAppend,
+		LogsAppend: t.taskRun(task).mask(logsAppend),
 	}
 
-	t.notifyTaskEvent(taskId, view.TaskStepUpdateEvent, data)
+	t.notifyTaskEvent(task, view.TaskStepUpdateEvent, data)
 }
 
+// codeBaseDir 代码目录，拆分下发的任务各部分使用独立的目录，避免同一 worker 上的分片同时拉取代码
 func (t *TestWorker) codeBaseDir(task view.TestTask) string {
+	if task.Part > 0 {
+		return filepath.Join(t.option.RepoStorageDir, task.AppName, fmt.Sprintf("%s@part-%d", task.Branch, task.Part))
+	}
 	return filepath.Join(t.option.RepoStorageDir, task.AppName, task.Branch)
 }
 
@@ -302,10 +598,10 @@ func (t *TestWorker) gitPull(task view.TestTask, name string, p json.RawMessage)
 	defer func() {
 		if err != nil {
 			// failed
-			t.notifyStepStatus(task.TaskID, name, db.TestStepStatusFailed, fmt.Sprintf("%s\nerr = %s", progress, err.Error()))
+			t.notifyStepStatus(task, name, db.TestStepStatusFailed, fmt.Sprintf("%s\nerr = %s", progress, err.Error()))
 		} else {
 			// success
-			t.notifyStepStatus(task.TaskID, name, db.TestStepStatusSuccess, progress)
+			t.notifyStepStatus(task, name, db.TestStepStatusSuccess, progress)
 		}
 	}()
 
@@ -329,17 +625,23 @@ func (t *TestWorker) gitPull(task view.TestTask, name string, p json.RawMessage)
 
 func (t *TestWorker) unitTest(task view.TestTask, name string, p json.RawMessage) (err error) {
 	var payload pipeline.JobUnitTestPayload
-	printer := NewPrinter(128)
+	printer := t.newPrinter()
+	collector := NewTestCollector()
+	coverProfile := filepath.Join(os.TempDir(), fmt.Sprintf("juno-cover-%d-%s.out", task.TaskID, name))
 
 	defer func() {
+		// 先上传报告再更新阶段状态，任务结束时报告已经可以查看
+		t.uploadReport(task, name, payload, collector, coverProfile)
+		_ = os.Remove(coverProfile)
+
 		logs := printer.Flush()
 
 		if err != nil {
-			t.notifyStepStatus(task.TaskID, name, db.TestStepStatusFailed, string(logs))
-			t.notifyProgress(task.TaskID, name, db.TestTaskStatusFailed, progressFailed, err.Error())
+			t.notifyStepStatus(task, name, db.TestStepStatusFailed, string(logs))
+			t.notifyProgress(task, name, db.TestTaskStatusFailed, progressFailed, err.Error())
 		} else {
-			t.notifyStepStatus(task.TaskID, name, db.TestTaskStatusSuccess, string(logs))
-			t.notifyProgress(task.TaskID, name, db.TestTaskStatusFailed, progressSuccess, "")
+			t.notifyStepStatus(task, name, db.TestTaskStatusSuccess, string(logs))
+			t.notifyProgress(task, name, db.TestTaskStatusFailed, progressSuccess, "")
 		}
 	}()
 
@@ -353,51 +655,240 @@ func (t *TestWorker) unitTest(task view.TestTask, name string, p json.RawMessage
 		return errors.Wrapf(err, "invalid GitUrl")
 	}
 
+	gitConfig := gitCredentialConfig(gitUrlParsed.Host, payload.AccessToken)
+	packages := "./..."
+	if payload.ShardTotal > 1 || payload.AffectedBase != "" {
+		var selected []string
+		selected, err = t.testPackages(task, gitConfig, payload, printer)
+		if err != nil {
+			return
+		}
+		if len(selected) == 0 {
+			_, _ = printer.Write([]byte("no packages to test\n"))
+			return nil
+		}
+		packages = strings.Join(selected, " ")
+	}
+
+	flags := "-v -json"
+	if payload.RaceDetector {
+		flags += " -race"
+	}
 	cmdArray := []string{
-		fmt.Sprintf("git config --global url.\"https://juno:%s@%s/\".insteadOf \"https://%s/\"", payload.AccessToken, gitUrlParsed.Host, gitUrlParsed.Host),
+		gitConfig,
 		fmt.Sprintf("cd %s", t.codeBaseDir(task)),
-		"go test -v -json ./...",
+		fmt.Sprintf("go test %s -coverprofile=%s %s", flags, coverProfile, packages),
 	}
-	cmd := exec.Command("sh", "-c", strings.Join(cmdArray, " && "))
-	cmd.Stdout = printer
-	cmd.Stderr = printer
+	cmd := t.shellCommand(task, cmdArray...)
+	output := io.MultiWriter(printer, collector)
+	cmd.Stdout = output
+	cmd.Stderr = output
 	finishChan := make(chan error, 1)
-	timer := time.NewTimer(5 * time.Minute)
 
 	go func() {
-		finishChan <- cmd.Run()
-		_ = exec.Command(fmt.Sprintf("git config --global --remove-section url.\"https://juno:%s@%s/\"", payload.AccessToken, gitUrlParsed.Host)).Run()
+		// 凭证写在任务独立的 HOME 中，随工作目录删除。超时后进程被终止，保留被终止前的输出
+		finishChan <- t.runCmd(task, cmd)
 	}()
 
 	for {
 		select {
 		case logs := <-printer.C:
-			fmt.Printf("\n-> printer logs: %s\n", logs)
-			t.notifyStepStatus(task.TaskID, name, db.TestStepStatusRunning, logs)
+			t.notifyStepStatus(task, name, db.TestStepStatusRunning, logs)
 
-		case <-timer.C: // timeout
-			close(finishChan)
-			err = cmd.Process.Kill()
-			if err != nil {
-				err = errors.Wrap(err, "unitTest process kill failed")
-				return
+		case err = <-finishChan:
+			if err == ErrStepTimeout {
+				return fmt.Errorf("unitTest process timeout. killed")
+			}
+			// 数据竞争的报告比 exit status 更能说明失败原因
+			if races := collector.Races(); err != nil && err != ErrTaskCanceled && len(races) > 0 {
+				return raceFailure(races)
 			}
+			return
+		}
+	}
+}
 
-			return fmt.Errorf("unitTest process timeout. killed")
+// gitCredentialConfig 设置访问代码平台的凭证，go 命令下载同一代码平台上的私有依赖时使用。
+// 写入任务独立 HOME 下的 .gitconfig，不影响其他任务
+func gitCredentialConfig(host, accessToken string) string {
+	return fmt.Sprintf("git config --global url.\"https://juno:%s@%s/\".insteadOf \"https://%s/\"", accessToken, host, host)
+}
 
-		case err = <-finishChan:
-			return
+// testPackages 需要执行测试的包，开启影响分析时只保留变更影响的包，分片时只保留分到当前分片的包。
+// 影响分析失败时执行全部测试
+func (t *TestWorker) testPackages(task view.TestTask, gitConfig string, payload pipeline.JobUnitTestPayload, printer io.Writer) (packages []string, err error) {
+	dir, err := filepath.Abs(t.codeBaseDir(task))
+	if err != nil {
+		return
+	}
+
+	cmd := t.shellCommand(task,
+		gitConfig,
+		fmt.Sprintf("cd %s", dir),
+		"go list -json ./...",
+	)
+	out, err := t.cmdOutput(task, cmd)
+	if err != nil {
+		return nil, errors.Wrap(err, "go list failed")
+	}
+	list, err := ParseGoList(bytes.NewReader(out))
+	if err != nil {
+		return nil, errors.Wrap(err, "parse go list output failed")
+	}
+	for _, pkg := range list {
+		packages = append(packages, pkg.ImportPath)
+	}
+
+	if payload.AffectedBase != "" {
+		changed, diffErr := changedFiles(dir, payload.AffectedBase, t.taskEnv(task))
+		if diffErr != nil {
+			_, _ = fmt.Fprintf(printer, "affected analysis failed, run all tests: %s\n", diffErr.Error())
+		} else if affected, full := AffectedPackages(list, changed); full {
+			_, _ = fmt.Fprintf(printer, "go.mod or go.sum changed since %s, run all tests\n", payload.AffectedBase)
+		} else {
+			_, _ = fmt.Fprintf(printer, "%d files changed since %s, %d of %d packages affected\n",
+				len(changed), payload.AffectedBase, len(affected), len(packages))
+			packages = affected
 		}
 	}
+
+	if payload.ShardTotal > 1 {
+		packages = ShardPackages(packages, payload.Timings, payload.Shard, payload.ShardTotal)
+	}
+	return
 }
 
-func (t *TestWorker) notifyProgress(taskId uint, stepName string, status db.TestStepStatus, progressType progressType, msg string) {
+// changedFiles 当前代码相对 base 分支最新提交变更的文件，返回绝对路径。
+// 代码是浅克隆，没有共同祖先，直接比较两个提交的文件，base 分支上的新变更也会计入，结果只会多不会少。
+// env 为任务的环境变量，fetch 使用其中 HOME 下配置的凭证
+func changedFiles(dir, base string, env []string) (files []string, err error) {
+	if strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("invalid base branch: %s", base)
+	}
+
+	fetch := exec.Command("git", "-C", dir, "fetch", "--depth=1", "origin", base)
+	fetch.Env = env
+	err = fetch.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetch %s failed", base)
+	}
+	diff := exec.Command("git", "-C", dir, "diff", "--name-only", "FETCH_HEAD", "HEAD")
+	diff.Env = env
+	out, err := diff.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "git diff failed")
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, filepath.Join(dir, line))
+		}
+	}
+	return
+}
+
+// uploadReport 生成 HTML 测试报告并作为制品上传，没有覆盖率文件时报告中不展示覆盖率。
+// 同时上报覆盖率用于覆盖率趋势，上报各个包的耗时用于下次分片
+func (t *TestWorker) uploadReport(task view.TestTask, stepName string, payload pipeline.JobUnitTestPayload, collector *TestCollector, coverProfile string) {
+	var coverage *CoverageProfile
+	file, err := os.Open(coverProfile)
+	if err == nil {
+		coverage, err = ParseCoverProfile(file)
+		_ = file.Close()
+		if err != nil {
+			xlog.Error("TestWorker.uploadReport parse cover profile failed", xlog.String("err", err.Error()))
+		}
+	}
+
+	title := fmt.Sprintf("%s %s 单元测试报告 #%d", task.AppName, task.Branch, task.TaskID)
+	artifactName := ReportArtifactName
+	if payload.ShardTotal > 1 {
+		title += fmt.Sprintf(" 分片 %d/%d", payload.Shard, payload.ShardTotal)
+		artifactName = fmt.Sprintf("report-shard-%d.html", payload.Shard)
+	}
+
+	if coverage != nil {
+		var coverageReport string
+		if payload.CoverageReport {
+			coverageReport = t.uploadCoverage(task, stepName, payload, coverProfile)
+		}
+		t.notifyTaskEvent(task, view.TaskCoverageEvent, view.TestTaskCoveragePayload{
+			StepName:   stepName,
+			Statements: coverage.Statements,
+			Covered:    coverage.Covered,
+			Partial:    payload.AffectedBase != "",
+			Packages:   coverage.Packages(),
+			Report:     coverageReport,
+		})
+	}
+
+	report := collector.Report(title, coverage)
+	if len(report.Packages) > 0 {
+		timings := make(map[string]float64, len(report.Packages))
+		for _, pkg := range report.Packages {
+			timings[pkg.Name] = pkg.Elapsed
+		}
+		t.notifyTaskEvent(task, view.TaskTestTimingEvent, view.TestTaskTimingPayload{Packages: timings})
+	}
+
+	content, err := RenderReport(report)
+	if err != nil {
+		xlog.Error("TestWorker.uploadReport render failed", xlog.String("err", err.Error()))
+		return
+	}
+
+	t.notifyArtifact(task, stepName, artifactName, "text/html; charset=utf-8", content)
+}
+
+// uploadCoverage 上传覆盖率文件和 go tool cover 生成的 HTML 覆盖率报告，返回报告的制品名，生成失败时返回空字符串
+func (t *TestWorker) uploadCoverage(task view.TestTask, stepName string, payload pipeline.JobUnitTestPayload, coverProfile string) string {
+	profileName, reportName := CoverProfileArtifactName, CoverReportArtifactName
+	if payload.ShardTotal > 1 {
+		profileName = fmt.Sprintf("coverage-shard-%d.out", payload.Shard)
+		reportName = fmt.Sprintf("coverage-shard-%d.html", payload.Shard)
+	}
+
+	profile, err := ioutil.ReadFile(coverProfile)
+	if err != nil {
+		xlog.Error("TestWorker.uploadCoverage read cover profile failed", xlog.String("err", err.Error()))
+		return ""
+	}
+	t.notifyArtifact(task, stepName, profileName, "text/plain; charset=utf-8", profile)
+
+	// go tool cover 需要读取源码，在代码目录中执行
+	htmlFile := strings.TrimSuffix(coverProfile, ".out") + ".html"
+	defer os.Remove(htmlFile)
+	cmd := t.goCommand(task, "tool", "cover", "-html="+coverProfile, "-o", htmlFile)
+	cmd.Dir = t.codeBaseDir(task)
+	out, err := t.cmdCombinedOutput(task, cmd)
+	if err != nil {
+		xlog.Error("TestWorker.uploadCoverage go tool cover failed",
+			xlog.String("err", err.Error()), xlog.String("output", string(out)))
+		return ""
+	}
+	content, err := ioutil.ReadFile(htmlFile)
+	if err != nil {
+		xlog.Error("TestWorker.uploadCoverage read coverage report failed", xlog.String("err", err.Error()))
+		return ""
+	}
+	t.notifyArtifact(task, stepName, reportName, "text/html; charset=utf-8", content)
+	return reportName
+}
+
+func (t *TestWorker) notifyArtifact(task view.TestTask, stepName, name, contentType string, content []byte) {
+	err := t.uploadArtifact(task, stepName, name, contentType, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		xlog.Error("TestWorker.notifyArtifact failed", xlog.String("artifact", name), xlog.String("err", err.Error()))
+	}
+}
+
+func (t *TestWorker) notifyProgress(task view.TestTask, stepName string, status db.TestStepStatus, progressType progressType, msg string) {
 	logs, _ := json.Marshal(ProgressLog{
 		ProgressLog: true,
 		Type:        progressType,
-		Msg:         msg,
+		Msg:         t.taskRun(task).mask(msg),
 	})
-	t.notifyStepStatus(taskId, stepName, status, string(logs)+"\n")
+	t.notifyStepStatus(task, stepName, status, string(logs)+"\n")
 }
 
 func (t *TestWorker) codeCheck(task view.TestTask, name string, p json.RawMessage) error {
@@ -410,14 +901,457 @@ func (t *TestWorker) codeCheck(task view.TestTask, name string, p json.RawMessag
 		problemBytes, _ := json.Marshal(problem)
 		logs += string(problemBytes) + "\n"
 	}
-	t.notifyStepStatus(task.TaskID, name, db.TestStepStatusRunning, logs)
+	t.notifyStepStatus(task, name, db.TestStepStatusRunning, logs)
+
+	if err != nil {
+		t.notifyProgress(task, name, db.TestStepStatusFailed, progressFailed, err.Error())
+	} else {
+		t.notifyProgress(task, name, db.TestStepStatusSuccess, progressSuccess, "")
+	}
+
+	return nil
+}
+
+// vulnCheck 使用 govulncheck 扫描依赖漏洞，上报扫描结果，存在达到拦截级别的漏洞时阶段失败
+func (t *TestWorker) vulnCheck(task view.TestTask, name string, p json.RawMessage) (err error) {
+	var payload pipeline.JobVulnCheckPayload
+	var logs strings.Builder
+
+	defer func() {
+		if err != nil {
+			t.notifyStepStatus(task, name, db.TestStepStatusFailed, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusFailed, progressFailed, err.Error())
+		} else {
+			t.notifyStepStatus(task, name, db.TestStepStatusSuccess, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusSuccess, progressSuccess, "")
+		}
+	}()
+
+	err = json.Unmarshal(p, &payload)
+	if err != nil {
+		return errors.Wrapf(err, "unmarshall payload into pipeline.JobVulnCheckPayload failed. err = %s", err.Error())
+	}
+
+	gitUrlParsed, err := url.Parse(task.GitUrl)
+	if err != nil {
+		return errors.Wrapf(err, "invalid GitUrl")
+	}
+
+	cmd := t.shellCommand(task,
+		gitCredentialConfig(gitUrlParsed.Host, payload.AccessToken),
+		fmt.Sprintf("cd %s", t.codeBaseDir(task)),
+		"govulncheck -json ./...",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := t.cmdOutput(task, cmd)
+	if err != nil {
+		logs.Write(stderr.Bytes())
+		return errors.Wrap(err, "govulncheck failed")
+	}
+
+	vulns, err := ParseGovulncheck(bytes.NewReader(out))
+	if err != nil {
+		return errors.Wrap(err, "parse govulncheck output failed")
+	}
+	t.notifyTaskEvent(task, view.TaskVulnerabilityEvent, view.TestTaskVulnerabilityPayload{
+		StepName:        name,
+		Vulnerabilities: vulns,
+	})
+
+	blocked := 0
+	for _, v := range vulns {
+		logs.WriteString(formatVulnerability(v))
+		if VulnBlocked(v, payload.Gate) {
+			blocked++
+		}
+	}
+	fmt.Fprintf(&logs, "%d vulnerabilities found\n", len(vulns))
+
+	if blocked > 0 {
+		return fmt.Errorf("%d called vulnerabilities at or above %s", blocked, payload.Gate)
+	}
+	return nil
+}
+
+// licenseCheck 识别依赖模块的许可证并按策略检查，上传 SBOM。策略为 fail 且存在违规时阶段失败，否则只提示
+func (t *TestWorker) licenseCheck(task view.TestTask, name string, p json.RawMessage) (err error) {
+	var payload pipeline.JobLicenseCheckPayload
+	var logs strings.Builder
+
+	defer func() {
+		if err != nil {
+			t.notifyStepStatus(task, name, db.TestStepStatusFailed, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusFailed, progressFailed, err.Error())
+		} else {
+			t.notifyStepStatus(task, name, db.TestStepStatusSuccess, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusSuccess, progressSuccess, "")
+		}
+	}()
+
+	err = json.Unmarshal(p, &payload)
+	if err != nil {
+		return errors.Wrapf(err, "unmarshall payload into pipeline.JobLicenseCheckPayload failed. err = %s", err.Error())
+	}
+
+	gitUrlParsed, err := url.Parse(task.GitUrl)
+	if err != nil {
+		return errors.Wrapf(err, "invalid GitUrl")
+	}
+
+	// 先下载全部模块，go list 才会返回模块目录
+	cmd := t.shellCommand(task,
+		gitCredentialConfig(gitUrlParsed.Host, payload.AccessToken),
+		fmt.Sprintf("cd %s", t.codeBaseDir(task)),
+		"go mod download all",
+		"go list -m -json all",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := t.cmdOutput(task, cmd)
+	if err != nil {
+		logs.Write(stderr.Bytes())
+		return errors.Wrap(err, "go list modules failed")
+	}
+
+	modules, err := ParseGoListModules(bytes.NewReader(out))
+	if err != nil {
+		return errors.Wrap(err, "parse go list output failed")
+	}
+
+	licenses := ScanLicenses(modules, payload.Policy)
+	content, _ := json.MarshalIndent(BuildSBOM(task.AppName, task.Branch, licenses, time.Now()), "", "  ")
+	t.notifyArtifact(task, name, SBOMArtifactName, "application/json", content)
+
+	violations := 0
+	for _, l := range licenses {
+		logs.WriteString(formatModuleLicense(l))
+		if l.Violation != "" {
+			violations++
+		}
+	}
+	fmt.Fprintf(&logs, "%d modules scanned, %d license violations\n", len(licenses), violations)
+
+	if violations > 0 && payload.Policy.Action == view.LicenseActionFail {
+		return fmt.Errorf("%d modules violate the license policy", violations)
+	}
+	return nil
+}
+
+// buildReport 构建服务二进制，上报大小、构建耗时和占用最大的包
+func (t *TestWorker) buildReport(task view.TestTask, name string, p json.RawMessage) (err error) {
+	var payload pipeline.JobBuildReportPayload
+	var logs strings.Builder
+
+	defer func() {
+		if err != nil {
+			t.notifyStepStatus(task, name, db.TestStepStatusFailed, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusFailed, progressFailed, err.Error())
+		} else {
+			t.notifyStepStatus(task, name, db.TestStepStatusSuccess, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusSuccess, progressSuccess, "")
+		}
+	}()
+
+	err = json.Unmarshal(p, &payload)
+	if err != nil {
+		return errors.Wrapf(err, "unmarshall payload into pipeline.JobBuildReportPayload failed. err = %s", err.Error())
+	}
+
+	gitUrlParsed, err := url.Parse(task.GitUrl)
+	if err != nil {
+		return errors.Wrapf(err, "invalid GitUrl")
+	}
+
+	dir, err := filepath.Abs(t.codeBaseDir(task))
+	if err != nil {
+		return
+	}
+
+	err = t.runCmd(task, t.shellCommand(task, gitCredentialConfig(gitUrlParsed.Host, payload.AccessToken)))
+	if err != nil {
+		return errors.Wrap(err, "set git credential failed")
+	}
+
+	pkg := payload.Package
+	if pkg == "" {
+		cmd := t.goCommand(task, "list", "-json", "./...")
+		cmd.Dir = dir
+		var out []byte
+		out, err = t.cmdOutput(task, cmd)
+		if err != nil {
+			return errors.Wrap(err, "go list failed")
+		}
+		var list []GoPackage
+		list, err = ParseGoList(bytes.NewReader(out))
+		if err != nil {
+			return errors.Wrap(err, "parse go list output failed")
+		}
+		pkg, err = MainPackage(list, dir)
+		if err != nil {
+			return
+		}
+	}
+	if strings.HasPrefix(pkg, "-") {
+		return fmt.Errorf("invalid build package: %s", pkg)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "juno-build")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+	binary := filepath.Join(tmpDir, "app")
+
+	// 不经过 shell 执行，构建包由用户配置
+	start := time.Now()
+	cmd := t.goCommand(task, "build", "-o", binary, pkg)
+	cmd.Dir = dir
+	out, err := t.cmdCombinedOutput(task, cmd)
+	buildSeconds := time.Since(start).Seconds()
+	if err != nil {
+		logs.Write(out)
+		return errors.Wrap(err, "go build failed")
+	}
 
+	info, err := os.Stat(binary)
 	if err != nil {
-		t.notifyProgress(task.TaskID, name, db.TestStepStatusFailed, progressFailed, err.Error())
+		return
+	}
+
+	out, err = t.cmdOutput(task, t.goCommand(task, "tool", "nm", "-size", "-sort", "size", binary))
+	if err != nil {
+		return errors.Wrap(err, "go tool nm failed")
+	}
+	sizes, err := ParseNmSizes(bytes.NewReader(out))
+	if err != nil {
+		return errors.Wrap(err, "parse go tool nm output failed")
+	}
+	packages := TopPackages(sizes, buildReportTopPackages)
+
+	t.notifyTaskEvent(task, view.TaskBuildReportEvent, view.TestTaskBuildReportPayload{
+		StepName:     name,
+		Package:      pkg,
+		BinarySize:   info.Size(),
+		BuildSeconds: buildSeconds,
+		Packages:     packages,
+	})
+
+	fmt.Fprintf(&logs, "package: %s\nbinary size: %s\nbuild time: %.1fs\nlargest packages:\n", pkg, formatSize(info.Size()), buildSeconds)
+	for _, item := range packages {
+		fmt.Fprintf(&logs, "  %-60s %s\n", item.Package, formatSize(item.Size))
+	}
+	return nil
+}
+
+// crossBuild 依次为每个目标平台执行 go build ./...，只检查能否编译，不执行测试。
+// 所有平台都会构建，最后汇总失败的平台
+func (t *TestWorker) crossBuild(task view.TestTask, name string, p json.RawMessage) (err error) {
+	var payload pipeline.JobCrossBuildPayload
+	var logs strings.Builder
+
+	defer func() {
+		if err != nil {
+			t.notifyStepStatus(task, name, db.TestStepStatusFailed, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusFailed, progressFailed, err.Error())
+		} else {
+			t.notifyStepStatus(task, name, db.TestStepStatusSuccess, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusSuccess, progressSuccess, "")
+		}
+	}()
+
+	err = json.Unmarshal(p, &payload)
+	if err != nil {
+		return errors.Wrapf(err, "unmarshall payload into pipeline.JobCrossBuildPayload failed. err = %s", err.Error())
+	}
+	if len(payload.Targets) == 0 {
+		return fmt.Errorf("no cross build target")
+	}
+
+	gitUrlParsed, err := url.Parse(task.GitUrl)
+	if err != nil {
+		return errors.Wrapf(err, "invalid GitUrl")
+	}
+
+	dir, err := filepath.Abs(t.codeBaseDir(task))
+	if err != nil {
+		return
+	}
+
+	err = t.runCmd(task, t.shellCommand(task, gitCredentialConfig(gitUrlParsed.Host, payload.AccessToken)))
+	if err != nil {
+		return errors.Wrap(err, "set git credential failed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "juno-cross-build")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var failed []string
+	for _, target := range payload.Targets {
+		// 输出到目录，./... 中只有一个 main 包时也不会在代码目录生成二进制
+		output := filepath.Join(tmpDir, target.GOOS+"_"+target.GOARCH) + string(filepath.Separator)
+		cmd := t.goCommand(task, "build", "-o", output, "./...")
+		cmd.Dir = dir
+		cmd.Env = crossBuildEnv(cmd.Env, target)
+
+		start := time.Now()
+		out, buildErr := t.cmdCombinedOutput(task, cmd)
+		if buildErr != nil {
+			failed = append(failed, target.String())
+			fmt.Fprintf(&logs, "=== %s FAIL (%.1fs)\n%s\n", target, time.Since(start).Seconds(), out)
+			continue
+		}
+		fmt.Fprintf(&logs, "=== %s ok (%.1fs)\n", target, time.Since(start).Seconds())
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("build failed on %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// buildCheck 在代码根目录执行编译命令，编译失败时阶段失败，错误信息中汇总编译错误。
+// 没有设置命令时执行 go build ./...，构建结果输出到临时目录
+func (t *TestWorker) buildCheck(task view.TestTask, name string, p json.RawMessage) (err error) {
+	var payload pipeline.JobBuildCheckPayload
+	var logs strings.Builder
+
+	defer func() {
+		if err != nil {
+			t.notifyStepStatus(task, name, db.TestStepStatusFailed, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusFailed, progressFailed, err.Error())
+		} else {
+			t.notifyStepStatus(task, name, db.TestStepStatusSuccess, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusSuccess, progressSuccess, "")
+		}
+	}()
+
+	err = json.Unmarshal(p, &payload)
+	if err != nil {
+		return errors.Wrapf(err, "unmarshall payload into pipeline.JobBuildCheckPayload failed. err = %s", err.Error())
+	}
+
+	gitUrlParsed, err := url.Parse(task.GitUrl)
+	if err != nil {
+		return errors.Wrapf(err, "invalid GitUrl")
+	}
+
+	dir, err := filepath.Abs(t.codeBaseDir(task))
+	if err != nil {
+		return
+	}
+
+	err = t.runCmd(task, t.shellCommand(task, gitCredentialConfig(gitUrlParsed.Host, payload.AccessToken)))
+	if err != nil {
+		return errors.Wrap(err, "set git credential failed")
+	}
+
+	var cmd *exec.Cmd
+	if payload.Command != "" {
+		cmd = t.shellCommand(task, payload.Command)
 	} else {
-		t.notifyProgress(task.TaskID, name, db.TestStepStatusSuccess, progressSuccess, "")
+		var tmpDir string
+		tmpDir, err = ioutil.TempDir("", "juno-build-check")
+		if err != nil {
+			return
+		}
+		defer os.RemoveAll(tmpDir)
+		cmd = t.goCommand(task, "build", "-o", tmpDir+string(filepath.Separator), "./...")
+	}
+	cmd.Dir = dir
+
+	start := time.Now()
+	out, buildErr := t.cmdCombinedOutput(task, cmd)
+	logs.Write(out)
+	if buildErr == ErrTaskCanceled || buildErr == ErrStepTimeout {
+		return buildErr
 	}
+	if buildErr != nil {
+		return buildFailure(out, buildErr)
+	}
+	fmt.Fprintf(&logs, "build ok (%.1fs)\n", time.Since(start).Seconds())
+	return nil
+}
+
+// benchmark 只执行基准测试，上报结果，并在日志中输出与同一分支上一次结果的对比。
+// 性能退化只在日志中标记，不影响阶段结果
+func (t *TestWorker) benchmark(task view.TestTask, name string, p json.RawMessage) (err error) {
+	var payload pipeline.JobBenchmarkPayload
+	var logs strings.Builder
 
+	defer func() {
+		if err != nil {
+			t.notifyStepStatus(task, name, db.TestStepStatusFailed, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusFailed, progressFailed, err.Error())
+		} else {
+			t.notifyStepStatus(task, name, db.TestStepStatusSuccess, logs.String())
+			t.notifyProgress(task, name, db.TestStepStatusSuccess, progressSuccess, "")
+		}
+	}()
+
+	err = json.Unmarshal(p, &payload)
+	if err != nil {
+		return errors.Wrapf(err, "unmarshall payload into pipeline.JobBenchmarkPayload failed. err = %s", err.Error())
+	}
+
+	gitUrlParsed, err := url.Parse(task.GitUrl)
+	if err != nil {
+		return errors.Wrapf(err, "invalid GitUrl")
+	}
+
+	dir, err := filepath.Abs(t.codeBaseDir(task))
+	if err != nil {
+		return
+	}
+
+	err = t.runCmd(task, t.shellCommand(task, gitCredentialConfig(gitUrlParsed.Host, payload.AccessToken)))
+	if err != nil {
+		return errors.Wrap(err, "set git credential failed")
+	}
+
+	// 不经过 shell 执行，包和参数由用户配置。-run ^$ 跳过单元测试，用户参数在后可以覆盖默认值
+	args := append([]string{"test", "-run", "^$", "-bench", ".", "-benchmem"}, payload.Flags...)
+	args = append(args, payload.Packages...)
+	cmd := t.goCommand(task, args...)
+	cmd.Dir = dir
+	out, err := t.cmdCombinedOutput(task, cmd)
+	logs.Write(out)
+	if err != nil {
+		return errors.Wrap(err, "go test -bench failed")
+	}
+
+	results, err := ParseBenchmarks(bytes.NewReader(out))
+	if err != nil {
+		return errors.Wrap(err, "parse benchmark output failed")
+	}
+	if len(results) == 0 {
+		_, _ = logs.WriteString("no benchmarks found\n")
+		return nil
+	}
+
+	var baseline db.BenchmarkResults
+	var baselineTaskID uint
+	if payload.Baseline != nil {
+		baseline = payload.Baseline.Results
+		baselineTaskID = payload.Baseline.TaskID
+		fmt.Fprintf(&logs, "\nbaseline: task %d, commit %s\n", payload.Baseline.TaskID, payload.Baseline.CommitSHA)
+	} else {
+		_, _ = logs.WriteString("\nno baseline, this run will be the baseline of the next run\n")
+	}
+	compared, regressions := pipeline.CompareBenchmarks(results, baseline, payload.Threshold)
+	writeBenchmarkReport(&logs, compared)
+	if regressions > 0 {
+		fmt.Fprintf(&logs, "%d benchmarks are more than %.0f%% slower than baseline\n", regressions, payload.Threshold*100)
+	}
+
+	t.notifyTaskEvent(task, view.TaskBenchmarkEvent, view.TestTaskBenchmarkPayload{
+		StepName:       name,
+		BaselineTaskID: baselineTaskID,
+		Results:        results,
+	})
 	return nil
 }
 
@@ -432,7 +1366,7 @@ func (t *TestWorker) httpTest(task view.TestTask, name string, p json.RawMessage
 
 	notifyStepProgress := func(log view.HttpCollectionTestLog) {
 		respBytes, _ := json.Marshal(log)
-		t.notifyStepStatus(task.TaskID, name, db.TestStepStatusRunning, string(respBytes)+"\n")
+		t.notifyStepStatus(task, name, db.TestStepStatusRunning, string(respBytes)+"\n")
 	}
 
 	tester := xtest.New(
@@ -505,9 +1439,9 @@ func (t *TestWorker) httpTest(task view.TestTask, name string, p json.RawMessage
 	}
 
 	if testSuccess {
-		t.notifyStepStatus(task.TaskID, name, db.TestStepStatusSuccess, "")
+		t.notifyStepStatus(task, name, db.TestStepStatusSuccess, "")
 	} else {
-		t.notifyStepStatus(task.TaskID, name, db.TestStepStatusFailed, "")
+		t.notifyStepStatus(task, name, db.TestStepStatusFailed, "")
 	}
 
 	return nil
@@ -522,7 +1456,7 @@ func (t *TestWorker) httpTest(task view.TestTask, name string, p json.RawMessage
 //		if log != nil {
 //			logContent, _ = json.Marshal(log)
 //		}
-//		t.notifyStepStatus(task.TaskID, name, status, string(logContent))
+//		t.notifyStepStatus(task, name, status, string(logContent))
 //	}
 //
 //	err = json.Unmarshal(p, &payload)
@@ -0,0 +1,110 @@
+package testworker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+type (
+	// workspace 任务独立的 HOME、GOPATH、构建缓存和临时目录，任务开始时创建、结束后删除。
+	// git 凭证写入 HOME 下的 .gitconfig，模块缓存和临时文件都不会在任务之间共享
+	workspace struct {
+		dir string
+	}
+
+	// taskKey 同一任务的不同部分可能在同一个 worker 上并发执行
+	taskKey struct {
+		TaskID uint
+		Part   int
+	}
+)
+
+var workspaceDirs = []string{"home", "gopath", "cache", "tmp"}
+
+func newWorkspace(root string, task view.TestTask) (ws *workspace, err error) {
+	if root != "" {
+		err = os.MkdirAll(root, 0755)
+		if err != nil {
+			return
+		}
+	}
+	dir, err := ioutil.TempDir(root, fmt.Sprintf("task-%d-", task.TaskID))
+	if err != nil {
+		return
+	}
+
+	ws = &workspace{dir: dir}
+	for _, name := range workspaceDirs {
+		err = os.Mkdir(filepath.Join(dir, name), 0700)
+		if err != nil {
+			_ = ws.remove()
+			return nil, err
+		}
+	}
+	return
+}
+
+// env 覆盖 worker 环境中与用户目录相关的变量
+func (w *workspace) env() map[string]string {
+	home := filepath.Join(w.dir, "home")
+	cache := filepath.Join(w.dir, "cache")
+	return map[string]string{
+		"HOME":            home,
+		"XDG_CONFIG_HOME": filepath.Join(home, ".config"),
+		"XDG_CACHE_HOME":  cache,
+		"GOPATH":          filepath.Join(w.dir, "gopath"),
+		"GOMODCACHE":      filepath.Join(w.dir, "gopath", "pkg", "mod"),
+		"GOCACHE":         filepath.Join(cache, "go-build"),
+		"TMPDIR":          filepath.Join(w.dir, "tmp"),
+	}
+}
+
+// remove 模块缓存中的文件是只读的，先恢复写权限再删除
+func (w *workspace) remove() error {
+	_ = filepath.Walk(w.dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() && info.Mode().Perm()&0700 != 0700 {
+			_ = os.Chmod(path, info.Mode().Perm()|0700)
+		}
+		return nil
+	})
+	return os.RemoveAll(w.dir)
+}
+
+// mergeEnv 在 base 的基础上设置 set 中的变量，pathPrefix 不为空时加在 PATH 最前
+func mergeEnv(base []string, set map[string]string, pathPrefix string) []string {
+	env := make([]string, 0, len(base)+len(set)+1)
+	hasPath := false
+	for _, kv := range base {
+		key := kv
+		if i := strings.Index(kv, "="); i >= 0 {
+			key = kv[:i]
+		}
+		if _, ok := set[key]; ok {
+			continue
+		}
+		if key == "PATH" && pathPrefix != "" {
+			kv = "PATH=" + pathPrefix + string(os.PathListSeparator) + strings.TrimPrefix(kv, "PATH=")
+			hasPath = true
+		}
+		env = append(env, kv)
+	}
+	if !hasPath && pathPrefix != "" {
+		env = append(env, "PATH="+pathPrefix)
+	}
+
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+set[key])
+	}
+	return env
+}
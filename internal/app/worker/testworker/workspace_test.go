@@ -0,0 +1,81 @@
+package testworker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func TestWorkspace(t *testing.T) {
+	root, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	a, err := newWorkspace(filepath.Join(root, "workspaces"), view.TestTask{TaskID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newWorkspace(filepath.Join(root, "workspaces"), view.TestTask{TaskID: 1, Part: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.dir == b.dir {
+		t.Fatal("workspaces of different parts should not share directory")
+	}
+
+	env := a.env()
+	for _, key := range []string{"HOME", "GOPATH", "GOMODCACHE", "GOCACHE", "TMPDIR"} {
+		if !strings.HasPrefix(env[key], a.dir+string(filepath.Separator)) {
+			t.Errorf("%s = %q, want inside %s", key, env[key], a.dir)
+		}
+	}
+	for _, key := range []string{"HOME", "GOPATH", "TMPDIR"} {
+		if _, err := os.Stat(env[key]); err != nil {
+			t.Errorf("%s not created: %v", key, err)
+		}
+	}
+
+	// 模拟 go mod download 留下的只读目录
+	modDir := filepath.Join(env["GOMODCACHE"], "example.com", "mod@v1.0.0")
+	if err := os.MkdirAll(modDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(modDir, "go.mod"), []byte("module example.com/mod\n"), 0444); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(modDir, 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(a.dir); !os.IsNotExist(err) {
+		t.Fatalf("workspace not removed: %v", err)
+	}
+	if _, err := os.Stat(b.dir); err != nil {
+		t.Fatalf("other workspace removed: %v", err)
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "HOME=/root", "LANG=C"}
+	env := mergeEnv(base, map[string]string{"HOME": "/ws/home", "GOPATH": "/ws/gopath"}, "/opt/go/bin")
+	want := []string{"PATH=/opt/go/bin" + string(os.PathListSeparator) + "/usr/bin", "LANG=C", "GOPATH=/ws/gopath", "HOME=/ws/home"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("mergeEnv() = %v, want %v", env, want)
+	}
+
+	env = mergeEnv([]string{"LANG=C"}, nil, "/opt/go/bin")
+	want = []string{"LANG=C", "PATH=/opt/go/bin"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("mergeEnv() without PATH = %v, want %v", env, want)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/douyu/juno/internal/app/worker/heartbeat"
 
 	"github.com/douyu/juno/internal/app/worker/cfg"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/douyu/jupiter"
 )
 
@@ -25,6 +26,7 @@ func New() *Worker {
 	err := w.Startup(
 		initLogger,
 		cfg.Init,
+		initTracing,
 		initWorker,
 		w.serveHttp,
 		heartbeat.Start,
@@ -43,7 +45,14 @@ func initWorker() error {
 		Token:          cfg.Cfg.Juno.Token,
 		ParallelWorker: cfg.Cfg.Worker.ParallelWorker,
 		RepoStorageDir: cfg.Cfg.Worker.RepoStorageDir,
-		QueueDir:       cfg.Cfg.Worker.TestTaskQueueDir,
+		MaxStepLogSize: cfg.Cfg.Worker.MaxStepLogSize,
+		GoToolchainDir: cfg.Cfg.Worker.GoToolchainDir,
+		GoDownloadURL:  cfg.Cfg.Worker.GoDownloadURL,
+		WorkspaceDir:   cfg.Cfg.Worker.WorkspaceDir,
+		StepTimeout:    cfg.Cfg.Worker.StepTimeout,
+		Queue:          cfg.Cfg.Worker.Queue,
+		Labels:         cfg.Cfg.Worker.Labels,
+		LogStream:      cfg.Cfg.Worker.LogStream,
 	})
 
 	return err
@@ -53,3 +62,8 @@ func initLogger() error {
 	xlog.DefaultLogger = xlog.StdConfig("default").Build()
 	return nil
 }
+
+func initTracing() error {
+	tracing.Init(cfg.Cfg.Trace, "juno-worker")
+	return nil
+}
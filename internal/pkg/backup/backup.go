@@ -0,0 +1,154 @@
+// Package backup 导出、恢复 Juno 的数据，用于灾备和复制环境。
+// 备份文件为 gzip 压缩的 JSON Lines，第一行为 Manifest，之后每行一条数据库记录或 etcd key。
+// 使用实例密钥加密的凭证导出时改用备份密钥加密，恢复时再用目标实例的密钥加密
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/migration"
+	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/util"
+	"github.com/jinzhu/gorm"
+)
+
+// FormatVersion 备份文件格式版本
+const FormatVersion = 1
+
+const (
+	KindTable = "table"
+	KindEtcd  = "etcd"
+)
+
+// keyCheckText 用于在恢复前校验备份密钥
+const keyCheckText = "juno-backup"
+
+var (
+	ErrNoBackupKey    = fmt.Errorf("备份中包含加密的凭证，需要指定备份密钥")
+	ErrBackupKey      = fmt.Errorf("备份密钥错误")
+	ErrFormatVersion  = fmt.Errorf("不支持的备份文件版本")
+	ErrDialect        = fmt.Errorf("备份与当前实例的数据库类型不一致")
+	ErrSchemaVersion  = fmt.Errorf("备份与当前实例的表结构版本不一致，请先执行 --migrate 到相同版本")
+	ErrMissingSecrets = fmt.Errorf("当前实例未配置凭证加密密钥，无法恢复加密的凭证")
+)
+
+type (
+	// Manifest 备份文件头
+	Manifest struct {
+		Version   int       `json:"version"`
+		Schema    int       `json:"schema"` // 已执行的迁移版本，恢复时必须一致
+		Dialect   string    `json:"dialect"`
+		CreatedAt time.Time `json:"created_at"`
+		// Tables 导出的全部表，包括空表，恢复时先清空这些表
+		Tables []string `json:"tables"`
+		// KeyCheck 备份密钥加密的 keyCheckText，未指定备份密钥时为空
+		KeyCheck string `json:"key_check,omitempty"`
+	}
+
+	// Record 一条数据库记录或 etcd key
+	Record struct {
+		Kind string `json:"kind"`
+		// KindTable
+		Table string                 `json:"table,omitempty"`
+		Row   map[string]interface{} `json:"row,omitempty"`
+		// KindEtcd，Zone 为 env.zone，单机房模式为空
+		Zone  string `json:"zone,omitempty"`
+		Key   string `json:"key,omitempty"`
+		Value string `json:"value,omitempty"`
+	}
+
+	// Option 备份、恢复使用的数据库、etcd 和备份密钥
+	Option struct {
+		DB *gorm.DB
+		// Key 备份密钥，备份中有加密的凭证时必须指定
+		Key string
+		// Etcd 导出、恢复的 etcd，为空时不处理 etcd
+		Etcd []*clientproxy.EtcdClient
+	}
+
+	// Summary 导出、恢复的数量
+	Summary struct {
+		Tables   int `json:"tables"`
+		Rows     int `json:"rows"`
+		EtcdKeys int `json:"etcd_keys"`
+		// Skipped 恢复时找不到对应机房而跳过的 etcd key
+		Skipped int `json:"skipped"`
+	}
+
+	// secretColumn 使用实例密钥加密的列
+	secretColumn struct {
+		Table  string
+		Column string
+		// Key 当前实例加密该列使用的密钥
+		Key func() string
+	}
+)
+
+// secretColumns 新增加密存储的列时需要在这里登记，否则恢复到其他实例后无法解密
+var secretColumns = []secretColumn{
+	{Table: "k8s_cluster", Column: "credential", Key: func() string { return cfg.Cfg.K8SCluster.SecretKey }},
+	{Table: "test_secret", Column: "value", Key: func() string { return cfg.Cfg.TestPlatform.SecretKey }},
+}
+
+// skipTables 不导出的表，迁移记录由目标实例自己维护
+var skipTables = map[string]bool{
+	migration.Record{}.TableName(): true,
+}
+
+// etcdPrefixes Juno 写入 etcd 的 key 前缀
+func etcdPrefixes() []string {
+	prefixes := []string{"/juno/"}
+	for _, prefix := range cfg.Cfg.Configure.Prefixes {
+		prefixes = append(prefixes, "/"+prefix+"/")
+	}
+	return prefixes
+}
+
+// ownedEtcdKey 是否为需要备份的 etcd key。
+// 定时任务的锁、进程、执行结果是运行时状态；配置前缀下只备份发布的配置，agent 上报的数据不备份
+func ownedEtcdKey(key string) bool {
+	for _, prefix := range []string{"/juno/cronjob/lock/", "/juno/cronjob/proc/", "/juno/cronjob/result/"} {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	if strings.HasPrefix(key, "/juno/") {
+		return true
+	}
+	return strings.Contains(key, "/static/")
+}
+
+// EtcdClients 各机房配置中心的 etcd，未连接成功的跳过
+func EtcdClients() []*clientproxy.EtcdClient {
+	var clients []*clientproxy.EtcdClient
+	for _, client := range clientproxy.ClientProxy.DefaultEtcdClients() {
+		if client != nil {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// zoneName etcd 所属机房，单机房模式为空
+func zoneName(client *clientproxy.EtcdClient) string {
+	if client.UniqueZone == nil {
+		return ""
+	}
+	return client.UniqueZone.String()
+}
+
+// reencrypt 将 from 加密的凭证改为 to 加密，空值不处理
+func reencrypt(value interface{}, from, to string) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return value, nil
+	}
+	plain, err := util.AESGCMDecrypt(s, from)
+	if err != nil {
+		return nil, err
+	}
+	return util.AESGCMEncrypt(plain, to)
+}
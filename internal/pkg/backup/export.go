@@ -0,0 +1,189 @@
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/douyu/juno/internal/pkg/migration"
+	"github.com/douyu/juno/pkg/sqldialect"
+	"github.com/douyu/juno/pkg/util"
+)
+
+// Export 导出数据库和 etcd 到 w。
+// 数据库在一个只读的可重复读事务中导出，所有表来自同一个快照
+func Export(ctx context.Context, o Option, w io.Writer) (summary Summary, err error) {
+	dialect := o.DB.Dialect().GetName()
+	schema, err := migration.Current(o.DB)
+	if err != nil {
+		return
+	}
+
+	manifest := Manifest{
+		Version:   FormatVersion,
+		Schema:    schema,
+		Dialect:   dialect,
+		CreatedAt: time.Now(),
+	}
+	if o.Key != "" {
+		if manifest.KeyCheck, err = util.AESGCMEncrypt(keyCheckText, o.Key); err != nil {
+			return
+		}
+	}
+
+	tx, err := o.DB.DB().BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	manifest.Tables, err = listTables(tx, dialect)
+	if err != nil {
+		return
+	}
+
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+	if err = enc.Encode(manifest); err != nil {
+		return
+	}
+	for _, table := range manifest.Tables {
+		var n int
+		n, err = exportTable(tx, o, dialect, table, enc)
+		if err != nil {
+			return summary, fmt.Errorf("export table %s: %w", table, err)
+		}
+		summary.Tables++
+		summary.Rows += n
+	}
+
+	for _, client := range o.Etcd {
+		var n int
+		n, err = exportEtcd(ctx, client.Conn(), zoneName(client), enc)
+		if err != nil {
+			return summary, fmt.Errorf("export etcd %s: %w", zoneName(client), err)
+		}
+		summary.EtcdKeys += n
+	}
+
+	err = gz.Close()
+	return
+}
+
+// listTables 当前库的全部表，按名称排序
+func listTables(tx *sql.Tx, dialect string) (tables []string, err error) {
+	query := "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'"
+	if dialect == sqldialect.Postgres {
+		query = "SELECT tablename FROM pg_tables WHERE schemaname = current_schema()"
+	}
+	rows, err := tx.Query(query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			return
+		}
+		if !skipTables[table] {
+			tables = append(tables, table)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+	sort.Strings(tables)
+	return
+}
+
+func exportTable(tx *sql.Tx, o Option, dialect string, table string, enc *json.Encoder) (n int, err error) {
+	secrets := tableSecrets(table)
+	rows, err := tx.Query("SELECT * FROM " + quote(dialect, table))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return
+	}
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = encodeValue(dialect, values[i])
+		}
+		for _, secret := range secrets {
+			if s, _ := row[secret.Column].(string); s != "" && o.Key == "" {
+				return n, ErrNoBackupKey
+			}
+			row[secret.Column], err = reencrypt(row[secret.Column], secret.Key(), o.Key)
+			if err != nil {
+				return n, fmt.Errorf("decrypt %s.%s: %w", table, secret.Column, err)
+			}
+		}
+		if err = enc.Encode(Record{Kind: KindTable, Table: table, Row: row}); err != nil {
+			return
+		}
+		n++
+	}
+	err = rows.Err()
+	return
+}
+
+func exportEtcd(ctx context.Context, client *clientv3.Client, zone string, enc *json.Encoder) (n int, err error) {
+	for _, prefix := range etcdPrefixes() {
+		var resp *clientv3.GetResponse
+		resp, err = client.Get(ctx, prefix, clientv3.WithPrefix())
+		if err != nil {
+			return
+		}
+		for _, kv := range resp.Kvs {
+			// 带租约的 key 由运行中的进程维护，恢复后会立即过期
+			if kv.Lease != 0 || !ownedEtcdKey(string(kv.Key)) {
+				continue
+			}
+			err = enc.Encode(Record{Kind: KindEtcd, Zone: zone, Key: string(kv.Key), Value: string(kv.Value)})
+			if err != nil {
+				return
+			}
+			n++
+		}
+	}
+	return
+}
+
+func tableSecrets(table string) (list []secretColumn) {
+	for _, secret := range secretColumns {
+		if secret.Table == table {
+			list = append(list, secret)
+		}
+	}
+	return
+}
+
+func quote(dialect string, name string) string {
+	if dialect == sqldialect.Postgres {
+		return `"` + name + `"`
+	}
+	return "`" + name + "`"
+}
@@ -0,0 +1,237 @@
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/migration"
+	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/pkg/sqldialect"
+	"github.com/douyu/juno/pkg/util"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+// insertBatch 每条 INSERT 语句写入的行数
+const insertBatch = 100
+
+// Restore 将 Export 的备份恢复到当前实例。
+// 目标实例需要与备份使用相同的数据库类型和迁移版本，备份中的表会先被清空，
+// 数据库在一个事务中恢复，成功后再写入 etcd
+func Restore(ctx context.Context, o Option, r io.Reader) (summary Summary, err error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return
+	}
+	defer gz.Close()
+
+	dec := json.NewDecoder(gz)
+	dec.UseNumber()
+
+	var manifest Manifest
+	if err = dec.Decode(&manifest); err != nil {
+		return
+	}
+	if err = checkManifest(o, manifest); err != nil {
+		return
+	}
+
+	tx := o.DB.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	for _, table := range manifest.Tables {
+		if err = tx.Exec("DELETE FROM " + quote(manifest.Dialect, table)).Error; err != nil {
+			return summary, fmt.Errorf("clear table %s: %w", table, err)
+		}
+	}
+	summary.Tables = len(manifest.Tables)
+
+	var (
+		batch []map[string]interface{}
+		table string
+		etcd  []Record
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := insertRows(tx, manifest.Dialect, table, batch)
+		summary.Rows += len(batch)
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		var record Record
+		err = dec.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return
+		}
+
+		switch record.Kind {
+		case KindTable:
+			if record.Table != table || len(batch) >= insertBatch {
+				if err = flush(); err != nil {
+					return summary, fmt.Errorf("restore table %s: %w", table, err)
+				}
+				table = record.Table
+			}
+			if err = restoreSecrets(o, record); err != nil {
+				return
+			}
+			batch = append(batch, record.Row)
+		case KindEtcd:
+			etcd = append(etcd, record)
+		default:
+			return summary, fmt.Errorf("unknown record kind %q", record.Kind)
+		}
+	}
+	if err = flush(); err != nil {
+		return summary, fmt.Errorf("restore table %s: %w", table, err)
+	}
+
+	if manifest.Dialect == sqldialect.Postgres {
+		if err = resetSequences(tx); err != nil {
+			return
+		}
+	}
+	if err = tx.Commit().Error; err != nil {
+		return
+	}
+
+	summary.EtcdKeys, summary.Skipped, err = restoreEtcd(ctx, o, etcd)
+	return
+}
+
+func checkManifest(o Option, manifest Manifest) error {
+	if manifest.Version != FormatVersion {
+		return ErrFormatVersion
+	}
+	if manifest.Dialect != o.DB.Dialect().GetName() {
+		return ErrDialect
+	}
+	schema, err := migration.Current(o.DB)
+	if err != nil {
+		return err
+	}
+	if schema != manifest.Schema {
+		return ErrSchemaVersion
+	}
+	if manifest.KeyCheck == "" {
+		return nil
+	}
+	if o.Key == "" {
+		return ErrNoBackupKey
+	}
+	if text, err := util.AESGCMDecrypt(manifest.KeyCheck, o.Key); err != nil || text != keyCheckText {
+		return ErrBackupKey
+	}
+	return nil
+}
+
+// restoreSecrets 凭证由备份密钥加密改为当前实例的密钥加密
+func restoreSecrets(o Option, record Record) (err error) {
+	for _, secret := range tableSecrets(record.Table) {
+		if s, _ := record.Row[secret.Column].(string); s == "" {
+			continue
+		}
+		if secret.Key() == "" {
+			return ErrMissingSecrets
+		}
+		record.Row[secret.Column], err = reencrypt(record.Row[secret.Column], o.Key, secret.Key())
+		if err != nil {
+			return fmt.Errorf("decrypt %s.%s: %w", record.Table, secret.Column, err)
+		}
+	}
+	return nil
+}
+
+// insertRows 同一张表的行写入一条 INSERT 语句，一张表的所有行列名相同
+func insertRows(tx *gorm.DB, dialect string, table string, rows []map[string]interface{}) error {
+	columns := make([]string, 0, len(rows[0]))
+	for column := range rows[0] {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quote(dialect, column)
+	}
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+
+	values := make([]string, 0, len(rows))
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for _, row := range rows {
+		values = append(values, placeholder)
+		for _, column := range columns {
+			value, err := decodeValue(row[column])
+			if err != nil {
+				return err
+			}
+			args = append(args, value)
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		quote(dialect, table), strings.Join(quoted, ","), strings.Join(values, ","))
+	return tx.Exec(query, args...).Error
+}
+
+// resetSequences PostgreSQL 写入指定 id 后自增序列不会变化，需要改为各表当前的最大值
+func resetSequences(tx *gorm.DB) error {
+	var columns []struct {
+		TableName  string
+		ColumnName string
+	}
+	err := tx.Raw("SELECT table_name, column_name FROM information_schema.columns " +
+		"WHERE table_schema = current_schema() AND column_default LIKE 'nextval(%'").Scan(&columns).Error
+	if err != nil {
+		return err
+	}
+	for _, c := range columns {
+		table := quote(sqldialect.Postgres, c.TableName)
+		column := quote(sqldialect.Postgres, c.ColumnName)
+		err = tx.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)",
+			table, c.ColumnName, column, table)).Error
+		if err != nil {
+			return fmt.Errorf("reset sequence of %s.%s: %w", c.TableName, c.ColumnName, err)
+		}
+	}
+	return nil
+}
+
+// restoreEtcd 按机房写入 etcd，当前实例没有对应机房的 key 跳过
+func restoreEtcd(ctx context.Context, o Option, records []Record) (n, skipped int, err error) {
+	clients := make(map[string]*clientproxy.EtcdClient, len(o.Etcd))
+	for _, client := range o.Etcd {
+		clients[zoneName(client)] = client
+	}
+
+	for _, record := range records {
+		client, ok := clients[record.Zone]
+		if !ok {
+			skipped++
+			continue
+		}
+		if _, err = client.Put(ctx, record.Key, record.Value); err != nil {
+			return n, skipped, fmt.Errorf("put etcd key %s: %w", record.Key, err)
+		}
+		n++
+	}
+	if skipped > 0 {
+		xlog.Warn("backup: skip etcd keys of unknown zones", xlog.Int("skipped", skipped))
+	}
+	return
+}
@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+
+	"github.com/douyu/juno/pkg/sqldialect"
+)
+
+// binaryKey 非 UTF-8 的二进制列编码为 {"$binary": base64}
+const binaryKey = "$binary"
+
+// encodeValue 数据库驱动返回的列值转为可 JSON 编码的值。
+// 时间按数据库的字面量格式输出，恢复时由数据库按列类型解析
+func encodeValue(dialect string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return map[string]interface{}{binaryKey: base64.StdEncoding.EncodeToString(v)}
+	case time.Time:
+		if dialect == sqldialect.Postgres {
+			return v.Format("2006-01-02 15:04:05.999999Z07:00")
+		}
+		return v.Format("2006-01-02 15:04:05.999999")
+	}
+	return value
+}
+
+// decodeValue encodeValue 的逆过程，数字保留原始文本交给数据库转换
+func decodeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String(), nil
+	case map[string]interface{}:
+		if s, ok := v[binaryKey].(string); ok && len(v) == 1 {
+			return base64.StdEncoding.DecodeString(s)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,92 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/sqldialect"
+	"github.com/douyu/juno/pkg/util"
+)
+
+func TestValueRoundTrip(t *testing.T) {
+	at := time.Date(2021, 3, 4, 5, 6, 7, 800000000, time.FixedZone("CST", 8*3600))
+	cases := []struct {
+		dialect string
+		in      interface{}
+		want    interface{}
+	}{
+		{sqldialect.MySQL, nil, nil},
+		{sqldialect.MySQL, []byte("juno"), "juno"},
+		{sqldialect.MySQL, []byte{0xff, 0x00}, []byte{0xff, 0x00}},
+		{sqldialect.MySQL, int64(42), "42"},
+		{sqldialect.MySQL, at, "2021-03-04 05:06:07.8"},
+		{sqldialect.Postgres, at, "2021-03-04 05:06:07.8+08:00"},
+		{sqldialect.Postgres, true, true},
+	}
+
+	for _, c := range cases {
+		buf, err := json.Marshal(encodeValue(c.dialect, c.in))
+		if err != nil {
+			t.Fatalf("marshal %v: %v", c.in, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.UseNumber()
+		var v interface{}
+		if err = dec.Decode(&v); err != nil {
+			t.Fatalf("decode %s: %v", buf, err)
+		}
+		got, err := decodeValue(v)
+		if err != nil {
+			t.Fatalf("decodeValue %s: %v", buf, err)
+		}
+		if b, ok := c.want.([]byte); ok {
+			if !bytes.Equal(got.([]byte), b) {
+				t.Errorf("%v: got %v, want %v", c.in, got, b)
+			}
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%v: got %#v, want %#v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestOwnedEtcdKey(t *testing.T) {
+	cases := map[string]bool{
+		"/juno/agent/config/host-1":                              true,
+		"/juno/cronjob/job/1":                                    true,
+		"/juno/cronjob/lock/1//2":                                false,
+		"/juno/cronjob/result/1/2":                               false,
+		"/juno-agent/host-1/app/dev/static/config.toml/8080":     true,
+		"/juno-agent/cluster/app/dev/static/config.toml":         true,
+		"/juno-agent/host-1/app/dev/status/config.toml/8080/pid": false,
+	}
+	for key, want := range cases {
+		if got := ownedEtcdKey(key); got != want {
+			t.Errorf("ownedEtcdKey(%s) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestReencrypt(t *testing.T) {
+	encrypted, err := util.AESGCMEncrypt("token", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := reencrypt(encrypted, "source", "backup")
+	if err != nil {
+		t.Fatalf("reencrypt: %v", err)
+	}
+	if plain, err := util.AESGCMDecrypt(v.(string), "backup"); err != nil || plain != "token" {
+		t.Fatalf("decrypt with backup key = %q, %v", plain, err)
+	}
+	if _, err = reencrypt(v, "source", "target"); err == nil {
+		t.Fatal("reencrypt with wrong key should fail")
+	}
+
+	if v, err = reencrypt("", "source", "backup"); err != nil || v != "" {
+		t.Fatalf("reencrypt empty = %v, %v", v, err)
+	}
+}
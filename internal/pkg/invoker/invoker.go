@@ -20,14 +20,17 @@ import (
 	rocketmq2 "github.com/apache/rocketmq-client-go"
 	"github.com/coreos/etcd/clientv3"
 	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/sqldialect"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/douyu/jupiter/pkg/client/rocketmq"
 	"github.com/douyu/jupiter/pkg/store/gorm"
 	"github.com/douyu/jupiter/pkg/util/xtime"
 	"github.com/go-resty/resty/v2"
+	jgorm "github.com/jinzhu/gorm"
 )
 
 var (
-	// JunoMysql mysql instance
+	// JunoMysql 元数据库实例，按 cfg.Database.Dialect 连接 MySQL 或 PostgreSQL
 	JunoMysql *gorm.DB
 	// ConfgoEtcd for configuration
 	ConfgoEtcd *clientv3.Client
@@ -41,9 +44,7 @@ var (
 // Init invoker
 func Init() {
 	if cfg.Cfg.Database.Enable {
-		gormConfig := gorm.DefaultConfig()
-		gormConfig.DSN = cfg.Cfg.Database.DSN
-		JunoMysql = gormConfig.Build()
+		JunoMysql = openDatabase(cfg.Cfg.Database)
 		JunoMysql.LogMode(cfg.Cfg.Database.Debug)
 		JunoMysql.SingularTable(true)
 	}
@@ -60,7 +61,7 @@ func Init() {
 			panic(err.Error())
 		}
 	}
-	Resty = resty.New().SetDebug(true).SetHeader("Content-Type", "application/json").SetTimeout(xtime.Duration("20s"))
+	Resty = tracing.WrapResty(resty.New().SetDebug(true).SetHeader("Content-Type", "application/json").SetTimeout(xtime.Duration("20s")))
 
 	if cfg.Cfg.JunoEvent.Rocketmq.Enable {
 		config := cfg.Cfg.JunoEvent.Rocketmq
@@ -77,3 +78,25 @@ func Init() {
 		}
 	}
 }
+
+// openDatabase MySQL 使用 jupiter 的 gorm 组件，带监控和链路追踪；
+// jupiter 只支持 MySQL 的 DSN，PostgreSQL 直接使用 gorm 连接，再注册相同的回调
+func openDatabase(c cfg.Database) *gorm.DB {
+	if c.Dialect != sqldialect.Postgres {
+		gormConfig := gorm.DefaultConfig()
+		gormConfig.DSN = c.DSN
+		return gormConfig.Build()
+	}
+
+	db, err := jgorm.Open(sqldialect.Postgres, c.DSN)
+	if err != nil {
+		panic("open postgres failed. err=" + err.Error())
+	}
+	db.DB().SetMaxIdleConns(c.MaxIdleConns)
+	db.DB().SetMaxOpenConns(c.MaxOpenConns)
+	if c.ConnMaxLifetime != 0 {
+		db.DB().SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+	registerCallbacks(db, c.DSN)
+	return db
+}
@@ -0,0 +1,321 @@
+// Package migration 版本化的数据库迁移。
+// 每个版本分别为 MySQL、PostgreSQL 提供 Up、Down 两组 SQL，已执行的版本记录在 schema_migration 表中
+package migration
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/sqldialect"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+// baselineTable 判断库是否由旧版本的 --install 创建
+const baselineTable = "app"
+
+type (
+	// Migration 一次表结构变更
+	Migration struct {
+		Version  int
+		Name     string
+		MySQL    Script
+		Postgres Script
+	}
+
+	// Script 一种数据库下的变更语句。MySQL 的 DDL 会隐式提交，
+	// 执行到一半失败时已执行的语句不会回滚，需要手动修复后重试
+	Script struct {
+		Up   []string
+		Down []string
+	}
+
+	// Record 已执行的版本
+	Record struct {
+		Version   int       `gorm:"column:version;primary_key;auto_increment:false"`
+		Name      string    `gorm:"column:name"`
+		AppliedAt time.Time `gorm:"column:applied_at"`
+	}
+
+	// Status 版本及其执行状态
+	Status struct {
+		Version   int
+		Name      string
+		AppliedAt *time.Time
+	}
+)
+
+func (Record) TableName() string {
+	return "schema_migration"
+}
+
+// Script 当前数据库对应的变更语句
+func (m Migration) Script(db *gorm.DB) (Script, error) {
+	switch name := db.Dialect().GetName(); name {
+	case sqldialect.MySQL:
+		return m.MySQL, nil
+	case sqldialect.Postgres:
+		return m.Postgres, nil
+	default:
+		return Script{}, fmt.Errorf("migration: unsupported dialect %s", name)
+	}
+}
+
+var migrations []Migration
+
+func register(m Migration) {
+	for _, item := range migrations {
+		if item.Version == m.Version {
+			panic(fmt.Sprintf("migration: duplicate version %d", m.Version))
+		}
+	}
+	migrations = append(migrations, m)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
+
+// List 全部迁移，按版本升序
+func List() []Migration {
+	return migrations
+}
+
+// Latest 最新版本
+func Latest() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Up 执行未执行过的迁移直到 target 版本，target <= 0 时执行到最新版本
+func Up(db *gorm.DB, target int) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+	if target <= 0 {
+		target = Latest()
+	}
+
+	for _, m := range migrations {
+		if m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		script, err := m.Script(db)
+		if err != nil {
+			return err
+		}
+		if err := exec(db, m, script.Up); err != nil {
+			return err
+		}
+		err = db.Create(&Record{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		if err != nil {
+			return fmt.Errorf("migration %d %s: save record: %w", m.Version, m.Name, err)
+		}
+		xlog.Info("migration up", xlog.Int("version", m.Version), xlog.String("name", m.Name))
+	}
+	return nil
+}
+
+// Down 按版本倒序回滚 target 之后已执行的迁移，target 为 0 时回滚全部
+func Down(db *gorm.DB, target int) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version <= target {
+			break
+		}
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		script, err := m.Script(db)
+		if err != nil {
+			return err
+		}
+		if err := exec(db, m, script.Down); err != nil {
+			return err
+		}
+		err = db.Where("version = ?", m.Version).Delete(&Record{}).Error
+		if err != nil {
+			return fmt.Errorf("migration %d %s: delete record: %w", m.Version, m.Name, err)
+		}
+		xlog.Info("migration down", xlog.Int("version", m.Version), xlog.String("name", m.Name))
+	}
+	return nil
+}
+
+// Current 已执行的最大版本，未执行过任何迁移时为 0
+func Current(db *gorm.DB) (int, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// Statuses 全部迁移的执行状态
+func Statuses(db *gorm.DB) ([]Status, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		status := Status{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		list = append(list, status)
+	}
+	return list, nil
+}
+
+// Run 执行命令行指定的操作并输出各版本的执行状态。up 执行到最新版本，up:N 执行到版本 N，
+// down 回滚最近的一个版本，down:N 回滚到版本 N（N 为 0 时回滚全部），status 只输出状态
+func Run(db *gorm.DB, command string, w io.Writer) (err error) {
+	action, target, err := parseCommand(command)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		err = Up(db, target)
+	case "down":
+		if target < 0 {
+			target, err = previous(db)
+			if err != nil {
+				return err
+			}
+		}
+		err = Down(db, target)
+	}
+	if err != nil {
+		return err
+	}
+
+	list, err := Statuses(db)
+	if err != nil {
+		return err
+	}
+	for _, status := range list {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		_, _ = fmt.Fprintf(w, "%6d  %-32s %s\n", status.Version, status.Name, appliedAt)
+	}
+	return nil
+}
+
+// parseCommand target 为 -1 表示未指定版本
+func parseCommand(command string) (action string, target int, err error) {
+	action, target = command, -1
+	if i := strings.IndexByte(command, ':'); i >= 0 {
+		action = command[:i]
+		target, err = strconv.Atoi(command[i+1:])
+		if err != nil || target < 0 {
+			return "", 0, fmt.Errorf("migration: invalid version in %q", command)
+		}
+	}
+
+	switch action {
+	case "up", "down":
+	case "status":
+		if target >= 0 {
+			return "", 0, fmt.Errorf("migration: status takes no version")
+		}
+	default:
+		return "", 0, fmt.Errorf("migration: unknown command %q, expect up, up:N, down, down:N or status", command)
+	}
+	return action, target, nil
+}
+
+// previous 当前版本的前一个已执行版本
+func previous(db *gorm.DB) (int, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return 0, err
+	}
+	return previousOf(applied), nil
+}
+
+func previousOf(applied map[int]Record) int {
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	if len(versions) < 2 {
+		return 0
+	}
+	return versions[len(versions)-2]
+}
+
+func exec(db *gorm.DB, m Migration, statements []string) error {
+	for i, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("migration %d %s: statement %d: %w", m.Version, m.Name, i+1, err)
+		}
+	}
+	return nil
+}
+
+// appliedVersions 读取已执行的版本。
+// 旧版本通过 AutoMigrate 建好的库没有 schema_migration 表，视为已执行 v1
+func appliedVersions(db *gorm.DB) (map[int]Record, error) {
+	if !db.HasTable(&Record{}) {
+		baseline := db.HasTable(baselineTable)
+		create := "CREATE TABLE schema_migration (" +
+			"version int NOT NULL," +
+			"name varchar(255) NOT NULL," +
+			"applied_at %s NOT NULL," +
+			"PRIMARY KEY (version)" +
+			")"
+		if db.Dialect().GetName() == sqldialect.Postgres {
+			create = fmt.Sprintf(create, "timestamp with time zone")
+		} else {
+			create = fmt.Sprintf(create, "datetime") + " ENGINE=InnoDB"
+		}
+		if err := db.Exec(create).Error; err != nil {
+			return nil, fmt.Errorf("migration: create schema_migration: %w", err)
+		}
+		if baseline && len(migrations) > 0 {
+			m := migrations[0]
+			err := db.Create(&Record{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+			if err != nil {
+				return nil, fmt.Errorf("migration: save baseline: %w", err)
+			}
+			xlog.Info("migration baseline", xlog.Int("version", m.Version), xlog.String("name", m.Name))
+		}
+	}
+
+	var records []Record
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
@@ -0,0 +1,104 @@
+package migration
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/douyu/juno/pkg/sqldialect"
+)
+
+func TestParseCommand(t *testing.T) {
+	cases := []struct {
+		command string
+		action  string
+		target  int
+		err     bool
+	}{
+		{"up", "up", -1, false},
+		{"up:3", "up", 3, false},
+		{"down", "down", -1, false},
+		{"down:0", "down", 0, false},
+		{"status", "status", -1, false},
+		{"status:1", "", 0, true},
+		{"down:-1", "", 0, true},
+		{"up:latest", "", 0, true},
+		{"redo", "", 0, true},
+	}
+	for _, c := range cases {
+		action, target, err := parseCommand(c.command)
+		if (err != nil) != c.err || action != c.action || target != c.target {
+			t.Errorf("parseCommand(%q) = %q %d %v", c.command, action, target, err)
+		}
+	}
+}
+
+func TestPreviousOf(t *testing.T) {
+	cases := []struct {
+		applied []int
+		want    int
+	}{
+		{nil, 0},
+		{[]int{1}, 0},
+		{[]int{3, 1, 2}, 2},
+		{[]int{1, 5}, 1},
+	}
+	for _, c := range cases {
+		applied := make(map[int]Record)
+		for _, version := range c.applied {
+			applied[version] = Record{Version: version}
+		}
+		if got := previousOf(applied); got != c.want {
+			t.Errorf("previousOf(%v) = %d, want %d", c.applied, got, c.want)
+		}
+	}
+}
+
+// TestMigrations Down 需要删除 Up 创建的全部表
+func TestMigrations(t *testing.T) {
+	if len(List()) == 0 || List()[0].Version != 1 {
+		t.Fatal("v1 not registered")
+	}
+
+	for i, m := range List() {
+		if i > 0 && List()[i-1].Version >= m.Version {
+			t.Errorf("versions not sorted: %d before %d", List()[i-1].Version, m.Version)
+		}
+		if m.Name == "" {
+			t.Errorf("migration %d has no name", m.Version)
+		}
+		checkScript(t, m.Version, sqldialect.MySQL, m.MySQL)
+		checkScript(t, m.Version, sqldialect.Postgres, m.Postgres)
+	}
+}
+
+var (
+	createTable = regexp.MustCompile("^CREATE TABLE [`\"]?([a-z_0-9]+)")
+	dropTable   = regexp.MustCompile("^DROP TABLE IF EXISTS [`\"]?([a-z_0-9]+)")
+	createIndex = regexp.MustCompile("^CREATE (?:UNIQUE )?INDEX ([a-z_0-9]+)")
+)
+
+func checkScript(t *testing.T, version int, dialect string, script Script) {
+	if len(script.Up) == 0 || len(script.Down) == 0 {
+		t.Errorf("migration %d: %s script is incomplete", version, dialect)
+	}
+
+	dropped := make(map[string]bool)
+	for _, statement := range script.Down {
+		if match := dropTable.FindStringSubmatch(statement); match != nil {
+			dropped[match[1]] = true
+		}
+	}
+	indexes := make(map[string]bool)
+	for _, statement := range script.Up {
+		if match := createTable.FindStringSubmatch(statement); match != nil && !dropped[match[1]] {
+			t.Errorf("migration %d: %s table %s is created but not dropped", version, dialect, match[1])
+		}
+		// PostgreSQL 的索引名在 schema 内唯一
+		if match := createIndex.FindStringSubmatch(statement); match != nil && dialect == sqldialect.Postgres {
+			if indexes[match[1]] {
+				t.Errorf("migration %d: %s index %s is duplicated", version, dialect, match[1])
+			}
+			indexes[match[1]] = true
+		}
+	}
+}
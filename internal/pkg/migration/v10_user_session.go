@@ -0,0 +1,53 @@
+package migration
+
+// v10 服务端登录会话
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "user_session",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `user_session` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`session_id` varchar(64)," +
+					"`uid` int," +
+					"`user_agent` varchar(512)," +
+					"`client_ip` varchar(64)," +
+					"`last_active_at` DATETIME NULL," +
+					"`expires_at` DATETIME NULL," +
+					"`revoked_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_user_session_uid ON `user_session`(`uid`)",
+				"CREATE INDEX idx_user_session_revoked_at ON `user_session`(revoked_at)",
+				"CREATE UNIQUE INDEX uix_user_session_session_id ON `user_session`(session_id)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `user_session`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE user_session (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"session_id varchar(64)," +
+					"uid integer," +
+					"user_agent varchar(512)," +
+					"client_ip varchar(64)," +
+					"last_active_at timestamp with time zone," +
+					"expires_at timestamp with time zone," +
+					"revoked_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_user_session_revoked_at ON user_session (revoked_at)",
+				"CREATE INDEX idx_user_session_uid ON user_session (uid)",
+				"CREATE UNIQUE INDEX uix_user_session_session_id ON user_session (session_id)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS user_session",
+			},
+		},
+	})
+}
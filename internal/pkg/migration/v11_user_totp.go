@@ -0,0 +1,53 @@
+package migration
+
+// v11 TOTP 两步验证
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "user_totp",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `user_totp` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`uid` int," +
+					"`secret` varchar(64)," +
+					"`enabled` boolean," +
+					"`enabled_at` DATETIME NULL," +
+					"`last_step` bigint," +
+					"`backup_codes` text," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_user_totp_deleted_at ON `user_totp`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_totp_uid ON `user_totp`(`uid`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `user_totp`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE user_totp (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"uid integer," +
+					"secret varchar(64)," +
+					"enabled boolean," +
+					"enabled_at timestamp with time zone," +
+					"last_step bigint," +
+					"backup_codes text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_user_totp_deleted_at ON user_totp (deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_totp_uid ON user_totp (uid)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS user_totp",
+			},
+		},
+	})
+}
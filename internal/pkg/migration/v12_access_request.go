@@ -0,0 +1,67 @@
+package migration
+
+// v12 应用权限申请
+func init() {
+	register(Migration{
+		Version: 12,
+		Name:    "access_request",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `access_request` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`uid` int," +
+					"`app_name` varchar(128)," +
+					"`env` varchar(64)," +
+					"`actions` varchar(512)," +
+					"`reason` varchar(512)," +
+					"`status` varchar(16)," +
+					"`reviewer_uid` int," +
+					"`review_comment` varchar(512)," +
+					"`reviewed_at` DATETIME NULL," +
+					"`expires_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_access_request_app_name ON `access_request`(app_name)",
+				"CREATE INDEX idx_access_request_status ON `access_request`(`status`)",
+				"CREATE INDEX idx_access_request_expires_at ON `access_request`(expires_at)",
+				"CREATE INDEX idx_access_request_deleted_at ON `access_request`(deleted_at)",
+				"CREATE INDEX idx_access_request_uid ON `access_request`(`uid`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `access_request`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE access_request (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"uid integer," +
+					"app_name varchar(128)," +
+					"env varchar(64)," +
+					"actions varchar(512)," +
+					"reason varchar(512)," +
+					"status varchar(16)," +
+					"reviewer_uid integer," +
+					"review_comment varchar(512)," +
+					"reviewed_at timestamp with time zone," +
+					"expires_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_access_request_uid ON access_request (uid)",
+				"CREATE INDEX idx_access_request_app_name ON access_request (app_name)",
+				"CREATE INDEX idx_access_request_status ON access_request (status)",
+				"CREATE INDEX idx_access_request_expires_at ON access_request (expires_at)",
+				"CREATE INDEX idx_access_request_deleted_at ON access_request (deleted_at)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS access_request",
+			},
+		},
+	})
+}
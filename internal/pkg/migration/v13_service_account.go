@@ -0,0 +1,87 @@
+package migration
+
+// v13 服务账号及其凭证
+func init() {
+	register(Migration{
+		Version: 13,
+		Name:    "service_account",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `service_account` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`name` varchar(64)," +
+					"`description` varchar(255)," +
+					"`scopes` varchar(255)," +
+					"`disabled` boolean," +
+					"`created_by` int," +
+					"`last_used_at` DATETIME NULL," +
+					"`last_used_ip` varchar(64)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_service_account_deleted_at ON `service_account`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_service_account_name ON `service_account`(`name`)",
+				"CREATE TABLE `service_account_credential` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`account_id` int unsigned," +
+					"`prefix` varchar(16)," +
+					"`token_hash` varchar(64)," +
+					"`expires_at` DATETIME NULL," +
+					"`revoked_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_service_account_credential_deleted_at ON `service_account_credential`(deleted_at)",
+				"CREATE INDEX idx_service_account_credential_account_id ON `service_account_credential`(account_id)",
+				"CREATE UNIQUE INDEX uix_service_account_credential_token_hash ON `service_account_credential`(token_hash)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `service_account_credential`",
+				"DROP TABLE IF EXISTS `service_account`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE service_account (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"name varchar(64)," +
+					"description varchar(255)," +
+					"scopes varchar(255)," +
+					"disabled boolean," +
+					"created_by integer," +
+					"last_used_at timestamp with time zone," +
+					"last_used_ip varchar(64)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_service_account_deleted_at ON service_account (deleted_at)",
+				"CREATE UNIQUE INDEX uix_service_account_name ON service_account (name)",
+				"CREATE TABLE service_account_credential (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"account_id integer," +
+					"prefix varchar(16)," +
+					"token_hash varchar(64)," +
+					"expires_at timestamp with time zone," +
+					"revoked_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_service_account_credential_deleted_at ON service_account_credential (deleted_at)",
+				"CREATE INDEX idx_service_account_credential_account_id ON service_account_credential (account_id)",
+				"CREATE UNIQUE INDEX uix_service_account_credential_token_hash ON service_account_credential (token_hash)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS service_account_credential",
+				"DROP TABLE IF EXISTS service_account",
+			},
+		},
+	})
+}
@@ -0,0 +1,75 @@
+package migration
+
+// v14 历史密码和登录锁定
+func init() {
+	register(Migration{
+		Version: 14,
+		Name:    "password_policy",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `user_password_history` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`uid` int," +
+					"`password` varchar(128)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_user_password_history_deleted_at ON `user_password_history`(deleted_at)",
+				"CREATE INDEX idx_user_password_history_uid ON `user_password_history`(`uid`)",
+				"CREATE TABLE `user_login_lock` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`username` varchar(64)," +
+					"`failures` int," +
+					"`last_failed_at` DATETIME NULL," +
+					"`last_failed_ip` varchar(64)," +
+					"`locked_until` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_user_login_lock_deleted_at ON `user_login_lock`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_login_lock_username ON `user_login_lock`(`username`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `user_login_lock`",
+				"DROP TABLE IF EXISTS `user_password_history`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE user_password_history (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"uid integer," +
+					"password varchar(128)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_user_password_history_uid ON user_password_history (uid)",
+				"CREATE INDEX idx_user_password_history_deleted_at ON user_password_history (deleted_at)",
+				"CREATE TABLE user_login_lock (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"username varchar(64)," +
+					"failures integer," +
+					"last_failed_at timestamp with time zone," +
+					"last_failed_ip varchar(64)," +
+					"locked_until timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_user_login_lock_deleted_at ON user_login_lock (deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_login_lock_username ON user_login_lock (username)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS user_login_lock",
+				"DROP TABLE IF EXISTS user_password_history",
+			},
+		},
+	})
+}
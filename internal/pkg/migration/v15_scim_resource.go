@@ -0,0 +1,49 @@
+package migration
+
+// v15 SCIM 资源的外部标识
+func init() {
+	register(Migration{
+		Version: 15,
+		Name:    "scim_resource",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `scim_resource` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`resource_type` varchar(16)," +
+					"`resource_id` int unsigned," +
+					"`external_id` varchar(255)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_scim_resource_deleted_at ON `scim_resource`(deleted_at)",
+				"CREATE INDEX idx_scim_resource_external_id ON `scim_resource`(external_id)",
+				"CREATE UNIQUE INDEX idx_scim_resource ON `scim_resource`(resource_type, resource_id)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `scim_resource`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE scim_resource (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"resource_type varchar(16)," +
+					"resource_id integer," +
+					"external_id varchar(255)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_scim_resource_deleted_at ON scim_resource (deleted_at)",
+				"CREATE INDEX idx_scim_resource_external_id ON scim_resource (external_id)",
+				"CREATE UNIQUE INDEX idx_scim_resource ON scim_resource (resource_type,resource_id)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS scim_resource",
+			},
+		},
+	})
+}
@@ -0,0 +1,47 @@
+package migration
+
+// v16 用户、团队可访问的可用区
+func init() {
+	register(Migration{
+		Version: 16,
+		Name:    "zone_scope",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `zone_scope` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`subject_type` varchar(16)," +
+					"`subject_id` int unsigned," +
+					"`zone_code` varchar(64)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_zone_scope_deleted_at ON `zone_scope`(deleted_at)",
+				"CREATE UNIQUE INDEX idx_zone_scope ON `zone_scope`(subject_type, subject_id, zone_code)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `zone_scope`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE zone_scope (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"subject_type varchar(16)," +
+					"subject_id integer," +
+					"zone_code varchar(64)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_zone_scope_deleted_at ON zone_scope (deleted_at)",
+				"CREATE UNIQUE INDEX idx_zone_scope ON zone_scope (subject_type,subject_id,zone_code)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS zone_scope",
+			},
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package migration
+
+// v17 用户接收通知的邮箱
+func init() {
+	register(Migration{
+		Version: 17,
+		Name:    "user_notify_email",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `user_notify_email` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`uid` int," +
+					"`email` varchar(255)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_user_notify_email_deleted_at ON `user_notify_email`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_notify_email_uid ON `user_notify_email`(`uid`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `user_notify_email`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE user_notify_email (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"uid integer," +
+					"email varchar(255)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_user_notify_email_deleted_at ON user_notify_email (deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_notify_email_uid ON user_notify_email (uid)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS user_notify_email",
+			},
+		},
+	})
+}
@@ -0,0 +1,67 @@
+package migration
+
+// v18 通知路由规则
+func init() {
+	register(Migration{
+		Version: 18,
+		Name:    "notify_rule",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `notify_rule` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`name` varchar(64)," +
+					"`priority` int," +
+					"`enable` boolean," +
+					"`event_types` varchar(255)," +
+					"`apps` varchar(1024)," +
+					"`envs` varchar(255)," +
+					"`min_severity` varchar(16)," +
+					"`channels` varchar(255)," +
+					"`recipients` varchar(1024)," +
+					"`quiet_start` varchar(8)," +
+					"`quiet_end` varchar(8)," +
+					"`suppress_seconds` int," +
+					"`stop` boolean," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_notify_rule_deleted_at ON `notify_rule`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_notify_rule_name ON `notify_rule`(`name`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `notify_rule`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE notify_rule (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"name varchar(64)," +
+					"priority integer," +
+					"enable boolean," +
+					"event_types varchar(255)," +
+					"apps varchar(1024)," +
+					"envs varchar(255)," +
+					"min_severity varchar(16)," +
+					"channels varchar(255)," +
+					"recipients varchar(1024)," +
+					"quiet_start varchar(8)," +
+					"quiet_end varchar(8)," +
+					"suppress_seconds integer," +
+					"stop boolean," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_notify_rule_deleted_at ON notify_rule (deleted_at)",
+				"CREATE UNIQUE INDEX uix_notify_rule_name ON notify_rule (name)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS notify_rule",
+			},
+		},
+	})
+}
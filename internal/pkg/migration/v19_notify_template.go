@@ -0,0 +1,49 @@
+package migration
+
+// v19 通知模板
+func init() {
+	register(Migration{
+		Version: 19,
+		Name:    "notify_template",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `notify_template` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`event_type` varchar(32)," +
+					"`channel` varchar(16)," +
+					"`subject` varchar(512)," +
+					"`body` text," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_notify_template_deleted_at ON `notify_template`(deleted_at)",
+				"CREATE UNIQUE INDEX idx_notify_template ON `notify_template`(event_type, `channel`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `notify_template`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE notify_template (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"event_type varchar(32)," +
+					"channel varchar(16)," +
+					"subject varchar(512)," +
+					"body text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_notify_template_deleted_at ON notify_template (deleted_at)",
+				"CREATE UNIQUE INDEX idx_notify_template ON notify_template (event_type,channel)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS notify_template",
+			},
+		},
+	})
+}
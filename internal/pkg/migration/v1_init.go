@@ -0,0 +1,1578 @@
+package migration
+
+// v1 Juno 0.4 的表结构，由当时 gorm AutoMigrate 的结果导出。
+// 已发布的迁移不再修改，之后的表结构变更都新增版本
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "init",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `app` (" +
+					"`aid` int AUTO_INCREMENT NOT NULL," +
+					"`gid` int NOT NULL COMMENT 'gitlab id'," +
+					"`name` varchar(255) NOT NULL COMMENT '项目中文名'," +
+					"`app_name` varchar(255) NOT NULL COMMENT '项目英文唯一标识名'," +
+					"`create_time` bigint NOT NULL COMMENT '创建时间'," +
+					"`update_time` bigint NOT NULL COMMENT '更新时间'," +
+					"`level` int NOT NULL COMMENT '层级'," +
+					"`lang` varchar(255) NOT NULL COMMENT '语言'," +
+					"`biz_domain` varchar(255) NOT NULL COMMENT '业务类型'," +
+					"`created_by` int NOT NULL COMMENT '创建者'," +
+					"`updated_by` int NOT NULL COMMENT '更新者'," +
+					"`http_port` varchar(255) NOT NULL COMMENT 'HTTP端口号'," +
+					"`rpc_port` varchar(255) NOT NULL COMMENT 'RPC端口号'," +
+					"`govern_port` varchar(255) NOT NULL COMMENT '治理端口号'," +
+					"`hook_id` int NOT NULL COMMENT '钩子'," +
+					"`users` json NOT NULL COMMENT '业务负责人'," +
+					"`web_url` varchar(255) NOT NULL," +
+					"`proto_dir` varchar(255) NOT NULL," +
+					"`git_url` varchar(255) NOT NULL," +
+					"PRIMARY KEY (`aid`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_app_name ON `app`(`name`)",
+				"CREATE INDEX idx_app_app_name ON `app`(app_name)",
+				"CREATE TABLE `app_change_map` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`app_name` varchar(255) NOT NULL," +
+					"`md5` varchar(255) NOT NULL," +
+					"`updated_at` bigint NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_app_change_map_app_name ON `app_change_map`(app_name)",
+				"CREATE INDEX idx_app_change_map_md5 ON `app_change_map`(`md5`)",
+				"CREATE TABLE `app_topology` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`aid` int NOT NULL," +
+					"`app_name` varchar(255) NOT NULL," +
+					"`region_code` varchar(255) NOT NULL," +
+					"`zone_code` varchar(255) NOT NULL," +
+					"`env` varchar(255) NOT NULL," +
+					"`file_name` varchar(255) NOT NULL," +
+					"`addr` varchar(255) NOT NULL," +
+					"`ip` varchar(255) NOT NULL," +
+					"`port` varchar(255) NOT NULL," +
+					"`name` varchar(255) NOT NULL," +
+					"`type` varchar(255) NOT NULL," +
+					"`info` varchar(255) NOT NULL," +
+					"`update_time` bigint NOT NULL," +
+					"`updated_by` int NOT NULL," +
+					"`extra` text NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `app_event` (" +
+					"`id` int AUTO_INCREMENT," +
+					"`app_name` varchar(255) NOT NULL," +
+					"`aid` int NOT NULL," +
+					"`zone_code` varchar(255) NOT NULL," +
+					"`env` varchar(255) NOT NULL," +
+					"`host_name` varchar(255) NOT NULL," +
+					"`operator_type` varchar(255) NOT NULL DEFAULT 'user'," +
+					"`user_name` varchar(255) NOT NULL," +
+					"`uid` int NOT NULL," +
+					"`operation` varchar(255) NOT NULL," +
+					"`create_time` bigint," +
+					"`source` varchar(255) NOT NULL," +
+					"`metadata` text NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_env ON `app_event`(`env`)",
+				"CREATE INDEX idx_operation ON `app_event`(`operation`)",
+				"CREATE INDEX idx_source ON `app_event`(`source`)",
+				"CREATE INDEX idx_app_name ON `app_event`(app_name)",
+				"CREATE INDEX idx_aid ON `app_event`(`aid`)",
+				"CREATE INDEX idx_zone_code ON `app_event`(zone_code)",
+				"CREATE TABLE `app_log` (" +
+					"`id` int AUTO_INCREMENT NOT NULL COMMENT '应用id'," +
+					"`aid` int NOT NULL COMMENT '应用id'," +
+					"`gid` int NOT NULL COMMENT 'gitlab id'," +
+					"`name` varchar(255) NOT NULL COMMENT '项目中文名'," +
+					"`app_name` varchar(255) NOT NULL COMMENT '项目英文唯一标识名'," +
+					"`create_time` bigint NOT NULL COMMENT '创建时间'," +
+					"`update_time` bigint NOT NULL COMMENT '更新时间'," +
+					"`level` int NOT NULL COMMENT '层级'," +
+					"`lang` varchar(255) NOT NULL COMMENT '语言'," +
+					"`biz_domain` varchar(255) NOT NULL," +
+					"`created_by` int NOT NULL COMMENT '创建者'," +
+					"`updated_by` int NOT NULL COMMENT '更新者'," +
+					"`http_port` varchar(255) NOT NULL," +
+					"`rpc_port` varchar(255) NOT NULL," +
+					"`health_port` varchar(255) NOT NULL," +
+					"`hook_id` int NOT NULL COMMENT '钩子'," +
+					"`users` json NOT NULL," +
+					"`web_url` varchar(255) NOT NULL," +
+					"`action` varchar(32) COMMENT '动作'," +
+					"`created_at` DATETIME NULL COMMENT '记录创建时间'," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_app_log_name ON `app_log`(`name`)",
+				"CREATE INDEX idx_app_log_app_name ON `app_log`(app_name)",
+				"CREATE TABLE `app_node` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`app_name` varchar(255) NOT NULL," +
+					"`aid` int NOT NULL," +
+					"`host_name` varchar(255) NOT NULL," +
+					"`ip` varchar(255) NOT NULL," +
+					"`device_id` int NOT NULL," +
+					"`env` varchar(255) NOT NULL," +
+					"`region_code` varchar(255) NOT NULL," +
+					"`region_name` varchar(255) NOT NULL," +
+					"`zone_code` varchar(255) NOT NULL," +
+					"`zone_name` varchar(255) NOT NULL," +
+					"`create_time` bigint NOT NULL," +
+					"`update_time` bigint NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_app_node_ip ON `app_node`(`ip`)",
+				"CREATE TABLE `node` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`host_name` varchar(255) NOT NULL," +
+					"`ip` varchar(255) NOT NULL," +
+					"`create_time` bigint NOT NULL," +
+					"`update_time` bigint NOT NULL," +
+					"`env` varchar(255) NOT NULL," +
+					"`region_code` varchar(255) NOT NULL," +
+					"`region_name` varchar(255) NOT NULL," +
+					"`zone_code` varchar(255) NOT NULL," +
+					"`zone_name` varchar(255) NOT NULL," +
+					"`agent_heartbeat_time` bigint NOT NULL," +
+					"`proxy_heartbeat_time` bigint NOT NULL," +
+					"`node_type` int NOT NULL," +
+					"`agent_type` int NOT NULL," +
+					"`agent_version` varchar(255) NOT NULL," +
+					"`proxy_type` int NOT NULL," +
+					"`proxy_version` varchar(255) NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `app_node_map` (" +
+					"`id` int AUTO_INCREMENT," +
+					"`aid` int," +
+					"`app_name` varchar(255)," +
+					"`md5` varchar(255)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `app_package` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`aid` int NOT NULL," +
+					"`name` varchar(255) NOT NULL," +
+					"`branch` varchar(255) NOT NULL," +
+					"`version` varchar(255) NOT NULL," +
+					"`revision` varchar(255) NOT NULL," +
+					"`packages` text NOT NULL," +
+					"`update_time` bigint NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `app_statics` (" +
+					"`aid` int NOT NULL," +
+					"`app_name` varchar(255) NOT NULL," +
+					"`git_push` int NOT NULL," +
+					"`git_tag_push` int NOT NULL," +
+					"`git_issue` int NOT NULL," +
+					"`git_merge_request` int NOT NULL," +
+					"`git_wiki_page` int NOT NULL," +
+					"`git_pipeline` int NOT NULL," +
+					"`git_job` int NOT NULL," +
+					"`cmc_create` int NOT NULL," +
+					"`cmc_update` int NOT NULL," +
+					"`cmc_delete` int NOT NULL," +
+					"`app_create` int NOT NULL," +
+					"`app_update` int NOT NULL," +
+					"`app_delete` int NOT NULL," +
+					"`node_create` int NOT NULL," +
+					"`node_update` int NOT NULL," +
+					"`node_delete` int NOT NULL," +
+					"`pprof_create` int NOT NULL," +
+					"`devops_update` int NOT NULL," +
+					"`devops_register` int NOT NULL," +
+					"`devops_unregister` int NOT NULL," +
+					"`devops_start` int NOT NULL," +
+					"`devops_restart` int NOT NULL," +
+					"`devops_stop` int NOT NULL," +
+					"`devops_deploy` int NOT NULL," +
+					"`devops_rollback` int NOT NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`created_at` DATETIME NULL" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `app_user_relation` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`app_name` varchar(255) NOT NULL," +
+					"`user_name` varchar(255) NOT NULL," +
+					"`updated_at` bigint NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `app_view_history` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`uid` int unsigned," +
+					"`aid` int unsigned," +
+					"`app_name` varchar(255)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_app_view_history_deleted_at ON `app_view_history`(deleted_at)",
+				"CREATE TABLE `board` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`name` varchar(255) NOT NULL," +
+					"`src` varchar(255) NOT NULL," +
+					"`meta_data` json NOT NULL," +
+					"`is_enable` boolean NOT NULL," +
+					"`is_common` boolean NOT NULL," +
+					"`created_at` bigint NOT NULL," +
+					"`updated_at` bigint NOT NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_board_deleted_at ON `board`(deleted_at)",
+				"CREATE TABLE `board_auth` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`did` int NOT NULL," +
+					"`uid` int NOT NULL," +
+					"`created_at` bigint NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `cmc_tpl` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`tpl_type` varchar(255) NOT NULL," +
+					"`content` longtext NOT NULL," +
+					"`create_time` bigint NOT NULL," +
+					"`update_time` bigint NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `zone` (" +
+					"`id` int AUTO_INCREMENT NOT NULL COMMENT '注释'," +
+					"`env` varchar(255) NOT NULL," +
+					"`region_code` varchar(255) NOT NULL," +
+					"`region_name` varchar(255) NOT NULL," +
+					"`zone_code` varchar(255) NOT NULL," +
+					"`zone_name` varchar(255) NOT NULL," +
+					"`create_time` bigint NOT NULL COMMENT '注释'," +
+					"`update_time` bigint NOT NULL COMMENT '注释'," +
+					"`created_by` int NOT NULL COMMENT '注释'," +
+					"`updated_by` int NOT NULL COMMENT '注释'," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `ops_supervisor_config` (" +
+					"`id` int AUTO_INCREMENT," +
+					"`aid` int," +
+					"`app_name` varchar(255)," +
+					"`ops_app_name` varchar(255)," +
+					"`zone_code` varchar(255)," +
+					"`access_key` varchar(255)," +
+					"`access_secret` varchar(255)," +
+					"`create_time` bigint," +
+					"`update_time` bigint," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `pprof` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`type` varchar(255) NOT NULL," +
+					"`scene_id` varchar(255) NOT NULL," +
+					"`app_name` varchar(255) NOT NULL," +
+					"`aid` int NOT NULL," +
+					"`file_info` varchar(255) NOT NULL," +
+					"`zone_code` varchar(255) NOT NULL," +
+					"`env` varchar(255) NOT NULL," +
+					"`ext` varchar(255) NOT NULL," +
+					"`remark` varchar(255) NOT NULL," +
+					"`host_name` varchar(255) NOT NULL," +
+					"`create_time` bigint," +
+					"`update_time` bigint," +
+					"`delete_time` bigint," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_pprof_delete_time ON `pprof`(delete_time)",
+				"CREATE TABLE `tool` (" +
+					"`id` bigint unsigned AUTO_INCREMENT NOT NULL COMMENT 'id'," +
+					"`name` varchar(255) NOT NULL COMMENT '工具名'," +
+					"`url` varchar(255) NOT NULL COMMENT '工具地址'," +
+					"`pic_url` varchar(255) NOT NULL COMMENT '图片地址'," +
+					"`desc` varchar(255) NOT NULL COMMENT '工具描述'," +
+					"`create_time` bigint NOT NULL COMMENT '创建时间'," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `user` (" +
+					"`uid` int AUTO_INCREMENT NOT NULL," +
+					"`oaid` int NOT NULL COMMENT 'oa uid'," +
+					"`username` varchar(255) NOT NULL COMMENT '用户名'," +
+					"`nickname` varchar(255) NOT NULL COMMENT '昵称'," +
+					"`secret` varchar(255) NOT NULL COMMENT '秘钥'," +
+					"`email` varchar(255) NOT NULL COMMENT 'email'," +
+					"`avatar` varchar(255) NOT NULL COMMENT 'avatart'," +
+					"`web_url` varchar(255) NOT NULL COMMENT '注释'," +
+					"`state` varchar(255) NOT NULL COMMENT '注释'," +
+					"`hash` varchar(255) NOT NULL COMMENT '注释'," +
+					"`create_time` bigint NOT NULL COMMENT '注释'," +
+					"`update_time` bigint NOT NULL COMMENT '注释'," +
+					"`oauth` varchar(255) NOT NULL," +
+					"`oauth_id` varchar(255) NOT NULL," +
+					"`password` varchar(255) NOT NULL COMMENT '注释'," +
+					"`current_authority` varchar(255)," +
+					"`access` varchar(255)," +
+					"`oauth_token` json COMMENT 'OAuth Token 信息'," +
+					"PRIMARY KEY (`uid`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `user_relation` (" +
+					"`id` int AUTO_INCREMENT NOT NULL COMMENT '注释'," +
+					"`uid` int NOT NULL COMMENT '注释'," +
+					"`bid` int NOT NULL COMMENT '注释'," +
+					"`type` int NOT NULL COMMENT '注释'," +
+					"`create_time` bigint NOT NULL COMMENT '注释'," +
+					"`update_time` bigint NOT NULL COMMENT '注释'," +
+					"`delete_time` bigint NOT NULL COMMENT '注释'," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `option` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`option_title` varchar(255) NOT NULL," +
+					"`option_name` varchar(255) NOT NULL," +
+					"`option_value` varchar(255) NOT NULL," +
+					"`create_time` bigint NOT NULL COMMENT '创建时间'," +
+					"`update_time` bigint NOT NULL COMMENT '更新时间'," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `system_config` (" +
+					"`name` varchar(50) NOT NULL," +
+					"`content` longtext NOT NULL," +
+					"`create_time` bigint NOT NULL," +
+					"`update_time` bigint NOT NULL," +
+					"PRIMARY KEY (`name`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `configuration` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`aid` int unsigned," +
+					"`name` varchar(32)," +
+					"`content` longtext," +
+					"`format` varchar(20)," +
+					"`env` varchar(20)," +
+					"`zone` varchar(50)," +
+					"`version` varchar(50)," +
+					"`created_at` DATETIME NULL," +
+					"`access_token_id` int unsigned," +
+					"`uid` int unsigned," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`published_at` DATETIME NULL," +
+					"`lock_uid` int unsigned," +
+					"`lock_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `configuration_publish` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`uid` int unsigned," +
+					"`access_token_id` int unsigned," +
+					"`configuration_id` int unsigned," +
+					"`configuration_history_id` int unsigned," +
+					"`apply_instance` varchar(255)," +
+					"`file_path` varchar(255)," +
+					"`created_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `configuration_history` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`access_token_id` int unsigned," +
+					"`uid` int unsigned," +
+					"`configuration_id` int unsigned," +
+					"`change_log` longtext," +
+					"`content` longtext," +
+					"`version` varchar(50)," +
+					"`created_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `configuration_status` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`configuration_id` int unsigned," +
+					"`configuration_publish_id` int unsigned," +
+					"`host_name` varchar(255)," +
+					"`used` int unsigned," +
+					"`synced` int unsigned," +
+					"`take_effect` int unsigned," +
+					"`created_at` DATETIME NULL," +
+					"`update_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `configuration_resource_relation` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`configuration_history_id` int unsigned," +
+					"`config_resource_value_id` int unsigned," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `config_resource` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`uid` int unsigned," +
+					"`is_global` boolean," +
+					"`name` varchar(50)," +
+					"`env` varchar(30)," +
+					"`zone_code` varchar(50)," +
+					"`description` varchar(255)," +
+					"`visible` boolean," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `config_resource_tag` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`config_resource_id` int unsigned," +
+					"`value` varchar(30)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `config_resource_value` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`config_resource_id` int unsigned," +
+					"`value` text," +
+					"`created_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE TABLE `casbin_policy_auth` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`sub` varchar(255) NOT NULL," +
+					"`obj` varchar(255) NOT NULL," +
+					"`act` varchar(255) NOT NULL," +
+					"`type` varchar(255) NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_casbin_policy_auth_deleted_at ON `casbin_policy_auth`(deleted_at)",
+				"CREATE TABLE `casbin_policy_group` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`group_name` varchar(30) NOT NULL," +
+					"`uid` int NOT NULL," +
+					"`app_name` varchar(255) NOT NULL," +
+					"`app_env` varchar(30) NOT NULL," +
+					"`url` varchar(255) NOT NULL," +
+					"`type` varchar(255) NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_casbin_policy_group_deleted_at ON `casbin_policy_group`(deleted_at)",
+				"CREATE INDEX idx_casbin_policy_group_uid ON `casbin_policy_group`(`uid`)",
+				"CREATE TABLE `access_token` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`name` varchar(32) UNIQUE," +
+					"`app_id` varchar(32) UNIQUE," +
+					"`secret` varchar(64)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_access_token_deleted_at ON `access_token`(deleted_at)",
+				"CREATE TABLE `grpc_test_case` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`method_id` int unsigned," +
+					"`uid` int unsigned," +
+					"`name` varchar(255)," +
+					"`input` longtext," +
+					"`metadata` longtext," +
+					"`script` longtext," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_grpc_test_case_deleted_at ON `grpc_test_case`(deleted_at)",
+				"CREATE TABLE `grpc_test_log` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`method_id` int unsigned," +
+					"`operator_type` varchar(255)," +
+					"`operator_id` int unsigned," +
+					"`input` longtext," +
+					"`output` longtext," +
+					"`status` varchar(20)," +
+					"`error` longtext," +
+					"`time_cost` int unsigned," +
+					"`addr` varchar(30)," +
+					"`metadata` longtext," +
+					"`script` longtext," +
+					"`test_passed` boolean," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_grpc_test_log_deleted_at ON `grpc_test_log`(deleted_at)",
+				"CREATE TABLE `grpc_proto` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`app_name` varchar(255)," +
+					"`file_name` varchar(255)," +
+					"`package_name` varchar(255)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_grpc_proto_deleted_at ON `grpc_proto`(deleted_at)",
+				"CREATE TABLE `grpc_proto_service` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`proto_id` int unsigned," +
+					"`name` varchar(255)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_grpc_proto_service_deleted_at ON `grpc_proto_service`(deleted_at)",
+				"CREATE TABLE `grpc_service_method` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`service_id` int unsigned," +
+					"`name` varchar(255) NOT NULL," +
+					"`method_comment` varchar(255)," +
+					"`input_name` varchar(255) NOT NULL," +
+					"`input_type` json NOT NULL," +
+					"`output_type` json NOT NULL," +
+					"`output_name` varchar(255) NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_grpc_service_method_deleted_at ON `grpc_service_method`(deleted_at)",
+				"CREATE TABLE `http_test_log` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`operator_type` varchar(255)," +
+					"`operator_id` int unsigned," +
+					"`app_name` varchar(255)," +
+					"`name` varchar(255)," +
+					"`url` varchar(255)," +
+					"`method` varchar(255)," +
+					"`query` json," +
+					"`headers` json," +
+					"`content_type` varchar(255)," +
+					"`body` longtext," +
+					"`response_body` longtext," +
+					"`response_headers` json," +
+					"`size` bigint," +
+					"`cost` bigint," +
+					"`code` int," +
+					"`status` varchar(255)," +
+					"`error` varchar(255)," +
+					"`test_logs` json," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_http_test_log_deleted_at ON `http_test_log`(deleted_at)",
+				"CREATE TABLE `http_test_case` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`collection_id` int unsigned," +
+					"`name` varchar(255)," +
+					"`url` varchar(255)," +
+					"`method` varchar(255)," +
+					"`query` json," +
+					"`headers` json," +
+					"`content_type` varchar(255)," +
+					"`body` varchar(255)," +
+					"`script` longtext," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_http_test_case_deleted_at ON `http_test_case`(deleted_at)",
+				"CREATE TABLE `http_test_collection` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`created_by` int unsigned," +
+					"`app_name` varchar(255)," +
+					"`name` varchar(255)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_http_test_collection_deleted_at ON `http_test_collection`(deleted_at)",
+				"CREATE TABLE `test_pipeline` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`name` varchar(255)," +
+					"`app_name` varchar(255)," +
+					"`env` varchar(32)," +
+					"`zone_code` varchar(255)," +
+					"`branch` varchar(255)," +
+					"`code_check` boolean," +
+					"`unit_test` boolean," +
+					"`http_test_collection` int," +
+					"`grpc_test_addr` varchar(255)," +
+					"`grpc_test_cases` json," +
+					"`created_by` int unsigned," +
+					"`updated_by` int unsigned," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_pipeline_deleted_at ON `test_pipeline`(deleted_at)",
+				"CREATE TABLE `test_pipeline_task` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`pipeline_id` int unsigned," +
+					"`name` varchar(255)," +
+					"`app_name` varchar(255)," +
+					"`branch` varchar(255)," +
+					"`env` varchar(32)," +
+					"`zone_code` varchar(32)," +
+					"`desc` json," +
+					"`status` varchar(255)," +
+					"`logs` longtext," +
+					"`created_by` int unsigned," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_pipeline_task_deleted_at ON `test_pipeline_task`(deleted_at)",
+				"CREATE TABLE `test_pipeline_step_status` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`task_id` int unsigned," +
+					"`step_name` varchar(255)," +
+					"`status` varchar(255)," +
+					"`logs` longtext," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_pipeline_step_status_deleted_at ON `test_pipeline_step_status`(deleted_at)",
+				"CREATE TABLE `worker_node` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`host_name` varchar(255)," +
+					"`region_code` varchar(255)," +
+					"`region_name` varchar(255)," +
+					"`zone_code` varchar(255)," +
+					"`zone_name` varchar(255)," +
+					"`ip` varchar(255)," +
+					"`port` int," +
+					"`env` varchar(255)," +
+					"`last_heartbeat` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_worker_node_deleted_at ON `worker_node`(deleted_at)",
+				"CREATE TABLE `cron_job` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`name` varchar(255)," +
+					"`uid` int unsigned," +
+					"`app_name` varchar(255)," +
+					"`env` varchar(255)," +
+					"`zone` varchar(255)," +
+					"`timeout` int unsigned," +
+					"`retry_count` int unsigned," +
+					"`retry_interval` int unsigned," +
+					"`script` varchar(255)," +
+					"`enable` boolean," +
+					"`nodes` json," +
+					"`job_type` int," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_cron_job_deleted_at ON `cron_job`(deleted_at)",
+				"CREATE TABLE `cron_task` (" +
+					"`id` bigint unsigned AUTO_INCREMENT," +
+					"`job_id` int unsigned," +
+					"`node` varchar(255)," +
+					"`env` varchar(255)," +
+					"`zone` varchar(255)," +
+					"`status` varchar(255)," +
+					"`timeout` int unsigned," +
+					"`executed_at` DATETIME NULL," +
+					"`finished_at` DATETIME NULL," +
+					"`retry_count` int unsigned," +
+					"`log` longtext," +
+					"`script` varchar(255)," +
+					"`execute_type` int," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_cron_task_job_id ON `cron_task`(job_id)",
+				"CREATE INDEX idx_cron_task_deleted_at ON `cron_task`(deleted_at)",
+				"CREATE TABLE `cron_job_timer` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`job_id` int unsigned," +
+					"`cron` varchar(255)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_cron_job_timer_deleted_at ON `cron_job_timer`(deleted_at)",
+				"CREATE UNIQUE INDEX idx_unique_name ON `access_token`(`name`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `cron_job_timer`",
+				"DROP TABLE IF EXISTS `cron_task`",
+				"DROP TABLE IF EXISTS `cron_job`",
+				"DROP TABLE IF EXISTS `worker_node`",
+				"DROP TABLE IF EXISTS `test_pipeline_step_status`",
+				"DROP TABLE IF EXISTS `test_pipeline_task`",
+				"DROP TABLE IF EXISTS `test_pipeline`",
+				"DROP TABLE IF EXISTS `http_test_collection`",
+				"DROP TABLE IF EXISTS `http_test_case`",
+				"DROP TABLE IF EXISTS `http_test_log`",
+				"DROP TABLE IF EXISTS `grpc_service_method`",
+				"DROP TABLE IF EXISTS `grpc_proto_service`",
+				"DROP TABLE IF EXISTS `grpc_proto`",
+				"DROP TABLE IF EXISTS `grpc_test_log`",
+				"DROP TABLE IF EXISTS `grpc_test_case`",
+				"DROP TABLE IF EXISTS `access_token`",
+				"DROP TABLE IF EXISTS `casbin_policy_group`",
+				"DROP TABLE IF EXISTS `casbin_policy_auth`",
+				"DROP TABLE IF EXISTS `config_resource_value`",
+				"DROP TABLE IF EXISTS `config_resource_tag`",
+				"DROP TABLE IF EXISTS `config_resource`",
+				"DROP TABLE IF EXISTS `configuration_resource_relation`",
+				"DROP TABLE IF EXISTS `configuration_status`",
+				"DROP TABLE IF EXISTS `configuration_history`",
+				"DROP TABLE IF EXISTS `configuration_publish`",
+				"DROP TABLE IF EXISTS `configuration`",
+				"DROP TABLE IF EXISTS `system_config`",
+				"DROP TABLE IF EXISTS `option`",
+				"DROP TABLE IF EXISTS `user_relation`",
+				"DROP TABLE IF EXISTS `user`",
+				"DROP TABLE IF EXISTS `tool`",
+				"DROP TABLE IF EXISTS `pprof`",
+				"DROP TABLE IF EXISTS `ops_supervisor_config`",
+				"DROP TABLE IF EXISTS `zone`",
+				"DROP TABLE IF EXISTS `cmc_tpl`",
+				"DROP TABLE IF EXISTS `board_auth`",
+				"DROP TABLE IF EXISTS `board`",
+				"DROP TABLE IF EXISTS `app_view_history`",
+				"DROP TABLE IF EXISTS `app_user_relation`",
+				"DROP TABLE IF EXISTS `app_statics`",
+				"DROP TABLE IF EXISTS `app_package`",
+				"DROP TABLE IF EXISTS `app_node_map`",
+				"DROP TABLE IF EXISTS `node`",
+				"DROP TABLE IF EXISTS `app_node`",
+				"DROP TABLE IF EXISTS `app_log`",
+				"DROP TABLE IF EXISTS `app_event`",
+				"DROP TABLE IF EXISTS `app_topology`",
+				"DROP TABLE IF EXISTS `app_change_map`",
+				"DROP TABLE IF EXISTS `app`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE app (" +
+					"aid serial NOT NULL," +
+					"gid integer NOT NULL," +
+					"name text NOT NULL," +
+					"app_name text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"level integer NOT NULL," +
+					"lang text NOT NULL," +
+					"biz_domain text NOT NULL," +
+					"created_by integer NOT NULL," +
+					"updated_by integer NOT NULL," +
+					"http_port text NOT NULL," +
+					"rpc_port text NOT NULL," +
+					"govern_port text NOT NULL," +
+					"hook_id integer NOT NULL," +
+					"users jsonb NOT NULL," +
+					"web_url text NOT NULL," +
+					"proto_dir text NOT NULL," +
+					"git_url text NOT NULL," +
+					"PRIMARY KEY (aid)" +
+					")",
+				"COMMENT ON COLUMN app.gid IS 'gitlab id'",
+				"COMMENT ON COLUMN app.name IS '项目中文名'",
+				"COMMENT ON COLUMN app.app_name IS '项目英文唯一标识名'",
+				"COMMENT ON COLUMN app.create_time IS '创建时间'",
+				"COMMENT ON COLUMN app.update_time IS '更新时间'",
+				"COMMENT ON COLUMN app.level IS '层级'",
+				"COMMENT ON COLUMN app.lang IS '语言'",
+				"COMMENT ON COLUMN app.biz_domain IS '业务类型'",
+				"COMMENT ON COLUMN app.created_by IS '创建者'",
+				"COMMENT ON COLUMN app.updated_by IS '更新者'",
+				"COMMENT ON COLUMN app.http_port IS 'HTTP端口号'",
+				"COMMENT ON COLUMN app.rpc_port IS 'RPC端口号'",
+				"COMMENT ON COLUMN app.govern_port IS '治理端口号'",
+				"COMMENT ON COLUMN app.hook_id IS '钩子'",
+				"COMMENT ON COLUMN app.users IS '业务负责人'",
+				"CREATE INDEX idx_app_name ON app (name)",
+				"CREATE INDEX idx_app_app_name ON app (app_name)",
+				"CREATE TABLE app_change_map (" +
+					"id serial NOT NULL," +
+					"app_name text NOT NULL," +
+					"md5 text NOT NULL," +
+					"updated_at bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_app_change_map_app_name ON app_change_map (app_name)",
+				"CREATE INDEX idx_app_change_map_md5 ON app_change_map (md5)",
+				"CREATE TABLE app_topology (" +
+					"id serial NOT NULL," +
+					"aid integer NOT NULL," +
+					"app_name text NOT NULL," +
+					"region_code text NOT NULL," +
+					"zone_code text NOT NULL," +
+					"env text NOT NULL," +
+					"file_name text NOT NULL," +
+					"addr text NOT NULL," +
+					"ip text NOT NULL," +
+					"port text NOT NULL," +
+					"name text NOT NULL," +
+					"type text NOT NULL," +
+					"info text NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"updated_by integer NOT NULL," +
+					"extra text NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE app_event (" +
+					"id serial," +
+					"app_name text NOT NULL," +
+					"aid integer NOT NULL," +
+					"zone_code text NOT NULL," +
+					"env text NOT NULL," +
+					"host_name text NOT NULL," +
+					"operator_type text NOT NULL DEFAULT 'user'," +
+					"user_name text NOT NULL," +
+					"uid integer NOT NULL," +
+					"operation text NOT NULL," +
+					"create_time bigint," +
+					"source text NOT NULL," +
+					"metadata text NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_app_event_app_name ON app_event (app_name)",
+				"CREATE INDEX idx_aid ON app_event (aid)",
+				"CREATE INDEX idx_zone_code ON app_event (zone_code)",
+				"CREATE INDEX idx_env ON app_event (env)",
+				"CREATE INDEX idx_operation ON app_event (operation)",
+				"CREATE INDEX idx_source ON app_event (source)",
+				"CREATE TABLE app_log (" +
+					"id serial NOT NULL," +
+					"aid integer NOT NULL," +
+					"gid integer NOT NULL," +
+					"name text NOT NULL," +
+					"app_name text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"level integer NOT NULL," +
+					"lang text NOT NULL," +
+					"biz_domain text NOT NULL," +
+					"created_by integer NOT NULL," +
+					"updated_by integer NOT NULL," +
+					"http_port text NOT NULL," +
+					"rpc_port text NOT NULL," +
+					"health_port text NOT NULL," +
+					"hook_id integer NOT NULL," +
+					"users jsonb NOT NULL," +
+					"web_url text NOT NULL," +
+					"action varchar(32)," +
+					"created_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"COMMENT ON COLUMN app_log.id IS '应用id'",
+				"COMMENT ON COLUMN app_log.aid IS '应用id'",
+				"COMMENT ON COLUMN app_log.gid IS 'gitlab id'",
+				"COMMENT ON COLUMN app_log.name IS '项目中文名'",
+				"COMMENT ON COLUMN app_log.app_name IS '项目英文唯一标识名'",
+				"COMMENT ON COLUMN app_log.create_time IS '创建时间'",
+				"COMMENT ON COLUMN app_log.update_time IS '更新时间'",
+				"COMMENT ON COLUMN app_log.level IS '层级'",
+				"COMMENT ON COLUMN app_log.lang IS '语言'",
+				"COMMENT ON COLUMN app_log.created_by IS '创建者'",
+				"COMMENT ON COLUMN app_log.updated_by IS '更新者'",
+				"COMMENT ON COLUMN app_log.hook_id IS '钩子'",
+				"COMMENT ON COLUMN app_log.action IS '动作'",
+				"COMMENT ON COLUMN app_log.created_at IS '记录创建时间'",
+				"CREATE INDEX idx_app_log_name ON app_log (name)",
+				"CREATE INDEX idx_app_log_app_name ON app_log (app_name)",
+				"CREATE TABLE app_node (" +
+					"id serial NOT NULL," +
+					"app_name text NOT NULL," +
+					"aid integer NOT NULL," +
+					"host_name text NOT NULL," +
+					"ip text NOT NULL," +
+					"device_id integer NOT NULL," +
+					"env text NOT NULL," +
+					"region_code text NOT NULL," +
+					"region_name text NOT NULL," +
+					"zone_code text NOT NULL," +
+					"zone_name text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_app_node_ip ON app_node (ip)",
+				"CREATE TABLE node (" +
+					"id serial NOT NULL," +
+					"host_name text NOT NULL," +
+					"ip text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"env text NOT NULL," +
+					"region_code text NOT NULL," +
+					"region_name text NOT NULL," +
+					"zone_code text NOT NULL," +
+					"zone_name text NOT NULL," +
+					"agent_heartbeat_time bigint NOT NULL," +
+					"proxy_heartbeat_time bigint NOT NULL," +
+					"node_type integer NOT NULL," +
+					"agent_type integer NOT NULL," +
+					"agent_version text NOT NULL," +
+					"proxy_type integer NOT NULL," +
+					"proxy_version text NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE app_node_map (" +
+					"id serial," +
+					"aid integer," +
+					"app_name text," +
+					"md5 text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE app_package (" +
+					"id serial NOT NULL," +
+					"aid integer NOT NULL," +
+					"name text NOT NULL," +
+					"branch text NOT NULL," +
+					"version text NOT NULL," +
+					"revision text NOT NULL," +
+					"packages text NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE app_statics (" +
+					"aid integer NOT NULL," +
+					"app_name text NOT NULL," +
+					"git_push integer NOT NULL," +
+					"git_tag_push integer NOT NULL," +
+					"git_issue integer NOT NULL," +
+					"git_merge_request integer NOT NULL," +
+					"git_wiki_page integer NOT NULL," +
+					"git_pipeline integer NOT NULL," +
+					"git_job integer NOT NULL," +
+					"cmc_create integer NOT NULL," +
+					"cmc_update integer NOT NULL," +
+					"cmc_delete integer NOT NULL," +
+					"app_create integer NOT NULL," +
+					"app_update integer NOT NULL," +
+					"app_delete integer NOT NULL," +
+					"node_create integer NOT NULL," +
+					"node_update integer NOT NULL," +
+					"node_delete integer NOT NULL," +
+					"pprof_create integer NOT NULL," +
+					"devops_update integer NOT NULL," +
+					"devops_register integer NOT NULL," +
+					"devops_unregister integer NOT NULL," +
+					"devops_start integer NOT NULL," +
+					"devops_restart integer NOT NULL," +
+					"devops_stop integer NOT NULL," +
+					"devops_deploy integer NOT NULL," +
+					"devops_rollback integer NOT NULL," +
+					"updated_at timestamp with time zone," +
+					"created_at timestamp with time zone" +
+					")",
+				"CREATE TABLE app_user_relation (" +
+					"id serial NOT NULL," +
+					"app_name text NOT NULL," +
+					"user_name text NOT NULL," +
+					"updated_at bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE app_view_history (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"uid integer," +
+					"aid integer," +
+					"app_name text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_app_view_history_deleted_at ON app_view_history (deleted_at)",
+				"CREATE TABLE board (" +
+					"id serial NOT NULL," +
+					"name text NOT NULL," +
+					"src text NOT NULL," +
+					"meta_data jsonb NOT NULL," +
+					"is_enable boolean NOT NULL," +
+					"is_common boolean NOT NULL," +
+					"created_at bigint NOT NULL," +
+					"updated_at bigint NOT NULL," +
+					"deleted_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_board_deleted_at ON board (deleted_at)",
+				"CREATE TABLE board_auth (" +
+					"id serial NOT NULL," +
+					"did integer NOT NULL," +
+					"uid integer NOT NULL," +
+					"created_at bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE cmc_tpl (" +
+					"id serial NOT NULL," +
+					"tpl_type text NOT NULL," +
+					"content text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE zone (" +
+					"id serial NOT NULL," +
+					"env text NOT NULL," +
+					"region_code text NOT NULL," +
+					"region_name text NOT NULL," +
+					"zone_code text NOT NULL," +
+					"zone_name text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"created_by integer NOT NULL," +
+					"updated_by integer NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"COMMENT ON COLUMN zone.id IS '注释'",
+				"COMMENT ON COLUMN zone.create_time IS '注释'",
+				"COMMENT ON COLUMN zone.update_time IS '注释'",
+				"COMMENT ON COLUMN zone.created_by IS '注释'",
+				"COMMENT ON COLUMN zone.updated_by IS '注释'",
+				"CREATE TABLE ops_supervisor_config (" +
+					"id serial," +
+					"aid integer," +
+					"app_name text," +
+					"ops_app_name text," +
+					"zone_code text," +
+					"access_key text," +
+					"access_secret text," +
+					"create_time bigint," +
+					"update_time bigint," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE pprof (" +
+					"id serial NOT NULL," +
+					"type text NOT NULL," +
+					"scene_id text NOT NULL," +
+					"app_name text NOT NULL," +
+					"aid integer NOT NULL," +
+					"file_info text NOT NULL," +
+					"zone_code text NOT NULL," +
+					"env text NOT NULL," +
+					"ext text NOT NULL," +
+					"remark text NOT NULL," +
+					"host_name text NOT NULL," +
+					"create_time bigint," +
+					"update_time bigint," +
+					"delete_time bigint," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_pprof_delete_time ON pprof (delete_time)",
+				"CREATE TABLE tool (" +
+					"id bigserial NOT NULL," +
+					"name text NOT NULL," +
+					"url text NOT NULL," +
+					"pic_url text NOT NULL," +
+					"\"desc\" text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"COMMENT ON COLUMN tool.id IS 'id'",
+				"COMMENT ON COLUMN tool.name IS '工具名'",
+				"COMMENT ON COLUMN tool.url IS '工具地址'",
+				"COMMENT ON COLUMN tool.pic_url IS '图片地址'",
+				"COMMENT ON COLUMN tool.\"desc\" IS '工具描述'",
+				"COMMENT ON COLUMN tool.create_time IS '创建时间'",
+				"CREATE TABLE \"user\" (" +
+					"uid serial NOT NULL," +
+					"oaid integer NOT NULL," +
+					"username text NOT NULL," +
+					"nickname text NOT NULL," +
+					"secret text NOT NULL," +
+					"email text NOT NULL," +
+					"avatar text NOT NULL," +
+					"web_url text NOT NULL," +
+					"state text NOT NULL," +
+					"hash text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"oauth text NOT NULL," +
+					"oauth_id text NOT NULL," +
+					"password text NOT NULL," +
+					"current_authority text," +
+					"access text," +
+					"oauth_token jsonb," +
+					"PRIMARY KEY (uid)" +
+					")",
+				"COMMENT ON COLUMN \"user\".oaid IS 'oa uid'",
+				"COMMENT ON COLUMN \"user\".username IS '用户名'",
+				"COMMENT ON COLUMN \"user\".nickname IS '昵称'",
+				"COMMENT ON COLUMN \"user\".secret IS '秘钥'",
+				"COMMENT ON COLUMN \"user\".email IS 'email'",
+				"COMMENT ON COLUMN \"user\".avatar IS 'avatart'",
+				"COMMENT ON COLUMN \"user\".web_url IS '注释'",
+				"COMMENT ON COLUMN \"user\".state IS '注释'",
+				"COMMENT ON COLUMN \"user\".hash IS '注释'",
+				"COMMENT ON COLUMN \"user\".create_time IS '注释'",
+				"COMMENT ON COLUMN \"user\".update_time IS '注释'",
+				"COMMENT ON COLUMN \"user\".password IS '注释'",
+				"COMMENT ON COLUMN \"user\".oauth_token IS 'OAuth Token 信息'",
+				"CREATE TABLE user_relation (" +
+					"id serial NOT NULL," +
+					"uid integer NOT NULL," +
+					"bid integer NOT NULL," +
+					"type integer NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"delete_time bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"COMMENT ON COLUMN user_relation.id IS '注释'",
+				"COMMENT ON COLUMN user_relation.uid IS '注释'",
+				"COMMENT ON COLUMN user_relation.bid IS '注释'",
+				"COMMENT ON COLUMN user_relation.type IS '注释'",
+				"COMMENT ON COLUMN user_relation.create_time IS '注释'",
+				"COMMENT ON COLUMN user_relation.update_time IS '注释'",
+				"COMMENT ON COLUMN user_relation.delete_time IS '注释'",
+				"CREATE TABLE option (" +
+					"id serial NOT NULL," +
+					"option_title text NOT NULL," +
+					"option_name text NOT NULL," +
+					"option_value text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"COMMENT ON COLUMN option.create_time IS '创建时间'",
+				"COMMENT ON COLUMN option.update_time IS '更新时间'",
+				"CREATE TABLE system_config (" +
+					"name varchar(50) NOT NULL," +
+					"content text NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"update_time bigint NOT NULL," +
+					"PRIMARY KEY (name)" +
+					")",
+				"CREATE TABLE configuration (" +
+					"id serial," +
+					"aid integer," +
+					"name varchar(32)," +
+					"content text," +
+					"format varchar(20)," +
+					"env varchar(20)," +
+					"zone varchar(50)," +
+					"version varchar(50)," +
+					"created_at timestamp with time zone," +
+					"access_token_id integer," +
+					"uid integer," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"published_at timestamp with time zone," +
+					"lock_uid integer," +
+					"lock_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE configuration_publish (" +
+					"id serial," +
+					"uid integer," +
+					"access_token_id integer," +
+					"configuration_id integer," +
+					"configuration_history_id integer," +
+					"apply_instance text," +
+					"file_path text," +
+					"created_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE configuration_history (" +
+					"id serial," +
+					"access_token_id integer," +
+					"uid integer," +
+					"configuration_id integer," +
+					"change_log text," +
+					"content text," +
+					"version varchar(50)," +
+					"created_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE configuration_status (" +
+					"id serial," +
+					"configuration_id integer," +
+					"configuration_publish_id integer," +
+					"host_name text," +
+					"used integer," +
+					"synced integer," +
+					"take_effect integer," +
+					"created_at timestamp with time zone," +
+					"update_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE configuration_resource_relation (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"configuration_history_id integer," +
+					"config_resource_value_id integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE config_resource (" +
+					"id serial," +
+					"uid integer," +
+					"is_global boolean," +
+					"name varchar(50)," +
+					"env varchar(30)," +
+					"zone_code varchar(50)," +
+					"description text," +
+					"visible boolean," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE config_resource_tag (" +
+					"id serial," +
+					"config_resource_id integer," +
+					"value varchar(30)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE config_resource_value (" +
+					"id serial," +
+					"config_resource_id integer," +
+					"value text," +
+					"created_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE TABLE casbin_policy_auth (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"sub text NOT NULL," +
+					"obj varchar(255) NOT NULL," +
+					"act varchar(255) NOT NULL," +
+					"type text NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_casbin_policy_auth_deleted_at ON casbin_policy_auth (deleted_at)",
+				"CREATE TABLE casbin_policy_group (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"group_name varchar(30) NOT NULL," +
+					"uid integer NOT NULL," +
+					"app_name varchar(255) NOT NULL," +
+					"app_env varchar(30) NOT NULL," +
+					"url varchar(255) NOT NULL," +
+					"type text NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_casbin_policy_group_deleted_at ON casbin_policy_group (deleted_at)",
+				"CREATE INDEX idx_casbin_policy_group_uid ON casbin_policy_group (uid)",
+				"CREATE TABLE access_token (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"name varchar(32) UNIQUE," +
+					"app_id varchar(32) UNIQUE," +
+					"secret varchar(64)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_access_token_deleted_at ON access_token (deleted_at)",
+				"CREATE TABLE grpc_test_case (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"method_id integer," +
+					"uid integer," +
+					"name text," +
+					"input text," +
+					"metadata text," +
+					"script text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_grpc_test_case_deleted_at ON grpc_test_case (deleted_at)",
+				"CREATE TABLE grpc_test_log (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"method_id integer," +
+					"operator_type text," +
+					"operator_id integer," +
+					"input text," +
+					"output text," +
+					"status varchar(20)," +
+					"error text," +
+					"time_cost bigint," +
+					"addr varchar(30)," +
+					"metadata text," +
+					"script text," +
+					"test_passed boolean," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_grpc_test_log_deleted_at ON grpc_test_log (deleted_at)",
+				"CREATE TABLE grpc_proto (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"app_name text," +
+					"file_name text," +
+					"package_name text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_grpc_proto_deleted_at ON grpc_proto (deleted_at)",
+				"CREATE TABLE grpc_proto_service (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"proto_id integer," +
+					"name text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_grpc_proto_service_deleted_at ON grpc_proto_service (deleted_at)",
+				"CREATE TABLE grpc_service_method (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"service_id integer," +
+					"name text NOT NULL," +
+					"method_comment text," +
+					"input_name text NOT NULL," +
+					"input_type jsonb NOT NULL," +
+					"output_type jsonb NOT NULL," +
+					"output_name text NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_grpc_service_method_deleted_at ON grpc_service_method (deleted_at)",
+				"CREATE TABLE http_test_log (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"operator_type text," +
+					"operator_id integer," +
+					"app_name text," +
+					"name text," +
+					"url text," +
+					"method text," +
+					"query jsonb," +
+					"headers jsonb," +
+					"content_type text," +
+					"body text," +
+					"response_body text," +
+					"response_headers jsonb," +
+					"size bigint," +
+					"cost bigint," +
+					"code integer," +
+					"status text," +
+					"error text," +
+					"test_logs jsonb," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_http_test_log_deleted_at ON http_test_log (deleted_at)",
+				"CREATE TABLE http_test_case (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"collection_id integer," +
+					"name text," +
+					"url text," +
+					"method text," +
+					"query jsonb," +
+					"headers jsonb," +
+					"content_type text," +
+					"body text," +
+					"script text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_http_test_case_deleted_at ON http_test_case (deleted_at)",
+				"CREATE TABLE http_test_collection (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"created_by integer," +
+					"app_name text," +
+					"name text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_http_test_collection_deleted_at ON http_test_collection (deleted_at)",
+				"CREATE TABLE test_pipeline (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"name text," +
+					"app_name text," +
+					"env varchar(32)," +
+					"zone_code text," +
+					"branch text," +
+					"code_check boolean," +
+					"unit_test boolean," +
+					"http_test_collection integer," +
+					"grpc_test_addr text," +
+					"grpc_test_cases jsonb," +
+					"created_by integer," +
+					"updated_by integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_pipeline_deleted_at ON test_pipeline (deleted_at)",
+				"CREATE TABLE test_pipeline_task (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"pipeline_id integer," +
+					"name text," +
+					"app_name text," +
+					"branch text," +
+					"env varchar(32)," +
+					"zone_code varchar(32)," +
+					"\"desc\" jsonb," +
+					"status text," +
+					"logs text," +
+					"created_by integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_pipeline_task_deleted_at ON test_pipeline_task (deleted_at)",
+				"CREATE TABLE test_pipeline_step_status (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"task_id integer," +
+					"step_name text," +
+					"status text," +
+					"logs text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_pipeline_step_status_deleted_at ON test_pipeline_step_status (deleted_at)",
+				"CREATE TABLE worker_node (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"host_name text," +
+					"region_code text," +
+					"region_name text," +
+					"zone_code text," +
+					"zone_name text," +
+					"ip text," +
+					"port integer," +
+					"env text," +
+					"last_heartbeat timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_worker_node_deleted_at ON worker_node (deleted_at)",
+				"CREATE TABLE cron_job (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"name text," +
+					"uid integer," +
+					"app_name text," +
+					"env text," +
+					"zone text," +
+					"timeout integer," +
+					"retry_count integer," +
+					"retry_interval integer," +
+					"script text," +
+					"enable boolean," +
+					"nodes jsonb," +
+					"job_type integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_cron_job_deleted_at ON cron_job (deleted_at)",
+				"CREATE TABLE cron_task (" +
+					"id bigserial," +
+					"job_id integer," +
+					"node text," +
+					"env text," +
+					"zone text," +
+					"status text," +
+					"timeout integer," +
+					"executed_at timestamp with time zone," +
+					"finished_at timestamp with time zone," +
+					"retry_count integer," +
+					"log text," +
+					"script text," +
+					"execute_type integer," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_cron_task_job_id ON cron_task (job_id)",
+				"CREATE INDEX idx_cron_task_deleted_at ON cron_task (deleted_at)",
+				"CREATE TABLE cron_job_timer (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"job_id integer," +
+					"cron text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_cron_job_timer_deleted_at ON cron_job_timer (deleted_at)",
+				"CREATE UNIQUE INDEX idx_unique_name ON access_token (name)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS cron_job_timer",
+				"DROP TABLE IF EXISTS cron_task",
+				"DROP TABLE IF EXISTS cron_job",
+				"DROP TABLE IF EXISTS worker_node",
+				"DROP TABLE IF EXISTS test_pipeline_step_status",
+				"DROP TABLE IF EXISTS test_pipeline_task",
+				"DROP TABLE IF EXISTS test_pipeline",
+				"DROP TABLE IF EXISTS http_test_collection",
+				"DROP TABLE IF EXISTS http_test_case",
+				"DROP TABLE IF EXISTS http_test_log",
+				"DROP TABLE IF EXISTS grpc_service_method",
+				"DROP TABLE IF EXISTS grpc_proto_service",
+				"DROP TABLE IF EXISTS grpc_proto",
+				"DROP TABLE IF EXISTS grpc_test_log",
+				"DROP TABLE IF EXISTS grpc_test_case",
+				"DROP TABLE IF EXISTS access_token",
+				"DROP TABLE IF EXISTS casbin_policy_group",
+				"DROP TABLE IF EXISTS casbin_policy_auth",
+				"DROP TABLE IF EXISTS config_resource_value",
+				"DROP TABLE IF EXISTS config_resource_tag",
+				"DROP TABLE IF EXISTS config_resource",
+				"DROP TABLE IF EXISTS configuration_resource_relation",
+				"DROP TABLE IF EXISTS configuration_status",
+				"DROP TABLE IF EXISTS configuration_history",
+				"DROP TABLE IF EXISTS configuration_publish",
+				"DROP TABLE IF EXISTS configuration",
+				"DROP TABLE IF EXISTS system_config",
+				"DROP TABLE IF EXISTS option",
+				"DROP TABLE IF EXISTS user_relation",
+				"DROP TABLE IF EXISTS \"user\"",
+				"DROP TABLE IF EXISTS tool",
+				"DROP TABLE IF EXISTS pprof",
+				"DROP TABLE IF EXISTS ops_supervisor_config",
+				"DROP TABLE IF EXISTS zone",
+				"DROP TABLE IF EXISTS cmc_tpl",
+				"DROP TABLE IF EXISTS board_auth",
+				"DROP TABLE IF EXISTS board",
+				"DROP TABLE IF EXISTS app_view_history",
+				"DROP TABLE IF EXISTS app_user_relation",
+				"DROP TABLE IF EXISTS app_statics",
+				"DROP TABLE IF EXISTS app_package",
+				"DROP TABLE IF EXISTS app_node_map",
+				"DROP TABLE IF EXISTS node",
+				"DROP TABLE IF EXISTS app_node",
+				"DROP TABLE IF EXISTS app_log",
+				"DROP TABLE IF EXISTS app_event",
+				"DROP TABLE IF EXISTS app_topology",
+				"DROP TABLE IF EXISTS app_change_map",
+				"DROP TABLE IF EXISTS app",
+			},
+		},
+	})
+}
@@ -0,0 +1,107 @@
+package migration
+
+// v20 值班轮换和告警事件
+func init() {
+	register(Migration{
+		Version: 20,
+		Name:    "oncall",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `oncall_rotation` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`team_id` int unsigned," +
+					"`users` varchar(1024)," +
+					"`shift_hours` int," +
+					"`start_at` bigint," +
+					"`escalate_minutes` int," +
+					"`escalate_to_owners` boolean," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_oncall_rotation_deleted_at ON `oncall_rotation`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_oncall_rotation_team_id ON `oncall_rotation`(team_id)",
+				"CREATE TABLE `incident` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`team_id` int unsigned," +
+					"`app` varchar(128)," +
+					"`env` varchar(64)," +
+					"`type` varchar(32)," +
+					"`severity` varchar(16)," +
+					"`subject` varchar(512)," +
+					"`content` text," +
+					"`fire_count` int," +
+					"`status` varchar(16)," +
+					"`level` int," +
+					"`notified_uids` varchar(512)," +
+					"`next_escalate_at` bigint," +
+					"`ack_uid` int," +
+					"`ack_time` bigint," +
+					"`resolve_time` bigint," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_incident_deleted_at ON `incident`(deleted_at)",
+				"CREATE INDEX idx_incident_team_id ON `incident`(team_id)",
+				"CREATE INDEX idx_incident_status ON `incident`(`status`)",
+				"CREATE INDEX idx_incident_next_escalate_at ON `incident`(next_escalate_at)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `incident`",
+				"DROP TABLE IF EXISTS `oncall_rotation`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE oncall_rotation (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"team_id integer," +
+					"users varchar(1024)," +
+					"shift_hours integer," +
+					"start_at bigint," +
+					"escalate_minutes integer," +
+					"escalate_to_owners boolean," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_oncall_rotation_deleted_at ON oncall_rotation (deleted_at)",
+				"CREATE UNIQUE INDEX uix_oncall_rotation_team_id ON oncall_rotation (team_id)",
+				"CREATE TABLE incident (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"team_id integer," +
+					"app varchar(128)," +
+					"env varchar(64)," +
+					"type varchar(32)," +
+					"severity varchar(16)," +
+					"subject varchar(512)," +
+					"content text," +
+					"fire_count integer," +
+					"status varchar(16)," +
+					"level integer," +
+					"notified_uids varchar(512)," +
+					"next_escalate_at bigint," +
+					"ack_uid integer," +
+					"ack_time bigint," +
+					"resolve_time bigint," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_incident_status ON incident (status)",
+				"CREATE INDEX idx_incident_next_escalate_at ON incident (next_escalate_at)",
+				"CREATE INDEX idx_incident_deleted_at ON incident (deleted_at)",
+				"CREATE INDEX idx_incident_team_id ON incident (team_id)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS incident",
+				"DROP TABLE IF EXISTS oncall_rotation",
+			},
+		},
+	})
+}
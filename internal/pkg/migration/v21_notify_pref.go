@@ -0,0 +1,57 @@
+package migration
+
+// v21 用户通知偏好，团队必须接收的通知级别
+func init() {
+	register(Migration{
+		Version: 21,
+		Name:    "notify_pref",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `team` ADD COLUMN `mandatory_severity` varchar(16)",
+				"CREATE TABLE `user_notify_pref` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`uid` int," +
+					"`muted_events` varchar(512)," +
+					"`min_severity` varchar(16)," +
+					"`channels` varchar(255)," +
+					"`token` varchar(64)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_user_notify_pref_deleted_at ON `user_notify_pref`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_notify_pref_token ON `user_notify_pref`(`token`)",
+				"CREATE UNIQUE INDEX uix_user_notify_pref_uid ON `user_notify_pref`(`uid`)",
+			},
+			Down: []string{
+				"ALTER TABLE `team` DROP COLUMN `mandatory_severity`",
+				"DROP TABLE IF EXISTS `user_notify_pref`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE team ADD COLUMN mandatory_severity varchar(16)",
+				"CREATE TABLE user_notify_pref (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"uid integer," +
+					"muted_events varchar(512)," +
+					"min_severity varchar(16)," +
+					"channels varchar(255)," +
+					"token varchar(64)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_user_notify_pref_deleted_at ON user_notify_pref (deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_notify_pref_uid ON user_notify_pref (uid)",
+				"CREATE UNIQUE INDEX uix_user_notify_pref_token ON user_notify_pref (token)",
+			},
+			Down: []string{
+				"ALTER TABLE team DROP COLUMN mandatory_severity",
+				"DROP TABLE IF EXISTS user_notify_pref",
+			},
+		},
+	})
+}
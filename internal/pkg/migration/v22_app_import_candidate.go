@@ -0,0 +1,73 @@
+package migration
+
+// v22 GitLab 扫描到的待导入应用
+func init() {
+	register(Migration{
+		Version: 22,
+		Name:    "app_import_candidate",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `app_import_candidate` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`gid` int," +
+					"`app_name` varchar(128)," +
+					"`name` varchar(255)," +
+					"`module` varchar(255)," +
+					"`jupiter` boolean," +
+					"`group_path` varchar(255)," +
+					"`git_url` varchar(512)," +
+					"`web_url` varchar(512)," +
+					"`owners` varchar(512)," +
+					"`committers` varchar(1024)," +
+					"`status` varchar(16)," +
+					"`aid` int," +
+					"`scanned_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_app_import_candidate_deleted_at ON `app_import_candidate`(deleted_at)",
+				"CREATE INDEX idx_app_import_candidate_app_name ON `app_import_candidate`(app_name)",
+				"CREATE INDEX idx_app_import_candidate_group_path ON `app_import_candidate`(group_path)",
+				"CREATE INDEX idx_app_import_candidate_status ON `app_import_candidate`(`status`)",
+				"CREATE UNIQUE INDEX uix_app_import_candidate_gid ON `app_import_candidate`(`gid`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `app_import_candidate`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE app_import_candidate (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"gid integer," +
+					"app_name varchar(128)," +
+					"name varchar(255)," +
+					"module varchar(255)," +
+					"jupiter boolean," +
+					"group_path varchar(255)," +
+					"git_url varchar(512)," +
+					"web_url varchar(512)," +
+					"owners varchar(512)," +
+					"committers varchar(1024)," +
+					"status varchar(16)," +
+					"aid integer," +
+					"scanned_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_app_import_candidate_status ON app_import_candidate (status)",
+				"CREATE INDEX idx_app_import_candidate_deleted_at ON app_import_candidate (deleted_at)",
+				"CREATE INDEX idx_app_import_candidate_app_name ON app_import_candidate (app_name)",
+				"CREATE INDEX idx_app_import_candidate_group_path ON app_import_candidate (group_path)",
+				"CREATE UNIQUE INDEX uix_app_import_candidate_gid ON app_import_candidate (gid)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS app_import_candidate",
+			},
+		},
+	})
+}
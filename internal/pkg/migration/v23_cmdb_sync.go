@@ -0,0 +1,93 @@
+package migration
+
+// v23 CMDB 同步记录和冲突
+func init() {
+	register(Migration{
+		Version: 23,
+		Name:    "cmdb_sync",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `cmdb_sync_record` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`source` varchar(64)," +
+					"`kind` varchar(16)," +
+					"`record_key` varchar(255)," +
+					"`synced` text," +
+					"`ignored` text," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_cmdb_sync_record_deleted_at ON `cmdb_sync_record`(deleted_at)",
+				"CREATE UNIQUE INDEX idx_source_kind_key ON `cmdb_sync_record`(`source`, `kind`, record_key)",
+				"CREATE TABLE `cmdb_conflict` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`source` varchar(64)," +
+					"`kind` varchar(16)," +
+					"`record_key` varchar(255)," +
+					"`op` varchar(16)," +
+					"`remote` text," +
+					"`local` text," +
+					"`diffs` text," +
+					"`status` varchar(16)," +
+					"`uid` int," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_cmdb_conflict_deleted_at ON `cmdb_conflict`(deleted_at)",
+				"CREATE INDEX idx_cmdb_conflict_source ON `cmdb_conflict`(`source`)",
+				"CREATE INDEX idx_cmdb_conflict_kind ON `cmdb_conflict`(`kind`)",
+				"CREATE INDEX idx_cmdb_conflict_status ON `cmdb_conflict`(`status`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `cmdb_conflict`",
+				"DROP TABLE IF EXISTS `cmdb_sync_record`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE cmdb_sync_record (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"source varchar(64)," +
+					"kind varchar(16)," +
+					"record_key varchar(255)," +
+					"synced text," +
+					"ignored text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_cmdb_sync_record_deleted_at ON cmdb_sync_record (deleted_at)",
+				"CREATE UNIQUE INDEX idx_source_kind_key ON cmdb_sync_record (source,kind,record_key)",
+				"CREATE TABLE cmdb_conflict (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"source varchar(64)," +
+					"kind varchar(16)," +
+					"record_key varchar(255)," +
+					"op varchar(16)," +
+					"remote text," +
+					"local text," +
+					"diffs text," +
+					"status varchar(16)," +
+					"uid integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_cmdb_conflict_kind ON cmdb_conflict (kind)",
+				"CREATE INDEX idx_cmdb_conflict_status ON cmdb_conflict (status)",
+				"CREATE INDEX idx_cmdb_conflict_deleted_at ON cmdb_conflict (deleted_at)",
+				"CREATE INDEX idx_cmdb_conflict_source ON cmdb_conflict (source)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS cmdb_conflict",
+				"DROP TABLE IF EXISTS cmdb_sync_record",
+			},
+		},
+	})
+}
@@ -0,0 +1,83 @@
+package migration
+
+// v24 应用自定义字段
+func init() {
+	register(Migration{
+		Version: 24,
+		Name:    "app_meta",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `app_meta_field` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`name` varchar(32)," +
+					"`title` varchar(64)," +
+					"`type` varchar(16)," +
+					"`options` varchar(1024)," +
+					"`required` boolean," +
+					"`regex` varchar(255)," +
+					"`sort` int," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_app_meta_field_deleted_at ON `app_meta_field`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_app_meta_field_name ON `app_meta_field`(`name`)",
+				"CREATE TABLE `app_meta_value` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`aid` int," +
+					"`field` varchar(32)," +
+					"`value` varchar(255)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_field_value ON `app_meta_value`(`field`, `value`)",
+				"CREATE INDEX idx_app_meta_value_deleted_at ON `app_meta_value`(deleted_at)",
+				"CREATE UNIQUE INDEX idx_aid_field ON `app_meta_value`(`aid`, `field`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `app_meta_value`",
+				"DROP TABLE IF EXISTS `app_meta_field`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE app_meta_field (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"name varchar(32)," +
+					"title varchar(64)," +
+					"type varchar(16)," +
+					"options varchar(1024)," +
+					"required boolean," +
+					"regex varchar(255)," +
+					"sort integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_app_meta_field_deleted_at ON app_meta_field (deleted_at)",
+				"CREATE UNIQUE INDEX uix_app_meta_field_name ON app_meta_field (name)",
+				"CREATE TABLE app_meta_value (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"aid integer," +
+					"field varchar(32)," +
+					"value varchar(255)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_field_value ON app_meta_value (field,value)",
+				"CREATE INDEX idx_app_meta_value_deleted_at ON app_meta_value (deleted_at)",
+				"CREATE UNIQUE INDEX idx_aid_field ON app_meta_value (aid,field)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS app_meta_value",
+				"DROP TABLE IF EXISTS app_meta_field",
+			},
+		},
+	})
+}
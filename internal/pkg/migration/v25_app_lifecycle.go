@@ -0,0 +1,68 @@
+package migration
+
+// v25 应用生命周期状态和归档记录
+func init() {
+	register(Migration{
+		Version: 25,
+		Name:    "app_lifecycle",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `app` ADD COLUMN `status` varchar(255) NOT NULL DEFAULT 'active' COMMENT '生命周期状态'",
+				"CREATE INDEX idx_app_status ON `app`(`status`)",
+				"CREATE TABLE `app_archive` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`aid` int," +
+					"`app_name` varchar(128)," +
+					"`status` varchar(16)," +
+					"`reason` varchar(255)," +
+					"`uid` int," +
+					"`configs` longtext," +
+					"`cron_jobs` longtext," +
+					"`cleaned_at` DATETIME NULL," +
+					"`error` text," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_app_archive_deleted_at ON `app_archive`(deleted_at)",
+				"CREATE INDEX idx_app_archive_aid ON `app_archive`(`aid`)",
+				"CREATE INDEX idx_app_archive_app_name ON `app_archive`(app_name)",
+			},
+			Down: []string{
+				"ALTER TABLE `app` DROP COLUMN `status`",
+				"DROP TABLE IF EXISTS `app_archive`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE app ADD COLUMN status text NOT NULL DEFAULT 'active'",
+				"COMMENT ON COLUMN app.status IS '生命周期状态'",
+				"CREATE INDEX idx_app_status ON app (status)",
+				"CREATE TABLE app_archive (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"aid integer," +
+					"app_name varchar(128)," +
+					"status varchar(16)," +
+					"reason varchar(255)," +
+					"uid integer," +
+					"configs text," +
+					"cron_jobs text," +
+					"cleaned_at timestamp with time zone," +
+					"error text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_app_archive_deleted_at ON app_archive (deleted_at)",
+				"CREATE INDEX idx_app_archive_aid ON app_archive (aid)",
+				"CREATE INDEX idx_app_archive_app_name ON app_archive (app_name)",
+			},
+			Down: []string{
+				"ALTER TABLE app DROP COLUMN status",
+				"DROP TABLE IF EXISTS app_archive",
+			},
+		},
+	})
+}
@@ -0,0 +1,75 @@
+package migration
+
+// v26 Kubernetes 集群
+func init() {
+	register(Migration{
+		Version: 26,
+		Name:    "k8s_cluster",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `k8s_cluster` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`name` varchar(64)," +
+					"`env` json," +
+					"`zone_code` varchar(64)," +
+					"`zone_name` varchar(64)," +
+					"`server` varchar(255)," +
+					"`auth_type` varchar(16)," +
+					"`credential` text," +
+					"`ca_data` text," +
+					"`insecure` boolean," +
+					"`namespace` varchar(64)," +
+					"`app_label` varchar(64)," +
+					"`uid` int," +
+					"`status` varchar(16)," +
+					"`version` varchar(64)," +
+					"`message` text," +
+					"`checked_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_k8s_cluster_deleted_at ON `k8s_cluster`(deleted_at)",
+				"CREATE INDEX idx_k8s_cluster_zone_code ON `k8s_cluster`(zone_code)",
+				"CREATE UNIQUE INDEX uix_k8s_cluster_name ON `k8s_cluster`(`name`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `k8s_cluster`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE k8s_cluster (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"name varchar(64)," +
+					"env jsonb," +
+					"zone_code varchar(64)," +
+					"zone_name varchar(64)," +
+					"server varchar(255)," +
+					"auth_type varchar(16)," +
+					"credential text," +
+					"ca_data text," +
+					"insecure boolean," +
+					"namespace varchar(64)," +
+					"app_label varchar(64)," +
+					"uid integer," +
+					"status varchar(16)," +
+					"version varchar(64)," +
+					"message text," +
+					"checked_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_k8s_cluster_deleted_at ON k8s_cluster (deleted_at)",
+				"CREATE INDEX idx_k8s_cluster_zone_code ON k8s_cluster (zone_code)",
+				"CREATE UNIQUE INDEX uix_k8s_cluster_name ON k8s_cluster (name)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS k8s_cluster",
+			},
+		},
+	})
+}
@@ -0,0 +1,87 @@
+package migration
+
+// v27 配置、流水线环境晋升
+func init() {
+	register(Migration{
+		Version: 27,
+		Name:    "promotion",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `promotion` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`kind` varchar(16)," +
+					"`app_name` varchar(128)," +
+					"`name` varchar(64)," +
+					"`source_id` int unsigned," +
+					"`source_env` varchar(64)," +
+					"`source_zone` varchar(64)," +
+					"`source_version` varchar(64)," +
+					"`target_id` int unsigned," +
+					"`target_env` varchar(64)," +
+					"`target_zone` varchar(64)," +
+					"`content` longtext," +
+					"`target_content` longtext," +
+					"`reason` varchar(512)," +
+					"`status` varchar(16)," +
+					"`uid` int," +
+					"`reviewer_uid` int," +
+					"`review_comment` varchar(512)," +
+					"`reviewed_at` DATETIME NULL," +
+					"`applied_id` int unsigned," +
+					"`error` varchar(512)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_promotion_uid ON `promotion`(`uid`)",
+				"CREATE INDEX idx_promotion_deleted_at ON `promotion`(deleted_at)",
+				"CREATE INDEX idx_promotion_kind ON `promotion`(`kind`)",
+				"CREATE INDEX idx_promotion_app_name ON `promotion`(app_name)",
+				"CREATE INDEX idx_promotion_status ON `promotion`(`status`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `promotion`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE promotion (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"kind varchar(16)," +
+					"app_name varchar(128)," +
+					"name varchar(64)," +
+					"source_id integer," +
+					"source_env varchar(64)," +
+					"source_zone varchar(64)," +
+					"source_version varchar(64)," +
+					"target_id integer," +
+					"target_env varchar(64)," +
+					"target_zone varchar(64)," +
+					"content text," +
+					"target_content text," +
+					"reason varchar(512)," +
+					"status varchar(16)," +
+					"uid integer," +
+					"reviewer_uid integer," +
+					"review_comment varchar(512)," +
+					"reviewed_at timestamp with time zone," +
+					"applied_id integer," +
+					"error varchar(512)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_promotion_deleted_at ON promotion (deleted_at)",
+				"CREATE INDEX idx_promotion_kind ON promotion (kind)",
+				"CREATE INDEX idx_promotion_app_name ON promotion (app_name)",
+				"CREATE INDEX idx_promotion_status ON promotion (status)",
+				"CREATE INDEX idx_promotion_uid ON promotion (uid)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS promotion",
+			},
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package migration
+
+// v28 心跳上报的主机信息
+func init() {
+	register(Migration{
+		Version: 28,
+		Name:    "node_facts",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `node` ADD COLUMN `cpu_count` int NOT NULL DEFAULT 0",
+				"ALTER TABLE `node` ADD COLUMN `mem_total` bigint unsigned NOT NULL DEFAULT 0",
+				"ALTER TABLE `node` ADD COLUMN `os` varchar(128)",
+				"ALTER TABLE `node` ADD COLUMN `kernel` varchar(128)",
+				"ALTER TABLE `node` ADD COLUMN `arch` varchar(32)",
+				"ALTER TABLE `node` ADD COLUMN `ips` varchar(512)",
+				"ALTER TABLE `node` ADD COLUMN `container_runtime` varchar(32)",
+				"ALTER TABLE `node` ADD COLUMN `facts_time` bigint NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE `node` DROP COLUMN `facts_time`",
+				"ALTER TABLE `node` DROP COLUMN `container_runtime`",
+				"ALTER TABLE `node` DROP COLUMN `ips`",
+				"ALTER TABLE `node` DROP COLUMN `arch`",
+				"ALTER TABLE `node` DROP COLUMN `kernel`",
+				"ALTER TABLE `node` DROP COLUMN `os`",
+				"ALTER TABLE `node` DROP COLUMN `mem_total`",
+				"ALTER TABLE `node` DROP COLUMN `cpu_count`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE node ADD COLUMN cpu_count integer NOT NULL DEFAULT 0",
+				"ALTER TABLE node ADD COLUMN mem_total bigint NOT NULL DEFAULT 0",
+				"ALTER TABLE node ADD COLUMN os varchar(128)",
+				"ALTER TABLE node ADD COLUMN kernel varchar(128)",
+				"ALTER TABLE node ADD COLUMN arch varchar(32)",
+				"ALTER TABLE node ADD COLUMN ips varchar(512)",
+				"ALTER TABLE node ADD COLUMN container_runtime varchar(32)",
+				"ALTER TABLE node ADD COLUMN facts_time bigint NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE node DROP COLUMN facts_time",
+				"ALTER TABLE node DROP COLUMN container_runtime",
+				"ALTER TABLE node DROP COLUMN ips",
+				"ALTER TABLE node DROP COLUMN arch",
+				"ALTER TABLE node DROP COLUMN kernel",
+				"ALTER TABLE node DROP COLUMN os",
+				"ALTER TABLE node DROP COLUMN mem_total",
+				"ALTER TABLE node DROP COLUMN cpu_count",
+			},
+		},
+	})
+}
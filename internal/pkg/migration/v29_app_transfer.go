@@ -0,0 +1,67 @@
+package migration
+
+// v29 应用转移
+func init() {
+	register(Migration{
+		Version: 29,
+		Name:    "app_transfer",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `app_transfer` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`app_name` varchar(128)," +
+					"`from_team_id` int unsigned," +
+					"`to_team_id` int unsigned," +
+					"`users` json," +
+					"`reason` varchar(512)," +
+					"`status` varchar(16)," +
+					"`uid` int," +
+					"`reviewer_uid` int," +
+					"`review_comment` varchar(512)," +
+					"`reviewed_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_app_transfer_to_team_id ON `app_transfer`(to_team_id)",
+				"CREATE INDEX idx_app_transfer_status ON `app_transfer`(`status`)",
+				"CREATE INDEX idx_app_transfer_uid ON `app_transfer`(`uid`)",
+				"CREATE INDEX idx_app_transfer_deleted_at ON `app_transfer`(deleted_at)",
+				"CREATE INDEX idx_app_transfer_app_name ON `app_transfer`(app_name)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `app_transfer`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE app_transfer (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"app_name varchar(128)," +
+					"from_team_id integer," +
+					"to_team_id integer," +
+					"users jsonb," +
+					"reason varchar(512)," +
+					"status varchar(16)," +
+					"uid integer," +
+					"reviewer_uid integer," +
+					"review_comment varchar(512)," +
+					"reviewed_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_app_transfer_deleted_at ON app_transfer (deleted_at)",
+				"CREATE INDEX idx_app_transfer_app_name ON app_transfer (app_name)",
+				"CREATE INDEX idx_app_transfer_to_team_id ON app_transfer (to_team_id)",
+				"CREATE INDEX idx_app_transfer_status ON app_transfer (status)",
+				"CREATE INDEX idx_app_transfer_uid ON app_transfer (uid)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS app_transfer",
+			},
+		},
+	})
+}
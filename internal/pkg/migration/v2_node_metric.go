@@ -0,0 +1,57 @@
+package migration
+
+// v2 agent 心跳上报的主机资源指标
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "node_metric",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `node_metric` (" +
+					"`id` int AUTO_INCREMENT NOT NULL," +
+					"`host_name` varchar(255) NOT NULL," +
+					"`cpu_percent` double NOT NULL," +
+					"`mem_total` bigint unsigned NOT NULL," +
+					"`mem_used` bigint unsigned NOT NULL," +
+					"`mem_percent` double NOT NULL," +
+					"`disk_total` bigint unsigned NOT NULL," +
+					"`disk_used` bigint unsigned NOT NULL," +
+					"`disk_percent` double NOT NULL," +
+					"`load1` double NOT NULL," +
+					"`load5` double NOT NULL," +
+					"`load15` double NOT NULL," +
+					"`create_time` bigint NOT NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_host_time ON `node_metric`(host_name, create_time)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `node_metric`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE node_metric (" +
+					"id serial NOT NULL," +
+					"host_name text NOT NULL," +
+					"cpu_percent numeric NOT NULL," +
+					"mem_total bigint NOT NULL," +
+					"mem_used bigint NOT NULL," +
+					"mem_percent numeric NOT NULL," +
+					"disk_total bigint NOT NULL," +
+					"disk_used bigint NOT NULL," +
+					"disk_percent numeric NOT NULL," +
+					"load1 numeric NOT NULL," +
+					"load5 numeric NOT NULL," +
+					"load15 numeric NOT NULL," +
+					"create_time bigint NOT NULL," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_host_time ON node_metric (host_name,create_time)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS node_metric",
+			},
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package migration
+
+// v30 实例部署记录
+func init() {
+	register(Migration{
+		Version: 30,
+		Name:    "deployment",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `deployment` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`app_name` varchar(128)," +
+					"`host_name` varchar(128)," +
+					"`env` varchar(32)," +
+					"`zone_code` varchar(64)," +
+					"`version` varchar(128)," +
+					"`prev_version` varchar(128)," +
+					"`commit` varchar(64)," +
+					"`source` varchar(16)," +
+					"`pipeline_task_id` int unsigned," +
+					"`pipeline_url` varchar(512)," +
+					"`operator` varchar(64)," +
+					"`deployed_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_deployment_deleted_at ON `deployment`(deleted_at)",
+				"CREATE INDEX idx_app_host ON `deployment`(app_name, host_name)",
+				"CREATE INDEX idx_deployment_env ON `deployment`(`env`)",
+				"CREATE INDEX idx_deployment_deployed_at ON `deployment`(deployed_at)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `deployment`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE deployment (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"app_name varchar(128)," +
+					"host_name varchar(128)," +
+					"env varchar(32)," +
+					"zone_code varchar(64)," +
+					"version varchar(128)," +
+					"prev_version varchar(128)," +
+					"commit varchar(64)," +
+					"source varchar(16)," +
+					"pipeline_task_id integer," +
+					"pipeline_url varchar(512)," +
+					"operator varchar(64)," +
+					"deployed_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_deployment_env ON deployment (env)",
+				"CREATE INDEX idx_deployment_deployed_at ON deployment (deployed_at)",
+				"CREATE INDEX idx_deployment_deleted_at ON deployment (deleted_at)",
+				"CREATE INDEX idx_app_host ON deployment (app_name,host_name)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS deployment",
+			},
+		},
+	})
+}
@@ -0,0 +1,47 @@
+package migration
+
+// v31 应用、节点、流水线标签，通知规则按应用标签匹配
+func init() {
+	register(Migration{
+		Version: 31,
+		Name:    "entity_tag",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `entity_tag` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`entity_type` varchar(16)," +
+					"`entity_key` varchar(128)," +
+					"`tag` varchar(64)," +
+					"`created_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_entity_tag_tag ON `entity_tag`(`tag`)",
+				"CREATE UNIQUE INDEX idx_entity_tag ON `entity_tag`(entity_type, entity_key, `tag`)",
+				"ALTER TABLE `notify_rule` ADD COLUMN `tags` varchar(512)",
+			},
+			Down: []string{
+				"ALTER TABLE `notify_rule` DROP COLUMN `tags`",
+				"DROP TABLE IF EXISTS `entity_tag`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE entity_tag (" +
+					"id serial," +
+					"entity_type varchar(16)," +
+					"entity_key varchar(128)," +
+					"tag varchar(64)," +
+					"created_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_entity_tag_tag ON entity_tag (tag)",
+				"CREATE UNIQUE INDEX idx_entity_tag ON entity_tag (entity_type,entity_key,tag)",
+				"ALTER TABLE notify_rule ADD COLUMN tags varchar(512)",
+			},
+			Down: []string{
+				"ALTER TABLE notify_rule DROP COLUMN tags",
+				"DROP TABLE IF EXISTS entity_tag",
+			},
+		},
+	})
+}
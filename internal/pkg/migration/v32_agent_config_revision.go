@@ -0,0 +1,37 @@
+package migration
+
+// v32 agent 配置保存已发布的内容和发布序号。
+// 之前下发的是编辑中的内容，已发布过的配置以当前内容作为已发布内容，与 agent 上生效的配置一致；
+// 序号取原先各配置版本之和，不小于 agent 已收到的版本
+func init() {
+	register(Migration{
+		Version: 32,
+		Name:    "agent_config_revision",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `agent_config` ADD COLUMN `published_content` json",
+				"ALTER TABLE `agent_config` ADD COLUMN `revision` int unsigned NOT NULL DEFAULT 0",
+				"UPDATE `agent_config` SET `published_content` = `content` WHERE `published_version` > 0",
+				"UPDATE `agent_config` SET `revision` = (" +
+					"SELECT total FROM (SELECT COALESCE(SUM(`version`), 0) AS total FROM `agent_config`) t" +
+					")",
+			},
+			Down: []string{
+				"ALTER TABLE `agent_config` DROP COLUMN `revision`",
+				"ALTER TABLE `agent_config` DROP COLUMN `published_content`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE agent_config ADD COLUMN published_content jsonb",
+				"ALTER TABLE agent_config ADD COLUMN revision integer NOT NULL DEFAULT 0",
+				"UPDATE agent_config SET published_content = content WHERE published_version > 0",
+				"UPDATE agent_config SET revision = (SELECT COALESCE(SUM(version), 0) FROM agent_config)",
+			},
+			Down: []string{
+				"ALTER TABLE agent_config DROP COLUMN revision",
+				"ALTER TABLE agent_config DROP COLUMN published_content",
+			},
+		},
+	})
+}
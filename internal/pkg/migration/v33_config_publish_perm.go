@@ -0,0 +1,39 @@
+package migration
+
+// v33 配置发布拆分为单独的 config:publish 权限。
+// 之前 config:write 同时允许发布，已有 config:write 的用户组补充 config:publish，升级后权限不变；
+// 回滚时删除与 config:write 同时存在的 config:publish
+func init() {
+	register(Migration{
+		Version: 33,
+		Name:    "config_publish_perm",
+		MySQL: Script{
+			Up: []string{
+				"INSERT INTO `casbin_policy_auth` (`created_at`, `updated_at`, `sub`, `obj`, `act`, `type`) " +
+					"SELECT NOW(), NOW(), w.`sub`, w.`obj`, 'config:publish', w.`type` FROM `casbin_policy_auth` w " +
+					"LEFT JOIN `casbin_policy_auth` p ON p.`sub` = w.`sub` AND p.`obj` = w.`obj` " +
+					"AND p.`type` = 'app' AND p.`act` = 'config:publish' AND p.`deleted_at` IS NULL " +
+					"WHERE w.`type` = 'app' AND w.`act` = 'config:write' AND w.`deleted_at` IS NULL AND p.`id` IS NULL",
+			},
+			Down: []string{
+				"DELETE p FROM `casbin_policy_auth` p JOIN `casbin_policy_auth` w ON w.`sub` = p.`sub` AND w.`obj` = p.`obj` " +
+					"AND w.`type` = 'app' AND w.`act` = 'config:write' AND w.`deleted_at` IS NULL " +
+					"WHERE p.`type` = 'app' AND p.`act` = 'config:publish'",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"INSERT INTO casbin_policy_auth (created_at, updated_at, sub, obj, act, type) " +
+					"SELECT now(), now(), w.sub, w.obj, 'config:publish', w.type FROM casbin_policy_auth w " +
+					"LEFT JOIN casbin_policy_auth p ON p.sub = w.sub AND p.obj = w.obj " +
+					"AND p.type = 'app' AND p.act = 'config:publish' AND p.deleted_at IS NULL " +
+					"WHERE w.type = 'app' AND w.act = 'config:write' AND w.deleted_at IS NULL AND p.id IS NULL",
+			},
+			Down: []string{
+				"DELETE FROM casbin_policy_auth p USING casbin_policy_auth w " +
+					"WHERE w.sub = p.sub AND w.obj = p.obj AND w.type = 'app' AND w.act = 'config:write' AND w.deleted_at IS NULL " +
+					"AND p.type = 'app' AND p.act = 'config:publish'",
+			},
+		},
+	})
+}
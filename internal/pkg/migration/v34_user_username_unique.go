@@ -0,0 +1,26 @@
+package migration
+
+// v34 用户名唯一，避免第三方登录创建与本地账号同名的用户。
+// 已有重复用户名时迁移失败，需要先合并或重命名重复的账号
+func init() {
+	register(Migration{
+		Version: 34,
+		Name:    "user_username_unique",
+		MySQL: Script{
+			Up: []string{
+				"CREATE UNIQUE INDEX uix_user_username ON `user`(`username`)",
+			},
+			Down: []string{
+				"DROP INDEX uix_user_username ON `user`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				`CREATE UNIQUE INDEX uix_user_username ON "user" (username)`,
+			},
+			Down: []string{
+				"DROP INDEX IF EXISTS uix_user_username",
+			},
+		},
+	})
+}
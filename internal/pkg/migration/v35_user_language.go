@@ -0,0 +1,45 @@
+package migration
+
+// v35 用户语言设置
+func init() {
+	register(Migration{
+		Version: 35,
+		Name:    "user_language",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `user_language` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`uid` int," +
+					"`language` varchar(16)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_user_language_deleted_at ON `user_language`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_language_uid ON `user_language`(`uid`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `user_language`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE user_language (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"uid integer," +
+					"language varchar(16)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_user_language_deleted_at ON user_language (deleted_at)",
+				"CREATE UNIQUE INDEX uix_user_language_uid ON user_language (uid)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS user_language",
+			},
+		},
+	})
+}
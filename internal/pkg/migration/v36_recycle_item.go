@@ -0,0 +1,63 @@
+package migration
+
+// v36 回收站
+func init() {
+	register(Migration{
+		Version: 36,
+		Name:    "recycle_item",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `recycle_item` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`kind` varchar(32)," +
+					"`object_id` int unsigned," +
+					"`name` varchar(255)," +
+					"`app_name` varchar(255)," +
+					"`env` varchar(32)," +
+					"`snapshot` longtext," +
+					"`deleted_by` int," +
+					"`expire_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_recycle_item_deleted_at ON `recycle_item`(deleted_at)",
+				"CREATE INDEX idx_recycle_item_kind ON `recycle_item`(`kind`)",
+				"CREATE INDEX idx_recycle_item_object_id ON `recycle_item`(`object_id`)",
+				"CREATE INDEX idx_recycle_item_app_name ON `recycle_item`(`app_name`)",
+				"CREATE INDEX idx_recycle_item_expire_at ON `recycle_item`(`expire_at`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `recycle_item`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE recycle_item (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"kind varchar(32)," +
+					"object_id integer," +
+					"name varchar(255)," +
+					"app_name varchar(255)," +
+					"env varchar(32)," +
+					"snapshot text," +
+					"deleted_by integer," +
+					"expire_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_recycle_item_deleted_at ON recycle_item (deleted_at)",
+				"CREATE INDEX idx_recycle_item_kind ON recycle_item (kind)",
+				"CREATE INDEX idx_recycle_item_object_id ON recycle_item (object_id)",
+				"CREATE INDEX idx_recycle_item_app_name ON recycle_item (app_name)",
+				"CREATE INDEX idx_recycle_item_expire_at ON recycle_item (expire_at)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS recycle_item",
+			},
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package migration
+
+// v37 测试任务制品，如 HTML 测试报告
+func init() {
+	register(Migration{
+		Version: 37,
+		Name:    "test_task_artifact",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `test_task_artifact` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`task_id` int unsigned," +
+					"`step_name` varchar(255)," +
+					"`name` varchar(255)," +
+					"`content_type` varchar(255)," +
+					"`size` int," +
+					"`content` longblob," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_task_artifact_deleted_at ON `test_task_artifact`(deleted_at)",
+				"CREATE INDEX idx_test_task_artifact_task_id ON `test_task_artifact`(`task_id`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `test_task_artifact`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE test_task_artifact (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"task_id integer," +
+					"step_name varchar(255)," +
+					"name varchar(255)," +
+					"content_type varchar(255)," +
+					"size integer," +
+					"content bytea," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_task_artifact_deleted_at ON test_task_artifact (deleted_at)",
+				"CREATE INDEX idx_test_task_artifact_task_id ON test_task_artifact (task_id)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS test_task_artifact",
+			},
+		},
+	})
+}
@@ -0,0 +1,51 @@
+package migration
+
+// v38 单元测试分片，流水线分片数和各个包的测试耗时
+func init() {
+	register(Migration{
+		Version: 38,
+		Name:    "test_shard",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `unit_test_shards` int NOT NULL DEFAULT 0",
+				"CREATE TABLE `test_package_timing` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`app_name` varchar(255)," +
+					"`package` varchar(512)," +
+					"`elapsed` double," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_package_timing_deleted_at ON `test_package_timing`(deleted_at)",
+				"CREATE INDEX idx_test_package_timing_app_name ON `test_package_timing`(`app_name`)",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `unit_test_shards`",
+				"DROP TABLE IF EXISTS `test_package_timing`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN unit_test_shards integer NOT NULL DEFAULT 0",
+				"CREATE TABLE test_package_timing (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"app_name varchar(255)," +
+					"package varchar(512)," +
+					"elapsed double precision," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_package_timing_deleted_at ON test_package_timing (deleted_at)",
+				"CREATE INDEX idx_test_package_timing_app_name ON test_package_timing (app_name)",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN unit_test_shards",
+				"DROP TABLE IF EXISTS test_package_timing",
+			},
+		},
+	})
+}
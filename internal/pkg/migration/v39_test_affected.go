@@ -0,0 +1,37 @@
+package migration
+
+// v39 单元测试影响分析，只执行变更影响的包并定期全量执行
+func init() {
+	register(Migration{
+		Version: 39,
+		Name:    "test_affected",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `unit_test_affected` boolean NOT NULL DEFAULT false",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `base_branch` varchar(255)",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `full_run_hours` int NOT NULL DEFAULT 0",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `last_full_run_at` DATETIME NULL",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `unit_test_affected`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `base_branch`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `full_run_hours`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `last_full_run_at`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN unit_test_affected boolean NOT NULL DEFAULT false",
+				"ALTER TABLE test_pipeline ADD COLUMN base_branch varchar(255)",
+				"ALTER TABLE test_pipeline ADD COLUMN full_run_hours integer NOT NULL DEFAULT 0",
+				"ALTER TABLE test_pipeline ADD COLUMN last_full_run_at timestamp with time zone",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN unit_test_affected",
+				"ALTER TABLE test_pipeline DROP COLUMN base_branch",
+				"ALTER TABLE test_pipeline DROP COLUMN full_run_hours",
+				"ALTER TABLE test_pipeline DROP COLUMN last_full_run_at",
+			},
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package migration
+
+// v3 下发给 agent 的配置
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "agent_config",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `agent_config` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`env` varchar(32)," +
+					"`zone_code` varchar(64)," +
+					"`host_name` varchar(255)," +
+					"`content` json," +
+					"`version` int unsigned," +
+					"`published_version` int unsigned," +
+					"`created_by` int unsigned," +
+					"`updated_by` int unsigned," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_agent_config_deleted_at ON `agent_config`(deleted_at)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `agent_config`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE agent_config (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"env varchar(32)," +
+					"zone_code varchar(64)," +
+					"host_name text," +
+					"content jsonb," +
+					"version integer," +
+					"published_version integer," +
+					"created_by integer," +
+					"updated_by integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_agent_config_deleted_at ON agent_config (deleted_at)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS agent_config",
+			},
+		},
+	})
+}
@@ -0,0 +1,73 @@
+package migration
+
+// v40 依赖漏洞扫描
+func init() {
+	register(Migration{
+		Version: 40,
+		Name:    "test_vulnerability",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `vuln_check` boolean NOT NULL DEFAULT false",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `vuln_gate` varchar(16)",
+				"CREATE TABLE `test_vulnerability` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`task_id` int unsigned," +
+					"`step_name` varchar(255)," +
+					"`app_name` varchar(255)," +
+					"`vuln_id` varchar(64)," +
+					"`aliases` varchar(512)," +
+					"`module` varchar(512)," +
+					"`version` varchar(128)," +
+					"`fixed_version` varchar(128)," +
+					"`severity` varchar(16)," +
+					"`called` boolean," +
+					"`summary` text," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_vulnerability_deleted_at ON `test_vulnerability`(deleted_at)",
+				"CREATE INDEX idx_test_vulnerability_task_id ON `test_vulnerability`(`task_id`)",
+				"CREATE INDEX idx_test_vulnerability_app_name ON `test_vulnerability`(`app_name`)",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `vuln_check`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `vuln_gate`",
+				"DROP TABLE IF EXISTS `test_vulnerability`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN vuln_check boolean NOT NULL DEFAULT false",
+				"ALTER TABLE test_pipeline ADD COLUMN vuln_gate varchar(16)",
+				"CREATE TABLE test_vulnerability (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"task_id integer," +
+					"step_name varchar(255)," +
+					"app_name varchar(255)," +
+					"vuln_id varchar(64)," +
+					"aliases varchar(512)," +
+					"module varchar(512)," +
+					"version varchar(128)," +
+					"fixed_version varchar(128)," +
+					"severity varchar(16)," +
+					"called boolean," +
+					"summary text," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_vulnerability_deleted_at ON test_vulnerability (deleted_at)",
+				"CREATE INDEX idx_test_vulnerability_task_id ON test_vulnerability (task_id)",
+				"CREATE INDEX idx_test_vulnerability_app_name ON test_vulnerability (app_name)",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN vuln_check",
+				"ALTER TABLE test_pipeline DROP COLUMN vuln_gate",
+				"DROP TABLE IF EXISTS test_vulnerability",
+			},
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package migration
+
+// v41 依赖许可证合规检查
+func init() {
+	register(Migration{
+		Version: 41,
+		Name:    "license_check",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `license_check` boolean NOT NULL DEFAULT false",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `license_check`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN license_check boolean NOT NULL DEFAULT false",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN license_check",
+			},
+		},
+	})
+}
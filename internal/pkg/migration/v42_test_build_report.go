@@ -0,0 +1,69 @@
+package migration
+
+// v42 构建报告
+func init() {
+	register(Migration{
+		Version: 42,
+		Name:    "test_build_report",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `build_report` boolean NOT NULL DEFAULT false",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `build_package` varchar(128)",
+				"CREATE TABLE `test_build_report` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`task_id` int unsigned," +
+					"`pipeline_id` int unsigned," +
+					"`step_name` varchar(255)," +
+					"`app_name` varchar(255)," +
+					"`branch` varchar(255)," +
+					"`package` varchar(255)," +
+					"`binary_size` bigint," +
+					"`build_seconds` double," +
+					"`packages` json," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_build_report_deleted_at ON `test_build_report`(deleted_at)",
+				"CREATE INDEX idx_test_build_report_task_id ON `test_build_report`(`task_id`)",
+				"CREATE INDEX idx_test_build_report_pipeline_id ON `test_build_report`(`pipeline_id`)",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `build_report`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `build_package`",
+				"DROP TABLE IF EXISTS `test_build_report`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN build_report boolean NOT NULL DEFAULT false",
+				"ALTER TABLE test_pipeline ADD COLUMN build_package varchar(128)",
+				"CREATE TABLE test_build_report (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"task_id integer," +
+					"pipeline_id integer," +
+					"step_name varchar(255)," +
+					"app_name varchar(255)," +
+					"branch varchar(255)," +
+					"package varchar(255)," +
+					"binary_size bigint," +
+					"build_seconds double precision," +
+					"packages json," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_build_report_deleted_at ON test_build_report (deleted_at)",
+				"CREATE INDEX idx_test_build_report_task_id ON test_build_report (task_id)",
+				"CREATE INDEX idx_test_build_report_pipeline_id ON test_build_report (pipeline_id)",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN build_report",
+				"ALTER TABLE test_pipeline DROP COLUMN build_package",
+				"DROP TABLE IF EXISTS test_build_report",
+			},
+		},
+	})
+}
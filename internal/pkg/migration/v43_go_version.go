@@ -0,0 +1,29 @@
+package migration
+
+// v43 流水线 Go 版本
+func init() {
+	register(Migration{
+		Version: 43,
+		Name:    "go_version",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `go_version` varchar(32)",
+				"ALTER TABLE `test_pipeline_task` ADD COLUMN `go_version` varchar(64)",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `go_version`",
+				"ALTER TABLE `test_pipeline_task` DROP COLUMN `go_version`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN go_version varchar(32)",
+				"ALTER TABLE test_pipeline_task ADD COLUMN go_version varchar(64)",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN go_version",
+				"ALTER TABLE test_pipeline_task DROP COLUMN go_version",
+			},
+		},
+	})
+}
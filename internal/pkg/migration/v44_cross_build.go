@@ -0,0 +1,29 @@
+package migration
+
+// v44 流水线交叉编译检查
+func init() {
+	register(Migration{
+		Version: 44,
+		Name:    "cross_build",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `cross_build` boolean NOT NULL DEFAULT false",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `cross_build_targets` varchar(255)",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `cross_build`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `cross_build_targets`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN cross_build boolean NOT NULL DEFAULT false",
+				"ALTER TABLE test_pipeline ADD COLUMN cross_build_targets varchar(255)",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN cross_build",
+				"ALTER TABLE test_pipeline DROP COLUMN cross_build_targets",
+			},
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package migration
+
+// v45 构建日志按行索引，用于搜索历史构建日志
+func init() {
+	register(Migration{
+		Version: 45,
+		Name:    "test_log_line",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `test_log_line` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`task_id` int unsigned," +
+					"`pipeline_id` int unsigned," +
+					"`app_name` varchar(64)," +
+					"`env` varchar(32)," +
+					"`zone_code` varchar(32)," +
+					"`step_name` varchar(64)," +
+					"`line` int," +
+					"`content` varchar(1024)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_log_line_task_id ON `test_log_line`(`task_id`)",
+				"CREATE INDEX idx_test_log_line_app_env ON `test_log_line`(`app_name`, `env`, `id`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `test_log_line`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE test_log_line (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"task_id integer," +
+					"pipeline_id integer," +
+					"app_name varchar(64)," +
+					"env varchar(32)," +
+					"zone_code varchar(32)," +
+					"step_name varchar(64)," +
+					"line integer," +
+					"content varchar(1024)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_log_line_task_id ON test_log_line (task_id)",
+				"CREATE INDEX idx_test_log_line_app_env ON test_log_line (app_name, env, id)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS test_log_line",
+			},
+		},
+	})
+}
@@ -0,0 +1,65 @@
+package migration
+
+// v46 覆盖率趋势
+func init() {
+	register(Migration{
+		Version: 46,
+		Name:    "test_coverage",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `test_coverage` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`task_id` int unsigned," +
+					"`pipeline_id` int unsigned," +
+					"`step_name` varchar(255)," +
+					"`app_name` varchar(255)," +
+					"`env` varchar(32)," +
+					"`branch` varchar(255)," +
+					"`statements` int," +
+					"`covered` int," +
+					"`partial` boolean NOT NULL DEFAULT false," +
+					"`packages` json," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_coverage_deleted_at ON `test_coverage`(deleted_at)",
+				"CREATE INDEX idx_test_coverage_task_id ON `test_coverage`(`task_id`)",
+				"CREATE INDEX idx_test_coverage_pipeline_id ON `test_coverage`(`pipeline_id`)",
+				"CREATE INDEX idx_test_coverage_app_env ON `test_coverage`(`app_name`, `env`, `branch`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `test_coverage`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE test_coverage (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"task_id integer," +
+					"pipeline_id integer," +
+					"step_name varchar(255)," +
+					"app_name varchar(255)," +
+					"env varchar(32)," +
+					"branch varchar(255)," +
+					"statements integer," +
+					"covered integer," +
+					"partial boolean NOT NULL DEFAULT false," +
+					"packages json," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_coverage_deleted_at ON test_coverage (deleted_at)",
+				"CREATE INDEX idx_test_coverage_task_id ON test_coverage (task_id)",
+				"CREATE INDEX idx_test_coverage_pipeline_id ON test_coverage (pipeline_id)",
+				"CREATE INDEX idx_test_coverage_app_env ON test_coverage (app_name, env, branch)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS test_coverage",
+			},
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package migration
+
+// v47 worker drain，缩容前不再下发新任务
+func init() {
+	register(Migration{
+		Version: 47,
+		Name:    "worker_drain",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `worker_node` ADD COLUMN `drained` boolean NOT NULL DEFAULT false",
+			},
+			Down: []string{
+				"ALTER TABLE `worker_node` DROP COLUMN `drained`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE worker_node ADD COLUMN drained boolean NOT NULL DEFAULT false",
+			},
+			Down: []string{
+				"ALTER TABLE worker_node DROP COLUMN drained",
+			},
+		},
+	})
+}
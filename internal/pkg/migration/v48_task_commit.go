@@ -0,0 +1,31 @@
+package migration
+
+// v48 任务的提交，排队中同一提交的重复任务合并到最新的任务
+func init() {
+	register(Migration{
+		Version: 48,
+		Name:    "task_commit",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline_task` ADD COLUMN `commit_sha` varchar(64)",
+				"ALTER TABLE `test_pipeline_task` ADD COLUMN `superseded_by` int unsigned NOT NULL DEFAULT 0",
+				"CREATE INDEX idx_test_pipeline_task_commit_sha ON `test_pipeline_task`(`pipeline_id`, `commit_sha`)",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline_task` DROP COLUMN `superseded_by`",
+				"ALTER TABLE `test_pipeline_task` DROP COLUMN `commit_sha`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline_task ADD COLUMN commit_sha varchar(64)",
+				"ALTER TABLE test_pipeline_task ADD COLUMN superseded_by integer NOT NULL DEFAULT 0",
+				"CREATE INDEX idx_test_pipeline_task_commit_sha ON test_pipeline_task (pipeline_id, commit_sha)",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline_task DROP COLUMN superseded_by",
+				"ALTER TABLE test_pipeline_task DROP COLUMN commit_sha",
+			},
+		},
+	})
+}
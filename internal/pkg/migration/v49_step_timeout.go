@@ -0,0 +1,25 @@
+package migration
+
+// v49 流水线各阶段的超时时间，为 0 时使用 worker 的默认值
+func init() {
+	register(Migration{
+		Version: 49,
+		Name:    "step_timeout",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `step_timeout` int NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `step_timeout`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN step_timeout integer NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN step_timeout",
+			},
+		},
+	})
+}
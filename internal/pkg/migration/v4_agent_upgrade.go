@@ -0,0 +1,123 @@
+package migration
+
+// v4 agent 升级包和滚动升级任务
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "agent_upgrade",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `agent_package` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`version` varchar(64)," +
+					"`file_name` varchar(255)," +
+					"`size` bigint," +
+					"`sha256` varchar(64)," +
+					"`created_by` int unsigned," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_agent_package_deleted_at ON `agent_package`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_agent_package_version ON `agent_package`(`version`)",
+				"CREATE TABLE `agent_upgrade` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`version` varchar(64)," +
+					"`zones` json," +
+					"`canary_percent` int unsigned," +
+					"`failure_threshold` int unsigned," +
+					"`zone_index` int," +
+					"`stage` int," +
+					"`status` varchar(32)," +
+					"`reason` varchar(255)," +
+					"`created_by` int unsigned," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_agent_upgrade_deleted_at ON `agent_upgrade`(deleted_at)",
+				"CREATE TABLE `agent_upgrade_node` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`upgrade_id` int unsigned," +
+					"`zone_index` int," +
+					"`stage` int," +
+					"`host_name` varchar(255)," +
+					"`from_version` varchar(255)," +
+					"`status` varchar(32)," +
+					"`dispatched_at` DATETIME NULL," +
+					"`finished_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_agent_upgrade_node_deleted_at ON `agent_upgrade_node`(deleted_at)",
+				"CREATE INDEX idx_agent_upgrade_node_upgrade_id ON `agent_upgrade_node`(upgrade_id)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `agent_upgrade_node`",
+				"DROP TABLE IF EXISTS `agent_upgrade`",
+				"DROP TABLE IF EXISTS `agent_package`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE agent_package (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"version varchar(64)," +
+					"file_name text," +
+					"size bigint," +
+					"sha256 varchar(64)," +
+					"created_by integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_agent_package_deleted_at ON agent_package (deleted_at)",
+				"CREATE UNIQUE INDEX uix_agent_package_version ON agent_package (version)",
+				"CREATE TABLE agent_upgrade (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"version varchar(64)," +
+					"zones jsonb," +
+					"canary_percent integer," +
+					"failure_threshold integer," +
+					"zone_index integer," +
+					"stage integer," +
+					"status varchar(32)," +
+					"reason text," +
+					"created_by integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_agent_upgrade_deleted_at ON agent_upgrade (deleted_at)",
+				"CREATE TABLE agent_upgrade_node (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"upgrade_id integer," +
+					"zone_index integer," +
+					"stage integer," +
+					"host_name text," +
+					"from_version text," +
+					"status varchar(32)," +
+					"dispatched_at timestamp with time zone," +
+					"finished_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_agent_upgrade_node_deleted_at ON agent_upgrade_node (deleted_at)",
+				"CREATE INDEX idx_agent_upgrade_node_upgrade_id ON agent_upgrade_node (upgrade_id)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS agent_upgrade_node",
+				"DROP TABLE IF EXISTS agent_upgrade",
+				"DROP TABLE IF EXISTS agent_package",
+			},
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package migration
+
+// v50 流水线编译检查
+func init() {
+	register(Migration{
+		Version: 50,
+		Name:    "build_check",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `build_check` boolean NOT NULL DEFAULT false",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `build_command` varchar(255)",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `build_check`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `build_command`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN build_check boolean NOT NULL DEFAULT false",
+				"ALTER TABLE test_pipeline ADD COLUMN build_command varchar(255)",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN build_check",
+				"ALTER TABLE test_pipeline DROP COLUMN build_command",
+			},
+		},
+	})
+}
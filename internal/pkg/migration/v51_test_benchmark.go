@@ -0,0 +1,71 @@
+package migration
+
+// v51 基准测试结果
+func init() {
+	register(Migration{
+		Version: 51,
+		Name:    "test_benchmark",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `benchmark` boolean NOT NULL DEFAULT false",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `benchmark_packages` varchar(255)",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `benchmark_flags` varchar(255)",
+				"CREATE TABLE `test_benchmark` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`task_id` int unsigned," +
+					"`pipeline_id` int unsigned," +
+					"`step_name` varchar(255)," +
+					"`app_name` varchar(255)," +
+					"`branch` varchar(255)," +
+					"`commit_sha` varchar(64)," +
+					"`baseline_task_id` int unsigned NOT NULL DEFAULT 0," +
+					"`results` json," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_test_benchmark_deleted_at ON `test_benchmark`(deleted_at)",
+				"CREATE INDEX idx_test_benchmark_task_id ON `test_benchmark`(`task_id`)",
+				"CREATE INDEX idx_test_benchmark_pipeline_id ON `test_benchmark`(`pipeline_id`, `commit_sha`)",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `benchmark`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `benchmark_packages`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `benchmark_flags`",
+				"DROP TABLE IF EXISTS `test_benchmark`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN benchmark boolean NOT NULL DEFAULT false",
+				"ALTER TABLE test_pipeline ADD COLUMN benchmark_packages varchar(255)",
+				"ALTER TABLE test_pipeline ADD COLUMN benchmark_flags varchar(255)",
+				"CREATE TABLE test_benchmark (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"task_id integer," +
+					"pipeline_id integer," +
+					"step_name varchar(255)," +
+					"app_name varchar(255)," +
+					"branch varchar(255)," +
+					"commit_sha varchar(64)," +
+					"baseline_task_id integer NOT NULL DEFAULT 0," +
+					"results json," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_test_benchmark_deleted_at ON test_benchmark (deleted_at)",
+				"CREATE INDEX idx_test_benchmark_task_id ON test_benchmark (task_id)",
+				"CREATE INDEX idx_test_benchmark_pipeline_id ON test_benchmark (pipeline_id, commit_sha)",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN benchmark",
+				"ALTER TABLE test_pipeline DROP COLUMN benchmark_packages",
+				"ALTER TABLE test_pipeline DROP COLUMN benchmark_flags",
+				"DROP TABLE IF EXISTS test_benchmark",
+			},
+		},
+	})
+}
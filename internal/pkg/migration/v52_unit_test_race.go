@@ -0,0 +1,25 @@
+package migration
+
+// v52 单元测试数据竞争检测
+func init() {
+	register(Migration{
+		Version: 52,
+		Name:    "unit_test_race",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `unit_test_race` boolean NOT NULL DEFAULT false",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `unit_test_race`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN unit_test_race boolean NOT NULL DEFAULT false",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN unit_test_race",
+			},
+		},
+	})
+}
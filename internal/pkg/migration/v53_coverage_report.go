@@ -0,0 +1,29 @@
+package migration
+
+// v53 HTML 覆盖率报告
+func init() {
+	register(Migration{
+		Version: 53,
+		Name:    "coverage_report",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `unit_test_coverage` boolean NOT NULL DEFAULT false",
+				"ALTER TABLE `test_coverage` ADD COLUMN `report` varchar(255)",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `unit_test_coverage`",
+				"ALTER TABLE `test_coverage` DROP COLUMN `report`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN unit_test_coverage boolean NOT NULL DEFAULT false",
+				"ALTER TABLE test_coverage ADD COLUMN report varchar(255)",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN unit_test_coverage",
+				"ALTER TABLE test_coverage DROP COLUMN report",
+			},
+		},
+	})
+}
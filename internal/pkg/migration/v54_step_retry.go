@@ -0,0 +1,29 @@
+package migration
+
+// v54 阶段失败重试
+func init() {
+	register(Migration{
+		Version: 54,
+		Name:    "step_retry",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `step_retries` int NOT NULL DEFAULT 0",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `step_retry_delay` int NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `step_retries`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `step_retry_delay`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN step_retries int NOT NULL DEFAULT 0",
+				"ALTER TABLE test_pipeline ADD COLUMN step_retry_delay int NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN step_retries",
+				"ALTER TABLE test_pipeline DROP COLUMN step_retry_delay",
+			},
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package migration
+
+// v55 任务优先级
+func init() {
+	register(Migration{
+		Version: 55,
+		Name:    "task_priority",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `priority` int NOT NULL DEFAULT 0",
+				"ALTER TABLE `test_pipeline_task` ADD COLUMN `priority` int NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `priority`",
+				"ALTER TABLE `test_pipeline_task` DROP COLUMN `priority`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN priority int NOT NULL DEFAULT 0",
+				"ALTER TABLE test_pipeline_task ADD COLUMN priority int NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN priority",
+				"ALTER TABLE test_pipeline_task DROP COLUMN priority",
+			},
+		},
+	})
+}
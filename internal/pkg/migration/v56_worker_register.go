@@ -0,0 +1,45 @@
+package migration
+
+// v56 worker 注册时上报版本、容量和执行中的任务
+func init() {
+	register(Migration{
+		Version: 56,
+		Name:    "worker_register",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `worker_node` ADD COLUMN `version` varchar(64) NOT NULL DEFAULT ''",
+				"ALTER TABLE `worker_node` ADD COLUMN `capacity` int NOT NULL DEFAULT 0",
+				"ALTER TABLE `worker_node` ADD COLUMN `running` int NOT NULL DEFAULT 0",
+				"ALTER TABLE `worker_node` ADD COLUMN `queue_depth` int NOT NULL DEFAULT 0",
+				"ALTER TABLE `worker_node` ADD COLUMN `started_at` datetime NULL",
+				"ALTER TABLE `worker_node` ADD COLUMN `tasks` json NULL",
+			},
+			Down: []string{
+				"ALTER TABLE `worker_node` DROP COLUMN `version`",
+				"ALTER TABLE `worker_node` DROP COLUMN `capacity`",
+				"ALTER TABLE `worker_node` DROP COLUMN `running`",
+				"ALTER TABLE `worker_node` DROP COLUMN `queue_depth`",
+				"ALTER TABLE `worker_node` DROP COLUMN `started_at`",
+				"ALTER TABLE `worker_node` DROP COLUMN `tasks`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE worker_node ADD COLUMN version varchar(64) NOT NULL DEFAULT ''",
+				"ALTER TABLE worker_node ADD COLUMN capacity int NOT NULL DEFAULT 0",
+				"ALTER TABLE worker_node ADD COLUMN running int NOT NULL DEFAULT 0",
+				"ALTER TABLE worker_node ADD COLUMN queue_depth int NOT NULL DEFAULT 0",
+				"ALTER TABLE worker_node ADD COLUMN started_at timestamp with time zone NULL",
+				"ALTER TABLE worker_node ADD COLUMN tasks json NULL",
+			},
+			Down: []string{
+				"ALTER TABLE worker_node DROP COLUMN version",
+				"ALTER TABLE worker_node DROP COLUMN capacity",
+				"ALTER TABLE worker_node DROP COLUMN running",
+				"ALTER TABLE worker_node DROP COLUMN queue_depth",
+				"ALTER TABLE worker_node DROP COLUMN started_at",
+				"ALTER TABLE worker_node DROP COLUMN tasks",
+			},
+		},
+	})
+}
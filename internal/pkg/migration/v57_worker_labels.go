@@ -0,0 +1,33 @@
+package migration
+
+// v57 worker 标签，流水线按标签选择执行任务的 worker
+func init() {
+	register(Migration{
+		Version: 57,
+		Name:    "worker_labels",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `worker_node` ADD COLUMN `labels` json NULL",
+				"ALTER TABLE `test_pipeline` ADD COLUMN `worker_labels` json NULL",
+				"ALTER TABLE `test_pipeline_task` ADD COLUMN `worker_labels` json NULL",
+			},
+			Down: []string{
+				"ALTER TABLE `worker_node` DROP COLUMN `labels`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `worker_labels`",
+				"ALTER TABLE `test_pipeline_task` DROP COLUMN `worker_labels`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE worker_node ADD COLUMN labels json NULL",
+				"ALTER TABLE test_pipeline ADD COLUMN worker_labels json NULL",
+				"ALTER TABLE test_pipeline_task ADD COLUMN worker_labels json NULL",
+			},
+			Down: []string{
+				"ALTER TABLE worker_node DROP COLUMN labels",
+				"ALTER TABLE test_pipeline DROP COLUMN worker_labels",
+				"ALTER TABLE test_pipeline_task DROP COLUMN worker_labels",
+			},
+		},
+	})
+}
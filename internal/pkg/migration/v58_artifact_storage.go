@@ -0,0 +1,29 @@
+package migration
+
+// v58 阶段声明的制品路径，制品保存到对象存储
+func init() {
+	register(Migration{
+		Version: 58,
+		Name:    "artifact_storage",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `step_artifacts` json NULL",
+				"ALTER TABLE `test_task_artifact` ADD COLUMN `storage_key` varchar(512) NOT NULL DEFAULT ''",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline` DROP COLUMN `step_artifacts`",
+				"ALTER TABLE `test_task_artifact` DROP COLUMN `storage_key`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN step_artifacts json NULL",
+				"ALTER TABLE test_task_artifact ADD COLUMN storage_key varchar(512) NOT NULL DEFAULT ''",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline DROP COLUMN step_artifacts",
+				"ALTER TABLE test_task_artifact DROP COLUMN storage_key",
+			},
+		},
+	})
+}
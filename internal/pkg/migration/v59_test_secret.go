@@ -0,0 +1,51 @@
+package migration
+
+// v59 流水线阶段的环境变量和密钥
+func init() {
+	register(Migration{
+		Version: 59,
+		Name:    "test_secret",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `step_env` json NULL",
+				"CREATE TABLE `test_secret` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`app_name` varchar(64)," +
+					"`env` varchar(32)," +
+					"`name` varchar(64)," +
+					"`value` text," +
+					"`updated_by` int unsigned," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE UNIQUE INDEX uix_test_secret_app_env_name ON `test_secret`(`app_name`,`env`,`name`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `test_secret`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `step_env`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN step_env json NULL",
+				"CREATE TABLE test_secret (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"app_name varchar(64)," +
+					"env varchar(32)," +
+					"name varchar(64)," +
+					"value text," +
+					"updated_by integer," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE UNIQUE INDEX uix_test_secret_app_env_name ON test_secret (app_name, env, name)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS test_secret",
+				"ALTER TABLE test_pipeline DROP COLUMN step_env",
+			},
+		},
+	})
+}
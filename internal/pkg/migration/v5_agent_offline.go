@@ -0,0 +1,65 @@
+package migration
+
+// v5 agent 离线记录，可用区可以单独设置离线阈值
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "agent_offline",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `node` ADD COLUMN `agent_last_error` varchar(1024)",
+				"CREATE TABLE `agent_offline_event` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`host_name` varchar(128)," +
+					"`env` varchar(255)," +
+					"`zone_code` varchar(255)," +
+					"`last_heartbeat_time` bigint," +
+					"`last_error` varchar(1024)," +
+					"`offline_time` bigint," +
+					"`recover_time` bigint," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_agent_offline_event_host_name ON `agent_offline_event`(host_name)",
+				"CREATE INDEX idx_agent_offline_event_offline_time ON `agent_offline_event`(offline_time)",
+				"CREATE INDEX idx_agent_offline_event_deleted_at ON `agent_offline_event`(deleted_at)",
+				"ALTER TABLE `zone` ADD COLUMN `agent_offline_threshold` int NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE `zone` DROP COLUMN `agent_offline_threshold`",
+				"ALTER TABLE `node` DROP COLUMN `agent_last_error`",
+				"DROP TABLE IF EXISTS `agent_offline_event`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE node ADD COLUMN agent_last_error varchar(1024)",
+				"CREATE TABLE agent_offline_event (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"host_name varchar(128)," +
+					"env text," +
+					"zone_code text," +
+					"last_heartbeat_time bigint," +
+					"last_error varchar(1024)," +
+					"offline_time bigint," +
+					"recover_time bigint," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_agent_offline_event_deleted_at ON agent_offline_event (deleted_at)",
+				"CREATE INDEX idx_agent_offline_event_host_name ON agent_offline_event (host_name)",
+				"CREATE INDEX idx_agent_offline_event_offline_time ON agent_offline_event (offline_time)",
+				"ALTER TABLE zone ADD COLUMN agent_offline_threshold integer NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE zone DROP COLUMN agent_offline_threshold",
+				"ALTER TABLE node DROP COLUMN agent_last_error",
+				"DROP TABLE IF EXISTS agent_offline_event",
+			},
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package migration
+
+// v60 流水线阶段的执行条件和任务的触发事件
+func init() {
+	register(Migration{
+		Version: 60,
+		Name:    "test_step_when",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `test_pipeline` ADD COLUMN `step_when` json NULL",
+				"ALTER TABLE `test_pipeline_task` ADD COLUMN `event` varchar(32) NOT NULL DEFAULT ''",
+			},
+			Down: []string{
+				"ALTER TABLE `test_pipeline_task` DROP COLUMN `event`",
+				"ALTER TABLE `test_pipeline` DROP COLUMN `step_when`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE test_pipeline ADD COLUMN step_when json NULL",
+				"ALTER TABLE test_pipeline_task ADD COLUMN event varchar(32) NOT NULL DEFAULT ''",
+			},
+			Down: []string{
+				"ALTER TABLE test_pipeline_task DROP COLUMN event",
+				"ALTER TABLE test_pipeline DROP COLUMN step_when",
+			},
+		},
+	})
+}
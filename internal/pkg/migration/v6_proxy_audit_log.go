@@ -0,0 +1,67 @@
+package migration
+
+// v6 治理、pprof、Grafana 代理请求审计
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "proxy_audit_log",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `proxy_audit_log` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`uid` int," +
+					"`user_name` varchar(64)," +
+					"`kind` varchar(32)," +
+					"`method` varchar(16)," +
+					"`path` varchar(512)," +
+					"`query` varchar(1024)," +
+					"`app_name` varchar(128)," +
+					"`env` varchar(64)," +
+					"`target` varchar(255)," +
+					"`status` int," +
+					"`latency` bigint," +
+					"`error` varchar(1024)," +
+					"`client_ip` varchar(64)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_proxy_audit_log_user_name ON `proxy_audit_log`(user_name)",
+				"CREATE INDEX idx_proxy_audit_log_kind ON `proxy_audit_log`(`kind`)",
+				"CREATE INDEX idx_proxy_audit_log_target ON `proxy_audit_log`(`target`)",
+				"CREATE INDEX idx_proxy_audit_log_created_at ON `proxy_audit_log`(created_at)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `proxy_audit_log`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE proxy_audit_log (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"uid integer," +
+					"user_name varchar(64)," +
+					"kind varchar(32)," +
+					"method varchar(16)," +
+					"path varchar(512)," +
+					"query varchar(1024)," +
+					"app_name varchar(128)," +
+					"env varchar(64)," +
+					"target varchar(255)," +
+					"status integer," +
+					"latency bigint," +
+					"error varchar(1024)," +
+					"client_ip varchar(64)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_proxy_audit_log_created_at ON proxy_audit_log (created_at)",
+				"CREATE INDEX idx_proxy_audit_log_user_name ON proxy_audit_log (user_name)",
+				"CREATE INDEX idx_proxy_audit_log_kind ON proxy_audit_log (kind)",
+				"CREATE INDEX idx_proxy_audit_log_target ON proxy_audit_log (target)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS proxy_audit_log",
+			},
+		},
+	})
+}
@@ -0,0 +1,61 @@
+package migration
+
+// v7 个人访问令牌
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "personal_token",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `personal_token` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`uid` int," +
+					"`name` varchar(64)," +
+					"`prefix` varchar(16)," +
+					"`token_hash` varchar(64)," +
+					"`scopes` varchar(255)," +
+					"`expires_at` DATETIME NULL," +
+					"`last_used_at` DATETIME NULL," +
+					"`last_used_ip` varchar(64)," +
+					"`revoked_at` DATETIME NULL," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_personal_token_deleted_at ON `personal_token`(deleted_at)",
+				"CREATE INDEX idx_personal_token_uid ON `personal_token`(`uid`)",
+				"CREATE UNIQUE INDEX uix_personal_token_token_hash ON `personal_token`(token_hash)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `personal_token`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE personal_token (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"uid integer," +
+					"name varchar(64)," +
+					"prefix varchar(16)," +
+					"token_hash varchar(64)," +
+					"scopes varchar(255)," +
+					"expires_at timestamp with time zone," +
+					"last_used_at timestamp with time zone," +
+					"last_used_ip varchar(64)," +
+					"revoked_at timestamp with time zone," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_personal_token_deleted_at ON personal_token (deleted_at)",
+				"CREATE INDEX idx_personal_token_uid ON personal_token (uid)",
+				"CREATE UNIQUE INDEX uix_personal_token_token_hash ON personal_token (token_hash)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS personal_token",
+			},
+		},
+	})
+}
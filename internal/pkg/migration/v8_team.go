@@ -0,0 +1,84 @@
+package migration
+
+// v8 团队，应用归属团队
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "team",
+		MySQL: Script{
+			Up: []string{
+				"ALTER TABLE `app` ADD COLUMN `team_id` int unsigned NOT NULL DEFAULT 0 COMMENT '所属团队'",
+				"CREATE INDEX idx_app_team_id ON `app`(team_id)",
+				"CREATE TABLE `team` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`name` varchar(64)," +
+					"`description` varchar(255)," +
+					"`default_actions` varchar(512)," +
+					"`ding_webhook` varchar(512)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_team_deleted_at ON `team`(deleted_at)",
+				"CREATE UNIQUE INDEX uix_team_name ON `team`(`name`)",
+				"CREATE TABLE `team_member` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`updated_at` DATETIME NULL," +
+					"`deleted_at` DATETIME NULL," +
+					"`team_id` int unsigned," +
+					"`uid` int," +
+					"`role` varchar(16)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_team_member_deleted_at ON `team_member`(deleted_at)",
+				"CREATE INDEX idx_team_member_team_id ON `team_member`(team_id)",
+				"CREATE INDEX idx_team_member_uid ON `team_member`(`uid`)",
+			},
+			Down: []string{
+				"ALTER TABLE `app` DROP COLUMN `team_id`",
+				"DROP TABLE IF EXISTS `team_member`",
+				"DROP TABLE IF EXISTS `team`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"ALTER TABLE app ADD COLUMN team_id integer NOT NULL DEFAULT 0",
+				"COMMENT ON COLUMN app.team_id IS '所属团队'",
+				"CREATE INDEX idx_app_team_id ON app (team_id)",
+				"CREATE TABLE team (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"name varchar(64)," +
+					"description varchar(255)," +
+					"default_actions varchar(512)," +
+					"ding_webhook varchar(512)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_team_deleted_at ON team (deleted_at)",
+				"CREATE UNIQUE INDEX uix_team_name ON team (name)",
+				"CREATE TABLE team_member (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"updated_at timestamp with time zone," +
+					"deleted_at timestamp with time zone," +
+					"team_id integer," +
+					"uid integer," +
+					"role varchar(16)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_team_member_deleted_at ON team_member (deleted_at)",
+				"CREATE INDEX idx_team_member_team_id ON team_member (team_id)",
+				"CREATE INDEX idx_team_member_uid ON team_member (uid)",
+			},
+			Down: []string{
+				"ALTER TABLE app DROP COLUMN team_id",
+				"DROP TABLE IF EXISTS team_member",
+				"DROP TABLE IF EXISTS team",
+			},
+		},
+	})
+}
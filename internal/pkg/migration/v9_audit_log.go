@@ -0,0 +1,77 @@
+package migration
+
+// v9 操作审计
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "audit_log",
+		MySQL: Script{
+			Up: []string{
+				"CREATE TABLE `audit_log` (" +
+					"`id` int unsigned AUTO_INCREMENT," +
+					"`created_at` DATETIME NULL," +
+					"`uid` int," +
+					"`user_name` varchar(64)," +
+					"`method` varchar(16)," +
+					"`path` varchar(255)," +
+					"`action` varchar(128)," +
+					"`resource` varchar(32)," +
+					"`resource_id` varchar(255)," +
+					"`app_name` varchar(128)," +
+					"`env` varchar(64)," +
+					"`request` text," +
+					"`before` text," +
+					"`after` text," +
+					"`status` int," +
+					"`code` int," +
+					"`message` varchar(512)," +
+					"`latency` bigint," +
+					"`client_ip` varchar(64)," +
+					"PRIMARY KEY (`id`)" +
+					") ENGINE=InnoDB",
+				"CREATE INDEX idx_audit_log_app_name ON `audit_log`(app_name)",
+				"CREATE INDEX idx_audit_log_created_at ON `audit_log`(created_at)",
+				"CREATE INDEX idx_audit_log_user_name ON `audit_log`(user_name)",
+				"CREATE INDEX idx_audit_log_path ON `audit_log`(`path`)",
+				"CREATE INDEX idx_audit_log_resource ON `audit_log`(`resource`)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS `audit_log`",
+			},
+		},
+		Postgres: Script{
+			Up: []string{
+				"CREATE TABLE audit_log (" +
+					"id serial," +
+					"created_at timestamp with time zone," +
+					"uid integer," +
+					"user_name varchar(64)," +
+					"method varchar(16)," +
+					"path varchar(255)," +
+					"action varchar(128)," +
+					"resource varchar(32)," +
+					"resource_id varchar(255)," +
+					"app_name varchar(128)," +
+					"env varchar(64)," +
+					"request text," +
+					"before text," +
+					"after text," +
+					"status integer," +
+					"code integer," +
+					"message varchar(512)," +
+					"latency bigint," +
+					"client_ip varchar(64)," +
+					"PRIMARY KEY (id)" +
+					")",
+				"CREATE INDEX idx_audit_log_resource ON audit_log (resource)",
+				"CREATE INDEX idx_audit_log_app_name ON audit_log (app_name)",
+				"CREATE INDEX idx_audit_log_created_at ON audit_log (created_at)",
+				"CREATE INDEX idx_audit_log_user_name ON audit_log (user_name)",
+				"CREATE INDEX idx_audit_log_path ON audit_log (path)",
+			},
+			Down: []string{
+				"DROP TABLE IF EXISTS audit_log",
+			},
+		},
+	})
+}
@@ -3,6 +3,7 @@ package output
 import (
 	"net/http"
 
+	"github.com/douyu/juno/pkg/i18n"
 	"github.com/labstack/echo/v4"
 )
 
@@ -26,11 +27,11 @@ type Pagination struct {
 	CurrentPage int `json:"current_page"` // current page
 }
 
-// JSON 渲染
+// JSON 渲染，message 按请求的语言翻译
 func JSON(c echo.Context, Code int, message string, data ...interface{}) error {
 	result := new(JSONResult)
 	result.Code = Code
-	result.Message = message
+	result.Message = i18n.T(Lang(c), message)
 
 	if len(data) > 0 {
 		result.Data = data[0]
@@ -40,6 +41,14 @@ func JSON(c echo.Context, Code int, message string, data ...interface{}) error {
 	return c.JSON(http.StatusOK, result)
 }
 
+// Lang 请求的语言，登录用户由 LanguageMW 按个人设置写入，其他请求按 Accept-Language 选择
+func Lang(c echo.Context) string {
+	if lang, ok := c.Get(i18n.ContextKey).(string); ok && lang != "" {
+		return lang
+	}
+	return i18n.ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+}
+
 func WithData(data interface{}) JSONResult {
 	return JSONResult{
 		Code:    0,
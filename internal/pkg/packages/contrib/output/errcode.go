@@ -0,0 +1,256 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/labstack/echo/v4"
+)
+
+// Category 错误码分类
+type Category string
+
+const (
+	CategoryGeneral  Category = "general"  // 通用
+	CategoryAuth     Category = "auth"     // 登录、认证、权限
+	CategoryParam    Category = "param"    // 请求参数
+	CategoryResource Category = "resource" // 资源不存在、状态冲突
+	CategoryLimit    Category = "limit"    // 限流、配额
+	CategoryInternal Category = "internal" // 服务内部错误
+)
+
+// ErrCode 错误码目录中的一项
+type ErrCode struct {
+	Code     int      `json:"code"`
+	Category Category `json:"category"`
+	// Message 返回给用户的默认提示
+	Message string `json:"message"`
+	// Description 错误码的含义和处理方式，用于文档
+	Description string `json:"description"`
+}
+
+// catalog 错误码目录，响应中的 code 都应在此登记
+var catalog = make(map[int]ErrCode)
+
+func init() {
+	register(
+		ErrCode{MsgOk, CategoryGeneral, "success", "成功"},
+		ErrCode{MsgErr, CategoryGeneral, "操作失败", "未归类的业务错误，msg 为具体原因"},
+		ErrCode{MsgRedirect, CategoryAuth, "请先登录", "未登录，msg 为登录页地址"},
+		ErrCode{MsgNeedLogin, CategoryAuth, "请先登录", "未登录或登录凭证无效"},
+		ErrCode{MsgNeedTwoFactor, CategoryAuth, "请完成两步验证", "敏感操作需要输入两步验证码"},
+		ErrCode{MsgNeedTwoFactorEnroll, CategoryAuth, "请先开启两步验证", "敏感操作要求用户先开启两步验证"},
+		ErrCode{MsgPasswordExpired, CategoryAuth, "密码已过期，请修改密码后登录", "密码超过有效期"},
+		ErrCode{MsgOAuthFailed, CategoryAuth, "第三方登录失败", "OAuth 未启用、配置错误或与第三方交互失败"},
+		ErrCode{MsgInvalidParam, CategoryParam, "请求参数错误", "参数缺失、格式错误或未通过校验"},
+		ErrCode{MsgNotFound, CategoryResource, "资源不存在", "请求的资源或接口不存在"},
+		ErrCode{MsgConflict, CategoryResource, "资源状态冲突", "资源已存在或已被修改，刷新后重试"},
+		ErrCode{MsgNoAuth, CategoryAuth, "没有权限", "已登录但没有访问该资源的权限，或来源 IP 不在白名单内"},
+		ErrCode{MsgOpenAuthFailed, CategoryAuth, "开放平台认证失败", "app_id、签名或时间戳无效"},
+		ErrCode{MsgRateLimited, CategoryLimit, "请求过于频繁，请稍后再试", "超出限流规则，可按 Retry-After 响应头等待后重试"},
+		ErrCode{MsgInternal, CategoryInternal, "服务内部错误，请稍后再试", "服务异常，详细原因只记录在服务端日志中"},
+		ErrCode{MsgTaskQueueEmpty, CategoryGeneral, "任务队列为空", "worker 拉取测试任务时队列中没有任务"},
+	)
+}
+
+func register(codes ...ErrCode) {
+	for _, code := range codes {
+		if _, ok := catalog[code.Code]; ok {
+			panic(fmt.Sprintf("output: duplicate error code %d", code.Code))
+		}
+		catalog[code.Code] = code
+	}
+}
+
+// Codes 目录中的全部错误码，按 code 排序
+func Codes() []ErrCode {
+	list := make([]ErrCode, 0, len(catalog))
+	for _, code := range catalog {
+		list = append(list, code)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
+
+// Message 错误码的默认提示，未登记时返回空字符串
+func Message(code int) string {
+	return catalog[code].Message
+}
+
+// Error 带错误码的错误。Message 返回给用户，Err 为内部原因，只记录日志
+type Error struct {
+	Code    int
+	Message string
+	Err     error
+}
+
+// NewError 使用错误码的默认提示，err 不返回给用户
+func NewError(code int, err error) *Error {
+	return &Error{Code: code, Message: Message(code), Err: err}
+}
+
+// Errorf 自定义返回给用户的提示
+func Errorf(code int, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// mapping 未使用 Error 包装的错误对应的错误码
+type mapping struct {
+	target error
+	code   int
+}
+
+var mappings []mapping
+
+// RegisterError 登记 service 层哨兵错误对应的错误码，按 errors.Is 匹配，提示仍为 err.Error()。
+// 只应在 init 或服务启动时调用
+func RegisterError(code int, targets ...error) {
+	if _, ok := catalog[code]; !ok {
+		panic(fmt.Sprintf("output: unknown error code %d", code))
+	}
+	for _, target := range targets {
+		mappings = append(mappings, mapping{target: target, code: code})
+	}
+}
+
+// lookup 已登记的错误对应的错误码
+func lookup(err error) (code int, ok bool) {
+	for _, m := range mappings {
+		if errors.Is(err, m.target) {
+			return m.code, true
+		}
+	}
+	return 0, false
+}
+
+// InvalidParam 参数校验失败，提示为校验错误的具体原因
+func InvalidParam(err error) *Error {
+	return &Error{Code: MsgInvalidParam, Message: err.Error(), Err: err}
+}
+
+// Resolve 错误对应的错误码和提示。RegisterError 登记的错误使用登记的错误码，
+// 其余未使用 Error 包装的错误沿用 MsgErr + err.Error() 的返回方式
+func Resolve(err error) (code int, message string) {
+	if err == nil {
+		return MsgOk, Message(MsgOk)
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code, e.Message
+	}
+
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		code = codeOfStatus(he.Code)
+		if msg, ok := he.Message.(string); ok && code != MsgInternal {
+			return code, msg
+		}
+		return code, Message(code)
+	}
+
+	if code, ok := lookup(err); ok {
+		return code, err.Error()
+	}
+
+	return MsgErr, err.Error()
+}
+
+// ResolveRequest 同 Resolve，Error 的内部原因记录日志
+func ResolveRequest(c echo.Context, err error) (code int, message string) {
+	code, message = Resolve(err)
+	var e *Error
+	if errors.As(err, &e) && e.Err != nil {
+		xlog.Warn("request failed",
+			xlog.Int("code", code),
+			xlog.String("path", c.Request().URL.Path),
+			xlog.String("err", e.Err.Error()))
+	}
+	return
+}
+
+// JSONError 按错误码目录输出错误，内部原因只记录日志，不返回给用户
+func JSONError(c echo.Context, err error) error {
+	code, message := ResolveRequest(c, err)
+	return JSON(c, code, message)
+}
+
+// HTTPStatus handler 返回的错误对应的 HTTP 状态码
+func HTTPStatus(err error) int {
+	var (
+		e  *Error
+		he *echo.HTTPError
+	)
+	switch {
+	case errors.As(err, &e):
+		// 业务错误与 JSON 输出的方式保持一致
+		return http.StatusOK
+	case errors.As(err, &he):
+		return he.Code
+	}
+	if _, ok := lookup(err); ok {
+		return http.StatusOK
+	}
+	return http.StatusInternalServerError
+}
+
+// HTTPErrorHandler 替换 echo 默认的错误处理，handler 返回的错误也以 {code, msg, data} 格式输出。
+// Error 和 RegisterError 登记的错误按业务错误返回 200，echo.HTTPError 保留原 HTTP 状态码，
+// 其余错误视为服务内部错误
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status := HTTPStatus(err)
+	code, message := Resolve(err)
+	if status == http.StatusInternalServerError {
+		code, message = MsgInternal, Message(MsgInternal)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, JSONResult{Code: code, Message: message, Data: nil})
+	}
+	if err != nil {
+		xlog.Error("write error response failed", xlog.String("err", err.Error()))
+	}
+}
+
+// codeOfStatus HTTP 状态码对应的错误码
+func codeOfStatus(status int) int {
+	switch {
+	case status == http.StatusUnauthorized:
+		return MsgNeedLogin
+	case status == http.StatusForbidden:
+		return MsgNoAuth
+	case status == http.StatusNotFound, status == http.StatusMethodNotAllowed:
+		return MsgNotFound
+	case status == http.StatusConflict:
+		return MsgConflict
+	case status == http.StatusTooManyRequests:
+		return MsgRateLimited
+	case status >= http.StatusInternalServerError:
+		return MsgInternal
+	case status >= http.StatusBadRequest:
+		return MsgInvalidParam
+	}
+	return MsgErr
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestCodes(t *testing.T) {
+	codes := Codes()
+	for i, code := range codes {
+		if code.Message == "" || code.Category == "" {
+			t.Errorf("code %d has no message or category", code.Code)
+		}
+		if i > 0 && codes[i-1].Code >= code.Code {
+			t.Errorf("codes not sorted: %d before %d", codes[i-1].Code, code.Code)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate code should panic")
+		}
+	}()
+	register(ErrCode{Code: MsgNotFound, Category: CategoryResource, Message: "dup"})
+}
+
+func TestResolve(t *testing.T) {
+	internal := errors.New("dial tcp 10.0.0.1:3306: connection refused")
+	cases := []struct {
+		err  error
+		code int
+		msg  string
+	}{
+		{nil, MsgOk, "success"},
+		{errors.New("应用不存在"), MsgErr, "应用不存在"},
+		{NewError(MsgInternal, internal), MsgInternal, Message(MsgInternal)},
+		{fmt.Errorf("save: %w", Errorf(MsgConflict, "配置 %s 已存在", "config.toml")), MsgConflict, "配置 config.toml 已存在"},
+		{echo.ErrNotFound, MsgNotFound, "Not Found"},
+		{echo.NewHTTPError(http.StatusBadGateway, "upstream"), MsgInternal, Message(MsgInternal)},
+		{echo.NewHTTPError(http.StatusUnprocessableEntity), MsgInvalidParam, "Unprocessable Entity"},
+	}
+	for _, c := range cases {
+		code, msg := Resolve(c.err)
+		if code != c.code || msg != c.msg {
+			t.Errorf("Resolve(%v) = %d %q, want %d %q", c.err, code, msg, c.code, c.msg)
+		}
+	}
+
+	if err := NewError(MsgInternal, internal); !errors.Is(err, internal) {
+		t.Error("Error should unwrap to the internal error")
+	}
+}
+
+var errTestNotFound = errors.New("测试资源不存在")
+
+func init() {
+	RegisterError(MsgNotFound, errTestNotFound)
+}
+
+func TestRegisterError(t *testing.T) {
+	code, msg := Resolve(fmt.Errorf("load: %w", errTestNotFound))
+	if code != MsgNotFound || msg != "load: 测试资源不存在" {
+		t.Errorf("Resolve = %d %q, want registered code", code, msg)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering an unknown code should panic")
+		}
+	}()
+	RegisterError(-12345, errTestNotFound)
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   int
+		msg    string
+	}{
+		{Errorf(MsgInvalidParam, "name 不能为空"), http.StatusOK, MsgInvalidParam, "name 不能为空"},
+		{echo.ErrForbidden, http.StatusForbidden, MsgNoAuth, "Forbidden"},
+		{errors.New("sql: no rows"), http.StatusInternalServerError, MsgInternal, Message(MsgInternal)},
+		{fmt.Errorf("load: %w", errTestNotFound), http.StatusOK, MsgNotFound, "load: 测试资源不存在"},
+		{InvalidParam(errors.New("Key: 'Name' Error:required")), http.StatusOK, MsgInvalidParam, "Key: 'Name' Error:required"},
+	}
+
+	e := echo.New()
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		HTTPErrorHandler(c.err, e.NewContext(httptest.NewRequest(http.MethodGet, "/api/admin/app", nil), rec))
+
+		var res JSONResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != c.status || res.Code != c.code || res.Message != c.msg {
+			t.Errorf("HTTPErrorHandler(%v) = %d %+v, want %d %d %q", c.err, rec.Code, res, c.status, c.code, c.msg)
+		}
+	}
+}
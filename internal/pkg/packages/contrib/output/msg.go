@@ -1,11 +1,21 @@
 package output
 
+// 错误码一经发布不再修改含义，新增错误码需同时在 errcode.go 的目录中登记
 const (
-	MsgOk             = 0
-	MsgRedirect       = 302
-	MsgErr            = 1
-	MsgNoAuth         = 14000
-	MsgOpenAuthFailed = 14001
-	MsgNeedLogin      = 10000
-	MsgTaskQueueEmpty = 20001
+	MsgOk                  = 0
+	MsgRedirect            = 302
+	MsgErr                 = 1
+	MsgNoAuth              = 14000
+	MsgOpenAuthFailed      = 14001
+	MsgRateLimited         = 14029 // 请求过于频繁，被限流
+	MsgNeedLogin           = 10000
+	MsgNeedTwoFactor       = 10001 // 需要输入两步验证码
+	MsgNeedTwoFactorEnroll = 10002 // 需要先开启两步验证
+	MsgPasswordExpired     = 10003 // 密码已过期，需要修改密码后再登录
+	MsgOAuthFailed         = 10004 // 第三方登录失败
+	MsgInvalidParam        = 11000 // 请求参数错误
+	MsgNotFound            = 11004 // 资源不存在
+	MsgConflict            = 11009 // 资源状态冲突，如重复创建、已被修改
+	MsgInternal            = 15000 // 服务内部错误，详细原因只记录日志
+	MsgTaskQueueEmpty      = 20001
 )
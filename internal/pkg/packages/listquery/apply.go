@@ -0,0 +1,49 @@
+package listquery
+
+import (
+	"strconv"
+
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// HeaderTotalCount 列表总数
+	HeaderTotalCount = "X-Total-Count"
+	// HeaderNextCursor 下一页游标，没有更多数据时不返回
+	HeaderNextCursor = "X-Next-Cursor"
+)
+
+// Where 应用筛选条件
+func (q Query) Where(db *gorm.DB) *gorm.DB {
+	for _, f := range q.Filters {
+		switch f.Operator {
+		case "~":
+			db = db.Where(f.Column+" like ?", "%"+EscapeLike(f.Value)+"%")
+		default:
+			db = db.Where(f.Column+" "+f.Operator+" ?", f.Value)
+		}
+	}
+	return db
+}
+
+// Paginate 应用排序和分页，PageSize 为 0 时只排序
+func (q Query) Paginate(db *gorm.DB) *gorm.DB {
+	if q.Order != "" {
+		db = db.Order(q.Order)
+	}
+	if q.PageSize > 0 {
+		db = db.Offset(q.Offset).Limit(q.PageSize)
+	}
+	return db
+}
+
+// SetHeaders 在响应头中返回总数和下一页游标，供不方便解析响应体中分页信息的调用方使用
+func SetHeaders(c echo.Context, page *view.Pagination) {
+	header := c.Response().Header()
+	header.Set(HeaderTotalCount, strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		header.Set(HeaderNextCursor, page.NextCursor)
+	}
+}
@@ -0,0 +1,214 @@
+// Package listquery 解析、校验列表接口通用的分页、排序、筛选参数，并应用到 gorm 查询
+package listquery
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+const (
+	// DefaultMaxPageSize 未设置 Spec.MaxPageSize 时单页最多返回的条数
+	DefaultMaxPageSize = 1000
+
+	cursorPrefix = "offset:"
+)
+
+// 筛选操作符，按长度倒序排列，保证 >= 先于 > 匹配
+var operators = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+type (
+	// Spec 列表接口允许的排序、筛选字段，key 为参数中的字段名，value 为数据库列名
+	Spec struct {
+		Sorts   map[string]string
+		Filters map[string]string
+		// DefaultSort 未指定排序时使用，格式与 sort 参数相同
+		DefaultSort string
+		// Tiebreaker 唯一列，排序中不包含时按该列倒序兜底，保证分页稳定
+		Tiebreaker string
+		// DefaultPageSize 未指定分页大小时使用，为 0 时不分页返回全部数据
+		DefaultPageSize int
+		MaxPageSize     int
+	}
+
+	// Query 校验后的列表参数
+	Query struct {
+		Page     int
+		PageSize int // 为 0 时不分页
+		Offset   int
+		Order    string
+		Filters  []Filter
+	}
+
+	Filter struct {
+		Column   string
+		Operator string
+		Value    string
+	}
+)
+
+// Parse 校验列表参数，排序、筛选字段不在 Spec 中时返回错误
+func (s Spec) Parse(q view.ListQuery) (query Query, err error) {
+	query.Order, err = s.ParseSort(q.Sort)
+	if err != nil {
+		return
+	}
+
+	for _, filter := range q.Filter {
+		var f Filter
+		f, err = s.parseFilter(filter)
+		if err != nil {
+			return
+		}
+		query.Filters = append(query.Filters, f)
+	}
+
+	page, pageSize := q.Page, q.PageSize
+	if page == 0 {
+		page = q.CurrentPage
+	}
+	if pageSize == 0 {
+		pageSize = q.LegacyPageSize
+	}
+	if page < 0 || pageSize < 0 {
+		err = fmt.Errorf("page、page_size 不能小于 0")
+		return
+	}
+	if pageSize == 0 {
+		pageSize = s.DefaultPageSize
+	}
+	if pageSize == 0 && (page > 0 || q.Cursor != "") {
+		pageSize = view.NewPagination(0, 0).PageSize
+	}
+	maxPageSize := s.MaxPageSize
+	if maxPageSize == 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+	if pageSize > maxPageSize {
+		err = fmt.Errorf("page_size 不能大于 %d", maxPageSize)
+		return
+	}
+	query.PageSize = pageSize
+	if pageSize == 0 {
+		return
+	}
+
+	if q.Cursor != "" {
+		query.Offset, err = decodeCursor(q.Cursor)
+		if err != nil {
+			return
+		}
+		query.Page = query.Offset/pageSize + 1
+		return
+	}
+
+	if page == 0 {
+		page = 1
+	}
+	query.Page = page
+	query.Offset = (page - 1) * pageSize
+	return
+}
+
+// ParseSort 解析排序参数，多个字段用逗号分隔，字段前加 - 表示倒序，如 -update_time,name。
+// 为空时使用 DefaultSort，并以 Tiebreaker 兜底
+func (s Spec) ParseSort(sort string) (string, error) {
+	sort = strings.TrimSpace(sort)
+	if sort == "" {
+		sort = s.DefaultSort
+	}
+
+	orders := make([]string, 0)
+	hasTiebreaker := false
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		direction := "asc"
+		if strings.HasPrefix(field, "-") {
+			field = field[1:]
+			direction = "desc"
+		}
+		column, ok := s.Sorts[field]
+		if !ok {
+			return "", fmt.Errorf("不支持按 %s 排序", field)
+		}
+		if column == s.Tiebreaker {
+			hasTiebreaker = true
+		}
+		orders = append(orders, column+" "+direction)
+	}
+	if s.Tiebreaker != "" && !hasTiebreaker {
+		orders = append(orders, s.Tiebreaker+" desc")
+	}
+	return strings.Join(orders, ","), nil
+}
+
+// parseFilter 解析 字段 操作符 值 格式的筛选条件
+func (s Spec) parseFilter(filter string) (f Filter, err error) {
+	end := strings.IndexFunc(filter, func(r rune) bool {
+		return !(r == '_' || r == '.' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if end <= 0 {
+		err = fmt.Errorf("筛选条件 %s 格式错误", filter)
+		return
+	}
+
+	field, rest := filter[:end], filter[end:]
+	column, ok := s.Filters[field]
+	if !ok {
+		err = fmt.Errorf("不支持按 %s 筛选", field)
+		return
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			f = Filter{Column: column, Operator: op, Value: strings.TrimSpace(rest[len(op):])}
+			return
+		}
+	}
+	err = fmt.Errorf("筛选条件 %s 格式错误", filter)
+	return
+}
+
+// Pagination 根据总数和本页条数生成分页信息，还有更多数据时返回下一页游标
+func (q Query) Pagination(total, count int) *view.Pagination {
+	page := &view.Pagination{
+		Current:  q.Page,
+		Total:    total,
+		PageSize: q.PageSize,
+	}
+	if q.PageSize == 0 {
+		page.Current = 1
+		page.PageSize = count
+		return page
+	}
+	if next := q.Offset + count; count > 0 && next < total {
+		page.NextCursor = encodeCursor(next)
+	}
+	return page
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	content, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(content), cursorPrefix) {
+		return 0, fmt.Errorf("cursor 无效")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(content), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("cursor 无效")
+	}
+	return offset, nil
+}
+
+// EscapeLike 转义 LIKE 中的通配符，使筛选值、关键词按字面匹配
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,141 @@
+package listquery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+var testSpec = Spec{
+	Sorts: map[string]string{
+		"id":          "id",
+		"name":        "app_name",
+		"update_time": "update_time",
+	},
+	Filters: map[string]string{
+		"name":  "app_name",
+		"level": "biz_level",
+	},
+	DefaultSort:     "-update_time",
+	Tiebreaker:      "id",
+	DefaultPageSize: 20,
+	MaxPageSize:     100,
+}
+
+func TestParseSort(t *testing.T) {
+	cases := []struct {
+		sort string
+		want string
+		err  bool
+	}{
+		{sort: "", want: "update_time desc,id desc"},
+		{sort: "name,-update_time", want: "app_name asc,update_time desc,id desc"},
+		{sort: "-id", want: "id desc"},
+		{sort: "password", err: true},
+	}
+	for _, c := range cases {
+		got, err := testSpec.ParseSort(c.sort)
+		if (err != nil) != c.err || got != c.want {
+			t.Errorf("ParseSort(%q) = %q, %v", c.sort, got, err)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	query, err := testSpec.Parse(view.ListQuery{Filter: []string{"name~juno", "level>=2", "level!=3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Filter{
+		{Column: "app_name", Operator: "~", Value: "juno"},
+		{Column: "biz_level", Operator: ">=", Value: "2"},
+		{Column: "biz_level", Operator: "!=", Value: "3"},
+	}
+	if !reflect.DeepEqual(query.Filters, want) {
+		t.Errorf("filters = %+v", query.Filters)
+	}
+
+	for _, filter := range []string{"password=1", "name", "=juno", "name?juno"} {
+		if _, err := testSpec.Parse(view.ListQuery{Filter: []string{filter}}); err == nil {
+			t.Errorf("filter %q should be rejected", filter)
+		}
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	cases := []struct {
+		list     view.ListQuery
+		page     int
+		pageSize int
+		offset   int
+		err      bool
+	}{
+		{list: view.ListQuery{}, page: 1, pageSize: 20},
+		{list: view.ListQuery{Page: 3, PageSize: 10}, page: 3, pageSize: 10, offset: 20},
+		{list: view.ListQuery{CurrentPage: 2, LegacyPageSize: 5}, page: 2, pageSize: 5, offset: 5},
+		{list: view.ListQuery{Page: -1}, err: true},
+		{list: view.ListQuery{PageSize: 101}, err: true},
+		{list: view.ListQuery{PageSize: 10, Cursor: encodeCursor(30)}, page: 4, pageSize: 10, offset: 30},
+		{list: view.ListQuery{Cursor: "invalid"}, err: true},
+	}
+	for _, c := range cases {
+		query, err := testSpec.Parse(c.list)
+		if (err != nil) != c.err {
+			t.Errorf("Parse(%+v) err = %v", c.list, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if query.Page != c.page || query.PageSize != c.pageSize || query.Offset != c.offset {
+			t.Errorf("Parse(%+v) = %+v", c.list, query)
+		}
+	}
+}
+
+func TestUnpaged(t *testing.T) {
+	spec := Spec{Sorts: map[string]string{"id": "id"}, DefaultSort: "-id"}
+	query, err := spec.Parse(view.ListQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.PageSize != 0 {
+		t.Errorf("pageSize = %d", query.PageSize)
+	}
+	page := query.Pagination(42, 42)
+	if page.Current != 1 || page.PageSize != 42 || page.NextCursor != "" {
+		t.Errorf("pagination = %+v", page)
+	}
+
+	query, err = spec.Parse(view.ListQuery{Page: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.PageSize == 0 || query.Offset != query.PageSize {
+		t.Errorf("query = %+v", query)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	query, _ := testSpec.Parse(view.ListQuery{Page: 2, PageSize: 10})
+
+	page := query.Pagination(25, 10)
+	if page.Current != 2 || page.Total != 25 || page.NextCursor == "" {
+		t.Fatalf("pagination = %+v", page)
+	}
+
+	next, err := testSpec.Parse(view.ListQuery{PageSize: 10, Cursor: page.NextCursor})
+	if err != nil || next.Offset != 20 {
+		t.Fatalf("next = %+v, %v", next, err)
+	}
+	if page := next.Pagination(25, 5); page.NextCursor != "" {
+		t.Errorf("last page cursor = %s", page.NextCursor)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got := EscapeLike(`50%_a\b`); got != `50\%\_a\\b` {
+		t.Errorf("EscapeLike = %s", got)
+	}
+}
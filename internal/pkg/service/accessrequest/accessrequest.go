@@ -0,0 +1,380 @@
+package accessrequest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/auditlog"
+	"github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/team"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// AccessRequest 应用权限申请
+	AccessRequest *accessRequest
+
+	ErrRequestNotFound = fmt.Errorf("权限申请不存在")
+	ErrNoReviewPerm    = fmt.Errorf("只有应用所属团队的 owner 或管理员可以审批")
+)
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	accessRequest struct {
+		db *gorm.DB
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	AccessRequest = &accessRequest{
+		db: o.DB,
+	}
+}
+
+// Create 发起权限申请，通知应用所属团队审批
+func (a *accessRequest) Create(u *db.User, param view.ReqCreateAccessRequest) (err error) {
+	for _, act := range param.Actions {
+		if !casbin.Casbin.CheckAppPermissionKeyValid(act) {
+			return fmt.Errorf("无效的应用权限Key: %s", act)
+		}
+	}
+
+	var count int
+	err = a.db.Model(&db.AppInfo{}).Where("app_name = ?", param.AppName).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count == 0 {
+		return fmt.Errorf("应用 %s 不存在", param.AppName)
+	}
+
+	err = a.db.Model(&db.AccessRequest{}).
+		Where("uid = ? and app_name = ? and env = ? and status = ?", u.Uid, param.AppName, param.Env, db.AccessRequestStatusPending).
+		Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		return fmt.Errorf("已有待审批的申请，请勿重复提交")
+	}
+
+	item := db.AccessRequest{
+		Uid:     u.Uid,
+		AppName: param.AppName,
+		Env:     param.Env,
+		Actions: strings.Join(param.Actions, ","),
+		Reason:  param.Reason,
+		Status:  db.AccessRequestStatusPending,
+	}
+	err = a.db.Create(&item).Error
+	if err != nil {
+		return
+	}
+
+	go team.Team.Notify(notice.Event{
+		Type:     notice.EventApproval,
+		App:      item.AppName,
+		Env:      item.Env,
+		Severity: notice.SeverityWarning,
+		Subject:  fmt.Sprintf("[Juno] %s 申请应用 %s 的权限，请审批", u.Username, item.AppName),
+		Content: fmt.Sprintf("【权限申请】%s 申请应用 %s 环境 %s 的权限 %s，原因：%s，请前往 Juno 审批",
+			u.Username, item.AppName, item.Env, item.Actions, item.Reason),
+		Approval: &notice.Approval{Kind: notice.ApprovalAccessRequest, ID: item.ID},
+	})
+	return
+}
+
+// List 权限申请列表，scope 为 review 时返回当前用户可审批的申请
+func (a *accessRequest) List(u *db.User, param view.ReqListAccessRequest) (list []view.AccessRequest, page *view.Pagination, err error) {
+	var requests []db.AccessRequest
+
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := a.db.Model(&db.AccessRequest{})
+	if param.Scope == view.AccessRequestScopeReview {
+		if !isAdmin(u) {
+			var apps []string
+			apps, err = a.OwnedApps(u.Uid)
+			if err != nil {
+				return
+			}
+			query = query.Where("app_name in (?)", apps)
+		}
+	} else {
+		query = query.Where("uid = ?", u.Uid)
+	}
+	if param.Status != "" {
+		query = query.Where("status = ?", param.Status)
+	}
+	if param.AppName != "" {
+		query = query.Where("app_name = ?", param.AppName)
+	}
+
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&requests).Error
+	if err != nil {
+		return
+	}
+
+	usernames, err := a.usernames(requests)
+	if err != nil {
+		return
+	}
+
+	list = make([]view.AccessRequest, 0, len(requests))
+	for _, item := range requests {
+		list = append(list, view.AccessRequest{
+			ID:            item.ID,
+			Uid:           item.Uid,
+			Username:      usernames[item.Uid],
+			AppName:       item.AppName,
+			Env:           item.Env,
+			Actions:       strings.Split(item.Actions, ","),
+			Reason:        item.Reason,
+			Status:        item.Status,
+			ReviewerUid:   item.ReviewerUid,
+			Reviewer:      usernames[item.ReviewerUid],
+			ReviewComment: item.ReviewComment,
+			ReviewedAt:    item.ReviewedAt,
+			ExpiresAt:     item.ExpiresAt,
+			CreatedAt:     item.CreatedAt,
+		})
+	}
+	return
+}
+
+// Review 审批权限申请，通过后在有效期内授予申请人权限
+func (a *accessRequest) Review(u *db.User, param view.ReqReviewAccessRequest) (err error) {
+	item, err := a.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	err = a.CheckReviewPerm(u, item.AppName)
+	if err != nil {
+		return
+	}
+
+	if item.Uid == u.Uid && !isAdmin(u) {
+		return fmt.Errorf("不能审批自己的申请")
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":         db.AccessRequestStatusDenied,
+		"reviewer_uid":   u.Uid,
+		"review_comment": param.Comment,
+		"reviewed_at":    &now,
+	}
+	if param.Approve {
+		if param.ExpireDays <= 0 {
+			return fmt.Errorf("请设置权限有效期")
+		}
+		expiresAt := now.AddDate(0, 0, param.ExpireDays)
+		fields["status"] = db.AccessRequestStatusApproved
+		fields["expires_at"] = &expiresAt
+	}
+
+	err = a.transition(item.ID, db.AccessRequestStatusPending, fields)
+	if err != nil {
+		return
+	}
+
+	result := "拒绝"
+	if param.Approve {
+		result = fmt.Sprintf("通过，有效期 %d 天", param.ExpireDays)
+		_ = casbin.Casbin.LoadPolicy()
+	}
+
+	go team.Team.Notify(notice.Event{
+		Type:     notice.EventApproval,
+		App:      item.AppName,
+		Env:      item.Env,
+		Severity: notice.SeverityInfo,
+		Subject:  fmt.Sprintf("[Juno] 应用 %s 的权限申请已处理", item.AppName),
+		Content: fmt.Sprintf("【权限申请】%s 的应用 %s 环境 %s 权限申请已被 %s %s",
+			a.username(item.Uid), item.AppName, item.Env, u.Username, result),
+	})
+	return
+}
+
+// Cancel 申请人撤回待审批的申请
+func (a *accessRequest) Cancel(u *db.User, id uint) (err error) {
+	item, err := a.find(id)
+	if err != nil {
+		return
+	}
+
+	if item.Uid != u.Uid {
+		return ErrRequestNotFound
+	}
+
+	return a.transition(item.ID, db.AccessRequestStatusPending, map[string]interface{}{
+		"status": db.AccessRequestStatusCanceled,
+	})
+}
+
+// Revoke 提前收回已授予的权限
+func (a *accessRequest) Revoke(u *db.User, id uint) (err error) {
+	item, err := a.find(id)
+	if err != nil {
+		return
+	}
+
+	err = a.CheckReviewPerm(u, item.AppName)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	err = a.transition(item.ID, db.AccessRequestStatusApproved, map[string]interface{}{
+		"status":     db.AccessRequestStatusRevoked,
+		"expires_at": &now,
+	})
+	if err != nil {
+		return
+	}
+
+	_ = casbin.Casbin.LoadPolicy()
+	return
+}
+
+// ExpireTick 将到期的授权标记为过期并重新加载权限，由定时任务调用
+func (a *accessRequest) ExpireTick() (err error) {
+	var requests []db.AccessRequest
+	err = a.db.Where("status = ? and expires_at <= ?", db.AccessRequestStatusApproved, time.Now()).Find(&requests).Error
+	if err != nil {
+		xlog.Error("accessRequest.ExpireTick query failed", xlog.String("err", err.Error()))
+		return
+	}
+
+	expired := 0
+	for _, item := range requests {
+		if a.transition(item.ID, db.AccessRequestStatusApproved, map[string]interface{}{
+			"status": db.AccessRequestStatusExpired,
+		}) != nil {
+			continue
+		}
+
+		expired++
+		auditlog.AuditLog.Record(db.AuditLog{
+			CreatedAt:  time.Now(),
+			UserName:   "system",
+			Action:     "应用权限到期收回",
+			Resource:   db.AuditResourcePermission,
+			ResourceID: strconv.Itoa(int(item.ID)),
+			AppName:    item.AppName,
+			Env:        item.Env,
+			Before:     db.AccessRequestStatusApproved,
+			After:      db.AccessRequestStatusExpired,
+		})
+	}
+
+	if expired > 0 {
+		err = casbin.Casbin.LoadPolicy()
+	}
+	return
+}
+
+// transition 仅在申请处于 from 状态时更新，避免并发审批
+func (a *accessRequest) transition(id uint, from string, fields map[string]interface{}) error {
+	query := a.db.Model(&db.AccessRequest{}).Where("id = ? and status = ?", id, from).Updates(fields)
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		return fmt.Errorf("申请状态已变更，请刷新后重试")
+	}
+	return nil
+}
+
+// CheckReviewPerm 应用所属团队 owner 或管理员才能审批，环境晋升等审批流程共用
+func (a *accessRequest) CheckReviewPerm(u *db.User, appName string) error {
+	if isAdmin(u) {
+		return nil
+	}
+
+	apps, err := a.OwnedApps(u.Uid)
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		if app == appName {
+			return nil
+		}
+	}
+	return ErrNoReviewPerm
+}
+
+// OwnedApps 用户作为团队 owner 的应用
+func (a *accessRequest) OwnedApps(uid int) (apps []string, err error) {
+	var list []db.AppInfo
+	err = a.db.Table("app_info").Select("app_info.app_name").
+		Joins("inner join team_member on team_member.team_id = app_info.team_id and team_member.deleted_at is null").
+		Where("team_member.uid = ? and team_member.role = ?", uid, db.TeamMemberRoleOwner).
+		Find(&list).Error
+	if err != nil {
+		return
+	}
+
+	apps = make([]string, 0, len(list))
+	for _, item := range list {
+		apps = append(apps, item.AppName)
+	}
+	return
+}
+
+func (a *accessRequest) find(id uint) (item db.AccessRequest, err error) {
+	err = a.db.Where("id = ?", id).First(&item).Error
+	if err != nil && gorm.IsRecordNotFoundError(err) {
+		err = ErrRequestNotFound
+	}
+	return
+}
+
+func (a *accessRequest) usernames(requests []db.AccessRequest) (names map[int]string, err error) {
+	uids := make([]int, 0, len(requests)*2)
+	for _, item := range requests {
+		uids = append(uids, item.Uid)
+		if item.ReviewerUid != 0 {
+			uids = append(uids, item.ReviewerUid)
+		}
+	}
+
+	names = make(map[int]string)
+	if len(uids) == 0 {
+		return
+	}
+
+	var users []db.User
+	err = a.db.Select("uid, username").Where("uid in (?)", uids).Find(&users).Error
+	if err != nil {
+		return
+	}
+	for _, item := range users {
+		names[item.Uid] = item.Username
+	}
+	return
+}
+
+func (a *accessRequest) username(uid int) string {
+	var u db.User
+	a.db.Select("username").Where("uid = ?", uid).First(&u)
+	return u.Username
+}
+
+func isAdmin(u *db.User) bool {
+	return u.Access == "admin"
+}
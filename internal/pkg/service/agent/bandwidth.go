@@ -0,0 +1,21 @@
+package agent
+
+import (
+	"context"
+	"io"
+
+	"github.com/douyu/juno/pkg/util/throttle"
+	"golang.org/x/time/rate"
+)
+
+// bandwidth 服务端向 agent 传输数据的限速，升级包下载和配置拉取共用
+type bandwidth struct {
+	server *rate.Limiter
+	agents *throttle.KeyedLimiters
+}
+
+// Writer 按服务端总带宽和单个 agent 的带宽限制写入速度。
+// agentKey 为 agent 的来源地址，downloadLimit 为 agent 配置中的 download_limit（字节/秒），与 agent.bandwidth.agentLimit 取较小值
+func (b *bandwidth) Writer(ctx context.Context, w io.Writer, agentKey string, downloadLimit int64) io.Writer {
+	return throttle.NewWriter(ctx, w, b.server, b.agents.GetLimit(agentKey, downloadLimit))
+}
@@ -0,0 +1,260 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EtcdKeyFmtAgentConfig agent 监听该 key，配置变化后无需重启即可生效
+	EtcdKeyFmtAgentConfig = "/juno/agent/config/{{hostname}}"
+)
+
+type agentConfig struct {
+	db *gorm.DB
+}
+
+// List 配置列表
+func (a *agentConfig) List(param view.ReqListAgentConfig) (list []view.AgentConfig, err error) {
+	var configs []db.AgentConfig
+
+	query := a.db.Model(&db.AgentConfig{})
+	if param.Env != "" {
+		query = query.Where("env = ?", param.Env)
+	}
+	if param.ZoneCode != "" {
+		query = query.Where("zone_code = ?", param.ZoneCode)
+	}
+	if param.HostName != "" {
+		query = query.Where("host_name = ?", param.HostName)
+	}
+
+	err = query.Order("id desc").Find(&configs).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.AgentConfig, 0, len(configs))
+	for _, item := range configs {
+		list = append(list, view.AgentConfig{
+			ID:               item.ID,
+			Env:              item.Env,
+			ZoneCode:         item.ZoneCode,
+			HostName:         item.HostName,
+			Content:          item.Content,
+			Version:          item.Version,
+			PublishedContent: item.PublishedContent,
+			PublishedVersion: item.PublishedVersion,
+			UpdatedAt:        item.UpdatedAt,
+		})
+	}
+
+	return
+}
+
+// Create 创建配置，同一个目标只能存在一份配置
+func (a *agentConfig) Create(uid uint, param view.AgentConfig) (err error) {
+	var config db.AgentConfig
+
+	err = a.db.Where("env = ? and zone_code = ? and host_name = ?", param.Env, param.ZoneCode, param.HostName).
+		First(&config).Error
+	if err == nil {
+		return errors.New("agent config of this target already exists")
+	} else if !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	config = db.AgentConfig{
+		Env:       param.Env,
+		ZoneCode:  param.ZoneCode,
+		HostName:  param.HostName,
+		Content:   param.Content,
+		Version:   1,
+		CreatedBy: uid,
+		UpdatedBy: uid,
+	}
+
+	return a.db.Create(&config).Error
+}
+
+// Update 更新配置内容，需要 Publish 之后才会下发给 agent
+func (a *agentConfig) Update(uid uint, param view.AgentConfig) (err error) {
+	var config db.AgentConfig
+
+	err = a.db.Where("id = ?", param.ID).First(&config).Error
+	if err != nil {
+		return errors.Wrap(err, "cannot found agent config")
+	}
+
+	return a.db.Model(&config).Updates(map[string]interface{}{
+		"content":    param.Content,
+		"version":    config.Version + 1,
+		"updated_by": uid,
+	}).Error
+}
+
+// Delete 删除配置，并重新下发受影响 agent 的配置
+func (a *agentConfig) Delete(id uint) (err error) {
+	var config db.AgentConfig
+
+	err = a.db.Where("id = ?", id).First(&config).Error
+	if err != nil {
+		return errors.Wrap(err, "cannot found agent config")
+	}
+
+	// 软删除的记录保留删除时的序号，agent 收到的版本不会因为删除而变小
+	err = a.withRevision(func(tx *gorm.DB, revision uint) error {
+		err := tx.Model(&config).UpdateColumn("revision", revision).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&config).Error
+	})
+	if err != nil {
+		return
+	}
+
+	return a.dispatch(config)
+}
+
+// Publish 发布当前编辑的配置并下发到目标 agent，其他配置只下发已发布的内容
+func (a *agentConfig) Publish(id uint) (err error) {
+	var config db.AgentConfig
+
+	err = a.db.Where("id = ?", id).First(&config).Error
+	if err != nil {
+		return errors.Wrap(err, "cannot found agent config")
+	}
+
+	err = a.withRevision(func(tx *gorm.DB, revision uint) error {
+		return tx.Model(&config).UpdateColumns(map[string]interface{}{
+			"published_content": config.Content,
+			"published_version": config.Version,
+			"revision":          revision,
+		}).Error
+	})
+	if err != nil {
+		return
+	}
+
+	return a.dispatch(config)
+}
+
+// Effective 获取某台 agent 当前生效的配置，主机配置覆盖可用区配置，未发布过的配置不生效。
+// 版本取相关配置（包括已删除的）发布或删除时的最大序号，每次变更后单调递增
+func (a *agentConfig) Effective(env, zoneCode, hostName string) (payload view.AgentConfigPayload, err error) {
+	var configs []db.AgentConfig
+
+	err = a.db.Unscoped().
+		Where("env = ? and zone_code = ? and (host_name = '' or host_name = ?)", env, zoneCode, hostName).
+		Order("host_name asc").
+		Find(&configs).Error
+	if err != nil {
+		return
+	}
+
+	// 可用区配置排在主机配置之前，按顺序合并
+	for _, item := range configs {
+		if item.Revision > payload.Version {
+			payload.Version = item.Revision
+		}
+		if item.DeletedAt != nil || item.PublishedVersion == 0 {
+			continue
+		}
+		payload.Content = payload.Content.Merge(item.PublishedContent)
+	}
+	payload.Timestamp = time.Now().Unix()
+
+	return
+}
+
+// DownloadLimit 来源地址对应 agent 生效配置中的 download_limit，单位字节/秒，未配置或找不到 agent 时返回 0
+func (a *agentConfig) DownloadLimit(ip string) int64 {
+	var node db.Node
+	err := a.db.Where("ip = ?", ip).First(&node).Error
+	if err != nil {
+		return 0
+	}
+
+	payload, err := a.Effective(node.Env, node.ZoneCode, node.HostName)
+	if err != nil {
+		xlog.Warn("get agent effective config failed", xlog.String("host", node.HostName), xlog.FieldErr(err))
+		return 0
+	}
+	return payload.Content.DownloadBytesPerSec()
+}
+
+// withRevision 在事务中分配下一个发布序号并执行 fn，fn 返回错误时回滚
+func (a *agentConfig) withRevision(fn func(tx *gorm.DB, revision uint) error) (err error) {
+	tx := a.db.Begin()
+	revision, err := nextRevision(tx)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = fn(tx, revision)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit().Error
+}
+
+// nextRevision 全局递增的发布序号。查询时加锁，并发的发布、删除会等前一个事务提交后再取序号，不会拿到相同的序号
+func nextRevision(tx *gorm.DB) (uint, error) {
+	var row struct {
+		Revision uint
+	}
+	err := tx.Unscoped().Model(&db.AgentConfig{}).Set("gorm:query_option", "FOR UPDATE").
+		Select("COALESCE(MAX(revision), 0) AS revision").Scan(&row).Error
+	return row.Revision + 1, err
+}
+
+// dispatch 重新计算并下发 config 所影响 agent 的配置
+func (a *agentConfig) dispatch(config db.AgentConfig) (err error) {
+	var hostNames []string
+
+	if config.HostName != "" {
+		hostNames = []string{config.HostName}
+	} else {
+		err = a.db.Model(&db.Node{}).Where("env = ? and zone_code = ?", config.Env, config.ZoneCode).
+			Pluck("host_name", &hostNames).Error
+		if err != nil {
+			return
+		}
+	}
+
+	uniqZone := view.UniqZone{
+		Env:  config.Env,
+		Zone: config.ZoneCode,
+	}
+
+	for _, hostName := range hostNames {
+		payload, err := a.Effective(config.Env, config.ZoneCode, hostName)
+		if err != nil {
+			return err
+		}
+
+		buf, _ := json.Marshal(payload)
+		key := strings.Replace(EtcdKeyFmtAgentConfig, "{{hostname}}", hostName, -1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, err = clientproxy.ClientProxy.DefaultEtcdPut(uniqZone, ctx, key, string(buf))
+		cancel()
+		if err != nil {
+			xlog.Error("agentConfig.dispatch write etcd failed", xlog.Any("uniqZone", uniqZone), xlog.String("key", key))
+			return errors.Wrapf(err, "dispatch agent config to %s failed", hostName)
+		}
+	}
+
+	return
+}
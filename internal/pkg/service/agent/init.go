@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/util/throttle"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// AgentConfig agent配置热更新
+	AgentConfig *agentConfig
+	// AgentProcess agent管理的应用进程
+	AgentProcess *agentProcess
+	// AgentUpgrade agent滚动升级
+	AgentUpgrade *agentUpgrade
+	// AgentOffline agent离线检测
+	AgentOffline *agentOffline
+	// Bandwidth 向 agent 传输数据的限速
+	Bandwidth *bandwidth
+)
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	AgentConfig = &agentConfig{
+		db: o.DB,
+	}
+	AgentProcess = &agentProcess{
+		db: o.DB,
+	}
+	AgentUpgrade = &agentUpgrade{
+		db: o.DB,
+	}
+	AgentOffline = &agentOffline{
+		db: o.DB,
+	}
+	Bandwidth = &bandwidth{
+		server: throttle.NewLimiter(cfg.Cfg.Agent.Bandwidth.ServerLimit * 1024),
+		agents: throttle.NewKeyedLimiters(cfg.Cfg.Agent.Bandwidth.AgentLimit*1024, 10*time.Minute),
+	}
+}
@@ -0,0 +1,430 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/notifyrule"
+	"github.com/douyu/juno/internal/pkg/service/oncall"
+	"github.com/douyu/juno/internal/pkg/service/wsevent"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultFlappingDays     = 7
+	defaultFlappingMinCount = 3
+)
+
+type agentOffline struct {
+	db *gorm.DB
+}
+
+// Tick 检查 agent 心跳，记录离线/恢复并发送通知
+func (a *agentOffline) Tick() (err error) {
+	now := time.Now().Unix()
+
+	thresholds, err := a.zoneThresholds()
+	if err != nil {
+		xlog.Error("agentOffline.Tick: load zone thresholds failed", xlog.String("err", err.Error()))
+		return
+	}
+
+	var nodes []db.Node
+	err = a.db.Where("agent_type = ?", 1).Find(&nodes).Error
+	if err != nil {
+		xlog.Error("agentOffline.Tick: load nodes failed", xlog.String("err", err.Error()))
+		return
+	}
+
+	var events []db.AgentOfflineEvent
+	err = a.db.Where("recover_time = 0").Find(&events).Error
+	if err != nil {
+		xlog.Error("agentOffline.Tick: load offline events failed", xlog.String("err", err.Error()))
+		return
+	}
+	openEvents := make(map[string]db.AgentOfflineEvent, len(events))
+	for _, event := range events {
+		openEvents[event.HostName] = event
+	}
+
+	var offline, recovered []db.Node
+	for _, node := range nodes {
+		threshold := thresholds.get(node.Env, node.ZoneCode)
+		event, isOffline := openEvents[node.HostName]
+
+		if now-node.AgentHeartbeatTime > threshold {
+			if isOffline {
+				continue
+			}
+			err = a.db.Create(&db.AgentOfflineEvent{
+				HostName:          node.HostName,
+				Env:               node.Env,
+				ZoneCode:          node.ZoneCode,
+				LastHeartbeatTime: node.AgentHeartbeatTime,
+				LastError:         node.AgentLastError,
+				OfflineTime:       now,
+			}).Error
+			if err != nil {
+				xlog.Error("agentOffline.Tick: create offline event failed", xlog.String("host", node.HostName), xlog.String("err", err.Error()))
+				continue
+			}
+			offline = append(offline, node)
+		} else if isOffline {
+			err = a.db.Model(&event).Update("recover_time", now).Error
+			if err != nil {
+				xlog.Error("agentOffline.Tick: update offline event failed", xlog.String("host", node.HostName), xlog.String("err", err.Error()))
+				continue
+			}
+			recovered = append(recovered, node)
+		}
+	}
+
+	publishStatus(offline, "offline", now)
+	publishStatus(recovered, "online", now)
+	a.notify(offline, recovered, thresholds, now)
+	return nil
+}
+
+// publishStatus 推送 agent 离线、恢复事件，Key 为主机名
+func publishStatus(nodes []db.Node, status string, now int64) {
+	for _, node := range nodes {
+		wsevent.Publish(wsevent.Event{
+			Topic: wsevent.TopicAgent,
+			Key:   node.HostName,
+			Zone:  node.ZoneCode,
+			Data: map[string]interface{}{
+				"host_name":            node.HostName,
+				"env":                  node.Env,
+				"status":               status,
+				"agent_heartbeat_time": node.AgentHeartbeatTime,
+			},
+			Time: now,
+		})
+	}
+}
+
+func (a *agentOffline) notify(offline, recovered []db.Node, thresholds zoneThresholds, now int64) {
+	if len(offline) == 0 && len(recovered) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	if len(offline) > 0 {
+		b.WriteString(fmt.Sprintf("[Juno] %d 个 agent 离线\n", len(offline)))
+		for _, node := range offline {
+			b.WriteString(fmt.Sprintf("\n%s (%s/%s) 最后心跳: %s\n", node.HostName, node.Env, node.ZoneCode,
+				time.Unix(node.AgentHeartbeatTime, 0).Format("2006-01-02 15:04:05")))
+			if node.AgentLastError != "" {
+				b.WriteString("最近错误: " + node.AgentLastError + "\n")
+			}
+			for _, hint := range offlineHints(node, thresholds.get(node.Env, node.ZoneCode), now) {
+				b.WriteString("- " + hint + "\n")
+			}
+		}
+	}
+	if len(recovered) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(fmt.Sprintf("[Juno] %d 个 agent 已恢复\n", len(recovered)))
+		for _, node := range recovered {
+			b.WriteString(fmt.Sprintf("%s (%s/%s)\n", node.HostName, node.Env, node.ZoneCode))
+		}
+	}
+
+	severity := notice.SeverityInfo
+	if len(offline) > 0 {
+		severity = notice.SeverityError
+	}
+	e := notice.Event{
+		Type:     notice.EventAlert,
+		Env:      nodesEnv(append(offline, recovered...)),
+		Severity: severity,
+		Subject:  fmt.Sprintf("[Juno] agent 离线 %d 个，恢复 %d 个", len(offline), len(recovered)),
+		Content:  b.String(),
+	}
+	notifyrule.NotifyRule.Notify(e)
+	oncall.OnCall.Page(e)
+}
+
+// ListOffline 当前处于离线状态的 agent
+func (a *agentOffline) ListOffline(param view.ReqListAgentOffline) (list []view.AgentOffline, err error) {
+	var events []db.AgentOfflineEvent
+	query := a.db.Where("recover_time = 0")
+	if param.Env != "" {
+		query = query.Where("env = ?", param.Env)
+	}
+	if param.ZoneCode != "" {
+		query = query.Where("zone_code = ?", param.ZoneCode)
+	}
+	err = query.Order("offline_time desc").Find(&events).Error
+	if err != nil {
+		return
+	}
+
+	nodes, err := a.nodesByHost(hostNamesOfEvents(events))
+	if err != nil {
+		return
+	}
+
+	thresholds, err := a.zoneThresholds()
+	if err != nil {
+		return
+	}
+
+	now := time.Now().Unix()
+	list = make([]view.AgentOffline, 0, len(events))
+	for _, event := range events {
+		node, ok := nodes[event.HostName]
+		if !ok {
+			continue
+		}
+		lastError := node.AgentLastError
+		if lastError == "" {
+			lastError = event.LastError
+		}
+		list = append(list, view.AgentOffline{
+			HostName:           node.HostName,
+			Ip:                 node.Ip,
+			Env:                node.Env,
+			ZoneCode:           node.ZoneCode,
+			AgentVersion:       node.AgentVersion,
+			AgentHeartbeatTime: node.AgentHeartbeatTime,
+			ProxyHeartbeatTime: node.ProxyHeartbeatTime,
+			OfflineTime:        event.OfflineTime,
+			LastError:          lastError,
+			Hints:              offlineHints(node, thresholds.get(node.Env, node.ZoneCode), now),
+		})
+	}
+
+	return
+}
+
+// ListFlapping 统计窗口内频繁离线的 agent
+func (a *agentOffline) ListFlapping(param view.ReqListAgentFlapping) (list []view.AgentFlapping, err error) {
+	if param.Days == 0 {
+		param.Days = defaultFlappingDays
+	}
+	if param.MinCount == 0 {
+		param.MinCount = defaultFlappingMinCount
+	}
+
+	now := time.Now().Unix()
+	since := time.Now().AddDate(0, 0, -param.Days).Unix()
+
+	var stats []struct {
+		HostName        string
+		Env             string
+		ZoneCode        string
+		OfflineCount    int
+		OfflineDuration int64
+		LastOfflineTime int64
+	}
+	query := a.db.Model(&db.AgentOfflineEvent{}).
+		Select("host_name, env, zone_code, count(*) as offline_count, "+
+			"sum(case when recover_time = 0 then ? else recover_time end - offline_time) as offline_duration, "+
+			"max(offline_time) as last_offline_time", now).
+		Where("offline_time >= ?", since)
+	if param.Env != "" {
+		query = query.Where("env = ?", param.Env)
+	}
+	if param.ZoneCode != "" {
+		query = query.Where("zone_code = ?", param.ZoneCode)
+	}
+	err = query.Group("host_name, env, zone_code").
+		Having("count(*) >= ?", param.MinCount).
+		Order("offline_count desc").
+		Scan(&stats).Error
+	if err != nil {
+		return
+	}
+
+	hostNames := make([]string, 0, len(stats))
+	for _, item := range stats {
+		hostNames = append(hostNames, item.HostName)
+	}
+	nodes, err := a.nodesByHost(hostNames)
+	if err != nil {
+		return
+	}
+
+	thresholds, err := a.zoneThresholds()
+	if err != nil {
+		return
+	}
+
+	list = make([]view.AgentFlapping, 0, len(stats))
+	for _, item := range stats {
+		flapping := view.AgentFlapping{
+			HostName:        item.HostName,
+			Env:             item.Env,
+			ZoneCode:        item.ZoneCode,
+			OfflineCount:    item.OfflineCount,
+			OfflineDuration: item.OfflineDuration,
+			LastOfflineTime: item.LastOfflineTime,
+		}
+		if node, ok := nodes[item.HostName]; ok {
+			threshold := thresholds.get(node.Env, node.ZoneCode)
+			flapping.Online = now-node.AgentHeartbeatTime <= threshold
+			flapping.LastError = node.AgentLastError
+			flapping.Hints = offlineHints(node, threshold, now)
+		}
+		flapping.Hints = append(flapping.Hints, fmt.Sprintf("近 %d 天离线 %d 次，请排查主机资源、网络抖动或 agent 进程是否被反复拉起", param.Days, item.OfflineCount))
+		list = append(list, flapping)
+	}
+
+	return
+}
+
+// ListThreshold 各可用区的离线判定阈值
+func (a *agentOffline) ListThreshold() (list []view.AgentOfflineThreshold, err error) {
+	var zones []db.Zone
+	err = a.db.Order("env, zone_code").Find(&zones).Error
+	if err != nil {
+		return
+	}
+
+	defaultThreshold := int(defaultOfflineThreshold())
+	list = make([]view.AgentOfflineThreshold, 0, len(zones))
+	for _, zone := range zones {
+		effective := zone.AgentOfflineThreshold
+		if effective <= 0 {
+			effective = defaultThreshold
+		}
+		list = append(list, view.AgentOfflineThreshold{
+			Env:       zone.Env,
+			ZoneCode:  zone.ZoneCode,
+			ZoneName:  zone.ZoneName,
+			Threshold: zone.AgentOfflineThreshold,
+			Effective: effective,
+		})
+	}
+
+	return
+}
+
+// SetThreshold 设置可用区的离线判定阈值
+func (a *agentOffline) SetThreshold(param view.ReqAgentOfflineThreshold) (err error) {
+	query := a.db.Model(&db.Zone{}).Where("env = ? and zone_code = ?", param.Env, param.ZoneCode).
+		Update("agent_offline_threshold", param.Threshold)
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		var zone db.Zone
+		err = a.db.Where("env = ? and zone_code = ?", param.Env, param.ZoneCode).First(&zone).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return errors.New("zone not found")
+		}
+	}
+
+	return
+}
+
+func (a *agentOffline) nodesByHost(hostNames []string) (nodes map[string]db.Node, err error) {
+	nodes = make(map[string]db.Node)
+	if len(hostNames) == 0 {
+		return
+	}
+
+	var list []db.Node
+	err = a.db.Where("host_name in (?)", hostNames).Find(&list).Error
+	if err != nil {
+		return
+	}
+	for _, node := range list {
+		nodes[node.HostName] = node
+	}
+
+	return
+}
+
+// zoneThresholds env/zone_code => 离线阈值(秒)
+type zoneThresholds map[string]int64
+
+func (a *agentOffline) zoneThresholds() (thresholds zoneThresholds, err error) {
+	var zones []db.Zone
+	err = a.db.Where("agent_offline_threshold > 0").Find(&zones).Error
+	if err != nil {
+		return
+	}
+
+	thresholds = make(zoneThresholds, len(zones))
+	for _, zone := range zones {
+		thresholds[zone.Env+"/"+zone.ZoneCode] = int64(zone.AgentOfflineThreshold)
+	}
+
+	return
+}
+
+func (t zoneThresholds) get(env, zoneCode string) int64 {
+	if threshold, ok := t[env+"/"+zoneCode]; ok {
+		return threshold
+	}
+	return defaultOfflineThreshold()
+}
+
+func defaultOfflineThreshold() int64 {
+	threshold := int64(cfg.Cfg.Agent.OfflineThreshold / time.Second)
+	if threshold <= 0 {
+		threshold = 120
+	}
+	return threshold
+}
+
+func hostNamesOfEvents(events []db.AgentOfflineEvent) []string {
+	hostNames := make([]string, 0, len(events))
+	for _, event := range events {
+		hostNames = append(hostNames, event.HostName)
+	}
+	return hostNames
+}
+
+// nodesEnv 节点都属于同一环境时返回该环境，用于通知规则按环境匹配
+func nodesEnv(nodes []db.Node) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	for _, node := range nodes[1:] {
+		if node.Env != nodes[0].Env {
+			return ""
+		}
+	}
+	return nodes[0].Env
+}
+
+// offlineHints 根据 proxy 心跳和 agent 最近错误给出排查建议
+func offlineHints(node db.Node, threshold int64, now int64) (hints []string) {
+	if node.ProxyType == 1 && now-node.ProxyHeartbeatTime <= threshold {
+		hints = append(hints, "proxy 心跳正常，主机网络可达，agent 进程可能已退出，请检查 systemd/supervisord 中 juno-agent 的状态并重启")
+	} else {
+		hints = append(hints, "主机可能宕机或网络不可达，请检查主机状态及到 Juno 的网络连通性")
+	}
+
+	lastError := strings.ToLower(node.AgentLastError)
+	switch {
+	case lastError == "":
+	case strings.Contains(lastError, "etcd"):
+		hints = append(hints, "agent 最近报错与 etcd 相关，请检查 etcd 集群状态及 agent 的 etcd 配置")
+	case strings.Contains(lastError, "no space left"):
+		hints = append(hints, "磁盘空间不足，请清理日志或扩容磁盘")
+	case strings.Contains(lastError, "too many open files"):
+		hints = append(hints, "文件句柄耗尽，请检查 ulimit 配置及句柄泄漏")
+	case strings.Contains(lastError, "permission denied"):
+		hints = append(hints, "agent 权限不足，请检查运行用户及相关文件权限")
+	case strings.Contains(lastError, "timeout"), strings.Contains(lastError, "connection refused"),
+		strings.Contains(lastError, "no such host"):
+		hints = append(hints, "agent 连接服务端失败，请检查 DNS、防火墙及 Juno 服务端地址配置")
+	default:
+		hints = append(hints, "请根据最近错误排查 agent 日志")
+	}
+
+	return
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+func TestOfflineHints(t *testing.T) {
+	now := int64(10000)
+
+	tests := []struct {
+		name     string
+		node     db.Node
+		contains []string
+	}{
+		{
+			name:     "proxy alive",
+			node:     db.Node{ProxyType: 1, ProxyHeartbeatTime: now - 10},
+			contains: []string{"agent 进程可能已退出"},
+		},
+		{
+			name:     "host down",
+			node:     db.Node{ProxyType: 1, ProxyHeartbeatTime: now - 1000},
+			contains: []string{"主机可能宕机"},
+		},
+		{
+			name:     "etcd error",
+			node:     db.Node{AgentLastError: "ETCD: context deadline exceeded"},
+			contains: []string{"主机可能宕机", "etcd"},
+		},
+		{
+			name:     "disk full",
+			node:     db.Node{AgentLastError: "write /var/log/agent.log: no space left on device"},
+			contains: []string{"磁盘空间不足"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := strings.Join(offlineHints(tt.node, 120, now), "\n")
+			for _, s := range tt.contains {
+				if !strings.Contains(hints, s) {
+					t.Errorf("hints %q should contain %q", hints, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/douyu/juno/internal/pkg/service/appevent"
+	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const (
+	agentURLProcessStatus  = "/api/agent/process/status"
+	agentURLProcessRestart = "/api/agent/process/restart"
+)
+
+type (
+	agentProcess struct {
+		db *gorm.DB
+	}
+
+	agentProcessResp struct {
+		Code int                     `json:"code"`
+		Msg  string                  `json:"msg"`
+		Data view.AgentProcessStatus `json:"data"`
+	}
+)
+
+// Status 查询应用在节点上由 systemd/supervisord 管理的进程状态
+func (p *agentProcess) Status(param view.ReqAgentProcess) (status view.AgentProcessStatus, err error) {
+	node, err := p.appNode(param)
+	if err != nil {
+		return
+	}
+
+	resp, err := clientproxy.ClientProxy.HttpGet(view.UniqZone{Env: param.Env, Zone: param.ZoneCode}, view.ReqHTTPProxy{
+		Address: fmt.Sprintf("%s:%d", node.IP, cfg.Cfg.Agent.Port),
+		URL:     agentURLProcessStatus,
+		Type:    http.MethodGet,
+		Params: map[string]string{
+			"app_name": param.AppName,
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	return parseAgentProcessResp(resp.Body())
+}
+
+// Restart 通过 agent 重启应用进程，并记录应用事件
+func (p *agentProcess) Restart(param view.ReqAgentProcess, user *db.User) (status view.AgentProcessStatus, err error) {
+	node, err := p.appNode(param)
+	if err != nil {
+		return
+	}
+
+	body, _ := json.Marshal(map[string]string{
+		"app_name": param.AppName,
+	})
+	resp, err := clientproxy.ClientProxy.HttpPost(view.UniqZone{Env: param.Env, Zone: param.ZoneCode}, view.ReqHTTPProxy{
+		Address: fmt.Sprintf("%s:%d", node.IP, cfg.Cfg.Agent.Port),
+		URL:     agentURLProcessRestart,
+		Type:    http.MethodPost,
+		Body:    body,
+	})
+	if err != nil {
+		return
+	}
+
+	status, err = parseAgentProcessResp(resp.Body())
+	if err != nil {
+		xlog.Error("agentProcess.Restart failed", xlog.Any("param", param), xlog.String("err", err.Error()))
+		return
+	}
+
+	metadata, _ := json.Marshal(param)
+	appevent.AppEvent.UserAppRestart(node.Aid, node.AppName, node.ZoneCode, node.Env, node.HostName, string(metadata), user)
+
+	return
+}
+
+func (p *agentProcess) appNode(param view.ReqAgentProcess) (node db.AppNode, err error) {
+	err = p.db.Where("app_name = ? and host_name = ? and env = ? and zone_code = ?",
+		param.AppName, param.HostName, param.Env, param.ZoneCode).First(&node).Error
+	if err != nil {
+		return node, errors.Wrap(err, "cannot found app node")
+	}
+	return
+}
+
+func parseAgentProcessResp(body []byte) (status view.AgentProcessStatus, err error) {
+	var out agentProcessResp
+	err = json.Unmarshal(body, &out)
+	if err != nil {
+		return status, errors.Wrap(err, "invalid agent response")
+	}
+
+	if out.Code != 200 {
+		return status, fmt.Errorf("agent returns error: %d %s", out.Code, out.Msg)
+	}
+
+	return out.Data, nil
+}
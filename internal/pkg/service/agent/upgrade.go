@@ -0,0 +1,451 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EtcdKeyFmtAgentUpgrade agent 监听该 key，收到后下载新版本并替换自身
+	EtcdKeyFmtAgentUpgrade = "/juno/agent/upgrade/{{hostname}}"
+)
+
+type agentUpgrade struct {
+	db  *gorm.DB
+	mtx sync.Mutex
+}
+
+// UploadPackage 保存 agent 升级包
+func (a *agentUpgrade) UploadPackage(uid uint, version string, file *multipart.FileHeader) (err error) {
+	if version == "" || strings.ContainsAny(version, `/\`) || strings.Contains(version, "..") {
+		return errors.New("invalid version")
+	}
+
+	var pkg db.AgentPackage
+	err = a.db.Where("version = ?", version).First(&pkg).Error
+	if err == nil {
+		return errors.New("package of this version already exists")
+	} else if !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	fileName := filepath.Base(file.Filename)
+	dir := filepath.Join(cfg.Cfg.Agent.PackageDir, version)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.Create(filepath.Join(dir, fileName))
+	if err != nil {
+		return
+	}
+	defer dst.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(dst, hash), src)
+	if err != nil {
+		return
+	}
+
+	pkg = db.AgentPackage{
+		Version:   version,
+		FileName:  fileName,
+		Size:      size,
+		Sha256:    hex.EncodeToString(hash.Sum(nil)),
+		CreatedBy: uid,
+	}
+	return a.db.Create(&pkg).Error
+}
+
+// ListPackage 升级包列表
+func (a *agentUpgrade) ListPackage() (list []view.AgentPackage, err error) {
+	var packages []db.AgentPackage
+	err = a.db.Order("id desc").Find(&packages).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.AgentPackage, 0, len(packages))
+	for _, item := range packages {
+		list = append(list, view.AgentPackage{
+			ID:        item.ID,
+			Version:   item.Version,
+			FileName:  item.FileName,
+			Size:      item.Size,
+			Sha256:    item.Sha256,
+			CreatedAt: item.CreatedAt,
+		})
+	}
+	return
+}
+
+// PackagePath 获取升级包在本地的路径
+func (a *agentUpgrade) PackagePath(version string) (path string, err error) {
+	var pkg db.AgentPackage
+	err = a.db.Where("version = ?", version).First(&pkg).Error
+	if err != nil {
+		return "", errors.Wrap(err, "cannot found package")
+	}
+	return filepath.Join(cfg.Cfg.Agent.PackageDir, pkg.Version, pkg.FileName), nil
+}
+
+// Create 创建升级任务并开始第一个可用区的金丝雀批次
+func (a *agentUpgrade) Create(uid uint, param view.ReqCreateAgentUpgrade) (err error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	var pkg db.AgentPackage
+	err = a.db.Where("version = ?", param.Version).First(&pkg).Error
+	if err != nil {
+		return errors.Wrap(err, "cannot found package")
+	}
+
+	var cnt int
+	err = a.db.Model(&db.AgentUpgrade{}).Where("status in (?)",
+		[]db.AgentUpgradeStatus{db.AgentUpgradeStatusRunning, db.AgentUpgradeStatusPaused}).Count(&cnt).Error
+	if err != nil {
+		return
+	}
+	if cnt > 0 {
+		return errors.New("another upgrade is in progress")
+	}
+
+	upgrade := db.AgentUpgrade{
+		Version:          param.Version,
+		Zones:            param.Zones,
+		CanaryPercent:    param.CanaryPercent,
+		FailureThreshold: param.FailureThreshold,
+		ZoneIndex:        0,
+		Stage:            db.AgentUpgradeStageCanary,
+		Status:           db.AgentUpgradeStatusRunning,
+		CreatedBy:        uid,
+	}
+	err = a.db.Create(&upgrade).Error
+	if err != nil {
+		return
+	}
+
+	return a.startStage(upgrade, pkg)
+}
+
+// List 升级任务列表
+func (a *agentUpgrade) List() (list []view.AgentUpgrade, err error) {
+	var upgrades []db.AgentUpgrade
+	err = a.db.Order("id desc").Find(&upgrades).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.AgentUpgrade, 0, len(upgrades))
+	for _, item := range upgrades {
+		list = append(list, makeAgentUpgrade(item))
+	}
+	return
+}
+
+// Detail 升级任务详情，包含每个节点的升级状态
+func (a *agentUpgrade) Detail(id uint) (detail view.AgentUpgradeDetail, err error) {
+	var upgrade db.AgentUpgrade
+	var nodes []db.AgentUpgradeNode
+
+	err = a.db.Where("id = ?", id).First(&upgrade).Error
+	if err != nil {
+		return detail, errors.Wrap(err, "cannot found upgrade")
+	}
+
+	err = a.db.Where("upgrade_id = ?", id).Order("id asc").Find(&nodes).Error
+	if err != nil {
+		return
+	}
+
+	detail.AgentUpgrade = makeAgentUpgrade(upgrade)
+	detail.Nodes = make([]view.AgentUpgradeNode, 0, len(nodes))
+	for _, node := range nodes {
+		detail.Nodes = append(detail.Nodes, view.AgentUpgradeNode{
+			ZoneIndex:    node.ZoneIndex,
+			Stage:        node.Stage,
+			HostName:     node.HostName,
+			FromVersion:  node.FromVersion,
+			Status:       node.Status,
+			DispatchedAt: node.DispatchedAt,
+			FinishedAt:   node.FinishedAt,
+		})
+	}
+	return
+}
+
+// Pause 暂停升级，已经下发的节点不受影响
+func (a *agentUpgrade) Pause(id uint) error {
+	return a.setStatus(id, db.AgentUpgradeStatusRunning, db.AgentUpgradeStatusPaused, "paused by user")
+}
+
+// Resume 继续执行暂停的升级
+func (a *agentUpgrade) Resume(id uint) error {
+	return a.setStatus(id, db.AgentUpgradeStatusPaused, db.AgentUpgradeStatusRunning, "")
+}
+
+// Cancel 取消升级
+func (a *agentUpgrade) Cancel(id uint) (err error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	return a.db.Model(&db.AgentUpgrade{}).
+		Where("id = ? and status in (?)", id, []db.AgentUpgradeStatus{db.AgentUpgradeStatusRunning, db.AgentUpgradeStatusPaused}).
+		Updates(map[string]interface{}{
+			"status": db.AgentUpgradeStatusCanceled,
+			"reason": "canceled by user",
+		}).Error
+}
+
+func (a *agentUpgrade) setStatus(id uint, from, to db.AgentUpgradeStatus, reason string) (err error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	query := a.db.Model(&db.AgentUpgrade{}).Where("id = ? and status = ?", id, from).Updates(map[string]interface{}{
+		"status": to,
+		"reason": reason,
+	})
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		return fmt.Errorf("upgrade is not %s", from)
+	}
+	return
+}
+
+// Tick 检查运行中的升级任务：校验节点心跳版本，超过失败阈值时暂停，当前批次完成后进入下一批次
+func (a *agentUpgrade) Tick() (err error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	var upgrades []db.AgentUpgrade
+	err = a.db.Where("status = ?", db.AgentUpgradeStatusRunning).Find(&upgrades).Error
+	if err != nil {
+		return
+	}
+
+	for _, upgrade := range upgrades {
+		err = a.step(upgrade)
+		if err != nil {
+			xlog.Error("agentUpgrade.Tick failed", xlog.Uint("upgradeId", upgrade.ID), xlog.String("err", err.Error()))
+		}
+	}
+	return nil
+}
+
+func (a *agentUpgrade) step(upgrade db.AgentUpgrade) (err error) {
+	var nodes []db.AgentUpgradeNode
+	err = a.db.Where("upgrade_id = ? and zone_index = ? and stage = ?", upgrade.ID, upgrade.ZoneIndex, upgrade.Stage).
+		Find(&nodes).Error
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	failed, pending := 0, 0
+	for _, node := range nodes {
+		if node.Status == db.AgentUpgradeNodeStatusDispatched {
+			node.Status, err = a.checkNode(upgrade, node, now)
+			if err != nil {
+				return
+			}
+			if node.Status != db.AgentUpgradeNodeStatusDispatched {
+				err = a.db.Model(&node).Updates(map[string]interface{}{
+					"status":      node.Status,
+					"finished_at": now,
+				}).Error
+				if err != nil {
+					return
+				}
+			}
+		}
+
+		switch node.Status {
+		case db.AgentUpgradeNodeStatusFailed:
+			failed++
+		case db.AgentUpgradeNodeStatusDispatched:
+			pending++
+		}
+	}
+
+	if len(nodes) > 0 && uint(failed*100) > upgrade.FailureThreshold*uint(len(nodes)) {
+		return a.db.Model(&upgrade).Updates(map[string]interface{}{
+			"status": db.AgentUpgradeStatusPaused,
+			"reason": fmt.Sprintf("%d of %d agents failed to upgrade in zone %d stage %d", failed, len(nodes), upgrade.ZoneIndex, upgrade.Stage),
+		}).Error
+	}
+
+	if pending > 0 {
+		return
+	}
+
+	// 当前批次完成，进入下一批次
+	if upgrade.Stage == db.AgentUpgradeStageCanary {
+		upgrade.Stage = db.AgentUpgradeStageRest
+	} else {
+		upgrade.ZoneIndex++
+		upgrade.Stage = db.AgentUpgradeStageCanary
+	}
+
+	if upgrade.ZoneIndex >= len(upgrade.Zones) {
+		return a.db.Model(&upgrade).Update("status", db.AgentUpgradeStatusFinished).Error
+	}
+
+	err = a.db.Model(&upgrade).Updates(map[string]interface{}{
+		"zone_index": upgrade.ZoneIndex,
+		"stage":      upgrade.Stage,
+	}).Error
+	if err != nil {
+		return
+	}
+
+	var pkg db.AgentPackage
+	err = a.db.Where("version = ?", upgrade.Version).First(&pkg).Error
+	if err != nil {
+		return errors.Wrap(err, "cannot found package")
+	}
+
+	return a.startStage(upgrade, pkg)
+}
+
+// checkNode agent 以新版本完成心跳视为升级成功，超时未上报视为失败
+func (a *agentUpgrade) checkNode(upgrade db.AgentUpgrade, node db.AgentUpgradeNode, now time.Time) (status db.AgentUpgradeNodeStatus, err error) {
+	var info db.Node
+	err = a.db.Where("host_name = ?", node.HostName).First(&info).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+	err = nil
+
+	if info.AgentVersion == upgrade.Version && node.DispatchedAt != nil && info.AgentHeartbeatTime >= node.DispatchedAt.Unix() {
+		return db.AgentUpgradeNodeStatusSuccess, nil
+	}
+
+	if node.DispatchedAt == nil || now.Sub(*node.DispatchedAt) > cfg.Cfg.Agent.UpgradeTimeout {
+		return db.AgentUpgradeNodeStatusFailed, nil
+	}
+
+	return db.AgentUpgradeNodeStatusDispatched, nil
+}
+
+// startStage 选出当前批次的节点并下发升级
+func (a *agentUpgrade) startStage(upgrade db.AgentUpgrade, pkg db.AgentPackage) (err error) {
+	var candidates []db.Node
+	var upgradedHosts []string
+
+	zone := upgrade.Zones[upgrade.ZoneIndex]
+
+	err = a.db.Model(&db.AgentUpgradeNode{}).Where("upgrade_id = ?", upgrade.ID).Pluck("host_name", &upgradedHosts).Error
+	if err != nil {
+		return
+	}
+
+	query := a.db.Where("env = ? and zone_code = ? and agent_type = 1 and agent_version != ?", zone.Env, zone.ZoneCode, upgrade.Version)
+	if len(upgradedHosts) > 0 {
+		query = query.Where("host_name not in (?)", upgradedHosts)
+	}
+	err = query.Order("host_name asc").Find(&candidates).Error
+	if err != nil {
+		return
+	}
+
+	if upgrade.Stage == db.AgentUpgradeStageCanary {
+		cnt := int(math.Ceil(float64(len(candidates)) * float64(upgrade.CanaryPercent) / 100))
+		candidates = candidates[:cnt]
+	}
+
+	payload := view.AgentUpgradePayload{
+		UpgradeID: upgrade.ID,
+		Version:   pkg.Version,
+		URL:       packageDownloadURL(pkg.Version),
+		Sha256:    pkg.Sha256,
+	}
+	buf, _ := json.Marshal(payload)
+	uniqZone := view.UniqZone{
+		Env:  zone.Env,
+		Zone: zone.ZoneCode,
+	}
+
+	for _, candidate := range candidates {
+		now := time.Now()
+		node := db.AgentUpgradeNode{
+			UpgradeID:    upgrade.ID,
+			ZoneIndex:    upgrade.ZoneIndex,
+			Stage:        upgrade.Stage,
+			HostName:     candidate.HostName,
+			FromVersion:  candidate.AgentVersion,
+			Status:       db.AgentUpgradeNodeStatusDispatched,
+			DispatchedAt: &now,
+		}
+
+		key := strings.Replace(EtcdKeyFmtAgentUpgrade, "{{hostname}}", candidate.HostName, -1)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, putErr := clientproxy.ClientProxy.DefaultEtcdPut(uniqZone, ctx, key, string(buf))
+		cancel()
+		if putErr != nil {
+			xlog.Error("agentUpgrade.startStage write etcd failed", xlog.Any("uniqZone", uniqZone), xlog.String("key", key),
+				xlog.String("err", putErr.Error()))
+			node.Status = db.AgentUpgradeNodeStatusFailed
+			node.FinishedAt = &now
+		}
+
+		err = a.db.Create(&node).Error
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func packageDownloadURL(version string) string {
+	base := cfg.Cfg.Agent.DownloadURL
+	if base == "" {
+		base = cfg.Cfg.AppURL
+	}
+	return strings.TrimSuffix(base, "/") + "/api/v1/agent/package/download?version=" + url.QueryEscape(version)
+}
+
+func makeAgentUpgrade(item db.AgentUpgrade) view.AgentUpgrade {
+	return view.AgentUpgrade{
+		ID:               item.ID,
+		Version:          item.Version,
+		Zones:            item.Zones,
+		CanaryPercent:    item.CanaryPercent,
+		FailureThreshold: item.FailureThreshold,
+		ZoneIndex:        item.ZoneIndex,
+		Stage:            item.Stage,
+		Status:           item.Status,
+		Reason:           item.Reason,
+		CreatedAt:        item.CreatedAt,
+		UpdatedAt:        item.UpdatedAt,
+	}
+}
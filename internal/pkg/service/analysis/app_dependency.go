@@ -0,0 +1,214 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/internal/pkg/service/configresource"
+	"github.com/douyu/juno/pkg/cache"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// registryPrefix jupiter 注册中心前缀
+const registryPrefix = "/jupiter/"
+
+// registryCache 注册中心快照。服务注册由应用直接写入 etcd，无法主动失效，使用较短的过期时间
+var registryCache = cache.New("registry", 30*time.Second)
+
+// AppDependency 应用依赖拓扑：配置依赖解析、配置资源引用得到各应用的依赖，
+// 应用节点的 RPC 地址和注册中心的服务提供者用来识别依赖地址属于哪个应用
+func (r *analysis) AppDependency(param view.ReqAppDependency) (resp view.RespAppDependency, err error) {
+	resp.AppName = param.AppName
+	resp.Errors = make([]string, 0)
+
+	refs, err := r.dependencyRefs(param.Env, param.ZoneCode)
+	if err != nil {
+		return
+	}
+	providers, err := r.appProviders(param.Env, param.ZoneCode)
+	if err != nil {
+		return
+	}
+	if param.ZoneCode != "" {
+		err = registryProviders(param.Env, param.ZoneCode, providers)
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("注册中心: %s", err.Error()))
+		}
+	}
+
+	dep := buildDependency(param.AppName, refs, providers)
+	fillDependencyGraph(&resp, dep)
+	return resp, nil
+}
+
+// dependencyRefs 环境下所有应用的依赖
+func (r *analysis) dependencyRefs(env, zoneCode string) (refs []DependencyRef, err error) {
+	var rows []struct {
+		AppName string
+		Type    string
+		Name    string
+		Addr    string
+	}
+	query := r.DB.Table("app_topology").Select("app_name, type, name, addr").Where("env = ?", env)
+	if zoneCode != "" {
+		query = query.Where("zone_code = ?", zoneCode)
+	}
+	err = query.Scan(&rows).Error
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		refs = append(refs, DependencyRef{
+			AppName: row.AppName,
+			Source:  RefSourceConfig,
+			Type:    row.Type,
+			Name:    row.Name,
+			Addr:    row.Addr,
+		})
+	}
+
+	var configs []struct {
+		AppName string
+		Content string
+	}
+	query = r.DB.Table("configuration AS a").Select("b.app_name, a.content").
+		Joins("JOIN app b ON a.aid = b.aid").
+		Where("a.env = ? and a.deleted_at is null", env)
+	if zoneCode != "" {
+		query = query.Where("a.zone = ?", zoneCode)
+	}
+	err = query.Scan(&configs).Error
+	if err != nil {
+		return
+	}
+	for _, config := range configs {
+		for _, item := range configresource.ParseResourceFromConfig(config.Content) {
+			refs = append(refs, DependencyRef{
+				AppName: config.AppName,
+				Source:  RefSourceResource,
+				Type:    RefSourceResource,
+				Name:    item.Name,
+				Addr:    item.Name,
+			})
+		}
+	}
+	return
+}
+
+// appProviders 应用节点 IP 与应用 RPC 端口组成的地址
+func (r *analysis) appProviders(env, zoneCode string) (providers map[string]string, err error) {
+	var rows []struct {
+		AppName string
+		IP      string
+		RPCPort string
+	}
+	query := r.DB.Table("app_node AS a").Select("b.app_name, a.ip, b.rpc_port").
+		Joins("JOIN app b ON a.aid = b.aid").
+		Where("a.env = ? and b.rpc_port != ''", env)
+	if zoneCode != "" {
+		query = query.Where("a.zone_code = ?", zoneCode)
+	}
+	err = query.Scan(&rows).Error
+	if err != nil {
+		return
+	}
+
+	providers = make(map[string]string, len(rows))
+	for _, row := range rows {
+		providers[row.IP+":"+row.RPCPort] = row.AppName
+	}
+	return
+}
+
+// registryProviders 注册中心的服务提供者，覆盖按节点推断的地址
+func registryProviders(env, zoneCode string, providers map[string]string) error {
+	var snapshot map[string]string
+	err := registryCache.Fetch(env+":"+zoneCode, &snapshot, func() (err error) {
+		snapshot, err = registrySnapshot(env, zoneCode)
+		return
+	})
+	if err != nil {
+		return err
+	}
+	for addr, app := range snapshot {
+		providers[addr] = app
+	}
+	return nil
+}
+
+// registrySnapshot 读取注册中心中的服务提供者，key 为地址，value 为应用名
+func registrySnapshot(env, zoneCode string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := clientproxy.ClientProxy.RegisterEtcdGet(view.UniqZone{Env: env, Zone: zoneCode}, ctx, registryPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if app, addr, ok := ParseProviderKey(string(kv.Key)); ok {
+			snapshot[addr] = app
+		}
+	}
+	return snapshot, nil
+}
+
+func fillDependencyGraph(resp *view.RespAppDependency, dep dependency) {
+	resp.Upstreams = dep.Upstreams
+	resp.Downstreams = make([]string, 0, len(dep.Downstreams))
+	resp.SharedResources = make([]view.AppSharedResource, 0, len(dep.Shared))
+	resp.Nodes = make([]view.AppDependencyNode, 0)
+	resp.Edges = make([]view.AppDependencyEdge, 0)
+
+	nodes := make(map[string]bool)
+	addApp := func(name string) string {
+		id := "app:" + name
+		if !nodes[id] {
+			nodes[id] = true
+			resp.Nodes = append(resp.Nodes, view.AppDependencyNode{ID: id, Name: name, Category: "app", Current: name == resp.AppName})
+		}
+		return id
+	}
+	addResource := func(typ, addr string) string {
+		id := "resource:" + typ + ":" + addr
+		if !nodes[id] {
+			nodes[id] = true
+			resp.Nodes = append(resp.Nodes, view.AppDependencyNode{ID: id, Name: addr, Category: "resource", Type: typ})
+		}
+		return id
+	}
+
+	current := addApp(resp.AppName)
+	for _, name := range dep.Upstreams {
+		resp.Edges = append(resp.Edges, view.AppDependencyEdge{Source: addApp(name), Target: current, Type: "call"})
+	}
+
+	for name := range dep.Downstreams {
+		resp.Downstreams = append(resp.Downstreams, name)
+	}
+	sort.Strings(resp.Downstreams)
+	for _, name := range resp.Downstreams {
+		target := addApp(name)
+		for _, ref := range dep.Downstreams[name] {
+			resp.Edges = append(resp.Edges, view.AppDependencyEdge{Source: current, Target: target, Type: "call", Name: ref.Name})
+		}
+	}
+
+	for _, ref := range dep.Resources {
+		resp.Edges = append(resp.Edges, view.AppDependencyEdge{Source: current, Target: addResource(ref.Type, ref.Addr), Type: "use", Name: ref.Name})
+	}
+	for _, shared := range dep.Shared {
+		resp.SharedResources = append(resp.SharedResources, view.AppSharedResource{Type: shared.Type, Addr: shared.Addr, Apps: shared.Apps})
+		target := addResource(shared.Type, shared.Addr)
+		for _, name := range shared.Apps {
+			if name != resp.AppName {
+				resp.Edges = append(resp.Edges, view.AppDependencyEdge{Source: addApp(name), Target: target, Type: "use"})
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+)
+
+// 依赖引用的来源
+const (
+	RefSourceConfig   = "config"          // 配置依赖解析结果 app_topology
+	RefSourceResource = "config_resource" // 配置中引用的配置资源 {{name@version}}
+)
+
+// registryProviderSegment jupiter 注册中心 key 格式 /{prefix}/{app}/providers/{scheme}://{addr}
+const registryProviderSegment = "/providers/"
+
+type (
+	// DependencyRef 应用配置中的一条依赖
+	DependencyRef struct {
+		AppName string
+		Source  string
+		Type    string // mysql、redis、grpc 等，配置资源为 config_resource
+		Name    string // 配置中的 key 或配置资源名
+		Addr    string // 地址，配置资源为资源名
+	}
+
+	// SharedResource 多个应用共用的资源
+	SharedResource struct {
+		Type string
+		Addr string
+		Apps []string
+	}
+
+	// dependency 应用的上下游与使用的资源
+	dependency struct {
+		Upstreams   []string
+		Downstreams map[string][]DependencyRef // 下游应用及调用它的配置
+		Resources   []DependencyRef            // 非应用的依赖
+		Shared      []SharedResource
+	}
+)
+
+// ParseProviderKey 解析注册中心的服务提供者 key，返回应用名与地址
+func ParseProviderKey(key string) (app, addr string, ok bool) {
+	i := strings.Index(key, registryProviderSegment)
+	if i < 0 {
+		return
+	}
+	prefix := strings.TrimSuffix(key[:i], "/")
+	app = prefix[strings.LastIndex(prefix, "/")+1:]
+	addr = key[i+len(registryProviderSegment):]
+	if j := strings.Index(addr, "://"); j >= 0 {
+		addr = addr[j+3:]
+	}
+	addr = strings.TrimSuffix(addr, "/")
+	return app, addr, app != "" && addr != ""
+}
+
+// ResolveTarget 依赖地址对应的应用：etcd:///app 等服务发现地址直接取应用名，否则按服务提供者地址匹配
+func ResolveTarget(addr string, providers map[string]string) string {
+	if i := strings.Index(addr, ":///"); i >= 0 {
+		return strings.Trim(addr[i+4:], "/")
+	}
+	return providers[addr]
+}
+
+// buildDependency 根据所有应用的依赖计算 appName 的上游、下游和共用资源
+func buildDependency(appName string, refs []DependencyRef, providers map[string]string) dependency {
+	dep := dependency{
+		Upstreams:   make([]string, 0),
+		Downstreams: make(map[string][]DependencyRef),
+		Resources:   make([]DependencyRef, 0),
+		Shared:      make([]SharedResource, 0),
+	}
+
+	type resourceKey struct{ Type, Addr string }
+	users := make(map[resourceKey][]string)
+	owned := make(map[resourceKey]bool)
+	for _, ref := range refs {
+		target := ""
+		if ref.Source != RefSourceResource {
+			target = ResolveTarget(ref.Addr, providers)
+		}
+		if target != "" {
+			if target == ref.AppName {
+				continue
+			}
+			if ref.AppName == appName {
+				dep.Downstreams[target] = append(dep.Downstreams[target], ref)
+			} else if target == appName {
+				dep.Upstreams = appendUnique(dep.Upstreams, ref.AppName)
+			}
+			continue
+		}
+
+		key := resourceKey{Type: ref.Type, Addr: ref.Addr}
+		users[key] = appendUnique(users[key], ref.AppName)
+		if ref.AppName == appName && !owned[key] {
+			owned[key] = true
+			dep.Resources = append(dep.Resources, ref)
+		}
+	}
+
+	for _, ref := range dep.Resources {
+		apps := users[resourceKey{Type: ref.Type, Addr: ref.Addr}]
+		if len(apps) < 2 {
+			continue
+		}
+		sort.Strings(apps)
+		dep.Shared = append(dep.Shared, SharedResource{Type: ref.Type, Addr: ref.Addr, Apps: apps})
+	}
+	sort.Strings(dep.Upstreams)
+	return dep
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,48 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProviderKey(t *testing.T) {
+	app, addr, ok := ParseProviderKey("/jupiter/order/providers/grpc://10.0.0.1:9091")
+	if !ok || app != "order" || addr != "10.0.0.1:9091" {
+		t.Errorf("app = %q, addr = %q, ok = %v", app, addr, ok)
+	}
+	if _, _, ok = ParseProviderKey("/jupiter/order/configurators/grpc://10.0.0.1:9091"); ok {
+		t.Errorf("configurators key should not be parsed")
+	}
+}
+
+func TestBuildDependency(t *testing.T) {
+	providers := map[string]string{"10.0.0.1:9091": "order", "10.0.0.2:9091": "user"}
+	refs := []DependencyRef{
+		{AppName: "order", Source: RefSourceConfig, Type: "grpc", Name: "jupiter.grpc.user", Addr: "10.0.0.2:9091"},
+		{AppName: "order", Source: RefSourceConfig, Type: "grpc", Name: "jupiter.grpc.pay", Addr: "etcd:///pay"},
+		{AppName: "order", Source: RefSourceConfig, Type: "mysql", Name: "jupiter.mysql.order", Addr: "10.0.1.1:3306"},
+		{AppName: "order", Source: RefSourceResource, Type: RefSourceResource, Name: "redis_main", Addr: "redis_main"},
+		{AppName: "gateway", Source: RefSourceConfig, Type: "grpc", Name: "jupiter.grpc.order", Addr: "10.0.0.1:9091"},
+		{AppName: "report", Source: RefSourceConfig, Type: "mysql", Name: "jupiter.mysql.order", Addr: "10.0.1.1:3306"},
+		{AppName: "user", Source: RefSourceResource, Type: RefSourceResource, Name: "redis_main", Addr: "redis_main"},
+		{AppName: "user", Source: RefSourceConfig, Type: "grpc", Name: "jupiter.grpc.self", Addr: "10.0.0.2:9091"},
+	}
+
+	dep := buildDependency("order", refs, providers)
+	if !reflect.DeepEqual(dep.Upstreams, []string{"gateway"}) {
+		t.Errorf("upstreams = %v", dep.Upstreams)
+	}
+	if len(dep.Downstreams) != 2 || len(dep.Downstreams["user"]) != 1 || len(dep.Downstreams["pay"]) != 1 {
+		t.Errorf("downstreams = %v", dep.Downstreams)
+	}
+	if len(dep.Resources) != 2 {
+		t.Errorf("resources = %v", dep.Resources)
+	}
+	want := []SharedResource{
+		{Type: "mysql", Addr: "10.0.1.1:3306", Apps: []string{"order", "report"}},
+		{Type: RefSourceResource, Addr: "redis_main", Apps: []string{"order", "user"}},
+	}
+	if !reflect.DeepEqual(dep.Shared, want) {
+		t.Errorf("shared = %+v", dep.Shared)
+	}
+}
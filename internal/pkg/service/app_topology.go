@@ -84,7 +84,7 @@ func GetRelationApps(reqModel view.ReqRelationApps) (view.RespRelationApps, erro
  */
 func ListRSByEnvAndType(env, t string) ([]*SrvRelation, error) {
 	relations := make([]*SrvRelation, 0)
-	if err := invoker.JunoMysql.Table("app_topology AS a").Select("a.aid, b.app_name AS source, a.addr AS target, a.`name`, a.update_time, a.meta_data").
+	if err := invoker.JunoMysql.Table("app_topology AS a").Select("a.aid, b.app_name AS source, a.addr AS target, a.name, a.update_time, a.meta_data").
 		Joins("LEFT JOIN app b ON a.aid = b.aid").Where("a.env = ? and a.type = ?", env, t).Find(&relations).Error; err != nil {
 		return relations, err
 	}
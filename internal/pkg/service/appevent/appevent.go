@@ -14,6 +14,7 @@ import (
 	"github.com/douyu/juno/pkg/model/view"
 
 	"github.com/douyu/juno/internal/pkg/invoker"
+	"github.com/douyu/juno/pkg/metrics"
 	"github.com/douyu/juno/pkg/model/db"
 )
 
@@ -33,6 +34,7 @@ func InitAppEvent(eventProducer rocketmq2.Producer, topic string) *appEvent {
 		eventProducer: eventProducer,
 		topic:         topic,
 	}
+	metrics.RegisterQueue("appevent", func() int { return len(obj.eventChan) })
 	go obj.ConsumeEvent()
 	AppEvent = obj
 	return obj
@@ -0,0 +1,318 @@
+package appimport
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/conf"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/go-resty/resty/v2"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	defaultFrameName = "github.com/douyu/jupiter"
+	defaultMaxOwners = 3
+)
+
+var (
+	// AppImport 扫描 GitLab 分组批量导入应用
+	AppImport *appImport
+
+	ErrScanRunning = fmt.Errorf("正在扫描中，请稍后再试")
+)
+
+type (
+	Option struct {
+		DB   *gorm.DB
+		Conf cfg.AppImport
+	}
+
+	appImport struct {
+		db   *gorm.DB
+		conf cfg.AppImport
+
+		scanning int32
+	}
+)
+
+// Init 初始化，GitLab 地址和 Token 未配置时使用 godep.gitlab 的配置
+func Init(o Option) {
+	if o.Conf.Host == "" {
+		o.Conf.Host = conf.GetString("godep.gitlab.host")
+	}
+	if o.Conf.Token == "" {
+		o.Conf.Token = conf.GetString("godep.gitlab.token")
+	}
+	if o.Conf.FrameName == "" {
+		o.Conf.FrameName = defaultFrameName
+	}
+	if o.Conf.MaxOwners <= 0 {
+		o.Conf.MaxOwners = defaultMaxOwners
+	}
+
+	AppImport = &appImport{
+		db:   o.DB,
+		conf: o.Conf,
+	}
+}
+
+// List 待导入应用列表
+func (a *appImport) List(param view.ReqListAppImport) (list []view.AppImportCandidate, page *view.Pagination, err error) {
+	var rows []db.AppImportCandidate
+
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := a.db.Model(&db.AppImportCandidate{})
+	if param.Status != "" {
+		query = query.Where("status = ?", param.Status)
+	}
+	if param.GroupPath != "" {
+		query = query.Where("group_path = ?", param.GroupPath)
+	}
+	if param.Keyword != "" {
+		query = query.Where("app_name like ? or module like ?", "%"+param.Keyword+"%", "%"+param.Keyword+"%")
+	}
+
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&rows).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.AppImportCandidate, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, transformCandidate(row))
+	}
+	return
+}
+
+// Scan 扫描配置的 GitLab 分组，包含 go.mod 且尚未在 Juno 中创建的项目记录为待导入应用。
+// 同一时间只允许一次扫描
+func (a *appImport) Scan() (resp view.RespScanAppImport, err error) {
+	if len(a.conf.Groups) == 0 {
+		return resp, fmt.Errorf("未配置需要扫描的 GitLab 分组 appImport.groups")
+	}
+	if a.conf.Host == "" {
+		return resp, fmt.Errorf("未配置 GitLab 地址 appImport.host")
+	}
+
+	if !atomic.CompareAndSwapInt32(&a.scanning, 0, 1) {
+		return resp, ErrScanRunning
+	}
+	defer atomic.StoreInt32(&a.scanning, 0)
+
+	client := &gitlabClient{
+		Client: resty.New().SetHostURL(a.conf.Host).SetTimeout(10*time.Second).SetHeader("PRIVATE-TOKEN", a.conf.Token),
+	}
+
+	resp.Errors = make([]string, 0)
+	for _, group := range a.conf.Groups {
+		projects, err := client.groupProjects(group)
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("分组 %s: %s", group, err.Error()))
+			continue
+		}
+
+		for _, project := range projects {
+			resp.Projects++
+			found, err := a.scanProject(client, project)
+			if err != nil {
+				resp.Errors = append(resp.Errors, fmt.Sprintf("项目 %s: %s", project.PathWithNamespace, err.Error()))
+				continue
+			}
+			if found {
+				resp.Candidates++
+			}
+		}
+	}
+
+	xlog.Info("appimport.Scan finished", xlog.Int("projects", resp.Projects), xlog.Int("candidates", resp.Candidates), xlog.Int("errors", len(resp.Errors)))
+	return resp, nil
+}
+
+// ScanTick 定时扫描
+func (a *appImport) ScanTick() error {
+	_, err := a.Scan()
+	if err != nil && err != ErrScanRunning {
+		xlog.Error("appimport.ScanTick failed", xlog.String("err", err.Error()))
+	}
+	return nil
+}
+
+// Import 将待导入应用创建为 Juno 应用，应用名已存在的跳过并记录原因
+func (a *appImport) Import(u *db.User, param view.ReqAppImport) (resp view.RespAppImport, err error) {
+	var rows []db.AppImportCandidate
+	err = a.db.Where("id in (?) and status = ?", param.IDs, db.AppImportStatusPending).Find(&rows).Error
+	if err != nil {
+		return
+	}
+
+	resp.Imported = make([]string, 0)
+	resp.Errors = make([]string, 0)
+	for _, row := range rows {
+		err := resource.Resource.CreateApp(db.AppInfo{
+			Gid:     row.Gid,
+			Name:    row.Name,
+			AppName: row.AppName,
+			Lang:    "go",
+			Users:   splitList(row.Owners),
+			WebURL:  row.WebURL,
+			GitURL:  row.GitURL,
+		}, u)
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %s", row.AppName, err.Error()))
+			continue
+		}
+
+		app, err := resource.Resource.GetApp(row.AppName)
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %s", row.AppName, err.Error()))
+			continue
+		}
+
+		err = a.db.Model(&row).Updates(map[string]interface{}{
+			"status": db.AppImportStatusImported,
+			"aid":    app.Aid,
+		}).Error
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %s", row.AppName, err.Error()))
+			continue
+		}
+		resp.Imported = append(resp.Imported, row.AppName)
+	}
+	return resp, nil
+}
+
+// Ignore 忽略待导入应用，重新扫描时不会恢复
+func (a *appImport) Ignore(param view.ReqAppImport) error {
+	return a.db.Model(&db.AppImportCandidate{}).
+		Where("id in (?) and status = ?", param.IDs, db.AppImportStatusPending).
+		Update("status", db.AppImportStatusIgnored).Error
+}
+
+// scanProject 项目包含 go.mod 时更新待导入应用，已在 Juno 中创建的项目不记录
+func (a *appImport) scanProject(client *gitlabClient, project gitlabProject) (found bool, err error) {
+	if project.EmptyRepo || project.Archived || project.DefaultBranch == "" {
+		return
+	}
+
+	var count int
+	err = a.db.Model(&db.AppInfo{}).Where("gid = ?", project.ID).Count(&count).Error
+	if err != nil || count > 0 {
+		return
+	}
+
+	content, ok, err := client.rawFile(project.ID, "go.mod", project.DefaultBranch)
+	if err != nil || !ok {
+		return
+	}
+	mod := ParseGoMod(content)
+
+	contributors, err := client.contributors(project.ID)
+	if err != nil {
+		return
+	}
+	owners, committers, err := a.matchOwners(contributors)
+	if err != nil {
+		return
+	}
+
+	var item db.AppImportCandidate
+	err = a.db.Where("gid = ?", project.ID).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+	if item.ID == 0 {
+		item.Status = db.AppImportStatusPending
+	}
+
+	item.Gid = project.ID
+	item.AppName = AppNameFromModule(mod.Module, project.Path)
+	item.Name = project.Name
+	item.Module = mod.Module
+	item.Jupiter = mod.Require(a.conf.FrameName)
+	item.GroupPath = project.Namespace.FullPath
+	item.GitURL = project.HTTPURLToRepo
+	item.WebURL = project.WebURL
+	item.Owners = strings.Join(owners, ",")
+	item.Committers = strings.Join(committers, ",")
+	item.ScannedAt = time.Now()
+	err = a.db.Save(&item).Error
+	return err == nil, err
+}
+
+// matchOwners 提交次数最多的几名提交者，通过邮箱或用户名匹配 Juno 用户作为负责人
+func (a *appImport) matchOwners(contributors []gitlabContributor) (owners, committers []string, err error) {
+	owners = make([]string, 0)
+	committers = make([]string, 0)
+	for _, contributor := range contributors {
+		if len(committers) >= a.conf.MaxOwners {
+			break
+		}
+		committers = append(committers, contributor.Name)
+
+		var u db.User
+		err = a.db.Select("username").
+			Where("(email = ? or username = ?) and state != ?", contributor.Email, contributor.Name, db.UserStateDisabled).
+			First(&u).Error
+		if gorm.IsRecordNotFoundError(err) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			return
+		}
+		if !containsString(owners, u.Username) {
+			owners = append(owners, u.Username)
+		}
+	}
+	return
+}
+
+func transformCandidate(row db.AppImportCandidate) view.AppImportCandidate {
+	return view.AppImportCandidate{
+		ID:         row.ID,
+		Gid:        row.Gid,
+		AppName:    row.AppName,
+		Name:       row.Name,
+		Module:     row.Module,
+		Jupiter:    row.Jupiter,
+		GroupPath:  row.GroupPath,
+		GitURL:     row.GitURL,
+		WebURL:     row.WebURL,
+		Owners:     splitList(row.Owners),
+		Committers: splitList(row.Committers),
+		Status:     row.Status,
+		Aid:        row.Aid,
+		ScannedAt:  row.ScannedAt,
+	}
+}
+
+func splitList(s string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package appimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const gitlabPageSize = 100
+
+type (
+	gitlabClient struct {
+		*resty.Client
+	}
+
+	gitlabProject struct {
+		ID                int    `json:"id"`
+		Name              string `json:"name"`
+		Path              string `json:"path"`
+		PathWithNamespace string `json:"path_with_namespace"`
+		DefaultBranch     string `json:"default_branch"`
+		WebURL            string `json:"web_url"`
+		HTTPURLToRepo     string `json:"http_url_to_repo"`
+		EmptyRepo         bool   `json:"empty_repo"`
+		Archived          bool   `json:"archived"`
+		Namespace         struct {
+			FullPath string `json:"full_path"`
+		} `json:"namespace"`
+	}
+
+	gitlabContributor struct {
+		Name    string `json:"name"`
+		Email   string `json:"email"`
+		Commits int    `json:"commits"`
+	}
+)
+
+// groupProjects 分组及子分组下未归档的项目
+func (g *gitlabClient) groupProjects(group string) (projects []gitlabProject, err error) {
+	for page := 1; ; page++ {
+		var list []gitlabProject
+		err = g.get(fmt.Sprintf("/api/v4/groups/%s/projects", url.PathEscape(group)), map[string]string{
+			"include_subgroups": "true",
+			"archived":          "false",
+			"per_page":          strconv.Itoa(gitlabPageSize),
+			"page":              strconv.Itoa(page),
+		}, &list)
+		if err != nil {
+			return
+		}
+
+		projects = append(projects, list...)
+		if len(list) < gitlabPageSize {
+			return
+		}
+	}
+}
+
+// rawFile 项目文件内容，文件不存在时 found 为 false
+func (g *gitlabClient) rawFile(projectID int, file, ref string) (content string, found bool, err error) {
+	resp, err := g.R().
+		SetQueryParam("ref", ref).
+		Get(fmt.Sprintf("/api/v4/projects/%d/repository/files/%s/raw", projectID, url.PathEscape(file)))
+	if err != nil {
+		return
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return "", false, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return "", false, fmt.Errorf("gitlab %s: %s", resp.Status(), string(resp.Body()))
+	}
+	return string(resp.Body()), true, nil
+}
+
+// contributors 按提交次数倒序的提交者
+func (g *gitlabClient) contributors(projectID int) (list []gitlabContributor, err error) {
+	err = g.get(fmt.Sprintf("/api/v4/projects/%d/repository/contributors", projectID), map[string]string{
+		"order_by": "commits",
+		"sort":     "desc",
+		"per_page": strconv.Itoa(gitlabPageSize),
+	}, &list)
+	return
+}
+
+func (g *gitlabClient) get(path string, query map[string]string, out interface{}) error {
+	resp, err := g.R().SetQueryParams(query).Get(path)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("gitlab %s: %s", resp.Status(), string(resp.Body()))
+	}
+	return json.Unmarshal(resp.Body(), out)
+}
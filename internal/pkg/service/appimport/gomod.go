@@ -0,0 +1,89 @@
+package appimport
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// 模块路径最后一段为 v2、v3 等主版本号时需要去掉
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// GoMod go.mod 中导入应用需要的信息
+type GoMod struct {
+	Module   string
+	Requires []string
+}
+
+// ParseGoMod 解析 go.mod 的模块名和依赖模块，忽略版本、replace、exclude 等其他指令
+func ParseGoMod(content string) (mod GoMod) {
+	inRequire := false
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inRequire {
+			if fields[0] == ")" {
+				inRequire = false
+				continue
+			}
+			mod.Requires = append(mod.Requires, unquote(fields[0]))
+			continue
+		}
+
+		switch fields[0] {
+		case "module":
+			if len(fields) > 1 {
+				mod.Module = unquote(fields[1])
+			}
+		case "require":
+			if len(fields) > 1 && fields[1] == "(" {
+				inRequire = true
+			} else if len(fields) > 1 {
+				mod.Requires = append(mod.Requires, unquote(fields[1]))
+			}
+		}
+	}
+	return
+}
+
+// Require 是否依赖 module 或其子模块、主版本模块
+func (m GoMod) Require(module string) bool {
+	for _, item := range m.Requires {
+		if item == module || strings.HasPrefix(item, module+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// AppNameFromModule 根据模块名生成应用名：取最后一段路径，去掉主版本号，转为小写，非字母数字的字符替换为 -
+func AppNameFromModule(module, fallback string) string {
+	name := strings.TrimSuffix(module, "/")
+	if majorVersion.MatchString(path.Base(name)) {
+		name = path.Dir(name)
+	}
+	name = path.Base(name)
+	if name == "." || name == "/" || name == "" {
+		name = fallback
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, "\"`")
+}
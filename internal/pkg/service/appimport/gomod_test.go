@@ -0,0 +1,49 @@
+package appimport
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	mod := ParseGoMod(`module github.com/douyu/juno // juno admin
+
+go 1.14
+
+require github.com/BurntSushi/toml v0.3.1
+
+require (
+	// indirect deps
+	github.com/douyu/jupiter v0.2.5
+	"github.com/labstack/echo/v4" v4.1.16 // indirect
+)
+
+replace github.com/douyu/jupiter => ../jupiter
+`)
+	if mod.Module != "github.com/douyu/juno" {
+		t.Errorf("module = %q", mod.Module)
+	}
+	want := []string{"github.com/BurntSushi/toml", "github.com/douyu/jupiter", "github.com/labstack/echo/v4"}
+	if !reflect.DeepEqual(mod.Requires, want) {
+		t.Errorf("requires = %v", mod.Requires)
+	}
+	if !mod.Require("github.com/douyu/jupiter") || !mod.Require("github.com/labstack/echo") || mod.Require("github.com/douyu/jup") {
+		t.Error("Require mismatch")
+	}
+}
+
+func TestAppNameFromModule(t *testing.T) {
+	cases := []struct {
+		module, fallback, want string
+	}{
+		{"github.com/douyu/juno", "x", "juno"},
+		{"gitlab.example.com/biz/Order.Service/v2", "x", "order-service"},
+		{"", "user_api", "user_api"},
+		{"example", "x", "example"},
+	}
+	for _, c := range cases {
+		if got := AppNameFromModule(c.module, c.fallback); got != c.want {
+			t.Errorf("AppNameFromModule(%q) = %q, want %q", c.module, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package applifecycle
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/appevent"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// AppLifecycle 应用生命周期状态变更及归档、删除后的清理
+	AppLifecycle *appLifecycle
+
+	ErrCleanupRunning = fmt.Errorf("正在清理中，请稍后再试")
+)
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	appLifecycle struct {
+		db *gorm.DB
+
+		cleaning int32
+	}
+)
+
+func Init(o Option) {
+	AppLifecycle = &appLifecycle{
+		db: o.DB,
+	}
+}
+
+// SetStatus 修改应用状态。归档、删除时生成待清理的归档记录，恢复时取消尚未执行的清理
+func (a *appLifecycle) SetStatus(param view.ReqSetAppStatus, u *db.User) (err error) {
+	var app db.AppInfo
+	err = a.db.Where("aid = ?", param.Aid).First(&app).Error
+	if err != nil {
+		return
+	}
+	if app.Status == param.Status {
+		return nil
+	}
+	err = CheckTransition(app.Status, param.Status)
+	if err != nil {
+		return
+	}
+
+	tx := a.db.Begin()
+	err = tx.Model(db.AppInfo{}).Where("aid = ?", app.Aid).UpdateColumns(map[string]interface{}{
+		"status":      param.Status,
+		"update_time": time.Now().Unix(),
+		"updated_by":  u.Uid,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Unscoped().Where("aid = ? and cleaned_at is null", app.Aid).Delete(&db.AppArchive{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	if needCleanup(param.Status) {
+		err = tx.Create(&db.AppArchive{
+			Aid:     app.Aid,
+			AppName: app.AppName,
+			Status:  param.Status,
+			Reason:  param.Reason,
+			Uid:     u.Uid,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+	resource.InvalidateApp(app.Aid, app.AppName)
+
+	meta, _ := json.Marshal(map[string]interface{}{
+		"from":   app.Status,
+		"to":     param.Status,
+		"reason": param.Reason,
+	})
+	appevent.AppEvent.AppUpdateEvent(app.Aid, app.AppName, string(meta), u)
+	return nil
+}
+
+// ArchiveList 归档、删除记录
+func (a *appLifecycle) ArchiveList(param view.ReqListAppArchive) (list []db.AppArchive, err error) {
+	list = make([]db.AppArchive, 0)
+	query := a.db.Model(db.AppArchive{})
+	if param.Aid != 0 {
+		query = query.Where("aid = ?", param.Aid)
+	}
+	if param.AppName != "" {
+		query = query.Where("app_name = ?", param.AppName)
+	}
+	err = query.Order("id desc").Limit(100).Find(&list).Error
+	return
+}
+
+// Export 清理前导出的配置和定时任务
+func (a *appLifecycle) Export(id uint) (export view.AppArchiveExport, err error) {
+	var archive db.AppArchive
+	err = a.db.Where("id = ?", id).First(&archive).Error
+	if err != nil {
+		return
+	}
+	if archive.Configs == "" {
+		return export, fmt.Errorf("应用 %s 尚未清理，没有导出数据", archive.AppName)
+	}
+
+	export.AppName = archive.AppName
+	err = json.Unmarshal([]byte(archive.Configs), &export.Configs)
+	if err != nil {
+		return
+	}
+	if archive.CronJobs != "" {
+		err = json.Unmarshal([]byte(archive.CronJobs), &export.CronJobs)
+	}
+	return
+}
+
+// CleanupTick 定时清理已归档、待删除应用的关联资源
+func (a *appLifecycle) CleanupTick() error {
+	if !atomic.CompareAndSwapInt32(&a.cleaning, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreInt32(&a.cleaning, 0)
+
+	var archives []db.AppArchive
+	err := a.db.Where("cleaned_at is null").Order("id asc").Find(&archives).Error
+	if err != nil {
+		xlog.Error("applifecycle.CleanupTick failed", xlog.String("err", err.Error()))
+		return nil
+	}
+	for _, archive := range archives {
+		err = a.cleanup(&archive)
+		if err != nil {
+			xlog.Error("applifecycle.cleanup failed", xlog.String("app", archive.AppName), xlog.String("err", err.Error()))
+			a.db.Model(&archive).UpdateColumn("error", err.Error())
+			continue
+		}
+		now := time.Now()
+		a.db.Model(&archive).UpdateColumns(map[string]interface{}{
+			"cleaned_at": &now,
+			"error":      "",
+		})
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package applifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/internal/pkg/service/confgov2"
+	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/internal/pkg/service/taskplatform"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+)
+
+// cleanup 导出配置和定时任务后删除它们，并清除注册中心中发布的配置。待删除的应用最后删除应用本身。
+// 失败时下次定时任务重试，已导出的数据不会被覆盖
+func (a *appLifecycle) cleanup(archive *db.AppArchive) (err error) {
+	if archive.Configs == "" {
+		err = a.export(archive)
+		if err != nil {
+			return fmt.Errorf("导出失败: %s", err.Error())
+		}
+	}
+
+	var configs []view.AppConfigExport
+	err = json.Unmarshal([]byte(archive.Configs), &configs)
+	if err != nil {
+		return
+	}
+	err = a.removeRegistryConfigs(archive.Aid, archive.AppName, configs)
+	if err != nil {
+		return fmt.Errorf("清理注册中心配置失败: %s", err.Error())
+	}
+	err = a.removeCronJobs(archive.AppName)
+	if err != nil {
+		return fmt.Errorf("删除定时任务失败: %s", err.Error())
+	}
+	var configIDs []uint
+	err = a.db.Model(&db.Configuration{}).Where("aid = ?", archive.Aid).Pluck("id", &configIDs).Error
+	if err != nil {
+		return fmt.Errorf("删除配置失败: %s", err.Error())
+	}
+	err = a.db.Where("aid = ?", archive.Aid).Delete(&db.Configuration{}).Error
+	if err != nil {
+		return fmt.Errorf("删除配置失败: %s", err.Error())
+	}
+	confgov2.InvalidateConfiguration(configIDs...)
+
+	if archive.Status != db.AppStatusDeleted {
+		return nil
+	}
+	err = a.db.Unscoped().Where("aid = ?", archive.Aid).Delete(&db.AppMetaValue{}).Error
+	if err != nil {
+		return
+	}
+	err = resource.Resource.Delete(archive.AppName, db.AppLogActionManuallyDelete, archive.Uid)
+	if gorm.IsRecordNotFoundError(err) {
+		err = nil
+	}
+	return
+}
+
+// export 导出应用的配置和定时任务，保存在归档记录中
+func (a *appLifecycle) export(archive *db.AppArchive) (err error) {
+	var configs []db.Configuration
+	err = a.db.Where("aid = ?", archive.Aid).Find(&configs).Error
+	if err != nil {
+		return
+	}
+	configList := make([]view.AppConfigExport, 0, len(configs))
+	for _, config := range configs {
+		configList = append(configList, view.AppConfigExport{
+			Name:        config.Name,
+			Format:      config.Format,
+			Env:         config.Env,
+			Zone:        config.Zone,
+			Version:     config.Version,
+			Content:     config.Content,
+			PublishedAt: config.PublishedAt,
+		})
+	}
+
+	var jobs []db.CronJob
+	err = a.db.Preload("Timers").Preload("User").Where("app_name = ?", archive.AppName).Find(&jobs).Error
+	if err != nil {
+		return
+	}
+	jobList := make([]view.CronJob, 0, len(jobs))
+	for _, job := range jobs {
+		timers := make([]view.CronJobTimer, 0, len(job.Timers))
+		for _, timer := range job.Timers {
+			timers = append(timers, view.CronJobTimer{ID: timer.ID, JobID: timer.JobID, Cron: timer.Cron})
+		}
+		jobList = append(jobList, view.CronJob{
+			ID:            job.ID,
+			Name:          job.Name,
+			Username:      job.User.Nickname,
+			AppName:       job.AppName,
+			Env:           job.Env,
+			Zone:          job.Zone,
+			Timeout:       job.Timeout,
+			RetryCount:    job.RetryCount,
+			RetryInterval: job.RetryInterval,
+			Script:        job.Script,
+			Enable:        job.Enable,
+			JobType:       job.JobType,
+			Timers:        timers,
+			Nodes:         job.Nodes,
+		})
+	}
+
+	configBuf, _ := json.Marshal(configList)
+	jobBuf, _ := json.Marshal(jobList)
+	err = a.db.Model(archive).UpdateColumns(map[string]interface{}{
+		"configs":   string(configBuf),
+		"cron_jobs": string(jobBuf),
+	}).Error
+	if err != nil {
+		return
+	}
+	archive.Configs = string(configBuf)
+	archive.CronJobs = string(jobBuf)
+	return nil
+}
+
+// removeRegistryConfigs 删除发布到注册中心 etcd 中的配置，agent 和 k8s 集群不再监听到该应用的配置
+func (a *appLifecycle) removeRegistryConfigs(aid int, appName string, configs []view.AppConfigExport) (err error) {
+	var nodes []db.AppNode
+	err = a.db.Where("aid = ?", aid).Find(&nodes).Error
+	if err != nil {
+		return
+	}
+
+	hosts := make(map[view.UniqZone][]string)
+	for _, config := range configs {
+		zone := view.UniqZone{Env: config.Env, Zone: config.Zone}
+		if _, ok := hosts[zone]; !ok {
+			hosts[zone] = make([]string, 0)
+		}
+	}
+	for _, node := range nodes {
+		zone := view.UniqZone{Env: node.Env, Zone: node.ZoneCode}
+		hosts[zone] = append(hosts[zone], node.HostName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for zone, hostNames := range hosts {
+		client := clientproxy.ClientProxy.DefaultEtcd(zone)
+		if client == nil {
+			// 没有配置 etcd 的可用区无法发布配置
+			continue
+		}
+		for _, prefix := range cfg.Cfg.Configure.Prefixes {
+			keys := []string{fmt.Sprintf("/%s/cluster/%s/%s/static/", prefix, appName, zone.Env)}
+			for _, hostName := range hostNames {
+				keys = append(keys, fmt.Sprintf("/%s/%s/%s/%s/static/", prefix, hostName, appName, zone.Env))
+			}
+			for _, key := range keys {
+				_, err = client.Delete(ctx, key, clientv3.WithPrefix())
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// removeCronJobs 删除应用的定时任务，同时从 etcd 中撤销
+func (a *appLifecycle) removeCronJobs(appName string) (err error) {
+	var jobs []db.CronJob
+	err = a.db.Select("id").Where("app_name = ?", appName).Find(&jobs).Error
+	if err != nil {
+		return
+	}
+	for _, job := range jobs {
+		err = taskplatform.Job.Delete(job.ID)
+		if err != nil {
+			return
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package applifecycle
+
+import (
+	"fmt"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+// statusTransitions 允许的状态变更，待删除的应用在清理后被删除，不能恢复
+var statusTransitions = map[string][]string{
+	db.AppStatusActive:     {db.AppStatusDeprecated, db.AppStatusArchived, db.AppStatusDeleted},
+	db.AppStatusDeprecated: {db.AppStatusActive, db.AppStatusArchived, db.AppStatusDeleted},
+	db.AppStatusArchived:   {db.AppStatusActive, db.AppStatusDeprecated, db.AppStatusDeleted},
+}
+
+// statusNames 状态名称，用于提示信息
+var statusNames = map[string]string{
+	db.AppStatusActive:     "正常",
+	db.AppStatusDeprecated: "已废弃",
+	db.AppStatusArchived:   "已归档",
+	db.AppStatusDeleted:    "待删除",
+}
+
+// CheckTransition 校验状态变更，历史数据的空状态视为 active
+func CheckTransition(from, to string) error {
+	if from == "" {
+		from = db.AppStatusActive
+	}
+	if _, ok := statusNames[to]; !ok {
+		return fmt.Errorf("未知的应用状态 %s", to)
+	}
+	for _, item := range statusTransitions[from] {
+		if item == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("应用状态不能从%s变更为%s", statusNames[from], statusNames[to])
+}
+
+// needCleanup 进入该状态后需要清理关联资源
+func needCleanup(status string) bool {
+	return status == db.AppStatusArchived || status == db.AppStatusDeleted
+}
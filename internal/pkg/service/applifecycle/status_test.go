@@ -0,0 +1,34 @@
+package applifecycle
+
+import (
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+func TestCheckTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		ok       bool
+	}{
+		{"", db.AppStatusArchived, true},
+		{db.AppStatusActive, db.AppStatusDeprecated, true},
+		{db.AppStatusDeprecated, db.AppStatusActive, true},
+		{db.AppStatusArchived, db.AppStatusActive, true},
+		{db.AppStatusArchived, db.AppStatusDeleted, true},
+		{db.AppStatusDeleted, db.AppStatusActive, false},
+		{db.AppStatusDeleted, db.AppStatusArchived, false},
+		{db.AppStatusActive, "offline", false},
+	}
+	for _, c := range cases {
+		err := CheckTransition(c.from, c.to)
+		if (err == nil) != c.ok {
+			t.Errorf("CheckTransition(%q, %q) = %v", c.from, c.to, err)
+		}
+	}
+
+	err := CheckTransition(db.AppStatusDeleted, db.AppStatusActive)
+	if err == nil || err.Error() != "应用状态不能从待删除变更为正常" {
+		t.Errorf("err = %v", err)
+	}
+}
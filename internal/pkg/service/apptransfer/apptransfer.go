@@ -0,0 +1,428 @@
+package apptransfer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/auditlog"
+	"github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/internal/pkg/service/team"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// AppTransfer 应用归属转移
+	AppTransfer *appTransfer
+
+	ErrTransferNotFound = fmt.Errorf("转移申请不存在")
+)
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	appTransfer struct {
+		db *gorm.DB
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	AppTransfer = &appTransfer{
+		db: o.DB,
+	}
+}
+
+// Create 发起转移，通知目标团队接受
+func (a *appTransfer) Create(u *db.User, param view.ReqCreateAppTransfer) (err error) {
+	var app db.AppInfo
+	err = a.db.Select("aid, app_name, team_id").Where("app_name = ?", param.AppName).First(&app).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = fmt.Errorf("应用 %s 不存在", param.AppName)
+		}
+		return
+	}
+
+	ok, err := a.isOwner(u, app.TeamID)
+	if err != nil {
+		return
+	}
+	if !ok {
+		return fmt.Errorf("只有应用所属团队的 owner 或管理员可以发起转移")
+	}
+	if param.ToTeamID == app.TeamID {
+		return fmt.Errorf("应用已属于该团队")
+	}
+
+	teams, err := a.teamNames([]uint{param.ToTeamID})
+	if err != nil {
+		return
+	}
+	if _, ok := teams[param.ToTeamID]; !ok {
+		return team.ErrTeamNotFound
+	}
+
+	users, err := a.checkUsers(param.Users)
+	if err != nil {
+		return
+	}
+
+	var count int
+	err = a.db.Model(&db.AppTransfer{}).
+		Where("app_name = ? and status = ?", param.AppName, db.AppTransferStatusPending).
+		Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		return fmt.Errorf("应用 %s 已有待接受的转移，请勿重复提交", param.AppName)
+	}
+
+	item := db.AppTransfer{
+		AppName:    app.AppName,
+		FromTeamID: app.TeamID,
+		ToTeamID:   param.ToTeamID,
+		Users:      users,
+		Reason:     param.Reason,
+		Status:     db.AppTransferStatusPending,
+		Uid:        u.Uid,
+	}
+	err = a.db.Create(&item).Error
+	if err != nil {
+		return
+	}
+
+	go team.Team.NotifyTeam(item.ToTeamID, notice.Event{
+		Type:     notice.EventApproval,
+		App:      item.AppName,
+		Severity: notice.SeverityWarning,
+		Subject:  fmt.Sprintf("[Juno] %s 申请将应用 %s 转移到团队 %s，请确认", u.Username, item.AppName, teams[item.ToTeamID]),
+		Content: fmt.Sprintf("【应用转移】%s 申请将应用 %s 转移到团队 %s，原因：%s，请团队 owner 前往 Juno 确认",
+			u.Username, item.AppName, teams[item.ToTeamID], item.Reason),
+		Approval: &notice.Approval{Kind: notice.ApprovalAppTransfer, ID: item.ID},
+	})
+	return
+}
+
+// List 转移记录，scope 为 review 时返回转入当前用户作为 owner 的团队的转移
+func (a *appTransfer) List(u *db.User, param view.ReqListAppTransfer) (list []view.AppTransfer, page *view.Pagination, err error) {
+	var items []db.AppTransfer
+
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := a.db.Model(&db.AppTransfer{})
+	switch param.Scope {
+	case view.AppTransferScopeMine:
+		query = query.Where("uid = ?", u.Uid)
+	case view.AppTransferScopeReview:
+		if !isAdmin(u) {
+			var teamIDs []uint
+			err = a.db.Model(&db.TeamMember{}).Where("uid = ? and role = ?", u.Uid, db.TeamMemberRoleOwner).
+				Pluck("team_id", &teamIDs).Error
+			if err != nil {
+				return
+			}
+			query = query.Where("to_team_id in (?)", teamIDs)
+		}
+	}
+	if param.Status != "" {
+		query = query.Where("status = ?", param.Status)
+	}
+	if param.AppName != "" {
+		query = query.Where("app_name = ?", param.AppName)
+	}
+
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&items).Error
+	if err != nil {
+		return
+	}
+
+	uids := make([]int, 0, len(items)*2)
+	teamIDs := make([]uint, 0, len(items)*2)
+	for _, item := range items {
+		uids = append(uids, item.Uid, item.ReviewerUid)
+		teamIDs = append(teamIDs, item.FromTeamID, item.ToTeamID)
+	}
+	usernames, err := a.usernames(uids)
+	if err != nil {
+		return
+	}
+	teams, err := a.teamNames(teamIDs)
+	if err != nil {
+		return
+	}
+
+	list = make([]view.AppTransfer, 0, len(items))
+	for _, item := range items {
+		list = append(list, view.AppTransfer{
+			ID:            item.ID,
+			AppName:       item.AppName,
+			FromTeamID:    item.FromTeamID,
+			FromTeam:      teams[item.FromTeamID],
+			ToTeamID:      item.ToTeamID,
+			ToTeam:        teams[item.ToTeamID],
+			Users:         item.Users,
+			Reason:        item.Reason,
+			Status:        item.Status,
+			Uid:           item.Uid,
+			Username:      usernames[item.Uid],
+			ReviewerUid:   item.ReviewerUid,
+			Reviewer:      usernames[item.ReviewerUid],
+			ReviewComment: item.ReviewComment,
+			ReviewedAt:    item.ReviewedAt,
+			CreatedAt:     item.CreatedAt,
+		})
+	}
+	return
+}
+
+// Review 目标团队 owner 或管理员接受、拒绝转移。
+// 接受时在同一事务中修改应用所属团队和负责人，团队权限、通知和值班随团队生效，待审批的申请由新团队审批
+func (a *appTransfer) Review(u *db.User, param view.ReqReviewAppTransfer) (err error) {
+	item, err := a.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	ok, err := a.isOwner(u, item.ToTeamID)
+	if err != nil {
+		return
+	}
+	if !ok {
+		return fmt.Errorf("只有目标团队的 owner 或管理员可以确认转移")
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":         db.AppTransferStatusRejected,
+		"reviewer_uid":   u.Uid,
+		"review_comment": param.Comment,
+		"reviewed_at":    &now,
+	}
+	if param.Accept {
+		fields["status"] = db.AppTransferStatusAccepted
+	}
+
+	tx := a.db.Begin()
+	err = transition(tx, item.ID, db.AppTransferStatusPending, fields)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	if param.Accept {
+		users := item.Users
+		if users == nil {
+			users = db.UserNameJSON{}
+		}
+		query := tx.Model(&db.AppInfo{}).
+			Where("app_name = ? and team_id = ?", item.AppName, item.FromTeamID).
+			UpdateColumns(map[string]interface{}{
+				"team_id":     item.ToTeamID,
+				"users":       users,
+				"update_time": now.Unix(),
+				"updated_by":  u.Uid,
+			})
+		if query.Error != nil {
+			tx.Rollback()
+			return query.Error
+		}
+		if query.RowsAffected == 0 {
+			tx.Rollback()
+			return fmt.Errorf("应用 %s 的所属团队已变更，请重新发起转移", item.AppName)
+		}
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	teams, _ := a.teamNames([]uint{item.FromTeamID, item.ToTeamID})
+	result := "拒绝"
+	if param.Accept {
+		result = "接受"
+		resource.InvalidateApp(0, item.AppName)
+		_ = casbin.Casbin.LoadPolicy()
+		auditlog.AuditLog.Record(db.AuditLog{
+			CreatedAt:  now,
+			Uid:        u.Uid,
+			UserName:   u.Username,
+			Action:     "应用归属转移",
+			Resource:   db.AuditResourcePermission,
+			ResourceID: strconv.Itoa(int(item.ID)),
+			AppName:    item.AppName,
+			Before:     fmt.Sprintf("team=%s", teams[item.FromTeamID]),
+			After:      fmt.Sprintf("team=%s users=%s", teams[item.ToTeamID], strings.Join(item.Users, ",")),
+		})
+		go a.notifyPendingApprovals(item.AppName)
+	}
+
+	go team.Team.NotifyTeam(item.FromTeamID, notice.Event{
+		Type:     notice.EventApproval,
+		App:      item.AppName,
+		Severity: notice.SeverityInfo,
+		Subject:  fmt.Sprintf("[Juno] 应用 %s 的转移已处理", item.AppName),
+		Content: fmt.Sprintf("【应用转移】应用 %s 转移到团队 %s 的申请已被 %s %s",
+			item.AppName, teams[item.ToTeamID], u.Username, result),
+	})
+	return
+}
+
+// Cancel 发起人撤回待接受的转移
+func (a *appTransfer) Cancel(u *db.User, id uint) (err error) {
+	item, err := a.find(id)
+	if err != nil {
+		return
+	}
+
+	if item.Uid != u.Uid {
+		return ErrTransferNotFound
+	}
+
+	return transition(a.db, item.ID, db.AppTransferStatusPending, map[string]interface{}{
+		"status": db.AppTransferStatusCanceled,
+	})
+}
+
+// notifyPendingApprovals 转移后重新通知待审批的权限申请和环境晋升，审批人已变为新团队的 owner
+func (a *appTransfer) notifyPendingApprovals(appName string) {
+	var requests []db.AccessRequest
+	err := a.db.Where("app_name = ? and status = ?", appName, db.AccessRequestStatusPending).Find(&requests).Error
+	if err != nil {
+		xlog.Error("apptransfer.notifyPendingApprovals failed", xlog.String("app", appName), xlog.String("err", err.Error()))
+	}
+	for _, item := range requests {
+		team.Team.Notify(notice.Event{
+			Type:     notice.EventApproval,
+			App:      item.AppName,
+			Env:      item.Env,
+			Severity: notice.SeverityWarning,
+			Subject:  fmt.Sprintf("[Juno] 应用 %s 已转入团队，有待审批的权限申请", item.AppName),
+			Content: fmt.Sprintf("【权限申请】应用 %s 已转入团队，环境 %s 的权限申请 #%d 待审批，请前往 Juno 审批",
+				item.AppName, item.Env, item.ID),
+			Approval: &notice.Approval{Kind: notice.ApprovalAccessRequest, ID: item.ID},
+		})
+	}
+
+	var promotions []db.Promotion
+	err = a.db.Where("app_name = ? and status = ?", appName, db.PromotionStatusPending).Find(&promotions).Error
+	if err != nil {
+		xlog.Error("apptransfer.notifyPendingApprovals failed", xlog.String("app", appName), xlog.String("err", err.Error()))
+	}
+	for _, item := range promotions {
+		team.Team.Notify(notice.Event{
+			Type:     notice.EventApproval,
+			App:      item.AppName,
+			Env:      item.TargetEnv,
+			Severity: notice.SeverityWarning,
+			Subject:  fmt.Sprintf("[Juno] 应用 %s 已转入团队，有待审批的环境晋升", item.AppName),
+			Content: fmt.Sprintf("【环境晋升】应用 %s 已转入团队，%s 从 %s 晋升到 %s 的申请 #%d 待审批，请前往 Juno 审批",
+				item.AppName, item.Name, item.SourceEnv, item.TargetEnv, item.ID),
+			Approval: &notice.Approval{Kind: notice.ApprovalPromotion, ID: item.ID},
+		})
+	}
+}
+
+// transition 仅在转移处于 from 状态时更新，避免并发处理
+func transition(tx *gorm.DB, id uint, from string, fields map[string]interface{}) error {
+	query := tx.Model(&db.AppTransfer{}).Where("id = ? and status = ?", id, from).Updates(fields)
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		return fmt.Errorf("转移状态已变更，请刷新后重试")
+	}
+	return nil
+}
+
+func (a *appTransfer) isOwner(u *db.User, teamID uint) (bool, error) {
+	if isAdmin(u) {
+		return true, nil
+	}
+	if teamID == 0 {
+		return false, nil
+	}
+	return team.Team.IsOwner(teamID, u.Uid)
+}
+
+// checkUsers 校验负责人用户存在
+func (a *appTransfer) checkUsers(names []string) (users db.UserNameJSON, err error) {
+	users = make(db.UserNameJSON, 0, len(names))
+	if len(names) == 0 {
+		return
+	}
+
+	var exists []string
+	err = a.db.Model(&db.User{}).Where("username in (?)", names).Pluck("username", &exists).Error
+	if err != nil {
+		return
+	}
+	found := make(map[string]bool, len(exists))
+	for _, name := range exists {
+		found[name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, fmt.Errorf("用户 %s 不存在", name)
+		}
+		users = append(users, name)
+	}
+	return
+}
+
+func (a *appTransfer) find(id uint) (item db.AppTransfer, err error) {
+	err = a.db.Where("id = ?", id).First(&item).Error
+	if err != nil && gorm.IsRecordNotFoundError(err) {
+		err = ErrTransferNotFound
+	}
+	return
+}
+
+func (a *appTransfer) teamNames(ids []uint) (names map[uint]string, err error) {
+	names = make(map[uint]string)
+	if len(ids) == 0 {
+		return
+	}
+
+	var teams []db.Team
+	err = a.db.Select("id, name").Where("id in (?)", ids).Find(&teams).Error
+	if err != nil {
+		return
+	}
+	for _, item := range teams {
+		names[item.ID] = item.Name
+	}
+	return
+}
+
+func (a *appTransfer) usernames(uids []int) (names map[int]string, err error) {
+	names = make(map[int]string)
+	if len(uids) == 0 {
+		return
+	}
+
+	var users []db.User
+	err = a.db.Select("uid, username").Where("uid in (?)", uids).Find(&users).Error
+	if err != nil {
+		return
+	}
+	for _, item := range users {
+		names[item.Uid] = item.Username
+	}
+	return
+}
+
+func isAdmin(u *db.User) bool {
+	return u.Access == "admin"
+}
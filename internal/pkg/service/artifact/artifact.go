@@ -0,0 +1,114 @@
+// Package artifact 流水线制品的对象存储。
+// worker 使用 Juno 签发的预签名地址直接上传，下载时同样跳转到预签名地址，存储的访问密钥只保存在 Juno
+package artifact
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+const (
+	defaultRegion    = "us-east-1"
+	defaultURLExpire = 15 * time.Minute
+	defaultMaxSize   = 1 << 30
+)
+
+var (
+	ErrObjectNotFound = fmt.Errorf("制品文件不存在")
+
+	option Option
+	client *s3Client
+)
+
+type (
+	Option struct {
+		Conf cfg.TestArtifact
+	}
+)
+
+// Init 没有配置 Endpoint 时不启用对象存储
+func Init(o Option) (err error) {
+	option = o
+	client = nil
+	if o.Conf.Endpoint == "" {
+		return nil
+	}
+
+	endpoint, err := url.Parse(o.Conf.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("artifact: invalid endpoint %q", o.Conf.Endpoint)
+	}
+	if o.Conf.Bucket == "" || o.Conf.AccessKey == "" || o.Conf.SecretKey == "" {
+		return fmt.Errorf("artifact: bucket, accessKey and secretKey are required")
+	}
+	endpoint.Path = ""
+
+	if option.Conf.Region == "" {
+		option.Conf.Region = defaultRegion
+	}
+	if option.Conf.URLExpire <= 0 {
+		option.Conf.URLExpire = defaultURLExpire
+	}
+	if option.Conf.MaxSize <= 0 {
+		option.Conf.MaxSize = defaultMaxSize
+	}
+	if prefix := strings.Trim(option.Conf.Prefix, "/"); prefix != "" {
+		option.Conf.Prefix = prefix + "/"
+	}
+
+	client = &s3Client{
+		endpoint:  endpoint,
+		region:    option.Conf.Region,
+		bucket:    option.Conf.Bucket,
+		accessKey: option.Conf.AccessKey,
+		secretKey: option.Conf.SecretKey,
+		pathStyle: option.Conf.PathStyle,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+	}
+	xlog.Info("artifact storage enabled", xlog.String("endpoint", endpoint.String()), xlog.String("bucket", option.Conf.Bucket))
+	return nil
+}
+
+// Enabled 是否配置了对象存储
+func Enabled() bool {
+	return client != nil
+}
+
+// MaxSize 单个制品的大小上限
+func MaxSize() int64 {
+	return option.Conf.MaxSize
+}
+
+// ObjectKey 任务制品的 key，同一任务下同名制品使用同一个 key
+func ObjectKey(taskID uint, name string) string {
+	return fmt.Sprintf("%stasks/%d/%s", option.Conf.Prefix, taskID, name)
+}
+
+// UploadURL 使用 PUT 上传的预签名地址
+func UploadURL(key string) string {
+	return client.presign(http.MethodPut, key, option.Conf.URLExpire, nil)
+}
+
+// DownloadURL 预签名下载地址，下载的文件名为制品路径的最后一部分
+func DownloadURL(key, name, contentType string) string {
+	query := url.Values{}
+	query.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(name)}))
+	if contentType != "" {
+		query.Set("response-content-type", contentType)
+	}
+	return client.presign(http.MethodGet, key, option.Conf.URLExpire, query)
+}
+
+// Stat 对象的大小，对象不存在时返回 ErrObjectNotFound
+func Stat(key string) (int64, error) {
+	return client.stat(key)
+}
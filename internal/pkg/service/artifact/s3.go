@@ -0,0 +1,146 @@
+package artifact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	signAlgorithm  = "AWS4-HMAC-SHA256"
+	unsignedBody   = "UNSIGNED-PAYLOAD"
+	amzDateFormat  = "20060102T150405Z"
+	amzShortFormat = "20060102"
+)
+
+type (
+	// s3Client S3 兼容接口的对象存储，使用 AWS Signature V4 预签名地址访问，S3、OSS、MinIO 均支持
+	s3Client struct {
+		endpoint  *url.URL
+		region    string
+		bucket    string
+		accessKey string
+		secretKey string
+		pathStyle bool
+		client    *http.Client
+		now       func() time.Time
+	}
+)
+
+// presign 生成 method 访问 key 的预签名地址，query 为额外的参数，如 response-content-disposition
+func (c *s3Client) presign(method, key string, expire time.Duration, query url.Values) string {
+	u := *c.endpoint
+	if c.pathStyle {
+		u.Path = "/" + c.bucket + "/" + key
+	} else {
+		u.Host = c.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+
+	now := c.now().UTC()
+	scope := strings.Join([]string{now.Format(amzShortFormat), c.region, "s3", "aws4_request"}, "/")
+
+	params := url.Values{}
+	for k, v := range query {
+		params[k] = v
+	}
+	params.Set("X-Amz-Algorithm", signAlgorithm)
+	params.Set("X-Amz-Credential", c.accessKey+"/"+scope)
+	params.Set("X-Amz-Date", now.Format(amzDateFormat))
+	params.Set("X-Amz-Expires", fmt.Sprintf("%d", int(expire.Seconds())))
+	params.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalQuery := canonicalQueryString(params)
+	canonicalRequest := strings.Join([]string{
+		method,
+		uriEncode(u.Path, false),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedBody,
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		signAlgorithm,
+		now.Format(amzDateFormat),
+		scope,
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	key4 := hmacSHA256([]byte("AWS4"+c.secretKey), now.Format(amzShortFormat))
+	key4 = hmacSHA256(key4, c.region)
+	key4 = hmacSHA256(key4, "s3")
+	key4 = hmacSHA256(key4, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key4, stringToSign))
+
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + signature
+	return u.String()
+}
+
+// stat 返回对象的大小，对象不存在时返回 ErrObjectNotFound
+func (c *s3Client) stat(key string) (size int64, err error) {
+	resp, err := c.client.Head(c.presign(http.MethodHead, key, time.Minute, nil))
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, ErrObjectNotFound
+	case resp.StatusCode >= 300:
+		return 0, fmt.Errorf("head object %s: %s", key, resp.Status)
+	}
+	return resp.ContentLength, nil
+}
+
+func canonicalQueryString(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range params[k] {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode 按 SigV4 的规则编码，只保留 A-Z a-z 0-9 - _ . ~，encodeSlash 为 false 时保留 /
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
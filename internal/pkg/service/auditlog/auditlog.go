@@ -0,0 +1,266 @@
+package auditlog
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
+	"github.com/douyu/juno/pkg/graceful"
+	"github.com/douyu/juno/pkg/metrics"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/util/xgo"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	queueSize = 1024
+
+	// ExportLimit 单次导出的最大条数
+	ExportLimit = 10000
+)
+
+// listSpec 审计记录列表允许排序、筛选的字段
+var listSpec = listquery.Spec{
+	Sorts: map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+		"latency":    "latency",
+	},
+	Filters: map[string]string{
+		"method":    "method",
+		"action":    "action",
+		"status":    "status",
+		"code":      "code",
+		"latency":   "latency",
+		"client_ip": "client_ip",
+	},
+	DefaultSort:     "-id",
+	Tiebreaker:      "id",
+	DefaultPageSize: 20,
+}
+
+// AuditLog 平台操作审计
+var AuditLog *auditLog
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	auditLog struct {
+		db    *gorm.DB
+		queue chan db.AuditLog
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	AuditLog = &auditLog{
+		db:    o.DB,
+		queue: make(chan db.AuditLog, queueSize),
+	}
+	queue := AuditLog.queue
+	metrics.RegisterQueue("auditlog", func() int { return len(queue) })
+	graceful.OnShutdown("auditlog", func(ctx context.Context) error {
+		return graceful.WaitFor(ctx, func() bool { return len(queue) == 0 })
+	})
+	xgo.Go(AuditLog.consume)
+}
+
+// Record 异步写入审计记录，队列满时丢弃，避免影响业务请求
+func (a *auditLog) Record(item db.AuditLog) {
+	select {
+	case a.queue <- item:
+	default:
+		xlog.Warn("auditLog.Record queue is full, drop audit log",
+			xlog.String("path", item.Path), xlog.String("user", item.UserName))
+	}
+}
+
+func (a *auditLog) consume() {
+	for item := range a.queue {
+		err := a.db.Create(&item).Error
+		if err != nil {
+			xlog.Error("auditLog.consume create audit log failed", xlog.String("path", item.Path), xlog.String("err", err.Error()))
+		}
+	}
+}
+
+// List 审计记录列表
+func (a *auditLog) List(param view.ReqListAuditLog) (list []db.AuditLog, page *view.Pagination, err error) {
+	query, err := listSpec.Parse(param.ListQuery)
+	if err != nil {
+		return
+	}
+
+	var total int
+	sql := query.Where(a.query(param))
+	err = sql.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]db.AuditLog, 0)
+	err = query.Paginate(sql).Find(&list).Error
+	if err != nil {
+		return
+	}
+	page = query.Pagination(total, len(list))
+	return
+}
+
+// Export 按查询条件导出 CSV，最多导出 ExportLimit 条
+func (a *auditLog) Export(param view.ReqListAuditLog, w io.Writer) (err error) {
+	query, err := listSpec.Parse(param.ListQuery)
+	if err != nil {
+		return
+	}
+
+	var list []db.AuditLog
+	err = query.Where(a.query(param)).Order(query.Order).Limit(ExportLimit).Find(&list).Error
+	if err != nil {
+		return
+	}
+
+	writer := csv.NewWriter(w)
+	err = writer.Write([]string{"id", "created_at", "user_name", "method", "path", "action", "resource", "resource_id",
+		"app_name", "env", "request", "before", "after", "status", "code", "message", "latency_ms", "client_ip"})
+	if err != nil {
+		return
+	}
+
+	for _, item := range list {
+		err = writer.Write([]string{
+			strconv.Itoa(int(item.ID)),
+			item.CreatedAt.Format("2006-01-02 15:04:05"),
+			item.UserName,
+			item.Method,
+			item.Path,
+			item.Action,
+			item.Resource,
+			item.ResourceID,
+			item.AppName,
+			item.Env,
+			item.Request,
+			item.Before,
+			item.After,
+			strconv.Itoa(item.Status),
+			strconv.Itoa(item.Code),
+			item.Message,
+			strconv.FormatInt(item.Latency, 10),
+			item.ClientIP,
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (a *auditLog) query(param view.ReqListAuditLog) *gorm.DB {
+	query := a.db.Model(&db.AuditLog{})
+	if param.UserName != "" {
+		query = query.Where("user_name = ?", param.UserName)
+	}
+	if param.Resource != "" {
+		query = query.Where("resource = ?", param.Resource)
+	}
+	if param.ResourceID != "" {
+		query = query.Where("resource_id = ?", param.ResourceID)
+	}
+	if param.AppName != "" {
+		query = query.Where("app_name = ?", param.AppName)
+	}
+	if param.Env != "" {
+		query = query.Where("env = ?", param.Env)
+	}
+	if param.Path != "" {
+		query = query.Where("path like ?", param.Path+"%")
+	}
+	if param.Keyword != "" {
+		query = query.Where("request like ? or action like ?", "%"+param.Keyword+"%", "%"+param.Keyword+"%")
+	}
+	if param.StartTime > 0 {
+		query = query.Where("created_at >= ?", time.Unix(param.StartTime, 0))
+	}
+	if param.EndTime > 0 {
+		query = query.Where("created_at <= ?", time.Unix(param.EndTime, 0))
+	}
+	return query
+}
+
+// Snapshot 获取资源当前状态摘要，用于记录变更前后的对比，资源不存在时返回空字符串
+func (a *auditLog) Snapshot(resource, id string) string {
+	var (
+		summary interface{}
+		err     error
+	)
+
+	switch resource {
+	case db.AuditResourceConfig:
+		var item db.Configuration
+		err = a.db.Where("id = ?", id).First(&item).Error
+		summary = map[string]interface{}{
+			"id":             item.ID,
+			"aid":            item.AID,
+			"name":           item.Name,
+			"format":         item.Format,
+			"env":            item.Env,
+			"zone":           item.Zone,
+			"version":        item.Version,
+			"content_md5":    md5String(item.Content),
+			"content_length": len(item.Content),
+			"published_at":   item.PublishedAt,
+		}
+	case db.AuditResourcePipeline:
+		var item db.TestPipeline
+		err = a.db.Where("id = ?", id).First(&item).Error
+		summary = map[string]interface{}{
+			"id":         item.ID,
+			"name":       item.Name,
+			"app_name":   item.AppName,
+			"env":        item.Env,
+			"zone_code":  item.ZoneCode,
+			"branch":     item.Branch,
+			"code_check": item.CodeCheck,
+			"unit_test":  item.UnitTest,
+		}
+	case db.AuditResourceUser:
+		var item db.User
+		err = a.db.Where("uid = ?", id).First(&item).Error
+		summary = map[string]interface{}{
+			"uid":      item.Uid,
+			"username": item.Username,
+			"nickname": item.Nickname,
+			"email":    item.Email,
+			"access":   item.Access,
+		}
+	default:
+		return ""
+	}
+
+	if err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			xlog.Error("auditLog.Snapshot failed", xlog.String("resource", resource), xlog.String("id", id), xlog.String("err", err.Error()))
+		}
+		return ""
+	}
+
+	b, _ := json.Marshal(summary)
+	return string(b)
+}
+
+func md5String(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
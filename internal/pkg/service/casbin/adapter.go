@@ -2,6 +2,7 @@ package casbin
 
 import (
 	"fmt"
+	"strings"
 
 	casbinModel "github.com/casbin/casbin/v2/model"
 	"github.com/casbin/casbin/v2/persist"
@@ -34,6 +35,18 @@ func (a *CasbinAdapter) LoadPolicy(model casbinModel.Model) (err error) {
 		xlog.Error("load policy group error", zap.Error(err))
 		return err
 	}
+
+	err = a.loadPolicyTeam(model)
+	if err != nil {
+		xlog.Error("load policy team error", zap.Error(err))
+		return err
+	}
+
+	err = a.loadPolicyAccessRequest(model)
+	if err != nil {
+		xlog.Error("load policy access request error", zap.Error(err))
+		return err
+	}
 	return nil
 }
 
@@ -81,6 +94,74 @@ func (a *CasbinAdapter) loadPolicyGroup(m casbinModel.Model) (err error) {
 	return nil
 }
 
+// 加载团队策略，团队成员对团队应用的所有环境拥有团队默认权限
+// (g,uid,group_team:name)
+// (p,group_team:name,appname:*,act,app)
+func (a *CasbinAdapter) loadPolicyTeam(m casbinModel.Model) (err error) {
+	teams, err := TeamList()
+	if err != nil {
+		return
+	}
+
+	members, err := TeamMemberList()
+	if err != nil {
+		return
+	}
+
+	apps, err := TeamAppList()
+	if err != nil {
+		return
+	}
+
+	teamKeys := make(map[uint]string)
+	teamActions := make(map[uint][]string)
+	for _, team := range teams {
+		teamKeys[team.ID] = CasbinGroupKey(db.CasbinGroupTypeTeam, team.Name)
+		if team.DefaultActions != "" {
+			teamActions[team.ID] = strings.Split(team.DefaultActions, ",")
+		}
+	}
+
+	for _, member := range members {
+		groupKey, ok := teamKeys[member.TeamID]
+		if !ok {
+			continue
+		}
+		persist.LoadPolicyLine(fmt.Sprintf("g,%d,%s", member.Uid, groupKey), m)
+	}
+
+	for _, app := range apps {
+		groupKey, ok := teamKeys[app.TeamID]
+		if !ok {
+			continue
+		}
+		obj := CasbinAppObjKey(app.AppName, db.AppPermScopeAll)
+		for _, act := range teamActions[app.TeamID] {
+			persist.LoadPolicyLine(fmt.Sprintf("p,%s,%s,%s,%s", groupKey, obj, act, db.CasbinPolicyTypeApp), m)
+		}
+	}
+
+	return nil
+}
+
+// 加载审批通过且未过期的权限申请，直接授予申请人
+// (p,uid,appname:env,act,app)
+func (a *CasbinAdapter) loadPolicyAccessRequest(m casbinModel.Model) (err error) {
+	grants, err := AccessRequestGrantList()
+	if err != nil {
+		return
+	}
+
+	for _, grant := range grants {
+		obj := CasbinAppObjKey(grant.AppName, grant.Env)
+		for _, act := range strings.Split(grant.Actions, ",") {
+			persist.LoadPolicyLine(fmt.Sprintf("p,%d,%s,%s,%s", grant.Uid, obj, act, db.CasbinPolicyTypeApp), m)
+		}
+	}
+
+	return nil
+}
+
 // SavePolicy saves all policy rules to the storage.
 func (a *CasbinAdapter) SavePolicy(model casbinModel.Model) error {
 	return nil
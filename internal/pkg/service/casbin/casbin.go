@@ -102,6 +102,7 @@ func InitCasbin(adapter persist.Adapter) (err error) {
 	if err != nil {
 		return
 	}
+	e.AddFunction("appScopeMatch", AppScopeMatchFunc)
 	e.EnableEnforce(config.Enable)
 
 	if config.AutoLoad {
@@ -125,6 +126,23 @@ func (c *CasbinService) loadResourceFile(resourceFile string) {
 	}
 }
 
+// Reload 重新加载资源文件和策略，资源文件解析失败时保留原有配置
+func (c *CasbinService) Reload() error {
+	resourceContent, err := ioutil.ReadFile(cfg.Cfg.Casbin.ResourceFile)
+	if err != nil {
+		return fmt.Errorf("read resource file failed: %s", err.Error())
+	}
+
+	var tmp CasbinService
+	err = yaml.Unmarshal(resourceContent, &tmp.Resource)
+	if err != nil {
+		return fmt.Errorf("unmarshal resource file failed: %s", err.Error())
+	}
+	c.Resource = tmp.Resource
+
+	return c.LoadPolicy()
+}
+
 func (c *CasbinService) CheckPermission(sub, object, action string, policyType db.CasbinPolicyType) (ok bool, err error) {
 	if !c.enabled {
 		return true, nil
@@ -299,6 +317,21 @@ func (c *CasbinService) CheckAppPermissionKeyValid(key string) bool {
 	return false
 }
 
+// CheckMenuValid 菜单路径是否存在于资源文件中
+func (c *CasbinService) CheckMenuValid(path string) bool {
+	var findFn func(tree PermissionTree) bool
+	findFn = func(tree PermissionTree) bool {
+		for _, item := range tree {
+			if item.Path == path || findFn(item.Children) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return findFn(c.Resource.Permission)
+}
+
 func (c *CasbinService) CheckAPIValid(path, method string) bool {
 	for _, item := range c.FullAPIList() {
 		if item.Path == path && item.Method == method {
@@ -2,6 +2,9 @@ package casbin
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
 )
 
 const (
@@ -17,3 +20,37 @@ func CasbinGroupKey(gType string, name string) (key string) {
 func CasbinAppObjKey(appName, appEnv string) string {
 	return fmt.Sprintf("%s:%s", appName, appEnv)
 }
+
+// AppScopeMatch 应用权限对象匹配，policy 中的应用名或环境可以为 *，如 *:prod、appname:*
+func AppScopeMatch(obj, policyObj string) bool {
+	if !strings.Contains(policyObj, db.AppPermScopeAll) {
+		return obj == policyObj
+	}
+
+	i := strings.LastIndex(obj, ":")
+	j := strings.LastIndex(policyObj, ":")
+	if i < 0 || j < 0 {
+		return false
+	}
+
+	appName, env := obj[:i], obj[i+1:]
+	policyAppName, policyEnv := policyObj[:j], policyObj[j+1:]
+
+	return (policyAppName == db.AppPermScopeAll || policyAppName == appName) &&
+		(policyEnv == db.AppPermScopeAll || policyEnv == env)
+}
+
+// AppScopeMatchFunc casbin matcher 函数
+func AppScopeMatchFunc(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return false, fmt.Errorf("appScopeMatch: expected 2 arguments, got %d", len(args))
+	}
+
+	obj, ok1 := args[0].(string)
+	policyObj, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return false, fmt.Errorf("appScopeMatch: arguments must be string")
+	}
+
+	return AppScopeMatch(obj, policyObj), nil
+}
@@ -0,0 +1,26 @@
+package casbin
+
+import "testing"
+
+func TestAppScopeMatch(t *testing.T) {
+	tests := []struct {
+		obj       string
+		policyObj string
+		want      bool
+	}{
+		{"juno-admin:prod", "juno-admin:prod", true},
+		{"juno-admin:prod", "juno-admin:dev", false},
+		{"juno-admin:prod", "*:prod", true},
+		{"juno-admin:dev", "*:prod", false},
+		{"juno-admin:prod", "juno-admin:*", true},
+		{"juno-agent:prod", "juno-admin:*", false},
+		{"juno-admin:prod", "*:*", true},
+		{"juno-admin", "*:*", false},
+	}
+
+	for _, tt := range tests {
+		if got := AppScopeMatch(tt.obj, tt.policyObj); got != tt.want {
+			t.Errorf("AppScopeMatch(%q, %q) = %v, want %v", tt.obj, tt.policyObj, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package casbin
 
 import (
+	"time"
+
 	"github.com/douyu/juno/internal/pkg/invoker"
 	"github.com/douyu/juno/pkg/model/db"
 )
@@ -21,6 +23,26 @@ func PolicyGroupList() (list []db.CasbinPolicyGroup, err error) {
 	return
 }
 
+func TeamList() (list []db.Team, err error) {
+	err = invoker.JunoMysql.Find(&list).Error
+	return
+}
+
+func TeamMemberList() (list []db.TeamMember, err error) {
+	err = invoker.JunoMysql.Find(&list).Error
+	return
+}
+
+func TeamAppList() (list []db.AppInfo, err error) {
+	err = invoker.JunoMysql.Select("app_name, team_id").Where("team_id > 0").Find(&list).Error
+	return
+}
+
+func AccessRequestGrantList() (list []db.AccessRequest, err error) {
+	err = invoker.JunoMysql.Where("status = ? and expires_at > ?", db.AccessRequestStatusApproved, time.Now()).Find(&list).Error
+	return
+}
+
 // 110
 func genPolicyType(sub int, obj int, act int) int {
 	return sub & obj & act
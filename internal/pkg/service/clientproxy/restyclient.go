@@ -6,6 +6,7 @@ import (
 
 	"github.com/douyu/juno/pkg/constx"
 	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/go-resty/resty/v2"
 )
 
@@ -25,6 +26,7 @@ func newRestyClient(mode string, proxyAddr string) (obj *restyClient) {
 	obj.conn = resty.New().SetDebug(true).
 		SetTimeout(ClientDefaultTimeout*time.Second).
 		SetHeader("Content-Type", "application/json;charset=utf-8")
+	tracing.WrapResty(obj.conn)
 	if mode == constx.ModeMultiple {
 		obj.conn.SetHostURL(proxyAddr)
 	}
@@ -38,6 +40,9 @@ func (r *restyClient) Get(req view.ReqHTTPProxy) (*resty.Response, error) {
 		timeout = ClientDefaultTimeout
 	}
 	request := r.conn.SetTimeout(time.Duration(timeout) * time.Second).R()
+	if req.Context != nil {
+		request.SetContext(req.Context)
+	}
 	if r.mode == constx.ModeMultiple {
 		req.Type = "GET"
 		r.conn.Debug = true
@@ -57,6 +62,9 @@ func (r *restyClient) Post(req view.ReqHTTPProxy) (*resty.Response, error) {
 		timeout = ClientDefaultTimeout
 	}
 	request := r.conn.SetTimeout(time.Duration(timeout) * time.Second).R()
+	if req.Context != nil {
+		request.SetContext(req.Context)
+	}
 	if r.mode == constx.ModeMultiple {
 		req.Type = "POST"
 		r.conn.Debug = true
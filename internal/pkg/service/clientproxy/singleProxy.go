@@ -66,6 +66,9 @@ func (c *simpleProxy) initProxyHTTP() {
 }
 
 func (c *simpleProxy) DefaultEtcd(uniqZone view.UniqZone) *clientv3.Client {
+	if c.defaultEtcd == nil {
+		return nil
+	}
 	return c.defaultEtcd.conn
 }
 
@@ -0,0 +1,457 @@
+package cmdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// CMDB 从外部 CMDB 同步可用区、主机、应用
+	CMDB *cmdb
+
+	ErrSyncRunning = fmt.Errorf("正在同步中，请稍后再试")
+
+	// syncUser 定时同步时的操作人
+	syncUser = &db.User{Username: "cmdb", Nickname: "CMDB 同步"}
+)
+
+type (
+	Option struct {
+		DB   *gorm.DB
+		Conf cfg.CMDB
+	}
+
+	cmdb struct {
+		db   *gorm.DB
+		conf cfg.CMDB
+
+		syncing int32
+
+		mu      sync.RWMutex
+		results map[string]view.CmdbSyncResult
+	}
+)
+
+func Init(o Option) {
+	CMDB = &cmdb{
+		db:      o.DB,
+		conf:    o.Conf,
+		results: make(map[string]view.CmdbSyncResult),
+	}
+}
+
+// Sources 配置的数据源及最近一次同步结果
+func (c *cmdb) Sources() []view.CmdbSource {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	list := make([]view.CmdbSource, 0, len(c.conf.Sources))
+	for _, source := range c.conf.Sources {
+		item := view.CmdbSource{
+			Name:   source.Name,
+			Driver: source.Driver,
+			URL:    source.URL,
+			Kinds:  sourceKinds(source),
+		}
+		if result, ok := c.results[source.Name]; ok {
+			item.LastSync = &result
+		}
+		list = append(list, item)
+	}
+	return list
+}
+
+// Sync 同步数据源，name 为空时同步全部数据源。同一时间只允许一次同步
+func (c *cmdb) Sync(name string, u *db.User) (results []view.CmdbSyncResult, err error) {
+	if !c.conf.Enable {
+		return nil, fmt.Errorf("未开启 CMDB 同步 cmdb.enable")
+	}
+	if !atomic.CompareAndSwapInt32(&c.syncing, 0, 1) {
+		return nil, ErrSyncRunning
+	}
+	defer atomic.StoreInt32(&c.syncing, 0)
+
+	if u == nil {
+		u = syncUser
+	}
+
+	results = make([]view.CmdbSyncResult, 0)
+	for _, source := range c.conf.Sources {
+		if name != "" && source.Name != name {
+			continue
+		}
+		result := c.syncSource(source, u)
+		c.mu.Lock()
+		c.results[source.Name] = result
+		c.mu.Unlock()
+		results = append(results, result)
+	}
+	if name != "" && len(results) == 0 {
+		return nil, fmt.Errorf("数据源 %s 不存在", name)
+	}
+	return results, nil
+}
+
+// SyncTick 定时同步
+func (c *cmdb) SyncTick() error {
+	_, err := c.Sync("", nil)
+	if err != nil && err != ErrSyncRunning {
+		xlog.Error("cmdb.SyncTick failed", xlog.String("err", err.Error()))
+	}
+	return nil
+}
+
+// ConflictList 冲突列表
+func (c *cmdb) ConflictList(param view.ReqListCmdbConflict) (list []view.CmdbConflict, page *view.Pagination, err error) {
+	var rows []db.CmdbConflict
+
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := c.db.Model(&db.CmdbConflict{})
+	if param.Source != "" {
+		query = query.Where("source = ?", param.Source)
+	}
+	if param.Kind != "" {
+		query = query.Where("kind = ?", param.Kind)
+	}
+	if param.Status != "" {
+		query = query.Where("status = ?", param.Status)
+	}
+
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&rows).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.CmdbConflict, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, transformConflict(row))
+	}
+	return
+}
+
+// ResolveConflict 处理冲突。accept 使用 CMDB 的数据覆盖本地，CMDB 中已删除的记录会删除本地数据；
+// keep 保留本地数据，CMDB 再次变更前不再报告该冲突，CMDB 中已删除的记录不再跟踪
+func (c *cmdb) ResolveConflict(param view.ReqResolveCmdbConflict, u *db.User) (err error) {
+	var row db.CmdbConflict
+	err = c.db.Where("id = ? and status = ?", param.ID, db.CmdbConflictStatusOpen).First(&row).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = fmt.Errorf("冲突不存在或已处理")
+		}
+		return
+	}
+
+	var remote Fields
+	if row.Remote != "" {
+		if err = json.Unmarshal([]byte(row.Remote), &remote); err != nil {
+			return
+		}
+	}
+
+	status := db.CmdbConflictStatusResolved
+	switch {
+	case param.Action == "accept" && row.Op == OpRemoved:
+		err = c.remove(row.Kind, row.Key, u)
+		if err == nil {
+			err = c.deleteState(row.Source, row.Kind, row.Key)
+		}
+	case param.Action == "accept":
+		err = c.apply(row.Kind, row.Key, remote, u)
+		if err == nil {
+			err = c.saveState(row.Source, row.Kind, row.Key, State{Synced: remote})
+		}
+	case row.Op == OpRemoved:
+		status = db.CmdbConflictStatusIgnored
+		err = c.deleteState(row.Source, row.Kind, row.Key)
+	default:
+		status = db.CmdbConflictStatusIgnored
+		var state State
+		state, err = c.state(row.Source, row.Kind, row.Key)
+		if err == nil {
+			state.Ignored = remote
+			err = c.saveState(row.Source, row.Kind, row.Key, state)
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	return c.db.Model(&row).Updates(map[string]interface{}{
+		"status": status,
+		"uid":    u.Uid,
+	}).Error
+}
+
+// syncSource 按 Kinds 的顺序同步一个数据源
+func (c *cmdb) syncSource(source cfg.CMDBSource, u *db.User) (result view.CmdbSyncResult) {
+	result = view.CmdbSyncResult{
+		Source:    source.Name,
+		StartedAt: time.Now(),
+		Errors:    make([]string, 0),
+	}
+	defer func() {
+		result.FinishedAt = time.Now()
+		xlog.Info("cmdb.Sync finished",
+			xlog.String("source", source.Name),
+			xlog.Int("created", result.Created),
+			xlog.Int("updated", result.Updated),
+			xlog.Int("conflicts", result.Conflicts),
+			xlog.Int("errors", len(result.Errors)),
+		)
+	}()
+
+	driver, err := NewDriver(source)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return
+	}
+
+	kinds := sourceKinds(source)
+	for _, kind := range Kinds {
+		if !containsString(kinds, kind) {
+			continue
+		}
+		err = c.syncKind(source.Name, kind, driver, u, &result)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", kind, err.Error()))
+		}
+	}
+	return
+}
+
+func (c *cmdb) syncKind(source, kind string, driver Driver, u *db.User, result *view.CmdbSyncResult) (err error) {
+	remote, err := remoteFields(kind, driver)
+	if err != nil {
+		return
+	}
+	local, err := c.localFields(kind)
+	if err != nil {
+		return
+	}
+	states, err := c.states(source, kind)
+	if err != nil {
+		return
+	}
+
+	conflicts := make([]Action, 0)
+	for _, action := range Plan(kind, remote, local, states) {
+		switch action.Op {
+		case OpCreate, OpUpdate:
+			err = c.apply(kind, action.Key, action.Remote, u)
+			if err == nil {
+				err = c.saveState(source, kind, action.Key, State{Synced: action.Remote})
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s %s: %s", kind, action.Key, err.Error()))
+				continue
+			}
+			if action.Op == OpCreate {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		case OpNone:
+			// 与 CMDB 一致的记录开始跟踪，之后本地修改过时才能识别为冲突
+			state := states[action.Key]
+			if !action.Remote.Equal(state.Synced) {
+				state.Synced = action.Remote
+				err = c.saveState(source, kind, action.Key, state)
+				if err != nil {
+					return
+				}
+			}
+		case OpConflict, OpRemoved:
+			conflicts = append(conflicts, action)
+		}
+	}
+
+	err = c.reportConflicts(source, kind, conflicts)
+	if err != nil {
+		return
+	}
+	result.Conflicts += len(conflicts)
+	return nil
+}
+
+// reportConflicts 记录本次同步的冲突，之前未处理但本次不再冲突的自动标记为已处理
+func (c *cmdb) reportConflicts(source, kind string, conflicts []Action) (err error) {
+	var open []db.CmdbConflict
+	err = c.db.Where("source = ? and kind = ? and status = ?", source, kind, db.CmdbConflictStatusOpen).Find(&open).Error
+	if err != nil {
+		return
+	}
+	openByKey := make(map[string]db.CmdbConflict, len(open))
+	for _, row := range open {
+		openByKey[row.Key] = row
+	}
+
+	for _, action := range conflicts {
+		row := openByKey[action.Key]
+		delete(openByKey, action.Key)
+
+		row.Source = source
+		row.Kind = kind
+		row.Key = action.Key
+		row.Op = action.Op
+		row.Remote = marshalFields(action.Remote)
+		row.Local = marshalFields(action.Local)
+		diffs, _ := json.Marshal(action.Diffs)
+		row.Diffs = string(diffs)
+		row.Status = db.CmdbConflictStatusOpen
+		err = c.db.Save(&row).Error
+		if err != nil {
+			return
+		}
+	}
+
+	for _, row := range openByKey {
+		err = c.db.Model(&row).Update("status", db.CmdbConflictStatusResolved).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func remoteFields(kind string, driver Driver) (remote map[string]Fields, err error) {
+	remote = make(map[string]Fields)
+	switch kind {
+	case KindZone:
+		var zones []Zone
+		zones, err = driver.Zones()
+		for _, item := range zones {
+			remote[item.Key()] = item.Fields()
+		}
+	case KindHost:
+		var hosts []Host
+		hosts, err = driver.Hosts()
+		for _, item := range hosts {
+			remote[item.Key()] = item.Fields()
+		}
+	case KindApp:
+		var apps []App
+		apps, err = driver.Apps()
+		for _, item := range apps {
+			remote[item.Key()] = item.Fields()
+		}
+	default:
+		err = fmt.Errorf("不支持的数据类型 %s", kind)
+	}
+	return
+}
+
+func (c *cmdb) states(source, kind string) (states map[string]State, err error) {
+	var rows []db.CmdbSyncRecord
+	err = c.db.Where("source = ? and kind = ?", source, kind).Find(&rows).Error
+	if err != nil {
+		return
+	}
+
+	states = make(map[string]State, len(rows))
+	for _, row := range rows {
+		states[row.Key] = transformState(row)
+	}
+	return
+}
+
+func (c *cmdb) state(source, kind, key string) (state State, err error) {
+	var row db.CmdbSyncRecord
+	err = c.db.Where("source = ? and kind = ? and record_key = ?", source, kind, key).First(&row).Error
+	if err != nil {
+		return state, ignoreNotFound(err)
+	}
+	return transformState(row), nil
+}
+
+func (c *cmdb) saveState(source, kind, key string, state State) (err error) {
+	var row db.CmdbSyncRecord
+	err = c.db.Where("source = ? and kind = ? and record_key = ?", source, kind, key).First(&row).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	row.Source = source
+	row.Kind = kind
+	row.Key = key
+	row.Synced = marshalFields(state.Synced)
+	row.Ignored = marshalFields(state.Ignored)
+	return c.db.Save(&row).Error
+}
+
+func (c *cmdb) deleteState(source, kind, key string) error {
+	return c.db.Unscoped().Where("source = ? and kind = ? and record_key = ?", source, kind, key).Delete(&db.CmdbSyncRecord{}).Error
+}
+
+func transformState(row db.CmdbSyncRecord) State {
+	return State{
+		Synced:  unmarshalFields(row.Synced),
+		Ignored: unmarshalFields(row.Ignored),
+	}
+}
+
+func transformConflict(row db.CmdbConflict) view.CmdbConflict {
+	diffs := make([]view.CmdbDiff, 0)
+	_ = json.Unmarshal([]byte(row.Diffs), &diffs)
+	return view.CmdbConflict{
+		ID:        row.ID,
+		Source:    row.Source,
+		Kind:      row.Kind,
+		Key:       row.Key,
+		Op:        row.Op,
+		Remote:    unmarshalFields(row.Remote),
+		Local:     unmarshalFields(row.Local),
+		Diffs:     diffs,
+		Status:    row.Status,
+		Uid:       row.Uid,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+func marshalFields(f Fields) string {
+	if f == nil {
+		return ""
+	}
+	data, _ := json.Marshal(f)
+	return string(data)
+}
+
+func unmarshalFields(s string) Fields {
+	if s == "" {
+		return nil
+	}
+	var f Fields
+	if json.Unmarshal([]byte(s), &f) != nil {
+		return nil
+	}
+	return f
+}
+
+func sourceKinds(source cfg.CMDBSource) []string {
+	if len(source.Kinds) == 0 {
+		return Kinds
+	}
+	return source.Kinds
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
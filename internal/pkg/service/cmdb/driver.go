@@ -0,0 +1,138 @@
+package cmdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+// 同步的数据类型
+const (
+	KindZone = "zone"
+	KindHost = "host"
+	KindApp  = "app"
+)
+
+// Kinds 支持同步的数据类型，按同步顺序排列：主机依赖可用区
+var Kinds = []string{KindZone, KindHost, KindApp}
+
+type (
+	// Driver 从外部 CMDB 读取数据，接入新的 CMDB 时实现该接口并通过 Register 注册
+	Driver interface {
+		Zones() ([]Zone, error)
+		Hosts() ([]Host, error)
+		Apps() ([]App, error)
+	}
+
+	// Factory 根据数据源配置创建驱动
+	Factory func(source cfg.CMDBSource) (Driver, error)
+
+	// Zone 环境、可用区
+	Zone struct {
+		Env        string `json:"env"`
+		RegionCode string `json:"region_code"`
+		RegionName string `json:"region_name"`
+		ZoneCode   string `json:"zone_code"`
+		ZoneName   string `json:"zone_name"`
+	}
+
+	// Host 主机
+	Host struct {
+		HostName   string `json:"host_name"`
+		IP         string `json:"ip"`
+		Env        string `json:"env"`
+		RegionCode string `json:"region_code"`
+		RegionName string `json:"region_name"`
+		ZoneCode   string `json:"zone_code"`
+		ZoneName   string `json:"zone_name"`
+	}
+
+	// App 应用
+	App struct {
+		AppName   string   `json:"app_name"`
+		Name      string   `json:"name"`
+		BizDomain string   `json:"biz_domain"`
+		Lang      string   `json:"lang"`
+		GitURL    string   `json:"git_url"`
+		WebURL    string   `json:"web_url"`
+		Owners    []string `json:"owners"`
+	}
+)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Factory)
+)
+
+// Register 注册驱动，重复注册会覆盖
+func Register(name string, factory Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[name] = factory
+}
+
+// NewDriver 根据数据源配置的驱动名创建驱动
+func NewDriver(source cfg.CMDBSource) (Driver, error) {
+	driversMu.RLock()
+	factory, ok := drivers[source.Driver]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未注册的 CMDB 驱动 %s", source.Driver)
+	}
+	return factory(source)
+}
+
+// Key 可用区按环境和可用区编码区分
+func (z Zone) Key() string {
+	return z.Env + "|" + z.ZoneCode
+}
+
+func (z Zone) Fields() Fields {
+	return Fields{
+		"env":         z.Env,
+		"region_code": z.RegionCode,
+		"region_name": z.RegionName,
+		"zone_code":   z.ZoneCode,
+		"zone_name":   z.ZoneName,
+	}
+}
+
+// Key 主机按主机名区分
+func (h Host) Key() string {
+	return h.HostName
+}
+
+func (h Host) Fields() Fields {
+	return Fields{
+		"host_name":   h.HostName,
+		"ip":          h.IP,
+		"env":         h.Env,
+		"region_code": h.RegionCode,
+		"region_name": h.RegionName,
+		"zone_code":   h.ZoneCode,
+		"zone_name":   h.ZoneName,
+	}
+}
+
+// Key 应用按应用名区分
+func (a App) Key() string {
+	return a.AppName
+}
+
+// Fields 负责人排序后以逗号连接，CMDB 返回的顺序不同不算变更
+func (a App) Fields() Fields {
+	owners := append([]string(nil), a.Owners...)
+	sort.Strings(owners)
+	return Fields{
+		"app_name":   a.AppName,
+		"name":       a.Name,
+		"biz_domain": a.BizDomain,
+		"lang":       a.Lang,
+		"git_url":    a.GitURL,
+		"web_url":    a.WebURL,
+		"owners":     strings.Join(owners, ","),
+	}
+}
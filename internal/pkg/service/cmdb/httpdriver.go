@@ -0,0 +1,61 @@
+package cmdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/go-resty/resty/v2"
+)
+
+// DriverHTTP 内置驱动，从 {url}/zones、{url}/hosts、{url}/apps 获取 JSON 数组，配置了 token 时使用 Bearer 认证
+const DriverHTTP = "http"
+
+type httpDriver struct {
+	client *resty.Client
+}
+
+func init() {
+	Register(DriverHTTP, newHTTPDriver)
+}
+
+func newHTTPDriver(source cfg.CMDBSource) (Driver, error) {
+	if source.URL == "" {
+		return nil, fmt.Errorf("数据源 %s 未配置 url", source.Name)
+	}
+
+	client := resty.New().SetHostURL(strings.TrimSuffix(source.URL, "/")).SetTimeout(10 * time.Second)
+	if source.Token != "" {
+		client.SetAuthToken(source.Token)
+	}
+	return &httpDriver{client: client}, nil
+}
+
+func (d *httpDriver) Zones() (list []Zone, err error) {
+	err = d.get("/zones", &list)
+	return
+}
+
+func (d *httpDriver) Hosts() (list []Host, err error) {
+	err = d.get("/hosts", &list)
+	return
+}
+
+func (d *httpDriver) Apps() (list []App, err error) {
+	err = d.get("/apps", &list)
+	return
+}
+
+func (d *httpDriver) get(path string, out interface{}) error {
+	resp, err := d.client.R().SetHeader("Accept", "application/json").Get(path)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("cmdb %s %s", path, resp.Status())
+	}
+	return json.Unmarshal(resp.Body(), out)
+}
@@ -0,0 +1,200 @@
+package cmdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/jinzhu/gorm"
+)
+
+// localFields 本地数据，key 与 CMDB 记录的 Key 一致
+func (c *cmdb) localFields(kind string) (local map[string]Fields, err error) {
+	local = make(map[string]Fields)
+	switch kind {
+	case KindZone:
+		var zones []db.Zone
+		err = c.db.Find(&zones).Error
+		for _, item := range zones {
+			zone := zoneFromDB(item)
+			local[zone.Key()] = zone.Fields()
+		}
+	case KindHost:
+		var nodes []db.Node
+		err = c.db.Find(&nodes).Error
+		for _, item := range nodes {
+			host := hostFromDB(item)
+			local[host.Key()] = host.Fields()
+		}
+	case KindApp:
+		var apps []db.AppInfo
+		err = c.db.Find(&apps).Error
+		for _, item := range apps {
+			app := appFromDB(item)
+			local[app.Key()] = app.Fields()
+		}
+	default:
+		err = fmt.Errorf("不支持的数据类型 %s", kind)
+	}
+	return
+}
+
+// apply 将 CMDB 的值写入本地，不存在时创建
+func (c *cmdb) apply(kind, key string, fields Fields, u *db.User) (err error) {
+	switch kind {
+	case KindZone:
+		item := db.Zone{
+			Env:        fields["env"],
+			RegionCode: fields["region_code"],
+			RegionName: fields["region_name"],
+			ZoneCode:   fields["zone_code"],
+			ZoneName:   fields["zone_name"],
+		}
+		var info db.Zone
+		info, err = c.findZone(key)
+		if err != nil {
+			return
+		}
+		if info.Id == 0 {
+			return resource.Resource.CreateZone(item, u)
+		}
+		item.Id = info.Id
+		item.UpdatedBy = u.Uid
+		return resource.Resource.UpdateZone(item, u)
+	case KindHost:
+		item := db.Node{
+			HostName:   fields["host_name"],
+			Ip:         fields["ip"],
+			Env:        fields["env"],
+			RegionCode: fields["region_code"],
+			RegionName: fields["region_name"],
+			ZoneCode:   fields["zone_code"],
+			ZoneName:   fields["zone_name"],
+		}
+		var info db.Node
+		err = c.db.Where("host_name = ?", key).First(&info).Error
+		if gorm.IsRecordNotFoundError(err) {
+			item.NodeType = 1
+			return resource.Resource.CreateNode(c.db, item, u)
+		}
+		if err != nil {
+			return
+		}
+		item.Id = info.Id
+		return resource.Resource.UpdateNode(item, u)
+	case KindApp:
+		item := db.AppInfo{
+			AppName:   fields["app_name"],
+			Name:      fields["name"],
+			BizDomain: fields["biz_domain"],
+			Lang:      fields["lang"],
+			GitURL:    fields["git_url"],
+			WebURL:    fields["web_url"],
+			Users:     splitList(fields["owners"]),
+		}
+		var info db.AppInfo
+		err = c.db.Where("app_name = ?", key).First(&info).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return resource.Resource.CreateApp(item, u)
+		}
+		if err != nil {
+			return
+		}
+		item.Aid = info.Aid
+		item.UpdatedBy = u.Uid
+		return resource.Resource.UpdateApp(item, u)
+	}
+	return fmt.Errorf("不支持的数据类型 %s", kind)
+}
+
+// remove 删除 CMDB 中已删除的本地记录，仍有关联数据时返回 resource 的错误
+func (c *cmdb) remove(kind, key string, u *db.User) (err error) {
+	switch kind {
+	case KindZone:
+		var info db.Zone
+		info, err = c.findZone(key)
+		if err != nil || info.Id == 0 {
+			return
+		}
+		return resource.Resource.DeleteZone(info, u)
+	case KindHost:
+		var info db.Node
+		err = c.db.Where("host_name = ?", key).First(&info).Error
+		if err != nil {
+			return ignoreNotFound(err)
+		}
+		return resource.Resource.DeleteNode(info, u)
+	case KindApp:
+		var info db.AppInfo
+		err = c.db.Where("app_name = ?", key).First(&info).Error
+		if err != nil {
+			return ignoreNotFound(err)
+		}
+		return resource.Resource.DeleteApp(info, u)
+	}
+	return fmt.Errorf("不支持的数据类型 %s", kind)
+}
+
+func (c *cmdb) findZone(key string) (info db.Zone, err error) {
+	parts := strings.SplitN(key, "|", 2)
+	if len(parts) != 2 {
+		return info, fmt.Errorf("无效的可用区 %s", key)
+	}
+	err = c.db.Where("env = ? and zone_code = ?", parts[0], parts[1]).First(&info).Error
+	return info, ignoreNotFound(err)
+}
+
+func zoneFromDB(item db.Zone) Zone {
+	return Zone{
+		Env:        item.Env,
+		RegionCode: item.RegionCode,
+		RegionName: item.RegionName,
+		ZoneCode:   item.ZoneCode,
+		ZoneName:   item.ZoneName,
+	}
+}
+
+func hostFromDB(item db.Node) Host {
+	return Host{
+		HostName:   item.HostName,
+		IP:         item.Ip,
+		Env:        item.Env,
+		RegionCode: item.RegionCode,
+		RegionName: item.RegionName,
+		ZoneCode:   item.ZoneCode,
+		ZoneName:   item.ZoneName,
+	}
+}
+
+func appFromDB(item db.AppInfo) App {
+	return App{
+		AppName:   item.AppName,
+		Name:      item.Name,
+		BizDomain: item.BizDomain,
+		Lang:      item.Lang,
+		GitURL:    item.GitURL,
+		WebURL:    item.WebURL,
+		Owners:    item.Users,
+	}
+}
+
+func ignoreNotFound(err error) error {
+	if gorm.IsRecordNotFoundError(err) {
+		return nil
+	}
+	return err
+}
+
+func splitList(s string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
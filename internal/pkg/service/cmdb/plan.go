@@ -0,0 +1,151 @@
+package cmdb
+
+import (
+	"sort"
+)
+
+// 同步动作
+const (
+	OpCreate   = "create"   // 本地不存在，创建
+	OpUpdate   = "update"   // 本地未被修改过，使用 CMDB 的值更新
+	OpConflict = "conflict" // 本地被修改过或被删除，需要人工处理
+	OpRemoved  = "removed"  // CMDB 中已删除，本地仍存在，需要人工处理
+	OpNone     = "none"     // 与 CMDB 一致
+	OpIgnored  = "ignored"  // 已选择保留本地数据，CMDB 未再变更
+)
+
+type (
+	// Fields 参与同步的字段
+	Fields map[string]string
+
+	// State 记录最近一次同步的值和选择保留本地数据时 CMDB 的值
+	State struct {
+		Synced  Fields
+		Ignored Fields
+	}
+
+	// Diff 字段差异
+	Diff struct {
+		Field  string `json:"field"`
+		Remote string `json:"remote"`
+		Local  string `json:"local"`
+	}
+
+	// Action 一条记录的同步动作
+	Action struct {
+		Kind   string
+		Key    string
+		Op     string
+		Remote Fields
+		Local  Fields
+		Diffs  []Diff
+	}
+)
+
+// Plan 对比 CMDB 与本地数据生成同步动作，按 key 排序。
+// 本地数据与最近一次同步的值一致时才会自动更新，否则生成冲突；CMDB 中删除的记录只报告不删除
+func Plan(kind string, remote, local map[string]Fields, states map[string]State) []Action {
+	actions := make([]Action, 0, len(remote))
+	for _, key := range sortedKeys(remote) {
+		r := remote[key]
+		l, exists := local[key]
+		state := states[key]
+		action := Action{Kind: kind, Key: key, Remote: r, Local: l}
+
+		switch {
+		case state.Ignored != nil && r.Equal(state.Ignored):
+			action.Op = OpIgnored
+		case !exists && state.Synced == nil:
+			action.Op = OpCreate
+		case !exists:
+			// 同步过的记录在本地被删除
+			action.Op = OpConflict
+			action.Diffs = r.Diff(nil)
+		default:
+			action.Diffs = r.Diff(l)
+			if len(action.Diffs) == 0 {
+				action.Op = OpNone
+			} else if state.Synced != nil && l.Pick(state.Synced).Equal(state.Synced) {
+				action.Op = OpUpdate
+			} else {
+				action.Op = OpConflict
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	for _, key := range sortedStates(states) {
+		if _, ok := remote[key]; ok || states[key].Synced == nil {
+			continue
+		}
+		l, exists := local[key]
+		if !exists {
+			continue
+		}
+		actions = append(actions, Action{Kind: kind, Key: key, Op: OpRemoved, Local: l})
+	}
+	return actions
+}
+
+// Equal 字段值完全一致
+func (f Fields) Equal(other Fields) bool {
+	if len(f) != len(other) {
+		return false
+	}
+	for name, value := range f {
+		v, ok := other[name]
+		if !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff 与 local 不一致的字段，只对比 f 中的字段
+func (f Fields) Diff(local Fields) []Diff {
+	diffs := make([]Diff, 0)
+	for _, name := range sortedFieldNames(f) {
+		if local[name] != f[name] {
+			diffs = append(diffs, Diff{Field: name, Remote: f[name], Local: local[name]})
+		}
+	}
+	return diffs
+}
+
+// Pick 只保留 ref 中存在的字段
+func (f Fields) Pick(ref Fields) Fields {
+	picked := make(Fields, len(ref))
+	for name := range ref {
+		if value, ok := f[name]; ok {
+			picked[name] = value
+		}
+	}
+	return picked
+}
+
+func sortedKeys(m map[string]Fields) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedStates(m map[string]State) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFieldNames(f Fields) []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,70 @@
+package cmdb
+
+import (
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	remote := map[string]Fields{
+		"new":       {"ip": "10.0.0.1"},
+		"same":      {"ip": "10.0.0.2"},
+		"untouched": {"ip": "10.0.0.30"},
+		"modified":  {"ip": "10.0.0.40"},
+		"ignored":   {"ip": "10.0.0.50"},
+		"deleted":   {"ip": "10.0.0.6"},
+		"unknown":   {"ip": "10.0.0.70"},
+	}
+	local := map[string]Fields{
+		"same":      {"ip": "10.0.0.2", "extra": "x"},
+		"untouched": {"ip": "10.0.0.3"},
+		"modified":  {"ip": "10.0.0.41"},
+		"ignored":   {"ip": "10.0.0.51"},
+		"unknown":   {"ip": "10.0.0.7"},
+		"removed":   {"ip": "10.0.0.8"},
+	}
+	states := map[string]State{
+		"untouched": {Synced: Fields{"ip": "10.0.0.3"}},
+		"modified":  {Synced: Fields{"ip": "10.0.0.4"}},
+		"ignored":   {Synced: Fields{"ip": "10.0.0.5"}, Ignored: Fields{"ip": "10.0.0.50"}},
+		"deleted":   {Synced: Fields{"ip": "10.0.0.6"}},
+		"removed":   {Synced: Fields{"ip": "10.0.0.8"}},
+		"gone":      {Synced: Fields{"ip": "10.0.0.9"}},
+	}
+
+	want := map[string]string{
+		"new":       OpCreate,
+		"same":      OpNone,
+		"untouched": OpUpdate,
+		"modified":  OpConflict,
+		"ignored":   OpIgnored,
+		"deleted":   OpConflict,
+		"unknown":   OpConflict,
+		"removed":   OpRemoved,
+	}
+
+	actions := Plan(KindHost, remote, local, states)
+	if len(actions) != len(want) {
+		t.Fatalf("actions = %+v", actions)
+	}
+	for _, action := range actions {
+		if action.Op != want[action.Key] {
+			t.Errorf("%s: op = %s, want %s", action.Key, action.Op, want[action.Key])
+		}
+	}
+
+	modified := actions[2]
+	if modified.Key != "modified" || len(modified.Diffs) != 1 || modified.Diffs[0] != (Diff{Field: "ip", Remote: "10.0.0.40", Local: "10.0.0.41"}) {
+		t.Errorf("modified = %+v", modified)
+	}
+}
+
+func TestAppFields(t *testing.T) {
+	a := App{AppName: "juno", Owners: []string{"b", "a"}}
+	b := App{AppName: "juno", Owners: []string{"a", "b"}}
+	if !a.Fields().Equal(b.Fields()) || a.Fields()["owners"] != "a,b" {
+		t.Errorf("fields = %v, %v", a.Fields(), b.Fields())
+	}
+	if a.Owners[0] != "b" {
+		t.Errorf("owners should not be sorted in place")
+	}
+}
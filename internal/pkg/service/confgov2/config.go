@@ -6,27 +6,37 @@ import (
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
 	"github.com/douyu/juno/internal/pkg/service/agent"
 	"github.com/douyu/juno/internal/pkg/service/appevent"
 	"github.com/douyu/juno/internal/pkg/service/clientproxy"
 	"github.com/douyu/juno/internal/pkg/service/configresource"
+	"github.com/douyu/juno/internal/pkg/service/eventbus"
 	"github.com/douyu/juno/internal/pkg/service/openauth"
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
 	"github.com/douyu/juno/internal/pkg/service/resource"
 	"github.com/douyu/juno/internal/pkg/service/system"
+	"github.com/douyu/juno/internal/pkg/service/team"
 	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/cache"
 	"github.com/douyu/juno/pkg/cfg"
 	"github.com/douyu/juno/pkg/errorconst"
+	"github.com/douyu/juno/pkg/i18n"
 	"github.com/douyu/juno/pkg/model"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/douyu/juno/pkg/util"
 	"github.com/douyu/jupiter/pkg/xlog"
 	"github.com/go-resty/resty/v2"
 	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -37,21 +47,83 @@ const (
 	queryAgentUsedStatus = "/api/v1/conf/command_line/status"
 )
 
-func List(param view.ReqListConfig) (resp view.RespListConfig, err error) {
+// listSpec 配置文件列表允许排序、筛选的字段，未指定分页参数时返回全部
+var listSpec = listquery.Spec{
+	Sorts: map[string]string{
+		"id":           "id",
+		"name":         "name",
+		"zone":         "zone",
+		"created_time": "created_at",
+		"update_time":  "updated_at",
+		"published":    "published_at",
+	},
+	Filters: map[string]string{
+		"name":   "name",
+		"format": "format",
+		"zone":   "zone",
+	},
+	DefaultSort: "id",
+	Tiebreaker:  "id",
+}
+
+// configurationCache 配置文件缓存，修改内容、编辑锁或删除后按 id 失效
+var configurationCache = cache.New("configuration", 0)
+
+// getConfiguration 按 id 读取配置文件，优先读缓存。发布、更新等写操作仍直接读库
+func getConfiguration(id uint) (configuration db.Configuration, err error) {
+	err = configurationCache.Fetch(strconv.FormatUint(uint64(id), 10), &configuration, func() error {
+		return mysql.Where("id = ?", id).First(&configuration).Error
+	})
+	return
+}
+
+// InvalidateConfiguration 修改 configuration 表后清除缓存
+func InvalidateConfiguration(ids ...uint) {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, strconv.FormatUint(uint64(id), 10))
+	}
+	configurationCache.Invalidate(keys...)
+}
+
+// List 配置文件列表，zones 不为空时只返回这些机房以及不区分机房的配置
+func List(param view.ReqListConfig, zones ...string) (resp view.RespListConfig, page *view.Pagination, err error) {
 	var app db.AppInfo
 
 	resp = make(view.RespListConfig, 0)
 	list := make([]db.Configuration, 0)
 
+	query, err := listSpec.Parse(param.ListQuery)
+	if err != nil {
+		return
+	}
+
 	err = mysql.Where("app_name = ?", param.AppName).First(&app).Error
 	if err != nil {
-		return resp, err
+		return
 	}
 
-	err = mysql.Select("id, aid, name, format, env, zone, created_at, updated_at, published_at").
+	sql := mysql.Model(&db.Configuration{}).
 		Where("aid = ?", app.Aid).
-		Where("env = ?", param.Env).
+		Where("env = ?", param.Env)
+	if len(zones) > 0 {
+		sql = sql.Where("zone in (?) or zone = ''", zones)
+	}
+	sql = query.Where(sql)
+
+	var total int
+	err = sql.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	err = query.Paginate(sql).
+		Select("id, aid, name, format, env, zone, created_at, updated_at, published_at").
 		Find(&list).Error
+	if err != nil {
+		return
+	}
+	page = query.Pagination(total, len(list))
 
 	for _, item := range list {
 		resp = append(resp, view.RespListConfigItem{
@@ -72,8 +144,7 @@ func List(param view.ReqListConfig) (resp view.RespListConfig, err error) {
 }
 
 func Detail(param view.ReqDetailConfig) (resp view.RespDetailConfig, err error) {
-	configuration := db.Configuration{}
-	err = mysql.Where("id = ?", param.ID).First(&configuration).Error
+	configuration, err := getConfiguration(param.ID)
 	if err != nil {
 		return
 	}
@@ -370,6 +441,7 @@ func Update(c echo.Context, param view.ReqUpdateConfig) (err error) {
 		tx.Rollback()
 		return err
 	}
+	InvalidateConfiguration(configuration.ID)
 
 	return
 }
@@ -412,9 +484,7 @@ func Instances(param view.ReqConfigInstanceList) (resp view.RespConfigInstanceLi
 		instanceNotPublished = make(view.RespConfigInstanceList, 0)
 	)
 	// get configuration info
-	query := mysql.Where("id=?", param.ConfigurationID).Find(&configuration)
-	if query.Error != nil {
-		err = query.Error
+	if configuration, err = getConfiguration(param.ConfigurationID); err != nil {
 		return
 	}
 	// get app info
@@ -561,6 +631,11 @@ func assemblyJunoAgent(nodes []db.AppNode) []view.JunoAgent {
 
 // Publish ..
 func Publish(param view.ReqPublishConfig, c echo.Context) (err error) {
+	span, ctx := tracing.StartSpan(c.Request().Context(), "confgov2.Publish",
+		opentracing.Tag{Key: "config.id", Value: param.ID},
+		opentracing.Tag{Key: "config.version", Value: param.Version})
+	defer func() { tracing.Finish(span, err) }()
+
 	// Complete configuration release logic
 	u := user.GetUser(c)
 	authWithToken, token := openauth.GetAccessToken(c)
@@ -624,9 +699,12 @@ func Publish(param view.ReqPublishConfig, c echo.Context) (err error) {
 	if err != nil {
 		return
 	}
+	if !appInfo.Writable() {
+		return fmt.Errorf("应用 %s 已归档或待删除，不能发布配置", appInfo.AppName)
+	}
 
 	// Save the configuration in etcd
-	if err = publishETCD(view.ReqConfigPublish{
+	if err = publishETCD(ctx, view.ReqConfigPublish{
 		AppName:      appInfo.AppName,
 		ZoneCode:     zoneCode,
 		Port:         appInfo.GovernPort,
@@ -695,12 +773,46 @@ func Publish(param view.ReqPublishConfig, c echo.Context) (err error) {
 		"name":                     configuration.Name,
 		"format":                   configuration.Format,
 	})
+	operator := ""
 	if authWithToken {
+		operator = token.Name
 		appevent.AppEvent.OpenAPIConfigPublish(appInfo.Aid, appInfo.AppName, env, zoneCode, string(meta), token)
 	} else {
+		operator = u.Username
 		appevent.AppEvent.ConfgoFilePublishEvent(appInfo.Aid, appInfo.AppName, env, zoneCode, string(meta), u)
 	}
 
+	go watchPublishProgress(appInfo.AppName, zoneCode, publishProgress{
+		ConfigurationID: configuration.ID,
+		Env:             env,
+		Version:         version,
+	}, instanceList)
+
+	eventbus.Publish(eventbus.TopicConfigPublished, eventbus.ConfigPublished{
+		ConfigID:  configuration.ID,
+		AppName:   appInfo.AppName,
+		Env:       env,
+		ZoneCode:  zoneCode,
+		FileName:  filename,
+		Version:   version,
+		HostNames: instanceList,
+		PubK8S:    param.PubK8S,
+		Operator:  operator,
+	})
+
+	// 通知应用所属团队
+	go team.Team.Notify(notice.Event{
+		Type:     notice.EventConfig,
+		App:      appInfo.AppName,
+		Env:      env,
+		Severity: notice.SeverityInfo,
+		Localized: &notice.Localized{
+			Subject: i18n.Msg("[Juno] 应用 %s 配置 %s 已发布", appInfo.AppName, filename),
+			Content: i18n.Msg("[Juno] 应用 %s 配置 %s 已发布\n环境: %s/%s\n版本: %s\n操作人: %s",
+				appInfo.AppName, filename, env, zoneCode, version, operator),
+		},
+	})
+
 	return
 }
 
@@ -738,7 +850,13 @@ func configurationHeader(content, format, version string) string {
 	return content
 }
 
-func publishETCD(req view.ReqConfigPublish) (err error) {
+// publishETCD 写入 etcd 的元数据中带上追踪上下文，agent 监听到变更后可以继续该链路
+func publishETCD(ctx context.Context, req view.ReqConfigPublish) (err error) {
+	span, ctx := tracing.StartSpan(ctx, "confgov2.publishETCD",
+		opentracing.Tag{Key: "app.name", Value: req.AppName},
+		opentracing.Tag{Key: "env", Value: req.Env},
+		opentracing.Tag{Key: "zone.code", Value: req.ZoneCode})
+	defer func() { tracing.Finish(span, err) }()
 
 	content := configurationHeader(req.Content, req.Format, req.Version)
 
@@ -752,6 +870,7 @@ func publishETCD(req view.ReqConfigPublish) (err error) {
 			Format:    req.Format,
 			Version:   req.Version,
 			Paths:     paths,
+			Trace:     tracing.Inject(ctx),
 		},
 	}
 	var buf []byte
@@ -759,21 +878,21 @@ func publishETCD(req view.ReqConfigPublish) (err error) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	etcdCtx, cancel := context.WithTimeout(context.Background(), time.Second*3)
 	defer cancel()
 
 	for _, hostName := range req.InstanceList {
 		for _, prefix := range cfg.Cfg.Configure.Prefixes {
 			key := fmt.Sprintf("/%s/%s/%s/%s/static/%s/%s", prefix, hostName, req.AppName, req.Env, req.FileName, req.Port)
 			// The migration is complete, only write independent ETCD of the configuration center
-			_, err = clientproxy.ClientProxy.DefaultEtcdPut(view.UniqZone{Env: req.Env, Zone: req.ZoneCode}, ctx, key, string(buf))
+			_, err = clientproxy.ClientProxy.DefaultEtcdPut(view.UniqZone{Env: req.Env, Zone: req.ZoneCode}, etcdCtx, key, string(buf))
 			if err != nil {
 				return
 			}
 
 			// for k8s
 			clusterKey := fmt.Sprintf("/%s/cluster/%s/%s/static/%s", prefix, req.AppName, req.Env, req.FileName)
-			_, err = clientproxy.ClientProxy.DefaultEtcdPut(view.UniqZone{Env: req.Env, Zone: req.ZoneCode}, ctx, clusterKey, string(buf))
+			_, err = clientproxy.ClientProxy.DefaultEtcdPut(view.UniqZone{Env: req.Env, Zone: req.ZoneCode}, etcdCtx, clusterKey, string(buf))
 			if err != nil {
 				return
 			}
@@ -784,7 +903,7 @@ func publishETCD(req view.ReqConfigPublish) (err error) {
 		for _, prefix := range cfg.Cfg.Configure.Prefixes {
 			// for k8s
 			clusterKey := fmt.Sprintf("/%s/cluster/%s/%s/static/%s", prefix, req.AppName, req.Env, req.FileName)
-			_, err = clientproxy.ClientProxy.DefaultEtcdPut(view.UniqZone{Env: req.Env, Zone: req.ZoneCode}, ctx, clusterKey, string(buf))
+			_, err = clientproxy.ClientProxy.DefaultEtcdPut(view.UniqZone{Env: req.Env, Zone: req.ZoneCode}, etcdCtx, clusterKey, string(buf))
 			if err != nil {
 				return
 			}
@@ -925,7 +1044,7 @@ func Diff(configID, historyID uint) (resp view.RespDiffConfig, err error) {
 	return
 }
 
-// Delete ..
+// Delete 删除配置，删除的配置保留在回收站中，保留期内可以恢复
 func Delete(c echo.Context, id uint) (err error) {
 	var config db.Configuration
 
@@ -940,10 +1059,29 @@ func Delete(c echo.Context, id uint) (err error) {
 		return
 	}
 
-	err = mysql.Delete(&db.Configuration{}, "id = ?", id).Error
+	tx := mysql.Begin()
+	err = tx.Delete(&db.Configuration{}, "id = ?", id).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = recyclebin.Add(tx, db.RecycleItem{
+		Kind:      db.RecycleKindConfig,
+		ObjectID:  config.ID,
+		Name:      config.FileName(),
+		AppName:   config.App.AppName,
+		Env:       config.Env,
+		DeletedBy: u.Uid,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit().Error
 	if err != nil {
 		return err
 	}
+	InvalidateConfiguration(id)
 
 	metadata, _ := json.Marshal(&map[string]interface{}{
 		"id":        config.ID,
@@ -9,6 +9,7 @@ var (
 // Init ..
 func Init(d *gorm.DB) {
 	mysql = d
+	registerRecycleHandler()
 
 	go clearLockPeriodically()
 }
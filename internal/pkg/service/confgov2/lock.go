@@ -38,7 +38,11 @@ func TryLock(uid, configId uint) (err error) {
 			return errors.Wrap(err, "获取编辑锁失败")
 		}
 	}
-	return tx.Commit().Error
+	err = tx.Commit().Error
+	if err == nil {
+		InvalidateConfiguration(configId)
+	}
+	return err
 }
 
 func Unlock(uid, configId uint) (err error) {
@@ -65,7 +69,11 @@ func Unlock(uid, configId uint) (err error) {
 			return errors.Wrap(err, "释放编辑锁失败")
 		}
 	}
-	return tx.Commit().Error
+	err = tx.Commit().Error
+	if err == nil {
+		InvalidateConfiguration(configId)
+	}
+	return err
 }
 
 //clearLockPeriodically 定期清除编辑锁
@@ -91,6 +99,10 @@ func clearLockPeriodically() {
 				tx.Save(&config)
 			}
 		}
-		tx.Commit()
+		if tx.Commit().Error == nil {
+			for _, config := range configs {
+				InvalidateConfiguration(config.ID)
+			}
+		}
 	}
 }
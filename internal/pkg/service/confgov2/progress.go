@@ -0,0 +1,104 @@
+package confgov2
+
+import (
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/wsevent"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+const (
+	progressInterval = 5 * time.Second
+	progressTimeout  = 3 * time.Minute
+)
+
+// 配置发布进度阶段
+const (
+	publishStagePublished = "published" // 已写入 etcd
+	publishStageProgress  = "progress"  // 实例同步、生效中
+	publishStageDone      = "done"      // 全部实例已生效
+	publishStageTimeout   = "timeout"   // 超时仍有实例未生效
+)
+
+// publishProgress 配置发布进度，Key 为应用名
+type publishProgress struct {
+	ConfigurationID uint   `json:"configuration_id"`
+	Env             string `json:"env"`
+	Version         string `json:"version"`
+	Stage           string `json:"stage"`
+	Total           int    `json:"total"`
+	Synced          int    `json:"synced"`
+	TakeEffect      int    `json:"take_effect"`
+}
+
+func publishProgressEvent(appName, zoneCode string, progress publishProgress) {
+	wsevent.Publish(wsevent.Event{
+		Topic: wsevent.TopicConfigPublish,
+		Key:   appName,
+		Zone:  zoneCode,
+		Data:  progress,
+	})
+}
+
+// watchPublishProgress 发布后定时检查实例同步、生效状态并推送进度，直到全部生效或超时
+func watchPublishProgress(appName, zoneCode string, progress publishProgress, hostNames []string) {
+	progress.Total = len(hostNames)
+	progress.Stage = publishStagePublished
+	publishProgressEvent(appName, zoneCode, progress)
+	if len(hostNames) == 0 {
+		return
+	}
+
+	hosts := make(map[string]bool, len(hostNames))
+	for _, hostName := range hostNames {
+		hosts[hostName] = true
+	}
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	deadline := time.Now().Add(progressTimeout)
+
+	for range ticker.C {
+		instances, err := Instances(view.ReqConfigInstanceList{
+			ConfigurationID: progress.ConfigurationID,
+			Env:             progress.Env,
+			ZoneCode:        zoneCode,
+		})
+		if err != nil {
+			xlog.Error("watchPublishProgress", xlog.String("app", appName), xlog.String("err", err.Error()))
+		}
+
+		synced, takeEffect := 0, 0
+		for _, instance := range instances {
+			if !hosts[instance.HostName] {
+				continue
+			}
+			if instance.ConfigFileSynced == 1 {
+				synced++
+			}
+			if instance.ConfigFileTakeEffect == 1 {
+				takeEffect++
+			}
+		}
+
+		changed := synced != progress.Synced || takeEffect != progress.TakeEffect
+		progress.Synced, progress.TakeEffect = synced, takeEffect
+
+		switch {
+		case takeEffect >= progress.Total:
+			progress.Stage = publishStageDone
+		case time.Now().After(deadline):
+			progress.Stage = publishStageTimeout
+		case changed:
+			progress.Stage = publishStageProgress
+		default:
+			continue
+		}
+
+		publishProgressEvent(appName, zoneCode, progress)
+		if progress.Stage != publishStageProgress {
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package confgov2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/douyu/juno/internal/pkg/service/appevent"
+	"github.com/douyu/juno/internal/pkg/service/configresource"
+	"github.com/douyu/juno/pkg/model"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/util"
+)
+
+// Promote 将晋升的配置内容保存为目标配置的新版本，target.ID 为 0 时先创建目标配置。
+// 只保存不发布，目标环境仍需按发布流程单独发布
+func Promote(u *db.User, target db.Configuration, content, changeLog string) (id uint, err error) {
+	var app db.AppInfo
+	err = mysql.Where("aid = ?", target.AID).First(&app).Error
+	if err != nil {
+		return
+	}
+
+	filled := configresource.FillConfigResource(content)
+	err = CheckSyntax(model.ConfigFormat(target.Format), filled)
+	if err != nil {
+		return
+	}
+	version := util.Md5Str(filled)
+
+	history := db.ConfigurationHistory{
+		ChangeLog: changeLog,
+		Content:   content,
+		Version:   version,
+		UID:       uint(u.Uid),
+	}
+	resourceValues, err := parseConfigResourceValuesFromConfig(history)
+	if err != nil {
+		return
+	}
+
+	tx := mysql.Begin()
+	if target.ID == 0 {
+		exists := 0
+		err = tx.Model(&db.Configuration{}).
+			Where("aid = ? and env = ? and name = ? and format = ?", target.AID, target.Env, target.Name, target.Format).
+			Count(&exists).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if exists != 0 {
+			tx.Rollback()
+			return 0, fmt.Errorf("已存在同名配置")
+		}
+
+		target.UID = uint(u.Uid)
+		err = tx.Create(&target).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	} else {
+		err = tx.Where("id = ?", target.ID).First(&target).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if target.LockUid != 0 && target.LockUid != uint(u.Uid) {
+			tx.Rollback()
+			return 0, fmt.Errorf("当前有其他人正在编辑，更新失败")
+		}
+		if util.Md5Str(configresource.FillConfigResource(target.Content)) == version {
+			// 内容相同，无需保存新版本
+			tx.Rollback()
+			return target.ID, nil
+		}
+	}
+
+	history.ConfigurationID = target.ID
+	err = tx.Where("configuration_id = ? and version = ?", target.ID, version).Delete(&db.ConfigurationHistory{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Save(&history).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	for _, value := range resourceValues {
+		err = tx.Save(&db.ConfigurationResourceRelation{
+			ConfigurationHistoryID: history.ID,
+			ConfigResourceValueID:  value.ID,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+
+	target.Content = content
+	target.Version = version
+	err = tx.Save(&target).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+	InvalidateConfiguration(target.ID)
+
+	eventMetadata, _ := json.Marshal(map[string]interface{}{
+		"id":               history.ID,
+		"uid":              history.UID,
+		"configuration_id": history.ConfigurationID,
+		"change_log":       history.ChangeLog,
+		"version":          history.Version,
+		"name":             target.Name,
+		"format":           target.Format,
+	})
+	appevent.AppEvent.ConfgoFileUpdateEvent(int(target.AID), app.AppName, target.Zone, target.Env, string(eventMetadata), u)
+	return target.ID, nil
+}
@@ -0,0 +1,56 @@
+package confgov2
+
+import (
+	"fmt"
+
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/jinzhu/gorm"
+)
+
+func registerRecycleHandler() {
+	recyclebin.Register(db.RecycleKindConfig, recyclebin.Handler{
+		Restore:      restoreConfiguration,
+		Purge:        purgeConfiguration,
+		AfterRestore: func(item db.RecycleItem) { InvalidateConfiguration(item.ObjectID) },
+	})
+}
+
+// restoreConfiguration 取消配置的删除标记，应用已删除或已存在同名配置时不能恢复
+func restoreConfiguration(tx *gorm.DB, item db.RecycleItem) (err error) {
+	var config db.Configuration
+	err = tx.Unscoped().Where("id = ?", item.ObjectID).First(&config).Error
+	if err != nil {
+		return
+	}
+
+	var count int
+	err = tx.Model(&db.AppInfo{}).Where("aid = ?", config.AID).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count == 0 {
+		return fmt.Errorf("应用 %s 不存在，请先恢复应用", item.AppName)
+	}
+
+	err = tx.Model(&db.Configuration{}).
+		Where("aid = ? and env = ? and name = ? and format = ?", config.AID, config.Env, config.Name, config.Format).
+		Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		return fmt.Errorf("已存在同名配置")
+	}
+
+	return tx.Unscoped().Model(&db.Configuration{}).Where("id = ?", item.ObjectID).Update("deleted_at", nil).Error
+}
+
+// purgeConfiguration 彻底删除配置及其历史版本
+func purgeConfiguration(tx *gorm.DB, item db.RecycleItem) (err error) {
+	err = tx.Unscoped().Where("configuration_id = ?", item.ObjectID).Delete(&db.ConfigurationHistory{}).Error
+	if err != nil {
+		return
+	}
+	return tx.Unscoped().Where("id = ?", item.ObjectID).Delete(&db.Configuration{}).Error
+}
@@ -2,6 +2,7 @@ package confgov2
 
 import (
 	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/sqldialect"
 	"github.com/douyu/juno/pkg/util"
 )
 
@@ -24,7 +25,7 @@ func StatisticsCommit(start, end int64) (cmcCnt []view.CmcCnt, total int, err er
 	if err := dbConn.Count(&total).Error; err != nil {
 		return cmcCnt, total, err
 	}
-	if err := dbConn.Select("DATE_FORMAT(created_at, '%Y-%m-%d') as day_time, count(id) as cnt").
+	if err := dbConn.Select(sqldialect.Day(dbConn, "created_at")+" as day_time, count(id) as cnt").
 		Where("created_at between ? and ?", util.Timestamp2String(int(start)), util.Timestamp2String(int(end))).Group("day_time").Find(&cmcCnt).Error; err != nil {
 		return cmcCnt, total, err
 	}
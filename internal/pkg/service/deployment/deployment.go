@@ -0,0 +1,252 @@
+package deployment
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+)
+
+// Deployment 实例部署历史
+var Deployment *deployment
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	deployment struct {
+		db *gorm.DB
+		// versions 最近记录的实例版本，key 为 app_name/host_name，避免每次心跳都查询数据库
+		versions sync.Map
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	Deployment = &deployment{
+		db: o.DB,
+	}
+}
+
+// Observe agent 心跳上报的应用版本与最近一次记录不同时，记录一次部署
+func (d *deployment) Observe(appName, env, zoneCode, hostName, version string) (err error) {
+	key := appName + "/" + hostName
+	if last, ok := d.versions.Load(key); ok && last.(string) == version {
+		return nil
+	}
+
+	last, err := d.latest(appName, hostName)
+	if err != nil {
+		return
+	}
+	if last.ID == 0 || last.Version != version {
+		err = d.db.Create(&db.Deployment{
+			AppName:     appName,
+			HostName:    hostName,
+			Env:         env,
+			ZoneCode:    zoneCode,
+			Version:     version,
+			PrevVersion: last.Version,
+			Source:      db.DeploymentSourceAgent,
+			DeployedAt:  time.Now(),
+		}).Error
+		if err != nil {
+			return
+		}
+	}
+
+	d.versions.Store(key, version)
+	return nil
+}
+
+// Report CI 上报部署。实例最近一次记录的版本相同时（通常是 agent 已先上报），补充流水线信息而不重复记录
+func (d *deployment) Report(param view.ReqReportDeployment) (count int, err error) {
+	if param.PipelineTaskID != 0 {
+		var task db.TestPipelineTask
+		err = d.db.Select("id").Where("id = ?", param.PipelineTaskID).First(&task).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return 0, fmt.Errorf("流水线执行记录 %d 不存在", param.PipelineTaskID)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	nodes, err := d.instances(param)
+	if err != nil {
+		return
+	}
+
+	deployedAt := time.Now()
+	if param.DeployedAt > 0 {
+		deployedAt = time.Unix(param.DeployedAt, 0)
+	}
+
+	tx := d.db.Begin()
+	for _, node := range nodes {
+		var last db.Deployment
+		err = tx.Where("app_name = ? and host_name = ?", param.AppName, node.HostName).Order("id desc").First(&last).Error
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			tx.Rollback()
+			return
+		}
+
+		if last.ID != 0 && last.Version == param.Version {
+			err = tx.Model(&last).Updates(map[string]interface{}{
+				"commit":           param.Commit,
+				"pipeline_task_id": param.PipelineTaskID,
+				"pipeline_url":     param.PipelineURL,
+				"operator":         param.Operator,
+			}).Error
+		} else {
+			err = tx.Create(&db.Deployment{
+				AppName:        param.AppName,
+				HostName:       node.HostName,
+				Env:            node.Env,
+				ZoneCode:       node.ZoneCode,
+				Version:        param.Version,
+				PrevVersion:    last.Version,
+				Commit:         param.Commit,
+				Source:         db.DeploymentSourceAPI,
+				PipelineTaskID: param.PipelineTaskID,
+				PipelineURL:    param.PipelineURL,
+				Operator:       param.Operator,
+				DeployedAt:     deployedAt,
+			}).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	for _, node := range nodes {
+		d.versions.Store(param.AppName+"/"+node.HostName, param.Version)
+	}
+	return len(nodes), nil
+}
+
+// List 部署历史
+func (d *deployment) List(param view.ReqListDeployment) (list []db.Deployment, page *view.Pagination, err error) {
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := d.db.Model(&db.Deployment{}).Where("app_name = ?", param.AppName)
+	if param.Env != "" {
+		query = query.Where("env = ?", param.Env)
+	}
+	if param.HostName != "" {
+		query = query.Where("host_name = ?", param.HostName)
+	}
+
+	list = make([]db.Deployment, 0)
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&list).Error
+	return
+}
+
+// Latest 应用各实例最近一次部署
+func (d *deployment) Latest(param view.ReqLatestDeployment) (list []view.InstanceDeployment, err error) {
+	var nodes []db.AppNode
+	query := d.db.Where("app_name = ?", param.AppName)
+	if param.Env != "" {
+		query = query.Where("env = ?", param.Env)
+	}
+	err = query.Order("host_name").Find(&nodes).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.InstanceDeployment, 0, len(nodes))
+	if len(nodes) == 0 {
+		return
+	}
+
+	hostNames := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		hostNames = append(hostNames, node.HostName)
+	}
+
+	var ids []uint
+	err = d.db.Model(&db.Deployment{}).
+		Where("app_name = ? and host_name in (?)", param.AppName, hostNames).
+		Group("host_name").
+		Pluck("max(id)", &ids).Error
+	if err != nil {
+		return
+	}
+
+	var deployments []db.Deployment
+	if len(ids) > 0 {
+		err = d.db.Where("id in (?)", ids).Find(&deployments).Error
+		if err != nil {
+			return
+		}
+	}
+	latest := make(map[string]db.Deployment, len(deployments))
+	for _, item := range deployments {
+		latest[item.HostName] = item
+	}
+
+	for _, node := range nodes {
+		item := view.InstanceDeployment{
+			HostName: node.HostName,
+			IP:       node.IP,
+			Env:      node.Env,
+			ZoneCode: node.ZoneCode,
+		}
+		if last, ok := latest[node.HostName]; ok {
+			item.Deployment = &last
+		}
+		list = append(list, item)
+	}
+	return
+}
+
+// instances CI 上报的部署实例，只能是应用已关联的节点
+func (d *deployment) instances(param view.ReqReportDeployment) (nodes []db.AppNode, err error) {
+	query := d.db.Where("app_name = ? and env = ?", param.AppName, param.Env)
+	if param.ZoneCode != "" {
+		query = query.Where("zone_code = ?", param.ZoneCode)
+	}
+	if len(param.HostNames) > 0 {
+		query = query.Where("host_name in (?)", param.HostNames)
+	}
+	err = query.Find(&nodes).Error
+	if err != nil {
+		return
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("应用 %s 在 %s 环境没有匹配的实例", param.AppName, param.Env)
+	}
+	if len(param.HostNames) > 0 {
+		found := make(map[string]bool, len(nodes))
+		for _, node := range nodes {
+			found[node.HostName] = true
+		}
+		for _, hostName := range param.HostNames {
+			if !found[hostName] {
+				return nil, fmt.Errorf("实例 %s 不属于应用 %s 的 %s 环境", hostName, param.AppName, param.Env)
+			}
+		}
+	}
+	return
+}
+
+func (d *deployment) latest(appName, hostName string) (item db.Deployment, err error) {
+	err = d.db.Where("app_name = ? and host_name = ?", appName, hostName).Order("id desc").First(&item).Error
+	if gorm.IsRecordNotFoundError(err) {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,237 @@
+// Package eventbus 进程内事件总线。
+// 核心服务在任务结束、配置发布、应用创建等节点调用 Publish 发布事件，
+// 站点定制的自动化通过进程内插件（RegisterPlugin）或 HTTP Hook 订阅，不需要修改核心服务
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/metrics"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// 事件主题
+const (
+	TopicTaskFinished    = "task.finished"    // 测试流水线任务结束，Data 为 TaskFinished
+	TopicConfigPublished = "config.published" // 配置发布，Data 为 ConfigPublished
+	TopicAppCreated      = "app.created"      // 应用创建，Data 为 AppCreated
+	TopicWorkerScale     = "worker.scale"     // 机房的测试 worker 需要扩容或可以缩容，Data 为 WorkerScale
+
+	// TopicAll 订阅全部主题
+	TopicAll = "*"
+)
+
+const (
+	defaultWorkers        = 4
+	defaultBufferSize     = 1000
+	defaultHandlerTimeout = 30 * time.Second
+)
+
+type (
+	// Event 平台事件
+	Event struct {
+		ID    string      `json:"id"`
+		Topic string      `json:"topic"`
+		Time  time.Time   `json:"time"`
+		Data  interface{} `json:"data"`
+	}
+
+	// TaskFinished 测试流水线任务结束
+	TaskFinished struct {
+		TaskID     uint   `json:"task_id"`
+		PipelineID uint   `json:"pipeline_id"`
+		Name       string `json:"name"`
+		AppName    string `json:"app_name"`
+		Env        string `json:"env"`
+		ZoneCode   string `json:"zone_code"`
+		Branch     string `json:"branch"`
+		Status     string `json:"status"`
+	}
+
+	// ConfigPublished 配置发布，HostNames 为空表示发布到全部实例
+	ConfigPublished struct {
+		ConfigID  uint     `json:"config_id"`
+		AppName   string   `json:"app_name"`
+		Env       string   `json:"env"`
+		ZoneCode  string   `json:"zone_code"`
+		FileName  string   `json:"file_name"`
+		Version   string   `json:"version"`
+		HostNames []string `json:"host_names"`
+		PubK8S    bool     `json:"pub_k8s"`
+		Operator  string   `json:"operator"`
+	}
+
+	// AppCreated 应用创建
+	AppCreated struct {
+		Aid      int    `json:"aid"`
+		AppName  string `json:"app_name"`
+		Operator string `json:"operator"`
+	}
+
+	// WorkerScale 测试 worker 扩缩容建议，只在机房的状态变化时发布一次。
+	// 缩容前应先调用 drain 接口，等待 worker 空闲后再下线
+	WorkerScale struct {
+		Action         string  `json:"action"` // scale_up, scale_down
+		ZoneCode       string  `json:"zone_code"`
+		Reason         string  `json:"reason"`
+		QueueDepth     int     `json:"queue_depth"`     // 排队中的任务数
+		OldestWait     float64 `json:"oldest_wait"`     // 最早排队的任务已等待的时间（秒）
+		Running        int     `json:"running"`         // 执行中的任务数
+		Workers        int     `json:"workers"`         // 在线且未 drain 的 worker 数
+		DrainedWorkers int     `json:"drained_workers"` // 在线但已 drain 的 worker 数
+	}
+
+	// Handler 事件处理函数，返回的错误只记录日志，不影响其他订阅者
+	Handler func(ctx context.Context, e Event) error
+
+	// Plugin 进程内插件，在 init 中调用 RegisterPlugin 注册，事件总线初始化时调用 Setup 订阅事件
+	Plugin interface {
+		Name() string
+		Setup(bus *Bus) error
+	}
+
+	Option struct {
+		Conf cfg.EventBus
+	}
+
+	// Bus 事件总线，事件放入缓冲后由 Workers 个协程异步分发，不阻塞发布方
+	Bus struct {
+		option Option
+		events chan Event
+		seq    uint64
+
+		mtx         sync.RWMutex
+		subscribers map[string][]subscriber
+	}
+
+	subscriber struct {
+		name    string
+		handler Handler
+	}
+)
+
+var (
+	instance *Bus
+
+	pluginsMtx sync.Mutex
+	plugins    []Plugin
+)
+
+// RegisterPlugin 注册插件，需要在 Init 之前调用
+func RegisterPlugin(p Plugin) {
+	pluginsMtx.Lock()
+	defer pluginsMtx.Unlock()
+	plugins = append(plugins, p)
+}
+
+// Init 创建事件总线，订阅配置的 HTTP Hook 并初始化已注册的插件
+func Init(o Option) (err error) {
+	bus := New(o)
+	for _, hook := range o.Conf.Hooks {
+		bus.subscribeHook(hook)
+	}
+
+	pluginsMtx.Lock()
+	defer pluginsMtx.Unlock()
+	for _, p := range plugins {
+		if err = p.Setup(bus); err != nil {
+			return fmt.Errorf("setup eventbus plugin %s: %w", p.Name(), err)
+		}
+		xlog.Info("eventbus plugin loaded", xlog.String("plugin", p.Name()))
+	}
+
+	metrics.RegisterQueue("eventbus", func() int { return len(bus.events) })
+	bus.Start()
+	instance = bus
+	return nil
+}
+
+// Publish 发布事件，未初始化时忽略
+func Publish(topic string, data interface{}) {
+	if instance == nil {
+		return
+	}
+	instance.Publish(topic, data)
+}
+
+// New 创建事件总线，调用 Start 后开始分发事件
+func New(o Option) *Bus {
+	if o.Conf.Workers <= 0 {
+		o.Conf.Workers = defaultWorkers
+	}
+	if o.Conf.BufferSize <= 0 {
+		o.Conf.BufferSize = defaultBufferSize
+	}
+	return &Bus{
+		option:      o,
+		events:      make(chan Event, o.Conf.BufferSize),
+		subscribers: map[string][]subscriber{},
+	}
+}
+
+// Subscribe 订阅主题，topic 为 TopicAll 时接收全部事件，name 用于日志
+func (b *Bus) Subscribe(name, topic string, handler Handler) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.subscribers[topic] = append(b.subscribers[topic], subscriber{name: name, handler: handler})
+}
+
+// Publish 发布事件，缓冲已满时丢弃并记录日志
+func (b *Bus) Publish(topic string, data interface{}) {
+	e := Event{
+		ID:    fmt.Sprintf("%d-%d", time.Now().UnixNano(), atomic.AddUint64(&b.seq, 1)),
+		Topic: topic,
+		Time:  time.Now(),
+		Data:  data,
+	}
+
+	select {
+	case b.events <- e:
+	default:
+		xlog.Warn("eventbus buffer is full, drop event", xlog.String("topic", topic), xlog.String("id", e.ID))
+	}
+}
+
+// Start 启动分发协程
+func (b *Bus) Start() {
+	for i := 0; i < b.option.Conf.Workers; i++ {
+		go func() {
+			for e := range b.events {
+				b.dispatch(e)
+			}
+		}()
+	}
+}
+
+func (b *Bus) dispatch(e Event) {
+	b.mtx.RLock()
+	subs := make([]subscriber, 0, len(b.subscribers[e.Topic])+len(b.subscribers[TopicAll]))
+	subs = append(subs, b.subscribers[e.Topic]...)
+	subs = append(subs, b.subscribers[TopicAll]...)
+	b.mtx.RUnlock()
+
+	for _, sub := range subs {
+		if err := b.handle(sub, e); err != nil {
+			xlog.Error("eventbus handle event failed",
+				xlog.String("subscriber", sub.name), xlog.String("topic", e.Topic), xlog.String("id", e.ID), xlog.FieldErr(err))
+		}
+	}
+}
+
+// handle 执行订阅者的处理函数，处理函数 panic 时转为错误，避免影响分发协程
+func (b *Bus) handle(sub subscriber, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultHandlerTimeout)
+	defer cancel()
+	return sub.handler(ctx, e)
+}
@@ -0,0 +1,75 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/notice"
+)
+
+func TestBus(t *testing.T) {
+	bus := New(Option{Conf: cfg.EventBus{Workers: 1}})
+	received := make(chan string, 10)
+
+	bus.Subscribe("panic", TopicAppCreated, func(ctx context.Context, e Event) error {
+		panic("boom")
+	})
+	bus.Subscribe("app", TopicAppCreated, func(ctx context.Context, e Event) error {
+		received <- "app:" + e.Data.(AppCreated).AppName
+		return nil
+	})
+	bus.Subscribe("all", TopicAll, func(ctx context.Context, e Event) error {
+		received <- "all:" + e.Topic
+		return nil
+	})
+	bus.Start()
+
+	bus.Publish(TopicAppCreated, AppCreated{AppName: "juno-admin"})
+	bus.Publish(TopicTaskFinished, TaskFinished{TaskID: 1})
+
+	want := []string{"app:juno-admin", "all:" + TopicAppCreated, "all:" + TopicTaskFinished}
+	for _, w := range want {
+		select {
+		case got := <-received:
+			if got != w {
+				t.Errorf("got %s, want %s", got, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %s", w)
+		}
+	}
+}
+
+func TestSendHook(t *testing.T) {
+	var got Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sign := notice.SignWebhook("secret", r.Header.Get(notice.WebhookHeaderTimestamp), body)
+		if r.Header.Get(notice.WebhookHeaderSignature) != sign || r.Header.Get(notice.WebhookHeaderEvent) != TopicConfigPublished {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer server.Close()
+
+	hook := cfg.EventHook{Name: "test", URL: server.URL, Secret: "secret"}
+	e := Event{ID: "1", Topic: TopicConfigPublished, Data: ConfigPublished{ConfigID: 2}}
+	if err := sendHook(context.Background(), server.Client(), hook, e); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "1" || got.Topic != TopicConfigPublished {
+		t.Errorf("unexpected event %+v", got)
+	}
+
+	hook.Secret = "wrong"
+	if err := sendHook(context.Background(), server.Client(), hook, e); err == nil {
+		t.Error("expect error with wrong secret")
+	}
+}
@@ -0,0 +1,70 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/notice"
+)
+
+const defaultHookTimeout = 5 * time.Second
+
+// subscribeHook 将订阅的事件以 JSON POST 到 hook.URL，请求头与通知 Webhook 一致，非 2xx 响应视为失败
+func (b *Bus) subscribeHook(hook cfg.EventHook) {
+	topics := hook.Topics
+	if len(topics) == 0 {
+		topics = []string{TopicAll}
+	}
+
+	client := &http.Client{Timeout: hook.Timeout}
+	if hook.Timeout <= 0 {
+		client.Timeout = defaultHookTimeout
+	}
+	for _, topic := range topics {
+		b.Subscribe("hook:"+hook.Name, topic, func(ctx context.Context, e Event) error {
+			return sendHook(ctx, client, hook, e)
+		})
+	}
+}
+
+func sendHook(ctx context.Context, client *http.Client, hook cfg.EventHook, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	for key, value := range hook.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set(notice.WebhookHeaderEvent, e.Topic)
+	if hook.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(notice.WebhookHeaderTimestamp, timestamp)
+		req.Header.Set(notice.WebhookHeaderSignature, notice.SignWebhook(hook.Secret, timestamp, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("hook %s failed: %d %s", hook.Name, resp.StatusCode, string(msg))
+	}
+	return nil
+}
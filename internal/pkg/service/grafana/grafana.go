@@ -62,7 +62,7 @@ func getOption() (opt Option, err error) {
 func Proxy(c echo.Context) (err error) {
 	opt, err := getOption()
 	if err != nil {
-		return output.JSON(c, 1, err.Error())
+		return output.JSON(c, output.MsgErr, err.Error())
 	}
 
 	u := user.GetUser(c)
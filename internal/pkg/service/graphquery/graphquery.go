@@ -0,0 +1,91 @@
+package graphquery
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	casbin2 "github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/permission"
+	"github.com/douyu/juno/pkg/graphql"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/jinzhu/gorm"
+)
+
+// GraphQuery 应用、实例、配置、流水线、告警的只读 GraphQL 查询
+var GraphQuery *graphQuery
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	graphQuery struct {
+		db     *gorm.DB
+		schema *graphql.Schema
+	}
+
+	viewerKey struct{}
+
+	// viewer 当前查询的用户，权限检查结果在一次查询内缓存
+	viewer struct {
+		user       *db.User
+		zones      []string
+		restricted bool
+		perms      map[string]bool
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	g := &graphQuery{
+		db: o.DB,
+	}
+	g.schema = g.buildSchema()
+	GraphQuery = g
+}
+
+// Execute 以用户身份执行查询，配置、流水线按应用权限过滤，实例、配置、流水线按用户可访问的机房过滤
+func (g *graphQuery) Execute(ctx context.Context, u *db.User, req graphql.Request) *graphql.Response {
+	zones, restricted, err := permission.ZoneScope.UserZones(u)
+	if err != nil {
+		return &graphql.Response{Errors: []*graphql.Error{{Message: err.Error()}}}
+	}
+
+	ctx = context.WithValue(ctx, viewerKey{}, &viewer{
+		user:       u,
+		zones:      zones,
+		restricted: restricted,
+		perms:      make(map[string]bool),
+	})
+	return g.schema.Execute(ctx, req)
+}
+
+// SDL 查询支持的类型和字段
+func (g *graphQuery) SDL() string {
+	return g.schema.SDL()
+}
+
+func viewerFrom(ctx context.Context) *viewer {
+	return ctx.Value(viewerKey{}).(*viewer)
+}
+
+func (v *viewer) zoneAllowed(zone string) bool {
+	return permission.ZoneAllowed(v.zones, v.restricted, zone)
+}
+
+// can 用户是否有应用在该环境下的权限，与 CasbinAppMW 的判断一致
+func (v *viewer) can(appName, env, action string) bool {
+	key := fmt.Sprintf("%s|%s|%s", appName, env, action)
+	if allowed, ok := v.perms[key]; ok {
+		return allowed
+	}
+
+	obj := casbin2.CasbinAppObjKey(appName, env)
+	allowed, err := casbin2.Casbin.CheckPermission(strconv.Itoa(v.user.Uid), obj, action, db.CasbinPolicyTypeApp)
+	if err != nil || !allowed {
+		allowed = permission.Permission.CheckGitlabAuth(uint(v.user.Uid), appName, env) == nil
+	}
+	v.perms[key] = allowed
+	return allowed
+}
@@ -0,0 +1,163 @@
+package graphquery
+
+import (
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
+	"github.com/douyu/juno/internal/pkg/service/tag"
+	"github.com/douyu/juno/pkg/graphql"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/jinzhu/gorm"
+)
+
+func (g *graphQuery) resolveApp(p graphql.ResolveParams) (interface{}, error) {
+	var app db.AppInfo
+	err := g.db.Where("app_name = ?", stringArg(p.Args, "name")).First(&app).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+func (g *graphQuery) resolveApps(p graphql.ResolveParams) (interface{}, error) {
+	limit, offset := page(p.Args)
+	query := g.db.Where("status not in (?)", hiddenAppStatus)
+	if keyword := stringArg(p.Args, "keyword"); keyword != "" {
+		like := "%" + listquery.EscapeLike(keyword) + "%"
+		query = query.Where("app_name like ? or name like ?", like, like)
+	}
+
+	apps := make([]*db.AppInfo, 0)
+	err := query.Order("aid").Limit(limit).Offset(offset).Find(&apps).Error
+	return apps, err
+}
+
+func (g *graphQuery) resolveAppTags(p graphql.ResolveParams) (interface{}, error) {
+	return tag.Tag.Get(db.TagEntityApp, p.Source.(*db.AppInfo).AppName)
+}
+
+func (g *graphQuery) resolveAppInstances(p graphql.ResolveParams) (interface{}, error) {
+	v := viewerFrom(p.Context)
+	query := g.db.Where("app_name = ?", p.Source.(*db.AppInfo).AppName)
+	if env := stringArg(p.Args, "env"); env != "" {
+		query = query.Where("env = ?", env)
+	}
+	if zone := stringArg(p.Args, "zone_code"); zone != "" {
+		query = query.Where("zone_code = ?", zone)
+	}
+
+	var nodes []db.AppNode
+	err := query.Order("env, zone_code, host_name").Find(&nodes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]db.AppNode, 0, len(nodes))
+	for _, node := range nodes {
+		if v.zoneAllowed(node.ZoneCode) {
+			list = append(list, node)
+		}
+	}
+	return list, nil
+}
+
+func (g *graphQuery) resolveAppConfigs(p graphql.ResolveParams) (interface{}, error) {
+	v := viewerFrom(p.Context)
+	app := p.Source.(*db.AppInfo)
+	query := g.db.Select("id, aid, name, format, env, zone, version, created_at, updated_at, published_at").
+		Where("aid = ?", app.Aid)
+	if env := stringArg(p.Args, "env"); env != "" {
+		query = query.Where("env = ?", env)
+	}
+
+	var configs []db.Configuration
+	err := query.Order("env, zone, name").Find(&configs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]db.Configuration, 0, len(configs))
+	for _, config := range configs {
+		if v.zoneAllowed(config.Zone) && v.can(app.AppName, config.Env, db.AppPermConfigRead) {
+			list = append(list, config)
+		}
+	}
+	return list, nil
+}
+
+func (g *graphQuery) resolveAppPipelines(p graphql.ResolveParams) (interface{}, error) {
+	v := viewerFrom(p.Context)
+	app := p.Source.(*db.AppInfo)
+	query := g.db.Where("app_name = ?", app.AppName)
+	if env := stringArg(p.Args, "env"); env != "" {
+		query = query.Where("env = ?", env)
+	}
+
+	var pipelines []db.TestPipeline
+	err := query.Order("id").Find(&pipelines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]db.TestPipeline, 0, len(pipelines))
+	for _, pipeline := range pipelines {
+		if v.zoneAllowed(pipeline.ZoneCode) && v.can(app.AppName, pipeline.Env, db.AppPermPipelineRead) {
+			list = append(list, pipeline)
+		}
+	}
+	return list, nil
+}
+
+func (g *graphQuery) resolveLatestTask(p graphql.ResolveParams) (interface{}, error) {
+	var task db.TestPipelineTask
+	err := g.db.Select(taskColumns).Where("pipeline_id = ?", p.Source.(db.TestPipeline).ID).
+		Order("id desc").First(&task).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (g *graphQuery) resolvePipelineTasks(p graphql.ResolveParams) (interface{}, error) {
+	limit, offset := page(p.Args)
+	tasks := make([]db.TestPipelineTask, 0)
+	err := g.db.Select(taskColumns).Where("pipeline_id = ?", p.Source.(db.TestPipeline).ID).
+		Order("id desc").Limit(limit).Offset(offset).Find(&tasks).Error
+	return tasks, err
+}
+
+// taskColumns 任务列表不加载日志
+const taskColumns = "id, created_at, updated_at, pipeline_id, name, app_name, branch, env, zone_code, status, created_by"
+
+func (g *graphQuery) resolveTaskSteps(p graphql.ResolveParams) (interface{}, error) {
+	steps := make([]db.TestPipelineStepStatus, 0)
+	err := g.db.Select("id, task_id, step_name, status").Where("task_id = ?", p.Source.(db.TestPipelineTask).ID).
+		Order("id").Find(&steps).Error
+	return steps, err
+}
+
+func (g *graphQuery) resolveAlerts(p graphql.ResolveParams) (interface{}, error) {
+	return g.alerts(g.db, p.Args)
+}
+
+func (g *graphQuery) resolveAppAlerts(p graphql.ResolveParams) (interface{}, error) {
+	return g.alerts(g.db.Where("app = ?", p.Source.(*db.AppInfo).AppName), p.Args)
+}
+
+func (g *graphQuery) alerts(query *gorm.DB, args map[string]interface{}) (interface{}, error) {
+	limit, offset := page(args)
+	if env := stringArg(args, "env"); env != "" {
+		query = query.Where("env = ?", env)
+	}
+	if status := stringArg(args, "status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	incidents := make([]db.Incident, 0)
+	err := query.Order("id desc").Limit(limit).Offset(offset).Find(&incidents).Error
+	return incidents, err
+}
@@ -0,0 +1,175 @@
+package graphquery
+
+import (
+	"strings"
+
+	"github.com/douyu/juno/pkg/graphql"
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+const (
+	defaultFirst = 20
+	maxFirst     = 100
+	// maxDepth app → pipelines → latest_task → steps 为 4 层，留出余量
+	maxDepth = 6
+)
+
+var hiddenAppStatus = []string{db.AppStatusArchived, db.AppStatusDeleted}
+
+// pageArgs 列表字段通用的分页参数
+func pageArgs(args graphql.Args) graphql.Args {
+	args["first"] = &graphql.Arg{Type: graphql.Int, Default: defaultFirst, Description: "返回条数，最大 100"}
+	args["offset"] = &graphql.Arg{Type: graphql.Int, Default: 0}
+	return args
+}
+
+func page(args map[string]interface{}) (limit, offset int) {
+	limit, _ = args["first"].(int)
+	offset, _ = args["offset"].(int)
+	if limit <= 0 || limit > maxFirst {
+		limit = maxFirst
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return strings.TrimSpace(s)
+}
+
+func (g *graphQuery) buildSchema() *graphql.Schema {
+	step := &graphql.Object{Name: "TaskStep", Fields: graphql.Fields{
+		"step_name": {Type: graphql.String},
+		"status":    {Type: graphql.String},
+	}}
+
+	task := &graphql.Object{Name: "Task", Description: "流水线任务", Fields: graphql.Fields{
+		"id":         {Type: graphql.ID},
+		"name":       {Type: graphql.String},
+		"branch":     {Type: graphql.String},
+		"env":        {Type: graphql.String},
+		"zone_code":  {Type: graphql.String},
+		"status":     {Type: graphql.String, Description: "pending、running、failed、success"},
+		"created_by": {Type: graphql.Int},
+		"created_at": {Type: graphql.String},
+		"updated_at": {Type: graphql.String},
+		"steps":      {Type: graphql.ListOf(step), Resolve: g.resolveTaskSteps},
+	}}
+
+	pipeline := &graphql.Object{Name: "Pipeline", Description: "测试流水线", Fields: graphql.Fields{
+		"id":          {Type: graphql.ID},
+		"name":        {Type: graphql.String},
+		"env":         {Type: graphql.String},
+		"zone_code":   {Type: graphql.String},
+		"branch":      {Type: graphql.String},
+		"code_check":  {Type: graphql.Boolean},
+		"unit_test":   {Type: graphql.Boolean},
+		"latest_task": {Type: task, Description: "最近一次执行的任务", Resolve: g.resolveLatestTask},
+		"tasks":       {Type: graphql.ListOf(task), Args: pageArgs(graphql.Args{}), Resolve: g.resolvePipelineTasks},
+	}}
+
+	config := &graphql.Object{Name: "Config", Description: "配置文件，不包含配置内容", Fields: graphql.Fields{
+		"id":           {Type: graphql.ID},
+		"name":         {Type: graphql.String},
+		"format":       {Type: graphql.String},
+		"env":          {Type: graphql.String},
+		"zone":         {Type: graphql.String},
+		"version":      {Type: graphql.String},
+		"created_at":   {Type: graphql.String},
+		"updated_at":   {Type: graphql.String},
+		"published_at": {Type: graphql.String, Description: "未发布时为 null"},
+	}}
+
+	instance := &graphql.Object{Name: "Instance", Description: "应用实例", Fields: graphql.Fields{
+		"id":          {Type: graphql.ID},
+		"host_name":   {Type: graphql.String},
+		"ip":          {Type: graphql.String},
+		"env":         {Type: graphql.String},
+		"region_code": {Type: graphql.String},
+		"region_name": {Type: graphql.String},
+		"zone_code":   {Type: graphql.String},
+		"zone_name":   {Type: graphql.String},
+		"update_time": {Type: graphql.Int},
+	}}
+
+	alert := &graphql.Object{Name: "Alert", Description: "值班告警", Fields: graphql.Fields{
+		"id":           {Type: graphql.ID},
+		"app":          {Type: graphql.String},
+		"env":          {Type: graphql.String},
+		"type":         {Type: graphql.String},
+		"severity":     {Type: graphql.String},
+		"subject":      {Type: graphql.String},
+		"content":      {Type: graphql.String},
+		"count":        {Type: graphql.Int},
+		"status":       {Type: graphql.String, Description: "firing、acked、resolved"},
+		"level":        {Type: graphql.Int},
+		"ack_uid":      {Type: graphql.Int},
+		"ack_time":     {Type: graphql.Int},
+		"resolve_time": {Type: graphql.Int},
+		"created_at":   {Type: graphql.String},
+	}}
+
+	alertArgs := func() graphql.Args {
+		return pageArgs(graphql.Args{
+			"env":    {Type: graphql.String},
+			"status": {Type: graphql.String},
+		})
+	}
+
+	app := &graphql.Object{Name: "App", Description: "应用", Fields: graphql.Fields{
+		"aid":         {Type: graphql.ID},
+		"name":        {Type: graphql.String},
+		"app_name":    {Type: graphql.String},
+		"biz_domain":  {Type: graphql.String},
+		"lang":        {Type: graphql.String},
+		"level":       {Type: graphql.Int},
+		"team_id":     {Type: graphql.Int},
+		"status":      {Type: graphql.String},
+		"git_url":     {Type: graphql.String},
+		"create_time": {Type: graphql.Int},
+		"update_time": {Type: graphql.Int},
+		"tags":        {Type: graphql.ListOf(graphql.String), Resolve: g.resolveAppTags},
+		"instances": {
+			Type:    graphql.ListOf(instance),
+			Args:    graphql.Args{"env": {Type: graphql.String}, "zone_code": {Type: graphql.String}},
+			Resolve: g.resolveAppInstances,
+		},
+		"configs": {
+			Type:        graphql.ListOf(config),
+			Description: "需要配置读权限，无权限的环境不返回",
+			Args:        graphql.Args{"env": {Type: graphql.String}},
+			Resolve:     g.resolveAppConfigs,
+		},
+		"pipelines": {
+			Type:        graphql.ListOf(pipeline),
+			Description: "需要流水线读权限，无权限的环境不返回",
+			Args:        graphql.Args{"env": {Type: graphql.String}},
+			Resolve:     g.resolveAppPipelines,
+		},
+		"alerts": {Type: graphql.ListOf(alert), Args: alertArgs(), Resolve: g.resolveAppAlerts},
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: graphql.Fields{
+		"app": {
+			Type:    app,
+			Args:    graphql.Args{"name": {Type: graphql.String, Required: true, Description: "应用名"}},
+			Resolve: g.resolveApp,
+		},
+		"apps": {
+			Type:        graphql.ListOf(app),
+			Description: "不包含已归档、待删除的应用",
+			Args:        pageArgs(graphql.Args{"keyword": {Type: graphql.String, Description: "按应用名、中文名模糊匹配"}}),
+			Resolve:     g.resolveApps,
+		},
+		"alerts": {
+			Type:    graphql.ListOf(alert),
+			Args:    alertArgs(),
+			Resolve: g.resolveAlerts,
+		},
+	}}
+
+	return &graphql.Schema{Query: query, MaxDepth: maxDepth}
+}
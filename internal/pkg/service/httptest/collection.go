@@ -3,6 +3,7 @@ package httptest
 import (
 	"fmt"
 
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/view"
 	"github.com/jinzhu/gorm"
@@ -111,17 +112,34 @@ func ListCollection(uid uint, req view.ListHttpCollection) (resp view.RespListHt
 	return
 }
 
+// DeleteCollection 删除测试集，删除的测试集保留在回收站中，保留期内可以恢复
 func DeleteCollection(uid, id uint) (err error) {
-	query := option.DB.Where("id = ?", id).Delete(&db.HttpTestCollection{})
-	if query.RowsAffected == 0 {
-		err = fmt.Errorf("collection 不存在")
+	var collection db.HttpTestCollection
+	err = option.DB.Where("id = ?", id).First(&collection).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = fmt.Errorf("collection 不存在")
+		}
 		return
 	}
 
-	err = query.Error
+	tx := option.DB.Begin()
+	err = tx.Delete(&collection).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = recyclebin.Add(tx, db.RecycleItem{
+		Kind:      db.RecycleKindHttpCollection,
+		ObjectID:  collection.ID,
+		Name:      collection.Name,
+		AppName:   collection.AppName,
+		DeletedBy: int(uid),
+	})
 	if err != nil {
+		tx.Rollback()
 		return
 	}
 
-	return
+	return tx.Commit().Error
 }
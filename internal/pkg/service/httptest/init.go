@@ -19,4 +19,5 @@ type (
 func Init(opt Option) {
 	option = opt
 	option.client = resty.New()
+	registerRecycleHandler()
 }
@@ -0,0 +1,39 @@
+package httptest
+
+import (
+	"fmt"
+
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/jinzhu/gorm"
+)
+
+func registerRecycleHandler() {
+	recyclebin.Register(db.RecycleKindHttpCollection, recyclebin.Handler{
+		Restore: restoreCollection,
+		Purge:   purgeCollection,
+	})
+}
+
+// restoreCollection 取消测试集的删除标记，测试用例没有随测试集删除，不需要恢复
+func restoreCollection(tx *gorm.DB, item db.RecycleItem) (err error) {
+	var count int
+	err = tx.Model(&db.AppInfo{}).Where("app_name = ?", item.AppName).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count == 0 {
+		return fmt.Errorf("应用 %s 不存在，请先恢复应用", item.AppName)
+	}
+
+	return tx.Unscoped().Model(&db.HttpTestCollection{}).Where("id = ?", item.ObjectID).Update("deleted_at", nil).Error
+}
+
+// purgeCollection 彻底删除测试集及其测试用例
+func purgeCollection(tx *gorm.DB, item db.RecycleItem) (err error) {
+	err = tx.Unscoped().Where("collection_id = ?", item.ObjectID).Delete(&db.HttpTestCase{}).Error
+	if err != nil {
+		return
+	}
+	return tx.Unscoped().Where("id = ?", item.ObjectID).Delete(&db.HttpTestCollection{}).Error
+}
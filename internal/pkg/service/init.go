@@ -6,27 +6,52 @@ import (
 	"github.com/douyu/juno/internal/pkg/service/loggerplatform"
 
 	"github.com/douyu/juno/internal/pkg/invoker"
+	"github.com/douyu/juno/internal/pkg/service/accessrequest"
+	"github.com/douyu/juno/internal/pkg/service/agent"
 	"github.com/douyu/juno/internal/pkg/service/analysis"
 	"github.com/douyu/juno/internal/pkg/service/appDep"
 	"github.com/douyu/juno/internal/pkg/service/appevent"
+	"github.com/douyu/juno/internal/pkg/service/appimport"
+	"github.com/douyu/juno/internal/pkg/service/applifecycle"
 	"github.com/douyu/juno/internal/pkg/service/applog"
+	"github.com/douyu/juno/internal/pkg/service/apptransfer"
+	"github.com/douyu/juno/internal/pkg/service/artifact"
+	"github.com/douyu/juno/internal/pkg/service/auditlog"
 	"github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/cmdb"
 	"github.com/douyu/juno/internal/pkg/service/confgo"
 	"github.com/douyu/juno/internal/pkg/service/confgov2"
 	"github.com/douyu/juno/internal/pkg/service/configresource"
+	"github.com/douyu/juno/internal/pkg/service/deployment"
+	"github.com/douyu/juno/internal/pkg/service/eventbus"
 	"github.com/douyu/juno/internal/pkg/service/gateway"
+	"github.com/douyu/juno/internal/pkg/service/graphquery"
 	"github.com/douyu/juno/internal/pkg/service/grpcgovern"
 	"github.com/douyu/juno/internal/pkg/service/grpctest"
 	"github.com/douyu/juno/internal/pkg/service/httptest"
+	"github.com/douyu/juno/internal/pkg/service/k8scluster"
+	"github.com/douyu/juno/internal/pkg/service/notifyrule"
+	"github.com/douyu/juno/internal/pkg/service/notifytemplate"
+	"github.com/douyu/juno/internal/pkg/service/oncall"
 	"github.com/douyu/juno/internal/pkg/service/openauth"
 	"github.com/douyu/juno/internal/pkg/service/parse"
 	"github.com/douyu/juno/internal/pkg/service/permission"
+	"github.com/douyu/juno/internal/pkg/service/personaltoken"
 	"github.com/douyu/juno/internal/pkg/service/pprof"
+	"github.com/douyu/juno/internal/pkg/service/promotion"
+	"github.com/douyu/juno/internal/pkg/service/provision"
+	"github.com/douyu/juno/internal/pkg/service/proxyaudit"
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
 	sresource "github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/internal/pkg/service/serviceaccount"
 	"github.com/douyu/juno/internal/pkg/service/system"
+	"github.com/douyu/juno/internal/pkg/service/tag"
 	"github.com/douyu/juno/internal/pkg/service/taskplatform"
+	"github.com/douyu/juno/internal/pkg/service/team"
 	"github.com/douyu/juno/internal/pkg/service/testplatform"
 	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/internal/pkg/service/wsevent"
+	"github.com/douyu/juno/pkg/auth/oidc"
 	"github.com/douyu/juno/pkg/auth/social"
 	"github.com/douyu/juno/pkg/cfg"
 	"github.com/douyu/jupiter/pkg/conf"
@@ -37,12 +62,25 @@ func Init() (err error) {
 
 	// 事件最先初始化，最低层
 	appevent.InitAppEvent(invoker.EventProducer, cfg.Cfg.JunoEvent.Rocketmq.Topic)
+	wsevent.Init(wsevent.Option{})
+	err = eventbus.Init(eventbus.Option{Conf: cfg.Cfg.EventBus})
+	if err != nil {
+		return
+	}
+
+	// 回收站在删除对象的 service 之前初始化
+	recyclebin.Init(recyclebin.Option{
+		DB:   invoker.JunoMysql,
+		Conf: cfg.Cfg.RecycleBin,
+	})
 
 	// 初始化资源
 	sresource.InitResource(invoker.JunoMysql)
 
 	social.NewOAuthService()
 
+	oidc.Init(cfg.Cfg.Auth.OIDC)
+
 	confgo.Init()
 
 	confgov2.Init(invoker.JunoMysql)
@@ -90,6 +128,49 @@ func Init() (err error) {
 
 	appDep.Init()
 
+	appimport.Init(appimport.Option{
+		DB:   invoker.JunoMysql,
+		Conf: cfg.Cfg.AppImport,
+	})
+
+	cmdb.Init(cmdb.Option{
+		DB:   invoker.JunoMysql,
+		Conf: cfg.Cfg.CMDB,
+	})
+
+	applifecycle.Init(applifecycle.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	k8scluster.Init(k8scluster.Option{
+		DB:   invoker.JunoMysql,
+		Conf: cfg.Cfg.K8SCluster,
+	})
+
+	promotion.Init(promotion.Option{
+		DB:   invoker.JunoMysql,
+		Conf: cfg.Cfg.Promotion,
+	})
+
+	apptransfer.Init(apptransfer.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	deployment.Init(deployment.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	tag.Init(tag.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	err = artifact.Init(artifact.Option{
+		Conf: cfg.Cfg.TestPlatform.Artifact,
+	})
+	if err != nil {
+		return
+	}
+
 	testplatform.Init(testplatform.Option{
 		Enable:         cfg.Cfg.TestPlatform.Enable,
 		DB:             invoker.JunoMysql,
@@ -101,6 +182,8 @@ func Init() (err error) {
 			HeartbeatTimeout: cfg.Cfg.TestPlatform.Worker.HeartbeatTimeout,
 			LocalQueueDir:    cfg.Cfg.TestPlatform.Worker.LocalQueueDir,
 		},
+		Autoscale: cfg.Cfg.TestPlatform.Autoscale,
+		SecretKey: cfg.Cfg.TestPlatform.SecretKey,
 	})
 
 	taskplatform.Init(taskplatform.Option{
@@ -109,5 +192,53 @@ func Init() (err error) {
 
 	loggerplatform.Init()
 
+	agent.Init(agent.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	proxyaudit.Init(proxyaudit.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	personaltoken.Init(personaltoken.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	team.Init(team.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	notifyrule.Init(notifyrule.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	notifytemplate.Init(notifytemplate.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	oncall.Init(oncall.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	auditlog.Init(auditlog.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	accessrequest.Init(accessrequest.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	serviceaccount.Init(serviceaccount.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	provision.Init(provision.Option{
+		DB: invoker.JunoMysql,
+	})
+
+	graphquery.Init(graphquery.Option{
+		DB: invoker.JunoMysql,
+	})
+
 	return
 }
@@ -0,0 +1,285 @@
+package k8scluster
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/system"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/k8s"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/util"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	defaultNamespace = "default"
+	defaultAppLabel  = "app"
+)
+
+var (
+	// K8sCluster k8s 集群及凭证管理，供工作负载查询等需要访问集群的功能使用
+	K8sCluster *k8sCluster
+
+	errNoSecretKey = fmt.Errorf("未配置 k8sCluster.secretKey，不能保存集群凭证")
+)
+
+type (
+	Option struct {
+		DB   *gorm.DB
+		Conf cfg.K8SCluster
+	}
+
+	k8sCluster struct {
+		db   *gorm.DB
+		conf cfg.K8SCluster
+
+		checking int32
+	}
+)
+
+func Init(o Option) {
+	K8sCluster = &k8sCluster{
+		db:   o.DB,
+		conf: o.Conf,
+	}
+}
+
+// List 全部集群，不包含凭证
+func (k *k8sCluster) List() (list []db.K8sCluster, err error) {
+	list = make([]db.K8sCluster, 0)
+	err = k.db.Order("zone_code asc, name asc").Find(&list).Error
+	return
+}
+
+// Save 创建或更新集群，保存前校验凭证格式，保存后立即检查连通性
+func (k *k8sCluster) Save(param view.ReqSaveK8sCluster, u *db.User) (item db.K8sCluster, err error) {
+	if k.conf.SecretKey == "" {
+		return item, errNoSecretKey
+	}
+	if param.ID != 0 {
+		err = k.db.Where("id = ?", param.ID).First(&item).Error
+		if err != nil {
+			return
+		}
+	}
+	var count int
+	k.db.Model(db.K8sCluster{}).Where("name = ? and id != ?", param.Name, param.ID).Count(&count)
+	if count > 0 {
+		return item, fmt.Errorf("集群 %s 已存在", param.Name)
+	}
+
+	credential := param.Token
+	if param.AuthType == db.K8sAuthKubeconfig {
+		credential = param.Kubeconfig
+	}
+	credential = strings.TrimSpace(credential)
+	if credential == "" && (param.ID == 0 || item.AuthType != param.AuthType) {
+		return item, fmt.Errorf("请填写集群凭证")
+	}
+	if credential != "" {
+		item.Credential, err = util.AESGCMEncrypt(credential, k.conf.SecretKey)
+		if err != nil {
+			return
+		}
+	}
+
+	item.Name = param.Name
+	item.Env = param.Env
+	item.ZoneCode = param.ZoneCode
+	item.ZoneName = param.ZoneName
+	item.AuthType = param.AuthType
+	item.Server = strings.TrimSpace(param.Server)
+	item.CAData = param.CAData
+	item.Insecure = param.Insecure
+	item.Namespace = param.Namespace
+	item.AppLabel = param.AppLabel
+	item.Uid = u.Uid
+
+	// 校验凭证能够解析，kubeconfig 认证时从中取 API Server 地址
+	conf, err := k.Config(item)
+	if err != nil {
+		return
+	}
+	item.Server = conf.Server
+	if item.Server == "" {
+		return item, fmt.Errorf("请填写 API Server 地址")
+	}
+
+	if item.ID == 0 {
+		item.Status = db.K8sClusterUnknown
+		err = k.db.Create(&item).Error
+	} else {
+		err = k.db.Save(&item).Error
+	}
+	if err != nil {
+		return
+	}
+	k.check(&item)
+	return item, nil
+}
+
+// Delete 删除集群
+func (k *k8sCluster) Delete(id uint) error {
+	return k.db.Where("id = ?", id).Delete(&db.K8sCluster{}).Error
+}
+
+// Config 解密凭证得到集群访问配置
+func (k *k8sCluster) Config(item db.K8sCluster) (conf k8s.Config, err error) {
+	if k.conf.SecretKey == "" {
+		return conf, errNoSecretKey
+	}
+	credential, err := util.AESGCMDecrypt(item.Credential, k.conf.SecretKey)
+	if err != nil {
+		return conf, fmt.Errorf("集群 %s 凭证解密失败，k8sCluster.secretKey 可能已修改: %s", item.Name, err.Error())
+	}
+
+	switch item.AuthType {
+	case db.K8sAuthKubeconfig:
+		conf, _, err = k8s.ParseKubeconfig([]byte(credential))
+		if err != nil {
+			return
+		}
+		// 页面上勾选跳过证书校验时覆盖 kubeconfig 中的设置
+		conf.Insecure = conf.Insecure || item.Insecure
+	default:
+		conf = k8s.Config{
+			Server:   item.Server,
+			Token:    credential,
+			CAData:   item.CAData,
+			Insecure: item.Insecure,
+		}
+	}
+	return conf, nil
+}
+
+// Client 集群的 API 客户端
+func (k *k8sCluster) Client(item db.K8sCluster) (*k8s.Client, error) {
+	conf, err := k.Config(item)
+	if err != nil {
+		return nil, err
+	}
+	return k8s.NewClient(conf)
+}
+
+// Namespace 集群中应用所在的命名空间
+func Namespace(item db.K8sCluster) string {
+	if item.Namespace == "" {
+		return defaultNamespace
+	}
+	return item.Namespace
+}
+
+// AppLabel 集群中工作负载上标识应用名的标签
+func AppLabel(item db.K8sCluster) string {
+	if item.AppLabel == "" {
+		return defaultAppLabel
+	}
+	return item.AppLabel
+}
+
+// Check 立即检查集群连通性
+func (k *k8sCluster) Check(id uint) (item db.K8sCluster, err error) {
+	err = k.db.Where("id = ?", id).First(&item).Error
+	if err != nil {
+		return
+	}
+	k.check(&item)
+	return item, nil
+}
+
+// CheckTick 定时检查全部集群的连通性
+func (k *k8sCluster) CheckTick() error {
+	if !atomic.CompareAndSwapInt32(&k.checking, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreInt32(&k.checking, 0)
+
+	list, err := k.List()
+	if err != nil {
+		xlog.Error("k8scluster.CheckTick failed", xlog.String("err", err.Error()))
+		return nil
+	}
+	for i := range list {
+		k.check(&list[i])
+	}
+	return nil
+}
+
+// check 读取 API Server 版本并确认凭证能读取命名空间下的 Pod，结果保存到集群
+func (k *k8sCluster) check(item *db.K8sCluster) {
+	version, err := k.ping(*item)
+	now := time.Now()
+	item.CheckedAt = &now
+	if err != nil {
+		item.Status = db.K8sClusterUnhealthy
+		item.Message = err.Error()
+	} else {
+		item.Status = db.K8sClusterHealthy
+		item.Message = ""
+		item.Version = version
+	}
+	err = k.db.Model(db.K8sCluster{}).Where("id = ?", item.ID).UpdateColumns(map[string]interface{}{
+		"status":     item.Status,
+		"message":    item.Message,
+		"version":    item.Version,
+		"checked_at": item.CheckedAt,
+	}).Error
+	if err != nil {
+		xlog.Error("k8scluster.check save failed", xlog.String("cluster", item.Name), xlog.String("err", err.Error()))
+	}
+}
+
+func (k *k8sCluster) ping(item db.K8sCluster) (version string, err error) {
+	client, err := k.Client(item)
+	if err != nil {
+		return
+	}
+	version, err = client.ServerVersion()
+	if err != nil {
+		return
+	}
+	err = client.Ping(Namespace(item))
+	return
+}
+
+// ImportSetting 导入系统设置 k8s_cluster 中配置了 API Server 的集群，同名集群跳过
+func (k *k8sCluster) ImportSetting(u *db.User) (resp view.RespImportK8sCluster, err error) {
+	resp.Imported = make([]string, 0)
+	resp.Skipped = make([]string, 0)
+
+	setting, err := system.System.Setting.K8SClusterSetting()
+	if err != nil {
+		return
+	}
+	for _, cluster := range setting.List {
+		var count int
+		k.db.Model(db.K8sCluster{}).Where("name = ?", cluster.Name).Count(&count)
+		if cluster.Server == "" || cluster.Token == "" || count > 0 {
+			resp.Skipped = append(resp.Skipped, cluster.Name)
+			continue
+		}
+		_, err = k.Save(view.ReqSaveK8sCluster{
+			Name:      cluster.Name,
+			Env:       cluster.Env,
+			ZoneCode:  cluster.ZoneCode,
+			ZoneName:  cluster.ZoneName,
+			AuthType:  db.K8sAuthToken,
+			Server:    cluster.Server,
+			Token:     cluster.Token,
+			CAData:    cluster.CAData,
+			Insecure:  cluster.Insecure,
+			Namespace: cluster.Namespace,
+			AppLabel:  cluster.AppLabel,
+		}, u)
+		if err != nil {
+			return resp, fmt.Errorf("导入集群 %s 失败: %s", cluster.Name, err.Error())
+		}
+		resp.Imported = append(resp.Imported, cluster.Name)
+	}
+	return resp, nil
+}
@@ -0,0 +1,383 @@
+package notifyrule
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/tag"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/internal/pkg/service/wsevent"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// NotifyRule 通知路由规则
+	NotifyRule *notifyRule
+
+	ErrNotifyRuleNotFound = fmt.Errorf("通知规则不存在")
+)
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	notifyRule struct {
+		db *gorm.DB
+
+		mu     sync.RWMutex
+		rules  []view.NotifyRule
+		loaded bool
+
+		suppressMu sync.Mutex
+		suppressed map[string]time.Time
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	NotifyRule = &notifyRule{
+		db:         o.DB,
+		suppressed: make(map[string]time.Time),
+	}
+}
+
+// List 按优先级排序的规则列表
+func (n *notifyRule) List(param view.ReqListNotifyRule) (list []view.NotifyRule, err error) {
+	var rows []db.NotifyRule
+	query := n.db.Model(&db.NotifyRule{})
+	if param.Keyword != "" {
+		query = query.Where("name like ?", "%"+param.Keyword+"%")
+	}
+	err = query.Order("priority, id").Find(&rows).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.NotifyRule, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, transformNotifyRule(row))
+	}
+	return
+}
+
+// Create 创建规则
+func (n *notifyRule) Create(param view.ReqCreateNotifyRule) (err error) {
+	err = n.check(0, param)
+	if err != nil {
+		return
+	}
+
+	item := db.NotifyRule{}
+	fillNotifyRule(&item, param)
+	err = n.db.Create(&item).Error
+	if err != nil {
+		return
+	}
+
+	n.reset()
+	return
+}
+
+// Update 更新规则
+func (n *notifyRule) Update(param view.ReqUpdateNotifyRule) (err error) {
+	item, err := n.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	err = n.check(item.ID, param.ReqCreateNotifyRule)
+	if err != nil {
+		return
+	}
+
+	fillNotifyRule(&item, param.ReqCreateNotifyRule)
+	err = n.db.Save(&item).Error
+	if err != nil {
+		return
+	}
+
+	n.reset()
+	return
+}
+
+// Delete 删除规则
+func (n *notifyRule) Delete(param view.ReqDeleteNotifyRule) (err error) {
+	item, err := n.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	err = n.db.Delete(&item).Error
+	if err != nil {
+		return
+	}
+
+	n.reset()
+	return
+}
+
+// Notify 按规则路由并发送事件。
+// 规则按优先级依次匹配，命中规则的渠道、接收人取并集；免打扰时段内只发送 error 级别事件；
+// 配置了抑制时间的规则在时间窗口内不重复发送相同事件。没有命中任何规则时发送到全部已开启的渠道
+func (n *notifyRule) Notify(e notice.Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Severity == "" {
+		e.Severity = notice.SeverityInfo
+	}
+	if e.Type == notice.EventAlert {
+		// 告警同时推送到控制台，不受通知规则的免打扰、抑制影响
+		wsevent.Publish(wsevent.Event{
+			Topic: wsevent.TopicAlert,
+			Key:   e.App,
+			Data: map[string]interface{}{
+				"app":      e.App,
+				"env":      e.Env,
+				"severity": e.Severity,
+				"subject":  e.Subject,
+			},
+			Time: e.Time.Unix(),
+		})
+	}
+
+	rules, err := n.enabledRules()
+	if err != nil {
+		xlog.Error("notifyrule.Notify load rules failed", xlog.String("event", e.Type), xlog.String("err", err.Error()))
+		notice.Dispatch(e)
+		return
+	}
+	if e.App != "" && e.Tags == nil && hasTagRule(rules) {
+		e.Tags, err = tag.Tag.Get(db.TagEntityApp, e.App)
+		if err != nil {
+			xlog.Error("notifyrule.Notify load app tags failed", xlog.String("app", e.App), xlog.String("err", err.Error()))
+		}
+	}
+
+	matched := false
+	channels := make([]string, 0)
+	var recipients []string
+	for _, rule := range rules {
+		if !MatchRule(rule, e) {
+			continue
+		}
+		matched = true
+
+		if e.Severity != notice.SeverityError && InQuietHours(rule.QuietStart, rule.QuietEnd, e.Time) {
+			xlog.Info("notifyrule.Notify muted in quiet hours", xlog.String("rule", rule.Name), xlog.String("event", e.Type), xlog.String("app", e.App))
+		} else if n.suppress(rule, e) {
+			xlog.Info("notifyrule.Notify suppressed duplicate event", xlog.String("rule", rule.Name), xlog.String("event", e.Type), xlog.String("app", e.App))
+		} else {
+			channels = append(channels, rule.Channels...)
+			recipients = append(recipients, rule.Recipients...)
+		}
+
+		if rule.Stop {
+			break
+		}
+	}
+
+	if !matched {
+		notice.Dispatch(e)
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	if len(recipients) > 0 {
+		err = n.addRecipients(&e, recipients)
+		if err != nil {
+			xlog.Error("notifyrule.Notify load recipients failed", xlog.String("event", e.Type), xlog.String("err", err.Error()))
+		}
+	}
+
+	notice.DispatchTo(e, channels)
+}
+
+// addRecipients 将规则接收人加入 @ 列表和邮件接收人
+func (n *notifyRule) addRecipients(e *notice.Event, usernames []string) (err error) {
+	var users []db.User
+	err = n.db.Select("uid, username").Where("username in (?)", usernames).Find(&users).Error
+	if err != nil {
+		return
+	}
+
+	uids := make([]int, 0, len(users))
+	names := e.Mentions
+	for _, u := range users {
+		uids = append(uids, u.Uid)
+		names = append(names, u.Username)
+	}
+	e.Mentions = splitList(joinList(names))
+
+	emails, err := user.User.NotifyEmails(uids)
+	if err != nil {
+		return
+	}
+	e.Emails = splitList(joinList(append(e.Emails, emails...)))
+	return
+}
+
+// suppress 规则配置了抑制时间且窗口内已发送过相同事件时返回 true，否则记录本次发送
+func (n *notifyRule) suppress(rule view.NotifyRule, e notice.Event) bool {
+	if rule.SuppressSeconds <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	key := suppressKey(rule.ID, e)
+
+	n.suppressMu.Lock()
+	defer n.suppressMu.Unlock()
+
+	if expireAt, ok := n.suppressed[key]; ok && now.Before(expireAt) {
+		return true
+	}
+
+	for k, expireAt := range n.suppressed {
+		if !now.Before(expireAt) {
+			delete(n.suppressed, k)
+		}
+	}
+	n.suppressed[key] = now.Add(time.Duration(rule.SuppressSeconds) * time.Second)
+	return false
+}
+
+func (n *notifyRule) enabledRules() ([]view.NotifyRule, error) {
+	n.mu.RLock()
+	if n.loaded {
+		rules := n.rules
+		n.mu.RUnlock()
+		return rules, nil
+	}
+	n.mu.RUnlock()
+
+	var rows []db.NotifyRule
+	err := n.db.Where("enable = ?", true).Order("priority, id").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]view.NotifyRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, transformNotifyRule(row))
+	}
+
+	n.mu.Lock()
+	n.rules = rules
+	n.loaded = true
+	n.mu.Unlock()
+	return rules, nil
+}
+
+func (n *notifyRule) reset() {
+	n.mu.Lock()
+	n.rules = nil
+	n.loaded = false
+	n.mu.Unlock()
+}
+
+func (n *notifyRule) check(id uint, param view.ReqCreateNotifyRule) (err error) {
+	var count int
+	err = n.db.Model(&db.NotifyRule{}).Where("name = ? and id != ?", param.Name, id).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		return fmt.Errorf("通知规则 %s 已存在", param.Name)
+	}
+
+	for _, channel := range param.Channels {
+		if !matchList(notice.Channels, channel) {
+			return fmt.Errorf("不支持的通知渠道 %s", channel)
+		}
+	}
+
+	if _, err = tag.Normalize(param.Tags); err != nil {
+		return
+	}
+
+	if (param.QuietStart == "") != (param.QuietEnd == "") {
+		return fmt.Errorf("免打扰开始时间和结束时间需要同时设置")
+	}
+	if param.QuietStart != "" {
+		if _, err = parseClock(param.QuietStart); err != nil {
+			return
+		}
+		if _, err = parseClock(param.QuietEnd); err != nil {
+			return
+		}
+	}
+
+	recipients := splitList(joinList(param.Recipients))
+	if len(recipients) > 0 {
+		var exists []string
+		err = n.db.Model(&db.User{}).Where("username in (?)", recipients).Pluck("username", &exists).Error
+		if err != nil {
+			return
+		}
+		for _, name := range recipients {
+			if !matchList(exists, name) {
+				return fmt.Errorf("用户 %s 不存在", name)
+			}
+		}
+	}
+	return
+}
+
+func (n *notifyRule) find(id uint) (item db.NotifyRule, err error) {
+	err = n.db.Where("id = ?", id).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrNotifyRuleNotFound
+		}
+	}
+	return
+}
+
+func fillNotifyRule(item *db.NotifyRule, param view.ReqCreateNotifyRule) {
+	item.Name = param.Name
+	item.Priority = param.Priority
+	item.Enable = param.Enable
+	item.EventTypes = joinList(param.EventTypes)
+	item.Apps = joinList(param.Apps)
+	tags, _ := tag.Normalize(param.Tags)
+	item.Tags = joinList(tags)
+	item.Envs = joinList(param.Envs)
+	item.MinSeverity = param.MinSeverity
+	item.Channels = joinList(param.Channels)
+	item.Recipients = joinList(param.Recipients)
+	item.QuietStart = param.QuietStart
+	item.QuietEnd = param.QuietEnd
+	item.SuppressSeconds = param.SuppressSeconds
+	item.Stop = param.Stop
+}
+
+func transformNotifyRule(item db.NotifyRule) view.NotifyRule {
+	return view.NotifyRule{
+		ID:              item.ID,
+		Name:            item.Name,
+		Priority:        item.Priority,
+		Enable:          item.Enable,
+		EventTypes:      splitList(item.EventTypes),
+		Apps:            splitList(item.Apps),
+		Tags:            splitList(item.Tags),
+		Envs:            splitList(item.Envs),
+		MinSeverity:     item.MinSeverity,
+		Channels:        splitList(item.Channels),
+		Recipients:      splitList(item.Recipients),
+		QuietStart:      item.QuietStart,
+		QuietEnd:        item.QuietEnd,
+		SuppressSeconds: item.SuppressSeconds,
+		Stop:            item.Stop,
+		CreatedAt:       item.CreatedAt,
+		UpdatedAt:       item.UpdatedAt,
+	}
+}
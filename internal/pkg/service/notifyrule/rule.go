@@ -0,0 +1,124 @@
+package notifyrule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+)
+
+// MatchRule 事件是否命中规则，规则中为空的条件匹配全部
+func MatchRule(rule view.NotifyRule, e notice.Event) bool {
+	if !rule.Enable {
+		return false
+	}
+	if !matchList(rule.EventTypes, e.Type) || !matchList(rule.Apps, e.App) || !matchList(rule.Envs, e.Env) {
+		return false
+	}
+	if !matchAny(rule.Tags, e.Tags) {
+		return false
+	}
+	return notice.SeverityLevel(e.Severity) >= notice.SeverityLevel(rule.MinSeverity)
+}
+
+// hasTagRule 是否有按应用标签匹配的规则，没有时无需查询事件应用的标签
+func hasTagRule(rules []view.NotifyRule) bool {
+	for _, rule := range rules {
+		if len(rule.Tags) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// InQuietHours now 是否处于免打扰时段 [start, end)，end 早于 start 时表示跨天，未设置时返回 false
+func InQuietHours(start, end string, now time.Time) bool {
+	if start == "" || end == "" {
+		return false
+	}
+
+	startMin, err := parseClock(start)
+	if err != nil {
+		return false
+	}
+	endMin, err := parseClock(end)
+	if err != nil {
+		return false
+	}
+
+	cur := now.Hour()*60 + now.Minute()
+	if startMin <= endMin {
+		return cur >= startMin && cur < endMin
+	}
+	return cur >= startMin || cur < endMin
+}
+
+// parseClock 解析 HH:MM，返回当天的分钟数
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("时间格式错误 %s，应为 HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// suppressKey 相同规则下类型、应用、环境、标题一致的事件视为重复事件
+func suppressKey(ruleID uint, e notice.Event) string {
+	subject := e.Subject
+	if subject == "" {
+		subject = e.Content
+	}
+	return fmt.Sprintf("%d|%s|%s|%s|%s", ruleID, e.Type, e.App, e.Env, subject)
+}
+
+func matchList(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny values 中任一值在 list 中时返回 true，list 为空时匹配全部
+func matchAny(list []string, values []string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if matchList(list, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(s string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func joinList(list []string) string {
+	seen := make(map[string]struct{}, len(list))
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if _, ok := seen[item]; ok || item == "" {
+			continue
+		}
+		seen[item] = struct{}{}
+		items = append(items, item)
+	}
+	return strings.Join(items, ",")
+}
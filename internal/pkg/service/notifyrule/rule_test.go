@@ -0,0 +1,106 @@
+package notifyrule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+)
+
+func TestMatchRule(t *testing.T) {
+	rule := view.NotifyRule{
+		Enable:      true,
+		EventTypes:  []string{notice.EventPipeline},
+		Envs:        []string{"prod"},
+		MinSeverity: notice.SeverityWarning,
+	}
+	tests := []struct {
+		e    notice.Event
+		want bool
+	}{
+		{notice.Event{Type: notice.EventPipeline, App: "a", Env: "prod", Severity: notice.SeverityError}, true},
+		{notice.Event{Type: notice.EventPipeline, App: "b", Env: "prod", Severity: notice.SeverityWarning}, true},
+		{notice.Event{Type: notice.EventPipeline, App: "a", Env: "prod", Severity: notice.SeverityInfo}, false},
+		{notice.Event{Type: notice.EventPipeline, App: "a", Env: "dev", Severity: notice.SeverityError}, false},
+		{notice.Event{Type: notice.EventAlert, App: "a", Env: "prod", Severity: notice.SeverityError}, false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchRule(rule, tt.e); got != tt.want {
+			t.Errorf("MatchRule(%+v) = %v, want %v", tt.e, got, tt.want)
+		}
+	}
+
+	rule.Enable = false
+	if MatchRule(rule, tests[0].e) {
+		t.Error("disabled rule should not match")
+	}
+}
+
+func TestMatchRuleTags(t *testing.T) {
+	rule := view.NotifyRule{
+		Enable: true,
+		Tags:   []string{"payment", "core"},
+	}
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{[]string{"payment"}, true},
+		{[]string{"web", "core"}, true},
+		{[]string{"web"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		e := notice.Event{Type: notice.EventAlert, App: "a", Severity: notice.SeverityError, Tags: tt.tags}
+		if got := MatchRule(rule, e); got != tt.want {
+			t.Errorf("MatchRule(tags=%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+
+	if !hasTagRule([]view.NotifyRule{{}, rule}) || hasTagRule([]view.NotifyRule{{}}) {
+		t.Error("hasTagRule mismatch")
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	at := func(clock string) time.Time {
+		t, _ := time.Parse("15:04", clock)
+		return t
+	}
+	tests := []struct {
+		start, end, now string
+		want            bool
+	}{
+		{"", "", "03:00", false},
+		{"12:00", "14:00", "12:00", true},
+		{"12:00", "14:00", "14:00", false},
+		{"22:00", "08:00", "23:30", true},
+		{"22:00", "08:00", "07:59", true},
+		{"22:00", "08:00", "08:00", false},
+		{"22:00", "08:00", "12:00", false},
+		{"bad", "08:00", "03:00", false},
+	}
+
+	for _, tt := range tests {
+		if got := InQuietHours(tt.start, tt.end, at(tt.now)); got != tt.want {
+			t.Errorf("InQuietHours(%q, %q, %s) = %v, want %v", tt.start, tt.end, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestJoinSplitList(t *testing.T) {
+	s := joinList([]string{"slack", " email ", "", "slack"})
+	if s != "slack,email" {
+		t.Errorf("joinList = %q", s)
+	}
+	if got := splitList(s); !reflect.DeepEqual(got, []string{"slack", "email"}) {
+		t.Errorf("splitList = %v", got)
+	}
+	if got := splitList(""); len(got) != 0 {
+		t.Errorf("splitList empty = %v", got)
+	}
+}
@@ -0,0 +1,255 @@
+package notifytemplate
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// NotifyTemplate 通知消息模板
+	NotifyTemplate *notifyTemplate
+
+	ErrNotifyTemplateNotFound = fmt.Errorf("通知模板不存在")
+)
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	notifyTemplate struct {
+		db *gorm.DB
+
+		mu        sync.RWMutex
+		templates map[string]db.NotifyTemplate
+	}
+)
+
+// Init 初始化并注册为 notice 的模板来源
+func Init(o Option) {
+	NotifyTemplate = &notifyTemplate{
+		db: o.DB,
+	}
+	notice.SetTemplateProvider(NotifyTemplate.Lookup)
+}
+
+// List 模板列表
+func (n *notifyTemplate) List(param view.ReqListNotifyTemplate) (list []view.NotifyTemplate, err error) {
+	var rows []db.NotifyTemplate
+	query := n.db.Model(&db.NotifyTemplate{})
+	if param.EventType != "" {
+		query = query.Where("event_type = ?", param.EventType)
+	}
+	err = query.Order("event_type, channel").Find(&rows).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.NotifyTemplate, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, view.NotifyTemplate{
+			ID:        row.ID,
+			EventType: row.EventType,
+			Channel:   row.Channel,
+			Subject:   row.Subject,
+			Body:      row.Body,
+			UpdatedAt: row.UpdatedAt,
+		})
+	}
+	return
+}
+
+// Save 创建或更新事件类型、渠道对应的模板
+func (n *notifyTemplate) Save(param view.ReqSaveNotifyTemplate) (err error) {
+	err = checkTemplate(param)
+	if err != nil {
+		return
+	}
+
+	var item db.NotifyTemplate
+	err = n.db.Where("event_type = ? and channel = ?", param.EventType, param.Channel).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	item.EventType = param.EventType
+	item.Channel = param.Channel
+	item.Subject = param.Subject
+	item.Body = param.Body
+	err = n.db.Save(&item).Error
+	if err != nil {
+		return
+	}
+
+	n.reset()
+	return
+}
+
+// Delete 删除模板，对应的事件恢复使用默认消息
+func (n *notifyTemplate) Delete(param view.ReqDeleteNotifyTemplate) (err error) {
+	var item db.NotifyTemplate
+	err = n.db.Where("id = ?", param.ID).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrNotifyTemplateNotFound
+		}
+		return
+	}
+
+	err = n.db.Unscoped().Delete(&item).Error
+	if err != nil {
+		return
+	}
+
+	n.reset()
+	return
+}
+
+// Preview 使用示例事件渲染模板
+func (n *notifyTemplate) Preview(param view.ReqPreviewNotifyTemplate) (resp view.RespPreviewNotifyTemplate, err error) {
+	err = checkTemplate(param.ReqSaveNotifyTemplate)
+	if err != nil {
+		return
+	}
+
+	resp.Subject, resp.Content, err = notice.RenderTemplate(param.Subject, param.Body, sampleEvent(param))
+	return
+}
+
+// Test 渲染模板后发送测试消息到指定渠道，邮件只发送给当前用户
+func (n *notifyTemplate) Test(u *db.User, param view.ReqPreviewNotifyTemplate) (err error) {
+	if param.Channel == "" {
+		return fmt.Errorf("请选择测试发送的渠道")
+	}
+
+	resp, err := n.Preview(param)
+	if err != nil {
+		return
+	}
+
+	e := sampleEvent(param)
+	e.Subject = resp.Subject
+	e.Content = resp.Content
+	e.Mentions = []string{u.Username}
+	if param.Channel == notice.ChannelEmail {
+		e.Emails, err = user.User.NotifyEmails([]int{u.Uid})
+		if err != nil {
+			return
+		}
+		if len(e.Emails) == 0 {
+			return fmt.Errorf("当前用户未设置邮箱")
+		}
+	}
+
+	err = notice.SendChannel(param.Channel, e)
+	if err == notice.ErrChannelDisabled {
+		return fmt.Errorf("通知渠道 %s 未开启", param.Channel)
+	}
+	return
+}
+
+// Lookup 查找事件类型、渠道对应的模板，渠道没有单独配置时使用该事件类型的通用模板
+func (n *notifyTemplate) Lookup(eventType, channel string) (subject, body string, ok bool) {
+	templates, err := n.load()
+	if err != nil {
+		xlog.Error("notifytemplate.Lookup load templates failed", xlog.String("event", eventType), xlog.String("err", err.Error()))
+		return
+	}
+
+	item, ok := templates[templateKey(eventType, channel)]
+	if !ok {
+		item, ok = templates[templateKey(eventType, "")]
+	}
+	return item.Subject, item.Body, ok
+}
+
+func (n *notifyTemplate) load() (map[string]db.NotifyTemplate, error) {
+	n.mu.RLock()
+	templates := n.templates
+	n.mu.RUnlock()
+	if templates != nil {
+		return templates, nil
+	}
+
+	var rows []db.NotifyTemplate
+	err := n.db.Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	templates = make(map[string]db.NotifyTemplate, len(rows))
+	for _, row := range rows {
+		templates[templateKey(row.EventType, row.Channel)] = row
+	}
+
+	n.mu.Lock()
+	n.templates = templates
+	n.mu.Unlock()
+	return templates, nil
+}
+
+func (n *notifyTemplate) reset() {
+	n.mu.Lock()
+	n.templates = nil
+	n.mu.Unlock()
+}
+
+func checkTemplate(param view.ReqSaveNotifyTemplate) (err error) {
+	if param.Channel != "" {
+		valid := false
+		for _, channel := range notice.Channels {
+			if channel == param.Channel {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("不支持的通知渠道 %s", param.Channel)
+		}
+	}
+
+	if param.Subject == "" && param.Body == "" {
+		return fmt.Errorf("标题和正文模板不能同时为空")
+	}
+
+	// 使用示例事件校验模板语法和字段
+	_, _, err = notice.RenderTemplate(param.Subject, param.Body, notice.Event{Time: time.Now()})
+	return
+}
+
+func sampleEvent(param view.ReqPreviewNotifyTemplate) notice.Event {
+	e := notice.Event{
+		Type:     param.EventType,
+		App:      param.App,
+		Env:      param.Env,
+		Severity: param.Severity,
+		Content:  param.Content,
+		Time:     time.Now(),
+	}
+	if e.App == "" {
+		e.App = "juno-demo"
+	}
+	if e.Env == "" {
+		e.Env = "dev"
+	}
+	if e.Severity == "" {
+		e.Severity = notice.SeverityInfo
+	}
+	if e.Content == "" {
+		e.Content = fmt.Sprintf("[Juno] 应用 %s 的 %s 事件示例消息", e.App, e.Type)
+	}
+	e.Subject = fmt.Sprintf("[Juno] %s 事件通知: %s", e.Type, e.App)
+	return e
+}
+
+func templateKey(eventType, channel string) string {
+	return eventType + "|" + channel
+}
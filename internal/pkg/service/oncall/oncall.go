@@ -0,0 +1,459 @@
+package oncall
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// OnCall 值班轮换与告警升级
+	OnCall *onCall
+
+	ErrRotationNotFound = fmt.Errorf("值班轮换不存在")
+	ErrIncidentNotFound = fmt.Errorf("告警不存在")
+)
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	onCall struct {
+		db *gorm.DB
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	OnCall = &onCall{
+		db: o.DB,
+	}
+}
+
+// Rotations 全部值班轮换及当前值班人员
+func (o *onCall) Rotations() (list []view.OnCallRotation, err error) {
+	var rows []db.OnCallRotation
+	err = o.db.Order("team_id").Find(&rows).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.OnCallRotation, 0, len(rows))
+	for _, row := range rows {
+		var item view.OnCallRotation
+		item, err = o.transformRotation(row, time.Now())
+		if err != nil {
+			return
+		}
+		list = append(list, item)
+	}
+	return
+}
+
+// Rotation 团队值班轮换及当前值班人员
+func (o *onCall) Rotation(param view.ReqOnCallRotation) (resp view.OnCallRotation, err error) {
+	row, err := o.findRotation(param.TeamID)
+	if err != nil {
+		return
+	}
+	return o.transformRotation(row, time.Now())
+}
+
+// SetRotation 创建或更新团队值班轮换
+func (o *onCall) SetRotation(param view.ReqSetOnCallRotation) (err error) {
+	if param.TeamID > 0 {
+		var count int
+		err = o.db.Model(&db.Team{}).Where("id = ?", param.TeamID).Count(&count).Error
+		if err != nil {
+			return
+		}
+		if count == 0 {
+			return fmt.Errorf("团队不存在")
+		}
+	}
+
+	seen := make(map[int]struct{}, len(param.Uids))
+	for _, uid := range param.Uids {
+		if _, ok := seen[uid]; ok {
+			return fmt.Errorf("值班人员重复: %d", uid)
+		}
+		seen[uid] = struct{}{}
+	}
+	var count int
+	err = o.db.Model(&db.User{}).Where("uid in (?)", param.Uids).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count != len(param.Uids) {
+		return fmt.Errorf("值班人员不存在")
+	}
+
+	if param.ShiftHours == 0 {
+		param.ShiftHours = defaultShiftHours
+	}
+	if param.EscalateMinutes == 0 {
+		param.EscalateMinutes = defaultEscalateMinutes
+	}
+	if param.StartAt == 0 {
+		param.StartAt = time.Now().Unix()
+	}
+
+	var item db.OnCallRotation
+	err = o.db.Where("team_id = ?", param.TeamID).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	item.TeamID = param.TeamID
+	item.Users = joinUids(param.Uids)
+	item.ShiftHours = param.ShiftHours
+	item.StartAt = param.StartAt
+	item.EscalateMinutes = param.EscalateMinutes
+	item.EscalateToOwners = param.EscalateToOwners
+	return o.db.Save(&item).Error
+}
+
+// DeleteRotation 删除团队值班轮换
+func (o *onCall) DeleteRotation(param view.ReqDeleteOnCallRotation) (err error) {
+	item, err := o.findRotation(param.TeamID)
+	if err != nil {
+		return
+	}
+	return o.db.Unscoped().Delete(&item).Error
+}
+
+// Page 触发值班告警：error 级别的告警事件通知应用所属团队的主班，团队未设置值班时使用平台值班。
+// 相同告警未恢复前只累加次数，不重复通知
+func (o *onCall) Page(e notice.Event) {
+	if e.Type != notice.EventAlert || e.Severity != notice.SeverityError {
+		return
+	}
+
+	err := o.page(e)
+	if err != nil {
+		xlog.Error("oncall.Page failed", xlog.String("app", e.App), xlog.String("subject", e.Subject), xlog.String("err", err.Error()))
+	}
+}
+
+func (o *onCall) page(e notice.Event) (err error) {
+	var teamID uint
+	if e.App != "" {
+		var app db.AppInfo
+		err = o.db.Select("team_id").Where("app_name = ?", e.App).First(&app).Error
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			return
+		}
+		teamID = app.TeamID
+	}
+
+	rotation, err := o.findRotation(teamID)
+	if err == ErrRotationNotFound && teamID > 0 {
+		rotation, err = o.findRotation(0)
+	}
+	if err == ErrRotationNotFound {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+
+	var incident db.Incident
+	err = o.db.Where("team_id = ? and app = ? and type = ? and subject = ? and status != ?",
+		rotation.TeamID, e.App, e.Type, e.Subject, db.IncidentStatusResolved).First(&incident).Error
+	if err == nil {
+		return o.db.Model(&incident).Updates(map[string]interface{}{
+			"fire_count": gorm.Expr("fire_count + 1"),
+			"content":    e.Content,
+		}).Error
+	}
+	if !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	incident = db.Incident{
+		TeamID:   rotation.TeamID,
+		App:      e.App,
+		Env:      e.Env,
+		Type:     e.Type,
+		Severity: e.Severity,
+		Subject:  e.Subject,
+		Content:  e.Content,
+		Count:    1,
+		Status:   db.IncidentStatusFiring,
+		Level:    levelPrimary,
+	}
+	err = o.db.Create(&incident).Error
+	if err != nil {
+		return
+	}
+
+	return o.notifyLevel(&incident, rotation)
+}
+
+// EscalateTick 升级超时未确认的告警，由定时任务调用
+func (o *onCall) EscalateTick() (err error) {
+	var incidents []db.Incident
+	err = o.db.Where("status = ? and next_escalate_at > 0 and next_escalate_at <= ?", db.IncidentStatusFiring, time.Now().Unix()).
+		Find(&incidents).Error
+	if err != nil {
+		return
+	}
+
+	for i := range incidents {
+		incident := &incidents[i]
+		rotation, err := o.findRotation(incident.TeamID)
+		if err != nil {
+			xlog.Error("oncall.EscalateTick load rotation failed", xlog.Uint("incident", incident.ID), xlog.String("err", err.Error()))
+			o.db.Model(incident).UpdateColumn("next_escalate_at", 0)
+			continue
+		}
+
+		incident.Level = NextLevel(incident.Level, len(splitUids(rotation.Users)), rotation.EscalateToOwners, rotation.TeamID)
+		err = o.notifyLevel(incident, rotation)
+		if err != nil {
+			xlog.Error("oncall.EscalateTick escalate failed", xlog.Uint("incident", incident.ID), xlog.String("err", err.Error()))
+		}
+	}
+	return nil
+}
+
+// Ack 确认告警，停止继续升级
+func (o *onCall) Ack(u *db.User, param view.ReqAckIncident) (err error) {
+	incident, err := o.findIncident(param.ID)
+	if err != nil {
+		return
+	}
+	if incident.Status != db.IncidentStatusFiring {
+		return fmt.Errorf("告警已被确认或已恢复")
+	}
+
+	return o.db.Model(&incident).Updates(map[string]interface{}{
+		"status":           db.IncidentStatusAcked,
+		"ack_uid":          u.Uid,
+		"ack_time":         time.Now().Unix(),
+		"next_escalate_at": 0,
+	}).Error
+}
+
+// Resolve 标记告警已恢复
+func (o *onCall) Resolve(u *db.User, param view.ReqAckIncident) (err error) {
+	incident, err := o.findIncident(param.ID)
+	if err != nil {
+		return
+	}
+	if incident.Status == db.IncidentStatusResolved {
+		return fmt.Errorf("告警已恢复")
+	}
+
+	now := time.Now().Unix()
+	updates := map[string]interface{}{
+		"status":           db.IncidentStatusResolved,
+		"resolve_time":     now,
+		"next_escalate_at": 0,
+	}
+	if incident.AckUid == 0 {
+		updates["ack_uid"] = u.Uid
+		updates["ack_time"] = now
+	}
+	return o.db.Model(&incident).Updates(updates).Error
+}
+
+// ListIncident 告警列表
+func (o *onCall) ListIncident(param view.ReqListIncident) (list []view.Incident, page *view.Pagination, err error) {
+	var rows []db.Incident
+
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := o.db.Model(&db.Incident{})
+	if param.TeamID > 0 {
+		query = query.Where("team_id = ?", param.TeamID)
+	}
+	if param.App != "" {
+		query = query.Where("app = ?", param.App)
+	}
+	if param.Status != "" {
+		query = query.Where("status = ?", param.Status)
+	}
+
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&rows).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.Incident, 0, len(rows))
+	for _, row := range rows {
+		item := view.Incident{
+			ID:             row.ID,
+			TeamID:         row.TeamID,
+			App:            row.App,
+			Env:            row.Env,
+			Type:           row.Type,
+			Severity:       row.Severity,
+			Subject:        row.Subject,
+			Content:        row.Content,
+			Count:          row.Count,
+			Status:         row.Status,
+			Level:          row.Level,
+			NextEscalateAt: row.NextEscalateAt,
+			AckTime:        row.AckTime,
+			ResolveTime:    row.ResolveTime,
+			CreatedAt:      row.CreatedAt,
+		}
+		if row.AckUid > 0 {
+			item.AckUser = user.User.GetNameByUID(row.AckUid)
+		}
+		list = append(list, item)
+	}
+	return
+}
+
+// notifyLevel 通知告警当前级别的负责人并设置下次升级时间，level 为 -1 时不再升级
+func (o *onCall) notifyLevel(incident *db.Incident, rotation db.OnCallRotation) (err error) {
+	if incident.Level < 0 {
+		return o.db.Model(incident).UpdateColumn("next_escalate_at", 0).Error
+	}
+
+	uids, err := o.levelUids(incident.Level, rotation)
+	if err != nil {
+		return
+	}
+
+	var users []db.User
+	if len(uids) > 0 {
+		err = o.db.Select("uid, username").Where("uid in (?)", uids).Find(&users).Error
+		if err != nil {
+			return
+		}
+	}
+	mentions := make([]string, 0, len(users))
+	for _, u := range users {
+		mentions = append(mentions, u.Username)
+	}
+	emails, err := user.User.NotifyEmails(uids)
+	if err != nil {
+		return
+	}
+
+	var nextEscalateAt int64
+	if NextLevel(incident.Level, len(splitUids(rotation.Users)), rotation.EscalateToOwners, rotation.TeamID) >= 0 {
+		nextEscalateAt = time.Now().Add(time.Duration(rotation.EscalateMinutes) * time.Minute).Unix()
+	}
+
+	err = o.db.Model(incident).Updates(map[string]interface{}{
+		"level":            incident.Level,
+		"notified_uids":    joinUids(uids),
+		"next_escalate_at": nextEscalateAt,
+	}).Error
+	if err != nil {
+		return
+	}
+
+	content := fmt.Sprintf("%s\n\n[值班] 告警ID: %d，通知%s: %v", incident.Content, incident.ID, levelNames[incident.Level], mentions)
+	if nextEscalateAt > 0 {
+		content += fmt.Sprintf("\n%d 分钟内未确认将继续升级，请在 Juno 告警中心确认", rotation.EscalateMinutes)
+	}
+	notice.Dispatch(notice.Event{
+		Type:     incident.Type,
+		App:      incident.App,
+		Env:      incident.Env,
+		Severity: incident.Severity,
+		Subject:  "[值班] " + incident.Subject,
+		Content:  content,
+		Mentions: mentions,
+		Emails:   emails,
+	})
+	return
+}
+
+// levelUids 告警级别对应的通知对象
+func (o *onCall) levelUids(level int, rotation db.OnCallRotation) (uids []int, err error) {
+	users := splitUids(rotation.Users)
+	index, _ := ShiftIndex(rotation.StartAt, time.Now().Unix(), rotation.ShiftHours, len(users))
+
+	switch level {
+	case levelPrimary:
+		if len(users) > 0 {
+			uids = []int{users[index]}
+		}
+	case levelSecondary:
+		if len(users) > 1 {
+			uids = []int{users[(index+1)%len(users)]}
+		}
+	case levelOwner:
+		err = o.db.Model(&db.TeamMember{}).Where("team_id = ? and role = ?", rotation.TeamID, db.TeamMemberRoleOwner).
+			Pluck("uid", &uids).Error
+	}
+	return
+}
+
+func (o *onCall) transformRotation(row db.OnCallRotation, now time.Time) (resp view.OnCallRotation, err error) {
+	resp = view.OnCallRotation{
+		TeamID:           row.TeamID,
+		Users:            make([]view.OnCallUser, 0),
+		ShiftHours:       row.ShiftHours,
+		StartAt:          row.StartAt,
+		EscalateMinutes:  row.EscalateMinutes,
+		EscalateToOwners: row.EscalateToOwners,
+	}
+	if row.TeamID > 0 {
+		var item db.Team
+		err = o.db.Select("name").Where("id = ?", row.TeamID).First(&item).Error
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			return
+		}
+		resp.TeamName = item.Name
+	}
+
+	uids := splitUids(row.Users)
+	var users []db.User
+	if len(uids) > 0 {
+		err = o.db.Where("uid in (?)", uids).Find(&users).Error
+		if err != nil {
+			return
+		}
+	}
+	byUid := make(map[int]db.User, len(users))
+	for _, u := range users {
+		byUid[u.Uid] = u
+	}
+	for _, uid := range uids {
+		u := byUid[uid]
+		resp.Users = append(resp.Users, view.OnCallUser{Uid: uid, Username: u.Username, Nickname: u.Nickname})
+	}
+
+	if len(resp.Users) > 0 {
+		index, shiftEndAt := ShiftIndex(row.StartAt, now.Unix(), row.ShiftHours, len(resp.Users))
+		resp.Primary = &resp.Users[index]
+		resp.ShiftEndAt = shiftEndAt
+		if len(resp.Users) > 1 {
+			resp.Secondary = &resp.Users[(index+1)%len(resp.Users)]
+		}
+	}
+	return
+}
+
+func (o *onCall) findRotation(teamID uint) (item db.OnCallRotation, err error) {
+	err = o.db.Where("team_id = ?", teamID).First(&item).Error
+	if gorm.IsRecordNotFoundError(err) {
+		err = ErrRotationNotFound
+	}
+	return
+}
+
+func (o *onCall) findIncident(id uint) (item db.Incident, err error) {
+	err = o.db.Where("id = ?", id).First(&item).Error
+	if gorm.IsRecordNotFoundError(err) {
+		err = ErrIncidentNotFound
+	}
+	return
+}
@@ -0,0 +1,75 @@
+package oncall
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultShiftHours      = 24 * 7
+	defaultEscalateMinutes = 15
+)
+
+// 告警升级级别
+const (
+	levelPrimary = iota
+	levelSecondary
+	levelOwner
+)
+
+var levelNames = []string{"主班", "备班", "团队 owner"}
+
+// ShiftIndex 计算 now 时刻当班人员在轮换中的下标以及本班次结束时间，startAt 之前视为第一个班次
+func ShiftIndex(startAt, now int64, shiftHours, n int) (index int, shiftEndAt int64) {
+	if n <= 0 {
+		return 0, 0
+	}
+	if shiftHours <= 0 {
+		shiftHours = defaultShiftHours
+	}
+
+	shift := int64(shiftHours) * 3600
+	if now < startAt {
+		return 0, startAt + shift
+	}
+
+	shifts := (now - startAt) / shift
+	return int(shifts % int64(n)), startAt + (shifts+1)*shift
+}
+
+// NextLevel 当前级别未确认时升级到的下一个级别，没有可升级的级别时返回 -1。
+// 只有一名值班人员时跳过备班，未开启通知 owner 或平台值班时不升级到 owner
+func NextLevel(level, users int, escalateToOwners bool, teamID uint) int {
+	for next := level + 1; next <= levelOwner; next++ {
+		switch next {
+		case levelSecondary:
+			if users > 1 {
+				return next
+			}
+		case levelOwner:
+			if escalateToOwners && teamID > 0 {
+				return next
+			}
+		}
+	}
+	return -1
+}
+
+func joinUids(uids []int) string {
+	items := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		items = append(items, strconv.Itoa(uid))
+	}
+	return strings.Join(items, ",")
+}
+
+func splitUids(s string) []int {
+	uids := make([]int, 0)
+	for _, item := range strings.Split(s, ",") {
+		uid, err := strconv.Atoi(strings.TrimSpace(item))
+		if err == nil && uid > 0 {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
@@ -0,0 +1,66 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShiftIndex(t *testing.T) {
+	const day = 24 * 3600
+	start := int64(1600000000)
+	tests := []struct {
+		now        int64
+		index      int
+		shiftEndAt int64
+	}{
+		{start - 10, 0, start + day},
+		{start, 0, start + day},
+		{start + day - 1, 0, start + day},
+		{start + day, 1, start + 2*day},
+		{start + 2*day + 5, 2, start + 3*day},
+		{start + 3*day, 0, start + 4*day},
+	}
+
+	for _, tt := range tests {
+		index, shiftEndAt := ShiftIndex(start, tt.now, 24, 3)
+		if index != tt.index || shiftEndAt != tt.shiftEndAt {
+			t.Errorf("ShiftIndex(now=%d) = %d, %d, want %d, %d", tt.now, index, shiftEndAt, tt.index, tt.shiftEndAt)
+		}
+	}
+
+	if index, _ := ShiftIndex(start, start+8*day, 0, 2); index != 1 {
+		t.Errorf("default weekly shift index = %d, want 1", index)
+	}
+}
+
+func TestNextLevel(t *testing.T) {
+	tests := []struct {
+		level, users int
+		owners       bool
+		teamID       uint
+		want         int
+	}{
+		{levelPrimary, 2, false, 1, levelSecondary},
+		{levelPrimary, 1, true, 1, levelOwner},
+		{levelPrimary, 1, false, 1, -1},
+		{levelSecondary, 2, true, 1, levelOwner},
+		{levelSecondary, 2, true, 0, -1},
+		{levelOwner, 2, true, 1, -1},
+	}
+
+	for _, tt := range tests {
+		if got := NextLevel(tt.level, tt.users, tt.owners, tt.teamID); got != tt.want {
+			t.Errorf("NextLevel(%d, %d, %v, %d) = %d, want %d", tt.level, tt.users, tt.owners, tt.teamID, got, tt.want)
+		}
+	}
+}
+
+func TestUids(t *testing.T) {
+	s := joinUids([]int{3, 1, 2})
+	if s != "3,1,2" {
+		t.Errorf("joinUids = %q", s)
+	}
+	if got := splitUids(s + ",,x"); !reflect.DeepEqual(got, []int{3, 1, 2}) {
+		t.Errorf("splitUids = %v", got)
+	}
+}
@@ -14,4 +14,6 @@ func Init(o Option) {
 	initAppGroup(o.DB)
 	initUser(o.DB)
 	initPermission(o)
+	initPolicy(o.DB)
+	initZoneScope(o.DB)
 }
@@ -0,0 +1,322 @@
+package permission
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// Policy casbin 策略和用户组绑定管理
+	Policy *policy
+
+	ErrPolicyNotFound   = fmt.Errorf("策略不存在")
+	ErrBindingNotFound  = fmt.Errorf("用户组绑定不存在")
+	ErrCasbinNotEnabled = fmt.Errorf("未开启 casbin 权限校验")
+)
+
+type policy struct {
+	db *gorm.DB
+}
+
+func initPolicy(db *gorm.DB) {
+	Policy = &policy{
+		db: db,
+	}
+}
+
+// List 策略列表
+func (p *policy) List(param view.ReqListPolicy) (list []db.CasbinPolicyAuth, page *view.Pagination, err error) {
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := p.db.Model(&db.CasbinPolicyAuth{})
+	if param.Sub != "" {
+		query = query.Where("sub = ?", param.Sub)
+	}
+	if param.Obj != "" {
+		query = query.Where("obj like ?", "%"+param.Obj+"%")
+	}
+	if param.Type != "" {
+		query = query.Where("type = ?", param.Type)
+	}
+
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&list).Error
+	return
+}
+
+// Create 新建策略
+func (p *policy) Create(param view.ReqCreatePolicy) (err error) {
+	err = p.check(0, param)
+	if err != nil {
+		return
+	}
+
+	err = p.db.Create(&db.CasbinPolicyAuth{
+		Sub:  param.Sub,
+		Obj:  param.Obj,
+		Act:  param.Act,
+		Type: db.CasbinPolicyType(param.Type),
+	}).Error
+	if err != nil {
+		return
+	}
+
+	return casbin.Casbin.LoadPolicy()
+}
+
+// Update 更新策略
+func (p *policy) Update(param view.ReqUpdatePolicy) (err error) {
+	var item db.CasbinPolicyAuth
+	err = p.db.Where("id = ?", param.ID).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrPolicyNotFound
+		}
+		return
+	}
+
+	err = p.check(item.ID, param.ReqCreatePolicy)
+	if err != nil {
+		return
+	}
+
+	err = p.db.Model(&item).Updates(map[string]interface{}{
+		"sub":  param.Sub,
+		"obj":  param.Obj,
+		"act":  param.Act,
+		"type": param.Type,
+	}).Error
+	if err != nil {
+		return
+	}
+
+	return casbin.Casbin.LoadPolicy()
+}
+
+// Delete 删除策略
+func (p *policy) Delete(param view.ReqDeletePolicy) (err error) {
+	query := p.db.Where("id = ?", param.ID).Delete(&db.CasbinPolicyAuth{})
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		return ErrPolicyNotFound
+	}
+
+	return casbin.Casbin.LoadPolicy()
+}
+
+// ListBinding 用户组绑定列表
+func (p *policy) ListBinding(param view.ReqListRoleBinding) (list []view.RoleBinding, page *view.Pagination, err error) {
+	var groups []db.CasbinPolicyGroup
+
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := p.db.Model(&db.CasbinPolicyGroup{}).Where("type = ?", db.CasbinGroupTypeUser)
+	if param.Uid != 0 {
+		query = query.Where("uid = ?", param.Uid)
+	}
+	if param.GroupName != "" {
+		query = query.Where("group_name = ?", param.GroupName)
+	}
+
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&groups).Error
+	if err != nil {
+		return
+	}
+
+	uids := make([]int, 0, len(groups))
+	for _, item := range groups {
+		uids = append(uids, item.Uid)
+	}
+
+	var users []db.User
+	if len(uids) > 0 {
+		err = p.db.Select("uid, username").Where("uid in (?)", uids).Find(&users).Error
+		if err != nil {
+			return
+		}
+	}
+	usernames := make(map[int]string, len(users))
+	for _, u := range users {
+		usernames[u.Uid] = u.Username
+	}
+
+	list = make([]view.RoleBinding, 0, len(groups))
+	for _, item := range groups {
+		list = append(list, view.RoleBinding{
+			ID:        item.ID,
+			Uid:       item.Uid,
+			Username:  usernames[item.Uid],
+			GroupName: item.GroupName,
+		})
+	}
+	return
+}
+
+// CreateBinding 将用户加入用户组
+func (p *policy) CreateBinding(param view.ReqRoleBinding) (err error) {
+	err = p.checkUser(param.Uid)
+	if err != nil {
+		return
+	}
+
+	var count int
+	err = p.db.Model(&db.CasbinPolicyGroup{}).
+		Where("uid = ? and group_name = ? and type = ?", param.Uid, param.GroupName, db.CasbinGroupTypeUser).
+		Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		return fmt.Errorf("用户已在用户组 %s 中", param.GroupName)
+	}
+
+	err = p.db.Create(&db.CasbinPolicyGroup{
+		GroupName: param.GroupName,
+		Uid:       param.Uid,
+		Type:      db.CasbinGroupTypeUser,
+	}).Error
+	if err != nil {
+		return
+	}
+
+	return casbin.Casbin.LoadPolicy()
+}
+
+// DeleteBinding 将用户移出用户组
+func (p *policy) DeleteBinding(param view.ReqRoleBinding) (err error) {
+	query := p.db.Where("uid = ? and group_name = ? and type = ?", param.Uid, param.GroupName, db.CasbinGroupTypeUser).
+		Delete(&db.CasbinPolicyGroup{})
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		return ErrBindingNotFound
+	}
+
+	return casbin.Casbin.LoadPolicy()
+}
+
+// WhoCan 查询拥有某个权限的用户，只读取当前已加载的策略
+func (p *policy) WhoCan(param view.ReqWhoCan) (list []view.WhoCanItem, err error) {
+	if !cfg.Cfg.Casbin.Enable {
+		return nil, ErrCasbinNotEnabled
+	}
+
+	var users []db.User
+	err = p.db.Select("uid, username, nickname").Order("uid").Find(&users).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.WhoCanItem, 0)
+	for _, u := range users {
+		ok, err := casbin.Casbin.CheckPermission(strconv.Itoa(u.Uid), param.Obj, param.Act, db.CasbinPolicyType(param.Type))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		list = append(list, view.WhoCanItem{
+			Uid:      u.Uid,
+			Username: u.Username,
+			Nickname: u.Nickname,
+		})
+	}
+	return
+}
+
+// Check 查询用户是否拥有某个权限
+func (p *policy) Check(param view.ReqCheckPolicy) (bool, error) {
+	if !cfg.Cfg.Casbin.Enable {
+		return false, ErrCasbinNotEnabled
+	}
+
+	return casbin.Casbin.CheckPermission(strconv.Itoa(param.Uid), param.Obj, param.Act, db.CasbinPolicyType(param.Type))
+}
+
+// check 校验策略内容，id 为当前更新的策略，用于排除重复校验
+func (p *policy) check(id uint, param view.ReqCreatePolicy) (err error) {
+	err = p.checkSub(param.Sub)
+	if err != nil {
+		return
+	}
+
+	switch db.CasbinPolicyType(param.Type) {
+	case db.CasbinPolicyTypeApp:
+		if !casbin.Casbin.CheckAppPermissionKeyValid(param.Act) {
+			return ErrInvalidAppPerm
+		}
+		if !strings.Contains(param.Obj, ":") {
+			return fmt.Errorf("应用权限对象格式应为 appname:env")
+		}
+	case db.CasbinPolicyTypeAPI:
+		if !casbin.Casbin.CheckAPIValid(param.Obj, param.Act) {
+			return ErrInvalidAPIPerm
+		}
+	case db.CasbinPolicyTypeMenu:
+		if param.Act != string(casbin.ActionReadMenu) || !casbin.Casbin.CheckMenuValid(param.Obj) {
+			return fmt.Errorf("无效的菜单权限")
+		}
+	case db.CasbinPolicyTypeMonitor:
+		if param.Act != db.MonitorPermWrite {
+			return fmt.Errorf("无效的监控权限")
+		}
+	}
+
+	var count int
+	err = p.db.Model(&db.CasbinPolicyAuth{}).
+		Where("sub = ? and obj = ? and act = ? and type = ? and id != ?", param.Sub, param.Obj, param.Act, param.Type, id).
+		Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		return fmt.Errorf("策略已存在")
+	}
+	return nil
+}
+
+// checkSub 策略主体只能是用户组或用户ID，团队策略由团队配置生成
+func (p *policy) checkSub(sub string) error {
+	userGroupPrefix := casbin.CasbinGroupKey(db.CasbinGroupTypeUser, "")
+	if strings.HasPrefix(sub, userGroupPrefix) {
+		if len(sub) == len(userGroupPrefix) {
+			return fmt.Errorf("用户组名不能为空")
+		}
+		return nil
+	}
+
+	uid, err := strconv.Atoi(sub)
+	if err != nil {
+		return fmt.Errorf("策略主体应为 %s<用户组> 或用户ID", userGroupPrefix)
+	}
+	return p.checkUser(uid)
+}
+
+func (p *policy) checkUser(uid int) error {
+	var count int
+	err := p.db.Model(&db.User{}).Where("uid = ?", uid).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("用户不存在")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package permission
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/douyu/juno/internal/pkg/service/casbin"
 	"github.com/douyu/juno/pkg/model/db"
@@ -388,6 +389,43 @@ func (u *userGroup) GetAPIPerm(param view.ReqGetGroupAPIPerm) (resp view.RespGet
 	return
 }
 
+// AppPolicies 用户组的应用权限，按应用、环境聚合
+func (u *userGroup) AppPolicies(param view.ReqGetGroupAppPolicy) (list []view.GroupAppPolicy, err error) {
+	var policies []db.CasbinPolicyAuth
+
+	group, err := u.Find(param.GroupName)
+	if err != nil {
+		return
+	}
+
+	sub := casbin.CasbinGroupKey(db.CasbinGroupTypeUser, group.GroupName)
+	err = u.db.Where("type = ? and sub = ?", db.CasbinPolicyTypeApp, sub).Order("obj").Find(&policies).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.GroupAppPolicy, 0)
+	index := make(map[string]int)
+	for _, policy := range policies {
+		i, ok := index[policy.Obj]
+		if !ok {
+			sep := strings.LastIndex(policy.Obj, ":")
+			if sep < 0 {
+				continue
+			}
+			list = append(list, view.GroupAppPolicy{
+				AppName: policy.Obj[:sep],
+				Env:     policy.Obj[sep+1:],
+			})
+			i = len(list) - 1
+			index[policy.Obj] = i
+		}
+		list[i].Actions = append(list[i].Actions, policy.Act)
+	}
+
+	return
+}
+
 //Unused
 func (u *userGroup) GetAppPerm(param view.ReqGetAppPerm) (resp view.RespGetAppPerm, err error) {
 	var apps []db.AppInfo
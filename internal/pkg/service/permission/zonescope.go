@@ -0,0 +1,242 @@
+package permission
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+)
+
+// zoneScopeCacheTTL 团队成员变更不会主动清理缓存，最多延迟该时间生效
+const zoneScopeCacheTTL = 30 * time.Second
+
+var (
+	// ZoneScope 用户、团队的机房访问限制
+	ZoneScope *zoneScope
+
+	ErrZoneNotFound             = fmt.Errorf("机房不存在")
+	ErrZoneScopeSubjectNotFound = fmt.Errorf("用户或团队不存在")
+)
+
+type (
+	zoneScope struct {
+		db    *gorm.DB
+		mu    sync.RWMutex
+		cache map[int]userZoneScope
+	}
+
+	userZoneScope struct {
+		zones      []string
+		restricted bool
+		expireAt   time.Time
+	}
+)
+
+func initZoneScope(db *gorm.DB) {
+	ZoneScope = &zoneScope{
+		db:    db,
+		cache: make(map[int]userZoneScope),
+	}
+}
+
+// List 按用户、团队聚合的机房限制列表
+func (z *zoneScope) List(param view.ReqListZoneScope) (list []view.ZoneScopeItem, err error) {
+	var rows []db.ZoneScope
+	query := z.db.Model(&db.ZoneScope{})
+	if param.SubjectType != "" {
+		query = query.Where("subject_type = ?", param.SubjectType)
+	}
+	err = query.Order("subject_type, subject_id, zone_code").Find(&rows).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.ZoneScopeItem, 0)
+	var uids, teamIDs []uint
+	for _, row := range rows {
+		n := len(list)
+		if n > 0 && list[n-1].SubjectType == row.SubjectType && list[n-1].SubjectID == row.SubjectID {
+			list[n-1].Zones = append(list[n-1].Zones, row.ZoneCode)
+			continue
+		}
+
+		list = append(list, view.ZoneScopeItem{
+			SubjectType: row.SubjectType,
+			SubjectID:   row.SubjectID,
+			Zones:       []string{row.ZoneCode},
+		})
+		if row.SubjectType == db.ZoneScopeSubjectUser {
+			uids = append(uids, row.SubjectID)
+		} else {
+			teamIDs = append(teamIDs, row.SubjectID)
+		}
+	}
+
+	names := make(map[string]string)
+	if len(uids) > 0 {
+		var users []db.User
+		err = z.db.Where("uid in (?)", uids).Find(&users).Error
+		if err != nil {
+			return
+		}
+		for _, u := range users {
+			names[fmt.Sprintf("%s:%d", db.ZoneScopeSubjectUser, u.Uid)] = u.Username
+		}
+	}
+	if len(teamIDs) > 0 {
+		var teams []db.Team
+		err = z.db.Where("id in (?)", teamIDs).Find(&teams).Error
+		if err != nil {
+			return
+		}
+		for _, t := range teams {
+			names[fmt.Sprintf("%s:%d", db.ZoneScopeSubjectTeam, t.ID)] = t.Name
+		}
+	}
+
+	for i := range list {
+		list[i].SubjectName = names[fmt.Sprintf("%s:%d", list[i].SubjectType, list[i].SubjectID)]
+	}
+	return
+}
+
+// Set 替换用户或团队可访问的机房，Zones 为空时取消限制
+func (z *zoneScope) Set(param view.ReqSetZoneScope) (err error) {
+	err = z.checkSubject(param.SubjectType, param.SubjectID)
+	if err != nil {
+		return
+	}
+
+	zones := dedupeZones(param.Zones)
+	if len(zones) > 0 {
+		var exists []string
+		err = z.db.Model(&db.Zone{}).Where("zone_code in (?)", zones).Pluck("distinct zone_code", &exists).Error
+		if err != nil {
+			return
+		}
+		if len(exists) != len(zones) {
+			return ErrZoneNotFound
+		}
+	}
+
+	tx := z.db.Begin()
+	err = tx.Unscoped().Where("subject_type = ? and subject_id = ?", param.SubjectType, param.SubjectID).
+		Delete(&db.ZoneScope{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	for _, zone := range zones {
+		err = tx.Create(&db.ZoneScope{
+			SubjectType: param.SubjectType,
+			SubjectID:   param.SubjectID,
+			ZoneCode:    zone,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	z.mu.Lock()
+	z.cache = make(map[int]userZoneScope)
+	z.mu.Unlock()
+	return
+}
+
+// UserZones 用户可访问的机房，为用户本身与所属团队限制的并集。restricted 为 false 时不受限制，管理员不受限制
+func (z *zoneScope) UserZones(u *db.User) (zones []string, restricted bool, err error) {
+	if u == nil || u.Access == "admin" {
+		return nil, false, nil
+	}
+
+	z.mu.RLock()
+	item, ok := z.cache[u.Uid]
+	z.mu.RUnlock()
+	if ok && time.Now().Before(item.expireAt) {
+		return item.zones, item.restricted, nil
+	}
+
+	teamQuery := z.db.Model(&db.TeamMember{}).Select("team_id").Where("uid = ?", u.Uid).SubQuery()
+	err = z.db.Model(&db.ZoneScope{}).
+		Where("(subject_type = ? and subject_id = ?) or (subject_type = ? and subject_id in ?)",
+			db.ZoneScopeSubjectUser, u.Uid, db.ZoneScopeSubjectTeam, teamQuery).
+		Order("zone_code").
+		Pluck("distinct zone_code", &zones).Error
+	if err != nil {
+		return
+	}
+
+	restricted = len(zones) > 0
+	z.mu.Lock()
+	z.cache[u.Uid] = userZoneScope{
+		zones:      zones,
+		restricted: restricted,
+		expireAt:   time.Now().Add(zoneScopeCacheTTL),
+	}
+	z.mu.Unlock()
+	return
+}
+
+// Allowed 用户是否可以访问机房 zone，zone 为空表示请求不涉及具体机房
+func (z *zoneScope) Allowed(u *db.User, zone string) (bool, error) {
+	zones, restricted, err := z.UserZones(u)
+	if err != nil {
+		return false, err
+	}
+	return ZoneAllowed(zones, restricted, zone), nil
+}
+
+func (z *zoneScope) checkSubject(subjectType string, subjectID uint) (err error) {
+	var count int
+	switch subjectType {
+	case db.ZoneScopeSubjectUser:
+		err = z.db.Model(&db.User{}).Where("uid = ?", subjectID).Count(&count).Error
+	case db.ZoneScopeSubjectTeam:
+		err = z.db.Model(&db.Team{}).Where("id = ?", subjectID).Count(&count).Error
+	}
+	if err != nil {
+		return
+	}
+	if count == 0 {
+		return ErrZoneScopeSubjectNotFound
+	}
+	return
+}
+
+// ZoneAllowed 判断 zone 是否在 zones 中，未受限制或 zone 为空时返回 true
+func ZoneAllowed(zones []string, restricted bool, zone string) bool {
+	if !restricted || zone == "" {
+		return true
+	}
+	for _, item := range zones {
+		if item == zone {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupeZones(zones []string) []string {
+	seen := make(map[string]struct{}, len(zones))
+	list := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		if _, ok := seen[zone]; ok || zone == "" {
+			continue
+		}
+		seen[zone] = struct{}{}
+		list = append(list, zone)
+	}
+	sort.Strings(list)
+	return list
+}
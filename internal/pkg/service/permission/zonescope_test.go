@@ -0,0 +1,35 @@
+package permission
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestZoneAllowed(t *testing.T) {
+	zones := []string{"wh-1", "sh-2"}
+	tests := []struct {
+		zones      []string
+		restricted bool
+		zone       string
+		want       bool
+	}{
+		{nil, false, "bj-1", true},
+		{zones, true, "", true},
+		{zones, true, "sh-2", true},
+		{zones, true, "bj-1", false},
+		{zones, true, "all", false},
+	}
+
+	for _, tt := range tests {
+		if got := ZoneAllowed(tt.zones, tt.restricted, tt.zone); got != tt.want {
+			t.Errorf("ZoneAllowed(%v, %v, %q) = %v, want %v", tt.zones, tt.restricted, tt.zone, got, tt.want)
+		}
+	}
+}
+
+func TestDedupeZones(t *testing.T) {
+	got := dedupeZones([]string{"sh-2", "", "wh-1", "sh-2"})
+	if want := []string{"sh-2", "wh-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupeZones = %v, want %v", got, want)
+	}
+}
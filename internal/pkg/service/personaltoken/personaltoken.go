@@ -0,0 +1,258 @@
+package personaltoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const (
+	// TokenPrefix Token 明文前缀，便于在日志、代码仓库中识别泄露的 Token
+	TokenPrefix = "juno_"
+
+	// 最近使用时间的刷新间隔，避免每次请求都写库
+	lastUsedInterval = time.Minute
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+	ErrTokenRevoked = errors.New("token revoked")
+	ErrUserDisabled = errors.New("user disabled")
+
+	// scopeRules 各 scope 允许访问的接口
+	scopeRules = map[string][]scopeRule{
+		db.PersonalTokenScopeConfigRead: {
+			{Method: http.MethodGet, PathPrefix: "/api/admin/confgov2/"},
+		},
+		db.PersonalTokenScopePipelineRun: {
+			{Method: http.MethodGet, PathPrefix: "/api/admin/test/platform/pipeline/"},
+			{Method: http.MethodPost, PathPrefix: "/api/admin/test/platform/pipeline/run"},
+			{Method: http.MethodPost, PathPrefix: "/api/admin/test/platform/pipeline/tasks/cancel"},
+		},
+		db.PersonalTokenScopeMetricsRead: {
+			{Method: http.MethodGet, PathPrefix: "/api/admin/resource/node/metrics"},
+			{Method: http.MethodGet, PathPrefix: "/api/admin/analysis/"},
+		},
+		db.PersonalTokenScopeConfigPublish: {
+			{Method: http.MethodGet, PathPrefix: "/api/admin/confgov2/"},
+			{Method: http.MethodPost, PathPrefix: "/api/admin/confgov2/config/publish"},
+		},
+		db.PersonalTokenScopeResourceRead: {
+			{Method: http.MethodGet, PathPrefix: "/api/admin/resource/app_node/list"},
+		},
+	}
+)
+
+// PersonalToken 用户 API Token
+var PersonalToken *personalToken
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	personalToken struct {
+		db *gorm.DB
+	}
+
+	scopeRule struct {
+		Method     string
+		PathPrefix string
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	PersonalToken = &personalToken{
+		db: o.DB,
+	}
+}
+
+// Create 创建 Token，明文只在此处返回
+func (p *personalToken) Create(uid int, param view.ReqCreatePersonalToken) (resp view.RespCreatePersonalToken, err error) {
+	for _, scope := range param.Scopes {
+		if _, ok := scopeRules[scope]; !ok {
+			err = fmt.Errorf("invalid scope: %s", scope)
+			return
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return
+	}
+
+	item := db.PersonalToken{
+		Uid:       uid,
+		Name:      param.Name,
+		Prefix:    token[:len(TokenPrefix)+4],
+		TokenHash: hashToken(token),
+		Scopes:    strings.Join(param.Scopes, ","),
+	}
+	if param.ExpireDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, param.ExpireDays)
+		item.ExpiresAt = &expiresAt
+	}
+
+	err = p.db.Create(&item).Error
+	if err != nil {
+		return
+	}
+
+	resp.PersonalToken = transformToken(item)
+	resp.Token = token
+	return
+}
+
+// List 用户的 Token 列表
+func (p *personalToken) List(uid int) (list []view.PersonalToken, err error) {
+	var tokens []db.PersonalToken
+	err = p.db.Where("uid = ?", uid).Order("id desc").Find(&tokens).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.PersonalToken, 0, len(tokens))
+	for _, item := range tokens {
+		list = append(list, transformToken(item))
+	}
+	return
+}
+
+// Revoke 吊销 Token
+func (p *personalToken) Revoke(uid int, param view.ReqRevokePersonalToken) (err error) {
+	var item db.PersonalToken
+	err = p.db.Where("id = ? and uid = ?", param.ID, uid).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("token not found")
+		}
+		return
+	}
+
+	if item.RevokedAt != nil {
+		return nil
+	}
+
+	return p.db.Model(&item).Update("revoked_at", time.Now()).Error
+}
+
+// Authenticate 校验 Token，返回 Token 所属用户及 scopes
+func (p *personalToken) Authenticate(token, clientIP string) (user db.User, scopes []string, err error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		err = ErrInvalidToken
+		return
+	}
+
+	var item db.PersonalToken
+	err = p.db.Where("token_hash = ?", hashToken(token)).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrInvalidToken
+		}
+		return
+	}
+
+	now := time.Now()
+	if item.RevokedAt != nil {
+		err = ErrTokenRevoked
+		return
+	}
+	if item.ExpiresAt != nil && item.ExpiresAt.Before(now) {
+		err = ErrTokenExpired
+		return
+	}
+
+	err = p.db.Where("uid = ?", item.Uid).First(&user).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrInvalidToken
+		}
+		return
+	}
+	if user.State == db.UserStateDisabled {
+		err = ErrUserDisabled
+		return
+	}
+
+	if item.LastUsedAt == nil || now.Sub(*item.LastUsedAt) > lastUsedInterval || item.LastUsedIP != clientIP {
+		err = p.db.Model(&item).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": clientIP,
+		}).Error
+		if err != nil {
+			return
+		}
+	}
+
+	scopes = splitScopes(item.Scopes)
+	return
+}
+
+// Scopes 可用的 scope 列表
+func Scopes() []string {
+	return []string{
+		db.PersonalTokenScopeConfigRead,
+		db.PersonalTokenScopePipelineRun,
+		db.PersonalTokenScopeMetricsRead,
+		db.PersonalTokenScopeConfigPublish,
+		db.PersonalTokenScopeResourceRead,
+	}
+}
+
+// Allowed 判断 scopes 是否允许访问该接口
+func Allowed(scopes []string, method, path string) bool {
+	for _, scope := range scopes {
+		for _, rule := range scopeRules[scope] {
+			if rule.Method == method && strings.HasPrefix(path, rule.PathPrefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 20)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", errors.Wrap(err, "generate token failed")
+	}
+	return TokenPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return nil
+	}
+	return strings.Split(scopes, ",")
+}
+
+func transformToken(item db.PersonalToken) view.PersonalToken {
+	return view.PersonalToken{
+		ID:         item.ID,
+		Name:       item.Name,
+		Prefix:     item.Prefix,
+		Scopes:     splitScopes(item.Scopes),
+		CreatedAt:  item.CreatedAt,
+		ExpiresAt:  item.ExpiresAt,
+		LastUsedAt: item.LastUsedAt,
+		LastUsedIP: item.LastUsedIP,
+		RevokedAt:  item.RevokedAt,
+	}
+}
@@ -0,0 +1,51 @@
+package personaltoken
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		scopes []string
+		method string
+		path   string
+		want   bool
+	}{
+		{[]string{db.PersonalTokenScopeConfigRead}, http.MethodGet, "/api/admin/confgov2/config/detail", true},
+		{[]string{db.PersonalTokenScopeConfigRead}, http.MethodPost, "/api/admin/confgov2/config/publish", false},
+		{[]string{db.PersonalTokenScopePipelineRun}, http.MethodPost, "/api/admin/test/platform/pipeline/run", true},
+		{[]string{db.PersonalTokenScopePipelineRun}, http.MethodPost, "/api/admin/test/platform/pipeline/delete", false},
+		{[]string{db.PersonalTokenScopeMetricsRead}, http.MethodGet, "/api/admin/resource/node/metrics", true},
+		{[]string{db.PersonalTokenScopeConfigRead, db.PersonalTokenScopeMetricsRead}, http.MethodGet, "/api/admin/analysis/index", true},
+		{[]string{db.PersonalTokenScopeMetricsRead}, http.MethodGet, "/api/admin/public/user/token/list", false},
+		{[]string{db.PersonalTokenScopeConfigPublish}, http.MethodPost, "/api/admin/confgov2/config/publish", true},
+		{[]string{db.PersonalTokenScopeConfigPublish}, http.MethodPost, "/api/admin/confgov2/config/delete", false},
+		{[]string{db.PersonalTokenScopeResourceRead}, http.MethodGet, "/api/admin/resource/app_node/list", true},
+		{nil, http.MethodGet, "/api/admin/confgov2/config/list", false},
+	}
+
+	for _, tt := range tests {
+		if got := Allowed(tt.scopes, tt.method, tt.path); got != tt.want {
+			t.Errorf("Allowed(%v, %s, %s) = %v, want %v", tt.scopes, tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	token, err := generateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(token, TokenPrefix) || len(token) != len(TokenPrefix)+40 {
+		t.Errorf("unexpected token %q", token)
+	}
+
+	if hashToken(token) == hashToken(token+"x") || len(hashToken(token)) != 64 {
+		t.Errorf("unexpected token hash")
+	}
+}
@@ -0,0 +1,365 @@
+package promotion
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/douyu/juno/internal/pkg/service/confgov2"
+	"github.com/douyu/juno/internal/pkg/service/testplatform"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+)
+
+// pipelineDefinition 晋升的流水线定义，不包含环境和机房
+type pipelineDefinition struct {
+	Name               string                   `json:"name"`
+	Branch             string                   `json:"branch"`
+	CodeCheck          bool                     `json:"code_check"`
+	UnitTest           bool                     `json:"unit_test"`
+	UnitTestShards     int                      `json:"unit_test_shards,omitempty"`
+	UnitTestAffected   bool                     `json:"unit_test_affected,omitempty"`
+	UnitTestRace       bool                     `json:"unit_test_race,omitempty"`
+	UnitTestCoverage   bool                     `json:"unit_test_coverage,omitempty"`
+	BaseBranch         string                   `json:"base_branch,omitempty"`
+	FullRunHours       int                      `json:"full_run_hours,omitempty"`
+	VulnCheck          bool                     `json:"vuln_check,omitempty"`
+	VulnGate           string                   `json:"vuln_gate,omitempty"`
+	LicenseCheck       bool                     `json:"license_check,omitempty"`
+	BuildReport        bool                     `json:"build_report,omitempty"`
+	BuildPackage       string                   `json:"build_package,omitempty"`
+	CrossBuild         bool                     `json:"cross_build,omitempty"`
+	CrossBuildTargets  string                   `json:"cross_build_targets,omitempty"`
+	BuildCheck         bool                     `json:"build_check,omitempty"`
+	BuildCommand       string                   `json:"build_command,omitempty"`
+	Benchmark          bool                     `json:"benchmark,omitempty"`
+	BenchmarkPackages  string                   `json:"benchmark_packages,omitempty"`
+	BenchmarkFlags     string                   `json:"benchmark_flags,omitempty"`
+	GoVersion          string                   `json:"go_version,omitempty"`
+	StepTimeout        int                      `json:"step_timeout,omitempty"`
+	StepRetries        int                      `json:"step_retries,omitempty"`
+	StepRetryDelay     int                      `json:"step_retry_delay,omitempty"`
+	Priority           int                      `json:"priority,omitempty"`
+	HttpTestCollection *int                     `json:"http_test_collection"`
+	GrpcTestAddr       string                   `json:"grpc_test_addr"`
+	GrpcTestCases      db.PipelineGrpcTestCases `json:"grpc_test_cases"`
+	WorkerLabels       db.MapStringString       `json:"worker_labels,omitempty"`
+	StepArtifacts      db.MapStringArray        `json:"step_artifacts,omitempty"`
+	StepEnv            db.MapStringMap          `json:"step_env,omitempty"`
+	StepWhen           db.MapStringString       `json:"step_when,omitempty"`
+}
+
+// resolve 校验源内容已验证、目标环境符合晋升顺序，返回待保存的晋升记录
+func (p *promotion) resolve(kind string, param view.ReqPromotionPreview) (item db.Promotion, err error) {
+	switch kind {
+	case db.PromotionKindConfig:
+		item, err = p.resolveConfig(param)
+	case db.PromotionKindPipeline:
+		item, err = p.resolvePipeline(param)
+	default:
+		err = fmt.Errorf("不支持的晋升类型 %s", kind)
+	}
+	if err != nil {
+		return
+	}
+
+	err = checkFlow(p.conf.Flow, item.SourceEnv, item.TargetEnv)
+	if err != nil {
+		return
+	}
+	if item.TargetID == 0 && item.TargetZone == "" {
+		return item, fmt.Errorf("目标环境 %s 不存在%s %s，请选择目标机房", item.TargetEnv, kindName(kind), item.Name)
+	}
+	err = p.checkAppWritable(item.AppName)
+	return
+}
+
+// resolveConfig 只有在源环境发布过的配置版本才能晋升，未指定版本时使用最近发布的版本
+func (p *promotion) resolveConfig(param view.ReqPromotionPreview) (item db.Promotion, err error) {
+	var source db.Configuration
+	err = p.db.Where("id = ?", param.ID).First(&source).Error
+	if err != nil {
+		return
+	}
+	var app db.AppInfo
+	err = p.db.Where("aid = ?", source.AID).First(&app).Error
+	if err != nil {
+		return
+	}
+
+	var history db.ConfigurationHistory
+	if param.Version == "" {
+		var publish db.ConfigurationPublish
+		err = p.db.Where("configuration_id = ?", source.ID).Order("id desc").First(&publish).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return item, fmt.Errorf("配置 %s 尚未在 %s 环境发布，不能晋升", source.Name, source.Env)
+		}
+		if err != nil {
+			return
+		}
+		err = p.db.Where("id = ?", publish.ConfigurationHistoryID).First(&history).Error
+	} else {
+		err = p.db.Where("configuration_id = ? and version = ?", source.ID, param.Version).First(&history).Error
+		if err != nil {
+			return
+		}
+		var count int
+		err = p.db.Model(&db.ConfigurationPublish{}).Where("configuration_history_id = ?", history.ID).Count(&count).Error
+		if err == nil && count == 0 {
+			err = fmt.Errorf("配置版本 %s 尚未在 %s 环境发布，不能晋升", param.Version, source.Env)
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	item = db.Promotion{
+		Kind:          db.PromotionKindConfig,
+		AppName:       app.AppName,
+		Name:          source.Name + "." + source.Format,
+		SourceID:      source.ID,
+		SourceEnv:     source.Env,
+		SourceZone:    source.Zone,
+		SourceVersion: history.Version,
+		TargetEnv:     param.TargetEnv,
+		TargetZone:    param.TargetZone,
+		Content:       history.Content,
+	}
+
+	target, err := p.configTarget(source, param.TargetEnv)
+	if err != nil {
+		return
+	}
+	item.TargetID = target.ID
+	if target.ID != 0 {
+		item.TargetZone = target.Zone
+		item.TargetContent = target.Content
+	}
+	return
+}
+
+// resolvePipeline 只有最近一次执行成功的流水线才能晋升
+func (p *promotion) resolvePipeline(param view.ReqPromotionPreview) (item db.Promotion, err error) {
+	var source db.TestPipeline
+	err = p.db.Where("id = ?", param.ID).First(&source).Error
+	if err != nil {
+		return
+	}
+
+	var task db.TestPipelineTask
+	err = p.db.Where("pipeline_id = ?", source.ID).Order("id desc").First(&task).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+	if task.ID == 0 || task.Status != db.TestTaskStatusSuccess {
+		return item, fmt.Errorf("流水线 %s 最近一次执行未成功，不能晋升", source.Name)
+	}
+
+	item = db.Promotion{
+		Kind:          db.PromotionKindPipeline,
+		AppName:       source.AppName,
+		Name:          source.Name,
+		SourceID:      source.ID,
+		SourceEnv:     source.Env,
+		SourceZone:    source.ZoneCode,
+		SourceVersion: strconv.Itoa(int(task.ID)),
+		TargetEnv:     param.TargetEnv,
+		TargetZone:    param.TargetZone,
+		Content:       pipelineContent(source),
+	}
+
+	target, err := p.pipelineTarget(source.AppName, source.Name, param.TargetEnv)
+	if err != nil {
+		return
+	}
+	item.TargetID = target.ID
+	if target.ID != 0 {
+		item.TargetZone = target.ZoneCode
+		item.TargetContent = pipelineContent(target)
+	}
+	return
+}
+
+// apply 确认目标环境在申请后未被修改，再写入晋升内容
+func (p *promotion) apply(u *db.User, item db.Promotion) (appliedID uint, err error) {
+	err = p.checkAppWritable(item.AppName)
+	if err != nil {
+		return
+	}
+
+	switch item.Kind {
+	case db.PromotionKindConfig:
+		return p.applyConfig(u, item)
+	case db.PromotionKindPipeline:
+		return p.applyPipeline(u, item)
+	}
+	return 0, fmt.Errorf("不支持的晋升类型 %s", item.Kind)
+}
+
+func (p *promotion) applyConfig(u *db.User, item db.Promotion) (appliedID uint, err error) {
+	var source db.Configuration
+	err = p.db.Unscoped().Where("id = ?", item.SourceID).First(&source).Error
+	if err != nil {
+		return
+	}
+	target, err := p.configTarget(source, item.TargetEnv)
+	if err != nil {
+		return
+	}
+	if target.ID != item.TargetID || target.Content != item.TargetContent {
+		return 0, errTargetChanged(item.TargetEnv)
+	}
+
+	if target.ID == 0 {
+		target = db.Configuration{
+			AID:    source.AID,
+			Name:   source.Name,
+			Format: source.Format,
+			Env:    item.TargetEnv,
+			Zone:   item.TargetZone,
+		}
+	}
+	return confgov2.Promote(u, target, item.Content,
+		fmt.Sprintf("从 %s 晋升版本 %s，晋升单 #%d", item.SourceEnv, item.SourceVersion, item.ID))
+}
+
+func (p *promotion) applyPipeline(u *db.User, item db.Promotion) (appliedID uint, err error) {
+	var definition pipelineDefinition
+	err = json.Unmarshal([]byte(item.Content), &definition)
+	if err != nil {
+		return
+	}
+	target, err := p.pipelineTarget(item.AppName, definition.Name, item.TargetEnv)
+	if err != nil {
+		return
+	}
+	if target.ID != item.TargetID || (target.ID != 0 && pipelineContent(target) != item.TargetContent) {
+		return 0, errTargetChanged(item.TargetEnv)
+	}
+
+	payload := view.TestPipeline{
+		ID:                 target.ID,
+		Name:               definition.Name,
+		Env:                item.TargetEnv,
+		ZoneCode:           item.TargetZone,
+		AppName:            item.AppName,
+		Branch:             definition.Branch,
+		CodeCheck:          definition.CodeCheck,
+		UnitTest:           definition.UnitTest,
+		UnitTestShards:     definition.UnitTestShards,
+		UnitTestAffected:   definition.UnitTestAffected,
+		UnitTestRace:       definition.UnitTestRace,
+		UnitTestCoverage:   definition.UnitTestCoverage,
+		BaseBranch:         definition.BaseBranch,
+		FullRunHours:       definition.FullRunHours,
+		VulnCheck:          definition.VulnCheck,
+		VulnGate:           definition.VulnGate,
+		LicenseCheck:       definition.LicenseCheck,
+		BuildReport:        definition.BuildReport,
+		BuildPackage:       definition.BuildPackage,
+		CrossBuild:         definition.CrossBuild,
+		CrossBuildTargets:  definition.CrossBuildTargets,
+		BuildCheck:         definition.BuildCheck,
+		BuildCommand:       definition.BuildCommand,
+		Benchmark:          definition.Benchmark,
+		BenchmarkPackages:  definition.BenchmarkPackages,
+		BenchmarkFlags:     definition.BenchmarkFlags,
+		GoVersion:          definition.GoVersion,
+		StepTimeout:        definition.StepTimeout,
+		StepRetries:        definition.StepRetries,
+		StepRetryDelay:     definition.StepRetryDelay,
+		Priority:           definition.Priority,
+		HttpTestCollection: definition.HttpTestCollection,
+		GrpcTestAddr:       definition.GrpcTestAddr,
+		GrpcTestCases:      definition.GrpcTestCases,
+		WorkerLabels:       definition.WorkerLabels,
+		StepArtifacts:      definition.StepArtifacts,
+		StepEnv:            definition.StepEnv,
+		StepWhen:           definition.StepWhen,
+	}
+	if target.ID != 0 {
+		return target.ID, testplatform.UpdatePipeline(uint(u.Uid), payload)
+	}
+	err = testplatform.CreatePipeline(uint(u.Uid), payload)
+	if err != nil {
+		return
+	}
+	target, err = p.pipelineTarget(item.AppName, definition.Name, item.TargetEnv)
+	return target.ID, err
+}
+
+// configTarget 目标环境中的同名配置，不存在时返回空配置
+func (p *promotion) configTarget(source db.Configuration, env string) (target db.Configuration, err error) {
+	err = p.db.Where("aid = ? and env = ? and name = ? and format = ?", source.AID, env, source.Name, source.Format).
+		First(&target).Error
+	if gorm.IsRecordNotFoundError(err) {
+		err = nil
+	}
+	return
+}
+
+// pipelineTarget 目标环境中的同名流水线，不存在时返回空流水线
+func (p *promotion) pipelineTarget(appName, name, env string) (target db.TestPipeline, err error) {
+	err = p.db.Where("app_name = ? and env = ? and name = ?", appName, env, name).Order("id desc").First(&target).Error
+	if gorm.IsRecordNotFoundError(err) {
+		err = nil
+	}
+	return
+}
+
+func (p *promotion) checkAppWritable(appName string) (err error) {
+	var app db.AppInfo
+	err = p.db.Where("app_name = ?", appName).First(&app).Error
+	if err != nil {
+		return
+	}
+	if !app.Writable() {
+		return fmt.Errorf("应用 %s 已归档或待删除，不能晋升", appName)
+	}
+	return nil
+}
+
+func pipelineContent(pl db.TestPipeline) string {
+	buf, _ := json.MarshalIndent(pipelineDefinition{
+		Name:               pl.Name,
+		Branch:             pl.Branch,
+		CodeCheck:          pl.CodeCheck,
+		UnitTest:           pl.UnitTest,
+		UnitTestShards:     pl.UnitTestShards,
+		UnitTestAffected:   pl.UnitTestAffected,
+		UnitTestRace:       pl.UnitTestRace,
+		UnitTestCoverage:   pl.UnitTestCoverage,
+		BaseBranch:         pl.BaseBranch,
+		FullRunHours:       pl.FullRunHours,
+		VulnCheck:          pl.VulnCheck,
+		VulnGate:           pl.VulnGate,
+		LicenseCheck:       pl.LicenseCheck,
+		BuildReport:        pl.BuildReport,
+		BuildPackage:       pl.BuildPackage,
+		CrossBuild:         pl.CrossBuild,
+		CrossBuildTargets:  pl.CrossBuildTargets,
+		BuildCheck:         pl.BuildCheck,
+		BuildCommand:       pl.BuildCommand,
+		Benchmark:          pl.Benchmark,
+		BenchmarkPackages:  pl.BenchmarkPackages,
+		BenchmarkFlags:     pl.BenchmarkFlags,
+		GoVersion:          pl.GoVersion,
+		StepTimeout:        pl.StepTimeout,
+		StepRetries:        pl.StepRetries,
+		StepRetryDelay:     pl.StepRetryDelay,
+		Priority:           pl.Priority,
+		HttpTestCollection: pl.HttpTestCollection,
+		GrpcTestAddr:       pl.GrpcTestAddr,
+		GrpcTestCases:      pl.GrpcTestCases,
+		WorkerLabels:       pl.WorkerLabels,
+		StepArtifacts:      pl.StepArtifacts,
+		StepEnv:            pl.StepEnv,
+		StepWhen:           pl.StepWhen,
+	}, "", "  ")
+	return string(buf)
+}
+
+func errTargetChanged(env string) error {
+	return fmt.Errorf("目标环境 %s 在申请后已被修改，请重新发起晋升", env)
+}
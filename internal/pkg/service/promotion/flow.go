@@ -0,0 +1,117 @@
+package promotion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// maxDiffCells 比较的行数乘积超过该值时不再逐行比较，按整体替换展示
+const maxDiffCells = 4000000
+
+// checkFlow 按晋升顺序只能晋升到下一个环境，flow 为空时只要求环境不同
+func checkFlow(flow []string, from, to string) error {
+	if from == to {
+		return fmt.Errorf("目标环境不能与源环境相同")
+	}
+	if len(flow) == 0 {
+		return nil
+	}
+	for i, env := range flow {
+		if env != from {
+			continue
+		}
+		if i+1 < len(flow) && flow[i+1] == to {
+			return nil
+		}
+		if i+1 == len(flow) {
+			return fmt.Errorf("环境 %s 已是最后一个环境，不能继续晋升", from)
+		}
+		return fmt.Errorf("环境 %s 只能晋升到 %s", from, flow[i+1])
+	}
+	return fmt.Errorf("环境 %s 不在晋升顺序 %s 中", from, strings.Join(flow, " -> "))
+}
+
+// lineDiff 按行比较 origin 和 modified，去掉相同的首尾后对中间部分求最长公共子序列
+func lineDiff(origin, modified string) []view.PromotionDiffLine {
+	a, b := splitLines(origin), splitLines(modified)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]view.PromotionDiffLine, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		diff = append(diff, view.PromotionDiffLine{Type: view.PromotionDiffEqual, Text: line})
+	}
+	diff = append(diff, middleDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, view.PromotionDiffLine{Type: view.PromotionDiffEqual, Text: line})
+	}
+	return diff
+}
+
+func middleDiff(a, b []string) (diff []view.PromotionDiffLine) {
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			diff = append(diff, view.PromotionDiffLine{Type: view.PromotionDiffDelete, Text: line})
+		}
+		for _, line := range b {
+			diff = append(diff, view.PromotionDiffLine{Type: view.PromotionDiffAdd, Text: line})
+		}
+		return
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, view.PromotionDiffLine{Type: view.PromotionDiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, view.PromotionDiffLine{Type: view.PromotionDiffDelete, Text: a[i]})
+			i++
+		default:
+			diff = append(diff, view.PromotionDiffLine{Type: view.PromotionDiffAdd, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, view.PromotionDiffLine{Type: view.PromotionDiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, view.PromotionDiffLine{Type: view.PromotionDiffAdd, Text: b[j]})
+	}
+	return
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.Replace(content, "\r\n", "\n", -1), "\n"), "\n")
+}
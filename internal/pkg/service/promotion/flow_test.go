@@ -0,0 +1,66 @@
+package promotion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func TestCheckFlow(t *testing.T) {
+	flow := []string{"dev", "gray", "production"}
+	cases := []struct {
+		flow     []string
+		from, to string
+		ok       bool
+	}{
+		{flow, "dev", "gray", true},
+		{flow, "gray", "production", true},
+		{flow, "dev", "production", false},
+		{flow, "production", "dev", false},
+		{flow, "gray", "gray", false},
+		{flow, "live", "gray", false},
+		{nil, "dev", "production", true},
+		{nil, "dev", "dev", false},
+	}
+	for _, c := range cases {
+		err := checkFlow(c.flow, c.from, c.to)
+		if (err == nil) != c.ok {
+			t.Errorf("checkFlow(%v, %q, %q) = %v", c.flow, c.from, c.to, err)
+		}
+	}
+
+	err := checkFlow(flow, "dev", "production")
+	if err == nil || err.Error() != "环境 dev 只能晋升到 gray" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	render := func(diff []view.PromotionDiffLine) string {
+		lines := make([]string, 0, len(diff))
+		for _, line := range diff {
+			lines = append(lines, line.Type+line.Text)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	cases := []struct {
+		origin, modified, want string
+	}{
+		{"", "", ""},
+		{"", "a\nb\n", "+a\n+b"},
+		{"a\nb\n", "", "-a\n-b"},
+		{"a\nb\nc\n", "a\nb\nc", "=a\n=b\n=c"},
+		{"a\nb\nc\n", "a\nx\nc\n", "=a\n-b\n+x\n=c"},
+		{"[app]\nport = 80\n", "[app]\nport = 80\nhost = \"\"\n", "=[app]\n=port = 80\n+host = \"\""},
+		{"a\r\nb\r\n", "a\nc\n", "=a\n-b\n+c"},
+		{"a\nb\nc\nd\n", "b\nc\ne\n", "-a\n=b\n=c\n-d\n+e"},
+	}
+	for _, c := range cases {
+		got := render(lineDiff(c.origin, c.modified))
+		if got != c.want {
+			t.Errorf("lineDiff(%q, %q) =\n%s\nwant\n%s", c.origin, c.modified, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package promotion
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/accessrequest"
+	"github.com/douyu/juno/internal/pkg/service/team"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// Promotion 配置、流水线的环境晋升
+	Promotion *promotion
+
+	ErrPromotionNotFound = fmt.Errorf("晋升申请不存在")
+)
+
+type (
+	Option struct {
+		DB   *gorm.DB
+		Conf cfg.Promotion
+	}
+
+	promotion struct {
+		db   *gorm.DB
+		conf cfg.Promotion
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	Promotion = &promotion{
+		db:   o.DB,
+		conf: o.Conf,
+	}
+}
+
+// Preview 预览晋升内容与目标环境现有内容的差异
+func (p *promotion) Preview(kind string, param view.ReqPromotionPreview) (resp view.RespPromotionPreview, err error) {
+	item, err := p.resolve(kind, param)
+	if err != nil {
+		return
+	}
+
+	return view.RespPromotionPreview{
+		Kind:          item.Kind,
+		AppName:       item.AppName,
+		Name:          item.Name,
+		SourceEnv:     item.SourceEnv,
+		SourceZone:    item.SourceZone,
+		SourceVersion: item.SourceVersion,
+		TargetID:      item.TargetID,
+		TargetEnv:     item.TargetEnv,
+		TargetZone:    item.TargetZone,
+		Content:       item.Content,
+		TargetContent: item.TargetContent,
+		Changed:       item.Content != item.TargetContent,
+		Diff:          lineDiff(item.TargetContent, item.Content),
+	}, nil
+}
+
+// Create 发起晋升，保存晋升内容快照并通知应用所属团队审批
+func (p *promotion) Create(u *db.User, kind string, param view.ReqCreatePromotion) (item db.Promotion, err error) {
+	item, err = p.resolve(kind, view.ReqPromotionPreview{
+		ID:         param.ID,
+		Version:    param.Version,
+		TargetEnv:  param.TargetEnv,
+		TargetZone: param.TargetZone,
+	})
+	if err != nil {
+		return
+	}
+	if item.Content == item.TargetContent {
+		return item, fmt.Errorf("目标环境 %s 的内容与晋升内容相同，无需晋升", item.TargetEnv)
+	}
+
+	var count int
+	err = p.db.Model(&db.Promotion{}).
+		Where("kind = ? and source_id = ? and target_env = ? and status = ?", item.Kind, item.SourceID, item.TargetEnv, db.PromotionStatusPending).
+		Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		return item, fmt.Errorf("已有待审批的晋升，请勿重复提交")
+	}
+
+	item.Reason = param.Reason
+	item.Status = db.PromotionStatusPending
+	item.Uid = u.Uid
+	err = p.db.Create(&item).Error
+	if err != nil {
+		return
+	}
+
+	go team.Team.Notify(notice.Event{
+		Type:     notice.EventApproval,
+		App:      item.AppName,
+		Env:      item.TargetEnv,
+		Severity: notice.SeverityWarning,
+		Subject:  fmt.Sprintf("[Juno] %s 申请将应用 %s 的%s晋升到 %s，请审批", u.Username, item.AppName, kindName(item.Kind), item.TargetEnv),
+		Content: fmt.Sprintf("【环境晋升】%s 申请将应用 %s 的%s %s 从 %s 晋升到 %s，原因：%s，请前往 Juno 审批",
+			u.Username, item.AppName, kindName(item.Kind), item.Name, item.SourceEnv, item.TargetEnv, item.Reason),
+		Approval: &notice.Approval{Kind: notice.ApprovalPromotion, ID: item.ID},
+	})
+	return
+}
+
+// List 晋升记录，scope 为 mine 时返回我发起的，为 review 时返回我可审批的，为空时返回全部晋升历史
+func (p *promotion) List(u *db.User, param view.ReqListPromotion) (list []view.Promotion, page *view.Pagination, err error) {
+	var items []db.Promotion
+
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := p.db.Model(&db.Promotion{})
+	switch param.Scope {
+	case view.PromotionScopeMine:
+		query = query.Where("uid = ?", u.Uid)
+	case view.PromotionScopeReview:
+		if u.Access != "admin" {
+			var apps []string
+			apps, err = accessrequest.AccessRequest.OwnedApps(u.Uid)
+			if err != nil {
+				return
+			}
+			query = query.Where("app_name in (?)", apps)
+		}
+	}
+	if param.Kind != "" {
+		query = query.Where("kind = ?", param.Kind)
+	}
+	if param.Status != "" {
+		query = query.Where("status = ?", param.Status)
+	}
+	if param.AppName != "" {
+		query = query.Where("app_name = ?", param.AppName)
+	}
+
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&items).Error
+	if err != nil {
+		return
+	}
+
+	usernames, err := p.usernames(items)
+	if err != nil {
+		return
+	}
+
+	list = make([]view.Promotion, 0, len(items))
+	for _, item := range items {
+		list = append(list, toView(item, usernames))
+	}
+	return
+}
+
+// Detail 晋升详情，包含申请时的内容快照和差异
+func (p *promotion) Detail(id uint) (resp view.RespPromotionDetail, err error) {
+	item, err := p.find(id)
+	if err != nil {
+		return
+	}
+
+	usernames, err := p.usernames([]db.Promotion{item})
+	if err != nil {
+		return
+	}
+
+	return view.RespPromotionDetail{
+		Promotion:     toView(item, usernames),
+		Content:       item.Content,
+		TargetContent: item.TargetContent,
+		Diff:          lineDiff(item.TargetContent, item.Content),
+	}, nil
+}
+
+// Review 审批晋升，通过后立即写入目标环境
+func (p *promotion) Review(u *db.User, param view.ReqReviewPromotion) (err error) {
+	item, err := p.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	err = accessrequest.AccessRequest.CheckReviewPerm(u, item.AppName)
+	if err != nil {
+		return
+	}
+	if item.Uid == u.Uid && u.Access != "admin" {
+		return fmt.Errorf("不能审批自己的申请")
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":         db.PromotionStatusDenied,
+		"reviewer_uid":   u.Uid,
+		"review_comment": param.Comment,
+		"reviewed_at":    &now,
+	}
+	if param.Approve {
+		fields["status"] = db.PromotionStatusApproved
+	}
+	err = p.transition(item.ID, db.PromotionStatusPending, fields)
+	if err != nil {
+		return
+	}
+
+	result := "拒绝"
+	if param.Approve {
+		var appliedID uint
+		appliedID, err = p.apply(u, item)
+		fields = map[string]interface{}{
+			"status":     db.PromotionStatusApplied,
+			"applied_id": appliedID,
+			"error":      "",
+		}
+		result = fmt.Sprintf("通过，已写入 %s", item.TargetEnv)
+		if err != nil {
+			fields["status"] = db.PromotionStatusFailed
+			fields["error"] = err.Error()
+			result = fmt.Sprintf("通过，但写入 %s 失败：%s", item.TargetEnv, err.Error())
+		}
+		if updateErr := p.transition(item.ID, db.PromotionStatusApproved, fields); updateErr != nil && err == nil {
+			err = updateErr
+		}
+	}
+
+	go team.Team.Notify(notice.Event{
+		Type:     notice.EventApproval,
+		App:      item.AppName,
+		Env:      item.TargetEnv,
+		Severity: notice.SeverityInfo,
+		Subject:  fmt.Sprintf("[Juno] 应用 %s 的环境晋升已处理", item.AppName),
+		Content: fmt.Sprintf("【环境晋升】%s 申请将应用 %s 的%s %s 从 %s 晋升到 %s，已被 %s %s",
+			p.username(item.Uid), item.AppName, kindName(item.Kind), item.Name, item.SourceEnv, item.TargetEnv, u.Username, result),
+	})
+	return
+}
+
+// Cancel 申请人撤回待审批的晋升
+func (p *promotion) Cancel(u *db.User, id uint) (err error) {
+	item, err := p.find(id)
+	if err != nil {
+		return
+	}
+
+	if item.Uid != u.Uid {
+		return ErrPromotionNotFound
+	}
+
+	return p.transition(item.ID, db.PromotionStatusPending, map[string]interface{}{
+		"status": db.PromotionStatusCanceled,
+	})
+}
+
+// transition 仅在晋升处于 from 状态时更新，避免并发审批
+func (p *promotion) transition(id uint, from string, fields map[string]interface{}) error {
+	query := p.db.Model(&db.Promotion{}).Where("id = ? and status = ?", id, from).Updates(fields)
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		return fmt.Errorf("晋升状态已变更，请刷新后重试")
+	}
+	return nil
+}
+
+func (p *promotion) find(id uint) (item db.Promotion, err error) {
+	err = p.db.Where("id = ?", id).First(&item).Error
+	if err != nil && gorm.IsRecordNotFoundError(err) {
+		err = ErrPromotionNotFound
+	}
+	return
+}
+
+func (p *promotion) usernames(items []db.Promotion) (names map[int]string, err error) {
+	uids := make([]int, 0, len(items)*2)
+	for _, item := range items {
+		uids = append(uids, item.Uid)
+		if item.ReviewerUid != 0 {
+			uids = append(uids, item.ReviewerUid)
+		}
+	}
+
+	names = make(map[int]string)
+	if len(uids) == 0 {
+		return
+	}
+
+	var users []db.User
+	err = p.db.Select("uid, username").Where("uid in (?)", uids).Find(&users).Error
+	if err != nil {
+		return
+	}
+	for _, item := range users {
+		names[item.Uid] = item.Username
+	}
+	return
+}
+
+func (p *promotion) username(uid int) string {
+	var u db.User
+	p.db.Select("username").Where("uid = ?", uid).First(&u)
+	return u.Username
+}
+
+func toView(item db.Promotion, usernames map[int]string) view.Promotion {
+	return view.Promotion{
+		ID:            item.ID,
+		Kind:          item.Kind,
+		AppName:       item.AppName,
+		Name:          item.Name,
+		SourceID:      item.SourceID,
+		SourceEnv:     item.SourceEnv,
+		SourceZone:    item.SourceZone,
+		SourceVersion: item.SourceVersion,
+		TargetID:      item.TargetID,
+		TargetEnv:     item.TargetEnv,
+		TargetZone:    item.TargetZone,
+		Reason:        item.Reason,
+		Status:        item.Status,
+		Uid:           item.Uid,
+		Username:      usernames[item.Uid],
+		ReviewerUid:   item.ReviewerUid,
+		Reviewer:      usernames[item.ReviewerUid],
+		ReviewComment: item.ReviewComment,
+		ReviewedAt:    item.ReviewedAt,
+		AppliedID:     item.AppliedID,
+		Error:         item.Error,
+		CreatedAt:     item.CreatedAt,
+	}
+}
+
+func kindName(kind string) string {
+	if kind == db.PromotionKindPipeline {
+		return "流水线"
+	}
+	return "配置"
+}
@@ -0,0 +1,388 @@
+package provision
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/team"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/scim"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+// ListGroups 团队列表，支持按 displayName、externalId 过滤
+func (p *provision) ListGroups(filter string, startIndex, count int) (resp scim.ListResponse, err error) {
+	f, err := scim.ParseFilter(filter)
+	if err != nil {
+		return
+	}
+
+	query := p.db.Model(&db.Team{})
+	switch strings.ToLower(f.Attribute) {
+	case "":
+	case "displayname":
+		query = query.Where("name = ?", f.Value)
+	case "externalid":
+		query = query.Where("id in ?", p.externalIDQuery(db.ScimResourceGroup, f.Value))
+	default:
+		err = scim.BadRequest(scim.ScimTypeInvalidFilter, fmt.Sprintf("unsupported filter attribute: %s", f.Attribute))
+		return
+	}
+
+	var total int
+	err = query.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	offset, limit, start := scim.Pagination(startIndex, count)
+	var list []db.Team
+	err = query.Order("id asc").Offset(offset).Limit(limit).Find(&list).Error
+	if err != nil {
+		return
+	}
+
+	resources := make([]scim.Group, 0, len(list))
+	for _, item := range list {
+		var res scim.Group
+		res, err = p.transformGroup(item)
+		if err != nil {
+			return
+		}
+		resources = append(resources, res)
+	}
+
+	return scim.NewListResponse(resources, total, start, len(resources)), nil
+}
+
+// GetGroup ..
+func (p *provision) GetGroup(id string) (resp scim.Group, err error) {
+	item, err := p.findTeam(id)
+	if err != nil {
+		return
+	}
+	return p.transformGroup(item)
+}
+
+// CreateGroup 创建团队，成员角色为 member，团队应用默认权限使用系统默认值
+func (p *provision) CreateGroup(param scim.Group) (resp scim.Group, err error) {
+	if param.DisplayName == "" {
+		err = scim.BadRequest(scim.ScimTypeInvalidValue, "displayName is required")
+		return
+	}
+
+	err = p.checkTeamName(param.DisplayName, 0)
+	if err != nil {
+		return
+	}
+
+	uids, err := p.memberUids(refValues(param.Members))
+	if err != nil {
+		return
+	}
+
+	tx := p.db.Begin()
+	item := db.Team{
+		Name:           param.DisplayName,
+		DefaultActions: strings.Join(team.DefaultActions, ","),
+	}
+	err = tx.Create(&item).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = p.replaceMembers(tx, item.ID, uids)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = p.setExternalID(tx, db.ScimResourceGroup, item.ID, param.ExternalID)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	xlog.Info("scim group created", xlog.String("team", item.Name), xlog.Int("members", len(uids)))
+	_ = casbin.Casbin.LoadPolicy()
+	return p.GetGroup(strconv.Itoa(int(item.ID)))
+}
+
+// ReplaceGroup PUT 全量更新团队名称及成员
+func (p *provision) ReplaceGroup(id string, param scim.Group) (resp scim.Group, err error) {
+	if param.DisplayName == "" {
+		err = scim.BadRequest(scim.ScimTypeInvalidValue, "displayName is required")
+		return
+	}
+
+	return p.updateGroup(id, scim.GroupPatch{
+		DisplayName: &param.DisplayName,
+		Replace:     refValues(param.Members),
+	}, &param.ExternalID)
+}
+
+// PatchGroup PATCH 修改团队名称或增删成员
+func (p *provision) PatchGroup(id string, ops []scim.PatchOperation) (resp scim.Group, err error) {
+	patch, err := scim.ParseGroupPatch(ops)
+	if err != nil {
+		return
+	}
+	return p.updateGroup(id, patch, nil)
+}
+
+// DeleteGroup 删除团队，团队应用变为无归属
+func (p *provision) DeleteGroup(id string) (err error) {
+	item, err := p.findTeam(id)
+	if err != nil {
+		return
+	}
+
+	err = team.Team.Delete(view.ReqDeleteTeam{ID: item.ID})
+	if err != nil {
+		return
+	}
+
+	xlog.Info("scim group deleted", xlog.String("team", item.Name))
+	return p.db.Unscoped().Where("resource_type = ? and resource_id = ?", db.ScimResourceGroup, item.ID).Delete(&db.ScimResource{}).Error
+}
+
+func (p *provision) updateGroup(id string, patch scim.GroupPatch, externalID *string) (resp scim.Group, err error) {
+	item, err := p.findTeam(id)
+	if err != nil {
+		return
+	}
+
+	if patch.DisplayName != nil && *patch.DisplayName != item.Name {
+		err = p.checkTeamName(*patch.DisplayName, item.ID)
+		if err != nil {
+			return
+		}
+	}
+
+	add, err := p.memberUids(patch.Add)
+	if err != nil {
+		return
+	}
+	remove, err := p.memberUids(patch.Remove)
+	if err != nil {
+		return
+	}
+	var replace []int
+	if patch.Replace != nil {
+		replace, err = p.memberUids(patch.Replace)
+		if err != nil {
+			return
+		}
+	}
+
+	tx := p.db.Begin()
+	if patch.DisplayName != nil {
+		err = tx.Model(&item).UpdateColumn("name", *patch.DisplayName).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+
+	if patch.Replace != nil {
+		err = p.replaceMembers(tx, item.ID, replace)
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+
+	for _, uid := range add {
+		err = p.addMember(tx, item.ID, uid)
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+
+	if len(remove) > 0 {
+		err = tx.Where("team_id = ? and uid in (?)", item.ID, remove).Delete(&db.TeamMember{}).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+
+	if externalID != nil {
+		err = p.setExternalID(tx, db.ScimResourceGroup, item.ID, *externalID)
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	_ = casbin.Casbin.LoadPolicy()
+	return p.GetGroup(id)
+}
+
+// replaceMembers 将团队成员替换为 uids，保留已有成员的角色
+func (p *provision) replaceMembers(tx *gorm.DB, teamID uint, uids []int) (err error) {
+	query := tx.Where("team_id = ?", teamID)
+	if len(uids) > 0 {
+		query = query.Where("uid not in (?)", uids)
+	}
+	err = query.Delete(&db.TeamMember{}).Error
+	if err != nil {
+		return
+	}
+
+	for _, uid := range uids {
+		err = p.addMember(tx, teamID, uid)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (p *provision) addMember(tx *gorm.DB, teamID uint, uid int) error {
+	var count int
+	err := tx.Model(&db.TeamMember{}).Where("team_id = ? and uid = ?", teamID, uid).Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+
+	return tx.Create(&db.TeamMember{
+		TeamID: teamID,
+		Uid:    uid,
+		Role:   db.TeamMemberRoleMember,
+	}).Error
+}
+
+// memberUids 将成员 id 转换为 uid，并校验用户存在
+func (p *provision) memberUids(ids []string) (uids []int, err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	uids = make([]int, 0, len(ids))
+	for _, id := range ids {
+		uid, e := strconv.Atoi(id)
+		if e != nil {
+			return nil, scim.BadRequest(scim.ScimTypeInvalidValue, fmt.Sprintf("invalid member %s", id))
+		}
+		uids = append(uids, uid)
+	}
+
+	var count int
+	err = p.db.Model(&db.User{}).Where("uid in (?)", uids).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count != len(dedupe(uids)) {
+		return nil, scim.BadRequest(scim.ScimTypeInvalidValue, "members contain unknown users")
+	}
+	return
+}
+
+func (p *provision) findTeam(id string) (item db.Team, err error) {
+	teamID, err := strconv.Atoi(id)
+	if err != nil {
+		err = scim.NotFound(fmt.Sprintf("group %s not found", id))
+		return
+	}
+
+	err = p.db.Where("id = ?", teamID).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = scim.NotFound(fmt.Sprintf("group %s not found", id))
+		}
+		return
+	}
+	return
+}
+
+func (p *provision) checkTeamName(name string, excludeID uint) error {
+	var count int
+	err := p.db.Model(&db.Team{}).Where("name = ? and id != ?", name, excludeID).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return scim.NewError(http.StatusConflict, scim.ScimTypeUniqueness, fmt.Sprintf("displayName %s already exists", name))
+	}
+	return nil
+}
+
+func (p *provision) transformGroup(item db.Team) (resp scim.Group, err error) {
+	externalID, err := p.externalID(db.ScimResourceGroup, item.ID)
+	if err != nil {
+		return
+	}
+
+	var uids []int
+	err = p.db.Model(&db.TeamMember{}).Where("team_id = ?", item.ID).Order("id").Pluck("uid", &uids).Error
+	if err != nil {
+		return
+	}
+
+	var users []db.User
+	if len(uids) > 0 {
+		err = p.db.Where("uid in (?)", uids).Order("uid").Find(&users).Error
+		if err != nil {
+			return
+		}
+	}
+
+	members := make([]scim.Ref, 0, len(users))
+	for _, u := range users {
+		members = append(members, scim.Ref{Value: strconv.Itoa(u.Uid), Display: u.Username})
+	}
+
+	created, modified := item.CreatedAt, item.UpdatedAt
+	resp = scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          strconv.Itoa(int(item.ID)),
+		ExternalID:  externalID,
+		DisplayName: item.Name,
+		Members:     members,
+		Meta: &scim.Meta{
+			ResourceType: db.ScimResourceGroup,
+			Created:      &created,
+			LastModified: &modified,
+		},
+	}
+	return
+}
+
+func refValues(refs []scim.Ref) []string {
+	values := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		values = append(values, ref.Value)
+	}
+	return values
+}
+
+func dedupe(uids []int) []int {
+	seen := make(map[int]struct{}, len(uids))
+	list := make([]int, 0, len(uids))
+	for _, uid := range uids {
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		list = append(list, uid)
+	}
+	return list
+}
@@ -0,0 +1,362 @@
+package provision
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/scim"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+// Provision 企业 IdP 通过 SCIM 2.0 同步用户与团队
+var Provision *provision
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	provision struct {
+		db *gorm.DB
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	Provision = &provision{
+		db: o.DB,
+	}
+}
+
+// ListUsers 用户列表，支持按 userName、externalId 过滤
+func (p *provision) ListUsers(filter string, startIndex, count int) (resp scim.ListResponse, err error) {
+	f, err := scim.ParseFilter(filter)
+	if err != nil {
+		return
+	}
+
+	query := p.db.Model(&db.User{})
+	switch strings.ToLower(f.Attribute) {
+	case "":
+	case "username":
+		query = query.Where("username = ?", f.Value)
+	case "externalid":
+		query = query.Where("uid in ?", p.externalIDQuery(db.ScimResourceUser, f.Value))
+	default:
+		err = scim.BadRequest(scim.ScimTypeInvalidFilter, fmt.Sprintf("unsupported filter attribute: %s", f.Attribute))
+		return
+	}
+
+	var total int
+	err = query.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	offset, limit, start := scim.Pagination(startIndex, count)
+	var list []db.User
+	err = query.Order("uid asc").Offset(offset).Limit(limit).Find(&list).Error
+	if err != nil {
+		return
+	}
+
+	resources := make([]scim.User, 0, len(list))
+	for _, item := range list {
+		var res scim.User
+		res, err = p.transformUser(item)
+		if err != nil {
+			return
+		}
+		resources = append(resources, res)
+	}
+
+	return scim.NewListResponse(resources, total, start, len(resources)), nil
+}
+
+// GetUser ..
+func (p *provision) GetUser(id string) (resp scim.User, err error) {
+	item, err := p.findUser(id)
+	if err != nil {
+		return
+	}
+	return p.transformUser(item)
+}
+
+// CreateUser 创建用户，用户名已存在时返回 409，IdP 会先按 userName 查询再关联已有用户
+func (p *provision) CreateUser(param scim.User) (resp scim.User, err error) {
+	if param.UserName == "" {
+		err = scim.BadRequest(scim.ScimTypeInvalidValue, "userName is required")
+		return
+	}
+
+	err = p.checkUserName(param.UserName, 0)
+	if err != nil {
+		return
+	}
+
+	item := db.User{
+		Username: param.UserName,
+		Nickname: userDisplayName(param),
+		Email:    scim.PrimaryEmail(param.Emails),
+		Oauth:    user.OauthSCIM,
+		OauthId:  param.ExternalID,
+		Access:   "user",
+	}
+	if param.Active != nil && !*param.Active {
+		item.State = db.UserStateDisabled
+	}
+
+	err = user.User.Create(&item)
+	if err != nil {
+		return
+	}
+
+	err = p.setExternalID(p.db, db.ScimResourceUser, uint(item.Uid), param.ExternalID)
+	if err != nil {
+		return
+	}
+
+	xlog.Info("scim user created", xlog.String("username", item.Username), xlog.Int("uid", item.Uid))
+	return p.GetUser(strconv.Itoa(item.Uid))
+}
+
+// ReplaceUser PUT 全量更新用户
+func (p *provision) ReplaceUser(id string, param scim.User) (resp scim.User, err error) {
+	if param.UserName == "" {
+		err = scim.BadRequest(scim.ScimTypeInvalidValue, "userName is required")
+		return
+	}
+
+	email := scim.PrimaryEmail(param.Emails)
+	displayName := userDisplayName(param)
+	active := param.Active == nil || *param.Active
+
+	return p.updateUser(id, scim.UserPatch{
+		UserName:    &param.UserName,
+		DisplayName: &displayName,
+		ExternalID:  &param.ExternalID,
+		Email:       &email,
+		Active:      &active,
+	})
+}
+
+// PatchUser PATCH 部分更新用户，IdP 通过 active=false 停用用户
+func (p *provision) PatchUser(id string, ops []scim.PatchOperation) (resp scim.User, err error) {
+	patch, err := scim.ParseUserPatch(ops)
+	if err != nil {
+		return
+	}
+	return p.updateUser(id, patch)
+}
+
+// DeleteUser 删除用户及其团队成员关系
+func (p *provision) DeleteUser(id string) (err error) {
+	item, err := p.findUser(id)
+	if err != nil {
+		return
+	}
+
+	err = user.User.Delete(item)
+	if err != nil {
+		return
+	}
+
+	err = p.db.Where("uid = ?", item.Uid).Delete(&db.TeamMember{}).Error
+	if err != nil {
+		return
+	}
+
+	err = p.db.Unscoped().Where("resource_type = ? and resource_id = ?", db.ScimResourceUser, item.Uid).Delete(&db.ScimResource{}).Error
+	if err != nil {
+		return
+	}
+
+	xlog.Info("scim user deleted", xlog.String("username", item.Username), xlog.Int("uid", item.Uid))
+	return casbin.Casbin.LoadPolicy()
+}
+
+func (p *provision) updateUser(id string, patch scim.UserPatch) (resp scim.User, err error) {
+	item, err := p.findUser(id)
+	if err != nil {
+		return
+	}
+
+	update := map[string]interface{}{}
+	if patch.UserName != nil && *patch.UserName != item.Username {
+		err = p.checkUserName(*patch.UserName, item.Uid)
+		if err != nil {
+			return
+		}
+		update["username"] = *patch.UserName
+	}
+	if patch.DisplayName != nil {
+		update["nickname"] = *patch.DisplayName
+	}
+	if patch.Email != nil {
+		update["email"] = *patch.Email
+	}
+
+	deactivated := false
+	if patch.Active != nil {
+		state := ""
+		if !*patch.Active {
+			state = db.UserStateDisabled
+		}
+		deactivated = state == db.UserStateDisabled && item.State != db.UserStateDisabled
+		update["state"] = state
+	}
+
+	if len(update) > 0 {
+		update["update_time"] = time.Now().Unix()
+		err = p.db.Model(&db.User{}).Where("uid = ?", item.Uid).UpdateColumns(update).Error
+		if err != nil {
+			return
+		}
+	}
+
+	if patch.ExternalID != nil {
+		err = p.setExternalID(p.db, db.ScimResourceUser, uint(item.Uid), *patch.ExternalID)
+		if err != nil {
+			return
+		}
+	}
+
+	// 停用后立即吊销所有登录会话
+	if deactivated {
+		err = user.Session.RevokeAll(item.Uid, "")
+		if err != nil {
+			return
+		}
+		xlog.Info("scim user deactivated", xlog.String("username", item.Username), xlog.Int("uid", item.Uid))
+	}
+
+	return p.GetUser(id)
+}
+
+func (p *provision) findUser(id string) (item db.User, err error) {
+	uid, err := strconv.Atoi(id)
+	if err != nil {
+		err = scim.NotFound(fmt.Sprintf("user %s not found", id))
+		return
+	}
+
+	err = p.db.Where("uid = ?", uid).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = scim.NotFound(fmt.Sprintf("user %s not found", id))
+		}
+		return
+	}
+	return
+}
+
+func (p *provision) checkUserName(username string, excludeUid int) error {
+	var count int
+	err := p.db.Model(&db.User{}).Where("username = ? and uid != ?", username, excludeUid).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return scim.NewError(http.StatusConflict, scim.ScimTypeUniqueness, fmt.Sprintf("userName %s already exists", username))
+	}
+	return nil
+}
+
+func (p *provision) transformUser(item db.User) (resp scim.User, err error) {
+	externalID, err := p.externalID(db.ScimResourceUser, uint(item.Uid))
+	if err != nil {
+		return
+	}
+
+	var teams []db.Team
+	err = p.db.Table("team").
+		Joins("inner join team_member on team_member.team_id = team.id and team_member.deleted_at is null").
+		Where("team_member.uid = ?", item.Uid).
+		Find(&teams).Error
+	if err != nil {
+		return
+	}
+
+	groups := make([]scim.Ref, 0, len(teams))
+	for _, t := range teams {
+		groups = append(groups, scim.Ref{Value: strconv.Itoa(int(t.ID)), Display: t.Name})
+	}
+
+	active := item.State != db.UserStateDisabled
+	created := time.Unix(item.CreateTime, 0)
+	modified := time.Unix(item.UpdateTime, 0)
+	resp = scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          strconv.Itoa(item.Uid),
+		ExternalID:  externalID,
+		UserName:    item.Username,
+		Name:        &scim.Name{Formatted: item.Nickname},
+		DisplayName: item.Nickname,
+		Active:      &active,
+		Groups:      groups,
+		Meta: &scim.Meta{
+			ResourceType: db.ScimResourceUser,
+			Created:      &created,
+			LastModified: &modified,
+		},
+	}
+	if item.Email != "" {
+		resp.Emails = []scim.Email{{Value: item.Email, Type: "work", Primary: true}}
+	}
+	return
+}
+
+func (p *provision) externalID(resourceType string, resourceID uint) (string, error) {
+	var item db.ScimResource
+	err := p.db.Where("resource_type = ? and resource_id = ?", resourceType, resourceID).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return item.ExternalID, nil
+}
+
+func (p *provision) externalIDQuery(resourceType, externalID string) interface{} {
+	return p.db.Model(&db.ScimResource{}).Select("resource_id").
+		Where("resource_type = ? and external_id = ?", resourceType, externalID).SubQuery()
+}
+
+func (p *provision) setExternalID(tx *gorm.DB, resourceType string, resourceID uint, externalID string) error {
+	var item db.ScimResource
+	err := tx.Where("resource_type = ? and resource_id = ?", resourceType, resourceID).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return err
+	}
+
+	if item.ID == 0 {
+		if externalID == "" {
+			return nil
+		}
+		return tx.Create(&db.ScimResource{ResourceType: resourceType, ResourceID: resourceID, ExternalID: externalID}).Error
+	}
+	return tx.Model(&item).UpdateColumn("external_id", externalID).Error
+}
+
+func userDisplayName(u scim.User) string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name != nil {
+		if u.Name.Formatted != "" {
+			return u.Name.Formatted
+		}
+		if name := strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName); name != "" {
+			return name
+		}
+	}
+	return u.UserName
+}
@@ -0,0 +1,102 @@
+package proxyaudit
+
+import (
+	"context"
+	"time"
+
+	"github.com/douyu/juno/pkg/graceful"
+	"github.com/douyu/juno/pkg/metrics"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/util/xgo"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+const queueSize = 1024
+
+// ProxyAudit 代理请求审计
+var ProxyAudit *proxyAudit
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	proxyAudit struct {
+		db    *gorm.DB
+		queue chan db.ProxyAuditLog
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	ProxyAudit = &proxyAudit{
+		db:    o.DB,
+		queue: make(chan db.ProxyAuditLog, queueSize),
+	}
+	queue := ProxyAudit.queue
+	metrics.RegisterQueue("proxyaudit", func() int { return len(queue) })
+	graceful.OnShutdown("proxyaudit", func(ctx context.Context) error {
+		return graceful.WaitFor(ctx, func() bool { return len(queue) == 0 })
+	})
+	xgo.Go(ProxyAudit.consume)
+}
+
+// Record 异步写入审计记录，队列满时丢弃，避免影响代理请求
+func (p *proxyAudit) Record(item db.ProxyAuditLog) {
+	select {
+	case p.queue <- item:
+	default:
+		xlog.Warn("proxyAudit.Record queue is full, drop audit log", xlog.Any("item", item))
+	}
+}
+
+func (p *proxyAudit) consume() {
+	for item := range p.queue {
+		err := p.db.Create(&item).Error
+		if err != nil {
+			xlog.Error("proxyAudit.consume create audit log failed", xlog.Any("item", item), xlog.String("err", err.Error()))
+		}
+	}
+}
+
+// List 审计记录列表
+func (p *proxyAudit) List(param view.ReqListProxyAudit) (list []db.ProxyAuditLog, page *view.Pagination, err error) {
+	page = view.NewPagination(param.Page, param.PageSize)
+
+	query := p.db.Model(&db.ProxyAuditLog{})
+	if param.Kind != "" {
+		query = query.Where("kind = ?", param.Kind)
+	}
+	if param.UserName != "" {
+		query = query.Where("user_name = ?", param.UserName)
+	}
+	if param.AppName != "" {
+		query = query.Where("app_name = ?", param.AppName)
+	}
+	if param.Env != "" {
+		query = query.Where("env = ?", param.Env)
+	}
+	if param.Target != "" {
+		query = query.Where("target = ?", param.Target)
+	}
+	if param.Path != "" {
+		query = query.Where("path like ?", param.Path+"%")
+	}
+	if param.StartTime > 0 {
+		query = query.Where("created_at >= ?", time.Unix(param.StartTime, 0))
+	}
+	if param.EndTime > 0 {
+		query = query.Where("created_at <= ?", time.Unix(param.EndTime, 0))
+	}
+
+	list = make([]db.ProxyAuditLog, 0)
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&list).Error
+
+	return
+}
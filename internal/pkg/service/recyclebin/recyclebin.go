@@ -0,0 +1,250 @@
+// Package recyclebin 回收站。
+// 应用、配置、流水线、测试集删除时在同一事务中写入回收站记录，保留期内可以恢复，
+// 过期后由 PurgeTick 彻底删除。各对象的恢复、彻底删除由所属 service 通过 Register 登记
+package recyclebin
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	defaultRetentionDays = 7
+	defaultPurgeInterval = time.Hour
+
+	// purgeBatchSize 每次清理的最大条数
+	purgeBatchSize = 100
+)
+
+var (
+	// RecycleBin 回收站
+	RecycleBin *recycleBin
+
+	ErrItemNotFound  = fmt.Errorf("回收站中不存在该对象")
+	ErrItemExpired   = fmt.Errorf("已超过保留期，不能恢复")
+	ErrNoRestorePerm = fmt.Errorf("只能恢复自己删除的对象，其他对象请联系管理员")
+	ErrNoPurgePerm   = fmt.Errorf("只有管理员可以彻底删除")
+
+	handlersMtx sync.RWMutex
+	handlers    = map[string]Handler{}
+)
+
+// listSpec 回收站列表允许排序、筛选的字段
+var listSpec = listquery.Spec{
+	Sorts: map[string]string{
+		"id":        "id",
+		"expire_at": "expire_at",
+	},
+	Filters: map[string]string{
+		"name": "name",
+		"env":  "env",
+	},
+	DefaultSort:     "-id",
+	Tiebreaker:      "id",
+	DefaultPageSize: 20,
+}
+
+type (
+	Option struct {
+		DB   *gorm.DB
+		Conf cfg.RecycleBin
+	}
+
+	// Handler 对象的恢复和彻底删除，在回收站记录所在的事务中执行
+	Handler struct {
+		// Restore 恢复对象，对象已存在同名记录等不能恢复的情况返回错误
+		Restore func(tx *gorm.DB, item db.RecycleItem) error
+		// Purge 彻底删除对象，为空时只删除回收站记录
+		Purge func(tx *gorm.DB, item db.RecycleItem) error
+		// AfterRestore 事务提交后执行，如清理缓存
+		AfterRestore func(item db.RecycleItem)
+	}
+
+	recycleBin struct {
+		db   *gorm.DB
+		conf cfg.RecycleBin
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	if o.Conf.RetentionDays <= 0 {
+		o.Conf.RetentionDays = defaultRetentionDays
+	}
+	if o.Conf.PurgeInterval <= 0 {
+		o.Conf.PurgeInterval = defaultPurgeInterval
+	}
+	RecycleBin = &recycleBin{
+		db:   o.DB,
+		conf: o.Conf,
+	}
+}
+
+// Register 登记 kind 对象的恢复和彻底删除
+func Register(kind string, h Handler) {
+	handlersMtx.Lock()
+	defer handlersMtx.Unlock()
+	handlers[kind] = h
+}
+
+func handler(kind string) (Handler, bool) {
+	handlersMtx.RLock()
+	defer handlersMtx.RUnlock()
+	h, ok := handlers[kind]
+	return h, ok
+}
+
+// Add 在删除对象的事务 tx 中写入回收站记录，回收站未初始化时不记录
+func Add(tx *gorm.DB, item db.RecycleItem) error {
+	if RecycleBin == nil {
+		return nil
+	}
+	item.ExpireAt = time.Now().AddDate(0, 0, RecycleBin.conf.RetentionDays)
+	return tx.Create(&item).Error
+}
+
+// PurgeInterval 清理过期对象的间隔
+func (r *recycleBin) PurgeInterval() time.Duration {
+	return r.conf.PurgeInterval
+}
+
+// List 回收站列表
+func (r *recycleBin) List(u *db.User, param view.ReqListRecycleItem) (list []db.RecycleItem, page *view.Pagination, err error) {
+	query, err := listSpec.Parse(param.ListQuery)
+	if err != nil {
+		return
+	}
+
+	sql := query.Where(r.db.Model(&db.RecycleItem{}))
+	if param.Kind != "" {
+		sql = sql.Where("kind = ?", param.Kind)
+	}
+	if param.AppName != "" {
+		sql = sql.Where("app_name = ?", param.AppName)
+	}
+	if param.Mine || !isAdmin(u) {
+		sql = sql.Where("deleted_by = ?", u.Uid)
+	}
+
+	var total int
+	err = sql.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]db.RecycleItem, 0)
+	err = query.Paginate(sql).Find(&list).Error
+	if err != nil {
+		return
+	}
+	page = query.Pagination(total, len(list))
+	return
+}
+
+// Restore 恢复对象，非管理员只能恢复自己删除的对象
+func (r *recycleBin) Restore(u *db.User, id uint) (err error) {
+	item, err := r.item(id)
+	if err != nil {
+		return
+	}
+	if !isAdmin(u) && item.DeletedBy != u.Uid {
+		return ErrNoRestorePerm
+	}
+	if time.Now().After(item.ExpireAt) {
+		return ErrItemExpired
+	}
+
+	h, ok := handler(item.Kind)
+	if !ok || h.Restore == nil {
+		return fmt.Errorf("不支持恢复 %s", item.Kind)
+	}
+
+	tx := r.db.Begin()
+	err = h.Restore(tx, item)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Unscoped().Delete(&item).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	if h.AfterRestore != nil {
+		h.AfterRestore(item)
+	}
+	return
+}
+
+// Purge 彻底删除对象，只有管理员可以操作
+func (r *recycleBin) Purge(u *db.User, id uint) (err error) {
+	if !isAdmin(u) {
+		return ErrNoPurgePerm
+	}
+
+	item, err := r.item(id)
+	if err != nil {
+		return
+	}
+	return r.purge(item)
+}
+
+// PurgeTick 彻底删除过期的对象，单个对象失败时记录日志并继续
+func (r *recycleBin) PurgeTick() error {
+	var items []db.RecycleItem
+	err := r.db.Where("expire_at < ?", time.Now()).Order("id").Limit(purgeBatchSize).Find(&items).Error
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		err = r.purge(item)
+		if err != nil {
+			xlog.Error("recycleBin.PurgeTick purge failed",
+				xlog.String("kind", item.Kind), xlog.Uint("objectId", item.ObjectID), xlog.FieldErr(err))
+		}
+	}
+	return nil
+}
+
+func (r *recycleBin) purge(item db.RecycleItem) (err error) {
+	tx := r.db.Begin()
+	if h, ok := handler(item.Kind); ok && h.Purge != nil {
+		err = h.Purge(tx, item)
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+	err = tx.Unscoped().Delete(&item).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit().Error
+}
+
+func (r *recycleBin) item(id uint) (item db.RecycleItem, err error) {
+	err = r.db.Where("id = ?", id).First(&item).Error
+	if gorm.IsRecordNotFoundError(err) {
+		err = ErrItemNotFound
+	}
+	return
+}
+
+func isAdmin(u *db.User) bool {
+	return u.Access == "admin"
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// factsRefreshInterval 主机信息未变化时重新上报的间隔，避免管理端漏收后节点信息长期缺失
+const factsRefreshInterval = time.Hour
+
+// containerRuntimeSockets 按顺序检测容器运行时，docker 同时带有 containerd，需要先检测
+var containerRuntimeSockets = []struct {
+	name string
+	path string
+}{
+	{"docker", "/var/run/docker.sock"},
+	{"containerd", "/run/containerd/containerd.sock"},
+	{"cri-o", "/var/run/crio/crio.sock"},
+}
+
+// collectHostFacts 采集本机硬件、系统信息，读取失败的项留空
+func collectHostFacts() view.HostFacts {
+	facts := view.HostFacts{
+		CPUCount:         runtime.NumCPU(),
+		OS:               runtime.GOOS,
+		Arch:             runtime.GOARCH,
+		IPs:              hostIPs(),
+		ContainerRuntime: detectContainerRuntime(fileExists),
+	}
+	if buf, err := ioutil.ReadFile("/proc/meminfo"); err == nil {
+		facts.MemTotal = parseMemTotal(string(buf))
+	}
+	if buf, err := ioutil.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		facts.Kernel = strings.TrimSpace(string(buf))
+	}
+	if buf, err := ioutil.ReadFile("/etc/os-release"); err == nil {
+		if name := parseOSRelease(string(buf)); name != "" {
+			facts.OS = name
+		}
+	}
+	return facts
+}
+
+// parseMemTotal 从 /proc/meminfo 中解析总内存，返回字节数
+func parseMemTotal(meminfo string) uint64 {
+	scanner := bufio.NewScanner(strings.NewReader(meminfo))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kb * 1024
+	}
+	return 0
+}
+
+// parseOSRelease 从 /etc/os-release 中解析发行版名称，优先使用 PRETTY_NAME
+func parseOSRelease(content string) (name string) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.Trim(parts[1], `"'`)
+		switch parts[0] {
+		case "PRETTY_NAME":
+			return value
+		case "NAME":
+			name = value
+		}
+	}
+	return name
+}
+
+func detectContainerRuntime(exists func(path string) bool) string {
+	for _, item := range containerRuntimeSockets {
+		if exists(item.path) {
+			return item.name
+		}
+	}
+	return ""
+}
+
+// hostIPs 本机已启用网卡上的全部非回环 IPv4 地址
+func hostIPs() []string {
+	ips := make([]string, 0)
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return ips
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ip := getIpFromAddr(addr); ip != nil {
+				ips = append(ips, ip.String())
+			}
+		}
+	}
+	return ips
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"testing"
+)
+
+func TestParseMemTotal(t *testing.T) {
+	meminfo := "MemTotal:       16318412 kB\nMemFree:         1234567 kB\n"
+	if got := parseMemTotal(meminfo); got != 16318412*1024 {
+		t.Errorf("parseMemTotal = %d", got)
+	}
+	if got := parseMemTotal("MemFree: 1 kB\n"); got != 0 {
+		t.Errorf("parseMemTotal without MemTotal = %d", got)
+	}
+}
+
+func TestParseOSRelease(t *testing.T) {
+	cases := []struct {
+		content, want string
+	}{
+		{"NAME=\"CentOS Linux\"\nVERSION=\"7 (Core)\"\nPRETTY_NAME=\"CentOS Linux 7 (Core)\"\n", "CentOS Linux 7 (Core)"},
+		{"NAME='Alpine Linux'\nID=alpine\n", "Alpine Linux"},
+		{"ID=unknown\n", ""},
+	}
+	for _, c := range cases {
+		if got := parseOSRelease(c.content); got != c.want {
+			t.Errorf("parseOSRelease(%q) = %q, want %q", c.content, got, c.want)
+		}
+	}
+}
+
+func TestDetectContainerRuntime(t *testing.T) {
+	cases := []struct {
+		paths []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"/run/containerd/containerd.sock"}, "containerd"},
+		{[]string{"/run/containerd/containerd.sock", "/var/run/docker.sock"}, "docker"},
+		{[]string{"/var/run/crio/crio.sock"}, "cri-o"},
+	}
+	for _, c := range cases {
+		exists := func(path string) bool {
+			for _, p := range c.paths {
+				if p == path {
+					return true
+				}
+			}
+			return false
+		}
+		if got := detectContainerRuntime(exists); got != c.want {
+			t.Errorf("detectContainerRuntime(%v) = %q, want %q", c.paths, got, c.want)
+		}
+	}
+}
@@ -16,11 +16,13 @@ package report
 
 import (
 	"encoding/json"
+	"reflect"
 	"time"
 
 	"github.com/douyu/juno/internal/pkg/service/proxy"
 	"github.com/douyu/juno/pkg/cfg"
 	"github.com/douyu/juno/pkg/constx"
+	"github.com/douyu/juno/pkg/model/view"
 	"github.com/douyu/juno/pkg/pb"
 	"github.com/douyu/jupiter/pkg/xlog"
 )
@@ -54,6 +56,8 @@ type AgentReportRequest struct {
 	ZoneName     string `json:"zone_name"`
 	Env          string `json:"env"`
 	ProxyType    int    `json:"proxy_type"`
+
+	HostFacts *view.HostFacts `json:"host_facts,omitempty"` // 首次上报、信息变化或超过刷新间隔时携带
 }
 
 // ReportAgentStatus report agent status
@@ -75,21 +79,39 @@ func (r *Report) ReportAgentStatus() error {
 	}
 
 	go func() {
+		var (
+			lastFacts   view.HostFacts
+			lastFactsAt time.Time
+		)
 		for {
+			facts := collectHostFacts()
+			req.HostFacts = nil
+			if time.Since(lastFactsAt) > factsRefreshInterval || !reflect.DeepEqual(facts, lastFacts) {
+				req.HostFacts = &facts
+			}
+
+			sent := false
 			if r.config.Addr == "stream" {
 				if proxy.StreamStore.IsStreamExist() {
 					msgByte, _ := json.Marshal(req)
-					_ = proxy.StreamStore.GetStream().Send(&pb.NotifyResp{
+					err := proxy.StreamStore.GetStream().Send(&pb.NotifyResp{
 						MsgId: constx.MsgNodeHeartBeatResp,
 						Msg:   msgByte,
 					})
+					sent = err == nil
 					xlog.Debug("report stream info", xlog.Any("response", string(msgByte)))
 
 				}
 			} else {
 				response := r.Reporter.Report(req)
+				sent = response.Err == 0
 				xlog.Debug("report http info", xlog.Any("response", response))
 			}
+
+			if sent && req.HostFacts != nil {
+				lastFacts = facts
+				lastFactsAt = time.Now()
+			}
 			time.Sleep(time.Duration(r.config.Internal))
 		}
 	}()
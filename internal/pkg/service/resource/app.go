@@ -4,13 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/douyu/juno/internal/pkg/invoker"
 	"github.com/douyu/juno/internal/pkg/service/appevent"
+	"github.com/douyu/juno/internal/pkg/service/eventbus"
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
+	"github.com/douyu/juno/internal/pkg/service/tag"
+	"github.com/douyu/juno/pkg/cache"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/event"
 	"github.com/douyu/juno/pkg/model/view"
@@ -21,20 +24,56 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// hiddenAppStatus 默认列表中不展示的应用状态
+var hiddenAppStatus = []string{db.AppStatusArchived, db.AppStatusDeleted}
+
+// appCache 应用信息缓存。name:<app_name> 缓存应用信息，aid:<aid> 只缓存 aid 对应的应用名，
+// 修改应用字段时只需按应用名失效
+var appCache = cache.New("app", 0)
+
 // GetApp 根据ID或者APPNAME获取APP信息
 // 只支持int和string查询
 func (r *resource) GetApp(identify interface{}) (resp db.AppInfo, err error) {
 	switch v := identify.(type) {
 	case string:
-		err = r.DB.Where("app_name = ?", v).Find(&resp).Error
+		err = appCache.Fetch("name:"+v, &resp, func() error {
+			return r.DB.Where("app_name = ?", v).Find(&resp).Error
+		})
 	case int, uint:
-		err = r.DB.Where("aid=?", v).Find(&resp).Error
+		if !cache.Enabled() {
+			err = r.DB.Where("aid=?", v).Find(&resp).Error
+			return
+		}
+		var appName string
+		err = appCache.Fetch(fmt.Sprintf("aid:%d", v), &appName, func() error {
+			var app db.AppInfo
+			err := r.DB.Select("app_name").Where("aid=?", v).Find(&app).Error
+			appName = app.AppName
+			return err
+		})
+		if err != nil {
+			return
+		}
+		return r.GetApp(appName)
 	default:
 		err = errors.New("identify type error")
 	}
 	return
 }
 
+// InvalidateApp 修改 app 表后清除应用信息缓存。只修改字段时 aid 传 0 即可，
+// 删除应用或修改应用名时需要同时清除 aid 的映射
+func InvalidateApp(aid int, appName string) {
+	keys := make([]string, 0, 2)
+	if aid != 0 {
+		keys = append(keys, fmt.Sprintf("aid:%d", aid))
+	}
+	if appName != "" {
+		keys = append(keys, "name:"+appName)
+	}
+	appCache.Invalidate(keys...)
+}
+
 // 设置APP信息
 func (r *resource) PutApp(item db.AppInfo, user *db.User) (err error) {
 	var count int
@@ -84,13 +123,40 @@ func (r *resource) CreateApp(item db.AppInfo, user *db.User) (err error) {
 		return
 	}
 
+	// 传入自定义字段时先校验，避免应用创建后字段写入失败
+	var appMeta map[string]string
+	if item.Meta != nil {
+		appMeta, err = r.checkAppMeta(0, item.Meta)
+		if err != nil {
+			return
+		}
+	}
+
 	item.CreateTime = time.Now().Unix()
 	item.UpdateTime = time.Now().Unix()
 	item.CreatedBy = user.Uid
+	item.Status = db.AppStatusActive
 
-	err = r.DB.Create(&item).Error
+	tx := r.DB.Begin()
+	err = tx.Create(&item).Error
+	if err == nil && appMeta != nil {
+		err = saveAppMeta(tx, item.Aid, item.Meta, appMeta)
+	}
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
 	meta, _ := json.Marshal(item)
 	appevent.AppEvent.AppCreateEvent(info.Aid, info.AppName, string(meta), user)
+	eventbus.Publish(eventbus.TopicAppCreated, eventbus.AppCreated{
+		Aid:      item.Aid,
+		AppName:  item.AppName,
+		Operator: user.Username,
+	})
 	return
 }
 
@@ -106,8 +172,27 @@ func (r *resource) UpdateApp(item db.AppInfo, user *db.User) (err error) {
 		err = errors.New("app is not exist")
 		return
 	}
+	var appMeta map[string]string
+	if item.Meta != nil {
+		appMeta, err = r.checkAppMeta(item.Aid, item.Meta)
+		if err != nil {
+			return
+		}
+	}
 	item.UpdateTime = time.Now().Unix()
-	err = r.DB.Model(db.AppInfo{}).Where("aid = ?", item.Aid).UpdateColumns(&item).Error
+	// 生命周期状态只能通过 applifecycle 修改
+	item.Status = ""
+	tx := r.DB.Begin()
+	err = tx.Model(db.AppInfo{}).Where("aid = ?", item.Aid).UpdateColumns(&item).Error
+	if err == nil && appMeta != nil {
+		err = saveAppMeta(tx, item.Aid, item.Meta, appMeta)
+	}
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Commit().Error
+	InvalidateApp(info.Aid, info.AppName)
 	meta, _ := json.Marshal(item)
 	appevent.AppEvent.AppUpdateEvent(info.Aid, info.AppName, string(meta), user)
 	return
@@ -126,6 +211,7 @@ func (r *resource) DeleteApp(item db.AppInfo, user *db.User) (err error) {
 		return
 	}
 	err = r.DB.Where("aid = ?", item.Aid).Delete(&db.AppInfo{}).Error
+	InvalidateApp(info.Aid, info.AppName)
 	meta, _ := json.Marshal(item)
 	appevent.AppEvent.AppDeleteEvent(info.Aid, info.AppName, string(meta), user)
 	return
@@ -183,30 +269,48 @@ func (r *resource) GetAppHttpList(appName string) (port string, appNodes []db.Ap
 }
 
 // 根据分页获取应用列表
-func (r *resource) GetAppList(where db.AppInfo, currentPage, pageSize int, keyType, keyWords, searchPort string, sort string) (resp []db.AppInfo, page *view.Pagination, err error) {
-	page = view.NewPagination(currentPage, pageSize)
-	sql := r.DB.Model(db.AppInfo{}).Where(where)
-	switch keyType {
-	case "app_name":
-		keyWords = strings.TrimSpace(keyWords)
-		if len(keyWords) > 0 {
-			sql = sql.Where("`app_name` like ?", "%"+keyWords+"%")
-		}
-	case "aid":
-		aid, _ := strconv.Atoi(strings.TrimSpace(keyWords))
-		if aid > 0 {
-			sql = sql.Where("`aid` = ? ", aid)
-		}
+// 未指定排序时按更新时间倒序，允许的排序、筛选字段见 appListSpec
+func (r *resource) GetAppList(where db.AppInfo, keyType, keyWords, searchPort string, filter view.AppListFilter, list view.ListQuery) (resp []db.AppInfo, page *view.Pagination, err error) {
+	query, err := appListSpec.Parse(list)
+	if err != nil {
+		return
+	}
+	filters, err := parseAppMetaFilter(filter.Meta)
+	if err != nil {
+		return
+	}
+	sql := query.Where(r.DB.Model(db.AppInfo{}).Where(where))
+	// 未指定状态时不展示已归档、待删除的应用
+	if where.Status == "" {
+		sql = sql.Where("status not in (?)", hiddenAppStatus)
+	}
+	for name, value := range filters {
+		sql = sql.Where("aid in (select aid from app_meta_value where field = ? and value = ? and deleted_at is null)", name, value)
 	}
+	if filter.ZoneCode != "" {
+		sql = sql.Where("app_name in (select app_name from app_node where zone_code = ?)", filter.ZoneCode)
+	}
+	sql = tag.Filter(sql, db.TagEntityApp, "app_name", filter.Tags)
+	sql = searchApps(sql, keyType, keyWords)
 	searchPort = strings.TrimSpace(searchPort)
 	if searchPort != "" {
-		sql = sql.Where("`http_port` = ? OR `rpc_port` = ? OR `govern_port` = ? ", searchPort, searchPort, searchPort)
+		sql = sql.Where("http_port = ? OR rpc_port = ? OR govern_port = ? ", searchPort, searchPort, searchPort)
 	}
-	sql.Count(&page.Total)
-	if sort != "" {
-		sql = sql.Order(sort)
+	var total int
+	err = sql.Count(&total).Error
+	if err != nil {
+		return
+	}
+	err = query.Paginate(sql).Find(&resp).Error
+	if err != nil {
+		return
+	}
+	page = query.Pagination(total, len(resp))
+	err = r.FillAppMeta(resp)
+	if err != nil {
+		return
 	}
-	err = sql.Offset((page.Current - 1) * page.PageSize).Limit(page.PageSize).Find(&resp).Error
+	err = r.fillAppTags(resp)
 	return
 }
 
@@ -228,20 +332,20 @@ func (r *resource) GetAppListWithEnv(param view.ReqAppListWithEnv) (resp view.Re
 	resp.Pagination.Current = int(param.Page)
 	resp.Pagination.PageSize = int(pageSize)
 
-	query := r.DB.Model(&db.AppInfo{})
-	if param.SearchText != "" {
-		query = query.Where("app_name like ?", "%"+param.SearchText+"%")
+	sort, err := parseAppSort(param.Sort)
+	if err != nil {
+		return
 	}
 
+	query := r.DB.Model(&db.AppInfo{}).Where("status not in (?)", hiddenAppStatus)
+	query = searchApps(query, param.KeywordsType, param.SearchText)
+
 	eg.Go(func() error {
 		return query.Count(&resp.Pagination.Total).Error
 	})
 
 	eg.Go(func() error {
-		return query.Preload("AppNodes", func(db *gorm.DB) *gorm.DB {
-			// group by env
-			return db.Group("app_name,env")
-		}).Limit(pageSize).Offset(offset).Find(&apps).Error
+		return query.Order(sort).Limit(pageSize).Offset(offset).Find(&apps).Error
 	})
 
 	err = eg.Wait()
@@ -249,14 +353,31 @@ func (r *resource) GetAppListWithEnv(param view.ReqAppListWithEnv) (resp view.Re
 		return view.RespAppListWithEnv{}, err
 	}
 
+	// 只查询当前页应用的环境，避免加载全部应用节点
+	appNames := make([]string, 0, len(apps))
+	for _, app := range apps {
+		appNames = append(appNames, app.AppName)
+	}
+	var nodes []db.AppNode
+	if len(appNames) > 0 {
+		err = r.DB.Select("distinct app_name, env").Where("app_name in (?)", appNames).Order("env").Find(&nodes).Error
+		if err != nil {
+			return view.RespAppListWithEnv{}, err
+		}
+	}
+	envs := make(map[string][]string, len(apps))
+	for _, node := range nodes {
+		envs[node.AppName] = append(envs[node.AppName], node.Env)
+	}
+
+	resp.List = make([]view.AppListWithEnvItem, 0, len(apps))
 	for _, app := range apps {
 		appItem := view.AppListWithEnvItem{
 			AppInfo: app,
-			Envs:    make([]string, 0),
+			Envs:    envs[app.AppName],
 		}
-
-		for _, node := range app.AppNodes {
-			appItem.Envs = append(appItem.Envs, node.Env)
+		if appItem.Envs == nil {
+			appItem.Envs = make([]string, 0)
 		}
 
 		resp.List = append(resp.List, appItem)
@@ -285,7 +406,7 @@ func (r *resource) FilterListInLangs(langs []string) ([]view.RespAppInfo, error)
 
 // SimpleAppList 获取应用及对应的负责人信息，主要用于访问gitlab交互
 func (r *resource) SimpleAppList(lang string) (resp []db.AppInfo) {
-	invoker.JunoMysql.Where("lang = ?", lang).Find(&resp)
+	invoker.JunoMysql.Where("lang = ? and status not in (?)", lang, hiddenAppStatus).Find(&resp)
 	return
 }
 
@@ -398,7 +519,8 @@ func (r *resource) updateAppInfo(info *db.AppInfo, user *db.User) {
 			log.Error("put appUpEvent failed", err.Error())
 		}
 	} else { // 更新
-		r.DB.Model(db.AppInfo{}).Where("app_name = ?", info.AppName).Save(info)
+		r.DB.Model(db.AppInfo{}).Where("app_name = ?", info.AppName).Omit("status").Save(info)
+		InvalidateApp(info.Aid, info.AppName)
 		if err := r.appUpdateEvent(info, user); err != nil {
 			log.Error("put appUpdateEvent failed", err.Error())
 		}
@@ -440,8 +562,9 @@ func (r *resource) GetVisitedApp(userName string) []db.UserVisitedApp {
 	return userVisitedApp
 }
 
-// Delete 删除对应aid的App，action为对应的删除行为
-func (r *resource) Delete(appName string, action db.AppLogAction) (err error) {
+// Delete 删除对应aid的App，action为对应的删除行为，uid 为删除人，系统删除时为 0。
+// 删除的应用保留在回收站中，保留期内可以恢复
+func (r *resource) Delete(appName string, action db.AppLogAction, uid int) (err error) {
 	app := db.AppInfo{}
 
 	// 先找到
@@ -492,14 +615,30 @@ func (r *resource) Delete(appName string, action db.AppLogAction) (err error) {
 		return
 	}
 
+	snapshot, _ := json.Marshal(app)
+	err = recyclebin.Add(tx, db.RecycleItem{
+		Kind:      db.RecycleKindApp,
+		ObjectID:  uint(app.Aid),
+		Name:      app.Name,
+		AppName:   app.AppName,
+		Snapshot:  string(snapshot),
+		DeletedBy: uid,
+	})
+	if err != nil {
+		log.Error("app.Delete: add recycle item failed", err.Error())
+		tx.Rollback()
+		return
+	}
+
 	err = tx.Commit().Error
+	InvalidateApp(app.Aid, app.AppName)
 
 	return
 }
 
 func (r *resource) removeAppDown(appDownList []db.AppInfo, user *db.User) error {
 	for _, item := range appDownList {
-		err := r.Delete(item.AppName, db.AppLogActionDelete)
+		err := r.Delete(item.AppName, db.AppLogActionDelete, user.Uid)
 		if err != nil {
 			log.Error("removeAppDown: app.Delete failed", err.Error())
 			continue
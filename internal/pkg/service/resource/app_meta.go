@@ -0,0 +1,223 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/store/gorm"
+)
+
+// AppMetaFieldList 应用自定义字段定义
+func (r *resource) AppMetaFieldList() (list []db.AppMetaField, err error) {
+	list = make([]db.AppMetaField, 0)
+	err = r.DB.Order("sort asc, id asc").Find(&list).Error
+	return
+}
+
+// SaveAppMetaField 创建或更新应用自定义字段。修改类型不会重新校验已有的值
+func (r *resource) SaveAppMetaField(param view.ReqSaveAppMetaField) (err error) {
+	param.Name = strings.TrimSpace(param.Name)
+	options := make([]string, 0, len(param.Options))
+	for _, option := range param.Options {
+		option = strings.TrimSpace(option)
+		if option != "" && !inStrings(options, option) {
+			options = append(options, option)
+		}
+	}
+	err = checkAppMetaField(param.Name, param.Type, options, param.Regex)
+	if err != nil {
+		return
+	}
+
+	item := db.AppMetaField{
+		Name:     param.Name,
+		Title:    param.Title,
+		Type:     param.Type,
+		Options:  strings.Join(options, ","),
+		Required: param.Required,
+		Regex:    param.Regex,
+		Sort:     param.Sort,
+	}
+	if param.ID == 0 {
+		var count int
+		r.DB.Model(db.AppMetaField{}).Where("name = ?", item.Name).Count(&count)
+		if count > 0 {
+			return fmt.Errorf("字段 %s 已存在", item.Name)
+		}
+		return r.DB.Create(&item).Error
+	}
+
+	var field db.AppMetaField
+	err = r.DB.Where("id = ?", param.ID).First(&field).Error
+	if err != nil {
+		return
+	}
+	if field.Name != item.Name {
+		return errors.New("字段标识不可修改")
+	}
+	return r.DB.Model(&field).Updates(map[string]interface{}{
+		"title":    item.Title,
+		"type":     item.Type,
+		"options":  item.Options,
+		"required": item.Required,
+		"regex":    item.Regex,
+		"sort":     item.Sort,
+	}).Error
+}
+
+// DeleteAppMetaField 删除字段定义及所有应用中该字段的值
+func (r *resource) DeleteAppMetaField(id uint) (err error) {
+	var field db.AppMetaField
+	err = r.DB.Where("id = ?", id).First(&field).Error
+	if err != nil {
+		return
+	}
+
+	tx := r.DB.Begin()
+	err = tx.Unscoped().Where("field = ?", field.Name).Delete(&db.AppMetaValue{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Unscoped().Delete(&field).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit().Error
+}
+
+// AppMeta 应用的自定义字段值
+func (r *resource) AppMeta(aid int) (meta map[string]string, err error) {
+	list, err := r.appMetaByAids([]int{aid})
+	if err != nil {
+		return
+	}
+	meta = list[aid]
+	if meta == nil {
+		meta = make(map[string]string)
+	}
+	return
+}
+
+// FillAppMeta 填充应用列表的自定义字段值
+func (r *resource) FillAppMeta(apps []db.AppInfo) error {
+	aids := make([]int, 0, len(apps))
+	for _, app := range apps {
+		aids = append(aids, app.Aid)
+	}
+	list, err := r.appMetaByAids(aids)
+	if err != nil {
+		return err
+	}
+	for i := range apps {
+		apps[i].Meta = list[apps[i].Aid]
+		if apps[i].Meta == nil {
+			apps[i].Meta = make(map[string]string)
+		}
+	}
+	return nil
+}
+
+// SetAppMeta 设置应用的自定义字段值，未传入的字段保持不变，值为空时删除
+func (r *resource) SetAppMeta(aid int, meta map[string]string) (err error) {
+	var count int
+	err = r.DB.Model(db.AppInfo{}).Where("aid = ?", aid).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count == 0 {
+		return errors.New("app is not exist")
+	}
+
+	merged, err := r.checkAppMeta(aid, meta)
+	if err != nil {
+		return
+	}
+	tx := r.DB.Begin()
+	err = saveAppMeta(tx, aid, meta, merged)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit().Error
+}
+
+// checkAppMeta 按字段定义校验自定义字段值，merged 为合并已有值后的结果
+func (r *resource) checkAppMeta(aid int, meta map[string]string) (merged map[string]string, err error) {
+	fields, err := r.AppMetaFieldList()
+	if err != nil {
+		return
+	}
+	fieldByName := make(map[string]db.AppMetaField, len(fields))
+	for _, field := range fields {
+		fieldByName[field.Name] = field
+	}
+
+	merged = make(map[string]string)
+	if aid > 0 {
+		merged, err = r.AppMeta(aid)
+		if err != nil {
+			return
+		}
+	}
+	for name, value := range meta {
+		field, ok := fieldByName[name]
+		if !ok {
+			return nil, fmt.Errorf("未定义的自定义字段 %s", name)
+		}
+		value = strings.TrimSpace(value)
+		err = checkAppMetaValue(name, field.Type, splitAppMetaOptions(field.Options), field.Regex, value)
+		if err != nil {
+			return nil, err
+		}
+		merged[name] = value
+	}
+	for _, field := range fields {
+		if field.Required && merged[field.Name] == "" {
+			return nil, fmt.Errorf("%s 不能为空", field.Name)
+		}
+	}
+	return merged, nil
+}
+
+// saveAppMeta 写入 meta 中的字段，值取自校验后的 merged
+func saveAppMeta(tx *gorm.DB, aid int, meta, merged map[string]string) (err error) {
+	for name := range meta {
+		value := merged[name]
+		err = tx.Unscoped().Where("aid = ? and field = ?", aid, name).Delete(&db.AppMetaValue{}).Error
+		if err != nil {
+			return
+		}
+		if value == "" {
+			continue
+		}
+		err = tx.Create(&db.AppMetaValue{Aid: aid, Field: name, Value: value}).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (r *resource) appMetaByAids(aids []int) (res map[int]map[string]string, err error) {
+	res = make(map[int]map[string]string)
+	if len(aids) == 0 {
+		return
+	}
+	var values []db.AppMetaValue
+	err = r.DB.Where("aid in (?)", aids).Find(&values).Error
+	if err != nil {
+		return
+	}
+	for _, value := range values {
+		if res[value.Aid] == nil {
+			res[value.Aid] = make(map[string]string)
+		}
+		res[value.Aid][value.Field] = value.Value
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 与 db.AppMetaType* 一致
+var appMetaTypes = []string{"string", "number", "bool", "enum", "url"}
+
+var appMetaNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// checkAppMetaField 校验字段定义，options 为 enum 可选值
+func checkAppMetaField(name, typ string, options []string, pattern string) error {
+	if !appMetaNameRegexp.MatchString(name) {
+		return fmt.Errorf("字段标识 %s 只能包含小写字母、数字和下划线，以字母开头，最长 32 位", name)
+	}
+	if !inStrings(appMetaTypes, typ) {
+		return fmt.Errorf("不支持的字段类型 %s", typ)
+	}
+	if typ == "enum" && len(options) == 0 {
+		return fmt.Errorf("enum 类型需要设置可选值")
+	}
+	if pattern != "" {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("正则格式错误: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// checkAppMetaValue 按字段类型校验值，空值不校验
+func checkAppMetaValue(name, typ string, options []string, pattern, value string) error {
+	if value == "" {
+		return nil
+	}
+	switch typ {
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s 需要是数字", name)
+		}
+	case "bool":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s 只能是 true 或 false", name)
+		}
+	case "enum":
+		if !inStrings(options, value) {
+			return fmt.Errorf("%s 只能是 %s 之一", name, strings.Join(options, ", "))
+		}
+	case "url":
+		u, err := url.ParseRequestURI(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s 需要是 http 或 https 地址", name)
+		}
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s 不匹配 %s", name, pattern)
+		}
+	}
+	return nil
+}
+
+// splitAppMetaOptions enum 可选值，逗号分隔
+func splitAppMetaOptions(options string) (list []string) {
+	for _, item := range strings.Split(options, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return
+}
+
+// parseAppMetaFilter 解析 name=value 形式的自定义字段筛选条件
+func parseAppMetaFilter(filters []string) (map[string]string, error) {
+	res := make(map[string]string, len(filters))
+	for _, filter := range filters {
+		i := strings.Index(filter, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("筛选条件 %s 格式错误，需要是 name=value", filter)
+		}
+		res[strings.TrimSpace(filter[:i])] = strings.TrimSpace(filter[i+1:])
+	}
+	return res, nil
+}
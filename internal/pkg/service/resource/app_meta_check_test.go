@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"testing"
+)
+
+func TestCheckAppMetaField(t *testing.T) {
+	cases := []struct {
+		name, typ string
+		options   []string
+		pattern   string
+		ok        bool
+	}{
+		{"tier", "enum", []string{"core", "normal"}, "", true},
+		{"runbook_url", "url", nil, "", true},
+		{"cost_center", "string", nil, `^CC\d+$`, true},
+		{"Tier", "string", nil, "", false},
+		{"1tier", "string", nil, "", false},
+		{"tier", "date", nil, "", false},
+		{"tier", "enum", nil, "", false},
+		{"tier", "string", nil, "(", false},
+	}
+	for _, c := range cases {
+		err := checkAppMetaField(c.name, c.typ, c.options, c.pattern)
+		if (err == nil) != c.ok {
+			t.Errorf("checkAppMetaField(%s, %s, %v, %s) = %v", c.name, c.typ, c.options, c.pattern, err)
+		}
+	}
+}
+
+func TestCheckAppMetaValue(t *testing.T) {
+	cases := []struct {
+		typ     string
+		options []string
+		pattern string
+		value   string
+		ok      bool
+	}{
+		{"string", nil, "", "", true},
+		{"number", nil, "", "1.5", true},
+		{"number", nil, "", "abc", false},
+		{"bool", nil, "", "true", true},
+		{"bool", nil, "", "yes", false},
+		{"enum", []string{"core", "normal"}, "", "core", true},
+		{"enum", []string{"core", "normal"}, "", "low", false},
+		{"url", nil, "", "https://wiki.example.com/runbook", true},
+		{"url", nil, "", "wiki/runbook", false},
+		{"url", nil, "", "ftp://example.com", false},
+		{"string", nil, `^CC\d+$`, "CC100", true},
+		{"string", nil, `^CC\d+$`, "100", false},
+	}
+	for _, c := range cases {
+		err := checkAppMetaValue("field", c.typ, c.options, c.pattern, c.value)
+		if (err == nil) != c.ok {
+			t.Errorf("checkAppMetaValue(%s, %v, %s, %s) = %v", c.typ, c.options, c.pattern, c.value, err)
+		}
+	}
+}
+
+func TestParseAppMetaFilter(t *testing.T) {
+	filter, err := parseAppMetaFilter([]string{"tier=core", "runbook_url = https://a.com/?x=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter["tier"] != "core" || filter["runbook_url"] != "https://a.com/?x=1" {
+		t.Errorf("filter = %v", filter)
+	}
+	if _, err = parseAppMetaFilter([]string{"tier"}); err == nil {
+		t.Error("expect error")
+	}
+}
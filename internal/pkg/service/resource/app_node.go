@@ -42,9 +42,13 @@ func (r *resource) GetAllAppEnvZone(where db.AppNode) (resp []db.AppNode, err er
 	return
 }
 
-func (r *resource) GetAppNodeList(where db.AppNode, currentPage, pageSize int, sort string) (resp []db.AppNode, page *view.Pagination, err error) {
+// GetAppNodeList zones 不为空时只返回这些机房的节点
+func (r *resource) GetAppNodeList(where db.AppNode, currentPage, pageSize int, sort string, zones ...string) (resp []db.AppNode, page *view.Pagination, err error) {
 	page = view.NewPagination(currentPage, pageSize)
 	sql := r.DB.Model(db.AppNode{}).Where(where)
+	if len(zones) > 0 {
+		sql = sql.Where("zone_code in (?)", zones)
+	}
 	sql.Count(&page.Total)
 	err = sql.Order(sort).Offset((page.Current - 1) * page.PageSize).Limit(page.PageSize).Find(&resp).Error
 	return
@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
+	"github.com/douyu/juno/pkg/sqldialect"
+	"github.com/douyu/jupiter/pkg/store/gorm"
+)
+
+const defaultAppSort = "update_time desc,aid desc"
+
+// appListSpec 应用列表允许排序、筛选的字段
+var appListSpec = listquery.Spec{
+	Sorts: map[string]string{
+		"aid":         "aid",
+		"app_name":    "app_name",
+		"name":        "name",
+		"create_time": "create_time",
+		"update_time": "update_time",
+	},
+	Filters: map[string]string{
+		"aid":        "aid",
+		"app_name":   "app_name",
+		"name":       "name",
+		"lang":       "lang",
+		"biz_domain": "biz_domain",
+		"level":      "level",
+		"team_id":    "team_id",
+		"git_url":    "git_url",
+	},
+	DefaultSort:     "-update_time",
+	Tiebreaker:      "aid",
+	DefaultPageSize: 20,
+	// 前端下拉框会一次拉取全部应用
+	MaxPageSize: 10000,
+}
+
+// parseAppSort 解析排序参数，多个字段用逗号分隔，字段前加 - 表示倒序，如 -update_time,app_name。
+// 为空时使用默认排序，并以 aid 兜底保证分页稳定
+func parseAppSort(sort string) (string, error) {
+	return appListSpec.ParseSort(sort)
+}
+
+// searchApps 按关键词筛选应用，keyType 为空时同时匹配应用名、中文名、仓库地址和负责人
+func searchApps(query *gorm.DB, keyType, keywords string) *gorm.DB {
+	keywords = strings.TrimSpace(keywords)
+	if keywords == "" {
+		return query
+	}
+
+	like := "%" + listquery.EscapeLike(keywords) + "%"
+	switch keyType {
+	case "app_name":
+		return query.Where("app_name like ?", like)
+	case "aid":
+		aid, _ := strconv.Atoi(keywords)
+		if aid > 0 {
+			return query.Where("aid = ?", aid)
+		}
+		return query
+	case "name":
+		return query.Where("name like ?", like)
+	case "repo":
+		return query.Where("git_url like ? or web_url like ?", like, like)
+	case "owner":
+		return query.Where(sqldialect.JSONArrayContains(query, "users"), keywords)
+	case "":
+		return query.Where("app_name like ? or name like ? or git_url like ? or "+sqldialect.JSONArrayContains(query, "users"),
+			like, like, like, keywords)
+	}
+	return query
+}
@@ -0,0 +1,25 @@
+package resource
+
+import (
+	"testing"
+)
+
+func TestParseAppSort(t *testing.T) {
+	cases := []struct {
+		sort, want string
+		ok         bool
+	}{
+		{"", defaultAppSort, true},
+		{"app_name", "app_name asc,aid desc", true},
+		{"-update_time, app_name", "update_time desc,app_name asc,aid desc", true},
+		{"-aid", "aid desc", true},
+		{"users", "", false},
+		{"app_name;drop table app", "", false},
+	}
+	for _, c := range cases {
+		got, err := parseAppSort(c.sort)
+		if (err == nil) != c.ok || got != c.want {
+			t.Errorf("parseAppSort(%q) = %q, %v", c.sort, got, err)
+		}
+	}
+}
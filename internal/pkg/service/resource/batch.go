@@ -0,0 +1,286 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/appevent"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+var (
+	zoneCSVColumns = []string{"env", "region_code", "region_name", "zone_code", "zone_name"}
+	nodeCSVColumns = []string{"host_name", "ip", "env", "zone_code"}
+)
+
+// ParseZoneCSV 解析可用区 CSV，表头为 env,region_code,region_name,zone_code,zone_name
+func ParseZoneCSV(reader io.Reader) (list []view.BatchZoneItem, err error) {
+	rows, err := parseCSV(reader, zoneCSVColumns)
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		list = append(list, view.BatchZoneItem{
+			Line:       row.Line,
+			Env:        row.Fields["env"],
+			RegionCode: row.Fields["region_code"],
+			RegionName: row.Fields["region_name"],
+			ZoneCode:   row.Fields["zone_code"],
+			ZoneName:   row.Fields["zone_name"],
+		})
+	}
+	return
+}
+
+// ParseNodeCSV 解析机器节点 CSV，表头为 host_name,ip,env,zone_code
+func ParseNodeCSV(reader io.Reader) (list []view.BatchNodeItem, err error) {
+	rows, err := parseCSV(reader, nodeCSVColumns)
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		list = append(list, view.BatchNodeItem{
+			Line:     row.Line,
+			HostName: row.Fields["host_name"],
+			IP:       row.Fields["ip"],
+			Env:      row.Fields["env"],
+			ZoneCode: row.Fields["zone_code"],
+		})
+	}
+	return
+}
+
+// BatchZones 批量创建、更新可用区。先校验全部数据，有校验失败的行或 preview 为 true 时不写入；
+// 写入在同一事务中完成
+func (r *resource) BatchZones(param view.ReqBatchZone, user *db.User) (resp view.RespBatchResource, err error) {
+	resp.Preview = param.Preview
+	resp.Rows = make([]view.BatchResourceRow, 0, len(param.List))
+
+	var zones []db.Zone
+	err = r.DB.Find(&zones).Error
+	if err != nil {
+		return
+	}
+	existing := make(map[string]db.Zone, len(zones))
+	regionByZone := make(map[string]string, len(zones))
+	for _, zone := range zones {
+		existing[zone.Env+"/"+zone.ZoneCode] = zone
+		regionByZone[zone.ZoneCode] = zone.RegionCode
+	}
+
+	type plan struct {
+		action string
+		item   view.BatchZoneItem
+		id     int
+	}
+	plans := make([]plan, 0, len(param.List))
+	seen := make(map[string]int)
+	for i, item := range param.List {
+		if item.Line == 0 {
+			item.Line = i + 1
+		}
+		key := item.Env + "/" + item.ZoneCode
+		row := view.BatchResourceRow{Line: item.Line, Key: key}
+
+		row.Errors = missingFields([][2]string{
+			{"env", item.Env},
+			{"region_code", item.RegionCode},
+			{"region_name", item.RegionName},
+			{"zone_code", item.ZoneCode},
+			{"zone_name", item.ZoneName},
+		})
+		if line, ok := seen[key]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("与第 %d 行重复", line))
+		}
+		seen[key] = item.Line
+		// 同一可用区在不同环境中属于同一地域
+		if region, ok := regionByZone[item.ZoneCode]; ok && item.RegionCode != "" && region != item.RegionCode {
+			row.Errors = append(row.Errors, fmt.Sprintf("可用区 %s 已属于地域 %s", item.ZoneCode, region))
+		} else if !ok && item.RegionCode != "" {
+			regionByZone[item.ZoneCode] = item.RegionCode
+		}
+
+		zone, ok := existing[key]
+		switch {
+		case len(row.Errors) > 0:
+			row.Action = view.BatchActionInvalid
+		case !ok:
+			row.Action = view.BatchActionCreate
+		case zone.RegionCode == item.RegionCode && zone.RegionName == item.RegionName && zone.ZoneName == item.ZoneName:
+			row.Action = view.BatchActionUnchanged
+		default:
+			row.Action = view.BatchActionUpdate
+		}
+		resp.Add(row)
+		plans = append(plans, plan{action: row.Action, item: item, id: zone.Id})
+	}
+
+	if param.Preview || resp.Invalid > 0 {
+		return
+	}
+
+	now := time.Now().Unix()
+	tx := r.DB.Begin()
+	for _, p := range plans {
+		switch p.action {
+		case view.BatchActionCreate:
+			err = tx.Create(&db.Zone{
+				Env:        p.item.Env,
+				RegionCode: p.item.RegionCode,
+				RegionName: p.item.RegionName,
+				ZoneCode:   p.item.ZoneCode,
+				ZoneName:   p.item.ZoneName,
+				CreateTime: now,
+				UpdateTime: now,
+				CreatedBy:  user.Uid,
+				UpdatedBy:  user.Uid,
+			}).Error
+		case view.BatchActionUpdate:
+			err = tx.Model(db.Zone{}).Where("id = ?", p.id).Updates(map[string]interface{}{
+				"region_code": p.item.RegionCode,
+				"region_name": p.item.RegionName,
+				"zone_name":   p.item.ZoneName,
+				"update_time": now,
+				"updated_by":  user.Uid,
+			}).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+	err = tx.Commit().Error
+	resp.Applied = err == nil
+	return
+}
+
+// BatchNodes 批量创建、更新机器节点，节点所在的可用区必须已存在。
+// 先校验全部数据，有校验失败的行或 preview 为 true 时不写入；写入在同一事务中完成
+func (r *resource) BatchNodes(param view.ReqBatchNode, user *db.User) (resp view.RespBatchResource, err error) {
+	resp.Preview = param.Preview
+	resp.Rows = make([]view.BatchResourceRow, 0, len(param.List))
+
+	var zones []db.Zone
+	err = r.DB.Find(&zones).Error
+	if err != nil {
+		return
+	}
+	zoneByKey := make(map[string]db.Zone, len(zones))
+	for _, zone := range zones {
+		zoneByKey[zone.Env+"/"+zone.ZoneCode] = zone
+	}
+
+	var nodes []db.Node
+	err = r.DB.Find(&nodes).Error
+	if err != nil {
+		return
+	}
+	existing := make(map[string]db.Node, len(nodes))
+	for _, node := range nodes {
+		existing[node.HostName] = node
+	}
+
+	type plan struct {
+		action string
+		node   db.Node
+	}
+	plans := make([]plan, 0, len(param.List))
+	seen := make(map[string]int)
+	for i, item := range param.List {
+		if item.Line == 0 {
+			item.Line = i + 1
+		}
+		row := view.BatchResourceRow{Line: item.Line, Key: item.HostName}
+
+		row.Errors = missingFields([][2]string{
+			{"host_name", item.HostName},
+			{"ip", item.IP},
+			{"env", item.Env},
+			{"zone_code", item.ZoneCode},
+		})
+		if item.IP != "" && !validIP(item.IP) {
+			row.Errors = append(row.Errors, fmt.Sprintf("IP %s 格式错误", item.IP))
+		}
+		if line, ok := seen[item.HostName]; ok && item.HostName != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("与第 %d 行重复", line))
+		}
+		seen[item.HostName] = item.Line
+		zone, zoneExists := zoneByKey[item.Env+"/"+item.ZoneCode]
+		if !zoneExists && item.Env != "" && item.ZoneCode != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("环境 %s 下不存在可用区 %s", item.Env, item.ZoneCode))
+		}
+
+		node, ok := existing[item.HostName]
+		target := node
+		target.HostName = item.HostName
+		target.Ip = item.IP
+		target.Env = item.Env
+		target.RegionCode = zone.RegionCode
+		target.RegionName = zone.RegionName
+		target.ZoneCode = zone.ZoneCode
+		target.ZoneName = zone.ZoneName
+		switch {
+		case len(row.Errors) > 0:
+			row.Action = view.BatchActionInvalid
+		case !ok:
+			row.Action = view.BatchActionCreate
+		case node == target:
+			row.Action = view.BatchActionUnchanged
+		default:
+			row.Action = view.BatchActionUpdate
+		}
+		resp.Add(row)
+		plans = append(plans, plan{action: row.Action, node: target})
+	}
+
+	if param.Preview || resp.Invalid > 0 {
+		return
+	}
+
+	now := time.Now().Unix()
+	tx := r.DB.Begin()
+	for i, p := range plans {
+		switch p.action {
+		case view.BatchActionCreate:
+			p.node.NodeType = 2
+			p.node.CreateTime = now
+			p.node.UpdateTime = now
+			err = tx.Create(&p.node).Error
+		case view.BatchActionUpdate:
+			p.node.UpdateTime = now
+			err = tx.Model(db.Node{}).Where("id = ?", p.node.Id).Updates(map[string]interface{}{
+				"ip":          p.node.Ip,
+				"env":         p.node.Env,
+				"region_code": p.node.RegionCode,
+				"region_name": p.node.RegionName,
+				"zone_code":   p.node.ZoneCode,
+				"zone_name":   p.node.ZoneName,
+				"update_time": now,
+			}).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		plans[i] = p
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+	resp.Applied = true
+
+	for _, p := range plans {
+		meta, _ := json.Marshal(p.node)
+		switch p.action {
+		case view.BatchActionCreate:
+			appevent.AppEvent.NodeCreateEvent(p.node.ZoneCode, p.node.Env, p.node.HostName, string(meta), user)
+		case view.BatchActionUpdate:
+			appevent.AppEvent.NodeUpdateEvent(p.node.ZoneCode, p.node.Env, p.node.HostName, string(meta), user)
+		}
+	}
+	return
+}
@@ -0,0 +1,90 @@
+package resource
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// maxBatchRows 单次批量导入的最大行数
+const maxBatchRows = 5000
+
+// csvRow CSV 中的一行，Line 为行号便于用户定位，文件中的空行不计入
+type csvRow struct {
+	Line   int
+	Fields map[string]string
+}
+
+// parseCSV 按表头解析 CSV，表头不区分大小写，缺少 required 中的列时返回错误。空行和 # 开头的行跳过
+func parseCSV(reader io.Reader, required []string) (rows []csvRow, err error) {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV 文件为空")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		// Excel 导出的 UTF-8 CSV 带 BOM
+		columns[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	}
+	for _, name := range required {
+		if !inStrings(columns, name) {
+			return nil, fmt.Errorf("CSV 缺少列 %s", name)
+		}
+	}
+
+	// 表头为第 1 行
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) > 0 && strings.HasPrefix(strings.TrimSpace(record[0]), "#") {
+			continue
+		}
+
+		row := csvRow{Line: line, Fields: make(map[string]string, len(columns))}
+		empty := true
+		for i, name := range columns {
+			if i < len(record) {
+				row.Fields[name] = strings.TrimSpace(record[i])
+				if row.Fields[name] != "" {
+					empty = false
+				}
+			}
+		}
+		if empty {
+			continue
+		}
+		if len(rows) >= maxBatchRows {
+			return nil, fmt.Errorf("单次最多导入 %d 行", maxBatchRows)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// missingFields 为空的必填字段，fields 为字段名、值
+func missingFields(fields [][2]string) (errs []string) {
+	for _, field := range fields {
+		if field[1] == "" {
+			errs = append(errs, fmt.Sprintf("%s 不能为空", field[0]))
+		}
+	}
+	return
+}
+
+func validIP(ip string) bool {
+	return net.ParseIP(ip) != nil
+}
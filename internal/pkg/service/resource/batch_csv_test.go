@@ -0,0 +1,46 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	content := "\ufeffHost_Name, ip ,env,zone_code\n" +
+		"host-1,10.0.0.1,prod,wh\n" +
+		"# 注释\n" +
+		",,,\n" +
+		"host-2,10.0.0.2,prod\n"
+
+	rows, err := parseCSV(strings.NewReader(content), nodeCSVColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if rows[0].Line != 2 || rows[0].Fields["host_name"] != "host-1" || rows[0].Fields["ip"] != "10.0.0.1" {
+		t.Errorf("row 0 = %+v", rows[0])
+	}
+	if rows[1].Line != 5 || rows[1].Fields["zone_code"] != "" {
+		t.Errorf("row 1 = %+v", rows[1])
+	}
+
+	_, err = parseCSV(strings.NewReader("host_name,ip\n"), nodeCSVColumns)
+	if err == nil || err.Error() != "CSV 缺少列 env" {
+		t.Errorf("missing column err = %v", err)
+	}
+	if _, err = parseCSV(strings.NewReader(""), nodeCSVColumns); err == nil {
+		t.Errorf("empty csv should fail")
+	}
+}
+
+func TestMissingFields(t *testing.T) {
+	errs := missingFields([][2]string{{"env", "prod"}, {"zone_code", ""}})
+	if len(errs) != 1 || errs[0] != "zone_code 不能为空" {
+		t.Errorf("errs = %v", errs)
+	}
+	if !validIP("10.0.0.1") || validIP("10.0.0") {
+		t.Errorf("validIP")
+	}
+}
@@ -0,0 +1,186 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// Catalog 导出应用目录：应用基本信息、负责人、环境、实例及配置中解析出的依赖
+func (r *resource) Catalog(param view.ReqCatalogExport) (catalog view.Catalog, err error) {
+	var apps []db.AppInfo
+	query := r.DB.Model(&db.AppInfo{})
+	if !param.IncludeArchived {
+		query = query.Where("status not in (?)", hiddenAppStatus)
+	}
+	if param.TeamID != 0 {
+		query = query.Where("team_id = ?", param.TeamID)
+	}
+	if param.AppName != "" {
+		query = query.Where("app_name = ?", param.AppName)
+	}
+	err = query.Order("app_name").Find(&apps).Error
+	if err != nil {
+		return
+	}
+
+	catalog.GeneratedAt = time.Now()
+	catalog.Apps = make([]view.CatalogApp, 0, len(apps))
+	if len(apps) == 0 {
+		return
+	}
+
+	err = r.FillAppMeta(apps)
+	if err != nil {
+		return
+	}
+
+	aids := make([]int, 0, len(apps))
+	appNames := make([]string, 0, len(apps))
+	for _, app := range apps {
+		aids = append(aids, app.Aid)
+		appNames = append(appNames, app.AppName)
+	}
+
+	var teams []db.Team
+	err = r.DB.Select("id, name").Find(&teams).Error
+	if err != nil {
+		return
+	}
+	teamNames := make(map[uint]string, len(teams))
+	for _, item := range teams {
+		teamNames[item.ID] = item.Name
+	}
+
+	var nodes []db.AppNode
+	query = r.DB.Where("app_name in (?)", appNames)
+	if param.Env != "" {
+		query = query.Where("env = ?", param.Env)
+	}
+	err = query.Order("env, zone_code, host_name").Find(&nodes).Error
+	if err != nil {
+		return
+	}
+
+	var topologies []db.AppTopology
+	query = r.DB.Select("aid, env, type, name, addr").Where("aid in (?)", aids)
+	if param.Env != "" {
+		query = query.Where("env = ?", param.Env)
+	}
+	err = query.Order("env, type, name").Find(&topologies).Error
+	if err != nil {
+		return
+	}
+
+	deployments, err := r.catalogDeployments(appNames)
+	if err != nil {
+		return
+	}
+
+	nodesByApp := make(map[string][]db.AppNode)
+	for _, node := range nodes {
+		nodesByApp[node.AppName] = append(nodesByApp[node.AppName], node)
+	}
+	dependencies := make(map[int][]view.CatalogDependency)
+	seen := make(map[view.CatalogDependency]bool)
+	for _, item := range topologies {
+		dependency := view.CatalogDependency{Env: item.Env, Type: item.Type, Name: item.Name, Addr: item.Addr}
+		if seen[dependency] {
+			continue
+		}
+		seen[dependency] = true
+		dependencies[item.Aid] = append(dependencies[item.Aid], dependency)
+	}
+
+	for _, app := range apps {
+		owners := []string(app.Users)
+		if owners == nil {
+			owners = make([]string, 0)
+		}
+		deps := dependencies[app.Aid]
+		if deps == nil {
+			deps = make([]view.CatalogDependency, 0)
+		}
+		catalog.Apps = append(catalog.Apps, view.CatalogApp{
+			AppName:      app.AppName,
+			Name:         app.Name,
+			Lang:         app.Lang,
+			BizDomain:    app.BizDomain,
+			Level:        app.Level,
+			Status:       app.Status,
+			GitURL:       app.GitURL,
+			WebURL:       app.WebURL,
+			HTTPPort:     app.HTTPPort,
+			RPCPort:      app.RPCPort,
+			GovernPort:   app.GovernPort,
+			Team:         teamNames[app.TeamID],
+			Owners:       owners,
+			Meta:         app.Meta,
+			Envs:         catalogEnvs(nodesByApp[app.AppName], deployments),
+			Dependencies: deps,
+		})
+	}
+	return
+}
+
+// catalogDeployments 各实例最近一次部署，key 为 app_name/host_name
+func (r *resource) catalogDeployments(appNames []string) (latest map[string]db.Deployment, err error) {
+	var ids []uint
+	err = r.DB.Model(&db.Deployment{}).
+		Where("app_name in (?)", appNames).
+		Group("app_name, host_name").
+		Pluck("max(id)", &ids).Error
+	if err != nil {
+		return
+	}
+
+	latest = make(map[string]db.Deployment, len(ids))
+	if len(ids) == 0 {
+		return
+	}
+	var list []db.Deployment
+	err = r.DB.Select("app_name, host_name, version, deployed_at").Where("id in (?)", ids).Find(&list).Error
+	if err != nil {
+		return
+	}
+	for _, item := range list {
+		latest[item.AppName+"/"+item.HostName] = item
+	}
+	return
+}
+
+// catalogEnvs 按环境汇总实例，nodes 已按 env、zone_code、host_name 排序
+func catalogEnvs(nodes []db.AppNode, deployments map[string]db.Deployment) []view.CatalogEnv {
+	envs := make([]view.CatalogEnv, 0)
+	index := make(map[string]int)
+	for _, node := range nodes {
+		i, ok := index[node.Env]
+		if !ok {
+			i = len(envs)
+			index[node.Env] = i
+			envs = append(envs, view.CatalogEnv{
+				Env:       node.Env,
+				Zones:     make([]string, 0),
+				Instances: make([]view.CatalogInstance, 0),
+			})
+		}
+
+		env := &envs[i]
+		if n := len(env.Zones); n == 0 || env.Zones[n-1] != node.ZoneCode {
+			env.Zones = append(env.Zones, node.ZoneCode)
+		}
+		instance := view.CatalogInstance{
+			HostName: node.HostName,
+			IP:       node.IP,
+			ZoneCode: node.ZoneCode,
+		}
+		if item, ok := deployments[node.AppName+"/"+node.HostName]; ok {
+			deployedAt := item.DeployedAt
+			instance.Version = item.Version
+			instance.DeployedAt = &deployedAt
+		}
+		env.Instances = append(env.Instances, instance)
+	}
+	return envs
+}
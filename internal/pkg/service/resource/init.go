@@ -14,5 +14,6 @@ func InitResource(db *gorm.DB) {
 	Resource = &resource{
 		db,
 	}
+	registerRecycleHandler()
 	return
 }
@@ -0,0 +1,116 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/douyu/juno/internal/pkg/service/k8scluster"
+	"github.com/douyu/juno/pkg/k8s"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/util"
+)
+
+// AppWorkloadList 应用在 k8s 集群中的 Deployment、StatefulSet 及其 Pod。
+// 查询集群管理中的集群，工作负载通过集群配置的应用标签与应用关联；zones 不为空时只查询这些机房的集群
+func (r *resource) AppWorkloadList(param view.ReqAppWorkloadList, zones ...string) (resp view.RespAppWorkloadList, err error) {
+	clusters, err := k8scluster.K8sCluster.List()
+	if err != nil {
+		return
+	}
+
+	resp.List = make([]view.K8sWorkload, 0)
+	resp.Errors = make([]string, 0)
+	for _, cluster := range clusters {
+		if param.ZoneCode != "" && cluster.ZoneCode != param.ZoneCode {
+			continue
+		}
+		if param.Env != "" && !inStrings(cluster.Env, param.Env) {
+			continue
+		}
+		if len(zones) > 0 && !inStrings(zones, cluster.ZoneCode) {
+			continue
+		}
+
+		workloads, err := clusterWorkloads(cluster, param.AppName)
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("集群 %s: %s", cluster.Name, err.Error()))
+			continue
+		}
+		resp.List = append(resp.List, workloads...)
+	}
+	return resp, nil
+}
+
+func clusterWorkloads(cluster db.K8sCluster, appName string) (list []view.K8sWorkload, err error) {
+	client, err := k8scluster.K8sCluster.Client(cluster)
+	if err != nil {
+		return
+	}
+
+	namespace := k8scluster.Namespace(cluster)
+	appSelector := k8s.Selector(map[string]string{k8scluster.AppLabel(cluster): appName})
+
+	deployments, err := client.ListDeployments(namespace, appSelector)
+	if err != nil {
+		return
+	}
+	statefulSets, err := client.ListStatefulSets(namespace, appSelector)
+	if err != nil {
+		return
+	}
+
+	for _, workload := range append(deployments, statefulSets...) {
+		// 工作负载的 selector 比应用标签更精确，可以区分同一应用的多个工作负载
+		selector := k8s.Selector(workload.Spec.Selector.MatchLabels)
+		if selector == "" {
+			selector = appSelector
+		}
+
+		pods, err := client.ListPods(namespace, selector)
+		if err != nil {
+			return nil, err
+		}
+
+		item := view.K8sWorkload{
+			Cluster:         cluster.Name,
+			ZoneCode:        cluster.ZoneCode,
+			ZoneName:        cluster.ZoneName,
+			Namespace:       namespace,
+			Kind:            workload.Kind,
+			Name:            workload.Metadata.Name,
+			Replicas:        workload.DesiredReplicas(),
+			ReadyReplicas:   workload.Status.ReadyReplicas,
+			UpdatedReplicas: workload.Status.UpdatedReplicas,
+			ImageTags:       make([]string, 0),
+			CreatedAt:       workload.Metadata.CreationTimestamp,
+			Pods:            make([]view.K8sPod, 0, len(pods)),
+		}
+		for _, container := range workload.Spec.Template.Spec.Containers {
+			tag := k8s.ImageTag(container.Image)
+			if !inStrings(item.ImageTags, tag) {
+				item.ImageTags = append(item.ImageTags, tag)
+			}
+		}
+		for _, pod := range pods {
+			ready, total := pod.Ready()
+			item.Pods = append(item.Pods, view.K8sPod{
+				Name:      pod.Metadata.Name,
+				Status:    pod.DisplayStatus(),
+				Ready:     fmt.Sprintf("%d/%d", ready, total),
+				Restarts:  pod.Restarts(),
+				Node:      pod.Spec.NodeName,
+				HostIP:    pod.Status.HostIP,
+				PodIP:     pod.Status.PodIP,
+				ImageTags: pod.ImageTags(),
+				StartTime: pod.Status.StartTime,
+			})
+		}
+		list = append(list, item)
+	}
+	return
+}
+
+func inStrings(list []string, s string) bool {
+	_, ok := util.InArray(s, list)
+	return ok
+}
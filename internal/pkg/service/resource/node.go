@@ -7,9 +7,12 @@ import (
 	"time"
 
 	"github.com/douyu/juno/internal/pkg/service/appevent"
+	"github.com/douyu/juno/internal/pkg/service/deployment"
+	"github.com/douyu/juno/internal/pkg/service/tag"
 	"github.com/douyu/juno/pkg/model/view"
 
 	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/sqldialect"
 	"github.com/douyu/jupiter/pkg/store/gorm"
 )
 
@@ -27,22 +30,32 @@ func (r *resource) GetNode(tx *gorm.DB, identify interface{}) (resp db.Node, err
 	return
 }
 
-func (r *resource) GetNodeList(where db.Node, currentPage, pageSize int, keyType, keyWords string, sort string) (resp []db.Node, page *view.Pagination, err error) {
+// GetNodeList zones 不为空时只返回这些机房的节点
+// tags 不为空时只返回同时拥有全部标签的节点
+func (r *resource) GetNodeList(where db.Node, currentPage, pageSize int, keyType, keyWords string, sort string, tags []string, zones ...string) (resp []db.Node, page *view.Pagination, err error) {
 	page = view.NewPagination(currentPage, pageSize)
 	sql := r.DB.Model(db.Node{}).Where(where)
+	if len(zones) > 0 {
+		sql = sql.Where("zone_code in (?)", zones)
+	}
 	keyWords = strings.TrimSpace(keyWords)
 	switch keyType {
 	case "ip":
 		if len(keyWords) > 0 {
-			sql = sql.Where("`ip` like ?", "%"+keyWords+"%")
+			sql = sql.Where("ip like ?", "%"+keyWords+"%")
 		}
 	case "host_name":
 		if len(keyWords) > 0 {
-			sql = sql.Where("`host_name` like ?", "%"+keyWords+"%")
+			sql = sql.Where("host_name like ?", "%"+keyWords+"%")
 		}
 	}
+	sql = tag.Filter(sql, db.TagEntityNode, "host_name", tags)
 	sql.Count(&page.Total)
 	err = sql.Order(sort).Offset((page.Current - 1) * page.PageSize).Limit(page.PageSize).Find(&resp).Error
+	if err != nil {
+		return
+	}
+	err = r.fillNodeTags(resp)
 	return
 }
 
@@ -52,7 +65,7 @@ func (r *resource) GetAllNode() (resp []db.Node, err error) {
 }
 
 func (r *resource) NodeDayCnt(start, end int64) (resp []db.NodeCnt, err error) {
-	err = r.DB.Table("node").Select("DATE_FORMAT(FROM_UNIXTIME(create_time), '%Y-%m-%d') as day_time, count(id) as cnt").
+	err = r.DB.Table("node").Select(sqldialect.UnixDay(r.DB, "create_time")+" as day_time, count(id) as cnt").
 		Where("create_time between ? and ?", start, end).Group("day_time").Find(&resp).Error
 	return
 }
@@ -166,12 +179,17 @@ func (r *resource) NodeHeartBeat(reqInfo view.ReqNodeHeartBeat,
 			ProxyVersion:       reqInfo.ProxyVersion,
 		}
 	} else {
+		lastError := []rune(reqInfo.LastError)
+		if len(lastError) > 1024 {
+			lastError = lastError[:1024]
+		}
 		nodeInfo = db.Node{
 			HostName:           reqInfo.Hostname,
 			Ip:                 reqInfo.IP,
 			AgentHeartbeatTime: time.Now().Unix(),
 			AgentType:          reqInfo.AgentType,
 			AgentVersion:       reqInfo.AgentVersion,
+			AgentLastError:     string(lastError),
 		}
 	}
 	isPutZone := false
@@ -184,6 +202,9 @@ func (r *resource) NodeHeartBeat(reqInfo view.ReqNodeHeartBeat,
 		nodeInfo.Env = reqInfo.Env
 		isPutZone = true
 	}
+	if reqInfo.HostFacts != nil {
+		fillHostFacts(&nodeInfo, *reqInfo.HostFacts, time.Now().Unix())
+	}
 	tx := r.DB.Begin()
 	if isPutZone {
 		err = r.PutZone(tx, db.Zone{
@@ -208,6 +229,13 @@ func (r *resource) NodeHeartBeat(reqInfo view.ReqNodeHeartBeat,
 			tx.Rollback()
 			return
 		}
+		if reqInfo.HostFacts != nil {
+			err = tx.Model(db.Node{}).Where("host_name = ?", reqInfo.Hostname).UpdateColumns(hostFactsColumns(nodeInfo)).Error
+			if err != nil {
+				tx.Rollback()
+				return
+			}
+		}
 	} else {
 		nodeInfo.CreateTime = time.Now().Unix()
 		nodeInfo.UpdateTime = time.Now().Unix()
@@ -218,6 +246,14 @@ func (r *resource) NodeHeartBeat(reqInfo view.ReqNodeHeartBeat,
 		}
 	}
 
+	if reqInfo.HostMetrics != nil {
+		err = r.putNodeMetric(tx, reqInfo.Hostname, *reqInfo.HostMetrics)
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+
 	// 如果存在app name，就对app和node进行关联
 	if isPutZone && reqInfo.AppName != "" {
 		var appInfo db.AppInfo
@@ -237,6 +273,10 @@ func (r *resource) NodeHeartBeat(reqInfo view.ReqNodeHeartBeat,
 	}
 
 	tx.Commit()
+
+	if isPutZone && reqInfo.AppName != "" && reqInfo.AppVersion != "" {
+		err = deployment.Deployment.Observe(reqInfo.AppName, reqInfo.Env, reqInfo.ZoneCode, reqInfo.Hostname, reqInfo.AppVersion)
+	}
 	return
 }
 
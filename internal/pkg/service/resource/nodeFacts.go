@@ -0,0 +1,56 @@
+package resource
+
+import (
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+// fillHostFacts 将agent上报的主机信息写入节点，超出字段长度的内容截断
+func fillHostFacts(node *db.Node, facts view.HostFacts, now int64) {
+	node.CPUCount = facts.CPUCount
+	node.MemTotal = facts.MemTotal
+	node.OS = truncate(facts.OS, 128)
+	node.Kernel = truncate(facts.Kernel, 128)
+	node.Arch = truncate(facts.Arch, 32)
+	node.ContainerRuntime = truncate(facts.ContainerRuntime, 32)
+	node.FactsTime = now
+
+	ips := make([]string, 0, len(facts.IPs))
+	length := 0
+	for _, ip := range facts.IPs {
+		ip = strings.TrimSpace(ip)
+		if ip == "" {
+			continue
+		}
+		if length+len(ip)+len(ips) > 512 {
+			break
+		}
+		length += len(ip)
+		ips = append(ips, ip)
+	}
+	node.IPs = strings.Join(ips, ",")
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
+
+// hostFactsColumns 主机信息字段，更新时包含零值，以便清除已不存在的信息
+func hostFactsColumns(node db.Node) map[string]interface{} {
+	return map[string]interface{}{
+		"cpu_count":         node.CPUCount,
+		"mem_total":         node.MemTotal,
+		"os":                node.OS,
+		"kernel":            node.Kernel,
+		"arch":              node.Arch,
+		"ips":               node.IPs,
+		"container_runtime": node.ContainerRuntime,
+		"facts_time":        node.FactsTime,
+	}
+}
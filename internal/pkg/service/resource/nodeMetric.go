@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/store/gorm"
+)
+
+const (
+	// nodeMetricRetention 主机指标历史保留时长
+	nodeMetricRetention = 24 * time.Hour
+	// nodeMetricDefaultRange 查询指标时默认的时间范围
+	nodeMetricDefaultRange = time.Hour
+)
+
+// putNodeMetric 记录agent上报的主机指标，并清理过期的历史数据
+func (r *resource) putNodeMetric(tx *gorm.DB, hostName string, metrics view.HostMetrics) (err error) {
+	now := time.Now()
+	err = tx.Create(&db.NodeMetric{
+		HostName:    hostName,
+		CPUPercent:  metrics.CPUPercent,
+		MemTotal:    metrics.MemTotal,
+		MemUsed:     metrics.MemUsed,
+		MemPercent:  metrics.MemPercent,
+		DiskTotal:   metrics.DiskTotal,
+		DiskUsed:    metrics.DiskUsed,
+		DiskPercent: metrics.DiskPercent,
+		Load1:       metrics.Load1,
+		Load5:       metrics.Load5,
+		Load15:      metrics.Load15,
+		CreateTime:  now.Unix(),
+	}).Error
+	if err != nil {
+		return
+	}
+
+	err = tx.Where("host_name = ? and create_time < ?", hostName, now.Add(-nodeMetricRetention).Unix()).
+		Delete(&db.NodeMetric{}).Error
+	return
+}
+
+// NodeMetricList 获取节点在时间范围内的主机指标
+func (r *resource) NodeMetricList(param view.ReqNodeMetricList) (resp []db.NodeMetric, err error) {
+	if param.EndTime == 0 {
+		param.EndTime = time.Now().Unix()
+	}
+	if param.StartTime == 0 {
+		param.StartTime = param.EndTime - int64(nodeMetricDefaultRange/time.Second)
+	}
+
+	resp = make([]db.NodeMetric, 0)
+	err = r.DB.Where("host_name = ? and create_time between ? and ?", param.HostName, param.StartTime, param.EndTime).
+		Order("create_time asc").Find(&resp).Error
+	return
+}
+
+// LatestNodeMetric 获取节点最近一次上报的主机指标，没有上报过或历史已过期时返回 nil
+func (r *resource) LatestNodeMetric(hostName string) (resp *db.NodeMetric, err error) {
+	var metric db.NodeMetric
+	err = r.DB.Where("host_name = ?", hostName).Order("create_time desc").First(&metric).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+	return &metric, nil
+}
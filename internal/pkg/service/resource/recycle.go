@@ -0,0 +1,41 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/jupiter/pkg/store/gorm"
+)
+
+func registerRecycleHandler() {
+	recyclebin.Register(db.RecycleKindApp, recyclebin.Handler{
+		Restore:      restoreApp,
+		AfterRestore: func(item db.RecycleItem) { InvalidateApp(int(item.ObjectID), item.AppName) },
+	})
+}
+
+// restoreApp 按删除时的快照重新写入应用，保留原 aid，应用名已被占用时不能恢复。
+// 待删除状态的应用恢复为正常状态
+func restoreApp(tx *gorm.DB, item db.RecycleItem) (err error) {
+	var app db.AppInfo
+	err = json.Unmarshal([]byte(item.Snapshot), &app)
+	if err != nil {
+		return
+	}
+
+	var count int
+	err = tx.Table("app").Where("aid = ? or app_name = ?", app.Aid, app.AppName).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		return fmt.Errorf("应用 %s 已存在，不能恢复", app.AppName)
+	}
+
+	if app.Status == db.AppStatusDeleted {
+		app.Status = db.AppStatusActive
+	}
+	return tx.Table("app").Create(&app).Error
+}
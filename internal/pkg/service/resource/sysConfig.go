@@ -9,10 +9,10 @@ func (r *resource) GetSysConfig(sysType int, setCate string) (info []db.SystemCo
 	info = make([]db.SystemConfig, 0)
 	dbConn := r.DB.Table("system_config")
 	if sysType > 0 {
-		dbConn = dbConn.Where("`sys_type` = ?", sysType)
+		dbConn = dbConn.Where("sys_type = ?", sysType)
 	}
 	if setCate != "" {
-		dbConn = dbConn.Where("`set_cate` = ?", setCate)
+		dbConn = dbConn.Where("set_cate = ?", setCate)
 	}
 	err = dbConn.Find(&info).Error
 	// 返回系统错误
@@ -30,7 +30,7 @@ func (r *resource) AddSysConfig(record db.SystemConfig) (err error) {
 }
 
 func (r *resource) DelSysConfig(id int) (err error) {
-	if err := r.DB.Table("system_config").Where("`id` = ?", id).Delete(db.SystemConfig{}).Error; err != nil {
+	if err := r.DB.Table("system_config").Where("id = ?", id).Delete(db.SystemConfig{}).Error; err != nil {
 		return err
 	}
 	return nil
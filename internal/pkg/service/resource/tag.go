@@ -0,0 +1,38 @@
+package resource
+
+import (
+	"github.com/douyu/juno/internal/pkg/service/tag"
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+// fillAppTags 填充应用列表的标签
+func (r *resource) fillAppTags(apps []db.AppInfo) error {
+	keys := make([]string, 0, len(apps))
+	for _, app := range apps {
+		keys = append(keys, app.AppName)
+	}
+	tags, err := tag.Tag.Tags(db.TagEntityApp, keys)
+	if err != nil {
+		return err
+	}
+	for i := range apps {
+		apps[i].Tags = tags[apps[i].AppName]
+	}
+	return nil
+}
+
+// fillNodeTags 填充节点列表的标签
+func (r *resource) fillNodeTags(nodes []db.Node) error {
+	keys := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		keys = append(keys, node.HostName)
+	}
+	tags, err := tag.Tag.Tags(db.TagEntityNode, keys)
+	if err != nil {
+		return err
+	}
+	for i := range nodes {
+		nodes[i].Tags = tags[nodes[i].HostName]
+	}
+	return nil
+}
@@ -25,15 +25,15 @@ func (r *resource) GetZoneList(where db.Zone, currentPage, pageSize int, keyType
 	case "id":
 		id, _ := strconv.Atoi(strings.TrimSpace(keyWords))
 		if id > 0 {
-			sql = sql.Where("`id` = ? ", id)
+			sql = sql.Where("id = ? ", id)
 		}
 	case "zone_code":
 		if len(keyWords) > 0 {
-			sql = sql.Where("`zone_code` like ?", "%"+keyWords+"%")
+			sql = sql.Where("zone_code like ?", "%"+keyWords+"%")
 		}
 	case "zone_name":
 		if len(keyWords) > 0 {
-			sql = sql.Where("`zone_name` like ?", "%"+keyWords+"%")
+			sql = sql.Where("zone_name like ?", "%"+keyWords+"%")
 		}
 	}
 	err = sql.Count(&page.Total).Error
@@ -0,0 +1,382 @@
+package serviceaccount
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const (
+	// TokenPrefix 服务账号凭证明文前缀
+	TokenPrefix = "juno_sa_"
+
+	// 最近使用时间的刷新间隔，worker、agent 心跳频繁，避免每次请求都写库
+	lastUsedInterval = time.Minute
+)
+
+var (
+	ErrInvalidToken      = errors.New("invalid service account token")
+	ErrTokenExpired      = errors.New("service account token expired")
+	ErrTokenRevoked      = errors.New("service account token revoked")
+	ErrAccountDisabled   = errors.New("service account disabled")
+	ErrAccountNotFound   = fmt.Errorf("服务账号不存在")
+	ErrCredentialMissing = fmt.Errorf("凭证不存在")
+)
+
+// ServiceAccount 服务账号
+var ServiceAccount *serviceAccount
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	serviceAccount struct {
+		db *gorm.DB
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	ServiceAccount = &serviceAccount{
+		db: o.DB,
+	}
+}
+
+// Scopes 可用的 scope 列表
+func Scopes() []string {
+	return []string{
+		db.ServiceAccountScopeWorker,
+		db.ServiceAccountScopeAgent,
+		db.ServiceAccountScopeSCIM,
+	}
+}
+
+// Create 创建服务账号及首个凭证，明文只在此处返回
+func (s *serviceAccount) Create(uid int, param view.ReqCreateServiceAccount) (resp view.RespServiceAccountToken, err error) {
+	err = checkScopes(param.Scopes)
+	if err != nil {
+		return
+	}
+
+	var count int
+	err = s.db.Model(&db.ServiceAccount{}).Where("name = ?", param.Name).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		err = fmt.Errorf("服务账号 %s 已存在", param.Name)
+		return
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return
+	}
+
+	tx := s.db.Begin()
+	item := db.ServiceAccount{
+		Name:        param.Name,
+		Description: param.Description,
+		Scopes:      strings.Join(param.Scopes, ","),
+		CreatedBy:   uid,
+	}
+	err = tx.Create(&item).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	credential := newCredential(item.ID, token)
+	err = tx.Create(&credential).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	resp.ServiceAccount = transformAccount(item, []db.ServiceAccountCredential{credential})
+	resp.Token = token
+	return
+}
+
+// List 服务账号列表，包含凭证信息
+func (s *serviceAccount) List() (list []view.ServiceAccount, err error) {
+	var accounts []db.ServiceAccount
+	err = s.db.Order("id desc").Find(&accounts).Error
+	if err != nil {
+		return
+	}
+
+	var credentials []db.ServiceAccountCredential
+	err = s.db.Order("id desc").Find(&credentials).Error
+	if err != nil {
+		return
+	}
+
+	credentialMap := make(map[uint][]db.ServiceAccountCredential)
+	for _, item := range credentials {
+		credentialMap[item.AccountID] = append(credentialMap[item.AccountID], item)
+	}
+
+	list = make([]view.ServiceAccount, 0, len(accounts))
+	for _, item := range accounts {
+		list = append(list, transformAccount(item, credentialMap[item.ID]))
+	}
+	return
+}
+
+// Update 更新服务账号的描述、scopes 和启用状态
+func (s *serviceAccount) Update(param view.ReqUpdateServiceAccount) (err error) {
+	err = checkScopes(param.Scopes)
+	if err != nil {
+		return
+	}
+
+	item, err := s.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	return s.db.Model(&item).Updates(map[string]interface{}{
+		"description": param.Description,
+		"scopes":      strings.Join(param.Scopes, ","),
+		"disabled":    param.Disabled,
+	}).Error
+}
+
+// Delete 删除服务账号及其全部凭证
+func (s *serviceAccount) Delete(id uint) (err error) {
+	item, err := s.find(id)
+	if err != nil {
+		return
+	}
+
+	tx := s.db.Begin()
+	err = tx.Where("account_id = ?", item.ID).Delete(&db.ServiceAccountCredential{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Delete(&item).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	return tx.Commit().Error
+}
+
+// Rotate 生成新凭证，旧凭证在过渡期后失效，便于逐台更新 worker 配置
+func (s *serviceAccount) Rotate(param view.ReqRotateServiceAccount) (resp view.RespServiceAccountToken, err error) {
+	item, err := s.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(param.GraceMinutes) * time.Minute)
+	tx := s.db.Begin()
+	err = tx.Model(&db.ServiceAccountCredential{}).
+		Where("account_id = ? and revoked_at is null and (expires_at is null or expires_at > ?)", item.ID, expiresAt).
+		UpdateColumn("expires_at", expiresAt).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	credential := newCredential(item.ID, token)
+	err = tx.Create(&credential).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	var credentials []db.ServiceAccountCredential
+	err = s.db.Where("account_id = ?", item.ID).Order("id desc").Find(&credentials).Error
+	if err != nil {
+		return
+	}
+
+	resp.ServiceAccount = transformAccount(item, credentials)
+	resp.Token = token
+	return
+}
+
+// RevokeCredential 立即吊销某个凭证
+func (s *serviceAccount) RevokeCredential(id uint) (err error) {
+	var item db.ServiceAccountCredential
+	err = s.db.Where("id = ?", id).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return ErrCredentialMissing
+		}
+		return
+	}
+
+	if item.RevokedAt != nil {
+		return nil
+	}
+
+	return s.db.Model(&item).Update("revoked_at", time.Now()).Error
+}
+
+// Authenticate 校验凭证，返回凭证所属的服务账号
+func (s *serviceAccount) Authenticate(token, clientIP string) (account db.ServiceAccount, err error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		err = ErrInvalidToken
+		return
+	}
+
+	var credential db.ServiceAccountCredential
+	err = s.db.Where("token_hash = ?", hashToken(token)).First(&credential).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrInvalidToken
+		}
+		return
+	}
+
+	now := time.Now()
+	if credential.RevokedAt != nil {
+		err = ErrTokenRevoked
+		return
+	}
+	if credential.ExpiresAt != nil && credential.ExpiresAt.Before(now) {
+		err = ErrTokenExpired
+		return
+	}
+
+	err = s.db.Where("id = ?", credential.AccountID).First(&account).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrInvalidToken
+		}
+		return
+	}
+	if account.Disabled {
+		err = ErrAccountDisabled
+		return
+	}
+
+	if account.LastUsedAt == nil || now.Sub(*account.LastUsedAt) > lastUsedInterval || account.LastUsedIP != clientIP {
+		err = s.db.Model(&account).UpdateColumns(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": clientIP,
+		}).Error
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Allowed 服务账号是否拥有 scope
+func Allowed(account db.ServiceAccount, scope string) bool {
+	for _, item := range splitScopes(account.Scopes) {
+		if item == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *serviceAccount) find(id uint) (item db.ServiceAccount, err error) {
+	err = s.db.Where("id = ?", id).First(&item).Error
+	if err != nil && gorm.IsRecordNotFoundError(err) {
+		err = ErrAccountNotFound
+	}
+	return
+}
+
+func checkScopes(scopes []string) error {
+	for _, scope := range scopes {
+		valid := false
+		for _, item := range Scopes() {
+			if item == scope {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid scope: %s", scope)
+		}
+	}
+	return nil
+}
+
+func newCredential(accountID uint, token string) db.ServiceAccountCredential {
+	return db.ServiceAccountCredential{
+		AccountID: accountID,
+		Prefix:    token[:len(TokenPrefix)+4],
+		TokenHash: hashToken(token),
+	}
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 20)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", errors.Wrap(err, "generate token failed")
+	}
+	return TokenPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return nil
+	}
+	return strings.Split(scopes, ",")
+}
+
+func transformAccount(item db.ServiceAccount, credentials []db.ServiceAccountCredential) view.ServiceAccount {
+	resp := view.ServiceAccount{
+		ID:          item.ID,
+		Name:        item.Name,
+		Description: item.Description,
+		Scopes:      splitScopes(item.Scopes),
+		Disabled:    item.Disabled,
+		CreatedAt:   item.CreatedAt,
+		LastUsedAt:  item.LastUsedAt,
+		LastUsedIP:  item.LastUsedIP,
+		Credentials: make([]view.ServiceAccountCredential, 0, len(credentials)),
+	}
+	for _, credential := range credentials {
+		resp.Credentials = append(resp.Credentials, view.ServiceAccountCredential{
+			ID:        credential.ID,
+			Prefix:    credential.Prefix,
+			CreatedAt: credential.CreatedAt,
+			ExpiresAt: credential.ExpiresAt,
+			RevokedAt: credential.RevokedAt,
+		})
+	}
+	return resp
+}
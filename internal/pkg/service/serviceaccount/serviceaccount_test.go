@@ -0,0 +1,53 @@
+package serviceaccount
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		scopes string
+		scope  string
+		want   bool
+	}{
+		{db.ServiceAccountScopeWorker, db.ServiceAccountScopeWorker, true},
+		{db.ServiceAccountScopeWorker, db.ServiceAccountScopeAgent, false},
+		{db.ServiceAccountScopeWorker + "," + db.ServiceAccountScopeAgent, db.ServiceAccountScopeAgent, true},
+		{"", db.ServiceAccountScopeWorker, false},
+	}
+
+	for _, tt := range tests {
+		if got := Allowed(db.ServiceAccount{Scopes: tt.scopes}, tt.scope); got != tt.want {
+			t.Errorf("Allowed(%q, %s) = %v, want %v", tt.scopes, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestCheckScopes(t *testing.T) {
+	if err := checkScopes([]string{db.ServiceAccountScopeWorker, db.ServiceAccountScopeAgent}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := checkScopes([]string{"config:read"}); err == nil {
+		t.Errorf("expected error for unknown scope")
+	}
+}
+
+func TestNewCredential(t *testing.T) {
+	token, err := generateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(token, TokenPrefix) || len(token) != len(TokenPrefix)+40 {
+		t.Errorf("unexpected token %q", token)
+	}
+
+	credential := newCredential(1, token)
+	if !strings.HasPrefix(token, credential.Prefix) || credential.TokenHash != hashToken(token) {
+		t.Errorf("unexpected credential %+v", credential)
+	}
+}
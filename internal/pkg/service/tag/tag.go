@@ -0,0 +1,195 @@
+package tag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	maxTagLength    = 64
+	maxTagsOfEntity = 20
+)
+
+// Tag 应用、节点、流水线的标签
+var Tag *tag
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	tag struct {
+		db *gorm.DB
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	Tag = &tag{
+		db: o.DB,
+	}
+}
+
+// Set 覆盖实体的标签，返回规范化后的标签
+func (t *tag) Set(entityType, entityKey string, tags []string) (list []string, err error) {
+	list, err = Normalize(tags)
+	if err != nil {
+		return
+	}
+	err = t.checkEntity(entityType, entityKey)
+	if err != nil {
+		return
+	}
+
+	tx := t.db.Begin()
+	query := tx.Where("entity_type = ? and entity_key = ?", entityType, entityKey)
+	if len(list) > 0 {
+		query = query.Where("tag not in (?)", list)
+	}
+	err = query.Delete(&db.EntityTag{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	var exists []string
+	err = tx.Model(&db.EntityTag{}).Where("entity_type = ? and entity_key = ?", entityType, entityKey).
+		Pluck("tag", &exists).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	existed := make(map[string]bool, len(exists))
+	for _, item := range exists {
+		existed[item] = true
+	}
+	for _, item := range list {
+		if existed[item] {
+			continue
+		}
+		err = tx.Create(&db.EntityTag{
+			EntityType: entityType,
+			EntityKey:  entityKey,
+			Tag:        item,
+			CreatedAt:  time.Now(),
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+
+	err = tx.Commit().Error
+	return
+}
+
+// Get 实体的标签
+func (t *tag) Get(entityType, entityKey string) (tags []string, err error) {
+	tags = make([]string, 0)
+	err = t.db.Model(&db.EntityTag{}).Where("entity_type = ? and entity_key = ?", entityType, entityKey).
+		Order("tag").Pluck("tag", &tags).Error
+	return
+}
+
+// Tags 批量获取实体的标签，key 为实体 EntityKey
+func (t *tag) Tags(entityType string, entityKeys []string) (tags map[string][]string, err error) {
+	tags = make(map[string][]string)
+	if len(entityKeys) == 0 {
+		return
+	}
+
+	var list []db.EntityTag
+	err = t.db.Where("entity_type = ? and entity_key in (?)", entityType, entityKeys).Order("tag").Find(&list).Error
+	if err != nil {
+		return
+	}
+	for _, item := range list {
+		tags[item.EntityKey] = append(tags[item.EntityKey], item.Tag)
+	}
+	return
+}
+
+// Suggest 标签自动补全，entityType 为空时统计全部实体
+func (t *tag) Suggest(param view.ReqSuggestTags) (list []view.TagCount, err error) {
+	limit := param.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	query := t.db.Model(&db.EntityTag{}).Select("tag, count(*) as count")
+	if param.EntityType != "" {
+		query = query.Where("entity_type = ?", param.EntityType)
+	}
+	prefix := strings.ToLower(strings.TrimSpace(param.Prefix))
+	if prefix != "" {
+		query = query.Where("tag like ?", listquery.EscapeLike(prefix)+"%")
+	}
+
+	list = make([]view.TagCount, 0)
+	err = query.Group("tag").Order("count desc, tag").Limit(limit).Scan(&list).Error
+	return
+}
+
+// Filter 只保留同时拥有全部标签的实体，column 为 query 中实体 EntityKey 对应的列
+func Filter(query *gorm.DB, entityType, column string, tags []string) *gorm.DB {
+	tags, _ = Normalize(tags)
+	if len(tags) == 0 {
+		return query
+	}
+	return query.Where(column+" in (?)", query.New().Model(&db.EntityTag{}).
+		Select("entity_key").
+		Where("entity_type = ? and tag in (?)", entityType, tags).
+		Group("entity_key").
+		Having("count(*) = ?", len(tags)).
+		SubQuery())
+}
+
+// Normalize 标签统一为小写，去除首尾空白并去重。标签中不能包含逗号，便于在通知规则中以逗号分隔保存
+func Normalize(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	list := make([]string, 0, len(tags))
+	for _, item := range tags {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" || seen[item] {
+			continue
+		}
+		if strings.Contains(item, ",") {
+			return nil, fmt.Errorf("标签 %s 不能包含逗号", item)
+		}
+		if len([]rune(item)) > maxTagLength {
+			return nil, fmt.Errorf("标签 %s 超过 %d 个字符", item, maxTagLength)
+		}
+		seen[item] = true
+		list = append(list, item)
+	}
+	if len(list) > maxTagsOfEntity {
+		return nil, fmt.Errorf("最多设置 %d 个标签", maxTagsOfEntity)
+	}
+	return list, nil
+}
+
+func (t *tag) checkEntity(entityType, entityKey string) (err error) {
+	var count int
+	switch entityType {
+	case db.TagEntityApp:
+		err = t.db.Model(&db.AppInfo{}).Where("app_name = ?", entityKey).Count(&count).Error
+	case db.TagEntityNode:
+		err = t.db.Model(&db.Node{}).Where("host_name = ?", entityKey).Count(&count).Error
+	case db.TagEntityPipeline:
+		id, _ := strconv.Atoi(entityKey)
+		err = t.db.Model(&db.TestPipeline{}).Where("id = ?", id).Count(&count).Error
+	default:
+		return fmt.Errorf("不支持的标签对象 %s", entityType)
+	}
+	if err == nil && count == 0 {
+		err = fmt.Errorf("%s %s 不存在", entityType, entityKey)
+	}
+	return
+}
@@ -0,0 +1,29 @@
+package tag
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize([]string{" Payment ", "payment", "", "core"})
+	if err != nil || !reflect.DeepEqual(got, []string{"payment", "core"}) {
+		t.Errorf("Normalize = %v, %v", got, err)
+	}
+
+	if _, err = Normalize([]string{"a,b"}); err == nil {
+		t.Error("tag with comma should be rejected")
+	}
+	if _, err = Normalize([]string{strings.Repeat("标", maxTagLength+1)}); err == nil {
+		t.Error("too long tag should be rejected")
+	}
+
+	tags := make([]string, 0, maxTagsOfEntity+1)
+	for i := 0; i <= maxTagsOfEntity; i++ {
+		tags = append(tags, strings.Repeat("t", i+1))
+	}
+	if _, err = Normalize(tags); err == nil {
+		t.Error("too many tags should be rejected")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/douyu/juno/internal/pkg/service/clientproxy"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/sqldialect"
 	"github.com/douyu/jupiter/pkg/worker/xcron"
 	"github.com/douyu/jupiter/pkg/xlog"
 	"github.com/jinzhu/gorm"
@@ -75,8 +76,9 @@ func (j *CronJob) List(params view.ReqQueryJobs) (list []view.CronJobListItem, p
 	}
 	if params.User != nil {
 		username := "%" + *params.User + "%"
-		query = query.Joins("left join user on cron_job.uid = user.uid")
-		query = query.Where("user.username like ? or user.nickname like ?", username, username)
+		user := sqldialect.Quote(j.db, "user")
+		query = query.Joins("left join " + user + " on cron_job.uid = " + user + ".uid")
+		query = query.Where(user+".username like ? or "+user+".nickname like ?", username, username)
 	}
 
 	eg := errgroup.Group{}
@@ -338,7 +340,13 @@ func (j *CronJob) DispatchOnce(id uint, node string) (err error) {
 		}
 	}
 
-	return tx.Commit().Error
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	publishTask(task)
+	return
 }
 
 //ListTask 任务列表
@@ -593,6 +601,10 @@ func (j *CronJob) checkTask(id uint64) {
 	}
 
 	tx.Commit()
+
+	if task.Status == db.CronTaskStatusFailed {
+		publishTask(task)
+	}
 }
 
 func makeOnceJob(job db.CronJob, taskId uint64) OnceJob {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/coreos/etcd/clientv3"
 	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/douyu/juno/internal/pkg/service/wsevent"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/view"
 	"github.com/douyu/jupiter/pkg/xlog"
@@ -141,6 +142,23 @@ func (r *ResultWatcher) updateTask(result TaskResult) {
 		}
 	}
 	tx.Commit()
+
+	publishTask(task)
+}
+
+// publishTask 推送定时任务执行状态变化
+func publishTask(task db.CronTask) {
+	wsevent.Publish(wsevent.Event{
+		Topic: wsevent.TopicTask,
+		Key:   "cron/" + strconv.FormatUint(uint64(task.JobID), 10),
+		Zone:  task.Zone,
+		Data: map[string]interface{}{
+			"task_id": task.ID,
+			"job_id":  task.JobID,
+			"node":    task.Node,
+			"status":  task.Status,
+		},
+	})
 }
 
 func (r *ResultWatcher) deleteResult(key []byte) {
@@ -0,0 +1,497 @@
+package team
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/service/casbin"
+	"github.com/douyu/juno/internal/pkg/service/notifyrule"
+	"github.com/douyu/juno/internal/pkg/service/oncall"
+	"github.com/douyu/juno/internal/pkg/service/resource"
+	"github.com/douyu/juno/internal/pkg/service/user"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// Team 团队管理
+	Team *team
+
+	// DefaultActions 未指定时团队成员对团队应用默认拥有的权限
+	DefaultActions = []string{db.AppPermAppRead, db.AppPermConfigRead, db.AppPermPipelineRead}
+
+	ErrTeamNotFound = fmt.Errorf("团队不存在")
+)
+
+type (
+	Option struct {
+		DB *gorm.DB
+	}
+
+	team struct {
+		db *gorm.DB
+	}
+)
+
+// Init ..
+func Init(o Option) {
+	Team = &team{
+		db: o.DB,
+	}
+}
+
+// List 团队列表
+func (t *team) List(param view.ReqListTeam) (list []view.Team, page *view.Pagination, err error) {
+	var teams []db.Team
+
+	page = view.NewPagination(param.Page, param.PageSize)
+	query := t.db.Model(&db.Team{})
+	if param.Keyword != "" {
+		query = query.Where("name like ?", "%"+param.Keyword+"%")
+	}
+
+	err = query.Count(&page.Total).
+		Order("id desc").
+		Offset((page.Current - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&teams).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.Team, 0, len(teams))
+	for _, item := range teams {
+		teamView := transformTeam(item)
+
+		err = t.db.Model(&db.TeamMember{}).Where("team_id = ?", item.ID).Count(&teamView.MemberCount).Error
+		if err != nil {
+			return
+		}
+
+		err = t.db.Model(&db.AppInfo{}).Where("team_id = ?", item.ID).Count(&teamView.AppCount).Error
+		if err != nil {
+			return
+		}
+
+		list = append(list, teamView)
+	}
+
+	return
+}
+
+// Detail 团队详情，包含成员和应用
+func (t *team) Detail(param view.ReqTeamDetail) (resp view.TeamDetail, err error) {
+	item, err := t.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	var members []db.TeamMember
+	err = t.db.Where("team_id = ?", item.ID).Order("id").Find(&members).Error
+	if err != nil {
+		return
+	}
+
+	var apps []db.AppInfo
+	err = t.db.Select("app_name").Where("team_id = ?", item.ID).Order("app_name").Find(&apps).Error
+	if err != nil {
+		return
+	}
+
+	resp.Team = transformTeam(item)
+	resp.Team.MemberCount = len(members)
+	resp.Team.AppCount = len(apps)
+
+	resp.Members = make([]view.TeamMember, 0, len(members))
+	for _, member := range members {
+		var u db.User
+		err = t.db.Where("uid = ?", member.Uid).First(&u).Error
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			return
+		}
+
+		resp.Members = append(resp.Members, view.TeamMember{
+			Uid:      member.Uid,
+			Username: u.Username,
+			Nickname: u.Nickname,
+			Role:     member.Role,
+		})
+	}
+
+	resp.Apps = make([]string, 0, len(apps))
+	for _, app := range apps {
+		resp.Apps = append(resp.Apps, app.AppName)
+	}
+
+	return resp, nil
+}
+
+// Create 创建团队，创建者为团队 owner
+func (t *team) Create(uid int, param view.ReqCreateTeam) (err error) {
+	actions, err := checkActions(param.DefaultActions)
+	if err != nil {
+		return
+	}
+
+	var count int
+	err = t.db.Model(&db.Team{}).Where("name = ?", param.Name).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count > 0 {
+		return fmt.Errorf("团队 %s 已存在", param.Name)
+	}
+
+	tx := t.db.Begin()
+	item := db.Team{
+		Name:              param.Name,
+		Description:       param.Description,
+		DefaultActions:    strings.Join(actions, ","),
+		DingWebhook:       param.DingWebhook,
+		MandatorySeverity: param.MandatorySeverity,
+	}
+	err = tx.Create(&item).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Create(&db.TeamMember{
+		TeamID: item.ID,
+		Uid:    uid,
+		Role:   db.TeamMemberRoleOwner,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	_ = casbin.Casbin.LoadPolicy()
+	return
+}
+
+// Update 更新团队信息
+func (t *team) Update(param view.ReqUpdateTeam) (err error) {
+	actions, err := checkActions(param.DefaultActions)
+	if err != nil {
+		return
+	}
+
+	item, err := t.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	if item.Name != param.Name {
+		var count int
+		err = t.db.Model(&db.Team{}).Where("name = ? and id != ?", param.Name, item.ID).Count(&count).Error
+		if err != nil {
+			return
+		}
+		if count > 0 {
+			return fmt.Errorf("团队 %s 已存在", param.Name)
+		}
+	}
+
+	err = t.db.Model(&item).Updates(map[string]interface{}{
+		"name":               param.Name,
+		"description":        param.Description,
+		"default_actions":    strings.Join(actions, ","),
+		"ding_webhook":       param.DingWebhook,
+		"mandatory_severity": param.MandatorySeverity,
+	}).Error
+	if err != nil {
+		return
+	}
+
+	_ = casbin.Casbin.LoadPolicy()
+	return
+}
+
+// Delete 删除团队，团队应用变为无归属
+func (t *team) Delete(param view.ReqDeleteTeam) (err error) {
+	item, err := t.find(param.ID)
+	if err != nil {
+		return
+	}
+
+	var appNames []string
+	err = t.db.Model(&db.AppInfo{}).Where("team_id = ?", item.ID).Pluck("app_name", &appNames).Error
+	if err != nil {
+		return
+	}
+
+	tx := t.db.Begin()
+	err = tx.Model(&db.AppInfo{}).Where("team_id = ?", item.ID).UpdateColumn("team_id", 0).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Where("team_id = ?", item.ID).Delete(&db.TeamMember{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Delete(&item).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+	for _, appName := range appNames {
+		resource.InvalidateApp(0, appName)
+	}
+
+	_ = casbin.Casbin.LoadPolicy()
+	return
+}
+
+// SetMember 添加团队成员或修改成员角色
+func (t *team) SetMember(param view.ReqSetTeamMember) (err error) {
+	item, err := t.find(param.TeamID)
+	if err != nil {
+		return
+	}
+
+	var u db.User
+	err = t.db.Where("uid = ?", param.Uid).First(&u).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("用户不存在")
+		}
+		return
+	}
+
+	var member db.TeamMember
+	err = t.db.Where("team_id = ? and uid = ?", item.ID, param.Uid).First(&member).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	if member.ID == 0 {
+		err = t.db.Create(&db.TeamMember{
+			TeamID: item.ID,
+			Uid:    param.Uid,
+			Role:   param.Role,
+		}).Error
+	} else {
+		err = t.db.Model(&member).UpdateColumn("role", param.Role).Error
+	}
+	if err != nil {
+		return
+	}
+
+	_ = casbin.Casbin.LoadPolicy()
+	return
+}
+
+// RemoveMember 移除团队成员
+func (t *team) RemoveMember(param view.ReqRemoveTeamMember) (err error) {
+	_, err = t.find(param.TeamID)
+	if err != nil {
+		return
+	}
+
+	err = t.db.Where("team_id = ? and uid = ?", param.TeamID, param.Uid).Delete(&db.TeamMember{}).Error
+	if err != nil {
+		return
+	}
+
+	_ = casbin.Casbin.LoadPolicy()
+	return
+}
+
+// SetAppTeam 设置应用所属团队
+func (t *team) SetAppTeam(param view.ReqSetAppTeam) (err error) {
+	if param.TeamID != 0 {
+		_, err = t.find(param.TeamID)
+		if err != nil {
+			return
+		}
+	}
+
+	query := t.db.Model(&db.AppInfo{}).Where("app_name = ?", param.AppName).UpdateColumn("team_id", param.TeamID)
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		return fmt.Errorf("应用 %s 不存在", param.AppName)
+	}
+	resource.InvalidateApp(0, param.AppName)
+
+	_ = casbin.Casbin.LoadPolicy()
+	return
+}
+
+// UserTeams 用户所在的团队
+func (t *team) UserTeams(uid int) (list []view.Team, err error) {
+	var teams []db.Team
+	err = t.db.Table("team").
+		Joins("inner join team_member on team_member.team_id = team.id and team_member.deleted_at is null").
+		Where("team_member.uid = ?", uid).
+		Find(&teams).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.Team, 0, len(teams))
+	for _, item := range teams {
+		list = append(list, transformTeam(item))
+	}
+	return
+}
+
+// AppTeam 应用所属团队，未设置时返回 ErrTeamNotFound
+func (t *team) AppTeam(appName string) (item db.Team, err error) {
+	var app db.AppInfo
+	err = t.db.Select("team_id").Where("app_name = ?", appName).First(&app).Error
+	if err != nil {
+		return
+	}
+
+	if app.TeamID == 0 {
+		err = ErrTeamNotFound
+		return
+	}
+
+	return t.find(app.TeamID)
+}
+
+// Notify 发送应用相关通知。
+// 钉钉优先发送到应用所属团队的机器人，未设置时发送到全局机器人；邮件发送给应用所属团队成员；企业微信 @ 应用负责人；
+// 团队成员和负责人按个人通知偏好接收，达到团队强制通知级别的通知不受个人偏好影响；
+// 发送渠道由通知路由规则决定，未命中规则时发送到全部已开启的渠道；error 级别的告警同时通知值班人员
+func (t *team) Notify(e notice.Event) {
+	item, err := t.AppTeam(e.App)
+
+	var owners []string
+	if len(e.Mentions) == 0 {
+		owners = t.appOwners(e.App, item.ID)
+	}
+	t.notify(e, item, err, owners)
+}
+
+// NotifyTeam 发送通知到指定团队而非应用所属团队，用于应用转入前通知目标团队，@ 团队 owner
+func (t *team) NotifyTeam(teamID uint, e notice.Event) {
+	item, err := t.find(teamID)
+
+	var owners []string
+	if err == nil && len(e.Mentions) == 0 {
+		owners = t.teamOwners(teamID)
+	}
+	t.notify(e, item, err, owners)
+}
+
+// IsOwner 用户是否为团队 owner
+func (t *team) IsOwner(teamID uint, uid int) (bool, error) {
+	var count int
+	err := t.db.Model(&db.TeamMember{}).
+		Where("team_id = ? and uid = ? and role = ?", teamID, uid, db.TeamMemberRoleOwner).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// notify 按团队成员的通知偏好和团队机器人发送通知，err 为查询团队的错误，不为空时不通知团队成员
+func (t *team) notify(e notice.Event, item db.Team, err error, owners []string) {
+	var uids []int
+	if err == nil && cfg.Cfg.Notice.Email.Enable && len(e.Emails) == 0 {
+		err = t.db.Model(&db.TeamMember{}).Where("team_id = ?", item.ID).Pluck("uid", &uids).Error
+		if err != nil {
+			xlog.Error("team.Notify load members failed", xlog.String("app", e.App), xlog.String("err", err.Error()))
+		}
+	}
+	if len(owners) > 0 || len(uids) > 0 {
+		mandatory := item.MandatorySeverity != "" && notice.SeverityLevel(e.Severity) >= notice.SeverityLevel(item.MandatorySeverity)
+		err = user.User.ApplyNotifyPrefs(&e, uids, owners, mandatory)
+		if err != nil {
+			xlog.Error("team.Notify apply notify prefs failed", xlog.String("app", e.App), xlog.String("err", err.Error()))
+		}
+	}
+	if e.DingWebhook == "" {
+		e.DingWebhook = item.DingWebhook
+	}
+
+	notifyrule.NotifyRule.Notify(e)
+	oncall.OnCall.Page(e)
+}
+
+// appOwners 应用负责人用户名，未设置负责人时使用应用所属团队的 owner
+func (t *team) appOwners(appName string, teamID uint) (owners []string) {
+	var app db.AppInfo
+	err := t.db.Select("users").Where("app_name = ?", appName).First(&app).Error
+	if err == nil && len(app.Users) > 0 {
+		return app.Users
+	}
+	if teamID == 0 {
+		return nil
+	}
+	return t.teamOwners(teamID)
+}
+
+// teamOwners 团队 owner 用户名
+func (t *team) teamOwners(teamID uint) (owners []string) {
+	err := t.db.Table("user").
+		Joins("inner join team_member on team_member.uid = user.uid and team_member.deleted_at is null").
+		Where("team_member.team_id = ? and team_member.role = ?", teamID, db.TeamMemberRoleOwner).
+		Pluck("user.username", &owners).Error
+	if err != nil {
+		xlog.Error("team.teamOwners load team owners failed", xlog.Uint("team", teamID), xlog.String("err", err.Error()))
+	}
+	return
+}
+
+func (t *team) find(id uint) (item db.Team, err error) {
+	err = t.db.Where("id = ?", id).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrTeamNotFound
+		}
+		return
+	}
+	return
+}
+
+func checkActions(actions []string) ([]string, error) {
+	if len(actions) == 0 {
+		return DefaultActions, nil
+	}
+
+	for _, act := range actions {
+		if !casbin.Casbin.CheckAppPermissionKeyValid(act) {
+			return nil, fmt.Errorf("无效的应用权限Key: %s", act)
+		}
+	}
+	return actions, nil
+}
+
+func transformTeam(item db.Team) view.Team {
+	actions := make([]string, 0)
+	if item.DefaultActions != "" {
+		actions = strings.Split(item.DefaultActions, ",")
+	}
+
+	return view.Team{
+		ID:                item.ID,
+		Name:              item.Name,
+		Description:       item.Description,
+		DefaultActions:    actions,
+		DingWebhook:       item.DingWebhook,
+		MandatorySeverity: item.MandatorySeverity,
+		CreatedAt:         item.CreatedAt,
+	}
+}
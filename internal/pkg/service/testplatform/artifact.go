@@ -0,0 +1,142 @@
+package testplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/service/artifact"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// maxArtifactSize 保存在数据库中的单个制品的大小上限
+const maxArtifactSize = 16 * 1024 * 1024
+
+var (
+	ErrArtifactTooLarge    = fmt.Errorf("制品超过大小限制")
+	ErrInvalidArtifactName = fmt.Errorf("制品名称格式错误，应为不包含 .. 的相对路径")
+)
+
+// PrepareTaskArtifact 签发制品的上传地址，没有配置对象存储时返回 Storage 为 false，worker 直接上报制品内容
+func PrepareTaskArtifact(params view.ReqPrepareTaskArtifact) (upload view.TestTaskArtifactUpload, err error) {
+	if !validArtifactName(params.Name) {
+		return upload, ErrInvalidArtifactName
+	}
+	if !artifact.Enabled() {
+		return
+	}
+	if params.Size > artifact.MaxSize() {
+		return upload, ErrArtifactTooLarge
+	}
+
+	var task db.TestPipelineTask
+	err = option.DB.Select("id").Where("id = ?", params.TaskID).First(&task).Error
+	if err != nil {
+		return
+	}
+
+	key := artifact.ObjectKey(params.TaskID, params.Name)
+	return view.TestTaskArtifactUpload{
+		Storage: true,
+		URL:     artifact.UploadURL(key),
+		Key:     key,
+	}, nil
+}
+
+// onTaskArtifact 保存 worker 上传的制品，同一任务下同名制品覆盖。
+// 已上传到对象存储的制品只记录 key，大小以对象存储中的为准
+func onTaskArtifact(params view.TestTaskEvent) (err error) {
+	var eventData view.TestTaskArtifactPayload
+	err = json.Unmarshal(params.Data, &eventData)
+	if err != nil {
+		return errors.Wrapf(err, "invalid event data")
+	}
+	if !validArtifactName(eventData.Name) {
+		return ErrInvalidArtifactName
+	}
+
+	size := len(eventData.Content)
+	if eventData.StorageKey != "" {
+		// 只接受 PrepareTaskArtifact 签发的 key，避免记录其他任务的对象
+		if !artifact.Enabled() || eventData.StorageKey != artifact.ObjectKey(params.TaskID, eventData.Name) {
+			return fmt.Errorf("invalid artifact storage key %s", eventData.StorageKey)
+		}
+		var objectSize int64
+		objectSize, err = artifact.Stat(eventData.StorageKey)
+		if err != nil {
+			return
+		}
+		if objectSize > artifact.MaxSize() {
+			return ErrArtifactTooLarge
+		}
+		size = int(objectSize)
+		eventData.Content = nil
+	} else if size > maxArtifactSize {
+		return ErrArtifactTooLarge
+	}
+
+	var item db.TestTaskArtifact
+	err = option.DB.Where("task_id = ? and name = ?", params.TaskID, eventData.Name).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	item.TaskID = params.TaskID
+	item.StepName = eventData.StepName
+	item.Name = eventData.Name
+	item.ContentType = eventData.ContentType
+	item.Size = size
+	item.Content = eventData.Content
+	item.StorageKey = eventData.StorageKey
+	return option.DB.Save(&item).Error
+}
+
+// validArtifactName 制品名称为以 / 分隔的相对路径，同时用作对象存储的 key
+func validArtifactName(name string) bool {
+	if name == "" || len(name) > 255 || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") {
+		return false
+	}
+	return path.Clean(name) == name && name != ".." && !strings.HasPrefix(name, "../")
+}
+
+// TaskArtifacts 任务的制品列表，不包含内容
+func TaskArtifacts(taskID uint) (list []view.TestTaskArtifact, err error) {
+	var artifacts []db.TestTaskArtifact
+	err = option.DB.Select("id, created_at, task_id, step_name, name, content_type, size").
+		Where("task_id = ?", taskID).Order("id").Find(&artifacts).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.TestTaskArtifact, 0, len(artifacts))
+	for _, item := range artifacts {
+		list = append(list, view.TestTaskArtifact{
+			ID:          item.ID,
+			TaskID:      item.TaskID,
+			StepName:    item.StepName,
+			Name:        item.Name,
+			ContentType: item.ContentType,
+			Size:        item.Size,
+			CreatedAt:   item.CreatedAt,
+		})
+	}
+	return
+}
+
+// TaskArtifact 制品详情，包括保存在数据库中的内容
+func TaskArtifact(taskID uint, name string) (item db.TestTaskArtifact, err error) {
+	err = option.DB.Where("task_id = ? and name = ?", taskID, name).First(&item).Error
+	return
+}
+
+// ArtifactDownloadURL 保存在对象存储中的制品的预签名下载地址，之后取消了对象存储的配置时返回 ErrObjectNotFound
+func ArtifactDownloadURL(item db.TestTaskArtifact) (string, error) {
+	if !artifact.Enabled() {
+		return "", artifact.ErrObjectNotFound
+	}
+	return artifact.DownloadURL(item.StorageKey, item.Name, item.ContentType), nil
+}
@@ -0,0 +1,21 @@
+package testplatform
+
+import "testing"
+
+func TestValidArtifactName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"report.html":         true,
+		"reports/a/junit.xml": true,
+		"":                    false,
+		"/etc/passwd":         false,
+		"../other/report":     false,
+		"a/../../b":           false,
+		"a//b":                false,
+		"./a":                 false,
+		`bin\app`:             false,
+	} {
+		if got := validArtifactName(name); got != want {
+			t.Errorf("validArtifactName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
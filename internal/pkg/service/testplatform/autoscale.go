@@ -0,0 +1,258 @@
+package testplatform
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/eventbus"
+	"github.com/douyu/juno/internal/pkg/service/testplatform/workerpool"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/metrics"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+const (
+	scaleUp   = "scale_up"
+	scaleDown = "scale_down"
+
+	defaultAutoscaleInterval = 30 * time.Second
+	// pendingWindow 只统计该时间内创建的排队任务，排队状态一直没有更新的任务不再计入
+	pendingWindow = 24 * time.Hour
+)
+
+type (
+	// zoneScale 机房最近一次发布的扩缩容建议，同一建议不重复发布
+	zoneScale struct {
+		action    string
+		idleSince time.Time // 开始没有排队和执行中的任务的时间
+	}
+
+	zoneTaskCount struct {
+		ZoneCode string
+		Count    int
+		Oldest   *time.Time
+	}
+)
+
+// startAutoscaleTask 定期统计各机房的排队情况，更新监控指标，需要扩缩容时发布 worker.scale 事件。
+// 多个 Juno 实例会分别发布，订阅方需要自行去重
+func startAutoscaleTask() {
+	conf := option.Autoscale
+	if !conf.Enable {
+		return
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = defaultAutoscaleInterval
+	}
+
+	go func() {
+		states := make(map[string]*zoneScale)
+		for {
+			err := checkAutoscale(conf, states)
+			if err != nil {
+				xlog.Error("checkAutoscale failed", xlog.String("err", err.Error()))
+			}
+
+			time.Sleep(conf.Interval)
+		}
+	}()
+}
+
+func checkAutoscale(conf cfg.TestAutoscale, states map[string]*zoneScale) error {
+	stats, err := WorkerQueueStats()
+	if err != nil {
+		return err
+	}
+
+	queues := make([]metrics.TestQueue, 0, len(stats))
+	now := time.Now()
+	current := make(map[string]bool, len(stats))
+	for _, stat := range stats {
+		queues = append(queues, metrics.TestQueue{
+			Zone:           stat.ZoneCode,
+			Depth:          stat.QueueDepth,
+			OldestWait:     time.Duration(stat.OldestWait * float64(time.Second)),
+			Running:        stat.Running,
+			Workers:        stat.Workers,
+			DrainedWorkers: stat.DrainedWorkers,
+		})
+
+		current[stat.ZoneCode] = true
+		state := states[stat.ZoneCode]
+		if state == nil {
+			state = &zoneScale{}
+			states[stat.ZoneCode] = state
+		}
+		action, reason := nextScaleAction(state, stat, conf, now)
+		if action == "" {
+			continue
+		}
+
+		xlog.Info("worker autoscale", xlog.String("zone", stat.ZoneCode), xlog.String("action", action), xlog.String("reason", reason))
+		eventbus.Publish(eventbus.TopicWorkerScale, eventbus.WorkerScale{
+			Action:         action,
+			ZoneCode:       stat.ZoneCode,
+			Reason:         reason,
+			QueueDepth:     stat.QueueDepth,
+			OldestWait:     stat.OldestWait,
+			Running:        stat.Running,
+			Workers:        stat.Workers,
+			DrainedWorkers: stat.DrainedWorkers,
+		})
+	}
+	metrics.SetTestQueues(queues)
+
+	for zone := range states {
+		if !current[zone] {
+			delete(states, zone)
+		}
+	}
+	return nil
+}
+
+// nextScaleAction 根据机房当前的排队情况判断是否需要扩缩容，与上一次的建议相同时返回空字符串
+func nextScaleAction(state *zoneScale, stat view.WorkerQueueStat, conf cfg.TestAutoscale, now time.Time) (action, reason string) {
+	wait := time.Duration(stat.OldestWait * float64(time.Second))
+	switch {
+	case stat.QueueDepth > 0 && stat.Workers == 0:
+		action, reason = scaleUp, "no available worker"
+	case conf.ScaleUpQueueDepth > 0 && stat.QueueDepth >= conf.ScaleUpQueueDepth:
+		action, reason = scaleUp, fmt.Sprintf("queue depth %d reached %d", stat.QueueDepth, conf.ScaleUpQueueDepth)
+	case conf.ScaleUpWaitTime > 0 && wait >= conf.ScaleUpWaitTime:
+		action, reason = scaleUp, fmt.Sprintf("oldest task waited %s, limit %s", wait.Truncate(time.Second), conf.ScaleUpWaitTime)
+	}
+
+	if stat.QueueDepth == 0 && stat.Running == 0 {
+		if state.idleSince.IsZero() {
+			state.idleSince = now
+		}
+	} else {
+		state.idleSince = time.Time{}
+	}
+	if action == "" && conf.ScaleDownIdle > 0 && stat.Workers > 0 &&
+		!state.idleSince.IsZero() && now.Sub(state.idleSince) >= conf.ScaleDownIdle {
+		action, reason = scaleDown, fmt.Sprintf("idle for %s", conf.ScaleDownIdle)
+	}
+
+	if action == state.action {
+		return "", ""
+	}
+	state.action = action
+	return
+}
+
+// WorkerQueueStats 各机房排队、执行中的任务数和在线的 worker 数，按机房排序
+func WorkerQueueStats() (stats []view.WorkerQueueStat, err error) {
+	now := time.Now()
+	byZone := make(map[string]*view.WorkerQueueStat)
+	zoneStat := func(zone string) *view.WorkerQueueStat {
+		if byZone[zone] == nil {
+			byZone[zone] = &view.WorkerQueueStat{ZoneCode: zone}
+		}
+		return byZone[zone]
+	}
+
+	var pending, running []zoneTaskCount
+	err = option.DB.Model(&db.TestPipelineTask{}).
+		Select("zone_code, count(*) as count, min(created_at) as oldest").
+		Where("status = ? and created_at >= ?", db.TestTaskStatusPending, now.Add(-pendingWindow)).
+		Group("zone_code").Scan(&pending).Error
+	if err != nil {
+		return
+	}
+	err = option.DB.Model(&db.TestPipelineTask{}).
+		Select("zone_code, count(*) as count").
+		Where("status = ?", db.TestTaskStatusRunning).
+		Group("zone_code").Scan(&running).Error
+	if err != nil {
+		return
+	}
+
+	var nodes []db.WorkerNode
+	err = option.DB.Select("zone_code, drained").
+		Where("last_heartbeat >= ?", now.Add(-option.Worker.HeartbeatTimeout)).Find(&nodes).Error
+	if err != nil {
+		return
+	}
+
+	for _, item := range pending {
+		stat := zoneStat(item.ZoneCode)
+		stat.QueueDepth = item.Count
+		if item.Oldest != nil {
+			stat.OldestWait = now.Sub(*item.Oldest).Seconds()
+		}
+	}
+	for _, item := range running {
+		zoneStat(item.ZoneCode).Running = item.Count
+	}
+	for _, node := range nodes {
+		stat := zoneStat(node.ZoneCode)
+		if node.Drained {
+			stat.DrainedWorkers++
+		} else {
+			stat.Workers++
+		}
+	}
+
+	stats = make([]view.WorkerQueueStat, 0, len(byZone))
+	for _, stat := range byZone {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ZoneCode < stats[j].ZoneCode })
+	return
+}
+
+// DrainWorker 不再向 worker 下发新任务，worker 执行完已经领取的任务后空闲，可以安全下线
+func DrainWorker(params view.ReqDrainWorker) (status view.WorkerDrainStatus, err error) {
+	return setWorkerDrained(params, true)
+}
+
+// UndrainWorker 恢复向 worker 下发任务
+func UndrainWorker(params view.ReqDrainWorker) (status view.WorkerDrainStatus, err error) {
+	return setWorkerDrained(params, false)
+}
+
+func setWorkerDrained(params view.ReqDrainWorker, drained bool) (status view.WorkerDrainStatus, err error) {
+	node, err := findWorkerNode(params)
+	if err != nil {
+		return
+	}
+
+	err = workerpool.Instance().SetDrained(node, drained)
+	if err != nil {
+		return
+	}
+
+	url := "/api/v1/undrain"
+	if drained {
+		url = "/api/v1/drain"
+	}
+	err = callWorker(node.ID, view.ReqHTTPProxy{URL: url, Type: http.MethodPost}, &status)
+	return
+}
+
+// WorkerDrainStatus worker 的 drain 状态和剩余的任务数
+func WorkerDrainStatus(params view.ReqDrainWorker) (status view.WorkerDrainStatus, err error) {
+	node, err := findWorkerNode(params)
+	if err != nil {
+		return
+	}
+
+	err = callWorker(node.ID, view.ReqHTTPProxy{URL: "/api/v1/drain", Type: http.MethodGet}, &status)
+	return
+}
+
+func findWorkerNode(params view.ReqDrainWorker) (node db.WorkerNode, err error) {
+	if params.NodeID != 0 {
+		err = option.DB.Where("id = ?", params.NodeID).First(&node).Error
+		return
+	}
+
+	err = option.DB.Where("host_name = ? and zone_code = ?", params.HostName, params.ZoneCode).
+		Order("last_heartbeat desc").First(&node).Error
+	return
+}
@@ -0,0 +1,56 @@
+package testplatform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func TestNextScaleAction(t *testing.T) {
+	conf := cfg.TestAutoscale{
+		ScaleUpQueueDepth: 5,
+		ScaleUpWaitTime:   time.Minute,
+		ScaleDownIdle:     10 * time.Minute,
+	}
+	state := &zoneScale{}
+	now := time.Now()
+
+	steps := []struct {
+		stat   view.WorkerQueueStat
+		after  time.Duration
+		action string
+	}{
+		{stat: view.WorkerQueueStat{QueueDepth: 2, OldestWait: 10, Workers: 2}},
+		{stat: view.WorkerQueueStat{QueueDepth: 5, OldestWait: 10, Workers: 2}, action: scaleUp},
+		// 同一建议不重复发布
+		{stat: view.WorkerQueueStat{QueueDepth: 3, OldestWait: 90, Workers: 3}},
+		{stat: view.WorkerQueueStat{Running: 1, Workers: 3}},
+		{stat: view.WorkerQueueStat{Workers: 3}},
+		{stat: view.WorkerQueueStat{Workers: 3}, after: 9 * time.Minute},
+		{stat: view.WorkerQueueStat{Workers: 3}, after: 10 * time.Minute, action: scaleDown},
+		{stat: view.WorkerQueueStat{Workers: 3}, after: 20 * time.Minute},
+		{stat: view.WorkerQueueStat{QueueDepth: 1, OldestWait: 1, DrainedWorkers: 1}, after: 21 * time.Minute, action: scaleUp},
+	}
+	for i, step := range steps {
+		action, reason := nextScaleAction(state, step.stat, conf, now.Add(step.after))
+		if action != step.action {
+			t.Errorf("step %d: action = %q (%s), want %q", i, action, reason, step.action)
+		}
+	}
+}
+
+func TestNextScaleActionDisabled(t *testing.T) {
+	state := &zoneScale{}
+	now := time.Now()
+	for _, after := range []time.Duration{0, time.Hour} {
+		stat := view.WorkerQueueStat{QueueDepth: 100, OldestWait: 3600, Workers: 1}
+		if after > 0 {
+			stat = view.WorkerQueueStat{Workers: 1}
+		}
+		if action, _ := nextScaleAction(state, stat, cfg.TestAutoscale{}, now.Add(after)); action != "" {
+			t.Errorf("action = %q, want none when thresholds are not set", action)
+		}
+	}
+}
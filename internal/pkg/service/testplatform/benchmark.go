@@ -0,0 +1,142 @@
+package testplatform
+
+import (
+	"encoding/json"
+
+	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// defaultBenchmarkHistoryLimit 基准测试历史默认返回的次数
+const defaultBenchmarkHistoryLimit = 10
+
+// onTaskBenchmark 保存基准测试结果，同一阶段重复上报时覆盖之前的结果
+func onTaskBenchmark(params view.TestTaskEvent) (err error) {
+	var eventData view.TestTaskBenchmarkPayload
+	err = json.Unmarshal(params.Data, &eventData)
+	if err != nil {
+		return errors.Wrapf(err, "invalid event data")
+	}
+
+	var task db.TestPipelineTask
+	err = option.DB.Select("id, pipeline_id, app_name, branch, commit_sha").Where("id = ?", params.TaskID).First(&task).Error
+	if err != nil {
+		return
+	}
+
+	tx := option.DB.Begin()
+	err = tx.Unscoped().Where("task_id = ? and step_name = ?", task.ID, eventData.StepName).
+		Delete(&db.TestBenchmark{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Create(&db.TestBenchmark{
+		TaskID:         task.ID,
+		PipelineID:     task.PipelineID,
+		StepName:       eventData.StepName,
+		AppName:        task.AppName,
+		Branch:         task.Branch,
+		CommitSHA:      task.CommitSHA,
+		BaselineTaskID: eventData.BaselineTaskID,
+		Results:        eventData.Results,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit().Error
+}
+
+// benchmarkBaseline 流水线在该分支上最近一次的基准测试结果，没有时返回 nil
+func benchmarkBaseline(pipelineID uint, branch string) (baseline *pipeline.JobBenchmarkBaseline, err error) {
+	var item db.TestBenchmark
+	err = option.DB.Where("pipeline_id = ? and branch = ?", pipelineID, branch).Order("id desc").First(&item).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+	return &pipeline.JobBenchmarkBaseline{
+		TaskID:    item.TaskID,
+		CommitSHA: item.CommitSHA,
+		Results:   item.Results,
+	}, nil
+}
+
+// TaskBenchmarks 任务的基准测试结果，与下发任务时的基准对比
+func TaskBenchmarks(taskID uint) (list []view.Benchmark, err error) {
+	var items []db.TestBenchmark
+	err = option.DB.Where("task_id = ?", taskID).Order("id").Find(&items).Error
+	if err != nil {
+		return
+	}
+	return benchmarkViews(items)
+}
+
+// BenchmarkHistory 流水线最近的基准测试结果，指定提交时只返回该提交的结果
+func BenchmarkHistory(params view.ReqBenchmarkHistory) (list []view.Benchmark, err error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultBenchmarkHistoryLimit
+	}
+
+	query := option.DB.Where("pipeline_id = ?", params.PipelineID)
+	if params.CommitSHA != "" {
+		var commitSHA string
+		commitSHA, err = normalizeCommitSHA(params.CommitSHA)
+		if err != nil {
+			return
+		}
+		query = query.Where("commit_sha = ?", commitSHA)
+	}
+
+	var items []db.TestBenchmark
+	err = query.Order("id desc").Limit(limit).Find(&items).Error
+	if err != nil {
+		return
+	}
+	return benchmarkViews(items)
+}
+
+// benchmarkViews 查询各结果的基准任务，逐项对比
+func benchmarkViews(items []db.TestBenchmark) (list []view.Benchmark, err error) {
+	baselineIDs := make([]uint, 0)
+	for _, item := range items {
+		if item.BaselineTaskID != 0 {
+			baselineIDs = append(baselineIDs, item.BaselineTaskID)
+		}
+	}
+	baselines := make(map[uint]db.BenchmarkResults)
+	if len(baselineIDs) > 0 {
+		var baseItems []db.TestBenchmark
+		err = option.DB.Select("task_id, results").Where("task_id in (?)", baselineIDs).Find(&baseItems).Error
+		if err != nil {
+			return
+		}
+		for _, item := range baseItems {
+			baselines[item.TaskID] = append(baselines[item.TaskID], item.Results...)
+		}
+	}
+
+	list = make([]view.Benchmark, 0, len(items))
+	for _, item := range items {
+		results, regressions := pipeline.CompareBenchmarks(item.Results, baselines[item.BaselineTaskID], pipeline.DefaultBenchmarkThreshold)
+		list = append(list, view.Benchmark{
+			TaskID:         item.TaskID,
+			StepName:       item.StepName,
+			Branch:         item.Branch,
+			CommitSHA:      item.CommitSHA,
+			BaselineTaskID: item.BaselineTaskID,
+			Results:        results,
+			Regressions:    regressions,
+			CreatedAt:      item.CreatedAt,
+		})
+	}
+	return
+}
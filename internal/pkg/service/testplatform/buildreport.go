@@ -0,0 +1,111 @@
+package testplatform
+
+import (
+	"encoding/json"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/pkg/errors"
+)
+
+// defaultBuildReportTrendLimit 构建趋势默认返回的次数
+const defaultBuildReportTrendLimit = 30
+
+// onTaskBuildReport 保存构建报告，同一阶段重复上报时覆盖之前的结果
+func onTaskBuildReport(params view.TestTaskEvent) (err error) {
+	var eventData view.TestTaskBuildReportPayload
+	err = json.Unmarshal(params.Data, &eventData)
+	if err != nil {
+		return errors.Wrapf(err, "invalid event data")
+	}
+
+	var task db.TestPipelineTask
+	err = option.DB.Select("id, pipeline_id, app_name, branch").Where("id = ?", params.TaskID).First(&task).Error
+	if err != nil {
+		return
+	}
+
+	tx := option.DB.Begin()
+	err = tx.Unscoped().Where("task_id = ? and step_name = ?", task.ID, eventData.StepName).
+		Delete(&db.TestBuildReport{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Create(&db.TestBuildReport{
+		TaskID:       task.ID,
+		PipelineID:   task.PipelineID,
+		StepName:     eventData.StepName,
+		AppName:      task.AppName,
+		Branch:       task.Branch,
+		Package:      eventData.Package,
+		BinarySize:   eventData.BinarySize,
+		BuildSeconds: eventData.BuildSeconds,
+		Packages:     eventData.Packages,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit().Error
+}
+
+// TaskBuildReports 任务的构建报告，包含占用最大的包
+func TaskBuildReports(taskID uint) (list []view.BuildReport, err error) {
+	var items []db.TestBuildReport
+	err = option.DB.Where("task_id = ?", taskID).Order("id").Find(&items).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.BuildReport, 0, len(items))
+	for _, item := range items {
+		report := buildReportView(item)
+		report.Packages = item.Packages
+		list = append(list, report)
+	}
+	return
+}
+
+// BuildReportTrend 流水线最近的构建报告，按时间正序，SizeDelta 为相对上一次构建的大小变化
+func BuildReportTrend(params view.ReqBuildReportTrend) (list []view.BuildReport, err error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultBuildReportTrendLimit
+	}
+
+	// 多查一条用于计算第一条的大小变化
+	var items []db.TestBuildReport
+	err = option.DB.Select("id, created_at, task_id, step_name, branch, package, binary_size, build_seconds").
+		Where("pipeline_id = ?", params.PipelineID).
+		Order("id desc").Limit(limit + 1).Find(&items).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.BuildReport, 0, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		report := buildReportView(items[i])
+		if i+1 < len(items) {
+			report.SizeDelta = items[i].BinarySize - items[i+1].BinarySize
+		}
+		list = append(list, report)
+	}
+	if len(list) > limit {
+		list = list[1:]
+	}
+	return
+}
+
+func buildReportView(item db.TestBuildReport) view.BuildReport {
+	return view.BuildReport{
+		TaskID:       item.TaskID,
+		StepName:     item.StepName,
+		Branch:       item.Branch,
+		Package:      item.Package,
+		BinarySize:   item.BinarySize,
+		BuildSeconds: item.BuildSeconds,
+		CreatedAt:    item.CreatedAt,
+	}
+}
@@ -0,0 +1,84 @@
+package testplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+var (
+	ErrTaskCanceled = fmt.Errorf("任务已取消")
+	ErrTaskFinished = fmt.Errorf("任务已结束")
+)
+
+// CancelTask 取消排队中或执行中的任务，未结束的阶段标记为跳过。
+// 执行中的任务通知同一机房的 worker 终止进程，没有收到通知的 worker 在下次上报状态时得知任务已取消；
+// 排队中的任务在 worker 开始执行时跳过
+func CancelTask(taskID uint, operator string) (err error) {
+	var task db.TestPipelineTask
+
+	tx := option.DB.Begin()
+	err = tx.Where("id = ?", taskID).First(&task).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	if isTaskFinished(task.Status) {
+		tx.Rollback()
+		return ErrTaskFinished
+	}
+
+	prevStatus := task.Status
+	task.Status = db.TestTaskStatusCanceled
+	task.Logs += fmt.Sprintf("task canceled by %s\n", operator)
+	err = tx.Save(&task).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Model(&db.TestPipelineStepStatus{}).
+		Where("task_id = ? and status in (?)", task.ID, []db.TestStepStatus{db.TestStepStatusWaiting, db.TestStepStatusRunning}).
+		Update("status", db.TestStepStatusSkipped).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	publishTask(task, "")
+	notifyTaskFinished(task, prevStatus)
+	if prevStatus == db.TestTaskStatusRunning {
+		go cancelOnWorkers(task)
+	}
+	return
+}
+
+// cancelOnWorkers 任务经过共享队列时不知道由哪个 worker 执行，通知同一机房的全部在线 worker
+func cancelOnWorkers(task db.TestPipelineTask) {
+	var nodes []db.WorkerNode
+	err := option.DB.Where("zone_code = ? and last_heartbeat >= ?", task.ZoneCode, time.Now().Add(-option.Worker.HeartbeatTimeout)).
+		Find(&nodes).Error
+	if err != nil {
+		xlog.Error("cancelOnWorkers: query worker nodes failed", xlog.String("err", err.Error()))
+		return
+	}
+
+	body, _ := json.Marshal(view.ReqCancelTask{TaskID: task.ID})
+	for _, node := range nodes {
+		err = callWorker(node.ID, view.ReqHTTPProxy{URL: "/api/v1/testTask/cancel", Type: http.MethodPost, Body: body}, nil)
+		if err != nil {
+			xlog.Warn("cancelOnWorkers: cancel task failed",
+				xlog.Int("taskId", int(task.ID)), xlog.String("host", node.HostName), xlog.String("err", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package testplatform
+
+import (
+	"fmt"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+var (
+	ErrCompareDifferentPipeline = fmt.Errorf("只能对比同一流水线的任务")
+)
+
+// CompareTasks 对比同一流水线的两次任务：各阶段耗时、新失败的测试、覆盖率和日志大小
+func CompareTasks(params view.ReqCompareTasks) (result view.TaskComparison, err error) {
+	var task, base db.TestPipelineTask
+	err = option.DB.Select("id, pipeline_id, status, logs").Where("id = ?", params.TaskID).First(&task).Error
+	if err != nil {
+		return
+	}
+	err = option.DB.Select("id, pipeline_id, status, logs").Where("id = ?", params.BaseTaskID).First(&base).Error
+	if err != nil {
+		return
+	}
+	if task.PipelineID != base.PipelineID {
+		err = ErrCompareDifferentPipeline
+		return
+	}
+
+	var steps, baseSteps []db.TestPipelineStepStatus
+	err = option.DB.Where("task_id = ?", task.ID).Order("id").Find(&steps).Error
+	if err != nil {
+		return
+	}
+	err = option.DB.Where("task_id = ?", base.ID).Order("id").Find(&baseSteps).Error
+	if err != nil {
+		return
+	}
+
+	result = view.TaskComparison{
+		TaskID:      task.ID,
+		BaseTaskID:  base.ID,
+		Status:      task.Status,
+		BaseStatus:  base.Status,
+		Steps:       compareSteps(steps, baseSteps),
+		LogSize:     len(task.Logs),
+		BaseLogSize: len(base.Logs),
+	}
+
+	current, previous := newTestResults(), newTestResults()
+	for _, step := range steps {
+		result.LogSize += len(step.Logs)
+		current.parse(step.Logs)
+	}
+	for _, step := range baseSteps {
+		result.BaseLogSize += len(step.Logs)
+		previous.parse(step.Logs)
+	}
+	result.LogSizeDelta = result.LogSize - result.BaseLogSize
+	result.NewFailures, result.FixedTests = compareTests(current, previous)
+	result.Coverage = compareCoverage(current.coverage, previous.coverage)
+	return
+}
+
+// compareSteps 按阶段名对比，顺序为本次任务的阶段在前，只在对比任务中存在的阶段在后
+func compareSteps(steps, baseSteps []db.TestPipelineStepStatus) []view.StepComparison {
+	items := make(map[string]*view.StepComparison)
+	var names []string
+	item := func(name string) *view.StepComparison {
+		if _, ok := items[name]; !ok {
+			items[name] = &view.StepComparison{StepName: name}
+			names = append(names, name)
+		}
+		return items[name]
+	}
+
+	for _, step := range steps {
+		c := item(step.StepName)
+		c.Status = string(step.Status)
+		c.Duration = step.UpdatedAt.Sub(step.CreatedAt).Seconds()
+		c.LogSize = len(step.Logs)
+	}
+	for _, step := range baseSteps {
+		c := item(step.StepName)
+		c.BaseStatus = string(step.Status)
+		c.BaseDuration = step.UpdatedAt.Sub(step.CreatedAt).Seconds()
+		c.BaseLogSize = len(step.Logs)
+	}
+
+	list := make([]view.StepComparison, 0, len(names))
+	for _, name := range names {
+		c := items[name]
+		c.DurationDelta = c.Duration - c.BaseDuration
+		list = append(list, *c)
+	}
+	return list
+}
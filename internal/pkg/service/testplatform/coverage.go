@@ -0,0 +1,188 @@
+package testplatform
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/pkg/errors"
+)
+
+// defaultCoverageTrendLimit 覆盖率趋势默认返回的任务数
+const defaultCoverageTrendLimit = 30
+
+// onTaskCoverage 保存单元测试阶段的覆盖率，同一阶段重复上报时覆盖之前的结果
+func onTaskCoverage(params view.TestTaskEvent) (err error) {
+	var eventData view.TestTaskCoveragePayload
+	err = json.Unmarshal(params.Data, &eventData)
+	if err != nil {
+		return errors.Wrapf(err, "invalid event data")
+	}
+
+	var task db.TestPipelineTask
+	err = option.DB.Select("id, pipeline_id, app_name, env, branch").Where("id = ?", params.TaskID).First(&task).Error
+	if err != nil {
+		return
+	}
+
+	tx := option.DB.Begin()
+	err = tx.Unscoped().Where("task_id = ? and step_name = ?", task.ID, eventData.StepName).
+		Delete(&db.TestCoverage{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Create(&db.TestCoverage{
+		TaskID:     task.ID,
+		PipelineID: task.PipelineID,
+		StepName:   eventData.StepName,
+		AppName:    task.AppName,
+		Env:        task.Env,
+		Branch:     task.Branch,
+		Statements: eventData.Statements,
+		Covered:    eventData.Covered,
+		Partial:    eventData.Partial,
+		Packages:   eventData.Packages,
+		Report:     eventData.Report,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit().Error
+}
+
+// TaskCoverageDetail 任务的覆盖率和各个包的覆盖率，没有覆盖率时返回 nil
+func TaskCoverageDetail(taskID uint) (coverage *view.TaskCoverage, err error) {
+	var items []db.TestCoverage
+	err = option.DB.Where("task_id = ?", taskID).Order("id").Find(&items).Error
+	if err != nil || len(items) == 0 {
+		return
+	}
+
+	result := mergeCoverage(items, true)
+	return &result, nil
+}
+
+// CoverageTrend 应用最近的覆盖率，按时间正序，Delta 为相对上一次任务的变化
+func CoverageTrend(params view.ReqCoverageTrend) (list []view.TaskCoverage, err error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultCoverageTrendLimit
+	}
+
+	query := option.DB.Model(&db.TestCoverage{}).
+		Where("app_name = ? and env = ? and partial = ?", params.AppName, params.Env, false)
+	if params.Branch != "" {
+		query = query.Where("branch = ?", params.Branch)
+	}
+	if params.PipelineID != 0 {
+		query = query.Where("pipeline_id = ?", params.PipelineID)
+	}
+
+	// 多查一次任务用于计算第一条的变化
+	var taskIDs []uint
+	err = query.Group("task_id").Order("task_id desc").Limit(limit+1).Pluck("task_id", &taskIDs).Error
+	if err != nil {
+		return
+	}
+
+	var items []db.TestCoverage
+	if len(taskIDs) > 0 {
+		err = option.DB.Select("id, created_at, task_id, pipeline_id, branch, statements, covered, packages").
+			Where("task_id in (?)", taskIDs).Order("id").Find(&items).Error
+		if err != nil {
+			return
+		}
+	}
+
+	list = coverageTrend(items)
+	if len(list) > limit {
+		list = list[1:]
+	}
+	return
+}
+
+// coverageTrend 按任务汇总，按任务 ID 正序，计算相对上一次任务的变化
+func coverageTrend(items []db.TestCoverage) []view.TaskCoverage {
+	var taskIDs []uint
+	byTask := make(map[uint][]db.TestCoverage)
+	for _, item := range items {
+		if _, ok := byTask[item.TaskID]; !ok {
+			taskIDs = append(taskIDs, item.TaskID)
+		}
+		byTask[item.TaskID] = append(byTask[item.TaskID], item)
+	}
+	sort.Slice(taskIDs, func(i, j int) bool { return taskIDs[i] < taskIDs[j] })
+
+	list := make([]view.TaskCoverage, 0, len(taskIDs))
+	for i, taskID := range taskIDs {
+		coverage := mergeCoverage(byTask[taskID], false)
+		if i > 0 {
+			coverage.Delta = coverage.Coverage - list[i-1].Coverage
+		}
+		list = append(list, coverage)
+	}
+	return list
+}
+
+// mergeCoverage 合并同一任务各个阶段的覆盖率。分片之间的包不重复，
+// 同一个包在多个阶段中出现时取覆盖语句最多的一次；没有包明细时使用阶段的合计
+func mergeCoverage(items []db.TestCoverage, withPackages bool) view.TaskCoverage {
+	result := view.TaskCoverage{}
+	packages := make(map[string]db.CoveragePackage)
+	for _, item := range items {
+		result.TaskID = item.TaskID
+		result.PipelineID = item.PipelineID
+		result.Branch = item.Branch
+		if item.CreatedAt.After(result.CreatedAt) {
+			result.CreatedAt = item.CreatedAt
+		}
+		if withPackages && item.Report != "" {
+			result.Reports = append(result.Reports, item.Report)
+		}
+
+		if len(item.Packages) == 0 {
+			result.Statements += item.Statements
+			result.Covered += item.Covered
+			continue
+		}
+		for _, pkg := range item.Packages {
+			if prev, ok := packages[pkg.Package]; !ok || pkg.Covered > prev.Covered {
+				packages[pkg.Package] = pkg
+			}
+		}
+	}
+
+	names := make([]string, 0, len(packages))
+	for name, pkg := range packages {
+		names = append(names, name)
+		result.Statements += pkg.Statements
+		result.Covered += pkg.Covered
+	}
+	result.Coverage = coveragePercent(result.Covered, result.Statements)
+
+	if withPackages {
+		sort.Strings(names)
+		result.Packages = make([]view.PackageCoverageStat, 0, len(names))
+		for _, name := range names {
+			pkg := packages[name]
+			result.Packages = append(result.Packages, view.PackageCoverageStat{
+				Package:    pkg.Package,
+				Statements: pkg.Statements,
+				Covered:    pkg.Covered,
+				Coverage:   coveragePercent(pkg.Covered, pkg.Statements),
+			})
+		}
+	}
+	return result
+}
+
+func coveragePercent(covered, statements int) float64 {
+	if statements == 0 {
+		return 0
+	}
+	return float64(covered) * 100 / float64(statements)
+}
@@ -0,0 +1,58 @@
+package testplatform
+
+import (
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+func TestMergeCoverage(t *testing.T) {
+	items := []db.TestCoverage{
+		{TaskID: 1, StepName: "unit_test_shard_1", Statements: 30, Covered: 15, Packages: db.CoveragePackages{
+			{Package: "a", Statements: 10, Covered: 5},
+			{Package: "b", Statements: 20, Covered: 10},
+		}},
+		{TaskID: 1, StepName: "unit_test_shard_2", Statements: 20, Covered: 20, Packages: db.CoveragePackages{
+			{Package: "c", Statements: 20, Covered: 20},
+			// 重复出现的包取覆盖最多的一次
+			{Package: "a", Statements: 10, Covered: 8},
+		}, Report: "coverage-shard-2.html"},
+	}
+
+	c := mergeCoverage(items, true)
+	if c.Statements != 50 || c.Covered != 38 || c.Coverage != 76 {
+		t.Fatalf("merged coverage = %d/%d %v", c.Covered, c.Statements, c.Coverage)
+	}
+	if len(c.Packages) != 3 || c.Packages[0].Package != "a" || c.Packages[0].Covered != 8 || c.Packages[2].Coverage != 100 {
+		t.Errorf("packages = %+v", c.Packages)
+	}
+	if len(c.Reports) != 1 || c.Reports[0] != "coverage-shard-2.html" {
+		t.Errorf("reports = %v", c.Reports)
+	}
+
+	if c := mergeCoverage(items, false); c.Packages != nil || c.Reports != nil {
+		t.Errorf("packages and reports should be omitted, got %+v", c)
+	}
+
+	// 没有包明细时使用阶段的合计
+	c = mergeCoverage([]db.TestCoverage{{TaskID: 2, Statements: 4, Covered: 1}}, false)
+	if c.Statements != 4 || c.Covered != 1 || c.Coverage != 25 {
+		t.Errorf("merged coverage without packages = %+v", c)
+	}
+}
+
+func TestCoverageTrend(t *testing.T) {
+	items := []db.TestCoverage{
+		{TaskID: 3, Statements: 10, Covered: 5},
+		{TaskID: 2, Statements: 10, Covered: 8},
+		{TaskID: 3, StepName: "other", Statements: 10, Covered: 5},
+	}
+
+	list := coverageTrend(items)
+	if len(list) != 2 || list[0].TaskID != 2 || list[1].TaskID != 3 {
+		t.Fatalf("trend = %+v", list)
+	}
+	if list[0].Delta != 0 || list[1].Coverage != 50 || list[1].Delta != -30 {
+		t.Errorf("trend = %+v", list)
+	}
+}
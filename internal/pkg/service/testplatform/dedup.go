@@ -0,0 +1,52 @@
+package testplatform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	ErrTaskSuperseded   = fmt.Errorf("任务已被同一提交的新任务取代")
+	ErrInvalidCommitSHA = fmt.Errorf("无效的提交 SHA")
+
+	commitSHARegexp = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+)
+
+// normalizeCommitSHA 提交 SHA 统一为小写，允许缩写但不少于 7 位
+func normalizeCommitSHA(sha string) (string, error) {
+	sha = strings.ToLower(strings.TrimSpace(sha))
+	if sha == "" {
+		return "", nil
+	}
+	if !commitSHARegexp.MatchString(sha) {
+		return "", ErrInvalidCommitSHA
+	}
+	return sha, nil
+}
+
+// supersedePendingTasks 同一流水线、分支、提交还在排队的任务由 task 取代，保留最新一次触发的参数。
+// 只有仍为 pending 的任务会被更新，worker 已经开始执行的任务不受影响，返回被取代的任务 ID
+func supersedePendingTasks(tx *gorm.DB, task db.TestPipelineTask) (ids []uint, err error) {
+	if task.CommitSHA == "" {
+		return
+	}
+
+	err = tx.Model(&db.TestPipelineTask{}).
+		Where("pipeline_id = ? and branch = ? and commit_sha = ?", task.PipelineID, task.Branch, task.CommitSHA).
+		Where("status = ? and id < ?", db.TestTaskStatusPending, task.ID).
+		Updates(map[string]interface{}{
+			"status":        db.TestTaskStatusSuperseded,
+			"superseded_by": task.ID,
+			"logs":          gorm.Expr("concat(coalesce(logs, ''), ?)", fmt.Sprintf("superseded by task %d\n", task.ID)),
+		}).Error
+	if err != nil {
+		return
+	}
+
+	err = tx.Model(&db.TestPipelineTask{}).Where("superseded_by = ?", task.ID).Pluck("id", &ids).Error
+	return
+}
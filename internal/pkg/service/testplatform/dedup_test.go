@@ -0,0 +1,24 @@
+package testplatform
+
+import "testing"
+
+func TestNormalizeCommitSHA(t *testing.T) {
+	cases := []struct {
+		sha  string
+		want string
+		err  error
+	}{
+		{"", "", nil},
+		{" 3F2A9C1 ", "3f2a9c1", nil},
+		{"3f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39", "3f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39", nil},
+		{"3f2a9c", "", ErrInvalidCommitSHA},
+		{"master", "", ErrInvalidCommitSHA},
+		{"3f2a9c1; drop table", "", ErrInvalidCommitSHA},
+	}
+	for _, c := range cases {
+		got, err := normalizeCommitSHA(c.sha)
+		if got != c.want || err != c.err {
+			t.Errorf("normalizeCommitSHA(%q) = %q, %v, want %q, %v", c.sha, got, err, c.want, c.err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package testplatform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+var (
+	ErrInvalidTaskEvent = fmt.Errorf("触发事件只能是 manual、push、merge_request、tag_push 或 schedule")
+
+	taskEvents = map[string]bool{
+		db.TestTaskEventManual:       true,
+		db.TestTaskEventPush:         true,
+		db.TestTaskEventMergeRequest: true,
+		db.TestTaskEventTagPush:      true,
+		db.TestTaskEventSchedule:     true,
+	}
+)
+
+// normalizeTaskEvent 触发任务的事件，为空时是手动触发
+func normalizeTaskEvent(event string) (string, error) {
+	event = strings.ToLower(strings.TrimSpace(event))
+	if event == "" {
+		return db.TestTaskEventManual, nil
+	}
+	if !taskEvents[event] {
+		return "", ErrInvalidTaskEvent
+	}
+	return event, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/douyu/juno/internal/pkg/service/system"
 	"github.com/douyu/juno/internal/pkg/service/testplatform/localworker"
 	"github.com/douyu/juno/internal/pkg/service/testplatform/workerpool"
+	"github.com/douyu/juno/pkg/cfg"
 	"github.com/douyu/juno/pkg/model/view"
 	"github.com/douyu/jupiter/pkg/xlog"
 	"github.com/jinzhu/gorm"
@@ -21,6 +22,9 @@ type (
 			HeartbeatTimeout time.Duration
 			LocalQueueDir    string
 		}
+		Autoscale cfg.TestAutoscale
+		// SecretKey 加密流水线密钥的密钥，为空时不能保存和使用密钥
+		SecretKey string
 	}
 )
 
@@ -30,6 +34,7 @@ var (
 
 func Init(o Option) {
 	option = o
+	registerRecycleHandler()
 
 	system.System.Setting.Subscribe(view.TestPlatformSettingName, onSettingChange)
 
@@ -43,6 +48,7 @@ func Init(o Option) {
 	})
 
 	startClearTimeoutTask()
+	startAutoscaleTask()
 }
 
 func onSettingChange(content string) {
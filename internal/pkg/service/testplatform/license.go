@@ -0,0 +1,22 @@
+package testplatform
+
+import (
+	"encoding/json"
+
+	"github.com/douyu/juno/internal/pkg/service/system"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// licensePolicy 系统设置中的许可证策略，随任务下发到 worker。读取失败时不限制许可证，只提示
+func licensePolicy() (policy view.SettingLicensePolicy) {
+	content, err := system.System.Setting.Get(view.LicensePolicySettingName)
+	if err == nil {
+		err = json.Unmarshal([]byte(content), &policy)
+	}
+	if err != nil {
+		xlog.Error("testplatform.licensePolicy failed", xlog.FieldErr(err))
+		return view.SettingLicensePolicy{Action: view.LicenseActionWarn}
+	}
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/beeker1121/goque"
+	"github.com/douyu/juno/pkg/metrics"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/jupiter/pkg/xlog"
 )
@@ -41,6 +42,7 @@ func (w *localWorker) Init(o Option) {
 		xlog.Panicf("init local worker queue failed")
 	}
 	w.queue = queue
+	metrics.RegisterQueue("localworker", func() int { return int(queue.Length()) })
 
 	go w.start()
 }
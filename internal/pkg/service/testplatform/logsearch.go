@@ -0,0 +1,144 @@
+package testplatform
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+const (
+	defaultLogSearchLimit = 50
+	// maxIndexedLines 每个阶段最多索引的行数，超过时只索引最后的部分，失败原因通常在最后
+	maxIndexedLines = 10000
+	// maxIndexedLineSize 索引中单行的最大长度（字节），与 content 字段长度一致
+	maxIndexedLineSize = 1024
+)
+
+type logLine struct {
+	Line    int
+	Content string
+}
+
+// indexStepLogs 阶段结束后按行索引日志，同一阶段重复结束时覆盖之前的索引。
+// 日志较大时写入较慢，在后台执行，失败只记录日志
+func indexStepLogs(task db.TestPipelineTask, step db.TestPipelineStepStatus) {
+	lines := splitLogLines(step.Logs, maxIndexedLines)
+
+	tx := option.DB.Begin()
+	err := tx.Where("task_id = ? and step_name = ?", task.ID, step.StepName).Delete(&db.TestLogLine{}).Error
+	if err != nil {
+		tx.Rollback()
+		xlog.Error("indexStepLogs: delete failed", xlog.Uint("taskId", task.ID), xlog.String("err", err.Error()))
+		return
+	}
+
+	for _, line := range lines {
+		err = tx.Create(&db.TestLogLine{
+			TaskID:     task.ID,
+			PipelineID: task.PipelineID,
+			AppName:    task.AppName,
+			Env:        task.Env,
+			ZoneCode:   task.ZoneCode,
+			StepName:   step.StepName,
+			Line:       line.Line,
+			Content:    line.Content,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			xlog.Error("indexStepLogs: create failed", xlog.Uint("taskId", task.ID), xlog.String("err", err.Error()))
+			return
+		}
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		xlog.Error("indexStepLogs: commit failed", xlog.Uint("taskId", task.ID), xlog.String("err", err.Error()))
+	}
+}
+
+// splitLogLines 拆分需要索引的行，跳过空行和 worker 上报的进度信息，超过 maxLines 时保留最后的部分，行号保持不变
+func splitLogLines(logs string, maxLines int) []logLine {
+	lines := make([]logLine, 0)
+	for i, content := range strings.Split(logs, "\n") {
+		content = strings.TrimRight(content, "\r")
+		if strings.TrimSpace(content) == "" || strings.HasPrefix(content, `{"progress_log":true`) {
+			continue
+		}
+		lines = append(lines, logLine{Line: i + 1, Content: truncateLine(content, maxIndexedLineSize)})
+	}
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	return lines
+}
+
+// truncateLine 截断到 size 字节以内，不截断 UTF-8 字符
+func truncateLine(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	for size > 0 && !utf8.RuneStart(s[size]) {
+		size--
+	}
+	return s[:size]
+}
+
+// SearchLogs 在应用的历史构建日志中按字面搜索，最近的结果在前。zones 不为空时只返回这些机房的任务。
+// 只能搜索到阶段结束后建立了索引的日志
+func SearchLogs(params view.ReqSearchLogs, zones ...string) (hits []view.LogSearchHit, err error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultLogSearchLimit
+	}
+
+	query := option.DB.Where("app_name = ? and env = ?", params.AppName, params.Env).
+		Where("content like ?", "%"+listquery.EscapeLike(params.Query)+"%")
+	if len(zones) > 0 {
+		query = query.Where("zone_code in (?) or zone_code = ''", zones)
+	}
+	if params.PipelineID != 0 {
+		query = query.Where("pipeline_id = ?", params.PipelineID)
+	}
+	if params.StepName != "" {
+		query = query.Where("step_name = ?", params.StepName)
+	}
+
+	var items []db.TestLogLine
+	err = query.Order("id desc").Limit(limit).Find(&items).Error
+	if err != nil {
+		return
+	}
+
+	hits = make([]view.LogSearchHit, 0, len(items))
+	for _, item := range items {
+		hits = append(hits, view.LogSearchHit{
+			TaskID:     item.TaskID,
+			PipelineID: item.PipelineID,
+			ZoneCode:   item.ZoneCode,
+			StepName:   item.StepName,
+			Line:       item.Line,
+			Content:    item.Content,
+			Link:       logLink(item),
+			CreatedAt:  item.CreatedAt,
+		})
+	}
+	return
+}
+
+// logLink 控制台应用页面测试标签下的任务阶段日志
+func logLink(item db.TestLogLine) string {
+	query := url.Values{}
+	query.Set("appName", item.AppName)
+	query.Set("env", item.Env)
+	query.Set("zone", item.ZoneCode)
+	query.Set("tab", "test")
+	query.Set("task_id", fmt.Sprint(item.TaskID))
+	query.Set("step", item.StepName)
+	return fmt.Sprintf("/app?%s#L%d", query.Encode(), item.Line)
+}
@@ -0,0 +1,46 @@
+package testplatform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+func TestSplitLogLines(t *testing.T) {
+	logs := "go: downloading example.com/mod v1.0.0\r\n\n{\"progress_log\":true,\"type\":\"start\"}\n--- FAIL: TestA\n   \nFAIL\n"
+	want := []logLine{
+		{Line: 1, Content: "go: downloading example.com/mod v1.0.0"},
+		{Line: 4, Content: "--- FAIL: TestA"},
+		{Line: 6, Content: "FAIL"},
+	}
+	if got := splitLogLines(logs, maxIndexedLines); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitLogLines() = %+v, want %+v", got, want)
+	}
+
+	// 超过上限时保留最后的部分
+	if got := splitLogLines(logs, 2); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("splitLogLines() with limit = %+v, want %+v", got, want[1:])
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	line := strings.Repeat("a", maxIndexedLineSize-1) + "错误"
+	got := truncateLine(line, maxIndexedLineSize)
+	if got != strings.Repeat("a", maxIndexedLineSize-1) || !utf8.ValidString(got) {
+		t.Errorf("truncateLine() = %q", got[len(got)-4:])
+	}
+	if got := truncateLine("short", maxIndexedLineSize); got != "short" {
+		t.Errorf("truncateLine() = %q", got)
+	}
+}
+
+func TestLogLink(t *testing.T) {
+	link := logLink(db.TestLogLine{AppName: "app", Env: "dev", ZoneCode: "wh", TaskID: 12, StepName: "unit_test", Line: 30})
+	want := "/app?appName=app&env=dev&step=unit_test&tab=test&task_id=12&zone=wh#L30"
+	if link != want {
+		t.Errorf("logLink() = %q, want %q", link, want)
+	}
+}
@@ -0,0 +1,137 @@
+package pipeline
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+const (
+	// MaxArtifactPatterns 每个阶段最多声明的制品路径数
+	MaxArtifactPatterns = 20
+)
+
+var (
+	ErrInvalidArtifactPath = fmt.Errorf("制品路径格式错误，应为代码目录下的相对路径，支持 * ? [] 和匹配多级目录的 **")
+	ErrInvalidArtifactStep = fmt.Errorf("声明制品的阶段不存在")
+
+	// jobSteps 可以声明制品、设置环境变量和执行条件的阶段
+	jobSteps = map[string]bool{
+		StepCodeCheckName:    true,
+		StepUnitTestName:     true,
+		StepVulnCheckName:    true,
+		StepLicenseCheckName: true,
+		StepBuildReportName:  true,
+		StepCrossBuildName:   true,
+		StepBuildCheckName:   true,
+		StepBenchmarkName:    true,
+	}
+)
+
+// NormalizeStepArtifacts 去掉空白和重复的路径，校验阶段名和路径格式。没有声明任何路径时返回 nil
+func NormalizeStepArtifacts(artifacts map[string][]string) (db.MapStringArray, error) {
+	var result db.MapStringArray
+	for step, patterns := range artifacts {
+		if !jobSteps[step] {
+			return nil, ErrInvalidArtifactStep
+		}
+
+		items := make([]string, 0, len(patterns))
+		seen := make(map[string]bool)
+		for _, pattern := range patterns {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" || seen[pattern] {
+				continue
+			}
+			if !validArtifactPattern(pattern) {
+				return nil, ErrInvalidArtifactPath
+			}
+			seen[pattern] = true
+			items = append(items, pattern)
+		}
+		if len(items) > MaxArtifactPatterns {
+			return nil, ErrInvalidArtifactPath
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		if result == nil {
+			result = make(db.MapStringArray)
+		}
+		result[step] = items
+	}
+	return result, nil
+}
+
+func validArtifactPattern(pattern string) bool {
+	if strings.HasPrefix(pattern, "/") || strings.Contains(pattern, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// StepArtifacts 设置各阶段完成后上传的制品路径，分片阶段使用对应阶段的设置，需要在添加阶段之后使用
+func StepArtifacts(artifacts map[string][]string) StepOption {
+	return func(desc *db.TestPipelineDesc) {
+		if len(artifacts) == 0 {
+			return
+		}
+		for i := range desc.Steps {
+			step := &desc.Steps[i]
+			if step.SubPipeline != nil {
+				StepArtifacts(artifacts)(step.SubPipeline)
+			}
+			if step.Type != db.StepTypeJob {
+				continue
+			}
+			if patterns, ok := artifacts[shardBaseName(step.Name)]; ok {
+				step.Artifacts = patterns
+			}
+		}
+	}
+}
+
+// shardBaseName 分片阶段对应的阶段名，不是分片阶段时返回原名称
+func shardBaseName(name string) string {
+	if i := strings.LastIndex(name, "_shard_"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// MatchArtifactPath name 为代码目录下以 / 分隔的相对路径，** 匹配零到多级目录，其余部分与 path.Match 相同
+func MatchArtifactPath(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(patterns, names []string) bool {
+	if len(patterns) == 0 {
+		return len(names) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(names); i++ {
+			if matchSegments(patterns[1:], names[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(names) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(patterns[0], names[0]); !ok {
+		return false
+	}
+	return matchSegments(patterns[1:], names[1:])
+}
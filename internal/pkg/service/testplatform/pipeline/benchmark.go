@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+const (
+	// DefaultBenchmarkPackages 未配置包时执行基准测试的包
+	DefaultBenchmarkPackages = "./..."
+	// DefaultBenchmarkThreshold ns/op 相对基准增加超过该比例时视为性能退化
+	DefaultBenchmarkThreshold = 0.1
+)
+
+var ErrInvalidBenchmarkArgs = fmt.Errorf("基准测试参数格式错误，包不能以 - 开头，参数必须以 - 开头")
+
+// NormalizeBenchmarkArgs 去掉多余的空白，检查包和参数。参数不经过 shell 直接传给 go test
+func NormalizeBenchmarkArgs(packages, flags string) (string, string, error) {
+	pkgs := strings.Fields(packages)
+	for _, pkg := range pkgs {
+		if strings.HasPrefix(pkg, "-") {
+			return "", "", ErrInvalidBenchmarkArgs
+		}
+	}
+	fs := strings.Fields(flags)
+	for _, flag := range fs {
+		if !strings.HasPrefix(flag, "-") {
+			return "", "", ErrInvalidBenchmarkArgs
+		}
+	}
+	return strings.Join(pkgs, " "), strings.Join(fs, " "), nil
+}
+
+// BenchmarkBaseline 设置基准测试阶段对比的基准，需要在添加阶段之后使用
+func BenchmarkBaseline(baseline *JobBenchmarkBaseline) StepOption {
+	return func(desc *db.TestPipelineDesc) {
+		if baseline == nil {
+			return
+		}
+		for i := range desc.Steps {
+			step := &desc.Steps[i]
+			if step.SubPipeline != nil {
+				BenchmarkBaseline(baseline)(step.SubPipeline)
+			}
+			if step.Type != db.StepTypeJob || step.JobPayload == nil || step.JobPayload.Type != db.JobBenchmark {
+				continue
+			}
+			var payload JobBenchmarkPayload
+			if err := json.Unmarshal(step.JobPayload.Payload, &payload); err != nil {
+				continue
+			}
+			payload.Baseline = baseline
+			step.JobPayload.Payload, _ = json.Marshal(payload)
+		}
+	}
+}
+
+// CompareBenchmarks 按包和名称匹配基准中的结果，ns/op 增加超过 threshold 时视为性能退化
+func CompareBenchmarks(results, baseline db.BenchmarkResults, threshold float64) (list []view.BenchmarkResult, regressions int) {
+	base := make(map[string]db.BenchmarkResult, len(baseline))
+	for _, item := range baseline {
+		base[item.Package+"."+item.Name] = item
+	}
+
+	list = make([]view.BenchmarkResult, 0, len(results))
+	for _, item := range results {
+		result := view.BenchmarkResult{BenchmarkResult: item}
+		if b, ok := base[item.Package+"."+item.Name]; ok && b.NsPerOp > 0 {
+			b := b
+			delta := (item.NsPerOp - b.NsPerOp) / b.NsPerOp
+			result.Baseline = &b
+			result.Delta = &delta
+			result.Regression = delta > threshold
+			if result.Regression {
+				regressions++
+			}
+		}
+		list = append(list, result)
+	}
+	return
+}
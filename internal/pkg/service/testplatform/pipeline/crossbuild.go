@@ -0,0 +1,57 @@
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultCrossBuildTargets 未配置目标平台时交叉编译的平台
+const DefaultCrossBuildTargets = "linux/amd64,linux/arm64,darwin/amd64,windows/amd64"
+
+var (
+	ErrInvalidBuildTarget = fmt.Errorf("交叉编译目标平台格式错误，应为 linux/amd64,darwin/arm64 的形式")
+
+	buildTargetPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9]+$`)
+)
+
+// NormalizeBuildTargets 去掉空白和重复的平台，统一为逗号分隔的 GOOS/GOARCH 列表。为空时表示使用 DefaultCrossBuildTargets
+func NormalizeBuildTargets(targets string) (string, error) {
+	items := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(targets, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" || seen[item] {
+			continue
+		}
+		if !buildTargetPattern.MatchString(item) {
+			return "", ErrInvalidBuildTarget
+		}
+		seen[item] = true
+		items = append(items, item)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// ParseBuildTargets 解析 NormalizeBuildTargets 的结果，为空时使用 DefaultCrossBuildTargets
+func ParseBuildTargets(targets string) ([]BuildTarget, error) {
+	targets, err := NormalizeBuildTargets(targets)
+	if err != nil {
+		return nil, err
+	}
+	if targets == "" {
+		targets = DefaultCrossBuildTargets
+	}
+
+	items := strings.Split(targets, ",")
+	result := make([]BuildTarget, 0, len(items))
+	for _, item := range items {
+		parts := strings.SplitN(item, "/", 2)
+		result = append(result, BuildTarget{GOOS: parts[0], GOARCH: parts[1]})
+	}
+	return result, nil
+}
+
+func (t BuildTarget) String() string {
+	return t.GOOS + "/" + t.GOARCH
+}
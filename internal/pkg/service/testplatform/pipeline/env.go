@@ -0,0 +1,162 @@
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+const (
+	// MaxStepEnv 每个阶段最多设置的环境变量数
+	MaxStepEnv = 50
+	// MaxEnvValueSize 环境变量值的长度上限
+	MaxEnvValueSize = 4096
+)
+
+var (
+	ErrInvalidEnvName    = fmt.Errorf("环境变量名格式错误，只能包含字母、数字和下划线，且不能以数字开头")
+	ErrReservedEnvName   = fmt.Errorf("HOME、PATH、GOPATH 等环境变量由 worker 设置，不能在阶段中修改")
+	ErrInvalidEnvValue   = fmt.Errorf("环境变量值过长，或 ${{ }} 中不是 secrets.NAME 形式的密钥引用")
+	ErrInvalidEnvStep    = fmt.Errorf("设置环境变量的阶段不存在")
+	ErrTooManyStepEnv    = fmt.Errorf("每个阶段最多设置 %d 个环境变量", MaxStepEnv)
+	ErrSecretNotResolved = fmt.Errorf("secret not found")
+
+	envNamePattern    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	secretRefPattern  = regexp.MustCompile(`\$\{\{\s*secrets\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+	expressionPattern = regexp.MustCompile(`\$\{\{.*?\}\}`)
+
+	// reservedEnvNames worker 为任务设置的环境变量，修改后任务之间不再隔离或使用了错误的 Go 版本
+	reservedEnvNames = map[string]bool{
+		"PATH":            true,
+		"HOME":            true,
+		"TMPDIR":          true,
+		"XDG_CONFIG_HOME": true,
+		"XDG_CACHE_HOME":  true,
+		"GOROOT":          true,
+		"GOPATH":          true,
+		"GOMODCACHE":      true,
+		"GOCACHE":         true,
+		"GOTOOLCHAIN":     true,
+	}
+)
+
+// ValidSecretName 密钥名与环境变量名的规则相同
+func ValidSecretName(name string) bool {
+	return len(name) <= 64 && envNamePattern.MatchString(name)
+}
+
+// NormalizeStepEnv 校验阶段名、变量名和变量值，值中可以使用 ${{ secrets.NAME }} 引用应用在该环境下的密钥。
+// 没有设置任何变量时返回 nil
+func NormalizeStepEnv(env map[string]map[string]string) (db.MapStringMap, error) {
+	var result db.MapStringMap
+	for step, vars := range env {
+		if !jobSteps[step] {
+			return nil, ErrInvalidEnvStep
+		}
+		if len(vars) > MaxStepEnv {
+			return nil, ErrTooManyStepEnv
+		}
+		if len(vars) == 0 {
+			continue
+		}
+
+		items := make(map[string]string, len(vars))
+		for name, value := range vars {
+			name = strings.TrimSpace(name)
+			if !envNamePattern.MatchString(name) {
+				return nil, ErrInvalidEnvName
+			}
+			if reservedEnvNames[name] {
+				return nil, ErrReservedEnvName
+			}
+			if len(value) > MaxEnvValueSize || !validEnvValue(value) {
+				return nil, ErrInvalidEnvValue
+			}
+			items[name] = value
+		}
+
+		if result == nil {
+			result = make(db.MapStringMap)
+		}
+		result[step] = items
+	}
+	return result, nil
+}
+
+// validEnvValue 值中的 ${{ }} 只能是密钥引用
+func validEnvValue(value string) bool {
+	for _, expr := range expressionPattern.FindAllString(value, -1) {
+		if secretRefPattern.FindString(expr) != expr {
+			return false
+		}
+	}
+	return true
+}
+
+// StepEnv 设置各阶段命令的环境变量，分片阶段使用对应阶段的设置，需要在添加阶段之后使用
+func StepEnv(env map[string]map[string]string) StepOption {
+	return func(desc *db.TestPipelineDesc) {
+		if len(env) == 0 {
+			return
+		}
+		for i := range desc.Steps {
+			step := &desc.Steps[i]
+			if step.SubPipeline != nil {
+				StepEnv(env)(step.SubPipeline)
+			}
+			if step.Type != db.StepTypeJob {
+				continue
+			}
+			if vars, ok := env[shardBaseName(step.Name)]; ok {
+				step.Env = vars
+			}
+		}
+	}
+}
+
+// SecretRefs 流水线各阶段环境变量引用的密钥名，去重后排序
+func SecretRefs(desc db.TestPipelineDesc) []string {
+	set := make(map[string]bool)
+	collectSecretRefs(desc, set)
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func collectSecretRefs(desc db.TestPipelineDesc, set map[string]bool) {
+	for _, step := range desc.Steps {
+		if step.SubPipeline != nil {
+			collectSecretRefs(*step.SubPipeline, set)
+		}
+		for _, value := range step.Env {
+			for _, match := range secretRefPattern.FindAllStringSubmatch(value, -1) {
+				set[match[1]] = true
+			}
+		}
+	}
+}
+
+// ExpandSecrets 把值中的 ${{ secrets.NAME }} 替换为密钥的值，引用的密钥不在 secrets 中时返回 ErrSecretNotResolved
+func ExpandSecrets(value string, secrets map[string]string) (string, error) {
+	var err error
+	expanded := secretRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := secretRefPattern.FindStringSubmatch(ref)[1]
+		secret, ok := secrets[name]
+		if !ok {
+			err = fmt.Errorf("%w: %s", ErrSecretNotResolved, name)
+			return ref
+		}
+		return secret
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
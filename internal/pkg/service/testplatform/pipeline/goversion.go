@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+var (
+	ErrInvalidGoVersion = fmt.Errorf("Go 版本格式错误，应为 1.16.15 的形式")
+
+	goVersionPattern = regexp.MustCompile(`^go1\.\d+(\.\d+)?((beta|rc)\d+)?$`)
+)
+
+// NormalizeGoVersion 统一为 go1.16.15 的形式，与官方发布包的名称一致。为空时表示使用 worker 默认的 go
+func NormalizeGoVersion(version string) (string, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(version, "go") {
+		version = "go" + version
+	}
+	if !goVersionPattern.MatchString(version) {
+		return "", ErrInvalidGoVersion
+	}
+	return version, nil
+}
+
+// GoVersion 执行任务使用的 Go 版本，version 为 NormalizeGoVersion 的结果
+func GoVersion(version string) StepOption {
+	return func(desc *db.TestPipelineDesc) {
+		desc.GoVersion = version
+	}
+}
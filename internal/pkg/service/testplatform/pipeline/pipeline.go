@@ -2,6 +2,8 @@ package pipeline
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/view"
@@ -21,6 +23,70 @@ type (
 
 	JobUnitTestPayload struct {
 		AccessToken string `json:"access_token"`
+		// Shard 分片序号，从 1 开始，ShardTotal 大于 1 时只执行分到该分片的包
+		Shard      int `json:"shard,omitempty"`
+		ShardTotal int `json:"shard_total,omitempty"`
+		// Timings 各个包上次执行的耗时，各分片按相同的耗时分配包，保证分片之间不重复、不遗漏
+		Timings map[string]float64 `json:"timings,omitempty"`
+		// AffectedBase 不为空时只执行相对该分支变更影响的包
+		AffectedBase string `json:"affected_base,omitempty"`
+		// RaceDetector 使用 -race 执行，检测到数据竞争时阶段失败
+		RaceDetector bool `json:"race_detector,omitempty"`
+		// CoverageReport 上传覆盖率文件和 go tool cover 生成的 HTML 覆盖率报告
+		CoverageReport bool `json:"coverage_report,omitempty"`
+	}
+
+	UnitTestOption func(payload *JobUnitTestPayload)
+
+	JobVulnCheckPayload struct {
+		AccessToken string `json:"access_token"`
+		// Gate 存在该级别及以上、且代码实际调用的漏洞时阶段失败，为空时只记录
+		Gate string `json:"gate,omitempty"`
+	}
+
+	JobLicenseCheckPayload struct {
+		AccessToken string `json:"access_token"`
+		// Policy 下发任务时的许可证策略
+		Policy view.SettingLicensePolicy `json:"policy"`
+	}
+
+	JobBuildReportPayload struct {
+		AccessToken string `json:"access_token"`
+		// Package 构建的 main 包，为空时构建根目录，根目录不是 main 包时使用第一个 main 包
+		Package string `json:"package,omitempty"`
+	}
+
+	JobBuildCheckPayload struct {
+		AccessToken string `json:"access_token"`
+		// Command 在代码根目录执行的编译命令，为空时执行 go build ./...
+		Command string `json:"command,omitempty"`
+	}
+
+	JobBenchmarkPayload struct {
+		AccessToken string   `json:"access_token"`
+		Packages    []string `json:"packages"`
+		Flags       []string `json:"flags,omitempty"`
+		// Threshold ns/op 相对基准增加超过该比例时视为性能退化
+		Threshold float64 `json:"threshold"`
+		// Baseline 同一分支上一次的结果，下发任务时查询，为空时不对比
+		Baseline *JobBenchmarkBaseline `json:"baseline,omitempty"`
+	}
+
+	JobBenchmarkBaseline struct {
+		TaskID    uint                `json:"task_id"`
+		CommitSHA string              `json:"commit_sha"`
+		Results   db.BenchmarkResults `json:"results"`
+	}
+
+	JobCrossBuildPayload struct {
+		AccessToken string        `json:"access_token"`
+		Targets     []BuildTarget `json:"targets"`
+	}
+
+	// BuildTarget 交叉编译的目标平台
+	BuildTarget struct {
+		GOOS   string `json:"goos"`
+		GOARCH string `json:"goarch"`
 	}
 
 	JobHttpTestPayload struct {
@@ -35,11 +101,24 @@ type (
 )
 
 const (
-	StepGitPullName   = "git_pull"
-	StepCodeCheckName = "code_check"
-	StepUnitTestName  = "unit_test"
-	StepHttpTestName  = "http_test"
-	StepGrpcTestName  = "grpc_test"
+	StepGitPullName      = "git_pull"
+	StepCodeCheckName    = "code_check"
+	StepUnitTestName     = "unit_test"
+	StepHttpTestName     = "http_test"
+	StepGrpcTestName     = "grpc_test"
+	StepVulnCheckName    = "vuln_check"
+	StepLicenseCheckName = "license_check"
+	StepBuildReportName  = "build_report"
+	StepCrossBuildName   = "cross_build"
+	StepBuildCheckName   = "build_check"
+	StepBenchmarkName    = "benchmark"
+)
+
+const (
+	// DefaultGitPullRetries 拉取代码失败后的默认重试次数
+	DefaultGitPullRetries = 2
+	// DefaultGitPullRetryDelay 拉取代码第一次重试前等待的时间（秒）
+	DefaultGitPullRetryDelay = 5
 )
 
 func New(options ...StepOption) *db.TestPipelineDesc {
@@ -79,11 +158,18 @@ func StepJob(name string, jobPayload db.TestJobPayload) StepOption {
 	}
 }
 
+// StepGitPull 拉取代码，网络抖动时默认重试
 func StepGitPull(gitHttpUrl, branch, accessToken string) StepOption {
-	return StepJob(
-		StepGitPullName,
-		JobGitPull(gitHttpUrl, branch, accessToken),
-	)
+	return stepGitPull(StepGitPullName, gitHttpUrl, branch, accessToken)
+}
+
+func stepGitPull(name, gitHttpUrl, branch, accessToken string) StepOption {
+	return func(desc *db.TestPipelineDesc) {
+		StepJob(name, JobGitPull(gitHttpUrl, branch, accessToken))(desc)
+		step := &desc.Steps[len(desc.Steps)-1]
+		step.Retries = DefaultGitPullRetries
+		step.RetryDelaySeconds = DefaultGitPullRetryDelay
+	}
 }
 
 func StepCodeCheck() StepOption {
@@ -93,10 +179,90 @@ func StepCodeCheck() StepOption {
 	)
 }
 
-func StepUnitTest(accessToken string) StepOption {
+func StepUnitTest(accessToken string, options ...UnitTestOption) StepOption {
 	return StepJob(
 		StepUnitTestName,
-		JobUnitTest(accessToken),
+		JobUnitTest(accessToken, options...),
+	)
+}
+
+// StepUnitTestShards 单元测试分片，每个分片是独立拉取代码、执行测试的子流水线，可以由不同的 worker 执行
+func StepUnitTestShards(gitHttpUrl, branch, accessToken string, total int, timings map[string]float64, options ...UnitTestOption) StepOption {
+	return func(desc *db.TestPipelineDesc) {
+		for shard := 1; shard <= total; shard++ {
+			StepSubPipeline(
+				stepGitPull(ShardStepName(StepGitPullName, shard), gitHttpUrl, branch, accessToken),
+				StepJob(ShardStepName(StepUnitTestName, shard), JobUnitTestShard(accessToken, shard, total, timings, options...)),
+			)(desc)
+		}
+	}
+}
+
+// UnitTestAffected 只执行相对 base 分支变更影响的包
+func UnitTestAffected(base string) UnitTestOption {
+	return func(payload *JobUnitTestPayload) {
+		payload.AffectedBase = base
+	}
+}
+
+// UnitTestRace 开启数据竞争检测
+func UnitTestRace() UnitTestOption {
+	return func(payload *JobUnitTestPayload) {
+		payload.RaceDetector = true
+	}
+}
+
+// UnitTestCoverage 上传覆盖率文件和 HTML 覆盖率报告
+func UnitTestCoverage() UnitTestOption {
+	return func(payload *JobUnitTestPayload) {
+		payload.CoverageReport = true
+	}
+}
+
+// ShardStepName 分片阶段的名称，同一流水线中阶段名不能重复
+func ShardStepName(name string, shard int) string {
+	return fmt.Sprintf("%s_shard_%d", name, shard)
+}
+
+func StepVulnCheck(accessToken, gate string) StepOption {
+	return StepJob(
+		StepVulnCheckName,
+		JobVulnCheck(accessToken, gate),
+	)
+}
+
+func StepLicenseCheck(accessToken string, policy view.SettingLicensePolicy) StepOption {
+	return StepJob(
+		StepLicenseCheckName,
+		JobLicenseCheck(accessToken, policy),
+	)
+}
+
+func StepBuildReport(accessToken, pkg string) StepOption {
+	return StepJob(
+		StepBuildReportName,
+		JobBuildReport(accessToken, pkg),
+	)
+}
+
+func StepCrossBuild(accessToken string, targets []BuildTarget) StepOption {
+	return StepJob(
+		StepCrossBuildName,
+		JobCrossBuild(accessToken, targets),
+	)
+}
+
+func StepBuildCheck(accessToken, command string) StepOption {
+	return StepJob(
+		StepBuildCheckName,
+		JobBuildCheck(accessToken, command),
+	)
+}
+
+func StepBenchmark(accessToken, packages, flags string) StepOption {
+	return StepJob(
+		StepBenchmarkName,
+		JobBenchmark(accessToken, packages, flags),
 	)
 }
 
@@ -143,16 +309,111 @@ func JobCodeCheck() db.TestJobPayload {
 	}
 }
 
-func JobUnitTest(accessToken string) db.TestJobPayload {
-	payload, _ := json.Marshal(JobUnitTestPayload{
+func JobUnitTest(accessToken string, options ...UnitTestOption) db.TestJobPayload {
+	p := JobUnitTestPayload{
 		AccessToken: accessToken,
-	})
+	}
+	for _, option := range options {
+		option(&p)
+	}
+
+	payload, _ := json.Marshal(p)
+	return db.TestJobPayload{
+		Type:    db.JobUnitTest,
+		Payload: payload,
+	}
+}
+
+func JobUnitTestShard(accessToken string, shard, total int, timings map[string]float64, options ...UnitTestOption) db.TestJobPayload {
+	p := JobUnitTestPayload{
+		AccessToken: accessToken,
+		Shard:       shard,
+		ShardTotal:  total,
+		Timings:     timings,
+	}
+	for _, option := range options {
+		option(&p)
+	}
+
+	payload, _ := json.Marshal(p)
 	return db.TestJobPayload{
 		Type:    db.JobUnitTest,
 		Payload: payload,
 	}
 }
 
+func JobVulnCheck(accessToken, gate string) db.TestJobPayload {
+	payload, _ := json.Marshal(JobVulnCheckPayload{
+		AccessToken: accessToken,
+		Gate:        gate,
+	})
+	return db.TestJobPayload{
+		Type:    db.JobVulnCheck,
+		Payload: payload,
+	}
+}
+
+func JobLicenseCheck(accessToken string, policy view.SettingLicensePolicy) db.TestJobPayload {
+	payload, _ := json.Marshal(JobLicenseCheckPayload{
+		AccessToken: accessToken,
+		Policy:      policy,
+	})
+	return db.TestJobPayload{
+		Type:    db.JobLicenseCheck,
+		Payload: payload,
+	}
+}
+
+func JobBuildReport(accessToken, pkg string) db.TestJobPayload {
+	payload, _ := json.Marshal(JobBuildReportPayload{
+		AccessToken: accessToken,
+		Package:     pkg,
+	})
+	return db.TestJobPayload{
+		Type:    db.JobBuildReport,
+		Payload: payload,
+	}
+}
+
+func JobCrossBuild(accessToken string, targets []BuildTarget) db.TestJobPayload {
+	payload, _ := json.Marshal(JobCrossBuildPayload{
+		AccessToken: accessToken,
+		Targets:     targets,
+	})
+	return db.TestJobPayload{
+		Type:    db.JobCrossBuild,
+		Payload: payload,
+	}
+}
+
+func JobBuildCheck(accessToken, command string) db.TestJobPayload {
+	payload, _ := json.Marshal(JobBuildCheckPayload{
+		AccessToken: accessToken,
+		Command:     command,
+	})
+	return db.TestJobPayload{
+		Type:    db.JobBuildCheck,
+		Payload: payload,
+	}
+}
+
+// JobBenchmark packages 和 flags 为 NormalizeBenchmarkArgs 的结果
+func JobBenchmark(accessToken, packages, flags string) db.TestJobPayload {
+	if packages == "" {
+		packages = DefaultBenchmarkPackages
+	}
+	payload, _ := json.Marshal(JobBenchmarkPayload{
+		AccessToken: accessToken,
+		Packages:    strings.Fields(packages),
+		Flags:       strings.Fields(flags),
+		Threshold:   DefaultBenchmarkThreshold,
+	})
+	return db.TestJobPayload{
+		Type:    db.JobBenchmark,
+		Payload: payload,
+	}
+}
+
 func JobGrpcTest(addr string, testCases []view.GrpcTestCase) db.TestJobPayload {
 	payload, _ := json.Marshal(JobGrpcTestPayload{
 		Addr:      addr,
@@ -164,3 +425,40 @@ func JobGrpcTest(addr string, testCases []view.GrpcTestCase) db.TestJobPayload {
 		Payload: payload,
 	}
 }
+
+// StepTimeout 设置各 job 阶段的超时时间（秒），已经单独设置的阶段不变，需要在添加阶段之后使用
+func StepTimeout(seconds int) StepOption {
+	return func(desc *db.TestPipelineDesc) {
+		if seconds <= 0 {
+			return
+		}
+		for i := range desc.Steps {
+			step := &desc.Steps[i]
+			if step.SubPipeline != nil {
+				StepTimeout(seconds)(step.SubPipeline)
+			}
+			if step.Type == db.StepTypeJob && step.TimeoutSeconds == 0 {
+				step.TimeoutSeconds = seconds
+			}
+		}
+	}
+}
+
+// StepRetry 设置各 job 阶段失败后的重试次数和第一次重试前等待的时间（秒），已经单独设置的阶段不变，需要在添加阶段之后使用
+func StepRetry(retries, delaySeconds int) StepOption {
+	return func(desc *db.TestPipelineDesc) {
+		if retries <= 0 {
+			return
+		}
+		for i := range desc.Steps {
+			step := &desc.Steps[i]
+			if step.SubPipeline != nil {
+				StepRetry(retries, delaySeconds)(step.SubPipeline)
+			}
+			if step.Type == db.StepTypeJob && step.Retries == 0 {
+				step.Retries = retries
+				step.RetryDelaySeconds = delaySeconds
+			}
+		}
+	}
+}
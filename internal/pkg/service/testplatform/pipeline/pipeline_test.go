@@ -2,7 +2,12 @@ package pipeline
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
 )
 
 func TestJobGitPull(t *testing.T) {
@@ -15,3 +20,380 @@ func TestJobGitPull(t *testing.T) {
 	payloadBytes, _ := json.Marshal(payload)
 	t.Logf("payload = %s", string(payloadBytes))
 }
+
+func TestStepUnitTestShards(t *testing.T) {
+	desc := New(
+		Parallel(true),
+		StepUnitTestShards("https://github.com/linux/linux", "master", "token", 3, map[string]float64{"a": 1}),
+	)
+
+	if len(desc.Steps) != 3 || desc.JobCount() != 6 {
+		t.Fatalf("expect 3 shards with 6 jobs, got %d shards, %d jobs", len(desc.Steps), desc.JobCount())
+	}
+	if err := desc.ValidatePipelineDesc(); err != nil {
+		t.Fatal(err)
+	}
+
+	var payload JobUnitTestPayload
+	_ = json.Unmarshal(desc.Steps[2].SubPipeline.Steps[1].JobPayload.Payload, &payload)
+	if payload.Shard != 3 || payload.ShardTotal != 3 || payload.Timings["a"] != 1 {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
+
+func TestJobUnitTestAffected(t *testing.T) {
+	var payload JobUnitTestPayload
+	_ = json.Unmarshal(JobUnitTest("token", UnitTestAffected("master")).Payload, &payload)
+	if payload.AccessToken != "token" || payload.AffectedBase != "master" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	payload = JobUnitTestPayload{}
+	_ = json.Unmarshal(JobUnitTest("token", UnitTestRace()).Payload, &payload)
+	if !payload.RaceDetector || payload.AffectedBase != "" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	payload = JobUnitTestPayload{}
+	_ = json.Unmarshal(JobUnitTest("token", UnitTestCoverage()).Payload, &payload)
+	if !payload.CoverageReport || payload.RaceDetector {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
+
+func TestJobLicenseCheck(t *testing.T) {
+	policy := view.SettingLicensePolicy{Deny: []string{"AGPL-3.0"}, Action: view.LicenseActionFail}
+	job := JobLicenseCheck("token", policy)
+	if job.Type != db.JobLicenseCheck {
+		t.Fatalf("unexpected job type %s", job.Type)
+	}
+
+	var payload JobLicenseCheckPayload
+	_ = json.Unmarshal(job.Payload, &payload)
+	if payload.AccessToken != "token" || payload.Policy.Action != view.LicenseActionFail ||
+		len(payload.Policy.Deny) != 1 || payload.Policy.Deny[0] != "AGPL-3.0" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
+
+func TestJobBuildCheck(t *testing.T) {
+	job := JobBuildCheck("token", "make build")
+	if job.Type != db.JobBuildCheck {
+		t.Fatalf("unexpected job type %s", job.Type)
+	}
+
+	var payload JobBuildCheckPayload
+	_ = json.Unmarshal(job.Payload, &payload)
+	if payload.AccessToken != "token" || payload.Command != "make build" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
+
+func TestNormalizeGoVersion(t *testing.T) {
+	for _, c := range []struct {
+		version string
+		want    string
+		valid   bool
+	}{
+		{"", "", true},
+		{"1.16.15", "go1.16.15", true},
+		{" go1.21 ", "go1.21", true},
+		{"1.22rc1", "go1.22rc1", true},
+		{"1.16.15/../..", "", false},
+		{"2.0", "", false},
+	} {
+		got, err := NormalizeGoVersion(c.version)
+		if (err == nil) != c.valid || got != c.want {
+			t.Errorf("NormalizeGoVersion(%q) = %q, %v", c.version, got, err)
+		}
+	}
+
+	if desc := New(GoVersion("go1.16.15")); desc.GoVersion != "go1.16.15" {
+		t.Errorf("unexpected go version %q", desc.GoVersion)
+	}
+}
+
+func TestStepTimeout(t *testing.T) {
+	desc := New(
+		StepCodeCheck(),
+		StepUnitTestShards("https://github.com/linux/linux", "master", "token", 2, nil),
+		StepTimeout(600),
+	)
+	if desc.Steps[0].TimeoutSeconds != 600 {
+		t.Errorf("step timeout = %d, want 600", desc.Steps[0].TimeoutSeconds)
+	}
+	if desc.Steps[1].TimeoutSeconds != 0 {
+		t.Errorf("sub pipeline step should not have timeout, got %d", desc.Steps[1].TimeoutSeconds)
+	}
+	for _, step := range desc.Steps[1].SubPipeline.Steps {
+		if step.TimeoutSeconds != 600 {
+			t.Errorf("step %s timeout = %d, want 600", step.Name, step.TimeoutSeconds)
+		}
+	}
+
+	desc.Steps[0].TimeoutSeconds = 30
+	StepTimeout(900)(desc)
+	if desc.Steps[0].TimeoutSeconds != 30 {
+		t.Errorf("step timeout overridden: %d", desc.Steps[0].TimeoutSeconds)
+	}
+}
+
+func TestStepRetry(t *testing.T) {
+	desc := New(
+		StepGitPull("https://github.com/linux/linux", "master", "token"),
+		StepCodeCheck(),
+		StepUnitTestShards("https://github.com/linux/linux", "master", "token", 2, nil),
+		StepRetry(1, 30),
+	)
+	if desc.Steps[0].Retries != DefaultGitPullRetries || desc.Steps[0].RetryDelaySeconds != DefaultGitPullRetryDelay {
+		t.Errorf("git pull retries = %d, delay = %d", desc.Steps[0].Retries, desc.Steps[0].RetryDelaySeconds)
+	}
+	if desc.Steps[1].Retries != 1 || desc.Steps[1].RetryDelaySeconds != 30 {
+		t.Errorf("code check retries = %d, delay = %d", desc.Steps[1].Retries, desc.Steps[1].RetryDelaySeconds)
+	}
+	for _, shard := range desc.Steps[2:] {
+		if shard.Retries != 0 {
+			t.Errorf("sub pipeline step should not retry, got %d", shard.Retries)
+		}
+		pull, test := shard.SubPipeline.Steps[0], shard.SubPipeline.Steps[1]
+		if pull.Retries != DefaultGitPullRetries || test.Retries != 1 {
+			t.Errorf("shard retries = %d, %d", pull.Retries, test.Retries)
+		}
+	}
+}
+
+func TestParseBuildTargets(t *testing.T) {
+	normalized, err := NormalizeBuildTargets(" linux/amd64, Darwin/ARM64,,linux/amd64 ")
+	if err != nil || normalized != "linux/amd64,darwin/arm64" {
+		t.Fatalf("NormalizeBuildTargets() = %q, %v", normalized, err)
+	}
+	for _, targets := range []string{"linux", "linux/amd64/v2", "linux amd64", "linux/-o"} {
+		if _, err := NormalizeBuildTargets(targets); err != ErrInvalidBuildTarget {
+			t.Errorf("NormalizeBuildTargets(%q) err = %v, want ErrInvalidBuildTarget", targets, err)
+		}
+	}
+
+	parsed, err := ParseBuildTargets("")
+	if err != nil || len(parsed) != 4 || parsed[0] != (BuildTarget{GOOS: "linux", GOARCH: "amd64"}) {
+		t.Fatalf("ParseBuildTargets(\"\") = %v, %v", parsed, err)
+	}
+	parsed, err = ParseBuildTargets("windows/386")
+	if err != nil || len(parsed) != 1 || parsed[0].String() != "windows/386" {
+		t.Errorf("ParseBuildTargets(windows/386) = %v, %v", parsed, err)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	packages, flags, err := NormalizeBenchmarkArgs(" ./codec/...  ./store ", "-count=3\t-benchtime=2s ")
+	if err != nil || packages != "./codec/... ./store" || flags != "-count=3 -benchtime=2s" {
+		t.Fatalf("NormalizeBenchmarkArgs() = %q, %q, %v", packages, flags, err)
+	}
+	for _, args := range [][2]string{{"-exec=sh", ""}, {"./...", "3"}} {
+		if _, _, err := NormalizeBenchmarkArgs(args[0], args[1]); err != ErrInvalidBenchmarkArgs {
+			t.Errorf("NormalizeBenchmarkArgs(%q, %q) err = %v, want ErrInvalidBenchmarkArgs", args[0], args[1], err)
+		}
+	}
+
+	desc := New(StepCodeCheck(), StepSubPipeline(StepBenchmark("token", "", flags)))
+	baseline := &JobBenchmarkBaseline{TaskID: 7, CommitSHA: "abc1234", Results: db.BenchmarkResults{
+		{Package: "a", Name: "BenchmarkX-8", NsPerOp: 1000},
+		{Package: "a", Name: "BenchmarkY-8", NsPerOp: 1000},
+	}}
+	BenchmarkBaseline(baseline)(desc)
+	var payload JobBenchmarkPayload
+	_ = json.Unmarshal(desc.Steps[1].SubPipeline.Steps[0].JobPayload.Payload, &payload)
+	if payload.Baseline == nil || payload.Baseline.TaskID != 7 || len(payload.Packages) != 1 || payload.Packages[0] != DefaultBenchmarkPackages ||
+		len(payload.Flags) != 2 || payload.Threshold != DefaultBenchmarkThreshold {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	results, regressions := CompareBenchmarks(db.BenchmarkResults{
+		{Package: "a", Name: "BenchmarkX-8", NsPerOp: 1200},
+		{Package: "a", Name: "BenchmarkY-8", NsPerOp: 1050},
+		{Package: "b", Name: "BenchmarkX-8", NsPerOp: 10},
+	}, payload.Baseline.Results, payload.Threshold)
+	if regressions != 1 || !results[0].Regression || results[1].Regression || *results[1].Delta != 0.05 || results[2].Delta != nil {
+		t.Errorf("CompareBenchmarks() = %+v, %d regressions", results, regressions)
+	}
+}
+
+func TestStepArtifacts(t *testing.T) {
+	artifacts, err := NormalizeStepArtifacts(map[string][]string{
+		StepUnitTestName:  {" coverage.out", "reports/**/*.xml", "coverage.out", ""},
+		StepCodeCheckName: {" "},
+	})
+	if err != nil || len(artifacts) != 1 || strings.Join(artifacts[StepUnitTestName], ",") != "coverage.out,reports/**/*.xml" {
+		t.Fatalf("NormalizeStepArtifacts() = %v, %v", artifacts, err)
+	}
+	for _, pattern := range []string{"/etc/passwd", "../secret", "a/./b", "bin//app", "[a-", `bin\app`} {
+		if _, err := NormalizeStepArtifacts(map[string][]string{StepUnitTestName: {pattern}}); err != ErrInvalidArtifactPath {
+			t.Errorf("NormalizeStepArtifacts(%q) err = %v, want ErrInvalidArtifactPath", pattern, err)
+		}
+	}
+	if _, err := NormalizeStepArtifacts(map[string][]string{StepGitPullName: {"a"}}); err != ErrInvalidArtifactStep {
+		t.Errorf("NormalizeStepArtifacts(git_pull) err = %v, want ErrInvalidArtifactStep", err)
+	}
+
+	desc := New(
+		StepCodeCheck(),
+		StepUnitTestShards("https://github.com/linux/linux", "master", "token", 2, nil),
+		StepArtifacts(artifacts),
+	)
+	if len(desc.Steps[0].Artifacts) != 0 {
+		t.Errorf("code check artifacts = %v", desc.Steps[0].Artifacts)
+	}
+	for _, shard := range desc.Steps[1:] {
+		pull, test := shard.SubPipeline.Steps[0], shard.SubPipeline.Steps[1]
+		if len(pull.Artifacts) != 0 || len(test.Artifacts) != 2 {
+			t.Errorf("shard %s artifacts = %v, %s artifacts = %v", pull.Name, pull.Artifacts, test.Name, test.Artifacts)
+		}
+	}
+}
+
+func TestMatchArtifactPath(t *testing.T) {
+	for _, c := range []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"coverage.out", "coverage.out", true},
+		{"bin/*", "bin/app", true},
+		{"bin/*", "bin/linux/app", false},
+		{"**/*.xml", "report.xml", true},
+		{"reports/**/*.xml", "reports/a/b/junit.xml", true},
+		{"reports/**", "reports/a/junit.xml", true},
+		{"reports/**/*.xml", "other/junit.xml", false},
+		{"*.out", "dir/coverage.out", false},
+	} {
+		if got := MatchArtifactPath(c.pattern, c.name); got != c.want {
+			t.Errorf("MatchArtifactPath(%q, %q) = %v, want %v", c.pattern, c.name, got, c.want)
+		}
+	}
+}
+
+func TestStepEnv(t *testing.T) {
+	env, err := NormalizeStepEnv(map[string]map[string]string{
+		StepUnitTestName:  {" GOFLAGS": "-mod=vendor", "TOKEN": "Bearer ${{ secrets.API_TOKEN }}"},
+		StepCodeCheckName: {},
+	})
+	if err != nil || len(env) != 1 || env[StepUnitTestName]["GOFLAGS"] != "-mod=vendor" {
+		t.Fatalf("NormalizeStepEnv() = %v, %v", env, err)
+	}
+	for _, c := range []struct {
+		name, value string
+		err         error
+	}{
+		{"1A", "", ErrInvalidEnvName},
+		{"A-B", "", ErrInvalidEnvName},
+		{"PATH", "/tmp", ErrReservedEnvName},
+		{"A", "${{ env.HOME }}", ErrInvalidEnvValue},
+		{"A", "${{ secrets.A }} ${{ github.sha }}", ErrInvalidEnvValue},
+		{"A", strings.Repeat("a", MaxEnvValueSize+1), ErrInvalidEnvValue},
+	} {
+		if _, err := NormalizeStepEnv(map[string]map[string]string{StepUnitTestName: {c.name: c.value}}); err != c.err {
+			t.Errorf("NormalizeStepEnv(%q=%q) err = %v, want %v", c.name, c.value, err, c.err)
+		}
+	}
+	if _, err := NormalizeStepEnv(map[string]map[string]string{StepGitPullName: {"A": "a"}}); err != ErrInvalidEnvStep {
+		t.Errorf("NormalizeStepEnv(git_pull) err = %v, want ErrInvalidEnvStep", err)
+	}
+
+	desc := New(
+		StepCodeCheck(),
+		StepUnitTestShards("https://github.com/linux/linux", "master", "token", 2, nil),
+		StepEnv(env),
+	)
+	if len(desc.Steps[0].Env) != 0 {
+		t.Errorf("code check env = %v", desc.Steps[0].Env)
+	}
+	for _, shard := range desc.Steps[1:] {
+		if test := shard.SubPipeline.Steps[1]; len(test.Env) != 2 {
+			t.Errorf("%s env = %v", test.Name, test.Env)
+		}
+	}
+	if refs := SecretRefs(*desc); strings.Join(refs, ",") != "API_TOKEN" {
+		t.Errorf("SecretRefs() = %v", refs)
+	}
+}
+
+func TestExpandSecrets(t *testing.T) {
+	secrets := map[string]string{"A": "a1", "B": "b2"}
+	got, err := ExpandSecrets("${{secrets.A}}:${{ secrets.B }}:${{ secrets.A }}", secrets)
+	if err != nil || got != "a1:b2:a1" {
+		t.Errorf("ExpandSecrets() = %q, %v", got, err)
+	}
+	if _, err = ExpandSecrets("${{ secrets.C }}", secrets); !errors.Is(err, ErrSecretNotResolved) || !strings.Contains(err.Error(), "C") {
+		t.Errorf("ExpandSecrets(missing) err = %v", err)
+	}
+}
+
+func TestStepWhen(t *testing.T) {
+	when, err := NormalizeStepWhen(map[string]string{
+		StepUnitTestName:  ` branch == "master" `,
+		StepCodeCheckName: " ",
+	})
+	if err != nil || len(when) != 1 || when[StepUnitTestName] != `branch == "master"` {
+		t.Fatalf("NormalizeStepWhen() = %v, %v", when, err)
+	}
+	for _, expr := range []string{
+		`branch`,
+		`branch = "master"`,
+		`commit == "abc"`,
+		`branch == "master`,
+		`(branch == "master"`,
+		`branch == "master" &&`,
+		`branch == "master" event == "push"`,
+		`branch == 'master'`,
+	} {
+		if _, err := NormalizeStepWhen(map[string]string{StepUnitTestName: expr}); !errors.Is(err, ErrInvalidWhen) {
+			t.Errorf("NormalizeStepWhen(%s) err = %v, want ErrInvalidWhen", expr, err)
+		}
+	}
+	if _, err := NormalizeStepWhen(map[string]string{StepGitPullName: `branch == "master"`}); err != ErrInvalidWhenStep {
+		t.Errorf("NormalizeStepWhen(git_pull) err = %v, want ErrInvalidWhenStep", err)
+	}
+
+	desc := New(
+		StepCodeCheck(),
+		StepUnitTestShards("https://github.com/linux/linux", "master", "token", 2, nil),
+		StepWhen(when),
+	)
+	if desc.Steps[0].When != "" {
+		t.Errorf("code check when = %q", desc.Steps[0].When)
+	}
+	for _, shard := range desc.Steps[1:] {
+		if test := shard.SubPipeline.Steps[1]; test.When != `branch == "master"` {
+			t.Errorf("%s when = %q", test.Name, test.When)
+		}
+	}
+}
+
+func TestEvalWhen(t *testing.T) {
+	vars := WhenVars{Branch: "feature/x", Event: "merge_request", Env: "dev"}
+	for _, c := range []struct {
+		expr string
+		want bool
+	}{
+		{``, true},
+		{`branch == "master"`, false},
+		{`branch != "master"`, true},
+		{`"merge_request" == event`, true},
+		{`branch == "master" || event == "merge_request"`, true},
+		{`branch == "master" || event == "push" && env == "dev"`, false},
+		{`(branch == "master" || event == "merge_request") && env == "dev"`, true},
+		{`!(env == "prod")`, true},
+		{`!env == "dev"`, false},
+		{`branch == "feature/x" && env != "pro\"d"`, true},
+	} {
+		got, err := EvalWhen(c.expr, vars)
+		if err != nil || got != c.want {
+			t.Errorf("EvalWhen(%s) = %v, %v, want %v", c.expr, got, err, c.want)
+		}
+	}
+
+	if got, _ := EvalWhen(`event == "manual"`, WhenVars{Branch: "master"}); !got {
+		t.Errorf("EvalWhen() without event should be manual")
+	}
+	if _, err := EvalWhen(`branch ==`, vars); !errors.Is(err, ErrInvalidWhen) {
+		t.Errorf("EvalWhen(invalid) err = %v", err)
+	}
+}
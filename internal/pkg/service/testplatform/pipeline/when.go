@@ -0,0 +1,323 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+const (
+	// MaxWhenSize 阶段执行条件的长度上限
+	MaxWhenSize = 256
+)
+
+var (
+	ErrInvalidWhen     = fmt.Errorf("阶段执行条件格式错误，应为 branch、event、env 与字符串比较，如 branch == \"master\" && event != \"merge_request\"")
+	ErrInvalidWhenStep = fmt.Errorf("设置执行条件的阶段不存在")
+
+	// whenVariables 执行条件中可以使用的变量
+	whenVariables = map[string]bool{
+		"branch": true,
+		"event":  true,
+		"env":    true,
+	}
+)
+
+type (
+	// WhenVars 执行条件中变量的值，Event 为空时按手动触发处理
+	WhenVars struct {
+		Branch string
+		Event  string
+		Env    string
+	}
+
+	whenExpr interface {
+		eval(vars map[string]string) bool
+	}
+
+	whenNot struct {
+		expr whenExpr
+	}
+
+	whenLogic struct {
+		and         bool
+		left, right whenExpr
+	}
+
+	whenCompare struct {
+		equal       bool
+		left, right whenOperand
+	}
+
+	// whenOperand 变量或字符串，isVar 为 true 时 value 为变量名
+	whenOperand struct {
+		isVar bool
+		value string
+	}
+
+	whenToken struct {
+		kind  string // ident, string 或运算符本身
+		value string
+	}
+
+	whenParser struct {
+		tokens []whenToken
+		pos    int
+	}
+)
+
+// NormalizeStepWhen 去掉空白并校验阶段名和条件格式，没有设置任何条件时返回 nil
+func NormalizeStepWhen(when map[string]string) (db.MapStringString, error) {
+	var result db.MapStringString
+	for step, expr := range when {
+		if !jobSteps[step] {
+			return nil, ErrInvalidWhenStep
+		}
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		if _, err := parseWhen(expr); err != nil {
+			return nil, err
+		}
+
+		if result == nil {
+			result = make(db.MapStringString)
+		}
+		result[step] = expr
+	}
+	return result, nil
+}
+
+// StepWhen 设置各阶段的执行条件，分片阶段使用对应阶段的设置，需要在添加阶段之后使用
+func StepWhen(when map[string]string) StepOption {
+	return func(desc *db.TestPipelineDesc) {
+		if len(when) == 0 {
+			return
+		}
+		for i := range desc.Steps {
+			step := &desc.Steps[i]
+			if step.SubPipeline != nil {
+				StepWhen(when)(step.SubPipeline)
+			}
+			if step.Type != db.StepTypeJob {
+				continue
+			}
+			if expr, ok := when[shardBaseName(step.Name)]; ok {
+				step.When = expr
+			}
+		}
+	}
+}
+
+// EvalWhen 计算阶段的执行条件，条件为空时总是执行
+func EvalWhen(expr string, vars WhenVars) (bool, error) {
+	if strings.TrimSpace(expr) == "" {
+		return true, nil
+	}
+	parsed, err := parseWhen(expr)
+	if err != nil {
+		return false, err
+	}
+
+	event := vars.Event
+	if event == "" {
+		event = db.TestTaskEventManual
+	}
+	return parsed.eval(map[string]string{
+		"branch": vars.Branch,
+		"event":  event,
+		"env":    vars.Env,
+	}), nil
+}
+
+// parseWhen 条件的语法：
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "(" expr ")" | operand ( "==" | "!=" ) operand
+//	operand = branch | event | env | "字符串"
+func parseWhen(expr string) (whenExpr, error) {
+	if len(expr) > MaxWhenSize {
+		return nil, fmt.Errorf("%w: 不能超过 %d 字节", ErrInvalidWhen, MaxWhenSize)
+	}
+	tokens, err := tokenizeWhen(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, ErrInvalidWhen
+	}
+
+	p := &whenParser{tokens: tokens}
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: 多余的 %s", ErrInvalidWhen, p.tokens[p.pos].value)
+	}
+	return result, nil
+}
+
+func tokenizeWhen(expr string) (tokens []whenToken, err error) {
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, whenToken{kind: string(c), value: string(c)})
+			i++
+		case strings.HasPrefix(expr[i:], "==") || strings.HasPrefix(expr[i:], "!=") ||
+			strings.HasPrefix(expr[i:], "&&") || strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, whenToken{kind: expr[i : i+2], value: expr[i : i+2]})
+			i += 2
+		case c == '!':
+			tokens = append(tokens, whenToken{kind: "!", value: "!"})
+			i++
+		case c == '"':
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(expr) && expr[j] != '"'; j++ {
+				if expr[j] == '\\' && j+1 < len(expr) {
+					j++
+				}
+				value.WriteByte(expr[j])
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("%w: 字符串缺少结束的引号", ErrInvalidWhen)
+			}
+			tokens = append(tokens, whenToken{kind: "string", value: value.String()})
+			i = j + 1
+		case c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			j := i + 1
+			for j < len(expr) && (expr[j] == '_' || ('a' <= expr[j] && expr[j] <= 'z') ||
+				('A' <= expr[j] && expr[j] <= 'Z') || ('0' <= expr[j] && expr[j] <= '9')) {
+				j++
+			}
+			if !whenVariables[expr[i:j]] {
+				return nil, fmt.Errorf("%w: 未知的变量 %s", ErrInvalidWhen, expr[i:j])
+			}
+			tokens = append(tokens, whenToken{kind: "ident", value: expr[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("%w: 不支持的字符 %q", ErrInvalidWhen, c)
+		}
+	}
+	return
+}
+
+func (p *whenParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].kind
+	}
+	return ""
+}
+
+func (p *whenParser) parseOr() (whenExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = whenLogic{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whenParser) parseAnd() (whenExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = whenLogic{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whenParser) parseUnary() (whenExpr, error) {
+	switch p.peek() {
+	case "!":
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return whenNot{expr: expr}, nil
+	case "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("%w: 缺少 )", ErrInvalidWhen)
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	if op != "==" && op != "!=" {
+		return nil, fmt.Errorf("%w: %s 后应为 == 或 !=", ErrInvalidWhen, left.value)
+	}
+	p.pos++
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return whenCompare{equal: op == "==", left: left, right: right}, nil
+}
+
+func (p *whenParser) parseOperand() (whenOperand, error) {
+	switch p.peek() {
+	case "ident":
+		p.pos++
+		return whenOperand{isVar: true, value: p.tokens[p.pos-1].value}, nil
+	case "string":
+		p.pos++
+		return whenOperand{value: p.tokens[p.pos-1].value}, nil
+	case "":
+		return whenOperand{}, fmt.Errorf("%w: 条件不完整", ErrInvalidWhen)
+	}
+	return whenOperand{}, fmt.Errorf("%w: %s 处应为变量或字符串", ErrInvalidWhen, p.tokens[p.pos].value)
+}
+
+func (e whenNot) eval(vars map[string]string) bool {
+	return !e.expr.eval(vars)
+}
+
+func (e whenLogic) eval(vars map[string]string) bool {
+	if e.and {
+		return e.left.eval(vars) && e.right.eval(vars)
+	}
+	return e.left.eval(vars) || e.right.eval(vars)
+}
+
+func (e whenCompare) eval(vars map[string]string) bool {
+	return (e.left.get(vars) == e.right.get(vars)) == e.equal
+}
+
+func (o whenOperand) get(vars map[string]string) string {
+	if o.isVar {
+		return vars[o.value]
+	}
+	return o.value
+}
@@ -0,0 +1,134 @@
+package testplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// WorkerNodes 在线的 worker 节点
+func WorkerNodes(params view.ReqWorkerNodes) (list []view.WorkerNode, err error) {
+	var nodes []db.WorkerNode
+
+	query := option.DB.Where("last_heartbeat >= ?", time.Now().Add(-option.Worker.HeartbeatTimeout))
+	if params.ZoneCode != "" {
+		query = query.Where("zone_code = ?", params.ZoneCode)
+	}
+	err = query.Order("zone_code, host_name").Find(&nodes).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.WorkerNode, 0, len(nodes))
+	for _, node := range nodes {
+		list = append(list, view.WorkerNode{
+			ID:            node.ID,
+			HostName:      node.HostName,
+			IP:            node.IP,
+			Port:          node.Port,
+			Env:           node.Env,
+			ZoneCode:      node.ZoneCode,
+			ZoneName:      node.ZoneName,
+			LastHeartbeat: node.LastHeartbeat,
+			Drained:       node.Drained,
+			Version:       node.Version,
+			Capacity:      node.Capacity,
+			Running:       node.Running,
+			QueueDepth:    node.QueueDepth,
+			StartedAt:     node.StartedAt,
+			Tasks:         node.Tasks,
+			Labels:        node.Labels,
+		})
+	}
+	return
+}
+
+// WorkerQueue worker 节点的等待队列和暂停状态
+func WorkerQueue(params view.ReqWorkerQueue) (queue view.WorkerQueue, err error) {
+	req := view.ReqHTTPProxy{
+		URL:    "/api/v1/queue",
+		Type:   http.MethodGet,
+		Params: map[string]string{"limit": strconv.Itoa(params.Limit)},
+	}
+	err = callWorker(params.NodeID, req, &queue)
+	return
+}
+
+// RemoveQueuedTask 从 worker 队列删除等待中的任务，指定 TaskID 时将任务标记为失败
+func RemoveQueuedTask(params view.ReqWorkerQueueItem, operator string) (err error) {
+	body, _ := json.Marshal(view.ReqQueueItem{ID: params.ID})
+	err = callWorker(params.NodeID, view.ReqHTTPProxy{URL: "/api/v1/queue/remove", Type: http.MethodPost, Body: body}, nil)
+	if err != nil || params.TaskID == 0 {
+		return
+	}
+
+	data, _ := json.Marshal(view.TestTaskUpdateEventPayload{
+		Status:     db.TestTaskStatusFailed,
+		LogsAppend: fmt.Sprintf("task removed from worker queue by %s\n", operator),
+	})
+	return onTaskUpdate(view.TestTaskEvent{Type: view.TaskUpdateEvent, TaskID: params.TaskID, Data: data})
+}
+
+// MoveQueuedTaskToFront 将 worker 队列中等待的任务移到队首
+func MoveQueuedTaskToFront(params view.ReqWorkerQueueItem) error {
+	body, _ := json.Marshal(view.ReqQueueItem{ID: params.ID})
+	return callWorker(params.NodeID, view.ReqHTTPProxy{URL: "/api/v1/queue/moveToFront", Type: http.MethodPost, Body: body}, nil)
+}
+
+// PauseWorker 暂停 worker 节点消费队列，正在执行的任务不受影响
+func PauseWorker(params view.ReqWorkerNode) error {
+	return callWorker(params.NodeID, view.ReqHTTPProxy{URL: "/api/v1/queue/pause", Type: http.MethodPost}, nil)
+}
+
+// ResumeWorker 恢复 worker 节点消费队列
+func ResumeWorker(params view.ReqWorkerNode) error {
+	return callWorker(params.NodeID, view.ReqHTTPProxy{URL: "/api/v1/queue/resume", Type: http.MethodPost}, nil)
+}
+
+// callWorker 通过代理调用 worker 节点的接口，data 不为 nil 时解析返回的 data
+func callWorker(nodeID uint, req view.ReqHTTPProxy, data interface{}) error {
+	var node db.WorkerNode
+	err := option.DB.Where("id = ?", nodeID).First(&node).Error
+	if err != nil {
+		return err
+	}
+
+	req.Address = fmt.Sprintf("%s:%d", node.IP, node.Port)
+	uniqZone := view.UniqZone{Env: node.Env, Zone: node.ZoneCode}
+
+	var resp *resty.Response
+	if req.Type == http.MethodGet {
+		resp, err = clientproxy.ClientProxy.HttpGet(uniqZone, req)
+	} else {
+		resp, err = clientproxy.ClientProxy.HttpPost(uniqZone, req)
+	}
+	if err != nil {
+		return err
+	}
+
+	respObj := struct {
+		Code int             `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	err = json.Unmarshal(resp.Body(), &respObj)
+	if err != nil {
+		return errors.Wrapf(err, "unmarshall response failed")
+	}
+	if respObj.Code != 0 {
+		return errors.Errorf("worker %s: %s", node.HostName, respObj.Msg)
+	}
+
+	if data != nil {
+		return json.Unmarshal(respObj.Data, data)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package testplatform
+
+import (
+	"fmt"
+
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/jinzhu/gorm"
+)
+
+func registerRecycleHandler() {
+	recyclebin.Register(db.RecycleKindPipeline, recyclebin.Handler{
+		Restore: restorePipeline,
+		Purge:   purgePipeline,
+	})
+}
+
+// restorePipeline 取消流水线的删除标记，应用已删除时不能恢复
+func restorePipeline(tx *gorm.DB, item db.RecycleItem) (err error) {
+	var count int
+	err = tx.Model(&db.AppInfo{}).Where("app_name = ?", item.AppName).Count(&count).Error
+	if err != nil {
+		return
+	}
+	if count == 0 {
+		return fmt.Errorf("应用 %s 不存在，请先恢复应用", item.AppName)
+	}
+
+	return tx.Unscoped().Model(&db.TestPipeline{}).Where("id = ?", item.ObjectID).Update("deleted_at", nil).Error
+}
+
+func purgePipeline(tx *gorm.DB, item db.RecycleItem) error {
+	return tx.Unscoped().Where("id = ?", item.ObjectID).Delete(&db.TestPipeline{}).Error
+}
@@ -0,0 +1,118 @@
+package testplatform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/util"
+)
+
+// maxSecretSize 密钥值的长度上限
+const maxSecretSize = pipeline.MaxEnvValueSize
+
+var (
+	ErrNoSecretKey        = fmt.Errorf("未配置 testplatform.secretKey，不能保存流水线密钥")
+	ErrInvalidSecretName  = fmt.Errorf("密钥名格式错误，只能包含字母、数字和下划线，且不能以数字开头")
+	ErrInvalidSecretValue = fmt.Errorf("密钥值不能为空，且不能超过 %d 字节", maxSecretSize)
+	ErrSecretNotFound     = fmt.Errorf("流水线引用的密钥不存在")
+)
+
+// ListSecrets 应用在环境下的密钥，不返回密钥的值
+func ListSecrets(params view.ReqListTestSecret) (list []view.TestSecret, err error) {
+	var secrets []db.TestSecret
+	err = option.DB.Select("name, updated_at, updated_by").
+		Where("app_name = ? and env = ?", params.AppName, params.Env).
+		Order("name asc").Find(&secrets).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.TestSecret, 0, len(secrets))
+	for _, item := range secrets {
+		list = append(list, view.TestSecret{
+			Name:      item.Name,
+			UpdatedAt: item.UpdatedAt,
+			UpdatedBy: item.UpdatedBy,
+		})
+	}
+	return
+}
+
+// SaveSecret 创建或更新密钥，值加密后保存
+func SaveSecret(uid uint, params view.ReqSaveTestSecret) (err error) {
+	if option.SecretKey == "" {
+		return ErrNoSecretKey
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	if !pipeline.ValidSecretName(params.Name) {
+		return ErrInvalidSecretName
+	}
+	if params.Value == "" || len(params.Value) > maxSecretSize {
+		return ErrInvalidSecretValue
+	}
+
+	value, err := util.AESGCMEncrypt(params.Value, option.SecretKey)
+	if err != nil {
+		return
+	}
+
+	var item db.TestSecret
+	err = option.DB.Where(db.TestSecret{AppName: params.AppName, Env: params.Env, Name: params.Name}).
+		Assign(db.TestSecret{Value: value, UpdatedBy: uid}).
+		FirstOrCreate(&item).Error
+	return
+}
+
+// DeleteSecret 删除密钥，引用它的流水线之后的任务会失败
+func DeleteSecret(params view.ReqDeleteTestSecret) error {
+	return option.DB.Where("app_name = ? and env = ? and name = ?", params.AppName, params.Env, params.Name).
+		Delete(&db.TestSecret{}).Error
+}
+
+// TaskSecrets 任务各阶段环境变量引用的密钥，供 worker 执行任务时使用。
+// 只返回任务引用的密钥，任务结束后不再返回；引用的密钥不存在时返回 ErrSecretNotFound
+func TaskSecrets(taskID uint) (secrets map[string]string, err error) {
+	var task db.TestPipelineTask
+	err = option.DB.Where("id = ?", taskID).First(&task).Error
+	if err != nil {
+		return
+	}
+	if isTaskFinished(task.Status) {
+		return nil, ErrTaskFinished
+	}
+
+	secrets = make(map[string]string)
+	names := pipeline.SecretRefs(task.Desc)
+	if len(names) == 0 {
+		return
+	}
+	if option.SecretKey == "" {
+		return nil, ErrNoSecretKey
+	}
+
+	var items []db.TestSecret
+	err = option.DB.Where("app_name = ? and env = ? and name in (?)", task.AppName, task.Env, names).Find(&items).Error
+	if err != nil {
+		return
+	}
+	for _, item := range items {
+		secrets[item.Name], err = util.AESGCMDecrypt(item.Value, option.SecretKey)
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 解密失败，testplatform.secretKey 可能已修改: %s", item.Name, err.Error())
+		}
+	}
+
+	var missing []string
+	for _, name := range names {
+		if _, ok := secrets[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, strings.Join(missing, ", "))
+	}
+	return
+}
@@ -0,0 +1,63 @@
+package testplatform
+
+import (
+	"encoding/json"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// onTaskTestTiming 记录单元测试各个包的耗时，下次分片时按耗时均衡分配
+func onTaskTestTiming(params view.TestTaskEvent) (err error) {
+	var eventData view.TestTaskTimingPayload
+	err = json.Unmarshal(params.Data, &eventData)
+	if err != nil {
+		return errors.Wrapf(err, "invalid event data")
+	}
+	if len(eventData.Packages) == 0 {
+		return
+	}
+
+	var task db.TestPipelineTask
+	err = option.DB.Select("id, app_name").Where("id = ?", params.TaskID).First(&task).Error
+	if err != nil {
+		return
+	}
+
+	tx := option.DB.Begin()
+	for pkg, elapsed := range eventData.Packages {
+		var timing db.TestPackageTiming
+		err = tx.Where("app_name = ? and package = ?", task.AppName, pkg).First(&timing).Error
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			tx.Rollback()
+			return
+		}
+
+		timing.AppName = task.AppName
+		timing.Package = pkg
+		timing.Elapsed = elapsed
+		err = tx.Save(&timing).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+	return tx.Commit().Error
+}
+
+// packageTimings 应用各个包最近一次单元测试的耗时
+func packageTimings(appName string) (timings map[string]float64, err error) {
+	var list []db.TestPackageTiming
+	err = option.DB.Where("app_name = ?", appName).Find(&list).Error
+	if err != nil {
+		return
+	}
+
+	timings = make(map[string]float64, len(list))
+	for _, item := range list {
+		timings[item.Package] = item.Elapsed
+	}
+	return
+}
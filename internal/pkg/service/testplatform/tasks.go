@@ -33,7 +33,8 @@ func clearTimeoutTask() (err error) {
 		return
 	}
 
-	for _, task := range tasks {
+	for i := range tasks {
+		task := &tasks[i]
 		task.Status = db.TestTaskStatusFailed
 		task.Logs += "error: timeout"
 
@@ -47,7 +48,7 @@ func clearTimeoutTask() (err error) {
 			}
 		}
 
-		err = tx.Save(&task).Error
+		err = tx.Save(task).Error
 		if err != nil {
 			tx.Rollback()
 			return
@@ -55,5 +56,9 @@ func clearTimeoutTask() (err error) {
 	}
 
 	tx.Commit()
+
+	for _, task := range tasks {
+		publishTask(task, "")
+	}
 	return
 }
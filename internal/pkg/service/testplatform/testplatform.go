@@ -7,20 +7,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/douyu/jupiter/pkg/xlog"
 
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
 	"github.com/douyu/juno/internal/pkg/service/clientproxy"
+	"github.com/douyu/juno/internal/pkg/service/eventbus"
 	"github.com/douyu/juno/internal/pkg/service/grpctest"
 	"github.com/douyu/juno/internal/pkg/service/grpctest/grpcinvoker"
 	"github.com/douyu/juno/internal/pkg/service/grpctest/grpctester"
+	"github.com/douyu/juno/internal/pkg/service/recyclebin"
+	"github.com/douyu/juno/internal/pkg/service/tag"
+	"github.com/douyu/juno/internal/pkg/service/team"
 	"github.com/douyu/juno/internal/pkg/service/testplatform/pipeline"
 	"github.com/douyu/juno/internal/pkg/service/testplatform/workerpool"
+	"github.com/douyu/juno/internal/pkg/service/wsevent"
+	"github.com/douyu/juno/pkg/i18n"
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jinzhu/gorm"
+	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 )
@@ -29,7 +40,28 @@ func init() {
 	gob.Register(desc.MethodDescriptor{})
 }
 
-func ListPipeline(params view.ReqListPipeline) (pipelines []view.TestPipelineUV, err error) {
+// pipelineListSpec 流水线列表允许排序、筛选的字段，未指定分页参数时返回全部
+var pipelineListSpec = listquery.Spec{
+	Sorts: map[string]string{
+		"id":         "test_pipeline.id",
+		"name":       "test_pipeline.name",
+		"env":        "test_pipeline.env",
+		"zone_code":  "test_pipeline.zone_code",
+		"created_at": "test_pipeline.created_at",
+		"updated_at": "test_pipeline.updated_at",
+	},
+	Filters: map[string]string{
+		"name":      "test_pipeline.name",
+		"branch":    "test_pipeline.branch",
+		"env":       "test_pipeline.env",
+		"zone_code": "test_pipeline.zone_code",
+	},
+	DefaultSort: "-id",
+	Tiebreaker:  "test_pipeline.id",
+}
+
+// ListPipeline zones 不为空时只返回这些机房的流水线
+func ListPipeline(params view.ReqListPipeline, zones ...string) (pipelines []view.TestPipelineUV, page *view.Pagination, err error) {
 	type PipelineWithTaskStatus struct {
 		db.TestPipeline
 		Count  int
@@ -37,23 +69,49 @@ func ListPipeline(params view.ReqListPipeline) (pipelines []view.TestPipelineUV,
 	}
 	var pls []PipelineWithTaskStatus
 
-	query := option.DB.Model(&db.TestPipeline{}).
+	list, err := pipelineListSpec.Parse(params.ListQuery)
+	if err != nil {
+		return
+	}
+
+	query := option.DB.Model(&db.TestPipeline{}).Where("app_name = ?", params.AppName)
+	if params.Env != "" {
+		query = query.Where("env = ?", params.Env)
+	}
+	if params.ZoneCode != "" && params.ZoneCode != "all" {
+		query = query.Where("zone_code = ?", params.ZoneCode)
+	}
+	if len(zones) > 0 {
+		query = query.Where("zone_code in (?) or zone_code = ''", zones)
+	}
+	query = tag.Filter(query, db.TagEntityPipeline, "test_pipeline.id", params.Tags)
+	query = list.Where(query)
+
+	var total int
+	err = query.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	err = list.Paginate(query).
 		Select(
 			"test_pipeline.*, ? as count, ? as status",
 			option.DB.Model(&db.TestPipelineTask{}).Select("count(*) as count").
 				Where("pipeline_id = test_pipeline.id").SubQuery(),
 			option.DB.Model(&db.TestPipelineTask{}).Select("status").
 				Where("pipeline_id = test_pipeline.id").Order("id desc").Limit(1).SubQuery(),
-		).Where("app_name = ?", params.AppName).
-		Order("id desc")
-	if params.Env != "" {
-		query = query.Where("env = ?", params.Env)
-	}
-	if params.ZoneCode != "" && params.ZoneCode != "all" {
-		query = query.Where("zone_code = ?", params.ZoneCode)
+		).
+		Find(&pls).Error
+	if err != nil {
+		return
 	}
+	page = list.Pagination(total, len(pls))
 
-	err = query.Find(&pls).Error
+	keys := make([]string, 0, len(pls))
+	for _, pl := range pls {
+		keys = append(keys, strconv.Itoa(int(pl.ID)))
+	}
+	tags, err := tag.Tag.Tags(db.TagEntityPipeline, keys)
 	if err != nil {
 		return
 	}
@@ -73,9 +131,35 @@ func ListPipeline(params view.ReqListPipeline) (pipelines []view.TestPipelineUV,
 				Branch:             pl.Branch,
 				CodeCheck:          pl.CodeCheck,
 				UnitTest:           pl.UnitTest,
+				UnitTestShards:     pl.UnitTestShards,
+				UnitTestAffected:   pl.UnitTestAffected,
+				UnitTestRace:       pl.UnitTestRace,
+				UnitTestCoverage:   pl.UnitTestCoverage,
+				BaseBranch:         pl.BaseBranch,
+				VulnCheck:          pl.VulnCheck,
+				VulnGate:           pl.VulnGate,
+				LicenseCheck:       pl.LicenseCheck,
+				BuildReport:        pl.BuildReport,
+				BuildPackage:       pl.BuildPackage,
+				CrossBuild:         pl.CrossBuild,
+				CrossBuildTargets:  pl.CrossBuildTargets,
+				BuildCheck:         pl.BuildCheck,
+				BuildCommand:       pl.BuildCommand,
+				Benchmark:          pl.Benchmark,
+				BenchmarkPackages:  pl.BenchmarkPackages,
+				BenchmarkFlags:     pl.BenchmarkFlags,
+				GoVersion:          pl.GoVersion,
+				StepTimeout:        pl.StepTimeout,
+				StepRetries:        pl.StepRetries,
+				StepRetryDelay:     pl.StepRetryDelay,
+				Priority:           pl.Priority,
 				HttpTestCollection: pl.HttpTestCollection,
 				GrpcTestAddr:       pl.GrpcTestAddr,
 				GrpcTestCases:      pl.GrpcTestCases,
+				WorkerLabels:       pl.WorkerLabels,
+				StepArtifacts:      pl.StepArtifacts,
+				StepEnv:            pl.StepEnv,
+				StepWhen:           pl.StepWhen,
 			})
 			if err != nil {
 				return err
@@ -93,9 +177,38 @@ func ListPipeline(params view.ReqListPipeline) (pipelines []view.TestPipelineUV,
 				Status:             pl.Status,
 				CodeCheck:          pl.CodeCheck,
 				UnitTest:           pl.UnitTest,
+				UnitTestShards:     pl.UnitTestShards,
+				UnitTestAffected:   pl.UnitTestAffected,
+				UnitTestRace:       pl.UnitTestRace,
+				UnitTestCoverage:   pl.UnitTestCoverage,
+				BaseBranch:         pl.BaseBranch,
+				FullRunHours:       pl.FullRunHours,
+				LastFullRunAt:      pl.LastFullRunAt,
+				VulnCheck:          pl.VulnCheck,
+				VulnGate:           pl.VulnGate,
+				LicenseCheck:       pl.LicenseCheck,
+				BuildReport:        pl.BuildReport,
+				BuildPackage:       pl.BuildPackage,
+				CrossBuild:         pl.CrossBuild,
+				CrossBuildTargets:  pl.CrossBuildTargets,
+				BuildCheck:         pl.BuildCheck,
+				BuildCommand:       pl.BuildCommand,
+				Benchmark:          pl.Benchmark,
+				BenchmarkPackages:  pl.BenchmarkPackages,
+				BenchmarkFlags:     pl.BenchmarkFlags,
+				GoVersion:          pl.GoVersion,
+				StepTimeout:        pl.StepTimeout,
+				StepRetries:        pl.StepRetries,
+				StepRetryDelay:     pl.StepRetryDelay,
+				Priority:           pl.Priority,
 				HttpTestCollection: pl.HttpTestCollection,
 				GrpcTestAddr:       pl.GrpcTestAddr,
 				GrpcTestCases:      pl.GrpcTestCases,
+				WorkerLabels:       pl.WorkerLabels,
+				StepArtifacts:      pl.StepArtifacts,
+				StepEnv:            pl.StepEnv,
+				StepWhen:           pl.StepWhen,
+				Tags:               tags[strconv.Itoa(int(pl.ID))],
 			}
 
 			// without lock
@@ -124,6 +237,66 @@ func secureFormatDesc(desc db.TestPipelineDesc) db.TestPipelineDesc {
 						continue
 					}
 
+					payload.AccessToken = "******"
+					payloadBytes, _ := json.Marshal(payload)
+					step.JobPayload.Payload = payloadBytes
+				case db.JobVulnCheck:
+					var payload pipeline.JobVulnCheckPayload
+					err := json.Unmarshal(step.JobPayload.Payload, &payload)
+					if err != nil {
+						continue
+					}
+
+					payload.AccessToken = "******"
+					payloadBytes, _ := json.Marshal(payload)
+					step.JobPayload.Payload = payloadBytes
+				case db.JobLicenseCheck:
+					var payload pipeline.JobLicenseCheckPayload
+					err := json.Unmarshal(step.JobPayload.Payload, &payload)
+					if err != nil {
+						continue
+					}
+
+					payload.AccessToken = "******"
+					payloadBytes, _ := json.Marshal(payload)
+					step.JobPayload.Payload = payloadBytes
+				case db.JobBuildReport:
+					var payload pipeline.JobBuildReportPayload
+					err := json.Unmarshal(step.JobPayload.Payload, &payload)
+					if err != nil {
+						continue
+					}
+
+					payload.AccessToken = "******"
+					payloadBytes, _ := json.Marshal(payload)
+					step.JobPayload.Payload = payloadBytes
+				case db.JobCrossBuild:
+					var payload pipeline.JobCrossBuildPayload
+					err := json.Unmarshal(step.JobPayload.Payload, &payload)
+					if err != nil {
+						continue
+					}
+
+					payload.AccessToken = "******"
+					payloadBytes, _ := json.Marshal(payload)
+					step.JobPayload.Payload = payloadBytes
+				case db.JobBuildCheck:
+					var payload pipeline.JobBuildCheckPayload
+					err := json.Unmarshal(step.JobPayload.Payload, &payload)
+					if err != nil {
+						continue
+					}
+
+					payload.AccessToken = "******"
+					payloadBytes, _ := json.Marshal(payload)
+					step.JobPayload.Payload = payloadBytes
+				case db.JobBenchmark:
+					var payload pipeline.JobBenchmarkPayload
+					err := json.Unmarshal(step.JobPayload.Payload, &payload)
+					if err != nil {
+						continue
+					}
+
 					payload.AccessToken = "******"
 					payloadBytes, _ := json.Marshal(payload)
 					step.JobPayload.Payload = payloadBytes
@@ -145,6 +318,36 @@ func secureFormatDesc(desc db.TestPipelineDesc) db.TestPipelineDesc {
 }
 
 func CreatePipeline(uid uint, payload view.TestPipeline) (err error) {
+	err = checkAppWritable(payload.AppName)
+	if err != nil {
+		return
+	}
+
+	goVersion, err := pipeline.NormalizeGoVersion(payload.GoVersion)
+	if err != nil {
+		return
+	}
+	crossBuildTargets, err := pipeline.NormalizeBuildTargets(payload.CrossBuildTargets)
+	if err != nil {
+		return
+	}
+	benchmarkPackages, benchmarkFlags, err := pipeline.NormalizeBenchmarkArgs(payload.BenchmarkPackages, payload.BenchmarkFlags)
+	if err != nil {
+		return
+	}
+	stepArtifacts, err := pipeline.NormalizeStepArtifacts(payload.StepArtifacts)
+	if err != nil {
+		return
+	}
+	stepEnv, err := pipeline.NormalizeStepEnv(payload.StepEnv)
+	if err != nil {
+		return
+	}
+	stepWhen, err := pipeline.NormalizeStepWhen(payload.StepWhen)
+	if err != nil {
+		return
+	}
+
 	var pl db.TestPipeline
 	pl = db.TestPipeline{
 		Name:               payload.Name,
@@ -155,9 +358,36 @@ func CreatePipeline(uid uint, payload view.TestPipeline) (err error) {
 		Branch:             payload.Branch,
 		CodeCheck:          payload.CodeCheck,
 		UnitTest:           payload.UnitTest,
+		UnitTestShards:     payload.UnitTestShards,
+		UnitTestAffected:   payload.UnitTestAffected,
+		UnitTestRace:       payload.UnitTestRace,
+		UnitTestCoverage:   payload.UnitTestCoverage,
+		BaseBranch:         payload.BaseBranch,
+		FullRunHours:       payload.FullRunHours,
+		VulnCheck:          payload.VulnCheck,
+		VulnGate:           payload.VulnGate,
+		LicenseCheck:       payload.LicenseCheck,
+		BuildReport:        payload.BuildReport,
+		BuildPackage:       payload.BuildPackage,
+		CrossBuild:         payload.CrossBuild,
+		CrossBuildTargets:  crossBuildTargets,
+		BuildCheck:         payload.BuildCheck,
+		BuildCommand:       payload.BuildCommand,
+		Benchmark:          payload.Benchmark,
+		BenchmarkPackages:  benchmarkPackages,
+		BenchmarkFlags:     benchmarkFlags,
+		GoVersion:          goVersion,
+		StepTimeout:        payload.StepTimeout,
+		StepRetries:        payload.StepRetries,
+		StepRetryDelay:     payload.StepRetryDelay,
+		Priority:           payload.Priority,
 		HttpTestCollection: payload.HttpTestCollection,
 		GrpcTestCases:      payload.GrpcTestCases,
 		GrpcTestAddr:       payload.GrpcTestAddr,
+		WorkerLabels:       payload.WorkerLabels,
+		StepArtifacts:      stepArtifacts,
+		StepEnv:            stepEnv,
+		StepWhen:           stepWhen,
 	}
 
 	err = option.DB.Save(&pl).Error
@@ -177,8 +407,40 @@ func makePipelineDesc(payload view.TestPipeline) (desc *db.TestPipelineDesc, err
 		userTaskOptions = append(userTaskOptions, pipeline.StepCodeCheck())
 	}
 
-	if payload.UnitTest {
-		userTaskOptions = append(userTaskOptions, pipeline.StepUnitTest(option.GitAccessToken))
+	sharded := payload.UnitTest && payload.UnitTestShards > 1
+	var unitTestOptions []pipeline.UnitTestOption
+	if payload.UnitTestAffected {
+		unitTestOptions = append(unitTestOptions, pipeline.UnitTestAffected(baseBranch(payload.BaseBranch)))
+	}
+	if payload.UnitTestRace {
+		unitTestOptions = append(unitTestOptions, pipeline.UnitTestRace())
+	}
+	if payload.UnitTestCoverage {
+		unitTestOptions = append(unitTestOptions, pipeline.UnitTestCoverage())
+	}
+	if payload.UnitTest && !sharded {
+		userTaskOptions = append(userTaskOptions, pipeline.StepUnitTest(option.GitAccessToken, unitTestOptions...))
+	}
+
+	if payload.VulnCheck {
+		userTaskOptions = append(userTaskOptions, pipeline.StepVulnCheck(option.GitAccessToken, payload.VulnGate))
+	}
+
+	if payload.LicenseCheck {
+		userTaskOptions = append(userTaskOptions, pipeline.StepLicenseCheck(option.GitAccessToken, licensePolicy()))
+	}
+
+	if payload.BuildReport {
+		userTaskOptions = append(userTaskOptions, pipeline.StepBuildReport(option.GitAccessToken, payload.BuildPackage))
+	}
+
+	if payload.CrossBuild {
+		var targets []pipeline.BuildTarget
+		targets, err = pipeline.ParseBuildTargets(payload.CrossBuildTargets)
+		if err != nil {
+			return
+		}
+		userTaskOptions = append(userTaskOptions, pipeline.StepCrossBuild(option.GitAccessToken, targets))
 	}
 
 	if payload.HttpTestCollection != nil {
@@ -201,7 +463,7 @@ func makePipelineDesc(payload view.TestPipeline) (desc *db.TestPipelineDesc, err
 		}
 	}
 
-	if len(userTaskOptions) == 0 {
+	if len(userTaskOptions) == 0 && !sharded && !payload.BuildCheck && !payload.Benchmark {
 		err = fmt.Errorf("最少要有一个执行的任务")
 		return
 	}
@@ -211,21 +473,87 @@ func makePipelineDesc(payload view.TestPipeline) (desc *db.TestPipelineDesc, err
 		return
 	}
 
-	taskOptions = append(taskOptions, pipeline.StepGitPull(
+	if len(userTaskOptions) > 0 || payload.BuildCheck || payload.Benchmark {
+		taskOptions = append(taskOptions, pipeline.StepGitPull(
+			app.WebURL,
+			payload.Branch,
+			option.GitAccessToken,
+		))
+
+		// 编译检查在其他阶段之前执行，编译失败时不再执行测试
+		if payload.BuildCheck {
+			taskOptions = append(taskOptions, pipeline.StepBuildCheck(option.GitAccessToken, payload.BuildCommand))
+		}
+	}
+	if len(userTaskOptions) > 0 {
+		userTaskOptions = append(userTaskOptions, pipeline.Parallel(true))
+		taskOptions = append(taskOptions, pipeline.StepSubPipeline(
+			userTaskOptions...,
+		))
+	}
+	// 基准测试在其他阶段结束后单独执行，避免并行的阶段影响耗时
+	if payload.Benchmark {
+		taskOptions = append(taskOptions, pipeline.StepBenchmark(option.GitAccessToken, payload.BenchmarkPackages, payload.BenchmarkFlags))
+	}
+
+	if !sharded {
+		taskOptions = append(taskOptions, pipeline.GoVersion(payload.GoVersion), pipeline.StepTimeout(payload.StepTimeout),
+			pipeline.StepRetry(payload.StepRetries, payload.StepRetryDelay), pipeline.StepArtifacts(payload.StepArtifacts),
+			pipeline.StepEnv(payload.StepEnv), pipeline.StepWhen(payload.StepWhen))
+		desc = pipeline.New(taskOptions...)
+		return
+	}
+
+	// 分片时其他阶段和各个分片作为并行的子流水线，分别下发到 worker
+	timings, err := packageTimings(payload.AppName)
+	if err != nil {
+		return
+	}
+	shardOptions := []pipeline.StepOption{pipeline.Parallel(true)}
+	if len(taskOptions) > 0 {
+		shardOptions = append(shardOptions, pipeline.StepSubPipeline(taskOptions...))
+	}
+	shardOptions = append(shardOptions, pipeline.StepUnitTestShards(
 		app.WebURL,
 		payload.Branch,
 		option.GitAccessToken,
+		payload.UnitTestShards,
+		timings,
+		unitTestOptions...,
 	))
 
-	userTaskOptions = append(userTaskOptions, pipeline.Parallel(true))
-	taskOptions = append(taskOptions, pipeline.StepSubPipeline(
-		userTaskOptions...,
-	))
-
-	desc = pipeline.New(taskOptions...)
+	shardOptions = append(shardOptions, pipeline.GoVersion(payload.GoVersion), pipeline.StepTimeout(payload.StepTimeout),
+		pipeline.StepRetry(payload.StepRetries, payload.StepRetryDelay), pipeline.StepArtifacts(payload.StepArtifacts),
+		pipeline.StepEnv(payload.StepEnv), pipeline.StepWhen(payload.StepWhen))
+	desc = pipeline.New(shardOptions...)
 	return
 }
 
+// baseBranch 影响分析的基准分支，未设置时为 master
+func baseBranch(branch string) string {
+	if branch == "" {
+		return "master"
+	}
+	return branch
+}
+
+// taskParts 任务拆分后分别下发到 worker 的部分，分片的任务每个顶层子流水线单独下发
+func taskParts(desc db.TestPipelineDesc, sharded bool) []db.TestPipelineDesc {
+	if !sharded {
+		return []db.TestPipelineDesc{desc}
+	}
+
+	parts := make([]db.TestPipelineDesc, 0, len(desc.Steps))
+	for _, step := range desc.Steps {
+		if step.SubPipeline != nil {
+			part := *step.SubPipeline
+			part.GoVersion = desc.GoVersion
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
 func makeGrpcTestCases(addr string, cases db.PipelineGrpcTestCases) (stepOption pipeline.StepOption, err error) {
 	var testCases []view.GrpcTestCase
 
@@ -295,6 +623,31 @@ func makeGrpcTestCases(addr string, cases db.PipelineGrpcTestCases) (stepOption
 func UpdatePipeline(uid uint, payload view.TestPipeline) (err error) {
 	var pl db.TestPipeline
 
+	goVersion, err := pipeline.NormalizeGoVersion(payload.GoVersion)
+	if err != nil {
+		return
+	}
+	crossBuildTargets, err := pipeline.NormalizeBuildTargets(payload.CrossBuildTargets)
+	if err != nil {
+		return
+	}
+	benchmarkPackages, benchmarkFlags, err := pipeline.NormalizeBenchmarkArgs(payload.BenchmarkPackages, payload.BenchmarkFlags)
+	if err != nil {
+		return
+	}
+	stepArtifacts, err := pipeline.NormalizeStepArtifacts(payload.StepArtifacts)
+	if err != nil {
+		return
+	}
+	stepEnv, err := pipeline.NormalizeStepEnv(payload.StepEnv)
+	if err != nil {
+		return
+	}
+	stepWhen, err := pipeline.NormalizeStepWhen(payload.StepWhen)
+	if err != nil {
+		return
+	}
+
 	err = option.DB.Where("id = ?", payload.ID).Preload("App").First(&pl).Error
 	if err != nil {
 		return
@@ -305,9 +658,36 @@ func UpdatePipeline(uid uint, payload view.TestPipeline) (err error) {
 	pl.Branch = payload.Branch
 	pl.CodeCheck = payload.CodeCheck
 	pl.UnitTest = payload.UnitTest
+	pl.UnitTestShards = payload.UnitTestShards
+	pl.UnitTestAffected = payload.UnitTestAffected
+	pl.UnitTestRace = payload.UnitTestRace
+	pl.UnitTestCoverage = payload.UnitTestCoverage
+	pl.BaseBranch = payload.BaseBranch
+	pl.FullRunHours = payload.FullRunHours
+	pl.VulnCheck = payload.VulnCheck
+	pl.VulnGate = payload.VulnGate
+	pl.LicenseCheck = payload.LicenseCheck
+	pl.BuildReport = payload.BuildReport
+	pl.BuildPackage = payload.BuildPackage
+	pl.CrossBuild = payload.CrossBuild
+	pl.CrossBuildTargets = crossBuildTargets
+	pl.BuildCheck = payload.BuildCheck
+	pl.BuildCommand = payload.BuildCommand
+	pl.Benchmark = payload.Benchmark
+	pl.BenchmarkPackages = benchmarkPackages
+	pl.BenchmarkFlags = benchmarkFlags
+	pl.GoVersion = goVersion
+	pl.StepTimeout = payload.StepTimeout
+	pl.StepRetries = payload.StepRetries
+	pl.StepRetryDelay = payload.StepRetryDelay
+	pl.Priority = payload.Priority
 	pl.HttpTestCollection = payload.HttpTestCollection
 	pl.GrpcTestCases = payload.GrpcTestCases
 	pl.GrpcTestAddr = payload.GrpcTestAddr
+	pl.WorkerLabels = payload.WorkerLabels
+	pl.StepArtifacts = stepArtifacts
+	pl.StepEnv = stepEnv
+	pl.StepWhen = stepWhen
 
 	err = option.DB.Save(&pl).Error
 	if err != nil {
@@ -317,20 +697,57 @@ func UpdatePipeline(uid uint, payload view.TestPipeline) (err error) {
 	return
 }
 
-func DeletePipeline(id int) (err error) {
-	err = option.DB.Where("id = ?", id).Delete(&db.TestPipeline{}).Error
+// DeletePipeline 删除流水线，删除的流水线保留在回收站中，保留期内可以恢复
+func DeletePipeline(uid, id int) (err error) {
+	var pl db.TestPipeline
+	err = option.DB.Where("id = ?", id).First(&pl).Error
 	if err != nil {
 		return
 	}
 
-	return
+	tx := option.DB.Begin()
+	err = tx.Delete(&pl).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = recyclebin.Add(tx, db.RecycleItem{
+		Kind:      db.RecycleKindPipeline,
+		ObjectID:  pl.ID,
+		Name:      pl.Name,
+		AppName:   pl.AppName,
+		Env:       pl.Env,
+		DeletedBy: uid,
+	})
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	return tx.Commit().Error
 }
 
-func DispatchTask(uid, pipelineID uint) (err error) {
+// DispatchTask 创建任务并下发到 worker，ctx 中的追踪上下文会随任务传递到 worker。
+// commitSHA 不为空时，同一提交还在排队的任务由新任务取代，返回被取代的任务 ID。
+// event 为触发任务的事件，worker 用于计算阶段的执行条件，为空时是手动触发
+func DispatchTask(ctx context.Context, uid, pipelineID uint, commitSHA, event string) (taskID uint, superseded []uint, err error) {
 	if !option.Enable {
-		return fmt.Errorf("测试平台功能未启用，请联系管理员")
+		err = fmt.Errorf("测试平台功能未启用，请联系管理员")
+		return
+	}
+
+	commitSHA, err = normalizeCommitSHA(commitSHA)
+	if err != nil {
+		return
+	}
+	event, err = normalizeTaskEvent(event)
+	if err != nil {
+		return
 	}
 
+	span, ctx := tracing.StartSpan(ctx, "testplatform.DispatchTask", opentracing.Tag{Key: "pipeline.id", Value: pipelineID})
+	defer func() { tracing.Finish(span, err) }()
+
 	var pl db.TestPipeline
 
 	err = option.DB.Where("id = ?", pipelineID).First(&pl).Error
@@ -338,6 +755,16 @@ func DispatchTask(uid, pipelineID uint) (err error) {
 		return
 	}
 
+	err = checkAppWritable(pl.AppName)
+	if err != nil {
+		return
+	}
+
+	fullRun, err := checkFullRun(pl)
+	if err != nil {
+		return
+	}
+
 	desc, err := makePipelineDesc(view.TestPipeline{
 		Name:               pl.Name,
 		Env:                pl.Env,
@@ -346,24 +773,62 @@ func DispatchTask(uid, pipelineID uint) (err error) {
 		Branch:             pl.Branch,
 		CodeCheck:          pl.CodeCheck,
 		UnitTest:           pl.UnitTest,
+		UnitTestShards:     pl.UnitTestShards,
+		UnitTestAffected:   pl.UnitTestAffected && !fullRun,
+		UnitTestRace:       pl.UnitTestRace,
+		UnitTestCoverage:   pl.UnitTestCoverage,
+		BaseBranch:         pl.BaseBranch,
+		VulnCheck:          pl.VulnCheck,
+		VulnGate:           pl.VulnGate,
+		LicenseCheck:       pl.LicenseCheck,
+		BuildReport:        pl.BuildReport,
+		BuildPackage:       pl.BuildPackage,
+		CrossBuild:         pl.CrossBuild,
+		CrossBuildTargets:  pl.CrossBuildTargets,
+		BuildCheck:         pl.BuildCheck,
+		BuildCommand:       pl.BuildCommand,
+		Benchmark:          pl.Benchmark,
+		BenchmarkPackages:  pl.BenchmarkPackages,
+		BenchmarkFlags:     pl.BenchmarkFlags,
+		GoVersion:          pl.GoVersion,
+		StepTimeout:        pl.StepTimeout,
+		StepRetries:        pl.StepRetries,
+		StepRetryDelay:     pl.StepRetryDelay,
+		Priority:           pl.Priority,
 		HttpTestCollection: pl.HttpTestCollection,
 		GrpcTestCases:      pl.GrpcTestCases,
+		StepArtifacts:      pl.StepArtifacts,
+		StepEnv:            pl.StepEnv,
+		StepWhen:           pl.StepWhen,
 	})
 	if err != nil {
 		return
 	}
 
+	if pl.Benchmark {
+		var baseline *pipeline.JobBenchmarkBaseline
+		baseline, err = benchmarkBaseline(pl.ID, pl.Branch)
+		if err != nil {
+			return
+		}
+		pipeline.BenchmarkBaseline(baseline)(desc)
+	}
+
 	task := db.TestPipelineTask{
-		Name:       pl.Name,
-		PipelineID: pipelineID,
-		Branch:     pl.Branch,
-		AppName:    pl.AppName,
-		Env:        pl.Env,
-		ZoneCode:   pl.ZoneCode,
-		Desc:       *desc,
-		Status:     db.TestTaskStatusPending,
-		Logs:       "",
-		CreatedBy:  uid,
+		Name:         pl.Name,
+		PipelineID:   pipelineID,
+		Branch:       pl.Branch,
+		AppName:      pl.AppName,
+		Env:          pl.Env,
+		ZoneCode:     pl.ZoneCode,
+		Desc:         *desc,
+		Status:       db.TestTaskStatusPending,
+		Logs:         "",
+		CommitSHA:    commitSHA,
+		Priority:     pl.Priority,
+		CreatedBy:    uid,
+		WorkerLabels: pl.WorkerLabels,
+		Event:        event,
 	}
 
 	err = func() (err error) {
@@ -380,21 +845,52 @@ func DispatchTask(uid, pipelineID uint) (err error) {
 			return err
 		}
 
-		err = dispatchToWorker(task)
+		superseded, err = supersedePendingTasks(tx, task)
 		if err != nil {
 			return err
 		}
 
+		parts := taskParts(task.Desc, pl.UnitTest && pl.UnitTestShards > 1)
+		for i, part := range parts {
+			partNo := 0
+			if len(parts) > 1 {
+				partNo = i + 1
+			}
+			err = dispatchToWorker(ctx, task, part, partNo)
+			if err != nil {
+				return err
+			}
+		}
+
 		tx.Commit()
 		return nil
 	}()
 	if err != nil {
-		return err
+		return
 	}
 
+	publishTask(task, "")
+	for _, id := range superseded {
+		prev := task
+		prev.ID, prev.Status = id, db.TestTaskStatusSuperseded
+		publishTask(prev, "")
+	}
 	go runGrpcTest(task.ID, pl)
 
-	return
+	return task.ID, superseded, nil
+}
+
+// checkFullRun 开启影响分析的流水线距上次全量执行超过 FullRunHours 时本次执行全部测试，并记录全量执行时间
+func checkFullRun(pl db.TestPipeline) (fullRun bool, err error) {
+	if !pl.UnitTest || !pl.UnitTestAffected || pl.FullRunHours <= 0 {
+		return false, nil
+	}
+	if pl.LastFullRunAt != nil && time.Since(*pl.LastFullRunAt) < time.Duration(pl.FullRunHours)*time.Hour {
+		return false, nil
+	}
+
+	err = option.DB.Model(&db.TestPipeline{}).Where("id = ?", pl.ID).Update("last_full_run_at", time.Now()).Error
+	return err == nil, err
 }
 
 func runGrpcTest(taskId uint, pl db.TestPipeline) {
@@ -513,6 +1009,18 @@ func UpdateTaskStatus(params view.TestTaskEvent) (err error) {
 		err = onTaskUpdate(params)
 	case view.TaskStepUpdateEvent:
 		err = onTaskStepUpdate(params)
+	case view.TaskArtifactEvent:
+		err = onTaskArtifact(params)
+	case view.TaskTestTimingEvent:
+		err = onTaskTestTiming(params)
+	case view.TaskVulnerabilityEvent:
+		err = onTaskVulnerability(params)
+	case view.TaskBuildReportEvent:
+		err = onTaskBuildReport(params)
+	case view.TaskCoverageEvent:
+		err = onTaskCoverage(params)
+	case view.TaskBenchmarkEvent:
+		err = onTaskBenchmark(params)
 	}
 
 	return
@@ -520,6 +1028,7 @@ func UpdateTaskStatus(params view.TestTaskEvent) (err error) {
 
 func onTaskUpdate(params view.TestTaskEvent) error {
 	var task db.TestPipelineTask
+	var prevStatus db.TestTaskStatus
 	var eventData view.TestTaskUpdateEventPayload
 
 	err := json.Unmarshal(params.Data, &eventData)
@@ -535,8 +1044,25 @@ func onTaskUpdate(params view.TestTaskEvent) error {
 			return errors.Wrapf(err, "cannot found task where id = %d", task.ID)
 		}
 
-		task.Status = eventData.Status
+		prevStatus = task.Status
+		// 被取代的任务不再执行，worker 收到冲突后跳过
+		if task.Status == db.TestTaskStatusSuperseded {
+			tx.Rollback()
+			return ErrTaskSuperseded
+		}
+		// 已取消的任务只接受 worker 终止后上报的取消，其他状态返回冲突，worker 收到后终止任务
+		if task.Status == db.TestTaskStatusCanceled && eventData.Status != db.TestTaskStatusCanceled {
+			tx.Rollback()
+			return ErrTaskCanceled
+		}
+		// 分片任务的各部分分别上报开始执行，已经结束的任务不再回到执行中
+		if !(eventData.Status == db.TestTaskStatusRunning && isTaskFinished(task.Status)) {
+			task.Status = eventData.Status
+		}
 		task.Logs += eventData.LogsAppend
+		if eventData.GoVersion != "" {
+			task.GoVersion = eventData.GoVersion
+		}
 
 		err = tx.Save(&task).Error
 		if err != nil {
@@ -546,11 +1072,14 @@ func onTaskUpdate(params view.TestTaskEvent) error {
 	}
 	tx.Commit()
 
+	publishTask(task, "")
+	notifyTaskFinished(task, prevStatus)
 	return nil
 }
 
 func onTaskStepUpdate(params view.TestTaskEvent) (err error) {
 	var task db.TestPipelineTask
+	var prevStatus db.TestTaskStatus
 	var taskStepStatus db.TestPipelineStepStatus
 	var steps []db.TestPipelineStepStatus
 	var eventData view.TestTaskStepUpdatePayload
@@ -592,7 +1121,8 @@ func onTaskStepUpdate(params view.TestTaskEvent) (err error) {
 			return
 		}
 
-		if len(steps) >= task.Desc.JobCount() {
+		prevStatus = task.Status
+		if task.Status != db.TestTaskStatusCanceled && len(steps) >= task.Desc.JobCount() {
 			// 检查是否全部结束
 			finish, success := checkTaskFinish(steps)
 			if !finish {
@@ -615,16 +1145,113 @@ func onTaskStepUpdate(params view.TestTaskEvent) (err error) {
 		return
 	}
 
+	publishTask(task, eventData.StepName)
+	publishStepLogs(task, eventData)
+	notifyTaskFinished(task, prevStatus)
+	if eventData.Status == db.TestStepStatusSuccess || eventData.Status == db.TestStepStatusFailed {
+		go indexStepLogs(task, taskStepStatus)
+	}
+	if task.Status == db.TestTaskStatusCanceled {
+		// 阶段的日志照常保存，返回冲突通知没有收到取消请求的 worker 终止任务
+		return ErrTaskCanceled
+	}
 	return
 }
 
+// publishTask 推送任务状态变化，step 为发生变化的阶段，任务本身变化时为空
+func publishTask(task db.TestPipelineTask, step string) {
+	wsevent.Publish(wsevent.Event{
+		Topic: wsevent.TopicTask,
+		Key:   fmt.Sprintf("test/%d", task.PipelineID),
+		Zone:  task.ZoneCode,
+		Data: map[string]interface{}{
+			"task_id":  task.ID,
+			"app_name": task.AppName,
+			"status":   task.Status,
+			"step":     step,
+		},
+	})
+}
+
+// publishStepLogs 推送阶段新增的日志，页面打开任务详情时实时追加，不需要轮询。只推送给有流水线读权限的用户
+func publishStepLogs(task db.TestPipelineTask, step view.TestTaskStepUpdatePayload) {
+	if step.LogsAppend == "" {
+		return
+	}
+	wsevent.Publish(wsevent.Event{
+		Topic:   wsevent.TopicTaskLog,
+		Key:     fmt.Sprintf("task/%d", task.ID),
+		Zone:    task.ZoneCode,
+		AppName: task.AppName,
+		Env:     task.Env,
+		AppPerm: db.AppPermPipelineRead,
+		Data: map[string]interface{}{
+			"task_id": task.ID,
+			"step":    step.StepName,
+			"status":  step.Status,
+			"logs":    step.LogsAppend,
+		},
+	})
+}
+
+// notifyTaskFinished 任务执行结束时通知应用所属团队，并发布到事件总线
+func notifyTaskFinished(task db.TestPipelineTask, prevStatus db.TestTaskStatus) {
+	if task.Status == prevStatus {
+		return
+	}
+
+	var (
+		result   i18n.Text
+		severity string
+	)
+	switch task.Status {
+	case db.TestTaskStatusSuccess:
+		result, severity = "成功", notice.SeverityInfo
+	case db.TestTaskStatusFailed:
+		result, severity = "失败", notice.SeverityError
+	default:
+		return
+	}
+
+	eventbus.Publish(eventbus.TopicTaskFinished, eventbus.TaskFinished{
+		TaskID:     task.ID,
+		PipelineID: task.PipelineID,
+		Name:       task.Name,
+		AppName:    task.AppName,
+		Env:        task.Env,
+		ZoneCode:   task.ZoneCode,
+		Branch:     task.Branch,
+		Status:     string(task.Status),
+	})
+
+	go team.Team.Notify(notice.Event{
+		Type:     notice.EventPipeline,
+		App:      task.AppName,
+		Env:      task.Env,
+		Severity: severity,
+		Localized: &notice.Localized{
+			Subject: i18n.Msg("[Juno] 应用 %s 流水线 %s 执行%s", task.AppName, task.Name, result),
+			Content: i18n.Msg("[Juno] 应用 %s 流水线 %s 执行%s\n环境: %s/%s\n分支: %s\n任务ID: %d",
+				task.AppName, task.Name, result, task.Env, task.ZoneCode, task.Branch, task.ID),
+		},
+	})
+}
+
+func isTaskFinished(status db.TestTaskStatus) bool {
+	switch status {
+	case db.TestTaskStatusSuccess, db.TestTaskStatusFailed, db.TestTaskStatusSuperseded, db.TestTaskStatusCanceled:
+		return true
+	}
+	return false
+}
+
 func checkTaskFinish(steps []db.TestPipelineStepStatus) (finished, success bool) {
 	finished = true
 	success = true
 	for _, step := range steps {
 		if step.Status == db.TestStepStatusFailed {
 			success = false
-		} else if step.Status != db.TestStepStatusSuccess {
+		} else if step.Status != db.TestStepStatusSuccess && step.Status != db.TestStepStatusSkipped {
 			finished = false
 			success = false
 			break
@@ -634,7 +1261,13 @@ func checkTaskFinish(steps []db.TestPipelineStepStatus) (finished, success bool)
 	return
 }
 
-func dispatchToWorker(task db.TestPipelineTask) error {
+// dispatchToWorker 下发任务的 desc 部分到 worker，part 为拆分的部分序号，不拆分时为 0
+func dispatchToWorker(ctx context.Context, task db.TestPipelineTask, desc db.TestPipelineDesc, part int) error {
+	span, ctx := tracing.StartSpan(ctx, "testplatform.dispatchToWorker",
+		opentracing.Tag{Key: "task.id", Value: task.ID},
+		opentracing.Tag{Key: "app.name", Value: task.AppName})
+	defer span.Finish()
+
 	var app db.AppInfo
 
 	err := option.DB.Where("app_name = ?", task.AppName).First(&app).Error
@@ -642,20 +1275,25 @@ func dispatchToWorker(task db.TestPipelineTask) error {
 		return err
 	}
 
-	node, err := workerpool.Instance().Select(task.ZoneCode)
+	node, err := workerpool.Instance().Select(task.ZoneCode, task.WorkerLabels)
 	if err != nil {
 		return err
 	}
 
 	taskBytes, _ := json.Marshal(view.TestTask{
-		TaskID:   task.ID,
-		Name:     task.Name,
-		AppName:  task.AppName,
-		Env:      task.Env,
-		ZoneCode: task.ZoneCode,
-		Branch:   task.Branch,
-		Desc:     task.Desc,
-		GitUrl:   app.WebURL,
+		TaskID:       task.ID,
+		Name:         task.Name,
+		AppName:      task.AppName,
+		Env:          task.Env,
+		ZoneCode:     task.ZoneCode,
+		Branch:       task.Branch,
+		Desc:         desc,
+		GitUrl:       app.WebURL,
+		Trace:        tracing.Inject(ctx),
+		Part:         part,
+		Priority:     task.Priority,
+		WorkerLabels: task.WorkerLabels,
+		Event:        task.Event,
 	})
 
 	resp, err := clientproxy.ClientProxy.HttpPost(
@@ -668,6 +1306,7 @@ func dispatchToWorker(task db.TestPipelineTask) error {
 			URL:     "/api/v1/testTask/dispatch",
 			Type:    http.MethodPost,
 			Body:    taskBytes,
+			Context: ctx,
 		},
 	)
 	if err != nil {
@@ -730,21 +1369,57 @@ func ListTasks(params view.ReqQueryTestTasks) (list []view.TestTask, pagination
 
 	for _, task := range tasks {
 		list = append(list, view.TestTask{
-			TaskID:    task.ID,
-			Name:      task.Name,
-			AppName:   task.AppName,
-			Env:       task.Env,
-			ZoneCode:  task.ZoneCode,
-			Branch:    task.Branch,
-			Desc:      task.Desc,
-			Status:    task.Status,
-			CreatedAt: task.CreatedAt,
+			TaskID:       task.ID,
+			Name:         task.Name,
+			AppName:      task.AppName,
+			Env:          task.Env,
+			ZoneCode:     task.ZoneCode,
+			Branch:       task.Branch,
+			Desc:         task.Desc,
+			Status:       task.Status,
+			CreatedAt:    task.CreatedAt,
+			GoVersion:    task.GoVersion,
+			CommitSHA:    task.CommitSHA,
+			SupersededBy: task.SupersededBy,
+			Priority:     task.Priority,
+			WorkerLabels: task.WorkerLabels,
+			Event:        task.Event,
 		})
 	}
 
 	return
 }
 
+// TaskInfo 任务详情，包括任务级别的日志
+func TaskInfo(taskID uint) (task view.TestTask, err error) {
+	var item db.TestPipelineTask
+	err = option.DB.Where("id = ?", taskID).First(&item).Error
+	if err != nil {
+		return
+	}
+
+	task = view.TestTask{
+		TaskID:       item.ID,
+		Name:         item.Name,
+		AppName:      item.AppName,
+		Env:          item.Env,
+		ZoneCode:     item.ZoneCode,
+		Branch:       item.Branch,
+		Desc:         item.Desc,
+		Status:       item.Status,
+		CreatedAt:    item.CreatedAt,
+		Logs:         item.Logs,
+		GoVersion:    item.GoVersion,
+		CommitSHA:    item.CommitSHA,
+		SupersededBy: item.SupersededBy,
+		Priority:     item.Priority,
+		WorkerLabels: item.WorkerLabels,
+		Event:        item.Event,
+	}
+	task.Artifacts, err = TaskArtifacts(taskID)
+	return
+}
+
 func TaskSteps(taskId uint) (list []view.TestTaskStepStatus, err error) {
 	var steps []db.TestPipelineStepStatus
 	err = option.DB.Where("task_id = ?", taskId).Find(&steps).Error
@@ -787,3 +1462,60 @@ func WorkerZones() (zones []view.WorkerZone, err error) {
 
 	return
 }
+
+// PipelineAppEnv 获取流水线所属应用和环境，用于应用权限校验
+func PipelineAppEnv(pipelineID uint) (appName, env string, err error) {
+	var pl db.TestPipeline
+	err = option.DB.Where("id = ?", pipelineID).First(&pl).Error
+	if err != nil {
+		return
+	}
+
+	return pl.AppName, pl.Env, nil
+}
+
+// TaskAppEnv 获取任务所属应用和环境，用于应用权限校验
+func TaskAppEnv(taskID uint) (appName, env string, err error) {
+	var task db.TestPipelineTask
+	err = option.DB.Where("id = ?", taskID).First(&task).Error
+	if err != nil {
+		return
+	}
+
+	return task.AppName, task.Env, nil
+}
+
+// PipelineZone 获取流水线所在机房，用于机房访问限制校验
+func PipelineZone(pipelineID uint) (zoneCode string, err error) {
+	var pl db.TestPipeline
+	err = option.DB.Where("id = ?", pipelineID).First(&pl).Error
+	if err != nil {
+		return
+	}
+
+	return pl.ZoneCode, nil
+}
+
+// TaskZone 获取任务所在机房，用于机房访问限制校验
+func TaskZone(taskID uint) (zoneCode string, err error) {
+	var task db.TestPipelineTask
+	err = option.DB.Where("id = ?", taskID).First(&task).Error
+	if err != nil {
+		return
+	}
+
+	return task.ZoneCode, nil
+}
+
+// checkAppWritable 已归档、待删除的应用不能创建和执行流水线
+func checkAppWritable(appName string) (err error) {
+	var app db.AppInfo
+	err = option.DB.Where("app_name = ?", appName).First(&app).Error
+	if err != nil {
+		return
+	}
+	if !app.Writable() {
+		return fmt.Errorf("应用 %s 已归档或待删除，不能创建或执行流水线", appName)
+	}
+	return nil
+}
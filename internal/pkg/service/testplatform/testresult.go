@@ -0,0 +1,161 @@
+package testplatform
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+var coveragePattern = regexp.MustCompile(`coverage: ([0-9.]+)% of statements`)
+
+type (
+	// testResults 从单元测试阶段日志（go test -json 输出）中解析的测试结果
+	testResults struct {
+		failed   map[view.TestRef]bool
+		passed   map[view.TestRef]bool
+		coverage map[string]float64 // 包 -> 覆盖率
+	}
+
+	testLogEvent struct {
+		Action  string
+		Package string
+		Test    string
+		Output  string
+	}
+)
+
+func newTestResults() *testResults {
+	return &testResults{
+		failed:   make(map[view.TestRef]bool),
+		passed:   make(map[view.TestRef]bool),
+		coverage: make(map[string]float64),
+	}
+}
+
+// parse 解析一个阶段的日志，非 go test -json 的行忽略。
+// 包失败时只记录不包含测试失败的情况，如编译失败
+func (r *testResults) parse(logs string) {
+	failedTests := make(map[string]int)
+	for _, line := range strings.Split(logs, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var e testLogEvent
+		if json.Unmarshal([]byte(line), &e) != nil || e.Package == "" {
+			continue
+		}
+
+		ref := view.TestRef{Package: e.Package, Test: e.Test}
+		switch e.Action {
+		case "fail":
+			if e.Test != "" {
+				failedTests[e.Package]++
+				r.failed[ref] = true
+			} else if failedTests[e.Package] == 0 {
+				r.failed[ref] = true
+			}
+		case "pass":
+			r.passed[ref] = true
+		case "output":
+			if e.Test != "" {
+				continue
+			}
+			if m := coveragePattern.FindStringSubmatch(e.Output); m != nil {
+				if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+					r.coverage[e.Package] = v
+				}
+			}
+		}
+	}
+}
+
+// compareTests 本次新失败的测试和本次修复的测试
+func compareTests(current, base *testResults) (newFailures, fixed []view.TestRef) {
+	newFailures, fixed = make([]view.TestRef, 0), make([]view.TestRef, 0)
+	for ref := range current.failed {
+		if !base.failed[ref] {
+			newFailures = append(newFailures, ref)
+		}
+	}
+	for ref := range base.failed {
+		if current.passed[ref] {
+			fixed = append(fixed, ref)
+		}
+	}
+	sortTestRefs(newFailures)
+	sortTestRefs(fixed)
+	return
+}
+
+// compareCoverage 对比覆盖率，只返回覆盖率变化、新增和移除的包
+func compareCoverage(current, base map[string]float64) (c view.CoverageComparison) {
+	c.Coverage = averageCoverage(current)
+	c.BaseCoverage = averageCoverage(base)
+	if c.Coverage != nil && c.BaseCoverage != nil {
+		delta := *c.Coverage - *c.BaseCoverage
+		c.Delta = &delta
+	}
+
+	c.Packages = make([]view.PackageCoverage, 0)
+	for pkg, v := range current {
+		v := v
+		item := view.PackageCoverage{Package: pkg, Coverage: &v}
+		if b, ok := base[pkg]; ok {
+			if b == v {
+				continue
+			}
+			item.BaseCoverage = &b
+		}
+		c.Packages = append(c.Packages, item)
+	}
+	for pkg, b := range base {
+		b := b
+		if _, ok := current[pkg]; !ok {
+			c.Packages = append(c.Packages, view.PackageCoverage{Package: pkg, BaseCoverage: &b})
+		}
+	}
+
+	// 新增、移除的包按 100% 的变化排序
+	change := func(p view.PackageCoverage) float64 {
+		if p.Coverage == nil || p.BaseCoverage == nil {
+			return 100
+		}
+		return math.Abs(*p.Coverage - *p.BaseCoverage)
+	}
+	sort.Slice(c.Packages, func(i, j int) bool {
+		ci, cj := change(c.Packages[i]), change(c.Packages[j])
+		if ci != cj {
+			return ci > cj
+		}
+		return c.Packages[i].Package < c.Packages[j].Package
+	})
+	return
+}
+
+func averageCoverage(coverage map[string]float64) *float64 {
+	if len(coverage) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, v := range coverage {
+		sum += v
+	}
+	avg := sum / float64(len(coverage))
+	return &avg
+}
+
+func sortTestRefs(refs []view.TestRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Package != refs[j].Package {
+			return refs[i].Package < refs[j].Package
+		}
+		return refs[i].Test < refs[j].Test
+	})
+}
@@ -0,0 +1,69 @@
+package testplatform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/douyu/juno/pkg/model/view"
+)
+
+func TestCompareTests(t *testing.T) {
+	base := newTestResults()
+	base.parse(`{"Action":"pass","Package":"a","Test":"TestOK"}
+{"Action":"fail","Package":"a","Test":"TestFixed"}
+{"Action":"fail","Package":"a"}
+{"Action":"fail","Package":"b","Test":"TestStillFailing"}
+{"progress_log":true,"type":"start"}
+not json
+`)
+
+	current := newTestResults()
+	current.parse(`{"Action":"fail","Package":"a","Test":"TestOK"}
+{"Action":"pass","Package":"a","Test":"TestFixed"}
+{"Action":"fail","Package":"a"}
+{"Action":"fail","Package":"b","Test":"TestStillFailing"}
+{"Action":"fail","Package":"c"}
+`)
+
+	newFailures, fixed := compareTests(current, base)
+	wantNew := []view.TestRef{{Package: "a", Test: "TestOK"}, {Package: "c"}}
+	if !reflect.DeepEqual(newFailures, wantNew) {
+		t.Errorf("unexpected new failures %+v", newFailures)
+	}
+	wantFixed := []view.TestRef{{Package: "a", Test: "TestFixed"}}
+	if !reflect.DeepEqual(fixed, wantFixed) {
+		t.Errorf("unexpected fixed tests %+v", fixed)
+	}
+}
+
+func TestCompareCoverage(t *testing.T) {
+	base := newTestResults()
+	base.parse(`{"Action":"output","Package":"a","Output":"coverage: 50.0% of statements\n"}
+{"Action":"output","Package":"b","Output":"ok  \tb\t0.1s\tcoverage: 80.0% of statements\n"}
+{"Action":"output","Package":"c","Output":"coverage: 10.0% of statements\n"}
+{"Action":"output","Package":"a","Test":"TestX","Output":"coverage: 99.0% of statements\n"}
+`)
+	current := newTestResults()
+	current.parse(`{"Action":"output","Package":"a","Output":"coverage: 60.0% of statements\n"}
+{"Action":"output","Package":"b","Output":"coverage: 80.0% of statements\n"}
+`)
+
+	c := compareCoverage(current.coverage, base.coverage)
+	if c.Coverage == nil || *c.Coverage != 70 || *c.BaseCoverage != (50.0+80+10)/3 {
+		t.Fatalf("unexpected coverage %+v", c)
+	}
+
+	var packages []string
+	for _, p := range c.Packages {
+		packages = append(packages, p.Package)
+	}
+	// c 被移除，a 提升 10%，b 没有变化
+	if !reflect.DeepEqual(packages, []string{"c", "a"}) {
+		t.Errorf("unexpected packages %v", packages)
+	}
+
+	empty := compareCoverage(nil, base.coverage)
+	if empty.Coverage != nil || empty.Delta != nil {
+		t.Errorf("unexpected coverage %+v", empty)
+	}
+}
@@ -0,0 +1,85 @@
+package testplatform
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/pkg/errors"
+)
+
+// onTaskVulnerability 保存漏洞扫描结果，同一阶段重复上报时覆盖之前的结果
+func onTaskVulnerability(params view.TestTaskEvent) (err error) {
+	var eventData view.TestTaskVulnerabilityPayload
+	err = json.Unmarshal(params.Data, &eventData)
+	if err != nil {
+		return errors.Wrapf(err, "invalid event data")
+	}
+
+	var task db.TestPipelineTask
+	err = option.DB.Select("id, app_name").Where("id = ?", params.TaskID).First(&task).Error
+	if err != nil {
+		return
+	}
+
+	tx := option.DB.Begin()
+	err = tx.Unscoped().Where("task_id = ? and step_name = ?", task.ID, eventData.StepName).
+		Delete(&db.TestVulnerability{}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	for _, item := range eventData.Vulnerabilities {
+		err = tx.Create(&db.TestVulnerability{
+			TaskID:       task.ID,
+			StepName:     eventData.StepName,
+			AppName:      task.AppName,
+			VulnID:       item.VulnID,
+			Aliases:      strings.Join(item.Aliases, ","),
+			Module:       item.Module,
+			Version:      item.Version,
+			FixedVersion: item.FixedVersion,
+			Severity:     item.Severity,
+			Called:       item.Called,
+			Summary:      item.Summary,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+	return tx.Commit().Error
+}
+
+// TaskVulnerabilities 任务扫描发现的漏洞，实际调用的漏洞排在前面
+func TaskVulnerabilities(taskID uint) (list []view.Vulnerability, err error) {
+	var items []db.TestVulnerability
+	err = option.DB.Where("task_id = ?", taskID).Order("called desc, id").Find(&items).Error
+	if err != nil {
+		return
+	}
+
+	list = make([]view.Vulnerability, 0, len(items))
+	for _, item := range items {
+		var aliases []string
+		if item.Aliases != "" {
+			aliases = strings.Split(item.Aliases, ",")
+		}
+		list = append(list, view.Vulnerability{
+			ID:           item.ID,
+			TaskID:       item.TaskID,
+			StepName:     item.StepName,
+			VulnID:       item.VulnID,
+			Aliases:      aliases,
+			Module:       item.Module,
+			Version:      item.Version,
+			FixedVersion: item.FixedVersion,
+			Severity:     item.Severity,
+			Called:       item.Called,
+			Summary:      item.Summary,
+		})
+	}
+	return
+}
@@ -28,7 +28,10 @@ var (
 	instance *WorkerPool
 	initOnce sync.Once
 
-	ErrNodesEmpty = errors.New("worker nodes empty in current env")
+	ErrNodesEmpty   = errors.New("worker nodes empty in current env")
+	ErrNodesDrained = errors.New("all worker nodes in current zone are drained")
+	// ErrNodesUnmatched 当前机房没有具有流水线要求标签的节点
+	ErrNodesUnmatched = errors.New("no worker node in current zone has the required labels")
 )
 
 func Instance() *WorkerPool {
@@ -121,6 +124,15 @@ func (w *WorkerPool) Heartbeat(params view.WorkerHeartbeat) {
 		node.ZoneName = params.ZoneName
 		node.IP = params.IP
 		node.Port = params.Port
+		node.Version = params.Version
+		node.Capacity = params.Capacity
+		node.Running = len(params.Tasks)
+		node.QueueDepth = params.QueueDepth
+		node.Tasks = params.Tasks
+		node.Labels = params.Labels
+		if !params.StartedAt.IsZero() {
+			node.StartedAt = &params.StartedAt
+		}
 
 		err = tx.Save(&node).Error
 		if err != nil {
@@ -145,7 +157,8 @@ func (w *WorkerPool) updateNode(node db.WorkerNode) {
 	w.nodes[node.ZoneCode] = selector
 }
 
-func (w *WorkerPool) Select(zoneCode string) (node db.WorkerNode, err error) {
+// Select 在机房中选择具有全部 labels 的节点，labels 为空时不限制
+func (w *WorkerPool) Select(zoneCode string, labels map[string]string) (node db.WorkerNode, err error) {
 	w.nodesMtx.RLock()
 	defer w.nodesMtx.RUnlock()
 
@@ -155,10 +168,25 @@ func (w *WorkerPool) Select(zoneCode string) (node db.WorkerNode, err error) {
 		return
 	}
 
-	node, err = selector.pick()
+	node, err = selector.pick(labels)
 	if err != nil {
 		return
 	}
 
 	return
 }
+
+// SetDrained 设置节点的 drain 状态，drain 的节点不再被 Select 选中
+func (w *WorkerPool) SetDrained(node db.WorkerNode, drained bool) (err error) {
+	err = w.option.DB.Model(&node).Update("drained", drained).Error
+	if err != nil {
+		return
+	}
+
+	w.nodesMtx.RLock()
+	defer w.nodesMtx.RUnlock()
+	if selector := w.nodes[node.ZoneCode]; selector != nil {
+		selector.setDrained(node.HostName, drained)
+	}
+	return
+}
@@ -46,7 +46,8 @@ func (s *workerSelector) push(node db.WorkerNode) {
 	s.keys = keys
 }
 
-func (s *workerSelector) pick() (node db.WorkerNode, err error) {
+// pick 轮询选择具有全部 labels 的节点
+func (s *workerSelector) pick(labels map[string]string) (node db.WorkerNode, err error) {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
 
@@ -55,10 +56,35 @@ func (s *workerSelector) pick() (node db.WorkerNode, err error) {
 		return
 	}
 
-	s.index = (s.index + 1) % len(s.nodes)
-	node = s.nodes[s.keys[s.index]]
+	// 跳过已经 drain 和标签不匹配的节点
+	matched := false
+	for i := 0; i < len(s.keys); i++ {
+		s.index = (s.index + 1) % len(s.keys)
+		node = s.nodes[s.keys[s.index]]
+		if !node.Labels.Contains(labels) {
+			continue
+		}
+		matched = true
+		if !node.Drained {
+			return
+		}
+	}
+
+	if !matched {
+		return db.WorkerNode{}, ErrNodesUnmatched
+	}
+	return db.WorkerNode{}, ErrNodesDrained
+}
 
-	return
+// setDrained 更新节点的 drain 状态，节点不在当前机房时忽略
+func (s *workerSelector) setDrained(hostName string, drained bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if node, ok := s.nodes[hostName]; ok {
+		node.Drained = drained
+		s.nodes[hostName] = node
+	}
 }
 
 func (s *workerSelector) clearTimeoutNodes(duration time.Duration) {
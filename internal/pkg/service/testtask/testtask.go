@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/douyu/juno/pkg/metrics"
 	"github.com/douyu/juno/pkg/model/view"
 	"github.com/douyu/jupiter/pkg/xlog"
 	"github.com/jinzhu/gorm"
@@ -29,6 +30,8 @@ func Init(o Option) {
 		option: o,
 		c:      make(chan view.TestTask, 1000),
 	}
+	c := instance.c
+	metrics.RegisterQueue("testtask", func() int { return len(c) })
 }
 
 func Instance() *TestTask {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/douyu/juno/pkg/model/db"
 	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/sqldialect"
 )
 
 type tool struct{}
@@ -30,7 +31,7 @@ func (t *tool) List(currentPage int, pageSize int, queryObj string) *view.RespTo
 	offset := 0
 	offset = currentPage * pageSize
 
-	sql := invoker.JunoMysql.Table("tool").Select("id, name, url, pic_url, create_time, `desc`")
+	sql := invoker.JunoMysql.Table("tool").Select("id, name, url, pic_url, create_time, " + sqldialect.Quote(invoker.JunoMysql, "desc"))
 	if queryObj != "" {
 		sql = sql.Where("name like ?", "%"+queryObj+"%")
 	}
@@ -50,7 +51,7 @@ func (t *tool) List(currentPage int, pageSize int, queryObj string) *view.RespTo
 // Detail ...
 func (t *tool) Detail(id int) *db.ToolInfo {
 	resp := new(db.ToolInfo)
-	invoker.JunoMysql.Table("tool").Select("id, name, url, pic_url, create_time, `desc`").
+	invoker.JunoMysql.Table("tool").Select("id, name, url, pic_url, create_time, "+sqldialect.Quote(invoker.JunoMysql, "desc")).
 		Where("id = ?", id).Find(&resp)
 	return resp
 }
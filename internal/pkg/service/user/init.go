@@ -10,6 +10,6 @@ var (
 func Init(db *gorm.DB) {
 	initGob()
 	User = InitUser(db)
-	Session = InitUserSession()
+	Session = InitUserSession(db)
 	return
 }
@@ -0,0 +1,74 @@
+package user
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/douyu/juno/pkg/cache"
+	"github.com/douyu/juno/pkg/i18n"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/store/gorm"
+)
+
+// ErrUnsupportedLanguage 不支持的语言
+var ErrUnsupportedLanguage = errors.New("不支持的语言")
+
+// languageCache 用户语言设置，每个请求都会读取
+var languageCache = cache.New("user_language", 0)
+
+// Language 用户设置的语言，未设置时返回空字符串
+func (u *user) Language(uid int) (lang string, err error) {
+	err = languageCache.Fetch(strconv.Itoa(uid), &lang, func() error {
+		var item db.UserLanguage
+		err := u.DB.Where("uid = ?", uid).First(&item).Error
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			return err
+		}
+		lang = item.Language
+		return nil
+	})
+	return
+}
+
+// SetLanguage 设置用户语言，为空时恢复按浏览器语言
+func (u *user) SetLanguage(uid int, param view.ReqSetLanguage) (err error) {
+	defer languageCache.Invalidate(strconv.Itoa(uid))
+
+	if param.Language == "" {
+		return u.DB.Unscoped().Where("uid = ?", uid).Delete(&db.UserLanguage{}).Error
+	}
+	lang, ok := i18n.Normalize(param.Language)
+	if !ok {
+		return ErrUnsupportedLanguage
+	}
+
+	var item db.UserLanguage
+	err = u.DB.Where("uid = ?", uid).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	if item.ID == 0 {
+		return u.DB.Create(&db.UserLanguage{Uid: uid, Language: lang}).Error
+	}
+	return u.DB.Model(&item).UpdateColumn("language", lang).Error
+}
+
+// Languages 用户 uid 对应的语言，未设置的用户不返回
+func (u *user) Languages(uids []int) (langs map[int]string, err error) {
+	langs = make(map[int]string)
+	if len(uids) == 0 {
+		return
+	}
+
+	var items []db.UserLanguage
+	err = u.DB.Where("uid in (?)", uids).Find(&items).Error
+	if err != nil {
+		return
+	}
+	for _, item := range items {
+		langs[item.Uid] = item.Language
+	}
+	return
+}
@@ -0,0 +1,42 @@
+package user
+
+import (
+	"github.com/douyu/juno/pkg/auth/ldap"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+const oauthLDAP = "ldap"
+
+// LoginLDAP 使用 LDAP 校验账号密码，成功后创建或更新本地用户，返回用户及映射的 Juno 用户组
+func (u *user) LoginLDAP(username, password string) (info db.User, userGroup string, err error) {
+	ldapUser, err := ldap.Authenticate(cfg.Cfg.Auth.LDAP, username, password)
+	if err != nil {
+		return
+	}
+
+	mapping, err := ldap.MapGroup(cfg.Cfg.Auth.LDAP.GroupMappings, ldapUser.Groups)
+	if err != nil {
+		return
+	}
+
+	access := "user"
+	if mapping.Admin {
+		access = "admin"
+	}
+
+	info = db.User{
+		Username: ldapUser.Username,
+		Nickname: ldapUser.Nickname,
+		Email:    ldapUser.Email,
+		Oauth:    oauthLDAP,
+		OauthId:  ldapUser.DN,
+		Access:   access,
+	}
+	err = u.CreateOrUpdateOauthUser(&info)
+	if err != nil {
+		return
+	}
+
+	return info, mapping.UserGroup, nil
+}
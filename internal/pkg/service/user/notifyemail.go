@@ -0,0 +1,103 @@
+package user
+
+import (
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/store/gorm"
+)
+
+// NotifyEmail 用户接收通知的邮箱设置
+func (u *user) NotifyEmail(uid int) (resp view.RespNotifyEmail, err error) {
+	var info db.User
+	err = u.DB.Where("uid = ?", uid).First(&info).Error
+	if err != nil {
+		return
+	}
+
+	var item db.UserNotifyEmail
+	err = u.DB.Where("uid = ?", uid).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	resp = view.RespNotifyEmail{
+		Email:        item.Email,
+		AccountEmail: info.Email,
+	}
+	return resp, nil
+}
+
+// SetNotifyEmail 设置接收通知的邮箱，email 为空时恢复使用账号邮箱
+func (u *user) SetNotifyEmail(uid int, email string) (err error) {
+	if email == "" {
+		return u.DB.Unscoped().Where("uid = ?", uid).Delete(&db.UserNotifyEmail{}).Error
+	}
+
+	var item db.UserNotifyEmail
+	err = u.DB.Where("uid = ?", uid).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	if item.ID == 0 {
+		return u.DB.Create(&db.UserNotifyEmail{Uid: uid, Email: email}).Error
+	}
+	return u.DB.Model(&item).UpdateColumn("email", email).Error
+}
+
+// NotifyEmails 用户接收通知的邮箱列表，已停用及未设置邮箱的用户会被忽略
+func (u *user) NotifyEmails(uids []int) (emails []string, err error) {
+	byUid, err := u.notifyEmailByUid(uids)
+	if err != nil {
+		return
+	}
+
+	seen := make(map[string]struct{}, len(byUid))
+	for _, uid := range uids {
+		email, ok := byUid[uid]
+		if !ok {
+			continue
+		}
+		if _, ok = seen[email]; ok {
+			continue
+		}
+		seen[email] = struct{}{}
+		emails = append(emails, email)
+	}
+	return
+}
+
+// notifyEmailByUid 用户 uid 对应的通知邮箱，已停用及未设置邮箱的用户会被忽略
+func (u *user) notifyEmailByUid(uids []int) (emails map[int]string, err error) {
+	emails = make(map[int]string)
+	if len(uids) == 0 {
+		return
+	}
+
+	var users []db.User
+	err = u.DB.Where("uid in (?) and state != ?", uids, db.UserStateDisabled).Find(&users).Error
+	if err != nil {
+		return
+	}
+
+	var items []db.UserNotifyEmail
+	err = u.DB.Where("uid in (?)", uids).Find(&items).Error
+	if err != nil {
+		return
+	}
+	overrides := make(map[int]string, len(items))
+	for _, item := range items {
+		overrides[item.Uid] = item.Email
+	}
+
+	for _, info := range users {
+		email := info.Email
+		if override, ok := overrides[info.Uid]; ok {
+			email = override
+		}
+		if email != "" {
+			emails[info.Uid] = email
+		}
+	}
+	return
+}
@@ -0,0 +1,271 @@
+package user
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/juno/pkg/notice"
+	"github.com/douyu/jupiter/pkg/store/gorm"
+)
+
+// unsubscribePath 邮件退订链接地址，不需要登录
+const unsubscribePath = "/api/admin/public/user/notify/unsubscribe"
+
+// ErrUnsubscribeToken 退订链接无效
+var ErrUnsubscribeToken = errors.New("退订链接无效或已过期")
+
+// NotifyPref 用户通知偏好
+func (u *user) NotifyPref(uid int) (resp view.NotifyPref, err error) {
+	var item db.UserNotifyPref
+	err = u.DB.Where("uid = ?", uid).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	pref := transformNotifyPref(item)
+	resp = view.NotifyPref{
+		MutedEvents: pref.MutedEvents,
+		MinSeverity: pref.MinSeverity,
+		Channels:    pref.Channels,
+	}
+	return resp, nil
+}
+
+// SetNotifyPref 设置用户通知偏好，只影响团队默认发送给成员的通知，团队设置了强制通知级别时达到该级别的通知仍会发送
+func (u *user) SetNotifyPref(uid int, param view.NotifyPref) (err error) {
+	for _, channel := range param.Channels {
+		if !containsString(notice.Channels, channel) {
+			return fmt.Errorf("不支持的通知渠道 %s", channel)
+		}
+	}
+
+	item, err := u.notifyPref(uid)
+	if err != nil {
+		return
+	}
+
+	return u.DB.Model(&item).Updates(map[string]interface{}{
+		"muted_events": joinPrefList(param.MutedEvents),
+		"min_severity": param.MinSeverity,
+		"channels":     joinPrefList(param.Channels),
+	}).Error
+}
+
+// Unsubscribe 通过邮件退订链接退订，eventType 为空时退订全部邮件通知，否则不再接收该类事件
+func (u *user) Unsubscribe(token, eventType string) (username string, err error) {
+	if token == "" {
+		return "", ErrUnsubscribeToken
+	}
+
+	var item db.UserNotifyPref
+	err = u.DB.Where("token = ?", token).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrUnsubscribeToken
+		}
+		return
+	}
+
+	var info db.User
+	err = u.DB.Where("uid = ?", item.Uid).First(&info).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrUnsubscribeToken
+		}
+		return
+	}
+
+	pref := transformNotifyPref(item)
+	if eventType != "" {
+		pref.MutedEvents = appendPrefList(pref.MutedEvents, eventType)
+	} else {
+		if len(pref.Channels) == 0 {
+			pref.Channels = notice.Channels
+		}
+		channels := make([]string, 0, len(pref.Channels))
+		for _, channel := range pref.Channels {
+			if channel != notice.ChannelEmail {
+				channels = append(channels, channel)
+			}
+		}
+		pref.Channels = channels
+	}
+
+	err = u.DB.Model(&item).Updates(map[string]interface{}{
+		"muted_events": joinPrefList(pref.MutedEvents),
+		"channels":     joinPrefList(pref.Channels),
+	}).Error
+	return info.Username, err
+}
+
+// ApplyNotifyPrefs 按用户通知偏好将 uids 的通知邮箱加入邮件接收人、usernames 加入 @ 列表，并为邮件接收人生成退订链接、记录语言设置。
+// mandatory 为 true 时忽略个人偏好
+func (u *user) ApplyNotifyPrefs(e *notice.Event, uids []int, usernames []string, mandatory bool) (err error) {
+	// 追加不存在的 uid、用户名，避免 in 条件为空
+	var users []db.User
+	err = u.DB.Select("uid, username").
+		Where("(uid in (?) or username in (?)) and state != ?", append([]int{0}, uids...), append([]string{""}, usernames...), db.UserStateDisabled).
+		Find(&users).Error
+	if err != nil {
+		return
+	}
+
+	allUids := make([]int, 0, len(users))
+	uidByName := make(map[string]int, len(users))
+	for _, info := range users {
+		allUids = append(allUids, info.Uid)
+		uidByName[info.Username] = info.Uid
+	}
+
+	var items []db.UserNotifyPref
+	if len(allUids) > 0 {
+		err = u.DB.Where("uid in (?)", allUids).Find(&items).Error
+		if err != nil {
+			return
+		}
+	}
+	prefs := make(map[int]db.UserNotifyPref, len(items))
+	for _, item := range items {
+		prefs[item.Uid] = item
+	}
+
+	for _, name := range usernames {
+		uid, ok := uidByName[name]
+		// 不是 Juno 用户的负责人没有个人偏好，保持原样
+		if ok && !mandatory && !transformNotifyPref(prefs[uid]).AcceptMention(e.Type, e.Severity) {
+			continue
+		}
+		if !containsString(e.Mentions, name) {
+			e.Mentions = append(e.Mentions, name)
+		}
+	}
+
+	accepted := make([]int, 0, len(uids))
+	for _, uid := range uids {
+		if mandatory || transformNotifyPref(prefs[uid]).Accept(e.Type, e.Severity, notice.ChannelEmail) {
+			accepted = append(accepted, uid)
+		}
+	}
+	emails, err := u.notifyEmailByUid(accepted)
+	if err != nil {
+		return
+	}
+	langs, err := u.Languages(accepted)
+	if err != nil {
+		return
+	}
+
+	for _, uid := range accepted {
+		email, ok := emails[uid]
+		if !ok || containsString(e.Emails, email) {
+			continue
+		}
+		e.Emails = append(e.Emails, email)
+
+		if lang, ok := langs[uid]; ok {
+			if e.Languages == nil {
+				e.Languages = make(map[string]string)
+			}
+			e.Languages[email] = lang
+		}
+
+		if cfg.Cfg.AppURL == "" {
+			continue
+		}
+		item, ok := prefs[uid]
+		if !ok {
+			item, err = u.notifyPref(uid)
+			if err != nil {
+				return
+			}
+		}
+		if e.Unsubscribes == nil {
+			e.Unsubscribes = make(map[string]string)
+		}
+		e.Unsubscribes[email] = unsubscribeURL(item.Token, e.Type)
+	}
+	return
+}
+
+// notifyPref 用户通知偏好记录，不存在时创建并生成退订凭证
+func (u *user) notifyPref(uid int) (item db.UserNotifyPref, err error) {
+	err = u.DB.Where("uid = ?", uid).First(&item).Error
+	if err == nil || !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+
+	token, err := generateUnsubscribeToken()
+	if err != nil {
+		return
+	}
+	item = db.UserNotifyPref{Uid: uid, Token: token}
+	err = u.DB.Create(&item).Error
+	return
+}
+
+func transformNotifyPref(item db.UserNotifyPref) notice.Preference {
+	return notice.Preference{
+		MutedEvents: splitPrefList(item.MutedEvents),
+		MinSeverity: item.MinSeverity,
+		Channels:    splitPrefList(item.Channels),
+	}
+}
+
+func unsubscribeURL(token, eventType string) string {
+	query := url.Values{}
+	query.Set("token", token)
+	if eventType != "" {
+		query.Set("event", eventType)
+	}
+	return strings.TrimSuffix(cfg.Cfg.AppURL, "/") + unsubscribePath + "?" + query.Encode()
+}
+
+func generateUnsubscribeToken() (string, error) {
+	buf := make([]byte, 20)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func splitPrefList(s string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func joinPrefList(list []string) string {
+	return strings.Join(appendPrefList(nil, list...), ",")
+}
+
+func appendPrefList(list []string, items ...string) []string {
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item != "" && !containsString(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
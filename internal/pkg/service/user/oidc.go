@@ -0,0 +1,36 @@
+package user
+
+import (
+	"github.com/douyu/juno/pkg/auth/oidc"
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+const oauthOIDC = "oidc"
+
+// LoginOIDC 根据 ID Token 中的用户信息创建或更新本地用户，返回用户及映射的 Juno 用户组
+func (u *user) LoginOIDC(userInfo *oidc.UserInfo) (info db.User, userGroup string, err error) {
+	mapping, err := oidc.Provider.MapGroup(userInfo.Groups)
+	if err != nil {
+		return
+	}
+
+	access := "user"
+	if mapping.Admin {
+		access = "admin"
+	}
+
+	info = db.User{
+		Username: userInfo.Username,
+		Nickname: userInfo.Nickname,
+		Email:    userInfo.Email,
+		Oauth:    oauthOIDC,
+		OauthId:  userInfo.Subject,
+		Access:   access,
+	}
+	err = u.CreateOrUpdateOauthUser(&info)
+	if err != nil {
+		return
+	}
+
+	return info, mapping.UserGroup, nil
+}
@@ -0,0 +1,186 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/douyu/juno/pkg/auth/password"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/jupiter/pkg/store/gorm"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrOldPasswordWrong = errors.New("原密码错误")
+	ErrPasswordExpired  = errors.New("密码已过期，请修改密码")
+	ErrPasswordReused   = errors.New("新密码不能与最近使用过的密码相同")
+)
+
+// LoginLockedError 账号因连续登录失败被锁定
+type LoginLockedError struct {
+	Until time.Time
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("登录失败次数过多，账号已锁定，请在 %s 后重试", e.Until.Format("2006-01-02 15:04:05"))
+}
+
+// ValidatePassword 校验密码是否满足配置的复杂度要求
+func ValidatePassword(pwd string) error {
+	conf := cfg.Cfg.Auth.PasswordPolicy
+	return password.Policy{
+		MinLength:     conf.MinLength,
+		RequireUpper:  conf.RequireUpper,
+		RequireLower:  conf.RequireLower,
+		RequireDigit:  conf.RequireDigit,
+		RequireSymbol: conf.RequireSymbol,
+	}.Validate(pwd)
+}
+
+// CheckLoginLock 账号处于锁定期内时返回 *LoginLockedError
+func (u *user) CheckLoginLock(username string) error {
+	var item db.UserLoginLock
+	err := u.DB.Where("username = ?", username).First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+
+	if item.LockedUntil != nil && item.LockedUntil.After(time.Now()) {
+		return &LoginLockedError{Until: *item.LockedUntil}
+	}
+	return nil
+}
+
+// RecordLoginFailure 记录一次登录失败，失败次数达到阈值后锁定账号，锁定时长随失败次数递增
+func (u *user) RecordLoginFailure(username, ip string) (err error) {
+	conf := cfg.Cfg.Auth.PasswordPolicy
+	if conf.LockoutThreshold <= 0 || username == "" {
+		return nil
+	}
+
+	// 条件更新，并发失败时重新读取后重试，保证失败次数不丢失
+	for i := 0; i < 3; i++ {
+		var item db.UserLoginLock
+		err = u.DB.Where("username = ?", username).First(&item).Error
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			return
+		}
+
+		now := time.Now()
+		failures := item.Failures + 1
+		update := map[string]interface{}{
+			"failures":       failures,
+			"last_failed_at": now,
+			"last_failed_ip": ip,
+		}
+		if d := password.LockDuration(failures, conf.LockoutThreshold, conf.LockoutDuration, conf.LockoutMaxDuration); d > 0 {
+			update["locked_until"] = now.Add(d)
+		}
+
+		if item.ID == 0 {
+			item = db.UserLoginLock{Username: username, Failures: failures, LastFailedAt: &now, LastFailedIP: ip}
+			if until, ok := update["locked_until"].(time.Time); ok {
+				item.LockedUntil = &until
+			}
+			err = u.DB.Create(&item).Error
+			if err == nil {
+				return
+			}
+			// 唯一索引冲突说明其他请求已创建记录，重试更新
+			continue
+		}
+
+		query := u.DB.Model(&db.UserLoginLock{}).Where("id = ? and failures = ?", item.ID, item.Failures).UpdateColumns(update)
+		if query.Error != nil {
+			return query.Error
+		}
+		if query.RowsAffected > 0 {
+			return nil
+		}
+	}
+	return
+}
+
+// ClearLoginFailures 登录成功或管理员解锁后清除失败记录
+func (u *user) ClearLoginFailures(username string) error {
+	return u.DB.Unscoped().Where("username = ?", username).Delete(&db.UserLoginLock{}).Error
+}
+
+// LoginLockList 登录失败记录列表
+func (u *user) LoginLockList() (list []db.UserLoginLock, err error) {
+	err = u.DB.Order("locked_until desc, id desc").Find(&list).Error
+	return
+}
+
+// PasswordExpired 本地账号密码是否已过期。
+// 没有修改记录的账号（策略启用前创建）以首次检查的时间作为起点
+func (u *user) PasswordExpired(info *db.User) (bool, error) {
+	days := cfg.Cfg.Auth.PasswordPolicy.ExpireDays
+	if days <= 0 || info.Password == "" {
+		return false, nil
+	}
+
+	var item db.UserPasswordHistory
+	err := u.DB.Where("uid = ?", info.Uid).Order("id desc").First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return false, u.recordPassword(u.DB, info.Uid, info.Password)
+		}
+		return false, err
+	}
+
+	return time.Since(item.CreatedAt) > time.Duration(days)*24*time.Hour, nil
+}
+
+// checkPasswordReuse 新密码不能与当前密码及最近 HistoryCount 次使用过的密码相同
+func (u *user) checkPasswordReuse(info *db.User, newPassword string) error {
+	count := cfg.Cfg.Auth.PasswordPolicy.HistoryCount
+	if count <= 0 {
+		return nil
+	}
+
+	hashes := []string{info.Password}
+	var list []db.UserPasswordHistory
+	err := u.DB.Where("uid = ?", info.Uid).Order("id desc").Limit(count).Find(&list).Error
+	if err != nil {
+		return err
+	}
+	for _, item := range list {
+		hashes = append(hashes, item.Password)
+	}
+
+	for _, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(newPassword)) == nil {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// recordPassword 记录密码修改，只保留校验历史密码所需的条数
+func (u *user) recordPassword(tx *gorm.DB, uid int, hash string) (err error) {
+	err = tx.Create(&db.UserPasswordHistory{Uid: uid, Password: hash}).Error
+	if err != nil {
+		return
+	}
+
+	keep := cfg.Cfg.Auth.PasswordPolicy.HistoryCount
+	if keep < 1 {
+		keep = 1
+	}
+
+	var ids []uint
+	err = tx.Model(&db.UserPasswordHistory{}).Where("uid = ?", uid).Order("id desc").Pluck("id", &ids).Error
+	if err != nil || len(ids) <= keep {
+		return
+	}
+	return tx.Unscoped().Where("id in (?)", ids[keep:]).Delete(&db.UserPasswordHistory{}).Error
+}
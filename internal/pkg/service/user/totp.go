@@ -0,0 +1,242 @@
+package user
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/auth/totp"
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
+	"github.com/douyu/jupiter/pkg/store/gorm"
+)
+
+const backupCodeCount = 10
+
+var (
+	ErrTOTPNotEnabled     = errors.New("未开启两步验证")
+	ErrTOTPAlreadyEnabled = errors.New("已开启两步验证")
+	ErrTOTPInvalidCode    = errors.New("验证码错误")
+	ErrTOTPRequired       = errors.New("当前角色必须开启两步验证")
+)
+
+// TwoFactorRequired 用户的角色是否要求开启两步验证
+func TwoFactorRequired(u *db.User) bool {
+	for _, access := range cfg.Cfg.Auth.TwoFactor.RequiredAccess {
+		if u.Access == access {
+			return true
+		}
+	}
+	return false
+}
+
+// TOTPEnabled 用户是否已开启两步验证
+func (u *user) TOTPEnabled(uid int) (bool, error) {
+	item, err := u.findTOTP(uid)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return item.Enabled, nil
+}
+
+// TOTPStatus 两步验证状态
+func (u *user) TOTPStatus(usr *db.User) (resp view.TOTPStatus, err error) {
+	resp.Required = TwoFactorRequired(usr)
+
+	item, err := u.findTOTP(usr.Uid)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return resp, nil
+		}
+		return
+	}
+
+	resp.Enabled = item.Enabled
+	resp.BackupCodesCount = len(splitBackupCodes(item.BackupCodes))
+	return
+}
+
+// EnrollTOTP 生成新的密钥，需要调用 ActivateTOTP 校验验证码后才会生效
+func (u *user) EnrollTOTP(usr *db.User) (resp view.RespTOTPEnroll, err error) {
+	item, err := u.findTOTP(usr.Uid)
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return
+	}
+	if item.Enabled {
+		err = ErrTOTPAlreadyEnabled
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return
+	}
+
+	if item.ID == 0 {
+		err = u.DB.Create(&db.UserTOTP{Uid: usr.Uid, Secret: secret}).Error
+	} else {
+		err = u.DB.Model(&item).UpdateColumns(map[string]interface{}{
+			"secret":    secret,
+			"last_step": 0,
+		}).Error
+	}
+	if err != nil {
+		return
+	}
+
+	resp.Secret = secret
+	resp.URI = totp.URI(cfg.Cfg.Auth.TwoFactor.Issuer, usr.Username, secret)
+	return
+}
+
+// ActivateTOTP 校验验证码并开启两步验证，返回备用码，备用码只返回一次
+func (u *user) ActivateTOTP(uid int, code string) (backupCodes []string, err error) {
+	item, err := u.findTOTP(uid)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrTOTPNotEnabled
+		}
+		return
+	}
+	if item.Enabled {
+		err = ErrTOTPAlreadyEnabled
+		return
+	}
+
+	step, ok := totp.Validate(item.Secret, code, time.Now(), item.LastStep)
+	if !ok {
+		err = ErrTOTPInvalidCode
+		return
+	}
+
+	backupCodes, hashes, err := generateBackupCodes()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	err = u.DB.Model(&item).UpdateColumns(map[string]interface{}{
+		"enabled":      true,
+		"enabled_at":   &now,
+		"last_step":    step,
+		"backup_codes": hashes,
+	}).Error
+	return
+}
+
+// VerifyTOTP 校验验证码或备用码，备用码使用后失效
+func (u *user) VerifyTOTP(uid int, code string) (err error) {
+	item, err := u.findTOTP(uid)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrTOTPNotEnabled
+		}
+		return
+	}
+	if !item.Enabled {
+		return ErrTOTPNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(item.Secret, code, time.Now(), item.LastStep)
+		if !ok {
+			return ErrTOTPInvalidCode
+		}
+
+		// 条件更新，避免并发请求重复使用同一个验证码
+		query := u.DB.Model(&db.UserTOTP{}).Where("id = ? and last_step < ?", item.ID, step).UpdateColumn("last_step", step)
+		if query.Error != nil {
+			return query.Error
+		}
+		if query.RowsAffected == 0 {
+			return ErrTOTPInvalidCode
+		}
+		return nil
+	}
+
+	hashes := splitBackupCodes(item.BackupCodes)
+	hash := totp.HashBackupCode(code)
+	for i, h := range hashes {
+		if h != hash {
+			continue
+		}
+
+		remain := append(hashes[:i:i], hashes[i+1:]...)
+		query := u.DB.Model(&db.UserTOTP{}).Where("uid = ? and backup_codes = ?", uid, strings.Join(hashes, ",")).
+			UpdateColumn("backup_codes", strings.Join(remain, ","))
+		if query.Error != nil {
+			return query.Error
+		}
+		if query.RowsAffected == 0 {
+			return ErrTOTPInvalidCode
+		}
+		return nil
+	}
+
+	return ErrTOTPInvalidCode
+}
+
+// RegenerateBackupCodes 校验验证码后重新生成备用码
+func (u *user) RegenerateBackupCodes(uid int, code string) (backupCodes []string, err error) {
+	err = u.VerifyTOTP(uid, code)
+	if err != nil {
+		return
+	}
+
+	backupCodes, hashes, err := generateBackupCodes()
+	if err != nil {
+		return
+	}
+
+	err = u.DB.Model(&db.UserTOTP{}).Where("uid = ?", uid).UpdateColumn("backup_codes", hashes).Error
+	return
+}
+
+// DisableTOTP 校验验证码后关闭两步验证
+func (u *user) DisableTOTP(usr *db.User, code string) (err error) {
+	if TwoFactorRequired(usr) {
+		return ErrTOTPRequired
+	}
+
+	err = u.VerifyTOTP(usr.Uid, code)
+	if err != nil {
+		return
+	}
+
+	return u.ResetTOTP(usr.Uid)
+}
+
+// ResetTOTP 管理员重置用户的两步验证，如用户丢失设备
+func (u *user) ResetTOTP(uid int) error {
+	return u.DB.Unscoped().Where("uid = ?", uid).Delete(&db.UserTOTP{}).Error
+}
+
+func (u *user) findTOTP(uid int) (item db.UserTOTP, err error) {
+	err = u.DB.Where("uid = ?", uid).First(&item).Error
+	return
+}
+
+func generateBackupCodes() (codes []string, hashes string, err error) {
+	codes, err = totp.GenerateBackupCodes(backupCodeCount)
+	if err != nil {
+		return
+	}
+
+	list := make([]string, 0, len(codes))
+	for _, code := range codes {
+		list = append(list, totp.HashBackupCode(code))
+	}
+	return codes, strings.Join(list, ","), nil
+}
+
+func splitBackupCodes(codes string) []string {
+	if codes == "" {
+		return nil
+	}
+	return strings.Split(codes, ",")
+}
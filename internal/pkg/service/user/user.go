@@ -2,11 +2,15 @@ package user
 
 import (
 	"errors"
+	"strconv"
 	"time"
 
+	"github.com/douyu/juno/internal/pkg/packages/listquery"
 	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
 	"github.com/douyu/jupiter/pkg/store/gorm"
 	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // User 指定Menu结构体对应的表名
@@ -22,8 +26,25 @@ func InitUser(invokerDB *gorm.DB) *user {
 	return u
 }
 
+// OauthSCIM 通过 SCIM 同步创建的用户的来源
+const OauthSCIM = "scim"
+
+var (
+	// ErrUserDisabled 用户已停用
+	ErrUserDisabled = errors.New("账号已停用，请联系管理员")
+	// ErrUsernameConflict 第三方登录的用户名已被本地账号或其他来源的账号使用
+	ErrUsernameConflict = errors.New("用户名已被其他账号使用，请联系管理员")
+)
+
+// ContextTokenUser 通过 API Token 认证的用户在 echo.Context 中的 key
+const ContextTokenUser = "token_user"
+
 // GetUser ...
 func GetUser(c echo.Context) *db.User {
+	if user, ok := c.Get(ContextTokenUser).(*db.User); ok {
+		return user
+	}
+
 	user := Session.Read(c)
 	// return default user
 	if user == nil {
@@ -33,33 +54,58 @@ func GetUser(c echo.Context) *db.User {
 }
 
 func IsAdmin(c echo.Context) bool {
-	user := Session.Read(c)
-	if user == nil {
-		return false
-	}
+	user := GetUser(c)
 	if user.Access == "admin" {
 		return true
 	}
 	return false
 }
 
-func (u *user) GetList(where db.User, currentPage, pageSize int, sort string) (out []db.UserInfo, total int, err error) {
-	if pageSize == 0 {
-		pageSize = 20
+// listSpec 用户列表允许排序、筛选的字段
+var listSpec = listquery.Spec{
+	Sorts: map[string]string{
+		"uid":         "uid",
+		"username":    "username",
+		"nickname":    "nickname",
+		"create_time": "create_time",
+		"update_time": "update_time",
+	},
+	Filters: map[string]string{
+		"username": "username",
+		"nickname": "nickname",
+		"email":    "email",
+		"oauth":    "oauth",
+		"access":   "access",
+	},
+	DefaultSort:     "-update_time",
+	Tiebreaker:      "uid",
+	DefaultPageSize: 20,
+}
+
+// GetList 用户列表，允许的排序、筛选字段见 listSpec
+func (u *user) GetList(where db.User, list view.ListQuery) (out []db.UserInfo, page *view.Pagination, err error) {
+	query, err := listSpec.Parse(list)
+	if err != nil {
+		return
 	}
-	if currentPage == 0 {
-		currentPage = 1
+
+	var (
+		resp  []db.User
+		total int
+	)
+	sql := query.Where(u.DB.Model(db.User{}).Where(where))
+	err = sql.Count(&total).Error
+	if err != nil {
+		return
 	}
-	var resp []db.User
-	sql := u.DB.Model(db.User{}).Where(where)
-	sql.Count(&total)
-	err = sql.Order(sort).Offset((currentPage - 1) * pageSize).Limit(pageSize).Find(&resp).Error
+	err = query.Paginate(sql).Find(&resp).Error
 	if err != nil {
 		return
 	}
 	for _, user := range resp {
 		out = append(out, user.TransformUserInfo())
 	}
+	page = query.Pagination(total, len(resp))
 	return
 }
 
@@ -92,10 +138,24 @@ func (u *user) CreateOrUpdateOauthUser(info *db.User) (err error) {
 	}
 	// not found
 	if gorm.IsRecordNotFoundError(err) {
-		err = u.Create(info)
-		if err != nil {
+		// SCIM 预先创建的用户，首次单点登录时绑定登录来源
+		err = u.DB.Where("username = ? and oauth = ?", info.Username, OauthSCIM).First(&user).Error
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
 			return
 		}
+		if gorm.IsRecordNotFoundError(err) {
+			err = u.checkUsernameConflict(0, info.Username)
+			if err != nil {
+				return
+			}
+			return u.Create(info)
+		}
+	}
+
+	// 第三方账号改名时，不能改为其他账号已使用的用户名
+	err = u.checkUsernameConflict(user.Uid, info.Username)
+	if err != nil {
+		return
 	}
 
 	err = u.Update(user.Uid, info)
@@ -107,6 +167,19 @@ func (u *user) CreateOrUpdateOauthUser(info *db.User) (err error) {
 	return
 }
 
+// checkUsernameConflict 第三方登录不能接管同名的本地账号或其他来源的账号，uid 为当前账号，新建时为 0
+func (u *user) checkUsernameConflict(uid int, username string) error {
+	var count int
+	err := u.DB.Model(&db.User{}).Where("username = ? and uid <> ?", username, uid).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUsernameConflict
+	}
+	return nil
+}
+
 // 设置APP信息
 func (u *user) Create(item *db.User) (err error) {
 	err = u.DB.Where("username = ?", item.Username).Find(item).Error
@@ -125,6 +198,13 @@ func (u *user) Create(item *db.User) (err error) {
 		return err
 	}
 
+	if item.Password != "" {
+		err = u.recordPassword(u.DB, item.Uid, item.Password)
+		if err != nil {
+			return err
+		}
+	}
+
 	groupName := "default"
 	if item.Access == "admin" {
 		groupName = "admin"
@@ -168,6 +248,95 @@ func (u *user) Delete(item db.User) (err error) {
 		return
 	}
 	err = u.DB.Where("uid = ?", item.Uid).Delete(&db.User{}).Error
+	if err != nil {
+		return
+	}
+
+	err = Session.RevokeAll(item.Uid, "")
+	if err != nil {
+		return
+	}
+
+	err = u.ResetTOTP(item.Uid)
+	if err != nil {
+		return
+	}
+
+	err = u.DB.Unscoped().Where("uid = ?", item.Uid).Delete(&db.UserPasswordHistory{}).Error
+	if err != nil {
+		return
+	}
 
+	err = u.DB.Unscoped().Where("uid = ?", item.Uid).Delete(&db.UserNotifyEmail{}).Error
+	if err != nil {
+		return
+	}
+
+	err = u.DB.Unscoped().Where("uid = ?", item.Uid).Delete(&db.UserNotifyPref{}).Error
+	if err != nil {
+		return
+	}
+
+	err = u.DB.Unscoped().Where("uid = ?", item.Uid).Delete(&db.UserLanguage{}).Error
+	languageCache.Invalidate(strconv.Itoa(item.Uid))
 	return
 }
+
+// ChangePassword 修改密码，修改成功后吊销用户除当前会话外的所有会话，其他设备需要重新登录
+func (u *user) ChangePassword(uid int, oldPassword, newPassword, currentSessionID string) (err error) {
+	var info db.User
+	err = u.DB.Where("uid = ?", uid).First(&info).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return errors.New("用户不存在")
+		}
+		return
+	}
+
+	if info.Password == "" {
+		return errors.New("第三方登录用户不支持修改密码")
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(info.Password), []byte(oldPassword))
+	if err != nil {
+		return ErrOldPasswordWrong
+	}
+
+	err = ValidatePassword(newPassword)
+	if err != nil {
+		return
+	}
+
+	err = u.checkPasswordReuse(&info, newPassword)
+	if err != nil {
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return
+	}
+
+	tx := u.DB.Begin()
+	err = tx.Model(db.User{}).Where("uid = ?", uid).UpdateColumns(map[string]interface{}{
+		"password":    string(hash),
+		"update_time": time.Now().Unix(),
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = u.recordPassword(tx, uid, string(hash))
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return
+	}
+
+	return Session.RevokeAll(uid, currentSessionID)
+}
@@ -1,11 +1,16 @@
 package user
 
 import (
+	"encoding/hex"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/douyu/jupiter/pkg/conf"
+	"github.com/douyu/jupiter/pkg/store/gorm"
 
 	"github.com/douyu/juno/pkg/model/db"
+	"github.com/douyu/juno/pkg/model/view"
 	"github.com/gorilla/sessions"
 	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
@@ -13,17 +18,60 @@ import (
 
 const DefaultKey = "session_juno"
 
+const (
+	// session 中保存会话ID的 key
+	sessionIDKey = "sid"
+
+	// 会话状态的本地缓存时间，多实例部署时吊销最多延迟该时间生效
+	sessionCacheTTL = 10 * time.Second
+	// 本地缓存的最大会话数，超过后清空
+	sessionCacheSize = 10000
+	// 最近活跃时间的刷新间隔，避免每次请求都写库
+	sessionTouchInterval = time.Minute
+
+	// 已通过密码校验、等待两步验证的用户
+	pendingUidKey = "totp_pending_uid"
+	pendingAtKey  = "totp_pending_at"
+	// 等待两步验证期间验证码错误的次数
+	pendingFailuresKey = "totp_pending_failures"
+	// 等待两步验证的有效时间
+	pendingTTL = 5 * time.Minute
+	// 等待两步验证期间允许的验证码错误次数，超过后需要重新输入密码
+	pendingMaxFailures = 5
+
+	// 完成两步验证的会话ID和时间
+	mfaSessionIDKey = "mfa_sid"
+	mfaAtKey        = "mfa_at"
+
+	// 标记本次登录已完成两步验证
+	contextTwoFactorVerified = "juno_two_factor_verified"
+)
+
+var ErrSessionNotFound = fmt.Errorf("会话不存在")
+
 type userSession struct {
 	option sessions.Options
+	db     *gorm.DB
+
+	mu    sync.Mutex
+	cache map[string]*sessionState
 }
 
-func InitUserSession() *userSession {
+type sessionState struct {
+	uid       int
+	checkedAt time.Time
+	touchedAt time.Time
+}
+
+func InitUserSession(db *gorm.DB) *userSession {
 	return &userSession{
 		option: sessions.Options{
 			Path:     "/",
 			MaxAge:   conf.GetInt("session.maxAge"),
 			HttpOnly: true,
 		},
+		db:    db,
+		cache: make(map[string]*sessionState),
 	}
 }
 
@@ -33,8 +81,29 @@ func (u *userSession) Save(c echo.Context, user *db.User) error {
 		fmt.Println("userSession get session err:", err.Error())
 		return err
 	}
+
+	// 会话不存在、已吊销或属于其他用户时创建新会话
+	sid, _ := sess.Values[sessionIDKey].(string)
+	if sid == "" || !u.valid(sid, user.Uid) {
+		sid, err = u.create(c, user.Uid)
+		if err != nil {
+			fmt.Println("userSession create session err:", err.Error())
+			return err
+		}
+	} else {
+		u.touch(c, sid)
+	}
+
 	sess.Options = &u.option
 	sess.Values["user"] = user
+	sess.Values[sessionIDKey] = sid
+	if verified, _ := c.Get(contextTwoFactorVerified).(bool); verified {
+		sess.Values[mfaSessionIDKey] = sid
+		sess.Values[mfaAtKey] = time.Now().Unix()
+	}
+	delete(sess.Values, pendingUidKey)
+	delete(sess.Values, pendingAtKey)
+	delete(sess.Values, pendingFailuresKey)
 	err = sess.Save(c.Request(), c.Response())
 	if err != nil {
 		fmt.Println("userSession save session err:", err.Error())
@@ -54,20 +123,295 @@ func (u *userSession) Read(c echo.Context) *db.User {
 	if !ok {
 		return nil
 	}
+	sid, _ := sess.Values[sessionIDKey].(string)
+	if sid == "" || !u.valid(sid, user.Uid) {
+		return nil
+	}
 	return user
 }
 
 func (u *userSession) Logout(c echo.Context) error {
 	sess, _ := session.Get(DefaultKey, c)
+	if sid, ok := sess.Values[sessionIDKey].(string); ok && sid != "" {
+		_ = u.revoke(u.db.Where("session_id = ?", sid))
+	}
 	sess.Options = &sessions.Options{
 		Path:     "/",
 		MaxAge:   -1,
 		HttpOnly: true,
 	}
 	delete(sess.Values, "user")
+	delete(sess.Values, sessionIDKey)
+	delete(sess.Values, mfaSessionIDKey)
+	delete(sess.Values, mfaAtKey)
+	delete(sess.Values, pendingUidKey)
+	delete(sess.Values, pendingAtKey)
+	delete(sess.Values, pendingFailuresKey)
+	return sess.Save(c.Request(), c.Response())
+}
+
+// SavePending 保存已通过密码校验、等待两步验证的用户，此时不创建登录会话
+func (u *userSession) SavePending(c echo.Context, uid int) error {
+	sess, err := session.Get(DefaultKey, c)
+	if err != nil {
+		return err
+	}
+
+	sess.Options = &u.option
+	sess.Values[pendingUidKey] = uid
+	sess.Values[pendingAtKey] = time.Now().Unix()
+	sess.Values[pendingFailuresKey] = 0
 	return sess.Save(c.Request(), c.Response())
 }
 
+// PendingUid 等待两步验证的用户，不存在或已过期时返回 0
+func (u *userSession) PendingUid(c echo.Context) int {
+	sess, err := session.Get(DefaultKey, c)
+	if err != nil {
+		return 0
+	}
+
+	uid, _ := sess.Values[pendingUidKey].(int)
+	at, _ := sess.Values[pendingAtKey].(int64)
+	if uid == 0 || time.Since(time.Unix(at, 0)) > pendingTTL {
+		return 0
+	}
+	return uid
+}
+
+// RecordPendingFailure 记录一次验证码错误，达到 pendingMaxFailures 次后作废待验证状态，需要重新输入密码。
+// 会话保存在 cookie 中，重放旧 cookie 可以重置该计数，账号维度的限制由登录锁定保证
+func (u *userSession) RecordPendingFailure(c echo.Context) (invalidated bool, err error) {
+	sess, err := session.Get(DefaultKey, c)
+	if err != nil {
+		return false, err
+	}
+
+	failures, _ := sess.Values[pendingFailuresKey].(int)
+	failures++
+	sess.Options = &u.option
+	if failures >= pendingMaxFailures {
+		invalidated = true
+		delete(sess.Values, pendingUidKey)
+		delete(sess.Values, pendingAtKey)
+		delete(sess.Values, pendingFailuresKey)
+	} else {
+		sess.Values[pendingFailuresKey] = failures
+	}
+	return invalidated, sess.Save(c.Request(), c.Response())
+}
+
+// MarkTwoFactorVerified 标记当前请求已完成两步验证，随后调用 Save 时写入会话
+func (u *userSession) MarkTwoFactorVerified(c echo.Context) {
+	c.Set(contextTwoFactorVerified, true)
+}
+
+// TwoFactorVerifiedAt 当前会话最近一次完成两步验证的时间，未验证时返回零值
+func (u *userSession) TwoFactorVerifiedAt(c echo.Context) time.Time {
+	sess, err := session.Get(DefaultKey, c)
+	if err != nil {
+		return time.Time{}
+	}
+
+	sid, _ := sess.Values[sessionIDKey].(string)
+	mfaSid, _ := sess.Values[mfaSessionIDKey].(string)
+	at, _ := sess.Values[mfaAtKey].(int64)
+	if sid == "" || sid != mfaSid || at == 0 {
+		return time.Time{}
+	}
+	return time.Unix(at, 0)
+}
+
+// CurrentID 当前请求所在的会话ID
+func (u *userSession) CurrentID(c echo.Context) string {
+	sess, err := session.Get(DefaultKey, c)
+	if err != nil {
+		return ""
+	}
+	sid, _ := sess.Values[sessionIDKey].(string)
+	return sid
+}
+
+// List 用户的有效会话列表
+func (u *userSession) List(c echo.Context, uid int) (list []view.UserSession, err error) {
+	var sessionList []db.UserSession
+	err = u.db.Where("uid = ? and revoked_at is null and (expires_at is null or expires_at > ?)", uid, time.Now()).
+		Order("last_active_at desc").
+		Find(&sessionList).Error
+	if err != nil {
+		return
+	}
+
+	current := u.CurrentID(c)
+	list = make([]view.UserSession, 0, len(sessionList))
+	for _, item := range sessionList {
+		list = append(list, view.UserSession{
+			ID:           item.ID,
+			UserAgent:    item.UserAgent,
+			ClientIP:     item.ClientIP,
+			CreatedAt:    item.CreatedAt,
+			LastActiveAt: item.LastActiveAt,
+			ExpiresAt:    item.ExpiresAt,
+			Current:      item.SessionID == current,
+		})
+	}
+	return
+}
+
+// Revoke 吊销用户的某个会话，uid 为 0 时不校验会话所属用户
+func (u *userSession) Revoke(uid int, id uint) error {
+	query := u.db.Where("id = ?", id)
+	if uid != 0 {
+		query = query.Where("uid = ?", uid)
+	}
+
+	var item db.UserSession
+	err := query.First(&item).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	return u.revoke(u.db.Where("id = ?", item.ID))
+}
+
+// RevokeAll 吊销用户的全部会话，exceptSessionID 不为空时保留该会话
+func (u *userSession) RevokeAll(uid int, exceptSessionID string) error {
+	query := u.db.Where("uid = ?", uid)
+	if exceptSessionID != "" {
+		query = query.Where("session_id != ?", exceptSessionID)
+	}
+	return u.revoke(query)
+}
+
+func (u *userSession) revoke(query *gorm.DB) error {
+	var sessionList []db.UserSession
+	err := query.Where("revoked_at is null").Find(&sessionList).Error
+	if err != nil {
+		return err
+	}
+	if len(sessionList) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(sessionList))
+	for _, item := range sessionList {
+		ids = append(ids, item.ID)
+	}
+	err = u.db.Model(&db.UserSession{}).Where("id in (?)", ids).UpdateColumn("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	for _, item := range sessionList {
+		delete(u.cache, item.SessionID)
+	}
+	u.mu.Unlock()
+	return nil
+}
+
+func (u *userSession) create(c echo.Context, uid int) (sid string, err error) {
+	sid = hex.EncodeToString(generateRandomKey(32))
+	now := time.Now()
+	item := db.UserSession{
+		SessionID:    sid,
+		Uid:          uid,
+		UserAgent:    truncate(c.Request().UserAgent(), 512),
+		ClientIP:     c.RealIP(),
+		LastActiveAt: now,
+		ExpiresAt:    u.expiresAt(now),
+	}
+	err = u.db.Create(&item).Error
+	if err != nil {
+		return "", err
+	}
+
+	u.mu.Lock()
+	u.cache[sid] = &sessionState{uid: uid, checkedAt: now, touchedAt: now}
+	u.mu.Unlock()
+	return
+}
+
+// valid 会话是否有效，结果在本地缓存 sessionCacheTTL
+func (u *userSession) valid(sid string, uid int) bool {
+	now := time.Now()
+
+	u.mu.Lock()
+	state, ok := u.cache[sid]
+	u.mu.Unlock()
+	if ok && now.Sub(state.checkedAt) < sessionCacheTTL {
+		return state.uid == uid
+	}
+
+	var item db.UserSession
+	err := u.db.Where("session_id = ?", sid).First(&item).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		fmt.Println("userSession check session err:", err.Error())
+		return false
+	}
+
+	valid := err == nil && item.RevokedAt == nil && (item.ExpiresAt == nil || item.ExpiresAt.After(now))
+	u.mu.Lock()
+	if !valid {
+		delete(u.cache, sid)
+	} else {
+		if state == nil {
+			if len(u.cache) >= sessionCacheSize {
+				u.cache = make(map[string]*sessionState)
+			}
+			state = &sessionState{touchedAt: item.LastActiveAt}
+			u.cache[sid] = state
+		}
+		state.uid = item.Uid
+		state.checkedAt = now
+	}
+	u.mu.Unlock()
+
+	return valid && item.Uid == uid
+}
+
+// touch 刷新会话最近活跃时间和过期时间
+func (u *userSession) touch(c echo.Context, sid string) {
+	now := time.Now()
+
+	u.mu.Lock()
+	state, ok := u.cache[sid]
+	if !ok || now.Sub(state.touchedAt) < sessionTouchInterval {
+		u.mu.Unlock()
+		return
+	}
+	state.touchedAt = now
+	u.mu.Unlock()
+
+	err := u.db.Model(&db.UserSession{}).Where("session_id = ?", sid).Updates(map[string]interface{}{
+		"last_active_at": now,
+		"client_ip":      c.RealIP(),
+		"expires_at":     u.expiresAt(now),
+	}).Error
+	if err != nil {
+		fmt.Println("userSession touch session err:", err.Error())
+	}
+}
+
+func (u *userSession) expiresAt(now time.Time) *time.Time {
+	if u.option.MaxAge <= 0 {
+		return nil
+	}
+	expiresAt := now.Add(time.Duration(u.option.MaxAge) * time.Second)
+	return &expiresAt
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
 func NewSessionStore() sessions.Store {
 	storeTyp := conf.GetString("session.type")
 	switch storeTyp {
@@ -2,22 +2,77 @@ package wsevent
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/douyu/jupiter/pkg/xlog"
 )
 
+// 推送给浏览器的事件主题
+const (
+	TopicTask          = "task"           // 测试流水线任务、定时任务状态变化
+	TopicConfigPublish = "config.publish" // 配置发布及实例同步进度
+	TopicAlert         = "alert"          // 告警
+	TopicAgent         = "agent"          // agent 离线、恢复
+	TopicTaskLog       = "task.log"       // 测试任务阶段执行中的日志，Key 为 task/任务ID
+
+	// TopicResync 会话缓冲溢出、丢弃过事件时推送，页面收到后应重新拉取数据
+	TopicResync = "resync"
+	// TopicPing 连接保活
+	TopicPing = "ping"
+
+	defaultBufferSize = 64
+)
+
+var topics = map[string]bool{
+	TopicTask:          true,
+	TopicConfigPublish: true,
+	TopicAlert:         true,
+	TopicAgent:         true,
+	TopicTaskLog:       true,
+}
+
 type (
 	WSEvent struct {
 		option Option
 
 		subscribersMtx sync.Mutex
 		subscribers    map[string][]SubscribeHandler
+
+		sessionsMtx sync.RWMutex
+		sessions    map[*Session]struct{}
 	}
 
 	Option struct {
+		// BufferSize 每个浏览器会话缓冲的事件数，默认 64
+		BufferSize int
 	}
 
 	SubscribeHandler func(res interface{})
+
+	// Event 实体变更事件
+	Event struct {
+		Topic string `json:"topic"`
+		// Key 实体标识，如 test/流水线ID、cron/任务ID、应用名、主机名
+		Key string `json:"key,omitempty"`
+		// Zone 实体所属机房，只推送给可以访问该机房的用户，为空时不限制
+		Zone string `json:"zone,omitempty"`
+		// AppName、Env 实体所属应用和环境，AppPerm 不为空时只推送给对该应用和环境有 AppPerm 权限的用户
+		AppName string      `json:"app_name,omitempty"`
+		Env     string      `json:"env,omitempty"`
+		AppPerm string      `json:"-"`
+		Data    interface{} `json:"data,omitempty"`
+		Time    int64       `json:"time"`
+	}
+
+	// Session 浏览器会话的订阅
+	Session struct {
+		topics   map[string]bool
+		keys     map[string]bool
+		allow    func(Event) bool
+		events   chan Event
+		overflow int32
+	}
 )
 
 var (
@@ -25,10 +80,15 @@ var (
 )
 
 func Init(o Option) {
+	if o.BufferSize <= 0 {
+		o.BufferSize = defaultBufferSize
+	}
+
 	instance = &WSEvent{
 		option:         o,
 		subscribersMtx: sync.Mutex{},
 		subscribers:    map[string][]SubscribeHandler{},
+		sessions:       map[*Session]struct{}{},
 	}
 }
 
@@ -40,6 +100,34 @@ func Instance() *WSEvent {
 	return instance
 }
 
+// ValidTopic 是否为可订阅的主题
+func ValidTopic(topic string) bool {
+	return topics[topic]
+}
+
+// Publish 发布事件，未初始化时忽略，供各服务在实体状态变化时调用
+func Publish(e Event) {
+	if instance == nil {
+		return
+	}
+	instance.Publish(e)
+}
+
+// Publish 发布事件给进程内的订阅者及订阅了该主题的浏览器会话
+func (w *WSEvent) Publish(e Event) {
+	if e.Time == 0 {
+		e.Time = time.Now().Unix()
+	}
+
+	w.Pub(e.Topic, e)
+
+	w.sessionsMtx.RLock()
+	defer w.sessionsMtx.RUnlock()
+	for session := range w.sessions {
+		session.push(e)
+	}
+}
+
 func (w *WSEvent) Pub(event string, data interface{}) {
 	w.subscribersMtx.Lock()
 	defer w.subscribersMtx.Unlock()
@@ -63,3 +151,80 @@ func (w *WSEvent) Sub(event string, handler SubscribeHandler) {
 	defer w.subscribersMtx.Unlock()
 	w.subscribers[event] = append(w.subscribers[event], handler)
 }
+
+// Subscribe 创建浏览器会话的订阅，keys 为空时接收主题下的全部事件，allow 为 nil 时不做权限过滤。
+// 会话结束后需调用 Unsubscribe
+func (w *WSEvent) Subscribe(topics, keys []string, allow func(Event) bool) *Session {
+	session := &Session{
+		topics: make(map[string]bool, len(topics)),
+		keys:   make(map[string]bool, len(keys)),
+		allow:  allow,
+		events: make(chan Event, w.option.BufferSize),
+	}
+	for _, topic := range topics {
+		session.topics[topic] = true
+	}
+	for _, key := range keys {
+		session.keys[key] = true
+	}
+
+	w.sessionsMtx.Lock()
+	defer w.sessionsMtx.Unlock()
+	w.sessions[session] = struct{}{}
+	return session
+}
+
+func (w *WSEvent) Unsubscribe(session *Session) {
+	w.sessionsMtx.Lock()
+	defer w.sessionsMtx.Unlock()
+	delete(w.sessions, session)
+}
+
+// AppPermFilter 在 allow 的基础上检查应用权限，设置了 AppPerm 的事件只在 can 返回 true 时推送，
+// 没有应用名的此类事件不推送。allow 为 nil 时不做其他过滤
+func AppPermFilter(allow func(Event) bool, can func(appName, env, action string) bool) func(Event) bool {
+	return func(e Event) bool {
+		if allow != nil && !allow(e) {
+			return false
+		}
+		if e.AppPerm == "" {
+			return true
+		}
+		return e.AppName != "" && can(e.AppName, e.Env, e.AppPerm)
+	}
+}
+
+// Events 会话待推送的事件
+func (s *Session) Events() <-chan Event {
+	return s.events
+}
+
+// Overflowed 会话自上次调用以来是否因缓冲已满丢弃过事件
+func (s *Session) Overflowed() bool {
+	return atomic.SwapInt32(&s.overflow, 0) == 1
+}
+
+func (s *Session) match(e Event) bool {
+	if !s.topics[e.Topic] {
+		return false
+	}
+	if len(s.keys) > 0 && !s.keys[e.Key] {
+		return false
+	}
+	return s.allow == nil || s.allow(e)
+}
+
+// push 不阻塞发布方，浏览器消费过慢时丢弃事件并标记溢出
+func (s *Session) push(e Event) {
+	if !s.match(e) {
+		return
+	}
+
+	select {
+	case s.events <- e:
+	default:
+		if atomic.CompareAndSwapInt32(&s.overflow, 0, 1) {
+			xlog.Warn("wsevent session buffer is full, drop event", xlog.String("topic", e.Topic), xlog.String("key", e.Key))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package wsevent
+
+import (
+	"testing"
+)
+
+func TestSessionMatch(t *testing.T) {
+	Init(Option{})
+	w := Instance()
+
+	all := w.Subscribe([]string{TopicTask}, nil, nil)
+	pipeline := w.Subscribe([]string{TopicTask, TopicAgent}, []string{"test/1"}, nil)
+	zone := w.Subscribe([]string{TopicAgent}, nil, func(e Event) bool {
+		return e.Zone == "" || e.Zone == "wh"
+	})
+	defer func() {
+		w.Unsubscribe(all)
+		w.Unsubscribe(pipeline)
+		w.Unsubscribe(zone)
+	}()
+
+	w.Publish(Event{Topic: TopicTask, Key: "test/1"})
+	w.Publish(Event{Topic: TopicTask, Key: "test/2"})
+	w.Publish(Event{Topic: TopicAgent, Key: "host-a", Zone: "wh"})
+	w.Publish(Event{Topic: TopicAgent, Key: "host-b", Zone: "bj"})
+
+	expect := func(name string, s *Session, keys ...string) {
+		t.Helper()
+		if len(s.events) != len(keys) {
+			t.Fatalf("%s: got %d events, want %d", name, len(s.events), len(keys))
+		}
+		for _, key := range keys {
+			e := <-s.Events()
+			if e.Key != key || e.Time == 0 {
+				t.Errorf("%s: got %+v, want key %s", name, e, key)
+			}
+		}
+	}
+	expect("all", all, "test/1", "test/2")
+	expect("pipeline", pipeline, "test/1")
+	expect("zone", zone, "host-a")
+}
+
+func TestSessionOverflow(t *testing.T) {
+	Init(Option{BufferSize: 2})
+	w := Instance()
+
+	s := w.Subscribe([]string{TopicAlert}, nil, nil)
+	for i := 0; i < 3; i++ {
+		w.Publish(Event{Topic: TopicAlert})
+	}
+	if len(s.events) != 2 {
+		t.Fatalf("buffered %d events", len(s.events))
+	}
+	if !s.Overflowed() {
+		t.Error("session should be overflowed")
+	}
+	if s.Overflowed() {
+		t.Error("overflow flag should be reset")
+	}
+
+	w.Unsubscribe(s)
+	w.Publish(Event{Topic: TopicAlert})
+	if len(s.events) != 2 {
+		t.Error("unsubscribed session should not receive events")
+	}
+}
+
+func TestValidTopic(t *testing.T) {
+	if !ValidTopic(TopicConfigPublish) || !ValidTopic(TopicTaskLog) || ValidTopic(TopicPing) || ValidTopic("unknown") {
+		t.Error("unexpected ValidTopic result")
+	}
+}
+
+func TestAppPermFilter(t *testing.T) {
+	Init(Option{})
+	w := Instance()
+
+	can := func(user string) func(appName, env, action string) bool {
+		return func(appName, env, action string) bool {
+			return user == "dev" && appName == "app-a" && env == "dev" && action == "pipeline:read"
+		}
+	}
+	zone := func(e Event) bool { return e.Zone == "wh" }
+	dev := w.Subscribe([]string{TopicTaskLog}, []string{"task/1"}, AppPermFilter(zone, can("dev")))
+	guest := w.Subscribe([]string{TopicTaskLog}, []string{"task/1"}, AppPermFilter(zone, can("guest")))
+	defer func() {
+		w.Unsubscribe(dev)
+		w.Unsubscribe(guest)
+	}()
+
+	w.Publish(Event{Topic: TopicTaskLog, Key: "task/1", Zone: "wh", AppName: "app-a", Env: "dev", AppPerm: "pipeline:read"})
+	w.Publish(Event{Topic: TopicTaskLog, Key: "task/1", Zone: "wh", AppPerm: "pipeline:read"})
+	w.Publish(Event{Topic: TopicTaskLog, Key: "task/1", Zone: "bj", AppName: "app-a", Env: "dev", AppPerm: "pipeline:read"})
+
+	if len(dev.events) != 1 {
+		t.Errorf("user with permission got %d events, want 1", len(dev.events))
+	}
+	if len(guest.events) != 0 {
+		t.Errorf("user without permission got %d events, want 0", len(guest.events))
+	}
+}
@@ -0,0 +1,370 @@
+// Package apispec 根据路由注解生成 OpenAPI 3 文档，供 CLI、Terraform 等工具生成客户端
+package apispec
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Version 生成文档使用的 OpenAPI 版本
+const Version = "3.0.3"
+
+type (
+	Document struct {
+		OpenAPI    string              `json:"openapi"`
+		Info       Info                `json:"info"`
+		Servers    []Server            `json:"servers,omitempty"`
+		Tags       []Tag               `json:"tags,omitempty"`
+		Paths      map[string]PathItem `json:"paths"`
+		Components Components          `json:"components"`
+		// ErrorCodes 扩展字段，完整的错误码目录
+		ErrorCodes []ErrorCode `json:"x-error-codes,omitempty"`
+	}
+
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	Server struct {
+		URL         string `json:"url"`
+		Description string `json:"description,omitempty"`
+	}
+
+	Tag struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// PathItem key 为小写的 HTTP 方法
+	PathItem map[string]*Operation
+
+	Operation struct {
+		Tags        []string              `json:"tags,omitempty"`
+		Summary     string                `json:"summary,omitempty"`
+		Description string                `json:"description,omitempty"`
+		OperationID string                `json:"operationId"`
+		Parameters  []Parameter           `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]Response   `json:"responses"`
+		Security    []map[string][]string `json:"security,omitempty"`
+		Deprecated  bool                  `json:"deprecated,omitempty"`
+	}
+
+	Parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	RequestBody struct {
+		Required bool                 `json:"required,omitempty"`
+		Content  map[string]MediaType `json:"content"`
+	}
+
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	Response struct {
+		Description string               `json:"description"`
+		Content     map[string]MediaType `json:"content,omitempty"`
+	}
+
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Nullable             bool               `json:"nullable,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		Enum                 []interface{}      `json:"enum,omitempty"`
+	}
+
+	Components struct {
+		Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+		SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	SecurityScheme struct {
+		Type        string `json:"type"`
+		Description string `json:"description,omitempty"`
+		Name        string `json:"name,omitempty"`
+		In          string `json:"in,omitempty"`
+		Scheme      string `json:"scheme,omitempty"`
+	}
+
+	// ErrorCode 响应中 code 字段的取值
+	ErrorCode struct {
+		Code        int    `json:"code"`
+		Category    string `json:"category"`
+		Message     string `json:"message"`
+		Description string `json:"description,omitempty"`
+	}
+)
+
+// Doc 路由注解，Request、Response 传入零值即可，按类型反射生成 Schema
+type Doc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// OperationID 为空时由方法和路径生成
+	OperationID string
+	// Request GET、DELETE 请求按 query 标签生成查询参数，其余方法按 json 标签生成请求体
+	Request interface{}
+	// Response 响应中 data 字段的结构
+	Response interface{}
+	// Raw 响应不使用 {code, msg, data} 包装，Response 即为完整响应体
+	Raw bool
+	// ContentType 响应的 Content-Type，默认为 application/json，application/octet-stream 时响应为二进制文件
+	ContentType string
+	// Security 认证方式，为空时使用 Secure 按路径前缀设置的认证方式
+	Security []string
+	// Public 无需认证
+	Public     bool
+	Deprecated bool
+}
+
+// Object 以属性名和零值描述的对象，用于 map[string]interface{} 形式的响应，如 {"pagination": ..., "list": ...}
+type Object map[string]interface{}
+
+// Route 已注册的路由，路径使用 echo 的格式，如 /scim/v2/Users/:id
+type Route struct {
+	Method string
+	Path   string
+}
+
+type registry struct {
+	mu         sync.RWMutex
+	docs       map[string]Doc
+	security   map[string][]string
+	errorCodes []ErrorCode
+}
+
+var defaultRegistry = &registry{
+	docs:     make(map[string]Doc),
+	security: make(map[string][]string),
+}
+
+// Annotate 为路由添加注解，重复注解时后者覆盖前者
+func Annotate(method, path string, doc Doc) {
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+	defaultRegistry.docs[routeKey(method, path)] = doc
+}
+
+// Secure 设置路径前缀下接口默认的认证方式，多个前缀匹配时取最长的前缀
+func Secure(prefix string, schemes ...string) {
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+	defaultRegistry.security[prefix] = schemes
+}
+
+// ErrorCodes 设置错误码目录，生成的文档中响应的 code 字段引用 ErrorCode 组件
+func ErrorCodes(codes ...ErrorCode) {
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+	defaultRegistry.errorCodes = codes
+}
+
+// Build 根据路由和注解生成文档，未注解的路由只包含路径、方法和路径参数
+func Build(info Info, schemes map[string]SecurityScheme, routes []Route) *Document {
+	defaultRegistry.mu.RLock()
+	defer defaultRegistry.mu.RUnlock()
+	return defaultRegistry.build(info, schemes, routes)
+}
+
+func (r *registry) build(info Info, schemes map[string]SecurityScheme, routes []Route) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			SecuritySchemes: schemes,
+		},
+	}
+	gen := newGenerator()
+	tags := make(map[string]bool)
+	if len(r.errorCodes) > 0 {
+		gen.schemas[errorCodeSchema] = errorCodeSchemaOf(r.errorCodes)
+		doc.ErrorCodes = r.errorCodes
+	}
+
+	for _, route := range routes {
+		method := strings.ToLower(route.Method)
+		path, pathParams := convertPath(route.Path)
+		annotation := r.docs[routeKey(route.Method, route.Path)]
+
+		op := &Operation{
+			Tags:        annotation.Tags,
+			Summary:     annotation.Summary,
+			Description: annotation.Description,
+			OperationID: annotation.OperationID,
+			Deprecated:  annotation.Deprecated,
+		}
+		if op.OperationID == "" {
+			op.OperationID = operationID(route.Method, route.Path)
+		}
+		if len(op.Tags) == 0 {
+			op.Tags = defaultTags(route.Path)
+		}
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+
+		for _, name := range pathParams {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+		if annotation.Request != nil {
+			switch route.Method {
+			case http.MethodGet, http.MethodDelete, http.MethodHead:
+				op.Parameters = append(op.Parameters, gen.queryParams(annotation.Request)...)
+			default:
+				op.RequestBody = &RequestBody{
+					Required: true,
+					Content: map[string]MediaType{
+						"application/json": {Schema: gen.schemaOf(annotation.Request)},
+					},
+				}
+			}
+		}
+
+		contentType := annotation.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		responseSchema := &Schema{Type: "string", Format: "binary"}
+		if contentType != "application/octet-stream" {
+			responseSchema = gen.responseSchema(annotation.Response, annotation.Raw)
+		}
+		op.Responses = map[string]Response{
+			"200": {
+				Description: "success",
+				Content: map[string]MediaType{
+					contentType: {Schema: responseSchema},
+				},
+			},
+		}
+
+		security := annotation.Security
+		if len(security) == 0 && !annotation.Public {
+			security = r.securityOf(route.Path)
+		}
+		for _, scheme := range security {
+			op.Security = append(op.Security, map[string][]string{scheme: {}})
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		item[method] = op
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool {
+		return doc.Tags[i].Name < doc.Tags[j].Name
+	})
+
+	if len(gen.schemas) > 0 {
+		doc.Components.Schemas = gen.schemas
+	}
+	return doc
+}
+
+// errorCodeSchemaOf 错误码的枚举，说明中列出每个错误码的含义
+func errorCodeSchemaOf(codes []ErrorCode) *Schema {
+	s := &Schema{Type: "integer", Enum: make([]interface{}, 0, len(codes))}
+	lines := make([]string, 0, len(codes))
+	for _, code := range codes {
+		s.Enum = append(s.Enum, code.Code)
+		line := fmt.Sprintf("- %d [%s] %s", code.Code, code.Category, code.Message)
+		if code.Description != "" {
+			line += "：" + code.Description
+		}
+		lines = append(lines, line)
+	}
+	s.Description = "错误码，0 表示成功\n\n" + strings.Join(lines, "\n")
+	return s
+}
+
+func (r *registry) securityOf(path string) []string {
+	var (
+		matched  string
+		security []string
+	)
+	for prefix, schemes := range r.security {
+		if strings.HasPrefix(path, prefix) && len(prefix) >= len(matched) {
+			matched = prefix
+			security = schemes
+		}
+	}
+	return security
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// convertPath 将 echo 的 :param 转为 OpenAPI 的 {param}
+func convertPath(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID 由方法和路径生成，如 GET /api/v1/resource/app/list => getApiV1ResourceAppList
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// defaultTags 按路径分组，如 /api/v1/resource/app/list => v1/resource
+func defaultTags(path string) []string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 0 && segments[0] == "api" {
+		segments = segments[1:]
+	}
+	if len(segments) > 2 {
+		segments = segments[:2]
+	}
+	return []string{strings.Join(segments, "/")}
+}
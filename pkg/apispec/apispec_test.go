@@ -0,0 +1,192 @@
+package apispec
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testModel struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	DeletedAt *time.Time
+}
+
+type testApp struct {
+	testModel
+	Aid      int             `json:"aid"`
+	AppName  string          `json:"app_name" validate:"required"`
+	Users    []int           `json:"users"`
+	Meta     json.RawMessage `json:"meta"`
+	Parent   *testApp        `json:"parent,omitempty"`
+	Hidden   string          `json:"-"`
+	internal string
+}
+
+type testAppList struct {
+	testApp
+	Page     int      `query:"page"`
+	Tags     []string `query:"tag"`
+	Keywords string   `query:"keywords" validate:"required"`
+	Ignored  string
+}
+
+func TestConvertPath(t *testing.T) {
+	path, params := convertPath("/scim/v2/Users/:id")
+	if path != "/scim/v2/Users/{id}" || !reflect.DeepEqual(params, []string{"id"}) {
+		t.Errorf("convertPath = %s %v", path, params)
+	}
+
+	if got := operationID("GET", "/api/v1/resource/app_node/list"); got != "getApiV1ResourceAppNodeList" {
+		t.Errorf("operationID = %s", got)
+	}
+	if got := defaultTags("/api/admin/resource/app/list"); !reflect.DeepEqual(got, []string{"admin/resource"}) {
+		t.Errorf("defaultTags = %v", got)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	g := newGenerator()
+	s := g.schemaOf(testApp{})
+	if s.Ref != "#/components/schemas/apispec.testApp" {
+		t.Fatalf("ref = %s", s.Ref)
+	}
+
+	app := g.schemas["apispec.testApp"]
+	want := map[string]string{
+		"ID":        "integer",
+		"CreatedAt": "string",
+		"DeletedAt": "string",
+		"aid":       "integer",
+		"app_name":  "string",
+		"users":     "array",
+		"meta":      "",
+	}
+	for name, typ := range want {
+		prop, ok := app.Properties[name]
+		if !ok {
+			t.Errorf("missing property %s", name)
+			continue
+		}
+		if prop.Type != typ {
+			t.Errorf("property %s type = %s, want %s", name, prop.Type, typ)
+		}
+	}
+	for _, name := range []string{"Hidden", "internal", "testModel"} {
+		if _, ok := app.Properties[name]; ok {
+			t.Errorf("unexpected property %s", name)
+		}
+	}
+	if app.Properties["parent"].Ref != s.Ref {
+		t.Errorf("recursive ref = %s", app.Properties["parent"].Ref)
+	}
+	if !reflect.DeepEqual(app.Required, []string{"app_name"}) {
+		t.Errorf("required = %v", app.Required)
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	params := newGenerator().queryParams(testAppList{})
+
+	var names []string
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"page", "tag", "keywords"}) {
+		t.Fatalf("params = %v", names)
+	}
+	if params[1].Schema.Type != "array" || !params[2].Required {
+		t.Errorf("params = %+v", params)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r := &registry{
+		docs:     make(map[string]Doc),
+		security: map[string][]string{"/api": {"bearer"}, "/api/v1": {"openAuth"}},
+	}
+	r.docs[routeKey("GET", "/api/v1/app/list")] = Doc{Summary: "应用列表", Request: testAppList{}, Response: []testApp{}}
+	r.docs[routeKey("POST", "/api/v1/app/put")] = Doc{Request: testApp{}}
+
+	doc := r.build(Info{Title: "Juno", Version: "test"}, nil, []Route{
+		{Method: "GET", Path: "/api/v1/app/list"},
+		{Method: "POST", Path: "/api/v1/app/put"},
+		{Method: "DELETE", Path: "/api/admin/app/:aid"},
+	})
+
+	list := doc.Paths["/api/v1/app/list"]["get"]
+	if list == nil || list.Summary != "应用列表" || len(list.Parameters) != 3 {
+		t.Fatalf("list = %+v", list)
+	}
+	data := list.Responses["200"].Content["application/json"].Schema.Properties["data"]
+	if data.Type != "array" || data.Items.Ref != "#/components/schemas/apispec.testApp" {
+		t.Errorf("data = %+v", data)
+	}
+	if !reflect.DeepEqual(list.Security, []map[string][]string{{"openAuth": {}}}) {
+		t.Errorf("security = %v", list.Security)
+	}
+
+	put := doc.Paths["/api/v1/app/put"]["post"]
+	if put.RequestBody == nil || put.RequestBody.Content["application/json"].Schema.Ref == "" {
+		t.Errorf("put = %+v", put)
+	}
+
+	del := doc.Paths["/api/admin/app/{aid}"]["delete"]
+	if del == nil || len(del.Parameters) != 1 || del.Parameters[0].In != "path" {
+		t.Fatalf("delete = %+v", del)
+	}
+	if !reflect.DeepEqual(del.Security, []map[string][]string{{"bearer": {}}}) {
+		t.Errorf("security = %v", del.Security)
+	}
+
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObject(t *testing.T) {
+	g := newGenerator()
+	s := g.schemaOf(Object{"list": []testApp{}, "total": 0})
+	if s.Type != "object" || s.Properties["list"].Items.Ref == "" || s.Properties["total"].Type != "integer" {
+		t.Errorf("schema = %+v", s)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	r := &registry{
+		docs: make(map[string]Doc),
+		errorCodes: []ErrorCode{
+			{Code: 0, Category: "general", Message: "success"},
+			{Code: 14029, Category: "limit", Message: "请求过于频繁", Description: "按 Retry-After 重试"},
+		},
+	}
+	r.docs[routeKey("GET", "/api/v1/app/info")] = Doc{Response: testApp{}}
+	r.docs[routeKey("GET", "/api/v1/app/export")] = Doc{Response: testApp{}, Raw: true}
+
+	doc := r.build(Info{Title: "Juno", Version: "test"}, nil, []Route{
+		{Method: "GET", Path: "/api/v1/app/info"},
+		{Method: "GET", Path: "/api/v1/app/export"},
+	})
+
+	codes := doc.Components.Schemas[errorCodeSchema]
+	if codes == nil || codes.Type != "integer" || !reflect.DeepEqual(codes.Enum, []interface{}{0, 14029}) {
+		t.Fatalf("ErrorCode schema = %+v", codes)
+	}
+	if !strings.Contains(codes.Description, "- 14029 [limit] 请求过于频繁：按 Retry-After 重试") {
+		t.Errorf("description = %q", codes.Description)
+	}
+	if len(doc.ErrorCodes) != 2 {
+		t.Errorf("x-error-codes = %v", doc.ErrorCodes)
+	}
+
+	info := doc.Paths["/api/v1/app/info"]["get"].Responses["200"].Content["application/json"].Schema
+	if info.Properties["code"].Ref != "#/components/schemas/"+errorCodeSchema {
+		t.Errorf("code = %+v", info.Properties["code"])
+	}
+	export := doc.Paths["/api/v1/app/export"]["get"].Responses["200"].Content["application/json"].Schema
+	if export.Ref != "#/components/schemas/apispec.testApp" {
+		t.Errorf("raw response = %+v", export)
+	}
+}
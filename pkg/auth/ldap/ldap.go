@@ -0,0 +1,150 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("账号或密码错误")
+	ErrNoGroupMatched     = errors.New("LDAP 用户不属于任何允许登录的组")
+)
+
+// UserInfo LDAP 用户信息
+type UserInfo struct {
+	DN       string
+	Username string
+	Nickname string
+	Email    string
+	Groups   []string
+}
+
+// Authenticate 使用服务账号查找用户，再以用户DN和密码绑定校验
+func Authenticate(config cfg.LDAP, username, password string) (info *UserInfo, err error) {
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := dial(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "connect ldap failed")
+	}
+	defer conn.Close()
+
+	if config.BindDN != "" {
+		err = conn.Bind(config.BindDN, config.BindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap bind failed")
+	}
+
+	attributes := []string{config.UsernameAttribute, config.NicknameAttribute, config.EmailAttribute}
+	if config.MemberOfAttribute != "" {
+		attributes = append(attributes, config.MemberOfAttribute)
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(
+		config.SearchBaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(config.SearchFilter, ldap.EscapeFilter(username)),
+		attributes,
+		nil,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap search user failed")
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	entry := result.Entries[0]
+	err = conn.Bind(entry.DN, password)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "ldap bind user failed")
+	}
+
+	info = &UserInfo{
+		DN:       entry.DN,
+		Username: entry.GetAttributeValue(config.UsernameAttribute),
+		Nickname: entry.GetAttributeValue(config.NicknameAttribute),
+		Email:    entry.GetAttributeValue(config.EmailAttribute),
+	}
+	if info.Username == "" {
+		info.Username = username
+	}
+	if config.MemberOfAttribute != "" {
+		info.Groups = entry.GetAttributeValues(config.MemberOfAttribute)
+	}
+
+	if config.GroupSearchBaseDN != "" && config.GroupSearchFilter != "" {
+		// 重新以服务账号绑定查询用户所属组
+		if config.BindDN != "" {
+			err = conn.Bind(config.BindDN, config.BindPassword)
+			if err != nil {
+				return nil, errors.Wrap(err, "ldap bind failed")
+			}
+		}
+
+		groupResult, err := conn.Search(ldap.NewSearchRequest(
+			config.GroupSearchBaseDN,
+			ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			fmt.Sprintf(config.GroupSearchFilter, ldap.EscapeFilter(entry.DN)),
+			[]string{"dn"},
+			nil,
+		))
+		if err != nil {
+			return nil, errors.Wrap(err, "ldap search groups failed")
+		}
+		for _, group := range groupResult.Entries {
+			info.Groups = append(info.Groups, group.DN)
+		}
+	}
+
+	return info, nil
+}
+
+// MapGroup 按配置顺序匹配用户所属组，返回对应的 Juno 用户组
+func MapGroup(mappings []cfg.LDAPGroupMapping, groups []string) (mapping cfg.LDAPGroupMapping, err error) {
+	for _, item := range mappings {
+		if item.GroupDN == "*" {
+			return item, nil
+		}
+		for _, group := range groups {
+			if strings.EqualFold(item.GroupDN, group) {
+				return item, nil
+			}
+		}
+	}
+
+	return mapping, ErrNoGroupMatched
+}
+
+func dial(config cfg.LDAP) (conn *ldap.Conn, err error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.SkipVerify,
+	}
+
+	conn, err = ldap.DialURL(config.URL, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return
+	}
+
+	if config.StartTLS && strings.HasPrefix(config.URL, "ldap://") {
+		err = conn.StartTLS(tlsConfig)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return
+}
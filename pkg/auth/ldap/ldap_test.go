@@ -0,0 +1,34 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+func TestMapGroup(t *testing.T) {
+	mappings := []cfg.LDAPGroupMapping{
+		{GroupDN: "cn=admins,ou=groups,dc=example,dc=org", UserGroup: "admin", Admin: true},
+		{GroupDN: "cn=dev,ou=groups,dc=example,dc=org", UserGroup: "dev"},
+	}
+
+	mapping, err := MapGroup(mappings, []string{"CN=dev,OU=groups,DC=example,DC=org", "cn=admins,ou=groups,dc=example,dc=org"})
+	if err != nil || mapping.UserGroup != "admin" || !mapping.Admin {
+		t.Errorf("MapGroup() = %v, %v, want admin", mapping, err)
+	}
+
+	mapping, err = MapGroup(mappings, []string{"CN=dev,OU=groups,DC=example,DC=org"})
+	if err != nil || mapping.UserGroup != "dev" || mapping.Admin {
+		t.Errorf("MapGroup() = %v, %v, want dev", mapping, err)
+	}
+
+	_, err = MapGroup(mappings, []string{"cn=other,ou=groups,dc=example,dc=org"})
+	if err != ErrNoGroupMatched {
+		t.Errorf("MapGroup() err = %v, want ErrNoGroupMatched", err)
+	}
+
+	mapping, err = MapGroup(append(mappings, cfg.LDAPGroupMapping{GroupDN: "*", UserGroup: "default"}), nil)
+	if err != nil || mapping.UserGroup != "default" {
+		t.Errorf("MapGroup() = %v, %v, want default", mapping, err)
+	}
+}
@@ -0,0 +1,292 @@
+package oidc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// CallbackPath 授权回调地址
+	CallbackPath = "/api/admin/user/login/oidc"
+
+	discoveryPath = "/.well-known/openid-configuration"
+	keysCacheTTL  = time.Hour
+)
+
+var (
+	// Provider 为空表示未开启 OIDC
+	Provider *provider
+
+	ErrNoGroupMatched = errors.New("OIDC 用户不属于任何允许登录的组")
+)
+
+type (
+	// metadata OpenID Provider Metadata
+	metadata struct {
+		Issuer                string   `json:"issuer"`
+		AuthorizationEndpoint string   `json:"authorization_endpoint"`
+		TokenEndpoint         string   `json:"token_endpoint"`
+		JwksURI               string   `json:"jwks_uri"`
+		UserinfoEndpoint      string   `json:"userinfo_endpoint"`
+		CodeChallengeMethods  []string `json:"code_challenge_methods_supported"`
+	}
+
+	provider struct {
+		config cfg.OIDC
+		client *http.Client
+
+		mtx           sync.Mutex
+		metadata      *metadata
+		keys          map[string]interface{}
+		keysFetchedAt time.Time
+	}
+
+	// UserInfo ID Token 中解析出的用户信息
+	UserInfo struct {
+		Subject  string
+		Username string
+		Nickname string
+		Email    string
+		Groups   []string
+	}
+)
+
+// Init ..
+func Init(config cfg.OIDC) {
+	if !config.Enable {
+		return
+	}
+
+	Provider = &provider{
+		config: config,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: config.TlsSkipVerify,
+				},
+			},
+		},
+	}
+}
+
+// Name 登录页展示名称
+func (p *provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL 生成授权地址，使用 PKCE S256
+func (p *provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	oauthConfig, err := p.oauthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return oauthConfig.AuthCodeURL(state,
+		oauth2.AccessTypeOnline,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("code_challenge", CodeChallengeS256(codeVerifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), nil
+}
+
+// Exchange 使用授权码换取 token 并校验 ID Token
+func (p *provider) Exchange(ctx context.Context, code, nonce, codeVerifier string) (info *UserInfo, err error) {
+	oauthConfig, err := p.oauthConfig(ctx)
+	if err != nil {
+		return
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	if err != nil {
+		return nil, errors.Wrap(err, "exchange token failed")
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("id_token not found in token response")
+	}
+
+	claims, err := p.verifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		return
+	}
+
+	info = &UserInfo{
+		Subject:  claimString(claims, "sub"),
+		Username: claimString(claims, p.config.UsernameClaim),
+		Nickname: claimString(claims, p.config.NicknameClaim),
+		Email:    claimString(claims, p.config.EmailClaim),
+		Groups:   claimStrings(claims, p.config.GroupsClaim),
+	}
+	if info.Subject == "" {
+		return nil, errors.New("sub claim not found in id_token")
+	}
+	if info.Username == "" {
+		info.Username = info.Email
+	}
+	if info.Username == "" {
+		return nil, errors.New("username claim not found in id_token")
+	}
+
+	return
+}
+
+// MapGroup 按配置顺序匹配用户所属组，返回对应的 Juno 用户组
+func (p *provider) MapGroup(groups []string) (cfg.OIDCGroupMapping, error) {
+	return MapGroup(p.config.GroupMappings, groups)
+}
+
+// MapGroup ..
+func MapGroup(mappings []cfg.OIDCGroupMapping, groups []string) (mapping cfg.OIDCGroupMapping, err error) {
+	for _, item := range mappings {
+		if item.Group == "*" {
+			return item, nil
+		}
+		for _, group := range groups {
+			if item.Group == group {
+				return item, nil
+			}
+		}
+	}
+
+	return mapping, ErrNoGroupMatched
+}
+
+func (p *provider) oauthConfig(ctx context.Context) (*oauth2.Config, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  meta.AuthorizationEndpoint,
+			TokenURL: meta.TokenEndpoint,
+		},
+		RedirectURL: strings.TrimSuffix(cfg.Cfg.AppURL, "/") + CallbackPath,
+		Scopes:      p.config.Scopes,
+	}, nil
+}
+
+// discover 获取并缓存 Provider Metadata
+func (p *provider) discover(ctx context.Context) (*metadata, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var meta metadata
+	err := p.getJSON(ctx, strings.TrimSuffix(p.config.Issuer, "/")+discoveryPath, &meta)
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc discovery failed")
+	}
+	if strings.TrimSuffix(meta.Issuer, "/") != strings.TrimSuffix(p.config.Issuer, "/") {
+		return nil, fmt.Errorf("oidc issuer mismatch, expected %s got %s", p.config.Issuer, meta.Issuer)
+	}
+
+	p.metadata = &meta
+	return p.metadata, nil
+}
+
+// publicKey 根据 kid 获取签名公钥，找不到时刷新一次 JWKS 以支持密钥轮换
+func (p *provider) publicKey(ctx context.Context, kid string) (interface{}, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok && time.Since(p.keysFetchedAt) < keysCacheTTL {
+		return key, nil
+	}
+
+	var set jsonWebKeySet
+	err = p.getJSON(ctx, meta.JwksURI, &set)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch jwks failed")
+	}
+	p.keys, err = set.publicKeys()
+	if err != nil {
+		return nil, err
+	}
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("signing key %s not found", kid)
+}
+
+func (p *provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func claimString(claims map[string]interface{}, name string) string {
+	if name == "" {
+		return ""
+	}
+	v, _ := claims[name].(string)
+	return v
+}
+
+// claimStrings groups 可能是字符串数组，也可能是单个字符串
+func claimStrings(claims map[string]interface{}, name string) (list []string) {
+	if name == "" {
+		return
+	}
+	switch v := claims[name].(type) {
+	case string:
+		list = append(list, v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,97 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+func TestCodeChallengeS256(t *testing.T) {
+	// RFC 7636 Appendix B
+	got := CodeChallengeS256("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if got != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Errorf("CodeChallengeS256() = %v", got)
+	}
+}
+
+func TestMapGroup(t *testing.T) {
+	mappings := []cfg.OIDCGroupMapping{
+		{Group: "juno-admin", UserGroup: "admin", Admin: true},
+		{Group: "juno-dev", UserGroup: "dev"},
+	}
+
+	mapping, err := MapGroup(mappings, []string{"juno-dev", "juno-admin"})
+	if err != nil || mapping.UserGroup != "admin" {
+		t.Errorf("MapGroup() = %v, %v, want admin", mapping, err)
+	}
+
+	_, err = MapGroup(mappings, []string{"other"})
+	if err != ErrNoGroupMatched {
+		t.Errorf("MapGroup() err = %v, want ErrNoGroupMatched", err)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &provider{
+		config:        cfg.OIDC{ClientID: "juno"},
+		metadata:      &metadata{Issuer: "https://sso.example.org"},
+		keys:          map[string]interface{}{"k1": &key.PublicKey},
+		keysFetchedAt: time.Now(),
+	}
+
+	sign := func(claims map[string]interface{}) string {
+		header, _ := json.Marshal(jwtHeader{Alg: "RS256", Kid: "k1"})
+		payload, _ := json.Marshal(claims)
+		input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		digest := sha256.Sum256([]byte(input))
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	valid := map[string]interface{}{
+		"iss":   "https://sso.example.org",
+		"aud":   []string{"juno"},
+		"sub":   "u1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": "n1",
+	}
+
+	claims, err := p.verifyIDToken(context.Background(), sign(valid), "n1")
+	if err != nil {
+		t.Fatalf("verifyIDToken() err = %v", err)
+	}
+	if claims["sub"] != "u1" {
+		t.Errorf("verifyIDToken() sub = %v", claims["sub"])
+	}
+
+	if _, err = p.verifyIDToken(context.Background(), sign(valid), "n2"); err == nil {
+		t.Error("verifyIDToken() should reject nonce mismatch")
+	}
+
+	expired := map[string]interface{}{}
+	for k, v := range valid {
+		expired[k] = v
+	}
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	if _, err = p.verifyIDToken(context.Background(), sign(expired), "n1"); err == nil {
+		t.Error("verifyIDToken() should reject expired token")
+	}
+
+	token := sign(valid)
+	if _, err = p.verifyIDToken(context.Background(), token[:len(token)-4]+"AAAA", "n1"); err == nil {
+		t.Error("verifyIDToken() should reject invalid signature")
+	}
+}
@@ -0,0 +1,82 @@
+// Package password 本地账号密码复杂度校验与登录失败锁定退避计算
+package password
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Policy 密码复杂度要求
+type Policy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// Validate 校验密码是否满足复杂度要求，返回的错误信息列出所有未满足的规则
+func (p Policy) Validate(password string) error {
+	var (
+		upper, lower, digit, symbol bool
+		length                      int
+		unmet                       []string
+	)
+
+	for _, r := range password {
+		length++
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	if length < p.MinLength {
+		unmet = append(unmet, fmt.Sprintf("长度至少 %d 位", p.MinLength))
+	}
+	if p.RequireUpper && !upper {
+		unmet = append(unmet, "包含大写字母")
+	}
+	if p.RequireLower && !lower {
+		unmet = append(unmet, "包含小写字母")
+	}
+	if p.RequireDigit && !digit {
+		unmet = append(unmet, "包含数字")
+	}
+	if p.RequireSymbol && !symbol {
+		unmet = append(unmet, "包含特殊字符")
+	}
+
+	if len(unmet) > 0 {
+		return fmt.Errorf("密码不满足要求：%s", strings.Join(unmet, "，"))
+	}
+	return nil
+}
+
+// LockDuration 计算连续失败 failures 次后的锁定时长。
+// 达到 threshold 次时锁定 base，之后每多失败一次锁定时长翻倍，最长 max；threshold <= 0 表示不锁定
+func LockDuration(failures, threshold int, base, max time.Duration) time.Duration {
+	if threshold <= 0 || failures < threshold || base <= 0 {
+		return 0
+	}
+
+	d := base
+	for i := threshold; i < failures; i++ {
+		d *= 2
+		if max > 0 && d >= max {
+			return max
+		}
+	}
+	if max > 0 && d > max {
+		return max
+	}
+	return d
+}
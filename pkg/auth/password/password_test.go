@@ -0,0 +1,62 @@
+package password
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	p := Policy{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		password string
+		ok       bool
+	}{
+		{"Abcdef1!", true},
+		{"Abc1!", false},
+		{"abcdefg1!", false},
+		{"ABCDEFG1!", false},
+		{"Abcdefgh!", false},
+		{"Abcdefgh1", false},
+		{"密码Abcdef1!", true},
+	}
+
+	for _, tt := range tests {
+		err := p.Validate(tt.password)
+		if (err == nil) != tt.ok {
+			t.Errorf("Validate(%q) err = %v, want ok = %v", tt.password, err, tt.ok)
+		}
+	}
+
+	if err := (Policy{}).Validate(""); err != nil {
+		t.Errorf("empty policy should accept any password, got %v", err)
+	}
+}
+
+func TestLockDuration(t *testing.T) {
+	base, max := time.Minute, 10*time.Minute
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{8, 8 * time.Minute},
+		{9, 10 * time.Minute},
+		{100, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := LockDuration(tt.failures, 5, base, max); got != tt.want {
+			t.Errorf("LockDuration(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+
+	if got := LockDuration(100, 0, base, max); got != 0 {
+		t.Errorf("threshold 0 should disable lockout, got %v", got)
+	}
+}
@@ -0,0 +1,91 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 的 SHA1 测试向量，取后 6 位
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeAt(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := CodeAt(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("CodeAt(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	step, ok := Validate(rfcSecret, "081804", now, 0)
+	if !ok || step != Step(now) {
+		t.Fatalf("Validate() = (%d, %v), want (%d, true)", step, ok, Step(now))
+	}
+
+	// 允许前后一个时间步的偏差
+	if _, ok := Validate(rfcSecret, "081804", now.Add(Period), 0); !ok {
+		t.Errorf("Validate() should accept code of previous step")
+	}
+	if _, ok := Validate(rfcSecret, "081804", now.Add(2*Period), 0); ok {
+		t.Errorf("Validate() should reject code out of skew")
+	}
+
+	// 已使用过的验证码不能重复使用
+	if _, ok := Validate(rfcSecret, "081804", now, step); ok {
+		t.Errorf("Validate() should reject replayed code")
+	}
+
+	if _, ok := Validate(rfcSecret, "12345", now, 0); ok {
+		t.Errorf("Validate() should reject code with wrong length")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := CodeAt(secret, 1)
+	if err != nil || len(code) != Digits {
+		t.Errorf("CodeAt() = (%s, %v)", code, err)
+	}
+
+	uri := URI("Juno", "admin", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Juno:admin?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("unexpected uri %s", uri)
+	}
+}
+
+func TestBackupCodes(t *testing.T) {
+	codes, err := GenerateBackupCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 10 || len(codes[0]) != 11 || codes[0][5] != '-' {
+		t.Fatalf("unexpected backup codes %v", codes)
+	}
+
+	if HashBackupCode(codes[0]) != HashBackupCode(" "+strings.ToUpper(strings.Replace(codes[0], "-", "", 1))+" ") {
+		t.Errorf("HashBackupCode() should ignore case, spaces and dash")
+	}
+}
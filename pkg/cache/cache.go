@@ -0,0 +1,170 @@
+// Package cache 读多写少数据的缓存，如应用信息、配置文件、注册中心快照。
+// 配置 Redis 时多个实例共享缓存，未配置时使用进程内缓存；
+// 数据修改后由写入方调用 Invalidate 主动失效，TTL 只作为兜底
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+const (
+	TypeMemory = "memory"
+	TypeRedis  = "redis"
+)
+
+// DefaultTTL 未配置 ttl 时的过期时间
+const DefaultTTL = 5 * time.Minute
+
+type (
+	// Config 缓存配置，未开启时所有读取直接访问数据库
+	Config struct {
+		Enable bool `toml:"enable"`
+		// Type memory 或 redis，默认 memory。
+		// 多实例部署时应使用 redis，进程内缓存在其他实例修改数据后要等到过期才会更新
+		Type string `toml:"type"`
+		// Prefix 缓存 key 的前缀，多套 Juno 共用一个 Redis 时用来区分，默认 juno
+		Prefix string `toml:"prefix"`
+		// TTL 缓存过期时间，默认 5m
+		TTL   time.Duration `toml:"ttl"`
+		Redis RedisConfig   `toml:"redis"`
+	}
+
+	RedisConfig struct {
+		Addrs []string `toml:"addrs"`
+		// Mode stub 或 cluster，为空时按地址个数判断
+		Mode     string `toml:"mode"`
+		Password string `toml:"password"`
+		DB       int    `toml:"db"`
+	}
+
+	// Store 缓存存储，未命中时 ok 为 false
+	Store interface {
+		Get(key string) (value []byte, ok bool, err error)
+		Set(key string, value []byte, ttl time.Duration) error
+		Delete(keys ...string) error
+		Ping(ctx context.Context) error
+	}
+
+	// Cache 一类数据的缓存，key 加上类别名称后写入存储
+	Cache struct {
+		name string
+		ttl  time.Duration
+	}
+)
+
+var (
+	// store 为 nil 表示未开启缓存
+	store      Store
+	prefix     = "juno"
+	defaultTTL = DefaultTTL
+)
+
+// Init 按配置初始化存储，type 为 redis 但没有配置地址时使用进程内缓存
+func Init(c Config) {
+	if !c.Enable {
+		store = nil
+		return
+	}
+	if c.Prefix != "" {
+		prefix = c.Prefix
+	}
+	if c.TTL > 0 {
+		defaultTTL = c.TTL
+	}
+
+	switch c.Type {
+	case TypeRedis:
+		if len(c.Redis.Addrs) == 0 {
+			xlog.Warn("cache: redis addrs is empty, fallback to memory")
+			store = newMemoryStore(defaultMaxEntries)
+			return
+		}
+		store = newRedisStore(c.Redis)
+	case "", TypeMemory:
+		store = newMemoryStore(defaultMaxEntries)
+	default:
+		xlog.Warn("cache: unknown type, fallback to memory", xlog.String("type", c.Type))
+		store = newMemoryStore(defaultMaxEntries)
+	}
+}
+
+// Enabled 是否开启了缓存
+func Enabled() bool {
+	return store != nil
+}
+
+// Ping 检查缓存存储是否可用，未开启时返回 nil
+func Ping(ctx context.Context) error {
+	if store == nil {
+		return nil
+	}
+	return store.Ping(ctx)
+}
+
+// New ttl 为 0 时使用配置的过期时间。通常作为包级变量声明，早于 Init 执行
+func New(name string, ttl time.Duration) *Cache {
+	return &Cache{name: name, ttl: ttl}
+}
+
+// Fetch 命中时将缓存的 JSON 解码到 dst，否则调用 load 填充 dst 并写入缓存。
+// load 返回错误时不缓存（包括记录不存在）；存储不可用时跳过缓存，直接调用 load
+func (c *Cache) Fetch(key string, dst interface{}, load func() error) error {
+	s := store
+	if s == nil {
+		return load()
+	}
+
+	k := c.key(key)
+	value, ok, err := s.Get(k)
+	if err != nil {
+		xlog.Warn("cache: get failed", xlog.String("key", k), xlog.String("err", err.Error()))
+	} else if ok {
+		if err = json.Unmarshal(value, dst); err == nil {
+			return nil
+		}
+		xlog.Warn("cache: decode failed", xlog.String("key", k), xlog.String("err", err.Error()))
+	}
+
+	if err := load(); err != nil {
+		return err
+	}
+	value, err = json.Marshal(dst)
+	if err != nil {
+		xlog.Warn("cache: encode failed", xlog.String("key", k), xlog.String("err", err.Error()))
+		return nil
+	}
+	if err = s.Set(k, value, c.expiration()); err != nil {
+		xlog.Warn("cache: set failed", xlog.String("key", k), xlog.String("err", err.Error()))
+	}
+	return nil
+}
+
+// Invalidate 删除缓存，在数据修改并提交后调用
+func (c *Cache) Invalidate(keys ...string) {
+	s := store
+	if s == nil || len(keys) == 0 {
+		return
+	}
+	list := make([]string, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, c.key(key))
+	}
+	if err := s.Delete(list...); err != nil {
+		xlog.Warn("cache: delete failed", xlog.Any("keys", list), xlog.String("err", err.Error()))
+	}
+}
+
+func (c *Cache) key(key string) string {
+	return prefix + ":" + c.name + ":" + key
+}
+
+func (c *Cache) expiration() time.Duration {
+	if c.ttl > 0 {
+		return c.ttl
+	}
+	return defaultTTL
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func useStore(t *testing.T, s Store) {
+	old := store
+	store = s
+	t.Cleanup(func() { store = old })
+}
+
+func TestFetchDisabled(t *testing.T) {
+	useStore(t, nil)
+	c := New("item", 0)
+
+	loads := 0
+	for i := 0; i < 2; i++ {
+		var v item
+		err := c.Fetch("1", &v, func() error {
+			loads++
+			v = item{ID: 1, Name: "a"}
+			return nil
+		})
+		if err != nil || v.Name != "a" {
+			t.Fatalf("fetch = %+v, %v", v, err)
+		}
+	}
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2", loads)
+	}
+}
+
+func TestFetchAndInvalidate(t *testing.T) {
+	useStore(t, newMemoryStore(defaultMaxEntries))
+	c := New("item", 0)
+
+	loads := 0
+	name := "a"
+	fetch := func() item {
+		var v item
+		err := c.Fetch("1", &v, func() error {
+			loads++
+			v = item{ID: 1, Name: name}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	if v := fetch(); v.Name != "a" {
+		t.Fatalf("first fetch = %+v", v)
+	}
+	name = "b"
+	if v := fetch(); v.Name != "a" || loads != 1 {
+		t.Fatalf("cached fetch = %+v, loads = %d", v, loads)
+	}
+	c.Invalidate("1")
+	if v := fetch(); v.Name != "b" || loads != 2 {
+		t.Fatalf("fetch after invalidate = %+v, loads = %d", v, loads)
+	}
+}
+
+func TestFetchLoadError(t *testing.T) {
+	useStore(t, newMemoryStore(defaultMaxEntries))
+	c := New("item", 0)
+
+	notFound := errors.New("not found")
+	var v item
+	if err := c.Fetch("1", &v, func() error { return notFound }); err != notFound {
+		t.Fatalf("err = %v, want %v", err, notFound)
+	}
+	loaded := false
+	err := c.Fetch("1", &v, func() error {
+		loaded = true
+		return nil
+	})
+	if err != nil || !loaded {
+		t.Fatalf("error result should not be cached, err = %v, loaded = %v", err, loaded)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := newMemoryStore(2)
+
+	_ = s.Set("a", []byte("1"), time.Minute)
+	_ = s.Set("b", []byte("2"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := s.Get("b"); ok {
+		t.Fatal("b should be expired")
+	}
+
+	_ = s.Set("b", []byte("2"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	// 写满时清理过期的 b 后写入 c
+	_ = s.Set("c", []byte("3"), time.Minute)
+	if value, ok, _ := s.Get("c"); !ok || string(value) != "3" {
+		t.Fatalf("c = %q, %v", value, ok)
+	}
+	// 没有过期条目可清理时不写入
+	_ = s.Set("d", []byte("4"), time.Minute)
+	if _, ok, _ := s.Get("d"); ok {
+		t.Fatal("d should not be stored when full")
+	}
+
+	_ = s.Delete("a", "c")
+	if _, ok, _ := s.Get("a"); ok {
+		t.Fatal("a should be deleted")
+	}
+}
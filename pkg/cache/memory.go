@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultMaxEntries 进程内缓存的条目上限，写满且没有过期条目可清理时不再写入
+const defaultMaxEntries = 10000
+
+type (
+	memoryStore struct {
+		mu         sync.RWMutex
+		items      map[string]memoryItem
+		maxEntries int
+	}
+
+	memoryItem struct {
+		value    []byte
+		expireAt time.Time
+	}
+)
+
+func newMemoryStore(maxEntries int) *memoryStore {
+	return &memoryStore{
+		items:      make(map[string]memoryItem),
+		maxEntries: maxEntries,
+	}
+}
+
+func (s *memoryStore) Get(key string) ([]byte, bool, error) {
+	s.mu.RLock()
+	item, ok := s.items[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(item.expireAt) {
+		s.mu.Lock()
+		if current, ok := s.items[key]; ok && !time.Now().Before(current.expireAt) {
+			delete(s.items, key)
+		}
+		s.mu.Unlock()
+		return nil, false, nil
+	}
+	return item.value, true, nil
+}
+
+func (s *memoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[key]; !ok && len(s.items) >= s.maxEntries {
+		s.evictExpired()
+		if len(s.items) >= s.maxEntries {
+			return nil
+		}
+	}
+	s.items[key] = memoryItem{value: value, expireAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryStore) Delete(keys ...string) error {
+	s.mu.Lock()
+	for _, key := range keys {
+		delete(s.items, key)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// evictExpired 调用方需持有写锁
+func (s *memoryStore) evictExpired() {
+	now := time.Now()
+	for key, item := range s.items {
+		if now.After(item.expireAt) {
+			delete(s.items, key)
+		}
+	}
+}
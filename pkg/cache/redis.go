@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/douyu/jupiter/pkg/client/redis"
+)
+
+type redisStore struct {
+	client *redis.Redis
+}
+
+// newRedisStore 启动时 Redis 不可用只记录错误，之后的读写失败时跳过缓存直接读库
+func newRedisStore(c RedisConfig) *redisStore {
+	config := redis.DefaultRedisConfig()
+	config.Addrs = c.Addrs
+	config.Mode = c.Mode
+	config.Password = c.Password
+	config.DB = c.DB
+	config.OnDialError = "error"
+	return &redisStore{client: config.Build()}
+}
+
+func (s *redisStore) Get(key string) ([]byte, bool, error) {
+	value, err := s.client.GetRaw(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, len(value) > 0, nil
+}
+
+func (s *redisStore) Set(key string, value []byte, ttl time.Duration) error {
+	return s.client.SetWithErr(key, value, ttl)
+}
+
+// Delete 逐个删除，cluster 模式下多个 key 可能不在同一个 slot
+func (s *redisStore) Delete(keys ...string) error {
+	var lastErr error
+	for _, key := range keys {
+		if _, err := s.client.DelWithErr(key); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (s *redisStore) Ping(ctx context.Context) error {
+	return s.client.Client.Ping().Err()
+}
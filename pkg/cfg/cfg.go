@@ -21,6 +21,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/douyu/juno/pkg/cache"
+	"github.com/douyu/juno/pkg/sqldialect"
+	"github.com/douyu/juno/pkg/tracing"
 	"github.com/douyu/jupiter/pkg/conf"
 	"github.com/douyu/jupiter/pkg/ecode"
 	"github.com/douyu/jupiter/pkg/util/xtime"
@@ -44,6 +47,7 @@ type cfg struct {
 	ClientProxy       ClientProxy
 	ServerProxy       ServerProxy
 	Database          Database
+	Cache             cache.Config
 	Configure         Configure
 	Agent             Agent
 	Casbin            Casbin
@@ -54,10 +58,21 @@ type cfg struct {
 	AppLog            AppLog
 	GrpcTest          GrpcTest
 	ProxyAuth         ProxyAuth
+	ServiceAccount    ServiceAccount
+	IPAllowlist       IPAllowlist
+	RateLimit         RateLimit
+	Trace             tracing.Config
 	CodePlatform      CodePlatform
+	AppImport         AppImport
+	CMDB              CMDB `toml:"cmdb"`
+	AppLifecycle      AppLifecycle
+	K8SCluster        K8SCluster `toml:"k8sCluster"`
+	Promotion         Promotion
 	TestPlatform      TestPlatform
 	Notice            Notice
 	JunoEvent         JunoEvent
+	EventBus          EventBus
+	RecycleBin        RecycleBin
 }
 
 // DefaultConfig ...
@@ -81,15 +96,50 @@ func defaultConfig() cfg {
 			OauthAutoLogin:                   false,
 			OauthStateCookieMaxAge:           60,
 			ApiKeyMaxSecondsToLive:           -1,
+			LDAP: LDAP{
+				SearchFilter:      "(uid=%s)",
+				UsernameAttribute: "uid",
+				NicknameAttribute: "cn",
+				EmailAttribute:    "mail",
+				MemberOfAttribute: "memberOf",
+			},
+			OIDC: OIDC{
+				Name:          "SSO",
+				Scopes:        []string{"openid", "profile", "email"},
+				UsernameClaim: "preferred_username",
+				NicknameClaim: "name",
+				EmailClaim:    "email",
+				GroupsClaim:   "groups",
+			},
+			TwoFactor: TwoFactor{
+				Issuer:          "Juno",
+				SensitiveWindow: xtime.Duration("10m"),
+			},
+			PasswordPolicy: PasswordPolicy{
+				MinLength:          8,
+				LockoutThreshold:   5,
+				LockoutDuration:    xtime.Duration("1m"),
+				LockoutMaxDuration: xtime.Duration("30m"),
+			},
+		},
+		ServiceAccount: ServiceAccount{
+			AllowSharedToken:        true,
+			AllowAnonymousHeartbeat: true,
 		},
 		Database: Database{
 			Enable:          false,
+			Dialect:         sqldialect.MySQL,
 			ConnMaxLifetime: time.Duration(time.Second * 300),
 			Debug:           true,
 			DSN:             "root:root@tcp(127.0.0.1:3306)/juno?charset=utf8&parseTime=True&loc=Local&readTimeout=1s&timeout=1s&writeTimeout=3s",
 			MaxIdleConns:    50,
 			MaxOpenConns:    100,
 		},
+		Cache: cache.Config{
+			Type:   cache.TypeMemory,
+			Prefix: "juno",
+			TTL:    cache.DefaultTTL,
+		},
 		Server: Server{
 			Http: ServerSchema{
 				Host:           "0.0.0.0",
@@ -103,6 +153,24 @@ func defaultConfig() cfg {
 				Host: "0.0.0.0",
 				Port: 50001,
 			},
+			Graceful: Graceful{
+				Timeout:        xtime.Duration("30s"),
+				UpgradeTimeout: xtime.Duration("30s"),
+			},
+		},
+		RateLimit: RateLimit{
+			Enable: true,
+			Groups: map[string]RateLimitRule{
+				// Grafana 面板打开时会并发发起多个 PromQL 查询
+				"grafana":  {Rate: 20, Burst: 60},
+				"registry": {Rate: 1, Burst: 5},
+				"logger":   {Rate: 2, Burst: 10},
+				"graphql":  {Rate: 5, Burst: 20},
+			},
+		},
+		Trace: tracing.Config{
+			Enable:     false,
+			SampleRate: 1,
 		},
 		GrafanaProxy: GrafanaProxy{
 			Enable: false,
@@ -210,6 +278,11 @@ func defaultConfig() cfg {
 			Debug:       false,
 			StorePath:   "data/pprof",
 		},
+		Agent: Agent{
+			PackageDir:       "data/agent",
+			UpgradeTimeout:   xtime.Duration("5m"),
+			OfflineThreshold: xtime.Duration("2m"),
+		},
 		Casbin: Casbin{
 			Enable:           false,
 			Debug:            true,
@@ -269,9 +342,14 @@ func InitCfg() {
 	if err != nil {
 		xlog.Panic("parse root url err", zap.Error(err))
 	}
+	config.Database.Dialect, err = sqldialect.Validate(config.Database.Dialect)
+	if err != nil {
+		xlog.Panic("parse database dialect err", zap.Error(err))
+	}
 	config.parseHeartBeat()
 	Cfg = config
 	xlog.Info("InitCfg", xlog.Any("config", config))
+	watchReload()
 }
 
 func parseAppAndSubURL(rootURL string) (string, string, error) {
@@ -0,0 +1,95 @@
+package cfg
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/douyu/jupiter/pkg/conf"
+	"github.com/douyu/jupiter/pkg/flag"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// reloadableFields 运行时可以重新加载的配置，读取方需要在使用时读取 Cfg，不能在启动时保存副本。
+// 其他配置（监听地址、数据库、etcd、日志等）在启动时初始化，修改后需要重启
+var reloadableFields = []string{
+	"Notice",         // 通知渠道
+	"GrafanaProxy",   // Grafana 设置项
+	"RateLimit",      // 接口限流
+	"ProxyAuth",      // worker 共享 token
+	"ServiceAccount", // worker、agent 认证兼容开关
+}
+
+var reloadMtx sync.Mutex
+
+// Reload 重新读取配置文件，更新 reloadableFields 中的配置，返回有变化的配置名。
+// 只支持本地配置文件；使用 etcd 等远程数据源时通过 --watch 自动重新加载
+func Reload() ([]string, error) {
+	reloadMtx.Lock()
+	defer reloadMtx.Unlock()
+
+	content, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if err := conf.LoadFromReader(bytes.NewReader(content), toml.Unmarshal); err != nil {
+		return nil, fmt.Errorf("parse config file: %w", err)
+	}
+	return reload()
+}
+
+// watchReload --watch 开启时，jupiter 重新加载配置后同步更新 Cfg
+func watchReload() {
+	conf.OnChange(func(*conf.Configuration) {
+		reloadMtx.Lock()
+		defer reloadMtx.Unlock()
+
+		if _, err := reload(); err != nil {
+			xlog.Error("reload cfg failed", xlog.FieldErr(err))
+		}
+	})
+}
+
+func reload() ([]string, error) {
+	next := defaultConfig()
+	if err := conf.UnmarshalKey("", &next); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+
+	changed := Cfg.apply(next)
+	if len(changed) > 0 {
+		xlog.Info("reload cfg", xlog.Any("changed", changed))
+	}
+	return changed, nil
+}
+
+// apply 整体替换有变化的配置项，不修改原有的 map、slice
+func (c *cfg) apply(next cfg) (changed []string) {
+	cur := reflect.ValueOf(c).Elem()
+	nextValue := reflect.ValueOf(next)
+	for _, name := range reloadableFields {
+		field := cur.FieldByName(name)
+		value := nextValue.FieldByName(name)
+		if reflect.DeepEqual(field.Interface(), value.Interface()) {
+			continue
+		}
+		field.Set(value)
+		changed = append(changed, name)
+	}
+	return
+}
+
+func readConfigFile() ([]byte, error) {
+	addr := flag.String("config")
+	if addr == "" {
+		return nil, fmt.Errorf("no config file")
+	}
+	if u, err := url.Parse(addr); err == nil && len(u.Scheme) > 1 {
+		return nil, fmt.Errorf("reload only supports local config file, use --watch for %s data source", u.Scheme)
+	}
+	return ioutil.ReadFile(addr)
+}
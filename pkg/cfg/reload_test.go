@@ -0,0 +1,34 @@
+package cfg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyReloadable(t *testing.T) {
+	c := defaultConfig()
+	groups := c.RateLimit.Groups
+
+	next := defaultConfig()
+	next.RateLimit.Groups = map[string]RateLimitRule{"grafana": {Rate: 1, Burst: 1}}
+	next.ProxyAuth.Token = "token"
+	next.Server.Http.Port = 1
+
+	changed := c.apply(next)
+	if want := []string{"RateLimit", "ProxyAuth"}; !reflect.DeepEqual(changed, want) {
+		t.Fatalf("changed = %v, want %v", changed, want)
+	}
+	if c.RateLimit.Groups["grafana"].Rate != 1 || c.ProxyAuth.Token != "token" {
+		t.Errorf("reloadable fields not applied: %+v %+v", c.RateLimit, c.ProxyAuth)
+	}
+	if c.Server.Http.Port == 1 {
+		t.Error("server config should not be reloaded")
+	}
+	if groups["grafana"].Rate != 20 {
+		t.Error("previous map should not be modified")
+	}
+
+	if changed := c.apply(next); len(changed) != 0 {
+		t.Errorf("unchanged config reported as changed: %v", changed)
+	}
+}
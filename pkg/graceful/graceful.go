@@ -0,0 +1,109 @@
+// Package graceful juno-admin 平滑退出与重启。
+// 退出时先标记为退出中（/readyz 返回 503，WebSocket 主动断开），再停止接收新请求并等待进行中的请求结束；
+// 升级时可以把监听 socket 交给新进程，新旧进程交替期间 agent、worker 的回调不会被拒绝
+package graceful
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pollInterval WaitFor 检查条件的间隔
+const pollInterval = 50 * time.Millisecond
+
+var (
+	closing   = make(chan struct{})
+	closeOnce sync.Once
+
+	streams int64
+
+	hooksMu sync.Mutex
+	hooks   []hook
+)
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Shutdown 标记进程开始退出，可以重复调用
+func Shutdown() {
+	closeOnce.Do(func() {
+		close(closing)
+	})
+}
+
+// Closing 开始退出时关闭，长连接据此主动断开，由客户端重连到其他实例或新进程
+func Closing() <-chan struct{} {
+	return closing
+}
+
+// Draining 是否正在退出
+func Draining() bool {
+	select {
+	case <-closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// Track 登记一个长连接，连接结束时调用返回的 done。
+// http.Server.Shutdown 不会等待已被接管（Hijack）的连接，退出时通过 Streams 等待
+func Track() (done func()) {
+	atomic.AddInt64(&streams, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.AddInt64(&streams, -1)
+		})
+	}
+}
+
+// Streams 进行中的长连接数
+func Streams() int {
+	return int(atomic.LoadInt64(&streams))
+}
+
+// WaitFor 等待 cond 返回 true，ctx 结束时返回 ctx.Err()
+func WaitFor(ctx context.Context, cond func() bool) error {
+	if cond() {
+		return nil
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if cond() {
+				return nil
+			}
+		}
+	}
+}
+
+// OnShutdown 注册退出时执行的函数，如发送缓存的通知、等待异步队列写完，由 Flush 按注册顺序执行
+func OnShutdown(name string, fn func(ctx context.Context) error) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, hook{name: name, fn: fn})
+}
+
+// Flush 执行 OnShutdown 注册的全部函数，某个函数失败不影响其他函数执行，返回第一个错误
+func Flush(ctx context.Context) (err error) {
+	hooksMu.Lock()
+	list := append([]hook(nil), hooks...)
+	hooksMu.Unlock()
+
+	for _, h := range list {
+		if e := h.fn(ctx); e != nil && err == nil {
+			err = fmt.Errorf("graceful: flush %s: %w", h.name, e)
+		}
+	}
+	return
+}
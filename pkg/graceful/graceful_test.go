@@ -0,0 +1,63 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTrack(t *testing.T) {
+	done := Track()
+	if Streams() != 1 {
+		t.Fatalf("Streams() = %d, want 1", Streams())
+	}
+	done()
+	done()
+	if Streams() != 0 {
+		t.Fatalf("Streams() = %d after done, want 0", Streams())
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	done := Track()
+	time.AfterFunc(100*time.Millisecond, done)
+	if err := WaitFor(context.Background(), func() bool { return Streams() == 0 }); err != nil {
+		t.Fatalf("WaitFor: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := WaitFor(ctx, func() bool { return false }); err != context.DeadlineExceeded {
+		t.Fatalf("WaitFor = %v, want deadline exceeded", err)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	var called []string
+	OnShutdown("a", func(ctx context.Context) error {
+		called = append(called, "a")
+		return errors.New("failed")
+	})
+	OnShutdown("b", func(ctx context.Context) error {
+		called = append(called, "b")
+		return nil
+	})
+
+	if Draining() {
+		t.Fatal("Draining() = true before Shutdown")
+	}
+	Shutdown()
+	Shutdown()
+	if !Draining() {
+		t.Fatal("Draining() = false after Shutdown")
+	}
+
+	err := Flush(context.Background())
+	if err == nil || err.Error() != "graceful: flush a: failed" {
+		t.Fatalf("Flush = %v", err)
+	}
+	if len(called) != 2 {
+		t.Fatalf("called = %v, want both hooks", called)
+	}
+}
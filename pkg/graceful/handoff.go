@@ -0,0 +1,136 @@
+//go:build !windows
+// +build !windows
+
+package graceful
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 父进程通过 ExtraFiles 传递的文件描述符，ExtraFiles[i] 在子进程中为 3+i
+const (
+	envListenFD = "JUNO_GRACEFUL_LISTEN_FD"
+	envReadyFD  = "JUNO_GRACEFUL_READY_FD"
+)
+
+// Inherited 返回父进程交接的监听 socket，不是由 Upgrade 启动时返回 nil, nil
+func Inherited() (net.Listener, error) {
+	file, err := inheritedFile(envListenFD, "listener")
+	if file == nil || err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ln, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("graceful: inherit listener: %w", err)
+	}
+	return ln, nil
+}
+
+// Ready 通知父进程新进程已开始服务，父进程收到后开始退出。不是由 Upgrade 启动时什么都不做
+func Ready() error {
+	file, err := inheritedFile(envReadyFD, "ready")
+	if file == nil || err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write([]byte{1})
+	return err
+}
+
+// Upgrade 使用相同的参数启动新进程并交接监听 socket，新进程调用 Ready 后返回。
+// 新进程在 timeout 内没有就绪时会被结束，当前进程继续服务
+func Upgrade(ln net.Listener, timeout time.Duration) error {
+	tcpListener, ok := ln.(*net.TCPListener)
+	if !ok {
+		return fmt.Errorf("graceful: unsupported listener %T", ln)
+	}
+	// File 返回的是复制的描述符，关闭不影响当前进程继续 Accept
+	listenFile, err := tcpListener.File()
+	if err != nil {
+		return err
+	}
+	defer listenFile.Close()
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		readyW.Close()
+		return err
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{listenFile, readyW}
+	cmd.Env = append(environ(), envListenFD+"=3", envReadyFD+"=4")
+	err = cmd.Start()
+	readyW.Close()
+	if err != nil {
+		return err
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := readyR.Read(buf); err != nil {
+			// 新进程退出时管道的写端被关闭
+			result <- fmt.Errorf("graceful: new process exited before ready: %w", err)
+			return
+		}
+		result <- nil
+	}()
+
+	select {
+	case err = <-result:
+	case <-time.After(timeout):
+		err = fmt.Errorf("graceful: new process not ready in %s", timeout)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	// 新进程由当前进程退出后的 init 进程回收
+	return cmd.Process.Release()
+}
+
+func inheritedFile(env, name string) (*os.File, error) {
+	value := os.Getenv(env)
+	if value == "" {
+		return nil, nil
+	}
+	// 只使用一次，避免之后启动的子进程误用
+	_ = os.Unsetenv(env)
+
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("graceful: invalid %s=%q", env, value)
+	}
+	return os.NewFile(uintptr(fd), name), nil
+}
+
+// environ 去掉已有的交接变量，多次升级时不会重复
+func environ() []string {
+	env := make([]string, 0, len(os.Environ()))
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, envListenFD+"=") || strings.HasPrefix(kv, envReadyFD+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
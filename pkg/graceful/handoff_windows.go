@@ -0,0 +1,22 @@
+package graceful
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// Inherited windows 不支持交接监听 socket
+func Inherited() (net.Listener, error) {
+	return nil, nil
+}
+
+// Ready windows 不支持交接监听 socket
+func Ready() error {
+	return nil
+}
+
+// Upgrade windows 不支持交接监听 socket
+func Upgrade(ln net.Listener, timeout time.Duration) error {
+	return fmt.Errorf("graceful: upgrade is not supported on windows")
+}
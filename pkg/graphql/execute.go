@@ -0,0 +1,611 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const defaultMaxDepth = 10
+
+var builtinScalars = map[string]*Scalar{
+	Int.Name:     Int,
+	Float.Name:   Float,
+	String.Name:  String,
+	Boolean.Name: Boolean,
+	ID.Name:      ID,
+}
+
+type executor struct {
+	ctx      context.Context
+	schema   *Schema
+	doc      *document
+	op       *operation
+	vars     map[string]interface{}
+	maxDepth int
+	// args 校验阶段计算的字段参数
+	args   map[*field]map[string]interface{}
+	errors []*Error
+}
+
+// Execute 解析、校验并执行查询。校验不通过时不会调用任何 Resolve
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return errorResponse(err)
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if op.kind != "query" {
+		return errorResponse(fmt.Errorf("只支持 query 操作，不支持 %s", op.kind))
+	}
+	vars, err := coerceVariables(op.variables, req.Variables)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	e := &executor{
+		ctx:      ctx,
+		schema:   s,
+		doc:      doc,
+		op:       op,
+		vars:     vars,
+		maxDepth: s.MaxDepth,
+		args:     make(map[*field]map[string]interface{}),
+	}
+	if e.maxDepth <= 0 {
+		e.maxDepth = defaultMaxDepth
+	}
+	if err = e.validate(s.Query, op.selectionSet, 1, make(map[string]bool)); err != nil {
+		return errorResponse(err)
+	}
+
+	data := e.executeSelectionSet(s.Query, nil, op.selectionSet, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func errorResponse(err error) *Response {
+	var gqlErr *Error
+	if !errors.As(err, &gqlErr) {
+		gqlErr = &Error{Message: err.Error()}
+	}
+	return &Response{Errors: []*Error{gqlErr}}
+}
+
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, fmt.Errorf("查询包含多个操作，需指定 operationName")
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("操作 %s 不存在", name)
+}
+
+func coerceVariables(defs []*variableDefinition, input map[string]interface{}) (vars map[string]interface{}, err error) {
+	vars = make(map[string]interface{}, len(defs))
+	for _, def := range defs {
+		v, ok := input[def.name]
+		if !ok && def.defaultValue != nil {
+			v, ok = literal(def.defaultValue), true
+		}
+		if !ok || v == nil {
+			if def.typ.nonNull {
+				return nil, fmt.Errorf("变量 $%s 不能为空", def.name)
+			}
+			if ok {
+				vars[def.name] = nil
+			}
+			continue
+		}
+
+		vars[def.name], err = coerceVariable(def.typ, v)
+		if err != nil {
+			return nil, fmt.Errorf("变量 $%s: %s", def.name, err.Error())
+		}
+	}
+	return
+}
+
+func coerceVariable(ref typeRef, v interface{}) (interface{}, error) {
+	if v == nil {
+		if ref.nonNull {
+			return nil, fmt.Errorf("不能为空")
+		}
+		return nil, nil
+	}
+	if ref.list == nil {
+		scalar, ok := builtinScalars[ref.name]
+		if !ok {
+			return nil, fmt.Errorf("不支持类型 %s", ref.name)
+		}
+		return coerceInput(scalar, v)
+	}
+
+	items, ok := v.([]interface{})
+	if !ok {
+		items = []interface{}{v}
+	}
+	list := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		coerced, err := coerceVariable(*ref.list, item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, coerced)
+	}
+	return list, nil
+}
+
+// coerceInput 将参数、变量的值转为 t 对应的 Go 类型
+func coerceInput(t Type, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *Scalar:
+		coerced, ok := t.coerce(v)
+		if !ok {
+			return nil, fmt.Errorf("%v 不是有效的 %s", v, t.Name)
+		}
+		return coerced, nil
+	case *List:
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v}
+		}
+		list := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			coerced, err := coerceInput(t.Of, item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, coerced)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("参数不支持类型 %s", t)
+}
+
+// literal 将语法树中的常量转为 Go 值
+func literal(v value) interface{} {
+	switch v := v.(type) {
+	case []value:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, literal(item))
+		}
+		return list
+	case []objectField:
+		obj := make(map[string]interface{}, len(v))
+		for _, f := range v {
+			obj[f.name] = literal(f.value)
+		}
+		return obj
+	case enumValue:
+		return string(v)
+	}
+	return v
+}
+
+// resolveValue 将参数中的变量替换为变量值
+func (e *executor) resolveValue(v value) (interface{}, error) {
+	switch v := v.(type) {
+	case variable:
+		return e.vars[string(v)], nil
+	case []value:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			resolved, err := e.resolveValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, resolved)
+		}
+		return list, nil
+	case []objectField:
+		return nil, fmt.Errorf("不支持对象类型的参数")
+	case enumValue:
+		return nil, fmt.Errorf("不支持枚举值 %s", v)
+	}
+	return v, nil
+}
+
+func (e *executor) checkVariables(v value) error {
+	switch v := v.(type) {
+	case variable:
+		if !e.declared(string(v)) {
+			return fmt.Errorf("变量 $%s 未定义", v)
+		}
+	case []value:
+		for _, item := range v {
+			if err := e.checkVariables(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *executor) declared(name string) bool {
+	for _, def := range e.op.variables {
+		if def.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *executor) coerceArgs(defs Args, args []*argument) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(defs))
+	for _, arg := range args {
+		def, ok := defs[arg.name]
+		if !ok {
+			return nil, fmt.Errorf("不支持参数 %s", arg.name)
+		}
+		if err := e.checkVariables(arg.value); err != nil {
+			return nil, err
+		}
+		v, err := e.resolveValue(arg.value)
+		if err != nil {
+			return nil, fmt.Errorf("参数 %s: %s", arg.name, err.Error())
+		}
+		// 未传的变量视为未传参数
+		if name, isVar := arg.value.(variable); isVar {
+			if _, provided := e.vars[string(name)]; !provided {
+				continue
+			}
+		}
+		if values[arg.name], err = coerceInput(def.Type, v); err != nil {
+			return nil, fmt.Errorf("参数 %s: %s", arg.name, err.Error())
+		}
+	}
+	for name, def := range defs {
+		if _, ok := values[name]; !ok && def.Default != nil {
+			values[name] = def.Default
+		}
+		if def.Required && values[name] == nil {
+			return nil, fmt.Errorf("参数 %s 不能为空", name)
+		}
+	}
+	return values, nil
+}
+
+// included 按 @include、@skip 指令判断是否需要执行
+func (e *executor) included(directives []*directive) (bool, error) {
+	for _, d := range directives {
+		if d.name != "include" && d.name != "skip" {
+			return false, fmt.Errorf("不支持指令 @%s", d.name)
+		}
+		args, err := e.coerceArgs(Args{"if": {Type: Boolean, Required: true}}, d.arguments)
+		if err != nil {
+			return false, fmt.Errorf("@%s %s", d.name, err.Error())
+		}
+		if args["if"].(bool) == (d.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func locate(err error, loc Location) error {
+	return &Error{Message: err.Error(), Locations: []Location{loc}}
+}
+
+// validate 校验字段、参数、片段及嵌套层数，同时计算字段参数
+func (e *executor) validate(obj *Object, set []selection, depth int, visiting map[string]bool) error {
+	if depth > e.maxDepth {
+		return fmt.Errorf("查询嵌套层数不能超过 %d", e.maxDepth)
+	}
+
+	for _, s := range set {
+		switch s := s.(type) {
+		case *field:
+			if _, err := e.included(s.directives); err != nil {
+				return locate(err, s.loc)
+			}
+			if s.name == "__typename" {
+				if s.selectionSet != nil {
+					return locate(fmt.Errorf("__typename 不能包含子字段"), s.loc)
+				}
+				continue
+			}
+
+			def, ok := obj.Fields[s.name]
+			if !ok {
+				return locate(fmt.Errorf("类型 %s 没有字段 %s", obj.Name, s.name), s.loc)
+			}
+			args, err := e.coerceArgs(def.Args, s.arguments)
+			if err != nil {
+				return locate(fmt.Errorf("%s.%s %s", obj.Name, s.name, err.Error()), s.loc)
+			}
+			e.args[s] = args
+
+			child := namedObject(def.Type)
+			switch {
+			case child == nil && s.selectionSet != nil:
+				return locate(fmt.Errorf("字段 %s 的类型 %s 不能包含子字段", s.name, def.Type), s.loc)
+			case child != nil && s.selectionSet == nil:
+				return locate(fmt.Errorf("字段 %s 的类型 %s 需要指定子字段", s.name, def.Type), s.loc)
+			case child != nil:
+				if err = e.validate(child, s.selectionSet, depth+1, visiting); err != nil {
+					return err
+				}
+			}
+		case *fragmentSpread:
+			if _, err := e.included(s.directives); err != nil {
+				return locate(err, s.loc)
+			}
+			f, ok := e.doc.fragments[s.name]
+			if !ok {
+				return locate(fmt.Errorf("片段 %s 不存在", s.name), s.loc)
+			}
+			if visiting[s.name] {
+				return locate(fmt.Errorf("片段 %s 循环引用", s.name), s.loc)
+			}
+			if f.typeCondition != obj.Name {
+				return locate(fmt.Errorf("片段 %s 不能用于类型 %s", s.name, obj.Name), s.loc)
+			}
+			visiting[s.name] = true
+			err := e.validate(obj, f.selectionSet, depth, visiting)
+			delete(visiting, s.name)
+			if err != nil {
+				return err
+			}
+		case *inlineFragment:
+			if _, err := e.included(s.directives); err != nil {
+				return err
+			}
+			if s.typeCondition != "" && s.typeCondition != obj.Name {
+				return fmt.Errorf("片段不能用于类型 %s", obj.Name)
+			}
+			if err := e.validate(obj, s.selectionSet, depth, visiting); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// namedObject 字段类型为对象或对象列表时返回对象类型
+func namedObject(t Type) *Object {
+	for {
+		switch v := t.(type) {
+		case *List:
+			t = v.Of
+		case *Object:
+			return v
+		default:
+			return nil
+		}
+	}
+}
+
+// collectFields 按结果中的 key 合并字段，保持查询中的顺序
+func (e *executor) collectFields(set []selection, keys *[]string, fields map[string][]*field) {
+	for _, s := range set {
+		switch s := s.(type) {
+		case *field:
+			if ok, _ := e.included(s.directives); !ok {
+				continue
+			}
+			key := s.name
+			if s.alias != "" {
+				key = s.alias
+			}
+			if _, ok := fields[key]; !ok {
+				*keys = append(*keys, key)
+			}
+			fields[key] = append(fields[key], s)
+		case *fragmentSpread:
+			if ok, _ := e.included(s.directives); ok {
+				e.collectFields(e.doc.fragments[s.name].selectionSet, keys, fields)
+			}
+		case *inlineFragment:
+			if ok, _ := e.included(s.directives); ok {
+				e.collectFields(s.selectionSet, keys, fields)
+			}
+		}
+	}
+}
+
+func (e *executor) executeSelectionSet(obj *Object, source interface{}, set []selection, path []interface{}) *orderedMap {
+	var keys []string
+	fields := make(map[string][]*field)
+	e.collectFields(set, &keys, fields)
+
+	result := &orderedMap{}
+	for _, key := range keys {
+		fieldPath := append(append([]interface{}{}, path...), key)
+		result.set(key, e.executeField(obj, source, fields[key], fieldPath))
+	}
+	return result
+}
+
+func (e *executor) executeField(obj *Object, source interface{}, fields []*field, path []interface{}) (result interface{}) {
+	f := fields[0]
+	if f.name == "__typename" {
+		return obj.Name
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			e.fieldError(fmt.Errorf("字段 %s 解析失败: %v", f.name, r), f, path)
+			result = nil
+		}
+	}()
+
+	def := obj.Fields[f.name]
+	var (
+		v   interface{}
+		err error
+	)
+	if def.Resolve != nil {
+		v, err = def.Resolve(ResolveParams{Context: e.ctx, Source: source, Args: e.args[f]})
+	} else {
+		v, err = defaultResolve(source, f.name)
+	}
+	if err != nil {
+		e.fieldError(err, f, path)
+		return nil
+	}
+
+	var set []selection
+	for _, f := range fields {
+		set = append(set, f.selectionSet...)
+	}
+	return e.complete(def.Type, v, set, f, path)
+}
+
+func (e *executor) fieldError(err error, f *field, path []interface{}) {
+	e.errors = append(e.errors, &Error{Message: err.Error(), Locations: []Location{f.loc}, Path: path})
+}
+
+func (e *executor) complete(t Type, v interface{}, set []selection, f *field, path []interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() && (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		return rv.Interface()
+	case *Object:
+		return e.executeSelectionSet(t, v, set, path)
+	case *List:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.fieldError(fmt.Errorf("字段 %s 的值不是列表", f.name), f, path)
+			return nil
+		}
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			itemPath := append(append([]interface{}{}, path...), i)
+			list[i] = e.complete(t.Of, rv.Index(i).Interface(), set, f, itemPath)
+		}
+		return list
+	}
+	return nil
+}
+
+// defaultResolve 按字段名从 map 或结构体中取值
+func defaultResolve(source interface{}, name string) (interface{}, error) {
+	v := reflect.ValueOf(source)
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		item := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !item.IsValid() {
+			return nil, nil
+		}
+		return item.Interface(), nil
+	case reflect.Struct:
+		if item, ok := structField(v, name, false); ok {
+			return item.Interface(), nil
+		}
+		if item, ok := structField(v, normalize(name), true); ok {
+			return item.Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("无法从 %s 中读取字段 %s", v.Type(), name)
+}
+
+// structField 查找 json 标签为 name 的字段，fuzzy 时比较忽略大小写、下划线后的字段名，匿名结构体字段会被展开
+func structField(v reflect.Value, name string, fuzzy bool) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if sf.Anonymous && tag == "" {
+			fv := v.Field(i)
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if item, ok := structField(fv, name, fuzzy); ok {
+					return item, true
+				}
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if fuzzy && (normalize(sf.Name) == name || tag != "" && normalize(tag) == name) || !fuzzy && tag == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+// orderedMap 按字段在查询中的顺序输出 JSON
+type orderedMap struct {
+	keys   []string
+	values []interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(m.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,257 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testModel struct {
+	ID        uint
+	CreatedAt time.Time
+}
+
+type testApp struct {
+	testModel
+	AppName string `json:"app_name"`
+	Lang    string
+	secret  string
+}
+
+type testNode struct {
+	HostName string `json:"host_name"`
+	Env      string `json:"env"`
+}
+
+func testSchema() *Schema {
+	node := &Object{Name: "Instance", Fields: Fields{
+		"host_name": {Type: String},
+		"env":       {Type: String},
+		"broken": {Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			return nil, errors.New("broken")
+		}},
+	}}
+	app := &Object{Name: "App", Fields: Fields{
+		"id":         {Type: ID},
+		"app_name":   {Type: String},
+		"lang":       {Type: String},
+		"created_at": {Type: String},
+		"secret":     {Type: String},
+		"instances": {
+			Type: ListOf(node),
+			Args: Args{"env": {Type: String}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				nodes := []testNode{{"a", "dev"}, {"b", "prod"}}
+				env, _ := p.Args["env"].(string)
+				var result []*testNode
+				for i := range nodes {
+					if env == "" || nodes[i].Env == env {
+						result = append(result, &nodes[i])
+					}
+				}
+				return result, nil
+			},
+		},
+	}}
+	app.Fields["self"] = &Field{Type: app, Resolve: func(p ResolveParams) (interface{}, error) {
+		return p.Source, nil
+	}}
+
+	return &Schema{
+		MaxDepth: 4,
+		Query: &Object{Name: "Query", Fields: Fields{
+			"app": {
+				Type: app,
+				Args: Args{"name": {Type: String, Required: true}},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					if p.Args["name"] != "juno" {
+						return nil, nil
+					}
+					return &testApp{testModel: testModel{ID: 1}, AppName: "juno", Lang: "go"}, nil
+				},
+			},
+			"apps": {
+				Type: ListOf(app),
+				Args: Args{"first": {Type: Int, Default: 20}, "langs": {Type: ListOf(String)}},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					apps := []map[string]interface{}{{"app_name": "a"}, {"app_name": "b"}, {"app_name": "c"}}
+					if first := p.Args["first"].(int); first < len(apps) {
+						apps = apps[:first]
+					}
+					return apps, nil
+				},
+			},
+		}},
+	}
+}
+
+func execute(t *testing.T, query string, variables map[string]interface{}) (string, []*Error) {
+	t.Helper()
+	resp := testSchema().Execute(context.Background(), Request{Query: query, Variables: variables})
+	if resp.Data == nil {
+		return "", resp.Errors
+	}
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), resp.Errors
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		want      string
+	}{
+		{
+			name:  "nested",
+			query: `{ app(name: "juno") { id app_name lang instances(env: "prod") { host_name } } }`,
+			want:  `{"app":{"id":1,"app_name":"juno","lang":"go","instances":[{"host_name":"b"}]}}`,
+		},
+		{
+			name:  "alias and typename",
+			query: `query { a: app(name: "juno") { __typename name: app_name } b: app(name: "none") { id } }`,
+			want:  `{"a":{"__typename":"App","name":"juno"},"b":null}`,
+		},
+		{
+			name:      "variables and defaults",
+			query:     `query Q($name: String!, $first: Int = 2) { app(name: $name) { app_name } apps(first: $first) { app_name } }`,
+			variables: map[string]interface{}{"name": "juno"},
+			want:      `{"app":{"app_name":"juno"},"apps":[{"app_name":"a"},{"app_name":"b"}]}`,
+		},
+		{
+			name:      "json number variable",
+			query:     `query ($first: Int) { apps(first: $first) { app_name } }`,
+			variables: map[string]interface{}{"first": float64(1)},
+			want:      `{"apps":[{"app_name":"a"}]}`,
+		},
+		{
+			name:  "missing variable uses argument default",
+			query: `query ($first: Int) { apps(first: $first) { app_name } }`,
+			want:  `{"apps":[{"app_name":"a"},{"app_name":"b"},{"app_name":"c"}]}`,
+		},
+		{
+			name: "fragments",
+			query: `
+				query { app(name: "juno") { ...base ... on App { lang } } }
+				fragment base on App { id app_name }`,
+			want: `{"app":{"id":1,"app_name":"juno","lang":"go"}}`,
+		},
+		{
+			name:      "directives",
+			query:     `query ($on: Boolean!) { app(name: "juno") { id @skip(if: $on) lang @include(if: $on) } }`,
+			variables: map[string]interface{}{"on": true},
+			want:      `{"app":{"lang":"go"}}`,
+		},
+		{
+			name:  "merge selections",
+			query: `{ app(name: "juno") { instances { host_name } instances { env } } }`,
+			want:  `{"app":{"instances":[{"host_name":"a","env":"dev"},{"host_name":"b","env":"prod"}]}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := execute(t, tt.query, tt.variables)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs[0])
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		want      string
+	}{
+		{"syntax", `{ app(name: "juno") { id }`, nil, "语法错误"},
+		{"mutation", `mutation { app(name: "juno") { id } }`, nil, "只支持 query"},
+		{"unknown field", `{ app(name: "juno") { password } }`, nil, "没有字段 password"},
+		{"unexported field", `{ app(name: "juno") { secret } }`, nil, "无法从"},
+		{"unknown argument", `{ app(name: "juno", id: 1) { id } }`, nil, "不支持参数 id"},
+		{"required argument", `{ app { id } }`, nil, "参数 name 不能为空"},
+		{"argument type", `{ apps(first: "1") { id } }`, nil, "不是有效的 Int"},
+		{"required variable", `query ($name: String!) { app(name: $name) { id } }`, nil, "变量 $name 不能为空"},
+		{"undefined variable", `{ app(name: $name) { id } }`, nil, "变量 $name 未定义"},
+		{"variable type", `query ($name: String) { app(name: $name) { id } }`, map[string]interface{}{"name": 1.0}, "变量 $name"},
+		{"missing selection", `{ app(name: "juno") }`, nil, "需要指定子字段"},
+		{"scalar selection", `{ app(name: "juno") { id { x } } }`, nil, "不能包含子字段"},
+		{"depth", `{ app(name: "juno") { self { self { self { id } } } } }`, nil, "嵌套层数不能超过 4"},
+		{"fragment cycle", `{ app(name: "juno") { ...a } } fragment a on App { self { ...a } }`, nil, "循环引用"},
+		{"fragment self", `{ app(name: "juno") { ...a } } fragment a on App { ...a }`, nil, "循环引用"},
+		{"fragment type", `{ app(name: "juno") { ...a } } fragment a on Instance { env }`, nil, "不能用于类型 App"},
+		{"directive", `{ app(name: "juno") { id @defer } }`, nil, "不支持指令 @defer"},
+		{"field error", `{ app(name: "juno") { instances { broken } } }`, nil, "broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := execute(t, tt.query, tt.variables)
+			if len(errs) == 0 {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(errs[0].Message, tt.want) {
+				t.Errorf("got %q, want %q", errs[0].Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldErrorPath(t *testing.T) {
+	resp := testSchema().Execute(context.Background(), Request{Query: `{ app(name: "juno") { instances { env broken } } }`})
+	if len(resp.Errors) != 2 {
+		t.Fatalf("got %d errors, want 2", len(resp.Errors))
+	}
+	path, _ := json.Marshal(resp.Errors[1].Path)
+	if string(path) != `["app","instances",1,"broken"]` {
+		t.Errorf("unexpected path %s", path)
+	}
+	if loc := resp.Errors[0].Locations[0]; loc.Line != 1 || loc.Column != 39 {
+		t.Errorf("unexpected location %+v", loc)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	if want := `{"app":{"instances":[{"env":"dev","broken":null},{"env":"prod","broken":null}]}}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	query := `query A { app(name: "juno") { id } } query B { apps(first: 1) { app_name } }`
+	resp := testSchema().Execute(context.Background(), Request{Query: query})
+	if len(resp.Errors) == 0 {
+		t.Fatal("expected error without operationName")
+	}
+
+	resp = testSchema().Execute(context.Background(), Request{Query: query, OperationName: "B"})
+	data, _ := json.Marshal(resp.Data)
+	if string(data) != `{"apps":[{"app_name":"a"}]}` {
+		t.Errorf("got %s", data)
+	}
+}
+
+func TestSDL(t *testing.T) {
+	sdl := testSchema().SDL()
+	for _, want := range []string{
+		"type Query {\n  app(name: String!): App\n  apps(first: Int = 20, langs: [String]): [App]\n}",
+		"type App {",
+		"  instances(env: String): [Instance]",
+		"type Instance {",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("SDL missing %q:\n%s", want, sdl)
+		}
+	}
+	if strings.Index(sdl, "type App") > strings.Index(sdl, "type Instance") {
+		t.Error("types should be sorted after Query")
+	}
+}
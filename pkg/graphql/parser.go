@@ -0,0 +1,700 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	// document 解析后的查询文档
+	document struct {
+		operations []*operation
+		fragments  map[string]*fragment
+	}
+
+	operation struct {
+		kind         string // query、mutation、subscription
+		name         string
+		variables    []*variableDefinition
+		selectionSet []selection
+	}
+
+	variableDefinition struct {
+		name         string
+		typ          typeRef
+		defaultValue value
+	}
+
+	// typeRef 变量声明的类型，如 [Int!]!
+	typeRef struct {
+		name    string
+		list    *typeRef
+		nonNull bool
+	}
+
+	fragment struct {
+		name          string
+		typeCondition string
+		selectionSet  []selection
+	}
+
+	// selection 为 *field、*fragmentSpread、*inlineFragment 之一
+	selection interface{}
+
+	field struct {
+		alias        string
+		name         string
+		arguments    []*argument
+		directives   []*directive
+		selectionSet []selection
+		loc          Location
+	}
+
+	argument struct {
+		name  string
+		value value
+	}
+
+	directive struct {
+		name      string
+		arguments []*argument
+	}
+
+	fragmentSpread struct {
+		name       string
+		directives []*directive
+		loc        Location
+	}
+
+	inlineFragment struct {
+		typeCondition string
+		directives    []*directive
+		selectionSet  []selection
+	}
+
+	// value 为 nil、int64、float64、string、bool、enumValue、variable、[]value、[]objectField 之一
+	value interface{}
+
+	enumValue string
+	variable  string
+
+	objectField struct {
+		name  string
+		value value
+	}
+)
+
+// Location 错误在查询中的位置，行列从 1 开始
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+const (
+	tokenEOF = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  int
+	value string
+	loc   Location
+}
+
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+func (l *lexer) errorf(loc Location, format string, args ...interface{}) error {
+	return &Error{Message: "语法错误: " + fmt.Sprintf(format, args...), Locations: []Location{loc}}
+}
+
+func (l *lexer) advance(n int) {
+	for i := 0; i < n; i++ {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.advance(1)
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.advance(1)
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (tok token, err error) {
+	l.skipIgnored()
+	tok.loc = Location{Line: l.line, Column: l.col}
+	if l.pos >= len(l.src) {
+		tok.kind = tokenEOF
+		return
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		tok.kind, tok.value = tokenPunct, "..."
+		l.advance(3)
+	case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+		tok.kind, tok.value = tokenPunct, string(c)
+		l.advance(1)
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.advance(1)
+		}
+		tok.kind, tok.value = tokenName, l.src[start:l.pos]
+	case c == '-' || isDigit(c):
+		return l.number(tok)
+	case c == '"':
+		return l.string(tok)
+	default:
+		r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+		err = l.errorf(tok.loc, "无法识别的字符 %q", r)
+	}
+	return
+}
+
+func (l *lexer) number(tok token) (token, error) {
+	start := l.pos
+	tok.kind = tokenInt
+	if l.src[l.pos] == '-' {
+		l.advance(1)
+	}
+	digits := func() int {
+		n := 0
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.advance(1)
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return tok, l.errorf(tok.loc, "无效的数字")
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		tok.kind = tokenFloat
+		l.advance(1)
+		if digits() == 0 {
+			return tok, l.errorf(tok.loc, "无效的数字")
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		tok.kind = tokenFloat
+		l.advance(1)
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.advance(1)
+		}
+		if digits() == 0 {
+			return tok, l.errorf(tok.loc, "无效的数字")
+		}
+	}
+	tok.value = l.src[start:l.pos]
+	return tok, nil
+}
+
+func (l *lexer) string(tok token) (token, error) {
+	tok.kind = tokenString
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		l.advance(3)
+		end := strings.Index(l.src[l.pos:], `"""`)
+		if end < 0 {
+			return tok, l.errorf(tok.loc, "字符串未结束")
+		}
+		tok.value = l.src[l.pos : l.pos+end]
+		l.advance(end + 3)
+		return tok, nil
+	}
+
+	l.advance(1)
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			return tok, l.errorf(tok.loc, "字符串未结束")
+		}
+		c := l.src[l.pos]
+		if c == '"' {
+			l.advance(1)
+			break
+		}
+		if c != '\\' {
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			b.WriteRune(r)
+			l.pos += size
+			l.col++
+			continue
+		}
+
+		if l.pos+1 >= len(l.src) {
+			return tok, l.errorf(tok.loc, "字符串未结束")
+		}
+		switch esc := l.src[l.pos+1]; esc {
+		case '"', '\\', '/':
+			b.WriteByte(esc)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if l.pos+6 > len(l.src) {
+				return tok, l.errorf(tok.loc, "无效的转义字符")
+			}
+			code, err := strconv.ParseUint(l.src[l.pos+2:l.pos+6], 16, 32)
+			if err != nil {
+				return tok, l.errorf(tok.loc, "无效的转义字符")
+			}
+			b.WriteRune(rune(code))
+			l.advance(4)
+		default:
+			return tok, l.errorf(tok.loc, "无效的转义字符 \\%c", esc)
+		}
+		l.advance(2)
+	}
+	tok.value = b.String()
+	return tok, nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+// parse 解析查询文档，支持操作、变量、片段和指令，不支持类型定义
+func parse(src string) (doc *document, err error) {
+	p := &parser{lexer: &lexer{src: src, line: 1, col: 1}}
+	if err = p.advance(); err != nil {
+		return
+	}
+
+	doc = &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenName, "fragment"):
+			var f *fragment
+			if f, err = p.parseFragment(); err != nil {
+				return
+			}
+			if _, ok := doc.fragments[f.name]; ok {
+				return nil, p.errorf("片段 %s 重复定义", f.name)
+			}
+			doc.fragments[f.name] = f
+		case p.peek(tokenPunct, "{"), p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			var op *operation
+			if op, err = p.parseOperation(); err != nil {
+				return
+			}
+			doc.operations = append(doc.operations, op)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, &Error{Message: "查询中没有操作"}
+	}
+	return
+}
+
+func (p *parser) advance() (err error) {
+	p.tok, err = p.lexer.next()
+	return
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.lexer.errorf(p.tok.loc, format, args...)
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return p.errorf("查询意外结束")
+	}
+	return p.errorf("意外的 %s", p.tok.value)
+}
+
+func (p *parser) peek(kind int, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+func (p *parser) skip(kind int, value string) (bool, error) {
+	if !p.peek(kind, value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(kind int, value string) error {
+	if !p.peek(kind, value) {
+		if p.tok.kind == tokenEOF {
+			return p.errorf("缺少 %s", value)
+		}
+		return p.errorf("期望 %s，实际为 %s", value, p.tok.value)
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (name string, err error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name = p.tok.value
+	err = p.advance()
+	return
+}
+
+func (p *parser) parseOperation() (op *operation, err error) {
+	op = &operation{kind: "query"}
+	if p.tok.kind == tokenName {
+		op.kind = p.tok.value
+		if err = p.advance(); err != nil {
+			return
+		}
+		if p.tok.kind == tokenName {
+			if op.name, err = p.name(); err != nil {
+				return
+			}
+		}
+		if p.peek(tokenPunct, "(") {
+			if op.variables, err = p.parseVariableDefinitions(); err != nil {
+				return
+			}
+		}
+		if _, err = p.parseDirectives(); err != nil {
+			return
+		}
+	}
+	op.selectionSet, err = p.parseSelectionSet()
+	return
+}
+
+func (p *parser) parseVariableDefinitions() (defs []*variableDefinition, err error) {
+	if err = p.expect(tokenPunct, "("); err != nil {
+		return
+	}
+	for !p.peek(tokenPunct, ")") {
+		def := &variableDefinition{}
+		if err = p.expect(tokenPunct, "$"); err != nil {
+			return
+		}
+		if def.name, err = p.name(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunct, ":"); err != nil {
+			return
+		}
+		if def.typ, err = p.parseTypeRef(); err != nil {
+			return
+		}
+		var ok bool
+		if ok, err = p.skip(tokenPunct, "="); err != nil {
+			return
+		}
+		if ok {
+			if def.defaultValue, err = p.parseValue(true); err != nil {
+				return
+			}
+		}
+		defs = append(defs, def)
+	}
+	err = p.advance()
+	return
+}
+
+func (p *parser) parseTypeRef() (ref typeRef, err error) {
+	var ok bool
+	if ok, err = p.skip(tokenPunct, "["); err != nil {
+		return
+	}
+	if ok {
+		var of typeRef
+		if of, err = p.parseTypeRef(); err != nil {
+			return
+		}
+		ref.list = &of
+		if err = p.expect(tokenPunct, "]"); err != nil {
+			return
+		}
+	} else if ref.name, err = p.name(); err != nil {
+		return
+	}
+	ref.nonNull, err = p.skip(tokenPunct, "!")
+	return
+}
+
+func (p *parser) parseFragment() (f *fragment, err error) {
+	f = &fragment{}
+	if err = p.advance(); err != nil {
+		return
+	}
+	if f.name, err = p.name(); err != nil {
+		return
+	}
+	if f.name == "on" {
+		return nil, p.errorf("片段名不能为 on")
+	}
+	if err = p.expect(tokenName, "on"); err != nil {
+		return
+	}
+	if f.typeCondition, err = p.name(); err != nil {
+		return
+	}
+	if _, err = p.parseDirectives(); err != nil {
+		return
+	}
+	f.selectionSet, err = p.parseSelectionSet()
+	return
+}
+
+func (p *parser) parseSelectionSet() (set []selection, err error) {
+	if err = p.expect(tokenPunct, "{"); err != nil {
+		return
+	}
+	for !p.peek(tokenPunct, "}") {
+		var s selection
+		if p.peek(tokenPunct, "...") {
+			s, err = p.parseFragmentSelection()
+		} else {
+			s, err = p.parseField()
+		}
+		if err != nil {
+			return
+		}
+		set = append(set, s)
+	}
+	if len(set) == 0 {
+		return nil, p.errorf("选择集不能为空")
+	}
+	err = p.advance()
+	return
+}
+
+func (p *parser) parseFragmentSelection() (s selection, err error) {
+	loc := p.tok.loc
+	if err = p.advance(); err != nil {
+		return
+	}
+
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &fragmentSpread{loc: loc}
+		if spread.name, err = p.name(); err != nil {
+			return
+		}
+		spread.directives, err = p.parseDirectives()
+		return spread, err
+	}
+
+	inline := &inlineFragment{}
+	var ok bool
+	if ok, err = p.skip(tokenName, "on"); err != nil {
+		return
+	}
+	if ok {
+		if inline.typeCondition, err = p.name(); err != nil {
+			return
+		}
+	}
+	if inline.directives, err = p.parseDirectives(); err != nil {
+		return
+	}
+	inline.selectionSet, err = p.parseSelectionSet()
+	return inline, err
+}
+
+func (p *parser) parseField() (f *field, err error) {
+	f = &field{loc: p.tok.loc}
+	if f.name, err = p.name(); err != nil {
+		return
+	}
+	var ok bool
+	if ok, err = p.skip(tokenPunct, ":"); err != nil {
+		return
+	}
+	if ok {
+		f.alias = f.name
+		if f.name, err = p.name(); err != nil {
+			return
+		}
+	}
+	if p.peek(tokenPunct, "(") {
+		if f.arguments, err = p.parseArguments(); err != nil {
+			return
+		}
+	}
+	if f.directives, err = p.parseDirectives(); err != nil {
+		return
+	}
+	if p.peek(tokenPunct, "{") {
+		f.selectionSet, err = p.parseSelectionSet()
+	}
+	return
+}
+
+func (p *parser) parseArguments() (args []*argument, err error) {
+	if err = p.expect(tokenPunct, "("); err != nil {
+		return
+	}
+	for !p.peek(tokenPunct, ")") {
+		arg := &argument{}
+		if arg.name, err = p.name(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunct, ":"); err != nil {
+			return
+		}
+		if arg.value, err = p.parseValue(false); err != nil {
+			return
+		}
+		args = append(args, arg)
+	}
+	err = p.advance()
+	return
+}
+
+func (p *parser) parseDirectives() (directives []*directive, err error) {
+	for p.peek(tokenPunct, "@") {
+		if err = p.advance(); err != nil {
+			return
+		}
+		d := &directive{}
+		if d.name, err = p.name(); err != nil {
+			return
+		}
+		if p.peek(tokenPunct, "(") {
+			if d.arguments, err = p.parseArguments(); err != nil {
+				return
+			}
+		}
+		directives = append(directives, d)
+	}
+	return
+}
+
+// parseValue constant 为 true 时不允许使用变量，用于变量默认值
+func (p *parser) parseValue(constant bool) (v value, err error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		var n int64
+		if n, err = strconv.ParseInt(tok.value, 10, 64); err != nil {
+			return nil, p.errorf("整数 %s 超出范围", tok.value)
+		}
+		v = n
+	case tokenFloat:
+		var f float64
+		if f, err = strconv.ParseFloat(tok.value, 64); err != nil {
+			return nil, p.errorf("无效的数字 %s", tok.value)
+		}
+		v = f
+	case tokenString:
+		v = tok.value
+	case tokenName:
+		switch tok.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = enumValue(tok.value)
+		}
+	case tokenPunct:
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.errorf("默认值中不能使用变量")
+			}
+			if err = p.advance(); err != nil {
+				return
+			}
+			var name string
+			if name, err = p.name(); err != nil {
+				return
+			}
+			return variable(name), nil
+		case "[":
+			return p.parseList(constant)
+		case "{":
+			return p.parseObject(constant)
+		default:
+			return nil, p.unexpected()
+		}
+	default:
+		return nil, p.unexpected()
+	}
+	err = p.advance()
+	return
+}
+
+func (p *parser) parseList(constant bool) (v value, err error) {
+	if err = p.advance(); err != nil {
+		return
+	}
+	list := make([]value, 0)
+	for !p.peek(tokenPunct, "]") {
+		var item value
+		if item, err = p.parseValue(constant); err != nil {
+			return
+		}
+		list = append(list, item)
+	}
+	return list, p.advance()
+}
+
+func (p *parser) parseObject(constant bool) (v value, err error) {
+	if err = p.advance(); err != nil {
+		return
+	}
+	fields := make([]objectField, 0)
+	for !p.peek(tokenPunct, "}") {
+		var f objectField
+		if f.name, err = p.name(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunct, ":"); err != nil {
+			return
+		}
+		if f.value, err = p.parseValue(constant); err != nil {
+			return
+		}
+		fields = append(fields, f)
+	}
+	return fields, p.advance()
+}
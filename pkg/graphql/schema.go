@@ -0,0 +1,243 @@
+// Package graphql 只读 GraphQL 查询的解析与执行，支持变量、别名、片段以及 @include、@skip 指令，不支持 mutation、subscription 和内省查询
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	// Type 为 *Scalar、*Object、*List 之一
+	Type interface {
+		String() string
+	}
+
+	// Scalar 标量类型，值按 encoding/json 的规则输出
+	Scalar struct {
+		Name string
+		// coerce 将参数、变量转为 Go 类型
+		coerce func(v interface{}) (interface{}, bool)
+	}
+
+	Object struct {
+		Name        string
+		Description string
+		Fields      Fields
+	}
+
+	List struct {
+		Of Type
+	}
+
+	Fields map[string]*Field
+
+	Field struct {
+		Type        Type
+		Description string
+		Args        Args
+		// Resolve 为空时按字段名从 Source 中取值：map 取同名 key，结构体取 json 标签或忽略大小写、下划线后同名的字段
+		Resolve ResolveFunc
+	}
+
+	Args map[string]*Arg
+
+	Arg struct {
+		Type        Type
+		Required    bool
+		Default     interface{}
+		Description string
+	}
+
+	ResolveFunc func(p ResolveParams) (interface{}, error)
+
+	ResolveParams struct {
+		Context context.Context
+		// Source 父字段解析的值，根查询的字段为 nil
+		Source interface{}
+		// Args 参数值，Int 为 int，Float 为 float64，String、ID 为 string，List 为 []interface{}，未传且无默认值的参数不存在
+		Args map[string]interface{}
+	}
+
+	Schema struct {
+		Query *Object
+		// MaxDepth 查询允许的最大嵌套层数，默认 10
+		MaxDepth int
+	}
+)
+
+// 内置标量类型
+var (
+	Int = &Scalar{Name: "Int", coerce: func(v interface{}) (interface{}, bool) {
+		switch n := v.(type) {
+		case int64:
+			return int(n), int64(int(n)) == n
+		case float64:
+			return int(n), float64(int(n)) == n
+		case int:
+			return n, true
+		}
+		return nil, false
+	}}
+	Float = &Scalar{Name: "Float", coerce: func(v interface{}) (interface{}, bool) {
+		switch n := v.(type) {
+		case int64:
+			return float64(n), true
+		case float64:
+			return n, true
+		case int:
+			return float64(n), true
+		}
+		return nil, false
+	}}
+	String = &Scalar{Name: "String", coerce: func(v interface{}) (interface{}, bool) {
+		s, ok := v.(string)
+		return s, ok
+	}}
+	Boolean = &Scalar{Name: "Boolean", coerce: func(v interface{}) (interface{}, bool) {
+		b, ok := v.(bool)
+		return b, ok
+	}}
+	// ID 输出为 JSON 原始值，参数接受字符串或整数，统一转为字符串
+	ID = &Scalar{Name: "ID", coerce: func(v interface{}) (interface{}, bool) {
+		switch id := v.(type) {
+		case string:
+			return id, true
+		case int64:
+			return fmt.Sprint(id), true
+		case int:
+			return fmt.Sprint(id), true
+		case float64:
+			return fmt.Sprint(int64(id)), float64(int64(id)) == id
+		}
+		return nil, false
+	}}
+)
+
+func (s *Scalar) String() string { return s.Name }
+func (o *Object) String() string { return o.Name }
+func (l *List) String() string   { return "[" + l.Of.String() + "]" }
+
+// ListOf 列表类型
+func ListOf(t Type) *List {
+	return &List{Of: t}
+}
+
+// Error 查询错误，Path 为出错字段在结果中的路径
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Request 查询请求，GET 请求中 variables 为 JSON 字符串
+type Request struct {
+	Query         string                 `json:"query" query:"query"`
+	OperationName string                 `json:"operationName" query:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response 查询结果，语法、校验错误时 Data 为空；字段解析出错时该字段为 null，错误记录在 Errors 中
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// SDL 以 GraphQL SDL 描述 Schema，供调用方了解可查询的字段
+func (s *Schema) SDL() string {
+	objects := make(map[string]*Object)
+	var collect func(t Type)
+	collect = func(t Type) {
+		switch t := t.(type) {
+		case *List:
+			collect(t.Of)
+		case *Object:
+			if _, ok := objects[t.Name]; ok {
+				return
+			}
+			objects[t.Name] = t
+			for _, f := range t.Fields {
+				collect(f.Type)
+			}
+		}
+	}
+	collect(s.Query)
+
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		if name != s.Query.Name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{s.Query.Name}, names...)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		o := objects[name]
+		writeDescription(&b, "", o.Description)
+		b.WriteString("type " + o.Name + " {\n")
+		for _, fieldName := range sortedKeys(o.Fields) {
+			f := o.Fields[fieldName]
+			writeDescription(&b, "  ", f.Description)
+			b.WriteString("  " + fieldName)
+			if len(f.Args) > 0 {
+				args := make([]string, 0, len(f.Args))
+				for _, argName := range sortedArgs(f.Args) {
+					arg := f.Args[argName]
+					def := argName + ": " + arg.Type.String()
+					if arg.Required {
+						def += "!"
+					}
+					if arg.Default != nil {
+						def += fmt.Sprintf(" = %v", formatDefault(arg.Default))
+					}
+					args = append(args, def)
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		b.WriteString(indent + `"""` + description + `"""` + "\n")
+	}
+}
+
+func formatDefault(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedArgs(args Args) []string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,202 @@
+// Package health 存活、就绪探针。/healthz 只表示进程存活，/readyz 检查依赖，
+// 关键依赖不可用时返回 503，供负载均衡和 k8s 探针摘除实例
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultTimeout 单个依赖检查的超时时间
+const DefaultTimeout = 3 * time.Second
+
+const (
+	StatusUp       Status = "up"
+	StatusDown     Status = "down"
+	StatusDegraded Status = "degraded" // 非关键依赖不可用，仍可以对外服务
+)
+
+type (
+	Status string
+
+	// CheckFunc 返回 nil 表示依赖可用
+	CheckFunc func(ctx context.Context) error
+
+	// Checker 依赖检查集合
+	Checker struct {
+		timeout time.Duration
+		checks  []check
+	}
+
+	check struct {
+		name     string
+		critical bool
+		fn       CheckFunc
+	}
+
+	Report struct {
+		Status Status            `json:"status"`
+		Checks map[string]Result `json:"checks"`
+	}
+
+	Result struct {
+		Status   Status `json:"status"`
+		Critical bool   `json:"critical"`
+		Latency  int64  `json:"latency_ms"`
+		Error    string `json:"error,omitempty"`
+	}
+)
+
+// New timeout <= 0 时使用 DefaultTimeout
+func New(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Critical 关键依赖，不可用时实例未就绪
+func (h *Checker) Critical(name string, fn CheckFunc) *Checker {
+	h.checks = append(h.checks, check{name: name, critical: true, fn: fn})
+	return h
+}
+
+// Optional 非关键依赖，不可用时只标记为 degraded
+func (h *Checker) Optional(name string, fn CheckFunc) *Checker {
+	h.checks = append(h.checks, check{name: name, fn: fn})
+	return h
+}
+
+// Run 并发执行全部检查
+func (h *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(h.checks))}
+	results := make([]Result, len(h.checks))
+
+	var wg sync.WaitGroup
+	for i := range h.checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = h.run(ctx, h.checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, c := range h.checks {
+		result := results[i]
+		report.Checks[c.name] = result
+		if result.Status == StatusUp {
+			continue
+		}
+		if c.critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func (h *Checker) run(ctx context.Context, c check) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		result.Critical = c.critical
+		result.Latency = time.Since(start).Milliseconds()
+	}()
+
+	// 检查函数未响应超时时不等待其返回
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		return Result{Status: StatusDown, Error: err.Error()}
+	}
+	return Result{Status: StatusUp}
+}
+
+// Register 注册 GET /healthz、/readyz
+func (h *Checker) Register(e *echo.Echo) {
+	e.GET("/healthz", Livez)
+	e.GET("/readyz", h.Readyz)
+}
+
+// Livez 进程存活即返回 200，不检查依赖，避免依赖故障时实例被反复重启
+func Livez(c echo.Context) error {
+	return c.JSON(http.StatusOK, Report{Status: StatusUp, Checks: map[string]Result{}})
+}
+
+// Readyz 关键依赖不可用时返回 503
+func (h *Checker) Readyz(c echo.Context) error {
+	report := h.Run(c.Request().Context())
+	status := http.StatusOK
+	if report.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
+
+// SQL 检查数据库连接
+func SQL(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		if db == nil {
+			return fmt.Errorf("database not initialized")
+		}
+		return db.PingContext(ctx)
+	}
+}
+
+// Etcd 读取 health 键检查集群是否可用，与 etcdctl endpoint health 的做法一致
+func Etcd(client *clientv3.Client) CheckFunc {
+	return func(ctx context.Context) error {
+		if client == nil {
+			return fmt.Errorf("etcd client not initialized")
+		}
+		_, err := client.Get(ctx, "health", clientv3.WithCountOnly())
+		return err
+	}
+}
+
+// HTTP 请求 url，返回 2xx 时可用
+func HTTP(client *http.Client, url string) CheckFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func ok(ctx context.Context) error { return nil }
+
+func fail(ctx context.Context) error { return errors.New("connection refused") }
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		name    string
+		checker *Checker
+		status  Status
+	}{
+		{"all up", New(0).Critical("mysql", ok).Optional("etcd", ok), StatusUp},
+		{"optional down", New(0).Critical("mysql", ok).Optional("etcd", fail), StatusDegraded},
+		{"critical down", New(0).Critical("mysql", fail).Optional("etcd", fail), StatusDown},
+		{"no checks", New(0), StatusUp},
+	}
+	for _, c := range cases {
+		report := c.checker.Run(context.Background())
+		if report.Status != c.status {
+			t.Errorf("%s: status = %s, want %s", c.name, report.Status, c.status)
+		}
+	}
+}
+
+func TestRunTimeoutAndPanic(t *testing.T) {
+	block := func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+	panics := func(ctx context.Context) error {
+		panic("nil client")
+	}
+
+	start := time.Now()
+	report := New(50*time.Millisecond).Critical("slow", block).Optional("panic", panics).Run(context.Background())
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("Run should not wait for a check past its timeout")
+	}
+	if report.Status != StatusDown {
+		t.Errorf("status = %s, want %s", report.Status, StatusDown)
+	}
+	if r := report.Checks["slow"]; r.Status != StatusDown || r.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("slow = %+v", r)
+	}
+	if r := report.Checks["panic"]; r.Status != StatusDown || r.Critical || r.Error != "panic: nil client" {
+		t.Errorf("panic = %+v", r)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	cases := []struct {
+		path    string
+		checker *Checker
+		status  int
+	}{
+		{"/healthz", New(0).Critical("mysql", fail), http.StatusOK},
+		{"/readyz", New(0).Critical("mysql", fail), http.StatusServiceUnavailable},
+		{"/readyz", New(0).Critical("mysql", ok).Optional("etcd", fail), http.StatusOK},
+	}
+	for _, c := range cases {
+		e := echo.New()
+		c.checker.Register(e)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.path, nil))
+		if rec.Code != c.status {
+			t.Errorf("GET %s = %d, want %d", c.path, rec.Code, c.status)
+		}
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	if err := HTTP(nil, srv.URL+"/healthz")(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if err := HTTP(nil, srv.URL+"/readyz")(context.Background()); err == nil {
+		t.Error("non-2xx response should fail the check")
+	}
+}
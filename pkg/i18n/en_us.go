@@ -0,0 +1,97 @@
+package i18n
+
+// en-US 译文，key 为 zh-CN 原文。新增面向用户的提示时在这里补充译文
+func init() {
+	Register(EnUS, map[string]string{
+		// 错误码默认提示
+		"操作失败":           "Operation failed",
+		"请先登录":           "Please log in first",
+		"请完成两步验证":        "Two-factor verification is required",
+		"请先开启两步验证":       "Please enable two-factor authentication first",
+		"密码已过期，请修改密码后登录": "Your password has expired, please change it and log in again",
+		"第三方登录失败":        "Third-party login failed",
+		"请求参数错误":         "Invalid request parameters",
+		"资源不存在":          "Resource not found",
+		"资源状态冲突":         "Resource conflict, please refresh and retry",
+		"没有权限":           "Permission denied",
+		"开放平台认证失败":       "Open API authentication failed",
+		"请求过于频繁，请稍后再试":   "Too many requests, please try again later",
+		"服务内部错误，请稍后再试":   "Internal server error, please try again later",
+		"任务队列为空":         "Task queue is empty",
+
+		// service 层登记的错误
+		"新密码不能与最近使用过的密码相同":         "The new password must not match a recently used password",
+		"退订链接无效或已过期":               "The unsubscribe link is invalid or has expired",
+		"验证码错误":                    "Invalid verification code",
+		"已开启两步验证":                  "Two-factor authentication is already enabled",
+		"用户名已被其他账号使用，请联系管理员":       "The username is used by another account, please contact an administrator",
+		"账号已停用，请联系管理员":             "The account is disabled, please contact an administrator",
+		"密码已过期，请修改密码":              "Your password has expired, please change it",
+		"当前角色必须开启两步验证":             "Your role requires two-factor authentication",
+		"会话不存在":                    "Session not found",
+		"团队不存在":                    "Team not found",
+		"权限申请不存在":                  "Access request not found",
+		"只有应用所属团队的 owner 或管理员可以审批": "Only owners of the app's team or administrators can review",
+		"备份中包含加密的凭证，需要指定备份密钥":      "The backup contains encrypted credentials, a backup key is required",
+		"当前用户没有该机房的访问权限":           "You don't have access to this zone",
+		"只有管理员可以导出备份":              "Only administrators can export backups",
+		"测试平台功能未启用，请联系管理员":         "The test platform is disabled, please contact an administrator",
+		"不支持的语言":                   "Unsupported language",
+		"未选择发布实例或集群":               "No instance or cluster selected to publish",
+		"无法获取授权信息":                 "Unable to get authorization info",
+		"回收站中不存在该对象":               "The item is not in the recycle bin",
+		"已超过保留期，不能恢复":              "The retention period has passed, the item can't be restored",
+		"只能恢复自己删除的对象，其他对象请联系管理员":   "You can only restore items deleted by yourself, please contact an administrator for others",
+		"只有管理员可以彻底删除":              "Only administrators can permanently delete items",
+		"已存在同名配置":                  "A config with the same name already exists",
+		"制品超过大小限制":                 "The artifact exceeds the size limit",
+		"只能对比同一流水线的任务":             "Only tasks of the same pipeline can be compared",
+		"任务已被同一提交的新任务取代":           "The task has been superseded by a newer task of the same commit",
+		"无效的提交 SHA":                "Invalid commit SHA",
+		"任务已取消":                    "The task has been canceled",
+		"任务已结束":                    "The task has already finished",
+		"Go 版本格式错误，应为 1.16.15 的形式": "Invalid Go version, expect a form like 1.16.15",
+		"交叉编译目标平台格式错误，应为 linux/amd64,darwin/arm64 的形式": "Invalid cross build targets, expect a comma separated list like linux/amd64,darwin/arm64",
+		"基准测试参数格式错误，包不能以 - 开头，参数必须以 - 开头":              "Invalid benchmark arguments, packages must not start with - and flags must start with -",
+		"制品路径格式错误，应为代码目录下的相对路径，支持 * ? [] 和匹配多级目录的 **":  "Invalid artifact path, expect a path relative to the code directory, * ? [] and ** for nested directories are supported",
+		"声明制品的阶段不存在":                                 "The step declaring artifacts does not exist",
+		"制品名称格式错误，应为不包含 .. 的相对路径":                    "Invalid artifact name, expect a relative path without ..",
+		"制品文件不存在":                                    "The artifact file does not exist",
+		"未配置 testplatform.secretKey，不能保存流水线密钥":       "testplatform.secretKey is not configured, pipeline secrets can't be saved",
+		"密钥名格式错误，只能包含字母、数字和下划线，且不能以数字开头":             "Invalid secret name, only letters, digits and underscores are allowed and it can't start with a digit",
+		"密钥值不能为空，且不能超过 4096 字节":                      "The secret value must not be empty or longer than 4096 bytes",
+		"流水线引用的密钥不存在":                                "A secret referenced by the pipeline does not exist",
+		"环境变量名格式错误，只能包含字母、数字和下划线，且不能以数字开头":           "Invalid environment variable name, only letters, digits and underscores are allowed and it can't start with a digit",
+		"HOME、PATH、GOPATH 等环境变量由 worker 设置，不能在阶段中修改": "Environment variables such as HOME, PATH and GOPATH are set by the worker and can't be changed in a step",
+		"环境变量值过长，或 ${{ }} 中不是 secrets.NAME 形式的密钥引用":  "The environment variable value is too long, or ${{ }} contains something other than a secrets.NAME reference",
+		"设置环境变量的阶段不存在":                               "The step setting environment variables does not exist",
+		"每个阶段最多设置 50 个环境变量":                          "At most 50 environment variables can be set per step",
+		"阶段执行条件格式错误，应为 branch、event、env 与字符串比较，如 branch == \"master\" && event != \"merge_request\"": "Invalid step condition, expect branch, event or env compared with strings, like branch == \"master\" && event != \"merge_request\"",
+		"设置执行条件的阶段不存在":                                          "The step setting a condition does not exist",
+		"触发事件只能是 manual、push、merge_request、tag_push 或 schedule": "The trigger event must be one of manual, push, merge_request, tag_push and schedule",
+
+		// 通知
+		"成功":                       "succeeded",
+		"失败":                       "failed",
+		"[Juno] 应用 %s 流水线 %s 执行%s": "[Juno] Pipeline %[2]s of app %[1]s %[3]s",
+		"[Juno] 应用 %s 流水线 %s 执行%s\n环境: %s/%s\n分支: %s\n任务ID: %d": "[Juno] Pipeline %[2]s of app %[1]s %[3]s\nEnv: %[4]s/%[5]s\nBranch: %[6]s\nTask ID: %[7]d",
+		"[Juno] 应用 %s 配置 %s 已发布":                                "[Juno] Config %[2]s of app %[1]s published",
+		"[Juno] 应用 %s 配置 %s 已发布\n环境: %s/%s\n版本: %s\n操作人: %s":    "[Juno] Config %[2]s of app %[1]s published\nEnv: %[3]s/%[4]s\nVersion: %[5]s\nOperator: %[6]s",
+
+		// 页面使用的枚举
+		"流水线":  "Pipeline",
+		"告警":   "Alert",
+		"审批":   "Approval",
+		"配置发布": "Config publish",
+		"汇总":   "Digest",
+		"提示":   "Info",
+		"警告":   "Warning",
+		"错误":   "Error",
+		"钉钉":   "DingTalk",
+		"企业微信": "WeCom",
+		"飞书":   "Feishu",
+		"邮件":   "Email",
+		"等待中":  "Pending",
+		"执行中":  "Running",
+	})
+}
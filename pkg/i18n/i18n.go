@@ -0,0 +1,164 @@
+// Package i18n 面向用户的文本翻译。
+// 以 zh-CN 原文作为 key，其他语言的目录登记原文对应的译文，未登记的文本原样返回，
+// 因此现有的中文提示不需要改动即可逐步补充翻译
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+
+	// Default 默认语言，也是原文的语言
+	Default = ZhCN
+)
+
+// ContextKey 请求语言在 echo.Context 中的 key
+const ContextKey = "juno_language"
+
+var (
+	catalogsMtx sync.RWMutex
+	catalogs    = map[string]map[string]string{
+		ZhCN: {},
+	}
+)
+
+// Register 登记 lang 的译文，key 为 zh-CN 原文。只应在 init 中调用
+func Register(lang string, messages map[string]string) {
+	catalogsMtx.Lock()
+	defer catalogsMtx.Unlock()
+
+	catalog, ok := catalogs[lang]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		catalogs[lang] = catalog
+	}
+	for key, value := range messages {
+		catalog[key] = value
+	}
+}
+
+// Supported 支持的语言列表
+func Supported() []string {
+	catalogsMtx.RLock()
+	defer catalogsMtx.RUnlock()
+
+	list := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		list = append(list, lang)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Messages lang 的全部译文，供前端翻译接口返回的文本
+func Messages(lang string) map[string]string {
+	catalogsMtx.RLock()
+	defer catalogsMtx.RUnlock()
+
+	messages := make(map[string]string, len(catalogs[lang]))
+	for key, value := range catalogs[lang] {
+		messages[key] = value
+	}
+	return messages
+}
+
+// T 翻译原文 text，没有译文时返回原文
+func T(lang, text string) string {
+	if lang == "" || lang == Default {
+		return text
+	}
+
+	catalogsMtx.RLock()
+	defer catalogsMtx.RUnlock()
+	if translated, ok := catalogs[lang][text]; ok {
+		return translated
+	}
+	return text
+}
+
+// Tf 翻译格式化字符串后格式化，参数中的 Text 也会被翻译
+func Tf(lang, format string, args ...interface{}) string {
+	translated := make([]interface{}, len(args))
+	for i, arg := range args {
+		if text, ok := arg.(Text); ok {
+			arg = T(lang, string(text))
+		}
+		translated[i] = arg
+	}
+	return fmt.Sprintf(T(lang, format), translated...)
+}
+
+// Text 作为 Tf、Message 参数时需要翻译的原文，如 "成功"、"失败"
+type Text string
+
+// Message 延迟翻译的文本，用于在确定接收方语言之前构造的消息，如通知
+type Message struct {
+	Format string
+	Args   []interface{}
+}
+
+// Msg 创建延迟翻译的文本，format 为 zh-CN 原文
+func Msg(format string, args ...interface{}) Message {
+	return Message{Format: format, Args: args}
+}
+
+// String 翻译为 lang
+func (m Message) String(lang string) string {
+	return Tf(lang, m.Format, m.Args...)
+}
+
+// Normalize 规范化语言标识，如 zh、zh_cn 转为 zh-CN，不支持的语言返回 false
+func Normalize(lang string) (string, bool) {
+	lang = strings.ToLower(strings.TrimSpace(strings.Replace(lang, "_", "-", -1)))
+	if lang == "" {
+		return "", false
+	}
+
+	catalogsMtx.RLock()
+	defer catalogsMtx.RUnlock()
+	for supported := range catalogs {
+		if strings.ToLower(supported) == lang {
+			return supported, true
+		}
+	}
+	// 地区不支持时按语种匹配，如 zh、zh-TW 使用 zh-CN，en、en-GB 使用 en-US
+	primary := strings.SplitN(lang, "-", 2)[0]
+	for _, supported := range []string{ZhCN, EnUS} {
+		if _, ok := catalogs[supported]; ok && strings.HasPrefix(strings.ToLower(supported), primary+"-") {
+			return supported, true
+		}
+	}
+	return "", false
+}
+
+// ParseAcceptLanguage 按 Accept-Language 的权重选择支持的语言，都不支持时返回 Default
+func ParseAcceptLanguage(header string) string {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, q := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			tag = part[:i]
+			params := strings.TrimSpace(part[i+1:])
+			if strings.HasPrefix(params, "q=") {
+				v, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+				if err != nil {
+					continue
+				}
+				q = v
+			}
+		}
+		lang, ok := Normalize(tag)
+		if ok && q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,60 @@
+package i18n
+
+import "testing"
+
+func TestT(t *testing.T) {
+	if got := T(EnUS, "请先登录"); got != "Please log in first" {
+		t.Errorf("T = %q", got)
+	}
+	if got := T(ZhCN, "请先登录"); got != "请先登录" {
+		t.Errorf("zh-CN should return source text, got %q", got)
+	}
+	if got := T(EnUS, "未登记的文本"); got != "未登记的文本" {
+		t.Errorf("missing translation should fall back to source text, got %q", got)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	m := Msg("[Juno] 应用 %s 流水线 %s 执行%s", "juno-admin", "unit", Text("失败"))
+	if got := m.String(EnUS); got != "[Juno] Pipeline unit of app juno-admin failed" {
+		t.Errorf("en-US = %q", got)
+	}
+	if got := m.String(ZhCN); got != "[Juno] 应用 juno-admin 流水线 unit 执行失败" {
+		t.Errorf("zh-CN = %q", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"zh-CN": ZhCN,
+		"zh_cn": ZhCN,
+		"zh":    ZhCN,
+		"zh-TW": ZhCN,
+		"en":    EnUS,
+		"EN-gb": EnUS,
+		"fr":    "",
+		"":      "",
+	}
+	for in, want := range cases {
+		got, ok := Normalize(in)
+		if got != want || ok != (want != "") {
+			t.Errorf("Normalize(%q) = %q, %v", in, got, ok)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":                               Default,
+		"fr-FR,fr;q=0.9":                 Default,
+		"en-US,en;q=0.9,zh-CN;q=0.8":     EnUS,
+		"zh-CN,zh;q=0.9,en;q=0.8":        ZhCN,
+		"fr;q=0.9, en;q=0.5, zh;q=0.4":   EnUS,
+		"en;q=0.3, zh-TW;q=0.6, *;q=0.1": ZhCN,
+	}
+	for in, want := range cases {
+		if got := ParseAcceptLanguage(in); got != want {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
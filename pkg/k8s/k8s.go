@@ -0,0 +1,349 @@
+package k8s
+
+// Kubernetes API 文档
+// https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.19/
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 工作负载类型
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+)
+
+type (
+	// Config 集群 API Server 访问配置，使用 ServiceAccount Token 或客户端证书认证
+	Config struct {
+		Server     string
+		Token      string
+		CAData     string // API Server 证书的 CA，PEM 格式，为空时使用系统 CA
+		ClientCert string // 客户端证书，PEM 格式
+		ClientKey  string // 客户端证书私钥，PEM 格式
+		Insecure   bool   // 跳过证书校验
+		Timeout    time.Duration
+	}
+
+	// Client 只读的 Kubernetes API 客户端，只包含工作负载、Pod 查询
+	Client struct {
+		server string
+		token  string
+		client *http.Client
+	}
+
+	ObjectMeta struct {
+		Name              string            `json:"name"`
+		Namespace         string            `json:"namespace"`
+		Labels            map[string]string `json:"labels"`
+		CreationTimestamp time.Time         `json:"creationTimestamp"`
+		DeletionTimestamp *time.Time        `json:"deletionTimestamp"`
+	}
+
+	LabelSelector struct {
+		MatchLabels map[string]string `json:"matchLabels"`
+	}
+
+	Container struct {
+		Name  string `json:"name"`
+		Image string `json:"image"`
+	}
+
+	// Workload Deployment、StatefulSet 的公共字段
+	Workload struct {
+		Kind     string     `json:"kind"`
+		Metadata ObjectMeta `json:"metadata"`
+		Spec     struct {
+			Replicas *int32        `json:"replicas"`
+			Selector LabelSelector `json:"selector"`
+			Template struct {
+				Spec struct {
+					Containers []Container `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+		Status struct {
+			Replicas        int32 `json:"replicas"`
+			ReadyReplicas   int32 `json:"readyReplicas"`
+			UpdatedReplicas int32 `json:"updatedReplicas"`
+		} `json:"status"`
+	}
+
+	ContainerState struct {
+		Waiting *struct {
+			Reason string `json:"reason"`
+		} `json:"waiting"`
+		Terminated *struct {
+			Reason string `json:"reason"`
+		} `json:"terminated"`
+	}
+
+	ContainerStatus struct {
+		Name         string         `json:"name"`
+		Ready        bool           `json:"ready"`
+		RestartCount int32          `json:"restartCount"`
+		Image        string         `json:"image"`
+		State        ContainerState `json:"state"`
+	}
+
+	Pod struct {
+		Metadata ObjectMeta `json:"metadata"`
+		Spec     struct {
+			NodeName   string      `json:"nodeName"`
+			Containers []Container `json:"containers"`
+		} `json:"spec"`
+		Status struct {
+			Phase             string            `json:"phase"`
+			Reason            string            `json:"reason"`
+			PodIP             string            `json:"podIP"`
+			HostIP            string            `json:"hostIP"`
+			StartTime         *time.Time        `json:"startTime"`
+			ContainerStatuses []ContainerStatus `json:"containerStatuses"`
+		} `json:"status"`
+	}
+
+	workloadList struct {
+		Items []Workload `json:"items"`
+	}
+
+	podList struct {
+		Items []Pod `json:"items"`
+	}
+
+	// apiStatus API Server 返回的错误信息
+	apiStatus struct {
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+	}
+)
+
+// NewClient 创建客户端
+func NewClient(conf Config) (*Client, error) {
+	if conf.Server == "" {
+		return nil, fmt.Errorf("k8s api server is empty")
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = 5 * time.Second
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: conf.Insecure}
+	if conf.CAData != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(conf.CAData)) {
+			return nil, fmt.Errorf("invalid k8s ca data")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if conf.ClientCert != "" || conf.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(conf.ClientCert), []byte(conf.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid k8s client certificate: %s", err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &Client{
+		server: strings.TrimSuffix(conf.Server, "/"),
+		token:  conf.Token,
+		client: &http.Client{
+			Timeout:   conf.Timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+		},
+	}, nil
+}
+
+// ListDeployments 命名空间下匹配 selector 的 Deployment
+func (c *Client) ListDeployments(namespace, selector string) ([]Workload, error) {
+	return c.listWorkloads(KindDeployment, "/apis/apps/v1/namespaces/"+url.PathEscape(namespace)+"/deployments", selector)
+}
+
+// ListStatefulSets 命名空间下匹配 selector 的 StatefulSet
+func (c *Client) ListStatefulSets(namespace, selector string) ([]Workload, error) {
+	return c.listWorkloads(KindStatefulSet, "/apis/apps/v1/namespaces/"+url.PathEscape(namespace)+"/statefulsets", selector)
+}
+
+// ListPods 命名空间下匹配 selector 的 Pod，按名称排序
+func (c *Client) ListPods(namespace, selector string) ([]Pod, error) {
+	var list podList
+	err := c.get("/api/v1/namespaces/"+url.PathEscape(namespace)+"/pods", selector, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Metadata.Name < list.Items[j].Metadata.Name
+	})
+	return list.Items, nil
+}
+
+// ServerVersion API Server 版本
+func (c *Client) ServerVersion() (string, error) {
+	var info struct {
+		GitVersion string `json:"gitVersion"`
+	}
+	err := c.get("/version", "", &info)
+	return info.GitVersion, err
+}
+
+// Ping 检查凭证能否读取命名空间下的 Pod，用于连通性检查
+func (c *Client) Ping(namespace string) error {
+	var list podList
+	return c.get("/api/v1/namespaces/"+url.PathEscape(namespace)+"/pods?limit=1", "", &list)
+}
+
+func (c *Client) listWorkloads(kind, path, selector string) ([]Workload, error) {
+	var list workloadList
+	err := c.get(path, selector, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	// 列表接口返回的条目不带 kind
+	for i := range list.Items {
+		list.Items[i].Kind = kind
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Metadata.Name < list.Items[j].Metadata.Name
+	})
+	return list.Items, nil
+}
+
+func (c *Client) get(path, selector string, out interface{}) error {
+	addr := c.server + path
+	if selector != "" {
+		addr += "?labelSelector=" + url.QueryEscape(selector)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, addr, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var status apiStatus
+		if json.Unmarshal(body, &status) == nil && status.Message != "" {
+			return fmt.Errorf("k8s api %s: %s", resp.Status, status.Message)
+		}
+		return fmt.Errorf("k8s api %s", resp.Status)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// Selector 将标签转换为 labelSelector 参数，按标签名排序
+func Selector(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// DesiredReplicas 期望副本数，未设置时为 1
+func (w Workload) DesiredReplicas() int32 {
+	if w.Spec.Replicas == nil {
+		return 1
+	}
+	return *w.Spec.Replicas
+}
+
+// DisplayStatus 与 kubectl get pods 一致的状态：删除中显示 Terminating，容器等待或异常退出时显示原因，否则显示 Phase
+func (p Pod) DisplayStatus() string {
+	if p.Metadata.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	for _, status := range p.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			return status.State.Waiting.Reason
+		}
+		if status.State.Terminated != nil && status.State.Terminated.Reason != "" {
+			return status.State.Terminated.Reason
+		}
+	}
+	if p.Status.Reason != "" {
+		return p.Status.Reason
+	}
+	return p.Status.Phase
+}
+
+// Restarts 所有容器的重启次数之和
+func (p Pod) Restarts() (restarts int32) {
+	for _, status := range p.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	return
+}
+
+// Ready 就绪的容器数、容器总数
+func (p Pod) Ready() (ready, total int) {
+	total = len(p.Spec.Containers)
+	for _, status := range p.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+	}
+	return
+}
+
+// ImageTags 各容器镜像的 tag，去重后按容器顺序返回
+func (p Pod) ImageTags() []string {
+	tags := make([]string, 0, len(p.Spec.Containers))
+	for _, container := range p.Spec.Containers {
+		tag := ImageTag(container.Image)
+		exists := false
+		for _, item := range tags {
+			if item == tag {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ImageTag 镜像 tag，使用 digest 时返回 digest，未指定时为 latest
+func ImageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	// 仓库地址可能带端口，只取最后一段路径中的 tag
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return "latest"
+}
@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImageTag(t *testing.T) {
+	cases := map[string]string{
+		"nginx":                                "latest",
+		"nginx:1.19":                           "1.19",
+		"registry.example.com:5000/juno/admin": "latest",
+		"registry.example.com:5000/juno/admin:v1": "v1",
+		"juno/admin@sha256:abcd":                  "sha256:abcd",
+	}
+	for image, want := range cases {
+		if got := ImageTag(image); got != want {
+			t.Errorf("ImageTag(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestSelector(t *testing.T) {
+	got := Selector(map[string]string{"app": "juno", "env": "prod"})
+	if got != "app=juno,env=prod" {
+		t.Errorf("Selector = %q", got)
+	}
+}
+
+func TestPodStatus(t *testing.T) {
+	var pod Pod
+	pod.Status.Phase = "Running"
+	pod.Spec.Containers = []Container{{Name: "app", Image: "juno/admin:v1"}, {Name: "sidecar", Image: "envoy:v1"}}
+	pod.Status.ContainerStatuses = []ContainerStatus{{Name: "app", Ready: true, RestartCount: 2}, {Name: "sidecar", RestartCount: 1}}
+	if pod.DisplayStatus() != "Running" || pod.Restarts() != 3 {
+		t.Errorf("status = %s, restarts = %d", pod.DisplayStatus(), pod.Restarts())
+	}
+	if ready, total := pod.Ready(); ready != 1 || total != 2 {
+		t.Errorf("ready = %d/%d", ready, total)
+	}
+	if tags := pod.ImageTags(); !reflect.DeepEqual(tags, []string{"v1"}) {
+		t.Errorf("image tags = %v", tags)
+	}
+
+	pod.Status.ContainerStatuses[1].State.Waiting = &struct {
+		Reason string `json:"reason"`
+	}{Reason: "CrashLoopBackOff"}
+	if pod.DisplayStatus() != "CrashLoopBackOff" {
+		t.Errorf("waiting status = %s", pod.DisplayStatus())
+	}
+
+	now := time.Now()
+	pod.Metadata.DeletionTimestamp = &now
+	if pod.DisplayStatus() != "Terminating" {
+		t.Errorf("deleting status = %s", pod.DisplayStatus())
+	}
+}
+
+func TestClientList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"kind":"Status","message":"Unauthorized"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/default/deployments":
+			if r.URL.Query().Get("labelSelector") != "app=juno" {
+				t.Errorf("labelSelector = %q", r.URL.Query().Get("labelSelector"))
+			}
+			_, _ = w.Write([]byte(`{"items":[{"metadata":{"name":"juno-b"},"spec":{"selector":{"matchLabels":{"app":"juno"}}}},{"metadata":{"name":"juno-a"},"spec":{"replicas":3}}]}`))
+		case "/api/v1/namespaces/default/pods":
+			_, _ = w.Write([]byte(`{"items":[{"metadata":{"name":"juno-a-1"},"spec":{"nodeName":"node1"},"status":{"phase":"Pending"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Server: server.URL + "/", Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workloads, err := client.ListDeployments("default", "app=juno")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workloads) != 2 || workloads[0].Metadata.Name != "juno-a" || workloads[0].Kind != KindDeployment {
+		t.Fatalf("workloads = %+v", workloads)
+	}
+	if workloads[0].DesiredReplicas() != 3 || workloads[1].DesiredReplicas() != 1 {
+		t.Errorf("replicas = %d, %d", workloads[0].DesiredReplicas(), workloads[1].DesiredReplicas())
+	}
+
+	pods, err := client.ListPods("default", "app=juno")
+	if err != nil || len(pods) != 1 || pods[0].Spec.NodeName != "node1" || pods[0].DisplayStatus() != "Pending" {
+		t.Errorf("pods = %+v, err = %v", pods, err)
+	}
+
+	if err = client.Ping("default"); err != nil {
+		t.Errorf("ping err = %v", err)
+	}
+
+	client, _ = NewClient(Config{Server: server.URL})
+	if _, err = client.ListPods("default", ""); err == nil || err.Error() != "k8s api 401 Unauthorized: Unauthorized" {
+		t.Errorf("unauthorized err = %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	kubeconfig struct {
+		CurrentContext string `yaml:"current-context"`
+		Clusters       []struct {
+			Name    string `yaml:"name"`
+			Cluster struct {
+				Server                   string `yaml:"server"`
+				CertificateAuthorityData string `yaml:"certificate-authority-data"`
+				InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+			} `yaml:"cluster"`
+		} `yaml:"clusters"`
+		Contexts []struct {
+			Name    string `yaml:"name"`
+			Context struct {
+				Cluster   string `yaml:"cluster"`
+				User      string `yaml:"user"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"context"`
+		} `yaml:"contexts"`
+		Users []struct {
+			Name string `yaml:"name"`
+			User struct {
+				Token                 string      `yaml:"token"`
+				ClientCertificateData string      `yaml:"client-certificate-data"`
+				ClientKeyData         string      `yaml:"client-key-data"`
+				Exec                  interface{} `yaml:"exec"`
+				AuthProvider          interface{} `yaml:"auth-provider"`
+			} `yaml:"user"`
+		} `yaml:"users"`
+	}
+)
+
+// ParseKubeconfig 解析 kubeconfig 中 current-context 对应的集群和用户，返回访问配置和上下文的命名空间。
+// 证书、Token 需要内联在文件中，不支持引用本地文件和 exec、auth-provider 插件
+func ParseKubeconfig(data []byte) (conf Config, namespace string, err error) {
+	var kc kubeconfig
+	err = yaml.Unmarshal(data, &kc)
+	if err != nil {
+		return conf, "", fmt.Errorf("invalid kubeconfig: %s", err.Error())
+	}
+
+	contextName := kc.CurrentContext
+	if contextName == "" && len(kc.Contexts) == 1 {
+		contextName = kc.Contexts[0].Name
+	}
+	var clusterName, userName string
+	found := false
+	for _, item := range kc.Contexts {
+		if item.Name == contextName {
+			clusterName, userName, namespace = item.Context.Cluster, item.Context.User, item.Context.Namespace
+			found = true
+			break
+		}
+	}
+	if !found {
+		return conf, "", fmt.Errorf("kubeconfig context %q not found", contextName)
+	}
+
+	found = false
+	for _, item := range kc.Clusters {
+		if item.Name != clusterName {
+			continue
+		}
+		found = true
+		conf.Server = item.Cluster.Server
+		conf.Insecure = item.Cluster.InsecureSkipTLSVerify
+		if item.Cluster.CertificateAuthorityData != "" {
+			conf.CAData, err = decodeKubeconfigData(item.Cluster.CertificateAuthorityData)
+			if err != nil {
+				return conf, "", fmt.Errorf("invalid certificate-authority-data: %s", err.Error())
+			}
+		}
+		break
+	}
+	if !found {
+		return conf, "", fmt.Errorf("kubeconfig cluster %q not found", clusterName)
+	}
+	if conf.Server == "" {
+		return conf, "", fmt.Errorf("kubeconfig cluster %q has no server", clusterName)
+	}
+
+	found = false
+	for _, item := range kc.Users {
+		if item.Name != userName {
+			continue
+		}
+		found = true
+		if item.User.Exec != nil || item.User.AuthProvider != nil {
+			return conf, "", fmt.Errorf("kubeconfig user %q uses exec or auth-provider, which is not supported", userName)
+		}
+		conf.Token = item.User.Token
+		if item.User.ClientCertificateData != "" {
+			conf.ClientCert, err = decodeKubeconfigData(item.User.ClientCertificateData)
+			if err != nil {
+				return conf, "", fmt.Errorf("invalid client-certificate-data: %s", err.Error())
+			}
+		}
+		if item.User.ClientKeyData != "" {
+			conf.ClientKey, err = decodeKubeconfigData(item.User.ClientKeyData)
+			if err != nil {
+				return conf, "", fmt.Errorf("invalid client-key-data: %s", err.Error())
+			}
+		}
+		break
+	}
+	if !found {
+		return conf, "", fmt.Errorf("kubeconfig user %q not found", userName)
+	}
+	if conf.Token == "" && conf.ClientCert == "" {
+		return conf, "", fmt.Errorf("kubeconfig user %q has no token or client certificate", userName)
+	}
+	return conf, namespace, nil
+}
+
+func decodeKubeconfigData(data string) (string, error) {
+	buf, err := base64.StdEncoding.DecodeString(data)
+	return string(buf), err
+}
@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+)
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+    certificate-authority-data: Y2EtZGF0YQ==
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+- name: prod
+  context:
+    cluster: prod
+    user: juno
+    namespace: apps
+users:
+- name: dev
+  user:
+    exec:
+      command: aws
+- name: juno
+  user:
+    token: sa-token
+`
+
+func TestParseKubeconfig(t *testing.T) {
+	conf, namespace, err := ParseKubeconfig([]byte(testKubeconfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Server != "https://prod.example.com:6443" || conf.CAData != "ca-data" || conf.Token != "sa-token" || namespace != "apps" {
+		t.Errorf("conf = %+v, namespace = %s", conf, namespace)
+	}
+
+	_, _, err = ParseKubeconfig([]byte(strings.Replace(testKubeconfig, "current-context: prod", "current-context: dev", 1)))
+	if err == nil || err.Error() != `kubeconfig user "dev" uses exec or auth-provider, which is not supported` {
+		t.Errorf("exec err = %v", err)
+	}
+
+	_, _, err = ParseKubeconfig([]byte("current-context: none\n"))
+	if err == nil || err.Error() != `kubeconfig context "none" not found` {
+		t.Errorf("context err = %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+// Package metrics juno-admin 自身的监控指标，通过主服务的 /metrics 暴露给 Prometheus。
+// 使用独立的 registry，不包含 jupiter 按来源 IP 统计的指标，jupiter 的指标仍由 governor 端口暴露
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "juno"
+
+const (
+	ResultOK     = "ok"
+	ResultFailed = "failed"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	noticeSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notice_send_total",
+		Help:      "Notifications sent by channel and result.",
+	}, []string{"channel", "result"})
+
+	testQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "test_queue_depth",
+		Help:      "Test tasks waiting for a worker by zone.",
+	}, []string{"zone"})
+
+	testQueueWait = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "test_queue_oldest_wait_seconds",
+		Help:      "Wait time of the oldest queued test task by zone.",
+	}, []string{"zone"})
+
+	testTasksRunning = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "test_tasks_running",
+		Help:      "Running test tasks by zone.",
+	}, []string{"zone"})
+
+	testWorkers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "test_workers",
+		Help:      "Online test workers by zone and state (active, drained).",
+	}, []string{"zone", "state"})
+
+	queues = &queueCollector{
+		desc:    prometheus.NewDesc(namespace+"_queue_length", "Items waiting in in-process queues.", []string{"queue"}, nil),
+		lengths: make(map[string]func() int),
+	}
+)
+
+func init() {
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		noticeSent,
+		testQueueDepth,
+		testQueueWait,
+		testTasksRunning,
+		testWorkers,
+		queues,
+	)
+}
+
+// Handler /metrics 接口
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTP 记录一次 HTTP 请求，route 为路由模板，不能使用实际请求路径
+func ObserveHTTP(method, route string, code int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveNotice 记录一次通知发送结果
+func ObserveNotice(channel string, err error) {
+	result := ResultOK
+	if err != nil {
+		result = ResultFailed
+	}
+	noticeSent.WithLabelValues(channel, result).Inc()
+}
+
+// TestQueue 机房的测试任务队列和 worker 数
+type TestQueue struct {
+	Zone           string
+	Depth          int
+	OldestWait     time.Duration
+	Running        int
+	Workers        int
+	DrainedWorkers int
+}
+
+// SetTestQueues 更新各机房的测试任务队列指标，不在 queues 中的机房不再上报
+func SetTestQueues(queues []TestQueue) {
+	testQueueDepth.Reset()
+	testQueueWait.Reset()
+	testTasksRunning.Reset()
+	testWorkers.Reset()
+	for _, q := range queues {
+		testQueueDepth.WithLabelValues(q.Zone).Set(float64(q.Depth))
+		testQueueWait.WithLabelValues(q.Zone).Set(q.OldestWait.Seconds())
+		testTasksRunning.WithLabelValues(q.Zone).Set(float64(q.Running))
+		testWorkers.WithLabelValues(q.Zone, "active").Set(float64(q.Workers))
+		testWorkers.WithLabelValues(q.Zone, "drained").Set(float64(q.DrainedWorkers))
+	}
+}
+
+// RegisterQueue 注册进程内队列，采集时调用 length 获取队列长度，同名队列后注册的生效
+func RegisterQueue(name string, length func() int) {
+	queues.mtx.Lock()
+	defer queues.mtx.Unlock()
+	queues.lengths[name] = length
+}
+
+// RegisterDB 注册数据库连接池，采集时读取 sql.DBStats
+func RegisterDB(name string, db *sql.DB) error {
+	return registry.Register(newDBStatsCollector(name, db))
+}
+
+type queueCollector struct {
+	desc *prometheus.Desc
+
+	mtx     sync.RWMutex
+	lengths map[string]func() int
+}
+
+func (q *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- q.desc
+}
+
+func (q *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	q.mtx.RLock()
+	defer q.mtx.RUnlock()
+	for name, length := range q.lengths {
+		ch <- prometheus.MustNewConstMetric(q.desc, prometheus.GaugeValue, float64(length()), name)
+	}
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveHTTP("GET", "/api/admin/user/list", 200, 10*time.Millisecond)
+	ObserveNotice("ding", errors.New("timeout"))
+	RegisterQueue("test", func() int { return 3 })
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		`juno_http_requests_total{code="200",method="GET",route="/api/admin/user/list"} 1`,
+		`juno_http_request_duration_seconds_count{method="GET",route="/api/admin/user/list"} 1`,
+		`juno_notice_send_total{channel="ding",result="failed"} 1`,
+		`juno_queue_length{queue="test"} 3`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	AccessRequestStatusPending  = "pending"
+	AccessRequestStatusApproved = "approved"
+	AccessRequestStatusDenied   = "denied"
+	AccessRequestStatusCanceled = "canceled"
+	AccessRequestStatusRevoked  = "revoked"
+	AccessRequestStatusExpired  = "expired"
+)
+
+// AccessRequest 用户申请应用环境权限，审批通过后在有效期内直接授予申请人
+type AccessRequest struct {
+	gorm.Model
+	Uid           int        `gorm:"column:uid;index" json:"uid"`
+	AppName       string     `gorm:"column:app_name;type:varchar(128);index" json:"app_name"`
+	Env           string     `gorm:"column:env;type:varchar(64)" json:"env"`
+	Actions       string     `gorm:"column:actions;type:varchar(512)" json:"-"` // 申请的应用权限，逗号分隔
+	Reason        string     `gorm:"column:reason;type:varchar(512)" json:"reason"`
+	Status        string     `gorm:"column:status;type:varchar(16);index" json:"status"`
+	ReviewerUid   int        `gorm:"column:reviewer_uid" json:"reviewer_uid"`
+	ReviewComment string     `gorm:"column:review_comment;type:varchar(512)" json:"review_comment"`
+	ReviewedAt    *time.Time `gorm:"column:reviewed_at" json:"reviewed_at"`
+	ExpiresAt     *time.Time `gorm:"column:expires_at;index" json:"expires_at"` // 权限过期时间
+}
+
+func (AccessRequest) TableName() string {
+	return "access_request"
+}
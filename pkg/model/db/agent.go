@@ -0,0 +1,99 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+type (
+	// AgentConfig 下发给 juno-agent 的运行时配置
+	// HostName 为空时对 Env+ZoneCode 下的全部 agent 生效，否则只对单台 agent 生效，并覆盖分组配置。
+	// Content 为编辑中的配置，发布时复制到 PublishedContent，下发给 agent 的只有已发布的配置
+	AgentConfig struct {
+		gorm.Model
+		Env              string             `gorm:"column:env;type:varchar(32)"`
+		ZoneCode         string             `gorm:"column:zone_code;type:varchar(64)"`
+		HostName         string             `gorm:"column:host_name"`
+		Content          AgentConfigContent `gorm:"column:content;type:json"`
+		Version          uint               `gorm:"column:version"`
+		PublishedContent AgentConfigContent `gorm:"column:published_content;type:json"`
+		PublishedVersion uint               `gorm:"column:published_version"`
+		Revision         uint               `gorm:"column:revision"` // 最近一次发布或删除时的全局序号
+		CreatedBy        uint               `gorm:"column:created_by"`
+		UpdatedBy        uint               `gorm:"column:updated_by"`
+	}
+
+	// AgentConfigContent agent 可热更新的配置项，字段为空表示不覆盖
+	AgentConfigContent struct {
+		HeartbeatInterval *int            `json:"heartbeat_interval,omitempty"` // 心跳间隔，单位秒
+		PollInterval      *int            `json:"poll_interval,omitempty"`      // 配置拉取间隔，单位秒
+		Features          map[string]bool `json:"features,omitempty"`           // 功能开关
+		LogPaths          []string        `json:"log_paths,omitempty"`          // 日志采集路径
+		DownloadLimit     *int64          `json:"download_limit,omitempty"`     // agent 下载配置、升级包的带宽，单位 KB/s，0 表示不限速
+		UploadLimit       *int64          `json:"upload_limit,omitempty"`       // agent 上传日志、profile 的带宽，单位 KB/s，0 表示不限速
+	}
+)
+
+func (AgentConfig) TableName() string {
+	return "agent_config"
+}
+
+func (c *AgentConfigContent) Scan(val interface{}) error {
+	*c = AgentConfigContent{}
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return fmt.Errorf("unsupported agent config content type %T", val)
+	}
+}
+
+func (c AgentConfigContent) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Merge 用 override 中非空的字段覆盖当前配置，返回新的配置
+func (c AgentConfigContent) Merge(override AgentConfigContent) AgentConfigContent {
+	ret := c
+	if override.HeartbeatInterval != nil {
+		ret.HeartbeatInterval = override.HeartbeatInterval
+	}
+	if override.PollInterval != nil {
+		ret.PollInterval = override.PollInterval
+	}
+	if len(override.Features) > 0 {
+		features := make(map[string]bool, len(c.Features)+len(override.Features))
+		for name, enable := range c.Features {
+			features[name] = enable
+		}
+		for name, enable := range override.Features {
+			features[name] = enable
+		}
+		ret.Features = features
+	}
+	if override.LogPaths != nil {
+		ret.LogPaths = override.LogPaths
+	}
+	if override.DownloadLimit != nil {
+		ret.DownloadLimit = override.DownloadLimit
+	}
+	if override.UploadLimit != nil {
+		ret.UploadLimit = override.UploadLimit
+	}
+	return ret
+}
+
+// DownloadBytesPerSec download_limit 换算为字节/秒，未配置时返回 0
+func (c AgentConfigContent) DownloadBytesPerSec() int64 {
+	if c.DownloadLimit == nil {
+		return 0
+	}
+	return *c.DownloadLimit * 1024
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// AgentOfflineEvent agent 一次离线记录，RecoverTime 为 0 表示仍处于离线状态
+type AgentOfflineEvent struct {
+	gorm.Model
+	HostName          string `gorm:"column:host_name;type:varchar(128);index"`
+	Env               string `gorm:"column:env"`
+	ZoneCode          string `gorm:"column:zone_code"`
+	LastHeartbeatTime int64  `gorm:"column:last_heartbeat_time"`
+	LastError         string `gorm:"column:last_error;type:varchar(1024)"`
+	OfflineTime       int64  `gorm:"column:offline_time;index"`
+	RecoverTime       int64  `gorm:"column:recover_time"`
+}
+
+func (AgentOfflineEvent) TableName() string {
+	return "agent_offline_event"
+}
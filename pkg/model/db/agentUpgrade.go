@@ -0,0 +1,106 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type (
+	// AgentPackage 上传的 agent 升级包
+	AgentPackage struct {
+		gorm.Model
+		Version   string `gorm:"column:version;type:varchar(64);unique_index"`
+		FileName  string `gorm:"column:file_name"`
+		Size      int64  `gorm:"column:size"`
+		Sha256    string `gorm:"column:sha256;type:varchar(64)"`
+		CreatedBy uint   `gorm:"column:created_by"`
+	}
+
+	// AgentUpgrade agent 滚动升级任务，按可用区顺序执行，每个可用区先升级金丝雀节点
+	AgentUpgrade struct {
+		gorm.Model
+		Version          string             `gorm:"column:version;type:varchar(64)"`
+		Zones            AgentUpgradeZones  `gorm:"column:zones;type:json"`
+		CanaryPercent    uint               `gorm:"column:canary_percent"`    // 每个可用区金丝雀节点比例
+		FailureThreshold uint               `gorm:"column:failure_threshold"` // 单批失败比例超过该值时自动暂停
+		ZoneIndex        int                `gorm:"column:zone_index"`        // 当前执行的可用区
+		Stage            AgentUpgradeStage  `gorm:"column:stage"`             // 当前执行的批次
+		Status           AgentUpgradeStatus `gorm:"column:status;type:varchar(32)"`
+		Reason           string             `gorm:"column:reason"` // 暂停或失败原因
+		CreatedBy        uint               `gorm:"column:created_by"`
+	}
+
+	// AgentUpgradeNode 单个节点的升级状态
+	AgentUpgradeNode struct {
+		gorm.Model
+		UpgradeID    uint                   `gorm:"column:upgrade_id;index"`
+		ZoneIndex    int                    `gorm:"column:zone_index"`
+		Stage        AgentUpgradeStage      `gorm:"column:stage"`
+		HostName     string                 `gorm:"column:host_name"`
+		FromVersion  string                 `gorm:"column:from_version"`
+		Status       AgentUpgradeNodeStatus `gorm:"column:status;type:varchar(32)"`
+		DispatchedAt *time.Time             `gorm:"column:dispatched_at"`
+		FinishedAt   *time.Time             `gorm:"column:finished_at"`
+	}
+
+	AgentUpgradeZone struct {
+		Env      string `json:"env"`
+		ZoneCode string `json:"zone_code"`
+	}
+
+	AgentUpgradeZones      []AgentUpgradeZone
+	AgentUpgradeStage      int
+	AgentUpgradeStatus     string
+	AgentUpgradeNodeStatus string
+)
+
+const (
+	AgentUpgradeStageCanary AgentUpgradeStage = 0 // 金丝雀批次
+	AgentUpgradeStageRest   AgentUpgradeStage = 1 // 可用区剩余节点
+
+	AgentUpgradeStatusRunning  AgentUpgradeStatus = "running"
+	AgentUpgradeStatusPaused   AgentUpgradeStatus = "paused"
+	AgentUpgradeStatusFinished AgentUpgradeStatus = "finished"
+	AgentUpgradeStatusCanceled AgentUpgradeStatus = "canceled"
+
+	AgentUpgradeNodeStatusDispatched AgentUpgradeNodeStatus = "dispatched"
+	AgentUpgradeNodeStatusSuccess    AgentUpgradeNodeStatus = "success"
+	AgentUpgradeNodeStatusFailed     AgentUpgradeNodeStatus = "failed"
+)
+
+func (AgentPackage) TableName() string {
+	return "agent_package"
+}
+
+func (AgentUpgrade) TableName() string {
+	return "agent_upgrade"
+}
+
+func (AgentUpgradeNode) TableName() string {
+	return "agent_upgrade_node"
+}
+
+func (z *AgentUpgradeZones) Scan(val interface{}) error {
+	*z = nil
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, z)
+	case string:
+		return json.Unmarshal([]byte(v), z)
+	default:
+		return fmt.Errorf("unsupported agent upgrade zones type %T", val)
+	}
+}
+
+func (z AgentUpgradeZones) Value() (driver.Value, error) {
+	if z == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(z)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAgentConfigContent_Merge(t *testing.T) {
+	interval := 30
+	override := 10
+
+	group := AgentConfigContent{
+		HeartbeatInterval: &interval,
+		Features:          map[string]bool{"pprof": true, "log": true},
+		LogPaths:          []string{"/home/www/logs"},
+	}
+	host := AgentConfigContent{
+		HeartbeatInterval: &override,
+		Features:          map[string]bool{"log": false},
+	}
+
+	ret := group.Merge(host)
+	if *ret.HeartbeatInterval != 10 {
+		t.Errorf("HeartbeatInterval = %d, want 10", *ret.HeartbeatInterval)
+	}
+	if ret.PollInterval != nil {
+		t.Errorf("PollInterval should not be set")
+	}
+	if !reflect.DeepEqual(ret.Features, map[string]bool{"pprof": true, "log": false}) {
+		t.Errorf("Features = %v", ret.Features)
+	}
+	if !reflect.DeepEqual(ret.LogPaths, []string{"/home/www/logs"}) {
+		t.Errorf("LogPaths = %v", ret.LogPaths)
+	}
+	// 合并不能修改原配置
+	if !group.Features["log"] {
+		t.Errorf("group features modified")
+	}
+}
+
+func TestAgentConfigContent_Scan(t *testing.T) {
+	var c AgentConfigContent
+	if err := c.Scan([]byte(`{"poll_interval":10}`)); err != nil || *c.PollInterval != 10 {
+		t.Fatalf("Scan []byte = %v, %+v", err, c)
+	}
+	if err := c.Scan(`{"log_paths":["/tmp"]}`); err != nil || c.PollInterval != nil || len(c.LogPaths) != 1 {
+		t.Fatalf("Scan string = %v, %+v", err, c)
+	}
+	if err := c.Scan(nil); err != nil || c.LogPaths != nil {
+		t.Fatalf("Scan nil = %v, %+v", err, c)
+	}
+	if err := c.Scan(1); err == nil {
+		t.Fatal("Scan int should fail")
+	}
+}
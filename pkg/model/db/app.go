@@ -7,6 +7,14 @@ import (
 	"github.com/douyu/juno/pkg/util"
 )
 
+// 应用生命周期状态
+const (
+	AppStatusActive     = "active"
+	AppStatusDeprecated = "deprecated" // 已废弃，仍可正常使用
+	AppStatusArchived   = "archived"   // 已归档，默认列表不展示，不能发布配置、创建流水线，关联资源会被清理
+	AppStatusDeleted    = "deleted"    // 待删除，关联资源清理后删除应用
+)
+
 // AppInfo ...
 type AppInfo struct {
 	Aid        int          `gorm:"not null;primary_key;AUTO_INCREMENT" json:"aid"`
@@ -28,6 +36,12 @@ type AppInfo struct {
 	WebURL     string       `gorm:"not null;" json:"web_url"`
 	ProtoDir   string       `gorm:"not null;" json:"proto_dir"`
 	GitURL     string       `gorm:"not null;" json:"git_url"`
+	TeamID     uint         `gorm:"not null;default:0;index;comment:'所属团队'" json:"team_id"`
+	Status     string       `gorm:"not null;default:'active';index;comment:'生命周期状态'" json:"status,omitempty"`
+	// Meta 自定义字段的值，存储在 app_meta_value 中
+	Meta map[string]string `gorm:"-" json:"meta,omitempty"`
+	// Tags 应用标签，存储在 entity_tag 中
+	Tags []string `gorm:"-" json:"tags,omitempty"`
 
 	AppNodes   []AppNode   `gorm:"foreignKey:Aid;association_foreignkey:Aid" json:"-"`
 	GrpcProtos []GrpcProto `gorm:"foreignKey:AppName;association_foreignkey:AppName" json:"-"`
@@ -38,6 +52,11 @@ func (AppInfo) TableName() string {
 	return "app"
 }
 
+// Writable 应用是否允许发布配置、创建流水线
+func (a AppInfo) Writable() bool {
+	return a.Status != AppStatusArchived && a.Status != AppStatusDeleted
+}
+
 // MD5String ...
 func (a *AppInfo) MD5String() string {
 	buf, _ := json.Marshal(a)
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// AppArchive 应用归档、删除记录。清理任务导出配置、定时任务后删除它们，并清除注册中心中发布的配置
+type AppArchive struct {
+	gorm.Model
+	Aid       int        `gorm:"column:aid;index" json:"aid"`
+	AppName   string     `gorm:"column:app_name;type:varchar(128);index" json:"app_name"`
+	Status    string     `gorm:"column:status;type:varchar(16)" json:"status"` // archived 或 deleted
+	Reason    string     `gorm:"column:reason;type:varchar(255)" json:"reason"`
+	Uid       int        `gorm:"column:uid" json:"uid"`
+	Configs   string     `gorm:"column:configs;type:longtext" json:"-"`   // 导出的配置，JSON
+	CronJobs  string     `gorm:"column:cron_jobs;type:longtext" json:"-"` // 导出的定时任务，JSON
+	CleanedAt *time.Time `gorm:"column:cleaned_at" json:"cleaned_at"`     // 为空时待清理
+	Error     string     `gorm:"column:error;type:text" json:"error"`     // 最近一次清理失败的原因
+}
+
+func (AppArchive) TableName() string {
+	return "app_archive"
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// 待导入应用状态
+const (
+	AppImportStatusPending  = "pending"
+	AppImportStatusImported = "imported"
+	AppImportStatusIgnored  = "ignored"
+)
+
+// AppImportCandidate 扫描 GitLab 分组发现的待导入应用，重新扫描时只更新项目信息，不改变导入状态
+type AppImportCandidate struct {
+	gorm.Model
+	Gid        int       `gorm:"column:gid;unique_index" json:"gid"` // GitLab 项目 ID
+	AppName    string    `gorm:"column:app_name;type:varchar(128);index" json:"app_name"`
+	Name       string    `gorm:"column:name;type:varchar(255)" json:"name"`
+	Module     string    `gorm:"column:module;type:varchar(255)" json:"module"` // go.mod 中的模块名
+	Jupiter    bool      `gorm:"column:jupiter" json:"jupiter"`                 // 是否依赖 Jupiter 框架
+	GroupPath  string    `gorm:"column:group_path;type:varchar(255);index" json:"group_path"`
+	GitURL     string    `gorm:"column:git_url;type:varchar(512)" json:"git_url"`
+	WebURL     string    `gorm:"column:web_url;type:varchar(512)" json:"web_url"`
+	Owners     string    `gorm:"column:owners;type:varchar(512)" json:"owners"`          // 匹配到 Juno 用户的提交者用户名，逗号分隔
+	Committers string    `gorm:"column:committers;type:varchar(1024)" json:"committers"` // 提交次数最多的提交者，逗号分隔
+	Status     string    `gorm:"column:status;type:varchar(16);index" json:"status"`
+	Aid        int       `gorm:"column:aid" json:"aid"` // 导入后的应用 ID
+	ScannedAt  time.Time `gorm:"column:scanned_at" json:"scanned_at"`
+}
+
+func (AppImportCandidate) TableName() string {
+	return "app_import_candidate"
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// 应用自定义字段类型
+const (
+	AppMetaTypeString = "string"
+	AppMetaTypeNumber = "number"
+	AppMetaTypeBool   = "bool"
+	AppMetaTypeEnum   = "enum"
+	AppMetaTypeURL    = "url"
+)
+
+// AppMetaField 管理员定义的应用自定义字段，如 tier、language、cost_center、runbook_url
+type AppMetaField struct {
+	gorm.Model
+	Name     string `gorm:"column:name;type:varchar(32);unique_index" json:"name"` // 字段标识，创建后不可修改
+	Title    string `gorm:"column:title;type:varchar(64)" json:"title"`
+	Type     string `gorm:"column:type;type:varchar(16)" json:"type"`
+	Options  string `gorm:"column:options;type:varchar(1024)" json:"options"` // enum 类型的可选值，逗号分隔
+	Required bool   `gorm:"column:required" json:"required"`
+	Regex    string `gorm:"column:regex;type:varchar(255)" json:"regex"` // 值需要匹配的正则，为空不校验
+	Sort     int    `gorm:"column:sort" json:"sort"`
+}
+
+func (AppMetaField) TableName() string {
+	return "app_meta_field"
+}
+
+// AppMetaValue 应用自定义字段的值
+type AppMetaValue struct {
+	gorm.Model
+	Aid   int    `gorm:"column:aid;unique_index:idx_aid_field" json:"aid"`
+	Field string `gorm:"column:field;type:varchar(32);unique_index:idx_aid_field;index:idx_field_value" json:"field"`
+	Value string `gorm:"column:value;type:varchar(255);index:idx_field_value" json:"value"`
+}
+
+func (AppMetaValue) TableName() string {
+	return "app_meta_value"
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	AppTransferStatusPending  = "pending"
+	AppTransferStatusAccepted = "accepted"
+	AppTransferStatusRejected = "rejected"
+	AppTransferStatusCanceled = "canceled"
+)
+
+// AppTransfer 应用归属转移，由当前团队 owner 或管理员发起，目标团队 owner 接受后生效
+type AppTransfer struct {
+	gorm.Model
+	AppName       string       `gorm:"column:app_name;type:varchar(128);index" json:"app_name"`
+	FromTeamID    uint         `gorm:"column:from_team_id" json:"from_team_id"`
+	ToTeamID      uint         `gorm:"column:to_team_id;index" json:"to_team_id"`
+	Users         UserNameJSON `gorm:"column:users;type:json" json:"users"` // 转移后的应用负责人，为空时使用目标团队 owner
+	Reason        string       `gorm:"column:reason;type:varchar(512)" json:"reason"`
+	Status        string       `gorm:"column:status;type:varchar(16);index" json:"status"`
+	Uid           int          `gorm:"column:uid;index" json:"uid"`
+	ReviewerUid   int          `gorm:"column:reviewer_uid" json:"reviewer_uid"`
+	ReviewComment string       `gorm:"column:review_comment;type:varchar(512)" json:"review_comment"`
+	ReviewedAt    *time.Time   `gorm:"column:reviewed_at" json:"reviewed_at"`
+}
+
+func (AppTransfer) TableName() string {
+	return "app_transfer"
+}
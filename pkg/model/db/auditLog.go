@@ -0,0 +1,41 @@
+package db
+
+import (
+	"time"
+)
+
+const (
+	AuditResourceConfig     = "config"
+	AuditResourcePipeline   = "pipeline"
+	AuditResourceUser       = "user"
+	AuditResourcePermission = "permission"
+	AuditResourceResource   = "resource"
+	AuditResourceSecurity   = "security" // 被安全策略拦截的请求，如 IP 白名单
+)
+
+// AuditLog 平台变更操作审计记录，记录所有非 GET 的 Admin API 调用
+type AuditLog struct {
+	ID         uint      `gorm:"primary_key" json:"id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	Uid        int       `gorm:"column:uid" json:"uid"`
+	UserName   string    `gorm:"column:user_name;type:varchar(64);index" json:"user_name"`
+	Method     string    `gorm:"column:method;type:varchar(16)" json:"method"`
+	Path       string    `gorm:"column:path;type:varchar(255);index" json:"path"`
+	Action     string    `gorm:"column:action;type:varchar(128)" json:"action"`           // 接口名称
+	Resource   string    `gorm:"column:resource;type:varchar(32);index" json:"resource"`  // 资源类型，config/pipeline/user等
+	ResourceID string    `gorm:"column:resource_id;type:varchar(255)" json:"resource_id"` // 资源标识
+	AppName    string    `gorm:"column:app_name;type:varchar(128);index" json:"app_name"` // 应用名
+	Env        string    `gorm:"column:env;type:varchar(64)" json:"env"`                  // 环境
+	Request    string    `gorm:"column:request;type:text" json:"request"`                 // 请求参数，敏感字段已脱敏
+	Before     string    `gorm:"column:before;type:text" json:"before"`                   // 变更前资源摘要
+	After      string    `gorm:"column:after;type:text" json:"after"`                     // 变更后资源摘要
+	Status     int       `gorm:"column:status" json:"status"`                             // HTTP 状态码
+	Code       int       `gorm:"column:code" json:"code"`                                 // 业务返回码
+	Message    string    `gorm:"column:message;type:varchar(512)" json:"message"`         // 业务返回信息
+	Latency    int64     `gorm:"column:latency" json:"latency"`                           // 毫秒
+	ClientIP   string    `gorm:"column:client_ip;type:varchar(64)" json:"client_ip"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
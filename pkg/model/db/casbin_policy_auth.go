@@ -37,10 +37,18 @@ var (
 	AppPermAppRead            = "app:read"
 	AppPermConfigRead         = "config:read"
 	AppPermConfigWrite        = "config:write"
+	AppPermConfigPublish      = "config:publish"
 	AppPermConfigReadInstance = "config:readInstance"
 	AppPermMonitorRead        = "monitor:read"
 	AppPermPProfRead          = "pprof:read"
 	AppPermPProfRun           = "pprof:run"
+	AppPermProcessRestart     = "process:restart"
+	AppPermPipelineRead       = "pipeline:read"
+	AppPermPipelineWrite      = "pipeline:write"
+	AppPermPipelineRun        = "pipeline:run"
+
+	// AppPermScopeAll 应用权限中应用名或环境为该值时，表示对所有应用或所有环境生效
+	AppPermScopeAll = "*"
 )
 
 func (c CasbinPolicyAuth) TableName() string {
@@ -22,6 +22,7 @@ var (
 	CasbinGroupTypeUser = "user"
 	CasbinGroupTypeApp  = "app"
 	CasbinGroupTypeMenu = "url"
+	CasbinGroupTypeTeam = "team"
 )
 
 func (c CasbinPolicyGroup) TableName() string {
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// CMDB 冲突状态
+const (
+	CmdbConflictStatusOpen     = "open"
+	CmdbConflictStatusResolved = "resolved"
+	CmdbConflictStatusIgnored  = "ignored"
+)
+
+// CmdbSyncRecord 每条 CMDB 记录最近一次同步到 Juno 的值，用来判断本地数据是否被修改过
+type CmdbSyncRecord struct {
+	gorm.Model
+	Source  string `gorm:"column:source;type:varchar(64);unique_index:idx_source_kind_key" json:"source"`
+	Kind    string `gorm:"column:kind;type:varchar(16);unique_index:idx_source_kind_key" json:"kind"`
+	Key     string `gorm:"column:record_key;type:varchar(255);unique_index:idx_source_kind_key" json:"key"`
+	Synced  string `gorm:"column:synced;type:text" json:"synced"`   // 最近一次同步的字段值，JSON
+	Ignored string `gorm:"column:ignored;type:text" json:"ignored"` // 选择保留本地数据时 CMDB 的字段值，CMDB 再次变更前不再报告冲突，JSON
+}
+
+func (CmdbSyncRecord) TableName() string {
+	return "cmdb_sync_record"
+}
+
+// CmdbConflict CMDB 数据与本地修改过的数据不一致，或 CMDB 中已删除，需要人工处理
+type CmdbConflict struct {
+	gorm.Model
+	Source string `gorm:"column:source;type:varchar(64);index" json:"source"`
+	Kind   string `gorm:"column:kind;type:varchar(16);index" json:"kind"`
+	Key    string `gorm:"column:record_key;type:varchar(255)" json:"key"`
+	Op     string `gorm:"column:op;type:varchar(16)" json:"op"`  // conflict 或 removed
+	Remote string `gorm:"column:remote;type:text" json:"remote"` // JSON
+	Local  string `gorm:"column:local;type:text" json:"local"`   // JSON
+	Diffs  string `gorm:"column:diffs;type:text" json:"diffs"`   // JSON
+	Status string `gorm:"column:status;type:varchar(16);index" json:"status"`
+	Uid    int    `gorm:"column:uid" json:"uid"` // 处理人
+}
+
+func (CmdbConflict) TableName() string {
+	return "cmdb_conflict"
+}
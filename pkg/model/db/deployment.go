@@ -0,0 +1,35 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// DeploymentSourceAgent agent 上报的二进制版本发生变化
+	DeploymentSourceAgent = "agent"
+	// DeploymentSourceAPI CI 调用开放接口上报
+	DeploymentSourceAPI = "api"
+)
+
+// Deployment 实例部署记录，每次实例的应用版本变化记录一条
+type Deployment struct {
+	gorm.Model
+	AppName        string    `gorm:"column:app_name;type:varchar(128);index:idx_app_host" json:"app_name"`
+	HostName       string    `gorm:"column:host_name;type:varchar(128);index:idx_app_host" json:"host_name"`
+	Env            string    `gorm:"column:env;type:varchar(32);index" json:"env"`
+	ZoneCode       string    `gorm:"column:zone_code;type:varchar(64)" json:"zone_code"`
+	Version        string    `gorm:"column:version;type:varchar(128)" json:"version"`
+	PrevVersion    string    `gorm:"column:prev_version;type:varchar(128)" json:"prev_version"`
+	Commit         string    `gorm:"column:commit;type:varchar(64)" json:"commit"`
+	Source         string    `gorm:"column:source;type:varchar(16)" json:"source"`
+	PipelineTaskID uint      `gorm:"column:pipeline_task_id" json:"pipeline_task_id"`           // Juno 流水线执行记录
+	PipelineURL    string    `gorm:"column:pipeline_url;type:varchar(512)" json:"pipeline_url"` // 外部 CI 的流水线执行链接
+	Operator       string    `gorm:"column:operator;type:varchar(64)" json:"operator"`
+	DeployedAt     time.Time `gorm:"column:deployed_at;index" json:"deployed_at"`
+}
+
+func (Deployment) TableName() string {
+	return "deployment"
+}
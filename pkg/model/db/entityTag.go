@@ -0,0 +1,24 @@
+package db
+
+import (
+	"time"
+)
+
+const (
+	TagEntityApp      = "app"      // EntityKey 为应用名
+	TagEntityNode     = "node"     // EntityKey 为节点 host_name
+	TagEntityPipeline = "pipeline" // EntityKey 为流水线 ID
+)
+
+// EntityTag 应用、节点、流水线上的自由标签，删除标签时直接删除记录
+type EntityTag struct {
+	ID         uint      `gorm:"primary_key" json:"id"`
+	EntityType string    `gorm:"column:entity_type;type:varchar(16);unique_index:idx_entity_tag" json:"entity_type"`
+	EntityKey  string    `gorm:"column:entity_key;type:varchar(128);unique_index:idx_entity_tag" json:"entity_key"`
+	Tag        string    `gorm:"column:tag;type:varchar(64);unique_index:idx_entity_tag;index" json:"tag"`
+	CreatedAt  time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (EntityTag) TableName() string {
+	return "entity_tag"
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// k8s 集群认证方式
+const (
+	K8sAuthToken      = "token"      // ServiceAccount Token
+	K8sAuthKubeconfig = "kubeconfig" // 内联证书或 Token 的 kubeconfig
+)
+
+// k8s 集群连通性
+const (
+	K8sClusterUnknown   = "unknown"
+	K8sClusterHealthy   = "healthy"
+	K8sClusterUnhealthy = "unhealthy"
+)
+
+// K8sCluster k8s 集群及其访问凭证，凭证使用 k8sCluster.secretKey 加密存储
+type K8sCluster struct {
+	gorm.Model
+	Name       string      `gorm:"column:name;type:varchar(64);unique_index" json:"name"`
+	Env        StringArray `gorm:"column:env;type:json" json:"env"`
+	ZoneCode   string      `gorm:"column:zone_code;type:varchar(64);index" json:"zone_code"`
+	ZoneName   string      `gorm:"column:zone_name;type:varchar(64)" json:"zone_name"`
+	Server     string      `gorm:"column:server;type:varchar(255)" json:"server"` // API Server 地址，kubeconfig 认证时从 kubeconfig 中解析
+	AuthType   string      `gorm:"column:auth_type;type:varchar(16)" json:"auth_type"`
+	Credential string      `gorm:"column:credential;type:text" json:"-"`    // 加密后的 Token 或 kubeconfig
+	CAData     string      `gorm:"column:ca_data;type:text" json:"ca_data"` // Token 认证时 API Server 证书的 CA，PEM 格式
+	Insecure   bool        `gorm:"column:insecure" json:"insecure"`         // 跳过证书校验
+	Namespace  string      `gorm:"column:namespace;type:varchar(64)" json:"namespace"`
+	AppLabel   string      `gorm:"column:app_label;type:varchar(64)" json:"app_label"` // 工作负载上标识应用名的标签
+	Uid        int         `gorm:"column:uid" json:"uid"`
+
+	Status    string     `gorm:"column:status;type:varchar(16)" json:"status"`
+	Version   string     `gorm:"column:version;type:varchar(64)" json:"version"` // API Server 版本
+	Message   string     `gorm:"column:message;type:text" json:"message"`        // 最近一次检查失败的原因
+	CheckedAt *time.Time `gorm:"column:checked_at" json:"checked_at"`
+}
+
+func (K8sCluster) TableName() string {
+	return "k8s_cluster"
+}
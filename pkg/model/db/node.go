@@ -21,6 +21,21 @@ type Node struct {
 	AgentVersion string `gorm:"not null;"json:"agent_version"` // agent version
 	ProxyType    int    `gorm:"not null;"json:"proxy_type"`    // proxy 类型
 	ProxyVersion string `gorm:"not null;"json:"proxy_version"` // proxy version
+
+	AgentLastError string `gorm:"type:varchar(1024)"json:"agent_last_error"` // agent 最近一次上报的错误
+
+	// 以下为 agent 上报的主机信息，FactsTime 为最近一次上报时间
+	CPUCount         int    `gorm:"column:cpu_count;not null;default:0" json:"cpu_count"`
+	MemTotal         uint64 `gorm:"column:mem_total;not null;default:0" json:"mem_total"`
+	OS               string `gorm:"column:os;type:varchar(128)" json:"os"`
+	Kernel           string `gorm:"column:kernel;type:varchar(128)" json:"kernel"`
+	Arch             string `gorm:"column:arch;type:varchar(32)" json:"arch"`
+	IPs              string `gorm:"column:ips;type:varchar(512)" json:"ips"` // 逗号分隔
+	ContainerRuntime string `gorm:"column:container_runtime;type:varchar(32)" json:"container_runtime"`
+	FactsTime        int64  `gorm:"column:facts_time;not null;default:0" json:"facts_time"`
+
+	// Tags 节点标签，存储在 entity_tag 中
+	Tags []string `gorm:"-" json:"tags,omitempty"`
 }
 
 func (Node) TableName() string {
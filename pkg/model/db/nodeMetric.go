@@ -0,0 +1,22 @@
+package db
+
+// NodeMetric 由juno agent心跳上报的主机资源指标，只保留最近一段时间的历史
+type NodeMetric struct {
+	Id          int     `gorm:"not null;" json:"id"`
+	HostName    string  `gorm:"not null;index:idx_host_time" json:"host_name"`
+	CPUPercent  float64 `gorm:"not null;column:cpu_percent" json:"cpu_percent"`  // cpu使用率
+	MemTotal    uint64  `gorm:"not null;" json:"mem_total"`                      // 内存总量 bytes
+	MemUsed     uint64  `gorm:"not null;" json:"mem_used"`                       // 已使用内存 bytes
+	MemPercent  float64 `gorm:"not null;" json:"mem_percent"`                    // 内存使用率
+	DiskTotal   uint64  `gorm:"not null;" json:"disk_total"`                     // 磁盘总量 bytes
+	DiskUsed    uint64  `gorm:"not null;" json:"disk_used"`                      // 已使用磁盘 bytes
+	DiskPercent float64 `gorm:"not null;" json:"disk_percent"`                   // 磁盘使用率
+	Load1       float64 `gorm:"not null;column:load1" json:"load1"`              // 1分钟负载
+	Load5       float64 `gorm:"not null;column:load5" json:"load5"`              // 5分钟负载
+	Load15      float64 `gorm:"not null;column:load15" json:"load15"`            // 15分钟负载
+	CreateTime  int64   `gorm:"not null;index:idx_host_time" json:"create_time"` // 上报时间
+}
+
+func (NodeMetric) TableName() string {
+	return "node_metric"
+}
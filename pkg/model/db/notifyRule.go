@@ -0,0 +1,28 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// NotifyRule 通知路由规则，按事件类型、应用、应用标签、环境、级别匹配后决定发送渠道与接收人
+type NotifyRule struct {
+	gorm.Model
+	Name            string `gorm:"column:name;type:varchar(64);unique_index" json:"name"`
+	Priority        int    `gorm:"column:priority" json:"priority"` // 数值越小越先匹配
+	Enable          bool   `gorm:"column:enable" json:"enable"`
+	EventTypes      string `gorm:"column:event_types;type:varchar(255)" json:"-"` // 事件类型，逗号分隔，为空匹配全部
+	Apps            string `gorm:"column:apps;type:varchar(1024)" json:"-"`       // 应用名，逗号分隔，为空匹配全部
+	Tags            string `gorm:"column:tags;type:varchar(512)" json:"-"`        // 应用标签，逗号分隔，应用拥有其中任一标签即匹配，为空匹配全部
+	Envs            string `gorm:"column:envs;type:varchar(255)" json:"-"`        // 环境，逗号分隔，为空匹配全部
+	MinSeverity     string `gorm:"column:min_severity;type:varchar(16)" json:"min_severity"`
+	Channels        string `gorm:"column:channels;type:varchar(255)" json:"-"`            // 发送渠道，逗号分隔，为空表示不发送
+	Recipients      string `gorm:"column:recipients;type:varchar(1024)" json:"-"`         // 额外接收人用户名，逗号分隔
+	QuietStart      string `gorm:"column:quiet_start;type:varchar(8)" json:"quiet_start"` // 免打扰开始时间 HH:MM
+	QuietEnd        string `gorm:"column:quiet_end;type:varchar(8)" json:"quiet_end"`     // 免打扰结束时间 HH:MM，早于开始时间表示跨天
+	SuppressSeconds int    `gorm:"column:suppress_seconds" json:"suppress_seconds"`       // 相同事件在该时间内只发送一次
+	Stop            bool   `gorm:"column:stop" json:"stop"`                               // 命中后不再匹配后续规则
+}
+
+func (NotifyRule) TableName() string {
+	return "notify_rule"
+}
@@ -0,0 +1,18 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// NotifyTemplate 通知消息模板，按事件类型和渠道替换默认的标题和正文
+type NotifyTemplate struct {
+	gorm.Model
+	EventType string `gorm:"column:event_type;type:varchar(32);unique_index:idx_notify_template" json:"event_type"`
+	Channel   string `gorm:"column:channel;type:varchar(16);unique_index:idx_notify_template" json:"channel"` // 为空表示该事件类型的全部渠道
+	Subject   string `gorm:"column:subject;type:varchar(512)" json:"subject"`
+	Body      string `gorm:"column:body;type:text" json:"body"`
+}
+
+func (NotifyTemplate) TableName() string {
+	return "notify_template"
+}
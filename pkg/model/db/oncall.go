@@ -0,0 +1,52 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	IncidentStatusFiring   = "firing"
+	IncidentStatusAcked    = "acked"
+	IncidentStatusResolved = "resolved"
+)
+
+type (
+	// OnCallRotation 团队值班轮换，TeamID 为 0 表示平台值班，负责不属于任何团队的告警
+	OnCallRotation struct {
+		gorm.Model
+		TeamID           uint   `gorm:"column:team_id;unique_index" json:"team_id"`
+		Users            string `gorm:"column:users;type:varchar(1024)" json:"-"` // 按轮换顺序排列的 uid，逗号分隔
+		ShiftHours       int    `gorm:"column:shift_hours" json:"shift_hours"`    // 每班时长
+		StartAt          int64  `gorm:"column:start_at" json:"start_at"`          // 第一个班次开始时间
+		EscalateMinutes  int    `gorm:"column:escalate_minutes" json:"escalate_minutes"`
+		EscalateToOwners bool   `gorm:"column:escalate_to_owners" json:"escalate_to_owners"` // 备班未确认时继续通知团队 owner
+	}
+
+	// Incident 需要值班人员确认的告警，未确认时按升级策略依次通知主班、备班、团队 owner
+	Incident struct {
+		gorm.Model
+		TeamID         uint   `gorm:"column:team_id;index" json:"team_id"`
+		App            string `gorm:"column:app;type:varchar(128)" json:"app"`
+		Env            string `gorm:"column:env;type:varchar(64)" json:"env"`
+		Type           string `gorm:"column:type;type:varchar(32)" json:"type"`
+		Severity       string `gorm:"column:severity;type:varchar(16)" json:"severity"`
+		Subject        string `gorm:"column:subject;type:varchar(512)" json:"subject"`
+		Content        string `gorm:"column:content;type:text" json:"content"`
+		Count          int    `gorm:"column:fire_count" json:"count"` // 未恢复期间相同告警的触发次数
+		Status         string `gorm:"column:status;type:varchar(16);index" json:"status"`
+		Level          int    `gorm:"column:level" json:"level"`                       // 升级级别：0 主班，1 备班，2 团队 owner
+		NotifiedUids   string `gorm:"column:notified_uids;type:varchar(512)" json:"-"` // 最近一次通知的 uid，逗号分隔
+		NextEscalateAt int64  `gorm:"column:next_escalate_at;index" json:"next_escalate_at"`
+		AckUid         int    `gorm:"column:ack_uid" json:"ack_uid"`
+		AckTime        int64  `gorm:"column:ack_time" json:"ack_time"`
+		ResolveTime    int64  `gorm:"column:resolve_time" json:"resolve_time"`
+	}
+)
+
+func (OnCallRotation) TableName() string {
+	return "oncall_rotation"
+}
+
+func (Incident) TableName() string {
+	return "incident"
+}
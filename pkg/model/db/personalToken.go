@@ -0,0 +1,34 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	PersonalTokenScopeConfigRead  = "config:read"
+	PersonalTokenScopePipelineRun = "pipeline:run"
+	PersonalTokenScopeMetricsRead = "metrics:read"
+	// PersonalTokenScopeConfigPublish 发布配置，发布前仍需通过配置发布权限校验
+	PersonalTokenScopeConfigPublish = "config:publish"
+	PersonalTokenScopeResourceRead  = "resource:read"
+)
+
+// PersonalToken 用户创建的 API Token，用于脚本、CI 调用 Admin API
+type PersonalToken struct {
+	gorm.Model
+	Uid        int        `gorm:"column:uid;index" json:"uid"`
+	Name       string     `gorm:"column:name;type:varchar(64)" json:"name"`
+	Prefix     string     `gorm:"column:prefix;type:varchar(16)" json:"prefix"` // Token 前缀，用于展示和辨认
+	TokenHash  string     `gorm:"column:token_hash;type:varchar(64);unique_index" json:"-"`
+	Scopes     string     `gorm:"column:scopes;type:varchar(255)" json:"-"` // 逗号分隔
+	ExpiresAt  *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	LastUsedIP string     `gorm:"column:last_used_ip;type:varchar(64)" json:"last_used_ip"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+}
+
+func (PersonalToken) TableName() string {
+	return "personal_token"
+}
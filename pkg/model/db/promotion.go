@@ -0,0 +1,48 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	PromotionKindConfig   = "config"
+	PromotionKindPipeline = "pipeline"
+
+	PromotionStatusPending  = "pending"
+	PromotionStatusApproved = "approved" // 审批通过，正在写入目标环境
+	PromotionStatusApplied  = "applied"
+	PromotionStatusFailed   = "failed"
+	PromotionStatusDenied   = "denied"
+	PromotionStatusCanceled = "canceled"
+)
+
+// Promotion 将已验证的配置版本或流水线定义晋升到下一个环境，审批通过后写入目标环境
+type Promotion struct {
+	gorm.Model
+	Kind          string     `gorm:"column:kind;type:varchar(16);index" json:"kind"`
+	AppName       string     `gorm:"column:app_name;type:varchar(128);index" json:"app_name"`
+	Name          string     `gorm:"column:name;type:varchar(64)" json:"name"` // 配置文件名或流水线名
+	SourceID      uint       `gorm:"column:source_id" json:"source_id"`
+	SourceEnv     string     `gorm:"column:source_env;type:varchar(64)" json:"source_env"`
+	SourceZone    string     `gorm:"column:source_zone;type:varchar(64)" json:"source_zone"`
+	SourceVersion string     `gorm:"column:source_version;type:varchar(64)" json:"source_version"` // 配置版本号，流水线为最近一次成功的任务 ID
+	TargetID      uint       `gorm:"column:target_id" json:"target_id"`                            // 申请时目标环境已有的配置或流水线，为 0 时新建
+	TargetEnv     string     `gorm:"column:target_env;type:varchar(64)" json:"target_env"`
+	TargetZone    string     `gorm:"column:target_zone;type:varchar(64)" json:"target_zone"`
+	Content       string     `gorm:"column:content;type:longtext" json:"-"`        // 晋升的内容快照
+	TargetContent string     `gorm:"column:target_content;type:longtext" json:"-"` // 申请时目标环境的内容，写入前据此判断目标是否已被修改
+	Reason        string     `gorm:"column:reason;type:varchar(512)" json:"reason"`
+	Status        string     `gorm:"column:status;type:varchar(16);index" json:"status"`
+	Uid           int        `gorm:"column:uid;index" json:"uid"`
+	ReviewerUid   int        `gorm:"column:reviewer_uid" json:"reviewer_uid"`
+	ReviewComment string     `gorm:"column:review_comment;type:varchar(512)" json:"review_comment"`
+	ReviewedAt    *time.Time `gorm:"column:reviewed_at" json:"reviewed_at"`
+	AppliedID     uint       `gorm:"column:applied_id" json:"applied_id"` // 写入的目标配置或流水线
+	Error         string     `gorm:"column:error;type:varchar(512)" json:"error"`
+}
+
+func (Promotion) TableName() string {
+	return "promotion"
+}
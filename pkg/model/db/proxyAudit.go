@@ -0,0 +1,34 @@
+package db
+
+import (
+	"time"
+)
+
+// ProxyAuditLog 经 Juno 代理到业务实例(治理端口、pprof、Grafana等)的请求审计记录
+type ProxyAuditLog struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	Uid       int       `gorm:"column:uid" json:"uid"`
+	UserName  string    `gorm:"column:user_name;type:varchar(64);index" json:"user_name"`
+	Kind      string    `gorm:"column:kind;type:varchar(32);index" json:"kind"` // governance/pprof/grafana
+	Method    string    `gorm:"column:method;type:varchar(16)" json:"method"`
+	Path      string    `gorm:"column:path;type:varchar(512)" json:"path"`
+	Query     string    `gorm:"column:query;type:varchar(1024)" json:"query"`
+	AppName   string    `gorm:"column:app_name;type:varchar(128)" json:"app_name"`
+	Env       string    `gorm:"column:env;type:varchar(64)" json:"env"`
+	Target    string    `gorm:"column:target;type:varchar(255);index" json:"target"` // 目标实例，hostname 或 ip:port
+	Status    int       `gorm:"column:status" json:"status"`
+	Latency   int64     `gorm:"column:latency" json:"latency"` // 毫秒
+	Error     string    `gorm:"column:error;type:varchar(1024)" json:"error"`
+	ClientIP  string    `gorm:"column:client_ip;type:varchar(64)" json:"client_ip"`
+}
+
+func (ProxyAuditLog) TableName() string {
+	return "proxy_audit_log"
+}
+
+const (
+	ProxyAuditKindGovernance = "governance"
+	ProxyAuditKindPProf      = "pprof"
+	ProxyAuditKindGrafana    = "grafana"
+)
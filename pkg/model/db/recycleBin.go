@@ -0,0 +1,32 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// 回收站对象类型
+const (
+	RecycleKindApp            = "app"
+	RecycleKindConfig         = "config"
+	RecycleKindPipeline       = "pipeline"
+	RecycleKindHttpCollection = "http_collection"
+)
+
+// RecycleItem 回收站记录，删除对象时写入，恢复或过期清理后删除
+type RecycleItem struct {
+	gorm.Model
+	Kind      string    `gorm:"column:kind;type:varchar(32);index" json:"kind"`
+	ObjectID  uint      `gorm:"column:object_id;index" json:"object_id"` // 被删除对象的 ID
+	Name      string    `gorm:"column:name;type:varchar(255)" json:"name"`
+	AppName   string    `gorm:"column:app_name;type:varchar(255);index" json:"app_name"`
+	Env       string    `gorm:"column:env;type:varchar(32)" json:"env"`
+	Snapshot  string    `gorm:"column:snapshot;type:longtext" json:"-"`  // 删除前的记录，物理删除的对象恢复时使用
+	DeletedBy int       `gorm:"column:deleted_by" json:"deleted_by"`     // 删除人 uid，系统删除时为 0
+	ExpireAt  time.Time `gorm:"column:expire_at;index" json:"expire_at"` // 过期后不能恢复，由清理任务彻底删除
+}
+
+func (RecycleItem) TableName() string {
+	return "recycle_item"
+}
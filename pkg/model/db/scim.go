@@ -0,0 +1,22 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	ScimResourceUser  = "User"
+	ScimResourceGroup = "Group"
+)
+
+// ScimResource 记录 IdP 通过 SCIM 同步的用户、团队对应的 externalId
+type ScimResource struct {
+	gorm.Model
+	ResourceType string `gorm:"column:resource_type;type:varchar(16);unique_index:idx_scim_resource" json:"resource_type"`
+	ResourceID   uint   `gorm:"column:resource_id;unique_index:idx_scim_resource" json:"resource_id"` // 用户 uid 或团队 id
+	ExternalID   string `gorm:"column:external_id;type:varchar(255);index" json:"external_id"`
+}
+
+func (ScimResource) TableName() string {
+	return "scim_resource"
+}
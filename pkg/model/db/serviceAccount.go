@@ -0,0 +1,48 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// ServiceAccountScopeWorker 测试平台 worker：心跳、任务状态上报
+	ServiceAccountScopeWorker = "worker"
+	// ServiceAccountScopeAgent 机器上的 juno-agent：心跳、安装包下载
+	ServiceAccountScopeAgent = "agent"
+	// ServiceAccountScopeSCIM 企业 IdP 通过 SCIM 同步用户与团队
+	ServiceAccountScopeSCIM = "scim"
+)
+
+type (
+	// ServiceAccount 服务账号，worker、agent 等非人类身份
+	ServiceAccount struct {
+		gorm.Model
+		Name        string     `gorm:"column:name;type:varchar(64);unique_index" json:"name"`
+		Description string     `gorm:"column:description;type:varchar(255)" json:"description"`
+		Scopes      string     `gorm:"column:scopes;type:varchar(255)" json:"-"` // 逗号分隔
+		Disabled    bool       `gorm:"column:disabled" json:"disabled"`
+		CreatedBy   int        `gorm:"column:created_by" json:"created_by"`
+		LastUsedAt  *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+		LastUsedIP  string     `gorm:"column:last_used_ip;type:varchar(64)" json:"last_used_ip"`
+	}
+
+	// ServiceAccountCredential 服务账号凭证，轮换时旧凭证在过渡期后失效
+	ServiceAccountCredential struct {
+		gorm.Model
+		AccountID uint       `gorm:"column:account_id;index" json:"account_id"`
+		Prefix    string     `gorm:"column:prefix;type:varchar(16)" json:"prefix"` // 凭证前缀，用于展示和辨认
+		TokenHash string     `gorm:"column:token_hash;type:varchar(64);unique_index" json:"-"`
+		ExpiresAt *time.Time `gorm:"column:expires_at" json:"expires_at"`
+		RevokedAt *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+	}
+)
+
+func (ServiceAccount) TableName() string {
+	return "service_account"
+}
+
+func (ServiceAccountCredential) TableName() string {
+	return "service_account_credential"
+}
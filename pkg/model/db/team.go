@@ -0,0 +1,39 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	TeamMemberRoleOwner  = "owner"
+	TeamMemberRoleMember = "member"
+)
+
+type (
+	// Team 团队，应用归属于团队，团队成员默认拥有团队应用的权限
+	Team struct {
+		gorm.Model
+		Name           string `gorm:"column:name;type:varchar(64);unique_index" json:"name"`
+		Description    string `gorm:"column:description;type:varchar(255)" json:"description"`
+		DefaultActions string `gorm:"column:default_actions;type:varchar(512)" json:"-"`         // 成员对团队应用默认拥有的应用权限，逗号分隔
+		DingWebhook    string `gorm:"column:ding_webhook;type:varchar(512)" json:"ding_webhook"` // 团队应用通知发送到该钉钉机器人
+		// MandatorySeverity 达到该级别的团队通知不受成员个人通知偏好影响，为空时成员可完全按个人偏好接收
+		MandatorySeverity string `gorm:"column:mandatory_severity;type:varchar(16)" json:"mandatory_severity"`
+	}
+
+	// TeamMember 团队成员
+	TeamMember struct {
+		gorm.Model
+		TeamID uint   `gorm:"column:team_id;index" json:"team_id"`
+		Uid    int    `gorm:"column:uid;index" json:"uid"`
+		Role   string `gorm:"column:role;type:varchar(16)" json:"role"`
+	}
+)
+
+func (Team) TableName() string {
+	return "team"
+}
+
+func (TeamMember) TableName() string {
+	return "team_member"
+}
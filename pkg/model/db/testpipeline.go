@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
@@ -18,9 +19,37 @@ type (
 		Branch             string
 		CodeCheck          bool
 		UnitTest           bool
+		UnitTestShards     int        // 单元测试分片数，大于 1 时按包拆分到多个 worker 并行执行
+		UnitTestAffected   bool       // 只执行相对 BaseBranch 变更影响的包
+		UnitTestRace       bool       // 单元测试开启 -race 数据竞争检测
+		UnitTestCoverage   bool       // 上传覆盖率文件和 HTML 覆盖率报告
+		BaseBranch         string     // 影响分析的基准分支
+		FullRunHours       int        // 距上次全量执行超过该小时数时执行全部测试
+		LastFullRunAt      *time.Time // 上次全量执行单元测试的时间
+		VulnCheck          bool       // 依赖漏洞扫描
+		VulnGate           string     // 存在该级别及以上、且代码实际调用的漏洞时流水线失败，为空时只记录不拦截
+		LicenseCheck       bool       // 依赖许可证合规检查，策略见系统设置 license_policy
+		BuildReport        bool       // 构建服务二进制，记录大小和构建耗时
+		BuildPackage       string     // 构建的 main 包，如 ./cmd/server，为空时自动选择
+		CrossBuild         bool       // 交叉编译检查，只构建不执行测试
+		CrossBuildTargets  string     // 交叉编译的平台，逗号分隔的 GOOS/GOARCH，为空时使用默认平台
+		BuildCheck         bool       // 拉取代码后先检查能否编译，失败时不再执行其他阶段
+		BuildCommand       string     // 编译检查执行的命令，为空时执行 go build ./...
+		Benchmark          bool       // 执行基准测试，与同一分支上一次的结果对比
+		BenchmarkPackages  string     // 基准测试的包，空格分隔，为空时为 ./...
+		BenchmarkFlags     string     // 基准测试额外的 go test 参数，如 -bench=. -count=3
+		GoVersion          string     // 使用的 Go 版本，如 1.16.15，为空时使用 worker 默认的 go
+		StepTimeout        int        // 各阶段的超时时间（秒），为 0 时使用 worker 的默认值
+		StepRetries        int        // 阶段失败后的重试次数
+		StepRetryDelay     int        // 第一次重试前等待的时间（秒），之后每次翻倍
+		Priority           int        // 任务在 worker 队列中的优先级，数值大的先执行，默认为 0
 		HttpTestCollection *int
 		GrpcTestAddr       string
 		GrpcTestCases      PipelineGrpcTestCases `gorm:"type:json"` // GRPC 测试用例列表
+		WorkerLabels       MapStringString       `gorm:"type:json"` // 执行任务的 worker 需要具有的标签，如 arch=arm64
+		StepArtifacts      MapStringArray        `gorm:"type:json"` // 各阶段结束后上传的制品，阶段名 => 代码目录下的相对路径
+		StepEnv            MapStringMap          `gorm:"type:json"` // 各阶段命令的环境变量，阶段名 => 变量名 => 值，值中可以引用密钥
+		StepWhen           MapStringString       `gorm:"type:json"` // 各阶段的执行条件，阶段名 => 条件，如 branch == "master"
 		CreatedBy          uint
 		UpdatedBy          uint
 
@@ -37,9 +66,18 @@ type (
 		Env        string           `gorm:"type:varchar(32)"`
 		ZoneCode   string           `gorm:"type:varchar(32)"`
 		Desc       TestPipelineDesc `gorm:"type:json"`
-		Status     TestTaskStatus   // pending, running, failed, success
+		Status     TestTaskStatus   // pending, running, failed, success, superseded, canceled
 		Logs       string           `gorm:"type:longtext"`
-		CreatedBy  uint
+		GoVersion  string           `gorm:"type:varchar(64)"` // worker 实际使用的 Go 版本，如 go1.16.15 linux/amd64
+		CommitSHA  string           `gorm:"type:varchar(64)"` // 触发任务的提交，为空时不参与合并
+		Priority   int              // 创建任务时流水线的优先级
+		// SupersededBy 排队中被同一提交的新任务取代时，取代它的任务 ID
+		SupersededBy uint
+		CreatedBy    uint
+		// WorkerLabels 创建任务时流水线要求的 worker 标签
+		WorkerLabels MapStringString `gorm:"type:json"`
+		// Event 触发任务的事件，如 manual、push、merge_request，用于阶段的执行条件
+		Event string `gorm:"type:varchar(32)"`
 
 		StepStatus []TestPipelineStepStatus `gorm:"foreignKey:TaskID" json:"-"`
 	}
@@ -49,15 +87,150 @@ type (
 		gorm.Model
 		TaskID   uint
 		StepName string
-		Status   TestStepStatus // waiting, running, failed, success
+		Status   TestStepStatus // waiting, running, failed, success, skipped
 		Logs     string         `gorm:"type:longtext"`
 	}
 
+	//TestLogLine 阶段结束后按行索引的日志，用于搜索应用历史构建日志中的错误信息
+	TestLogLine struct {
+		ID         uint `gorm:"primary_key"`
+		CreatedAt  time.Time
+		TaskID     uint   `gorm:"index"`
+		PipelineID uint   // 流水线 ID
+		AppName    string `gorm:"type:varchar(64)"`
+		Env        string `gorm:"type:varchar(32)"`
+		ZoneCode   string `gorm:"type:varchar(32)"`
+		StepName   string `gorm:"type:varchar(64)"`
+		Line       int    // 在阶段日志中的行号，从 1 开始
+		Content    string `gorm:"type:varchar(1024)"`
+	}
+
+	//TestTaskArtifact 任务产出的制品，如测试报告，同一任务下按名称覆盖
+	TestTaskArtifact struct {
+		gorm.Model
+		TaskID      uint `gorm:"index"`
+		StepName    string
+		Name        string
+		ContentType string
+		Size        int
+		Content     []byte `gorm:"type:longblob" json:"-"`
+		StorageKey  string // 保存在对象存储中时的 key，为空时内容保存在 Content 中
+	}
+
+	//TestSecret 流水线使用的密钥，按应用和环境隔离，值使用 testplatform.secretKey 加密保存
+	TestSecret struct {
+		ID        uint `gorm:"primary_key"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		AppName   string `gorm:"type:varchar(64)"`
+		Env       string `gorm:"type:varchar(32)"`
+		Name      string `gorm:"type:varchar(64)"`
+		Value     string `gorm:"type:text" json:"-"`
+		UpdatedBy uint
+	}
+
+	//TestPackageTiming 应用各个包最近一次单元测试的耗时，用于分片时均衡各分片的耗时
+	TestPackageTiming struct {
+		gorm.Model
+		AppName string `gorm:"index"`
+		Package string
+		Elapsed float64 // 秒
+	}
+
+	//TestVulnerability 漏洞扫描发现的依赖漏洞
+	TestVulnerability struct {
+		gorm.Model
+		TaskID       uint `gorm:"index"`
+		StepName     string
+		AppName      string `gorm:"index"`
+		VulnID       string // OSV ID，如 GO-2023-1234
+		Aliases      string // CVE、GHSA 等别名，逗号分隔
+		Module       string
+		Version      string
+		FixedVersion string // 为空时没有修复版本
+		Severity     string
+		Called       bool // 代码是否调用了存在漏洞的函数
+		Summary      string
+	}
+
+	//TestBuildReport 构建报告，记录每次任务构建的二进制大小和耗时，用于观察大小变化趋势
+	TestBuildReport struct {
+		gorm.Model
+		TaskID       uint `gorm:"index"`
+		PipelineID   uint `gorm:"index"`
+		StepName     string
+		AppName      string
+		Branch       string
+		Package      string            // 构建的 main 包
+		BinarySize   int64             // 字节
+		BuildSeconds float64           // 构建耗时（秒）
+		Packages     BuildPackageSizes `gorm:"type:json"` // 占用最大的包
+	}
+
+	//TestCoverage 单元测试阶段的覆盖率，分片时每个分片一条，用于观察覆盖率变化趋势
+	TestCoverage struct {
+		gorm.Model
+		TaskID     uint `gorm:"index"`
+		PipelineID uint `gorm:"index"`
+		StepName   string
+		AppName    string
+		Env        string `gorm:"type:varchar(32)"`
+		Branch     string
+		Statements int
+		Covered    int
+		Partial    bool             // 只测试了变更影响的包，覆盖率不代表整个应用
+		Packages   CoveragePackages `gorm:"type:json"`
+		Report     string           // HTML 覆盖率报告的制品名，没有生成报告时为空
+	}
+
+	//TestBenchmark 基准测试阶段的结果，记录任务的提交，用于和同一分支上一次的结果对比
+	TestBenchmark struct {
+		gorm.Model
+		TaskID         uint `gorm:"index"`
+		PipelineID     uint `gorm:"index"`
+		StepName       string
+		AppName        string
+		Branch         string
+		CommitSHA      string           `gorm:"type:varchar(64)"`
+		BaselineTaskID uint             // 对比的基准任务，为 0 时没有基准
+		Results        BenchmarkResults `gorm:"type:json"`
+	}
+
+	BenchmarkResults []BenchmarkResult
+
+	// BenchmarkResult 同一基准测试多次执行（-count）时为平均值
+	BenchmarkResult struct {
+		Package     string  `json:"package"`
+		Name        string  `json:"name"` // 含 GOMAXPROCS 后缀，如 BenchmarkEncode-8
+		Runs        int     `json:"runs"`
+		Iterations  int64   `json:"iterations"`
+		NsPerOp     float64 `json:"ns_per_op"`
+		BytesPerOp  float64 `json:"bytes_per_op,omitempty"`  // 使用 -benchmem 时输出
+		AllocsPerOp float64 `json:"allocs_per_op,omitempty"` // 使用 -benchmem 时输出
+	}
+
+	CoveragePackages []CoveragePackage
+
+	CoveragePackage struct {
+		Package    string `json:"package"`
+		Statements int    `json:"statements"`
+		Covered    int    `json:"covered"`
+	}
+
+	BuildPackageSizes []BuildPackageSize
+
+	BuildPackageSize struct {
+		Package string `json:"package"`
+		Size    int64  `json:"size"` // 包内符号的大小之和，字节
+	}
+
 	StepType int
 
 	TestPipelineDesc struct {
 		Parallel bool               `json:"parallel"`
 		Steps    []TestPipelineStep `json:"steps"`
+		// GoVersion 执行任务使用的 Go 版本，只在下发到 worker 的顶层流水线设置
+		GoVersion string `json:"go_version,omitempty"`
 	}
 
 	TestPipelineStep struct {
@@ -65,6 +238,18 @@ type (
 		Name        string            `json:"name"`         // MUST be unique under one TestPipelineDesc
 		SubPipeline *TestPipelineDesc `json:"sub_pipeline"` // MUST be set when Type equals StepTypeSubPipeline
 		JobPayload  *TestJobPayload   `json:"job_payload"`  // MUST be set when Type equals StepTypeJob
+		// TimeoutSeconds 阶段的超时时间，超时后终止阶段的命令，为 0 时使用 worker 的默认值
+		TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+		// Retries 阶段失败后的重试次数，任务取消时不再重试
+		Retries int `json:"retries,omitempty"`
+		// RetryDelaySeconds 第一次重试前等待的时间，之后每次翻倍
+		RetryDelaySeconds int `json:"retry_delay_seconds,omitempty"`
+		// Artifacts 阶段结束后上传的制品，代码目录下的相对路径，支持通配符
+		Artifacts []string `json:"artifacts,omitempty"`
+		// Env 阶段命令的环境变量，值中的 ${{ secrets.NAME }} 由 worker 执行前替换为密钥的值
+		Env map[string]string `json:"env,omitempty"`
+		// When 阶段的执行条件，如 branch == "master"，条件不满足时阶段标记为跳过
+		When string `json:"when,omitempty"`
 	}
 
 	TestJobPayload struct {
@@ -87,21 +272,54 @@ const (
 	StepTypeSubPipeline StepType = 1 // 子Pipeline类型，当前Step拥有多个子Step
 	StepTypeJob                  = 2 // 任务类型，当前Step执行某个任务
 
-	JobGitPull   TestJobType = "git_pull"
-	JobUnitTest  TestJobType = "unit_test"
-	JobCodeCheck TestJobType = "code_check"
-	JobHttpTest  TestJobType = "http_test"
-	JobGrpcTest  TestJobType = "grpc_test"
+	JobGitPull      TestJobType = "git_pull"
+	JobUnitTest     TestJobType = "unit_test"
+	JobCodeCheck    TestJobType = "code_check"
+	JobHttpTest     TestJobType = "http_test"
+	JobGrpcTest     TestJobType = "grpc_test"
+	JobVulnCheck    TestJobType = "vuln_check"
+	JobLicenseCheck TestJobType = "license_check"
+	JobBuildReport  TestJobType = "build_report"
+	JobCrossBuild   TestJobType = "cross_build"
+	JobBuildCheck   TestJobType = "build_check"
+	JobBenchmark    TestJobType = "benchmark"
 
 	TestTaskStatusPending TestTaskStatus = "pending"
 	TestTaskStatusRunning                = "running"
 	TestTaskStatusFailed                 = "failed"
 	TestTaskStatusSuccess                = "success"
+	// TestTaskStatusSuperseded 排队中被同一提交的新任务取代，不再执行
+	TestTaskStatusSuperseded = "superseded"
+	// TestTaskStatusCanceled 用户取消，执行中的命令被终止
+	TestTaskStatusCanceled = "canceled"
 
 	TestStepStatusWaiting TestStepStatus = "waiting"
 	TestStepStatusRunning                = "running"
 	TestStepStatusFailed                 = "failed"
 	TestStepStatusSuccess                = "success"
+	// TestStepStatusSkipped 任务取消时未执行的阶段，或执行条件不满足的阶段
+	TestStepStatusSkipped = "skipped"
+)
+
+// 触发任务的事件，阶段的执行条件中通过 event 使用
+const (
+	TestTaskEventManual       = "manual"
+	TestTaskEventPush         = "push"
+	TestTaskEventMergeRequest = "merge_request"
+	TestTaskEventTagPush      = "tag_push"
+	TestTaskEventSchedule     = "schedule"
+)
+
+// 漏洞级别，Go 漏洞库的大部分条目没有级别，为 unknown
+const (
+	VulnSeverityCritical = "critical"
+	VulnSeverityHigh     = "high"
+	VulnSeverityModerate = "moderate"
+	VulnSeverityLow      = "low"
+	VulnSeverityUnknown  = "unknown"
+
+	// VulnGateAny 存在任何实际调用的漏洞时拦截，包括没有级别的漏洞
+	VulnGateAny = "any"
 )
 
 func (*TestPipeline) TableName() string {
@@ -116,6 +334,38 @@ func (*TestPipelineStepStatus) TableName() string {
 	return "test_pipeline_step_status"
 }
 
+func (*TestTaskArtifact) TableName() string {
+	return "test_task_artifact"
+}
+
+func (*TestSecret) TableName() string {
+	return "test_secret"
+}
+
+func (*TestPackageTiming) TableName() string {
+	return "test_package_timing"
+}
+
+func (*TestVulnerability) TableName() string {
+	return "test_vulnerability"
+}
+
+func (*TestBuildReport) TableName() string {
+	return "test_build_report"
+}
+
+func (*TestLogLine) TableName() string {
+	return "test_log_line"
+}
+
+func (*TestCoverage) TableName() string {
+	return "test_coverage"
+}
+
+func (*TestBenchmark) TableName() string {
+	return "test_benchmark"
+}
+
 func (d TestPipelineDesc) Value() (driver.Value, error) {
 	return json.Marshal(d)
 }
@@ -132,6 +382,30 @@ func (d *PipelineGrpcTestCases) Scan(input interface{}) error {
 	return json.Unmarshal(input.([]byte), d)
 }
 
+func (d BuildPackageSizes) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+func (d *BuildPackageSizes) Scan(input interface{}) error {
+	return json.Unmarshal(input.([]byte), d)
+}
+
+func (d CoveragePackages) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+func (d *CoveragePackages) Scan(input interface{}) error {
+	return json.Unmarshal(input.([]byte), d)
+}
+
+func (d BenchmarkResults) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+func (d *BenchmarkResults) Scan(input interface{}) error {
+	return json.Unmarshal(input.([]byte), d)
+}
+
 //ValidatePipelineDesc 检查 TestPipelineDesc 是否有效
 func (d TestPipelineDesc) ValidatePipelineDesc() error {
 	names := make(map[string]bool)
@@ -139,10 +413,16 @@ func (d TestPipelineDesc) ValidatePipelineDesc() error {
 	var functor func(desc TestPipelineDesc) error
 	functor = func(desc TestPipelineDesc) error {
 		for _, step := range desc.Steps {
-			if names[step.Name] {
-				return fmt.Errorf("step name conflicts: %s", step.Name)
+			// 子流水线没有名称，只检查 job 阶段
+			if step.Type == StepTypeJob {
+				if step.Name == "" {
+					return fmt.Errorf("step.Name MUST not be empty when type = StepTypeJob")
+				}
+				if names[step.Name] {
+					return fmt.Errorf("step name conflicts: %s", step.Name)
+				}
+				names[step.Name] = true
 			}
-			names[step.Name] = true
 
 			switch step.Type {
 			case StepTypeSubPipeline:
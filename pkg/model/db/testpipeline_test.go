@@ -0,0 +1,45 @@
+package db
+
+import "testing"
+
+func TestValidatePipelineDesc(t *testing.T) {
+	job := func(name string) TestPipelineStep {
+		return TestPipelineStep{Type: StepTypeJob, Name: name, JobPayload: &TestJobPayload{Type: JobUnitTest}}
+	}
+	sub := func(steps ...TestPipelineStep) TestPipelineStep {
+		return TestPipelineStep{Type: StepTypeSubPipeline, SubPipeline: &TestPipelineDesc{Steps: steps}}
+	}
+
+	cases := []struct {
+		name  string
+		desc  TestPipelineDesc
+		valid bool
+	}{
+		{"jobs", TestPipelineDesc{Steps: []TestPipelineStep{job("git_pull"), job("unit_test")}}, true},
+		{
+			// 分片时各分片是没有名称的子流水线，阶段名不重复
+			"sharded",
+			TestPipelineDesc{Parallel: true, Steps: []TestPipelineStep{
+				sub(job("git_pull"), job("code_check")),
+				sub(job("git_pull_shard_1"), job("unit_test_shard_1")),
+				sub(job("git_pull_shard_2"), job("unit_test_shard_2")),
+			}},
+			true,
+		},
+		{"duplicate job", TestPipelineDesc{Steps: []TestPipelineStep{job("unit_test"), job("unit_test")}}, false},
+		{"duplicate job in sub pipelines", TestPipelineDesc{Steps: []TestPipelineStep{
+			sub(job("git_pull"), job("unit_test")),
+			sub(job("git_pull_shard_1"), job("unit_test")),
+		}}, false},
+		{"empty job name", TestPipelineDesc{Steps: []TestPipelineStep{job("git_pull"), job("")}}, false},
+		{"empty job name in sub pipeline", TestPipelineDesc{Steps: []TestPipelineStep{sub(job(""))}}, false},
+		{"nil sub pipeline", TestPipelineDesc{Steps: []TestPipelineStep{{Type: StepTypeSubPipeline}}}, false},
+		{"nil job payload", TestPipelineDesc{Steps: []TestPipelineStep{{Type: StepTypeJob, Name: "unit_test"}}}, false},
+	}
+	for _, c := range cases {
+		err := c.desc.ValidatePipelineDesc()
+		if (err == nil) != c.valid {
+			t.Errorf("%s: ValidatePipelineDesc() = %v, want valid = %v", c.name, err, c.valid)
+		}
+	}
+}
@@ -8,10 +8,15 @@ import (
 type (
 	MapStringArray  map[string][]string
 	MapStringString map[string]string
+	MapStringMap    map[string]map[string]string
 	StringArray     []string
 )
 
 func (h *MapStringArray) Scan(val interface{}) error {
+	if val == nil {
+		*h = nil
+		return nil
+	}
 	return json.Unmarshal(val.([]byte), h)
 }
 
@@ -25,6 +30,10 @@ func (h MapStringArray) Value() (val driver.Value, err error) {
 }
 
 func (h *MapStringString) Scan(val interface{}) error {
+	if val == nil {
+		*h = nil
+		return nil
+	}
 	return json.Unmarshal(val.([]byte), h)
 }
 
@@ -36,6 +45,34 @@ func (h MapStringString) Value() (val driver.Value, err error) {
 	val, err = json.Marshal(&h)
 	return
 }
+
+func (h *MapStringMap) Scan(val interface{}) error {
+	if val == nil {
+		*h = nil
+		return nil
+	}
+	return json.Unmarshal(val.([]byte), h)
+}
+
+func (h MapStringMap) Value() (val driver.Value, err error) {
+	if h == nil {
+		val = "{}"
+		return
+	}
+	val, err = json.Marshal(&h)
+	return
+}
+
+// Contains 是否包含 required 中的全部键值，required 为空时返回 true
+func (h MapStringString) Contains(required map[string]string) bool {
+	for key, value := range required {
+		if v, ok := h[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *StringArray) Scan(val interface{}) error {
 	return json.Unmarshal(val.([]byte), h)
 }
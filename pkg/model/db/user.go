@@ -7,11 +7,14 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// UserStateDisabled 已停用的用户不能登录，API Token 也会失效
+const UserStateDisabled = "disabled"
+
 // swagger:model user
 type User struct {
 	Uid           int    `gorm:"not null;primary_key;AUTO_INCREMENT"json:"uid"`
 	Oaid          int    `gorm:"not null;comment:'oa uid'"json:"id"`
-	Username      string `gorm:"not null;comment:'用户名'"json:"username"`
+	Username      string `gorm:"not null;unique_index;comment:'用户名'"json:"username"`
 	Nickname      string `gorm:"not null;comment:'昵称'"json:"nickname"`
 	Secret        string `gorm:"not null;comment:'秘钥'"json:"secret"`
 	Email         string `gorm:"not null;comment:'email'"json:"email"`
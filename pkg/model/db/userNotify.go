@@ -0,0 +1,41 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// UserNotifyEmail 用户接收通知的邮箱，未设置时使用账号邮箱
+type UserNotifyEmail struct {
+	gorm.Model
+	Uid   int    `gorm:"column:uid;unique_index" json:"uid"`
+	Email string `gorm:"column:email;type:varchar(255)" json:"email"`
+}
+
+func (UserNotifyEmail) TableName() string {
+	return "user_notify_email"
+}
+
+// UserNotifyPref 用户通知偏好，未设置时接收所在团队的全部通知
+type UserNotifyPref struct {
+	gorm.Model
+	Uid         int    `gorm:"column:uid;unique_index" json:"uid"`
+	MutedEvents string `gorm:"column:muted_events;type:varchar(512)" json:"muted_events"` // 不接收的事件类型，逗号分隔
+	MinSeverity string `gorm:"column:min_severity;type:varchar(16)" json:"min_severity"`  // 接收通知的最低级别
+	Channels    string `gorm:"column:channels;type:varchar(255)" json:"channels"`         // 接收通知的渠道，逗号分隔，为空时全部渠道
+	Token       string `gorm:"column:token;type:varchar(64);unique_index" json:"-"`       // 邮件退订链接凭证
+}
+
+func (UserNotifyPref) TableName() string {
+	return "user_notify_pref"
+}
+
+// UserLanguage 用户界面、接口提示和通知使用的语言，未设置时按浏览器语言
+type UserLanguage struct {
+	gorm.Model
+	Uid      int    `gorm:"column:uid;unique_index" json:"uid"`
+	Language string `gorm:"column:language;type:varchar(16)" json:"language"`
+}
+
+func (UserLanguage) TableName() string {
+	return "user_language"
+}
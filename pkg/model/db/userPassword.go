@@ -0,0 +1,32 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// UserPasswordHistory 本地账号密码修改记录，用于密码过期与历史密码校验
+type UserPasswordHistory struct {
+	gorm.Model
+	Uid      int    `gorm:"column:uid;index" json:"uid"`
+	Password string `gorm:"column:password;type:varchar(128)" json:"-"`
+}
+
+func (UserPasswordHistory) TableName() string {
+	return "user_password_history"
+}
+
+// UserLoginLock 账号登录失败记录，按用户名记录以覆盖 LDAP 账号
+type UserLoginLock struct {
+	gorm.Model
+	Username     string     `gorm:"column:username;type:varchar(64);unique_index" json:"username"`
+	Failures     int        `gorm:"column:failures" json:"failures"`
+	LastFailedAt *time.Time `gorm:"column:last_failed_at" json:"last_failed_at"`
+	LastFailedIP string     `gorm:"column:last_failed_ip;type:varchar(64)" json:"last_failed_ip"`
+	LockedUntil  *time.Time `gorm:"column:locked_until" json:"locked_until"`
+}
+
+func (UserLoginLock) TableName() string {
+	return "user_login_lock"
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"time"
+)
+
+// UserSession 用户登录会话，用于查看和吊销已登录的设备
+type UserSession struct {
+	ID           uint       `gorm:"primary_key" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	SessionID    string     `gorm:"column:session_id;type:varchar(64);unique_index" json:"-"`
+	Uid          int        `gorm:"column:uid;index" json:"uid"`
+	UserAgent    string     `gorm:"column:user_agent;type:varchar(512)" json:"user_agent"`
+	ClientIP     string     `gorm:"column:client_ip;type:varchar(64)" json:"client_ip"`
+	LastActiveAt time.Time  `gorm:"column:last_active_at" json:"last_active_at"`
+	ExpiresAt    *time.Time `gorm:"column:expires_at" json:"expires_at"` // 为空表示浏览器会话
+	RevokedAt    *time.Time `gorm:"column:revoked_at;index" json:"revoked_at"`
+}
+
+func (UserSession) TableName() string {
+	return "user_session"
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// UserTOTP 用户两步验证配置
+type UserTOTP struct {
+	gorm.Model
+	Uid         int        `gorm:"column:uid;unique_index" json:"uid"`
+	Secret      string     `gorm:"column:secret;type:varchar(64)" json:"-"`
+	Enabled     bool       `gorm:"column:enabled" json:"enabled"`
+	EnabledAt   *time.Time `gorm:"column:enabled_at" json:"enabled_at"`
+	LastStep    int64      `gorm:"column:last_step" json:"-"`              // 最近一次使用的验证码时间步，防止重放
+	BackupCodes string     `gorm:"column:backup_codes;type:text" json:"-"` // 未使用的备用码哈希，逗号分隔
+}
+
+func (UserTOTP) TableName() string {
+	return "user_totp"
+}
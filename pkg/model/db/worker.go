@@ -1,6 +1,8 @@
 package db
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"time"
 
 	"github.com/jinzhu/gorm"
@@ -19,9 +21,45 @@ type (
 		Port          int       `json:"port"`
 		Env           string    `json:"env"`
 		LastHeartbeat time.Time `json:"last_heartbeat"`
+		// Drained 不再下发新任务，用于缩容前等待正在执行的任务结束
+		Drained bool `json:"drained"`
+
+		Version    string      `json:"version"`
+		Capacity   int         `json:"capacity"`    // 同时执行的任务数
+		Running    int         `json:"running"`     // 执行中的任务数
+		QueueDepth int         `json:"queue_depth"` // 等待中的任务数，队列不支持查看时为 -1
+		StartedAt  *time.Time  `json:"started_at"`  // worker 进程的启动时间
+		Tasks      WorkerTasks `gorm:"type:json" json:"tasks"`
+		// Labels worker 声明的标签，流水线要求的标签全部匹配时才向其下发任务
+		Labels MapStringString `gorm:"type:json" json:"labels"`
+	}
+
+	// WorkerTasks worker 执行中的任务，随心跳更新
+	WorkerTasks []WorkerTask
+
+	WorkerTask struct {
+		TaskID    uint      `json:"task_id"`
+		Part      int       `json:"part,omitempty"`
+		Name      string    `json:"name"`
+		AppName   string    `json:"app_name"`
+		Branch    string    `json:"branch"`
+		StartedAt time.Time `json:"started_at"`
 	}
 )
 
 func (WorkerNode) TableName() string {
 	return "worker_node"
 }
+
+func (d WorkerTasks) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Scan 升级前注册的节点 tasks 为 NULL
+func (d *WorkerTasks) Scan(input interface{}) error {
+	if input == nil {
+		*d = nil
+		return nil
+	}
+	return json.Unmarshal(input.([]byte), d)
+}
@@ -12,6 +12,8 @@ type Zone struct {
 	UpdateTime int64  `gorm:"not null;comment:'注释'"json:"update_time"`
 	CreatedBy  int    `gorm:"not null;comment:'注释'"json:"created_by"`
 	UpdatedBy  int    `gorm:"not null;comment:'注释'"json:"updated_by"`
+
+	AgentOfflineThreshold int `gorm:"not null;default:0"json:"agent_offline_threshold"` // agent 离线判定阈值(秒)，0 表示使用默认值
 }
 
 // TableName 表名
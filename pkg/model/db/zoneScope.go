@@ -0,0 +1,22 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	ZoneScopeSubjectUser = "user"
+	ZoneScopeSubjectTeam = "team"
+)
+
+// ZoneScope 限制用户或团队只能访问指定机房，未配置的用户不受限制
+type ZoneScope struct {
+	gorm.Model
+	SubjectType string `gorm:"column:subject_type;type:varchar(16);unique_index:idx_zone_scope" json:"subject_type"`
+	SubjectID   uint   `gorm:"column:subject_id;unique_index:idx_zone_scope" json:"subject_id"` // 用户 uid 或团队 id
+	ZoneCode    string `gorm:"column:zone_code;type:varchar(64);unique_index:idx_zone_scope" json:"zone_code"`
+}
+
+func (ZoneScope) TableName() string {
+	return "zone_scope"
+}
@@ -0,0 +1,58 @@
+package view
+
+import (
+	"time"
+)
+
+const (
+	// AccessRequestScopeMine 我发起的申请
+	AccessRequestScopeMine = "mine"
+	// AccessRequestScopeReview 待我审批的申请
+	AccessRequestScopeReview = "review"
+)
+
+type (
+	ReqCreateAccessRequest struct {
+		AppName string   `json:"app_name" validate:"required"`
+		Env     string   `json:"env" validate:"required"`
+		Actions []string `json:"actions" validate:"required,min=1"`
+		Reason  string   `json:"reason" validate:"required,max=512"`
+	}
+
+	ReqListAccessRequest struct {
+		Scope    string `query:"scope" validate:"omitempty,oneof=mine review"`
+		Status   string `query:"status"`
+		AppName  string `query:"app_name"`
+		Page     int    `query:"page"`
+		PageSize int    `query:"page_size"`
+	}
+
+	// ReqReviewAccessRequest 审批权限申请，通过时必须设置有效天数
+	ReqReviewAccessRequest struct {
+		ID         uint   `json:"id" validate:"required"`
+		Approve    bool   `json:"approve"`
+		ExpireDays int    `json:"expire_days" validate:"min=0,max=365"`
+		Comment    string `json:"comment" validate:"max=512"`
+	}
+
+	ReqAccessRequestID struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	AccessRequest struct {
+		ID            uint       `json:"id"`
+		Uid           int        `json:"uid"`
+		Username      string     `json:"username"`
+		AppName       string     `json:"app_name"`
+		Env           string     `json:"env"`
+		Actions       []string   `json:"actions"`
+		Reason        string     `json:"reason"`
+		Status        string     `json:"status"`
+		ReviewerUid   int        `json:"reviewer_uid"`
+		Reviewer      string     `json:"reviewer"`
+		ReviewComment string     `json:"review_comment"`
+		ReviewedAt    *time.Time `json:"reviewed_at"`
+		ExpiresAt     *time.Time `json:"expires_at"`
+		CreatedAt     time.Time  `json:"created_at"`
+	}
+)
@@ -0,0 +1,172 @@
+package view
+
+import (
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+type (
+	AgentConfig struct {
+		ID               uint                  `json:"id"`
+		Env              string                `json:"env" validate:"required"`
+		ZoneCode         string                `json:"zone_code" validate:"required"`
+		HostName         string                `json:"host_name"` // 为空表示对整个可用区生效
+		Content          db.AgentConfigContent `json:"content"`
+		Version          uint                  `json:"version"`
+		PublishedContent db.AgentConfigContent `json:"published_content"`
+		PublishedVersion uint                  `json:"published_version"`
+		UpdatedAt        time.Time             `json:"updated_at"`
+	}
+
+	ReqListAgentConfig struct {
+		Env      string `query:"env"`
+		ZoneCode string `query:"zone_code"`
+		HostName string `query:"host_name"`
+	}
+
+	ReqAgentConfigID struct {
+		ID uint `json:"id" query:"id" validate:"required"`
+	}
+
+	// AgentConfigPayload 写入etcd供agent监听的配置
+	AgentConfigPayload struct {
+		Version   uint                  `json:"version"`
+		Timestamp int64                 `json:"timestamp"`
+		Content   db.AgentConfigContent `json:"content"`
+	}
+)
+
+type (
+	// ReqAgentProcess 查询/操作 agent 管理的应用进程
+	ReqAgentProcess struct {
+		AppName  string `json:"app_name" query:"app_name" validate:"required"`
+		Env      string `json:"env" query:"env" validate:"required"`
+		ZoneCode string `json:"zone_code" query:"zone_code" validate:"required"`
+		HostName string `json:"host_name" query:"host_name" validate:"required"`
+	}
+
+	// AgentProcessStatus agent 从 systemd 或 supervisord 查询到的进程状态
+	AgentProcessStatus struct {
+		Manager      string `json:"manager"`       // systemd, supervisor
+		Unit         string `json:"unit"`          // systemd unit 或 supervisor program 名称
+		State        string `json:"state"`         // active, inactive, failed, running, stopped, fatal ...
+		SubState     string `json:"sub_state"`     // systemd sub state
+		Pid          int    `json:"pid"`           // 主进程 pid
+		RestartCount int    `json:"restart_count"` // 重启次数
+		Since        int64  `json:"since"`         // 进入当前状态的时间
+		Message      string `json:"message"`       // 附加信息
+	}
+)
+
+type (
+	AgentPackage struct {
+		ID        uint      `json:"id"`
+		Version   string    `json:"version"`
+		FileName  string    `json:"file_name"`
+		Size      int64     `json:"size"`
+		Sha256    string    `json:"sha256"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	ReqCreateAgentUpgrade struct {
+		Version          string                `json:"version" validate:"required"`
+		Zones            []db.AgentUpgradeZone `json:"zones" validate:"required,min=1"`
+		CanaryPercent    uint                  `json:"canary_percent" validate:"max=100"`
+		FailureThreshold uint                  `json:"failure_threshold" validate:"max=100"`
+	}
+
+	ReqAgentUpgradeID struct {
+		ID uint `json:"id" query:"id" validate:"required"`
+	}
+
+	AgentUpgrade struct {
+		ID               uint                  `json:"id"`
+		Version          string                `json:"version"`
+		Zones            []db.AgentUpgradeZone `json:"zones"`
+		CanaryPercent    uint                  `json:"canary_percent"`
+		FailureThreshold uint                  `json:"failure_threshold"`
+		ZoneIndex        int                   `json:"zone_index"`
+		Stage            db.AgentUpgradeStage  `json:"stage"`
+		Status           db.AgentUpgradeStatus `json:"status"`
+		Reason           string                `json:"reason"`
+		CreatedAt        time.Time             `json:"created_at"`
+		UpdatedAt        time.Time             `json:"updated_at"`
+	}
+
+	AgentUpgradeDetail struct {
+		AgentUpgrade
+		Nodes []AgentUpgradeNode `json:"nodes"`
+	}
+
+	AgentUpgradeNode struct {
+		ZoneIndex    int                       `json:"zone_index"`
+		Stage        db.AgentUpgradeStage      `json:"stage"`
+		HostName     string                    `json:"host_name"`
+		FromVersion  string                    `json:"from_version"`
+		Status       db.AgentUpgradeNodeStatus `json:"status"`
+		DispatchedAt *time.Time                `json:"dispatched_at"`
+		FinishedAt   *time.Time                `json:"finished_at"`
+	}
+
+	// AgentUpgradePayload 写入etcd，agent 监听到之后下载并替换自身
+	AgentUpgradePayload struct {
+		UpgradeID uint   `json:"upgrade_id"`
+		Version   string `json:"version"`
+		URL       string `json:"url"` // 下载时需要携带 agent 的服务账号 Token，与心跳相同
+		Sha256    string `json:"sha256"`
+	}
+)
+
+type (
+	ReqAgentOfflineThreshold struct {
+		Env       string `json:"env" validate:"required"`
+		ZoneCode  string `json:"zone_code" validate:"required"`
+		Threshold int    `json:"threshold" validate:"min=0"` // 秒，0 表示使用默认值
+	}
+
+	AgentOfflineThreshold struct {
+		Env       string `json:"env"`
+		ZoneCode  string `json:"zone_code"`
+		ZoneName  string `json:"zone_name"`
+		Threshold int    `json:"threshold"`
+		Effective int    `json:"effective"` // 实际生效的阈值(秒)
+	}
+
+	ReqListAgentOffline struct {
+		Env      string `json:"env" query:"env"`
+		ZoneCode string `json:"zone_code" query:"zone_code"`
+	}
+
+	AgentOffline struct {
+		HostName           string   `json:"host_name"`
+		Ip                 string   `json:"ip"`
+		Env                string   `json:"env"`
+		ZoneCode           string   `json:"zone_code"`
+		AgentVersion       string   `json:"agent_version"`
+		AgentHeartbeatTime int64    `json:"agent_heartbeat_time"`
+		ProxyHeartbeatTime int64    `json:"proxy_heartbeat_time"`
+		OfflineTime        int64    `json:"offline_time"`
+		LastError          string   `json:"last_error"`
+		Hints              []string `json:"hints"`
+	}
+
+	ReqListAgentFlapping struct {
+		Env      string `json:"env" query:"env"`
+		ZoneCode string `json:"zone_code" query:"zone_code"`
+		Days     int    `json:"days" query:"days" validate:"min=0,max=90"`    // 统计窗口，默认 7 天
+		MinCount int    `json:"min_count" query:"min_count" validate:"min=0"` // 窗口内最少离线次数，默认 3 次
+	}
+
+	AgentFlapping struct {
+		HostName        string   `json:"host_name"`
+		Env             string   `json:"env"`
+		ZoneCode        string   `json:"zone_code"`
+		OfflineCount    int      `json:"offline_count"`
+		OfflineDuration int64    `json:"offline_duration"` // 窗口内累计离线时长(秒)
+		LastOfflineTime int64    `json:"last_offline_time"`
+		Online          bool     `json:"online"`
+		LastError       string   `json:"last_error"`
+		Hints           []string `json:"hints"`
+	}
+)
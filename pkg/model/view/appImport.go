@@ -0,0 +1,49 @@
+package view
+
+import "time"
+
+type (
+	// ReqListAppImport 待导入应用列表，status 为空时返回全部
+	ReqListAppImport struct {
+		Status    string `query:"status"`
+		GroupPath string `query:"group_path"`
+		Keyword   string `query:"keyword"`
+		Page      int    `query:"page"`
+		PageSize  int    `query:"page_size"`
+	}
+
+	// ReqAppImport 导入或忽略待导入应用
+	ReqAppImport struct {
+		IDs []uint `json:"ids" validate:"required,min=1"`
+	}
+
+	// RespScanAppImport 扫描结果，部分分组、项目失败时继续扫描，失败原因记录在 Errors 中
+	RespScanAppImport struct {
+		Projects   int      `json:"projects"`   // 扫描的项目数
+		Candidates int      `json:"candidates"` // 新增或更新的待导入应用数
+		Errors     []string `json:"errors"`
+	}
+
+	// RespAppImport 导入结果，失败的应用保持待导入状态
+	RespAppImport struct {
+		Imported []string `json:"imported"`
+		Errors   []string `json:"errors"`
+	}
+
+	AppImportCandidate struct {
+		ID         uint      `json:"id"`
+		Gid        int       `json:"gid"`
+		AppName    string    `json:"app_name"`
+		Name       string    `json:"name"`
+		Module     string    `json:"module"`
+		Jupiter    bool      `json:"jupiter"`
+		GroupPath  string    `json:"group_path"`
+		GitURL     string    `json:"git_url"`
+		WebURL     string    `json:"web_url"`
+		Owners     []string  `json:"owners"`
+		Committers []string  `json:"committers"`
+		Status     string    `json:"status"`
+		Aid        int       `json:"aid"`
+		ScannedAt  time.Time `json:"scanned_at"`
+	}
+)
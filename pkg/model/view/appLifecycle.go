@@ -0,0 +1,38 @@
+package view
+
+import "time"
+
+type (
+	// ReqSetAppStatus 修改应用生命周期状态
+	ReqSetAppStatus struct {
+		Aid    int    `json:"aid" validate:"required"`
+		Status string `json:"status" validate:"required,oneof=active deprecated archived deleted"`
+		Reason string `json:"reason"`
+	}
+
+	ReqListAppArchive struct {
+		Aid     int    `query:"aid"`
+		AppName string `query:"app_name"`
+	}
+
+	ReqExportAppArchive struct {
+		ID uint `query:"id" validate:"required"`
+	}
+
+	// AppArchiveExport 应用归档时导出的配置与定时任务
+	AppArchiveExport struct {
+		AppName  string            `json:"app_name"`
+		Configs  []AppConfigExport `json:"configs"`
+		CronJobs []CronJob         `json:"cron_jobs"`
+	}
+
+	AppConfigExport struct {
+		Name        string     `json:"name"`
+		Format      string     `json:"format"`
+		Env         string     `json:"env"`
+		Zone        string     `json:"zone"`
+		Version     string     `json:"version"`
+		Content     string     `json:"content"`
+		PublishedAt *time.Time `json:"published_at"`
+	}
+)
@@ -0,0 +1,25 @@
+package view
+
+type (
+	// ReqSaveAppMetaField 创建或更新应用自定义字段，ID 为 0 时创建，字段标识创建后不可修改
+	ReqSaveAppMetaField struct {
+		ID       uint     `json:"id"`
+		Name     string   `json:"name" validate:"required"`
+		Title    string   `json:"title" validate:"required"`
+		Type     string   `json:"type" validate:"required,oneof=string number bool enum url"`
+		Options  []string `json:"options"` // enum 类型的可选值
+		Required bool     `json:"required"`
+		Regex    string   `json:"regex"`
+		Sort     int      `json:"sort"`
+	}
+
+	ReqDeleteAppMetaField struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	// ReqSetAppMeta 设置应用自定义字段的值，值为空时删除该字段的值
+	ReqSetAppMeta struct {
+		Aid  int               `json:"aid" validate:"required"`
+		Meta map[string]string `json:"meta" validate:"required"`
+	}
+)
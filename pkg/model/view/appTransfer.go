@@ -0,0 +1,59 @@
+package view
+
+import (
+	"time"
+)
+
+const (
+	// AppTransferScopeMine 我发起的转移
+	AppTransferScopeMine = "mine"
+	// AppTransferScopeReview 转入我所在团队、待我接受的转移
+	AppTransferScopeReview = "review"
+)
+
+type (
+	// ReqCreateAppTransfer 发起应用归属转移，Users 为转移后的应用负责人，为空时使用目标团队 owner
+	ReqCreateAppTransfer struct {
+		AppName  string   `json:"app_name" validate:"required"`
+		ToTeamID uint     `json:"to_team_id" validate:"required"`
+		Users    []string `json:"users"`
+		Reason   string   `json:"reason" validate:"required,max=512"`
+	}
+
+	ReqListAppTransfer struct {
+		Scope    string `query:"scope" validate:"omitempty,oneof=mine review"`
+		Status   string `query:"status"`
+		AppName  string `query:"app_name"`
+		Page     int    `query:"page"`
+		PageSize int    `query:"page_size"`
+	}
+
+	ReqReviewAppTransfer struct {
+		ID      uint   `json:"id" validate:"required"`
+		Accept  bool   `json:"accept"`
+		Comment string `json:"comment" validate:"max=512"`
+	}
+
+	ReqAppTransferID struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	AppTransfer struct {
+		ID            uint       `json:"id"`
+		AppName       string     `json:"app_name"`
+		FromTeamID    uint       `json:"from_team_id"`
+		FromTeam      string     `json:"from_team"`
+		ToTeamID      uint       `json:"to_team_id"`
+		ToTeam        string     `json:"to_team"`
+		Users         []string   `json:"users"`
+		Reason        string     `json:"reason"`
+		Status        string     `json:"status"`
+		Uid           int        `json:"uid"`
+		Username      string     `json:"username"`
+		ReviewerUid   int        `json:"reviewer_uid"`
+		Reviewer      string     `json:"reviewer"`
+		ReviewComment string     `json:"review_comment"`
+		ReviewedAt    *time.Time `json:"reviewed_at"`
+		CreatedAt     time.Time  `json:"created_at"`
+	}
+)
@@ -0,0 +1,17 @@
+package view
+
+type (
+	ReqListAuditLog struct {
+		UserName   string `query:"user_name"`
+		Resource   string `query:"resource"`
+		ResourceID string `query:"resource_id"`
+		AppName    string `query:"app_name"`
+		Env        string `query:"env"`
+		Path       string `query:"path"` // 路径前缀
+		Keyword    string `query:"keyword"`
+		StartTime  int64  `query:"start_time"`
+		EndTime    int64  `query:"end_time"`
+
+		ListQuery
+	}
+)
@@ -0,0 +1,68 @@
+package view
+
+import (
+	"time"
+)
+
+const (
+	CatalogFormatJSON = "json"
+	CatalogFormatYAML = "yaml"
+)
+
+type (
+	// ReqCatalogExport 导出服务目录，默认不包含已归档、待删除的应用
+	ReqCatalogExport struct {
+		Format          string `query:"format" validate:"omitempty,oneof=json yaml"`
+		Env             string `query:"env"`
+		TeamID          uint   `query:"team_id"`
+		AppName         string `query:"app_name"`
+		IncludeArchived bool   `query:"include_archived"`
+	}
+
+	// Catalog 服务目录，用于导入 Backstage 或生成内部文档
+	Catalog struct {
+		GeneratedAt time.Time    `json:"generated_at" yaml:"generated_at"`
+		Apps        []CatalogApp `json:"apps" yaml:"apps"`
+	}
+
+	CatalogApp struct {
+		AppName      string              `json:"app_name" yaml:"app_name"`
+		Name         string              `json:"name" yaml:"name"`
+		Lang         string              `json:"lang" yaml:"lang"`
+		BizDomain    string              `json:"biz_domain" yaml:"biz_domain"`
+		Level        int                 `json:"level" yaml:"level"`
+		Status       string              `json:"status" yaml:"status"`
+		GitURL       string              `json:"git_url" yaml:"git_url"`
+		WebURL       string              `json:"web_url" yaml:"web_url"`
+		HTTPPort     string              `json:"http_port" yaml:"http_port"`
+		RPCPort      string              `json:"rpc_port" yaml:"rpc_port"`
+		GovernPort   string              `json:"govern_port" yaml:"govern_port"`
+		Team         string              `json:"team" yaml:"team"`
+		Owners       []string            `json:"owners" yaml:"owners"`
+		Meta         map[string]string   `json:"meta" yaml:"meta"`
+		Envs         []CatalogEnv        `json:"envs" yaml:"envs"`
+		Dependencies []CatalogDependency `json:"dependencies" yaml:"dependencies"`
+	}
+
+	CatalogEnv struct {
+		Env       string            `json:"env" yaml:"env"`
+		Zones     []string          `json:"zones" yaml:"zones"`
+		Instances []CatalogInstance `json:"instances" yaml:"instances"`
+	}
+
+	CatalogInstance struct {
+		HostName   string     `json:"host_name" yaml:"host_name"`
+		IP         string     `json:"ip" yaml:"ip"`
+		ZoneCode   string     `json:"zone_code" yaml:"zone_code"`
+		Version    string     `json:"version" yaml:"version"` // 最近一次部署的版本，未记录过部署时为空
+		DeployedAt *time.Time `json:"deployed_at" yaml:"deployed_at"`
+	}
+
+	// CatalogDependency 从配置文件解析出的依赖，如 mysql、redis、grpc
+	CatalogDependency struct {
+		Env  string `json:"env" yaml:"env"`
+		Type string `json:"type" yaml:"type"`
+		Name string `json:"name" yaml:"name"`
+		Addr string `json:"addr" yaml:"addr"`
+	}
+)
@@ -0,0 +1,66 @@
+package view
+
+import "time"
+
+type (
+	// CmdbSource CMDB 数据源及最近一次同步结果
+	CmdbSource struct {
+		Name     string          `json:"name"`
+		Driver   string          `json:"driver"`
+		URL      string          `json:"url"`
+		Kinds    []string        `json:"kinds"`
+		LastSync *CmdbSyncResult `json:"last_sync"`
+	}
+
+	// ReqCmdbSync 立即同步，source 为空时同步全部数据源
+	ReqCmdbSync struct {
+		Source string `json:"source"`
+	}
+
+	// CmdbSyncResult 同步结果，部分数据类型失败时继续同步，失败原因记录在 Errors 中
+	CmdbSyncResult struct {
+		Source     string    `json:"source"`
+		StartedAt  time.Time `json:"started_at"`
+		FinishedAt time.Time `json:"finished_at"`
+		Created    int       `json:"created"`
+		Updated    int       `json:"updated"`
+		Conflicts  int       `json:"conflicts"` // 当前未处理的冲突数，包括 CMDB 中已删除的记录
+		Errors     []string  `json:"errors"`
+	}
+
+	// ReqListCmdbConflict 冲突列表，status 为空时返回全部
+	ReqListCmdbConflict struct {
+		Source   string `query:"source"`
+		Kind     string `query:"kind"`
+		Status   string `query:"status"`
+		Page     int    `query:"page"`
+		PageSize int    `query:"page_size"`
+	}
+
+	// ReqResolveCmdbConflict 处理冲突，accept 使用 CMDB 的数据，keep 保留本地数据
+	ReqResolveCmdbConflict struct {
+		ID     uint   `json:"id" validate:"required"`
+		Action string `json:"action" validate:"required,oneof=accept keep"`
+	}
+
+	CmdbDiff struct {
+		Field  string `json:"field"`
+		Remote string `json:"remote"`
+		Local  string `json:"local"`
+	}
+
+	CmdbConflict struct {
+		ID        uint              `json:"id"`
+		Source    string            `json:"source"`
+		Kind      string            `json:"kind"`
+		Key       string            `json:"key"`
+		Op        string            `json:"op"`
+		Remote    map[string]string `json:"remote"`
+		Local     map[string]string `json:"local"`
+		Diffs     []CmdbDiff        `json:"diffs"`
+		Status    string            `json:"status"`
+		Uid       int               `json:"uid"`
+		CreatedAt time.Time         `json:"created_at"`
+		UpdatedAt time.Time         `json:"updated_at"`
+	}
+)
@@ -25,6 +25,7 @@ var (
 type (
 	// ReqListConfig ..
 	ReqListConfig struct {
+		ListQuery
 		AppName string `query:"app_name" validate:"required"`
 		Env     string `query:"env" validate:"required"`
 	}
@@ -221,6 +222,8 @@ type (
 		Version   string   `json:"version"`
 		Format    string   `json:"format"`
 		Paths     []string `json:"paths"`
+		// Trace 发布操作的追踪上下文，HTTP 头格式
+		Trace map[string]string `json:"trace,omitempty"`
 	}
 
 	// ConfigurationStatus ..
@@ -0,0 +1,43 @@
+package view
+
+import (
+	"github.com/douyu/juno/pkg/model/db"
+)
+
+type (
+	// ReqReportDeployment CI 上报部署，HostNames 为空时记录应用在该环境、机房下的全部实例
+	ReqReportDeployment struct {
+		AppName        string   `json:"app_name" validate:"required"`
+		Env            string   `json:"env" validate:"required"`
+		ZoneCode       string   `json:"zone_code"`
+		HostNames      []string `json:"host_names"`
+		Version        string   `json:"version" validate:"required,max=128"`
+		Commit         string   `json:"commit" validate:"max=64"`
+		PipelineTaskID uint     `json:"pipeline_task_id"`
+		PipelineURL    string   `json:"pipeline_url" validate:"omitempty,url,max=512"`
+		Operator       string   `json:"operator" validate:"max=64"`
+		DeployedAt     int64    `json:"deployed_at"` // 秒级时间戳，为空时使用上报时间
+	}
+
+	ReqListDeployment struct {
+		AppName  string `query:"app_name" validate:"required"`
+		Env      string `query:"env"`
+		HostName string `query:"host_name"`
+		Page     int    `query:"page"`
+		PageSize int    `query:"page_size"`
+	}
+
+	ReqLatestDeployment struct {
+		AppName string `query:"app_name" validate:"required"`
+		Env     string `query:"env"`
+	}
+
+	// InstanceDeployment 实例最近一次部署，未记录过部署时 Deployment 为空
+	InstanceDeployment struct {
+		HostName   string         `json:"host_name"`
+		IP         string         `json:"ip"`
+		Env        string         `json:"env"`
+		ZoneCode   string         `json:"zone_code"`
+		Deployment *db.Deployment `json:"deployment"`
+	}
+)
@@ -1,6 +1,13 @@
 package view
 
+import (
+	"time"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
+
 type (
+	// WorkerHeartbeat worker 定时注册，上报节点信息和执行情况
 	WorkerHeartbeat struct {
 		IP         string `json:"ip"`
 		Port       int    `json:"port"`
@@ -10,5 +17,12 @@ type (
 		ZoneCode   string `json:"zone_code"`
 		ZoneName   string `json:"zone_name"`
 		Env        string `json:"env"`
+
+		Version    string            `json:"version"`
+		Capacity   int               `json:"capacity"`    // 同时执行的任务数
+		QueueDepth int               `json:"queue_depth"` // 等待中的任务数，队列不支持查看时为 -1
+		StartedAt  time.Time         `json:"started_at"`
+		Tasks      db.WorkerTasks    `json:"tasks"` // 执行中的任务
+		Labels     map[string]string `json:"labels"`
 	}
 )
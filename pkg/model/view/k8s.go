@@ -0,0 +1,47 @@
+package view
+
+import "time"
+
+type (
+	// ReqAppWorkloadList 应用在 k8s 集群中的工作负载，env、zone_code 为空时查询全部集群
+	ReqAppWorkloadList struct {
+		AppName  string `query:"app_name" validate:"required"`
+		Env      string `query:"env"`
+		ZoneCode string `query:"zone_code"`
+	}
+
+	// RespAppWorkloadList 部分集群查询失败时仍返回其他集群的结果，失败原因记录在 Errors 中
+	RespAppWorkloadList struct {
+		List   []K8sWorkload `json:"list"`
+		Errors []string      `json:"errors"`
+	}
+
+	// K8sWorkload Deployment 或 StatefulSet
+	K8sWorkload struct {
+		Cluster         string    `json:"cluster"`
+		ZoneCode        string    `json:"zone_code"`
+		ZoneName        string    `json:"zone_name"`
+		Namespace       string    `json:"namespace"`
+		Kind            string    `json:"kind"`
+		Name            string    `json:"name"`
+		Replicas        int32     `json:"replicas"`
+		ReadyReplicas   int32     `json:"ready_replicas"`
+		UpdatedReplicas int32     `json:"updated_replicas"`
+		ImageTags       []string  `json:"image_tags"`
+		CreatedAt       time.Time `json:"created_at"`
+		Pods            []K8sPod  `json:"pods"`
+	}
+
+	// K8sPod 工作负载的 Pod
+	K8sPod struct {
+		Name      string     `json:"name"`
+		Status    string     `json:"status"`
+		Ready     string     `json:"ready"` // 就绪容器数/容器总数
+		Restarts  int32      `json:"restarts"`
+		Node      string     `json:"node"`
+		HostIP    string     `json:"host_ip"`
+		PodIP     string     `json:"pod_ip"`
+		ImageTags []string   `json:"image_tags"`
+		StartTime *time.Time `json:"start_time"`
+	}
+)
@@ -0,0 +1,30 @@
+package view
+
+type (
+	// ReqSaveK8sCluster 创建或更新 k8s 集群，ID 为 0 时创建。更新时 Token、Kubeconfig 为空则保留原凭证
+	ReqSaveK8sCluster struct {
+		ID         uint     `json:"id"`
+		Name       string   `json:"name" validate:"required"`
+		Env        []string `json:"env" validate:"required,min=1"`
+		ZoneCode   string   `json:"zone_code" validate:"required"`
+		ZoneName   string   `json:"zone_name" validate:"required"`
+		AuthType   string   `json:"auth_type" validate:"required,oneof=token kubeconfig"`
+		Server     string   `json:"server"` // Token 认证时必填
+		Token      string   `json:"token"`
+		Kubeconfig string   `json:"kubeconfig"`
+		CAData     string   `json:"ca_data"`
+		Insecure   bool     `json:"insecure"`
+		Namespace  string   `json:"namespace"`
+		AppLabel   string   `json:"app_label"`
+	}
+
+	ReqK8sClusterID struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	// RespImportK8sCluster 从系统设置导入集群的结果
+	RespImportK8sCluster struct {
+		Imported []string `json:"imported"`
+		Skipped  []string `json:"skipped"` // 同名集群已存在或未配置 API Server
+	}
+)
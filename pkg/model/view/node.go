@@ -1,6 +1,11 @@
 package view
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/douyu/juno/pkg/model/db"
+)
 
 // ReqNodeHeartBeat ..
 type ReqNodeHeartBeat struct {
@@ -11,11 +16,54 @@ type ReqNodeHeartBeat struct {
 	ZoneCode     string `json:"zone_code"`
 	ZoneName     string `json:"zone_name"`
 	AppName      string `json:"app_name"`
+	AppVersion   string `json:"app_version"` // 应用二进制版本，版本变化时记录部署历史
 	Env          string `json:"env"`
 	AgentType    int    `json:"agent_type"`
 	AgentVersion string `json:"agent_version"`
 	ProxyType    int    `json:"proxy_type"`
 	ProxyVersion string `json:"proxy_version"`
+
+	HostMetrics *HostMetrics `json:"host_metrics"` // agent上报的主机资源指标，proxy心跳为空
+	HostFacts   *HostFacts   `json:"host_facts"`   // 主机硬件、系统信息，注册时及信息变化时上报，其余心跳为空
+	LastError   string       `json:"last_error"`   // agent最近一次的错误，用于离线排查
+}
+
+// HostFacts 主机硬件、系统信息，用于自动填充节点记录
+type HostFacts struct {
+	CPUCount         int      `json:"cpu_count"`
+	MemTotal         uint64   `json:"mem_total"` // 字节
+	OS               string   `json:"os"`        // 发行版名称，如 CentOS Linux 7 (Core)
+	Kernel           string   `json:"kernel"`
+	Arch             string   `json:"arch"`
+	IPs              []string `json:"ips"`               // 全部非回环地址
+	ContainerRuntime string   `json:"container_runtime"` // docker、containerd、cri-o，未安装时为空
+}
+
+// HostMetrics 主机资源指标
+type HostMetrics struct {
+	CPUPercent  float64 `json:"cpu_percent"`
+	MemTotal    uint64  `json:"mem_total"`
+	MemUsed     uint64  `json:"mem_used"`
+	MemPercent  float64 `json:"mem_percent"`
+	DiskTotal   uint64  `json:"disk_total"`
+	DiskUsed    uint64  `json:"disk_used"`
+	DiskPercent float64 `json:"disk_percent"`
+	Load1       float64 `json:"load1"`
+	Load5       float64 `json:"load5"`
+	Load15      float64 `json:"load15"`
+}
+
+// NodeInfo 节点详情，附带最近一次上报的主机指标
+type NodeInfo struct {
+	db.Node
+	Metric *db.NodeMetric `json:"metric"` // agent最近一次上报的主机指标，没有上报时为null
+}
+
+// ReqNodeMetricList ..
+type ReqNodeMetricList struct {
+	HostName  string `query:"host_name" validate:"required"`
+	StartTime int64  `query:"start_time"` // 默认最近1小时
+	EndTime   int64  `query:"end_time"`
 }
 
 // ReqHTTPProxy ..
@@ -26,4 +74,6 @@ type ReqHTTPProxy struct {
 	Timeout int               `json:"timeout"` // seconds
 	Body    json.RawMessage   `json:"body"`
 	Params  map[string]string `json:"params"`
+	// Context 用于传递追踪上下文，不参与序列化
+	Context context.Context `json:"-"`
 }
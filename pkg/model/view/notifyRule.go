@@ -0,0 +1,58 @@
+package view
+
+import (
+	"time"
+)
+
+type (
+	ReqListNotifyRule struct {
+		Keyword string `query:"keyword"`
+	}
+
+	// ReqCreateNotifyRule 通知路由规则，EventTypes、Apps、Tags、Envs 为空时匹配全部
+	ReqCreateNotifyRule struct {
+		Name            string   `json:"name" validate:"required,max=64"`
+		Priority        int      `json:"priority"`
+		Enable          bool     `json:"enable"`
+		EventTypes      []string `json:"event_types"`
+		Apps            []string `json:"apps"`
+		Tags            []string `json:"tags"` // 应用拥有其中任一标签即匹配
+		Envs            []string `json:"envs"`
+		MinSeverity     string   `json:"min_severity" validate:"omitempty,oneof=info warning error"`
+		Channels        []string `json:"channels" validate:"required,min=1"`
+		Recipients      []string `json:"recipients"`
+		QuietStart      string   `json:"quiet_start"`
+		QuietEnd        string   `json:"quiet_end"`
+		SuppressSeconds int      `json:"suppress_seconds" validate:"min=0"`
+		Stop            bool     `json:"stop"`
+	}
+
+	ReqUpdateNotifyRule struct {
+		ID uint `json:"id" validate:"required"`
+		ReqCreateNotifyRule
+	}
+
+	ReqDeleteNotifyRule struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	NotifyRule struct {
+		ID              uint      `json:"id"`
+		Name            string    `json:"name"`
+		Priority        int       `json:"priority"`
+		Enable          bool      `json:"enable"`
+		EventTypes      []string  `json:"event_types"`
+		Apps            []string  `json:"apps"`
+		Tags            []string  `json:"tags"`
+		Envs            []string  `json:"envs"`
+		MinSeverity     string    `json:"min_severity"`
+		Channels        []string  `json:"channels"`
+		Recipients      []string  `json:"recipients"`
+		QuietStart      string    `json:"quiet_start"`
+		QuietEnd        string    `json:"quiet_end"`
+		SuppressSeconds int       `json:"suppress_seconds"`
+		Stop            bool      `json:"stop"`
+		CreatedAt       time.Time `json:"created_at"`
+		UpdatedAt       time.Time `json:"updated_at"`
+	}
+)
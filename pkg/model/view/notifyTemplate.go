@@ -0,0 +1,47 @@
+package view
+
+import (
+	"time"
+)
+
+type (
+	ReqListNotifyTemplate struct {
+		EventType string `query:"event_type"`
+	}
+
+	// ReqSaveNotifyTemplate 保存事件类型、渠道对应的模板，Channel 为空表示该事件类型的全部渠道
+	ReqSaveNotifyTemplate struct {
+		EventType string `json:"event_type" validate:"required,max=32"`
+		Channel   string `json:"channel" validate:"max=16"`
+		Subject   string `json:"subject" validate:"max=512"`
+		Body      string `json:"body"`
+	}
+
+	ReqDeleteNotifyTemplate struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	// ReqPreviewNotifyTemplate 使用示例事件渲染模板，App、Env、Severity、Content 为空时使用默认示例。
+	// 测试发送时 Channel 必填，邮件只发送给当前用户
+	ReqPreviewNotifyTemplate struct {
+		ReqSaveNotifyTemplate
+		App      string `json:"app"`
+		Env      string `json:"env"`
+		Severity string `json:"severity" validate:"omitempty,oneof=info warning error"`
+		Content  string `json:"content"`
+	}
+
+	NotifyTemplate struct {
+		ID        uint      `json:"id"`
+		EventType string    `json:"event_type"`
+		Channel   string    `json:"channel"`
+		Subject   string    `json:"subject"`
+		Body      string    `json:"body"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	RespPreviewNotifyTemplate struct {
+		Subject string `json:"subject"`
+		Content string `json:"content"`
+	}
+)
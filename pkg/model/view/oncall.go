@@ -0,0 +1,76 @@
+package view
+
+import (
+	"time"
+)
+
+type (
+	ReqOnCallRotation struct {
+		TeamID uint `query:"team_id"`
+	}
+
+	// ReqSetOnCallRotation 设置团队值班轮换，Uids 为轮换顺序，TeamID 为 0 表示平台值班
+	ReqSetOnCallRotation struct {
+		TeamID           uint  `json:"team_id"`
+		Uids             []int `json:"uids" validate:"required,min=1"`
+		ShiftHours       int   `json:"shift_hours" validate:"min=0"`
+		StartAt          int64 `json:"start_at"`
+		EscalateMinutes  int   `json:"escalate_minutes" validate:"min=0"`
+		EscalateToOwners bool  `json:"escalate_to_owners"`
+	}
+
+	ReqDeleteOnCallRotation struct {
+		TeamID uint `json:"team_id"`
+	}
+
+	OnCallUser struct {
+		Uid      int    `json:"uid"`
+		Username string `json:"username"`
+		Nickname string `json:"nickname"`
+	}
+
+	OnCallRotation struct {
+		TeamID           uint         `json:"team_id"`
+		TeamName         string       `json:"team_name"`
+		Users            []OnCallUser `json:"users"`
+		ShiftHours       int          `json:"shift_hours"`
+		StartAt          int64        `json:"start_at"`
+		EscalateMinutes  int          `json:"escalate_minutes"`
+		EscalateToOwners bool         `json:"escalate_to_owners"`
+		Primary          *OnCallUser  `json:"primary"`
+		Secondary        *OnCallUser  `json:"secondary"`
+		ShiftEndAt       int64        `json:"shift_end_at"`
+	}
+
+	// ReqListIncident 告警列表，TeamID 为 0 时不按团队过滤
+	ReqListIncident struct {
+		TeamID   uint   `query:"team_id"`
+		App      string `query:"app"`
+		Status   string `query:"status"`
+		Page     int    `query:"page"`
+		PageSize int    `query:"page_size"`
+	}
+
+	ReqAckIncident struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	Incident struct {
+		ID             uint      `json:"id"`
+		TeamID         uint      `json:"team_id"`
+		App            string    `json:"app"`
+		Env            string    `json:"env"`
+		Type           string    `json:"type"`
+		Severity       string    `json:"severity"`
+		Subject        string    `json:"subject"`
+		Content        string    `json:"content"`
+		Count          int       `json:"count"`
+		Status         string    `json:"status"`
+		Level          int       `json:"level"`
+		NextEscalateAt int64     `json:"next_escalate_at"`
+		AckUser        string    `json:"ack_user"`
+		AckTime        int64     `json:"ack_time"`
+		ResolveTime    int64     `json:"resolve_time"`
+		CreatedAt      time.Time `json:"created_at"`
+	}
+)
@@ -5,6 +5,23 @@ type Pagination struct {
 	Current  int `json:"current"`
 	Total    int `json:"total"`
 	PageSize int `json:"pageSize"`
+	// NextCursor 下一页的游标，没有更多数据时为空
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListQuery 列表接口通用的分页、排序、筛选参数
+type ListQuery struct {
+	Page     int    `query:"page"`
+	PageSize int    `query:"page_size"`
+	Cursor   string `query:"cursor"` // 上一页返回的 next_cursor，指定时忽略 page
+	Sort     string `query:"sort"`   // 逗号分隔，字段前加 - 表示倒序，如 -update_time,name
+	// Filter 筛选条件，可重复传入，条件之间为且的关系。格式为 字段 操作符 值，如 env=prod、name~juno
+	// 操作符支持 =、!=、>、>=、<、<=、~（包含）
+	Filter []string `query:"filter"`
+
+	// 兼容旧的分页参数
+	CurrentPage    int `query:"currentPage"`
+	LegacyPageSize int `query:"pageSize"`
 }
 
 func NewPagination(current int, pageSize int) *Pagination {
@@ -62,6 +62,17 @@ type (
 		Pagination Pagination    `json:"pagination"`
 	}
 
+	ReqGetGroupAppPolicy struct {
+		GroupName string `query:"group_name" valid:"required"`
+	}
+
+	// GroupAppPolicy 用户组在某应用某环境下的权限，应用名或环境为 * 表示全部
+	GroupAppPolicy struct {
+		AppName string   `json:"app_name"`
+		Env     string   `json:"env"`
+		Actions []string `json:"actions"`
+	}
+
 	AppPermItem struct {
 		Aid           int      `json:"aid"`
 		AppName       string   `json:"app_name"`
@@ -0,0 +1,65 @@
+package view
+
+type (
+	ReqListPolicy struct {
+		Sub      string `query:"sub"`
+		Obj      string `query:"obj"`
+		Type     string `query:"type"`
+		Page     int    `query:"page"`
+		PageSize int    `query:"page_size"`
+	}
+
+	// ReqCreatePolicy Sub 为用户组(group_user:name)或用户ID
+	ReqCreatePolicy struct {
+		Sub  string `json:"sub" validate:"required"`
+		Obj  string `json:"obj" validate:"required"`
+		Act  string `json:"act" validate:"required"`
+		Type string `json:"type" validate:"required,oneof=menu app api monitor"`
+	}
+
+	ReqUpdatePolicy struct {
+		ID uint `json:"id" validate:"required"`
+		ReqCreatePolicy
+	}
+
+	ReqDeletePolicy struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	ReqListRoleBinding struct {
+		Uid       int    `query:"uid"`
+		GroupName string `query:"group_name"`
+		Page      int    `query:"page"`
+		PageSize  int    `query:"page_size"`
+	}
+
+	ReqRoleBinding struct {
+		Uid       int    `json:"uid" validate:"required"`
+		GroupName string `json:"group_name" validate:"required,max=30"`
+	}
+
+	RoleBinding struct {
+		ID        uint   `json:"id"`
+		Uid       int    `json:"uid"`
+		Username  string `json:"username"`
+		GroupName string `json:"group_name"`
+	}
+
+	// ReqWhoCan 查询拥有某个权限的用户，不会修改任何策略。应用权限的 Obj 为 appname:env
+	ReqWhoCan struct {
+		Obj  string `query:"obj" validate:"required"`
+		Act  string `query:"act" validate:"required"`
+		Type string `query:"type" validate:"required,oneof=menu app api monitor"`
+	}
+
+	ReqCheckPolicy struct {
+		Uid int `query:"uid" validate:"required"`
+		ReqWhoCan
+	}
+
+	WhoCanItem struct {
+		Uid      int    `json:"uid"`
+		Username string `json:"username"`
+		Nickname string `json:"nickname"`
+	}
+)
@@ -0,0 +1,35 @@
+package view
+
+import (
+	"time"
+)
+
+type (
+	ReqCreatePersonalToken struct {
+		Name       string   `json:"name" validate:"required,max=64"`
+		Scopes     []string `json:"scopes" validate:"required,min=1"`
+		ExpireDays int      `json:"expire_days" validate:"min=0"` // 0 表示永不过期
+	}
+
+	ReqRevokePersonalToken struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	PersonalToken struct {
+		ID         uint       `json:"id"`
+		Name       string     `json:"name"`
+		Prefix     string     `json:"prefix"`
+		Scopes     []string   `json:"scopes"`
+		CreatedAt  time.Time  `json:"created_at"`
+		ExpiresAt  *time.Time `json:"expires_at"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+		LastUsedIP string     `json:"last_used_ip"`
+		RevokedAt  *time.Time `json:"revoked_at"`
+	}
+
+	// RespCreatePersonalToken Token 明文只在创建时返回一次
+	RespCreatePersonalToken struct {
+		PersonalToken
+		Token string `json:"token"`
+	}
+)
@@ -0,0 +1,108 @@
+package view
+
+import (
+	"time"
+)
+
+const (
+	// PromotionScopeMine 我发起的晋升
+	PromotionScopeMine = "mine"
+	// PromotionScopeReview 待我审批的晋升
+	PromotionScopeReview = "review"
+
+	PromotionDiffEqual  = "="
+	PromotionDiffAdd    = "+"
+	PromotionDiffDelete = "-"
+)
+
+type (
+	// ReqPromotionPreview 预览晋升，ID 为源配置或源流水线，配置未指定 Version 时使用最近发布的版本
+	ReqPromotionPreview struct {
+		ID         uint   `json:"id" query:"id" validate:"required"`
+		Version    string `json:"version" query:"version"`
+		TargetEnv  string `json:"target_env" query:"target_env" validate:"required"`
+		TargetZone string `json:"target_zone" query:"target_zone"` // 目标环境不存在同名配置或流水线时必填
+	}
+
+	ReqCreatePromotion struct {
+		ID         uint   `json:"id" validate:"required"`
+		Version    string `json:"version"`
+		TargetEnv  string `json:"target_env" validate:"required"`
+		TargetZone string `json:"target_zone"`
+		Reason     string `json:"reason" validate:"required,max=512"`
+	}
+
+	ReqListPromotion struct {
+		Scope    string `query:"scope" validate:"omitempty,oneof=mine review"`
+		Kind     string `query:"kind" validate:"omitempty,oneof=config pipeline"`
+		Status   string `query:"status"`
+		AppName  string `query:"app_name"`
+		Page     int    `query:"page"`
+		PageSize int    `query:"page_size"`
+	}
+
+	ReqReviewPromotion struct {
+		ID      uint   `json:"id" validate:"required"`
+		Approve bool   `json:"approve"`
+		Comment string `json:"comment" validate:"max=512"`
+	}
+
+	ReqPromotionID struct {
+		ID uint `json:"id" query:"id" validate:"required"`
+	}
+
+	// PromotionDiffLine 按行比较的结果，Type 为 =、+、-
+	PromotionDiffLine struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+
+	RespPromotionPreview struct {
+		Kind          string              `json:"kind"`
+		AppName       string              `json:"app_name"`
+		Name          string              `json:"name"`
+		SourceEnv     string              `json:"source_env"`
+		SourceZone    string              `json:"source_zone"`
+		SourceVersion string              `json:"source_version"`
+		TargetID      uint                `json:"target_id"`
+		TargetEnv     string              `json:"target_env"`
+		TargetZone    string              `json:"target_zone"`
+		Content       string              `json:"content"`
+		TargetContent string              `json:"target_content"`
+		Changed       bool                `json:"changed"`
+		Diff          []PromotionDiffLine `json:"diff"`
+	}
+
+	Promotion struct {
+		ID            uint       `json:"id"`
+		Kind          string     `json:"kind"`
+		AppName       string     `json:"app_name"`
+		Name          string     `json:"name"`
+		SourceID      uint       `json:"source_id"`
+		SourceEnv     string     `json:"source_env"`
+		SourceZone    string     `json:"source_zone"`
+		SourceVersion string     `json:"source_version"`
+		TargetID      uint       `json:"target_id"`
+		TargetEnv     string     `json:"target_env"`
+		TargetZone    string     `json:"target_zone"`
+		Reason        string     `json:"reason"`
+		Status        string     `json:"status"`
+		Uid           int        `json:"uid"`
+		Username      string     `json:"username"`
+		ReviewerUid   int        `json:"reviewer_uid"`
+		Reviewer      string     `json:"reviewer"`
+		ReviewComment string     `json:"review_comment"`
+		ReviewedAt    *time.Time `json:"reviewed_at"`
+		AppliedID     uint       `json:"applied_id"`
+		Error         string     `json:"error"`
+		CreatedAt     time.Time  `json:"created_at"`
+	}
+
+	// RespPromotionDetail 晋升详情，Diff 为申请时目标环境内容与晋升内容的比较
+	RespPromotionDetail struct {
+		Promotion
+		Content       string              `json:"content"`
+		TargetContent string              `json:"target_content"`
+		Diff          []PromotionDiffLine `json:"diff"`
+	}
+)
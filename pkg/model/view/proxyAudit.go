@@ -0,0 +1,17 @@
+package view
+
+type (
+	ReqListProxyAudit struct {
+		Kind      string `query:"kind"`
+		UserName  string `query:"user_name"`
+		AppName   string `query:"app_name"`
+		Env       string `query:"env"`
+		Target    string `query:"target"`
+		Path      string `query:"path"` // 路径前缀
+		StartTime int64  `query:"start_time"`
+		EndTime   int64  `query:"end_time"`
+
+		Page     int `query:"page"`
+		PageSize int `query:"page_size"`
+	}
+)
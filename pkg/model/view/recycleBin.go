@@ -0,0 +1,16 @@
+package view
+
+type (
+	ReqListRecycleItem struct {
+		Kind    string `query:"kind"`
+		AppName string `query:"app_name"`
+		// Mine 只返回当前用户删除的对象
+		Mine bool `query:"mine"`
+
+		ListQuery
+	}
+
+	ReqRecycleItemID struct {
+		ID uint `json:"id" validate:"required"`
+	}
+)
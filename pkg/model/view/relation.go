@@ -27,3 +27,45 @@ type ReqRelationApps struct {
 	Types    []string `json:"types"`
 	AddrList []string `json:"addrList"`
 }
+
+// ReqAppDependency 应用依赖拓扑，zone_code 不为空时同时查询该机房注册中心的服务提供者
+type ReqAppDependency struct {
+	AppName  string `query:"app_name" validate:"required"`
+	Env      string `query:"env" validate:"required"`
+	ZoneCode string `query:"zone_code"`
+}
+
+// RespAppDependency 应用依赖拓扑，Nodes、Edges 供前端绘制关系图
+type RespAppDependency struct {
+	AppName         string              `json:"app_name"`
+	Upstreams       []string            `json:"upstreams"`   // 调用该应用的应用
+	Downstreams     []string            `json:"downstreams"` // 该应用调用的应用
+	SharedResources []AppSharedResource `json:"shared_resources"`
+	Nodes           []AppDependencyNode `json:"nodes"`
+	Edges           []AppDependencyEdge `json:"edges"`
+	Errors          []string            `json:"errors"`
+}
+
+// AppSharedResource 与其他应用共用的数据库、缓存、配置资源等
+type AppSharedResource struct {
+	Type string   `json:"type"`
+	Addr string   `json:"addr"`
+	Apps []string `json:"apps"`
+}
+
+// AppDependencyNode 节点类型 app 或 resource
+type AppDependencyNode struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Type     string `json:"type"`
+	Current  bool   `json:"current"`
+}
+
+// AppDependencyEdge 应用调用应用，或应用使用资源
+type AppDependencyEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+	Name   string `json:"name"` // 配置中的 key
+}
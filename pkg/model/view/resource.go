@@ -3,10 +3,19 @@ package view
 import "github.com/douyu/juno/pkg/model/db"
 
 type (
+	// AppListFilter 应用列表筛选条件，多个条件同时满足
+	AppListFilter struct {
+		Meta     []string // name=value 形式的自定义字段筛选条件
+		Tags     []string // 同时拥有全部标签的应用
+		ZoneCode string   // 部署在该机房的应用
+	}
+
 	ReqAppListWithEnv struct {
-		SearchText string `query:"searchText"`
-		Page       int    `query:"page"`
-		PageSize   int    `query:"pageSize"`
+		SearchText   string `query:"searchText"`
+		KeywordsType string `query:"keywords_type"` // app_name、aid、name、owner、repo，为空时匹配应用名、中文名、仓库地址和负责人
+		Sort         string `query:"sort"`          // 逗号分隔，字段前加 - 表示倒序，如 -update_time,app_name
+		Page         int    `query:"page"`
+		PageSize     int    `query:"pageSize"`
 	}
 
 	RespAppListWithEnv struct {
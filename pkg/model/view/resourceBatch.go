@@ -0,0 +1,80 @@
+package view
+
+// 批量导入每行的处理结果
+const (
+	BatchActionCreate    = "create"
+	BatchActionUpdate    = "update"
+	BatchActionUnchanged = "unchanged"
+	BatchActionInvalid   = "invalid"
+)
+
+type (
+	// ReqBatchZone 批量创建、更新可用区，环境随可用区创建。preview 为 true 时只校验不写入
+	ReqBatchZone struct {
+		List    []BatchZoneItem `json:"list" validate:"required,min=1"`
+		Preview bool            `json:"preview"`
+	}
+
+	// BatchZoneItem 按 env + zone_code 确定可用区，已存在时更新地域和名称
+	BatchZoneItem struct {
+		Line       int    `json:"line"` // CSV 行号，接口提交时为列表序号
+		Env        string `json:"env"`
+		RegionCode string `json:"region_code"`
+		RegionName string `json:"region_name"`
+		ZoneCode   string `json:"zone_code"`
+		ZoneName   string `json:"zone_name"`
+	}
+
+	// ReqBatchNode 批量创建、更新机器节点。preview 为 true 时只校验不写入
+	ReqBatchNode struct {
+		List    []BatchNodeItem `json:"list" validate:"required,min=1"`
+		Preview bool            `json:"preview"`
+	}
+
+	// BatchNodeItem 按 host_name 确定节点，地域和可用区名称取自已存在的可用区
+	BatchNodeItem struct {
+		Line     int    `json:"line"`
+		HostName string `json:"host_name"`
+		IP       string `json:"ip"`
+		Env      string `json:"env"`
+		ZoneCode string `json:"zone_code"`
+	}
+
+	// RespBatchResource 批量处理结果，存在校验失败的行时不写入任何数据
+	RespBatchResource struct {
+		Preview   bool               `json:"preview"`
+		Applied   bool               `json:"applied"`
+		Total     int                `json:"total"`
+		Create    int                `json:"create"`
+		Update    int                `json:"update"`
+		Unchanged int                `json:"unchanged"`
+		Invalid   int                `json:"invalid"`
+		Rows      []BatchResourceRow `json:"rows"`
+	}
+
+	BatchResourceRow struct {
+		Line   int      `json:"line"`
+		Key    string   `json:"key"`
+		Action string   `json:"action"`
+		Errors []string `json:"errors"`
+	}
+)
+
+// Add 记录一行的处理结果
+func (r *RespBatchResource) Add(row BatchResourceRow) {
+	if row.Errors == nil {
+		row.Errors = make([]string, 0)
+	}
+	r.Total++
+	switch row.Action {
+	case BatchActionCreate:
+		r.Create++
+	case BatchActionUpdate:
+		r.Update++
+	case BatchActionUnchanged:
+		r.Unchanged++
+	case BatchActionInvalid:
+		r.Invalid++
+	}
+	r.Rows = append(r.Rows, row)
+}
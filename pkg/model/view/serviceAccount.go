@@ -0,0 +1,60 @@
+package view
+
+import (
+	"time"
+)
+
+type (
+	ReqCreateServiceAccount struct {
+		Name        string   `json:"name" validate:"required,max=64"`
+		Description string   `json:"description" validate:"max=255"`
+		Scopes      []string `json:"scopes" validate:"required,min=1"`
+	}
+
+	ReqUpdateServiceAccount struct {
+		ID          uint     `json:"id" validate:"required"`
+		Description string   `json:"description" validate:"max=255"`
+		Scopes      []string `json:"scopes" validate:"required,min=1"`
+		Disabled    bool     `json:"disabled"`
+	}
+
+	ReqServiceAccountID struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	// ReqRotateServiceAccount 轮换凭证，旧凭证在 GraceMinutes 后失效，为 0 时立即失效
+	ReqRotateServiceAccount struct {
+		ID           uint `json:"id" validate:"required"`
+		GraceMinutes int  `json:"grace_minutes" validate:"min=0,max=10080"`
+	}
+
+	ReqRevokeServiceAccountCredential struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	ServiceAccount struct {
+		ID          uint                       `json:"id"`
+		Name        string                     `json:"name"`
+		Description string                     `json:"description"`
+		Scopes      []string                   `json:"scopes"`
+		Disabled    bool                       `json:"disabled"`
+		CreatedAt   time.Time                  `json:"created_at"`
+		LastUsedAt  *time.Time                 `json:"last_used_at"`
+		LastUsedIP  string                     `json:"last_used_ip"`
+		Credentials []ServiceAccountCredential `json:"credentials"`
+	}
+
+	ServiceAccountCredential struct {
+		ID        uint       `json:"id"`
+		Prefix    string     `json:"prefix"`
+		CreatedAt time.Time  `json:"created_at"`
+		ExpiresAt *time.Time `json:"expires_at"`
+		RevokedAt *time.Time `json:"revoked_at"`
+	}
+
+	// RespServiceAccountToken 凭证明文只在创建、轮换时返回一次
+	RespServiceAccountToken struct {
+		ServiceAccount
+		Token string `json:"token"`
+	}
+)
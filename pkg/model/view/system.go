@@ -10,13 +10,20 @@ import (
 )
 
 const (
-	VersionSettingName      string = "version"
-	ConfigDepSettingName    string = "config_dep"
-	EtcdSettingName         string = "etcd"
-	GrafanaSettingName      string = "grafana"
-	GatewaySettingName      string = "gateway"
-	K8SClusterSettingName   string = "k8s_cluster"
-	TestPlatformSettingName string = "test_platform"
+	VersionSettingName       string = "version"
+	ConfigDepSettingName     string = "config_dep"
+	EtcdSettingName          string = "etcd"
+	GrafanaSettingName       string = "grafana"
+	GatewaySettingName       string = "gateway"
+	K8SClusterSettingName    string = "k8s_cluster"
+	TestPlatformSettingName  string = "test_platform"
+	LicensePolicySettingName string = "license_policy"
+)
+
+// 许可证策略的违规处理方式
+const (
+	LicenseActionFail = "fail" // 阶段失败
+	LicenseActionWarn = "warn" // 只提示
 )
 
 var (
@@ -113,6 +120,35 @@ var (
 					return err
 				}
 
+				return nil
+			},
+		},
+		LicensePolicySettingName: {
+			Default: "{\"allow\":[],\"deny\":[],\"action\":\"warn\"}",
+			Validate: func(value string) error {
+				data := SettingLicensePolicy{}
+
+				err := json.Unmarshal([]byte(value), &data)
+				if err != nil {
+					return err
+				}
+
+				err = validator.New().Struct(&data)
+				if err != nil {
+					return err
+				}
+
+				// 同一个许可证不能既允许又禁止
+				deny := make(map[string]bool, len(data.Deny))
+				for _, id := range data.Deny {
+					deny[strings.ToLower(id)] = true
+				}
+				for _, id := range data.Allow {
+					if deny[strings.ToLower(id)] {
+						return fmt.Errorf("许可证 %s 同时在允许和禁止列表中", id)
+					}
+				}
+
 				return nil
 			},
 		},
@@ -188,17 +224,40 @@ type (
 	}
 
 	SettingK8SCluster struct {
-		List []struct {
-			Name     string   `json:"name" validate:"required"`
-			Env      []string `json:"env" validate:"required"`
-			ZoneCode string   `json:"zone_code" validate:"required"`
-			ZoneName string   `json:"zone_name" validate:"required"`
-		} `json:"list"`
+		List []SettingK8SClusterItem `json:"list"`
+	}
+
+	SettingK8SClusterItem struct {
+		Name     string   `json:"name" validate:"required"`
+		Env      []string `json:"env" validate:"required"`
+		ZoneCode string   `json:"zone_code" validate:"required"`
+		ZoneName string   `json:"zone_name" validate:"required"`
+
+		// 以下配置已由集群管理接管，仅用于导入到 k8s_cluster，凭证会被加密保存
+		Server    string `json:"server"`    // API Server 地址
+		Token     string `json:"token"`     // 只读 ServiceAccount 的 Token
+		CAData    string `json:"ca_data"`   // API Server 证书的 CA，PEM 格式
+		Insecure  bool   `json:"insecure"`  // 跳过证书校验
+		Namespace string `json:"namespace"` // 应用所在命名空间，为空时使用 default
+		AppLabel  string `json:"app_label"` // 工作负载上标识应用名的标签，为空时使用 app
 	}
 
 	SettingTestPlatform struct {
 		Enable bool
 	}
+
+	// SettingLicensePolicy 依赖许可证策略，许可证使用 SPDX ID，如 MIT、Apache-2.0，
+	// 无法识别的许可证为 NOASSERTION
+	SettingLicensePolicy struct {
+		Allow  []string `json:"allow"`                                       // 允许的许可证，为空时不限制
+		Deny   []string `json:"deny"`                                        // 禁止的许可证
+		Action string   `json:"action" validate:"omitempty,oneof=fail warn"` // 违规时的处理，为空时只提示
+	}
+
+	// ReqSystemBackup 导出备份，备份中有加密的凭证时 key 必填
+	ReqSystemBackup struct {
+		Key string `json:"key"`
+	}
 )
 
 func CheckSettingNameValid(settingName string) bool {
@@ -0,0 +1,36 @@
+package view
+
+type (
+	// ReqSetAppTags 覆盖应用的全部标签，Tags 为空时清空
+	ReqSetAppTags struct {
+		AppName string   `json:"app_name" validate:"required"`
+		Tags    []string `json:"tags"`
+	}
+
+	ReqSetNodeTags struct {
+		HostName string   `json:"host_name" validate:"required"`
+		Tags     []string `json:"tags"`
+	}
+
+	ReqSetPipelineTags struct {
+		ID   uint     `json:"id" validate:"required"`
+		Tags []string `json:"tags"`
+	}
+
+	ReqEntityTags struct {
+		EntityType string `query:"entity_type" validate:"required,oneof=app node pipeline"`
+		EntityKey  string `query:"entity_key" validate:"required"`
+	}
+
+	// ReqSuggestTags 标签自动补全，按使用次数倒序
+	ReqSuggestTags struct {
+		EntityType string `query:"entity_type" validate:"omitempty,oneof=app node pipeline"`
+		Prefix     string `query:"prefix"`
+		Limit      int    `query:"limit" validate:"min=0,max=100"`
+	}
+
+	TagCount struct {
+		Tag   string `json:"tag"`
+		Count int    `json:"count"`
+	}
+)
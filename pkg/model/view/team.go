@@ -0,0 +1,77 @@
+package view
+
+import (
+	"time"
+)
+
+type (
+	ReqListTeam struct {
+		Keyword  string `query:"keyword"`
+		Page     int    `query:"page"`
+		PageSize int    `query:"page_size"`
+	}
+
+	ReqTeamDetail struct {
+		ID uint `query:"id" validate:"required"`
+	}
+
+	ReqCreateTeam struct {
+		Name           string   `json:"name" validate:"required,max=64"`
+		Description    string   `json:"description" validate:"max=255"`
+		DefaultActions []string `json:"default_actions"`
+		DingWebhook    string   `json:"ding_webhook" validate:"max=512"`
+		// MandatorySeverity 达到该级别的通知不受成员个人通知偏好影响
+		MandatorySeverity string `json:"mandatory_severity" validate:"omitempty,oneof=info warning error"`
+	}
+
+	ReqUpdateTeam struct {
+		ID uint `json:"id" validate:"required"`
+		ReqCreateTeam
+	}
+
+	ReqDeleteTeam struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	ReqSetTeamMember struct {
+		TeamID uint   `json:"team_id" validate:"required"`
+		Uid    int    `json:"uid" validate:"required"`
+		Role   string `json:"role" validate:"required,oneof=owner member"`
+	}
+
+	ReqRemoveTeamMember struct {
+		TeamID uint `json:"team_id" validate:"required"`
+		Uid    int  `json:"uid" validate:"required"`
+	}
+
+	// ReqSetAppTeam 设置应用所属团队，TeamID 为 0 表示取消归属
+	ReqSetAppTeam struct {
+		AppName string `json:"app_name" validate:"required"`
+		TeamID  uint   `json:"team_id"`
+	}
+
+	Team struct {
+		ID                uint      `json:"id"`
+		Name              string    `json:"name"`
+		Description       string    `json:"description"`
+		DefaultActions    []string  `json:"default_actions"`
+		DingWebhook       string    `json:"ding_webhook"`
+		MandatorySeverity string    `json:"mandatory_severity"`
+		MemberCount       int       `json:"member_count"`
+		AppCount          int       `json:"app_count"`
+		CreatedAt         time.Time `json:"created_at"`
+	}
+
+	TeamMember struct {
+		Uid      int    `json:"uid"`
+		Username string `json:"username"`
+		Nickname string `json:"nickname"`
+		Role     string `json:"role"`
+	}
+
+	TeamDetail struct {
+		Team
+		Members []TeamMember `json:"members"`
+		Apps    []string     `json:"apps"`
+	}
+)
@@ -20,9 +20,36 @@ type (
 		Branch             string                   `json:"branch" validate:"required,min=1,max=32"`
 		CodeCheck          bool                     `json:"code_check"`
 		UnitTest           bool                     `json:"unit_test"`
-		HttpTestCollection *int                     `json:"http_test_collection"` // http 测试集合
+		UnitTestShards     int                      `json:"unit_test_shards" validate:"min=0,max=32"`                            // 单元测试分片数，大于 1 时拆分到多个 worker 并行执行
+		UnitTestAffected   bool                     `json:"unit_test_affected"`                                                  // 只执行相对 BaseBranch 变更影响的包
+		UnitTestRace       bool                     `json:"unit_test_race"`                                                      // 单元测试开启 -race 数据竞争检测
+		UnitTestCoverage   bool                     `json:"unit_test_coverage"`                                                  // 上传覆盖率文件和 HTML 覆盖率报告
+		BaseBranch         string                   `json:"base_branch" validate:"max=64"`                                       // 影响分析的基准分支，默认 master
+		FullRunHours       int                      `json:"full_run_hours" validate:"min=0"`                                     // 距上次全量执行超过该小时数时执行全部测试，为 0 时不定期全量执行
+		VulnCheck          bool                     `json:"vuln_check"`                                                          // 依赖漏洞扫描
+		VulnGate           string                   `json:"vuln_gate" validate:"omitempty,oneof=critical high moderate low any"` // 存在该级别及以上、且实际调用的漏洞时流水线失败
+		LicenseCheck       bool                     `json:"license_check"`                                                       // 依赖许可证合规检查
+		BuildReport        bool                     `json:"build_report"`                                                        // 构建服务二进制，记录大小和构建耗时
+		BuildPackage       string                   `json:"build_package" validate:"max=128"`                                    // 构建的 main 包，为空时自动选择
+		CrossBuild         bool                     `json:"cross_build"`                                                         // 交叉编译检查
+		CrossBuildTargets  string                   `json:"cross_build_targets" validate:"max=255"`                              // 逗号分隔的 GOOS/GOARCH，如 linux/arm64,windows/amd64，为空时使用默认平台
+		BuildCheck         bool                     `json:"build_check"`                                                         // 拉取代码后先检查能否编译
+		BuildCommand       string                   `json:"build_command" validate:"max=255"`                                    // 编译检查执行的命令，为空时执行 go build ./...
+		Benchmark          bool                     `json:"benchmark"`                                                           // 执行基准测试，与同一分支上一次的结果对比
+		BenchmarkPackages  string                   `json:"benchmark_packages" validate:"max=255"`                               // 空格分隔的包，为空时为 ./...
+		BenchmarkFlags     string                   `json:"benchmark_flags" validate:"max=255"`                                  // 额外的 go test 参数，如 -bench=. -count=3
+		GoVersion          string                   `json:"go_version" validate:"max=32"`                                        // Go 版本，如 1.16.15，为空时使用 worker 默认的 go
+		StepTimeout        int                      `json:"step_timeout" validate:"min=0,max=86400"`                             // 各阶段的超时时间（秒），为 0 时使用 worker 的默认值
+		StepRetries        int                      `json:"step_retries" validate:"min=0,max=5"`                                 // 阶段失败后的重试次数
+		StepRetryDelay     int                      `json:"step_retry_delay" validate:"min=0,max=600"`                           // 第一次重试前等待的时间（秒），之后每次翻倍
+		Priority           int                      `json:"priority" validate:"min=-10,max=10"`                                  // 任务在 worker 队列中的优先级，数值大的先执行，默认为 0
+		HttpTestCollection *int                     `json:"http_test_collection"`                                                // http 测试集合
 		GrpcTestAddr       string                   `json:"grpc_test_addr"`
 		GrpcTestCases      db.PipelineGrpcTestCases `json:"grpc_test_cases"` // GRPC 测试用例列表
+		WorkerLabels       db.MapStringString       `json:"worker_labels"`   // 执行任务的 worker 需要具有的标签，如 arch=arm64
+		StepArtifacts      db.MapStringArray        `json:"step_artifacts"`  // 各阶段结束后上传的制品，阶段名 => 代码目录下的相对路径，如 unit_test => ["coverage.xml", "reports/**/*.html"]
+		StepEnv            db.MapStringMap          `json:"step_env"`        // 各阶段命令的环境变量，阶段名 => 变量名 => 值，值中可以使用 ${{ secrets.NAME }} 引用密钥
+		StepWhen           db.MapStringString       `json:"step_when"`       // 各阶段的执行条件，阶段名 => 条件，如 cross_build => branch == "master" && event != "merge_request"
 	}
 
 	TestPipelineUV struct {
@@ -34,12 +61,41 @@ type (
 		Branch             string                   `json:"branch" validate:"required,min=1,max=32"`
 		CodeCheck          bool                     `json:"code_check"`
 		UnitTest           bool                     `json:"unit_test"`
-		HttpTestCollection *int                     `json:"http_test_collection"` // http 测试集合
+		UnitTestShards     int                      `json:"unit_test_shards" validate:"min=0,max=32"`                            // 单元测试分片数，大于 1 时拆分到多个 worker 并行执行
+		UnitTestAffected   bool                     `json:"unit_test_affected"`                                                  // 只执行相对 BaseBranch 变更影响的包
+		UnitTestRace       bool                     `json:"unit_test_race"`                                                      // 单元测试开启 -race 数据竞争检测
+		UnitTestCoverage   bool                     `json:"unit_test_coverage"`                                                  // 上传覆盖率文件和 HTML 覆盖率报告
+		BaseBranch         string                   `json:"base_branch" validate:"max=64"`                                       // 影响分析的基准分支，默认 master
+		FullRunHours       int                      `json:"full_run_hours" validate:"min=0"`                                     // 距上次全量执行超过该小时数时执行全部测试，为 0 时不定期全量执行
+		VulnCheck          bool                     `json:"vuln_check"`                                                          // 依赖漏洞扫描
+		VulnGate           string                   `json:"vuln_gate" validate:"omitempty,oneof=critical high moderate low any"` // 存在该级别及以上、且实际调用的漏洞时流水线失败
+		LicenseCheck       bool                     `json:"license_check"`                                                       // 依赖许可证合规检查
+		BuildReport        bool                     `json:"build_report"`                                                        // 构建服务二进制，记录大小和构建耗时
+		BuildPackage       string                   `json:"build_package" validate:"max=128"`                                    // 构建的 main 包，为空时自动选择
+		CrossBuild         bool                     `json:"cross_build"`                                                         // 交叉编译检查
+		CrossBuildTargets  string                   `json:"cross_build_targets" validate:"max=255"`                              // 逗号分隔的 GOOS/GOARCH，如 linux/arm64,windows/amd64，为空时使用默认平台
+		BuildCheck         bool                     `json:"build_check"`                                                         // 拉取代码后先检查能否编译
+		BuildCommand       string                   `json:"build_command" validate:"max=255"`                                    // 编译检查执行的命令，为空时执行 go build ./...
+		Benchmark          bool                     `json:"benchmark"`                                                           // 执行基准测试，与同一分支上一次的结果对比
+		BenchmarkPackages  string                   `json:"benchmark_packages" validate:"max=255"`                               // 空格分隔的包，为空时为 ./...
+		BenchmarkFlags     string                   `json:"benchmark_flags" validate:"max=255"`                                  // 额外的 go test 参数，如 -bench=. -count=3
+		GoVersion          string                   `json:"go_version" validate:"max=32"`                                        // Go 版本，如 1.16.15，为空时使用 worker 默认的 go
+		StepTimeout        int                      `json:"step_timeout" validate:"min=0,max=86400"`                             // 各阶段的超时时间（秒），为 0 时使用 worker 的默认值
+		StepRetries        int                      `json:"step_retries" validate:"min=0,max=5"`                                 // 阶段失败后的重试次数
+		StepRetryDelay     int                      `json:"step_retry_delay" validate:"min=0,max=600"`                           // 第一次重试前等待的时间（秒），之后每次翻倍
+		Priority           int                      `json:"priority" validate:"min=-10,max=10"`                                  // 任务在 worker 队列中的优先级，数值大的先执行，默认为 0
+		HttpTestCollection *int                     `json:"http_test_collection"`                                                // http 测试集合
 		GrpcTestAddr       string                   `json:"grpc_test_addr"`
 		GrpcTestCases      db.PipelineGrpcTestCases `json:"grpc_test_cases"` // GRPC 测试用例列表
+		WorkerLabels       db.MapStringString       `json:"worker_labels"`   // 执行任务的 worker 需要具有的标签，如 arch=arm64
+		StepArtifacts      db.MapStringArray        `json:"step_artifacts"`  // 各阶段结束后上传的制品，阶段名 => 代码目录下的相对路径，如 unit_test => ["coverage.xml", "reports/**/*.html"]
+		StepEnv            db.MapStringMap          `json:"step_env"`        // 各阶段命令的环境变量，阶段名 => 变量名 => 值，值中可以使用 ${{ secrets.NAME }} 引用密钥
+		StepWhen           db.MapStringString       `json:"step_when"`       // 各阶段的执行条件，阶段名 => 条件，如 cross_build => branch == "master" && event != "merge_request"
 		Desc               db.TestPipelineDesc      `json:"desc"`
 		Status             db.TestTaskStatus        `json:"status"`
 		RunCount           int                      `json:"run_count"`
+		LastFullRunAt      *time.Time               `json:"last_full_run_at"`
+		Tags               []string                 `json:"tags,omitempty"`
 	}
 
 	ReqUpdatePipeline struct {
@@ -59,6 +115,37 @@ type (
 		GitUrl    string              `json:"git_url"`
 		Status    db.TestTaskStatus   `json:"status"`
 		CreatedAt time.Time           `json:"created_at"`
+		// Logs 任务日志，只在查询任务详情时返回
+		Logs string `json:"logs,omitempty"`
+		// Trace 追踪上下文，任务经过队列异步执行时用于关联链路
+		Trace map[string]string `json:"trace,omitempty"`
+		// Part 任务拆分下发时的部分序号，从 1 开始，为 0 时是完整的任务。
+		// 拆分的任务如单元测试分片，任务结果由 Juno 汇总全部阶段的状态得出
+		Part int `json:"part,omitempty"`
+		// QueuedAt worker 首次入队的时间，用于计算排队时间
+		QueuedAt time.Time `json:"queued_at"`
+		// GoVersion 执行任务实际使用的 Go 版本，只在查询任务时返回
+		GoVersion string `json:"go_version,omitempty"`
+		// Requeued worker 执行任务时 panic 后重新入队过，再次 panic 时标记失败
+		Requeued bool `json:"requeued,omitempty"`
+		// Priority worker 队列中的优先级，数值大的先执行，默认为 0。只有本地队列支持优先级
+		Priority int `json:"priority,omitempty"`
+		// WorkerLabels 执行任务的 worker 需要具有的标签，共享队列中标签不匹配的任务留给其他 worker
+		WorkerLabels map[string]string `json:"worker_labels,omitempty"`
+		// Event 触发任务的事件，如 manual、push、merge_request，worker 用于计算阶段的执行条件
+		Event string `json:"event,omitempty"`
+		// StepDeadline worker 执行阶段时设置的截止时间，超过时终止阶段启动的命令，不在 Juno 和 worker 之间传递
+		StepDeadline time.Time `json:"-"`
+		// StepRetryPending 阶段失败后还会重试，worker 把阶段的失败上报为执行中，不在 Juno 和 worker 之间传递
+		StepRetryPending bool `json:"-"`
+		// StepEnv worker 执行阶段时设置的环境变量，已替换密钥引用，不在 Juno 和 worker 之间传递
+		StepEnv map[string]string `json:"-"`
+		// CommitSHA 触发任务的提交，只在查询任务时返回
+		CommitSHA string `json:"commit_sha,omitempty"`
+		// SupersededBy 任务被同一提交的新任务取代时，取代它的任务 ID，只在查询任务时返回
+		SupersededBy uint `json:"superseded_by,omitempty"`
+		// Artifacts 任务的制品，通过 /pipeline/tasks/artifact 下载，只在查询任务详情时返回
+		Artifacts []TestTaskArtifact `json:"artifacts,omitempty"`
 	}
 
 	TestTaskEvent struct {
@@ -68,6 +155,19 @@ type (
 		Data json.RawMessage `json:"data"`
 	}
 
+	// TestTaskStreamMessage worker 通过 WebSocket 长连接上报的任务事件，Seq 用于匹配 Juno 的确认
+	TestTaskStreamMessage struct {
+		Seq   uint64        `json:"seq"`
+		Event TestTaskEvent `json:"event"`
+	}
+
+	// TestTaskStreamAck Juno 处理完事件后的确认，Code、Msg 与 HTTP 上报时的响应相同
+	TestTaskStreamAck struct {
+		Seq  uint64 `json:"seq"`
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+
 	TestTaskStepUpdatePayload struct {
 		StepName   string            `json:"step_name"`
 		Status     db.TestStepStatus `json:"status"`
@@ -77,6 +177,282 @@ type (
 	TestTaskUpdateEventPayload struct {
 		Status     db.TestTaskStatus `json:"status"`
 		LogsAppend string            `json:"logs"`
+		// GoVersion worker 实际使用的 Go 版本，开始执行时上报
+		GoVersion string `json:"go_version,omitempty"`
+	}
+
+	// TestTaskArtifactPayload 任务产出的制品，同一任务下同名制品会被覆盖。
+	// 已经上传到对象存储时 StorageKey 为 TestTaskArtifactUpload 中的 Key，Content 为空
+	TestTaskArtifactPayload struct {
+		StepName    string `json:"step_name"`
+		Name        string `json:"name"`
+		ContentType string `json:"content_type"`
+		Content     []byte `json:"content"`
+		StorageKey  string `json:"storage_key,omitempty"`
+	}
+
+	// ReqPrepareTaskArtifact worker 上传制品前申请上传地址
+	ReqPrepareTaskArtifact struct {
+		TaskID      uint   `json:"task_id" validate:"required"`
+		StepName    string `json:"step_name"`
+		Name        string `json:"name" validate:"required,max=255"`
+		ContentType string `json:"content_type"`
+		Size        int64  `json:"size" validate:"min=0"`
+	}
+
+	// TestTaskArtifactUpload 制品的上传地址，Storage 为 false 时没有配置对象存储，制品内容直接随事件上报
+	TestTaskArtifactUpload struct {
+		Storage bool   `json:"storage"`
+		URL     string `json:"url,omitempty"` // 使用 PUT 上传的预签名地址
+		Key     string `json:"key,omitempty"`
+	}
+
+	// ReqTaskSecrets worker 开始执行任务时获取任务引用的密钥
+	ReqTaskSecrets struct {
+		TaskID uint `query:"task_id" validate:"required"`
+	}
+
+	// ReqListTestSecret 应用在环境下的流水线密钥
+	ReqListTestSecret struct {
+		AppName string `query:"app_name" validate:"required"`
+		Env     string `query:"env" validate:"required"`
+	}
+
+	// ReqSaveTestSecret 创建或更新密钥，已存在同名密钥时覆盖
+	ReqSaveTestSecret struct {
+		AppName string `json:"app_name" validate:"required"`
+		Env     string `json:"env" validate:"required"`
+		Name    string `json:"name" validate:"required,max=64"`
+		Value   string `json:"value" validate:"required"`
+	}
+
+	ReqDeleteTestSecret struct {
+		AppName string `json:"app_name" validate:"required"`
+		Env     string `json:"env" validate:"required"`
+		Name    string `json:"name" validate:"required"`
+	}
+
+	// TestSecret 密钥的值保存后不再返回
+	TestSecret struct {
+		Name      string    `json:"name"`
+		UpdatedAt time.Time `json:"updated_at"`
+		UpdatedBy uint      `json:"updated_by"`
+	}
+
+	// TestTaskTimingPayload 单元测试各个包的耗时，用于下次分片
+	TestTaskTimingPayload struct {
+		Packages map[string]float64 `json:"packages"` // 包名 -> 耗时（秒）
+	}
+
+	// TestTaskVulnerabilityPayload 漏洞扫描结果，同一阶段重复上报时覆盖
+	TestTaskVulnerabilityPayload struct {
+		StepName        string          `json:"step_name"`
+		Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+	}
+
+	// TestTaskBuildReportPayload 构建报告，同一阶段重复上报时覆盖
+	TestTaskBuildReportPayload struct {
+		StepName     string               `json:"step_name"`
+		Package      string               `json:"package"`
+		BinarySize   int64                `json:"binary_size"`
+		BuildSeconds float64              `json:"build_seconds"`
+		Packages     db.BuildPackageSizes `json:"packages"`
+	}
+
+	// TestTaskCoveragePayload 单元测试阶段的覆盖率，同一阶段重复上报时覆盖
+	TestTaskCoveragePayload struct {
+		StepName   string              `json:"step_name"`
+		Statements int                 `json:"statements"`
+		Covered    int                 `json:"covered"`
+		Partial    bool                `json:"partial"` // 只测试了变更影响的包
+		Packages   db.CoveragePackages `json:"packages"`
+		Report     string              `json:"report,omitempty"` // HTML 覆盖率报告的制品名
+	}
+
+	// TestTaskBenchmarkPayload 基准测试结果，同一阶段重复上报时覆盖
+	TestTaskBenchmarkPayload struct {
+		StepName       string              `json:"step_name"`
+		BaselineTaskID uint                `json:"baseline_task_id"`
+		Results        db.BenchmarkResults `json:"results"`
+	}
+
+	BuildReport struct {
+		TaskID       uint                 `json:"task_id"`
+		StepName     string               `json:"step_name"`
+		Branch       string               `json:"branch"`
+		Package      string               `json:"package"`
+		BinarySize   int64                `json:"binary_size"`
+		SizeDelta    int64                `json:"size_delta"` // 相对上一次构建的大小变化，第一次构建时为 0
+		BuildSeconds float64              `json:"build_seconds"`
+		Packages     db.BuildPackageSizes `json:"packages,omitempty"`
+		CreatedAt    time.Time            `json:"created_at"`
+	}
+
+	// ReqBuildReportTrend 流水线最近的构建报告，按时间正序
+	ReqBuildReportTrend struct {
+		PipelineID uint `query:"pipeline_id" validate:"required"`
+		Limit      int  `query:"limit" validate:"min=0,max=200"` // 为 0 时返回最近 30 次
+	}
+
+	// ReqBenchmarkHistory 流水线最近的基准测试结果，按时间倒序
+	ReqBenchmarkHistory struct {
+		PipelineID uint   `query:"pipeline_id" validate:"required"`
+		CommitSHA  string `query:"commit_sha" validate:"max=64"`  // 只返回该提交的结果
+		Limit      int    `query:"limit" validate:"min=0,max=50"` // 为 0 时返回最近 10 次
+	}
+
+	// Benchmark 基准测试阶段的结果，Baseline 为对比的基准任务中同名基准测试的结果
+	Benchmark struct {
+		TaskID         uint              `json:"task_id"`
+		StepName       string            `json:"step_name"`
+		Branch         string            `json:"branch"`
+		CommitSHA      string            `json:"commit_sha"`
+		BaselineTaskID uint              `json:"baseline_task_id"`
+		Results        []BenchmarkResult `json:"results"`
+		Regressions    int               `json:"regressions"` // ns/op 增加超过阈值的基准测试数
+		CreatedAt      time.Time         `json:"created_at"`
+	}
+
+	BenchmarkResult struct {
+		db.BenchmarkResult
+		Baseline   *db.BenchmarkResult `json:"baseline,omitempty"`
+		Delta      *float64            `json:"delta,omitempty"` // ns/op 相对基准的变化比例，0.1 为慢了 10%
+		Regression bool                `json:"regression"`
+	}
+
+	// ReqCoverageTrend 应用最近的覆盖率，按时间正序，只测试变更影响的包的任务不计入
+	ReqCoverageTrend struct {
+		AppName    string `query:"app_name" validate:"required"`
+		Env        string `query:"env" validate:"required"`
+		Branch     string `query:"branch"` // 为空时包含所有分支
+		PipelineID uint   `query:"pipeline_id"`
+		Limit      int    `query:"limit" validate:"min=0,max=200"` // 为 0 时返回最近 30 次
+	}
+
+	// TaskCoverage 任务的覆盖率，分片任务为各个分片的合计
+	TaskCoverage struct {
+		TaskID     uint      `json:"task_id"`
+		PipelineID uint      `json:"pipeline_id"`
+		Branch     string    `json:"branch"`
+		Statements int       `json:"statements"`
+		Covered    int       `json:"covered"`
+		Coverage   float64   `json:"coverage"` // 百分比
+		Delta      float64   `json:"delta"`    // 相对上一次任务的变化，第一次时为 0
+		CreatedAt  time.Time `json:"created_at"`
+		// Packages 各个包的覆盖率，只在查询单个任务时返回
+		Packages []PackageCoverageStat `json:"packages,omitempty"`
+		// Reports HTML 覆盖率报告的制品名，分片时每个分片一个，只在查询单个任务时返回
+		Reports []string `json:"reports,omitempty"`
+	}
+
+	PackageCoverageStat struct {
+		Package    string  `json:"package"`
+		Statements int     `json:"statements"`
+		Covered    int     `json:"covered"`
+		Coverage   float64 `json:"coverage"`
+	}
+
+	// ReqCompareTasks 对比同一流水线的两次任务，BaseTaskID 通常是上一次成功的任务
+	ReqCompareTasks struct {
+		TaskID     uint `query:"task_id" validate:"required"`
+		BaseTaskID uint `query:"base_task_id" validate:"required"`
+	}
+
+	TaskComparison struct {
+		TaskID       uint               `json:"task_id"`
+		BaseTaskID   uint               `json:"base_task_id"`
+		Status       db.TestTaskStatus  `json:"status"`
+		BaseStatus   db.TestTaskStatus  `json:"base_status"`
+		Steps        []StepComparison   `json:"steps"`
+		NewFailures  []TestRef          `json:"new_failures"` // 本次失败、对比任务中没有失败的测试
+		FixedTests   []TestRef          `json:"fixed_tests"`  // 对比任务中失败、本次通过的测试
+		Coverage     CoverageComparison `json:"coverage"`
+		LogSize      int                `json:"log_size"`
+		BaseLogSize  int                `json:"base_log_size"`
+		LogSizeDelta int                `json:"log_size_delta"`
+	}
+
+	// ReqSearchLogs 搜索应用的历史构建日志，按字面匹配，不区分大小写取决于数据库的排序规则
+	ReqSearchLogs struct {
+		AppName    string `query:"app_name" validate:"required"`
+		Env        string `query:"env" validate:"required"`
+		Query      string `query:"query" validate:"required,min=3,max=200"`
+		PipelineID uint   `query:"pipeline_id"`
+		StepName   string `query:"step_name"`
+		Limit      int    `query:"limit" validate:"min=0,max=200"` // 为 0 时返回最近 50 条
+	}
+
+	// LogSearchHit 匹配的日志行，按时间倒序
+	LogSearchHit struct {
+		TaskID     uint      `json:"task_id"`
+		PipelineID uint      `json:"pipeline_id"`
+		ZoneCode   string    `json:"zone_code"`
+		StepName   string    `json:"step_name"`
+		Line       int       `json:"line"`
+		Content    string    `json:"content"`
+		Link       string    `json:"link"` // 控制台中该任务阶段日志的地址
+		CreatedAt  time.Time `json:"created_at"`
+	}
+
+	// StepComparison 阶段耗时以第一次、最后一次上报状态的时间计算，只在一个任务中存在的阶段另一方为空
+	StepComparison struct {
+		StepName      string  `json:"step_name"`
+		Status        string  `json:"status"`
+		BaseStatus    string  `json:"base_status"`
+		Duration      float64 `json:"duration"` // 秒
+		BaseDuration  float64 `json:"base_duration"`
+		DurationDelta float64 `json:"duration_delta"`
+		LogSize       int     `json:"log_size"`
+		BaseLogSize   int     `json:"base_log_size"`
+	}
+
+	// TestRef 测试，Test 为空时为整个包，如编译失败
+	TestRef struct {
+		Package string `json:"package"`
+		Test    string `json:"test,omitempty"`
+	}
+
+	// CoverageComparison 覆盖率为各个包的平均值，没有覆盖率输出时为空
+	CoverageComparison struct {
+		Coverage     *float64          `json:"coverage"`
+		BaseCoverage *float64          `json:"base_coverage"`
+		Delta        *float64          `json:"delta"`
+		Packages     []PackageCoverage `json:"packages"` // 覆盖率变化的包，按变化幅度倒序
+	}
+
+	PackageCoverage struct {
+		Package      string   `json:"package"`
+		Coverage     *float64 `json:"coverage"`
+		BaseCoverage *float64 `json:"base_coverage"`
+	}
+
+	Vulnerability struct {
+		ID           uint     `json:"id,omitempty"`
+		TaskID       uint     `json:"task_id,omitempty"`
+		StepName     string   `json:"step_name,omitempty"`
+		VulnID       string   `json:"vuln_id"`
+		Aliases      []string `json:"aliases"`
+		Module       string   `json:"module"`
+		Version      string   `json:"version"`
+		FixedVersion string   `json:"fixed_version"`
+		Severity     string   `json:"severity"`
+		Called       bool     `json:"called"`
+		Summary      string   `json:"summary"`
+	}
+
+	TestTaskArtifact struct {
+		ID          uint      `json:"id"`
+		TaskID      uint      `json:"task_id"`
+		StepName    string    `json:"step_name"`
+		Name        string    `json:"name"`
+		ContentType string    `json:"content_type"`
+		Size        int       `json:"size"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+
+	ReqQueryTaskArtifact struct {
+		TaskID uint   `query:"task_id" validate:"required"`
+		Name   string `query:"name" validate:"required"`
 	}
 
 	TestTaskEventType string
@@ -108,9 +484,11 @@ type (
 	}
 
 	ReqListPipeline struct {
-		AppName  string `query:"app_name"`
-		ZoneCode string `query:"zone_code"`
-		Env      string `query:"env"`
+		ListQuery
+		AppName  string   `query:"app_name"`
+		ZoneCode string   `query:"zone_code"`
+		Env      string   `query:"env"`
+		Tags     []string `query:"tag"` // 标签筛选，需同时拥有全部标签
 	}
 
 	WorkerZone struct {
@@ -119,6 +497,107 @@ type (
 		NodeCount int    `json:"node_count"`
 	}
 
+	ReqWorkerNodes struct {
+		ZoneCode string `query:"zone_code"`
+	}
+
+	WorkerNode struct {
+		ID            uint      `json:"id"`
+		HostName      string    `json:"host_name"`
+		IP            string    `json:"ip"`
+		Port          int       `json:"port"`
+		Env           string    `json:"env"`
+		ZoneCode      string    `json:"zone_code"`
+		ZoneName      string    `json:"zone_name"`
+		LastHeartbeat time.Time `json:"last_heartbeat"`
+		Drained       bool      `json:"drained"`
+
+		Version    string             `json:"version"`
+		Capacity   int                `json:"capacity"`
+		Running    int                `json:"running"`
+		QueueDepth int                `json:"queue_depth"` // 队列不支持查看时为 -1
+		StartedAt  *time.Time         `json:"started_at"`
+		Tasks      db.WorkerTasks     `json:"tasks"`
+		Labels     db.MapStringString `json:"labels"`
+	}
+
+	// ReqWorkerQueue 查看 worker 节点的等待队列
+	ReqWorkerQueue struct {
+		NodeID uint `query:"node_id" validate:"required"`
+		Limit  int  `query:"limit" validate:"omitempty,max=500"`
+	}
+
+	// ReqWorkerQueueItem 删除或调整等待中的任务，ID 为队列中的消息 ID
+	ReqWorkerQueueItem struct {
+		NodeID uint   `json:"node_id" validate:"required"`
+		ID     string `json:"id" validate:"required"`
+		// TaskID 删除后标记为失败的任务，为 0 时不更新任务状态
+		TaskID uint `json:"task_id"`
+	}
+
+	// ReqWorkerNode 暂停、恢复 worker 节点消费队列
+	ReqWorkerNode struct {
+		NodeID uint `json:"node_id" validate:"required"`
+	}
+
+	// ReqDrainWorker 指定 NodeID，或者 HostName 和 ZoneCode，外部扩缩容系统通常只知道主机名
+	ReqDrainWorker struct {
+		NodeID   uint   `json:"node_id" query:"node_id"`
+		HostName string `json:"host_name" query:"host_name" validate:"required_without=NodeID"`
+		ZoneCode string `json:"zone_code" query:"zone_code" validate:"required_with=HostName"`
+	}
+
+	// WorkerDrainStatus worker 的 drain 状态，Idle 为 true 时可以安全下线
+	WorkerDrainStatus struct {
+		Draining bool `json:"draining"`
+		Running  int  `json:"running"` // 执行中的任务数
+		Queued   int  `json:"queued"`  // 本地队列中等待的任务数，共享队列为 0
+		Idle     bool `json:"idle"`
+	}
+
+	// WorkerQueueStat 机房的任务排队情况，用于自动扩缩容
+	WorkerQueueStat struct {
+		ZoneCode       string  `json:"zone_code"`
+		QueueDepth     int     `json:"queue_depth"`
+		OldestWait     float64 `json:"oldest_wait"` // 秒
+		Running        int     `json:"running"`
+		Workers        int     `json:"workers"`
+		DrainedWorkers int     `json:"drained_workers"`
+	}
+
+	// WorkerQueue worker 的等待队列。redis、nsq 队列由多个 worker 共享，Tasks 为共享队列中的任务
+	WorkerQueue struct {
+		// Paused 当前 worker 是否暂停消费，暂停状态不持久化，worker 重启后恢复消费
+		Paused bool `json:"paused"`
+		// Manageable 队列是否支持查看和调整，nsq 不支持
+		Manageable bool         `json:"manageable"`
+		Total      int          `json:"total"`
+		Tasks      []QueuedTask `json:"tasks"`
+	}
+
+	QueuedTask struct {
+		ID       string    `json:"id"` // 队列中的消息 ID，删除、调整顺序后可能改变
+		TaskID   uint      `json:"task_id"`
+		Name     string    `json:"name"`
+		AppName  string    `json:"app_name"`
+		Env      string    `json:"env"`
+		Branch   string    `json:"branch"`
+		Part     int       `json:"part,omitempty"`
+		Priority int       `json:"priority"`
+		Attempts int       `json:"attempts"` // 之前已经投递的次数
+		QueuedAt time.Time `json:"queued_at"`
+		Wait     float64   `json:"wait"` // 排队时间，单位秒
+	}
+
+	ReqQueueItem struct {
+		ID string `json:"id" validate:"required"`
+	}
+
+	// ReqCancelTask Juno 通知 worker 终止执行中的任务
+	ReqCancelTask struct {
+		TaskID uint `json:"task_id" validate:"required"`
+	}
+
 	GrpcTestCase struct {
 		grpctester.RequestPayload
 		MethodDescriptor json.RawMessage `json:"method_descriptor"`
@@ -126,6 +605,12 @@ type (
 )
 
 var (
-	TaskUpdateEvent     TestTaskEventType = "task_update"
-	TaskStepUpdateEvent TestTaskEventType = "step_update"
+	TaskUpdateEvent        TestTaskEventType = "task_update"
+	TaskStepUpdateEvent    TestTaskEventType = "step_update"
+	TaskArtifactEvent      TestTaskEventType = "artifact"
+	TaskTestTimingEvent    TestTaskEventType = "test_timing"
+	TaskVulnerabilityEvent TestTaskEventType = "vulnerability"
+	TaskBuildReportEvent   TestTaskEventType = "build_report"
+	TaskCoverageEvent      TestTaskEventType = "coverage"
+	TaskBenchmarkEvent     TestTaskEventType = "benchmark"
 )
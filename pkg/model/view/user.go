@@ -43,3 +43,39 @@ type RespUserApps struct {
 type ReqUserAppViewHistory struct {
 	Aid uint `json:"aid" valid:"required"`
 }
+
+// ReqSetNotifyEmail 设置接收通知的邮箱，为空时使用账号邮箱
+type ReqSetNotifyEmail struct {
+	Email string `json:"email" validate:"omitempty,email,max=255"`
+}
+
+// RespNotifyEmail 用户接收通知的邮箱设置
+type RespNotifyEmail struct {
+	Email        string `json:"email"`         // 通知邮箱，为空时使用账号邮箱
+	AccountEmail string `json:"account_email"` // 账号邮箱
+}
+
+// NotifyPref 用户通知偏好，字段为空时不做限制
+type NotifyPref struct {
+	MutedEvents []string `json:"muted_events"` // 不接收的事件类型
+	MinSeverity string   `json:"min_severity" validate:"omitempty,oneof=info warning error"`
+	Channels    []string `json:"channels"` // 接收通知的渠道
+}
+
+// ReqSetLanguage 设置用户语言，为空时按浏览器语言
+type ReqSetLanguage struct {
+	Language string `json:"language" validate:"max=16"`
+}
+
+// RespLanguage 用户语言设置
+type RespLanguage struct {
+	Language  string   `json:"language"`  // 用户设置的语言，为空时按浏览器语言
+	Current   string   `json:"current"`   // 当前请求使用的语言
+	Supported []string `json:"supported"` // 支持的语言
+}
+
+// ReqNotifyUnsubscribe 邮件退订链接，event 为空时退订全部邮件通知
+type ReqNotifyUnsubscribe struct {
+	Token string `query:"token"`
+	Event string `query:"event"`
+}
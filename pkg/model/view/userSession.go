@@ -0,0 +1,45 @@
+package view
+
+import (
+	"time"
+)
+
+type (
+	ReqListUserSession struct {
+		Uid int `query:"uid" validate:"required"`
+	}
+
+	ReqRevokeUserSession struct {
+		ID uint `json:"id" validate:"required"`
+	}
+
+	ReqRevokeAllUserSession struct {
+		Uid int `json:"uid" validate:"required"`
+	}
+
+	ReqChangePassword struct {
+		OldPassword string `json:"old_password" validate:"required"`
+		NewPassword string `json:"new_password" validate:"required,max=64"`
+	}
+
+	// ReqChangeExpiredPassword 密码过期后未登录状态下修改密码
+	ReqChangeExpiredPassword struct {
+		Username    string `json:"username" validate:"required"`
+		OldPassword string `json:"old_password" validate:"required"`
+		NewPassword string `json:"new_password" validate:"required,max=64"`
+	}
+
+	ReqUnlockLogin struct {
+		Username string `json:"username" validate:"required"`
+	}
+
+	UserSession struct {
+		ID           uint       `json:"id"`
+		UserAgent    string     `json:"user_agent"`
+		ClientIP     string     `json:"client_ip"`
+		CreatedAt    time.Time  `json:"created_at"`
+		LastActiveAt time.Time  `json:"last_active_at"`
+		ExpiresAt    *time.Time `json:"expires_at"`
+		Current      bool       `json:"current"` // 是否为当前请求所在的会话
+	}
+)
@@ -0,0 +1,26 @@
+package view
+
+type (
+	ReqTOTPCode struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	ReqResetTOTP struct {
+		Uid int `json:"uid" validate:"required"`
+	}
+
+	TOTPStatus struct {
+		Enabled          bool `json:"enabled"`
+		Required         bool `json:"required"`           // 当前用户的角色要求开启两步验证
+		BackupCodesCount int  `json:"backup_codes_count"` // 剩余备用码数量
+	}
+
+	RespTOTPEnroll struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"` // otpauth:// 地址，前端生成二维码
+	}
+
+	RespTOTPBackupCodes struct {
+		BackupCodes []string `json:"backup_codes"`
+	}
+)
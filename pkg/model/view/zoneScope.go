@@ -0,0 +1,27 @@
+package view
+
+type (
+	ReqListZoneScope struct {
+		SubjectType string `query:"subject_type"`
+	}
+
+	// ReqSetZoneScope 设置用户或团队可访问的机房，Zones 为空时取消限制
+	ReqSetZoneScope struct {
+		SubjectType string   `json:"subject_type" validate:"required,oneof=user team"`
+		SubjectID   uint     `json:"subject_id" validate:"required"`
+		Zones       []string `json:"zones"`
+	}
+
+	ZoneScopeItem struct {
+		SubjectType string   `json:"subject_type"`
+		SubjectID   uint     `json:"subject_id"`
+		SubjectName string   `json:"subject_name"`
+		Zones       []string `json:"zones"`
+	}
+
+	// RespUserZoneScope 当前用户的机房限制，Restricted 为 false 时可访问所有机房
+	RespUserZoneScope struct {
+		Restricted bool     `json:"restricted"`
+		Zones      []string `json:"zones"`
+	}
+)
@@ -0,0 +1,176 @@
+package notice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+const (
+	defaultDigestInterval = 10 * time.Minute
+	defaultDigestMaxItems = 50
+)
+
+var digests = &digestBuffer{buckets: make(map[string]*digestBucket)}
+
+type (
+	digestBuffer struct {
+		mu      sync.Mutex
+		buckets map[string]*digestBucket
+	}
+
+	// digestBucket 同一应用、同一组渠道、同一钉钉机器人的待汇总事件
+	digestBucket struct {
+		channels []string
+		events   []Event
+	}
+)
+
+// DigestInterval 汇总消息发送间隔
+func DigestInterval() time.Duration {
+	interval := cfg.Cfg.Notice.Digest.Interval
+	if interval <= 0 {
+		interval = defaultDigestInterval
+	}
+	return interval
+}
+
+// Digestible 事件是否需要合并到汇总消息中发送
+func Digestible(conf cfg.NoticeDigest, e Event) bool {
+	if !conf.Enable || e.Type == EventDigest {
+		return false
+	}
+
+	maxSeverity := conf.MaxSeverity
+	if maxSeverity == "" {
+		maxSeverity = SeverityInfo
+	}
+	if SeverityLevel(e.Severity) > SeverityLevel(maxSeverity) {
+		return false
+	}
+
+	if len(conf.Events) == 0 {
+		return true
+	}
+	return containsString(conf.Events, e.Type)
+}
+
+// FlushDigest 发送所有待汇总的事件，由定时任务调用
+func FlushDigest() error {
+	maxItems := cfg.Cfg.Notice.Digest.MaxItems
+	if maxItems <= 0 {
+		maxItems = defaultDigestMaxItems
+	}
+
+	for _, bucket := range digests.take() {
+		dispatch(BuildDigest(bucket.events, maxItems), bucket.channels)
+	}
+	return nil
+}
+
+// BuildDigest 将多条事件合并为一条汇总事件，级别取最高级别，接收人取并集
+func BuildDigest(events []Event, maxItems int) Event {
+	digest := Event{
+		Type:     EventDigest,
+		Severity: SeverityInfo,
+		Time:     time.Now(),
+	}
+	if len(events) == 0 {
+		return digest
+	}
+
+	first := events[0]
+	digest.App = first.App
+	digest.Env = first.Env
+	digest.DingWebhook = first.DingWebhook
+
+	types := make([]string, 0)
+	for _, e := range events {
+		if SeverityLevel(e.Severity) > SeverityLevel(digest.Severity) {
+			digest.Severity = e.Severity
+		}
+		if e.Env != digest.Env {
+			digest.Env = ""
+		}
+		if !containsString(types, e.Type) {
+			types = append(types, e.Type)
+		}
+		digest.Mentions = appendUnique(digest.Mentions, e.Mentions...)
+		digest.Emails = appendUnique(digest.Emails, e.Emails...)
+	}
+
+	title := "[Juno] 平台通知汇总"
+	if digest.App != "" {
+		title = fmt.Sprintf("[Juno] 应用 %s 通知汇总", digest.App)
+	}
+	digest.Subject = fmt.Sprintf("%s: %d 条 %s 事件", title, len(events), strings.Join(types, "/"))
+
+	var b strings.Builder
+	b.WriteString(digest.Subject + "\n")
+	for i, e := range events {
+		if i >= maxItems {
+			b.WriteString(fmt.Sprintf("... 另有 %d 条事件未列出\n", len(events)-maxItems))
+			break
+		}
+		b.WriteString(fmt.Sprintf("- %s [%s/%s] %s\n", e.Time.Format("15:04:05"), e.Type, e.Severity, digestLine(e)))
+	}
+	digest.Content = b.String()
+	return digest
+}
+
+// add 缓存事件，按应用、渠道、钉钉机器人分组
+func (d *digestBuffer) add(e Event, channels []string) {
+	if channels != nil {
+		channels = append([]string(nil), channels...)
+		sort.Strings(channels)
+	}
+	key := fmt.Sprintf("%s|%s|%s|%v", e.App, strings.Join(channels, ","), e.DingWebhook, channels == nil)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	bucket, ok := d.buckets[key]
+	if !ok {
+		bucket = &digestBucket{channels: channels}
+		d.buckets[key] = bucket
+	}
+	bucket.events = append(bucket.events, e)
+}
+
+// take 取出并清空所有待汇总事件
+func (d *digestBuffer) take() []*digestBucket {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	buckets := make([]*digestBucket, 0, len(d.buckets))
+	for _, bucket := range d.buckets {
+		buckets = append(buckets, bucket)
+	}
+	d.buckets = make(map[string]*digestBucket)
+	return buckets
+}
+
+// digestLine 汇总消息中的单行描述，优先使用标题，否则使用正文第一行
+func digestLine(e Event) string {
+	line := e.Subject
+	if line == "" {
+		line = e.Content
+	}
+	if i := strings.Index(line, "\n"); i >= 0 {
+		line = line[:i]
+	}
+	return line
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if item != "" && !containsString(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,80 @@
+package notice
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+func TestDigestible(t *testing.T) {
+	conf := cfg.NoticeDigest{Enable: true}
+	tests := []struct {
+		conf cfg.NoticeDigest
+		e    Event
+		want bool
+	}{
+		{cfg.NoticeDigest{}, Event{Type: EventPipeline, Severity: SeverityInfo}, false},
+		{conf, Event{Type: EventPipeline, Severity: SeverityInfo}, true},
+		{conf, Event{Type: EventPipeline, Severity: SeverityWarning}, false},
+		{conf, Event{Type: EventDigest, Severity: SeverityInfo}, false},
+		{cfg.NoticeDigest{Enable: true, MaxSeverity: SeverityWarning}, Event{Type: EventAlert, Severity: SeverityWarning}, true},
+		{cfg.NoticeDigest{Enable: true, Events: []string{EventPipeline}}, Event{Type: EventConfig, Severity: SeverityInfo}, false},
+	}
+
+	for _, tt := range tests {
+		if got := Digestible(tt.conf, tt.e); got != tt.want {
+			t.Errorf("Digestible(%+v, %+v) = %v, want %v", tt.conf, tt.e, got, tt.want)
+		}
+	}
+}
+
+func TestBuildDigest(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	events := []Event{
+		{Type: EventPipeline, App: "juno-admin", Env: "dev", Severity: SeverityInfo, Subject: "pipeline ok", Time: at, Mentions: []string{"alice"}},
+		{Type: EventConfig, App: "juno-admin", Env: "prod", Severity: SeverityWarning, Content: "config published\nversion: 1", Time: at, Mentions: []string{"alice", "bob"}, Emails: []string{"a@x.com"}},
+		{Type: EventPipeline, App: "juno-admin", Env: "dev", Severity: SeverityInfo, Subject: "pipeline ok again", Time: at},
+	}
+
+	d := BuildDigest(events, 2)
+	if d.Type != EventDigest || d.App != "juno-admin" || d.Env != "" || d.Severity != SeverityWarning {
+		t.Errorf("digest = %+v", d)
+	}
+	if !reflect.DeepEqual(d.Mentions, []string{"alice", "bob"}) || !reflect.DeepEqual(d.Emails, []string{"a@x.com"}) {
+		t.Errorf("digest recipients = %v %v", d.Mentions, d.Emails)
+	}
+	if !strings.Contains(d.Subject, "3 条 pipeline/config 事件") {
+		t.Errorf("digest subject = %q", d.Subject)
+	}
+	for _, want := range []string{"- 03:04:05 [pipeline/info] pipeline ok\n", "[config/warning] config published\n", "另有 1 条事件未列出"} {
+		if !strings.Contains(d.Content, want) {
+			t.Errorf("digest content missing %q:\n%s", want, d.Content)
+		}
+	}
+}
+
+func TestDigestBuffer(t *testing.T) {
+	d := &digestBuffer{buckets: make(map[string]*digestBucket)}
+	d.add(Event{App: "a"}, []string{ChannelSlack, ChannelDing})
+	d.add(Event{App: "a"}, []string{ChannelDing, ChannelSlack})
+	d.add(Event{App: "a"}, nil)
+	d.add(Event{App: "b"}, nil)
+
+	buckets := d.take()
+	if len(buckets) != 3 {
+		t.Fatalf("buckets = %d, want 3", len(buckets))
+	}
+	total := 0
+	for _, bucket := range buckets {
+		total += len(bucket.events)
+	}
+	if total != 4 {
+		t.Errorf("events = %d, want 4", total)
+	}
+	if len(d.take()) != 0 {
+		t.Error("take should clear buffer")
+	}
+}
@@ -27,6 +27,13 @@ func (d *DingNotice) Send(content string) {
 	NewtDingNotice(TextMsgType).InitText(content, atMobiles, isAtAll).sendDingMsg()
 }
 
+// SendTo 发送文本消息到指定的钉钉机器人
+func (d *DingNotice) SendTo(webHook, content string) error {
+	msg := NewtDingNotice(TextMsgType)
+	msg.WebHook = webHook
+	return msg.InitText(content, []string{}, false).sendDingMsg()
+}
+
 type dingMsg struct {
 	WebHook string
 	Content interface{}
@@ -142,6 +149,9 @@ func (d *msgSubject) sendDingMsg() (err error) {
 	client := &http.Client{}
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
 	defer resp.Body.Close()
 	return
 }
@@ -2,6 +2,7 @@ package notice
 
 import (
 	"bytes"
+	"crypto/tls"
 	"errors"
 	"html/template"
 
@@ -16,6 +17,10 @@ type Email struct {
 	ServerHost string
 	// ServerPort 邮箱服务器端口，如腾讯企业邮箱为465
 	ServerPort int
+	// SSL 使用 SSL/TLS 连接，为 false 时服务器支持则使用 STARTTLS
+	SSL bool
+	// InsecureSkipVerify 不校验服务器证书
+	InsecureSkipVerify bool
 	// FromEmail　发件人邮箱地址
 	FromEmail string
 	// FromPasswd 发件人邮箱密码（注意，这里是明文形式），TODO：如果设置成密文？
@@ -64,6 +69,8 @@ func NewEmailNotice(setters ...EmailOption) (email *Email) {
 	email.m = gomail.NewMessage()
 	email.ServerHost = cfg.Cfg.Notice.Email.ServerHost
 	email.ServerPort = cfg.Cfg.Notice.Email.ServerPort
+	email.SSL = cfg.Cfg.Notice.Email.SSL || email.ServerPort == 465
+	email.InsecureSkipVerify = cfg.Cfg.Notice.Email.InsecureSkipVerify
 	email.FromEmail = cfg.Cfg.Notice.Email.FromEmail
 	email.FromPasswd = cfg.Cfg.Notice.Email.FromPasswd
 	email.Toers = cfg.Cfg.Notice.Email.Toers
@@ -102,6 +109,8 @@ func (ep *Email) send() (err error) {
 	ep.m.SetBody("text/html", ep.Body)
 
 	d := gomail.NewDialer(ep.ServerHost, ep.ServerPort, ep.FromEmail, ep.FromPasswd)
+	d.SSL = ep.SSL
+	d.TLSConfig = &tls.Config{ServerName: ep.ServerHost, InsecureSkipVerify: ep.InsecureSkipVerify}
 	// 发送
 	err = d.DialAndSend(ep.m)
 	return
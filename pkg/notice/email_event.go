@@ -0,0 +1,130 @@
+package notice
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"strings"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+// defaultEventEmailTemplate 平台事件邮件模板，可通过 notice.email.templatePath 替换
+const defaultEventEmailTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>{{.Subject}}</title></head>
+<body style="background-color:#e9e9e9;">
+<div style="background-color:#fff;max-width:700px;margin:20px auto 0;padding:10px 20px;border-radius:10px;">
+  <h2 style="border-bottom:1px solid #cecece;">{{.Subject}}</h2>
+  <p><b>事件类型：</b>{{.Type}}</p>
+  {{if .App}}<p><b>应用：</b>{{.App}}</p>{{end}}
+  <p><b>级别：</b>{{.Severity}}</p>
+  <p><b>时间：</b>{{.Time.Format "2006-01-02 15:04:05"}}</p>
+  <pre style="white-space:pre-wrap;background:#f6f6f6;padding:10px;">{{.Content}}</pre>
+  <p style="color:#999;font-size:12px;">此邮件由 Juno 自动发送，请勿回复{{if .UnsubscribeURL}}，<a href="{{.UnsubscribeURL}}">退订此类通知</a>{{end}}</p>
+</div>
+</body>
+</html>`
+
+// SendEventEmail 使用 HTML 模板渲染事件并发送邮件，接收人为空时发送到 notice.email.toers。
+// 有退订链接的接收人单独发送，邮件中附带各自的退订链接；其他接收人按语言分组发送
+func SendEventEmail(e Event) error {
+	if len(e.Emails) == 0 || (len(e.Unsubscribes) == 0 && len(e.Languages) == 0) {
+		return sendEventEmail(e, e.Emails)
+	}
+
+	var (
+		errs   []string
+		langs  []string
+		shared = map[string][]string{}
+	)
+	for _, to := range e.Emails {
+		lang := e.recipientLanguage(to)
+		link, ok := e.Unsubscribes[to]
+		if !ok {
+			if _, exist := shared[lang]; !exist {
+				langs = append(langs, lang)
+			}
+			shared[lang] = append(shared[lang], to)
+			continue
+		}
+
+		single := e.Localize(lang)
+		single.UnsubscribeURL = link
+		if err := sendEventEmail(single, []string{to}); err != nil {
+			errs = append(errs, to+": "+err.Error())
+		}
+	}
+	for _, lang := range langs {
+		if err := sendEventEmail(e.Localize(lang), shared[lang]); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("email send failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// recipientLanguage 邮件接收人的语言，未设置时使用 notice.language
+func (e Event) recipientLanguage(to string) string {
+	if lang, ok := e.Languages[to]; ok && lang != "" {
+		return lang
+	}
+	return Language()
+}
+
+func sendEventEmail(e Event, toers []string) error {
+	if e.Subject == "" {
+		e.Subject = eventSubject(e)
+	}
+	body, err := RenderEventEmail(cfg.Cfg.Notice.Email.TemplatePath, e)
+	if err != nil {
+		return err
+	}
+
+	email := NewEmailNotice()
+	email.Subject = e.Subject
+	email.Body = body
+	if len(toers) > 0 {
+		email.Toers = toers
+		email.CCers = nil
+	}
+	return email.send()
+}
+
+// RenderEventEmail 渲染事件邮件正文，templatePath 为空时使用内置模板
+func RenderEventEmail(templatePath string, e Event) (string, error) {
+	text := defaultEventEmailTemplate
+	if templatePath != "" {
+		b, err := ioutil.ReadFile(templatePath)
+		if err != nil {
+			return "", fmt.Errorf("read email template failed: %s", err.Error())
+		}
+		text = string(b)
+	}
+
+	tpl, err := template.New("event").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse email template failed: %s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	err = tpl.Execute(&buf, e)
+	if err != nil {
+		return "", fmt.Errorf("execute email template failed: %s", err.Error())
+	}
+	return buf.String(), nil
+}
+
+func eventSubject(e Event) string {
+	prefix := cfg.Cfg.Notice.Email.Subject
+	if prefix == "" {
+		prefix = "[Juno]"
+	}
+	if e.App != "" {
+		return fmt.Sprintf("%s %s 事件通知: %s", prefix, e.Type, e.App)
+	}
+	return fmt.Sprintf("%s %s 事件通知", prefix, e.Type)
+}
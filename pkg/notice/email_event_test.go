@@ -0,0 +1,92 @@
+package notice
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/i18n"
+)
+
+func TestRenderEventEmail(t *testing.T) {
+	e := Event{
+		Type:     EventPipeline,
+		App:      "juno-admin",
+		Severity: SeverityError,
+		Subject:  "[Juno] 流水线执行失败",
+		Content:  "<script>alert(1)</script>",
+		Time:     time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local),
+	}
+
+	body, err := RenderEventEmail("", e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[Juno] 流水线执行失败", "juno-admin", "2020-01-02 03:04:05", "&lt;script&gt;"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q", want)
+		}
+	}
+
+	if strings.Contains(body, "退订") {
+		t.Error("body without unsubscribe url should not contain unsubscribe link")
+	}
+	e.UnsubscribeURL = "https://juno.example.com/api/admin/public/user/notify/unsubscribe?token=abc&event=pipeline"
+	body, err = RenderEventEmail("", e)
+	if err != nil || !strings.Contains(body, `href="https://juno.example.com/api/admin/public/user/notify/unsubscribe?token=abc&amp;event=pipeline"`) {
+		t.Errorf("body missing unsubscribe link: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "notice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "event.html")
+	if err = ioutil.WriteFile(path, []byte(`<p>{{.App}}:{{.Type}}</p>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	body, err = RenderEventEmail(path, e)
+	if err != nil || body != "<p>juno-admin:pipeline</p>" {
+		t.Errorf("custom template body = %q, err = %v", body, err)
+	}
+
+	if _, err = RenderEventEmail(filepath.Join(dir, "missing.html"), e); err == nil {
+		t.Error("missing template should fail")
+	}
+}
+
+func TestSeverityLevel(t *testing.T) {
+	if !(SeverityLevel(SeverityError) > SeverityLevel(SeverityWarning) && SeverityLevel(SeverityWarning) > SeverityLevel(SeverityInfo)) {
+		t.Error("severity order wrong")
+	}
+	if SeverityLevel("unknown") != SeverityLevel(SeverityInfo) {
+		t.Error("unknown severity should be info")
+	}
+}
+
+func TestEventLocalize(t *testing.T) {
+	e := Event{
+		Subject: "fallback",
+		Localized: &Localized{
+			Subject: i18n.Msg("[Juno] 应用 %s 配置 %s 已发布", "juno-admin", "config.toml"),
+		},
+		Languages: map[string]string{"a@example.com": i18n.EnUS},
+	}
+
+	if got := e.Localize(e.recipientLanguage("a@example.com")).Subject; got != "[Juno] Config config.toml of app juno-admin published" {
+		t.Errorf("en-US subject = %q", got)
+	}
+	if got := e.Localize(e.recipientLanguage("b@example.com")).Subject; got != "[Juno] 应用 juno-admin 配置 config.toml 已发布" {
+		t.Errorf("default subject = %q", got)
+	}
+
+	e.Localized = nil
+	if got := e.Localize(i18n.EnUS).Subject; got != "fallback" {
+		t.Errorf("subject without Localized = %q", got)
+	}
+}
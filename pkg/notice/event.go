@@ -0,0 +1,253 @@
+package notice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+	"github.com/douyu/juno/pkg/i18n"
+	"github.com/douyu/juno/pkg/metrics"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// 事件级别
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// 通知渠道
+const (
+	ChannelDing    = "ding"
+	ChannelSlack   = "slack"
+	ChannelWeCom   = "wecom"
+	ChannelFeishu  = "feishu"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+// Channels 全部通知渠道
+var Channels = []string{ChannelDing, ChannelSlack, ChannelWeCom, ChannelFeishu, ChannelWebhook, ChannelEmail}
+
+// ErrChannelDisabled 渠道未开启或未配置
+var ErrChannelDisabled = fmt.Errorf("notice channel is disabled")
+
+// Event 平台事件通知
+type Event struct {
+	Type     string    `json:"type"`
+	App      string    `json:"app"`
+	Env      string    `json:"env,omitempty"`
+	Severity string    `json:"severity"`
+	Subject  string    `json:"subject"`
+	Content  string    `json:"content"`
+	Time     time.Time `json:"time"`
+
+	// Emails 邮件接收人，为空时发送到 notice.email.toers
+	Emails []string `json:"-"`
+	// Tags 应用标签，由通知路由在有规则按标签匹配时填充
+	Tags []string `json:"tags,omitempty"`
+	// Mentions 需要 @ 的负责人用户名
+	Mentions []string `json:"mentions,omitempty"`
+	// DingWebhook 钉钉机器人地址，为空时发送到 notice.ding.webHook
+	DingWebhook string `json:"-"`
+	// Approval 审批类事件的待审批对象，支持卡片交互的渠道据此渲染审批按钮
+	Approval *Approval `json:"approval,omitempty"`
+	// Unsubscribes 邮件接收人对应的退订链接，有退订链接的接收人单独发送邮件
+	Unsubscribes map[string]string `json:"-"`
+	// UnsubscribeURL 当前邮件的退订链接，由 SendEventEmail 设置
+	UnsubscribeURL string `json:"-"`
+	// Localized 延迟翻译的标题和正文，发送时按渠道语言渲染，邮件按接收人的语言渲染
+	Localized *Localized `json:"-"`
+	// Languages 邮件接收人对应的语言，未设置的接收人使用 notice.language
+	Languages map[string]string `json:"-"`
+}
+
+// Localized 延迟翻译的标题和正文
+type Localized struct {
+	Subject i18n.Message
+	Content i18n.Message
+}
+
+// Localize 将 Localized 渲染为 lang 的标题和正文，没有 Localized 时原样返回
+func (e Event) Localize(lang string) Event {
+	if e.Localized == nil {
+		return e
+	}
+	if e.Localized.Subject.Format != "" {
+		e.Subject = e.Localized.Subject.String(lang)
+	}
+	if e.Localized.Content.Format != "" {
+		e.Content = e.Localized.Content.String(lang)
+	}
+	return e
+}
+
+// Language 共享渠道使用的语言
+func Language() string {
+	if lang, ok := i18n.Normalize(cfg.Cfg.Notice.Language); ok {
+		return lang
+	}
+	return i18n.Default
+}
+
+// Approval 待审批对象
+type Approval struct {
+	Kind string `json:"kind"`
+	ID   uint   `json:"id"`
+}
+
+// SeverityLevel 事件级别排序，未知级别视为 info
+func SeverityLevel(severity string) int {
+	switch severity {
+	case SeverityWarning:
+		return 1
+	case SeverityError:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Dispatch 发送事件到全部已开启的渠道
+func Dispatch(e Event) {
+	DispatchTo(e, nil)
+}
+
+// DispatchTo 发送事件到 channels 中已开启的钉钉、Slack、企业微信、飞书、Webhook、邮件渠道，channels 为 nil 时发送到全部渠道。
+// 配置了自定义模板的渠道使用模板渲染后的标题和正文；开启汇总时低级别事件先缓存，由 FlushDigest 合并发送；发送失败只记录日志
+func DispatchTo(e Event, channels []string) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Severity == "" {
+		e.Severity = SeverityInfo
+	}
+	e = e.Localize(Language())
+
+	if Digestible(cfg.Cfg.Notice.Digest, e) {
+		digests.add(e, channels)
+		return
+	}
+	dispatch(e, channels)
+}
+
+func dispatch(e Event, channels []string) {
+	for _, channel := range Channels {
+		if channels != nil && !containsString(channels, channel) {
+			continue
+		}
+		if channel == ChannelEmail && !emailSeverityMatched(e.Severity) {
+			continue
+		}
+
+		err := SendChannel(channel, applyTemplate(channel, e))
+		if err == ErrChannelDisabled {
+			continue
+		}
+		metrics.ObserveNotice(channel, err)
+		if err != nil {
+			xlog.Error("notice.Dispatch send failed", xlog.String("channel", channel), xlog.String("app", e.App), xlog.String("event", e.Type), xlog.String("err", err.Error()))
+		}
+	}
+}
+
+// SendChannel 发送事件到单个渠道，不使用自定义模板，渠道未开启时返回 ErrChannelDisabled
+func SendChannel(channel string, e Event) error {
+	switch channel {
+	case ChannelDing:
+		webHook := e.DingWebhook
+		if webHook == "" {
+			webHook = cfg.Cfg.Notice.Ding.WebHook
+		}
+		if webHook == "" {
+			return ErrChannelDisabled
+		}
+		ding := &DingNotice{}
+		return ding.SendTo(webHook, e.Content)
+	case ChannelSlack:
+		if !cfg.Cfg.Notice.Slack.Enable {
+			return ErrChannelDisabled
+		}
+		slack := &SlackNotice{}
+		return slack.SendEvent(e.App, e.Type, e.Content)
+	case ChannelWeCom:
+		if !cfg.Cfg.Notice.WeCom.Enable {
+			return ErrChannelDisabled
+		}
+		wecom := &WeComNotice{Conf: cfg.Cfg.Notice.WeCom}
+		return wecom.Send(e)
+	case ChannelFeishu:
+		if !cfg.Cfg.Notice.Feishu.Enable {
+			return ErrChannelDisabled
+		}
+		feishu := &FeishuNotice{Conf: cfg.Cfg.Notice.Feishu}
+		return feishu.Send(e)
+	case ChannelWebhook:
+		var errs []string
+		for _, conf := range cfg.Cfg.Notice.Webhooks {
+			webhook := &WebhookNotice{Conf: conf}
+			if !webhook.Subscribed(e.Type) {
+				continue
+			}
+
+			err := webhook.Send(e)
+			if err != nil {
+				errs = append(errs, conf.Name+": "+err.Error())
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("webhook send failed: %s", strings.Join(errs, "; "))
+		}
+		return nil
+	case ChannelEmail:
+		if !cfg.Cfg.Notice.Email.Enable {
+			return ErrChannelDisabled
+		}
+		return SendEventEmail(e)
+	default:
+		return fmt.Errorf("unknown notice channel %s", channel)
+	}
+}
+
+// applyTemplate 使用渠道对应的自定义模板替换事件标题和正文，渲染失败时使用默认消息
+func applyTemplate(channel string, e Event) Event {
+	if templateProvider == nil {
+		return e
+	}
+
+	subjectTpl, bodyTpl, ok := templateProvider(e.Type, channel)
+	if !ok {
+		return e
+	}
+
+	subject, content, err := RenderTemplate(subjectTpl, bodyTpl, e)
+	if err != nil {
+		xlog.Error("notice.applyTemplate render failed", xlog.String("event", e.Type), xlog.String("channel", channel), xlog.String("err", err.Error()))
+		return e
+	}
+
+	e.Subject = subject
+	e.Content = content
+	// 自定义模板渲染的内容不再按接收人语言翻译
+	e.Localized = nil
+	return e
+}
+
+func emailSeverityMatched(severity string) bool {
+	minSeverity := cfg.Cfg.Notice.Email.MinSeverity
+	if minSeverity == "" {
+		minSeverity = SeverityWarning
+	}
+	return SeverityLevel(severity) >= SeverityLevel(minSeverity)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
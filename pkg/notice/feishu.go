@@ -0,0 +1,288 @@
+package notice
+
+// 飞书消息文档
+// https://open.feishu.cn/document/ukTMukTMukTM/ucTM5YjL3ETO24yNxkjN
+// https://open.feishu.cn/document/uAjLw4CM/ukTMukTMukTM/reference/im-v1/message/create
+// https://open.feishu.cn/document/ukTMukTMukTM/uYzM3QjL2MzN04iNzcDN/message-card-callback
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+// 卡片审批按钮的动作
+const (
+	FeishuActionApprove = "approve"
+	FeishuActionReject  = "reject"
+)
+
+var (
+	feishuAPI    = "https://open.feishu.cn/open-apis"
+	feishuClient = &http.Client{Timeout: 5 * time.Second}
+
+	feishuTokenMu sync.Mutex
+	feishuToken   struct {
+		appID    string
+		token    string
+		expireAt time.Time
+	}
+)
+
+type feishuResp struct {
+	Code              int    `json:"code"`
+	Msg               string `json:"msg"`
+	TenantAccessToken string `json:"tenant_access_token"`
+	Expire            int    `json:"expire"`
+}
+
+// FeishuActionValue 审批按钮回调时携带的数据
+type FeishuActionValue struct {
+	Kind   string `json:"kind"`
+	ID     uint   `json:"id"`
+	Action string `json:"action"`
+}
+
+// FeishuNotice 飞书通知，支持群机器人和应用机器人
+type FeishuNotice struct {
+	Conf cfg.NoticeFeishu
+}
+
+// Send 发送消息卡片，设置 AppID 时通过应用机器人发送到 ChatID 群，否则发送到群机器人
+func (f *FeishuNotice) Send(e Event) error {
+	card := FeishuCard(e, f.Conf.VerificationToken != "", cfg.Cfg.AppURL)
+	if f.Conf.AppID != "" {
+		return f.sendApp(card)
+	}
+	return f.sendRobot(card)
+}
+
+// FeishuCard 渲染事件消息卡片。审批类事件在 callback 为 true 时渲染通过、拒绝按钮，由卡片回调完成审批，
+// 否则渲染跳转到 Juno 的按钮
+func FeishuCard(e Event, callback bool, appURL string) map[string]interface{} {
+	subject := e.Subject
+	if subject == "" {
+		subject = fmt.Sprintf("[Juno] %s 事件通知", e.Type)
+	}
+
+	color := "blue"
+	switch e.Severity {
+	case SeverityWarning:
+		color = "orange"
+	case SeverityError:
+		color = "red"
+	}
+
+	var fields []string
+	if e.App != "" {
+		fields = append(fields, "**应用：**"+e.App)
+	}
+	if e.Env != "" {
+		fields = append(fields, "**环境：**"+e.Env)
+	}
+	if e.Severity != "" {
+		fields = append(fields, "**级别：**"+e.Severity)
+	}
+	if len(e.Mentions) > 0 {
+		fields = append(fields, "**负责人：**"+strings.Join(e.Mentions, ", "))
+	}
+
+	elements := make([]interface{}, 0)
+	if len(fields) > 0 {
+		elements = append(elements, feishuMarkdown(strings.Join(fields, "\n")))
+	}
+	elements = append(elements, feishuMarkdown(e.Content))
+
+	if e.Approval != nil {
+		var actions []interface{}
+		if callback {
+			actions = []interface{}{
+				feishuButton("通过", "primary", FeishuActionValue{Kind: e.Approval.Kind, ID: e.Approval.ID, Action: FeishuActionApprove}),
+				feishuButton("拒绝", "danger", FeishuActionValue{Kind: e.Approval.Kind, ID: e.Approval.ID, Action: FeishuActionReject}),
+			}
+		} else if appURL != "" {
+			actions = []interface{}{map[string]interface{}{
+				"tag":  "button",
+				"text": map[string]string{"tag": "plain_text", "content": "前往 Juno 审批"},
+				"type": "primary",
+				"url":  strings.TrimSuffix(appURL, "/"),
+			}}
+		}
+		if len(actions) > 0 {
+			elements = append(elements, map[string]interface{}{"tag": "action", "actions": actions})
+		}
+	}
+
+	return map[string]interface{}{
+		"config": map[string]bool{"wide_screen_mode": true},
+		"header": map[string]interface{}{
+			"template": color,
+			"title":    map[string]string{"tag": "plain_text", "content": subject},
+		},
+		"elements": elements,
+	}
+}
+
+// FeishuResultCard 卡片审批完成后替换原卡片，去掉审批按钮并展示处理结果
+func FeishuResultCard(title, content string, success bool) map[string]interface{} {
+	color := "green"
+	if !success {
+		color = "red"
+	}
+	return map[string]interface{}{
+		"config": map[string]bool{"wide_screen_mode": true},
+		"header": map[string]interface{}{
+			"template": color,
+			"title":    map[string]string{"tag": "plain_text", "content": title},
+		},
+		"elements": []interface{}{feishuMarkdown(content)},
+	}
+}
+
+// SignFeishu 群机器人签名：以 timestamp + "\n" + secret 为密钥对空字符串做 HMAC-SHA256 后 base64
+func SignFeishu(secret string, timestamp int64) string {
+	h := hmac.New(sha256.New, []byte(strconv.FormatInt(timestamp, 10)+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (f *FeishuNotice) sendRobot(card map[string]interface{}) error {
+	if f.Conf.WebHook == "" {
+		return fmt.Errorf("feishu webhook is empty")
+	}
+
+	payload := map[string]interface{}{
+		"msg_type": "interactive",
+		"card":     card,
+	}
+	if f.Conf.Secret != "" {
+		timestamp := time.Now().Unix()
+		payload["timestamp"] = strconv.FormatInt(timestamp, 10)
+		payload["sign"] = SignFeishu(f.Conf.Secret, timestamp)
+	}
+	return f.post(f.Conf.WebHook, "", payload)
+}
+
+func (f *FeishuNotice) sendApp(card map[string]interface{}) error {
+	if f.Conf.ChatID == "" {
+		return fmt.Errorf("feishu chatId is empty")
+	}
+
+	content, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+
+	token, err := f.tenantAccessToken()
+	if err != nil {
+		return err
+	}
+
+	err = f.post(feishuAPI+"/im/v1/messages?receive_id_type=chat_id", token, map[string]interface{}{
+		"receive_id": f.Conf.ChatID,
+		"msg_type":   "interactive",
+		"content":    string(content),
+	})
+	if err != nil {
+		// tenant_access_token 可能已失效，下次重新获取
+		feishuTokenMu.Lock()
+		feishuToken.token = ""
+		feishuTokenMu.Unlock()
+	}
+	return err
+}
+
+// tenantAccessToken 获取并缓存应用 tenant_access_token，提前 5 分钟刷新
+func (f *FeishuNotice) tenantAccessToken() (string, error) {
+	feishuTokenMu.Lock()
+	defer feishuTokenMu.Unlock()
+
+	if feishuToken.appID == f.Conf.AppID && feishuToken.token != "" && time.Now().Before(feishuToken.expireAt) {
+		return feishuToken.token, nil
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"app_id":     f.Conf.AppID,
+		"app_secret": f.Conf.AppSecret,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := feishuClient.Post(feishuAPI+"/auth/v3/tenant_access_token/internal", "application/json; charset=utf-8", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result feishuResp
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return "", err
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("tenant_access_token failed: %d %s", result.Code, result.Msg)
+	}
+
+	feishuToken.appID = f.Conf.AppID
+	feishuToken.token = result.TenantAccessToken
+	feishuToken.expireAt = time.Now().Add(time.Duration(result.Expire)*time.Second - 5*time.Minute)
+	return result.TenantAccessToken, nil
+}
+
+func (f *FeishuNotice) post(addr, token string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := feishuClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result feishuResp
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return fmt.Errorf("decode response failed: %s", err.Error())
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu send failed: %d %s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+func feishuMarkdown(content string) map[string]interface{} {
+	return map[string]interface{}{
+		"tag":  "div",
+		"text": map[string]string{"tag": "lark_md", "content": content},
+	}
+}
+
+func feishuButton(text, buttonType string, value FeishuActionValue) map[string]interface{} {
+	return map[string]interface{}{
+		"tag":   "button",
+		"text":  map[string]string{"tag": "plain_text", "content": text},
+		"type":  buttonType,
+		"value": value,
+	}
+}
@@ -0,0 +1,91 @@
+package notice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+func TestFeishuCard(t *testing.T) {
+	e := Event{
+		Type:     EventApproval,
+		App:      "juno-admin",
+		Env:      "prod",
+		Severity: SeverityWarning,
+		Subject:  "alice 申请应用 juno-admin 的权限",
+		Content:  "请审批",
+		Approval: &Approval{Kind: ApprovalAccessRequest, ID: 12},
+	}
+
+	b, _ := json.Marshal(FeishuCard(e, true, ""))
+	card := string(b)
+	for _, want := range []string{`"template":"orange"`, `alice 申请应用 juno-admin 的权限`, `**环境：**prod`,
+		`{"kind":"access_request","id":12,"action":"approve"}`, `{"kind":"access_request","id":12,"action":"reject"}`} {
+		if !strings.Contains(card, want) {
+			t.Errorf("card missing %s:\n%s", want, card)
+		}
+	}
+
+	b, _ = json.Marshal(FeishuCard(e, false, "https://juno.example.com/"))
+	if card = string(b); strings.Contains(card, `"value"`) || !strings.Contains(card, `"url":"https://juno.example.com"`) {
+		t.Errorf("card without callback should link to juno:\n%s", card)
+	}
+
+	e.Approval = nil
+	b, _ = json.Marshal(FeishuCard(e, true, ""))
+	if strings.Contains(string(b), `"tag":"action"`) {
+		t.Errorf("card without approval should not have actions:\n%s", b)
+	}
+}
+
+func TestFeishuSend(t *testing.T) {
+	var robot, app map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robot":
+			_ = json.NewDecoder(r.Body).Decode(&robot)
+		case "/auth/v3/tenant_access_token/internal":
+			_, _ = w.Write([]byte(`{"code":0,"tenant_access_token":"t-token","expire":7200}`))
+			return
+		case "/im/v1/messages":
+			if r.Header.Get("Authorization") != "Bearer t-token" || r.URL.Query().Get("receive_id_type") != "chat_id" {
+				_, _ = w.Write([]byte(`{"code":99991663,"msg":"invalid token"}`))
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&app)
+		}
+		_, _ = w.Write([]byte(`{"code":0,"msg":"success"}`))
+	}))
+	defer server.Close()
+
+	defaultAPI := feishuAPI
+	feishuAPI = server.URL
+	defer func() { feishuAPI = defaultAPI }()
+
+	e := Event{Type: EventAlert, Content: "agent offline"}
+	f := &FeishuNotice{Conf: cfg.NoticeFeishu{WebHook: server.URL + "/robot", Secret: "secret"}}
+	if err := f.Send(e); err != nil {
+		t.Fatal(err)
+	}
+	if robot["msg_type"] != "interactive" || robot["sign"] == "" || robot["timestamp"] == "" || robot["card"] == nil {
+		t.Errorf("robot payload = %v", robot)
+	}
+
+	f = &FeishuNotice{Conf: cfg.NoticeFeishu{AppID: "cli_a", AppSecret: "s", ChatID: "oc_1"}}
+	if err := f.Send(e); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := app["content"].(string)
+	if app["receive_id"] != "oc_1" || !strings.Contains(content, "agent offline") {
+		t.Errorf("app payload = %v", app)
+	}
+
+	f = &FeishuNotice{Conf: cfg.NoticeFeishu{WebHook: server.URL + "/im/v1/messages"}}
+	if err := f.Send(e); err == nil {
+		t.Error("send should fail when feishu returns non-zero code")
+	}
+}
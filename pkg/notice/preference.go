@@ -0,0 +1,35 @@
+package notice
+
+// Preference 用户通知偏好，字段为空时不做限制
+type Preference struct {
+	// MutedEvents 不接收的事件类型
+	MutedEvents []string
+	// MinSeverity 接收通知的最低级别
+	MinSeverity string
+	// Channels 接收通知的渠道
+	Channels []string
+}
+
+// Accept 用户是否在 channel 渠道接收该事件
+func (p Preference) Accept(eventType, severity, channel string) bool {
+	if containsString(p.MutedEvents, eventType) {
+		return false
+	}
+	if p.MinSeverity != "" && SeverityLevel(severity) < SeverityLevel(p.MinSeverity) {
+		return false
+	}
+	return len(p.Channels) == 0 || containsString(p.Channels, channel)
+}
+
+// AcceptMention 钉钉、Slack、企业微信、飞书共用 @ 列表，接收其中任一渠道时保留 @
+func (p Preference) AcceptMention(eventType, severity string) bool {
+	for _, channel := range Channels {
+		if channel == ChannelEmail || channel == ChannelWebhook {
+			continue
+		}
+		if p.Accept(eventType, severity, channel) {
+			return true
+		}
+	}
+	return false
+}
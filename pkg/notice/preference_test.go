@@ -0,0 +1,39 @@
+package notice
+
+import "testing"
+
+func TestPreferenceAccept(t *testing.T) {
+	cases := []struct {
+		name     string
+		pref     Preference
+		event    string
+		severity string
+		channel  string
+		want     bool
+	}{
+		{"empty", Preference{}, EventPipeline, SeverityInfo, ChannelEmail, true},
+		{"muted", Preference{MutedEvents: []string{EventPipeline}}, EventPipeline, SeverityError, ChannelEmail, false},
+		{"other event", Preference{MutedEvents: []string{EventPipeline}}, EventAlert, SeverityInfo, ChannelEmail, true},
+		{"below severity", Preference{MinSeverity: SeverityWarning}, EventAlert, SeverityInfo, ChannelEmail, false},
+		{"at severity", Preference{MinSeverity: SeverityWarning}, EventAlert, SeverityWarning, ChannelEmail, true},
+		{"channel off", Preference{Channels: []string{ChannelDing}}, EventAlert, SeverityError, ChannelEmail, false},
+		{"channel on", Preference{Channels: []string{ChannelDing}}, EventAlert, SeverityError, ChannelDing, true},
+	}
+	for _, c := range cases {
+		if got := c.pref.Accept(c.event, c.severity, c.channel); got != c.want {
+			t.Errorf("%s: Accept = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPreferenceAcceptMention(t *testing.T) {
+	if !(Preference{}).AcceptMention(EventAlert, SeverityInfo) {
+		t.Error("empty preference should accept mention")
+	}
+	if (Preference{Channels: []string{ChannelEmail, ChannelWebhook}}).AcceptMention(EventAlert, SeverityInfo) {
+		t.Error("email only preference should not accept mention")
+	}
+	if !(Preference{Channels: []string{ChannelEmail, ChannelFeishu}}).AcceptMention(EventAlert, SeverityInfo) {
+		t.Error("feishu preference should accept mention")
+	}
+}
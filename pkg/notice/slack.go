@@ -0,0 +1,143 @@
+package notice
+
+// Slack 消息文档
+// https://api.slack.com/messaging/webhooks
+// https://api.slack.com/methods/chat.postMessage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+var slackClient = &http.Client{Timeout: 5 * time.Second}
+
+// SlackTarget 消息发送目标，bot token 模式使用 Channel，webhook 模式使用 WebHook
+type SlackTarget struct {
+	Channel string
+	WebHook string
+}
+
+type SlackNotice struct{}
+
+// Send 发送文本消息到默认频道
+func (s *SlackNotice) Send(content string) error {
+	return s.SendEvent("", "", content)
+}
+
+// SendEvent 按应用、事件类型路由后发送文本消息
+func (s *SlackNotice) SendEvent(app, event, content string) error {
+	conf := cfg.Cfg.Notice.Slack
+	target := MatchSlackRoute(conf, app, event)
+	if conf.Token != "" {
+		return s.postMessage(conf.Token, target.Channel, content)
+	}
+	return s.postWebhook(target, content)
+}
+
+// MatchSlackRoute 选择匹配的路由，同时匹配应用和事件的规则优先，其次是只匹配应用、只匹配事件的规则，都不匹配时使用默认配置
+func MatchSlackRoute(conf cfg.NoticeSlack, app, event string) SlackTarget {
+	target := SlackTarget{Channel: conf.Channel, WebHook: conf.WebHook}
+	best := -1
+	for _, route := range conf.Routes {
+		if (route.App != "" && route.App != app) || (route.Event != "" && route.Event != event) {
+			continue
+		}
+
+		score := 0
+		if route.App != "" {
+			score += 2
+		}
+		if route.Event != "" {
+			score++
+		}
+		if score <= best {
+			continue
+		}
+
+		best = score
+		target = SlackTarget{Channel: route.Channel, WebHook: route.WebHook}
+		if target.Channel == "" {
+			target.Channel = conf.Channel
+		}
+		if target.WebHook == "" {
+			target.WebHook = conf.WebHook
+		}
+	}
+	return target
+}
+
+func (s *SlackNotice) postMessage(token, channel, content string) error {
+	if channel == "" {
+		return fmt.Errorf("slack channel is empty")
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"channel": channel,
+		"text":    content,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, slackPostMessageURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := slackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// chat.postMessage 失败时仍返回 200，需要检查 ok 字段
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return fmt.Errorf("slack response decode failed: %s", err.Error())
+	}
+	if !result.OK {
+		return fmt.Errorf("slack chat.postMessage failed: %s", result.Error)
+	}
+	return nil
+}
+
+func (s *SlackNotice) postWebhook(target SlackTarget, content string) error {
+	if target.WebHook == "" {
+		return fmt.Errorf("slack webhook is empty")
+	}
+
+	payload := map[string]string{"text": content}
+	if target.Channel != "" {
+		payload["channel"] = target.Channel
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := slackClient.Post(target.WebHook, "application/json; charset=utf-8", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("slack webhook failed: %d %s", resp.StatusCode, string(msg))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package notice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+func TestMatchSlackRoute(t *testing.T) {
+	conf := cfg.NoticeSlack{
+		WebHook: "https://hooks.slack.com/default",
+		Channel: "#juno",
+		Routes: []cfg.NoticeSlackRoute{
+			{Event: EventPipeline, Channel: "#ci"},
+			{App: "juno-admin", Channel: "#juno-admin"},
+			{App: "juno-admin", Event: EventAlert, Channel: "#juno-admin-alert", WebHook: "https://hooks.slack.com/alert"},
+		},
+	}
+
+	tests := []struct {
+		app, event string
+		want       SlackTarget
+	}{
+		{"", "", SlackTarget{"#juno", "https://hooks.slack.com/default"}},
+		{"other", EventPipeline, SlackTarget{"#ci", "https://hooks.slack.com/default"}},
+		{"juno-admin", EventPipeline, SlackTarget{"#juno-admin", "https://hooks.slack.com/default"}},
+		{"juno-admin", EventAlert, SlackTarget{"#juno-admin-alert", "https://hooks.slack.com/alert"}},
+		{"other", EventApproval, SlackTarget{"#juno", "https://hooks.slack.com/default"}},
+	}
+
+	for _, tt := range tests {
+		if got := MatchSlackRoute(conf, tt.app, tt.event); got != tt.want {
+			t.Errorf("MatchSlackRoute(%q, %q) = %+v, want %+v", tt.app, tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestSlackPostWebhook(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if payload["text"] == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("no_text"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	s := &SlackNotice{}
+	err := s.postWebhook(SlackTarget{WebHook: server.URL, Channel: "#ci"}, "pipeline success")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload["channel"] != "#ci" || payload["text"] != "pipeline success" {
+		t.Errorf("payload = %v", payload)
+	}
+
+	err = s.postWebhook(SlackTarget{WebHook: server.URL}, "")
+	if err == nil {
+		t.Error("empty text should fail")
+	}
+
+	err = s.postWebhook(SlackTarget{}, "pipeline success")
+	if err == nil {
+		t.Error("empty webhook should fail")
+	}
+}
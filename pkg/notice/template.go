@@ -0,0 +1,64 @@
+package notice
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TemplateProvider 按事件类型、渠道查找自定义消息模板，ok 为 false 时使用默认消息
+type TemplateProvider func(eventType, channel string) (subject, body string, ok bool)
+
+var (
+	templateProvider TemplateProvider
+
+	templateFuncs = template.FuncMap{
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+)
+
+// SetTemplateProvider 设置自定义消息模板来源
+func SetTemplateProvider(provider TemplateProvider) {
+	templateProvider = provider
+}
+
+// RenderTemplate 使用 Go 模板渲染事件标题和正文，模板为空时保留原值
+func RenderTemplate(subjectTpl, bodyTpl string, e Event) (subject, content string, err error) {
+	subject, err = renderText("subject", subjectTpl, e)
+	if err != nil {
+		return
+	}
+	content, err = renderText("body", bodyTpl, e)
+	if err != nil {
+		return
+	}
+
+	if subjectTpl == "" {
+		subject = e.Subject
+	}
+	if bodyTpl == "" {
+		content = e.Content
+	}
+	return
+}
+
+func renderText(name, text string, e Event) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	tpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse %s template failed: %s", name, err.Error())
+	}
+
+	var buf bytes.Buffer
+	err = tpl.Execute(&buf, e)
+	if err != nil {
+		return "", fmt.Errorf("execute %s template failed: %s", name, err.Error())
+	}
+	return buf.String(), nil
+}
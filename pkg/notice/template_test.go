@@ -0,0 +1,44 @@
+package notice
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	e := Event{
+		Type:     EventPipeline,
+		App:      "juno-admin",
+		Env:      "prod",
+		Severity: SeverityError,
+		Subject:  "default subject",
+		Content:  "default content",
+		Time:     time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Mentions: []string{"alice", "bob"},
+	}
+
+	subject, content, err := RenderTemplate(
+		`【{{upper .Severity}}】{{.App}}@{{.Env}}`,
+		`{{.Time.Format "2006-01-02 15:04"}} {{.Content}} @{{join .Mentions " @"}}`, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "【ERROR】juno-admin@prod"; subject != want {
+		t.Errorf("subject = %q, want %q", subject, want)
+	}
+	if want := "2020-01-02 03:04 default content @alice @bob"; content != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+
+	subject, content, err = RenderTemplate("", "{{.Type}}", e)
+	if err != nil || subject != e.Subject || content != EventPipeline {
+		t.Errorf("empty subject template = %q, %q, %v", subject, content, err)
+	}
+
+	if _, _, err = RenderTemplate("{{.Unknown}}", "", e); err == nil {
+		t.Error("unknown field should fail")
+	}
+	if _, _, err = RenderTemplate("{{.App", "", e); err == nil {
+		t.Error("invalid template should fail")
+	}
+}
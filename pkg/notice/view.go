@@ -1,5 +1,21 @@
 package notice
 
+// 通知事件类型，用于按事件路由通知渠道
+const (
+	EventPipeline = "pipeline"
+	EventAlert    = "alert"
+	EventApproval = "approval"
+	EventConfig   = "config"
+	EventDigest   = "digest"
+)
+
+// 待审批对象类型
+const (
+	ApprovalAccessRequest = "access_request"
+	ApprovalPromotion     = "promotion"
+	ApprovalAppTransfer   = "app_transfer"
+)
+
 // Message ..
 type Message struct {
 	Subject string
@@ -0,0 +1,126 @@
+package notice
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+const (
+	WebhookHeaderEvent     = "X-Juno-Event"
+	WebhookHeaderTimestamp = "X-Juno-Timestamp"
+	WebhookHeaderSignature = "X-Juno-Signature"
+
+	defaultWebhookTimeout = 5 * time.Second
+)
+
+var webhookFuncs = template.FuncMap{
+	// json 将值编码为 JSON，用于在模板中安全地嵌入字符串
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// WebhookNotice 通用 Webhook 通知
+type WebhookNotice struct {
+	Conf cfg.NoticeWebhook
+}
+
+// Subscribed 是否订阅了该类型的事件
+func (w *WebhookNotice) Subscribed(eventType string) bool {
+	if len(w.Conf.Events) == 0 {
+		return true
+	}
+	for _, item := range w.Conf.Events {
+		if item == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Send 渲染请求体并发送，非 2xx 响应视为失败
+func (w *WebhookNotice) Send(e Event) error {
+	if w.Conf.URL == "" {
+		return fmt.Errorf("webhook url is empty")
+	}
+
+	body, err := w.Render(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.Conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	for key, value := range w.Conf.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set(WebhookHeaderEvent, e.Type)
+	if w.Conf.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookHeaderTimestamp, timestamp)
+		req.Header.Set(WebhookHeaderSignature, SignWebhook(w.Conf.Secret, timestamp, body))
+	}
+
+	timeout := w.Conf.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("webhook %s failed: %d %s", w.Conf.Name, resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// Render 使用模板渲染请求体，未配置模板时发送事件 JSON
+func (w *WebhookNotice) Render(e Event) ([]byte, error) {
+	if w.Conf.Template == "" {
+		return json.Marshal(e)
+	}
+
+	tpl, err := template.New(w.Conf.Name).Funcs(webhookFuncs).Parse(w.Conf.Template)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s template invalid: %s", w.Conf.Name, err.Error())
+	}
+
+	var buf bytes.Buffer
+	err = tpl.Execute(&buf, e)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s template execute failed: %s", w.Conf.Name, err.Error())
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook %s template output is not valid json", w.Conf.Name)
+	}
+	return buf.Bytes(), nil
+}
+
+// SignWebhook 签名为 sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))，接收方可校验时间戳防止重放
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,87 @@
+package notice
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+func TestWebhookRender(t *testing.T) {
+	e := Event{Type: EventAlert, App: "juno-admin", Content: "agent \"a1\" offline\n", Time: time.Unix(0, 0).UTC()}
+
+	w := &WebhookNotice{Conf: cfg.NoticeWebhook{Name: "bot", Template: `{"text":{{json .Content}},"app":"{{.App}}"}`}}
+	body, err := w.Render(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]string
+	if err = json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["text"] != e.Content || payload["app"] != "juno-admin" {
+		t.Errorf("payload = %v", payload)
+	}
+
+	w.Conf.Template = `{"text":"{{.Content}}"}`
+	if _, err = w.Render(e); err == nil {
+		t.Error("unescaped content should produce invalid json")
+	}
+
+	w.Conf.Template = ""
+	body, err = w.Render(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Event
+	if err = json.Unmarshal(body, &got); err != nil || !reflect.DeepEqual(got, e) {
+		t.Errorf("default payload = %s, err = %v", body, err)
+	}
+}
+
+func TestWebhookSubscribed(t *testing.T) {
+	w := &WebhookNotice{}
+	if !w.Subscribed(EventPipeline) {
+		t.Error("empty events should subscribe all")
+	}
+
+	w.Conf.Events = []string{EventAlert}
+	if w.Subscribed(EventPipeline) || !w.Subscribed(EventAlert) {
+		t.Error("events filter not applied")
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	w := &WebhookNotice{Conf: cfg.NoticeWebhook{
+		Name:    "bot",
+		URL:     server.URL,
+		Secret:  "s3cret",
+		Headers: map[string]string{"X-Token": "abc"},
+	}}
+	err := w.Send(Event{Type: EventPipeline, App: "juno-admin", Content: "ok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Get("X-Token") != "abc" || header.Get(WebhookHeaderEvent) != EventPipeline {
+		t.Errorf("header = %v", header)
+	}
+	want := SignWebhook("s3cret", header.Get(WebhookHeaderTimestamp), body)
+	if header.Get(WebhookHeaderSignature) != want {
+		t.Errorf("signature = %s, want %s", header.Get(WebhookHeaderSignature), want)
+	}
+}
@@ -0,0 +1,179 @@
+package notice
+
+// 企业微信消息文档
+// https://developer.work.weixin.qq.com/document/path/91770
+// https://developer.work.weixin.qq.com/document/path/90236
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+var (
+	weComAPI    = "https://qyapi.weixin.qq.com/cgi-bin"
+	weComClient = &http.Client{Timeout: 5 * time.Second}
+
+	weComTokenMu sync.Mutex
+	weComToken   struct {
+		corpID   string
+		token    string
+		expireAt time.Time
+	}
+)
+
+type weComResp struct {
+	ErrCode     int    `json:"errcode"`
+	ErrMsg      string `json:"errmsg"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// WeComNotice 企业微信通知，支持群机器人和应用消息
+type WeComNotice struct {
+	Conf cfg.NoticeWeCom
+}
+
+// Send 发送 markdown 消息到群机器人并 @ 负责人，配置了企业应用时同时以应用消息发送给负责人
+func (w *WeComNotice) Send(e Event) error {
+	var errs []string
+	if w.Conf.WebHook != "" {
+		err := w.sendRobot(e)
+		if err != nil {
+			errs = append(errs, "robot: "+err.Error())
+		}
+	}
+
+	if w.Conf.CorpID != "" && len(e.Mentions) > 0 {
+		err := w.sendApp(e)
+		if err != nil {
+			errs = append(errs, "app: "+err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("wecom send failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// WeComMarkdown 渲染企业微信 markdown 消息，mention 为 true 时在末尾 @ 负责人
+func WeComMarkdown(e Event, mention bool) string {
+	var b strings.Builder
+	subject := e.Subject
+	if subject == "" {
+		subject = fmt.Sprintf("[Juno] %s 事件通知", e.Type)
+	}
+	b.WriteString("**" + subject + "**\n")
+	if e.App != "" {
+		b.WriteString("> 应用: <font color=\"info\">" + e.App + "</font>\n")
+	}
+	if e.Severity != "" {
+		color := "comment"
+		switch e.Severity {
+		case SeverityWarning, SeverityError:
+			color = "warning"
+		}
+		b.WriteString("> 级别: <font color=\"" + color + "\">" + e.Severity + "</font>\n")
+	}
+	b.WriteString("\n" + e.Content)
+
+	if mention && len(e.Mentions) > 0 {
+		b.WriteString("\n")
+		for _, userID := range e.Mentions {
+			b.WriteString("<@" + userID + ">")
+		}
+	}
+	return b.String()
+}
+
+func (w *WeComNotice) sendRobot(e Event) error {
+	return w.post(w.Conf.WebHook, map[string]interface{}{
+		"msgtype":  "markdown",
+		"markdown": map[string]string{"content": WeComMarkdown(e, true)},
+	})
+}
+
+func (w *WeComNotice) sendApp(e Event) error {
+	token, err := w.accessToken()
+	if err != nil {
+		return err
+	}
+
+	err = w.post(weComAPI+"/message/send?access_token="+url.QueryEscape(token), map[string]interface{}{
+		"touser":   strings.Join(e.Mentions, "|"),
+		"msgtype":  "markdown",
+		"agentid":  w.Conf.AgentID,
+		"markdown": map[string]string{"content": WeComMarkdown(e, false)},
+	})
+	if err != nil {
+		// access_token 可能已被提前失效，下次重新获取
+		weComTokenMu.Lock()
+		weComToken.token = ""
+		weComTokenMu.Unlock()
+	}
+	return err
+}
+
+// accessToken 获取并缓存应用 access_token，提前 5 分钟刷新
+func (w *WeComNotice) accessToken() (string, error) {
+	weComTokenMu.Lock()
+	defer weComTokenMu.Unlock()
+
+	if weComToken.corpID == w.Conf.CorpID && weComToken.token != "" && time.Now().Before(weComToken.expireAt) {
+		return weComToken.token, nil
+	}
+
+	query := url.Values{}
+	query.Set("corpid", w.Conf.CorpID)
+	query.Set("corpsecret", w.Conf.CorpSecret)
+	resp, err := weComClient.Get(weComAPI + "/gettoken?" + query.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result weComResp
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return "", err
+	}
+	if result.ErrCode != 0 {
+		return "", fmt.Errorf("gettoken failed: %d %s", result.ErrCode, result.ErrMsg)
+	}
+
+	weComToken.corpID = w.Conf.CorpID
+	weComToken.token = result.AccessToken
+	weComToken.expireAt = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - 5*time.Minute)
+	return result.AccessToken, nil
+}
+
+func (w *WeComNotice) post(addr string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := weComClient.Post(addr, "application/json; charset=utf-8", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result weComResp
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return fmt.Errorf("decode response failed: %s", err.Error())
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("%d %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
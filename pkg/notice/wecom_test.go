@@ -0,0 +1,80 @@
+package notice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/douyu/juno/pkg/cfg"
+)
+
+func TestWeComMarkdown(t *testing.T) {
+	e := Event{Type: EventPipeline, App: "juno-admin", Severity: SeverityError, Content: "流水线执行失败", Mentions: []string{"alice", "bob"}}
+
+	md := WeComMarkdown(e, true)
+	for _, want := range []string{"**[Juno] pipeline 事件通知**", "juno-admin", `<font color="warning">error</font>`, "流水线执行失败", "<@alice><@bob>"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	if strings.Contains(WeComMarkdown(e, false), "<@alice>") {
+		t.Error("mentions should be omitted")
+	}
+}
+
+func TestWeComSend(t *testing.T) {
+	var robot, app map[string]interface{}
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robot":
+			_ = json.NewDecoder(r.Body).Decode(&robot)
+		case "/gettoken":
+			tokenRequests++
+			if r.URL.Query().Get("corpsecret") != "secret" {
+				_, _ = w.Write([]byte(`{"errcode":40001,"errmsg":"invalid credential"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"errcode":0,"access_token":"token","expires_in":7200}`))
+			return
+		case "/message/send":
+			if r.URL.Query().Get("access_token") != "token" {
+				_, _ = w.Write([]byte(`{"errcode":40014,"errmsg":"invalid access_token"}`))
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&app)
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	defaultAPI := weComAPI
+	weComAPI = server.URL
+	defer func() { weComAPI = defaultAPI }()
+
+	w := &WeComNotice{Conf: cfg.NoticeWeCom{WebHook: server.URL + "/robot", CorpID: "corp", CorpSecret: "secret", AgentID: 1000002}}
+	e := Event{Type: EventAlert, Content: "agent offline", Mentions: []string{"alice", "bob"}}
+	for i := 0; i < 2; i++ {
+		if err := w.Send(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if robot["msgtype"] != "markdown" {
+		t.Errorf("robot payload = %v", robot)
+	}
+	if app["touser"] != "alice|bob" || app["agentid"] != float64(1000002) {
+		t.Errorf("app payload = %v", app)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("token requests = %d, want cached token", tokenRequests)
+	}
+
+	w.Conf.CorpID, w.Conf.CorpSecret = "other", "wrong"
+	if err := w.Send(e); err == nil || !strings.Contains(err.Error(), "40001") {
+		t.Errorf("invalid secret err = %v", err)
+	}
+}
@@ -0,0 +1,432 @@
+// Package scim 实现 SCIM 2.0（RFC 7643、RFC 7644）中 Juno 用到的资源结构、过滤表达式与 PATCH 操作解析
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+
+	// ContentType SCIM 响应的 Content-Type
+	ContentType = "application/scim+json"
+
+	// MaxResults 单次列表请求最多返回的资源数
+	MaxResults = 200
+)
+
+// ScimType 错误类型，见 RFC 7644 3.12
+const (
+	ScimTypeInvalidFilter = "invalidFilter"
+	ScimTypeUniqueness    = "uniqueness"
+	ScimTypeInvalidValue  = "invalidValue"
+	ScimTypeInvalidPath   = "invalidPath"
+	ScimTypeNoTarget      = "noTarget"
+)
+
+type (
+	Meta struct {
+		ResourceType string     `json:"resourceType"`
+		Created      *time.Time `json:"created,omitempty"`
+		LastModified *time.Time `json:"lastModified,omitempty"`
+		Location     string     `json:"location,omitempty"`
+	}
+
+	Name struct {
+		Formatted  string `json:"formatted,omitempty"`
+		GivenName  string `json:"givenName,omitempty"`
+		FamilyName string `json:"familyName,omitempty"`
+	}
+
+	Email struct {
+		Value   string `json:"value"`
+		Type    string `json:"type,omitempty"`
+		Primary bool   `json:"primary,omitempty"`
+	}
+
+	// Ref 对其他资源的引用，用于用户所属的组和组成员
+	Ref struct {
+		Value   string `json:"value"`
+		Display string `json:"display,omitempty"`
+		Ref     string `json:"$ref,omitempty"`
+	}
+
+	User struct {
+		Schemas     []string `json:"schemas"`
+		ID          string   `json:"id,omitempty"`
+		ExternalID  string   `json:"externalId,omitempty"`
+		UserName    string   `json:"userName"`
+		Name        *Name    `json:"name,omitempty"`
+		DisplayName string   `json:"displayName,omitempty"`
+		Emails      []Email  `json:"emails,omitempty"`
+		Active      *bool    `json:"active,omitempty"`
+		Groups      []Ref    `json:"groups,omitempty"`
+		Meta        *Meta    `json:"meta,omitempty"`
+	}
+
+	Group struct {
+		Schemas     []string `json:"schemas"`
+		ID          string   `json:"id,omitempty"`
+		ExternalID  string   `json:"externalId,omitempty"`
+		DisplayName string   `json:"displayName"`
+		Members     []Ref    `json:"members,omitempty"`
+		Meta        *Meta    `json:"meta,omitempty"`
+	}
+
+	ListResponse struct {
+		Schemas      []string    `json:"schemas"`
+		TotalResults int         `json:"totalResults"`
+		StartIndex   int         `json:"startIndex"`
+		ItemsPerPage int         `json:"itemsPerPage"`
+		Resources    interface{} `json:"Resources"`
+	}
+
+	PatchRequest struct {
+		Schemas    []string         `json:"schemas"`
+		Operations []PatchOperation `json:"Operations"`
+	}
+
+	PatchOperation struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path,omitempty"`
+		Value json.RawMessage `json:"value,omitempty"`
+	}
+)
+
+// Error SCIM 错误响应，同时实现 error 接口
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// NewError ..
+func NewError(status int, scimType, detail string) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+// NotFound ..
+func NotFound(detail string) *Error {
+	return NewError(http.StatusNotFound, "", detail)
+}
+
+// BadRequest ..
+func BadRequest(scimType, detail string) *Error {
+	return NewError(http.StatusBadRequest, scimType, detail)
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// StatusCode HTTP 状态码
+func (e *Error) StatusCode() int {
+	code, err := strconv.Atoi(e.Status)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	return code
+}
+
+// NewListResponse ..
+func NewListResponse(resources interface{}, total, startIndex, count int) ListResponse {
+	return ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: count,
+		Resources:    resources,
+	}
+}
+
+// Filter 过滤表达式，只支持 IdP 同步时使用的 `attribute eq "value"`
+type Filter struct {
+	Attribute string
+	Value     string
+}
+
+// ParseFilter 解析过滤表达式，空字符串返回零值
+func ParseFilter(filter string) (f Filter, err error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return
+	}
+
+	parts := strings.SplitN(filter, " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[1], "eq") {
+		err = BadRequest(ScimTypeInvalidFilter, fmt.Sprintf("unsupported filter: %s", filter))
+		return
+	}
+
+	value, err := unquote(strings.TrimSpace(parts[2]))
+	if err != nil {
+		err = BadRequest(ScimTypeInvalidFilter, fmt.Sprintf("invalid filter value: %s", parts[2]))
+		return
+	}
+
+	f.Attribute = parts[0]
+	f.Value = value
+	return
+}
+
+// Pagination 解析 startIndex、count，startIndex 从 1 开始，返回数据库查询使用的 offset、limit
+func Pagination(startIndex, count int) (offset, limit, start int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count == 0 || count > MaxResults {
+		count = MaxResults
+	}
+	return startIndex - 1, count, startIndex
+}
+
+// UserPatch 用户 PATCH 操作解析结果，nil 表示未修改
+type UserPatch struct {
+	UserName    *string
+	DisplayName *string
+	ExternalID  *string
+	Email       *string
+	Active      *bool
+}
+
+// ParseUserPatch 解析用户的 PATCH 操作，兼容 Azure AD 的大小写及字符串形式的布尔值
+func ParseUserPatch(ops []PatchOperation) (patch UserPatch, err error) {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+		default:
+			return patch, BadRequest(ScimTypeInvalidValue, fmt.Sprintf("unsupported user patch op: %s", op.Op))
+		}
+
+		if op.Path == "" {
+			var attrs map[string]json.RawMessage
+			err = json.Unmarshal(op.Value, &attrs)
+			if err != nil {
+				return patch, BadRequest(ScimTypeInvalidValue, "patch value must be an object when path is empty")
+			}
+			for path, value := range attrs {
+				err = patch.set(path, value)
+				if err != nil {
+					return
+				}
+			}
+			continue
+		}
+
+		err = patch.set(op.Path, op.Value)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (p *UserPatch) set(path string, value json.RawMessage) (err error) {
+	switch strings.ToLower(path) {
+	case "active":
+		var active bool
+		active, err = parseBool(value)
+		p.Active = &active
+	case "username":
+		p.UserName, err = parseString(value)
+	case "displayname", "name.formatted":
+		p.DisplayName, err = parseString(value)
+	case "externalid":
+		p.ExternalID, err = parseString(value)
+	case `emails[type eq "work"].value`, "emails":
+		p.Email, err = parseEmail(value)
+	default:
+		// 未支持的属性忽略，避免 IdP 同步扩展属性时整个请求失败
+		return nil
+	}
+	if err != nil {
+		return BadRequest(ScimTypeInvalidValue, fmt.Sprintf("invalid value for %s", path))
+	}
+	return nil
+}
+
+// GroupPatch 组 PATCH 操作解析结果
+type GroupPatch struct {
+	DisplayName *string
+	Add         []string
+	Remove      []string
+	// Replace 非 nil 时表示用该列表替换全部成员
+	Replace []string
+}
+
+// ParseGroupPatch 解析组的 PATCH 操作，支持修改名称以及添加、移除、替换成员
+func ParseGroupPatch(ops []PatchOperation) (patch GroupPatch, err error) {
+	for _, op := range ops {
+		path := strings.TrimSpace(op.Path)
+		lowerPath := strings.ToLower(path)
+
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			replace := strings.EqualFold(op.Op, "replace")
+			switch {
+			case lowerPath == "displayname":
+				patch.DisplayName, err = parseString(op.Value)
+			case lowerPath == "members":
+				var ids []string
+				ids, err = parseMembers(op.Value)
+				if err == nil && replace {
+					patch.Replace, patch.Add, patch.Remove = ids, nil, nil
+				} else if err == nil {
+					patch.Add = append(patch.Add, ids...)
+				}
+			case lowerPath == "":
+				var attrs struct {
+					DisplayName *string         `json:"displayName"`
+					Members     json.RawMessage `json:"members"`
+				}
+				err = json.Unmarshal(op.Value, &attrs)
+				if err == nil && attrs.DisplayName != nil {
+					patch.DisplayName = attrs.DisplayName
+				}
+				if err == nil && attrs.Members != nil {
+					var ids []string
+					ids, err = parseMembers(attrs.Members)
+					if err == nil && replace {
+						patch.Replace, patch.Add, patch.Remove = ids, nil, nil
+					} else if err == nil {
+						patch.Add = append(patch.Add, ids...)
+					}
+				}
+			default:
+				return patch, BadRequest(ScimTypeInvalidPath, fmt.Sprintf("unsupported group patch path: %s", path))
+			}
+			if err != nil {
+				return patch, BadRequest(ScimTypeInvalidValue, fmt.Sprintf("invalid value for %s", path))
+			}
+
+		case "remove":
+			if lowerPath == "members" {
+				if len(op.Value) == 0 {
+					patch.Replace, patch.Add, patch.Remove = []string{}, nil, nil
+					continue
+				}
+				var ids []string
+				ids, err = parseMembers(op.Value)
+				if err != nil {
+					return patch, BadRequest(ScimTypeInvalidValue, "invalid members value")
+				}
+				patch.Remove = append(patch.Remove, ids...)
+				continue
+			}
+
+			id, ok := ParseMemberPath(path)
+			if !ok {
+				return patch, BadRequest(ScimTypeNoTarget, fmt.Sprintf("unsupported group patch path: %s", path))
+			}
+			patch.Remove = append(patch.Remove, id)
+
+		default:
+			return patch, BadRequest(ScimTypeInvalidValue, fmt.Sprintf("unsupported group patch op: %s", op.Op))
+		}
+	}
+	return
+}
+
+// ParseMemberPath 解析 members[value eq "id"] 形式的路径
+func ParseMemberPath(path string) (id string, ok bool) {
+	if !strings.HasPrefix(strings.ToLower(path), "members[") || !strings.HasSuffix(path, "]") {
+		return "", false
+	}
+
+	f, err := ParseFilter(path[len("members[") : len(path)-1])
+	if err != nil || !strings.EqualFold(f.Attribute, "value") {
+		return "", false
+	}
+	return f.Value, true
+}
+
+func parseMembers(value json.RawMessage) (ids []string, err error) {
+	var members []Ref
+	err = json.Unmarshal(value, &members)
+	if err != nil {
+		return
+	}
+
+	ids = make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.Value)
+	}
+	return
+}
+
+func parseString(value json.RawMessage) (*string, error) {
+	var s string
+	err := json.Unmarshal(value, &s)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(strings.ToLower(s))
+}
+
+// parseEmail 兼容单个字符串与 emails 数组，数组时取 primary 或第一个
+func parseEmail(value json.RawMessage) (*string, error) {
+	if s, err := parseString(value); err == nil {
+		return s, nil
+	}
+
+	var emails []Email
+	err := json.Unmarshal(value, &emails)
+	if err != nil {
+		return nil, err
+	}
+	email := PrimaryEmail(emails)
+	return &email, nil
+}
+
+// PrimaryEmail 返回 primary 邮箱，没有时返回第一个
+func PrimaryEmail(emails []Email) string {
+	for _, e := range emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(emails) > 0 {
+		return emails[0].Value
+	}
+	return ""
+}
+
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("value must be quoted")
+	}
+	return strconv.Unquote(s)
+}
@@ -0,0 +1,169 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   Filter
+		ok     bool
+	}{
+		{"", Filter{}, true},
+		{`userName eq "alice"`, Filter{"userName", "alice"}, true},
+		{`externalId EQ "a b\"c"`, Filter{"externalId", `a b"c`}, true},
+		{`displayName eq "研发团队"`, Filter{"displayName", "研发团队"}, true},
+		{`userName co "alice"`, Filter{}, false},
+		{`userName eq alice`, Filter{}, false},
+		{`userName`, Filter{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFilter(tt.filter)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseFilter(%q) err = %v, want ok = %v", tt.filter, err, tt.ok)
+			continue
+		}
+		if err != nil {
+			if e, ok := err.(*Error); !ok || e.StatusCode() != http.StatusBadRequest || e.ScimType != ScimTypeInvalidFilter {
+				t.Errorf("ParseFilter(%q) err = %#v, want invalidFilter", tt.filter, err)
+			}
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestPagination(t *testing.T) {
+	tests := []struct {
+		startIndex, count        int
+		offset, limit, wantStart int
+	}{
+		{0, 0, 0, MaxResults, 1},
+		{1, 10, 0, 10, 1},
+		{21, 10, 20, 10, 21},
+		{-5, 1000, 0, MaxResults, 1},
+	}
+
+	for _, tt := range tests {
+		offset, limit, start := Pagination(tt.startIndex, tt.count)
+		if offset != tt.offset || limit != tt.limit || start != tt.wantStart {
+			t.Errorf("Pagination(%d, %d) = %d, %d, %d", tt.startIndex, tt.count, offset, limit, start)
+		}
+	}
+}
+
+func TestParseUserPatch(t *testing.T) {
+	body := `{"Operations":[
+		{"op":"Replace","path":"active","value":"False"},
+		{"op":"replace","value":{"displayName":"Alice","emails":[{"value":"a@x.com"},{"value":"alice@x.com","primary":true}]}},
+		{"op":"add","path":"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department","value":"dev"}
+	]}`
+
+	var req PatchRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+
+	patch, err := ParseUserPatch(req.Operations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch.Active == nil || *patch.Active {
+		t.Errorf("Active = %v, want false", patch.Active)
+	}
+	if patch.DisplayName == nil || *patch.DisplayName != "Alice" {
+		t.Errorf("DisplayName = %v, want Alice", patch.DisplayName)
+	}
+	if patch.Email == nil || *patch.Email != "alice@x.com" {
+		t.Errorf("Email = %v, want alice@x.com", patch.Email)
+	}
+	if patch.UserName != nil {
+		t.Errorf("UserName = %v, want nil", *patch.UserName)
+	}
+
+	_, err = ParseUserPatch([]PatchOperation{{Op: "remove", Path: "active"}})
+	if err == nil {
+		t.Error("remove op on user should be rejected")
+	}
+}
+
+func TestParseGroupPatch(t *testing.T) {
+	body := `{"Operations":[
+		{"op":"add","path":"members","value":[{"value":"1"},{"value":"2"}]},
+		{"op":"remove","path":"members[value eq \"3\"]"},
+		{"op":"Replace","path":"displayName","value":"platform"}
+	]}`
+
+	var req PatchRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+
+	patch, err := ParseGroupPatch(req.Operations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(patch.Add, []string{"1", "2"}) {
+		t.Errorf("Add = %v", patch.Add)
+	}
+	if !reflect.DeepEqual(patch.Remove, []string{"3"}) {
+		t.Errorf("Remove = %v", patch.Remove)
+	}
+	if patch.Replace != nil {
+		t.Errorf("Replace = %v, want nil", patch.Replace)
+	}
+	if patch.DisplayName == nil || *patch.DisplayName != "platform" {
+		t.Errorf("DisplayName = %v", patch.DisplayName)
+	}
+
+	patch, err = ParseGroupPatch([]PatchOperation{
+		{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"1"}]`)},
+		{Op: "replace", Value: json.RawMessage(`{"members":[{"value":"4"}]}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(patch.Replace, []string{"4"}) || patch.Add != nil {
+		t.Errorf("replace members: Replace = %v, Add = %v", patch.Replace, patch.Add)
+	}
+
+	patch, err = ParseGroupPatch([]PatchOperation{{Op: "remove", Path: "members"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch.Replace == nil || len(patch.Replace) != 0 {
+		t.Errorf("remove all members: Replace = %v, want empty", patch.Replace)
+	}
+
+	_, err = ParseGroupPatch([]PatchOperation{{Op: "remove", Path: "displayName"}})
+	if err == nil {
+		t.Error("remove displayName should be rejected")
+	}
+}
+
+func TestParseMemberPath(t *testing.T) {
+	tests := []struct {
+		path string
+		id   string
+		ok   bool
+	}{
+		{`members[value eq "12"]`, "12", true},
+		{`Members[Value eq "abc"]`, "abc", true},
+		{`members[display eq "12"]`, "", false},
+		{`members`, "", false},
+	}
+
+	for _, tt := range tests {
+		id, ok := ParseMemberPath(tt.path)
+		if id != tt.id || ok != tt.ok {
+			t.Errorf("ParseMemberPath(%q) = %q, %v", tt.path, id, ok)
+		}
+	}
+}
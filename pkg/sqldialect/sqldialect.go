@@ -0,0 +1,58 @@
+// Package sqldialect 元数据库支持 MySQL 和 PostgreSQL，
+// 两者语法不同的查询片段统一在这里按 gorm 的 dialect 生成
+package sqldialect
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	// 注册 gorm dialect
+	_ "github.com/jinzhu/gorm/dialects/mysql"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+)
+
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+)
+
+// Validate 配置中的 dialect 是否支持，为空时使用 MySQL
+func Validate(dialect string) (string, error) {
+	switch dialect {
+	case "":
+		return MySQL, nil
+	case MySQL, Postgres:
+		return dialect, nil
+	default:
+		return "", fmt.Errorf("unsupported database dialect %q, expect %s or %s", dialect, MySQL, Postgres)
+	}
+}
+
+// Quote 引用保留字作为的表名、列名，如 user、desc
+func Quote(db *gorm.DB, name string) string {
+	return db.Dialect().Quote(name)
+}
+
+// JSONArrayContains column 为 JSON 字符串数组，条件中的 ? 为要查找的元素
+func JSONArrayContains(db *gorm.DB, column string) string {
+	if db.Dialect().GetName() == Postgres {
+		return fmt.Sprintf("%s::jsonb @> jsonb_build_array(?::text)", column)
+	}
+	return fmt.Sprintf("JSON_CONTAINS(%s, JSON_QUOTE(?))", column)
+}
+
+// Day 时间列格式化为 YYYY-MM-DD
+func Day(db *gorm.DB, column string) string {
+	if db.Dialect().GetName() == Postgres {
+		return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD')", column)
+	}
+	return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", column)
+}
+
+// UnixDay unix 时间戳（秒）列格式化为 YYYY-MM-DD
+func UnixDay(db *gorm.DB, column string) string {
+	if db.Dialect().GetName() == Postgres {
+		return Day(db, fmt.Sprintf("to_timestamp(%s)", column))
+	}
+	return Day(db, fmt.Sprintf("FROM_UNIXTIME(%s)", column))
+}
@@ -0,0 +1,84 @@
+package sqldialect
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+// stubDriver 只用于创建 gorm.DB，不执行语句
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return stubConn{}, nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func init() {
+	sql.Register("sqldialect-stub", stubDriver{})
+}
+
+func open(t *testing.T, dialect string) *gorm.DB {
+	sqlDB, err := sql.Open("sqldialect-stub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(dialect, sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		dialect string
+		want    string
+		err     bool
+	}{
+		{"", MySQL, false},
+		{"mysql", MySQL, false},
+		{"postgres", Postgres, false},
+		{"sqlite3", "", true},
+	}
+	for _, c := range cases {
+		got, err := Validate(c.dialect)
+		if got != c.want || (err != nil) != c.err {
+			t.Errorf("Validate(%q) = %q %v", c.dialect, got, err)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	mysql, postgres := open(t, MySQL), open(t, Postgres)
+	cases := []struct {
+		name         string
+		mysql, pg    string
+		gotMy, gotPg string
+	}{
+		{"Quote", "`user`", `"user"`, Quote(mysql, "user"), Quote(postgres, "user")},
+		{"JSONArrayContains",
+			"JSON_CONTAINS(users, JSON_QUOTE(?))", "users::jsonb @> jsonb_build_array(?::text)",
+			JSONArrayContains(mysql, "users"), JSONArrayContains(postgres, "users")},
+		{"Day",
+			"DATE_FORMAT(created_at, '%Y-%m-%d')", "to_char(created_at, 'YYYY-MM-DD')",
+			Day(mysql, "created_at"), Day(postgres, "created_at")},
+		{"UnixDay",
+			"DATE_FORMAT(FROM_UNIXTIME(create_time), '%Y-%m-%d')", "to_char(to_timestamp(create_time), 'YYYY-MM-DD')",
+			UnixDay(mysql, "create_time"), UnixDay(postgres, "create_time")},
+	}
+	for _, c := range cases {
+		if c.gotMy != c.mysql {
+			t.Errorf("%s mysql = %s, want %s", c.name, c.gotMy, c.mysql)
+		}
+		if c.gotPg != c.pg {
+			t.Errorf("%s postgres = %s, want %s", c.name, c.gotPg, c.pg)
+		}
+	}
+}
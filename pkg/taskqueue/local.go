@@ -0,0 +1,257 @@
+package taskqueue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beeker1121/goque"
+	"github.com/douyu/jupiter/pkg/xlog"
+)
+
+// localPollInterval 队列为空时的轮询间隔
+const localPollInterval = time.Second
+
+// localPriorityOffset goque 的优先级为 0-255，数值大的先出队，优先级 0 对应 128
+const localPriorityOffset = 128
+
+// localQueue 消息取出即从磁盘删除，进程退出时正在处理的任务会丢失，Touch 无效。
+// goque 不支持删除和调整顺序，Remove、MoveToFront 通过取出全部消息后重新入队实现，期间 mtx 阻止其他读写。
+// 消息 ID 为 优先级-序号，同一优先级内的序号递增
+type localQueue struct {
+	mtx   sync.Mutex
+	queue *goque.PriorityQueue
+}
+
+func openLocal(c Config) (*localQueue, error) {
+	if c.Local.Dir == "" {
+		return nil, fmt.Errorf("taskqueue: local dir is empty")
+	}
+	queue, err := goque.OpenPriorityQueue(c.Local.Dir, goque.DESC)
+	if err == goque.ErrIncompatibleType {
+		queue, err = migrateLocal(c.Local.Dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &localQueue{queue: queue}, nil
+}
+
+// migrateLocal 之前版本的本地队列没有优先级，取出全部消息后删除旧队列，以默认优先级按原顺序重新入队
+func migrateLocal(dir string) (*goque.PriorityQueue, error) {
+	old, err := goque.OpenQueue(dir)
+	if err != nil {
+		return nil, err
+	}
+	var bodies [][]byte
+	for {
+		item, err := old.Dequeue()
+		if err == goque.ErrEmpty {
+			break
+		}
+		if err != nil {
+			_ = old.Close()
+			return nil, err
+		}
+		bodies = append(bodies, item.Value)
+	}
+	err = old.Drop()
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := goque.OpenPriorityQueue(dir, goque.DESC)
+	if err != nil {
+		return nil, err
+	}
+	for _, body := range bodies {
+		if _, err := queue.Enqueue(localPriority(0), body); err != nil {
+			_ = queue.Close()
+			return nil, err
+		}
+	}
+	xlog.Info("taskqueue: local queue migrated to priority queue", xlog.Int("messages", len(bodies)))
+	return queue, nil
+}
+
+func (q *localQueue) Push(ctx context.Context, body []byte) error {
+	return q.enqueue(body, 0)
+}
+
+func (q *localQueue) PushPriority(ctx context.Context, body []byte, priority int) error {
+	return q.enqueue(body, priority)
+}
+
+func (q *localQueue) enqueue(body []byte, priority int) error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	_, err := q.queue.Enqueue(localPriority(priority), body)
+	return q.wrap(err)
+}
+
+func (q *localQueue) dequeue() (*goque.PriorityItem, error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return q.queue.Dequeue()
+}
+
+func (q *localQueue) Pop(ctx context.Context) (*Message, error) {
+	for {
+		item, err := q.dequeue()
+		if err == nil {
+			msg := localMessage(item)
+			msg.Attempts = 1
+			return msg, nil
+		}
+		if err != goque.ErrEmpty {
+			return nil, q.wrap(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(localPollInterval):
+		}
+	}
+}
+
+func (q *localQueue) Ack(msg *Message) error {
+	return nil
+}
+
+// Nack 重新放到同一优先级的队尾
+func (q *localQueue) Nack(msg *Message) error {
+	return q.enqueue(msg.Body, msg.Priority)
+}
+
+func (q *localQueue) Touch(msg *Message) error {
+	return nil
+}
+
+func (q *localQueue) Ping(ctx context.Context) error {
+	_, err := q.queue.Peek()
+	if err != nil && err != goque.ErrEmpty {
+		return q.wrap(err)
+	}
+	return nil
+}
+
+func (q *localQueue) Pending(ctx context.Context, limit int) (messages []*Message, total int, err error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	length := q.queue.Length()
+	for i := uint64(0); i < length && len(messages) < limit; i++ {
+		item, err := q.queue.PeekByOffset(i)
+		if err != nil {
+			return nil, 0, q.wrap(err)
+		}
+		messages = append(messages, localMessage(item))
+	}
+	return messages, int(length), nil
+}
+
+// Remove 重新入队后其他消息的 ID 会改变
+func (q *localQueue) Remove(ctx context.Context, id string) error {
+	return q.rebuild(id, false)
+}
+
+// MoveToFront 目标消息提升到等待中的最高优先级并排在最前，重新入队后全部消息的 ID 会改变
+func (q *localQueue) MoveToFront(ctx context.Context, id string) error {
+	return q.rebuild(id, true)
+}
+
+// rebuild 取出全部消息，按新的顺序重新入队：front 为 true 时目标消息放在最前，否则删除目标消息
+func (q *localQueue) rebuild(id string, front bool) error {
+	level, target, ok := parseLocalID(id)
+	if !ok {
+		return ErrNotFound
+	}
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if _, err := q.queue.PeekByPriorityID(level, target); err != nil {
+		if err == goque.ErrEmpty || err == goque.ErrOutOfBounds {
+			return ErrNotFound
+		}
+		return q.wrap(err)
+	}
+
+	// 按出队顺序取出，第一条消息的优先级最高
+	var items []*goque.PriorityItem
+	for {
+		item, err := q.queue.Dequeue()
+		if err == goque.ErrEmpty {
+			break
+		}
+		if err != nil {
+			return q.wrap(err)
+		}
+		if item.Priority != level || item.ID != target {
+			items = append(items, item)
+		} else if front {
+			if len(items) > 0 {
+				item.Priority = items[0].Priority
+			}
+			items = append([]*goque.PriorityItem{item}, items...)
+		}
+	}
+
+	for _, item := range items {
+		if _, err := q.queue.Enqueue(item.Priority, item.Value); err != nil {
+			return q.wrap(err)
+		}
+	}
+	return nil
+}
+
+func (q *localQueue) Close() error {
+	return q.queue.Close()
+}
+
+func (q *localQueue) wrap(err error) error {
+	if err == goque.ErrDBClosed {
+		return ErrClosed
+	}
+	return err
+}
+
+// localPriority 超出 goque 范围的优先级取边界值
+func localPriority(priority int) uint8 {
+	level := priority + localPriorityOffset
+	if level < 0 {
+		return 0
+	}
+	if level > 255 {
+		return 255
+	}
+	return uint8(level)
+}
+
+func localMessage(item *goque.PriorityItem) *Message {
+	return &Message{
+		ID:       fmt.Sprintf("%d-%d", item.Priority, item.ID),
+		Body:     item.Value,
+		Priority: int(item.Priority) - localPriorityOffset,
+	}
+}
+
+func parseLocalID(id string) (level uint8, seq uint64, ok bool) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 {
+		return
+	}
+	l, err := strconv.ParseUint(parts[0], 10, 8)
+	if err != nil {
+		return
+	}
+	seq, err = strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return
+	}
+	return uint8(l), seq, true
+}
@@ -0,0 +1,189 @@
+package taskqueue
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openTestLocal(t *testing.T) Queue {
+	dir, err := ioutil.TempDir("", "taskqueue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := Open(Config{Local: LocalConfig{Dir: dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = q.Close()
+		_ = os.RemoveAll(dir)
+	})
+	return q
+}
+
+func TestLocalPushPop(t *testing.T) {
+	q := openTestLocal(t)
+	ctx := context.Background()
+
+	for _, body := range []string{"a", "b"} {
+		if err := q.Push(ctx, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, err := q.Pop(ctx)
+	if err != nil || string(a.Body) != "a" || a.Attempts != 1 {
+		t.Fatalf("pop = %+v, %v", a, err)
+	}
+	// Nack 放到队尾
+	if err := q.Nack(a); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"b", "a"} {
+		msg, err := q.Pop(ctx)
+		if err != nil || string(msg.Body) != want {
+			t.Fatalf("pop = %+v, %v, want %s", msg, err, want)
+		}
+		if err := q.Ack(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalPopCanceled(t *testing.T) {
+	q := openTestLocal(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.Pop(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	_ = q.Close()
+	if err := q.Push(context.Background(), []byte("a")); err != ErrClosed {
+		t.Fatalf("push after close err = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestLocalManager(t *testing.T) {
+	q := openTestLocal(t)
+	m := q.(Manager)
+	ctx := context.Background()
+
+	for _, body := range []string{"a", "b", "c"} {
+		if err := q.Push(ctx, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending := func(limit int) (bodies []string, ids []string, total int) {
+		messages, total, err := m.Pending(ctx, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, msg := range messages {
+			bodies = append(bodies, string(msg.Body))
+			ids = append(ids, msg.ID)
+		}
+		return
+	}
+
+	bodies, _, total := pending(2)
+	if total != 3 || !reflect.DeepEqual(bodies, []string{"a", "b"}) {
+		t.Fatalf("pending = %v, total %d", bodies, total)
+	}
+
+	_, ids, _ := pending(10)
+	if err := m.MoveToFront(ctx, ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	bodies, ids, _ = pending(10)
+	if !reflect.DeepEqual(bodies, []string{"c", "a", "b"}) {
+		t.Fatalf("pending after move = %v", bodies)
+	}
+
+	if err := m.Remove(ctx, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Remove(ctx, ids[1]); err != ErrNotFound {
+		t.Fatalf("remove twice err = %v, want %v", err, ErrNotFound)
+	}
+	if err := m.MoveToFront(ctx, "invalid"); err != ErrNotFound {
+		t.Fatalf("move invalid id err = %v, want %v", err, ErrNotFound)
+	}
+
+	for _, want := range []string{"c", "b"} {
+		msg, err := q.Pop(ctx)
+		if err != nil || string(msg.Body) != want {
+			t.Fatalf("pop = %+v, %v, want %s", msg, err, want)
+		}
+	}
+	if _, _, total := pending(10); total != 0 {
+		t.Fatalf("pending total = %d, want 0", total)
+	}
+}
+
+func TestLocalPriority(t *testing.T) {
+	q := openTestLocal(t)
+	ctx := context.Background()
+
+	for _, item := range []struct {
+		body     string
+		priority int
+	}{{"nightly", -5}, {"a", 0}, {"release", 5}, {"b", 0}, {"hotfix", 1000}} {
+		if err := PushPriority(ctx, q, []byte(item.body), item.priority); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	messages, _, err := q.(Manager).Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bodies []string
+	for _, msg := range messages {
+		bodies = append(bodies, string(msg.Body))
+	}
+	if want := []string{"hotfix", "release", "a", "b", "nightly"}; !reflect.DeepEqual(bodies, want) {
+		t.Fatalf("pending = %v, want %v", bodies, want)
+	}
+
+	// 移到队首的消息提升到最高的优先级
+	if err := q.(Manager).MoveToFront(ctx, messages[4].ID); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := q.Pop(ctx)
+	if err != nil || string(msg.Body) != "nightly" || msg.Priority != 127 {
+		t.Fatalf("pop = %+v, %v", msg, err)
+	}
+
+	msg, err = q.Pop(ctx)
+	if err != nil || string(msg.Body) != "hotfix" {
+		t.Fatalf("pop = %+v, %v", msg, err)
+	}
+	msg, err = q.Pop(ctx)
+	if err != nil || string(msg.Body) != "release" || msg.Priority != 5 {
+		t.Fatalf("pop = %+v, %v", msg, err)
+	}
+	// Nack 后保持原来的优先级
+	if err := q.Nack(msg); err != nil {
+		t.Fatal(err)
+	}
+	msg, err = q.Pop(ctx)
+	if err != nil || string(msg.Body) != "release" {
+		t.Fatalf("pop after nack = %+v, %v", msg, err)
+	}
+}
+
+func TestOpenUnsupported(t *testing.T) {
+	if _, err := Open(Config{Backend: "kafka"}); err == nil {
+		t.Fatal("expect error for unsupported backend")
+	}
+}
@@ -0,0 +1,138 @@
+package taskqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nsqio/go-nsq"
+)
+
+// nsqQueue 生产者发布到 NSQDAddr，消费者通过 channel 共享消息。
+// 消息在 MsgTimeout（VisibilityTimeout）内没有 FIN 会由 nsqd 重新投递，MaxAttempts 由 go-nsq 处理
+type nsqQueue struct {
+	producer *nsq.Producer
+	consumer *nsq.Consumer
+	topic    string
+	messages chan *nsq.Message
+	done     chan struct{}
+	once     sync.Once
+}
+
+func openNSQ(c Config) (*nsqQueue, error) {
+	if c.NSQ.NSQDAddr == "" {
+		return nil, fmt.Errorf("taskqueue: nsq nsqdAddr is empty")
+	}
+	config := nsq.NewConfig()
+	config.MsgTimeout = c.VisibilityTimeout
+	config.MaxInFlight = c.MaxInFlight
+	if c.MaxAttempts > 0 {
+		config.MaxAttempts = uint16(c.MaxAttempts)
+	}
+
+	producer, err := nsq.NewProducer(c.NSQ.NSQDAddr, config)
+	if err != nil {
+		return nil, err
+	}
+	consumer, err := nsq.NewConsumer(c.Name, c.Group, config)
+	if err != nil {
+		producer.Stop()
+		return nil, err
+	}
+
+	q := &nsqQueue{
+		producer: producer,
+		consumer: consumer,
+		topic:    c.Name,
+		messages: make(chan *nsq.Message),
+		done:     make(chan struct{}),
+	}
+	consumer.AddConcurrentHandlers(nsq.HandlerFunc(q.handle), c.MaxInFlight)
+
+	if len(c.NSQ.LookupdAddrs) > 0 {
+		err = consumer.ConnectToNSQLookupds(c.NSQ.LookupdAddrs)
+	} else {
+		err = consumer.ConnectToNSQD(c.NSQ.NSQDAddr)
+	}
+	if err != nil {
+		_ = q.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// handle 将消息交给 Pop，由调用方 Ack/Nack
+func (q *nsqQueue) handle(msg *nsq.Message) error {
+	msg.DisableAutoResponse()
+	select {
+	case q.messages <- msg:
+	case <-q.done:
+		// 关闭时没有被取走的消息立即重新入队
+		msg.RequeueWithoutBackoff(0)
+	}
+	return nil
+}
+
+func (q *nsqQueue) Push(ctx context.Context, body []byte) error {
+	select {
+	case <-q.done:
+		return ErrClosed
+	default:
+	}
+	return q.producer.Publish(q.topic, body)
+}
+
+func (q *nsqQueue) Pop(ctx context.Context) (*Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-q.done:
+		return nil, ErrClosed
+	case msg := <-q.messages:
+		return &Message{
+			ID:       string(msg.ID[:]),
+			Body:     msg.Body,
+			Attempts: int(msg.Attempts),
+			raw:      msg,
+		}, nil
+	}
+}
+
+func (q *nsqQueue) Ack(msg *Message) error {
+	q.raw(msg).Finish()
+	return nil
+}
+
+// Nack 立即重新入队，不触发消费者的退避
+func (q *nsqQueue) Nack(msg *Message) error {
+	q.raw(msg).RequeueWithoutBackoff(0)
+	return nil
+}
+
+func (q *nsqQueue) Touch(msg *Message) error {
+	q.raw(msg).Touch()
+	return nil
+}
+
+func (q *nsqQueue) Ping(ctx context.Context) error {
+	return q.producer.Ping()
+}
+
+// Close 停止消费并等待进行中的连接关闭，最长等待 5s
+func (q *nsqQueue) Close() error {
+	q.once.Do(func() {
+		close(q.done)
+		q.consumer.Stop()
+		select {
+		case <-q.consumer.StopChan:
+		case <-time.After(5 * time.Second):
+		}
+		q.producer.Stop()
+	})
+	return nil
+}
+
+func (q *nsqQueue) raw(msg *Message) *nsq.Message {
+	return msg.raw.(*nsq.Message)
+}
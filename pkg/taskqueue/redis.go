@@ -0,0 +1,322 @@
+package taskqueue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	jredis "github.com/douyu/jupiter/pkg/client/redis"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/go-redis/redis"
+)
+
+const (
+	// redisBlock XREADGROUP 的阻塞时间，同时也是检查超时消息的间隔
+	redisBlock = time.Second
+	// redisClaimBatch 每次检查的待确认消息数
+	redisClaimBatch = 10
+
+	redisFieldBody = "body"
+	// redisFieldAttempts Nack 重新入队时记录之前的投递次数
+	redisFieldAttempts = "attempts"
+)
+
+// redisQueue 基于 Redis Streams 的消费组。
+// 读取的消息进入消费组的待确认列表，Ack 后从 stream 删除；
+// 待确认时间超过 VisibilityTimeout 的消息由其他消费者通过 XCLAIM 认领
+type redisQueue struct {
+	client   *jredis.Redis
+	config   Config
+	closed   int32
+	consumer string
+}
+
+func openRedis(c Config) (*redisQueue, error) {
+	if len(c.Redis.Addrs) == 0 {
+		return nil, fmt.Errorf("taskqueue: redis addrs is empty")
+	}
+	config := jredis.DefaultRedisConfig()
+	config.Addrs = c.Redis.Addrs
+	config.Mode = c.Redis.Mode
+	config.Password = c.Redis.Password
+	config.DB = c.Redis.DB
+	config.OnDialError = "error"
+
+	q := &redisQueue{client: config.Build(), config: c, consumer: c.Consumer}
+	err := q.client.Client.XGroupCreateMkStream(c.Name, c.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		_ = q.client.Close()
+		return nil, fmt.Errorf("taskqueue: create consumer group: %w", err)
+	}
+	return q, nil
+}
+
+func (q *redisQueue) Push(ctx context.Context, body []byte) error {
+	return q.push(body, 0)
+}
+
+func (q *redisQueue) push(body []byte, attempts int) error {
+	if q.isClosed() {
+		return ErrClosed
+	}
+	values := map[string]interface{}{redisFieldBody: body}
+	if attempts > 0 {
+		values[redisFieldAttempts] = attempts
+	}
+	return q.client.Client.XAdd(&redis.XAddArgs{Stream: q.config.Name, Values: values}).Err()
+}
+
+func (q *redisQueue) Pop(ctx context.Context) (*Message, error) {
+	for {
+		if q.isClosed() {
+			return nil, ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		msg, err := q.claim()
+		if err != nil {
+			return nil, err
+		}
+		if msg != nil {
+			return msg, nil
+		}
+
+		streams, err := q.client.Client.XReadGroup(&redis.XReadGroupArgs{
+			Group:    q.config.Group,
+			Consumer: q.consumer,
+			Streams:  []string{q.config.Name, ">"},
+			Count:    1,
+			Block:    redisBlock,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if q.isClosed() {
+				return nil, ErrClosed
+			}
+			return nil, err
+		}
+		for _, stream := range streams {
+			for _, item := range stream.Messages {
+				return q.message(item, 1), nil
+			}
+		}
+	}
+}
+
+// claim 认领待确认时间超过 VisibilityTimeout 的消息，投递次数超过 MaxAttempts 的直接删除
+func (q *redisQueue) claim() (*Message, error) {
+	pending, err := q.client.Client.XPendingExt(&redis.XPendingExtArgs{
+		Stream: q.config.Name,
+		Group:  q.config.Group,
+		Start:  "-",
+		End:    "+",
+		Count:  redisClaimBatch,
+	}).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	for _, p := range pending {
+		if p.Idle < q.config.VisibilityTimeout {
+			continue
+		}
+		items, err := q.client.Client.XClaim(&redis.XClaimArgs{
+			Stream:   q.config.Name,
+			Group:    q.config.Group,
+			Consumer: q.consumer,
+			MinIdle:  q.config.VisibilityTimeout,
+			Messages: []string{p.Id},
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			// 已被其他消费者认领
+			continue
+		}
+
+		msg := q.message(items[0], int(p.RetryCount)+1)
+		if q.config.MaxAttempts > 0 && msg.Attempts > q.config.MaxAttempts {
+			xlog.Error("taskqueue: drop message exceeded max attempts",
+				xlog.String("id", msg.ID), xlog.Int("attempts", msg.Attempts), xlog.ByteString("body", msg.Body))
+			_ = q.Ack(msg)
+			continue
+		}
+		return msg, nil
+	}
+	return nil, nil
+}
+
+// message deliveries 为当前 stream 条目的投递次数，加上 Nack 之前的投递次数
+func (q *redisQueue) message(item redis.XMessage, deliveries int) *Message {
+	msg := &Message{ID: item.ID, Attempts: deliveries}
+	if body, ok := item.Values[redisFieldBody].(string); ok {
+		msg.Body = []byte(body)
+	}
+	if attempts, ok := item.Values[redisFieldAttempts].(string); ok {
+		n, _ := strconv.Atoi(attempts)
+		msg.Attempts += n
+	}
+	return msg
+}
+
+func (q *redisQueue) Ack(msg *Message) error {
+	err := q.client.Client.XAck(q.config.Name, q.config.Group, msg.ID).Err()
+	if err != nil {
+		return err
+	}
+	return q.client.Client.XDel(q.config.Name, msg.ID).Err()
+}
+
+// Nack 以新条目重新入队并确认原条目，投递次数记录在新条目中
+func (q *redisQueue) Nack(msg *Message) error {
+	if err := q.push(msg.Body, msg.Attempts); err != nil {
+		return err
+	}
+	return q.Ack(msg)
+}
+
+// Touch 由当前消费者重新认领，重置待确认时间，JUSTID 不增加投递次数
+func (q *redisQueue) Touch(msg *Message) error {
+	return q.client.Client.XClaimJustID(&redis.XClaimArgs{
+		Stream:   q.config.Name,
+		Group:    q.config.Group,
+		Consumer: q.consumer,
+		Messages: []string{msg.ID},
+	}).Err()
+}
+
+func (q *redisQueue) Ping(ctx context.Context) error {
+	return q.client.Client.Ping().Err()
+}
+
+// redisWaitingScript 返回消费组尚未投递的条目，即 last-delivered-id 之后的条目。
+// 脚本中读取和修改是原子的，不会和其他消费者的 XREADGROUP 交叉
+const redisWaitingScript = `
+redis.replicate_commands()
+local function waiting(stream, group)
+	local last = '0-0'
+	for _, g in ipairs(redis.call('XINFO', 'GROUPS', stream)) do
+		local name, id
+		for i = 1, #g, 2 do
+			if g[i] == 'name' then name = g[i + 1] end
+			if g[i] == 'last-delivered-id' then id = g[i + 1] end
+		end
+		if name == group then last = id end
+	end
+	local items = redis.call('XRANGE', stream, last, '+')
+	if #items > 0 and items[1][1] == last then
+		table.remove(items, 1)
+	end
+	return items
+end
+`
+
+var (
+	// redisPendingScript 返回 [总数, id, 字段列表, ...]
+	redisPendingScript = redis.NewScript(redisWaitingScript + `
+local items = waiting(KEYS[1], ARGV[1])
+local result = {#items}
+for i = 1, math.min(#items, tonumber(ARGV[2])) do
+	table.insert(result, items[i][1])
+	table.insert(result, items[i][2])
+end
+return result
+`)
+
+	// redisRemoveScript 删除尚未投递的条目，返回删除的条目数
+	redisRemoveScript = redis.NewScript(redisWaitingScript + `
+for _, item in ipairs(waiting(KEYS[1], ARGV[1])) do
+	if item[1] == ARGV[2] then
+		return redis.call('XDEL', KEYS[1], item[1])
+	end
+end
+return 0
+`)
+
+	// redisMoveToFrontScript stream 只能在末尾追加，删除全部等待条目后以目标条目在前的顺序重新追加。
+	// 返回 1 表示找到目标条目
+	redisMoveToFrontScript = redis.NewScript(redisWaitingScript + `
+local items = waiting(KEYS[1], ARGV[1])
+local target
+for i, item in ipairs(items) do
+	if item[1] == ARGV[2] then target = i end
+end
+if target == nil then
+	return 0
+end
+table.insert(items, 1, table.remove(items, target))
+for _, item in ipairs(items) do
+	redis.call('XDEL', KEYS[1], item[1])
+end
+for _, item in ipairs(items) do
+	redis.call('XADD', KEYS[1], '*', unpack(item[2]))
+end
+return 1
+`)
+)
+
+func (q *redisQueue) Pending(ctx context.Context, limit int) (messages []*Message, total int, err error) {
+	result, err := redisPendingScript.Run(q.client.Client, []string{q.config.Name}, q.config.Group, limit).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	values, _ := result.([]interface{})
+	if len(values) == 0 {
+		return nil, 0, fmt.Errorf("taskqueue: unexpected pending result %v", result)
+	}
+	total64, _ := values[0].(int64)
+	for i := 1; i+1 < len(values); i += 2 {
+		id, _ := values[i].(string)
+		fields, _ := values[i+1].([]interface{})
+		item := redis.XMessage{ID: id, Values: make(map[string]interface{})}
+		for j := 0; j+1 < len(fields); j += 2 {
+			key, _ := fields[j].(string)
+			item.Values[key] = fields[j+1]
+		}
+		messages = append(messages, q.message(item, 0))
+	}
+	return messages, int(total64), nil
+}
+
+func (q *redisQueue) Remove(ctx context.Context, id string) error {
+	n, err := redisRemoveScript.Run(q.client.Client, []string{q.config.Name}, q.config.Group, id).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MoveToFront 重新追加后等待中的条目 ID 都会改变
+func (q *redisQueue) MoveToFront(ctx context.Context, id string) error {
+	n, err := redisMoveToFrontScript.Run(q.client.Client, []string{q.config.Name}, q.config.Group, id).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (q *redisQueue) Close() error {
+	if !atomic.CompareAndSwapInt32(&q.closed, 0, 1) {
+		return nil
+	}
+	return q.client.Close()
+}
+
+func (q *redisQueue) isClosed() bool {
+	return atomic.LoadInt32(&q.closed) == 1
+}
@@ -0,0 +1,183 @@
+// Package taskqueue worker 的任务队列。
+// local 使用本地磁盘上的 goque，只能由一个进程消费，取出即删除；
+// redis（Streams）、nsq 由多个 worker 共享，取出的消息在 VisibilityTimeout 内没有 Ack 会重新投递给其他 worker，
+// 保证至少投递一次，任务处理需要能容忍重复执行。
+// 只有 local 支持优先级，redis、nsq 按入队顺序出队
+package taskqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	BackendLocal = "local"
+	BackendRedis = "redis"
+	BackendNSQ   = "nsq"
+)
+
+const (
+	defaultName              = "juno_test_task"
+	defaultGroup             = "worker"
+	defaultVisibilityTimeout = time.Minute
+)
+
+var (
+	ErrClosed = errors.New("taskqueue: closed")
+	// ErrNotFound 消息不存在，或者已经被取出
+	ErrNotFound = errors.New("taskqueue: message not found")
+)
+
+type (
+	// Config 队列配置，backend 为空时使用 local
+	Config struct {
+		Backend string
+		// Name redis 的 stream key、nsq 的 topic，默认 juno_test_task
+		Name string
+		// Group redis 的 consumer group、nsq 的 channel，共享队列的 worker 需要使用相同的 group，默认 worker
+		Group string
+		// Consumer redis 的消费者名称，默认为 主机名-进程号
+		Consumer string
+		// VisibilityTimeout 取出后没有 Ack 也没有 Touch 的消息重新投递的时间，默认 1m
+		VisibilityTimeout time.Duration
+		// MaxAttempts 消息最多投递的次数，超过后丢弃并记录日志，0 表示不限制
+		MaxAttempts int
+		// MaxInFlight nsq 同时处理的消息数，通常等于 worker 的并发数，默认 1
+		MaxInFlight int
+
+		Local LocalConfig
+		Redis RedisConfig
+		NSQ   NSQConfig
+	}
+
+	LocalConfig struct {
+		Dir string
+	}
+
+	RedisConfig struct {
+		Addrs []string
+		// Mode stub 或 cluster，为空时按地址个数判断
+		Mode     string
+		Password string
+		DB       int
+	}
+
+	NSQConfig struct {
+		// NSQDAddr 发布消息的 nsqd 地址
+		NSQDAddr string
+		// LookupdAddrs 不为空时通过 nsqlookupd 发现 nsqd 消费，否则直接消费 NSQDAddr
+		LookupdAddrs []string
+	}
+
+	// Message 取出的消息，处理完成后需要 Ack 或 Nack
+	Message struct {
+		ID   string
+		Body []byte
+		// Attempts 第几次投递，从 1 开始
+		Attempts int
+		// Priority 入队时的优先级，不支持优先级的队列为 0
+		Priority int
+
+		raw interface{}
+	}
+
+	Queue interface {
+		Push(ctx context.Context, body []byte) error
+		// Pop 阻塞直到取到消息、ctx 结束或队列关闭
+		Pop(ctx context.Context) (*Message, error)
+		// Ack 处理完成，消息不再投递
+		Ack(msg *Message) error
+		// Nack 放弃处理，消息重新入队，由其他 worker 处理
+		Nack(msg *Message) error
+		// Touch 重置消息的 visibility timeout，处理时间较长时需要定期调用
+		Touch(msg *Message) error
+		Ping(ctx context.Context) error
+		Close() error
+	}
+
+	// Manager 查看和调整尚未被取出的消息，用于故障时人工干预，local 和 redis 支持。
+	// 只能操作等待中的消息，已经取出正在处理的消息不受影响
+	Manager interface {
+		// Pending 按出队顺序返回前 limit 条等待中的消息，以及等待中的消息总数
+		Pending(ctx context.Context, limit int) (messages []*Message, total int, err error)
+		// Remove 删除等待中的消息
+		Remove(ctx context.Context, id string) error
+		// MoveToFront 将等待中的消息移到队首，下一次 Pop 时优先取出
+		MoveToFront(ctx context.Context, id string) error
+	}
+
+	// Prioritizer 按优先级出队的队列，local 支持。priority 大的先出队，同一优先级先进先出，默认为 0
+	Prioritizer interface {
+		PushPriority(ctx context.Context, body []byte, priority int) error
+	}
+)
+
+// Open 按 backend 创建队列
+func Open(c Config) (Queue, error) {
+	c = c.withDefaults()
+	switch c.Backend {
+	case BackendLocal:
+		return openLocal(c)
+	case BackendRedis:
+		return openRedis(c)
+	case BackendNSQ:
+		return openNSQ(c)
+	default:
+		return nil, fmt.Errorf("taskqueue: unsupported backend %q, expect %s, %s or %s", c.Backend, BackendLocal, BackendRedis, BackendNSQ)
+	}
+}
+
+func (c Config) withDefaults() Config {
+	if c.Backend == "" {
+		c.Backend = BackendLocal
+	}
+	if c.Name == "" {
+		c.Name = defaultName
+	}
+	if c.Group == "" {
+		c.Group = defaultGroup
+	}
+	if c.Consumer == "" {
+		host, _ := os.Hostname()
+		c.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if c.VisibilityTimeout <= 0 {
+		c.VisibilityTimeout = defaultVisibilityTimeout
+	}
+	if c.MaxInFlight <= 0 {
+		c.MaxInFlight = 1
+	}
+	return c
+}
+
+// PushPriority 队列支持优先级时按 priority 入队，否则忽略优先级
+func PushPriority(ctx context.Context, q Queue, body []byte, priority int) error {
+	if p, ok := q.(Prioritizer); ok {
+		return p.PushPriority(ctx, body, priority)
+	}
+	return q.Push(ctx, body)
+}
+
+// KeepAlive 每隔 VisibilityTimeout/3 调用一次 Touch，直到返回的函数被调用
+func KeepAlive(q Queue, msg *Message, visibilityTimeout time.Duration) (stop func()) {
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = defaultVisibilityTimeout
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(visibilityTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = q.Touch(msg)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,148 @@
+// Package tracing 分布式追踪，基于 Jupiter 的 opentracing + Jaeger 实现。
+// HTTP 服务端的 span 由 xecho 中间件创建，本包负责初始化 tracer、为 HTTP 客户端注入追踪头，
+// 以及通过任务、配置等异步载荷传递追踪上下文
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/douyu/jupiter/pkg/trace"
+	"github.com/douyu/jupiter/pkg/trace/jaeger"
+	"github.com/douyu/jupiter/pkg/xlog"
+	"github.com/go-resty/resty/v2"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// Config 追踪配置。上报到 OTLP 后端时，可由 OpenTelemetry Collector 的 jaeger receiver 接收后转发
+type Config struct {
+	Enable bool `toml:"enable"`
+	// ServiceName 为空时使用各组件的默认名称，如 juno-admin、juno-proxy、juno-worker
+	ServiceName string `toml:"serviceName"`
+	// AgentAddr Jaeger agent 的 UDP 地址，默认 127.0.0.1:6831
+	AgentAddr string `toml:"agentAddr"`
+	// CollectorEndpoint 不为空时直接通过 HTTP 上报到 collector，如 http://jaeger:14268/api/traces
+	CollectorEndpoint string `toml:"collectorEndpoint"`
+	// SampleRate 采样率，0~1
+	SampleRate float64 `toml:"sampleRate"`
+}
+
+// Init 初始化全局 tracer，未开启时使用 opentracing 的空实现
+func Init(c Config, defaultName string) {
+	if !c.Enable {
+		return
+	}
+
+	config := jaeger.DefaultConfig()
+	config.ServiceName = defaultName
+	if c.ServiceName != "" {
+		config.ServiceName = c.ServiceName
+	}
+	config.Sampler.Type = "probabilistic"
+	config.Sampler.Param = c.SampleRate
+	if c.AgentAddr != "" {
+		config.Reporter.LocalAgentHostPort = c.AgentAddr
+	}
+	config.Reporter.CollectorEndpoint = c.CollectorEndpoint
+	config.PanicOnError = false
+
+	trace.SetGlobalTracer(config.Build())
+	xlog.Info("tracing enabled", xlog.String("service", config.ServiceName), xlog.Any("sampleRate", c.SampleRate))
+}
+
+// StartSpan 创建 ctx 中 span 的子 span，ctx 中没有 span 时创建新的 trace
+func StartSpan(ctx context.Context, operation string, tags ...opentracing.Tag) (opentracing.Span, context.Context) {
+	opts := make([]opentracing.StartSpanOption, 0, len(tags))
+	for _, tag := range tags {
+		opts = append(opts, tag)
+	}
+	return opentracing.StartSpanFromContext(ctx, operation, opts...)
+}
+
+// Finish 结束 span，err 不为空时标记为失败
+func Finish(span opentracing.Span, err error) {
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.SetTag("error.message", err.Error())
+	}
+	span.Finish()
+}
+
+// Inject 将 ctx 中的追踪上下文序列化为 HTTP 头格式的 map，用于放入任务等异步载荷
+func Inject(ctx context.Context) map[string]string {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return nil
+	}
+
+	carrier := opentracing.TextMapCarrier{}
+	err := opentracing.GlobalTracer().Inject(span.Context(), opentracing.HTTPHeaders, carrier)
+	if err != nil || len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// StartSpanFromCarrier 以 Inject 得到的追踪上下文为父 span 创建 span，carrier 为空时创建新的 trace
+func StartSpanFromCarrier(ctx context.Context, carrier map[string]string, operation string, tags ...opentracing.Tag) (opentracing.Span, context.Context) {
+	opts := make([]opentracing.StartSpanOption, 0, len(tags)+1)
+	if len(carrier) > 0 {
+		parent, err := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, opentracing.TextMapCarrier(carrier))
+		if err == nil {
+			opts = append(opts, opentracing.ChildOf(parent))
+		}
+	}
+	for _, tag := range tags {
+		opts = append(opts, tag)
+	}
+
+	span := opentracing.StartSpan(operation, opts...)
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
+
+// Transport 为请求创建客户端 span 并注入追踪头，请求的 Context 中没有 span 时不做处理
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+// WrapResty 为 resty 客户端注入追踪头，请求需要通过 SetContext 传入上游的 Context
+func WrapResty(client *resty.Client) *resty.Client {
+	return client.SetTransport(Transport(client.GetClient().Transport))
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	parent := opentracing.SpanFromContext(req.Context())
+	if parent == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	span := opentracing.StartSpan("HTTP "+req.Method+" "+req.URL.Path, opentracing.ChildOf(parent.Context()))
+	ext.SpanKindRPCClient.Set(span)
+	ext.HTTPMethod.Set(span, req.Method)
+	ext.HTTPUrl.Set(span, req.URL.String())
+	ext.PeerHostname.Set(span, req.URL.Host)
+
+	// RoundTripper 不能修改传入的请求
+	req = req.Clone(req.Context())
+	_ = opentracing.GlobalTracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+		if resp.StatusCode >= http.StatusInternalServerError {
+			ext.Error.Set(span, true)
+			span.SetTag("error.message", "status "+strconv.Itoa(resp.StatusCode))
+		}
+	}
+	Finish(span, err)
+	return resp, err
+}
@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func useMockTracer(t *testing.T) *mocktracer.MockTracer {
+	tracer := mocktracer.New()
+	prev := opentracing.GlobalTracer()
+	opentracing.SetGlobalTracer(tracer)
+	t.Cleanup(func() { opentracing.SetGlobalTracer(prev) })
+	return tracer
+}
+
+func TestCarrier(t *testing.T) {
+	tracer := useMockTracer(t)
+
+	if carrier := Inject(context.Background()); carrier != nil {
+		t.Fatalf("Inject without span = %v, want nil", carrier)
+	}
+
+	parent, ctx := StartSpan(context.Background(), "dispatch")
+	carrier := Inject(ctx)
+	if len(carrier) == 0 {
+		t.Fatal("Inject returned empty carrier")
+	}
+
+	child, _ := StartSpanFromCarrier(context.Background(), carrier, "run")
+	Finish(child, errors.New("boom"))
+	parent.Finish()
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	run, dispatch := spans[0], spans[1]
+	if run.ParentID != dispatch.SpanContext.SpanID || run.SpanContext.TraceID != dispatch.SpanContext.TraceID {
+		t.Errorf("span run is not a child of dispatch")
+	}
+	if run.Tag("error") != true || run.Tag("error.message") != "boom" {
+		t.Errorf("error tags = %v", run.Tags())
+	}
+
+	// carrier 为空时创建新的 trace
+	orphan, _ := StartSpanFromCarrier(context.Background(), nil, "orphan")
+	orphan.Finish()
+	if span := tracer.FinishedSpans()[2]; span.ParentID != 0 {
+		t.Errorf("orphan span has parent %d", span.ParentID)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	tracer := useMockTracer(t)
+
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+
+	// 没有上游 span 时不注入追踪头
+	resp, err := client.Get(srv.URL + "/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(tracer.FinishedSpans()) != 0 || header.Get("Mockpfx-Ids-Traceid") != "" {
+		t.Fatalf("request without parent span was traced")
+	}
+
+	parent, ctx := StartSpan(context.Background(), "publish")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/api/v1/testTask/dispatch", nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	parent.Finish()
+
+	if len(req.Header) != 0 {
+		t.Errorf("original request header modified: %v", req.Header)
+	}
+	spans := tracer.FinishedSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	clientSpan := spans[0]
+	if clientSpan.OperationName != "HTTP POST /api/v1/testTask/dispatch" {
+		t.Errorf("operation = %q", clientSpan.OperationName)
+	}
+	if clientSpan.Tag("http.status_code") != uint16(http.StatusBadGateway) || clientSpan.Tag("error") != true {
+		t.Errorf("tags = %v", clientSpan.Tags())
+	}
+
+	extracted, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+	if err != nil {
+		t.Fatalf("extract from request header: %v", err)
+	}
+	if extracted.(mocktracer.MockSpanContext).SpanID != clientSpan.SpanContext.SpanID {
+		t.Errorf("injected span is not the client span")
+	}
+}
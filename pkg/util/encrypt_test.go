@@ -28,3 +28,25 @@ func TestPKCS7UnPadding(t *testing.T) {
 		})
 	}
 }
+
+func TestAESGCM(t *testing.T) {
+	cryted, err := AESGCMEncrypt("token", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := AESGCMEncrypt("token", "secret")
+	if cryted == again {
+		t.Error("nonce should be random")
+	}
+
+	orig, err := AESGCMDecrypt(cryted, "secret")
+	if err != nil || orig != "token" {
+		t.Errorf("AESGCMDecrypt() = %q, %v", orig, err)
+	}
+	if _, err = AESGCMDecrypt(cryted, "other"); err == nil {
+		t.Error("expect error with wrong secret")
+	}
+	if _, err = AESGCMEncrypt("token", ""); err == nil {
+		t.Error("expect error with empty secret")
+	}
+}
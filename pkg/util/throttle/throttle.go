@@ -0,0 +1,130 @@
+package throttle
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// chunkSize 每次写入的最大字节数，同时作为限速器的 burst
+const chunkSize = 32 * 1024
+
+// NewLimiter 创建每秒 bytesPerSec 字节的限速器，bytesPerSec <= 0 表示不限速
+func NewLimiter(bytesPerSec int64) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), chunkSize)
+}
+
+// Writer 写入前需要从所有限速器获取令牌
+type Writer struct {
+	ctx      context.Context
+	w        io.Writer
+	limiters []*rate.Limiter
+}
+
+// NewWriter 包装 w，写入速度受 limiters 中最严格的一个限制，nil 限速器会被忽略
+func NewWriter(ctx context.Context, w io.Writer, limiters ...*rate.Limiter) *Writer {
+	ret := &Writer{
+		ctx: ctx,
+		w:   w,
+	}
+	for _, limiter := range limiters {
+		if limiter != nil {
+			ret.limiters = append(ret.limiters, limiter)
+		}
+	}
+	return ret
+}
+
+func (t *Writer) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+
+		for _, limiter := range t.limiters {
+			err = limiter.WaitN(t.ctx, len(chunk))
+			if err != nil {
+				return
+			}
+		}
+
+		var written int
+		written, err = t.w.Write(chunk)
+		n += written
+		if err != nil {
+			return
+		}
+		p = p[written:]
+	}
+	return
+}
+
+// KeyedLimiters 按 key (如 agent 地址) 维护独立的限速器，长时间未使用的限速器会被回收
+type KeyedLimiters struct {
+	bytesPerSec int64
+	idleTimeout time.Duration
+
+	mtx      sync.Mutex
+	limiters map[string]*keyedLimiter
+}
+
+type keyedLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// NewKeyedLimiters ..
+func NewKeyedLimiters(bytesPerSec int64, idleTimeout time.Duration) *KeyedLimiters {
+	return &KeyedLimiters{
+		bytesPerSec: bytesPerSec,
+		idleTimeout: idleTimeout,
+		limiters:    make(map[string]*keyedLimiter),
+	}
+}
+
+// Get 获取 key 对应的限速器，不限速时返回 nil
+func (k *KeyedLimiters) Get(key string) *rate.Limiter {
+	return k.GetLimit(key, 0)
+}
+
+// GetLimit 同 Get，bytesPerSec > 0 时取其与默认速度中较小的一个，用于单个 key 单独配置了速度的情况
+func (k *KeyedLimiters) GetLimit(key string, bytesPerSec int64) *rate.Limiter {
+	limit := k.bytesPerSec
+	if bytesPerSec > 0 && (limit <= 0 || bytesPerSec < limit) {
+		limit = bytesPerSec
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	now := time.Now()
+	for name, item := range k.limiters {
+		if now.Sub(item.lastUsed) > k.idleTimeout {
+			delete(k.limiters, name)
+		}
+	}
+
+	item, ok := k.limiters[key]
+	if !ok {
+		item = &keyedLimiter{
+			limiter: NewLimiter(limit),
+		}
+		k.limiters[key] = item
+	} else if item.limiter.Limit() != rate.Limit(limit) {
+		// 配置变更后，正在进行的传输也按新的速度限制
+		item.limiter.SetLimit(rate.Limit(limit))
+	}
+	item.lastUsed = now
+
+	return item.limiter
+}
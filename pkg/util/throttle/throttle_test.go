@@ -0,0 +1,65 @@
+package throttle
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	data := bytes.Repeat([]byte("a"), 3*chunkSize)
+
+	// burst 允许第一个 chunk 立即写入，剩余 2 个 chunk 在 4*chunkSize 字节/秒 下约需要 500ms
+	w := NewWriter(context.Background(), &buf, NewLimiter(4*chunkSize), nil)
+	start := time.Now()
+	n, err := w.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(data) || !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("written %d bytes, want %d", n, len(data))
+	}
+	if cost := time.Since(start); cost < 400*time.Millisecond {
+		t.Errorf("write is not throttled, cost %s", cost)
+	}
+}
+
+func TestWriterWithoutLimiter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(context.Background(), &buf, NewLimiter(0))
+	_, err := w.Write([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello" {
+		t.Errorf("got %q", buf.String())
+	}
+}
+
+func TestKeyedLimiters(t *testing.T) {
+	limiters := NewKeyedLimiters(1024, time.Minute)
+	if limiters.Get("a") != limiters.Get("a") {
+		t.Errorf("limiter of the same key should be reused")
+	}
+	if limiters.Get("a") == limiters.Get("b") {
+		t.Errorf("limiter of different keys should be independent")
+	}
+	if NewKeyedLimiters(0, time.Minute).Get("a") != nil {
+		t.Errorf("limiter should be nil when unlimited")
+	}
+}
+
+func TestKeyedLimitersGetLimit(t *testing.T) {
+	limiters := NewKeyedLimiters(1024, time.Minute)
+	if got := limiters.GetLimit("a", 512).Limit(); got != 512 {
+		t.Errorf("limit = %v, want the smaller one 512", got)
+	}
+	if got := limiters.GetLimit("a", 4096).Limit(); got != 1024 {
+		t.Errorf("limit = %v, want the default 1024", got)
+	}
+	if got := NewKeyedLimiters(0, time.Minute).GetLimit("a", 512).Limit(); got != 512 {
+		t.Errorf("limit = %v, want 512 without default", got)
+	}
+}